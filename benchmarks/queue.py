@@ -0,0 +1,65 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Benchmarks for producer/consumer patterns using the Queue module."""
+
+import Queue
+import threading
+
+import weetest
+
+
+def _MakeProducerConsumerBenchmark(queue_type, p, maxsize):
+  """Create a benchmark that passes items from p producers to p consumers."""
+  def Benchmark(b):  # pylint: disable=missing-docstring
+    q = queue_type(maxsize)
+    n = b.N / p
+    def Produce():
+      for i in xrange(n):
+        q.put(i)
+    def Consume():
+      for _ in xrange(n):
+        q.get()
+    threads = []
+    for _ in xrange(p):
+      threads.append(threading.Thread(target=Produce))
+      threads.append(threading.Thread(target=Consume))
+    b.ResetTimer()
+    for t in threads:
+      t.start()
+    for t in threads:
+      t.join()
+  return Benchmark
+
+
+def BenchmarkQueuePutGet(b):
+  q = Queue.Queue()
+  for i in xrange(b.N):
+    q.put(i)
+    q.get()
+
+
+def _RegisterBenchmarks():
+  for queue_type in (Queue.Queue, Queue.LifoQueue, Queue.PriorityQueue):
+    for p in (1, 4):
+      for maxsize in (0, 10):
+        name = 'Benchmark%sProducerConsumer%d' % (queue_type.__name__, p)
+        if maxsize:
+          name += 'Bounded'
+        globals()[name] = _MakeProducerConsumerBenchmark(queue_type, p, maxsize)
+_RegisterBenchmarks()
+
+
+if __name__ == '__main__':
+  weetest.RunBenchmarks()
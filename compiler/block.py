@@ -65,6 +65,9 @@ class Block(object):
     self.checkpoints = set()
     self.loop_stack = []
     self.is_generator = False
+    # A Go expression for a *Dict holding the block's locals, which is recorded
+    # before each statement while the frame is traced, or None.
+    self.traced_locals = None
 
   @abc.abstractmethod
   def bind_var(self, writer, name, value):
@@ -152,6 +155,8 @@ class ModuleBlock(Block):
     self.filename = filename
     self.buffer = source.Buffer(src)
     self.strings = set()
+    self.str_consts = []
+    self._str_const_indexes = {}
     self.future_features = future_features
 
   def bind_var(self, writer, name, value):
@@ -167,8 +172,20 @@ class ModuleBlock(Block):
     return self._resolve_global(writer, name)
 
   def intern(self, s):
+    """Returns a Go expression for a *Str holding s.
+
+    Short identifier-like strings are interned and bound to ß-prefixed Go
+    variables. Other strings are added to the module's πStrs table. Both are
+    pre-hashed and initialized once during Go package initialization so that
+    evaluating a literal or looking up an attribute does not allocate.
+    """
     if len(s) > 64 or _non_word_re.search(s):
-      return 'πg.NewStr({})'.format(util.go_str(s))
+      i = self._str_const_indexes.get(s)
+      if i is None:
+        i = len(self.str_consts)
+        self._str_const_indexes[s] = i
+        self.str_consts.append(s)
+      return 'πStrs[{}]'.format(i)
     self.strings.add(s)
     return 'ß' + s
 
@@ -285,6 +302,9 @@ class BlockVisitor(algorithm.Visitor):
 
   def __init__(self):
     self.vars = collections.OrderedDict()
+    # Whether the block refers to a builtin that inspects its locals or
+    # contains an exec statement, which also runs in the block's locals.
+    self.uses_locals = False
 
   def visit_Assign(self, node):
     for target in node.targets:
@@ -303,6 +323,10 @@ class BlockVisitor(algorithm.Visitor):
       self._register_local(node.name.id)
     self.generic_visit(node)
 
+  def visit_Exec(self, node):
+    self.uses_locals = True
+    self.generic_visit(node)
+
   def visit_For(self, node):
     self._assign_target(node.target)
     self.generic_visit(node)
@@ -325,6 +349,10 @@ class BlockVisitor(algorithm.Visitor):
     for alias in node.names:
       self._register_local(alias.asname or alias.name)
 
+  def visit_Name(self, node):
+    if node.id in ('dir', 'eval', 'locals', 'vars'):
+      self.uses_locals = True
+
   def visit_With(self, node):
     for item in node.items:
       if item.optional_vars:
@@ -375,5 +403,6 @@ class FunctionBlockVisitor(BlockVisitor):
         raise util.ParseError(node, msg)
       self.vars[name] = Var(name, Var.TYPE_PARAM, arg_index=i)
 
-  def visit_Yield(self, unused_node): # pylint: disable=unused-argument
+  def visit_Yield(self, node):
     self.is_generator = True
+    self.generic_visit(node)
@@ -50,6 +50,15 @@ class BlockTest(unittest.TestCase):
     b.pop_loop()
     self.assertEqual(loop, b.top_loop())
 
+  def testIntern(self):
+    b = _MakeModuleBlock()
+    self.assertEqual(b.intern('foo'), 'ßfoo')
+    self.assertEqual(b.intern('foo bar'), 'πStrs[0]')
+    self.assertEqual(b.intern('x' * 65), 'πStrs[1]')
+    self.assertEqual(b.intern('foo bar'), 'πStrs[0]')
+    self.assertEqual(b.strings, {'foo'})
+    self.assertEqual(b.str_consts, ['foo bar', 'x' * 65])
+
   def testResolveName(self):
     module_block = _MakeModuleBlock()
     block_vars = {'foo': block.Var('foo', block.Var.TYPE_LOCAL)}
@@ -173,6 +182,28 @@ class BlockVisitorTest(unittest.TestCase):
     self.assertRegexpMatches(visitor.vars['baz'].init_expr, r'UnboundLocal')
     self.assertRegexpMatches(visitor.vars['qux'].init_expr, r'UnboundLocal')
 
+  def testUsesLocals(self):
+    visitor = block.BlockVisitor()
+    visitor.visit(_ParseStmt('foo = 1'))
+    self.assertFalse(visitor.uses_locals)
+    visitor.visit(_ParseStmt('print vars()'))
+    self.assertTrue(visitor.uses_locals)
+
+  def testUsesLocalsEval(self):
+    visitor = block.BlockVisitor()
+    visitor.visit(_ParseStmt('x = eval("y")'))
+    self.assertTrue(visitor.uses_locals)
+
+  def testUsesLocalsExec(self):
+    visitor = block.BlockVisitor()
+    visitor.visit(_ParseStmt('exec "y = 1"'))
+    self.assertTrue(visitor.uses_locals)
+
+  def testUsesLocalsNestedFunction(self):
+    visitor = block.BlockVisitor()
+    visitor.visit(_ParseStmt('def foo(): return locals()'))
+    self.assertFalse(visitor.uses_locals)
+
   def testGlobal(self):
     visitor = block.BlockVisitor()
     visitor.visit(_ParseStmt('global foo, bar'))
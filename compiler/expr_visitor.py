@@ -27,6 +27,19 @@ from grumpy.pythonparser import algorithm
 from grumpy.pythonparser import ast
 
 
+# Name of the parameter holding the list built by a list comprehension. It is
+# not a valid Python identifier so it never clashes with user variables.
+_LIST_COMP_VAR = 'πList'
+
+
+class ListCompAppend(ast.expr):
+  """Synthetic node that appends value to the list lst.
+
+  List comprehensions are compiled to a loop over ListCompAppend nodes.
+  """
+  _fields = ('lst', 'value')
+
+
 class ExprVisitor(algorithm.Visitor):
   """Builds and returns a Go expression representing the Python nodes."""
 
@@ -195,7 +208,7 @@ class ExprVisitor(algorithm.Visitor):
 
   def visit_DictComp(self, node):
     result = self.block.alloc_temp()
-    elt = ast.Tuple(elts=[node.key, node.value])
+    elt = ast.Tuple(elts=[node.key, node.value], ctx=None)
     gen_node = ast.GeneratorExp(
         elt=elt, generators=node.generators, loc=node.loc)
     with self.visit(gen_node) as gen:
@@ -222,14 +235,10 @@ class ExprVisitor(algorithm.Visitor):
 
   def visit_GeneratorExp(self, node):
     body = ast.Expr(value=ast.Yield(value=node.elt), loc=node.loc)
-    for comp_node in reversed(node.generators):
-      for if_node in reversed(comp_node.ifs):
-        body = ast.If(test=if_node, body=[body], orelse=[], loc=node.loc)  # pylint: disable=redefined-variable-type
-      body = ast.For(target=comp_node.target, iter=comp_node.iter,
-                     body=[body], orelse=[], loc=node.loc)
-
+    body = _comprehension_loops(node.generators, body, node.loc)
     args = ast.arguments(args=[], vararg=None, kwarg=None, defaults=[])
-    node = ast.FunctionDef(name='<generator>', args=args, body=[body])
+    node = ast.FunctionDef(name='<generator>', args=args, body=[body],
+                           loc=node.loc)
     gen_func = self.stmt_visitor.visit_function_inline(node)
     result = self.block.alloc_temp()
     self.writer.write_checked_call2(
@@ -264,7 +273,7 @@ class ExprVisitor(algorithm.Visitor):
   def visit_Lambda(self, node):
     ret = ast.Return(value=node.body, loc=node.loc)
     func_node = ast.FunctionDef(
-        name='<lambda>', args=node.args, body=[ret])
+        name='<lambda>', args=node.args, body=[ret], loc=node.loc)
     return self.stmt_visitor.visit_function_inline(func_node)
 
   def visit_List(self, node):
@@ -275,14 +284,31 @@ class ExprVisitor(algorithm.Visitor):
     return result
 
   def visit_ListComp(self, node):
+    # Like a generator expression, the comprehension runs in its own function
+    # so that its variables don't leak. Rather than yielding each element, the
+    # function appends it directly to the list passed as its argument.
+    lst = ast.Name(id=_LIST_COMP_VAR, ctx=None, loc=node.loc)
+    body = ast.Expr(value=ListCompAppend(lst=lst, value=node.elt, loc=node.loc),
+                    loc=node.loc)
+    body = _comprehension_loops(node.generators, body, node.loc)
+    args = ast.arguments(args=[ast.arg(arg=_LIST_COMP_VAR, annotation=None)],
+                         vararg=None, kwarg=None, defaults=[])
+    func_node = ast.FunctionDef(name='<listcomp>', args=args, body=[body],
+                                loc=node.loc)
     result = self.block.alloc_temp()
-    gen_node = ast.GeneratorExp(
-        elt=node.elt, generators=node.generators, loc=node.loc)
-    with self.visit(gen_node) as gen:
+    with self.stmt_visitor.visit_function_inline(func_node) as func:
+      self.writer.write('{} = πg.NewList().ToObject()'.format(result.name))
       self.writer.write_checked_call2(
-          result, 'πg.ListType.Call(πF, πg.Args{{{}}}, nil)', gen.expr)
+          expr.blank_var, '{}.Call(πF, πg.Args{{{}}}, nil)', func.expr,
+          result.expr)
     return result
 
+  def visit_ListCompAppend(self, node):
+    with self.visit(node.lst) as lst, self.visit(node.value) as value:
+      self.writer.write_checked_call1('πg.ListAppend(πF, {}, {})',
+                                      lst.expr, value.expr)
+    return expr.nil_expr
+
   def visit_Name(self, node):
     return self.block.resolve_name(self.writer, node.id)
 
@@ -299,9 +325,10 @@ class ExprVisitor(algorithm.Visitor):
       if node.n < 0:
         expr_str = expr_str + '.Neg()'
     elif isinstance(node.n, float):
-      expr_str = 'NewFloat({})'.format(node.n)
+      expr_str = 'NewFloat({!r})'.format(node.n)
     elif isinstance(node.n, complex):
-      expr_str = 'NewComplex(complex({}, {}))'.format(node.n.real, node.n.imag)
+      expr_str = 'NewComplex(complex({!r}, {!r}))'.format(
+          node.n.real, node.n.imag)
     else:
       msg = 'number type not yet implemented: ' + type(node.n).__name__
       raise util.ParseError(node, msg)
@@ -427,3 +454,13 @@ class ExprVisitor(algorithm.Visitor):
     raise util.ParseError(node, msg)
 
   visit_SetComp = _node_not_implemented
+
+
+def _comprehension_loops(generators, body, loc):
+  """Returns a statement running body for each iteration of generators."""
+  for comp_node in reversed(generators):
+    for if_node in reversed(comp_node.ifs):
+      body = ast.If(test=if_node, body=[body], orelse=[], loc=loc)  # pylint: disable=redefined-variable-type
+    body = ast.For(target=comp_node.target, iter=comp_node.iter,
+                   body=[body], orelse=[], loc=loc)
+  return body
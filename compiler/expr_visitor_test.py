@@ -132,6 +132,15 @@ class ExprVisitorTest(unittest.TestCase):
   testCompareInTuple = _MakeExprTest('1 in (1, 2, 3)')
   testCompareNotInTuple = _MakeExprTest('10 < 12 not in (1, 2, 3)')
 
+  def testCompareChainEvaluatesOperandsOnce(self):
+    code = textwrap.dedent("""\
+        def f(x):
+          print x,
+          return x
+        print f(1) < f(2) < f(3)
+        print f(1) < f(0) < f(3)""")
+    self.assertEqual((0, '1 2 3 True\n1 0 False\n'), _GrumpRun(code))
+
   testDictEmpty = _MakeLiteralTest('{}')
   testDictNonEmpty = _MakeLiteralTest("{'foo': 42, 'bar': 43}")
 
@@ -193,7 +202,11 @@ class ExprVisitorTest(unittest.TestCase):
   testNumFloatSciCap = _MakeLiteralTest('1E6', '1000000.0')
   testNumFloatSciCapPlus = _MakeLiteralTest('1E+6', '1000000.0')
   testNumFloatSciMinus = _MakeLiteralTest('1e-06')
+  testNumFloatPrecise = _MakeLiteralTest('1.2345678901234567')
+  testNumFloatLarge = _MakeLiteralTest('9007199254740993.0',
+                                       '9007199254740992.0')
   testNumComplex = _MakeLiteralTest('3j')
+  testNumComplexPrecise = _MakeLiteralTest('0.1234567890123j')
 
   testSubscriptDictStr = _MakeExprTest('{"foo": 42}["foo"]')
   testSubscriptListInt = _MakeExprTest('[1, 2, 3][2]')
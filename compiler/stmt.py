@@ -63,11 +63,15 @@ class StatementVisitor(algorithm.Visitor):
 
   def visit_Assert(self, node):
     self._write_py_context(node.lineno)
-    # TODO: Only evaluate msg if cond is false.
-    with self.visit_expr(node.msg) if node.msg else _nil_expr as msg,\
-        self.visit_expr(node.test) as cond:
-      self.writer.write_checked_call1(
-          'πg.Assert(πF, {}, {})', cond.expr, msg.expr)
+    # Skip evaluating the assertion altogether when __debug__ is false.
+    self.writer.write('if πg.Debug() {')
+    with self.writer.indent_block():
+      # TODO: Only evaluate msg if cond is false.
+      with self.visit_expr(node.msg) if node.msg else _nil_expr as msg,\
+          self.visit_expr(node.test) as cond:
+        self.writer.write_checked_call1(
+            'πg.Assert(πF, {}, {})', cond.expr, msg.expr)
+    self.writer.write('}')
 
   def visit_AugAssign(self, node):
     op_type = type(node.op)
@@ -107,8 +111,10 @@ class StatementVisitor(algorithm.Visitor):
     global_vars = {v.name for v in block_visitor.vars.values()
                    if v.type == block.Var.TYPE_GLOBAL}
     # Visit all the statements inside body of the class definition.
-    body_visitor = StatementVisitor(block.ClassBlock(
-        self.block, node.name, global_vars), self.future_node)
+    cls_block = block.ClassBlock(self.block, node.name, global_vars)
+    if not block_visitor.uses_locals:
+      cls_block.traced_locals = 'πClass'
+    body_visitor = StatementVisitor(cls_block, self.future_node)
     # Indent so that the function body is aligned with the goto labels.
     with body_visitor.writer.indent_block():
       body_visitor._visit_each(node.body)  # pylint: disable=protected-access
@@ -138,10 +144,13 @@ class StatementVisitor(algorithm.Visitor):
                              filename=util.go_str(self.block.root.filename),
                              cls=cls.expr)
       with self.writer.indent_block():
+        if block_visitor.uses_locals:
+          self.writer.write(
+              'πF.SetLocals(func() *πg.Dict { return πClass })')
         self.writer.write_temp_decls(body_visitor.block)
         self.writer.write_block(body_visitor.block,
                                 body_visitor.writer.getvalue())
-        self.writer.write('return nil, nil')
+        self.writer.write('return nil, πE')
       tmpl = textwrap.dedent("""\
           }).Eval(πF, πF.Globals(), nil, nil)
           if πE != nil {
@@ -149,7 +158,17 @@ class StatementVisitor(algorithm.Visitor):
           }
           if $meta, πE = $cls.GetItem(πF, $metaclass_str.ToObject()); πE != nil {
           \tcontinue
-          }
+          }""")
+      if not node.bases:
+        # Without bases, fall back to the module level __metaclass__. When
+        # there are bases, type.__new__ picks the most derived metaclass.
+        tmpl += textwrap.dedent("""
+            if $meta == nil {
+            \tif $meta, πE = πF.Globals().GetItem(πF, $metaclass_str.ToObject()); πE != nil {
+            \t\tcontinue
+            \t}
+            }""")
+      tmpl += textwrap.dedent("""
           if $meta == nil {
           \t$meta = πg.TypeType.ToObject()
           }""")
@@ -157,11 +176,11 @@ class StatementVisitor(algorithm.Visitor):
           tmpl, meta=meta.name, cls=cls.expr,
           metaclass_str=self.block.root.intern('__metaclass__'))
       with self.block.alloc_temp() as type_:
-        type_expr = ('{}.Call(πF, []*πg.Object{{πg.NewStr({}).ToObject(), '
+        type_expr = ('{}.Call(πF, []*πg.Object{{{}.ToObject(), '
                      'πg.NewTuple({}...).ToObject(), {}.ToObject()}}, nil)')
         self.writer.write_checked_call2(
             type_, type_expr, meta.expr,
-            util.go_str(node.name), bases.expr, cls.expr)
+            self.block.root.intern(node.name), bases.expr, cls.expr)
         self.block.bind_var(self.writer, node.name, type_.expr)
 
   def visit_Continue(self, node):
@@ -189,9 +208,19 @@ class StatementVisitor(algorithm.Visitor):
         msg = 'del target not implemented: {}'.format(type(target).__name__)
         raise util.ParseError(node, msg)
 
+  def visit_Exec(self, node):
+    self._write_py_context(node.lineno)
+    with self.visit_expr(node.body) as body,\
+        self.visit_expr(node.globals) if node.globals else _nil_expr as g,\
+        self.visit_expr(node.locals) if node.locals else _nil_expr as l:
+      self.writer.write_checked_call1(
+          'πg.Exec(πF, {}, {}, {})', body.expr, g.expr, l.expr)
+
   def visit_Expr(self, node):
     self._write_py_context(node.lineno)
-    self.visit_expr(node.value).free()
+    # Like CPython, discard constant statements such as docstrings.
+    if not isinstance(node.value, (ast.Num, ast.Str)):
+      self.visit_expr(node.value).free()
 
   def visit_For(self, node):
     with self.block.alloc_temp() as i:
@@ -218,15 +247,19 @@ class StatementVisitor(algorithm.Visitor):
 
   def visit_FunctionDef(self, node):
     self._write_py_context(node.lineno + len(node.decorator_list))
+    # Decorator expressions are evaluated before the function is bound so that
+    # e.g. "@x.setter" refers to the existing value of x.
+    decorators = [self.visit_expr(d) for d in node.decorator_list]
     func = self.visit_function_inline(node)
-    self.block.bind_var(self.writer, node.name, func.expr)
-    while node.decorator_list:
-      decorator = node.decorator_list.pop()
-      wrapped = ast.Name(id=node.name)
-      decorated = ast.Call(func=decorator, args=[wrapped], keywords=[],
-                           starargs=None, kwargs=None)
-      target = ast.Assign(targets=[wrapped], value=decorated, loc=node.loc)
-      self.visit_Assign(target)
+    for decorator in reversed(decorators):
+      with decorator, func:
+        decorated = self.block.alloc_temp()
+        self.writer.write_checked_call2(
+            decorated, '{}.Call(πF, πg.Args{{{}}}, nil)', decorator.expr,
+            func.expr)
+      func = decorated
+    with func:
+      self.block.bind_var(self.writer, node.name, func.expr)
 
   def visit_Global(self, node):
     self._write_py_context(node.lineno)
@@ -287,7 +320,15 @@ class StatementVisitor(algorithm.Visitor):
       self._import_and_bind(imp)
 
   def visit_Module(self, node):
-    self._visit_each(node.body)
+    body = node.body
+    if body and isinstance(body[0], ast.Expr) and isinstance(body[0].value,
+                                                             ast.Str):
+      # Bind the leading docstring to __doc__ rather than discarding it.
+      self._write_py_context(body[0].lineno)
+      with self.visit_expr(body[0].value) as doc:
+        self.block.bind_var(self.writer, '__doc__', doc.expr)
+      body = body[1:]
+    self._visit_each(body)
 
   def visit_Pass(self, node):
     self._write_py_context(node.lineno)
@@ -302,8 +343,13 @@ class StatementVisitor(algorithm.Visitor):
       for i, v in enumerate(node.values):
         with self.visit_expr(v) as arg:
           self.writer.write('{}[{}] = {}'.format(args.expr, i, arg.expr))
-      self.writer.write_checked_call1('πg.Print(πF, {}, {})', args.expr,
-                                      'true' if node.nl else 'false')
+      nl = 'true' if node.nl else 'false'
+      if node.dest:
+        with self.visit_expr(node.dest) as dest:
+          self.writer.write_checked_call1('πg.PrintTo(πF, {}, {}, {})',
+                                          dest.expr, args.expr, nl)
+      else:
+        self.writer.write_checked_call1('πg.Print(πF, {}, {})', args.expr, nl)
 
   def visit_Raise(self, node):
     with self.visit_expr(node.exc) if node.exc else _nil_expr as t,\
@@ -397,8 +443,15 @@ class StatementVisitor(algorithm.Visitor):
       self.writer.write_label(finally_label)
       if node.finalbody:
         with self.block.alloc_temp('*πg.Traceback') as tb:
-          self.writer.write('{}, {} = πF.RestoreExc(nil, nil)'.format(
-              exc.expr, tb.expr))
+          # Only take the exception when one is propagating. Otherwise the
+          # exception being handled belongs to an enclosing except clause,
+          # possibly in a calling function, and must not be re-raised.
+          self.writer.write_tmpl(textwrap.dedent("""\
+              if πE != nil {
+              \t$exc, $tb = πF.RestoreExc(nil, nil)
+              } else {
+              \t$exc, $tb = nil, nil
+              }"""), exc=exc.expr, tb=tb.expr)
           self._visit_each(node.finalbody)
           self.writer.write_tmpl(textwrap.dedent("""\
               if $exc != nil {
@@ -503,6 +556,8 @@ class StatementVisitor(algorithm.Visitor):
       func_visitor.visit(child)
     func_block = block.FunctionBlock(self.block, node.name, func_visitor.vars,
                                      func_visitor.is_generator)
+    if not func_visitor.uses_locals:
+      func_block.traced_locals = self._locals_dict_expr(func_block)
     visitor = StatementVisitor(func_block, self.future_node)
     # Indent so that the function body is aligned with the goto labels.
     with visitor.writer.indent_block():
@@ -542,6 +597,8 @@ class StatementVisitor(algorithm.Visitor):
             fmt = 'var {0} *πg.Object = {1}; _ = {0}'
             self.writer.write(fmt.format(
                 util.adjust_local_name(var.name), var.init_expr))
+        if func_visitor.uses_locals:
+          self._write_locals_func(func_block)
         self.writer.write_temp_decls(func_block)
         self.writer.write('var πR *πg.Object; _ = πR')
         self.writer.write('var πE *πg.BaseException; _ = πE')
@@ -562,9 +619,42 @@ class StatementVisitor(algorithm.Visitor):
               \tπR = πg.None
               }
               return πR, πE"""))
-      self.writer.write('}), πF.Globals()).ToObject()')
+      code_info = ''
+      if args.vararg or args.kwarg:
+        code_info += '.WithArgNames({}, {})'.format(
+            util.go_str(args.vararg.arg if args.vararg else ''),
+            util.go_str(args.kwarg.arg if args.kwarg else ''))
+      local_names = [v.name for v in func_block.vars.values()
+                     if v.type == block.Var.TYPE_LOCAL]
+      if local_names:
+        code_info += '.WithLocalNames({})'.format(
+            ', '.join(util.go_str(n) for n in local_names))
+      if getattr(node, 'loc', None):
+        code_info += '.WithLineno({})'.format(node.lineno)
+      self.writer.write('}}){}, πF.Globals()).ToObject()'.format(code_info))
+    if (node.body and isinstance(node.body[0], ast.Expr) and
+        isinstance(node.body[0].value, ast.Str)):
+      with self.visit_expr(node.body[0].value) as doc:
+        self.writer.write_checked_call1(
+            'πg.SetAttr(πF, {}, {}, {})', result.expr,
+            self.block.root.intern('__doc__'), doc.expr)
     return result
 
+  def _locals_dict_expr(self, func_block):
+    """Returns a Go expression for a *Dict snapshot of func_block's vars."""
+    names = [v.name for v in func_block.vars.values()
+             if v.type != block.Var.TYPE_GLOBAL]
+    return 'πg.NewLocalsDict([]string{{{}}}, []*πg.Object{{{}}})'.format(
+        ', '.join(util.go_str(n) for n in names),
+        ', '.join(util.adjust_local_name(n) for n in names))
+
+  def _write_locals_func(self, func_block):
+    """Writes code that lets the locals() builtin snapshot func_block's vars."""
+    self.writer.write_tmpl(textwrap.dedent("""\
+        πF.SetLocals(func() *πg.Dict {
+        \treturn $locals
+        })"""), locals=self._locals_dict_expr(func_block))
+
   _AUG_ASSIGN_TEMPLATES = {
       ast.Add: 'πg.IAdd(πF, {lhs}, {rhs})',
       ast.BitAnd: 'πg.IAnd(πF, {lhs}, {rhs})',
@@ -746,4 +836,11 @@ class StatementVisitor(algorithm.Visitor):
     if lineno:
       line = self.block.root.buffer.source_line(lineno).strip()
       self.writer.write('// line {}: {}'.format(lineno, line))
-      self.writer.write('πF.SetLineno({})'.format(lineno))
+      if self.block.traced_locals:
+        # Let debuggers see the locals without capturing them in a closure,
+        # which would move them to the heap on every call.
+        self.writer.write_tmpl(textwrap.dedent("""\
+            if πF.Traced() {
+            \tπF.SetTracedLocals($locals)
+            }"""), locals=self.block.traced_locals)
+      self.writer.write_checked_call1('πF.SetLineno({})', lineno)
@@ -49,6 +49,21 @@ class StatementVisitorTest(unittest.TestCase):
         except AssertionError as e:
           print repr(e)""")))
 
+  def testModuleDocstring(self):
+    want = (0, "'foo bar'\n")
+    self.assertEqual(want, _GrumpRun(textwrap.dedent("""\
+        'foo bar'
+        print repr(__doc__)""")))
+
+  def testModuleNoDocstring(self):
+    self.assertEqual((0, 'None\n'), _GrumpRun('print __doc__'))
+
+  def testAssertDebug(self):
+    want = (0, 'True\n')
+    self.assertEqual(want, _GrumpRun(textwrap.dedent("""\
+        assert __debug__
+        print __debug__""")))
+
   def testBareAssert(self):
     # Assertion errors at the top level of a block should raise:
     # https://github.com/google/grumpy/issues/18
@@ -121,6 +136,40 @@ class StatementVisitorTest(unittest.TestCase):
           bar = 'abc'
         print Foo.bar""")))
 
+  def testClassDefLocals(self):
+    self.assertEqual((0, "['__module__', 'bar']\n"), _GrumpRun(textwrap.dedent("""\
+        class Foo(object):
+          bar = 1
+          print sorted(locals())""")))
+
+  def testClassDefMetaclass(self):
+    self.assertEqual((0, "Meta Meta ['Foo', 'Bar']\n"), _GrumpRun(textwrap.dedent("""\
+        names = []
+        class Meta(type):
+          def __init__(cls, name, bases, dict):
+            super(Meta, cls).__init__(name, bases, dict)
+            names.append(name)
+        class Foo(object):
+          __metaclass__ = Meta
+        class Bar(Foo):
+          pass
+        print type(Foo).__name__, type(Bar).__name__, names""")))
+
+  def testClassDefModuleMetaclass(self):
+    self.assertEqual((0, "<type 'type'> True\n"), _GrumpRun(textwrap.dedent("""\
+        __metaclass__ = type
+        class Foo:
+          pass
+        print type(Foo), isinstance(Foo(), object)""")))
+
+  def testClassDefRaises(self):
+    self.assertEqual((0, "ValueError('foo',) False\n"), _GrumpRun(textwrap.dedent("""\
+        try:
+          class Foo(object):
+            raise ValueError('foo')
+        except ValueError as e:
+          print repr(e), 'Foo' in globals()""")))
+
   def testDeleteAttribute(self):
     self.assertEqual((0, 'False\n'), _GrumpRun(textwrap.dedent("""\
         class Foo(object):
@@ -164,6 +213,34 @@ class StatementVisitorTest(unittest.TestCase):
         del foo['bar']
         print foo""")))
 
+  def testExec(self):
+    self.assertEqual((0, '3\n1 2\n'), _GrumpRun(textwrap.dedent("""\
+        import sys
+        x = 1
+        exec 'y = x + 2'
+        print y
+        g, l = {'x': 1}, {}
+        exec 'import sys\\nprint x,\\ny = 2' in g, l
+        exec('print y', g, l)
+        assert 'y' not in g""")))
+
+  def testExecFunctionLocals(self):
+    self.assertEqual((0, '3 5\n6\n'), _GrumpRun(textwrap.dedent("""\
+        g = 5
+        def foo(a, b):
+          q = a + b
+          exec 'print q, g'
+          return eval('q * 2')
+        print foo(1, 2)""")))
+
+  def testExecSyntaxError(self):
+    self.assertEqual((0, 'invalid syntax (<string>, line 1)\n'), _GrumpRun(
+        textwrap.dedent("""\
+            try:
+              exec 'foo bar'
+            except SyntaxError as e:
+              print e""")))
+
   def testExprCall(self):
     self.assertEqual((0, 'bar\n'), _GrumpRun(textwrap.dedent("""\
         def foo():
@@ -237,12 +314,51 @@ class StatementVisitorTest(unittest.TestCase):
           return 'foo'
         print foo()""")))
 
+  def testFunctionDecoratorEvaluatedFirst(self):
+    self.assertEqual((0, '3 3\n'), _GrumpRun(textwrap.dedent("""\
+        class Foo(object):
+          @property
+          def x(self):
+            return self._x
+          @x.setter
+          def x(self, value):
+            self._x = value
+        foo = Foo()
+        foo.x = 3
+        print foo.x, foo.__dict__['_x']""")))
+
   def testFunctionDef(self):
     self.assertEqual((0, 'bar baz\n'), _GrumpRun(textwrap.dedent("""\
         def foo(a, b):
           print a, b
         foo('bar', 'baz')""")))
 
+  def testFunctionDefCodeInfo(self):
+    want = ("('a', 'b', 'c', 'd', 'q', 'f', 'i', 'w') (1,) 3\n"
+            "('x',) None 11\n")
+    self.assertEqual((0, want), _GrumpRun(textwrap.dedent("""\
+        def deco(fn):
+          return fn
+        @deco
+        def foo(a, b=1, *c, **d):
+          global g
+          q = g = a
+          def f(): pass
+          for i, w in d: pass
+        c = foo.func_code
+        print c.co_varnames, foo.func_defaults, c.co_firstlineno
+        f = lambda x: x
+        print f.func_code.co_varnames, f.__defaults__, f.func_code.co_firstlineno""")))
+
+  def testFunctionDefDocstring(self):
+    self.assertEqual((0, "'foo' None\n"), _GrumpRun(textwrap.dedent("""\
+        def foo():
+          'foo'
+        def bar():
+          pass
+          'bar'
+        print repr(foo.__doc__), bar.__doc__""")))
+
   def testFunctionDefGenerator(self):
     self.assertEqual((0, "['foo', 'bar']\n"), _GrumpRun(textwrap.dedent("""\
         def gen():
@@ -263,6 +379,30 @@ class StatementVisitorTest(unittest.TestCase):
           bar()
         foo()""")))
 
+  def testFunctionDefLocals(self):
+    self.assertEqual((0, "[('a', 1), ('c', 3)]\n['a', 'b', 'bar', 'c']\n"),
+                     _GrumpRun(textwrap.dedent("""\
+        def foo(a, b=2):
+          global g
+          del b
+          c = g = 3
+          print sorted(locals().items())
+          def bar():
+            return vars()
+          b = 4
+          print sorted(vars())
+        foo(1)""")))
+
+  def testFunctionDefLocalsGenerator(self):
+    self.assertEqual((0, "[('a', 1)]\n[('a', 1), ('b', 2)]\n"),
+                     _GrumpRun(textwrap.dedent("""\
+        def gen(a):
+          yield locals()
+          b = 2
+          yield locals()
+        for l in gen(1):
+          print sorted(l.items())""")))
+
   def testIf(self):
     self.assertEqual((0, 'foo\n'), _GrumpRun(textwrap.dedent("""\
         if 123:
@@ -346,6 +486,27 @@ class StatementVisitorTest(unittest.TestCase):
         print '123'
         print 'foo', 'bar'""")))
 
+  def testPrintStatementDest(self):
+    want = "'foo'' ''1''\\n''\\n'\n"
+    self.assertEqual((0, want), _GrumpRun(textwrap.dedent("""\
+        import sys
+        class Writer(object):
+          def write(self, s):
+            sys.stdout.write(repr(s))
+        w = Writer()
+        print >>w, 'foo',
+        print >>w, 1
+        print >>w
+        print""")))
+
+  def testPrintStatementSoftspace(self):
+    self.assertEqual((0, 'a\nb\nc\td 1\n'), _GrumpRun(textwrap.dedent("""\
+        print 'a\\n',
+        print 'b'
+        print 'c\\t',
+        print 'd',
+        print >>None, 1,""")))
+
   def testPrintFunction(self):
     want = "abc\n123\nabc 123\nabcx123\nabc 123 "
     self.assertEqual((0, want), _GrumpRun(textwrap.dedent("""\
@@ -448,6 +609,21 @@ class StatementVisitorTest(unittest.TestCase):
     self.assertIn('foo bar\nfoo bar\n', result[1])
     self.assertIn('Exception\n', result[1])
 
+  def testTryFinallyLoopExitFromExcept(self):
+    self.assertEqual((0, 'finally 0\nfinally 1\nfinally 2\ndone\n'),
+                     _GrumpRun(textwrap.dedent("""\
+        for i in range(3):
+          try:
+            try:
+              raise ValueError
+            except ValueError:
+              if i < 2:
+                continue
+              break
+          finally:
+            print 'finally', i
+        print 'done'""")))
+
   def testWhile(self):
     self.assertEqual((0, '2\n1\n'), _GrumpRun(textwrap.dedent("""\
         i = 2
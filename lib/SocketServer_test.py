@@ -0,0 +1,133 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import socket
+import SocketServer
+import threading
+
+import weetest
+
+
+class _UpperHandler(SocketServer.StreamRequestHandler):
+
+  def handle(self):
+    for line in self.rfile:
+      self.wfile.write(line.upper())
+
+
+class _DatagramHandler(SocketServer.BaseRequestHandler):
+
+  def handle(self):
+    data, sock = self.request
+    sock.sendto(data[::-1], self.client_address)
+
+
+def _StartServer(server):
+  t = threading.Thread(target=server.serve_forever, args=(0.01,))
+  t.start()
+  return t
+
+
+def _StopServer(server, t):
+  server.shutdown()
+  t.join()
+  server.server_close()
+
+
+def _RoundTrip(addr, data):
+  client = socket.create_connection(addr)
+  try:
+    client.sendall(data)
+    client.shutdown(socket.SHUT_WR)
+    f = client.makefile('rb')
+    return f.read()
+  finally:
+    client.close()
+
+
+def TestTCPServer():
+  server = SocketServer.TCPServer(('127.0.0.1', 0), _UpperHandler)
+  assert server.server_address[1] > 0
+  t = _StartServer(server)
+  try:
+    assert _RoundTrip(server.server_address, 'foo\nbar\n') == 'FOO\nBAR\n'
+    assert _RoundTrip(server.server_address, 'baz') == 'BAZ'
+  finally:
+    _StopServer(server, t)
+
+
+def TestThreadingTCPServer():
+  server = SocketServer.ThreadingTCPServer(('127.0.0.1', 0), _UpperHandler)
+  server.daemon_threads = True
+  t = _StartServer(server)
+  try:
+    # Hold one connection open while another is served.
+    idle = socket.create_connection(server.server_address)
+    try:
+      assert _RoundTrip(server.server_address, 'foo') == 'FOO'
+    finally:
+      idle.close()
+  finally:
+    _StopServer(server, t)
+
+
+def TestUDPServer():
+  server = SocketServer.UDPServer(('127.0.0.1', 0), _DatagramHandler)
+  t = _StartServer(server)
+  try:
+    client = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+    client.sendto('abc', server.server_address)
+    assert client.recvfrom(1024)[0] == 'cba'
+    client.close()
+  finally:
+    _StopServer(server, t)
+
+
+def TestHandleRequestTimeout():
+  timeouts = []
+  class Server(SocketServer.TCPServer):
+    timeout = 0.01
+    def handle_timeout(self):
+      timeouts.append(True)
+  server = Server(('127.0.0.1', 0), _UpperHandler)
+  try:
+    server.handle_request()
+  finally:
+    server.server_close()
+  assert timeouts == [True]
+
+
+def TestHandlerDispatch():
+  requests = []
+  class Handler(SocketServer.BaseRequestHandler):
+    def setup(self):
+      requests.append('setup')
+    def handle(self):
+      requests.append(self.request.recv(1024))
+    def finish(self):
+      requests.append('finish')
+  server = SocketServer.TCPServer(('127.0.0.1', 0), Handler)
+  try:
+    client = socket.create_connection(server.server_address)
+    client.sendall('foo')
+    server.handle_request()
+    client.close()
+  finally:
+    server.server_close()
+  assert requests == ['setup', 'foo', 'finish'], requests
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,29 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""Operator functions implemented natively in Go."""
+
+from '__go__/grumpy' import (AttrGetterType, ItemGetterType,  # pylint: disable=g-multiple-import
+                             MethodCallerType, OperatorFuncs)
+
+attrgetter = AttrGetterType
+itemgetter = ItemGetterType
+methodcaller = MethodCallerType
+
+g = globals()
+for k, v in OperatorFuncs.iteritems():
+  g[k] = v
+
+__all__ = ['attrgetter', 'itemgetter', 'methodcaller'] + sorted(OperatorFuncs)
@@ -0,0 +1,165 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Weak references and proxies, the primitives of the weakref module.
+
+ref is implemented natively in Go. A referent's weakrefs are cleared and their
+callbacks are called when the Go garbage collector finalizes it, which happens
+some time after the last strong reference to it goes away rather than
+immediately as in CPython.
+"""
+
+import operator
+
+from '__go__/grumpy' import WeakRefType as ReferenceType
+
+
+ref = ReferenceType
+
+
+def _deref(p):
+  o = object.__getattribute__(p, '_ref')()
+  if o is None:
+    raise ReferenceError('weakly-referenced object no longer exists')
+  return o
+
+
+def _unwrap(o):
+  if isinstance(o, ProxyTypes):
+    return _deref(o)
+  return o
+
+
+def _binary(op):
+  def Method(self, other):
+    return op(_deref(self), _unwrap(other))
+  return Method
+
+
+def _reflected(op):
+  def Method(self, other):
+    return op(_unwrap(other), _deref(self))
+  return Method
+
+
+def _unary(op):
+  def Method(self):
+    return op(_deref(self))
+  return Method
+
+
+def _proxy_getattribute(self, name):
+  return getattr(_deref(self), name)
+
+
+def _proxy_setattr(self, name, value):
+  setattr(_deref(self), name, value)
+
+
+def _proxy_delattr(self, name):
+  delattr(_deref(self), name)
+
+
+def _proxy_repr(self):
+  o = object.__getattribute__(self, '_ref')()
+  if o is None:
+    return '<weakproxy at %s; dead>' % hex(id(self))
+  return '<weakproxy at %s; to %r at %s>' % (
+      hex(id(self)), type(o).__name__, hex(id(o)))
+
+
+def _proxy_hash(self):
+  raise TypeError('unhashable type: %r' % type(self).__name__)
+
+
+def _proxy_call(self, *args, **kwargs):
+  return _deref(self)(*args, **kwargs)
+
+
+def _proxy_next(self):
+  return next(_deref(self))
+
+
+def _proxy_dict():
+  d = {
+      '__delattr__': _proxy_delattr,
+      '__getattribute__': _proxy_getattribute,
+      '__hash__': _proxy_hash,
+      '__repr__': _proxy_repr,
+      '__setattr__': _proxy_setattr,
+      'next': _proxy_next,
+  }
+  for name, op in [('add', operator.add), ('and', operator.and_),
+                   ('div', operator.div), ('floordiv', operator.floordiv),
+                   ('lshift', operator.lshift), ('mod', operator.mod),
+                   ('mul', operator.mul), ('or', operator.or_),
+                   ('pow', operator.pow), ('rshift', operator.rshift),
+                   ('sub', operator.sub), ('truediv', operator.truediv),
+                   ('xor', operator.xor)]:
+    d['__%s__' % name] = _binary(op)
+    d['__r%s__' % name] = _reflected(op)
+  for name, op in [('iadd', operator.iadd), ('iand', operator.iand),
+                   ('idiv', operator.idiv), ('ifloordiv', operator.ifloordiv),
+                   ('ilshift', operator.ilshift), ('imod', operator.imod),
+                   ('imul', operator.imul), ('ior', operator.ior),
+                   ('ipow', operator.ipow), ('irshift', operator.irshift),
+                   ('isub', operator.isub), ('itruediv', operator.itruediv),
+                   ('ixor', operator.ixor), ('eq', operator.eq),
+                   ('ne', operator.ne), ('lt', operator.lt),
+                   ('le', operator.le), ('gt', operator.gt),
+                   ('ge', operator.ge), ('contains', operator.contains),
+                   ('getitem', operator.getitem),
+                   ('delitem', operator.delitem)]:
+    d['__%s__' % name] = _binary(op)
+  for name, op in [('abs', abs), ('float', float), ('index', operator.index),
+                   ('int', int), ('invert', operator.invert), ('iter', iter),
+                   ('len', len), ('long', long), ('neg', operator.neg),
+                   ('nonzero', bool), ('pos', operator.pos), ('str', str),
+                   ('unicode', unicode)]:
+    d['__%s__' % name] = _unary(op)
+  d['__setitem__'] = lambda self, key, value: operator.setitem(
+      _deref(self), _unwrap(key), value)
+  return d
+
+
+ProxyType = type('weakproxy', (object,), _proxy_dict())
+CallableProxyType = type('weakcallableproxy', (ProxyType,),
+                         {'__call__': _proxy_call})
+ProxyTypes = (ProxyType, CallableProxyType)
+
+
+def proxy(obj, callback=None):
+  """Returns a proxy to obj that uses a weak reference.
+
+  Args:
+    obj: The referent. Attribute access and operators on the proxy are
+        forwarded to it while it is alive and raise ReferenceError after.
+    callback: If given, called with the proxy when obj is finalized, provided
+        the proxy is still alive.
+
+  Returns:
+    A CallableProxyType if obj is callable and a ProxyType otherwise.
+  """
+  t = CallableProxyType if callable(obj) else ProxyType
+  p = object.__new__(t)
+  if callback is None:
+    wr = ref(obj)
+  else:
+    def Finalize(unused_wr, selfref=ref(p)):
+      p = selfref()
+      if p is not None:
+        callback(p)
+    wr = ref(obj, Finalize)
+  object.__setattr__(p, '_ref', wr)
+  return p
@@ -0,0 +1,270 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Abstract Syntax Trees, built on the parser used by the Grumpy compiler.
+
+Nodes come from pythonparser and so follow its normalized format, which
+differs from CPython 2.7 in a few places: try statements produce Try rather
+than TryExcept/TryFinally, With holds a list of withitem nodes and the vararg
+and kwarg slots of arguments hold arg nodes.
+"""
+
+import pythonparser
+from pythonparser import ast as _ast
+from pythonparser import diagnostic
+from pythonparser import source as pythonparser_source
+
+AST = _ast.AST
+
+# Export every node class defined by the parser, e.g. Module, Name and BinOp.
+for _name in dir(_ast):
+  _obj = getattr(_ast, _name)
+  if isinstance(_obj, type) and issubclass(_obj, AST):
+    globals()[_name] = _obj
+del _name, _obj
+
+_MODES = ('exec', 'eval', 'single')
+
+
+class _Engine(diagnostic.Engine):
+  """Raises errors as diagnostic.Error rather than printing them to stderr."""
+
+  def __init__(self):
+    diagnostic.Engine.__init__(self, all_errors_are_fatal=True)
+
+  def render_diagnostic(self, d):
+    pass
+
+
+def parse(source, filename='<unknown>', mode='exec'):
+  """Parse the source into an AST node."""
+  if mode not in _MODES:
+    raise ValueError("compile() arg 3 must be 'exec', 'eval' or 'single'")
+  if isinstance(source, str):
+    source = source.decode('utf-8')
+  if mode != 'exec':
+    # The parser expects interactive input to be terminated by a blank line.
+    source = source.rstrip(u'\n') + u'\n\n'
+  elif source[-1:] != u'\n':
+    source += u'\n'
+  buf = pythonparser_source.Buffer(source, filename)
+  # Like CPython, encode byte string literals as UTF-8 rather than honoring
+  # the coding declaration of the source.
+  buf.encoding = 'utf-8'
+  try:
+    tree, _ = pythonparser.parse_buffer(buf, mode, engine=_Engine())
+  except diagnostic.Error as e:
+    d = e.diagnostic
+    exc = SyntaxError(d.message())
+    exc.msg = d.message()
+    exc.filename = filename
+    exc.lineno = d.location.line()
+    exc.offset = d.location.column() + 1
+    exc.text = d.location.source_line()
+    raise exc
+  if mode == 'eval':
+    # pythonparser wraps the expression in a list but CPython does not.
+    tree.body = tree.body[0]
+  return tree
+
+
+def literal_eval(node_or_string):
+  """Safely evaluate a node or string containing a Python literal.
+
+  The string or node provided may only consist of the following Python literal
+  structures: strings, numbers, tuples, lists, dicts, booleans, and None.
+  """
+  safe_names = {'None': None, 'True': True, 'False': False}
+  if isinstance(node_or_string, basestring):
+    node_or_string = parse(node_or_string, mode='eval')
+  if isinstance(node_or_string, _ast.Expression):
+    node_or_string = node_or_string.body
+  def _convert(node):
+    if isinstance(node, _ast.Str):
+      return node.s
+    elif isinstance(node, _ast.Num):
+      return node.n
+    elif isinstance(node, _ast.Tuple):
+      return tuple(_convert(x) for x in node.elts)
+    elif isinstance(node, _ast.List):
+      return [_convert(x) for x in node.elts]
+    elif isinstance(node, _ast.Dict):
+      return dict((_convert(k), _convert(v))
+                  for k, v in zip(node.keys, node.values))
+    elif isinstance(node, _ast.Name):
+      if node.id in safe_names:
+        return safe_names[node.id]
+    elif (isinstance(node, _ast.UnaryOp) and
+          isinstance(node.op, (_ast.UAdd, _ast.USub)) and
+          isinstance(node.operand, _ast.Num)):
+      # CPython folds signed literals into Num at parse time, pythonparser
+      # does not.
+      if isinstance(node.op, _ast.USub):
+        return -node.operand.n
+      return node.operand.n
+    elif (isinstance(node, _ast.BinOp) and
+          isinstance(node.op, (_ast.Add, _ast.Sub)) and
+          isinstance(node.right, _ast.Num) and
+          isinstance(node.right.n, complex) and
+          isinstance(node.left, _ast.Num) and
+          isinstance(node.left.n, (int, long, float))):
+      if isinstance(node.op, _ast.Add):
+        return node.left.n + node.right.n
+      return node.left.n - node.right.n
+    raise ValueError('malformed string')
+  return _convert(node_or_string)
+
+
+def dump(node, annotate_fields=True, include_attributes=False):
+  """Return a formatted dump of the tree in node.
+
+  This is mainly useful for debugging purposes. Field names are included
+  unless annotate_fields is False. Line numbers and column offsets are
+  included if include_attributes is True.
+  """
+  def _format(node):
+    if isinstance(node, AST):
+      fields = [(a, _format(b)) for a, b in iter_fields(node)]
+      if annotate_fields:
+        parts = ['%s=%s' % field for field in fields]
+      else:
+        parts = [b for a, b in fields]
+      if include_attributes and hasattr(node, 'loc'):
+        parts.append('lineno=%d' % node.lineno)
+        parts.append('col_offset=%d' % node.col_offset)
+      return '%s(%s)' % (type(node).__name__, ', '.join(parts))
+    elif isinstance(node, list):
+      return '[%s]' % ', '.join(_format(x) for x in node)
+    return repr(node)
+  if not isinstance(node, AST):
+    raise TypeError('expected AST, got %r' % type(node).__name__)
+  return _format(node)
+
+
+def iter_fields(node):
+  """Yield a tuple of (fieldname, value) for each field present on node."""
+  for field in node._fields:  # pylint: disable=protected-access
+    try:
+      yield field, getattr(node, field)
+    except AttributeError:
+      pass
+
+
+def iter_child_nodes(node):
+  """Yield all direct child nodes of node."""
+  for _, field in iter_fields(node):
+    if isinstance(field, AST):
+      yield field
+    elif isinstance(field, list):
+      for item in field:
+        if isinstance(item, AST):
+          yield item
+
+
+def walk(node):
+  """Recursively yield all descendant nodes of node, including node itself.
+
+  The order of the nodes is unspecified.
+  """
+  todo = [node]
+  while todo:
+    node = todo.pop(0)
+    todo.extend(iter_child_nodes(node))
+    yield node
+
+
+def get_docstring(node, clean=True):
+  """Return the docstring of a FunctionDef, ClassDef or Module node or None.
+
+  If clean is true, the docstring's indentation is cleaned up.
+  """
+  if not isinstance(node, (_ast.FunctionDef, _ast.ClassDef, _ast.Module)):
+    raise TypeError("%r can't have docstrings" % type(node).__name__)
+  if (node.body and isinstance(node.body[0], _ast.Expr) and
+      isinstance(node.body[0].value, _ast.Str)):
+    doc = node.body[0].value.s
+    if clean:
+      doc = _cleandoc(doc)
+    return doc
+  return None
+
+
+def _cleandoc(doc):
+  """Strip common leading indentation and surrounding blank lines from doc."""
+  lines = doc.split('\n')
+  margin = None
+  for line in lines[1:]:
+    content = len(line.lstrip())
+    if content:
+      indent = len(line) - content
+      if margin is None or indent < margin:
+        margin = indent
+  lines[0] = lines[0].lstrip()
+  if margin is not None:
+    lines[1:] = [line[margin:] for line in lines[1:]]
+  while lines and not lines[-1]:
+    lines.pop()
+  while lines and not lines[0]:
+    lines.pop(0)
+  return '\n'.join(lines)
+
+
+class NodeVisitor(object):
+  """Walks the tree calling visit_<ClassName> for each node.
+
+  Nodes without a matching visitor method are handled by generic_visit, which
+  visits their children.
+  """
+
+  def visit(self, node):
+    method = getattr(self, 'visit_' + type(node).__name__, self.generic_visit)
+    return method(node)
+
+  def generic_visit(self, node):
+    for _, value in iter_fields(node):
+      if isinstance(value, list):
+        for item in value:
+          if isinstance(item, AST):
+            self.visit(item)
+      elif isinstance(value, AST):
+        self.visit(value)
+
+
+class NodeTransformer(NodeVisitor):
+  """A NodeVisitor that replaces nodes with the return value of visitors.
+
+  Returning None from a visitor removes the node from its parent.
+  """
+
+  def generic_visit(self, node):
+    for field, old_value in iter_fields(node):
+      if isinstance(old_value, list):
+        new_values = []
+        for value in old_value:
+          if isinstance(value, AST):
+            value = self.visit(value)
+            if value is None:
+              continue
+            elif not isinstance(value, AST):
+              new_values.extend(value)
+              continue
+          new_values.append(value)
+        old_value[:] = new_values
+      elif isinstance(old_value, AST):
+        new_node = self.visit(old_value)
+        if new_node is None:
+          delattr(node, field)
+        else:
+          setattr(node, field, new_node)
+    return node
@@ -0,0 +1,121 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import ast
+
+import weetest
+
+
+def TestParse():
+  tree = ast.parse('x = 1 + 2\n')
+  assert isinstance(tree, ast.Module)
+  assign = tree.body[0]
+  assert isinstance(assign, ast.Assign)
+  assert [t.id for t in assign.targets] == ['x']
+  assert isinstance(assign.value, ast.BinOp)
+  assert isinstance(assign.value.op, ast.Add)
+  assert (assign.value.left.n, assign.value.right.n) == (1, 2)
+  assert (assign.lineno, assign.col_offset) == (1, 0)
+  assert (assign.value.lineno, assign.value.col_offset) == (1, 4)
+
+
+def TestParseModes():
+  assert isinstance(ast.parse('foo(bar)', mode='eval'), ast.Expression)
+  assert isinstance(ast.parse('x = 1', mode='single'), ast.Interactive)
+  assert isinstance(ast.parse(u'def f():\n  pass'), ast.Module)
+  try:
+    ast.parse('x', mode='foo')
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('parse() with bad mode did not raise')
+
+
+def TestParseSyntaxError():
+  try:
+    ast.parse('x = (1,\ny = 2\n', filename='foo.py')
+  except SyntaxError as e:
+    assert e.filename == 'foo.py'
+    assert e.lineno == 2
+  else:
+    raise AssertionError('parse() of bad source did not raise')
+
+
+def TestDump():
+  got = ast.dump(ast.parse('foo(1)'))
+  want = "Module(body=[Expr(value=Call(func=Name(id=u'foo', ctx=None), " \
+      "args=[Num(n=1)], keywords=[], starargs=None, kwargs=None))])"
+  assert got == want, '%s != %s' % (got, want)
+  got = ast.dump(ast.parse('x', mode='eval'), annotate_fields=False)
+  assert got == "Expression(Name(u'x', None))", got
+
+
+def TestLiteralEval():
+  cases = [
+      ('1', 1),
+      ('-2.5', -2.5),
+      ('"foo"', 'foo'),
+      ('(1, [2, None], {"a": True})', (1, [2, None], {'a': True})),
+      ('1+2j', 1+2j),
+  ]
+  for source, want in cases:
+    got = ast.literal_eval(source)
+    assert got == want, 'literal_eval(%r) == %r, want %r' % (source, got, want)
+  try:
+    ast.literal_eval('foo()')
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('literal_eval of a call did not raise')
+
+
+def TestWalk():
+  tree = ast.parse('def f(a):\n  return a + g(b)\n')
+  names = sorted(n.id for n in ast.walk(tree) if isinstance(n, ast.Name))
+  assert names == ['a', 'b', 'g'], names
+
+
+def TestGetDocstring():
+  tree = ast.parse('def f():\n  """Foo.\n\n    Bar.\n  """\n')
+  assert ast.get_docstring(tree.body[0]) == 'Foo.\n\nBar.'
+  assert ast.get_docstring(tree) is None
+
+
+def TestNodeVisitor():
+  class Visitor(ast.NodeVisitor):
+    def __init__(self):
+      self.calls = []
+    def visit_Call(self, node):
+      self.calls.append(node.func.id)
+      self.generic_visit(node)
+  v = Visitor()
+  v.visit(ast.parse('foo(bar(1), baz)\nqux()\n'))
+  assert v.calls == ['foo', 'bar', 'qux'], v.calls
+
+
+def TestNodeTransformer():
+  class Transformer(ast.NodeTransformer):
+    def visit_Num(self, node):
+      node.n *= 10
+      return node
+    def visit_Pass(self, node):
+      return None
+  tree = Transformer().visit(ast.parse('x = 1 + 2\npass\n'))
+  assert len(tree.body) == 1
+  assert ast.literal_eval(tree.body[0].value.right) == 20
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
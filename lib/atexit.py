@@ -0,0 +1,66 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Registers functions to be called when the program exits.
+
+The functions are run by sys.exitfunc, which the runtime calls after the main
+module finishes or raises SystemExit, in the reverse order of registration.
+They are not run when the program is terminated by a signal or os._exit().
+"""
+
+import sys
+
+_exithandlers = []
+
+
+def _run_exitfuncs():
+  """Runs the registered exit functions, last registered first.
+
+  An exception raised by a function is reported and the remaining functions
+  still run. The last exception is then re-raised.
+  """
+  exc_info = None
+  while _exithandlers:
+    func, targs, kargs = _exithandlers.pop()
+    try:
+      func(*targs, **kargs)
+    except SystemExit:
+      exc_info = sys.exc_info()
+    except:  # pylint: disable=bare-except
+      import traceback  # pylint: disable=g-import-not-at-top
+      sys.stderr.write('Error in atexit._run_exitfuncs:\n')
+      traceback.print_exc()
+      exc_info = sys.exc_info()
+  if exc_info is not None:
+    raise exc_info[0], exc_info[1], exc_info[2]
+
+
+def register(func, *targs, **kargs):
+  """Registers func to be called with targs and kargs at exit.
+
+  Returns func so that register can be used as a decorator.
+  """
+  _exithandlers.append((func, targs, kargs))
+  return func
+
+
+def unregister(func):
+  """Removes every registration of func."""
+  _exithandlers[:] = [h for h in _exithandlers if h[0] != func]
+
+
+if hasattr(sys, 'exitfunc'):
+  # Keep a function assigned to sys.exitfunc before this module was imported.
+  register(sys.exitfunc)
+sys.exitfunc = _run_exitfuncs
@@ -0,0 +1,86 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import atexit
+import StringIO
+import sys
+
+import weetest
+
+
+def TestExitFunc():
+  assert sys.exitfunc is atexit._run_exitfuncs  # pylint: disable=protected-access
+
+
+def TestRegisterOrder():
+  saved = atexit._exithandlers[:]  # pylint: disable=protected-access
+  del atexit._exithandlers[:]  # pylint: disable=protected-access
+  try:
+    calls = []
+    atexit.register(calls.append, 1)
+    f = atexit.register(lambda x=None: calls.append(x), x=2)
+    assert f(3) is None and calls == [3]
+    del calls[:]
+    atexit._run_exitfuncs()  # pylint: disable=protected-access
+    assert calls == [2, 1]
+    assert not atexit._exithandlers  # pylint: disable=protected-access
+  finally:
+    atexit._exithandlers[:] = saved  # pylint: disable=protected-access
+
+
+def TestUnregister():
+  saved = atexit._exithandlers[:]  # pylint: disable=protected-access
+  del atexit._exithandlers[:]  # pylint: disable=protected-access
+  try:
+    calls = []
+    def Foo():
+      calls.append('foo')
+    atexit.register(Foo)
+    atexit.register(calls.append, 'bar')
+    atexit.register(Foo)
+    atexit.unregister(Foo)
+    atexit._run_exitfuncs()  # pylint: disable=protected-access
+    assert calls == ['bar']
+  finally:
+    atexit._exithandlers[:] = saved  # pylint: disable=protected-access
+
+
+def TestRunExitFuncsRaises():
+  saved = atexit._exithandlers[:]  # pylint: disable=protected-access
+  del atexit._exithandlers[:]  # pylint: disable=protected-access
+  old_stderr = sys.stderr
+  sys.stderr = StringIO.StringIO()
+  try:
+    calls = []
+    def Raise(e):
+      raise e
+    atexit.register(calls.append, 'foo')
+    atexit.register(Raise, ValueError('bar'))
+    atexit.register(Raise, SystemExit(3))
+    try:
+      atexit._run_exitfuncs()  # pylint: disable=protected-access
+    except ValueError as e:
+      assert str(e) == 'bar'
+    else:
+      raise AssertionError
+    assert calls == ['foo']
+    assert 'Error in atexit._run_exitfuncs:' in sys.stderr.getvalue()
+    assert 'ValueError: bar' in sys.stderr.getvalue()
+  finally:
+    sys.stderr = old_stderr
+    atexit._exithandlers[:] = saved  # pylint: disable=protected-access
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,59 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import base64
+
+import weetest
+
+
+def TestB64():
+  assert base64.b64encode('abc') == 'YWJj'
+  assert base64.b64encode('\xfb\xff') == '+/8='
+  assert base64.b64encode('\xfb\xff', '*$') == '*$8='
+  assert base64.b64decode('YWJj') == 'abc'
+  assert base64.b64decode('*$8=', '*$') == '\xfb\xff'
+  assert base64.standard_b64encode('a') == 'YQ=='
+  assert base64.standard_b64decode('YQ==') == 'a'
+
+
+def TestB64DecodeError():
+  try:
+    base64.b64decode('YQ=')
+  except TypeError as e:
+    assert str(e) == 'Incorrect padding'
+  else:
+    raise AssertionError
+
+
+def TestURLSafe():
+  assert base64.urlsafe_b64encode('\xfb\xff') == '-_8='
+  assert base64.urlsafe_b64decode('-_8=') == '\xfb\xff'
+  # The standard alphabet is still accepted.
+  assert base64.urlsafe_b64decode('+/8=') == '\xfb\xff'
+
+
+def TestLegacy():
+  data = 'x' * 100
+  encoded = base64.encodestring(data)
+  assert encoded.count('\n') == 2
+  assert base64.decodestring(encoded) == data
+
+
+def TestB16():
+  assert base64.b16encode('\xab') == 'AB'
+  assert base64.b16decode('AB') == '\xab'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
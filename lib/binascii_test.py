@@ -0,0 +1,65 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import binascii
+
+import weetest
+
+
+def TestHexlify():
+  assert binascii.hexlify('\x00\xffab') == '00ff6162'
+  assert binascii.b2a_hex('') == ''
+  assert binascii.unhexlify('00FF6162') == '\x00\xffab'
+  assert binascii.a2b_hex(binascii.b2a_hex('foo')) == 'foo'
+
+
+def TestUnhexlifyErrors():
+  for s, want in (('abc', 'Odd-length string'),
+                  ('zz', 'Non-hexadecimal digit found')):
+    try:
+      binascii.unhexlify(s)
+    except TypeError as e:
+      assert str(e) == want, str(e)
+    else:
+      raise AssertionError
+
+
+def TestBase64():
+  assert binascii.b2a_base64('abc') == 'YWJj\n'
+  assert binascii.b2a_base64('\xfb\xff') == '+/8=\n'
+  assert binascii.a2b_base64('YWJj\n') == 'abc'
+  assert binascii.a2b_base64('Y W\nI=') == 'ab'
+  data = ''.join(chr(i) for i in xrange(256))
+  assert binascii.a2b_base64(binascii.b2a_base64(data)) == data
+  try:
+    binascii.a2b_base64('YQ=')
+  except binascii.Error as e:
+    assert str(e) == 'Incorrect padding'
+  else:
+    raise AssertionError
+
+
+def TestCRC32():
+  assert binascii.crc32('hello') == 907060870
+  assert binascii.crc32('llo', binascii.crc32('he')) == 907060870
+  assert binascii.crc32('\xff' * 10) == 266646364
+
+
+def TestQuotedPrintable():
+  assert binascii.b2a_qp('caf\xe9 = 1') == 'caf=E9 =3D 1'
+  assert binascii.a2b_qp('caf=E9 =3D 1') == 'caf\xe9 = 1'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,40 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Python 3 style aliases for the built-in identifiers.
+
+Names that differ between Python 2 and 3 are bound to their lazy Python 2
+equivalents, e.g. range is xrange and map is itertools.imap, so that code
+written against the builtins module of six or python-future runs unchanged.
+"""
+
+# pylint: disable=invalid-name,redefined-builtin
+
+import io
+import itertools
+
+from '__go__/grumpy' import Builtins
+
+
+for k, v in Builtins.iteritems():
+  globals()[k] = v
+
+ascii = repr
+chr = unichr
+filter = itertools.ifilter
+input = raw_input
+map = itertools.imap
+open = io.open
+range = xrange
+zip = itertools.izip
@@ -0,0 +1,51 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import builtins
+import types
+
+import weetest
+
+
+def TestBuiltinsAliases():
+  assert builtins.len is len
+  assert builtins.object is object
+  assert builtins.ascii is repr
+  assert builtins.chr is unichr
+  assert builtins.input is raw_input
+
+
+def TestRangeIsLazy():
+  r = builtins.range(5)
+  assert isinstance(r, xrange)
+  assert list(r) == [0, 1, 2, 3, 4]
+  assert builtins.range(1, 10, 3)[2] == 7
+
+
+def TestMapFilterZipAreLazy():
+  m = builtins.map(lambda x: x * 2, [1, 2])
+  assert not isinstance(m, list)
+  assert list(m) == [2, 4]
+  f = builtins.filter(None, [0, 1, '', 'a'])
+  assert list(f) == [1, 'a']
+  z = builtins.zip('ab', [1, 2, 3])
+  assert list(z) == [('a', 1), ('b', 2)]
+
+
+def TestOpen():
+  assert isinstance(builtins.open, types.FunctionType)
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,22 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Fast pickling. This is an alias of pickle, which is already native."""
+
+# pylint: disable=g-multiple-import,unused-import
+from pickle import (HIGHEST_PROTOCOL, PickleError, Pickler, PicklingError,
+                    Unpickler, UnpicklingError, compatible_formats, dump,
+                    dumps, format_version, load, loads)
+
+BadPickleGet = UnpicklingError
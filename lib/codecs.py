@@ -0,0 +1,75 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Codec registry and base classes (partial)."""
+
+from '__go__/grumpy' import (CodecsDecode as _CodecsDecode,  # pylint: disable=g-multiple-import
+                             CodecsEncode as _CodecsEncode,
+                             CodecsLookup as _CodecsLookup,
+                             CodecsRegister as _CodecsRegister)
+
+BOM_UTF8 = '\xef\xbb\xbf'
+BOM_LE = BOM_UTF16_LE = '\xff\xfe'
+BOM_BE = BOM_UTF16_BE = '\xfe\xff'
+BOM = BOM_UTF16 = BOM_LE
+
+
+class CodecInfo(tuple):
+  """Codec details returned by lookup()."""
+
+  def __new__(cls, encode, decode, streamreader=None, streamwriter=None,
+              incrementalencoder=None, incrementaldecoder=None, name=None):
+    self = tuple.__new__(cls, (encode, decode, streamreader, streamwriter))
+    self.name = name
+    self.encode = encode
+    self.decode = decode
+    self.incrementalencoder = incrementalencoder
+    self.incrementaldecoder = incrementaldecoder
+    self.streamwriter = streamwriter
+    self.streamreader = streamreader
+    return self
+
+  def __repr__(self):
+    return '<%s.%s object for encoding %s at 0x%x>' % (
+        self.__class__.__module__, self.__class__.__name__, self.name, id(self))
+
+
+def register(search_function):
+  _CodecsRegister(__frame__(), search_function)  # pylint: disable=undefined-variable
+
+
+def lookup(encoding):
+  info = _CodecsLookup(__frame__(), encoding)  # pylint: disable=undefined-variable
+  if isinstance(info, CodecInfo):
+    return info
+  # Codecs built into the runtime are described by plain 4-tuples whose encode
+  # function is named after the codec, e.g. utf_8_encode.
+  name = info[0].__name__[:-len('_encode')].replace('_', '-')
+  return CodecInfo(*info, name=name)
+
+
+def encode(obj, encoding='utf8', errors='strict'):
+  return _CodecsEncode(__frame__(), obj, encoding, errors)  # pylint: disable=undefined-variable
+
+
+def decode(obj, encoding='utf8', errors='strict'):
+  return _CodecsDecode(__frame__(), obj, encoding, errors)  # pylint: disable=undefined-variable
+
+
+def getencoder(encoding):
+  return lookup(encoding).encode
+
+
+def getdecoder(encoding):
+  return lookup(encoding).decode
@@ -0,0 +1,90 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import codecs
+
+import weetest
+
+
+def _Rot13(s, errors='strict'):
+  return ''.join(chr((ord(c) - 97 + 13) % 26 + 97) for c in s), len(s)
+
+
+def _Search(name):
+  if name == 'codecs-test-rot13':
+    return codecs.CodecInfo(_Rot13, _Rot13, name=name)
+  return None
+
+
+codecs.register(_Search)
+
+
+def TestLookupBuiltin():
+  info = codecs.lookup('UTF8')
+  assert isinstance(info, codecs.CodecInfo)
+  assert info.name == 'utf-8'
+  assert info.encode(u'caf\xe9') == ('caf\xc3\xa9', 4)
+  assert info.decode('caf\xc3\xa9') == (u'caf\xe9', 5)
+  assert codecs.lookup('iso-8859-1').name == 'latin-1'
+
+
+def TestLookupRegistered():
+  info = codecs.lookup('Codecs Test Rot13')
+  assert info.name == 'codecs-test-rot13'
+  assert codecs.encode('abc', 'codecs-test-rot13') == 'nop'
+  assert 'nop'.decode('codecs-test-rot13') == 'abc'
+
+
+def TestLookupUnknown():
+  try:
+    codecs.lookup('codecs-test-noexist')
+  except LookupError as e:
+    assert str(e) == 'unknown encoding: codecs-test-noexist'
+  else:
+    raise AssertionError('LookupError not raised')
+
+
+def TestRegisterNotCallable():
+  try:
+    codecs.register(42)
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('TypeError not raised')
+
+
+def TestStrDecode():
+  assert 'caf\xe9'.decode('latin-1') == u'caf\xe9'
+  assert '\xff\xfeh\x00i\x00'.decode('utf-16') == u'hi'
+  assert '666f6f'.decode('hex') == 'foo'
+  assert 'Zm9v\n'.decode('base64') == 'foo'
+  assert 'caf\xe9'.decode('ascii', 'replace') == u'caf\ufffd'
+
+
+def TestUnicodeEncode():
+  assert u'caf\xe9'.encode('latin-1') == 'caf\xe9'
+  assert u'caf\xe9'.encode('ascii', 'replace') == 'caf?'
+  assert u'hi'.encode('utf-16-be') == '\x00h\x00i'
+  assert u'foo'.encode('hex') == '666f6f'
+  try:
+    u'caf\xe9'.encode('ascii')
+  except UnicodeEncodeError:
+    pass
+  else:
+    raise AssertionError('UnicodeEncodeError not raised')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,301 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Execute calls asynchronously on goroutines.
+
+This implements the core of the Python 3 concurrent.futures API. Since
+goroutines are cheap, ThreadPoolExecutor starts one per submitted call and
+bounds concurrency with a channel used as a semaphore rather than keeping a
+pool of long lived worker threads.
+"""
+
+from '__go__/grumpy' import StartThread
+from '__go__/reflect' import (
+    BothDir as _BothDir,
+    ChanOf as _ChanOf,
+    MakeChan as _MakeChan,
+    TypeOf as _TypeOf
+)
+from '__go__/runtime' import NumCPU as _NumCPU
+from '__go__/sync' import WaitGroup as _WaitGroup
+import select_
+import thread
+import time
+
+__all__ = ['CancelledError', 'Executor', 'Future', 'ThreadPoolExecutor',
+           'TimeoutError', 'as_completed']
+
+_PENDING = 'PENDING'
+_RUNNING = 'RUNNING'
+_CANCELLED = 'CANCELLED'
+_FINISHED = 'FINISHED'
+
+
+class Error(Exception):
+  pass
+
+
+class CancelledError(Error):
+  pass
+
+
+class TimeoutError(Error):  # pylint: disable=redefined-builtin
+  pass
+
+
+def _make_chan(size=0):
+  return _MakeChan(_ChanOf(_BothDir, _TypeOf(0)), size).Interface()
+
+
+def _deadline(timeout):
+  if timeout is None:
+    return None
+  return time.time() + timeout
+
+
+def _remaining(deadline):
+  if deadline is None:
+    return None
+  return max(deadline - time.time(), 0)
+
+
+class Future(object):
+  """The result of a call that may not have completed yet."""
+
+  def __init__(self):
+    self._lock = thread.allocate_lock()
+    self._state = _PENDING
+    self._result = None
+    self._exception = None
+    self._callbacks = []
+    # Closed once the future is cancelled or finished, waking all waiters.
+    self._done = _make_chan()
+
+  def __repr__(self):
+    with self._lock:
+      if self._state == _FINISHED:
+        if self._exception is not None:
+          return '<Future at %#x state=finished raised %s>' % (
+              id(self), type(self._exception).__name__)
+        return '<Future at %#x state=finished returned %s>' % (
+            id(self), type(self._result).__name__)
+      return '<Future at %#x state=%s>' % (id(self), self._state.lower())
+
+  def cancel(self):
+    """Cancels the call if it has not started, returning True on success."""
+    with self._lock:
+      if self._state in (_RUNNING, _FINISHED):
+        return False
+      if self._state == _CANCELLED:
+        return True
+      self._state = _CANCELLED
+      self._done.close()
+    self._invoke_callbacks()
+    return True
+
+  def cancelled(self):
+    with self._lock:
+      return self._state == _CANCELLED
+
+  def running(self):
+    with self._lock:
+      return self._state == _RUNNING
+
+  def done(self):
+    with self._lock:
+      return self._state in (_CANCELLED, _FINISHED)
+
+  def result(self, timeout=None):
+    """Returns the value of the call, waiting up to timeout seconds for it.
+
+    Raises:
+      CancelledError: The future was cancelled.
+      TimeoutError: The call did not complete before the timeout.
+      Exception: The exception raised by the call, if any.
+    """
+    self._wait(timeout)
+    with self._lock:
+      if self._state == _CANCELLED:
+        raise CancelledError()
+      if self._exception is not None:
+        raise self._exception
+      return self._result
+
+  def exception(self, timeout=None):
+    """Returns the exception raised by the call, or None if it succeeded."""
+    self._wait(timeout)
+    with self._lock:
+      if self._state == _CANCELLED:
+        raise CancelledError()
+      return self._exception
+
+  def add_done_callback(self, fn):
+    """Arranges for fn(future) to be called when the future completes.
+
+    If the future has already completed, fn is called immediately.
+    """
+    with self._lock:
+      if self._state not in (_CANCELLED, _FINISHED):
+        self._callbacks.append(fn)
+        return
+    fn(self)
+
+  def set_running_or_notify_cancel(self):
+    """Marks the future running, returning False if it was cancelled."""
+    with self._lock:
+      if self._state == _CANCELLED:
+        return False
+      if self._state != _PENDING:
+        raise RuntimeError('Future in unexpected state: ' + self._state)
+      self._state = _RUNNING
+      return True
+
+  def set_result(self, result):
+    self._finish(result, None)
+
+  def set_exception(self, exception):
+    self._finish(None, exception)
+
+  def _finish(self, result, exception):
+    with self._lock:
+      self._result = result
+      self._exception = exception
+      self._state = _FINISHED
+      self._done.close()
+    self._invoke_callbacks()
+
+  def _invoke_callbacks(self):
+    with self._lock:
+      callbacks = self._callbacks
+      self._callbacks = []
+    for fn in callbacks:
+      fn(self)
+
+  def _wait(self, timeout):
+    if select_.multiplex([self._done], timeout)[0] < 0:
+      raise TimeoutError()
+
+
+def as_completed(fs, timeout=None):
+  """Yields the futures in fs as they complete.
+
+  Futures that completed before as_completed() was called are yielded first.
+  Duplicate futures are yielded once.
+
+  Raises:
+    TimeoutError: Some futures did not complete within timeout seconds of the
+        call to as_completed().
+  """
+  deadline = _deadline(timeout)
+  unique = []
+  for f in fs:
+    if f not in unique:
+      unique.append(f)
+  completed = _make_chan(len(unique))
+  for i, f in enumerate(unique):
+    f.add_done_callback(lambda _, i=i: completed.send(i))
+  for n in xrange(len(unique)):
+    i, value, _ = select_.multiplex([completed], _remaining(deadline))
+    if i < 0:
+      raise TimeoutError('%d (of %d) futures unfinished' % (
+          len(unique) - n, len(unique)))
+    yield unique[value]
+
+
+class Executor(object):
+  """Abstract base class for objects that execute calls asynchronously."""
+
+  def submit(self, fn, *args, **kwargs):
+    """Schedules fn(*args, **kwargs) and returns a Future for its result."""
+    raise NotImplementedError()
+
+  def map(self, fn, *iterables, **kwargs):
+    """Returns an iterator equivalent to map(fn, *iterables).
+
+    The calls are submitted immediately and run concurrently. The optional
+    timeout keyword argument is the maximum number of seconds to wait for
+    all results, measured from the call to map().
+    """
+    timeout = kwargs.pop('timeout', None)
+    if kwargs:
+      raise TypeError('map() got an unexpected keyword argument %r' %
+                      kwargs.keys()[0])
+    deadline = _deadline(timeout)
+    fs = [self.submit(fn, *args) for args in zip(*iterables)]
+    def results():
+      try:
+        for f in fs:
+          yield f.result(_remaining(deadline))
+      finally:
+        for f in fs:
+          f.cancel()
+    return results()
+
+  def shutdown(self, wait=True):
+    """Frees resources once all pending calls have completed."""
+    pass
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.shutdown(wait=True)
+    return False
+
+
+class ThreadPoolExecutor(Executor):
+  """An Executor that runs calls on at most max_workers goroutines at once."""
+
+  def __init__(self, max_workers=None):
+    if max_workers is None:
+      max_workers = _NumCPU() * 5
+    if max_workers <= 0:
+      raise ValueError('max_workers must be greater than 0')
+    self._max_workers = max_workers
+    self._slots = _make_chan(max_workers)
+    self._pending = _WaitGroup.new()
+    self._lock = thread.allocate_lock()
+    self._shutdown = False
+
+  def submit(self, fn, *args, **kwargs):
+    with self._lock:
+      if self._shutdown:
+        raise RuntimeError('cannot schedule new futures after shutdown')
+      self._pending.Add(1)
+    future = Future()
+    run = lambda: self._run(future, fn, args, kwargs)
+    StartThread(__frame__(), run)  # pylint: disable=undefined-variable
+    return future
+
+  def shutdown(self, wait=True):
+    with self._lock:
+      self._shutdown = True
+    if wait:
+      self._pending.Wait()
+
+  def _run(self, future, fn, args, kwargs):
+    try:
+      self._slots.send(0)
+      try:
+        if future.set_running_or_notify_cancel():
+          try:
+            result = fn(*args, **kwargs)
+          except BaseException as e:  # pylint: disable=broad-except
+            future.set_exception(e)
+          else:
+            future.set_result(result)
+      finally:
+        self._slots.recv()
+    finally:
+      self._pending.Done()
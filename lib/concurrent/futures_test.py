@@ -0,0 +1,172 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from concurrent import futures
+import thread
+import time
+
+import weetest
+
+
+def TestFutureResult():
+  f = futures.Future()
+  assert not f.done()
+  assert f.set_running_or_notify_cancel()
+  assert f.running()
+  f.set_result(42)
+  assert f.done()
+  assert f.result() == 42
+  assert f.exception() is None
+
+
+def TestFutureException():
+  f = futures.Future()
+  e = ValueError('foo')
+  f.set_exception(e)
+  assert f.exception() is e
+  try:
+    f.result()
+  except ValueError as got:
+    assert got is e
+  else:
+    raise AssertionError('result() did not raise')
+
+
+def TestFutureResultTimeout():
+  f = futures.Future()
+  start = time.time()
+  try:
+    f.result(timeout=0.05)
+  except futures.TimeoutError:
+    pass
+  else:
+    raise AssertionError('result() did not time out')
+  assert time.time() - start >= 0.04
+
+
+def TestFutureCancel():
+  f = futures.Future()
+  assert f.cancel()
+  assert f.cancelled()
+  assert f.done()
+  assert not f.set_running_or_notify_cancel()
+  try:
+    f.result()
+  except futures.CancelledError:
+    pass
+  else:
+    raise AssertionError('result() did not raise CancelledError')
+  f = futures.Future()
+  f.set_running_or_notify_cancel()
+  assert not f.cancel()
+
+
+def TestFutureDoneCallback():
+  calls = []
+  f = futures.Future()
+  f.add_done_callback(calls.append)
+  assert not calls
+  f.set_result(None)
+  assert calls == [f]
+  f.add_done_callback(calls.append)
+  assert calls == [f, f]
+
+
+def TestSubmit():
+  with futures.ThreadPoolExecutor(max_workers=2) as executor:
+    f = executor.submit(lambda x, y=1: x * y, 6, y=7)
+    assert f.result() == 42
+
+
+def TestSubmitRaises():
+  def Fail():
+    raise KeyError('bar')
+  with futures.ThreadPoolExecutor(max_workers=1) as executor:
+    f = executor.submit(Fail)
+    assert isinstance(f.exception(), KeyError)
+
+
+def TestSubmitAfterShutdown():
+  executor = futures.ThreadPoolExecutor(max_workers=1)
+  executor.shutdown()
+  try:
+    executor.submit(lambda: None)
+  except RuntimeError:
+    pass
+  else:
+    raise AssertionError('submit() after shutdown did not raise')
+
+
+def TestMaxWorkers():
+  lock = thread.allocate_lock()
+  state = {'running': 0, 'max': 0}
+  def Work():
+    with lock:
+      state['running'] += 1
+      state['max'] = max(state['max'], state['running'])
+    time.sleep(0.01)
+    with lock:
+      state['running'] -= 1
+  with futures.ThreadPoolExecutor(max_workers=3) as executor:
+    for _ in xrange(10):
+      executor.submit(Work)
+  assert state['running'] == 0
+  assert 1 <= state['max'] <= 3
+
+
+def TestInvalidMaxWorkers():
+  try:
+    futures.ThreadPoolExecutor(max_workers=0)
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('max_workers=0 did not raise')
+
+
+def TestMap():
+  with futures.ThreadPoolExecutor(max_workers=4) as executor:
+    results = executor.map(lambda x, y: x + y, [1, 2, 3], [10, 20, 30])
+    assert list(results) == [11, 22, 33]
+
+
+def TestMapTimeout():
+  with futures.ThreadPoolExecutor(max_workers=1) as executor:
+    results = executor.map(time.sleep, [0.2], timeout=0.01)
+    try:
+      list(results)
+    except futures.TimeoutError:
+      pass
+    else:
+      raise AssertionError('map() did not time out')
+
+
+def TestAsCompleted():
+  with futures.ThreadPoolExecutor(max_workers=2) as executor:
+    slow = executor.submit(time.sleep, 0.05)
+    fast = executor.submit(lambda: 'fast')
+    assert list(futures.as_completed([slow, fast, fast])) == [fast, slow]
+
+
+def TestAsCompletedTimeout():
+  f = futures.Future()
+  try:
+    list(futures.as_completed([f], timeout=0.01))
+  except futures.TimeoutError as e:
+    assert str(e) == '1 (of 1) futures unfinished'
+  else:
+    raise AssertionError('as_completed() did not time out')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
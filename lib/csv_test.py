@@ -0,0 +1,174 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import csv
+import StringIO
+
+import weetest
+
+
+def TestReader():
+  rows = list(csv.reader(['a,b,c\r\n', '1,"2,3",4\n', '\n', '"x\n', 'y",z']))
+  assert rows == [['a', 'b', 'c'], ['1', '2,3', '4'], [], ['x\ny', 'z']]
+  assert list(csv.reader(['a;"b""c"'], delimiter=';')) == [['a', 'b"c']]
+  assert list(csv.reader([" a, 'b,c'"], quotechar="'",
+                         skipinitialspace=True)) == [['a', 'b,c']]
+  assert list(csv.reader(['a\\,b'], escapechar='\\')) == [['a,b']]
+  assert list(csv.reader(['1,"a",2.5'], quoting=csv.QUOTE_NONNUMERIC)) == [
+      [1.0, 'a', 2.5]]
+  assert list(csv.reader(['"a,b"'], quoting=csv.QUOTE_NONE)) == [['"a', 'b"']]
+
+
+def TestReaderErrors():
+  cases = [
+      (['a,"b"c'], {'strict': True}, csv.Error),
+      (['"a'], {'strict': True}, csv.Error),
+      (['a\rb'], {}, csv.Error),
+      (['a\0b'], {}, csv.Error),
+      ([1], {}, TypeError),
+      (['a'], {'dialect': 'nosuch'}, csv.Error),
+  ]
+  for lines, kwargs, exc in cases:
+    try:
+      list(csv.reader(lines, **kwargs))
+    except exc:
+      pass
+    else:
+      raise AssertionError('%s not raised for %r' % (exc.__name__, lines))
+
+
+def TestReaderStreams():
+  consumed = []
+  def Lines():
+    for line in ['a,b', 'c,d', 'e,f']:
+      consumed.append(line)
+      yield line
+  r = csv.reader(Lines())
+  assert r.next() == ['a', 'b']
+  assert consumed == ['a,b']
+  assert r.line_num == 1
+  assert list(r) == [['c', 'd'], ['e', 'f']]
+  assert r.line_num == 3
+
+
+def TestFieldSizeLimit():
+  old = csv.field_size_limit(3)
+  try:
+    assert csv.field_size_limit() == 3
+    try:
+      list(csv.reader(['abcd']))
+    except csv.Error:
+      pass
+    else:
+      raise AssertionError('csv.Error not raised')
+  finally:
+    csv.field_size_limit(old)
+
+
+def TestWriter():
+  f = StringIO.StringIO()
+  w = csv.writer(f)
+  w.writerow(['a', 'b,c', 'd"e', 'f\ng', None, 1, 2.5])
+  w.writerow([''])
+  w.writerows([[1, 2], (3, 4)])
+  assert f.getvalue() == ('a,"b,c","d""e","f\ng",,1,2.5\r\n'
+                          '""\r\n1,2\r\n3,4\r\n')
+  try:
+    w.writerow(1)
+  except csv.Error:
+    pass
+  else:
+    raise AssertionError('csv.Error not raised')
+
+
+def TestWriterQuoting():
+  cases = [
+      ({'quoting': csv.QUOTE_ALL}, ['a', 1], '"a","1"\r\n'),
+      ({'quoting': csv.QUOTE_NONNUMERIC}, ['a', 1, 2.5], '"a",1,2.5\r\n'),
+      ({'quoting': csv.QUOTE_NONE, 'escapechar': '\\'}, ['a,b', 'c"d'],
+       'a\\,b,c\\"d\r\n'),
+      ({'doublequote': False, 'escapechar': '\\'}, ['a"b'], 'a\\"b\r\n'),
+      ({'delimiter': '\t', 'lineterminator': '\n'}, ['a', 'b c'], 'a\tb c\n'),
+  ]
+  for kwargs, row, want in cases:
+    f = StringIO.StringIO()
+    csv.writer(f, **kwargs).writerow(row)
+    assert f.getvalue() == want, (kwargs, f.getvalue())
+  try:
+    csv.writer(StringIO.StringIO(), quoting=csv.QUOTE_NONE).writerow(['a,b'])
+  except csv.Error:
+    pass
+  else:
+    raise AssertionError('csv.Error not raised')
+
+
+def TestDialect():
+  d = csv.get_dialect('excel')
+  assert d.delimiter == ',' and d.quotechar == '"' and d.escapechar is None
+  assert d.lineterminator == '\r\n' and d.quoting == csv.QUOTE_MINIMAL
+  assert d.doublequote and not d.skipinitialspace
+  assert csv.get_dialect('excel-tab').delimiter == '\t'
+  csv.register_dialect('pipes', delimiter='|', quoting=csv.QUOTE_ALL)
+  try:
+    assert 'pipes' in csv.list_dialects()
+    f = StringIO.StringIO()
+    csv.writer(f, 'pipes').writerow(['a', 'b'])
+    assert f.getvalue() == '"a"|"b"\r\n'
+    assert list(csv.reader(['a|b'], 'pipes')) == [['a', 'b']]
+  finally:
+    csv.unregister_dialect('pipes')
+  assert 'pipes' not in csv.list_dialects()
+
+  class Semicolons(csv.excel):
+    delimiter = ';'
+  assert list(csv.reader(['a;b'], Semicolons)) == [['a', 'b']]
+
+  for kwargs in [{'delimiter': 'ab'}, {'quotechar': None, 'quoting': 1},
+                 {'quoting': 7}, {'lineterminator': None}, {'foo': 1}]:
+    try:
+      csv.reader([], **kwargs)
+    except TypeError:
+      pass
+    else:
+      raise AssertionError('TypeError not raised for %r' % kwargs)
+
+
+def TestDictReader():
+  f = StringIO.StringIO('name,age\r\nalice,30\r\nbob\r\ncarol,40,x\r\n')
+  r = csv.DictReader(f, restval='?')
+  assert r.fieldnames == ['name', 'age']
+  rows = list(r)
+  assert rows[0] == {'name': 'alice', 'age': '30'}
+  assert rows[1] == {'name': 'bob', 'age': '?'}
+  assert rows[2] == {'name': 'carol', 'age': '40', None: ['x']}
+
+
+def TestDictWriter():
+  f = StringIO.StringIO()
+  w = csv.DictWriter(f, ['a', 'b'], restval='-')
+  w.writeheader()
+  w.writerow({'a': 1})
+  w.writerows([{'a': 2, 'b': 'x,y'}])
+  assert f.getvalue() == 'a,b\r\n1,-\r\n2,"x,y"\r\n'
+  try:
+    w.writerow({'c': 1})
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('ValueError not raised')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
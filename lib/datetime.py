@@ -0,0 +1,30 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Concrete date/time and related types implemented natively in Go."""
+
+from '__go__/grumpy' import (DateTimeType, DateType, TimeDeltaType, TimeType,
+                             TZInfoType)
+
+__all__ = ['MINYEAR', 'MAXYEAR', 'date', 'datetime', 'time', 'timedelta',
+           'tzinfo']
+
+MINYEAR = 1
+MAXYEAR = 9999
+
+date = DateType
+datetime = DateTimeType
+time = TimeType
+timedelta = TimeDeltaType
+tzinfo = TZInfoType
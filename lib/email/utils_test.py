@@ -0,0 +1,78 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from email import utils
+import rfc822
+import time
+
+import weetest
+
+
+def TestFormatDate():
+  assert utils.formatdate(0) == 'Thu, 01 Jan 1970 00:00:00 -0000'
+  assert (utils.formatdate(1005268127, usegmt=True) ==
+          'Fri, 09 Nov 2001 01:08:47 GMT')
+  s = utils.formatdate(1005268127, localtime=True)
+  assert utils.mktime_tz(utils.parsedate_tz(s)) == 1005268127, s
+  assert rfc822.formatdate(0) == 'Thu, 01 Jan 1970 00:00:00 GMT'
+
+
+def TestParseDateTz():
+  want = (2001, 11, 9, 1, 8, 47, 0, 1, -1, -18000)
+  got = utils.parsedate_tz('Fri, 09 Nov 2001 01:08:47 -0500')
+  assert got == want, got
+  # Day names are optional and named zones are understood.
+  got = utils.parsedate_tz('9 Nov 2001 01:08:47 EST')
+  assert got == want, got
+  assert utils.parsedate_tz('Fri, 09 Nov 2001 01:08:47')[9] is None
+  assert utils.parsedate_tz('') is None
+  assert utils.parsedate_tz('foo') is None
+  got = rfc822.parsedate_tz('Fri, 09 Nov 2001 01:08:47 -0500')
+  assert got[:6] == want[:6] and got[9] == want[9], got
+
+
+def TestParseDate():
+  got = utils.parsedate('Sun, 06 Nov 1994 08:49:37 GMT')
+  assert got == (1994, 11, 6, 8, 49, 37, 0, 1, -1), got
+
+
+def TestMktimeTz():
+  assert utils.mktime_tz(utils.parsedate_tz(
+      'Sun, 06 Nov 1994 08:49:37 GMT')) == 784111777
+  assert utils.mktime_tz(utils.parsedate_tz(
+      'Thu, 29 Feb 2024 00:00:00 +0100')) == 1709161200
+  assert utils.mktime_tz((2001, 11, 9, 1, 8, 47, 0, 1, -1, None)) == (
+      time.mktime((2001, 11, 9, 1, 8, 47, 0, 1, -1)))
+  assert rfc822.mktime_tz(rfc822.parsedate_tz(
+      'Sun, 06 Nov 1994 08:49:37 GMT')) == 784111777
+
+
+def TestAddresses():
+  assert utils.parseaddr('Foo Bar <foo@example.com>') == (
+      'Foo Bar', 'foo@example.com')
+  assert utils.parseaddr('foo@example.com') == ('', 'foo@example.com')
+  assert utils.formataddr(('Foo Bar', 'foo@example.com')) == (
+      'Foo Bar <foo@example.com>')
+  assert utils.formataddr(('Bar, Foo', 'foo@example.com')) == (
+      '"Bar, Foo" <foo@example.com>')
+  assert utils.formataddr(('Foo "Bar"', 'foo@example.com')) == (
+      '"Foo \\"Bar\\"" <foo@example.com>')
+  assert utils.formataddr(('', 'foo@example.com')) == 'foo@example.com'
+  got = utils.getaddresses(['a@example.com, "B" <b@example.com>'])
+  assert got == [('', 'a@example.com'), ('B', 'b@example.com')], got
+  assert utils.unquote('"foo \\"bar\\""') == 'foo "bar"'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
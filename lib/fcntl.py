@@ -0,0 +1,90 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""File control and advisory locking of file descriptors."""
+
+# pylint: disable=g-multiple-import
+from '__go__/syscall' import (
+    EINTR as _EINTR,
+    F_GETLK,
+    F_RDLCK,
+    F_SETLK,
+    F_SETLKW,
+    F_UNLCK,
+    F_WRLCK,
+    Flock as _Flock,
+    FcntlFlock as _FcntlFlock,
+    Flock_t as _Flock_t,
+    LOCK_EX,
+    LOCK_NB,
+    LOCK_SH,
+    LOCK_UN
+)
+
+
+def flock(fd, operation):
+  """Applies or removes a BSD style lock on the whole of fd.
+
+  Args:
+    fd: A file descriptor or an object with a fileno() method.
+    operation: One of LOCK_SH, LOCK_EX or LOCK_UN, optionally or'd with LOCK_NB
+        to raise IOError instead of waiting for a conflicting lock.
+  """
+  _invoke(_Flock, _fileno(fd), operation)
+
+
+def lockf(fd, operation, length=0, start=0, whence=0):
+  """Applies or removes a POSIX record lock on a region of fd.
+
+  Args:
+    fd: A file descriptor or an object with a fileno() method.
+    operation: One of LOCK_SH, LOCK_EX or LOCK_UN, optionally or'd with LOCK_NB
+        to raise IOError instead of waiting for a conflicting lock.
+    length: The number of bytes to lock. Zero means until the end of the file.
+    start: The offset of the region relative to whence.
+    whence: As for file.seek().
+  """
+  lock = _Flock_t.new()
+  op = operation & ~LOCK_NB
+  if op == LOCK_SH:
+    lock.Type = F_RDLCK
+  elif op == LOCK_EX:
+    lock.Type = F_WRLCK
+  elif op == LOCK_UN:
+    lock.Type = F_UNLCK
+  else:
+    raise ValueError('unrecognized lockf argument')
+  lock.Whence = whence
+  lock.Start = start
+  lock.Len = length
+  cmd = F_SETLK if operation & LOCK_NB else F_SETLKW
+  _invoke(_FcntlFlock, _fileno(fd), cmd, lock)
+
+
+def _fileno(fd):
+  if isinstance(fd, (int, long)):
+    return fd
+  if hasattr(fd, 'fileno'):
+    return fd.fileno()
+  raise TypeError('argument must be an int, or have a fileno() method.')
+
+
+def _invoke(func, *args):
+  while True:
+    err = func(*args)
+    if not err:
+      return
+    if err != _EINTR:
+      raise IOError(int(err), err.Error())
@@ -0,0 +1,95 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import fcntl
+import os
+import tempfile
+
+import weetest
+
+
+def _TempFile():
+  fd, path = tempfile.mkstemp()
+  os.close(fd)
+  return path
+
+
+def TestFlock():
+  path = _TempFile()
+  try:
+    with open(path, 'w') as f:
+      with open(path, 'w') as g:
+        fcntl.flock(f, fcntl.LOCK_EX)
+        try:
+          fcntl.flock(g.fileno(), fcntl.LOCK_EX | fcntl.LOCK_NB)
+        except IOError:
+          pass
+        else:
+          assert False
+        fcntl.flock(f, fcntl.LOCK_UN)
+        fcntl.flock(g.fileno(), fcntl.LOCK_EX | fcntl.LOCK_NB)
+        fcntl.flock(g, fcntl.LOCK_UN)
+  finally:
+    os.remove(path)
+
+
+def TestFlockShared():
+  path = _TempFile()
+  try:
+    with open(path) as f:
+      with open(path) as g:
+        fcntl.flock(f, fcntl.LOCK_SH)
+        fcntl.flock(g, fcntl.LOCK_SH | fcntl.LOCK_NB)
+  finally:
+    os.remove(path)
+
+
+def TestLockf():
+  path = _TempFile()
+  try:
+    with open(path, 'r+') as f:
+      fcntl.lockf(f, fcntl.LOCK_EX | fcntl.LOCK_NB)
+      fcntl.lockf(f, fcntl.LOCK_UN)
+      fcntl.lockf(f.fileno(), fcntl.LOCK_SH, 10, 5)
+      fcntl.lockf(f.fileno(), fcntl.LOCK_UN, 10, 5)
+  finally:
+    os.remove(path)
+
+
+def TestLockfInvalidArgs():
+  path = _TempFile()
+  try:
+    with open(path, 'r+') as f:
+      try:
+        fcntl.lockf(f, 0)
+      except ValueError:
+        pass
+      else:
+        assert False
+  finally:
+    os.remove(path)
+
+
+def TestFlockInvalidArgs():
+  try:
+    fcntl.flock('foo', fcntl.LOCK_EX)
+  except TypeError:
+    pass
+  else:
+    assert False
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,118 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""Inter-process file locks built on fcntl.flock.
+
+FileLock is typically used by daemons to ensure that only one instance runs at
+a time:
+
+  with filelock.FileLock('/var/run/mydaemon.lock', timeout=0):
+    serve_forever()
+"""
+
+from '__go__/syscall' import EACCES, EAGAIN
+import fcntl
+import time
+
+
+class LockTimeout(IOError):
+  """Raised when a lock could not be acquired within the requested timeout."""
+
+
+def lock(f, flags):
+  """Locks the open file f. flags are fcntl.LOCK_* values."""
+  try:
+    fcntl.flock(f, flags)
+  except IOError as e:
+    # flock() fails with EWOULDBLOCK (aka EAGAIN) but some platforms report
+    # EACCES for contended locks.
+    if flags & fcntl.LOCK_NB and e.args and e.args[0] in (EAGAIN, EACCES):
+      raise LockTimeout(*e.args)
+    raise
+
+
+def unlock(f):
+  """Releases a lock previously taken on f with lock()."""
+  fcntl.flock(f, fcntl.LOCK_UN)
+
+
+class FileLock(object):
+  """An exclusive advisory lock held on the file at path.
+
+  The file is created if it does not exist and is left in place when the lock
+  is released. Locks are held per FileLock object: acquiring a lock that is
+  already held by another FileLock blocks even within the same process.
+
+  Args:
+    path: The path of the lock file.
+    timeout: The maximum number of seconds acquire() waits for the lock. None
+        means wait forever and zero means fail immediately if the lock is
+        held elsewhere.
+    poll_interval: The number of seconds between attempts to take the lock
+        while waiting for a timeout to expire.
+  """
+
+  def __init__(self, path, timeout=None, poll_interval=0.05):
+    self.path = path
+    self.timeout = timeout
+    self.poll_interval = poll_interval
+    self._file = None
+
+  @property
+  def is_locked(self):
+    return self._file is not None
+
+  def acquire(self, timeout=None):
+    """Takes the lock, raising LockTimeout if it cannot be taken in time."""
+    if self._file is not None:
+      raise RuntimeError('lock %s is already held' % self.path)
+    if timeout is None:
+      timeout = self.timeout
+    f = open(self.path, 'a')
+    try:
+      if timeout is None:
+        lock(f, fcntl.LOCK_EX)
+      else:
+        deadline = time.time() + timeout
+        while True:
+          try:
+            lock(f, fcntl.LOCK_EX | fcntl.LOCK_NB)
+            break
+          except LockTimeout:
+            remaining = deadline - time.time()
+            if remaining <= 0:
+              raise LockTimeout('timed out waiting for lock %s' % self.path)
+            time.sleep(min(self.poll_interval, remaining))
+    except:
+      f.close()
+      raise
+    self._file = f
+
+  def release(self):
+    """Releases the lock. It is an error to release a lock that is not held."""
+    if self._file is None:
+      raise RuntimeError('lock %s is not held' % self.path)
+    f, self._file = self._file, None
+    try:
+      unlock(f)
+    finally:
+      f.close()
+
+  def __enter__(self):
+    self.acquire()
+    return self
+
+  def __exit__(self, *args):
+    self.release()
@@ -0,0 +1,109 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import os
+import tempfile
+
+import filelock
+import weetest
+
+
+def _TempPath():
+  fd, path = tempfile.mkstemp()
+  os.close(fd)
+  os.remove(path)
+  return path
+
+
+def TestFileLock():
+  path = _TempPath()
+  try:
+    lock = filelock.FileLock(path)
+    assert not lock.is_locked
+    with lock as l:
+      assert l is lock
+      assert lock.is_locked
+      assert os.path.exists(path)
+    assert not lock.is_locked
+    assert os.path.exists(path)
+  finally:
+    os.remove(path)
+
+
+def TestFileLockContended():
+  path = _TempPath()
+  try:
+    with filelock.FileLock(path):
+      other = filelock.FileLock(path, timeout=0)
+      try:
+        other.acquire()
+      except filelock.LockTimeout:
+        pass
+      else:
+        assert False
+      try:
+        other.acquire(timeout=0.1)
+      except filelock.LockTimeout as e:
+        assert isinstance(e, IOError)
+      else:
+        assert False
+      assert not other.is_locked
+    with other:
+      assert other.is_locked
+  finally:
+    os.remove(path)
+
+
+def TestFileLockNotReentrant():
+  path = _TempPath()
+  try:
+    lock = filelock.FileLock(path)
+    with lock:
+      try:
+        lock.acquire()
+      except RuntimeError:
+        pass
+      else:
+        assert False
+    try:
+      lock.release()
+    except RuntimeError:
+      pass
+    else:
+      assert False
+  finally:
+    os.remove(path)
+
+
+def TestLockUnlock():
+  path = _TempPath()
+  try:
+    with open(path, 'w') as f:
+      with open(path, 'w') as g:
+        filelock.lock(f, filelock.fcntl.LOCK_EX | filelock.fcntl.LOCK_NB)
+        try:
+          filelock.lock(g, filelock.fcntl.LOCK_EX | filelock.fcntl.LOCK_NB)
+        except filelock.LockTimeout:
+          pass
+        else:
+          assert False
+        filelock.unlock(f)
+        filelock.lock(g, filelock.fcntl.LOCK_EX | filelock.fcntl.LOCK_NB)
+  finally:
+    os.remove(path)
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,78 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import functools
+
+import weetest
+
+
+def TestPartial():
+  def f(*args, **kwargs):
+    return args, kwargs
+  p = functools.partial(f, 1, 2, a=3)
+  assert p() == ((1, 2), {'a': 3})
+  assert p(4, b=5) == ((1, 2, 4), {'a': 3, 'b': 5})
+  assert p(a=6) == ((1, 2), {'a': 6})
+  assert p.func is f
+  assert p.args == (1, 2)
+  assert p.keywords == {'a': 3}
+  p.foo = 'bar'
+  assert p.foo == 'bar'
+  try:
+    functools.partial(123)
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('partial() of a non-callable did not raise TypeError')
+
+
+def TestReduce():
+  assert functools.reduce(lambda x, y: x + y, [1, 2, 3]) == 6
+  assert functools.reduce(lambda x, y: x + y, [], 'a') == 'a'
+  try:
+    functools.reduce(lambda x, y: x + y, [])
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('reduce() of an empty sequence did not raise TypeError')
+
+
+def TestWraps():
+  def wrapped():
+    """Wrapped docstring."""
+  wrapped.foo = 'bar'
+  @functools.wraps(wrapped)
+  def wrapper():
+    pass
+  assert wrapper.__name__ == 'wrapped'
+  assert wrapper.__doc__ == wrapped.__doc__
+  assert wrapper.foo == 'bar'
+
+
+def TestCmpToKey():
+  key = functools.cmp_to_key(lambda x, y: cmp(y, x))
+  assert sorted([3, 1, 2], key=key) == [3, 2, 1]
+  assert key(1) > key(2)
+  assert key(1) == key(1)
+  assert key('a').obj == 'a'
+  try:
+    hash(key(1))
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('hash() of a key wrapper did not raise TypeError')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
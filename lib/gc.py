@@ -0,0 +1,173 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Controls for the garbage collector.
+
+Grumpy objects are managed by the Go garbage collector, which is concurrent,
+non-generational and collects reference cycles as a matter of course. This
+module maps CPython's gc interface onto it: collect() runs a collection,
+disable() and enable() turn automatic collection off and on by adjusting the
+GOGC percentage and the statistics come from runtime.MemStats. The collection
+thresholds and debug flags are accepted but have no effect.
+"""
+
+import thread
+
+from '__go__/grumpy' import Referents as _Referents
+from '__go__/runtime' import GC as _GC, MemStats as _MemStats, ReadMemStats as _ReadMemStats  # pylint: disable=g-multiple-import
+from '__go__/runtime/debug' import SetGCPercent as _SetGCPercent
+
+DEBUG_STATS = 1
+DEBUG_COLLECTABLE = 2
+DEBUG_UNCOLLECTABLE = 4
+DEBUG_INSTANCES = 8
+DEBUG_OBJECTS = 16
+DEBUG_SAVEALL = 32
+DEBUG_LEAK = (DEBUG_COLLECTABLE | DEBUG_UNCOLLECTABLE | DEBUG_INSTANCES |
+              DEBUG_OBJECTS | DEBUG_SAVEALL)
+
+# The Go collector frees every unreachable object, including cycles with
+# __del__ methods, so this is always empty.
+garbage = []
+
+_DEFAULT_GC_PERCENT = 100
+
+_lock = thread.allocate_lock()
+# The GOGC percentage to restore on enable() or None when collection is
+# enabled.
+_disabled_percent = None
+_threshold = (700, 10, 10)
+_debug = 0
+
+
+def collect(generation=2):
+  """Runs a full garbage collection and returns 0.
+
+  The Go runtime does not report how many objects were unreachable, and it
+  finalizes objects with __del__ methods or weak references asynchronously
+  after the collection returns.
+  """
+  if generation not in (0, 1, 2):
+    raise ValueError('invalid generation')
+  _GC()
+  return 0
+
+
+def disable():
+  """Disables automatic garbage collection."""
+  global _disabled_percent
+  with _lock:
+    percent = _SetGCPercent(-1)
+    if _disabled_percent is None:
+      _disabled_percent = percent if percent >= 0 else _DEFAULT_GC_PERCENT
+
+
+def enable():
+  """Enables automatic garbage collection."""
+  global _disabled_percent
+  with _lock:
+    percent = _disabled_percent
+    if percent is None:
+      # Collection may have been turned off with GOGC=off.
+      percent = _SetGCPercent(-1)
+      if percent < 0:
+        percent = _DEFAULT_GC_PERCENT
+    _SetGCPercent(percent)
+    _disabled_percent = None
+
+
+def isenabled():
+  """Returns True if automatic garbage collection is enabled."""
+  with _lock:
+    percent = _SetGCPercent(-1)
+    _SetGCPercent(percent)
+  return percent >= 0
+
+
+def _mem_stats():
+  stats = _MemStats.new()
+  _ReadMemStats(stats)
+  return stats
+
+
+def get_count():
+  """Returns a 3-tuple with the number of live heap objects and two zeros.
+
+  CPython counts the allocations since the last collection of each of its
+  three generations. Go has a single generation so the count is the number of
+  allocated objects not yet freed.
+  """
+  return _mem_stats().HeapObjects, 0, 0
+
+
+def get_stats():
+  """Returns a list of dicts of collection statistics, one per generation.
+
+  The first dict holds the statistics for the Go collector. In addition to
+  CPython's 'collections', 'collected' and 'uncollectable' keys, it has the
+  'heap_alloc', 'heap_objects', 'next_gc' and 'pause_total_ns' fields of
+  runtime.MemStats. The other two generations are always empty.
+  """
+  stats = _mem_stats()
+  result = [{
+      'collections': stats.NumGC,
+      'collected': stats.Frees,
+      'uncollectable': 0,
+      'heap_alloc': stats.HeapAlloc,
+      'heap_objects': stats.HeapObjects,
+      'next_gc': stats.NextGC,
+      'pause_total_ns': stats.PauseTotalNs,
+  }]
+  for _ in xrange(2):
+    result.append({'collections': 0, 'collected': 0, 'uncollectable': 0})
+  return result
+
+
+def get_threshold():
+  """Returns the thresholds last passed to set_threshold()."""
+  return _threshold
+
+
+def set_threshold(threshold0, threshold1=None, threshold2=None):
+  """Records the collection thresholds, which have no effect."""
+  global _threshold
+  t = list(_threshold)
+  for i, threshold in enumerate((threshold0, threshold1, threshold2)):
+    if threshold is not None:
+      t[i] = int(threshold)
+  _threshold = tuple(t)
+
+
+def get_debug():
+  """Returns the debug flags last passed to set_debug()."""
+  return _debug
+
+
+def set_debug(flags):
+  """Records the debug flags, which have no effect."""
+  global _debug
+  _debug = int(flags)
+
+
+def get_referents(*objs):
+  """Returns a list of the objects directly referenced by any of objs.
+
+  Only references the runtime knows about are followed: attributes, container
+  elements and the fields of builtin objects.
+  """
+  result = []
+  for o in objs:
+    result.extend(_Referents(o))
+  return result
+
@@ -0,0 +1,86 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import gc
+
+import weetest
+
+
+def TestCollect():
+  before = gc.get_stats()[0]['collections']
+  assert gc.collect() == 0
+  assert gc.collect(0) == 0
+  assert gc.get_stats()[0]['collections'] > before
+  try:
+    gc.collect(3)
+  except ValueError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestEnableDisable():
+  assert gc.isenabled()
+  gc.disable()
+  try:
+    assert not gc.isenabled()
+    gc.disable()
+    assert not gc.isenabled()
+    # Explicit collections still run.
+    assert gc.collect() == 0
+  finally:
+    gc.enable()
+  assert gc.isenabled()
+  gc.enable()
+  assert gc.isenabled()
+
+
+def TestGetCount():
+  count = gc.get_count()
+  assert len(count) == 3
+  assert count[0] > 0
+
+
+def TestGetStats():
+  stats = gc.get_stats()
+  assert len(stats) == 3
+  for s in stats:
+    assert set(['collections', 'collected', 'uncollectable']) <= set(s)
+  assert stats[0]['heap_objects'] > 0
+  assert stats[0]['heap_alloc'] > 0
+
+
+def TestThresholdAndDebug():
+  old = gc.get_threshold()
+  try:
+    gc.set_threshold(100)
+    assert gc.get_threshold() == (100,) + old[1:]
+    gc.set_threshold(1, 2, 3)
+    assert gc.get_threshold() == (1, 2, 3)
+  finally:
+    gc.set_threshold(*old)
+  gc.set_debug(gc.DEBUG_STATS)
+  assert gc.get_debug() == gc.DEBUG_STATS
+  gc.set_debug(0)
+  assert gc.garbage == []
+
+
+def TestGetReferents():
+  a, b = object(), object()
+  referents = gc.get_referents([a], (b,))
+  assert a in referents and b in referents
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
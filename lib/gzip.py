@@ -0,0 +1,218 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Functions that read and write gzipped files."""
+
+import __builtin__
+import _struct as struct
+import os
+import time
+
+import zlib
+
+__all__ = ['GzipFile', 'open']
+
+READ, WRITE = 1, 2
+
+_FNAME = 8
+_CHUNK_SIZE = 1024
+
+
+def open(filename, mode='rb', compresslevel=9):  # pylint: disable=redefined-builtin
+  return GzipFile(filename, mode, compresslevel)
+
+
+class GzipFile(object):
+  """A file object that transparently compresses or decompresses its data.
+
+  Reading decompresses each member of the file in turn, the same as gunzip.
+  Seeking is not supported.
+  """
+
+  myfileobj = None
+
+  def __init__(self, filename=None, mode=None, compresslevel=9, fileobj=None,
+               mtime=None):
+    if mode and 'b' not in mode:
+      mode += 'b'
+    if fileobj is None:
+      fileobj = self.myfileobj = __builtin__.open(filename, mode or 'rb')
+    if filename is None:
+      filename = getattr(fileobj, 'name', '')
+      if not isinstance(filename, basestring) or filename.startswith('<'):
+        filename = ''
+    if mode is None:
+      mode = getattr(fileobj, 'mode', 'rb')
+    if mode[0] == 'r':
+      self.mode = READ
+      self._buffer = ''
+      self._decompressor = zlib.decompressobj(16 + zlib.MAX_WBITS)
+      self._eof = False
+      self.name = filename
+    elif mode[0] in 'wa':
+      self.mode = WRITE
+      self.name = filename
+      self._compressor = zlib.compressobj(compresslevel, zlib.DEFLATED,
+                                          -zlib.MAX_WBITS)
+      self._crc = zlib.crc32('')
+      self._size = 0
+      self._write_header(fileobj, compresslevel, mtime)
+    else:
+      raise IOError('Mode ' + mode + ' not supported')
+    self.fileobj = fileobj
+
+  def __repr__(self):
+    return '<gzip ' + repr(self.fileobj)[1:-1] + ' ' + hex(id(self)) + '>'
+
+  @property
+  def closed(self):
+    return self.fileobj is None
+
+  def _check_closed(self):
+    if self.fileobj is None:
+      raise ValueError('I/O operation on closed file.')
+
+  def _write_header(self, fileobj, compresslevel, mtime):
+    fname = os.path.basename(self.name)
+    if isinstance(fname, unicode):
+      fname = fname.encode('latin-1')
+    if fname.endswith('.gz'):
+      fname = fname[:-3]
+    flags = _FNAME if fname else 0
+    if mtime is None:
+      mtime = time.time()
+    if compresslevel == zlib.Z_BEST_COMPRESSION:
+      xfl = '\002'
+    elif compresslevel == zlib.Z_BEST_SPEED:
+      xfl = '\004'
+    else:
+      xfl = '\000'
+    header = '\037\213\010' + chr(flags) + struct.pack('<L', int(mtime))
+    header += xfl + '\377'
+    if fname:
+      header += fname + '\000'
+    fileobj.write(header)
+
+  def write(self, data):
+    self._check_closed()
+    if self.mode != WRITE:
+      raise IOError('write() on read-only GzipFile object')
+    if data:
+      self._size += len(data)
+      self._crc = zlib.crc32(data, self._crc)
+      self.fileobj.write(self._compressor.compress(data))
+    return len(data)
+
+  def writelines(self, lines):
+    for line in lines:
+      self.write(line)
+
+  def _fill(self, size):
+    """Decompresses data until the buffer holds size bytes or input ends."""
+    while not self._eof and (size < 0 or len(self._buffer) < size):
+      chunk = self.fileobj.read(_CHUNK_SIZE)
+      if not chunk:
+        try:
+          self._buffer += self._decompressor.flush()
+        except zlib.error as e:
+          raise IOError(str(e))
+        self._eof = True
+        break
+      self._decompress(chunk)
+
+  def _decompress(self, chunk):
+    try:
+      self._buffer += self._decompressor.decompress(chunk)
+      # Anything following the end of a member is the start of another.
+      # Trailing null bytes are padding and are ignored.
+      rest = self._decompressor.unused_data
+      while rest and rest.strip('\000'):
+        self._decompressor = zlib.decompressobj(16 + zlib.MAX_WBITS)
+        self._buffer += self._decompressor.decompress(rest)
+        rest = self._decompressor.unused_data
+    except zlib.error as e:
+      raise IOError(str(e))
+
+  def read(self, size=-1):
+    self._check_closed()
+    if self.mode != READ:
+      raise IOError('read() on write-only GzipFile object')
+    self._fill(size)
+    if size < 0:
+      size = len(self._buffer)
+    data = self._buffer[:size]
+    self._buffer = self._buffer[size:]
+    return data
+
+  def readline(self, size=-1):
+    self._check_closed()
+    if self.mode != READ:
+      raise IOError('read() on write-only GzipFile object')
+    while True:
+      i = self._buffer.find('\n')
+      if i >= 0 or self._eof:
+        break
+      if 0 <= size <= len(self._buffer):
+        break
+      self._fill(len(self._buffer) + _CHUNK_SIZE)
+    end = len(self._buffer) if i < 0 else i + 1
+    if 0 <= size < end:
+      end = size
+    line = self._buffer[:end]
+    self._buffer = self._buffer[end:]
+    return line
+
+  def readlines(self, sizehint=0):  # pylint: disable=unused-argument
+    return list(self)
+
+  def __iter__(self):
+    return self
+
+  def next(self):
+    line = self.readline()
+    if not line:
+      raise StopIteration
+    return line
+
+  def flush(self, zlib_mode=zlib.Z_SYNC_FLUSH):
+    self._check_closed()
+    if self.mode == WRITE:
+      self.fileobj.write(self._compressor.flush(zlib_mode))
+      self.fileobj.flush()
+
+  def fileno(self):
+    return self.fileobj.fileno()
+
+  def close(self):
+    fileobj = self.fileobj
+    if fileobj is None:
+      return
+    self.fileobj = None
+    try:
+      if self.mode == WRITE:
+        fileobj.write(self._compressor.flush())
+        fileobj.write(struct.pack('<L', self._crc & 0xffffffff))
+        fileobj.write(struct.pack('<L', self._size & 0xffffffff))
+    finally:
+      myfileobj = self.myfileobj
+      if myfileobj:
+        self.myfileobj = None
+        myfileobj.close()
+
+  def __enter__(self):
+    self._check_closed()
+    return self
+
+  def __exit__(self, *args):
+    self.close()
@@ -0,0 +1,87 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import StringIO
+import tempfile
+
+import gzip
+import weetest
+
+
+# Produced by CPython's gzip module with mtime=1234.
+_HELLO_GZ = ('\x1f\x8b\x08\x08\xd2\x04\x00\x00\x02\xfffoo.txt\x00\xcbH\xcd\xc9'
+             '\xc9\xe7*\xcf/\xcaI\xe1\x02\x00\xff]\xc5\xc4\x0c\x00\x00\x00')
+
+
+def TestWrite():
+  buf = StringIO.StringIO()
+  f = gzip.GzipFile('foo.txt.gz', 'wb', 9, buf, mtime=1234)
+  f.write('hello\n')
+  f.write('world\n')
+  f.close()
+  assert f.closed
+  # Go's deflate encodes the data differently from zlib's but the header and
+  # trailer must match.
+  data = buf.getvalue()
+  assert data[:18] == _HELLO_GZ[:18], repr(data)
+  assert data[-8:] == _HELLO_GZ[-8:], repr(data)
+  assert gzip.GzipFile(fileobj=StringIO.StringIO(data)).read() == 'hello\nworld\n'
+
+
+def TestRead():
+  f = gzip.GzipFile(fileobj=StringIO.StringIO(_HELLO_GZ))
+  assert f.readline() == 'hello\n'
+  assert f.read(3) == 'wor'
+  assert f.read() == 'ld\n'
+  assert f.read() == ''
+
+
+def TestReadLines():
+  f = gzip.GzipFile(fileobj=StringIO.StringIO(_HELLO_GZ))
+  assert f.readlines() == ['hello\n', 'world\n']
+
+
+def TestMultipleMembers():
+  f = gzip.GzipFile(fileobj=StringIO.StringIO(_HELLO_GZ * 2 + '\0' * 8))
+  assert f.read() == 'hello\nworld\n' * 2
+
+
+def TestCorrupt():
+  f = gzip.GzipFile(fileobj=StringIO.StringIO('not gzipped'))
+  try:
+    f.read()
+  except IOError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestOpen():
+  fd, path = tempfile.mkstemp()
+  os.close(fd)
+  try:
+    data = ''.join('line %d\n' % i for i in xrange(1000))
+    with gzip.open(path, 'wb') as f:
+      f.write(data)
+    with gzip.open(path) as f:
+      assert f.read() == data
+    with gzip.open(path) as f:
+      assert list(f)[-1] == 'line 999\n'
+  finally:
+    os.remove(path)
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,56 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Secure hash and message digest algorithms backed by Go's crypto packages."""
+
+from '__go__/grumpy' import NewDigest
+
+__all__ = ['new', 'algorithms', 'algorithms_guaranteed', 'algorithms_available',
+           'md5', 'sha1', 'sha224', 'sha256', 'sha384', 'sha512']
+
+algorithms_guaranteed = ('md5', 'sha1', 'sha224', 'sha256', 'sha384', 'sha512')
+algorithms_available = frozenset(algorithms_guaranteed)
+algorithms = algorithms_guaranteed
+
+
+def new(name, string=''):
+  """Returns a new hash object computing the algorithm called name."""
+  h = NewDigest(__frame__(), name)  # pylint: disable=undefined-variable
+  if string:
+    h.update(string)
+  return h
+
+
+def md5(string=''):
+  return new('md5', string)
+
+
+def sha1(string=''):
+  return new('sha1', string)
+
+
+def sha224(string=''):
+  return new('sha224', string)
+
+
+def sha256(string=''):
+  return new('sha256', string)
+
+
+def sha384(string=''):
+  return new('sha384', string)
+
+
+def sha512(string=''):
+  return new('sha512', string)
@@ -0,0 +1,110 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import hashlib
+import md5
+import sha
+
+import weetest
+
+
+_ABC_DIGESTS = {
+    'md5': '900150983cd24fb0d6963f7d28e17f72',
+    'sha1': 'a9993e364706816aba3e25717850c26c9cd0d89d',
+    'sha224': '23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7',
+    'sha256': ('ba7816bf8f01cfea414140de5dae2223'
+               'b00361a396177a9cb410ff61f20015ad'),
+    'sha384': ('cb00753f45a35e8bb5a03d699ac65007272c32ab0eded163'
+               '1a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7'),
+    'sha512': ('ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a'
+               '2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f'),
+}
+
+
+def TestConstructors():
+  for name, want in _ABC_DIGESTS.iteritems():
+    h = getattr(hashlib, name)('abc')
+    assert h.name == name
+    assert h.hexdigest() == want, (name, h.hexdigest())
+    assert hashlib.new(name, 'abc').hexdigest() == want
+    assert hashlib.new(name.upper(), 'abc').hexdigest() == want
+    assert len(h.digest()) == h.digest_size
+
+
+def TestAlgorithms():
+  assert sorted(hashlib.algorithms) == sorted(_ABC_DIGESTS)
+  for name in hashlib.algorithms_available:
+    assert hashlib.new(name).name == name
+
+
+def TestUpdate():
+  h = hashlib.sha256()
+  h.update('a')
+  h.update(u'bc')
+  assert h.hexdigest() == _ABC_DIGESTS['sha256']
+  # Taking a digest doesn't finalize the hash.
+  h.update('d')
+  assert h.hexdigest() == hashlib.sha256('abcd').hexdigest()
+
+
+def TestDigest():
+  h = hashlib.md5('ab')
+  assert h.digest() == '\x18~\xf4Ca"\xd1\xcc/@\xdc+\x92\xf0\xeb\xa0'
+  assert ''.join('%02x' % ord(c) for c in h.digest()) == h.hexdigest()
+  assert h.digest_size == 16
+  assert h.block_size == 64
+  assert hashlib.sha384().block_size == 128
+
+
+def TestCopy():
+  h = hashlib.sha1('abc')
+  c = h.copy()
+  c.update('def')
+  assert h.hexdigest() == _ABC_DIGESTS['sha1']
+  assert c.hexdigest() == hashlib.sha1('abcdef').hexdigest()
+  assert c.name == 'sha1'
+
+
+def TestErrors():
+  try:
+    hashlib.new('md4')
+  except ValueError as e:
+    assert str(e) == 'unsupported hash type md4', str(e)
+  else:
+    raise AssertionError('expected ValueError')
+  try:
+    hashlib.md5().update(123)
+  except TypeError as e:
+    assert str(e) == 'must be string or buffer, not int', str(e)
+  else:
+    raise AssertionError('expected TypeError')
+  try:
+    type(hashlib.md5())()
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('expected TypeError')
+
+
+def TestLegacyModules():
+  assert md5.new('abc').hexdigest() == _ABC_DIGESTS['md5']
+  assert md5.md5('abc').digest() == hashlib.md5('abc').digest()
+  assert md5.digest_size == 16
+  assert sha.new('abc').hexdigest() == _ABC_DIGESTS['sha1']
+  assert sha.sha('abc').digest() == hashlib.sha1('abc').digest()
+  assert sha.digest_size == sha.digestsize == 20
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
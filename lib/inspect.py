@@ -0,0 +1,274 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Get useful information from live Python objects.
+
+Functions compiled by grumpc record their parameter names, defaults and the
+file and line on which they were defined, which is what getargspec() and the
+source lookup functions are based on. Builtin functions implemented in Go have
+no code object and so are not considered Python functions.
+"""
+
+import collections
+import os
+import re
+import sys
+import tokenize
+import types
+
+
+CO_VARARGS = 0x4
+CO_VARKEYWORDS = 0x8
+
+ArgSpec = collections.namedtuple('ArgSpec', 'args varargs keywords defaults')
+
+
+def ismodule(obj):
+  """Returns True if obj is a module."""
+  return isinstance(obj, types.ModuleType)
+
+
+def isclass(obj):
+  """Returns True if obj is a class."""
+  return isinstance(obj, type)
+
+
+def ismethod(obj):
+  """Returns True if obj is a bound or unbound method of a Python function."""
+  return isinstance(obj, types.MethodType) and isfunction(obj.im_func)
+
+
+def isfunction(obj):
+  """Returns True if obj is a Python function, including lambdas."""
+  return isinstance(obj, types.FunctionType) and obj.func_code is not None
+
+
+def isbuiltin(obj):
+  """Returns True if obj is a builtin function or method implemented in Go."""
+  if isinstance(obj, types.MethodType):
+    obj = obj.im_func
+  return isinstance(obj, types.FunctionType) and obj.func_code is None
+
+
+def isroutine(obj):
+  """Returns True if obj is any kind of function or method."""
+  return isinstance(obj, (types.FunctionType, types.MethodType))
+
+
+def isgenerator(obj):
+  """Returns True if obj is a generator."""
+  return isinstance(obj, types.GeneratorType)
+
+
+def istraceback(obj):
+  """Returns True if obj is a traceback."""
+  return isinstance(obj, types.TracebackType)
+
+
+def isframe(obj):
+  """Returns True if obj is a frame."""
+  return isinstance(obj, types.FrameType)
+
+
+def iscode(obj):
+  """Returns True if obj is a code object."""
+  return isinstance(obj, types.CodeType)
+
+
+def getmembers(obj, predicate=None):
+  """Returns all members of obj as (name, value) pairs sorted by name.
+
+  Args:
+    obj: The object whose attributes are listed.
+    predicate: If given, only members for which predicate(value) is true are
+        included.
+
+  Returns:
+    A list of (name, value) tuples.
+  """
+  results = []
+  for key in dir(obj):
+    try:
+      value = getattr(obj, key)
+    except AttributeError:
+      continue
+    if not predicate or predicate(value):
+      results.append((key, value))
+  results.sort(key=lambda item: item[0])
+  return results
+
+
+def getargs(co):
+  """Returns the (args, varargs, varkw) parameter names of code object co."""
+  if not iscode(co):
+    raise TypeError('%r is not a code object' % (co,))
+  nargs = co.co_argcount
+  names = co.co_varnames
+  args = list(names[:nargs])
+  varargs = None
+  if co.co_flags & CO_VARARGS:
+    varargs = names[nargs]
+    nargs += 1
+  varkw = None
+  if co.co_flags & CO_VARKEYWORDS:
+    varkw = names[nargs]
+  return args, varargs, varkw
+
+
+def getargspec(func):
+  """Returns the names and default values of a function's parameters.
+
+  Args:
+    func: A Python function or method.
+
+  Returns:
+    An ArgSpec(args, varargs, keywords, defaults) tuple. args is a list of the
+    positional parameter names, varargs and keywords are the names of the * and
+    ** parameters or None and defaults is a tuple of the default values of the
+    last len(defaults) positional parameters or None.
+
+  Raises:
+    TypeError: func is not a Python function.
+  """
+  if ismethod(func):
+    func = func.im_func
+  if not isfunction(func):
+    raise TypeError('%r is not a Python function' % (func,))
+  args, varargs, varkw = getargs(func.func_code)
+  return ArgSpec(args, varargs, varkw, func.func_defaults)
+
+
+def getfile(obj):
+  """Returns the name of the file in which obj was defined."""
+  if ismodule(obj):
+    if hasattr(obj, '__file__'):
+      return obj.__file__
+    raise TypeError('%r is a built-in module' % (obj,))
+  if isclass(obj):
+    module = sys.modules.get(obj.__module__)
+    if hasattr(module, '__file__'):
+      return module.__file__
+    raise TypeError('%r is a built-in class' % (obj,))
+  if ismethod(obj):
+    obj = obj.im_func
+  if isfunction(obj):
+    obj = obj.func_code
+  if istraceback(obj):
+    obj = obj.tb_frame
+  if isframe(obj):
+    obj = obj.f_code
+  if iscode(obj):
+    return obj.co_filename
+  raise TypeError('%r is not a module, class, method, function, traceback, '
+                  'frame, or code object' % (obj,))
+
+
+def getsourcefile(obj):
+  """Returns the source file in which obj was defined or None if not found."""
+  filename = getfile(obj)
+  if os.path.exists(filename):
+    return filename
+  return None
+
+
+def getsourcelines(obj):
+  """Returns the source lines for obj and the line number where they begin.
+
+  Args:
+    obj: A module, class, method, function, traceback, frame or code object.
+
+  Returns:
+    A (lines, lnum) tuple where lines is the list of lines, including line
+    terminators, that make up the definition of obj and lnum is the line number
+    of the first of them. lnum is 0 for modules.
+
+  Raises:
+    IOError: The source code could not be retrieved.
+  """
+  lines, lnum = findsource(obj)
+  if ismodule(obj):
+    return lines, 0
+  return getblock(lines[lnum:]), lnum + 1
+
+
+def getsource(obj):
+  """Returns the text of the source code for obj as a single string."""
+  lines, _ = getsourcelines(obj)
+  return ''.join(lines)
+
+
+def findsource(obj):
+  """Returns all lines of the file defining obj and the index of its first."""
+  filename = getsourcefile(obj)
+  if not filename:
+    raise IOError('source code not available')
+  with open(filename) as f:
+    lines = f.readlines()
+  if ismodule(obj):
+    return lines, 0
+  if isclass(obj):
+    pat = re.compile(r'^\s*class\s*' + obj.__name__ + r'\b')
+    for i, line in enumerate(lines):
+      if pat.match(line):
+        return lines, i
+    raise IOError('could not find class definition')
+  if ismethod(obj):
+    obj = obj.im_func
+  if isfunction(obj):
+    obj = obj.func_code
+  if istraceback(obj):
+    obj = obj.tb_frame
+  if isframe(obj):
+    obj = obj.f_code
+  lnum = obj.co_firstlineno - 1
+  if lnum < 0 or lnum >= len(lines):
+    raise IOError('could not find function definition')
+  return lines, lnum
+
+
+def getblock(lines):
+  """Returns the leading lines of lines that make up a def or class block."""
+  started = islambda = passline = False
+  indent = 0
+  last = 1
+  tokens = tokenize.generate_tokens(iter(lines).next)
+  try:
+    for tok_type, token, (srow, _), _, _ in tokens:
+      if not started:
+        # Skip decorators until the def, class or lambda keyword is found.
+        if token in ('def', 'class', 'lambda'):
+          islambda = token == 'lambda'
+          started = True
+        passline = True
+      elif tok_type == tokenize.NEWLINE:
+        passline = False
+        last = srow
+        if islambda:
+          break
+      elif passline:
+        pass
+      elif tok_type == tokenize.INDENT:
+        indent += 1
+        passline = True
+      elif tok_type == tokenize.DEDENT:
+        indent -= 1
+        if indent <= 0:
+          break
+      elif indent == 0:
+        if tok_type not in (tokenize.COMMENT, tokenize.NL):
+          break
+        last = srow
+  except (tokenize.TokenError, IndentationError):
+    pass
+  return lines[:last]
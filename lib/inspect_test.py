@@ -0,0 +1,117 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import inspect
+import os
+import sys
+
+import weetest
+
+
+def Decorate(f):
+  return f
+
+
+def Foo(a, b=1, *args, **kwargs):
+  return a, b, args, kwargs
+
+
+@Decorate
+def Bar():
+  # A comment.
+  return lambda x: x
+
+
+class Qux(object):
+
+  attr = 'quux'
+
+  def Method(self, c, d='d'):
+    """Indented method."""
+    return c, d
+
+
+def TestPredicates():
+  assert inspect.isfunction(Foo)
+  assert inspect.isfunction(Bar())
+  assert not inspect.isfunction(len)
+  assert inspect.isbuiltin(len)
+  assert inspect.isclass(Qux)
+  assert not inspect.isclass(Qux())
+  assert inspect.ismethod(Qux.Method)
+  assert inspect.ismethod(Qux().Method)
+  assert not inspect.ismethod(Foo)
+  assert not inspect.ismethod([].append)
+  assert inspect.isbuiltin([].append)
+  assert inspect.ismodule(inspect)
+  assert inspect.iscode(Foo.func_code)
+  assert inspect.isroutine(len) and inspect.isroutine(Qux.Method)
+
+
+def TestGetMembers():
+  members = inspect.getmembers(Qux)
+  assert [k for k, _ in members] == sorted(dir(Qux))
+  assert ('attr', 'quux') in members
+  methods = inspect.getmembers(Qux, inspect.ismethod)
+  assert [k for k, _ in methods] == ['Method']
+
+
+def TestGetArgSpec():
+  assert inspect.getargspec(Foo) == (['a', 'b'], 'args', 'kwargs', (1,))
+  assert inspect.getargspec(Bar) == ([], None, None, None)
+  assert inspect.getargspec(Bar()) == (['x'], None, None, None)
+  spec = inspect.getargspec(Qux().Method)
+  assert spec.args == ['self', 'c', 'd']
+  assert spec.defaults == ('d',)
+
+
+def TestGetArgSpecBuiltin():
+  try:
+    inspect.getargspec(len)
+  except TypeError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestGetSourceFile():
+  filename = sys.modules[__name__].__file__
+  assert inspect.getsourcefile(Foo) == filename
+  assert inspect.getsourcefile(Qux.Method) == filename
+  assert inspect.getsourcefile(Qux) == filename
+  assert os.path.basename(inspect.getsourcefile(inspect)) == 'inspect.py'
+
+
+def TestGetSourceLines():
+  lines, lnum = inspect.getsourcelines(Foo)
+  assert lines == ['def Foo(a, b=1, *args, **kwargs):\n',
+                   '  return a, b, args, kwargs\n']
+  assert lnum == Foo.func_code.co_firstlineno
+  lines, lnum = inspect.getsourcelines(Bar)
+  assert lines[0] == '@Decorate\n'
+  assert lines[-1] == '  return lambda x: x\n'
+  assert len(lines) == 4
+  assert inspect.getsource(Bar()) == '  return lambda x: x\n'
+  lines, _ = inspect.getsourcelines(Qux.Method)
+  assert lines[0] == '  def Method(self, c, d=\'d\'):\n'
+  assert lines[-1] == '    return c, d\n'
+  lines, _ = inspect.getsourcelines(Qux)
+  assert lines[0] == 'class Qux(object):\n'
+  assert lines[-1] == '    return c, d\n'
+  lines, lnum = inspect.getsourcelines(sys.modules[__name__])
+  assert lnum == 0 and lines[-1] == '  weetest.RunTests()\n'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,353 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""The io module provides the Python interfaces to stream handling.
+
+Grumpy does not implement the layered io stack of Python 3. open() returns a
+builtin file object in binary mode and a TextIOWrapper around one in text
+mode, so that code written against io.open() works unchanged.
+"""
+
+# pylint: disable=redefined-builtin,unused-argument
+
+import __builtin__
+import codecs
+import os
+import StringIO as _StringIO
+
+__all__ = ['BytesIO', 'DEFAULT_BUFFER_SIZE', 'SEEK_CUR', 'SEEK_END',
+           'SEEK_SET', 'StringIO', 'TextIOWrapper', 'open']
+
+DEFAULT_BUFFER_SIZE = 8 * 1024
+
+SEEK_SET = 0
+SEEK_CUR = 1
+SEEK_END = 2
+
+BytesIO = _StringIO.StringIO
+
+_DEFAULT_ENCODING = 'utf-8'
+
+
+def _check_newline(newline):
+  if newline not in (None, '', '\n', '\r', '\r\n'):
+    raise ValueError('illegal newline value: %s' % (newline,))
+
+
+def _translate_newlines(text):
+  return text.replace(u'\r\n', u'\n').replace(u'\r', u'\n')
+
+
+def _line_end(text, newline):
+  """Returns the index just past the first line ending in text or -1.
+
+  newline selects the line endings as for open(). In universal newlines mode
+  (newline is '') a trailing '\r' is not counted since it may be followed by
+  '\n' once more text is available.
+  """
+  if newline != '':
+    term = newline or u'\n'
+    i = text.find(term)
+    return i + len(term) if i >= 0 else -1
+  i = text.find(u'\n')
+  j = text.find(u'\r', 0, i if i >= 0 else len(text))
+  if j < 0:
+    return i + 1 if i >= 0 else -1
+  if j + 1 == len(text):
+    return -1
+  return j + 2 if text[j + 1] == u'\n' else j + 1
+
+
+class _TextIOBase(object):
+  """Line reading and iteration shared by the text streams."""
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.close()
+
+  def __iter__(self):
+    return self
+
+  def next(self):
+    line = self.readline()
+    if not line:
+      raise StopIteration
+    return line
+
+  def readlines(self, hint=-1):
+    lines = []
+    size = 0
+    for line in self:
+      lines.append(line)
+      size += len(line)
+      if 0 < hint <= size:
+        break
+    return lines
+
+  def writelines(self, lines):
+    for line in lines:
+      self.write(line)
+
+  def _check_unicode(self, s):
+    if not isinstance(s, unicode):
+      raise TypeError('write() argument 1 must be unicode, not %s' %
+                      type(s).__name__)
+
+  def _check_open(self):
+    if self.closed:
+      raise ValueError('I/O operation on closed file.')
+
+
+class TextIOWrapper(_TextIOBase):
+  """A text stream over a binary file.
+
+  Reads decode the bytes of buffer and writes encode text to it, translating
+  newlines as selected by newline. Grumpy's codecs aren't incremental, so
+  ASCII compatible encodings are decoded a line at a time and others, such as
+  utf-16, a whole file at a time.
+  """
+
+  def __init__(self, buffer, encoding=None, errors=None, newline=None):
+    _check_newline(newline)
+    self.buffer = buffer
+    self.encoding = encoding or _DEFAULT_ENCODING
+    self.errors = errors or 'strict'
+    self.mode = getattr(buffer, 'mode', None)
+    codecs.lookup(self.encoding)
+    self._newline = newline
+    # Encoding nothing yields the byte order mark that the codec writes at
+    # the start of a stream, which must not be repeated for later writes.
+    self._bom = codecs.encode(u'', self.encoding, self.errors)
+    self._by_line = (not self._bom and
+                     codecs.encode(u'\n', self.encoding, self.errors) == '\n')
+    self.closed = False
+    self._wrote = False
+    self._decoded = u''
+    self._eof = False
+
+  def __repr__(self):
+    return '<io.TextIOWrapper name=%r encoding=%r>' % (self.name,
+                                                       self.encoding)
+
+  @property
+  def name(self):
+    return self.buffer.name
+
+  def close(self):
+    self.buffer.close()
+    self.closed = True
+
+  def fileno(self):
+    return self.buffer.fileno()
+
+  def flush(self):
+    self.buffer.flush()
+
+  def isatty(self):
+    return self.buffer.isatty()
+
+  def read(self, size=-1):
+    self._check_open()
+    if size is None or size < 0:
+      while not self._eof:
+        self._read_chunk()
+      size = len(self._decoded)
+    else:
+      while len(self._decoded) < size and not self._eof:
+        self._read_chunk()
+    result = self._decoded[:size]
+    self._decoded = self._decoded[size:]
+    return result
+
+  def readline(self, size=-1):
+    self._check_open()
+    if size is None:
+      size = -1
+    while True:
+      end = _line_end(self._decoded, self._newline)
+      if end >= 0 or self._eof or 0 <= size <= len(self._decoded):
+        break
+      self._read_chunk()
+    if end < 0:
+      end = len(self._decoded)
+    if 0 <= size < end:
+      end = size
+    line = self._decoded[:end]
+    self._decoded = self._decoded[end:]
+    return line
+
+  def seek(self, offset, whence=SEEK_SET):
+    self._check_open()
+    self.buffer.seek(offset, whence)
+    self._decoded = u''
+    self._eof = False
+    return self.buffer.tell()
+
+  def tell(self):
+    self._check_open()
+    if self._decoded:
+      raise IOError('telling position disabled by buffered text')
+    return self.buffer.tell()
+
+  def write(self, s):
+    self._check_open()
+    self._check_unicode(s)
+    newline = os.linesep if self._newline is None else self._newline
+    text = s.replace(u'\n', newline) if newline not in ('', '\n') else s
+    data = codecs.encode(text, self.encoding, self.errors)
+    if self._wrote and self._bom and data.startswith(self._bom):
+      data = data[len(self._bom):]
+    self._wrote = True
+    self.buffer.write(data)
+    return len(s)
+
+  def _read_chunk(self):
+    """Decodes more of buffer, setting _eof once it has all been read."""
+    if self._by_line:
+      data = self.buffer.readline()
+      self._eof = not data
+    else:
+      data = self.buffer.read()
+      self._eof = True
+    text = codecs.decode(data, self.encoding, self.errors)
+    if self._newline is None:
+      text = _translate_newlines(text)
+    self._decoded += text
+
+
+class StringIO(_TextIOBase):
+  """An in-memory text stream holding unicode.
+
+  newline selects the translation of newlines in the text written as for
+  TextIOWrapper, except that with None they are written as '\n'.
+  """
+
+  def __init__(self, initial_value=u'', newline='\n'):
+    _check_newline(newline)
+    if initial_value is None:
+      initial_value = u''
+    if not isinstance(initial_value, unicode):
+      raise TypeError('initial_value must be unicode or None, not %s' %
+                      type(initial_value).__name__)
+    self._newline = newline
+    self._value = u''
+    self._pos = 0
+    self.closed = False
+    self.write(initial_value)
+    self._pos = 0
+
+  def close(self):
+    self.closed = True
+
+  def flush(self):
+    self._check_open()
+
+  def getvalue(self):
+    self._check_open()
+    return self._value
+
+  def isatty(self):
+    self._check_open()
+    return False
+
+  def read(self, size=-1):
+    self._check_open()
+    start = self._pos
+    if size is None or size < 0:
+      self._pos = len(self._value)
+    else:
+      self._pos = min(start + size, len(self._value))
+    return self._value[start:self._pos]
+
+  def readline(self, size=-1):
+    self._check_open()
+    rest = self._value[self._pos:]
+    end = _line_end(rest, self._newline)
+    if end < 0:
+      end = len(rest)
+    if size is not None and 0 <= size < end:
+      end = size
+    self._pos += end
+    return rest[:end]
+
+  def seek(self, pos, whence=SEEK_SET):
+    self._check_open()
+    if whence == SEEK_CUR:
+      pos += self._pos
+    elif whence == SEEK_END:
+      pos += len(self._value)
+    if pos < 0:
+      raise ValueError('Negative seek position %d' % pos)
+    self._pos = pos
+    return pos
+
+  def tell(self):
+    self._check_open()
+    return self._pos
+
+  def truncate(self, size=None):
+    self._check_open()
+    if size is None:
+      size = self._pos
+    if size < 0:
+      raise ValueError('Negative size value %d' % size)
+    self._value = self._value[:size]
+    return size
+
+  def write(self, s):
+    self._check_open()
+    self._check_unicode(s)
+    text = s
+    if self._newline is None:
+      text = _translate_newlines(text)
+    elif self._newline not in ('', '\n'):
+      text = text.replace(u'\n', self._newline)
+    value = self._value
+    if self._pos > len(value):
+      value += u'\0' * (self._pos - len(value))
+    self._value = value[:self._pos] + text + value[self._pos + len(text):]
+    self._pos += len(text)
+    return len(s)
+
+
+def open(file, mode='r', buffering=-1, encoding=None, errors=None,
+         newline=None, closefd=True):
+  """Opens file and returns a corresponding file object."""
+  if not isinstance(file, basestring):
+    raise TypeError('invalid file: %r' % (file,))
+  if not isinstance(mode, basestring):
+    raise TypeError('invalid mode: %r' % (mode,))
+  if not closefd:
+    raise ValueError('Cannot use closefd=False with file name')
+  if 'b' in mode:
+    if 't' in mode:
+      raise ValueError("can't have text and binary mode at once")
+    for value, name in ((encoding, 'an encoding'), (errors, 'an errors'),
+                        (newline, 'a newline')):
+      if value is not None:
+        raise ValueError("binary mode doesn't take %s argument" % name)
+    return __builtin__.open(file, mode, buffering)
+  if 'U' in mode and ('w' in mode or 'a' in mode or '+' in mode):
+    raise ValueError("can't use U and writing mode at once")
+  _check_newline(newline)
+  codecs.lookup(encoding or _DEFAULT_ENCODING)
+  raw_mode = mode.replace('t', '').replace('U', '')
+  if not ('r' in raw_mode or 'w' in raw_mode or 'a' in raw_mode):
+    raw_mode = 'r' + raw_mode
+  text = TextIOWrapper(__builtin__.open(file, raw_mode + 'b', buffering),
+                       encoding, errors, newline)
+  text.mode = mode
+  return text
@@ -0,0 +1,163 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import io
+import os
+import tempfile
+
+import weetest
+
+
+def _TempPath():
+  fd, path = tempfile.mkstemp()
+  os.close(fd)
+  return path
+
+
+def _ReadBytes(path):
+  with io.open(path, 'rb') as f:
+    return f.read()
+
+
+def TestOpenReadWrite():
+  path = _TempPath()
+  try:
+    f = io.open(path, 'wt', encoding='utf-8', newline=None)
+    f.write(u'foo\nbar\n')
+    f.close()
+    f = io.open(path, mode='rb')
+    assert isinstance(f, file)
+    assert f.read() == 'foo\nbar\n'
+    f.close()
+    f = io.open(path)
+    assert isinstance(f, io.TextIOWrapper)
+    assert f.mode == 'r'
+    assert f.name == path
+    assert list(f) == [u'foo\n', u'bar\n']
+    assert isinstance(f.read(), unicode)
+    f.close()
+    assert f.closed
+  finally:
+    os.remove(path)
+
+
+def TestOpenEncoding():
+  path = _TempPath()
+  try:
+    with io.open(path, 'w', encoding='utf-8') as f:
+      f.write(u'\xe9t\xe9\n')
+      try:
+        f.write('abc')
+      except TypeError:
+        pass
+      else:
+        raise AssertionError('writing str should raise')
+    assert _ReadBytes(path) == '\xc3\xa9t\xc3\xa9\n'
+    with io.open(path, encoding='utf-8') as f:
+      assert f.read(2) == u'\xe9t'
+      assert f.read() == u'\xe9\n'
+    with io.open(path, encoding='ascii', errors='replace') as f:
+      assert f.read() == u'\ufffd\ufffdt\ufffd\ufffd\n'
+    with io.open(path, encoding='ascii') as f:
+      try:
+        f.read()
+      except UnicodeDecodeError:
+        pass
+      else:
+        raise AssertionError('decoding should fail')
+    with io.open(path, 'w', encoding='utf-16') as f:
+      f.write(u'a\n')
+      f.write(u'\xe9')
+    assert _ReadBytes(path) == '\xff\xfea\x00\n\x00\xe9\x00'
+    with io.open(path, encoding='utf-16') as f:
+      assert f.readlines() == [u'a\n', u'\xe9']
+  finally:
+    os.remove(path)
+
+
+def TestOpenNewline():
+  path = _TempPath()
+  try:
+    with io.open(path, 'wb') as f:
+      f.write('a\r\nb\rc\nd')
+    for newline, want in [(None, [u'a\n', u'b\n', u'c\n', u'd']),
+                          ('', [u'a\r\n', u'b\r', u'c\n', u'd']),
+                          ('\n', [u'a\r\n', u'b\rc\n', u'd']),
+                          ('\r', [u'a\r', u'\nb\r', u'c\nd']),
+                          ('\r\n', [u'a\r\n', u'b\rc\nd'])]:
+      with io.open(path, newline=newline) as f:
+        got = f.readlines()
+      assert got == want, (newline, got)
+    for newline, want in [(None, 'a\nb'), ('', 'a\nb'), ('\n', 'a\nb'),
+                          ('\r', 'a\rb'), ('\r\n', 'a\r\nb')]:
+      with io.open(path, 'w', newline=newline) as f:
+        f.write(u'a\nb')
+      assert _ReadBytes(path) == want, (newline, _ReadBytes(path))
+  finally:
+    os.remove(path)
+
+
+def TestOpenInvalidArgs():
+  for args, kwargs, exc in [((123,), {}, TypeError),
+                            (('foo', 42), {}, TypeError),
+                            (('foo',), {'closefd': False}, ValueError),
+                            (('foo', 'rb'), {'encoding': 'utf-8'}, ValueError),
+                            (('foo', 'rb'), {'errors': 'strict'}, ValueError),
+                            (('foo', 'rb'), {'newline': ''}, ValueError),
+                            (('foo', 'rtb'), {}, ValueError),
+                            (('foo', 'Uw'), {}, ValueError),
+                            (('foo',), {'newline': 'x'}, ValueError),
+                            (('foo',), {'encoding': 'nope'}, LookupError)]:
+    try:
+      io.open(*args, **kwargs)
+    except exc:
+      pass
+    else:
+      raise AssertionError('%r %r should raise' % (args, kwargs))
+
+
+def TestStringIO():
+  s = io.StringIO()
+  s.write(u'abc')
+  s.seek(io.SEEK_SET)
+  assert s.read() == u'abc'
+  assert isinstance(io.StringIO().read(), unicode)
+  assert io.StringIO(u'a\nb').readline() == u'a\n'
+  assert list(io.StringIO(u'a\nb')) == [u'a\n', u'b']
+  assert io.StringIO(u'a\r\nb\rc', newline=None).getvalue() == u'a\nb\nc'
+  assert io.StringIO(u'a\nb', newline='\r\n').getvalue() == u'a\r\nb'
+  assert io.StringIO(u'a\r\nb', newline='').readline() == u'a\r\n'
+  s = io.StringIO(u'abcdef')
+  s.seek(2)
+  s.write(u'X')
+  assert s.getvalue() == u'abXdef'
+  assert s.read() == u'def'
+  for args, exc in [(('abc',), TypeError), ((u'', 'x'), ValueError)]:
+    try:
+      io.StringIO(*args)
+    except exc:
+      pass
+    else:
+      raise AssertionError('%r should raise' % (args,))
+  try:
+    io.StringIO().write('abc')
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('writing str should raise')
+  assert io.BytesIO('xyz').read(2) == 'xy'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
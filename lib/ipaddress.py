@@ -0,0 +1,675 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""IPv4 and IPv6 address, interface and network classes.
+
+This follows the API of the ipaddress module from the Python 3 standard
+library. Address strings are parsed with Go's net.ParseIP.
+"""
+
+from '__go__/net' import ParseIP as _ParseIP
+
+
+IPV4LENGTH = 32
+IPV6LENGTH = 128
+
+
+class AddressValueError(ValueError):
+  """A value error related to the address."""
+
+
+class NetmaskValueError(ValueError):
+  """A value error related to the netmask."""
+
+
+def ip_address(address):
+  """Returns an IPv4Address or IPv6Address depending on the input."""
+  try:
+    return IPv4Address(address)
+  except (AddressValueError, NetmaskValueError):
+    pass
+  try:
+    return IPv6Address(address)
+  except (AddressValueError, NetmaskValueError):
+    pass
+  raise ValueError('%r does not appear to be an IPv4 or IPv6 address' %
+                   (address,))
+
+
+def ip_network(address, strict=True):
+  """Returns an IPv4Network or IPv6Network depending on the input."""
+  try:
+    return IPv4Network(address, strict)
+  except (AddressValueError, NetmaskValueError):
+    pass
+  try:
+    return IPv6Network(address, strict)
+  except (AddressValueError, NetmaskValueError):
+    pass
+  raise ValueError('%r does not appear to be an IPv4 or IPv6 network' %
+                   (address,))
+
+
+def ip_interface(address):
+  """Returns an IPv4Interface or IPv6Interface depending on the input."""
+  try:
+    return IPv4Interface(address)
+  except (AddressValueError, NetmaskValueError):
+    pass
+  try:
+    return IPv6Interface(address)
+  except (AddressValueError, NetmaskValueError):
+    pass
+  raise ValueError('%r does not appear to be an IPv4 or IPv6 interface' %
+                   (address,))
+
+
+def _parse_ip(s, version):
+  if not isinstance(s, basestring):
+    raise AddressValueError('Expected a string, got %r' % (s,))
+  if isinstance(s, unicode):
+    s = s.encode('ascii', 'replace')
+  if version == 4:
+    ok = ':' not in s
+  else:
+    # Go accepts IPv4 addresses in IPv6 form, e.g. ::ffff:1.2.3.4, so only
+    # strings with a colon are parsed as IPv6.
+    ok = ':' in s
+  ip = _ParseIP(s) if ok else None
+  if not ip:
+    raise AddressValueError('%r does not appear to be an IPv%d address' %
+                            (s, version))
+  if version == 4:
+    ip = ip.To4()
+  n = 0
+  for b in ip:
+    n = (n << 8) | b
+  return n
+
+
+def _prefix_from_mask(mask, max_prefixlen):
+  host_bits = 0
+  while host_bits < max_prefixlen and not mask & (1 << host_bits):
+    host_bits += 1
+  prefixlen = max_prefixlen - host_bits
+  if mask != _mask_from_prefix(prefixlen, max_prefixlen):
+    return None
+  return prefixlen
+
+
+def _mask_from_prefix(prefixlen, max_prefixlen):
+  all_ones = (1 << max_prefixlen) - 1
+  return all_ones ^ (all_ones >> prefixlen)
+
+
+def _format_ipv6(n):
+  """Formats n in the RFC 5952 canonical form."""
+  hextets = ['%x' % ((n >> (112 - 16 * i)) & 0xffff) for i in range(8)]
+  best_start, best_len = -1, 0
+  start, length = -1, 0
+  for i, h in enumerate(hextets):
+    if h == '0':
+      if start < 0:
+        start = i
+      length += 1
+      if length > best_len:
+        best_start, best_len = start, length
+    else:
+      start, length = -1, 0
+  if best_len > 1:
+    head = ':'.join(hextets[:best_start])
+    tail = ':'.join(hextets[best_start + best_len:])
+    return head + '::' + tail
+  return ':'.join(hextets)
+
+
+class _IPAddressBase(object):
+  """Behavior common to addresses, interfaces and networks."""
+
+  @property
+  def exploded(self):
+    return self._explode()
+
+  @property
+  def compressed(self):
+    return str(self)
+
+  @property
+  def max_prefixlen(self):
+    return self._max_prefixlen
+
+  def __repr__(self):
+    return '%s(%r)' % (type(self).__name__, str(self))
+
+  def _check_version(self, other):
+    if self.version != other.version:
+      raise TypeError('%s and %s are not of the same version' %
+                      (self, other))
+
+
+class _BaseAddress(_IPAddressBase):
+  """Behavior common to IPv4Address and IPv6Address."""
+
+  def __init__(self, address):
+    if isinstance(address, (int, long)):
+      if address < 0 or address >= 1 << self._max_prefixlen:
+        raise AddressValueError('%d is out of range for IPv%d' %
+                                (address, self.version))
+      self._ip = address
+    elif isinstance(address, _BaseAddress):
+      self._check_version(address)
+      self._ip = address._ip  # pylint: disable=protected-access
+    else:
+      if '/' in address:
+        raise AddressValueError("Unexpected '/' in %r" % address)
+      self._ip = _parse_ip(address, self.version)
+
+  def __int__(self):
+    return self._ip
+
+  def __long__(self):
+    return long(self._ip)
+
+  def __hash__(self):
+    return hash(self._ip)
+
+  def __eq__(self, other):
+    if not isinstance(other, _BaseAddress):
+      return NotImplemented
+    return self.version == other.version and self._ip == other._ip  # pylint: disable=protected-access
+
+  def __ne__(self, other):
+    result = self.__eq__(other)
+    if result is NotImplemented:
+      return result
+    return not result
+
+  def __lt__(self, other):
+    if not isinstance(other, _BaseAddress):
+      return NotImplemented
+    self._check_version(other)
+    return self._ip < other._ip  # pylint: disable=protected-access
+
+  def __le__(self, other):
+    if not isinstance(other, _BaseAddress):
+      return NotImplemented
+    self._check_version(other)
+    return self._ip <= other._ip  # pylint: disable=protected-access
+
+  def __gt__(self, other):
+    if not isinstance(other, _BaseAddress):
+      return NotImplemented
+    self._check_version(other)
+    return self._ip > other._ip  # pylint: disable=protected-access
+
+  def __ge__(self, other):
+    if not isinstance(other, _BaseAddress):
+      return NotImplemented
+    self._check_version(other)
+    return self._ip >= other._ip  # pylint: disable=protected-access
+
+  def __add__(self, other):
+    if not isinstance(other, (int, long)):
+      return NotImplemented
+    return type(self)(self._ip + other)
+
+  def __sub__(self, other):
+    if not isinstance(other, (int, long)):
+      return NotImplemented
+    return type(self)(self._ip - other)
+
+  @property
+  def packed(self):
+    n = self._max_prefixlen // 8
+    return ''.join(chr((self._ip >> (8 * (n - i - 1))) & 0xff)
+                   for i in range(n))
+
+  @property
+  def is_unspecified(self):
+    return self._ip == 0
+
+  @property
+  def is_multicast(self):
+    return self in self._multicast_network()
+
+  @property
+  def is_loopback(self):
+    return self in self._loopback_network()
+
+  @property
+  def is_link_local(self):
+    return self in self._link_local_network()
+
+  @property
+  def is_private(self):
+    return any(self in net for net in self._private_networks())
+
+  @property
+  def is_global(self):
+    return not self.is_private
+
+
+class IPv4Address(_BaseAddress):
+  """An IPv4 address, e.g. IPv4Address('192.0.2.1')."""
+
+  version = 4
+  _max_prefixlen = IPV4LENGTH
+
+  def __str__(self):
+    return '%d.%d.%d.%d' % ((self._ip >> 24) & 0xff, (self._ip >> 16) & 0xff,
+                            (self._ip >> 8) & 0xff, self._ip & 0xff)
+
+  def _explode(self):
+    return str(self)
+
+  @staticmethod
+  def _multicast_network():
+    return _IPV4_MULTICAST
+
+  @staticmethod
+  def _loopback_network():
+    return _IPV4_LOOPBACK
+
+  @staticmethod
+  def _link_local_network():
+    return _IPV4_LINK_LOCAL
+
+  @staticmethod
+  def _private_networks():
+    return _IPV4_PRIVATE
+
+
+class IPv6Address(_BaseAddress):
+  """An IPv6 address, e.g. IPv6Address('2001:db8::1')."""
+
+  version = 6
+  _max_prefixlen = IPV6LENGTH
+
+  def __str__(self):
+    return _format_ipv6(self._ip)
+
+  def _explode(self):
+    return ':'.join('%04x' % ((self._ip >> (112 - 16 * i)) & 0xffff)
+                    for i in range(8))
+
+  @property
+  def ipv4_mapped(self):
+    """The IPv4 address for ::ffff:0:0/96 addresses, otherwise None."""
+    if (self._ip >> 32) != 0xffff:
+      return None
+    return IPv4Address(self._ip & 0xffffffff)
+
+  @staticmethod
+  def _multicast_network():
+    return _IPV6_MULTICAST
+
+  @staticmethod
+  def _loopback_network():
+    return _IPV6_LOOPBACK
+
+  @staticmethod
+  def _link_local_network():
+    return _IPV6_LINK_LOCAL
+
+  @staticmethod
+  def _private_networks():
+    return _IPV6_PRIVATE
+
+
+def _split_network(cls, address):
+  """Returns the address and prefix length of a network or interface."""
+  if isinstance(address, tuple):
+    if len(address) == 1:
+      addr, mask = address[0], cls._max_prefixlen  # pylint: disable=protected-access
+    else:
+      addr, mask = address
+  elif isinstance(address, (int, long, _BaseAddress)):
+    addr, mask = address, cls._max_prefixlen  # pylint: disable=protected-access
+  else:
+    parts = address.split('/')
+    if len(parts) > 2:
+      raise AddressValueError("Only one '/' permitted in %r" % address)
+    addr = parts[0]
+    mask = parts[1] if len(parts) == 2 else cls._max_prefixlen  # pylint: disable=protected-access
+  addr = cls._address_class(addr)  # pylint: disable=protected-access
+  return addr, _parse_prefix(cls, mask)
+
+
+def _parse_prefix(cls, mask):
+  max_prefixlen = cls._max_prefixlen  # pylint: disable=protected-access
+  if isinstance(mask, (int, long)):
+    prefixlen = mask
+  elif mask.isdigit():
+    prefixlen = int(mask)
+  else:
+    prefixlen = None
+    if cls.version == 4 and '.' in mask:
+      try:
+        prefixlen = _prefix_from_mask(_parse_ip(mask, 4), max_prefixlen)
+      except AddressValueError:
+        pass
+    if prefixlen is None:
+      raise NetmaskValueError('%r is not a valid netmask' % mask)
+  if prefixlen < 0 or prefixlen > max_prefixlen:
+    raise NetmaskValueError('%r is not a valid netmask' % mask)
+  return prefixlen
+
+
+class _BaseNetwork(_IPAddressBase):
+  """Behavior common to IPv4Network and IPv6Network."""
+
+  def __init__(self, address, strict=True):
+    addr, self._prefixlen = _split_network(type(self), address)
+    mask = _mask_from_prefix(self._prefixlen, self._max_prefixlen)
+    if addr._ip & ~mask:
+      if strict:
+        raise ValueError('%s has host bits set' % (address,))
+    self.network_address = self._address_class(addr._ip & mask)
+    self.netmask = self._address_class(mask)
+
+  def __str__(self):
+    return '%s/%d' % (self.network_address, self._prefixlen)
+
+  def _explode(self):
+    return '%s/%d' % (self.network_address.exploded, self._prefixlen)
+
+  def __hash__(self):
+    return hash((self.network_address._ip, self.netmask._ip))
+
+  def __eq__(self, other):
+    if not isinstance(other, _BaseNetwork):
+      return NotImplemented
+    return (self.version == other.version and
+            self.network_address == other.network_address and
+            self._prefixlen == other._prefixlen)  # pylint: disable=protected-access
+
+  def __ne__(self, other):
+    result = self.__eq__(other)
+    if result is NotImplemented:
+      return result
+    return not result
+
+  def _key(self):
+    return (self.version, self.network_address._ip, self._prefixlen)
+
+  def __lt__(self, other):
+    if not isinstance(other, _BaseNetwork):
+      return NotImplemented
+    self._check_version(other)
+    return self._key() < other._key()  # pylint: disable=protected-access
+
+  def __le__(self, other):
+    if not isinstance(other, _BaseNetwork):
+      return NotImplemented
+    self._check_version(other)
+    return self._key() <= other._key()  # pylint: disable=protected-access
+
+  def __gt__(self, other):
+    if not isinstance(other, _BaseNetwork):
+      return NotImplemented
+    self._check_version(other)
+    return self._key() > other._key()  # pylint: disable=protected-access
+
+  def __ge__(self, other):
+    if not isinstance(other, _BaseNetwork):
+      return NotImplemented
+    self._check_version(other)
+    return self._key() >= other._key()  # pylint: disable=protected-access
+
+  def __contains__(self, other):
+    if not isinstance(other, _BaseAddress) or self.version != other.version:
+      return False
+    return other._ip & self.netmask._ip == self.network_address._ip
+
+  def __iter__(self):
+    return self._range(self.network_address._ip, self.broadcast_address._ip)
+
+  def __getitem__(self, n):
+    if n < 0:
+      n += self.num_addresses
+    if n < 0 or n >= self.num_addresses:
+      raise IndexError('address out of range')
+    return self._address_class(self.network_address._ip + n)
+
+  def _range(self, first, last):
+    # IPv6 addresses don't fit in an int so xrange() can't be used.
+    while first <= last:
+      yield self._address_class(first)
+      first += 1
+
+  @property
+  def prefixlen(self):
+    return self._prefixlen
+
+  @property
+  def hostmask(self):
+    return self._address_class(self.netmask._ip ^
+                               ((1 << self._max_prefixlen) - 1))
+
+  @property
+  def broadcast_address(self):
+    return self._address_class(self.network_address._ip | self.hostmask._ip)
+
+  @property
+  def num_addresses(self):
+    return 1 << (self._max_prefixlen - self._prefixlen)
+
+  @property
+  def with_prefixlen(self):
+    return '%s/%d' % (self.network_address, self._prefixlen)
+
+  @property
+  def with_netmask(self):
+    return '%s/%s' % (self.network_address, self.netmask)
+
+  @property
+  def with_hostmask(self):
+    return '%s/%s' % (self.network_address, self.hostmask)
+
+  @property
+  def is_multicast(self):
+    return (self.network_address.is_multicast and
+            self.broadcast_address.is_multicast)
+
+  @property
+  def is_loopback(self):
+    return (self.network_address.is_loopback and
+            self.broadcast_address.is_loopback)
+
+  @property
+  def is_link_local(self):
+    return (self.network_address.is_link_local and
+            self.broadcast_address.is_link_local)
+
+  @property
+  def is_private(self):
+    return (self.network_address.is_private and
+            self.broadcast_address.is_private)
+
+  def hosts(self):
+    """Generates the usable hosts in the network.
+
+    For IPv4 the network and broadcast addresses are excluded and for IPv6
+    the Subnet-Router anycast address is, except for networks too small to
+    have any other hosts.
+    """
+    first = self.network_address._ip
+    last = self.broadcast_address._ip
+    if last - first > 1:
+      first += 1
+      if self.version == 4:
+        last -= 1
+    return self._range(first, last)
+
+  def overlaps(self, other):
+    """Tells if self is partly contained in other or vice versa."""
+    return (self.network_address in other or
+            self.broadcast_address in other or
+            other.network_address in self or
+            other.broadcast_address in self)
+
+  def subnet_of(self, other):
+    self._check_version(other)
+    return (other.network_address <= self.network_address and
+            other.broadcast_address >= self.broadcast_address)
+
+  def supernet_of(self, other):
+    return other.subnet_of(self)
+
+  def subnets(self, prefixlen_diff=1, new_prefix=None):
+    """Generates the subnets of the network with a longer prefix."""
+    if new_prefix is not None:
+      if new_prefix < self._prefixlen:
+        raise ValueError('new prefix must be longer')
+      if prefixlen_diff != 1:
+        raise ValueError('cannot set prefixlen_diff and new_prefix')
+      prefixlen_diff = new_prefix - self._prefixlen
+    if prefixlen_diff < 0:
+      raise ValueError('prefix length diff must be > 0')
+    new_prefixlen = self._prefixlen + prefixlen_diff
+    if new_prefixlen > self._max_prefixlen:
+      raise ValueError('prefix length diff %d is invalid for netblock %s' %
+                       (new_prefixlen, self))
+    step = 1 << (self._max_prefixlen - new_prefixlen)
+    first = self.network_address._ip
+    end = self.broadcast_address._ip + 1
+    while first < end:
+      yield type(self)((first, new_prefixlen))
+      first += step
+
+  def supernet(self, prefixlen_diff=1, new_prefix=None):
+    """Returns the network containing this one with a shorter prefix."""
+    if new_prefix is not None:
+      if new_prefix > self._prefixlen:
+        raise ValueError('new prefix must be shorter')
+      if prefixlen_diff != 1:
+        raise ValueError('cannot set prefixlen_diff and new_prefix')
+      prefixlen_diff = self._prefixlen - new_prefix
+    new_prefixlen = self._prefixlen - prefixlen_diff
+    if new_prefixlen < 0:
+      raise ValueError('current prefixlen is %d, cannot have a prefixlen_diff '
+                       'of %d' % (self._prefixlen, prefixlen_diff))
+    return type(self)((self.network_address._ip, new_prefixlen), strict=False)
+
+
+class IPv4Network(_BaseNetwork):
+  """An IPv4 network, e.g. IPv4Network('192.0.2.0/24')."""
+
+  version = 4
+  _max_prefixlen = IPV4LENGTH
+  _address_class = IPv4Address
+
+
+class IPv6Network(_BaseNetwork):
+  """An IPv6 network, e.g. IPv6Network('2001:db8::/32')."""
+
+  version = 6
+  _max_prefixlen = IPV6LENGTH
+  _address_class = IPv6Address
+
+
+class _BaseInterface(object):
+  """Behavior common to IPv4Interface and IPv6Interface.
+
+  An interface is an address together with the network it belongs to, e.g.
+  the configuration of a network card.
+  """
+
+  def __init__(self, address):
+    addr, prefixlen = _split_network(self._network_class, address)
+    self._address_class.__init__(self, addr._ip)
+    self.network = self._network_class((addr._ip, prefixlen), strict=False)
+    self.netmask = self.network.netmask
+    self._prefixlen = prefixlen
+
+  def __str__(self):
+    return '%s/%d' % (self.ip, self._prefixlen)
+
+  def _explode(self):
+    return '%s/%d' % (self.ip.exploded, self._prefixlen)
+
+  def __eq__(self, other):
+    if not isinstance(other, _BaseInterface):
+      return NotImplemented
+    return self.ip == other.ip and self.network == other.network
+
+  def __hash__(self):
+    return hash((self._ip, self._prefixlen))
+
+  @property
+  def ip(self):
+    return self._address_class(self._ip)
+
+  @property
+  def with_prefixlen(self):
+    return str(self)
+
+  @property
+  def with_netmask(self):
+    return '%s/%s' % (self.ip, self.netmask)
+
+  @property
+  def with_hostmask(self):
+    return '%s/%s' % (self.ip, self.network.hostmask)
+
+
+class IPv4Interface(_BaseInterface, IPv4Address):
+  """An IPv4 address with a netmask, e.g. IPv4Interface('192.0.2.5/24')."""
+
+  _address_class = IPv4Address
+  _network_class = IPv4Network
+
+
+class IPv6Interface(_BaseInterface, IPv6Address):
+  """An IPv6 address with a netmask, e.g. IPv6Interface('2001:db8::1/64')."""
+
+  _address_class = IPv6Address
+  _network_class = IPv6Network
+
+
+_IPV4_LOOPBACK = IPv4Network('127.0.0.0/8')
+_IPV4_LINK_LOCAL = IPv4Network('169.254.0.0/16')
+_IPV4_MULTICAST = IPv4Network('224.0.0.0/4')
+_IPV4_PRIVATE = [
+    IPv4Network('0.0.0.0/8'),
+    IPv4Network('10.0.0.0/8'),
+    IPv4Network('127.0.0.0/8'),
+    IPv4Network('169.254.0.0/16'),
+    IPv4Network('172.16.0.0/12'),
+    IPv4Network('192.0.0.0/29'),
+    IPv4Network('192.0.0.170/31'),
+    IPv4Network('192.0.2.0/24'),
+    IPv4Network('192.168.0.0/16'),
+    IPv4Network('198.18.0.0/15'),
+    IPv4Network('198.51.100.0/24'),
+    IPv4Network('203.0.113.0/24'),
+    IPv4Network('240.0.0.0/4'),
+    IPv4Network('255.255.255.255/32'),
+]
+
+_IPV6_LOOPBACK = IPv6Network('::1/128')
+_IPV6_LINK_LOCAL = IPv6Network('fe80::/10')
+_IPV6_MULTICAST = IPv6Network('ff00::/8')
+_IPV6_PRIVATE = [
+    IPv6Network('::1/128'),
+    IPv6Network('::/128'),
+    IPv6Network('::ffff:0:0/96'),
+    IPv6Network('100::/64'),
+    IPv6Network('2001::/23'),
+    IPv6Network('2001:2::/48'),
+    IPv6Network('2001:db8::/32'),
+    IPv6Network('2001:10::/28'),
+    IPv6Network('fc00::/7'),
+    IPv6Network('fe80::/10'),
+]
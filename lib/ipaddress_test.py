@@ -0,0 +1,187 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# pylint: disable=g-equals-none
+
+import ipaddress
+
+import weetest
+
+
+def _AssertRaises(exc_type, fn, *args):
+  try:
+    fn(*args)
+  except exc_type:
+    return
+  raise AssertionError('%s not raised' % exc_type.__name__)
+
+
+def TestIPv4Address():
+  addr = ipaddress.ip_address('192.0.2.1')
+  assert isinstance(addr, ipaddress.IPv4Address)
+  assert addr.version == 4
+  assert int(addr) == 0xc0000201
+  assert str(addr) == '192.0.2.1'
+  assert repr(addr) == "IPv4Address('192.0.2.1')"
+  assert addr.packed == '\xc0\x00\x02\x01'
+  assert addr == ipaddress.IPv4Address(0xc0000201)
+  assert addr + 1 == ipaddress.IPv4Address('192.0.2.2')
+  assert addr - 2 == ipaddress.IPv4Address('192.0.1.255')
+  assert addr < ipaddress.IPv4Address('192.0.2.10')
+  assert len(set([addr, ipaddress.IPv4Address('192.0.2.1')])) == 1
+  assert addr.is_private and not addr.is_global
+  assert ipaddress.ip_address('127.0.0.1').is_loopback
+  assert ipaddress.ip_address('224.0.0.1').is_multicast
+  assert ipaddress.ip_address('169.254.1.1').is_link_local
+  assert ipaddress.ip_address('0.0.0.0').is_unspecified
+  assert ipaddress.ip_address('8.8.8.8').is_global
+
+
+def TestIPv4AddressInvalid():
+  for s in ['', '1.2.3', '1.2.3.256', '1.2.3.4/24', '::1', 'foo']:
+    _AssertRaises(ipaddress.AddressValueError, ipaddress.IPv4Address, s)
+  _AssertRaises(ipaddress.AddressValueError, ipaddress.IPv4Address, 1 << 32)
+  _AssertRaises(ipaddress.AddressValueError, ipaddress.IPv4Address, -1)
+  _AssertRaises(ValueError, ipaddress.ip_address, 'foo')
+  _AssertRaises(ipaddress.AddressValueError, ipaddress.IPv4Address('0.0.0.0').__sub__, 1)
+
+
+def TestIPv6Address():
+  addr = ipaddress.ip_address('2001:DB8:0:0:0:0:0:1')
+  assert isinstance(addr, ipaddress.IPv6Address)
+  assert addr.version == 6
+  assert str(addr) == '2001:db8::1'
+  assert addr.exploded == '2001:0db8:0000:0000:0000:0000:0000:0001'
+  assert int(addr) == 0x20010db8000000000000000000000001
+  assert addr == ipaddress.IPv6Address(0x20010db8000000000000000000000001)
+  assert len(addr.packed) == 16
+  assert addr.is_private
+  cases = [
+      ('::', '::'),
+      ('::1', '::1'),
+      ('1::', '1::'),
+      ('1:0:0:2:0:0:0:3', '1:0:0:2::3'),
+      ('1:0:2:3:4:5:6:7', '1:0:2:3:4:5:6:7'),
+      ('fe80::1:0:0:1', 'fe80::1:0:0:1'),
+  ]
+  for s, want in cases:
+    got = str(ipaddress.IPv6Address(s))
+    assert got == want, (s, got, want)
+  assert ipaddress.ip_address('::1').is_loopback
+  assert ipaddress.ip_address('ff02::1').is_multicast
+  assert ipaddress.ip_address('fe80::1').is_link_local
+  mapped = ipaddress.ip_address('::ffff:192.0.2.1')
+  assert isinstance(mapped, ipaddress.IPv6Address)
+  assert mapped.ipv4_mapped == ipaddress.IPv4Address('192.0.2.1')
+  assert addr.ipv4_mapped is None
+  for s in ['1.2.3.4', ':::', '1::2::3', 'fe80::1%eth0']:
+    _AssertRaises(ipaddress.AddressValueError, ipaddress.IPv6Address, s)
+
+
+def TestMixedVersions():
+  v4 = ipaddress.ip_address('1.2.3.4')
+  v6 = ipaddress.ip_address('::1')
+  assert v4 != v6
+  _AssertRaises(TypeError, lambda: v4 < v6)
+  assert v6 not in ipaddress.ip_network('0.0.0.0/0')
+
+
+def TestIPv4Network():
+  net = ipaddress.ip_network('192.0.2.0/29')
+  assert isinstance(net, ipaddress.IPv4Network)
+  assert str(net) == '192.0.2.0/29'
+  assert repr(net) == "IPv4Network('192.0.2.0/29')"
+  assert net.prefixlen == 29
+  assert str(net.netmask) == '255.255.255.248'
+  assert str(net.hostmask) == '0.0.0.7'
+  assert str(net.broadcast_address) == '192.0.2.7'
+  assert net.num_addresses == 8
+  assert net.with_netmask == '192.0.2.0/255.255.255.248'
+  assert net.with_hostmask == '192.0.2.0/0.0.0.7'
+  assert ipaddress.IPv4Address('192.0.2.5') in net
+  assert ipaddress.IPv4Address('192.0.2.8') not in net
+  assert [str(a) for a in net.hosts()] == ['192.0.2.%d' % i for i in range(1, 7)]
+  assert len(list(net)) == 8
+  assert net[0] == net.network_address and net[-1] == net.broadcast_address
+  assert ipaddress.ip_network('192.0.2.0/255.255.255.0').prefixlen == 24
+  assert ipaddress.ip_network('192.0.2.1').prefixlen == 32
+  assert ipaddress.ip_network(('192.0.2.0', 24)) == ipaddress.ip_network(
+      '192.0.2.0/24')
+  assert [str(a) for a in ipaddress.ip_network('10.0.0.0/31').hosts()] == [
+      '10.0.0.0', '10.0.0.1']
+  assert net.is_private
+  assert ipaddress.ip_network('127.0.0.0/8').is_loopback
+
+
+def TestIPv4NetworkInvalid():
+  _AssertRaises(ValueError, ipaddress.ip_network, '192.0.2.1/24')
+  net = ipaddress.ip_network('192.0.2.1/24', strict=False)
+  assert str(net) == '192.0.2.0/24'
+  for s in ['192.0.2.0/33', '192.0.2.0/255.0.255.0', '192.0.2.0/foo']:
+    _AssertRaises(ipaddress.NetmaskValueError, ipaddress.IPv4Network, s)
+  _AssertRaises(ipaddress.AddressValueError, ipaddress.IPv4Network,
+                '192.0.2.0/24/1')
+
+
+def TestNetworkRelations():
+  net = ipaddress.ip_network('10.0.0.0/8')
+  subnets = list(ipaddress.ip_network('10.0.0.0/24').subnets())
+  assert [str(n) for n in subnets] == ['10.0.0.0/25', '10.0.0.128/25']
+  subnets = list(ipaddress.ip_network('10.0.0.0/24').subnets(new_prefix=26))
+  assert len(subnets) == 4 and str(subnets[-1]) == '10.0.0.192/26'
+  assert str(net.supernet()) == '10.0.0.0/7'
+  assert str(net.supernet(new_prefix=4)) == '0.0.0.0/4'
+  assert subnets[0].subnet_of(net)
+  assert net.supernet_of(subnets[0])
+  assert not net.subnet_of(subnets[0])
+  assert net.overlaps(ipaddress.ip_network('10.1.0.0/16'))
+  assert not net.overlaps(ipaddress.ip_network('11.0.0.0/16'))
+  assert sorted([net, subnets[1], subnets[0]]) == [net, subnets[0], subnets[1]]
+  _AssertRaises(ValueError, lambda: list(net.subnets(new_prefix=4)))
+
+
+def TestIPv6Network():
+  net = ipaddress.ip_network('2001:db8::/126')
+  assert isinstance(net, ipaddress.IPv6Network)
+  assert str(net.netmask) == 'ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffc'
+  assert net.num_addresses == 4
+  assert ipaddress.ip_address('2001:db8::3') in net
+  assert [str(a) for a in net.hosts()] == [
+      '2001:db8::1', '2001:db8::2', '2001:db8::3']
+  big = ipaddress.ip_network('2001:db8::/32')
+  assert big.num_addresses == 1 << 96
+  assert str(big.broadcast_address) == '2001:db8:ffff:ffff:ffff:ffff:ffff:ffff'
+  assert net.subnet_of(big)
+  hosts = big.hosts()
+  assert str(next(hosts)) == '2001:db8::1'
+
+
+def TestInterface():
+  iface = ipaddress.ip_interface('192.0.2.5/24')
+  assert isinstance(iface, ipaddress.IPv4Interface)
+  assert isinstance(iface, ipaddress.IPv4Address)
+  assert str(iface) == '192.0.2.5/24'
+  assert iface.ip == ipaddress.IPv4Address('192.0.2.5')
+  assert iface.network == ipaddress.IPv4Network('192.0.2.0/24')
+  assert iface.with_netmask == '192.0.2.5/255.255.255.0'
+  assert iface.ip in iface.network
+  iface6 = ipaddress.ip_interface('2001:db8::1/64')
+  assert isinstance(iface6, ipaddress.IPv6Interface)
+  assert str(iface6.network) == '2001:db8::/64'
+  assert iface6 == ipaddress.IPv6Interface('2001:db8::1/64')
+  assert iface6 != ipaddress.IPv6Interface('2001:db8::1/96')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -16,6 +16,21 @@ import itertools
 
 import weetest
 
+def TestCount():
+  it = itertools.count()
+  assert [next(it) for _ in range(3)] == [0, 1, 2]
+  assert repr(it) == 'count(3)'
+  it = itertools.count(1.5, -2)
+  assert [next(it) for _ in range(3)] == [1.5, -0.5, -2.5]
+  assert repr(it) == 'count(-4.5, -2)'
+  try:
+    itertools.count('a')
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('count() of a str did not raise TypeError')
+
+
 def TestCycle():
   want = []
   got = []
@@ -97,6 +112,27 @@ def TestIFilterFalse():
     got = tuple(itertools.ifilterfalse(*args))
     assert got == want, 'tuple(ifilterfalse%s) == %s, want %s' % (args, got, want)
 
+def TestIMap():
+  cases = [
+    ((lambda x: x * 2, [1, 2, 3]), (2, 4, 6)),
+    ((lambda x, y: x + y, [1, 2, 3], [10, 20]), (11, 22)),
+    ((None, 'ab', [1, 2]), (('a', 1), ('b', 2))),
+    ((lambda x: x, []), ())
+  ]
+  for args, want in cases:
+    got = tuple(itertools.imap(*args))
+    assert got == want, 'tuple(imap%s) == %s, want %s' % (args, got, want)
+
+
+def TestFusedPipeline():
+  it = itertools.imap(lambda x: x * 10, itertools.ifilter(lambda x: x % 2, xrange(6)))
+  assert list(it) == [10, 30, 50]
+  inner = itertools.ifilterfalse(None, [0, 1, 0, 2, 3])
+  outer = itertools.imap(str, inner)
+  assert next(inner) == 0
+  assert map(None, outer) == ['0']
+  assert filter(lambda x: x > 1, itertools.imap(len, ['a', 'bb', 'ccc'])) == [2, 3]
+
 
 def TestISlice():
   r = range(10)
@@ -104,12 +140,35 @@ def TestISlice():
       ((r, 5), (0, 1, 2, 3, 4)),
       ((r, 25, 30), ()),
       ((r, 1, None, 3), (1, 4, 7)),
+      ((r, None), tuple(r)),
+      ((itertools.count(), 2, 8, 2), (2, 4, 6)),
   ]
   for args, want in cases:
     got = tuple(itertools.islice(*args))
     assert got == want, 'tuple(islice%s) == %s, want %s' % (args, got, want)
 
 
+def TestISliceInvalid():
+  for args in [(-1,), (0, -1), (0, 1, 0), ('a',)]:
+    try:
+      itertools.islice([], *args)
+    except ValueError:
+      pass
+    else:
+      raise AssertionError('islice%s did not raise ValueError' % (args,))
+
+
+def TestIZip():
+  cases = [
+    ((), ()),
+    (('abc', range(2)), (('a', 0), ('b', 1))),
+    (('ab', itertools.count(5), [None] * 3), (('a', 5, None), ('b', 6, None))),
+  ]
+  for args, want in cases:
+    got = tuple(itertools.izip(*args))
+    assert got == want, 'tuple(izip%s) == %s, want %s' % (args, got, want)
+
+
 def TestIZipLongest():
   cases = [
     (('abc', range(6)), (('a', 0), ('b', 1), ('c', 2), (None, 3), (None, 4), (None, 5))),
@@ -177,6 +236,24 @@ def TestGroupBy():
     assert got == want, 'groupby %s == %s, want %s' % (args, got, want)
 
 
+def TestRepeat():
+  assert list(itertools.repeat('a', 3)) == ['a', 'a', 'a']
+  assert list(itertools.repeat('a', -1)) == []
+  assert list(itertools.islice(itertools.repeat(None), 2)) == [None, None]
+  assert repr(itertools.repeat(1, times=2)) == 'repeat(1, 2)'
+
+
+def TestStarmap():
+  cases = [
+    ((lambda x, y: x * y, [(2, 5), (3, 2)]), (10, 6)),
+    ((lambda *args: args, ['ab', [1]]), (('a', 'b'), (1,))),
+    ((lambda x: x, []), ()),
+  ]
+  for args, want in cases:
+    got = tuple(itertools.starmap(*args))
+    assert got == want, 'tuple(starmap%s) == %s, want %s' % (args, got, want)
+
+
 def TestTakewhile():
   r = range(10)
   cases = [
@@ -189,5 +266,27 @@ def TestTakewhile():
     assert got == want, 'tuple(takewhile%s) == %s, want %s' % (args, got, want)
 
 
+def TestTee():
+  a, b, c = itertools.tee(xrange(200), 3)
+  assert next(a) == 0
+  assert list(b) == range(200)
+  assert list(a) == range(1, 200)
+  d, e = itertools.tee(c, 2)
+  assert d is c
+  assert next(d) == 0
+  assert list(e) == range(200)
+  assert list(d) == range(1, 200)
+  assert itertools.tee([], 0) == ()
+
+
+def TestSubclass():
+  class Chain(itertools.chain):
+    pass
+  c = Chain('ab', 'c')
+  c.foo = 'bar'
+  assert list(c) == ['a', 'b', 'c']
+  assert list(Chain.from_iterable(['ab', 'c'])) == ['a', 'b', 'c']
+
+
 if __name__ == '__main__':
   weetest.RunTests()
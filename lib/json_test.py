@@ -0,0 +1,113 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import json
+import StringIO
+
+import weetest
+
+
+def TestLoads():
+  assert json.loads('null') is None
+  assert json.loads('[true, false, 1, 2.5, "foo"]') == [True, False, 1, 2.5,
+                                                        u'foo']
+  d = json.loads('{"a": {"b": [1, 2]}}')
+  assert d == {u'a': {u'b': [1, 2]}}, d
+  assert isinstance(d.keys()[0], unicode)
+  assert json.loads(u'"\\u00e9\\ud83d\\ude00"') == u'\xe9\U0001f600'
+  assert json.loads('12345678901234567890') == 12345678901234567890L
+
+
+def TestLoadsError():
+  for s, want in [('', 'No JSON object could be decoded'),
+                  ('[1,', 'Expecting object: line 1 column 4 (char 3)'),
+                  ('{"a" 1}', "Expecting ':' delimiter: line 1 column 6 "
+                              '(char 5)'),
+                  ('[] 1', 'Extra data: line 1 column 4 - line 1 column 5 '
+                           '(char 3 - 4)')]:
+    try:
+      json.loads(s)
+    except ValueError as e:
+      assert str(e) == want, str(e)
+    else:
+      raise AssertionError('ValueError not raised for %r' % s)
+
+
+def TestLoadsHooks():
+  pairs = json.loads('{"b": 1, "a": 2}', object_pairs_hook=tuple)
+  assert pairs == ((u'b', 1), (u'a', 2)), pairs
+  assert json.loads('[{"a": 1}]', object_hook=len) == [1]
+
+
+def TestLoad():
+  assert json.load(StringIO.StringIO('{"foo": [1]}')) == {'foo': [1]}
+
+
+def TestDumps():
+  assert json.dumps(None) == 'null'
+  assert json.dumps([True, 1, 1.5, 'foo']) == '[true, 1, 1.5, "foo"]'
+  assert json.dumps(u'\xe9') == '"\\u00e9"'
+  assert json.dumps(u'\xe9', ensure_ascii=False) == u'"\xe9"'
+  assert json.dumps('\x7f') == '"\\u007f"'
+  assert json.dumps({'b': 1, 'a': 2}, sort_keys=True) == '{"a": 2, "b": 1}'
+  assert json.dumps([1, {'a': 2}], separators=(',', ':')) == '[1,{"a":2}]'
+  assert json.dumps({'a': [1]}, indent=2) == '{\n  "a": [\n    1\n  ]\n}'
+
+
+def TestDumpsDefault():
+  got = json.dumps({'s': set([1])}, default=list)
+  assert got == '{"s": [1]}', got
+  try:
+    json.dumps(object())
+  except TypeError as e:
+    assert str(e).endswith('is not JSON serializable'), str(e)
+  else:
+    raise AssertionError('TypeError not raised')
+
+
+def TestDumpsCircular():
+  l = []
+  l.append(l)
+  try:
+    json.dumps(l)
+  except ValueError as e:
+    assert str(e) == 'Circular reference detected', str(e)
+  else:
+    raise AssertionError('ValueError not raised')
+
+
+def TestDumpsCustomEncoder():
+  class SetEncoder(json.JSONEncoder):
+
+    def default(self, o):  # pylint: disable=method-hidden
+      if isinstance(o, set):
+        return sorted(o)
+      return json.JSONEncoder.default(self, o)
+
+  assert json.dumps(set([2, 1]), cls=SetEncoder) == '[1, 2]'
+
+
+def TestDump():
+  buf = StringIO.StringIO()
+  json.dump({'foo': 'bar'}, buf)
+  assert buf.getvalue() == '{"foo": "bar"}'
+
+
+def TestRoundTrip():
+  obj = {u'a': [1, 2.5, None, True, u'x\n\ty'], u'b': {u'c': u'\u2603'}}
+  assert json.loads(json.dumps(obj)) == obj
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,129 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Tools for inspecting the graph of live Python objects.
+
+This is a small subset of the third party objgraph package, intended to help
+find objects kept alive by lingering globals. The graph is walked from
+sys.modules and the frames on the current stack. Only references visible to
+the runtime are followed: attributes, container elements and the fields of
+builtin objects. Function locals and variables captured by closures live in Go
+and are not seen.
+"""
+
+import sys
+
+from '__go__/grumpy' import (FindReferencePath as _FindReferencePath,  # pylint: disable=g-multiple-import
+                             ObjectHistogram as _ObjectHistogram,
+                             Referents as _Referents)
+
+__all__ = ['count', 'find_backref_chain', 'get_referents',
+           'most_common_types', 'show_chain', 'show_most_common_types',
+           'typestats']
+
+# Attributes checked when labelling a reference in show_chain().
+_EDGE_ATTRS = ('__dict__', '__class__', 'f_back', 'f_globals', 'im_func',
+               'im_self', '__func__', 'fget', 'fset', 'fdel')
+
+_MAX_REPR = 60
+
+
+def typestats():
+  """Returns a dict mapping type names to the number of reachable objects."""
+  return _ObjectHistogram(__frame__())  # pylint: disable=undefined-variable
+
+
+def count(typename):
+  """Returns the number of reachable objects whose type is named typename."""
+  return typestats().get(typename, 0)
+
+
+def most_common_types(limit=10):
+  """Returns (type name, count) pairs for the most common reachable types."""
+  stats = sorted(typestats().items(), key=lambda item: (-item[1], item[0]))
+  if limit is not None:
+    stats = stats[:limit]
+  return stats
+
+
+def show_most_common_types(limit=10, file=None):  # pylint: disable=redefined-builtin
+  """Prints a table of the most common reachable types and their counts."""
+  if file is None:
+    file = sys.stdout
+  stats = most_common_types(limit)
+  width = max([len(name) for name, _ in stats] or [0])
+  for name, n in stats:
+    file.write('%-*s %i\n' % (width, name, n))
+
+
+def get_referents(obj):
+  """Returns a list of the objects directly referenced by obj."""
+  return _Referents(obj)
+
+
+def find_backref_chain(obj):
+  """Returns a shortest chain of references from a root object to obj.
+
+  The first element of the list is sys.modules or a frame on the stack and the
+  last is obj. If obj is unreachable, [obj] is returned.
+  """
+  chain = _FindReferencePath(__frame__(), obj)  # pylint: disable=undefined-variable
+  if chain is None:
+    return [obj]
+  return chain
+
+
+def show_chain(chain, file=None):  # pylint: disable=redefined-builtin
+  """Prints a chain of references as returned by find_backref_chain()."""
+  if file is None:
+    file = sys.stdout
+  for i, obj in enumerate(chain):
+    if i == 0:
+      label = ''
+    else:
+      label = _edge_label(chain[i - 1], obj) + ' '
+    file.write('%s%s\n' % (label, _short_repr(obj)))
+
+
+def _edge_label(src, dst):
+  """Returns a description of how src refers to dst."""
+  if isinstance(src, dict):
+    for k, v in src.iteritems():
+      if v is dst:
+        return '[%s]' % _short_repr(k)
+      if k is dst:
+        return '(key)'
+  elif isinstance(src, (list, tuple)):
+    for i, v in enumerate(src):
+      if v is dst:
+        return '[%d]' % i
+  for attr in _EDGE_ATTRS:
+    try:
+      if getattr(src, attr) is dst:
+        return '.' + attr
+    except Exception:  # pylint: disable=broad-except
+      pass
+  return '->'
+
+
+def _short_repr(obj):
+  if isinstance(obj, (dict, list, tuple)):
+    return '<%s of %d items at 0x%x>' % (type(obj).__name__, len(obj), id(obj))
+  try:
+    s = repr(obj)
+  except Exception:  # pylint: disable=broad-except
+    s = '<%s object at 0x%x>' % (type(obj).__name__, id(obj))
+  if len(s) > _MAX_REPR:
+    s = s[:_MAX_REPR - 3] + '...'
+  return s
@@ -0,0 +1,84 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import StringIO
+import sys
+
+import objgraph
+import weetest
+
+
+class Leaky(object):
+  pass
+
+
+_cache = {'leak': [Leaky(), Leaky()]}
+
+
+def TestTypestats():
+  stats = objgraph.typestats()
+  assert stats['Leaky'] == 2, stats.get('Leaky')
+  assert stats['module'] >= 1
+  assert objgraph.count('Leaky') == 2
+  assert objgraph.count('NoSuchType') == 0
+
+
+def TestMostCommonTypes():
+  stats = objgraph.most_common_types(limit=3)
+  assert len(stats) == 3
+  counts = [n for _, n in stats]
+  assert counts == sorted(counts, reverse=True), stats
+
+
+def TestShowMostCommonTypes():
+  out = StringIO.StringIO()
+  objgraph.show_most_common_types(limit=2, file=out)
+  lines = out.getvalue().splitlines()
+  assert len(lines) == 2, lines
+
+
+def TestGetReferents():
+  a = object()
+  refs = objgraph.get_referents([a, 'foo'])
+  assert a in refs
+  assert 'foo' in refs
+  assert list in refs
+
+
+def TestFindBackrefChain():
+  target = _cache['leak'][1]
+  chain = objgraph.find_backref_chain(target)
+  # The __main__ globals are reachable through the stack as well as through
+  # sys.modules.
+  assert chain[0] is sys.modules or type(chain[0]).__name__ == 'frame'
+  assert chain[-1] is target
+  assert chain[-2] is _cache['leak']
+  assert chain[-3] is _cache
+  out = StringIO.StringIO()
+  objgraph.show_chain(chain, file=out)
+  lines = out.getvalue().splitlines()
+  assert len(lines) == len(chain)
+  assert lines[-3].startswith("['_cache'] <dict of 1 items"), lines
+  assert lines[-2].startswith("['leak'] <list of 2 items"), lines
+  assert lines[-1].startswith('[1] <'), lines
+
+
+def TestFindBackrefChainUnreachable():
+  o = Leaky()
+  assert objgraph.find_backref_chain(o) == [o]
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -16,17 +16,17 @@
 
 # pylint: disable=g-multiple-import
 from '__go__/io/ioutil' import ReadDir
-from '__go__/os' import (Chdir, Chmod, Environ, Getpid as getpid, Getwd, Pipe,
-    ProcAttr, Remove, StartProcess, Stat, Stdout, Stdin,
-    Stderr, Mkdir)
-from '__go__/path/filepath' import Separator
+from '__go__/os' import (Chdir, Chmod, Environ, Getpid as getpid,
+    Getppid as getppid, Getwd, Lstat, Pipe, ProcAttr, Remove, Rename, Setenv,
+    StartProcess, Stat, Stdout, Stdin, Stderr, Mkdir, Unsetenv)
+from '__go__/path/filepath' import ListSeparator, Separator
 from '__go__/grumpy' import (NewFileFromFD, StartThread, ToNative)
 from '__go__/reflect' import MakeSlice
 from '__go__/runtime' import GOOS
-from '__go__/syscall' import (Close, SYS_FCNTL, Syscall, F_GETFD, Wait4,
-    WaitStatus, WNOHANG)
+from '__go__/syscall' import (Close, SYS_FCNTL, Syscall, F_GETFD, Getrusage,
+    Kill, Rusage, RUSAGE_CHILDREN, RUSAGE_SELF, Wait4, WaitStatus, WNOHANG)
 from '__go__/sync' import WaitGroup
-from '__go__/time' import Second
+from '__go__/time' import Now, Second
 import _syscall
 from os import path
 import stat as stat_module
@@ -34,15 +34,66 @@ import sys
 
 
 sep = chr(Separator)
+pathsep = chr(ListSeparator)
+linesep = '\n'
+extsep = '.'
 error = OSError  # pylint: disable=invalid-name
 curdir = '.'
+pardir = '..'
+devnull = '/dev/null'
 name = 'posix'
 
 
-environ = {}
+class _Environ(dict):
+  """A dict of environment variables that writes changes through to Go."""
+
+  def __setitem__(self, key, value):
+    putenv(key, value)
+    dict.__setitem__(self, key, value)
+
+  def __delitem__(self, key):
+    unsetenv(key)
+    dict.__delitem__(self, key)
+
+  def clear(self):
+    for key in self.keys():
+      del self[key]
+
+  def pop(self, key, *args):
+    if key in self:
+      unsetenv(key)
+    return dict.pop(self, key, *args)
+
+  def setdefault(self, key, default=None):
+    if key not in self:
+      self[key] = default
+    return self[key]
+
+  def update(self, *args, **kwargs):
+    for key, value in dict(*args, **kwargs).iteritems():
+      self[key] = value
+
+
+environ = _Environ()
 for var in Environ():
   k, v = var.split('=', 1)
-  environ[k] = v
+  dict.__setitem__(environ, k, v)
+
+
+def getenv(key, default=None):
+  return environ.get(key, default)
+
+
+def putenv(key, value):
+  err = Setenv(key, value)
+  if err:
+    raise OSError(err.Error())
+
+
+def unsetenv(key):
+  err = Unsetenv(key)
+  if err:
+    raise OSError(err.Error())
 
 
 def mkdir(path, mode=0o777):
@@ -59,7 +110,7 @@ def chdir(path):
 
 def chmod(filepath, mode):
   # TODO: Support mode flags other than perms.
-  err = Chmod(filepath, stat(filepath).st_mode & ~0o777 | mode & 0o777)
+  err = Chmod(filepath, mode & 0o777)
   if err:
     raise OSError(err.Error())
 
@@ -92,6 +143,53 @@ def getcwd():
   return dir
 
 
+def kill(pid, sig):
+  """Sends signal number sig to the process pid."""
+  _syscall.invoke(Kill, pid, sig)
+
+
+# Hooks registered with register_at_fork().
+_at_fork_hooks = {'before': [], 'after_in_parent': [], 'after_in_child': []}
+
+
+def register_at_fork(**kwargs):
+  """Registers callables to be run when a child process is spawned.
+
+  Grumpy cannot fork, so the hooks run when os.popen() or subprocess.Popen
+  start a child instead. before hooks are called in reverse registration order
+  just before the child is started and after_in_parent hooks are called in
+  registration order once it has started or failed to start. Spawned children
+  are fresh processes that inherit no state, so after_in_child hooks are
+  accepted for compatibility but never called.
+  """
+  if not kwargs:
+    raise TypeError('At least one argument is required.')
+  for name, func in kwargs.iteritems():
+    if name not in _at_fork_hooks:
+      msg = "register_at_fork() got an unexpected keyword argument '%s'"
+      raise TypeError(msg % name)
+    if func is not None and not callable(func):
+      msg = "'%s' must be callable, not %s"
+      raise TypeError(msg % (name, type(func).__name__))
+  for name, func in kwargs.iteritems():
+    if func is not None:
+      _at_fork_hooks[name].append(func)
+
+
+def _run_at_fork_hooks(name):
+  hooks = _at_fork_hooks[name]
+  if name == 'before':
+    hooks = hooks[::-1]
+  for hook in hooks:
+    try:
+      hook()
+    except Exception:  # pylint: disable=broad-except
+      # As in CPython, a failing hook is reported but does not stop the spawn.
+      import traceback  # pylint: disable=g-import-not-at-top
+      sys.stderr.write('Exception ignored in: %r\n' % hook)
+      traceback.print_exc()
+
+
 class _Popen(object):
 
   def __init__(self, command, mode):
@@ -121,12 +219,16 @@ class _Popen(object):
     args[0] = shell
     args[1] = '-c'
     args[2] = command
-    self.proc, err = StartProcess(shell, args, attr)
+    _run_at_fork_hooks('before')
+    try:
+      self.proc, err = StartProcess(shell, args, attr)
+    finally:
+      _run_at_fork_hooks('after_in_parent')
     if err:
       raise OSError(err.Error())
     self.wg = WaitGroup.new()
     self.wg.Add(1)
-    StartThread(self._thread_func)
+    StartThread(__frame__(), self._thread_func)  # pylint: disable=undefined-variable
     self.file = NewFileFromFD(fd, self.close)
 
   def _thread_func(self):
@@ -165,33 +267,87 @@ def rmdir(filepath):
     raise OSError(err.Error())
 
 
-class StatResult(object):
+def rename(src, dst):
+  err = Rename(src, dst)
+  if err:
+    raise OSError(err.Error())
+
+
+class stat_result(tuple):  # pylint: disable=invalid-name
+  """Result of stat() and lstat(), indexable like a 10-tuple."""
+
+  def __new__(cls, seq):
+    if len(seq) != 10:
+      raise TypeError('stat_result() takes a 10-sequence')
+    return tuple.__new__(cls, seq)
+
+  def __repr__(self):
+    fields = ', '.join('%s=%r' % (k, v) for k, v in zip(_stat_fields, self))
+    return 'os.stat_result(%s)' % fields
+
+  st_mode = property(lambda self: self[0])
+  st_ino = property(lambda self: self[1])
+  st_dev = property(lambda self: self[2])
+  st_nlink = property(lambda self: self[3])
+  st_uid = property(lambda self: self[4])
+  st_gid = property(lambda self: self[5])
+  st_size = property(lambda self: self[6])
+  st_atime = property(lambda self: self[7])
+  st_mtime = property(lambda self: self[8])
+  st_ctime = property(lambda self: self[9])
+
+
+_stat_fields = ('st_mode', 'st_ino', 'st_dev', 'st_nlink', 'st_uid', 'st_gid',
+                'st_size', 'st_atime', 'st_mtime', 'st_ctime')
 
-  def __init__(self, info):
-    self._info = info
 
-  def st_mode(self):
-    # TODO: This is an incomplete mode flag. It should include S_IFDIR, etc.
-    return self._info.Mode()
-  # TODO: Make this a decorator once they're implemented.
-  st_mode = property(st_mode)
+def _timespec_to_float(ts):
+  return ts.Sec + float(ts.Nsec) / Second
 
-  def st_mtime(self):
-    return float(self._info.ModTime().UnixNano()) / Second
-  # TODO: Make this a decorator once they're implemented.
-  st_mtime = property(st_mtime)
 
-  def st_size(self):
-    return self._info.Size()
-  # TODO: Make this a decorator once they're implemented.
-  st_size = property(st_size)
+def _make_stat_result(info):
+  st = info.Sys()
+  # Linux names the timestamp fields Atim etc. while darwin uses Atimespec.
+  atime = getattr(st, 'Atim', None) or st.Atimespec
+  mtime = getattr(st, 'Mtim', None) or st.Mtimespec
+  ctime = getattr(st, 'Ctim', None) or st.Ctimespec
+  return stat_result((int(st.Mode), int(st.Ino), int(st.Dev), int(st.Nlink),
+                      int(st.Uid), int(st.Gid), int(st.Size),
+                      _timespec_to_float(atime), _timespec_to_float(mtime),
+                      _timespec_to_float(ctime)))
 
 
 def stat(filepath):
   info, err = Stat(filepath)
   if err:
     raise OSError(err.Error())
-  return StatResult(info)
+  return _make_stat_result(info)
+
+
+def lstat(filepath):
+  info, err = Lstat(filepath)
+  if err:
+    raise OSError(err.Error())
+  return _make_stat_result(info)
+
+
+def times():
+  """Returns a tuple of accumulated times in seconds.
+
+  The tuple contains the user and system CPU time of the current process and
+  its terminated children, followed by the elapsed real time since the epoch.
+  """
+  usage = Rusage.new()
+  _syscall.invoke(Getrusage, RUSAGE_SELF, usage)
+  children = Rusage.new()
+  _syscall.invoke(Getrusage, RUSAGE_CHILDREN, children)
+  return (_timeval_to_float(usage.Utime), _timeval_to_float(usage.Stime),
+          _timeval_to_float(children.Utime), _timeval_to_float(children.Stime),
+          float(Now().UnixNano()) / Second)
+
+
+def _timeval_to_float(tv):
+  return float(tv.Nano()) / Second
 
 
 unlink = remove
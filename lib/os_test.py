@@ -14,6 +14,7 @@
 
 import os
 import stat
+import subprocess
 import time
 import tempfile
 
@@ -79,6 +80,15 @@ def TestEnviron():
   assert 'HOME' in os.environ
 
 
+def TestEnvironSetAndDelete():
+  os.environ['GRUMPY_OS_TEST'] = 'foo'
+  assert os.getenv('GRUMPY_OS_TEST') == 'foo'
+  assert os.popen('echo $GRUMPY_OS_TEST').read() == 'foo\n'
+  del os.environ['GRUMPY_OS_TEST']
+  assert os.getenv('GRUMPY_OS_TEST') is None
+  assert os.popen('echo $GRUMPY_OS_TEST').read() == '\n'
+
+
 def TestFDOpen():
   fd, path = tempfile.mkstemp()
   f = os.fdopen(fd, 'w')
@@ -101,6 +111,31 @@ def TestFDOpenOSError():
     raise AssertionError
 
 
+def TestGetPid():
+  assert os.getpid() > 0
+  assert os.getppid() > 0
+  assert os.getpid() != os.getppid()
+
+
+def TestKill():
+  # Signal 0 only checks that the process exists.
+  os.kill(os.getpid(), 0)
+  proc = subprocess.Popen(['sleep', '10'])
+  os.kill(proc.pid, 15)
+  assert proc.wait() == -15
+
+
+def TestKillNoSuchProcess():
+  proc = subprocess.Popen(['true'])
+  proc.wait()
+  try:
+    os.kill(proc.pid, 0)
+  except OSError as e:
+    assert 'no such process' in str(e), str(e)
+  else:
+    raise AssertionError
+
+
 def TestMkdir():
   path = 'foobarqux'
   try:
@@ -136,6 +171,55 @@ def TestPopenWrite():
   f.close()
 
 
+def TestRegisterAtFork():
+  calls = []
+  os.register_at_fork(before=lambda: calls.append('before1'),
+                      after_in_parent=lambda: calls.append('parent1'),
+                      after_in_child=lambda: calls.append('child1'))
+  os.register_at_fork(before=lambda: calls.append('before2'),
+                      after_in_parent=lambda: calls.append('parent2'))
+  os.popen('true').close()
+  assert calls == ['before2', 'before1', 'parent1', 'parent2'], calls
+
+
+def TestRegisterAtForkErrors():
+  cases = [
+      ({}, 'At least one argument is required.'),
+      ({'before': 123}, "'before' must be callable, not int"),
+      ({'foo': len}, "register_at_fork() got an unexpected keyword argument 'foo'"),
+  ]
+  for kwargs, want in cases:
+    try:
+      os.register_at_fork(**kwargs)
+    except TypeError as e:
+      assert str(e) == want, str(e)
+    else:
+      raise AssertionError('register_at_fork(**%r) did not raise' % kwargs)
+
+
+def TestRename():
+  path = tempfile.mkdtemp()
+  src = os.path.join(path, 'src')
+  dst = os.path.join(path, 'dst')
+  open(src, 'w').close()
+  os.rename(src, dst)
+  assert os.listdir(path) == ['dst']
+  os.remove(dst)
+  os.rmdir(path)
+
+
+def TestRenameNoExist():
+  path = tempfile.mkdtemp()
+  try:
+    os.rename(path + '/nonexistent', path + '/foo')
+  except OSError:
+    pass
+  else:
+    raise AssertionError
+  finally:
+    os.rmdir(path)
+
+
 def TestRemove():
   fd, path = tempfile.mkstemp()
   os.close(fd)
@@ -221,6 +305,11 @@ def TestStatFile():
   # System time and mtime may have different precision so give 10 sec leeway.
   assert st.st_mtime + 10 > t
   assert st.st_size == 0
+  assert stat.S_ISREG(st.st_mode)
+  assert len(st) == 10
+  assert st[stat.ST_MODE] == st.st_mode
+  assert st[stat.ST_SIZE] == st.st_size
+  assert st.st_nlink == 1
 
 
 def TestStatDir():
@@ -243,6 +332,15 @@ def TestStatNoExist():
     os.rmdir(path)
 
 
+def TestTimes():
+  t = os.times()
+  assert len(t) == 5
+  for x in t:
+    assert isinstance(x, float)
+    assert x >= 0
+  assert abs(t[4] - time.time()) < 60, t
+
+
 def TestWaitPid():
   try:
     pid, status = os.waitpid(-1, os.WNOHANG)
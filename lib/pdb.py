@@ -0,0 +1,502 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""A minimal interactive debugger for Grumpy programs.
+
+Call set_trace() to stop in the calling frame or post_mortem() to inspect the
+frames of a traceback. At the (Pdb) prompt, type help to list the commands.
+
+The debugger is built on sys.settrace so the program runs more slowly while it
+is active. Expressions and statements entered at the prompt are interpreted by
+the runtime against the globals and f_locals of the current frame. Compiled
+functions only record their local variables when they reference locals(),
+vars() or dir(), so in other functions only globals are visible.
+"""
+
+import linecache
+import os
+import pprint
+import sys
+import traceback
+
+
+_HELP = """\
+Commands (abbreviations in parentheses):
+  s(tep)              Execute the current line, stopping in called functions.
+  n(ext)              Execute the current line without stopping in calls.
+  r(eturn)            Continue until the current function returns.
+  c(ont(inue))        Continue until a breakpoint is reached.
+  b(reak) [location]  Set a breakpoint at [file:]lineno or on entry to a
+                      function, or list the breakpoints.
+  cl(ear) [bpnum...]  Delete the given breakpoints, or all of them.
+  w(here)             Print the stack with the current frame marked.
+  u(p), d(own)        Move to the calling frame or back towards the newest.
+  l(ist) [first[, last]]
+                      List source code around the current line.
+  a(rgs)              Print the arguments of the current function.
+  p expr, pp expr     Print the value of expr, pretty printed for pp.
+  [!]statement        Execute statement in the current frame.
+  q(uit)              Abort the program.
+An empty line repeats the last command.
+"""
+
+_COMMANDS = {
+    'a': 'args', 'args': 'args',
+    'b': 'break', 'break': 'break',
+    'bt': 'where', 'w': 'where', 'where': 'where',
+    'c': 'continue', 'cont': 'continue', 'continue': 'continue',
+    'cl': 'clear', 'clear': 'clear',
+    'd': 'down', 'down': 'down',
+    'EOF': 'quit', 'exit': 'quit', 'q': 'quit', 'quit': 'quit',
+    'h': 'help', 'help': 'help',
+    'l': 'list', 'list': 'list',
+    'n': 'next', 'next': 'next',
+    'p': 'p', 'pp': 'pp',
+    'r': 'return', 'return': 'return',
+    's': 'step', 'step': 'step',
+    'u': 'up', 'up': 'up',
+}
+
+
+class BdbQuit(Exception):
+  """Raised to abort the program when the user quits the debugger."""
+
+
+class Breakpoint(object):
+  """A breakpoint on a line of a file or on entry to a function."""
+
+  def __init__(self, number, filename, lineno, funcname=None):
+    self.number = number
+    self.filename = filename
+    self.lineno = lineno
+    self.funcname = funcname
+
+  def __str__(self):
+    where = '%s:%d' % (self.filename, self.lineno)
+    if self.funcname:
+      return '%-4d %s() at %s' % (self.number, self.funcname, where)
+    return '%-4d at %s' % (self.number, where)
+
+
+class Pdb(object):
+  """The debugger, reading commands from stdin and writing to stdout."""
+
+  prompt = '(Pdb) '
+
+  def __init__(self, stdin=None, stdout=None):
+    self.stdin = stdin or sys.stdin
+    self.stdout = stdout or sys.stdout
+    self.breaks = []
+    self.lastcmd = ''
+    self._next_number = 1
+    self._reset()
+
+  def _reset(self):
+    # mode is one of 'step', 'next', 'return' or 'continue'. In next and
+    # return mode the debugger stops in stopframe.
+    self.mode = 'step'
+    self.stopframe = None
+    self.quitting = False
+    self.post_mortem = False
+    self.stack = []
+    self.curindex = 0
+    self.curframe = None
+    self.curframe_locals = None
+    self.lineno = None
+
+  def set_trace(self, frame=None):
+    """Starts debugging, stopping at the next line executed in frame."""
+    if frame is None:
+      frame = sys._getframe().f_back  # pylint: disable=protected-access
+    self._reset()
+    while frame is not None:
+      frame.f_trace = self.trace_dispatch
+      frame = frame.f_back
+    sys.settrace(self.trace_dispatch)
+
+  def runcall(self, func, *args, **kwargs):
+    """Calls func under the debugger, stopping at its first line."""
+    self._reset()
+    sys.settrace(self.trace_dispatch)
+    try:
+      return func(*args, **kwargs)
+    except BdbQuit:
+      pass
+    finally:
+      self.quitting = True
+      sys.settrace(None)
+
+  def interaction(self, frame, tb=None):
+    """Prompts for commands with frame or the frames of tb selected."""
+    stack = []
+    if tb is None:
+      while frame is not None:
+        if frame.f_code is not None:
+          stack.append((frame, frame.f_lineno))
+        frame = frame.f_back
+      stack.reverse()
+    else:
+      while tb is not None:
+        stack.append((tb.tb_frame, tb.tb_lineno))
+        tb = tb.tb_next
+    self.stack = stack
+    self._select(len(stack) - 1)
+    self._print_entry(self.curindex, '> ')
+    while not self.quitting:
+      self.stdout.write(self.prompt)
+      line = self.stdin.readline()
+      if not line:
+        line = 'EOF\n'
+      line = line.strip()
+      if line:
+        self.lastcmd = line
+      else:
+        line = self.lastcmd
+      if self.onecmd(line):
+        break
+    self.stack = []
+    self.curframe = None
+    self.curframe_locals = None
+
+  def onecmd(self, line):
+    """Runs the command line, returning True if execution should resume."""
+    if line.startswith('!'):
+      self.default(line[1:])
+      return False
+    parts = line.split(None, 1)
+    cmd = _COMMANDS.get(parts[0]) if parts else None
+    if cmd is None:
+      self.default(line)
+      return False
+    arg = parts[1].strip() if len(parts) > 1 else ''
+    return getattr(self, 'do_' + cmd)(arg)
+
+  def default(self, line):
+    """Executes line as a statement in the current frame."""
+    frame = self.curframe
+    try:
+      code = compile(line + '\n', '<stdin>', 'single')
+      exec code in frame.f_globals, self.curframe_locals  # pylint: disable=exec-used
+    except Exception:  # pylint: disable=broad-except
+      self._error()
+
+  def trace_dispatch(self, frame, event, arg):
+    """The trace function installed by sys.settrace while debugging."""
+    if self.quitting:
+      return None
+    if event == 'call':
+      return self._dispatch_call(frame)
+    if event == 'line':
+      return self._dispatch_line(frame)
+    if event == 'return':
+      self._dispatch_return(frame)
+    return self.trace_dispatch
+
+  def _dispatch_call(self, frame):
+    code = frame.f_code
+    filename = _canonic(code.co_filename)
+    for bp in self.breaks:
+      if (bp.funcname and bp.filename == filename and
+          bp.lineno == code.co_firstlineno):
+        self.mode = 'step'
+        return self.trace_dispatch
+    if self.mode == 'step':
+      return self.trace_dispatch
+    for bp in self.breaks:
+      if bp.filename == filename:
+        return self.trace_dispatch
+    # Nothing can stop in the new frame so don't trace its lines.
+    return None
+
+  def _dispatch_line(self, frame):
+    if self._stop_here(frame) or self._break_here(frame):
+      self.interaction(frame)
+      if self.quitting:
+        raise BdbQuit
+    if self.mode == 'continue' and not self.breaks:
+      return None
+    return self.trace_dispatch
+
+  def _dispatch_return(self, frame):
+    if (self.mode == 'step' or
+        self.mode in ('next', 'return') and frame is self.stopframe):
+      self.stdout.write('--Return--\n')
+      self.interaction(frame)
+      if self.quitting:
+        raise BdbQuit
+
+  def _stop_here(self, frame):
+    if self.mode == 'step':
+      return True
+    if self.mode != 'next':
+      return False
+    # Stop in stopframe or in a caller once stopframe has returned.
+    f = frame
+    while f is not None:
+      if f is self.stopframe:
+        return f is frame
+      f = f.f_back
+    return True
+
+  def _break_here(self, frame):
+    if not self.breaks:
+      return False
+    filename = _canonic(frame.f_code.co_filename)
+    for bp in self.breaks:
+      if (not bp.funcname and bp.filename == filename and
+          bp.lineno == frame.f_lineno):
+        return True
+    return False
+
+  def _resume(self, mode):
+    self.mode = mode
+    self.stopframe = self.curframe
+    if mode == 'continue' and not self.breaks:
+      # Nothing more to stop at so run at full speed.
+      sys.settrace(None)
+      for frame, _ in self.stack:
+        frame.f_trace = None
+    return True
+
+  def _select(self, index):
+    self.curindex = index
+    self.curframe, self.lineno = self.stack[index]
+    # Keep one snapshot of the locals so that assignments made at the prompt
+    # are visible to later commands.
+    self.curframe_locals = self.curframe.f_locals
+    self._list_next = None
+
+  def _print_entry(self, index, prefix='  '):
+    frame, lineno = self.stack[index]
+    code = frame.f_code
+    self.stdout.write('%s%s(%d)%s()\n' % (
+        prefix, code.co_filename, lineno, code.co_name))
+    line = linecache.getline(code.co_filename, lineno).strip()
+    if line:
+      self.stdout.write('-> %s\n' % line)
+
+  def _eval(self, expr):
+    frame = self.curframe
+    try:
+      return eval(expr, frame.f_globals, self.curframe_locals)  # pylint: disable=eval-used
+    except Exception:  # pylint: disable=broad-except
+      self._error()
+      raise
+
+  def _error(self, msg=None):
+    if msg is None:
+      t, v = sys.exc_info()[:2]
+      msg = traceback.format_exception_only(t, v)[-1].strip()
+    self.stdout.write('*** %s\n' % msg)
+
+  def do_help(self, arg):  # pylint: disable=unused-argument
+    self.stdout.write(_HELP)
+
+  def do_step(self, arg):  # pylint: disable=unused-argument
+    if self.post_mortem:
+      return True
+    return self._resume('step')
+
+  def do_next(self, arg):  # pylint: disable=unused-argument
+    if self.post_mortem:
+      return True
+    return self._resume('next')
+
+  def do_return(self, arg):  # pylint: disable=unused-argument
+    if self.post_mortem:
+      return True
+    return self._resume('return')
+
+  def do_continue(self, arg):  # pylint: disable=unused-argument
+    if self.post_mortem:
+      return True
+    return self._resume('continue')
+
+  def do_quit(self, arg):  # pylint: disable=unused-argument
+    self.quitting = True
+    sys.settrace(None)
+    for frame, _ in self.stack:
+      frame.f_trace = None
+    return True
+
+  def do_break(self, arg):
+    if not arg:
+      if self.breaks:
+        self.stdout.write('Num  Where\n')
+      for bp in self.breaks:
+        self.stdout.write('%s\n' % bp)
+      return False
+    filename = self.curframe.f_code.co_filename
+    funcname = None
+    location = arg
+    colon = arg.rfind(':')
+    if colon > 0:
+      filename, location = arg[:colon], arg[colon + 1:]
+      if not os.path.exists(filename):
+        self._error('%r not found' % filename)
+        return False
+    try:
+      lineno = int(location)
+    except ValueError:
+      try:
+        func = self._eval(arg)
+      except Exception:  # pylint: disable=broad-except
+        return False
+      func = getattr(func, 'im_func', func)
+      code = getattr(func, 'func_code', None)
+      if code is None:
+        self._error('%r is not a function' % arg)
+        return False
+      filename, lineno, funcname = (
+          code.co_filename, code.co_firstlineno, code.co_name)
+    bp = Breakpoint(self._next_number, _canonic(filename), lineno, funcname)
+    self._next_number += 1
+    self.breaks.append(bp)
+    self.stdout.write('Breakpoint %d at %s:%d\n' % (
+        bp.number, bp.filename, bp.lineno))
+    return False
+
+  def do_clear(self, arg):
+    if not arg:
+      del self.breaks[:]
+      self.stdout.write('Deleted all breakpoints\n')
+      return False
+    for s in arg.split():
+      try:
+        number = int(s)
+      except ValueError:
+        self._error('invalid breakpoint number %r' % s)
+        continue
+      for bp in self.breaks:
+        if bp.number == number:
+          self.breaks.remove(bp)
+          self.stdout.write('Deleted breakpoint %d\n' % number)
+          break
+      else:
+        self._error('no breakpoint number %d' % number)
+    return False
+
+  def do_where(self, arg):  # pylint: disable=unused-argument
+    for i in range(len(self.stack)):
+      self._print_entry(i, '> ' if i == self.curindex else '  ')
+    return False
+
+  def do_up(self, arg):  # pylint: disable=unused-argument
+    if self.curindex == 0:
+      self._error('Oldest frame')
+    else:
+      self._select(self.curindex - 1)
+      self._print_entry(self.curindex, '> ')
+    return False
+
+  def do_down(self, arg):  # pylint: disable=unused-argument
+    if self.curindex + 1 == len(self.stack):
+      self._error('Newest frame')
+    else:
+      self._select(self.curindex + 1)
+      self._print_entry(self.curindex, '> ')
+    return False
+
+  def do_list(self, arg):
+    if arg:
+      try:
+        bounds = [int(s) for s in arg.split(',')]
+      except ValueError:
+        self._error('invalid list arguments %r' % arg)
+        return False
+      first = max(1, bounds[0])
+      last = bounds[1] if len(bounds) > 1 else first + 10
+    elif self._list_next is not None:
+      first = self._list_next
+      last = first + 10
+    else:
+      first = max(1, self.lineno - 5)
+      last = first + 10
+    filename = self.curframe.f_code.co_filename
+    canonic = _canonic(filename)
+    breaklines = [bp.lineno for bp in self.breaks if bp.filename == canonic]
+    for lineno in range(first, last + 1):
+      line = linecache.getline(filename, lineno)
+      if not line:
+        self.stdout.write('[EOF]\n')
+        break
+      s = '%3d' % lineno
+      if len(s) < 4:
+        s += ' '
+      s += 'B' if lineno in breaklines else ' '
+      if lineno == self.lineno:
+        s += '->'
+      self.stdout.write(s + '\t' + line)
+      self._list_next = lineno + 1
+    return False
+
+  def do_args(self, arg):  # pylint: disable=unused-argument
+    code = self.curframe.f_code
+    n = code.co_argcount
+    if code.co_flags & 4:
+      n += 1
+    if code.co_flags & 8:
+      n += 1
+    local_vars = self.curframe_locals
+    for name in code.co_varnames[:n]:
+      if name in local_vars:
+        self.stdout.write('%s = %r\n' % (name, local_vars[name]))
+    return False
+
+  def do_p(self, arg):
+    try:
+      self.stdout.write(repr(self._eval(arg)) + '\n')
+    except Exception:  # pylint: disable=broad-except
+      pass
+    return False
+
+  def do_pp(self, arg):
+    try:
+      self.stdout.write(pprint.pformat(self._eval(arg)) + '\n')
+    except Exception:  # pylint: disable=broad-except
+      pass
+    return False
+
+
+_canonic_cache = {}
+
+
+def _canonic(filename):
+  canonic = _canonic_cache.get(filename)
+  if canonic is None:
+    canonic = os.path.abspath(filename)
+    _canonic_cache[filename] = canonic
+  return canonic
+
+
+def set_trace():
+  """Stops in the debugger at the next line of the calling frame."""
+  Pdb().set_trace(sys._getframe().f_back)  # pylint: disable=protected-access
+
+
+def runcall(func, *args, **kwargs):
+  """Calls func under the debugger, stopping at its first line."""
+  return Pdb().runcall(func, *args, **kwargs)
+
+
+def post_mortem(t=None):
+  """Inspects the frames of traceback t or the exception being handled."""
+  if t is None:
+    t = sys.exc_info()[2]
+  if t is None:
+    raise ValueError('A valid traceback must be passed if no exception is '
+                     'being handled')
+  p = Pdb()
+  p.post_mortem = True
+  p.interaction(None, t)
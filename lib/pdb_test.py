@@ -0,0 +1,177 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import pdb
+import StringIO
+import sys
+
+import weetest
+
+
+def Add(a, b):
+  total = a + b
+  return total
+
+
+def Twice(x):
+  y = Add(x, x)
+  return y
+
+
+def Locals(x):
+  y = x * 2
+  z = locals()
+  return z
+
+
+def _Debugger(*commands):
+  stdin = StringIO.StringIO(''.join(c + '\n' for c in commands))
+  return pdb.Pdb(stdin, StringIO.StringIO())
+
+
+def _Prompts(debugger):
+  return debugger.stdout.getvalue().count(debugger.prompt)
+
+
+def TestRunCallContinue():
+  debugger = _Debugger('c')
+  assert debugger.runcall(Twice, 3) == 6
+  out = debugger.stdout.getvalue()
+  assert out.startswith('> %s(%d)Twice()\n' % (
+      __file__, Twice.func_code.co_firstlineno + 1)), out
+  assert '-> y = Add(x, x)\n' in out
+  assert _Prompts(debugger) == 1
+  assert sys.gettrace() is None
+
+
+def TestStepIntoCall():
+  debugger = _Debugger('s', 's', 'where', 'c')
+  assert debugger.runcall(Twice, 3) == 6
+  out = debugger.stdout.getvalue()
+  assert '-> total = a + b\n' in out, out
+  assert '  %s(%d)Twice()\n' % (
+      __file__, Twice.func_code.co_firstlineno + 1) in out
+  assert '> %s(%d)Add()\n' % (__file__, Add.func_code.co_firstlineno + 1) in out
+
+
+def TestNextStepsOver():
+  debugger = _Debugger('n', 'n', 'c')
+  assert debugger.runcall(Twice, 2) == 4
+  out = debugger.stdout.getvalue()
+  assert '-> return y\n' in out, out
+  assert 'Add()' not in out
+  assert '--Return--\n' in out
+
+
+def TestReturn():
+  debugger = _Debugger('r', 'c')
+  assert debugger.runcall(Twice, 2) == 4
+  out = debugger.stdout.getvalue()
+  assert '--Return--\n' in out, out
+  assert _Prompts(debugger) == 2
+
+
+def TestBreakFunction():
+  debugger = _Debugger('b Add', 'c', 'c')
+  assert debugger.runcall(Twice, 5) == 10
+  out = debugger.stdout.getvalue()
+  assert 'Breakpoint 1 at %s:%d\n' % (
+      __file__, Add.func_code.co_firstlineno) in out, out
+  assert '-> total = a + b\n' in out
+  assert _Prompts(debugger) == 3
+
+
+def TestBreakLine():
+  lineno = Add.func_code.co_firstlineno + 2
+  debugger = _Debugger('b %d' % lineno, 'b', 'c', 'cl 1', 'c')
+  assert debugger.runcall(Twice, 1) == 2
+  out = debugger.stdout.getvalue()
+  assert 'Num  Where\n' in out, out
+  assert '-> return total\n' in out
+  assert 'Deleted breakpoint 1\n' in out
+  assert not debugger.breaks
+
+
+def TestPrintAndExec():
+  debugger = _Debugger('p x + 1', 'pp [x] * 2', '!x = 10', 'p x', 'n', 'p y',
+                       'args', 'p undefined', 'c')
+  result = debugger.runcall(Locals, 4)
+  # As with CPython, assignments to the locals of a function are not
+  # written back to its frame.
+  assert result == {'x': 4, 'y': 8}, result
+  out = debugger.stdout.getvalue()
+  assert '5\n' in out, out
+  assert '[4, 4]\n' in out
+  assert '10\n' in out
+  assert '8\n' in out
+  assert 'x = 4\n' in out
+  assert "*** NameError: name 'undefined' is not defined\n" in out
+
+
+def TestUpDown():
+  debugger = _Debugger('s', 'u', 'd', 'd', 'c')
+  debugger.runcall(Twice, 7)
+  out = debugger.stdout.getvalue()
+  twice = '> %s(%d)Twice()\n' % (__file__, Twice.func_code.co_firstlineno + 1)
+  add = '> %s(%d)Add()\n' % (__file__, Add.func_code.co_firstlineno + 1)
+  assert out.split('(Pdb) ')[1:-1] == [
+      add + '-> total = a + b\n', twice + '-> y = Add(x, x)\n',
+      add + '-> total = a + b\n', '*** Newest frame\n'], out
+
+
+def TestList():
+  debugger = _Debugger('l', 'c')
+  debugger.runcall(Add, 1, 2)
+  out = debugger.stdout.getvalue()
+  lineno = Add.func_code.co_firstlineno + 1
+  assert '%3d  ->\t  total = a + b\n' % lineno in out, out
+  assert '%3d  \tdef Add(a, b):\n' % (lineno - 1) in out
+
+
+def TestQuit():
+  debugger = _Debugger('q')
+  assert debugger.runcall(Twice, 1) is None
+  assert sys.gettrace() is None
+
+
+def TestSetTrace():
+  debugger = _Debugger('n', 'p result', 'c')
+  debugger.set_trace()
+  result = Twice(3)
+  result += 1
+  out = debugger.stdout.getvalue()
+  assert '-> result = Twice(3)\n' in out, out
+  assert '-> result += 1\n' in out
+  assert '6\n' in out
+  assert 'Twice()' not in out
+  assert sys.gettrace() is None
+  assert result == 7
+
+
+def TestPostMortem():
+  debugger = _Debugger('w', 'u', 'p Add.__name__', 'c')
+  debugger.post_mortem = True
+  try:
+    Twice(None)
+  except TypeError:
+    debugger.interaction(None, sys.exc_info()[2])
+  out = debugger.stdout.getvalue()
+  assert '-> total = a + b\n' in out, out
+  assert '> %s(%d)Twice()\n' % (
+      __file__, Twice.func_code.co_firstlineno + 1) in out
+  assert "'Add'\n" in out
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
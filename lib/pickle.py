@@ -0,0 +1,109 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Create portable serialized representations of Python objects.
+
+Pickling and unpickling are implemented natively by the runtime and support
+protocols 0 through 2. None, bool, int, long, float, str, unicode, tuple, list
+and dict are handled directly. Classes and functions are pickled by name and
+other objects are pickled using __reduce_ex__, __reduce__, __getstate__ and
+__setstate__. Persistent IDs and the copy_reg extension registry are not
+supported.
+"""
+
+# Objects reduced by object.__reduce_ex__ under protocols 0 and 1 reference
+# copy_reg._reconstructor so make sure it can be imported when unpickling.
+import copy_reg  # pylint: disable=unused-import
+
+from '__go__/grumpy' import (PickleDumps as _PickleDumps,  # pylint: disable=g-multiple-import
+                             PickleLoad as _PickleLoad,
+                             PickleLoads as _PickleLoads)
+
+__all__ = ['PickleError', 'PicklingError', 'UnpicklingError', 'Pickler',
+           'Unpickler', 'dump', 'dumps', 'load', 'loads']
+
+format_version = '2.0'
+compatible_formats = ['1.0', '1.1', '1.2', '1.3', '2.0']
+
+HIGHEST_PROTOCOL = 2
+
+
+class PickleError(Exception):
+  """A common base class for the other pickling exceptions."""
+
+
+class PicklingError(PickleError):
+  """Raised when an unpicklable object is passed to dump()."""
+
+
+class UnpicklingError(PickleError):
+  """Raised when there is a problem unpickling an object."""
+
+
+def dumps(obj, protocol=None):
+  """Returns the pickled representation of obj as a str."""
+  if protocol is None:
+    protocol = 0
+  return _PickleDumps(__frame__(), obj, protocol, PicklingError)  # pylint: disable=undefined-variable
+
+
+def dump(obj, file, protocol=None):  # pylint: disable=redefined-builtin
+  """Writes the pickled representation of obj to file."""
+  file.write(dumps(obj, protocol))
+
+
+def loads(s):
+  """Reconstructs an object from the pickle data in s."""
+  return _PickleLoads(__frame__(), s, UnpicklingError)  # pylint: disable=undefined-variable
+
+
+def load(file):  # pylint: disable=redefined-builtin
+  """Reads a pickled object from file.
+
+  Only the bytes making up the pickle are read so that multiple pickles can be
+  read from the same file.
+  """
+  return _PickleLoad(__frame__(), file, UnpicklingError)  # pylint: disable=undefined-variable
+
+
+class Pickler(object):
+  """Writes pickled objects to a file."""
+
+  def __init__(self, file, protocol=None):  # pylint: disable=redefined-builtin
+    if protocol is None:
+      protocol = 0
+    elif protocol < 0:
+      protocol = HIGHEST_PROTOCOL
+    elif protocol > HIGHEST_PROTOCOL:
+      raise ValueError('pickle protocol must be <= %d' % HIGHEST_PROTOCOL)
+    self.write = file.write
+    self.proto = protocol
+
+  def dump(self, obj):
+    """Writes the pickled representation of obj to the file."""
+    self.write(dumps(obj, self.proto))
+
+  def clear_memo(self):
+    """Does nothing since objects are never shared between dump() calls."""
+
+
+class Unpickler(object):
+  """Reads pickled objects from a file."""
+
+  def __init__(self, file):  # pylint: disable=redefined-builtin
+    self._file = file
+
+  def load(self):
+    """Reads the next pickled object from the file."""
+    return load(self._file)
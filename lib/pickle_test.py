@@ -0,0 +1,192 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import cPickle
+import pickle
+import StringIO
+
+import weetest
+
+PROTOCOLS = range(pickle.HIGHEST_PROTOCOL + 1)
+
+
+class Point(object):
+
+  def __init__(self, x, y):
+    self.x = x
+    self.y = y
+
+  def __eq__(self, other):
+    return (type(self) is type(other) and self.x == other.x and
+            self.y == other.y)
+
+
+class Reduced(object):
+
+  def __init__(self, value):
+    self.value = value
+
+  def __reduce__(self):
+    return Reduced, (self.value * 2,)
+
+
+class Stateful(object):
+
+  def __init__(self):
+    self.calls = []
+
+  def __getstate__(self):
+    return {'calls': ['getstate']}
+
+  def __setstate__(self, state):
+    self.calls = state['calls'] + ['setstate']
+
+
+class MyInt(int):
+  pass
+
+
+class MyList(list):
+  pass
+
+
+def Helper():
+  pass
+
+
+def TestRoundTrip():
+  values = [None, True, False, 0, -1, 255, 65536, -2**31, 2**40, 2**100,
+            -2**100, 0.5, float('inf'), '', 'foo\n\'"\\', u'', u'\u2603\n\\',
+            (), (1,), (1, 2), (1, 2, 3), (1, 2, 3, 4), [], [1, [2]], {},
+            {'a': [1, 2], 2: (3,), u'b': None}, range(2000),
+            dict((i, None) for i in range(1500))]
+  for proto in PROTOCOLS:
+    for v in values:
+      got = pickle.loads(pickle.dumps(v, proto))
+      assert got == v, (proto, v, got)
+      assert type(got) is type(v), (proto, v, got)
+
+
+def TestSharedReferences():
+  shared = [1]
+  for proto in PROTOCOLS:
+    got = pickle.loads(pickle.dumps([shared, shared], proto))
+    assert got[0] is got[1]
+    cycle = []
+    cycle.append(cycle)
+    got = pickle.loads(pickle.dumps(cycle, proto))
+    assert got[0] is got
+
+
+def TestUserClasses():
+  for proto in PROTOCOLS:
+    p = pickle.loads(pickle.dumps(Point(1, 'a'), proto))
+    assert p == Point(1, 'a'), (proto, p.__dict__)
+    r = pickle.loads(pickle.dumps(Reduced(3), proto))
+    assert isinstance(r, Reduced) and r.value == 6
+    s = pickle.loads(pickle.dumps(Stateful(), proto))
+    assert s.calls == ['getstate', 'setstate'], s.calls
+
+
+def TestBuiltinSubclasses():
+  for proto in PROTOCOLS:
+    i = MyInt(5)
+    i.attr = 'foo'
+    got = pickle.loads(pickle.dumps(i, proto))
+    assert type(got) is MyInt and got == 5 and got.attr == 'foo'
+    l = MyList([1, 2])
+    got = pickle.loads(pickle.dumps(l, proto))
+    assert type(got) is MyList and got == [1, 2]
+
+
+def TestGlobals():
+  for proto in PROTOCOLS:
+    assert pickle.loads(pickle.dumps(Point, proto)) is Point
+    assert pickle.loads(pickle.dumps(Helper, proto)) is Helper
+    assert pickle.loads(pickle.dumps(int, proto)) is int
+    assert pickle.loads(pickle.dumps(len, proto)) is len
+
+
+def TestDumpsFormat():
+  assert pickle.dumps([1, 'a']) == "(lp0\nI1\naS'a'\np1\na."
+  assert pickle.dumps((1, None), 2) == '\x80\x02K\x01N\x86q\x00.'
+  assert cPickle.dumps({}, 1) == '}q\x00.'
+
+
+def TestLoadsCPythonPickle():
+  # The result of pickle.dumps(Point(1, 2), 2) under CPython.
+  data = ('\x80\x02c__main__\nPoint\nq\x00)\x81q\x01}q\x02(U\x01yq\x03K\x02'
+          'U\x01xq\x04K\x01ub.')
+  assert pickle.loads(data) == Point(1, 2)
+
+
+def TestUnpicklable():
+  class Local(object):
+    pass
+  try:
+    pickle.dumps(Local)
+  except pickle.PicklingError as e:
+    assert 'Local' in str(e), str(e)
+  else:
+    raise AssertionError('PicklingError not raised')
+  try:
+    pickle.dumps(object())
+  except TypeError as e:
+    assert str(e) == "can't pickle object objects", str(e)
+  else:
+    raise AssertionError('TypeError not raised')
+
+
+def TestLoadsErrors():
+  try:
+    pickle.loads('z.')
+  except pickle.UnpicklingError as e:
+    assert str(e) == "invalid load key, 'z'.", str(e)
+  else:
+    raise AssertionError('UnpicklingError not raised')
+  try:
+    cPickle.loads('(I1\n')
+  except EOFError:
+    pass
+  else:
+    raise AssertionError('EOFError not raised')
+  try:
+    pickle.dumps(None, 3)
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('ValueError not raised')
+
+
+def TestFiles():
+  f = StringIO.StringIO()
+  pickle.dump([1, 2], f)
+  pickler = cPickle.Pickler(f, -1)
+  pickler.dump('foo')
+  pickler.dump({'a': None})
+  f.seek(0)
+  assert pickle.load(f) == [1, 2]
+  unpickler = cPickle.Unpickler(f)
+  assert unpickler.load() == 'foo'
+  assert unpickler.load() == {'a': None}
+  try:
+    unpickler.load()
+  except EOFError:
+    pass
+  else:
+    raise AssertionError('EOFError not raised')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,37 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""A subset of the setuptools pkg_resources resource API."""
+
+import cStringIO
+import pkgutil
+
+
+def resource_exists(package, resource_name):
+  """Return True if the named resource exists in package."""
+  try:
+    pkgutil.get_data(package, resource_name)
+  except IOError:
+    return False
+  return True
+
+
+def resource_stream(package, resource_name):
+  """Return a readable file-like object for the named resource."""
+  return cStringIO.StringIO(pkgutil.get_data(package, resource_name))
+
+
+def resource_string(package, resource_name):
+  """Return the contents of the named resource as a str."""
+  return pkgutil.get_data(package, resource_name)
@@ -0,0 +1,48 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Utilities to support packages."""
+
+from '__go__/grumpy' import GetResource, ImportModule
+import os
+import sys
+
+
+def get_data(package, resource):
+  """Get a resource from a package.
+
+  Resources embedded at compile time via grumpc's -resource flag take
+  precedence. Otherwise the resource is read from the filesystem relative to
+  the directory containing the package.
+
+  Args:
+    package: The dotted name of the package, e.g. 'foo.bar'.
+    resource: The slash separated path of the resource relative to the
+        package, e.g. 'templates/index.html'.
+
+  Returns:
+    The contents of the resource as a str.
+
+  Raises:
+    IOError: The resource could not be found.
+  """
+  ImportModule(__frame__(), package)
+  mod = sys.modules[package]
+  data, ok = GetResource(mod.__package__ or '', resource)
+  if ok:
+    return data
+  parts = resource.split('/')
+  parts.insert(0, os.path.dirname(mod.__file__))
+  with open(os.path.join(*parts), 'rb') as f:
+    return f.read()
@@ -0,0 +1,64 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from '__go__/grumpy' import RegisterResource
+import pkg_resources
+import pkgutil
+
+import weetest
+
+
+RegisterResource('os', 'testdata/pkgutil_test.txt', 'foo\0bar')
+RegisterResource('', 'pkgutil_test.txt', 'baz')
+
+
+def TestGetDataEmbedded():
+  assert pkgutil.get_data('os', 'testdata/pkgutil_test.txt') == 'foo\0bar'
+  # Resources are looked up relative to the package containing a module.
+  assert pkgutil.get_data('os.path', 'testdata/pkgutil_test.txt') == 'foo\0bar'
+  assert pkgutil.get_data('sys', 'pkgutil_test.txt') == 'baz'
+
+
+def TestGetDataFile():
+  assert 'Miscellaneous operating system' in pkgutil.get_data(
+      'os', '__init__.py')
+
+
+def TestGetDataMissing():
+  try:
+    pkgutil.get_data('os', 'testdata/missing.txt')
+  except IOError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestGetDataNoPackage():
+  try:
+    pkgutil.get_data('nonexistent', 'foo.txt')
+  except ImportError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestResourceString():
+  assert pkg_resources.resource_string('sys', 'pkgutil_test.txt') == 'baz'
+  assert pkg_resources.resource_stream('sys', 'pkgutil_test.txt').read() == 'baz'
+  assert pkg_resources.resource_exists('os', 'testdata/pkgutil_test.txt')
+  assert not pkg_resources.resource_exists('os', 'testdata/missing.txt')
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -18,39 +18,58 @@ import random
 import weetest
 
 
-def TestGrumpyRandom():
-  assert len(_random._gorandom(5)) == 5
-
-  assert _random._int_bit_length(0) == 0
-  assert _random._int_bit_length(1) == 1
-  assert _random._int_bit_length(8) == 4
-  assert _random._int_bit_length(256) == 9
-
-  assert _random._int_from_bytes([1, 0, 0, 0]) == 1
-  assert _random._int_from_bytes([0, 0, 0, 0]) == 0
-  assert _random._int_from_bytes([255, 255, 0, 0]) == 65535
-  assert _random._int_from_bytes([0, 0, 0, 1]) == 16777216
-
-  r = _random.GrumpyRandom()
-  assert 0.0 <= r.random() < 1.0
-
-  assert 0 <= r.getrandbits(1) <= 1
-  assert 0 <= r.getrandbits(2) <= 3
-  assert 0 <= r.getrandbits(8) <= 255
-
-  assert 0 <= r._randbelow(1) < 1
-  assert 0 <= r._randbelow(3) < 3
-  assert 0 <= r._randbelow(1000) < 1000
+def _AssertClose(got, want):
+  # Expected values are written as float literals so allow for rounding.
+  for g, w in zip(got, want):
+    assert abs(g - w) <= 1e-9 * abs(w), (got, want)
+
+
+def TestMersenneTwister():
+  # The expected values were produced by CPython 2.7.
+  r = _random.Random(42)
+  _AssertClose([r.random()], [0.6394267984578837])
+  assert r.getrandbits(32) == 107420369
+  r.seed(0)
+  _AssertClose([r.random()], [0.8444218515250481])
+  state = r.getstate()
+  assert len(state) == 625
+  a = r.random()
+  r.setstate(state)
+  assert r.random() == a
 
 
 def TestSeed():
+  random.seed(1)
+  _AssertClose([random.random() for _ in range(3)],
+               [0.13436424411240122, 0.8474337369372327, 0.763774618976614])
+  random.seed(1)
+  assert [random.randint(0, 100) for _ in range(5)] == [13, 85, 77, 25, 50]
+  random.seed(1)
+  assert [random.randrange(0, 2**64) for _ in range(2)] == [
+      14089154938208861744L, 2175216119781798972]
+  random.seed(1)
+  random.jumpahead(3)
+  _AssertClose([random.random()], [0.268433540210566])
   random.seed()
+  random.seed("foo")
+  a = random.random()
+  random.seed("foo")
+  assert random.random() == a
+
+
+def TestGetState():
+  r = random.Random(7)
+  r.gauss(0, 1)
+  state = r.getstate()
+  a = [r.gauss(0, 1) for _ in range(3)]
+  r.setstate(state)
+  assert [r.gauss(0, 1) for _ in range(3)] == a
   try:
-    random.seed("wrongtype")
-  except TypeError:
+    r.setstate((4, state[1], None))
+  except ValueError:
     pass
   else:
-    raise AssertionError("TypeError not raised")
+    raise AssertionError("ValueError not raised")
 
 
 def TestRandom():
@@ -108,5 +127,39 @@ def TestRandomChoice():
     raise AssertionError("IndexError not raised")
 
 
+def TestShuffleSample():
+  random.seed(1)
+  l = range(10)
+  random.shuffle(l)
+  assert l == [8, 0, 3, 4, 5, 2, 9, 6, 7, 1]
+  random.seed(1)
+  assert random.sample(range(100), 5) == [13, 84, 76, 25, 49]
+  assert random.sample(xrange(10**6), 3) == [449491, 651592, 788723]
+  try:
+    random.sample([1, 2], 3)
+  except ValueError:
+    pass
+  else:
+    raise AssertionError("ValueError not raised")
+
+
+def TestDistributions():
+  random.seed(1)
+  assert random.choice('abcdef') == 'a'
+  got = [random.uniform(1, 2), random.gauss(0, 1), random.gauss(0, 1)]
+  want = [1.8474337369372327, 0.06633580893826191, -0.7645436509716318]
+  _AssertClose(got, want)
+  random.seed(1)
+  got = [random.triangular(0, 10, 4), random.expovariate(2),
+         random.gammavariate(2, 3), random.betavariate(2, 3),
+         random.paretovariate(2), random.weibullvariate(1, 2),
+         random.vonmisesvariate(1, 2), random.normalvariate(0, 1),
+         random.lognormvariate(0, 1)]
+  want = [2.3183118350420524, 0.9400781327103127, 11.8138450472542,
+          0.33270298410156707, 1.050514980686597, 0.1695789626364361,
+          5.822495033398361, 0.4927897570785065, 3.213994969780892]
+  _AssertClose(got, want)
+
+
 if __name__ == '__main__':
   weetest.RunTests()
\ No newline at end of file
@@ -12,12 +12,21 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+from '__go__/grumpy' import SelectChannels as _SelectChannels, StartThread
+from '__go__/os' import Pipe as _Pipe
+from '__go__/reflect' import (
+    BothDir as _BothDir,
+    ChanOf as _ChanOf,
+    MakeChan as _MakeChan,
+    TypeOf as _TypeOf
+)
 from '__go__/syscall' import (
     FD_SETSIZE as _FD_SETSIZE,
     Select as _Select,
     FdSet as _FdSet,
     Timeval as _Timeval
 )
+from '__go__/time' import After as _After, Duration as _Duration, Second
 import _syscall
 import math
 
@@ -53,6 +62,92 @@ def select(rlist, wlist, xlist, timeout=None):
           [xlist[i] for i, fd in enumerate(xlist_norm) if _fdset_isset(fd, xfds)])
 
 
+def multiplex(cases, timeout=None):
+  """Waits until one of several channel or file operations can proceed.
+
+  Each case may be a Go channel to receive from, a (channel, value) tuple to
+  send value on a channel, or a file object or descriptor to wait for it to
+  become readable. If more than one case is ready, one is chosen at random.
+
+  Args:
+    cases: A sequence of cases as described above.
+    timeout: The maximum number of seconds to wait, or None to wait forever.
+
+  Returns:
+    A tuple (i, value, ok) where i is the index of the case that proceeded, or
+    -1 if the timeout expired. For channel receives, value and ok are as
+    returned by the channel's recv() method. Otherwise value is None and ok is
+    True.
+  """
+  chan_cases = []
+  chan_indices = []
+  file_cases = []
+  file_indices = []
+  for i, case in enumerate(cases):
+    if isinstance(case, tuple) or hasattr(case, 'recv'):
+      chan_cases.append(case)
+      chan_indices.append(i)
+    else:
+      file_cases.append(case)
+      file_indices.append(i)
+  if timeout is not None and timeout <= 0:
+    # Poll rather than block but still give ready files a chance.
+    if file_cases:
+      readable = select(file_cases, [], [], 0)[0]
+      if readable:
+        return file_indices[file_cases.index(readable[0])], None, True
+    i, value, ok = _SelectChannels(__frame__(), chan_cases, False)
+    if i < 0:
+      return -1, None, False
+    return chan_indices[i], value, ok
+  waiter = None
+  if file_cases:
+    waiter = _FileWaiter(file_cases)
+    chan_cases.append(waiter.ready)
+  if timeout is not None:
+    chan_cases.append(_After(_Duration(int(timeout * Second))))
+  try:
+    i, value, ok = _SelectChannels(__frame__(), chan_cases, True)
+  finally:
+    if waiter:
+      waiter.cancel()
+  if i < len(chan_indices):
+    return chan_indices[i], value, ok
+  if waiter and i == len(chan_indices):
+    return file_indices[value], None, True
+  return -1, None, False
+
+
+class _FileWaiter(object):
+  """Waits in a separate thread for one of a list of files to be readable.
+
+  The index of the first readable file is sent on the ready channel. The wait
+  can be abandoned by calling cancel().
+  """
+
+  def __init__(self, files):
+    self.files = files
+    self.ready = _MakeChan(_ChanOf(_BothDir, _TypeOf(0)), 1).Interface()
+    self._r, self._w, err = _Pipe()
+    if err:
+      raise OSError(err.Error())
+    StartThread(__frame__(), self._run)  # pylint: disable=undefined-variable
+
+  def _run(self):
+    try:
+      readable = select(self.files + [self._r.Fd()], [], [])[0]
+      for i, f in enumerate(self.files):
+        if f in readable:
+          self.ready.send(i)
+          break
+    finally:
+      self._r.Close()
+
+  def cancel(self):
+    self._w.Write('x')
+    self._w.Close()
+
+
 def _fdset_set(fd, fds):
   idx = fd / (_FD_SETSIZE / len(fds.Bits)) % len(fds.Bits)
   pos = fd % (_FD_SETSIZE / len(fds.Bits))
@@ -0,0 +1,82 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# pylint: disable=g-multiple-import
+from '__go__/os' import Pipe
+from '__go__/reflect' import BothDir, ChanOf, MakeChan, TypeOf
+import select_ as select
+import time
+
+import weetest
+
+
+def _MakeChan(size=0):
+  return MakeChan(ChanOf(BothDir, TypeOf('')), size).Interface()
+
+
+def TestMultiplexRecv():
+  c1, c2 = _MakeChan(1), _MakeChan(1)
+  c2.send('foo')
+  assert select.multiplex([c1, c2]) == (1, 'foo', True)
+
+
+def TestMultiplexRecvClosed():
+  c = _MakeChan()
+  c.close()
+  assert select.multiplex([c]) == (0, '', False)
+
+
+def TestMultiplexSend():
+  c1, c2 = _MakeChan(), _MakeChan(1)
+  assert select.multiplex([c1, (c2, 'bar')]) == (1, None, True)
+  assert c2.recv() == ('bar', True)
+
+
+def TestMultiplexTimeout():
+  start = time.time()
+  assert select.multiplex([_MakeChan()], timeout=0.05) == (-1, None, False)
+  assert time.time() - start >= 0.04
+
+
+def TestMultiplexPoll():
+  c = _MakeChan(1)
+  assert select.multiplex([c], timeout=0) == (-1, None, False)
+  c.send('baz')
+  assert select.multiplex([c], timeout=0) == (0, 'baz', True)
+
+
+def TestMultiplexFile():
+  r, w, _ = Pipe()
+  c = _MakeChan()
+  try:
+    assert select.multiplex([c, r.Fd()], timeout=0.01) == (-1, None, False)
+    w.Write('x')
+    assert select.multiplex([c, r.Fd()]) == (1, None, True)
+    assert select.multiplex([r.Fd()], timeout=0) == (0, None, True)
+  finally:
+    r.Close()
+    w.Close()
+
+
+def TestMultiplexBadCase():
+  try:
+    select.multiplex([(_MakeChan(), 'foo', 'bar')])
+  except TypeError:
+    pass
+  else:
+    raise AssertionError
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,387 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""Minimal BSD socket interface implemented on top of Go's net package.
+
+Only the subset of the socket API needed by SocketServer and simple clients is
+supported: TCP and UDP sockets over IPv4 and IPv6. Sockets do not have real
+file descriptors, so fileno() and select() on sockets are not available.
+"""
+
+# pylint: disable=g-multiple-import
+from '__go__/grumpy' import ToNative
+from '__go__/io/ioutil' import ReadAll as _ReadAll
+from '__go__/net' import (
+    Dial as _Dial,
+    JoinHostPort as _JoinHostPort,
+    Listen as _Listen,
+    ListenPacket as _ListenPacket,
+    LookupHost as _LookupHost,
+    ResolveUDPAddr as _ResolveUDPAddr,
+    SplitHostPort as _SplitHostPort
+)
+from '__go__/os' import Hostname as _Hostname
+from '__go__/reflect' import MakeSlice as _MakeSlice
+from '__go__/time' import (
+    Duration as _Duration,
+    Now as _Now,
+    Second as _Second,
+    Time as _Time
+)
+
+
+AF_INET = 2
+AF_INET6 = 10
+SOCK_STREAM = 1
+SOCK_DGRAM = 2
+SOL_SOCKET = 1
+SO_REUSEADDR = 2
+IPPROTO_TCP = 6
+TCP_NODELAY = 1
+SHUT_RD = 0
+SHUT_WR = 1
+SHUT_RDWR = 2
+
+_GLOBAL_DEFAULT_TIMEOUT = object()
+
+# The reflect.Type of []byte, used to allocate read buffers.
+_byte_slice_type = ToNative(__frame__(), _ReadAll).Type().Out(0)
+
+
+class error(IOError):
+  pass
+
+
+class herror(error):
+  pass
+
+
+class gaierror(error):
+  pass
+
+
+class timeout(error):
+  pass
+
+
+def gethostname():
+  name, err = _Hostname()
+  if err:
+    raise error(err.Error())
+  return name
+
+
+def gethostbyname(hostname):
+  addrs, err = _LookupHost(hostname)
+  if err:
+    raise gaierror(err.Error())
+  for addr in addrs:
+    if ':' not in addr:
+      return addr
+  raise gaierror('no IPv4 address for host: %s' % hostname)
+
+
+def create_connection(address, timeout=_GLOBAL_DEFAULT_TIMEOUT,  # pylint: disable=redefined-outer-name
+                      source_address=None):
+  """Connects to a TCP service at address, a (host, port) tuple."""
+  if source_address is not None:
+    raise NotImplementedError('source_address is not supported')
+  sock = socket(AF_INET, SOCK_STREAM)
+  if timeout is not _GLOBAL_DEFAULT_TIMEOUT:
+    sock.settimeout(timeout)
+  sock.connect(address)
+  return sock
+
+
+class socket(object):  # pylint: disable=invalid-name
+  """A TCP or UDP socket.
+
+  TCP sockets start listening as soon as they are bound so that getsockname()
+  reports the real address when binding to port zero. listen() is therefore
+  a no-op and the backlog is ignored.
+  """
+
+  def __init__(self, family=AF_INET, type=SOCK_STREAM, proto=0):  # pylint: disable=redefined-builtin
+    if family not in (AF_INET, AF_INET6):
+      raise error('unsupported address family: %r' % family)
+    if type not in (SOCK_STREAM, SOCK_DGRAM):
+      raise error('unsupported socket type: %r' % type)
+    self.family = family
+    self.type = type
+    self.proto = proto
+    self._timeout = None
+    self._listener = None
+    self._conn = None
+    self._packet_conn = None
+    self._closed = False
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.close()
+
+  def _network(self):
+    if self.type == SOCK_STREAM:
+      net = 'tcp'
+    else:
+      net = 'udp'
+    if self.family == AF_INET:
+      return net + '4'
+    return net + '6'
+
+  def _check_open(self):
+    if self._closed:
+      raise error('Bad file descriptor')
+
+  def _set_deadline(self, obj):
+    if self._timeout is None:
+      t = _Time.new()
+    else:
+      t = _Now().Add(_Duration(int(self._timeout * _Second)))
+    err = obj.SetDeadline(t)
+    if err:
+      raise error(err.Error())
+
+  def _raise(self, err):
+    if hasattr(err, 'Timeout') and err.Timeout():
+      raise timeout('timed out')
+    raise error(err.Error())
+
+  def settimeout(self, value):
+    if value is not None:
+      value = float(value)
+      if value < 0:
+        raise ValueError('Timeout value out of range')
+    self._timeout = value
+
+  def gettimeout(self):
+    return self._timeout
+
+  def setblocking(self, flag):
+    self.settimeout(None if flag else 0.0)
+
+  def setsockopt(self, level, optname, value):  # pylint: disable=unused-argument
+    # Go sets SO_REUSEADDR on listening sockets and TCP_NODELAY on TCP
+    # connections, so options are accepted but otherwise ignored.
+    self._check_open()
+
+  def bind(self, address):
+    self._check_open()
+    if self._listener or self._conn or self._packet_conn:
+      raise error('Invalid argument')
+    addr = _format_address(address)
+    if self.type == SOCK_STREAM:
+      self._listener, err = _Listen(self._network(), addr)
+    else:
+      self._packet_conn, err = _ListenPacket(self._network(), addr)
+    if err:
+      raise error(err.Error())
+
+  def listen(self, backlog=0):  # pylint: disable=unused-argument
+    self._check_open()
+    if self.type != SOCK_STREAM:
+      raise error('Operation not supported')
+    if not self._listener:
+      self.bind(('', 0))
+
+  def accept(self):
+    self._check_open()
+    if not self._listener:
+      raise error('Invalid argument')
+    self._set_deadline(self._listener)
+    conn, err = self._listener.Accept()
+    if err:
+      self._raise(err)
+    sock = socket(self.family, self.type, self.proto)
+    sock._conn = conn  # pylint: disable=protected-access
+    return sock, _parse_address(conn.RemoteAddr().String())
+
+  def connect(self, address):
+    self._check_open()
+    if self._listener or self._conn:
+      raise error('Transport endpoint is already connected')
+    conn, err = _Dial(self._network(), _format_address(address))
+    if err:
+      raise error(err.Error())
+    self._conn = conn
+
+  def getsockname(self):
+    self._check_open()
+    obj = self._listener or self._conn or self._packet_conn
+    if not obj:
+      if self.family == AF_INET:
+        return ('0.0.0.0', 0)
+      return ('::', 0)
+    if obj is self._listener:
+      return _parse_address(obj.Addr().String())
+    return _parse_address(obj.LocalAddr().String())
+
+  def getpeername(self):
+    self._check_open()
+    if not self._conn:
+      raise error('Transport endpoint is not connected')
+    return _parse_address(self._conn.RemoteAddr().String())
+
+  def recv(self, bufsize, flags=0):  # pylint: disable=unused-argument
+    self._check_open()
+    if self._packet_conn:
+      return self.recvfrom(bufsize)[0]
+    if not self._conn:
+      raise error('Transport endpoint is not connected')
+    buf = _MakeSlice(_byte_slice_type, bufsize, bufsize).Interface()
+    self._set_deadline(self._conn)
+    n, err = self._conn.Read(buf)
+    if n:
+      return _bytes_to_str(buf, n)
+    if err and err.Error() != 'EOF':
+      self._raise(err)
+    return ''
+
+  def recvfrom(self, bufsize, flags=0):  # pylint: disable=unused-argument
+    self._check_open()
+    if self._conn:
+      return self.recv(bufsize), None
+    if not self._packet_conn:
+      raise error('Invalid argument')
+    buf = _MakeSlice(_byte_slice_type, bufsize, bufsize).Interface()
+    self._set_deadline(self._packet_conn)
+    n, addr, err = self._packet_conn.ReadFrom(buf)
+    if err:
+      self._raise(err)
+    return _bytes_to_str(buf, n), _parse_address(addr.String())
+
+  def send(self, data, flags=0):  # pylint: disable=unused-argument
+    self._check_open()
+    if not self._conn:
+      raise error('Transport endpoint is not connected')
+    self._set_deadline(self._conn)
+    n, err = self._conn.Write(data)
+    if err:
+      self._raise(err)
+    return n
+
+  def sendall(self, data, flags=0):
+    # Go's Write only returns early on error, so send() writes everything.
+    self.send(data, flags)
+
+  def sendto(self, data, address):
+    self._check_open()
+    if self._conn:
+      return self.send(data)
+    if not self._packet_conn:
+      self.bind(('', 0))
+    addr, err = _ResolveUDPAddr(self._network(), _format_address(address))
+    if err:
+      raise gaierror(err.Error())
+    self._set_deadline(self._packet_conn)
+    n, err = self._packet_conn.WriteTo(data, addr)
+    if err:
+      self._raise(err)
+    return n
+
+  def makefile(self, mode='r', bufsize=-1):  # pylint: disable=unused-argument
+    return _fileobject(self, mode)
+
+  def shutdown(self, how):
+    self._check_open()
+    if not self._conn:
+      raise error('Transport endpoint is not connected')
+    if how in (SHUT_RD, SHUT_RDWR) and hasattr(self._conn, 'CloseRead'):
+      self._conn.CloseRead()
+    if how in (SHUT_WR, SHUT_RDWR) and hasattr(self._conn, 'CloseWrite'):
+      self._conn.CloseWrite()
+
+  def close(self):
+    if self._closed:
+      return
+    self._closed = True
+    for obj in (self._listener, self._conn, self._packet_conn):
+      if obj:
+        obj.Close()
+
+
+class _fileobject(object):  # pylint: disable=invalid-name
+  """A file-like object that reads from and writes to a connected socket."""
+
+  def __init__(self, sock, mode='r'):
+    self._sock = sock
+    self.mode = mode
+    self._rbuf = ''
+    self.closed = False
+
+  def _fill(self):
+    data = self._sock.recv(8192)
+    self._rbuf += data
+    return bool(data)
+
+  def read(self, size=-1):
+    while size < 0 or len(self._rbuf) < size:
+      if not self._fill():
+        break
+    if size < 0:
+      size = len(self._rbuf)
+    data, self._rbuf = self._rbuf[:size], self._rbuf[size:]
+    return data
+
+  def readline(self, size=-1):
+    while '\n' not in self._rbuf and (size < 0 or len(self._rbuf) < size):
+      if not self._fill():
+        break
+    i = self._rbuf.find('\n') + 1
+    if not i:
+      i = len(self._rbuf)
+    if size >= 0:
+      i = min(i, size)
+    line, self._rbuf = self._rbuf[:i], self._rbuf[i:]
+    return line
+
+  def readlines(self, sizehint=0):  # pylint: disable=unused-argument
+    return list(self)
+
+  def __iter__(self):
+    line = self.readline()
+    while line:
+      yield line
+      line = self.readline()
+
+  def write(self, data):
+    self._sock.sendall(data)
+
+  def writelines(self, lines):
+    for line in lines:
+      self.write(line)
+
+  def flush(self):
+    pass
+
+  def close(self):
+    self.closed = True
+
+
+def _format_address(address):
+  host, port = address
+  return _JoinHostPort(host, str(port))
+
+
+def _parse_address(s):
+  host, port, err = _SplitHostPort(s)
+  if err:
+    raise error(err.Error())
+  return host, int(port)
+
+
+def _bytes_to_str(buf, n):
+  return ''.join(chr(b) for b in buf[:n])
@@ -0,0 +1,127 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import socket
+import threading
+
+import weetest
+
+
+def _Serve(handler):
+  server = socket.socket()
+  server.bind(('127.0.0.1', 0))
+  server.listen(1)
+  def Accept():
+    conn, _ = server.accept()
+    try:
+      handler(conn)
+    finally:
+      conn.close()
+  t = threading.Thread(target=Accept)
+  t.start()
+  return server, t
+
+
+def TestTCPEcho():
+  def Echo(conn):
+    data = conn.recv(1024)
+    while data:
+      conn.sendall(data.upper())
+      data = conn.recv(1024)
+  server, t = _Serve(Echo)
+  host, port = server.getsockname()
+  assert host == '127.0.0.1', host
+  assert port > 0, port
+  client = socket.create_connection((host, port))
+  client.sendall('foo')
+  assert client.recv(1024) == 'FOO'
+  assert client.getpeername() == (host, port)
+  client.shutdown(socket.SHUT_WR)
+  assert client.recv(1024) == ''
+  client.close()
+  t.join()
+  server.close()
+
+
+def TestMakefile():
+  def Greet(conn):
+    f = conn.makefile('rb')
+    name = f.readline().strip()
+    conn.sendall('hello %s\nbye\n' % name)
+  server, t = _Serve(Greet)
+  client = socket.create_connection(server.getsockname())
+  client.sendall('world\n')
+  f = client.makefile('rb')
+  assert list(f) == ['hello world\n', 'bye\n']
+  client.close()
+  t.join()
+  server.close()
+
+
+def TestAcceptTimeout():
+  server = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
+  server.bind(('127.0.0.1', 0))
+  server.listen(1)
+  server.settimeout(0.01)
+  assert server.gettimeout() == 0.01
+  try:
+    server.accept()
+  except socket.timeout as e:
+    assert isinstance(e, socket.error)
+  else:
+    assert False
+  server.close()
+
+
+def TestUDP():
+  server = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+  server.bind(('127.0.0.1', 0))
+  client = socket.socket(socket.AF_INET, socket.SOCK_DGRAM)
+  client.sendto('ping', server.getsockname())
+  data, addr = server.recvfrom(1024)
+  assert data == 'ping'
+  assert addr[1] == client.getsockname()[1], (addr, client.getsockname())
+  server.sendto('pong', addr)
+  assert client.recvfrom(1024) == ('pong', server.getsockname())
+  client.close()
+  server.close()
+
+
+def TestClosed():
+  s = socket.socket()
+  s.close()
+  try:
+    s.bind(('127.0.0.1', 0))
+  except socket.error:
+    pass
+  else:
+    assert False
+
+
+def TestConnectRefused():
+  server = socket.socket()
+  server.bind(('127.0.0.1', 0))
+  addr = server.getsockname()
+  server.close()
+  try:
+    socket.create_connection(addr)
+  except socket.error:
+    pass
+  else:
+    assert False
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -14,13 +14,78 @@
 
 """Interpreting stat() results."""
 
-# pylint: disable=g-multiple-import
-from '__go__/os' import ModeDir, ModePerm
+# pylint: disable=invalid-name
 
+# Indices for stat struct members in the tuple returned by os.stat().
+ST_MODE = 0
+ST_INO = 1
+ST_DEV = 2
+ST_NLINK = 3
+ST_UID = 4
+ST_GID = 5
+ST_SIZE = 6
+ST_ATIME = 7
+ST_MTIME = 8
+ST_CTIME = 9
 
-def S_ISDIR(mode):  # pylint: disable=invalid-name
-  return mode & ModeDir != 0
+S_IFMT_MASK = 0o170000
+S_IFSOCK = 0o140000
+S_IFLNK = 0o120000
+S_IFREG = 0o100000
+S_IFBLK = 0o060000
+S_IFDIR = 0o040000
+S_IFCHR = 0o020000
+S_IFIFO = 0o010000
 
+S_ISUID = 0o4000
+S_ISGID = 0o2000
+S_ISVTX = 0o1000
 
-def S_IMODE(mode):  # pylint: disable=invalid-name
-  return mode & ModePerm
+S_IRWXU = 0o0700
+S_IRUSR = 0o0400
+S_IWUSR = 0o0200
+S_IXUSR = 0o0100
+S_IRWXG = 0o0070
+S_IRGRP = 0o0040
+S_IWGRP = 0o0020
+S_IXGRP = 0o0010
+S_IRWXO = 0o0007
+S_IROTH = 0o0004
+S_IWOTH = 0o0002
+S_IXOTH = 0o0001
+
+
+def S_IMODE(mode):
+  return mode & 0o7777
+
+
+def S_IFMT(mode):
+  return mode & S_IFMT_MASK
+
+
+def S_ISDIR(mode):
+  return S_IFMT(mode) == S_IFDIR
+
+
+def S_ISCHR(mode):
+  return S_IFMT(mode) == S_IFCHR
+
+
+def S_ISBLK(mode):
+  return S_IFMT(mode) == S_IFBLK
+
+
+def S_ISREG(mode):
+  return S_IFMT(mode) == S_IFREG
+
+
+def S_ISFIFO(mode):
+  return S_IFMT(mode) == S_IFIFO
+
+
+def S_ISLNK(mode):
+  return S_IFMT(mode) == S_IFLNK
+
+
+def S_ISSOCK(mode):
+  return S_IFMT(mode) == S_IFSOCK
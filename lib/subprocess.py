@@ -0,0 +1,222 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Subprocess management implemented on top of Go's os/exec package."""
+
+# pylint: disable=g-multiple-import
+from '__go__/grumpy' import NewFileFromFD, StartThread, ToNative
+from '__go__/os' import NewFile, Pipe, Stdin, Stdout, Stderr
+from '__go__/os/exec' import Command
+from '__go__/reflect' import MakeSlice
+from '__go__/sync' import WaitGroup
+from '__go__/syscall' import CloseOnExec, Dup, SIGKILL, SIGTERM, Signal
+import os
+
+
+PIPE = -1
+STDOUT = -2
+
+
+def _dup(fd):
+  dup, err = Dup(fd)
+  if err:
+    raise OSError(err.Error())
+  # Unlike the descriptors created by os.Pipe(), dups are inherited by child
+  # processes by default, which would keep pipes open after the parent closes
+  # them.
+  CloseOnExec(dup)
+  return dup
+
+
+def _get_args(popenargs, kwargs):
+  args = kwargs.get('args')
+  if args is None:
+    args = popenargs[0]
+  return args
+
+
+class CalledProcessError(Exception):
+  """Raised when a process run by check_call() or check_output() fails."""
+
+  def __init__(self, returncode, cmd, output=None):
+    super(CalledProcessError, self).__init__(returncode, cmd, output)
+    self.returncode = returncode
+    self.cmd = cmd
+    self.output = output
+
+  def __str__(self):
+    return "Command '%s' returned non-zero exit status %d" % (
+        self.cmd, self.returncode)
+
+
+class Popen(object):
+  """Execute a child program in a new process."""
+
+  def __init__(self, args, stdin=None, stdout=None, stderr=None, shell=False,
+               cwd=None, env=None):
+    if isinstance(args, basestring):
+      args = [args]
+    else:
+      args = list(args)
+    if shell:
+      args = ['/bin/sh', '-c'] + args
+    if not args:
+      raise ValueError('args must not be empty')
+    self.args = args
+    self.stdin = None
+    self.stdout = None
+    self.stderr = None
+    self.returncode = None
+    # Go files that must be closed in the parent once the child has started.
+    self._child_files = []
+    self._cmd = Command(*args)
+    if cwd is not None:
+      self._cmd.Dir = cwd
+    if env is not None:
+      # TODO: There should be a cleaner way to create slices in Python.
+      env_type = ToNative(__frame__(), self._cmd.Args).Type()
+      env_slice = MakeSlice(env_type, len(env), len(env)).Interface()
+      for i, (k, v) in enumerate(env.iteritems()):
+        env_slice[i] = '%s=%s' % (k, v)
+      self._cmd.Env = env_slice
+    try:
+      self._cmd.Stdin, self.stdin = self._child_file(stdin, Stdin, False)
+      self._cmd.Stdout, self.stdout = self._child_file(stdout, Stdout, True)
+      if stderr == STDOUT:
+        self._cmd.Stderr = self._cmd.Stdout
+      else:
+        self._cmd.Stderr, self.stderr = self._child_file(stderr, Stderr, True)
+      os._run_at_fork_hooks('before')  # pylint: disable=protected-access
+      try:
+        err = self._cmd.Start()
+      finally:
+        os._run_at_fork_hooks('after_in_parent')  # pylint: disable=protected-access
+      if err:
+        raise OSError(err.Error())
+    finally:
+      for f in self._child_files:
+        f.Close()
+      self._child_files = []
+    self.pid = self._cmd.Process.Pid
+    self._wg = WaitGroup.new()
+    self._wg.Add(1)
+    StartThread(__frame__(), self._wait_thread)  # pylint: disable=undefined-variable
+
+  def _child_file(self, spec, default, readable):
+    """Returns the Go file for the child and the parent's Python file."""
+    if spec is None:
+      return default, None
+    if spec == PIPE:
+      r, w, err = Pipe()
+      if err:
+        raise OSError(err.Error())
+      if readable:
+        child, parent = w, r
+      else:
+        child, parent = r, w
+      self._child_files.append(child)
+      # The Python file gets its own descriptor so that it is not closed out
+      # from under it when the Go file is finalized.
+      try:
+        return child, NewFileFromFD(_dup(parent.Fd()), None)
+      finally:
+        parent.Close()
+    if isinstance(spec, int):
+      fd = spec
+    else:
+      fd = spec.fileno()
+    f = NewFile(_dup(fd), '')
+    self._child_files.append(f)
+    return f, None
+
+  def _wait_thread(self):
+    # Wait returns an error for non-zero exit codes so consult the process
+    # state directly.
+    self._cmd.Wait()
+    status = self._cmd.ProcessState.Sys()
+    if status.Signaled():
+      self.returncode = -int(status.Signal())
+    else:
+      self.returncode = status.ExitStatus()
+    self._wg.Done()
+
+  def poll(self):
+    return self.returncode
+
+  def wait(self):
+    self._wg.Wait()
+    return self.returncode
+
+  def communicate(self, input=None):  # pylint: disable=redefined-builtin
+    """Sends input to stdin and reads stdout and stderr until EOF."""
+    stderr_data = []
+    wg = WaitGroup.new()
+    if self.stderr:
+      def ReadStderr():
+        stderr_data.append(self.stderr.read())
+        self.stderr.close()
+        wg.Done()
+      wg.Add(1)
+      StartThread(__frame__(), ReadStderr)  # pylint: disable=undefined-variable
+    if self.stdin:
+      if input:
+        self.stdin.write(input)
+      self.stdin.close()
+    stdout = None
+    if self.stdout:
+      stdout = self.stdout.read()
+      self.stdout.close()
+    wg.Wait()
+    self.wait()
+    return stdout, stderr_data[0] if stderr_data else None
+
+  def send_signal(self, sig):
+    if self.returncode is None:
+      err = self._cmd.Process.Signal(Signal(sig))
+      if err:
+        raise OSError(err.Error())
+
+  def terminate(self):
+    self.send_signal(SIGTERM)
+
+  def kill(self):
+    self.send_signal(SIGKILL)
+
+
+def call(*popenargs, **kwargs):
+  """Runs a command and returns its exit code."""
+  return Popen(*popenargs, **kwargs).wait()
+
+
+def check_call(*popenargs, **kwargs):
+  """Runs a command, raising CalledProcessError on a non-zero exit code."""
+  retcode = call(*popenargs, **kwargs)
+  if retcode:
+    raise CalledProcessError(retcode, _get_args(popenargs, kwargs))
+  return 0
+
+
+def check_output(*popenargs, **kwargs):
+  """Runs a command and returns its output as a string.
+
+  CalledProcessError is raised if the command exits with a non-zero code.
+  """
+  if 'stdout' in kwargs:
+    raise ValueError('stdout argument not allowed, it will be overridden.')
+  process = Popen(stdout=PIPE, *popenargs, **kwargs)
+  output, _ = process.communicate()
+  retcode = process.poll()
+  if retcode:
+    raise CalledProcessError(retcode, _get_args(popenargs, kwargs), output)
+  return output
@@ -0,0 +1,141 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import subprocess
+import tempfile
+
+import weetest
+
+
+def TestCall():
+  assert subprocess.call(['true']) == 0
+  assert subprocess.call(['false']) == 1
+  assert subprocess.call('exit 3', shell=True) == 3
+
+
+def TestCallNoExist():
+  try:
+    subprocess.call(['/nonexistent/program'])
+  except OSError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestCheckCall():
+  assert subprocess.check_call(['true']) == 0
+  try:
+    subprocess.check_call(['false'])
+  except subprocess.CalledProcessError as e:
+    assert e.returncode == 1
+    assert e.cmd == ['false']
+  else:
+    raise AssertionError
+
+
+def TestCheckOutput():
+  assert subprocess.check_output(['echo', 'foo', 'bar']) == 'foo bar\n'
+  try:
+    subprocess.check_output('echo baz; exit 2', shell=True)
+  except subprocess.CalledProcessError as e:
+    assert e.returncode == 2
+    assert e.output == 'baz\n'
+  else:
+    raise AssertionError
+
+
+def TestCheckOutputCwd():
+  path = tempfile.mkdtemp()
+  try:
+    assert path in subprocess.check_output(['pwd'], cwd=path)
+  finally:
+    os.rmdir(path)
+
+
+def TestCheckOutputEnv():
+  output = subprocess.check_output('echo $FOO', shell=True, env={'FOO': 'qux'})
+  assert output == 'qux\n'
+
+
+def TestCommunicate():
+  p = subprocess.Popen(['cat'], stdin=subprocess.PIPE, stdout=subprocess.PIPE)
+  assert p.communicate('foobar') == ('foobar', None)
+  assert p.returncode == 0
+
+
+def TestCommunicateStderr():
+  p = subprocess.Popen('echo foo; echo bar >&2', shell=True,
+                       stdout=subprocess.PIPE, stderr=subprocess.PIPE)
+  assert p.communicate() == ('foo\n', 'bar\n')
+
+
+def TestPopenStderrToStdout():
+  p = subprocess.Popen('echo foo; echo bar >&2', shell=True,
+                       stdout=subprocess.PIPE, stderr=subprocess.STDOUT)
+  stdout, stderr = p.communicate()
+  assert stdout == 'foo\nbar\n'
+  assert stderr is None
+
+
+def TestPopenStdoutToFile():
+  fd, path = tempfile.mkstemp()
+  try:
+    with open(path, 'w') as f:
+      assert subprocess.call(['echo', 'foo'], stdout=f) == 0
+    with open(path) as f:
+      assert f.read() == 'foo\n'
+  finally:
+    os.close(fd)
+    os.remove(path)
+
+
+def TestPopenRunsAtForkHooks():
+  calls = []
+  def Before():
+    calls.append('before')
+    raise RuntimeError('hook errors are ignored')
+  os.register_at_fork(before=Before,
+                      after_in_parent=lambda: calls.append('after'))
+  assert subprocess.call(['true']) == 0
+  assert calls == ['before', 'after'], calls
+  del calls[:]
+  try:
+    subprocess.call(['/nonexistent/program'])
+  except OSError:
+    pass
+  assert calls == ['before', 'after'], calls
+
+
+def TestPopenKill():
+  p = subprocess.Popen(['sleep', '10'])
+  assert p.poll() is None
+  p.kill()
+  assert p.wait() == -9
+
+
+def TestPopenSendSignalInt():
+  p = subprocess.Popen(['sleep', '10'])
+  p.send_signal(15)
+  assert p.wait() == -15
+
+
+def TestPopenTerminate():
+  p = subprocess.Popen(['sleep', '10'])
+  p.terminate()
+  assert p.wait() == -15
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
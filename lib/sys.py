@@ -16,6 +16,7 @@
 
 from '__go__/os' import Args
 from '__go__/grumpy' import SysModules, MaxInt, Stdin as stdin, Stdout as stdout, Stderr as stderr  # pylint: disable=g-multiple-import
+from '__go__/grumpy' import GetTrace, SetTrace
 from '__go__/runtime' import (GOOS as platform, Version)
 from '__go__/unicode' import MaxRune
 
@@ -28,12 +29,38 @@ maxint = MaxInt
 maxsize = maxint
 maxunicode = MaxRune
 modules = SysModules
+# The original streams, so that they can be restored after sys.stdout and
+# friends have been reassigned.
+__stdin__ = stdin
+__stdout__ = stdout
+__stderr__ = stderr
 py3kwarning = False
 warnoptions = []
 # TODO: Support actual byteorder
 byteorder = 'little'
 version = '2.7.13'
 
+
+class _VersionInfo(tuple):
+  """Type of sys.version_info, whose fields are also named attributes."""
+
+  def __new__(cls, major, minor, micro, releaselevel, serial):
+    return tuple.__new__(cls, (major, minor, micro, releaselevel, serial))
+
+  major = property(lambda self: self[0])
+  minor = property(lambda self: self[1])
+  micro = property(lambda self: self[2])
+  releaselevel = property(lambda self: self[3])
+  serial = property(lambda self: self[4])
+
+  def __repr__(self):
+    return ('sys.version_info(major=%r, minor=%r, micro=%r, '
+            'releaselevel=%r, serial=%r)' % self)
+
+
+version_info = _VersionInfo(2, 7, 13, 'final', 0)
+
+
 class _Flags(object):
   """Container class for sys.flags."""
   debug = 0
@@ -74,10 +101,19 @@ def exit(code=None):  # pylint: disable=redefined-builtin
 
 
 def _getframe(depth=0):
-  f = __frame__()
+  """Returns the frame depth calls below the caller's frame on the stack."""
+  f = __frame__().f_back  # pylint: disable=undefined-variable
   while depth > 0 and f is not None:
     f = f.f_back
     depth -= 1
   if f is None:
     raise ValueError('call stack is not deep enough')
   return f
+
+
+def gettrace():
+  return GetTrace(__frame__())  # pylint: disable=undefined-variable
+
+
+def settrace(func):
+  SetTrace(__frame__(), func)  # pylint: disable=undefined-variable
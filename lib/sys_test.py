@@ -14,6 +14,8 @@
 
 # pylint: disable=bare-except
 
+import __builtin__
+import StringIO
 import sys
 import types
 
@@ -28,10 +30,59 @@ def TestMaxInt():
   assert sys.maxint > 2000000000
 
 
+def TestVersionInfo():
+  assert sys.version_info[:2] == (2, 7)
+  assert sys.version_info.major == 2
+  assert sys.version_info.minor == 7
+  assert sys.version_info.releaselevel == 'final'
+  assert sys.version_info >= (2, 7)
+  assert repr(sys.version_info) == (
+      "sys.version_info(major=2, minor=7, micro=13, releaselevel='final', "
+      'serial=0)')
+  assert sys.version.startswith('%d.%d.%d' % sys.version_info[:3])
+
+
 def TestSysModules():
   assert sys.modules['sys'] is not None
 
 
+def TestStdStreams():
+  assert sys.stdin is sys.__stdin__
+  assert sys.stdout is sys.__stdout__
+  assert sys.stderr is sys.__stderr__
+  assert isinstance(sys.stdout, file)
+
+
+def TestStdoutRedirect():
+  buf = StringIO.StringIO()
+  sys.stdout = buf
+  try:
+    print 'foo', 123
+    getattr(__builtin__, 'print')('bar', 'baz', sep='-')
+  finally:
+    sys.stdout = sys.__stdout__
+  assert buf.getvalue() == 'foo 123\nbar-baz\n', buf.getvalue()
+
+
+def TestRawInputRedirect():
+  sys.stdin = StringIO.StringIO('foo\nbar')
+  sys.stdout = StringIO.StringIO()
+  try:
+    assert raw_input('>') == 'foo'
+    assert raw_input() == 'bar'
+    try:
+      raw_input()
+    except EOFError:
+      pass
+    else:
+      assert False
+    prompt = sys.stdout.getvalue()
+  finally:
+    sys.stdin = sys.__stdin__
+    sys.stdout = sys.__stdout__
+  assert prompt == '>', prompt
+
+
 def TestExcClear():
   try:
     raise RuntimeError
@@ -99,8 +150,64 @@ def TestGetFrame():
     pass
   else:
     assert False
-  assert sys._getframe().f_code.co_name == '_getframe'
-  assert sys._getframe(1).f_code.co_name == 'TestGetFrame'
+  f = sys._getframe()
+  lineno = f.f_lineno
+  assert f.f_code.co_name == 'TestGetFrame'
+  assert f.f_globals is globals()
+  assert f.f_lineno == lineno + 3
+  assert sys._getframe(1) is f.f_back
+  assert sys._getframe(1).f_code.co_name == '_RunOneTest'
+
+
+def TestGetFrameLocals():
+  def Foo(a):
+    b = a + 1
+    return sys._getframe().f_locals, locals()
+  def Bar():
+    return sys._getframe().f_locals
+  f_locals, l = Foo(1)
+  assert f_locals == l == {'a': 1, 'b': 2}
+  assert Bar() == {}
+  assert sys._getframe(1).f_locals == {}
+
+
+def TestGetFrameLocalsTraced():
+  def Baz():
+    c = 3
+    return c
+  seen = []
+  def Tracer(frame, event, unused_arg):
+    if frame.f_code.co_name == 'Baz':
+      seen.append((event, dict(frame.f_locals)))
+      return Tracer
+  sys.settrace(Tracer)
+  try:
+    Baz()
+  finally:
+    sys.settrace(None)
+  assert seen == [('call', {}), ('line', {}), ('line', {'c': 3}),
+                  ('return', {'c': 3})], seen
+
+
+def TestSetTrace():
+  events = []
+  def Tracer(frame, event, arg):
+    if frame.f_code is Foo.func_code:
+      events.append((event, frame.f_lineno - lineno, arg))
+    return Tracer
+  def Foo(x):
+    y = x + 1
+    return y
+  lineno = Foo.func_code.co_firstlineno
+  sys.settrace(Tracer)
+  try:
+    assert sys.gettrace() is Tracer
+    Foo(1)
+  finally:
+    sys.settrace(None)
+  assert sys.gettrace() is None
+  assert events == [('call', 0, None), ('line', 1, None), ('line', 2, None),
+                    ('return', 2, 2)], events
 
 
 if __name__ == '__main__':
@@ -0,0 +1,349 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""TELNET client class compatible with CPython's telnetlib.Telnet.
+
+Sockets have no file descriptors that can be passed to select() so reads are
+driven by socket timeouts instead. Option negotiation follows CPython: every
+DO/DONT is refused with WONT and every WILL/WONT with DONT unless a callback
+is installed with set_option_negotiation_callback().
+"""
+
+import re
+import socket
+import time
+
+__all__ = ['Telnet']
+
+DEBUGLEVEL = 0
+TELNET_PORT = 23
+
+# Telnet protocol characters (don't change).
+IAC = chr(255)  # "Interpret As Command"
+DONT = chr(254)
+DO = chr(253)
+WONT = chr(252)
+WILL = chr(251)
+theNULL = chr(0)
+
+SE = chr(240)  # Subnegotiation End
+NOP = chr(241)  # No Operation
+DM = chr(242)  # Data Mark
+BRK = chr(243)  # Break
+IP = chr(244)  # Interrupt process
+AO = chr(245)  # Abort output
+AYT = chr(246)  # Are You There
+EC = chr(247)  # Erase Character
+EL = chr(248)  # Erase Line
+GA = chr(249)  # Go Ahead
+SB = chr(250)  # Subnegotiation Begin
+
+# Telnet protocol options code (don't change).
+BINARY = chr(0)  # 8-bit data path
+ECHO = chr(1)  # echo
+SGA = chr(3)  # suppress go ahead
+STATUS = chr(5)  # give status
+TM = chr(6)  # timing mark
+TTYPE = chr(24)  # terminal type
+NAWS = chr(31)  # window size
+LINEMODE = chr(34)  # Linemode option
+NOOPT = chr(0)
+
+# How long the "eager" reads wait for data that is already in flight. A zero
+# socket timeout fails immediately without reading anything that has arrived.
+_POLL_INTERVAL = 0.001
+
+_RECV_SIZE = 4096
+
+
+class Telnet(object):
+  """Telnet interface class.
+
+  The interface mirrors CPython's: read_until(), read_all(), read_some(),
+  read_eager(), read_very_eager(), read_lazy(), read_very_lazy(), expect()
+  and write(). The read methods raise EOFError when the connection is closed
+  and no cooked data is available.
+  """
+
+  def __init__(self, host=None, port=0,
+               timeout=socket._GLOBAL_DEFAULT_TIMEOUT):  # pylint: disable=protected-access
+    self.debuglevel = DEBUGLEVEL
+    self.host = host
+    self.port = port
+    self.timeout = timeout
+    self.sock = None
+    self.cookedq = ''
+    self.eof = 0
+    self.iacseq = ''  # Buffer for IAC sequence.
+    self.sb = 0  # Flag for SB and SE sequence.
+    self.sbdataq = ''
+    self.option_callback = None
+    if host is not None:
+      self.open(host, port, timeout)
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.close()
+
+  def open(self, host, port=0,
+           timeout=socket._GLOBAL_DEFAULT_TIMEOUT):  # pylint: disable=protected-access
+    """Connect to a host. Don't try to reopen an already connected instance."""
+    self.eof = 0
+    if not port:
+      port = TELNET_PORT
+    self.host = host
+    self.port = port
+    self.timeout = timeout
+    self.sock = socket.create_connection((host, port), timeout)
+
+  def msg(self, msg, *args):
+    """Print a debug message, when the debug level is > 0."""
+    if self.debuglevel > 0:
+      print 'Telnet(%s,%s):' % (self.host, self.port),
+      if args:
+        print msg % args
+      else:
+        print msg
+
+  def set_debuglevel(self, debuglevel):
+    self.debuglevel = debuglevel
+
+  def close(self):
+    """Close the connection."""
+    sock = self.sock
+    self.sock = None
+    self.eof = 1
+    self.iacseq = ''
+    self.sb = 0
+    if sock:
+      sock.close()
+
+  def get_socket(self):
+    return self.sock
+
+  def write(self, buf):
+    """Write a string to the socket, doubling any IAC characters."""
+    if IAC in buf:
+      buf = buf.replace(IAC, IAC + IAC)
+    self.msg('send %r', buf)
+    self.sock.settimeout(self._default_timeout())
+    self.sock.sendall(buf)
+
+  def read_until(self, match, timeout=None):
+    """Read until a given string is encountered or until timeout.
+
+    When no match is found, return whatever is available instead, possibly
+    the empty string. Raise EOFError if the connection is closed and no
+    cooked data is available.
+    """
+    deadline = _deadline(timeout)
+    while True:
+      i = self.cookedq.find(match)
+      if i >= 0:
+        i += len(match)
+        buf = self.cookedq[:i]
+        self.cookedq = self.cookedq[i:]
+        return buf
+      if self.eof:
+        break
+      remaining = _remaining(deadline)
+      if remaining is not None and remaining <= 0:
+        break
+      self.fill_rawq(remaining)
+    return self.read_very_lazy()
+
+  def read_all(self):
+    """Read all data until EOF; block until connection closed."""
+    while not self.eof:
+      self.fill_rawq()
+    buf = self.cookedq
+    self.cookedq = ''
+    return buf
+
+  def read_some(self):
+    """Read at least one byte of cooked data unless EOF is hit.
+
+    Return '' if EOF is hit. Block if no data is immediately available.
+    """
+    while not self.cookedq and not self.eof:
+      self.fill_rawq()
+    buf = self.cookedq
+    self.cookedq = ''
+    return buf
+
+  def read_very_eager(self):
+    """Read everything that's possible without blocking.
+
+    Raise EOFError if connection closed and no cooked data available. Return
+    '' if no cooked data available otherwise.
+    """
+    while not self.eof and self.fill_rawq(_POLL_INTERVAL):
+      pass
+    return self.read_very_lazy()
+
+  def read_eager(self):
+    """Read readily available data.
+
+    Raise EOFError if connection closed and no cooked data available. Return
+    '' if no cooked data available otherwise.
+    """
+    while not self.cookedq and not self.eof:
+      if not self.fill_rawq(_POLL_INTERVAL):
+        break
+    return self.read_very_lazy()
+
+  def read_lazy(self):
+    """Return any data available in the cooked queue without blocking."""
+    return self.read_very_lazy()
+
+  def read_very_lazy(self):
+    """Return any data available in the cooked queue (very lazy).
+
+    Raise EOFError if connection closed and no data available.
+    """
+    buf = self.cookedq
+    self.cookedq = ''
+    if not buf and self.eof:
+      raise EOFError('telnet connection closed')
+    return buf
+
+  def read_sb_data(self):
+    """Return any data available in the SB ... SE queue."""
+    buf = self.sbdataq
+    self.sbdataq = ''
+    return buf
+
+  def set_option_negotiation_callback(self, callback):
+    """Provide a callback function called after each receipt of a command.
+
+    The callback is invoked as callback(sock, command, option) where option
+    is NOOPT for commands other than DO, DONT, WILL and WONT.
+    """
+    self.option_callback = callback
+
+  def fill_rawq(self, timeout=None):
+    """Receive and process data from the socket.
+
+    Block for at most timeout seconds, or indefinitely if timeout is None.
+    Return True if anything was received, including EOF, and False if the
+    timeout expired first.
+    """
+    self.sock.settimeout(timeout)
+    try:
+      data = self.sock.recv(_RECV_SIZE)
+    except socket.timeout:
+      return False
+    self.msg('recv %r', data)
+    if not data:
+      self.eof = 1
+    self.process_rawq(data)
+    return True
+
+  def process_rawq(self, data):
+    """Transfer data to the cooked queue, handling telnet commands."""
+    buf = ['', '']
+    for c in data:
+      if not self.iacseq:
+        if c == theNULL or c == '\021':
+          continue
+        if c == IAC:
+          self.iacseq = c
+        else:
+          buf[self.sb] += c
+      elif len(self.iacseq) == 1:
+        # 'IAC: IAC CMD [OPTION only for WILL/WONT/DO/DONT]'
+        if c in (DO, DONT, WILL, WONT):
+          self.iacseq += c
+          continue
+        self.iacseq = ''
+        if c == IAC:
+          buf[self.sb] += c
+          continue
+        if c == SB:
+          # SB ... SE start.
+          self.sb = 1
+          self.sbdataq = ''
+        elif c == SE:
+          self.sb = 0
+          self.sbdataq += buf[1]
+          buf[1] = ''
+        if self.option_callback:
+          # Callback is supposed to look into the sbdataq.
+          self.option_callback(self.sock, c, NOOPT)
+        else:
+          # We can't offer automatic processing of suboptions. Alas, we
+          # should not get any unless we did a WILL/DO before.
+          self.msg('IAC %d not recognized' % ord(c))
+      else:
+        cmd = self.iacseq[1]
+        self.iacseq = ''
+        if self.option_callback:
+          self.option_callback(self.sock, cmd, c)
+        elif cmd in (DO, DONT):
+          self.msg('IAC %s %d', cmd == DO and 'DO' or 'DONT', ord(c))
+          self.sock.sendall(IAC + WONT + c)
+        else:
+          self.msg('IAC %s %d', cmd == WILL and 'WILL' or 'WONT', ord(c))
+          self.sock.sendall(IAC + DONT + c)
+    self.cookedq += buf[0]
+    self.sbdataq += buf[1]
+
+  def expect(self, patterns, timeout=None):
+    """Read until one from a list of regular expressions matches.
+
+    Return a tuple (index, match object, text read up to and including the
+    match). If no match is found before the timeout or EOF, return
+    (-1, None, data) with whatever data was read. Raise EOFError if EOF was
+    reached and nothing was read.
+    """
+    patterns = list(patterns)
+    for i, pattern in enumerate(patterns):
+      if not hasattr(pattern, 'search'):
+        patterns[i] = re.compile(pattern)
+    deadline = _deadline(timeout)
+    while True:
+      for i, pattern in enumerate(patterns):
+        m = pattern.search(self.cookedq)
+        if m:
+          e = m.end()
+          text = self.cookedq[:e]
+          self.cookedq = self.cookedq[e:]
+          return (i, m, text)
+      if self.eof:
+        break
+      remaining = _remaining(deadline)
+      if remaining is not None and remaining <= 0:
+        break
+      self.fill_rawq(remaining)
+    text = self.read_very_lazy()
+    return (-1, None, text)
+
+  def _default_timeout(self):
+    if self.timeout is socket._GLOBAL_DEFAULT_TIMEOUT:  # pylint: disable=protected-access
+      return None
+    return self.timeout
+
+
+def _deadline(timeout):
+  if timeout is None:
+    return None
+  return time.time() + timeout
+
+
+def _remaining(deadline):
+  if deadline is None:
+    return None
+  return deadline - time.time()
@@ -0,0 +1,187 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import re
+import socket
+import telnetlib
+import threading
+
+import weetest
+
+
+def _Serve(handler):
+  """Runs handler on the first connection to a new server in a thread."""
+  server = socket.socket()
+  server.bind(('127.0.0.1', 0))
+  server.listen(1)
+  def Accept():
+    conn, _ = server.accept()
+    try:
+      handler(conn)
+    finally:
+      conn.close()
+      server.close()
+  t = threading.Thread(target=Accept)
+  t.start()
+  host, port = server.getsockname()
+  return host, port, t
+
+
+def _RecvUntil(conn, s):
+  data = ''
+  while s not in data:
+    chunk = conn.recv(1024)
+    if not chunk:
+      break
+    data += chunk
+  return data
+
+
+def TestReadUntil():
+  def Handle(conn):
+    conn.sendall('login: ')
+    name = _RecvUntil(conn, '\n')
+    conn.sendall('hello ' + name + '$ ')
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port)
+  assert tn.read_until('login: ') == 'login: '
+  tn.write('foo\n')
+  assert tn.read_until('$ ', 5) == 'hello foo\n$ '
+  assert tn.read_all() == ''
+  tn.close()
+  t.join()
+
+
+def TestReadUntilTimeout():
+  done = threading.Event()
+  def Handle(conn):
+    conn.sendall('foo')
+    done.wait()
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port)
+  try:
+    assert tn.read_until('bar', 0.05) == 'foo'
+    i, m, text = tn.expect(['bar'], 0.05)
+    assert (i, m, text) == (-1, None, ''), (i, m, text)
+  finally:
+    done.set()
+  tn.close()
+  t.join()
+
+
+def TestReadAllAndEOF():
+  def Handle(conn):
+    conn.sendall('foo\r\nbar\r\n')
+  host, port, t = _Serve(Handle)
+  with telnetlib.Telnet(host, port) as tn:
+    assert tn.read_all() == 'foo\r\nbar\r\n'
+    try:
+      tn.read_very_lazy()
+    except EOFError:
+      pass
+    else:
+      raise AssertionError
+  t.join()
+
+
+def TestExpect():
+  def Handle(conn):
+    conn.sendall('Password: ')
+    _RecvUntil(conn, '\n')
+    conn.sendall('router# ')
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port, 5)
+  i, m, text = tn.expect([r'[Ll]ogin: ', re.compile(r'[Pp]assword: ')])
+  assert (i, text) == (1, 'Password: '), (i, text)
+  assert m.group(0) == 'Password: '
+  tn.write('secret\n')
+  i, m, text = tn.expect(['> ', r'(\w+)# '], 5)
+  assert (i, m.group(1), text) == (1, 'router', 'router# ')
+  try:
+    tn.expect(['foo'])
+  except EOFError:
+    pass
+  else:
+    raise AssertionError
+  tn.close()
+  t.join()
+
+
+def TestOptionNegotiation():
+  received = []
+  def Handle(conn):
+    iac = telnetlib.IAC
+    conn.sendall(iac + telnetlib.DO + telnetlib.ECHO + 'f' +
+                 iac + telnetlib.WILL + telnetlib.SGA + 'o' + iac + iac + 'o')
+    received.append(_RecvUntil(conn, telnetlib.SGA))
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port)
+  assert tn.read_until('o' + telnetlib.IAC + 'o') == 'fo' + telnetlib.IAC + 'o'
+  t.join()
+  assert received == [telnetlib.IAC + telnetlib.WONT + telnetlib.ECHO +
+                      telnetlib.IAC + telnetlib.DONT + telnetlib.SGA], received
+  tn.close()
+
+
+def TestOptionNegotiationCallback():
+  calls = []
+  def Handle(conn):
+    conn.sendall(telnetlib.IAC + telnetlib.DO + telnetlib.NAWS + 'foo')
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port)
+  tn.set_option_negotiation_callback(
+      lambda sock, cmd, opt: calls.append((cmd, opt)))
+  assert tn.read_all() == 'foo'
+  assert calls == [(telnetlib.DO, telnetlib.NAWS)], calls
+  tn.close()
+  t.join()
+
+
+def TestWriteEscapesIAC():
+  received = []
+  def Handle(conn):
+    received.append(_RecvUntil(conn, '\n'))
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port)
+  tn.write('a' + telnetlib.IAC + 'b\n')
+  t.join()
+  assert received == ['a' + telnetlib.IAC + telnetlib.IAC + 'b\n'], received
+  tn.close()
+
+
+def TestReadVeryEager():
+  done = threading.Event()
+  def Handle(conn):
+    conn.sendall('foo')
+    done.wait()
+  host, port, t = _Serve(Handle)
+  tn = telnetlib.Telnet(host, port)
+  try:
+    assert tn.read_some() == 'foo'
+    assert tn.read_very_eager() == ''
+    assert tn.read_eager() == ''
+  finally:
+    done.set()
+  t.join()
+  try:
+    tn.read_very_eager()
+  except EOFError:
+    pass
+  else:
+    raise AssertionError
+  tn.close()
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -1,4 +1,6 @@
-from '__go__/grumpy' import NewTryableMutex, StartThread, ThreadCount
+from '__go__/grumpy' import (NewOnce, NewTryableMutex, NewWaitGroup,
+                             StartThread, ThreadCount)
+from '__go__/time' import Duration as _Duration, Second as _Second
 
 
 class error(Exception):
@@ -16,14 +18,24 @@ class LockType(object):
   def __init__(self):
     self._mutex = NewTryableMutex()
 
-  def acquire(self, waitflag=1):
-    if waitflag:
+  def acquire(self, waitflag=1, timeout=-1):
+    """Acquire the lock, giving up after timeout seconds if non-negative."""
+    if not waitflag:
+      return self._mutex.TryLock()
+    if timeout < 0:
       self._mutex.Lock()
       return True
-    return self._mutex.TryLock()
+    return self._mutex.LockTimeout(_Duration(int(timeout * _Second)))
 
   def release(self):
-    self._mutex.Unlock()
+    if not self._mutex.TryUnlock():
+      raise error('release unlocked lock')
+
+  def locked(self):
+    if self._mutex.TryLock():
+      self._mutex.Unlock()
+      return False
+    return True
 
   def __enter__(self):
     self.acquire()
@@ -37,6 +49,54 @@ def allocate_lock():
   return LockType()
 
 
+class WaitGroup(object):
+  """Waits for a collection of threads to finish, like Go's sync.WaitGroup.
+
+  Call add() before starting each thread, done() when each finishes and
+  wait() to block until they all have.
+  """
+
+  def __init__(self):
+    self._wg = NewWaitGroup()
+
+  def add(self, delta=1):
+    if not self._wg.Add(delta):
+      raise ValueError('negative WaitGroup counter')
+
+  def done(self):
+    self.add(-1)
+
+  @property
+  def count(self):
+    return self._wg.Count()
+
+  def wait(self, timeout=None):
+    """Returns True once the counter is zero or False on timeout."""
+    if timeout is None:
+      self._wg.Wait()
+      return True
+    return self._wg.WaitTimeout(_Duration(int(max(timeout, 0) * _Second)))
+
+
+class Once(object):
+  """Calls a function exactly once, like Go's sync.Once."""
+
+  def __init__(self):
+    self._once = NewOnce()
+
+  def do(self, func, *args, **kwargs):
+    """Calls func(*args, **kwargs) unless do() has been called before.
+
+    Threads calling do() concurrently block until the first call returns.
+    The result of func is discarded.
+    """
+    self._once.Do(__frame__(), lambda: func(*args, **kwargs))  # pylint: disable=undefined-variable
+
+  @property
+  def done(self):
+    return self._once.Done()
+
+
 def start_new_thread(func, args, kwargs=None):
   if kwargs is None:
     kwargs = {}
@@ -47,7 +107,7 @@ def start_new_thread(func, args, kwargs=None):
     l.release()
     func(*args, **kwargs)
   l.acquire()
-  StartThread(thread_func)
+  StartThread(__frame__(), thread_func)  # pylint: disable=undefined-variable
   l.acquire()
   return ident[0]
 
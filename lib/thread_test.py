@@ -0,0 +1,88 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import thread
+
+import weetest
+
+
+def TestWaitGroup():
+  wg = thread.WaitGroup()
+  results = []
+  lock = thread.allocate_lock()
+  def Work(i):
+    with lock:
+      results.append(i)
+    wg.done()
+  for i in xrange(10):
+    wg.add()
+    thread.start_new_thread(Work, (i,))
+  assert wg.wait(10)
+  assert sorted(results) == range(10)
+  assert wg.count == 0
+
+
+def TestWaitGroupTimeout():
+  wg = thread.WaitGroup()
+  assert wg.wait(0)
+  wg.add(2)
+  assert not wg.wait(0.01)
+  wg.done()
+  wg.done()
+  assert wg.wait(0)
+
+
+def TestWaitGroupNegative():
+  wg = thread.WaitGroup()
+  try:
+    wg.done()
+  except ValueError as e:
+    assert str(e) == 'negative WaitGroup counter'
+  else:
+    raise AssertionError
+  assert wg.count == 0
+
+
+def TestOnce():
+  once = thread.Once()
+  calls = []
+  wg = thread.WaitGroup()
+  def Work():
+    once.do(calls.append, 'foo')
+    wg.done()
+  for _ in xrange(10):
+    wg.add()
+    thread.start_new_thread(Work, ())
+  wg.wait()
+  assert once.done
+  assert calls == ['foo']
+
+
+def TestOnceRaises():
+  once = thread.Once()
+  def Fail():
+    raise RuntimeError('foo')
+  try:
+    once.do(Fail)
+  except RuntimeError as e:
+    assert str(e) == 'foo'
+  else:
+    raise AssertionError
+  # The function is not retried.
+  once.do(Fail)
+  assert once.done
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,118 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import threading
+import time
+
+import weetest
+
+
+def TestLockAcquireTimeout():
+  lock = threading.Lock()
+  assert lock.acquire(True, 0)
+  assert lock.locked()
+  start = time.time()
+  assert not lock.acquire(True, 0.05)
+  assert time.time() - start >= 0.04
+  lock.release()
+  assert not lock.locked()
+
+
+def TestLockReleaseUnlocked():
+  lock = threading.Lock()
+  try:
+    lock.release()
+  except threading.ThreadError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestConditionWaitTimeout():
+  cond = threading.Condition()
+  with cond:
+    start = time.time()
+    cond.wait(0.05)
+    assert time.time() - start >= 0.04
+
+
+def TestConditionNotify():
+  cond = threading.Condition()
+  ready = []
+  def Notify():
+    with cond:
+      ready.append(True)
+      cond.notify()
+  with cond:
+    threading.Thread(target=Notify).start()
+    while not ready:
+      cond.wait(10)
+  assert ready == [True]
+
+
+def TestEventWait():
+  e = threading.Event()
+  assert not e.wait(0.01)
+  threading.Thread(target=e.set).start()
+  assert e.wait(10)
+
+
+def TestSemaphore():
+  sema = threading.Semaphore(2)
+  assert sema.acquire(False)
+  assert sema.acquire(False)
+  assert not sema.acquire(False)
+  sema.release()
+  assert sema.acquire(False)
+
+
+def TestThreadJoin():
+  results = []
+  def Target(n):
+    time.sleep(0.01)
+    results.append(n)
+  t = threading.Thread(target=Target, args=(42,))
+  t.start()
+  t.join()
+  assert not t.is_alive()
+  assert results == [42]
+
+
+def TestThreadJoinTimeout():
+  e = threading.Event()
+  t = threading.Thread(target=e.wait)
+  t.start()
+  t.join(0.01)
+  assert t.is_alive()
+  e.set()
+  t.join()
+  assert not t.is_alive()
+
+
+def TestLocal():
+  local = threading.local()
+  local.foo = 'main'
+  seen = []
+  def Target():
+    seen.append(hasattr(local, 'foo'))
+    local.foo = 'thread'
+  t = threading.Thread(target=Target)
+  t.start()
+  t.join()
+  assert seen == [False]
+  assert local.foo == 'main'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -69,15 +69,16 @@ class struct_time(tuple):  #pylint: disable=invalid-name,missing-docstring
 
 
 def gmtime(seconds=None):
-  t = (Unix(seconds, 0) if seconds else Now()).UTC()
+  t = (Now() if seconds is None else Unix(seconds, 0)).UTC()
   return struct_time((t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(),
                       t.Second(), (t.Weekday() + 6) % 7, t.YearDay(), 0))
 
 
 def localtime(seconds=None):
-  t = (Unix(seconds, 0) if seconds else Now()).Local()
+  t = (Now() if seconds is None else Unix(seconds, 0)).Local()
+  isdst = int(daylight and t.Zone()[1] != -timezone)
   return struct_time((t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(),
-                      t.Second(), (t.Weekday() + 6) % 7, t.YearDay(), 0))
+                      t.Second(), (t.Weekday() + 6) % 7, t.YearDay(), isdst))
 
 
 def mktime(t):
@@ -112,9 +113,26 @@ def strftime(format, tt=None):  # pylint: disable=missing-docstring,redefined-bu
   return ''.join(ret)
 
 
-# TODO: Calculate real value for daylight saving.
-daylight = 0
+def _local_zones():
+  """Returns the local standard and daylight saving time zones.
+
+  Each zone is a (name, seconds east of UTC) pair. Daylight saving time is
+  assumed to be the zone with the larger offset in January or July, and the
+  two zones are the same if the local time zone has no DST.
+  """
+  year = Now().Year()
+  jan = Date(year, 1, 1, 0, 0, 0, 0, Local).Zone()
+  jul = Date(year, 7, 1, 0, 0, 0, 0, Local).Zone()
+  if jan[1] <= jul[1]:
+    return jan, jul
+  return jul, jan
+
+
+_std_zone, _dst_zone = _local_zones()
 
-# TODO: Use local DST instead of ''.
-tzname = (Now().Zone()[0], '')
+# Offsets of the local standard and DST time zones in seconds west of UTC.
+timezone = -_std_zone[1]
+altzone = -_dst_zone[1]
+daylight = int(_std_zone != _dst_zone)
+tzname = (_std_zone[0], _dst_zone[0])
 
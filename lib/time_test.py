@@ -19,4 +19,10 @@ assert time.time() < 3000000000
 
 time_struct = (1999, 9, 19, 0, 0, 0, 6, 262, 0)
 got = time.localtime(time.mktime(time_struct))
-assert got == time_struct, got
+# tm_isdst depends on whether the local time zone observes DST.
+assert got[:8] == time_struct[:8], got
+assert got.tm_isdst in (0, 1), got
+
+assert time.gmtime(0) == (1970, 1, 1, 0, 0, 0, 3, 1, 0), time.gmtime(0)
+assert time.altzone < time.timezone if time.daylight else time.altzone == time.timezone
+assert len(time.tzname) == 2
@@ -0,0 +1,108 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""Tool for measuring execution time of small code snippets.
+
+Grumpy cannot compile source code at runtime, so unlike CPython the statements
+passed to Timer must be callables (or the no-op 'pass').
+"""
+
+from '__go__/time' import Now, Second  # pylint: disable=g-multiple-import
+
+
+__all__ = ['Timer', 'timeit', 'repeat', 'default_timer']
+
+default_number = 1000000
+default_repeat = 3
+
+_epoch = Now()
+
+
+def default_timer():
+  """Returns a monotonic clock reading in seconds, with nanosecond precision."""
+  return float(Now().Sub(_epoch)) / Second
+
+
+def _noop():
+  pass
+
+
+def _make_callable(stmt, name):
+  if callable(stmt):
+    return stmt
+  if isinstance(stmt, basestring):
+    if stmt.strip() in ('', 'pass'):
+      return _noop
+    raise NotImplementedError(
+        'compiling %s strings is not supported, pass a callable' % name)
+  raise ValueError('%s is neither a string nor callable' % name)
+
+
+class Timer(object):
+  """Class for timing execution speed of small code snippets."""
+
+  def __init__(self, stmt='pass', setup='pass', timer=default_timer):
+    self.timer = timer
+    self._stmt = _make_callable(stmt, 'stmt')
+    self._setup = _make_callable(setup, 'setup')
+
+  def print_exc(self, file=None):  # pylint: disable=redefined-builtin
+    """Prints the traceback of an exception raised by the timed code."""
+    import traceback  # pylint: disable=g-import-not-at-top
+    traceback.print_exc(file=file)
+
+  def timeit(self, number=default_number):
+    """Returns the time in seconds taken to call stmt number times."""
+    self._setup()
+    stmt = self._stmt
+    timer = self.timer
+    start = timer()
+    for _ in xrange(number):
+      stmt()
+    return timer() - start
+
+  def repeat(self, repeat=default_repeat, number=default_number):  # pylint: disable=redefined-outer-name
+    """Calls timeit() repeat times, returning a list of the results."""
+    return [self.timeit(number) for _ in xrange(repeat)]
+
+  def autorange(self, callback=None):
+    """Finds a number of loops so that the total time is at least 0.2 seconds.
+
+    Calls timeit() with 1, 2, 5, 10, 20, 50, ... loops until the time taken is
+    at least 0.2 seconds and returns (number, time_taken). If callback is given
+    it is called with number and time_taken after each trial.
+    """
+    i = 1
+    while True:
+      for j in (1, 2, 5):
+        number = i * j
+        time_taken = self.timeit(number)
+        if callback:
+          callback(number, time_taken)
+        if time_taken >= 0.2:
+          return number, time_taken
+      i *= 10
+
+
+def timeit(stmt='pass', setup='pass', timer=default_timer,
+           number=default_number):
+  """Convenience function to create a Timer and call its timeit() method."""
+  return Timer(stmt, setup, timer).timeit(number)
+
+
+def repeat(stmt='pass', setup='pass', timer=default_timer,
+           repeat=default_repeat, number=default_number):  # pylint: disable=redefined-outer-name
+  """Convenience function to create a Timer and call its repeat() method."""
+  return Timer(stmt, setup, timer).repeat(repeat, number)
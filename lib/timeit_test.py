@@ -0,0 +1,94 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import timeit
+
+import weetest
+
+
+class FakeTimer(object):
+  """A timer that advances by one second each time it is read."""
+
+  def __init__(self):
+    self.now = 0.0
+
+  def __call__(self):
+    self.now += 1.0
+    return self.now
+
+
+def TestDefaultTimer():
+  t1 = timeit.default_timer()
+  t2 = timeit.default_timer()
+  assert isinstance(t1, float)
+  assert t2 >= t1
+
+
+def TestTimerTimeit():
+  calls = []
+  t = timeit.Timer(lambda: calls.append('stmt'),
+                   lambda: calls.append('setup'), FakeTimer())
+  assert t.timeit(3) == 1.0
+  assert calls == ['setup', 'stmt', 'stmt', 'stmt'], calls
+
+
+def TestTimerRepeat():
+  calls = []
+  t = timeit.Timer(lambda: calls.append(1), timer=FakeTimer())
+  assert t.repeat(4, 2) == [1.0] * 4
+  assert len(calls) == 8
+
+
+def TestTimerAutorange():
+  class SlowTimer(object):
+    def __init__(self):
+      self.now = 0.0
+    def __call__(self):
+      return self.now
+    def Stmt(self):
+      self.now += 0.05
+  slow = SlowTimer()
+  trials = []
+  t = timeit.Timer(slow.Stmt, timer=slow)
+  number, time_taken = t.autorange(lambda *args: trials.append(args))
+  assert number == 5, number
+  assert abs(time_taken - 0.25) < 1e-9, time_taken
+  assert [n for n, _ in trials] == [1, 2, 5]
+
+
+def TestTimerStatements():
+  assert timeit.Timer('pass', '', FakeTimer()).timeit(10) == 1.0
+  try:
+    timeit.Timer('x = 1')
+  except NotImplementedError:
+    pass
+  else:
+    raise AssertionError('string statement did not raise')
+  try:
+    timeit.Timer(123)
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('non-callable statement did not raise')
+
+
+def TestTimeitFunctions():
+  assert timeit.timeit(timer=FakeTimer(), number=5) == 1.0
+  assert timeit.repeat(timer=FakeTimer(), repeat=2, number=5) == [1.0, 1.0]
+  assert timeit.timeit(lambda: None, number=100) >= 0
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,79 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import StringIO
+import tokenize
+
+import weetest
+
+
+def _Tokens(source):
+  readline = StringIO.StringIO(source).readline
+  return [(tokenize.tok_name[t[0]], t[1])
+          for t in tokenize.generate_tokens(readline)]
+
+
+def TestGenerateTokens():
+  got = _Tokens('x = f(1)  # foo\n')
+  want = [('NAME', 'x'), ('OP', '='), ('NAME', 'f'), ('OP', '('),
+          ('NUMBER', '1'), ('OP', ')'), ('COMMENT', '# foo'),
+          ('NEWLINE', '\n'), ('ENDMARKER', '')]
+  assert got == want, '%s != %s' % (got, want)
+
+
+def TestGenerateTokensIndent():
+  got = _Tokens('if x:\n  y = "a"\n')
+  want = [('NAME', 'if'), ('NAME', 'x'), ('OP', ':'), ('NEWLINE', '\n'),
+          ('INDENT', '  '), ('NAME', 'y'), ('OP', '='), ('STRING', '"a"'),
+          ('NEWLINE', '\n'), ('DEDENT', ''), ('ENDMARKER', '')]
+  assert got == want, '%s != %s' % (got, want)
+
+
+def TestGenerateTokensPositions():
+  readline = StringIO.StringIO('foo.bar\n').readline
+  got = [t[2:4] for t in tokenize.generate_tokens(readline)]
+  want = [((1, 0), (1, 3)), ((1, 3), (1, 4)), ((1, 4), (1, 7)),
+          ((1, 7), (1, 8)), ((2, 0), (2, 0))]
+  assert got == want, '%s != %s' % (got, want)
+
+
+def TestTokenError():
+  readline = StringIO.StringIO('x = (1,\n').readline
+  try:
+    list(tokenize.generate_tokens(readline))
+  except tokenize.TokenError:
+    pass
+  else:
+    raise AssertionError('unterminated paren did not raise TokenError')
+
+
+def TestUntokenize():
+  source = 'def f(a, b):\n    return a + b\n'
+  readline = StringIO.StringIO(source).readline
+  tokens = [t[:2] for t in tokenize.generate_tokens(readline)]
+  got = tokenize.untokenize(tokens)
+  readline = StringIO.StringIO(got).readline
+  assert [t[:2] for t in tokenize.generate_tokens(readline)] == tokens
+
+
+def TestTokenConstants():
+  assert tokenize.tok_name[tokenize.NAME] == 'NAME'
+  assert tokenize.tok_name[tokenize.COMMENT] == 'COMMENT'
+  assert tokenize.tok_name[tokenize.NL] == 'NL'
+  assert 'generate_tokens' in tokenize.__all__
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,87 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# pylint: disable=bare-except
+
+import StringIO
+import sys
+import traceback
+
+import weetest
+
+
+def _Inner():
+  raise ValueError('foo')
+
+
+def _Outer():
+  _Inner()
+
+
+def TestTracebackAttributes():
+  try:
+    _Outer()
+  except ValueError:
+    tb = sys.exc_info()[2]
+  names = []
+  while tb is not None:
+    assert tb.tb_frame is not None
+    assert tb.tb_lineno > 0
+    names.append(tb.tb_frame.f_code.co_name)
+    tb = tb.tb_next
+  assert names == ['TestTracebackAttributes', '_Outer', '_Inner'], names
+
+
+def TestExtractTb():
+  try:
+    _Outer()
+  except ValueError:
+    entries = traceback.extract_tb(sys.exc_info()[2])
+  assert [e[2] for e in entries][-2:] == ['_Outer', '_Inner'], entries
+  assert entries[-1][3] == "raise ValueError('foo')", entries
+
+
+def TestFormatExc():
+  try:
+    _Outer()
+  except ValueError:
+    s = traceback.format_exc()
+  lines = s.splitlines()
+  assert lines[0] == 'Traceback (most recent call last):', s
+  assert lines[-1] == 'ValueError: foo', s
+  assert "    raise ValueError('foo')" in lines, s
+  assert '_Outer' in s and '_Inner' in s, s
+
+
+def TestPrintExc():
+  buf = StringIO.StringIO()
+  sys.stderr = buf
+  try:
+    try:
+      _Inner()
+    except ValueError:
+      traceback.print_exc()
+  finally:
+    sys.stderr = sys.__stderr__
+  assert buf.getvalue().endswith('ValueError: foo\n'), buf.getvalue()
+
+
+def TestFormatStack():
+  lines = traceback.format_stack()
+  assert lines, lines
+  assert 'TestFormatStack' in lines[-1], lines
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,100 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""Quoting and query string helpers from urllib.
+
+Only the functions for escaping URL components and encoding form data are
+provided. Use urllib2.urlopen to fetch URLs.
+"""
+
+import string
+
+
+__all__ = ['quote', 'quote_plus', 'unquote', 'unquote_plus', 'urlencode']
+
+_ALWAYS_SAFE = string.ascii_letters + string.digits + '_.-'
+_HEX_DIGITS = '0123456789abcdefABCDEF'
+
+
+def quote(s, safe='/'):
+  """Escapes the characters of s that are special in URLs as %xx sequences.
+
+  Letters, digits, '_.-' and the characters in safe are never escaped.
+  """
+  if not s:
+    if s is None:
+      raise TypeError('None object cannot be quoted')
+    return s
+  safe_chars = _ALWAYS_SAFE + safe
+  return ''.join(c if c in safe_chars else '%%%02X' % ord(c) for c in s)
+
+
+def quote_plus(s, safe=''):
+  """Like quote() but also replaces spaces with plus signs."""
+  if ' ' in s:
+    return quote(s, safe + ' ').replace(' ', '+')
+  return quote(s, safe)
+
+
+def unquote(s):
+  """Replaces %xx escapes in s by the characters they represent."""
+  parts = s.split('%')
+  if len(parts) == 1:
+    return s
+  result = [parts[0]]
+  for part in parts[1:]:
+    if (len(part) >= 2 and part[0] in _HEX_DIGITS and
+        part[1] in _HEX_DIGITS):
+      result.append(chr(int(part[:2], 16)) + part[2:])
+    else:
+      result.append('%' + part)
+  return ''.join(result)
+
+
+def unquote_plus(s):
+  """Like unquote() but also replaces plus signs with spaces."""
+  return unquote(s.replace('+', ' '))
+
+
+def urlencode(query, doseq=0):
+  """Encodes a mapping or sequence of pairs as a query string.
+
+  When doseq is true, values that are sequences other than strings are
+  expanded into one parameter per element.
+  """
+  if hasattr(query, 'items'):
+    query = query.items()
+  else:
+    try:
+      if query and not isinstance(query[0], tuple):
+        raise TypeError
+    except (TypeError, KeyError):
+      raise TypeError('not a valid non-string sequence or mapping object')
+  l = []
+  for k, v in query:
+    k = quote_plus(str(k))
+    if not doseq or isinstance(v, basestring):
+      if isinstance(v, unicode) and doseq:
+        v = v.encode('ASCII', 'replace')
+      l.append(k + '=' + quote_plus(str(v)))
+      continue
+    try:
+      elems = iter(v)
+    except TypeError:
+      l.append(k + '=' + quote_plus(str(v)))
+    else:
+      for elt in elems:
+        l.append(k + '=' + quote_plus(str(elt)))
+  return '&'.join(l)
@@ -0,0 +1,282 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+"""An HTTP client with the urllib2 interface built on Go's net/http package.
+
+Only http and https URLs are supported. Redirects are followed by the Go
+client and responses are decompressed transparently. Handlers, openers and
+proxies are not supported.
+"""
+
+# pylint: disable=g-multiple-import
+from '__go__/grumpy' import ToNative
+from '__go__/io/ioutil' import ReadAll as _ReadAll
+from '__go__/net/http' import (
+    Client as _Client,
+    DefaultClient as _DefaultClient,
+    NewRequest as _NewRequest
+)
+from '__go__/reflect' import MakeSlice as _MakeSlice
+from '__go__/strings' import NewReader as _NewReader
+from '__go__/time' import Duration as _Duration, Second as _Second
+
+import mimetools
+import socket
+import StringIO
+import urlparse
+
+
+__version__ = '2.7'
+
+_USER_AGENT = 'Python-urllib/%s' % __version__
+
+# The reflect.Type of []byte, used to allocate read buffers.
+_byte_slice_type = ToNative(__frame__(), _ReadAll).Type().Out(0)
+
+
+class URLError(IOError):
+  """Raised when a URL cannot be fetched."""
+
+  def __init__(self, reason):  # pylint: disable=super-init-not-called
+    self.args = (reason,)
+    self.reason = reason
+
+  def __str__(self):
+    return '<urlopen error %s>' % self.reason
+
+
+class addinfourl(object):  # pylint: disable=invalid-name
+  """A file-like response with the headers and status of the request."""
+
+  def __init__(self, fp, headers, url, code=None, msg=None):
+    self.fp = fp
+    self.headers = headers
+    self.url = url
+    self.code = code
+    self.msg = msg
+    self.read = fp.read
+    self.readline = fp.readline
+    self.readlines = fp.readlines
+
+  def __iter__(self):
+    return iter(self.fp)
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.close()
+
+  def __repr__(self):
+    return '<%s at %r whose fp = %r>' % (type(self).__name__, id(self),
+                                         self.fp)
+
+  def close(self):
+    self.fp.close()
+
+  def info(self):
+    return self.headers
+
+  def getcode(self):
+    return self.code
+
+  def geturl(self):
+    return self.url
+
+
+class HTTPError(URLError, addinfourl):
+  """Raised for responses with an error status. It is also a response."""
+
+  def __init__(self, url, code, msg, hdrs, fp):  # pylint: disable=super-init-not-called
+    self.args = (url, code, msg, hdrs, fp)
+    self.code = code
+    self.msg = msg
+    self.hdrs = hdrs
+    self.fp = fp
+    self.filename = url
+    if fp is not None:
+      addinfourl.__init__(self, fp, hdrs, url, code, msg)
+
+  def __str__(self):
+    return 'HTTP Error %s: %s' % (self.code, self.msg)
+
+  @property
+  def reason(self):
+    return self.msg
+
+
+class Request(object):
+  """A URL request with optional POST data and headers."""
+
+  def __init__(self, url, data=None, headers=None, origin_req_host=None,
+               unverifiable=False):
+    self._full_url, self._fragment = urlparse.urldefrag(url)
+    self.data = data
+    self.headers = {}
+    self.unredirected_hdrs = {}
+    for key, value in (headers or {}).items():
+      self.add_header(key, value)
+    self.origin_req_host = origin_req_host
+    self.unverifiable = unverifiable
+    self.timeout = socket._GLOBAL_DEFAULT_TIMEOUT  # pylint: disable=protected-access
+
+  def get_method(self):
+    if self.has_data():
+      return 'POST'
+    return 'GET'
+
+  def add_data(self, data):
+    self.data = data
+
+  def has_data(self):
+    return self.data is not None
+
+  def get_data(self):
+    return self.data
+
+  def get_full_url(self):
+    if self._fragment:
+      return '%s#%s' % (self._full_url, self._fragment)
+    return self._full_url
+
+  def get_type(self):
+    scheme = urlparse.urlsplit(self._full_url).scheme
+    if not scheme:
+      raise ValueError('unknown url type: %s' % self._full_url)
+    return scheme
+
+  def get_host(self):
+    return urlparse.urlsplit(self._full_url).netloc
+
+  def get_selector(self):
+    parts = urlparse.urlsplit(self._full_url)
+    return urlparse.urlunsplit(('', '', parts.path or '/', parts.query, ''))
+
+  def get_origin_req_host(self):
+    if self.origin_req_host is None:
+      return self.get_host().split(':')[0]
+    return self.origin_req_host
+
+  def is_unverifiable(self):
+    return self.unverifiable
+
+  def add_header(self, key, val):
+    self.headers[key.capitalize()] = val
+
+  def add_unredirected_header(self, key, val):
+    self.unredirected_hdrs[key.capitalize()] = val
+
+  def has_header(self, header_name):
+    return (header_name in self.headers or
+            header_name in self.unredirected_hdrs)
+
+  def get_header(self, header_name, default=None):
+    return self.headers.get(
+        header_name, self.unredirected_hdrs.get(header_name, default))
+
+  def header_items(self):
+    hdrs = self.unredirected_hdrs.copy()
+    hdrs.update(self.headers)
+    return hdrs.items()
+
+
+def urlopen(url, data=None, timeout=socket._GLOBAL_DEFAULT_TIMEOUT):  # pylint: disable=protected-access
+  """Fetches url, a string or Request, and returns the response.
+
+  The request is a POST when data is not None. HTTPError is raised for
+  responses with a 4xx or 5xx status and URLError when the server cannot be
+  reached.
+  """
+  if isinstance(url, basestring):
+    req = Request(url, data)
+  else:
+    req = url
+    if data is not None:
+      req.add_data(data)
+  req.timeout = timeout
+  scheme = req.get_type()
+  if scheme not in ('http', 'https'):
+    raise URLError('unknown url type: %s' % scheme)
+  body = None
+  if req.has_data():
+    body = _NewReader(req.get_data())
+    if not req.has_header('Content-type'):
+      req.add_unredirected_header(
+          'Content-type', 'application/x-www-form-urlencoded')
+  if not req.has_header('User-agent'):
+    req.add_unredirected_header('User-agent', _USER_AGENT)
+  goreq, err = _NewRequest(req.get_method(), req.get_full_url(), body)
+  if err:
+    raise URLError(err.Error())
+  for key, value in req.header_items():
+    goreq.Header.Set(key, str(value))
+  client = _DefaultClient
+  if timeout is not socket._GLOBAL_DEFAULT_TIMEOUT and timeout is not None:  # pylint: disable=protected-access
+    client = _Client.new()
+    client.Timeout = _Duration(int(timeout * _Second))
+  resp, err = client.Do(goreq)
+  if err:
+    if hasattr(err, 'Timeout') and err.Timeout():
+      raise URLError(socket.timeout('timed out'))
+    raise URLError(err.Error())
+  code = resp.StatusCode
+  msg = resp.Status[len(str(code)):].strip()
+  fp = _BodyFile(_Body(resp.Body), 'rb')
+  headers = _parse_headers(resp.Header)
+  url = resp.Request.URL.String()
+  if code >= 400:
+    raise HTTPError(url, code, msg, headers, fp)
+  return addinfourl(fp, headers, url, code, msg)
+
+
+class _Body(object):
+  """Adapts a response body to the recv() interface of socket._fileobject."""
+
+  def __init__(self, body):
+    self._body = body
+    self._closed = False
+
+  def recv(self, bufsize):
+    if self._closed:
+      return ''
+    buf = _MakeSlice(_byte_slice_type, bufsize, bufsize).Interface()
+    n, err = self._body.Read(buf)
+    if err:
+      self.close()
+      if err.Error() != 'EOF':
+        raise URLError(err.Error())
+    return ''.join(chr(b) for b in buf[:n])
+
+  def close(self):
+    if not self._closed:
+      self._closed = True
+      self._body.Close()
+
+
+class _BodyFile(socket._fileobject):  # pylint: disable=protected-access
+  """A file reading a response body that releases the body when closed."""
+
+  def close(self):
+    super(_BodyFile, self).close()
+    self._sock.close()
+
+
+def _parse_headers(header):
+  lines = []
+  for key in sorted(header.keys()):
+    for value in header[key]:
+      lines.append('%s: %s\r\n' % (key, value))
+  lines.append('\r\n')
+  return mimetools.Message(StringIO.StringIO(''.join(lines)))
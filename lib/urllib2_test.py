@@ -0,0 +1,164 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+
+import socket
+import threading
+import urllib
+import urllib2
+
+import weetest
+
+
+def _Serve(response):
+  """Serves response to one HTTP request and returns the request made."""
+  server = socket.socket()
+  server.bind(('127.0.0.1', 0))
+  server.listen(1)
+  request = []
+  def Handle():
+    conn, _ = server.accept()
+    f = conn.makefile('rb')
+    line = f.readline()
+    headers = {}
+    while line not in ('\r\n', ''):
+      request.append(line)
+      line = f.readline()
+      if ':' in line:
+        key, value = line.split(':', 1)
+        headers[key.lower()] = value.strip()
+    length = int(headers.get('content-length', 0))
+    request.append(f.read(length))
+    conn.sendall(response)
+    conn.close()
+    server.close()
+  t = threading.Thread(target=Handle)
+  t.start()
+  host, port = server.getsockname()
+  return 'http://%s:%d' % (host, port), request, t
+
+
+def TestQuote():
+  assert urllib.quote('/a b/c~') == '/a%20b/c%7E'
+  assert urllib.quote('/a', safe='') == '%2Fa'
+  assert urllib.quote_plus('a b&c=d') == 'a+b%26c%3Dd'
+  assert urllib.unquote('a%20b%2fc%zz%') == 'a b/c%zz%'
+  assert urllib.unquote_plus('a+b%2B') == 'a b+'
+  try:
+    urllib.quote(None)
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('TypeError not raised')
+
+
+def TestURLEncode():
+  assert urllib.urlencode([('a', 1), ('b', 'x y')]) == 'a=1&b=x+y'
+  assert urllib.urlencode({'a': '&'}) == 'a=%26'
+  assert urllib.urlencode([('a', [1, 2])]) == 'a=%5B1%2C+2%5D'
+  assert urllib.urlencode([('a', [1, 2]), ('b', 'cd')], True) == 'a=1&a=2&b=cd'
+  try:
+    urllib.urlencode('abc')
+  except TypeError:
+    pass
+  else:
+    raise AssertionError('TypeError not raised')
+
+
+def TestURLOpen():
+  url, request, t = _Serve('HTTP/1.0 200 OK\r\nContent-Type: text/plain\r\n'
+                           'X-Foo: bar\r\n\r\nline 1\nline 2\n')
+  resp = urllib2.urlopen(url + '/path?q=1')
+  t.join()
+  assert request[0] == 'GET /path?q=1 HTTP/1.1\r\n', request
+  assert 'User-Agent: Python-urllib/2.7\r\n' in request, request
+  assert resp.getcode() == 200
+  assert resp.msg == 'OK'
+  assert resp.geturl() == url + '/path?q=1'
+  assert resp.info().gettype() == 'text/plain'
+  assert resp.info()['x-foo'] == 'bar'
+  assert resp.readline() == 'line 1\n'
+  assert resp.read() == 'line 2\n'
+  assert resp.read() == ''
+  resp.close()
+
+
+def TestURLOpenPost():
+  url, request, t = _Serve('HTTP/1.0 201 Created\r\n\r\ndone')
+  req = urllib2.Request(url, urllib.urlencode({'a': 'b c'}),
+                        {'x-custom': 'foo'})
+  assert req.get_method() == 'POST'
+  assert req.get_header('X-custom') == 'foo'
+  resp = urllib2.urlopen(req, timeout=10)
+  t.join()
+  assert request[0] == 'POST / HTTP/1.1\r\n', request
+  assert 'X-Custom: foo\r\n' in request, request
+  assert 'Content-Type: application/x-www-form-urlencoded\r\n' in request
+  assert request[-1] == 'a=b+c', request
+  assert resp.getcode() == 201
+  assert list(resp) == ['done']
+
+
+def TestURLOpenHTTPError():
+  url, _, t = _Serve('HTTP/1.0 404 Not Found\r\nContent-Length: 7\r\n\r\nmissing')
+  try:
+    urllib2.urlopen(url)
+  except urllib2.HTTPError as e:
+    assert e.code == 404
+    assert e.reason == 'Not Found'
+    assert str(e) == 'HTTP Error 404: Not Found'
+    assert e.info()['content-length'] == '7'
+    assert e.read() == 'missing'
+  else:
+    raise AssertionError('HTTPError not raised')
+  t.join()
+
+
+def TestURLOpenURLError():
+  server = socket.socket()
+  server.bind(('127.0.0.1', 0))
+  host, port = server.getsockname()
+  server.close()
+  for url in ['http://%s:%d/' % (host, port), 'ftp://example.com/']:
+    try:
+      urllib2.urlopen(url)
+    except urllib2.URLError as e:
+      assert str(e).startswith('<urlopen error '), str(e)
+    else:
+      raise AssertionError('URLError not raised for %s' % url)
+  try:
+    urllib2.urlopen('example.com')
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('ValueError not raised')
+
+
+def TestRequest():
+  req = urllib2.Request('http://example.com:8080/a/b?c=d#frag')
+  assert req.get_method() == 'GET'
+  assert req.get_full_url() == 'http://example.com:8080/a/b?c=d#frag'
+  assert req.get_type() == 'http'
+  assert req.get_host() == 'example.com:8080'
+  assert req.get_selector() == '/a/b?c=d'
+  assert req.get_origin_req_host() == 'example.com'
+  req.add_header('accept', 'text/html')
+  assert req.has_header('Accept')
+  assert req.header_items() == [('Accept', 'text/html')]
+  req.add_data('x')
+  assert req.get_method() == 'POST'
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,155 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import time
+import weakref
+
+import weetest
+
+from '__go__/runtime' import GC
+
+
+class Foo(object):
+
+  def __init__(self, n):
+    self.n = n
+
+  def __add__(self, other):
+    return self.n + other
+
+  def __len__(self):
+    return self.n
+
+
+def _Collect(pred):
+  # Referents are finalized some time after they become unreachable.
+  for _ in xrange(100):
+    if pred():
+      return
+    GC()
+    time.sleep(0.01)
+  raise AssertionError('referent was not collected')
+
+
+def TestRef():
+  foo = Foo(1)
+  r = weakref.ref(foo)
+  assert r() is foo
+  assert weakref.ref(foo) is r
+  assert weakref.ref(foo, None) is r
+  assert weakref.ref(foo, lambda wr: None) is not r
+  assert weakref.ref(foo, lambda wr: None) == r
+  assert hash(r) == hash(foo)
+  assert isinstance(r, weakref.ReferenceType)
+
+
+def TestRefCallback():
+  called = []
+  r = weakref.ref(Foo(1), called.append)
+  _Collect(lambda: called)
+  assert called == [r]
+  assert r() is None
+
+
+def TestRefSubclass():
+  class Bar(weakref.ref):
+    pass
+  foo = Foo(1)
+  r = Bar(foo)
+  r.attr = 'baz'
+  assert r() is foo
+  assert r.attr == 'baz'
+  assert r is not weakref.ref(foo)
+
+
+def TestProxy():
+  foo = Foo(3)
+  p = weakref.proxy(foo)
+  assert type(p) is weakref.ProxyType
+  assert isinstance(p, weakref.ProxyTypes)
+  assert p.n == 3
+  assert p + 1 == 4
+  assert len(p) == 3
+  p.n = 4
+  assert foo.n == 4
+  del p.n
+  assert not hasattr(foo, 'n')
+
+
+def TestProxyCallable():
+  p = weakref.proxy(Foo)
+  assert type(p) is weakref.CallableProxyType
+  assert p(3).n == 3
+  assert p.__name__ == 'Foo'
+
+
+def TestProxyContainer():
+  l = [1, 2]
+  p = weakref.proxy(l)
+  p.append(3)
+  p[0] = 0
+  assert l == [0, 2, 3]
+  assert 2 in p
+  assert list(p) == l
+  assert p == l
+  assert p[1:] == [2, 3]
+  try:
+    hash(p)
+  except TypeError:
+    pass
+  else:
+    raise AssertionError
+
+
+def TestProxyDead():
+  called = []
+  p = weakref.proxy(Foo(1), called.append)
+  _Collect(lambda: called)
+  assert len(called) == 1 and called[0] is p
+  try:
+    p.n  # pylint: disable=pointless-statement
+  except ReferenceError:
+    pass
+  else:
+    raise AssertionError
+  assert 'dead' in repr(p)
+
+
+def TestWeakValueDictionary():
+  foo, bar = Foo(1), Foo(2)
+  d = weakref.WeakValueDictionary()
+  d['foo'] = foo
+  d['bar'] = bar
+  d['bar'] = foo
+  assert sorted(d.keys()) == ['bar', 'foo']
+  assert d['foo'] is foo and d['bar'] is foo
+  del foo
+  d['baz'] = Foo(3)
+  _Collect(lambda: not d)
+  assert d.get('foo') is None
+
+
+def TestWeakKeyDictionary():
+  foo = Foo(1)
+  d = weakref.WeakKeyDictionary()
+  d[foo] = 'foo'
+  d[Foo(2)] = 'bar'
+  assert d[foo] == 'foo'
+  assert foo in d
+  _Collect(lambda: len(d) == 1)
+  assert d.keys() == [foo]
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
@@ -0,0 +1,363 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Read and write ZIP files, backed by Go's archive/zip package.
+
+Archives are held in memory: reading loads the whole file and the entries
+written are only stored to the file when the ZipFile is closed. Encrypted
+entries are not supported.
+"""
+
+from '__go__/grumpy' import (NewZipWriter, ZipCloseWriter, ZipCopyFile,
+                             ZipOpenReader, ZipReadFile, ZipSetInfo,
+                             ZipWriteFile)
+import __builtin__
+import os
+import StringIO
+import time
+
+__all__ = ['BadZipfile', 'error', 'ZIP_STORED', 'ZIP_DEFLATED', 'is_zipfile',
+           'ZipInfo', 'ZipFile', 'LargeZipFile']
+
+ZIP_STORED = 0
+ZIP_DEFLATED = 8
+
+
+class BadZipfile(Exception):
+  pass
+
+
+class LargeZipFile(Exception):
+  pass
+
+
+error = BadZipfile  # pylint: disable=invalid-name
+
+
+def is_zipfile(filename):
+  """Returns True if filename is a path to, or a file holding, a ZIP file."""
+  try:
+    if hasattr(filename, 'read'):
+      data = filename.read()
+    else:
+      with __builtin__.open(filename, 'rb') as f:
+        data = f.read()
+    ZipOpenReader(__frame__(), data, BadZipfile)  # pylint: disable=undefined-variable
+  except (IOError, BadZipfile):
+    return False
+  return True
+
+
+class ZipInfo(object):
+  """Information about a single member of a ZIP archive."""
+
+  def __init__(self, filename='NoName', date_time=(1980, 1, 1, 0, 0, 0)):
+    self.orig_filename = filename
+    # Terminate the file name at the first null byte as the C
+    # implementation does.
+    null_byte = filename.find(chr(0))
+    if null_byte >= 0:
+      filename = filename[:null_byte]
+    if os.sep != '/' and os.sep in filename:
+      filename = filename.replace(os.sep, '/')
+    self.filename = filename
+    self.date_time = date_time
+    if date_time[0] < 1980:
+      raise ValueError('ZIP does not support timestamps before 1980')
+    self.compress_type = ZIP_STORED
+    self.comment = ''
+    self.extra = ''
+    self.create_system = 3
+    self.create_version = 20
+    self.extract_version = 20
+    self.reserved = 0
+    self.flag_bits = 0
+    self.volume = 0
+    self.internal_attr = 0
+    self.external_attr = 0
+    self.header_offset = 0
+    self.CRC = 0
+    self.compress_size = 0
+    self.file_size = 0
+
+  def __repr__(self):
+    return '<ZipInfo filename=%r compress_type=%d file_size=%d>' % (
+        self.filename, self.compress_type, self.file_size)
+
+
+class ZipExtFile(StringIO.StringIO):
+  """A file-like object holding the contents of an archive member."""
+
+  def __init__(self, data, mode, zipinfo):
+    StringIO.StringIO.__init__(self, data)
+    self.mode = mode
+    self.name = zipinfo.filename
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.close()
+
+
+class ZipFile(object):
+  """A ZIP archive opened for reading ('r'), writing ('w') or appending ('a').
+
+  file may be a path or a file-like object.
+  """
+
+  fp = None
+
+  def __init__(self, file, mode='r', compression=ZIP_STORED,  # pylint: disable=redefined-builtin
+               allowZip64=False):  # pylint: disable=unused-argument
+    if mode not in ('r', 'w', 'a'):
+      raise RuntimeError('ZipFile() requires mode "r", "w", or "a"')
+    if compression not in (ZIP_STORED, ZIP_DEFLATED):
+      raise RuntimeError('That compression method is not supported')
+    self.mode = mode
+    self.compression = compression
+    self.debug = 0
+    self.pwd = None
+    self.comment = ''
+    self.filelist = []
+    self.NameToInfo = {}
+    self._members = {}
+    self._writer = None
+    self._start = 0
+    if isinstance(file, basestring):
+      self.filename = file
+      self._filePassed = False
+      if mode == 'a' and not os.path.exists(file):
+        mode = 'w'
+      self.fp = __builtin__.open(file, {'r': 'rb', 'w': 'wb', 'a': 'r+b'}[mode])
+    else:
+      self.filename = getattr(file, 'name', None)
+      self._filePassed = True
+      self.fp = file
+    try:
+      if mode == 'r':
+        self._read(self.fp.read())
+      elif mode == 'w':
+        self._writer = NewZipWriter(0)
+      else:
+        self.fp.seek(0)
+        data = self.fp.read()
+        if is_zipfile(StringIO.StringIO(data)):
+          # The existing entries are copied into a new archive which
+          # replaces the old one when the ZipFile is closed.
+          reader = self._read(data)
+          self._writer = NewZipWriter(0)
+          for member in reader.File:
+            ZipCopyFile(__frame__(), self._writer, member, BadZipfile)  # pylint: disable=undefined-variable
+        else:
+          # Add an archive to the end of some other file, e.g. a
+          # self-extracting executable.
+          self._start = len(data)
+          self._writer = NewZipWriter(self._start)
+    except:
+      fp = self.fp
+      self.fp = None
+      if not self._filePassed:
+        fp.close()
+      raise
+
+  def __enter__(self):
+    return self
+
+  def __exit__(self, *args):
+    self.close()
+
+  def __repr__(self):
+    return '<zipfile.ZipFile filename=%r mode=%r>' % (self.filename, self.mode)
+
+  def _read(self, data):
+    reader = ZipOpenReader(__frame__(), data, BadZipfile)  # pylint: disable=undefined-variable
+    self.comment = reader.Comment
+    for member in reader.File:
+      zinfo = ZipInfo()
+      ZipSetInfo(__frame__(), zinfo, member)  # pylint: disable=undefined-variable
+      zinfo.orig_filename = zinfo.filename
+      self._add_info(zinfo)
+      self._members[zinfo.filename] = member
+    return reader
+
+  def _add_info(self, zinfo):
+    self.filelist.append(zinfo)
+    self.NameToInfo[zinfo.filename] = zinfo
+
+  def _check_writable(self, zinfo):
+    if self.mode not in ('w', 'a'):
+      raise RuntimeError('write() requires mode "w" or "a"')
+    if not self.fp:
+      raise RuntimeError(
+          'Attempt to write ZIP archive that was already closed')
+    if zinfo.compress_type not in (ZIP_STORED, ZIP_DEFLATED):
+      raise RuntimeError('That compression method is not supported')
+
+  def namelist(self):
+    return [zinfo.filename for zinfo in self.filelist]
+
+  def infolist(self):
+    return list(self.filelist)
+
+  def printdir(self):
+    print '%-46s %19s %12s' % ('File Name', 'Modified    ', 'Size')
+    for zinfo in self.filelist:
+      date = '%d-%02d-%02d %02d:%02d:%02d' % zinfo.date_time[:6]
+      print '%-46s %s %12d' % (zinfo.filename, date, zinfo.file_size)
+
+  def testzip(self):
+    """Returns the name of the first corrupt member, or None."""
+    for zinfo in self.filelist:
+      try:
+        self.read(zinfo)
+      except BadZipfile:
+        return zinfo.filename
+    return None
+
+  def getinfo(self, name):
+    info = self.NameToInfo.get(name)
+    if info is None:
+      raise KeyError('There is no item named %r in the archive' % name)
+    return info
+
+  def setpassword(self, pwd):
+    self.pwd = pwd
+
+  def read(self, name, pwd=None):
+    return self.open(name, 'r', pwd).read()
+
+  def open(self, name, mode='r', pwd=None):  # pylint: disable=unused-argument
+    if mode not in ('r', 'U', 'rU'):
+      raise RuntimeError('open() requires mode "r", "U", or "rU"')
+    if not self.fp:
+      raise RuntimeError(
+          'Attempt to read ZIP archive that was already closed')
+    if isinstance(name, ZipInfo):
+      zinfo = name
+    else:
+      zinfo = self.getinfo(name)
+    member = self._members.get(zinfo.filename)
+    if member is None:
+      raise RuntimeError(
+          '%r cannot be read until the archive is closed' % zinfo.filename)
+    if zinfo.flag_bits & 0x1:
+      raise RuntimeError('File %s is encrypted, which is not supported'
+                         % zinfo.filename)
+    data = ZipReadFile(__frame__(), member, BadZipfile)  # pylint: disable=undefined-variable
+    if 'U' in mode:
+      data = data.replace('\r\n', '\n').replace('\r', '\n')
+    return ZipExtFile(data, mode, zinfo)
+
+  def extract(self, member, path=None, pwd=None):
+    """Extracts member to path, defaulting to the current directory."""
+    if not isinstance(member, ZipInfo):
+      member = self.getinfo(member)
+    if path is None:
+      path = os.getcwd()
+    return self._extract_member(member, path, pwd)
+
+  def extractall(self, path=None, members=None, pwd=None):
+    if members is None:
+      members = self.namelist()
+    for zipinfo in members:
+      self.extract(zipinfo, path, pwd)
+
+  def _extract_member(self, member, targetpath, pwd):
+    # Strip absolute paths and '..' components so that members can't be
+    # written outside of targetpath.
+    arcname = member.filename.replace('/', os.sep)
+    arcname = os.sep.join(x for x in arcname.split(os.sep)
+                          if x not in ('', os.curdir, os.pardir))
+    targetpath = os.path.normpath(os.path.join(targetpath, arcname))
+    if member.filename.endswith('/'):
+      _makedirs(targetpath)
+      return targetpath
+    _makedirs(os.path.split(targetpath)[0])
+    data = self.read(member, pwd)
+    with __builtin__.open(targetpath, 'wb') as f:
+      f.write(data)
+    return targetpath
+
+  def write(self, filename, arcname=None, compress_type=None):
+    """Adds the file at filename to the archive under the name arcname."""
+    st = os.stat(filename)
+    isdir = os.path.isdir(filename)
+    date_time = time.localtime(st.st_mtime)[:6]
+    if arcname is None:
+      arcname = filename
+    arcname = os.path.normpath(arcname).lstrip(os.sep)
+    if isdir:
+      arcname += '/'
+    zinfo = ZipInfo(arcname, date_time)
+    zinfo.external_attr = (st.st_mode & 0xFFFF) << 16
+    if isdir:
+      zinfo.compress_type = ZIP_STORED
+      zinfo.external_attr |= 0x10
+      data = ''
+    else:
+      if compress_type is None:
+        compress_type = self.compression
+      zinfo.compress_type = compress_type
+      with __builtin__.open(filename, 'rb') as f:
+        data = f.read()
+    self._write(zinfo, data)
+
+  def writestr(self, zinfo_or_arcname, data, compress_type=None):
+    """Adds an entry named zinfo_or_arcname holding data to the archive."""
+    if isinstance(zinfo_or_arcname, ZipInfo):
+      zinfo = zinfo_or_arcname
+    else:
+      zinfo = ZipInfo(zinfo_or_arcname, time.localtime(time.time())[:6])
+      zinfo.compress_type = self.compression
+      if zinfo.filename.endswith('/'):
+        zinfo.external_attr = 0o40775 << 16
+        zinfo.external_attr |= 0x10
+      else:
+        zinfo.external_attr = 0o600 << 16
+    if compress_type is not None:
+      zinfo.compress_type = compress_type
+    self._write(zinfo, data)
+
+  def _write(self, zinfo, data):
+    self._check_writable(zinfo)
+    ZipWriteFile(__frame__(), self._writer, zinfo, data, BadZipfile)  # pylint: disable=undefined-variable
+    self._add_info(zinfo)
+
+  def close(self):
+    """Closes the file, first storing the archive if it was modified."""
+    fp = self.fp
+    if fp is None:
+      return
+    self.fp = None
+    try:
+      if self._writer:
+        data = ZipCloseWriter(__frame__(), self._writer, self.comment,  # pylint: disable=undefined-variable
+                              BadZipfile)
+        if self.mode == 'a':
+          fp.seek(self._start)
+        fp.write(data)
+        if self.mode == 'a':
+          fp.truncate()
+        fp.flush()
+    finally:
+      if not self._filePassed:
+        fp.close()
+
+
+def _makedirs(path):
+  if not path or os.path.isdir(path):
+    return
+  _makedirs(os.path.split(path)[0])
+  os.mkdir(path)
@@ -0,0 +1,144 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import StringIO
+import tempfile
+
+import weetest
+import zipfile
+
+
+# Produced by CPython's zipfile module: a.txt is deflated and dir/b.txt is
+# stored. The archive comment is 'hi'.
+_ARCHIVE = (
+    'PK\x03\x04\x14\x00\x00\x00\x08\x00\x83\x18"H\xe4M\xd0Y\x0b\x00\x00\x00<'
+    '\x00\x00\x00\x05\x00\x00\x00a.txt\xcbH\xcd\xc9\xc9W\xc8 \x8b\x04\x00PK'
+    '\x03\x04\x14\x00\x00\x00\x00\x00\x83\x18"H\xf9\xef\xbeq\x01\x00\x00\x00'
+    '\x01\x00\x00\x00\t\x00\x00\x00dir/b.txtbPK\x01\x02\x14\x03\x14\x00\x00'
+    '\x00\x08\x00\x83\x18"H\xe4M\xd0Y\x0b\x00\x00\x00<\x00\x00\x00\x05\x00'
+    '\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x01\x00\x00\x00\x00a.txtPK'
+    '\x01\x02\x14\x03\x14\x00\x00\x00\x00\x00\x83\x18"H\xf9\xef\xbeq\x01\x00'
+    '\x00\x00\x01\x00\x00\x00\t\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00'
+    '\x00\x00.\x00\x00\x00dir/b.txtPK\x05\x06\x00\x00\x00\x00\x02\x00\x02\x00'
+    'j\x00\x00\x00V\x00\x00\x00\x02\x00hi')
+
+
+def TestRead():
+  z = zipfile.ZipFile(StringIO.StringIO(_ARCHIVE))
+  assert z.namelist() == ['a.txt', 'dir/b.txt']
+  assert z.comment == 'hi'
+  info = z.getinfo('a.txt')
+  assert info.date_time == (2016, 1, 2, 3, 4, 6)
+  assert info.compress_type == zipfile.ZIP_DEFLATED
+  assert info.file_size == 60
+  assert info.compress_size == 11
+  assert info.external_attr == 0644 << 16
+  assert z.read('a.txt') == 'hello ' * 10
+  assert z.open('dir/b.txt').read() == 'b'
+  assert z.testzip() is None
+  z.close()
+
+
+def TestGetInfoMissing():
+  z = zipfile.ZipFile(StringIO.StringIO(_ARCHIVE))
+  try:
+    z.getinfo('missing')
+  except KeyError as e:
+    assert e.args[0] == "There is no item named 'missing' in the archive"
+  else:
+    raise AssertionError
+
+
+def TestBadZipfile():
+  assert zipfile.is_zipfile(StringIO.StringIO(_ARCHIVE))
+  assert not zipfile.is_zipfile(StringIO.StringIO('not a zip file'))
+  try:
+    zipfile.ZipFile(StringIO.StringIO('not a zip file'))
+  except zipfile.BadZipfile as e:
+    assert str(e) == 'File is not a zip file'
+  else:
+    raise AssertionError
+
+
+def TestBadCRC():
+  # Change the stored contents of dir/b.txt.
+  corrupt = _ARCHIVE.replace('dir/b.txtb', 'dir/b.txtc', 1)
+  z = zipfile.ZipFile(StringIO.StringIO(corrupt))
+  assert z.testzip() == 'dir/b.txt'
+  try:
+    z.read('dir/b.txt')
+  except zipfile.BadZipfile as e:
+    assert str(e) == 'Bad CRC-32 for file "dir/b.txt"', str(e)
+  else:
+    raise AssertionError
+
+
+def TestWriteStr():
+  buf = StringIO.StringIO()
+  with zipfile.ZipFile(buf, 'w', zipfile.ZIP_DEFLATED) as z:
+    z.writestr('a.txt', 'a' * 100)
+    info = zipfile.ZipInfo('b.txt', (2016, 12, 31, 23, 59, 58))
+    z.writestr(info, 'b', zipfile.ZIP_STORED)
+    assert info.file_size == 1
+    assert info.header_offset > 0
+    z.comment = 'comment'
+  z = zipfile.ZipFile(StringIO.StringIO(buf.getvalue()))
+  assert z.namelist() == ['a.txt', 'b.txt']
+  assert z.comment == 'comment'
+  assert z.getinfo('a.txt').compress_size < 100
+  assert z.read('a.txt') == 'a' * 100
+  assert z.getinfo('b.txt').date_time == (2016, 12, 31, 23, 59, 58)
+  assert z.read('b.txt') == 'b'
+
+
+def TestAppend():
+  buf = StringIO.StringIO(_ARCHIVE)
+  with zipfile.ZipFile(buf, 'a') as z:
+    z.writestr('c.txt', 'c')
+  z = zipfile.ZipFile(StringIO.StringIO(buf.getvalue()))
+  assert z.namelist() == ['a.txt', 'dir/b.txt', 'c.txt']
+  assert z.read('a.txt') == 'hello ' * 10
+  assert z.read('c.txt') == 'c'
+  # Appending to something other than an archive adds one to the end.
+  buf = StringIO.StringIO('prefix')
+  with zipfile.ZipFile(buf, 'a') as z:
+    z.writestr('d.txt', 'd')
+  assert buf.getvalue().startswith('prefix')
+  assert zipfile.ZipFile(StringIO.StringIO(buf.getvalue())).read('d.txt') == 'd'
+
+
+def TestWriteAndExtract():
+  tmpdir = tempfile.mkdtemp()
+  src = os.path.join(tmpdir, 'src.txt')
+  archive = os.path.join(tmpdir, 'archive.zip')
+  with open(src, 'w') as f:
+    f.write('source')
+  with zipfile.ZipFile(archive, 'w') as z:
+    z.write(src, 'src.txt')
+    z.writestr('../../evil.txt', 'evil')
+    z.writestr('nested/dir/', '')
+  assert zipfile.is_zipfile(archive)
+  out = os.path.join(tmpdir, 'out')
+  with zipfile.ZipFile(archive) as z:
+    z.extractall(out)
+  with open(os.path.join(out, 'src.txt')) as f:
+    assert f.read() == 'source'
+  with open(os.path.join(out, 'evil.txt')) as f:
+    assert f.read() == 'evil'
+  assert os.path.isdir(os.path.join(out, 'nested', 'dir'))
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
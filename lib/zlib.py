@@ -0,0 +1,74 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Compression compatible with zlib, backed by Go's compress packages."""
+
+from '__go__/grumpy' import (NewZlibCompressor, NewZlibDecompressor,
+                             ZlibAdler32, ZlibCompress, ZlibCRC32,
+                             ZlibDecompress)
+
+
+MAX_WBITS = 15
+DEFLATED = 8
+DEF_MEM_LEVEL = 8
+ZLIB_VERSION = '1.2.8'
+
+Z_BEST_COMPRESSION = 9
+Z_BEST_SPEED = 1
+Z_DEFAULT_COMPRESSION = -1
+
+Z_DEFAULT_STRATEGY = 0
+Z_FILTERED = 1
+Z_HUFFMAN_ONLY = 2
+
+Z_NO_FLUSH = 0
+Z_SYNC_FLUSH = 2
+Z_FULL_FLUSH = 3
+Z_FINISH = 4
+
+
+class error(Exception):
+  pass
+
+
+def compress(string, level=Z_DEFAULT_COMPRESSION):
+  return ZlibCompress(__frame__(), string, level, error)  # pylint: disable=undefined-variable
+
+
+def decompress(string, wbits=MAX_WBITS, bufsize=16384):  # pylint: disable=unused-argument
+  return ZlibDecompress(__frame__(), string, wbits, error)  # pylint: disable=undefined-variable
+
+
+def compressobj(level=Z_DEFAULT_COMPRESSION, method=DEFLATED, wbits=MAX_WBITS,
+                memlevel=DEF_MEM_LEVEL, strategy=Z_DEFAULT_STRATEGY):  # pylint: disable=unused-argument
+  """Returns a compressor for data too large to compress at once.
+
+  Only the deflate method is supported. memlevel and strategy tune the C
+  implementation and are ignored.
+  """
+  if method != DEFLATED:
+    raise ValueError('Invalid initialization option')
+  return NewZlibCompressor(__frame__(), level, wbits, error)  # pylint: disable=undefined-variable
+
+
+def decompressobj(wbits=MAX_WBITS):
+  return NewZlibDecompressor(__frame__(), wbits, error)  # pylint: disable=undefined-variable
+
+
+def crc32(data, value=0):
+  return ZlibCRC32(__frame__(), data, value)  # pylint: disable=undefined-variable
+
+
+def adler32(data, value=1):
+  return ZlibAdler32(__frame__(), data, value)  # pylint: disable=undefined-variable
@@ -0,0 +1,75 @@
+# Copyright 2016 Google Inc. All Rights Reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import zlib
+
+import weetest
+
+
+_HELLO = 'x\x9c\xcbH\xcd\xc9\xc9\x07\x00\x06,\x02\x15'
+_DATA = 'the quick brown fox jumps over the lazy dog\n' * 100
+
+
+def TestCompress():
+  assert zlib.decompress(_HELLO) == 'hello'
+  assert zlib.decompress(zlib.compress(_DATA)) == _DATA
+  assert zlib.decompress(zlib.compress(_DATA, zlib.Z_BEST_SPEED)) == _DATA
+  assert len(zlib.compress(_DATA)) < len(_DATA)
+
+
+def TestDecompressError():
+  try:
+    zlib.decompress('not compressed')
+  except zlib.error as e:
+    assert str(e) == 'Error -3 while decompressing data: incorrect header check'
+  else:
+    raise AssertionError
+
+
+def TestChecksums():
+  assert zlib.crc32('hello') == 907060870
+  assert zlib.adler32('hello') == 103547413
+  assert zlib.crc32('llo', zlib.crc32('he')) == zlib.crc32('hello')
+  assert zlib.adler32('llo', zlib.adler32('he')) == zlib.adler32('hello')
+
+
+def TestStreaming():
+  c = zlib.compressobj()
+  parts = [c.compress(_DATA[i:i+100]) for i in xrange(0, len(_DATA), 100)]
+  parts.append(c.flush())
+  compressed = ''.join(parts)
+  assert zlib.decompress(compressed) == _DATA
+  d = zlib.decompressobj()
+  out = [d.decompress(compressed[i:i+7]) for i in xrange(0, len(compressed), 7)]
+  out.append(d.decompress('trailing'))
+  out.append(d.flush())
+  assert ''.join(out) == _DATA
+  assert d.unused_data == 'trailing'
+
+
+def TestRawAndGzip():
+  c = zlib.compressobj(zlib.Z_DEFAULT_COMPRESSION, zlib.DEFLATED,
+                       -zlib.MAX_WBITS)
+  raw = c.compress(_DATA) + c.flush()
+  assert zlib.decompress(raw, -zlib.MAX_WBITS) == _DATA
+  c = zlib.compressobj(zlib.Z_DEFAULT_COMPRESSION, zlib.DEFLATED,
+                       16 + zlib.MAX_WBITS)
+  gz = c.compress(_DATA) + c.flush()
+  assert gz.startswith('\x1f\x8b')
+  assert zlib.decompress(gz, 16 + zlib.MAX_WBITS) == _DATA
+  assert zlib.decompress(gz, 32 + zlib.MAX_WBITS) == _DATA
+
+
+if __name__ == '__main__':
+  weetest.RunTests()
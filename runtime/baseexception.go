@@ -36,12 +36,47 @@ func (e *BaseException) ToObject() *Object {
 // BaseExceptionType corresponds to the Python type 'BaseException'.
 var BaseExceptionType = newBasisType("BaseException", reflect.TypeOf(BaseException{}), toBaseExceptionUnsafe, ObjectType)
 
+func baseExceptionGetArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_args", args, BaseExceptionType); raised != nil {
+		return nil, raised
+	}
+	e := toBaseExceptionUnsafe(args[0])
+	if e.args == nil {
+		return NewTuple().ToObject(), nil
+	}
+	return e.args.ToObject(), nil
+}
+
+func baseExceptionGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
+	e := toBaseExceptionUnsafe(o)
+	if e.args == nil {
+		return nil, f.RaiseType(IndexErrorType, "tuple index out of range")
+	}
+	return GetItem(f, e.args.ToObject(), key)
+}
+
 func baseExceptionInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	e := toBaseExceptionUnsafe(o)
 	e.args = NewTuple(args.makeCopy()...)
 	return None, nil
 }
 
+func baseExceptionReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, BaseExceptionType); raised != nil {
+		return nil, raised
+	}
+	o := args[0]
+	e := toBaseExceptionUnsafe(o)
+	excArgs := e.args
+	if excArgs == nil {
+		excArgs = NewTuple()
+	}
+	if d := o.Dict(); d != nil && d.Len() > 0 {
+		return NewTuple3(o.typ.ToObject(), excArgs.ToObject(), d.ToObject()).ToObject(), nil
+	}
+	return NewTuple2(o.typ.ToObject(), excArgs.ToObject()).ToObject(), nil
+}
+
 func baseExceptionRepr(f *Frame, o *Object) (*Object, *BaseException) {
 	e := toBaseExceptionUnsafe(o)
 	argsString := "()"
@@ -68,7 +103,22 @@ func baseExceptionStr(f *Frame, o *Object) (*Object, *BaseException) {
 	return s.ToObject(), raised
 }
 
-func initBaseExceptionType(map[string]*Object) {
+func baseExceptionSetArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_set_args", args, BaseExceptionType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	t, raised := TupleType.Call(f, args[1:], nil)
+	if raised != nil {
+		return nil, raised
+	}
+	toBaseExceptionUnsafe(args[0]).args = toTupleUnsafe(t)
+	return None, nil
+}
+
+func initBaseExceptionType(dict map[string]*Object) {
+	dict["args"] = newProperty(newBuiltinFunction("_get_args", baseExceptionGetArgs).ToObject(), newBuiltinFunction("_set_args", baseExceptionSetArgs).ToObject(), nil).ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", baseExceptionReduce).ToObject()
+	BaseExceptionType.slots.GetItem = &binaryOpSlot{baseExceptionGetItem}
 	BaseExceptionType.slots.Init = &initSlot{baseExceptionInit}
 	BaseExceptionType.slots.Repr = &unaryOpSlot{baseExceptionRepr}
 	BaseExceptionType.slots.Str = &unaryOpSlot{baseExceptionStr}
@@ -15,6 +15,8 @@
 package grumpy
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -80,3 +82,155 @@ func TestBaseExceptionStr(t *testing.T) {
 		}
 	}
 }
+
+func TestBaseExceptionArgs(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, e *Object, args *Object) (*Object, *BaseException) {
+		if args != None {
+			if raised := SetAttr(f, e, NewStr("args"), args); raised != nil {
+				return nil, raised
+			}
+		}
+		return GetAttr(f, e, NewStr("args"), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newObject(TypeErrorType), None), want: NewTuple().ToObject()},
+		{args: wrapArgs(mustCreateException(ValueErrorType, "foo"), None), want: newTestTuple("foo").ToObject()},
+		{args: wrapArgs(newObject(ExceptionType), newTestList(1, 2)), want: newTestTuple(1, 2).ToObject()},
+		{args: wrapArgs(newObject(ExceptionType), 42), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestBaseExceptionGetItem(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(mustNotRaise(ExceptionType.Call(NewRootFrame(), wrapArgs("foo", 42), nil)), 1), want: NewInt(42).ToObject()},
+		{args: wrapArgs(newObject(ExceptionType), 0), wantExc: mustCreateException(IndexErrorType, "tuple index out of range")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(BaseExceptionType, "__getitem__", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestBaseExceptionHash(t *testing.T) {
+	f := NewRootFrame()
+	e1 := mustNotRaise(ValueErrorType.Call(f, wrapArgs("foo"), nil))
+	e2 := mustNotRaise(ValueErrorType.Call(f, wrapArgs("foo"), nil))
+	s := NewSet()
+	for _, e := range []*Object{e1, e2, e1} {
+		if _, raised := s.Add(f, e); raised != nil {
+			t.Fatal(raised)
+		}
+	}
+	if got := s.dict.Len(); got != 2 {
+		t.Errorf("len(%v) = %d, want 2", s, got)
+	}
+	for _, key := range []*Object{toBaseExceptionUnsafe(e1).args.ToObject(), toBaseExceptionUnsafe(e2).args.ToObject()} {
+		if _, raised := s.Add(f, key); raised != nil {
+			t.Fatal(raised)
+		}
+	}
+	if got := s.dict.Len(); got != 3 {
+		t.Errorf("len(%v) = %d, want 3", s, got)
+	}
+}
+
+func TestBaseExceptionReduce(t *testing.T) {
+	f := NewRootFrame()
+	withDict := mustNotRaise(ExceptionType.Call(f, wrapArgs(1), nil))
+	if raised := withDict.Dict().SetItemString(f, "foo", NewStr("bar").ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
+	cases := []invokeTestCase{
+		{args: wrapArgs(newObject(KeyErrorType)), want: newTestTuple(KeyErrorType, NewTuple()).ToObject()},
+		{args: wrapArgs(mustNotRaise(IOErrorType.Call(f, wrapArgs(2, "foo"), nil))), want: newTestTuple(IOErrorType, newTestTuple(2, "foo")).ToObject()},
+		{args: wrapArgs(withDict), want: newTestTuple(ExceptionType, newTestTuple(1), newTestDict("foo", "bar")).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(BaseExceptionType, "__reduce__", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSystemExitCode(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		e, raised := SystemExitType.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, e, NewStr("code"), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(), want: None},
+		{args: wrapArgs(42), want: NewInt(42).ToObject()},
+		{args: wrapArgs("foo"), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(1, 2), want: newTestTuple(1, 2).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestGoError(t *testing.T) {
+	goErr := errors.New("foo")
+	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Tuple, *BaseException) {
+		e, raised := GoErrorType.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		s, raised := ToStr(f, e)
+		if raised != nil {
+			return nil, raised
+		}
+		o, raised := GetAttr(f, e, NewStr("go_error"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		native, raised := ToNative(f, e)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(s.ToObject(), o, GetBool(native.Interface() == goErr).ToObject()), nil
+	})
+	wrapped := mustNotRaise(WrapNative(NewRootFrame(), reflect.ValueOf(goErr)))
+	cases := []invokeTestCase{
+		{args: wrapArgs(wrapped), want: newTestTuple("foo", wrapped, true).ToObject()},
+		{args: wrapArgs("bar"), want: newTestTuple("bar", None, false).ToObject()},
+		{args: wrapArgs(1, "bar"), want: newTestTuple("(1, 'bar')", None, false).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestRaiseGoError(t *testing.T) {
+	f := NewRootFrame()
+	goErr := errors.New("foo")
+	raised := RaiseGoError(f, goErr)
+	if raised == nil || raised.typ != GoErrorType || !raised.isInstance(IOErrorType) {
+		t.Fatalf("RaiseGoError() = %v, want GoError", raised)
+	}
+	if exc, _ := f.ExcInfo(); exc != raised {
+		t.Errorf("ExcInfo() = %v, want %v", exc, raised)
+	}
+	if got := FormatExc(f); got != "GoError: foo\n" {
+		t.Errorf(`FormatExc() = %q, want "GoError: foo\n"`, got)
+	}
+	native, raised := ToNative(f, raised.ToObject())
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if native.Interface() != goErr {
+		t.Errorf("ToNative(GoError) = %v, want %v", native, goErr)
+	}
+}
@@ -31,6 +31,9 @@ const (
 	EncodeReplace = "replace"
 	// EncodeIgnore discards bad chars.
 	EncodeIgnore = "ignore"
+	// EncodeBackslashReplace replaces chars that can't be encoded with
+	// backslash escape sequences such as "\u20ac".
+	EncodeBackslashReplace = "backslashreplace"
 )
 
 var (
@@ -0,0 +1,138 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const binasciiBase64Pad = '='
+
+// binasciiBase64Values maps the characters of the standard base64 alphabet
+// to their values. Other characters map to -1.
+var binasciiBase64Values = func() [256]int {
+	var values [256]int
+	for i := range values {
+		values[i] = -1
+	}
+	for i, c := range "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/" {
+		values[c] = i
+	}
+	return values
+}()
+
+// BinasciiHexlify returns the hexadecimal representation of the bytes held by
+// data.
+func BinasciiHexlify(f *Frame, data *Object) (*Str, *BaseException) {
+	b, raised := bufferData(f, data, "must be string or buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	return NewStr(hex.EncodeToString(b)), nil
+}
+
+// BinasciiUnhexlify returns the bytes represented by the hexadecimal digits
+// in data, raising TypeError if data is not an even number of hex digits.
+func BinasciiUnhexlify(f *Frame, data *Object) (*Str, *BaseException) {
+	s, raised := bufferData(f, data, "must be string or buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	if len(s)%2 != 0 {
+		return nil, f.RaiseType(TypeErrorType, "Odd-length string")
+	}
+	b := make([]byte, hex.DecodedLen(len(s)))
+	if _, err := hex.Decode(b, s); err != nil {
+		return nil, f.RaiseType(TypeErrorType, "Non-hexadecimal digit found")
+	}
+	return NewStr(string(b)), nil
+}
+
+// BinasciiEncodeBase64 returns the base64 encoding of the bytes held by data.
+// altchars is either empty or holds the two characters that replace '+' and
+// '/' in the encoding.
+func BinasciiEncodeBase64(f *Frame, data *Object, altchars string) (*Str, *BaseException) {
+	b, raised := bufferData(f, data, "must be string or buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	encoding := base64.StdEncoding
+	if altchars != "" {
+		if len(altchars) != 2 {
+			return nil, f.RaiseType(ValueErrorType, "altchars must be 2 characters")
+		}
+		encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789" + altchars)
+	}
+	return NewStr(encoding.EncodeToString(b)), nil
+}
+
+// BinasciiDecodeBase64 decodes the base64 data held by data, raising
+// errorType if it is incorrectly padded. altchars is either empty or holds two
+// characters accepted in place of '+' and '/'. Like CPython's binascii, other
+// characters outside of the alphabet are ignored.
+func BinasciiDecodeBase64(f *Frame, data *Object, altchars string, errorType *Type) (*Str, *BaseException) {
+	s, raised := bufferData(f, data, "must be string or buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	if altchars != "" && len(altchars) != 2 {
+		return nil, f.RaiseType(ValueErrorType, "altchars must be 2 characters")
+	}
+	values := binasciiBase64Values
+	if altchars != "" {
+		values[altchars[0]] = values['+']
+		values[altchars[1]] = values['/']
+	}
+	// nextValid returns the first character of s[i:] that is in the
+	// alphabet or is padding, or -1 if there is none.
+	nextValid := func(i int) int {
+		for ; i < len(s); i++ {
+			if s[i] == binasciiBase64Pad || values[s[i]] >= 0 {
+				return int(s[i])
+			}
+		}
+		return -1
+	}
+	b := make([]byte, 0, len(s)*3/4)
+	quadPos, leftBits, leftChar := 0, uint(0), 0
+	for i, c := range s {
+		if c == binasciiBase64Pad {
+			// Padding ends the data once at least two characters of
+			// a quad have been seen and it's complete.
+			if quadPos < 2 || (quadPos == 2 && nextValid(i+1) != binasciiBase64Pad) {
+				continue
+			}
+			leftBits = 0
+			break
+		}
+		v := values[c]
+		if v < 0 {
+			continue
+		}
+		quadPos = (quadPos + 1) & 3
+		leftChar = leftChar<<6 | v
+		leftBits += 6
+		if leftBits >= 8 {
+			leftBits -= 8
+			b = append(b, byte(leftChar>>leftBits))
+			leftChar &= 1<<leftBits - 1
+		}
+	}
+	if leftBits != 0 {
+		return nil, f.RaiseType(errorType, "Incorrect padding")
+	}
+	return NewStr(string(b)), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestBinasciiHexlify(t *testing.T) {
+	hexlify := wrapFuncForTest(func(f *Frame, data *Object) (*Str, *BaseException) {
+		return BinasciiHexlify(f, data)
+	})
+	unhexlify := wrapFuncForTest(func(f *Frame, data *Object) (*Str, *BaseException) {
+		return BinasciiUnhexlify(f, data)
+	})
+	cases := []struct {
+		fun *Object
+		cas invokeTestCase
+	}{
+		{hexlify, invokeTestCase{args: wrapArgs(""), want: NewStr("").ToObject()}},
+		{hexlify, invokeTestCase{args: wrapArgs("\x00\xffab"), want: NewStr("00ff6162").ToObject()}},
+		{hexlify, invokeTestCase{args: wrapArgs(newTestByteArray("\x01")), want: NewStr("01").ToObject()}},
+		{hexlify, invokeTestCase{args: wrapArgs(123), wantExc: mustCreateException(TypeErrorType, "must be string or buffer, not int")}},
+		{unhexlify, invokeTestCase{args: wrapArgs("00FF6162"), want: NewStr("\x00\xffab").ToObject()}},
+		{unhexlify, invokeTestCase{args: wrapArgs("abc"), wantExc: mustCreateException(TypeErrorType, "Odd-length string")}},
+		{unhexlify, invokeTestCase{args: wrapArgs("zz"), wantExc: mustCreateException(TypeErrorType, "Non-hexadecimal digit found")}},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(cas.fun, &cas.cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestBinasciiEncodeBase64(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, data *Object, altchars string) (*Str, *BaseException) {
+		return BinasciiEncodeBase64(f, data, altchars)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("", ""), want: NewStr("").ToObject()},
+		{args: wrapArgs("abc", ""), want: NewStr("YWJj").ToObject()},
+		{args: wrapArgs("a", ""), want: NewStr("YQ==").ToObject()},
+		{args: wrapArgs("\xfb\xff", ""), want: NewStr("+/8=").ToObject()},
+		{args: wrapArgs("\xfb\xff", "-_"), want: NewStr("-_8=").ToObject()},
+		{args: wrapArgs("a", "-"), wantExc: mustCreateException(ValueErrorType, "altchars must be 2 characters")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestBinasciiDecodeBase64(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, data *Object, altchars string) (*Str, *BaseException) {
+		return BinasciiDecodeBase64(f, data, altchars, RuntimeErrorType)
+	})
+	// Expected values were produced by CPython's binascii.a2b_base64.
+	cases := []invokeTestCase{
+		{args: wrapArgs("", ""), want: NewStr("").ToObject()},
+		{args: wrapArgs("YWJj", ""), want: NewStr("abc").ToObject()},
+		{args: wrapArgs("YWI=", ""), want: NewStr("ab").ToObject()},
+		{args: wrapArgs("YQ==", ""), want: NewStr("a").ToObject()},
+		{args: wrapArgs("YW Jj\n", ""), want: NewStr("abc").ToObject()},
+		{args: wrapArgs("YWJj=YWJj", ""), want: NewStr("abcabc").ToObject()},
+		{args: wrapArgs("YQ==YQ==", ""), want: NewStr("a").ToObject()},
+		{args: wrapArgs("Y=Q==", ""), want: NewStr("a").ToObject()},
+		{args: wrapArgs("=YQ==", ""), want: NewStr("a").ToObject()},
+		{args: wrapArgs("YWJj\xff", ""), want: NewStr("abc").ToObject()},
+		{args: wrapArgs("-_8=", "-_"), want: NewStr("\xfb\xff").ToObject()},
+		{args: wrapArgs("+/8=", "-_"), want: NewStr("\xfb\xff").ToObject()},
+		{args: wrapArgs(newTestByteArray("YQ=="), ""), want: NewStr("a").ToObject()},
+		{args: wrapArgs("YQ=", ""), wantExc: mustCreateException(RuntimeErrorType, "Incorrect padding")},
+		{args: wrapArgs("Y", ""), wantExc: mustCreateException(RuntimeErrorType, "Incorrect padding")},
+		{args: wrapArgs("YQ==", "-"), wantExc: mustCreateException(ValueErrorType, "altchars must be 2 characters")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package grumpy
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"math/big"
@@ -100,20 +101,37 @@ type builtinTypeInfo struct {
 var builtinTypes = map[*Type]*builtinTypeInfo{
 	ArithmeticErrorType:           {global: true},
 	AssertionErrorType:            {global: true},
+	AttrGetterType:                {init: initAttrGetterType},
 	AttributeErrorType:            {global: true},
 	BaseExceptionType:             {init: initBaseExceptionType, global: true},
 	BaseStringType:                {init: initBaseStringType, global: true},
 	BoolType:                      {init: initBoolType, global: true},
 	ByteArrayType:                 {init: initByteArrayType, global: true},
 	BytesWarningType:              {global: true},
-	CodeType:                      {},
+	CodeType:                      {init: initCodeType},
+	CombinationsType:              {init: initCombinationsType},
 	ComplexType:                   {init: initComplexType, global: true},
+	CountType:                     {init: initCountType},
+	CSVDialectType:                {init: initCSVDialectType},
+	CSVReaderType:                 {init: initCSVReaderType},
+	CSVWriterType:                 {init: initCSVWriterType},
+	CycleType:                     {init: initCycleType},
+	DateTimeType:                  {init: initDateTimeType},
+	DateType:                      {init: initDateType},
+	ChainType:                     {init: initChainType},
 	ClassMethodType:               {init: initClassMethodType, global: true},
+	DefaultDictType:               {init: initDefaultDictType},
 	DeprecationWarningType:        {global: true},
+	dequeIteratorType:             {init: initDequeIteratorType},
+	DequeType:                     {init: initDequeType},
 	dictItemIteratorType:          {init: initDictItemIteratorType},
+	dictItemsViewType:             {init: initDictItemsViewType},
 	dictKeyIteratorType:           {init: initDictKeyIteratorType},
+	dictKeysViewType:              {init: initDictKeysViewType},
 	dictValueIteratorType:         {init: initDictValueIteratorType},
+	dictValuesViewType:            {init: initDictValuesViewType},
 	DictType:                      {init: initDictType, global: true},
+	DigestType:                    {init: initDigestType},
 	EllipsisType:                  {init: initEllipsisType, global: true},
 	enumerateType:                 {init: initEnumerateType, global: true},
 	EnvironmentErrorType:          {global: true},
@@ -126,23 +144,37 @@ var builtinTypes = map[*Type]*builtinTypeInfo{
 	FunctionType:                  {init: initFunctionType},
 	FutureWarningType:             {global: true},
 	GeneratorType:                 {init: initGeneratorType},
+	GoErrorType:                   {init: initGoErrorType},
+	grouperType:                   {init: initGrouperType},
+	GroupByType:                   {init: initGroupByType},
 	ImportErrorType:               {global: true},
 	ImportWarningType:             {global: true},
 	IndexErrorType:                {global: true},
 	IntType:                       {init: initIntType, global: true},
+	IFilterFalseType:              {init: initIFilterFalseType},
+	IFilterType:                   {init: initIFilterType},
+	IMapType:                      {init: initIMapType},
+	ISliceType:                    {init: initISliceType},
+	ItemGetterType:                {init: initItemGetterType},
+	IZipType:                      {init: initIZipType},
 	IOErrorType:                   {global: true},
 	KeyboardInterruptType:         {global: true},
+	KeyWrapperType:                {init: initKeyWrapperType},
 	KeyErrorType:                  {global: true},
 	listIteratorType:              {init: initListIteratorType},
 	ListType:                      {init: initListType, global: true},
 	LongType:                      {init: initLongType, global: true},
 	LookupErrorType:               {global: true},
+	memberDescriptorType:          {init: initMemberDescriptorType},
 	MemoryErrorType:               {global: true},
+	MethodCallerType:              {init: initMethodCallerType},
 	MethodType:                    {init: initMethodType},
 	ModuleType:                    {init: initModuleType},
 	NameErrorType:                 {global: true},
 	nativeBoolMetaclassType:       {init: initNativeBoolMetaclassType},
+	nativeChanType:                {init: initNativeChanType},
 	nativeFuncType:                {init: initNativeFuncType},
+	nativeMapType:                 {init: initNativeMapType},
 	nativeMetaclassType:           {init: initNativeMetaclassType},
 	nativeSliceType:               {init: initNativeSliceType},
 	nativeType:                    {init: initNativeType},
@@ -150,29 +182,44 @@ var builtinTypes = map[*Type]*builtinTypeInfo{
 	NotImplementedErrorType:       {global: true},
 	NotImplementedType:            {init: initNotImplementedType, global: true},
 	ObjectType:                    {init: initObjectType, global: true},
+	orderedDictIteratorType:       {init: initOrderedDictIteratorType},
+	OrderedDictType:               {init: initOrderedDictType},
 	OSErrorType:                   {global: true},
 	OverflowErrorType:             {global: true},
 	PendingDeprecationWarningType: {global: true},
+	PartialType:                   {init: initPartialType},
+	pipelineIteratorType:          {init: initPipelineIteratorType},
+	PermutationsType:              {init: initPermutationsType},
+	ProductType:                   {init: initProductType},
 	PropertyType:                  {init: initPropertyType, global: true},
+	RandomType:                    {init: initRandomType},
 	rangeIteratorType:             {init: initRangeIteratorType, global: true},
 	ReferenceErrorType:            {global: true},
+	RepeatType:                    {init: initRepeatType},
 	RuntimeErrorType:              {global: true},
 	RuntimeWarningType:            {global: true},
+	SecurityErrorType:             {global: true},
 	seqIteratorType:               {init: initSeqIteratorType},
 	SetType:                       {init: initSetType, global: true},
 	sliceIteratorType:             {init: initSliceIteratorType},
 	SliceType:                     {init: initSliceType, global: true},
 	StandardErrorType:             {global: true},
 	StaticMethodType:              {init: initStaticMethodType, global: true},
+	StarMapType:                   {init: initStarMapType},
 	StopIterationType:             {global: true},
 	StrType:                       {init: initStrType, global: true},
+	StringIOType:                  {init: initStringIOType},
 	superType:                     {init: initSuperType, global: true},
 	SyntaxErrorType:               {global: true},
 	SyntaxWarningType:             {global: true},
 	SystemErrorType:               {global: true},
 	SystemExitType:                {global: true, init: initSystemExitType},
+	TeeType:                       {init: initTeeType},
+	TimeDeltaType:                 {init: initTimeDeltaType},
+	TimeType:                      {init: initTimeType},
 	TracebackType:                 {init: initTracebackType},
 	TupleType:                     {init: initTupleType, global: true},
+	TZInfoType:                    {init: initTZInfoType},
 	TypeErrorType:                 {global: true},
 	TypeType:                      {init: initTypeType, global: true},
 	UnboundLocalErrorType:         {global: true},
@@ -180,6 +227,7 @@ var builtinTypes = map[*Type]*builtinTypeInfo{
 	UnicodeDecodeErrorType:        {global: true},
 	UnicodeEncodeErrorType:        {global: true},
 	UnicodeErrorType:              {global: true},
+	unicodeIteratorType:           {init: initUnicodeIteratorType},
 	UnicodeType:                   {init: initUnicodeType, global: true},
 	UnicodeWarningType:            {global: true},
 	UserWarningType:               {global: true},
@@ -188,6 +236,8 @@ var builtinTypes = map[*Type]*builtinTypeInfo{
 	WeakRefType:                   {init: initWeakRefType},
 	xrangeType:                    {init: initXRangeType, global: true},
 	ZeroDivisionErrorType:         {global: true},
+	ZlibCompressorType:            {init: initZlibCompressorType},
+	ZlibDecompressorType:          {init: initZlibDecompressorType},
 }
 
 func initBuiltinType(typ *Type, info *builtinTypeInfo) {
@@ -224,6 +274,18 @@ func builtinMapFn(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if argc < 2 {
 		return nil, f.RaiseType(TypeErrorType, "map() requires at least two args")
 	}
+	if _, ok := pipelineIteratorFusable(args[1:]); ok {
+		// Run fn in the same loop as the stages of the pipeline.
+		var stages []pipelineStage
+		if args[0] != None {
+			stages = []pipelineStage{{pipelineMap, args[0]}}
+		}
+		result, raised := pipelineCollect(f, args[1], stages)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewList(result...).ToObject(), nil
+	}
 	result := make([]*Object, 0, 2)
 	z, raised := zipLongest(f, args[1:])
 	if raised != nil {
@@ -311,10 +373,13 @@ func builtinCallable(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 }
 
 func builtinChr(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	if raised := checkFunctionArgs(f, "chr", args, IntType); raised != nil {
+	if raised := checkFunctionArgs(f, "chr", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	i, raised := ToIntValue(f, args[0])
+	if raised != nil {
 		return nil, raised
 	}
-	i := toIntUnsafe(args[0]).Value()
 	if i < 0 || i > 255 {
 		return nil, f.RaiseType(ValueErrorType, "chr() arg not in range(256)")
 	}
@@ -328,54 +393,126 @@ func builtinCmp(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	return Compare(f, args[0], args[1])
 }
 
+func builtinCompile(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	argc := len(args)
+	expectedTypes := []*Type{ObjectType, StrType, StrType, IntType, ObjectType}
+	if argc >= 3 && argc < 5 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkFunctionArgs(f, "compile", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	// Only the flag requesting an AST is significant since future
+	// statements have no effect on the interpreted code.
+	if argc > 3 && toIntUnsafe(args[3]).Value()&compileOnlyAST != 0 {
+		return nil, f.RaiseType(NotImplementedErrorType, "compile() cannot produce an AST")
+	}
+	c, raised := Compile(f, args[0], toStrUnsafe(args[1]).Value(), toStrUnsafe(args[2]).Value())
+	if raised != nil {
+		return nil, raised
+	}
+	return c.ToObject(), nil
+}
+
 func builtinDelAttr(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	if raised := checkFunctionArgs(f, "delattr", args, ObjectType, StrType); raised != nil {
+	if raised := checkFunctionArgs(f, "delattr", args, ObjectType, BaseStringType); raised != nil {
+		return nil, raised
+	}
+	name, raised := basestringToStr(f, args[1])
+	if raised != nil {
 		return nil, raised
 	}
-	return None, DelAttr(f, args[0], toStrUnsafe(args[1]))
+	return None, DelAttr(f, args[0], name)
 }
 
 func builtinDir(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	// TODO: Support __dir__.
+	if len(args) == 0 {
+		l := f.Locals().Keys(f)
+		if raised := l.Sort(f); raised != nil {
+			return nil, raised
+		}
+		return l.ToObject(), nil
+	}
 	if raised := checkFunctionArgs(f, "dir", args, ObjectType); raised != nil {
 		return nil, raised
 	}
-	d := NewDict()
-	o := args[0]
+	l, raised := Dir(f, args[0])
+	if raised != nil {
+		return nil, raised
+	}
+	return l.ToObject(), nil
+}
+
+func builtinDivMod(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "divmod", args, ObjectType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return DivMod(f, args[0], args[1])
+}
+
+func builtinEval(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	argc := len(args)
+	expectedTypes := []*Type{ObjectType, ObjectType, ObjectType}
+	if argc > 0 && argc < 3 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkFunctionArgs(f, "eval", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	var globals *Dict
+	var locals *Object
+	if argc > 1 && args[1] != None {
+		if !args[1].isInstance(DictType) {
+			return nil, f.RaiseType(TypeErrorType, "globals must be a dict")
+		}
+		globals = toDictUnsafe(args[1])
+	}
+	if argc > 2 && args[2] != None {
+		locals = args[2]
+	}
+	return Eval(f, args[0], globals, locals)
+}
+
+func builtinFilter(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "filter", args, ObjectType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	fn, seq := args[0], args[1]
+	stages := []pipelineStage{{pipelineFilter, fn}}
 	switch {
-	case o.isInstance(TypeType):
-		for _, t := range toTypeUnsafe(o).mro {
-			if raised := d.Update(f, t.Dict().ToObject()); raised != nil {
+	case seq.isInstance(StrType):
+		var buf bytes.Buffer
+		for _, c := range []byte(toStrUnsafe(seq).Value()) {
+			item, raised := pipelineApply(f, stages, Args{NewStr(string(c)).ToObject()})
+			if raised != nil {
 				return nil, raised
 			}
-		}
-	case o.isInstance(ModuleType):
-		d.Update(f, o.Dict().ToObject())
-	default:
-		d = NewDict()
-		if dict := o.Dict(); dict != nil {
-			if raised := d.Update(f, dict.ToObject()); raised != nil {
-				return nil, raised
+			if item != nil {
+				buf.WriteByte(c)
 			}
 		}
-		for _, t := range o.typ.mro {
-			if raised := d.Update(f, t.Dict().ToObject()); raised != nil {
+		return NewStr(buf.String()).ToObject(), nil
+	case seq.isInstance(UnicodeType):
+		var result []rune
+		for _, r := range toUnicodeUnsafe(seq).Value() {
+			item, raised := pipelineApply(f, stages, Args{NewUnicodeFromRunes([]rune{r}).ToObject()})
+			if raised != nil {
 				return nil, raised
 			}
+			if item != nil {
+				result = append(result, r)
+			}
 		}
+		return NewUnicodeFromRunes(result).ToObject(), nil
 	}
-	l := d.Keys(f)
-	if raised := l.Sort(f); raised != nil {
+	result, raised := pipelineCollect(f, seq, stages)
+	if raised != nil {
 		return nil, raised
 	}
-	return l.ToObject(), nil
-}
-
-func builtinDivMod(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	if raised := checkFunctionArgs(f, "divmod", args, ObjectType, ObjectType); raised != nil {
-		return nil, raised
+	if seq.isInstance(TupleType) {
+		return NewTuple(result...).ToObject(), nil
 	}
-	return DivMod(f, args[0], args[1])
+	return NewList(result...).ToObject(), nil
 }
 
 func builtinFrame(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
@@ -387,7 +524,7 @@ func builtinFrame(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 }
 
 func builtinGetAttr(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	expectedTypes := []*Type{ObjectType, StrType, ObjectType}
+	expectedTypes := []*Type{ObjectType, BaseStringType, ObjectType}
 	argc := len(args)
 	if argc == 2 {
 		expectedTypes = expectedTypes[:2]
@@ -395,11 +532,15 @@ func builtinGetAttr(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException
 	if raised := checkFunctionArgs(f, "getattr", args, expectedTypes...); raised != nil {
 		return nil, raised
 	}
+	name, raised := basestringToStr(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
 	var def *Object
 	if argc == 3 {
 		def = args[2]
 	}
-	return GetAttr(f, args[0], toStrUnsafe(args[1]), def)
+	return GetAttr(f, args[0], name, def)
 }
 
 func builtinGlobals(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -410,10 +551,14 @@ func builtinGlobals(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException
 }
 
 func builtinHasAttr(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	if raised := checkFunctionArgs(f, "hasattr", args, ObjectType, StrType); raised != nil {
+	if raised := checkFunctionArgs(f, "hasattr", args, ObjectType, BaseStringType); raised != nil {
 		return nil, raised
 	}
-	if _, raised := GetAttr(f, args[0], toStrUnsafe(args[1]), nil); raised != nil {
+	name, raised := basestringToStr(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	if _, raised := GetAttr(f, args[0], name, nil); raised != nil {
 		if raised.isInstance(AttributeErrorType) {
 			f.RestoreExc(nil, nil)
 			return False.ToObject(), nil
@@ -449,6 +594,16 @@ func builtinID(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return NewInt(int(uintptr(args[0].toPointer()))).ToObject(), nil
 }
 
+func builtinIntern(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "intern", args, StrType); raised != nil {
+		return nil, raised
+	}
+	if args[0].typ != StrType {
+		return nil, f.RaiseType(TypeErrorType, "can't intern subclass of string")
+	}
+	return internStrDynamic(toStrUnsafe(args[0])).ToObject(), nil
+}
+
 func builtinIsInstance(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	if raised := checkFunctionArgs(f, "isinstance", args, ObjectType, ObjectType); raised != nil {
 		return nil, raised
@@ -489,6 +644,13 @@ func builtinLen(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return ret.ToObject(), nil
 }
 
+func builtinLocals(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "locals", args); raised != nil {
+		return nil, raised
+	}
+	return f.Locals().ToObject(), nil
+}
+
 func builtinMax(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return builtinMinMax(f, true, args, kwargs)
 }
@@ -546,10 +708,65 @@ func builtinOrd(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	return NewInt(result).ToObject(), nil
 }
 
+func builtinPow(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{ObjectType, ObjectType, ObjectType}
+	if len(args) == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkFunctionArgs(f, "pow", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	if len(args) == 2 || args[2] == None {
+		return Pow(f, args[0], args[1])
+	}
+	x, y, z := args[0], args[1], args[2]
+	if x.isInstance(FloatType) || y.isInstance(FloatType) || z.isInstance(FloatType) {
+		return nil, f.RaiseType(TypeErrorType, "pow() 3rd argument not allowed unless all arguments are integers")
+	}
+	isInteger := func(o *Object) bool {
+		return o.isInstance(IntType) || o.isInstance(LongType)
+	}
+	if !isInteger(x) || !isInteger(y) || !isInteger(z) {
+		// Only a user defined __pow__ can accept the modulus, the
+		// builtin numeric types' methods are strictly binary.
+		if !isInteger(x) && !x.isInstance(ComplexType) {
+			pow, raised := x.typ.mroLookup(f, NewStr("__pow__"))
+			if raised != nil {
+				return nil, raised
+			}
+			if pow != nil {
+				return pow.Call(f, Args{x, y, z}, nil)
+			}
+		}
+		format := "unsupported operand type(s) for pow(): '%s', '%s', '%s'"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, x.typ.Name(), y.typ.Name(), z.typ.Name()))
+	}
+	toBig := func(o *Object) *big.Int {
+		if o.isInstance(LongType) {
+			return toLongUnsafe(o).Value()
+		}
+		return big.NewInt(int64(toIntUnsafe(o).Value()))
+	}
+	xBig, yBig, zBig := toBig(x), toBig(y), toBig(z)
+	if yBig.Sign() < 0 {
+		return nil, f.RaiseType(TypeErrorType, "pow() 2nd argument cannot be negative when 3rd argument specified")
+	}
+	if zBig.Sign() == 0 {
+		return nil, f.RaiseType(ValueErrorType, "pow() 3rd argument cannot be 0")
+	}
+	result := new(big.Int)
+	longPowMod(result, xBig, yBig, zBig)
+	if x.isInstance(IntType) && y.isInstance(IntType) && z.isInstance(IntType) {
+		// The result is smaller in magnitude than z so it fits in an int.
+		return NewInt(int(result.Int64())).ToObject(), nil
+	}
+	return NewLong(result).ToObject(), nil
+}
+
 func builtinPrint(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	sep := " "
 	end := "\n"
-	file := Stdout
+	file := None
 	for _, kwarg := range kwargs {
 		switch kwarg.Name {
 		case "sep":
@@ -565,12 +782,18 @@ func builtinPrint(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException)
 			}
 			end = kwend.Value()
 		case "file":
-			// TODO: need to map Python sys.stdout, sys.stderr etc. to os.Stdout,
-			// os.Stderr, but for other file-like objects would need to recover
-			// to the file descriptor probably
+			file = kwarg.Value
+		default:
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("'%s' is an invalid keyword argument for this function", kwarg.Name))
+		}
+	}
+	if file == None {
+		var raised *BaseException
+		if file, raised = sysStream(f, "stdout"); raised != nil {
+			return nil, raised
 		}
 	}
-	return nil, pyPrint(f, args, sep, end, file)
+	return None, pyPrint(f, args, sep, end, file)
 }
 
 func builtinRange(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -578,6 +801,11 @@ func builtinRange(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException)
 	if raised != nil {
 		return nil, raised
 	}
+	xr := toXRangeUnsafe(r)
+	_, n, _ := seqRange(xr.start, xr.stop, xr.step)
+	if raised := f.checkAlloc(n); raised != nil {
+		return nil, raised
+	}
 	return ListType.Call(f, []*Object{r}, nil)
 }
 
@@ -587,29 +815,67 @@ func builtinRawInput(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseExceptio
 		return nil, f.RaiseType(TypeErrorType, msg)
 	}
 
-	if Stdin == nil {
-		msg := fmt.Sprintf("[raw_]input: lost sys.stdin")
-		return nil, f.RaiseType(RuntimeErrorType, msg)
-	}
-
-	if Stdout == nil {
-		msg := fmt.Sprintf("[raw_]input: lost sys.stdout")
-		return nil, f.RaiseType(RuntimeErrorType, msg)
+	stdin, raised := sysStream(f, "stdin")
+	if raised != nil {
+		return nil, raised
 	}
-
 	if len(args) == 1 {
-		err := pyPrint(f, args, "", "", Stdout)
-		if err != nil {
-			return nil, err
+		stdout, raised := sysStream(f, "stdout")
+		if raised != nil {
+			return nil, raised
+		}
+		if raised := pyPrint(f, args, "", "", stdout); raised != nil {
+			return nil, raised
 		}
 	}
-
-	line, err := Stdin.reader.ReadString('\n')
-	if err != nil {
+	readline, raised := GetAttr(f, stdin, NewStr("readline"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	o, raised := readline.Call(f, nil, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	s, raised := ToStr(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	line := s.Value()
+	if line == "" {
 		return nil, f.RaiseType(EOFErrorType, "EOF when reading a line")
 	}
-	line = strings.TrimRight(line, "\n")
-	return NewStr(line).ToObject(), nil
+	return NewStr(strings.TrimSuffix(line, "\n")).ToObject(), nil
+}
+
+func builtinReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	argc := len(args)
+	expectedTypes := []*Type{ObjectType, ObjectType, ObjectType}
+	if argc == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkFunctionArgs(f, "reduce", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	fn := args[0]
+	var result *Object
+	if argc > 2 {
+		result = args[2]
+	}
+	raised := seqForEach(f, args[1], func(o *Object) (raised *BaseException) {
+		if result == nil {
+			result = o
+		} else {
+			result, raised = fn.Call(f, Args{result, o}, nil)
+		}
+		return raised
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	if result == nil {
+		return nil, f.RaiseType(TypeErrorType, "reduce() of empty sequence with no initial value")
+	}
+	return result, nil
 }
 
 func builtinRepr(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -663,22 +929,32 @@ func builtinRound(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 }
 
 func builtinSetAttr(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	if raised := checkFunctionArgs(f, "setattr", args, ObjectType, StrType, ObjectType); raised != nil {
+	if raised := checkFunctionArgs(f, "setattr", args, ObjectType, BaseStringType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	name, raised := basestringToStr(f, args[1])
+	if raised != nil {
 		return nil, raised
 	}
-	return None, SetAttr(f, args[0], toStrUnsafe(args[1]), args[2])
+	return None, SetAttr(f, args[0], name, args[2])
 }
 
-func builtinSorted(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	// TODO: Support (cmp=None, key=None, reverse=False)
-	if raised := checkFunctionArgs(f, "sorted", args, ObjectType); raised != nil {
+func builtinSorted(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionVarArgs(f, "sorted", args, ObjectType); raised != nil {
 		return nil, raised
 	}
 	result, raised := ListType.Call(f, Args{args[0]}, nil)
 	if raised != nil {
 		return nil, raised
 	}
-	toListUnsafe(result).Sort(f)
+	sortArgs := f.MakeArgs(len(args))
+	sortArgs[0] = result
+	copy(sortArgs[1:], args[1:])
+	_, raised = listSort(f, sortArgs, kwargs)
+	f.FreeArgs(sortArgs)
+	if raised != nil {
+		return nil, raised
+	}
 	return result, nil
 }
 
@@ -712,16 +988,37 @@ func builtinSum(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 }
 
 func builtinUniChr(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	if raised := checkFunctionArgs(f, "unichr", args, IntType); raised != nil {
+	if raised := checkFunctionArgs(f, "unichr", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	i, raised := ToIntValue(f, args[0])
+	if raised != nil {
 		return nil, raised
 	}
-	i := toIntUnsafe(args[0]).Value()
 	if i < 0 || i > unicode.MaxRune {
 		return nil, f.RaiseType(ValueErrorType, fmt.Sprintf("unichr() arg not in range(0x%x)", unicode.MaxRune))
 	}
 	return NewUnicodeFromRunes([]rune{rune(i)}).ToObject(), nil
 }
 
+func builtinVars(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if len(args) == 0 {
+		return f.Locals().ToObject(), nil
+	}
+	if raised := checkFunctionArgs(f, "vars", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	d, raised := GetAttr(f, args[0], NewStr("__dict__"), nil)
+	if raised != nil {
+		if !raised.isInstance(AttributeErrorType) {
+			return nil, raised
+		}
+		f.RestoreExc(nil, nil)
+		return nil, f.RaiseType(TypeErrorType, "vars() argument must have __dict__ attribute")
+	}
+	return d, nil
+}
+
 func builtinZip(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	argc := len(args)
 	if argc == 0 {
@@ -754,30 +1051,36 @@ Outer:
 
 func init() {
 	builtinMap := map[string]*Object{
-		"__debug__":      False.ToObject(),
+		"__debug__":      GetBool(Debug()).ToObject(),
 		"__frame__":      newBuiltinFunction("__frame__", builtinFrame).ToObject(),
 		"abs":            newBuiltinFunction("abs", builtinAbs).ToObject(),
 		"all":            newBuiltinFunction("all", builtinAll).ToObject(),
 		"any":            newBuiltinFunction("any", builtinAny).ToObject(),
 		"bin":            newBuiltinFunction("bin", builtinBin).ToObject(),
+		"bytes":          StrType.ToObject(),
 		"callable":       newBuiltinFunction("callable", builtinCallable).ToObject(),
 		"chr":            newBuiltinFunction("chr", builtinChr).ToObject(),
 		"cmp":            newBuiltinFunction("cmp", builtinCmp).ToObject(),
+		"compile":        newBuiltinFunction("compile", builtinCompile).ToObject(),
 		"delattr":        newBuiltinFunction("delattr", builtinDelAttr).ToObject(),
 		"dir":            newBuiltinFunction("dir", builtinDir).ToObject(),
 		"divmod":         newBuiltinFunction("divmod", builtinDivMod).ToObject(),
 		"Ellipsis":       Ellipsis,
+		"eval":           newBuiltinFunction("eval", builtinEval).ToObject(),
 		"False":          False.ToObject(),
+		"filter":         newBuiltinFunction("filter", builtinFilter).ToObject(),
 		"getattr":        newBuiltinFunction("getattr", builtinGetAttr).ToObject(),
 		"globals":        newBuiltinFunction("globals", builtinGlobals).ToObject(),
 		"hasattr":        newBuiltinFunction("hasattr", builtinHasAttr).ToObject(),
 		"hash":           newBuiltinFunction("hash", builtinHash).ToObject(),
 		"hex":            newBuiltinFunction("hex", builtinHex).ToObject(),
 		"id":             newBuiltinFunction("id", builtinID).ToObject(),
+		"intern":         newBuiltinFunction("intern", builtinIntern).ToObject(),
 		"isinstance":     newBuiltinFunction("isinstance", builtinIsInstance).ToObject(),
 		"issubclass":     newBuiltinFunction("issubclass", builtinIsSubclass).ToObject(),
 		"iter":           newBuiltinFunction("iter", builtinIter).ToObject(),
 		"len":            newBuiltinFunction("len", builtinLen).ToObject(),
+		"locals":         newBuiltinFunction("locals", builtinLocals).ToObject(),
 		"map":            newBuiltinFunction("map", builtinMapFn).ToObject(),
 		"max":            newBuiltinFunction("max", builtinMax).ToObject(),
 		"min":            newBuiltinFunction("min", builtinMin).ToObject(),
@@ -787,9 +1090,11 @@ func init() {
 		"oct":            newBuiltinFunction("oct", builtinOct).ToObject(),
 		"open":           newBuiltinFunction("open", builtinOpen).ToObject(),
 		"ord":            newBuiltinFunction("ord", builtinOrd).ToObject(),
+		"pow":            newBuiltinFunction("pow", builtinPow).ToObject(),
 		"print":          newBuiltinFunction("print", builtinPrint).ToObject(),
 		"range":          newBuiltinFunction("range", builtinRange).ToObject(),
 		"raw_input":      newBuiltinFunction("raw_input", builtinRawInput).ToObject(),
+		"reduce":         newBuiltinFunction("reduce", builtinReduce).ToObject(),
 		"repr":           newBuiltinFunction("repr", builtinRepr).ToObject(),
 		"round":          newBuiltinFunction("round", builtinRound).ToObject(),
 		"setattr":        newBuiltinFunction("setattr", builtinSetAttr).ToObject(),
@@ -797,6 +1102,7 @@ func init() {
 		"sum":            newBuiltinFunction("sum", builtinSum).ToObject(),
 		"True":           True.ToObject(),
 		"unichr":         newBuiltinFunction("unichr", builtinUniChr).ToObject(),
+		"vars":           newBuiltinFunction("vars", builtinVars).ToObject(),
 		"zip":            newBuiltinFunction("zip", builtinZip).ToObject(),
 	}
 	// Do type initialization in two phases so that we don't have to think
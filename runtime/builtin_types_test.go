@@ -15,11 +15,9 @@
 package grumpy
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"math/big"
-	"os"
+	"strings"
 	"testing"
 )
 
@@ -56,6 +54,7 @@ func TestBuiltinFuncs(t *testing.T) {
 	objectDir := ObjectType.Dict().Keys(f)
 	objectDir.Sort(f)
 	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"bar": None}))
+	addFunc := wrapFuncForTest(Add)
 	fooTypeDir := NewList(objectDir.elems...)
 	fooTypeDir.Append(NewStr("bar").ToObject())
 	fooTypeDir.Sort(f)
@@ -74,6 +73,14 @@ func TestBuiltinFuncs(t *testing.T) {
 	}
 	iter := mustNotRaise(Iter(f, mustNotRaise(xrangeType.Call(f, wrapArgs(5), nil))))
 	neg := wrapFuncForTest(func(f *Frame, i int) int { return -i })
+	isOdd := wrapFuncForTest(func(f *Frame, i int) bool { return i%2 != 0 })
+	notB := wrapFuncForTest(func(f *Frame, s string) bool { return s != "b" })
+	newTestIMap := func(args ...interface{}) *Object {
+		return mustNotRaise(IMapType.Call(f, wrapArgs(args...), nil))
+	}
+	newTestIFilter := func(args ...interface{}) *Object {
+		return mustNotRaise(IFilterType.Call(f, wrapArgs(args...), nil))
+	}
 	raiseKey := wrapFuncForTest(func(f *Frame, o *Object) *BaseException { return f.RaiseType(RuntimeErrorType, "foo") })
 	hexOctType := newTestClass("HexOct", []*Type{ObjectType}, newStringDict(map[string]*Object{
 		"__hex__": newBuiltinFunction("__hex__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
@@ -103,6 +110,16 @@ func TestBuiltinFuncs(t *testing.T) {
 			return NewInt(1).ToObject(), nil
 		}).ToObject(),
 	}))
+	dirType := newTestClass("Dir", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__dir__": newBuiltinFunction("__dir__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return newTestList("b", "a").ToObject(), nil
+		}).ToObject(),
+	}))
+	badDirType := newTestClass("BadDir", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__dir__": newBuiltinFunction("__dir__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return newTestTuple("a").ToObject(), nil
+		}).ToObject(),
+	}))
 	fooBuiltinFunc := newBuiltinFunction("foo", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 		return newTestTuple(NewTuple(args.makeCopy()...), kwargs.makeDict()).ToObject(), nil
 	}).ToObject()
@@ -160,6 +177,8 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "callable", args: wrapArgs(1, 2), wantExc: mustCreateException(TypeErrorType, "'callable' requires 1 arguments")},
 		{f: "chr", args: wrapArgs(0), want: NewStr("\x00").ToObject()},
 		{f: "chr", args: wrapArgs(65), want: NewStr("A").ToObject()},
+		{f: "chr", args: wrapArgs(big.NewInt(66)), want: NewStr("B").ToObject()},
+		{f: "chr", args: wrapArgs("A"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{f: "chr", args: wrapArgs(300), wantExc: mustCreateException(ValueErrorType, "chr() arg not in range(256)")},
 		{f: "chr", args: wrapArgs(-1), wantExc: mustCreateException(ValueErrorType, "chr() arg not in range(256)")},
 		{f: "chr", args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "'chr' requires 1 arguments")},
@@ -168,7 +187,9 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "dir", args: wrapArgs(fooType), want: fooTypeDir.ToObject()},
 		{f: "dir", args: wrapArgs(foo), want: fooDir.ToObject()},
 		{f: "dir", args: wrapArgs(dirModule), want: dirModuleDir.ToObject()},
-		{f: "dir", args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "'dir' requires 1 arguments")},
+		{f: "dir", args: wrapArgs(newObject(dirType)), want: newTestList("a", "b").ToObject()},
+		{f: "dir", args: wrapArgs(newObject(badDirType)), wantExc: mustCreateException(TypeErrorType, "__dir__() must return a list, not tuple")},
+		{f: "dir", args: wrapArgs(foo, 1), wantExc: mustCreateException(TypeErrorType, "'dir' requires 1 arguments")},
 		{f: "divmod", args: wrapArgs(12, 7), want: NewTuple2(NewInt(1).ToObject(), NewInt(5).ToObject()).ToObject()},
 		{f: "divmod", args: wrapArgs(-12, 7), want: NewTuple2(NewInt(-2).ToObject(), NewInt(2).ToObject()).ToObject()},
 		{f: "divmod", args: wrapArgs(12, -7), want: NewTuple2(NewInt(-2).ToObject(), NewInt(-2).ToObject()).ToObject()},
@@ -186,10 +207,19 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "divmod", args: wrapArgs(-3.25, -1.0), want: NewTuple2(NewFloat(3.0).ToObject(), NewFloat(-0.25).ToObject()).ToObject()},
 		{f: "divmod", args: wrapArgs(NewStr("a"), NewStr("b")), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for divmod(): 'str' and 'str'")},
 		{f: "divmod", args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "'divmod' requires 2 arguments")},
+		{f: "filter", args: wrapArgs(None, newTestList(0, 1, 2)), want: newTestList(1, 2).ToObject()},
+		{f: "filter", args: wrapArgs(isOdd, newTestTuple(1, 2, 3)), want: newTestTuple(1, 3).ToObject()},
+		{f: "filter", args: wrapArgs(notB, "abc"), want: NewStr("ac").ToObject()},
+		{f: "filter", args: wrapArgs(notB, NewUnicode("abc")), want: NewUnicode("ac").ToObject()},
+		{f: "filter", args: wrapArgs(isOdd, newTestIMap(neg, newTestList(1, 2, 3))), want: newTestList(-1, -3).ToObject()},
+		{f: "filter", args: wrapArgs(None, None), wantExc: mustCreateException(TypeErrorType, "'NoneType' object is not iterable")},
+		{f: "filter", args: wrapArgs(None), wantExc: mustCreateException(TypeErrorType, "'filter' requires 2 arguments")},
 		{f: "getattr", args: wrapArgs(None, NewStr("foo").ToObject(), NewStr("bar").ToObject()), want: NewStr("bar").ToObject()},
+		{f: "getattr", args: wrapArgs(None, NewUnicode("foo").ToObject(), NewStr("bar").ToObject()), want: NewStr("bar").ToObject()},
 		{f: "getattr", args: wrapArgs(None, NewStr("foo").ToObject()), wantExc: mustCreateException(AttributeErrorType, "'NoneType' object has no attribute 'foo'")},
 		{f: "hasattr", args: wrapArgs(newObject(ObjectType), NewStr("foo").ToObject()), want: False.ToObject()},
 		{f: "hasattr", args: wrapArgs(foo, NewStr("bar").ToObject()), want: True.ToObject()},
+		{f: "hasattr", args: wrapArgs(foo, NewUnicode("bar").ToObject()), want: True.ToObject()},
 		{f: "hasattr", args: wrapArgs(foo, NewStr("baz").ToObject()), want: True.ToObject()},
 		{f: "hasattr", args: wrapArgs(foo, NewStr("qux").ToObject()), want: False.ToObject()},
 		{f: "hash", args: wrapArgs(123), want: NewInt(123).ToObject()},
@@ -229,6 +259,8 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "map", args: wrapArgs(StrType, newTestList(), None), wantExc: mustCreateException(TypeErrorType, "'NoneType' object is not iterable")},
 		{f: "map", args: wrapArgs(newTestList(), newTestList(1, 2, 3)), wantExc: mustCreateException(TypeErrorType, "'list' object is not callable")},
 		{f: "map", args: wrapArgs(StrType, newTestList()), want: newTestList().ToObject()},
+		{f: "map", args: wrapArgs(neg, newTestIMap(neg, newTestList(1, 2))), want: newTestList(1, 2).ToObject()},
+		{f: "map", args: wrapArgs(None, newTestIFilter(isOdd, newTestList(1, 2, 3))), want: newTestList(1, 3).ToObject()},
 		{f: "map", args: wrapArgs(StrType, newTestList(1, 2, 3)), want: newTestList("1", "2", "3").ToObject()},
 		{f: "map", args: wrapArgs(StrType, newTestList(-1, -2, -3)), want: newTestList("-1", "-2", "-3").ToObject()},
 		{f: "map", args: wrapArgs(IntType, newTestList("1", "2", "3")), want: newTestList(1, 2, 3).ToObject()},
@@ -294,10 +326,30 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "ord", args: wrapArgs("foo"), wantExc: mustCreateException(ValueErrorType, "ord() expected a character, but string of length 3 found")},
 		{f: "ord", args: wrapArgs(NewUnicode("волн")), wantExc: mustCreateException(ValueErrorType, "ord() expected a character, but string of length 4 found")},
 		{f: "ord", args: wrapArgs(1, 2, 3), wantExc: mustCreateException(TypeErrorType, "'ord' requires 1 arguments")},
+		{f: "pow", args: wrapArgs(2, 10), want: NewInt(1024).ToObject()},
+		{f: "pow", args: wrapArgs(2, 10, None), want: NewInt(1024).ToObject()},
+		{f: "pow", args: wrapArgs(2, 10, 1000), want: NewInt(24).ToObject()},
+		{f: "pow", args: wrapArgs(-2, 3, 5), want: NewInt(2).ToObject()},
+		{f: "pow", args: wrapArgs(-2, 3, -5), want: NewInt(-3).ToObject()},
+		{f: "pow", args: wrapArgs(2, 3, -5), want: NewInt(-2).ToObject()},
+		{f: "pow", args: wrapArgs(5, 0, 1), want: NewInt(0).ToObject()},
+		{f: "pow", args: wrapArgs(big.NewInt(3), 200, 7), want: NewLong(big.NewInt(2)).ToObject()},
+		{f: "pow", args: wrapArgs(3, new(big.Int).Lsh(big.NewInt(1), 100), big.NewInt(-7)), want: NewLong(big.NewInt(-3)).ToObject()},
+		{f: "pow", args: wrapArgs(2, -1, 5), wantExc: mustCreateException(TypeErrorType, "pow() 2nd argument cannot be negative when 3rd argument specified")},
+		{f: "pow", args: wrapArgs(2, 3, 0), wantExc: mustCreateException(ValueErrorType, "pow() 3rd argument cannot be 0")},
+		{f: "pow", args: wrapArgs(2.0, 3, 5), wantExc: mustCreateException(TypeErrorType, "pow() 3rd argument not allowed unless all arguments are integers")},
+		{f: "pow", args: wrapArgs(2, 3, "foo"), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for pow(): 'int', 'int', 'str'")},
+		{f: "pow", args: wrapArgs(2), wantExc: mustCreateException(TypeErrorType, "'pow' requires 3 arguments")},
 		{f: "range", args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "'__new__' of 'int' requires 3 arguments")},
 		{f: "range", args: wrapArgs(3), want: newTestList(0, 1, 2).ToObject()},
 		{f: "range", args: wrapArgs(10, 0), want: NewList().ToObject()},
 		{f: "range", args: wrapArgs(-12, -23, -5), want: newTestList(-12, -17, -22).ToObject()},
+		{f: "reduce", args: wrapArgs(addFunc, newTestList(1, 2, 3)), want: NewInt(6).ToObject()},
+		{f: "reduce", args: wrapArgs(addFunc, "abc", "x"), want: NewStr("xabc").ToObject()},
+		{f: "reduce", args: wrapArgs(addFunc, NewTuple(), 5), want: NewInt(5).ToObject()},
+		{f: "reduce", args: wrapArgs(addFunc, NewTuple()), wantExc: mustCreateException(TypeErrorType, "reduce() of empty sequence with no initial value")},
+		{f: "reduce", args: wrapArgs(addFunc, 123), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+		{f: "reduce", args: wrapArgs(addFunc), wantExc: mustCreateException(TypeErrorType, "'reduce' requires 3 arguments")},
 		{f: "repr", args: wrapArgs(123), want: NewStr("123").ToObject()},
 		{f: "repr", args: wrapArgs(NewUnicode("abc")), want: NewStr("u'abc'").ToObject()},
 		{f: "repr", args: wrapArgs(newTestTuple("foo", "bar")), want: NewStr("('foo', 'bar')").ToObject()},
@@ -327,7 +379,11 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "sorted", args: wrapArgs(newTestTuple(1, 2, 0, 3)), want: newTestRange(4).ToObject()},
 		{f: "sorted", args: wrapArgs(newTestDict("foo", 1, "bar", 2)), want: newTestList("bar", "foo").ToObject()},
 		{f: "sorted", args: wrapArgs(1), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
-		{f: "sorted", args: wrapArgs(newTestList("foo", "bar"), 2), wantExc: mustCreateException(TypeErrorType, "'sorted' requires 1 arguments")},
+		{f: "sorted", args: wrapArgs(newTestList("foo", "bar"), 2), wantExc: mustCreateException(TypeErrorType, "'int' object is not callable")},
+		{f: "sorted", args: wrapArgs(newTestList(3, 1, 2)), kwargs: wrapKWArgs("reverse", true), want: newTestList(3, 2, 1).ToObject()},
+		{f: "sorted", args: wrapArgs(newTestList(1, 3, 2)), kwargs: wrapKWArgs("key", neg), want: newTestList(3, 2, 1).ToObject()},
+		{f: "sorted", args: wrapArgs(newTestList(1, 2)), kwargs: wrapKWArgs("key", raiseKey), wantExc: mustCreateException(RuntimeErrorType, "foo")},
+		{f: "sorted", wantExc: mustCreateException(TypeErrorType, "'sorted' requires 1 arguments")},
 		{f: "sum", args: wrapArgs(newTestList(1, 2, 3, 4)), want: NewInt(10).ToObject()},
 		{f: "sum", args: wrapArgs(newTestList(1, 2), 3), want: NewFloat(6).ToObject()},
 		{f: "sum", args: wrapArgs(newTestList(2, 1.1)), want: NewFloat(3.1).ToObject()},
@@ -337,9 +393,13 @@ func TestBuiltinFuncs(t *testing.T) {
 		{f: "sum", args: wrapArgs(newTestList(newObject(addType)), newObject(addType)), want: NewInt(1).ToObject()},
 		{f: "unichr", args: wrapArgs(0), want: NewUnicode("\x00").ToObject()},
 		{f: "unichr", args: wrapArgs(65), want: NewStr("A").ToObject()},
+		{f: "unichr", args: wrapArgs(big.NewInt(66)), want: NewUnicode("B").ToObject()},
 		{f: "unichr", args: wrapArgs(0x120000), wantExc: mustCreateException(ValueErrorType, "unichr() arg not in range(0x10ffff)")},
 		{f: "unichr", args: wrapArgs(-1), wantExc: mustCreateException(ValueErrorType, "unichr() arg not in range(0x10ffff)")},
 		{f: "unichr", args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "'unichr' requires 1 arguments")},
+		{f: "vars", args: wrapArgs(foo), want: newTestDict("baz", None).ToObject()},
+		{f: "vars", args: wrapArgs(1), wantExc: mustCreateException(TypeErrorType, "vars() argument must have __dict__ attribute")},
+		{f: "vars", args: wrapArgs(foo, 2), wantExc: mustCreateException(TypeErrorType, "'vars' requires 1 arguments")},
 		{f: "zip", args: wrapArgs(), want: newTestList().ToObject()},
 		{f: "zip", args: wrapArgs(newTestTuple()), want: newTestList().ToObject()},
 		{f: "zip", args: wrapArgs(newTestList()), want: newTestList().ToObject()},
@@ -378,6 +438,85 @@ func TestBuiltinGlobals(t *testing.T) {
 	}
 }
 
+func TestBuiltinLocals(t *testing.T) {
+	f := NewRootFrame()
+	f.globals = newTestDict("foo", 1)
+	locals := mustNotRaise(Builtins.GetItemString(f, "locals"))
+	vars := mustNotRaise(Builtins.GetItemString(f, "vars"))
+	for _, fn := range []*Object{locals, vars} {
+		if got := mustNotRaise(fn.Call(f, nil, nil)); got != f.globals.ToObject() {
+			t.Errorf("%v() at module level = %v, want %v", fn, got, f.globals)
+		}
+	}
+	dir := mustNotRaise(Builtins.GetItemString(f, "dir"))
+	got, raised := dir.Call(f, nil, nil)
+	if want := newTestList("foo").ToObject(); checkResult(got, want, raised, nil) != checkInvokeResultOk {
+		t.Errorf("dir() at module level = %v, %v, want %v", got, raised, want)
+	}
+	f.SetLocals(func() *Dict {
+		return NewLocalsDict([]string{"a", "b"}, []*Object{NewInt(2).ToObject(), UnboundLocal})
+	})
+	want := newTestDict("a", 2).ToObject()
+	for _, fn := range []*Object{locals, vars} {
+		got, raised := fn.Call(f, nil, nil)
+		switch checkResult(got, want, raised, nil) {
+		case checkInvokeResultExceptionMismatch:
+			t.Errorf("%v() raised %v, want nil", fn, raised)
+		case checkInvokeResultReturnValueMismatch:
+			t.Errorf("%v() = %v, want %v", fn, got, want)
+		}
+	}
+}
+
+func TestBuiltinCompile(t *testing.T) {
+	f := NewRootFrame()
+	compile := mustNotRaise(Builtins.GetItemString(f, "compile"))
+	cases := []struct {
+		args    Args
+		wantExc *BaseException
+	}{
+		{wrapArgs("x", "foo.py", "eval"), nil},
+		{wrapArgs("x = 1", "foo.py", "exec", 0, 1), nil},
+		{wrapArgs("x", 1, "eval"), mustCreateException(TypeErrorType, `'compile' requires a 'str' object but received a "int"`)},
+		{wrapArgs("x", "foo.py", "exec", compileOnlyAST), mustCreateException(NotImplementedErrorType, "compile() cannot produce an AST")},
+		{wrapArgs("x", "foo.py"), mustCreateException(TypeErrorType, "'compile' requires 5 arguments")},
+	}
+	for _, cas := range cases {
+		got, raised := compile.Call(f, cas.args, nil)
+		if !exceptionsAreEquivalent(raised, cas.wantExc) {
+			t.Errorf("compile%v raised %v, want %v", cas.args, raised, cas.wantExc)
+		} else if raised == nil && !got.isInstance(CodeType) {
+			t.Errorf("compile%v = %v, want code object", cas.args, got)
+		}
+	}
+}
+
+func TestBuiltinEval(t *testing.T) {
+	f := NewRootFrame()
+	f.globals = newTestDict("x", 3)
+	eval := mustNotRaise(Builtins.GetItemString(f, "eval"))
+	cases := []struct {
+		args    Args
+		want    *Object
+		wantExc *BaseException
+	}{
+		{wrapArgs("x * 2"), NewInt(6).ToObject(), nil},
+		{wrapArgs("x * 2", newTestDict("x", 4)), NewInt(8).ToObject(), nil},
+		{wrapArgs("x * 2", None, newTestDict("x", 5)), NewInt(10).ToObject(), nil},
+		{wrapArgs("x", 1), nil, mustCreateException(TypeErrorType, "globals must be a dict")},
+		{nil, nil, mustCreateException(TypeErrorType, "'eval' requires 3 arguments")},
+	}
+	for _, cas := range cases {
+		got, raised := eval.Call(f, cas.args, nil)
+		switch checkResult(got, cas.want, raised, cas.wantExc) {
+		case checkInvokeResultExceptionMismatch:
+			t.Errorf("eval%v raised %v, want %v", cas.args, raised, cas.wantExc)
+		case checkInvokeResultReturnValueMismatch:
+			t.Errorf("eval%v = %v, want %v", cas.args, got, cas.want)
+		}
+	}
+}
+
 func TestEllipsisRepr(t *testing.T) {
 	cas := invokeTestCase{args: wrapArgs(Ellipsis), want: NewStr("Ellipsis").ToObject()}
 	if err := runInvokeMethodTestCase(EllipsisType, "__repr__", &cas); err != "" {
@@ -402,35 +541,14 @@ func TestNotImplementedRepr(t *testing.T) {
 // captureStdout invokes a function closure which writes to stdout and captures
 // its output as string.
 func captureStdout(f *Frame, fn func() *BaseException) (string, *BaseException) {
-	r, w, err := os.Pipe()
-	if err != nil {
-		return "", f.RaiseType(RuntimeErrorType, fmt.Sprintf("failed to open pipe: %v", err))
-	}
-	oldStdout := Stdout
-	Stdout = NewFileFromFD(w.Fd(), nil)
-	defer func() {
-		Stdout = oldStdout
-	}()
-	done := make(chan struct{})
-	var raised *BaseException
-	go func() {
-		defer close(done)
-		defer w.Close()
-		raised = fn()
-	}()
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, r); err != nil {
-		return "", f.RaiseType(RuntimeErrorType, fmt.Sprintf("failed to copy buffer: %v", err))
-	}
-	<-done
+	output, raised := CaptureStdout(fn)
 	if raised != nil {
 		return "", raised
 	}
-	return buf.String(), nil
+	return output, nil
 }
 
-// TODO(corona10): Re-enable once #282 is addressed.
-/*func TestBuiltinPrint(t *testing.T) {
+func TestBuiltinPrint(t *testing.T) {
 	fun := wrapFuncForTest(func(f *Frame, args *Tuple, kwargs KWArgs) (string, *BaseException) {
 		return captureStdout(f, func() *BaseException {
 			_, raised := builtinPrint(NewRootFrame(), args.elems, kwargs)
@@ -450,7 +568,38 @@ func captureStdout(f *Frame, fn func() *BaseException) (string, *BaseException)
 			t.Error(err)
 		}
 	}
-}*/
+}
+
+func TestBuiltinPrintFile(t *testing.T) {
+	print := mustNotRaise(Builtins.GetItemString(NewRootFrame(), "print"))
+	writes := NewList()
+	writerType := newTestClass("Writer", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"write": newBuiltinFunction("write", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+			writes.Append(args[1])
+			return None, nil
+		}).ToObject(),
+	}))
+	writer := newObject(writerType)
+	fun := wrapFuncForTest(func(f *Frame, args *Tuple, kwargs KWArgs) (*Object, *BaseException) {
+		writes = NewList()
+		if _, raised := print.Call(f, args.elems, kwargs); raised != nil {
+			return nil, raised
+		}
+		return TupleType.Call(f, Args{writes.ToObject()}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewTuple(), wrapKWArgs("file", writer)), want: newTestTuple("\n").ToObject()},
+		{args: wrapArgs(newTestTuple("abc", 123), wrapKWArgs("file", writer)), want: newTestTuple("abc", " ", "123", "\n").ToObject()},
+		{args: wrapArgs(newTestTuple("abc", 123), wrapKWArgs("sep", "", "end", "", "file", writer)), want: newTestTuple("abc", "", "123", "").ToObject()},
+		{args: wrapArgs(newTestTuple("abc"), wrapKWArgs("file", 42)), wantExc: mustCreateException(AttributeErrorType, "'int' object has no attribute 'write'")},
+		{args: wrapArgs(newTestTuple("abc"), wrapKWArgs("foo", 42)), wantExc: mustCreateException(TypeErrorType, "'foo' is an invalid keyword argument for this function")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
 
 func TestBuiltinSetAttr(t *testing.T) {
 	setattr := mustNotRaise(Builtins.GetItemString(NewRootFrame(), "setattr"))
@@ -473,7 +622,8 @@ func TestBuiltinSetAttr(t *testing.T) {
 		{args: wrapArgs(newObject(fooType), "foo", 123), want: newTestTuple(None, 123).ToObject()},
 		{args: wrapArgs(foo, "foo"), wantExc: mustCreateException(TypeErrorType, "'setattr' requires 3 arguments")},
 		{args: wrapArgs(foo, "foo", 123, None), wantExc: mustCreateException(TypeErrorType, "'setattr' requires 3 arguments")},
-		{args: wrapArgs(foo, 123, 123), wantExc: mustCreateException(TypeErrorType, "'setattr' requires a 'str' object but received a \"int\"")},
+		{args: wrapArgs(newObject(fooType), NewUnicode("foo"), 123), want: newTestTuple(None, 123).ToObject()},
+		{args: wrapArgs(foo, 123, 123), wantExc: mustCreateException(TypeErrorType, "'setattr' requires a 'basestring' object but received a \"int\"")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -482,26 +632,10 @@ func TestBuiltinSetAttr(t *testing.T) {
 	}
 }
 
-// TODO(corona10): Re-enable once #282 is addressed.
-/*func TestRawInput(t *testing.T) {
+func TestRawInput(t *testing.T) {
 	fun := wrapFuncForTest(func(f *Frame, s string, args ...*Object) (*Object, *BaseException) {
-		// Create a fake Stdin for input test.
-		stdinFile, w, err := os.Pipe()
-		if err != nil {
-			return nil, f.RaiseType(RuntimeErrorType, fmt.Sprintf("failed to open pipe: %v", err))
-		}
-
-		go func() {
-			w.Write([]byte(s))
-			w.Close()
-		}()
-
-		oldStdin := Stdin
-		Stdin = NewFileFromFD(stdinFile.Fd(), nil)
-		defer func() {
-			Stdin = oldStdin
-			stdinFile.Close()
-		}()
+		// Feed s to raw_input through a fake Stdin.
+		defer Stdin.Redirect(strings.NewReader(s), nil)()
 
 		var input *Object
 		output, raised := captureStdout(f, func() *BaseException {
@@ -535,7 +669,7 @@ func TestBuiltinSetAttr(t *testing.T) {
 		}
 	}
 
-}*/
+}
 
 func newTestIndexObject(index int) *Object {
 	indexType := newTestClass("Index", []*Type{ObjectType}, newStringDict(map[string]*Object{
@@ -58,7 +58,7 @@ func byteArrayGE(f *Frame, v, w *Object) (*Object, *BaseException) {
 func byteArrayGetItem(f *Frame, o, key *Object) (result *Object, raised *BaseException) {
 	a := toByteArrayUnsafe(o)
 	if key.typ.slots.Index != nil {
-		index, raised := IndexInt(f, key)
+		index, raised := indexIntChecked(f, key, IndexErrorType)
 		if raised != nil {
 			return nil, raised
 		}
@@ -193,3 +193,21 @@ func byteArrayCompare(v, w *Object, ltResult, eqResult, gtResult *Int) *Object {
 		return gtResult.ToObject()
 	}
 }
+
+// bufferData returns a copy of the bytes held by o, which must be a str,
+// unicode or bytearray. Unicode objects are encoded with the default
+// encoding. format is used to describe the error for other types.
+func bufferData(f *Frame, o *Object, format string) ([]byte, *BaseException) {
+	if o.isInstance(ByteArrayType) {
+		a := toByteArrayUnsafe(o)
+		a.mutex.RLock()
+		data := append([]byte(nil), a.value...)
+		a.mutex.RUnlock()
+		return data, nil
+	}
+	s, raised := stringIOData(f, o, format)
+	if raised != nil {
+		return nil, raised
+	}
+	return []byte(s), nil
+}
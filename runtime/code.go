@@ -41,18 +41,68 @@ type Code struct {
 	flags     CodeFlag `attr:"co_flags"`
 	paramSpec *ParamSpec
 	fn        func(*Frame, []*Object) (*Object, *BaseException)
+	// program is the parsed source of code objects created by Compile.
+	program *evalProgram
+	// firstLineno is the line in filename on which the definition begins
+	// or zero if unknown.
+	firstLineno int `attr:"co_firstlineno"`
+	// varArg and kwArg are the names of the *args and **kwargs parameters.
+	varArg string
+	kwArg  string
+	// localNames are the names of the local variables that are not
+	// parameters.
+	localNames []string
 }
 
 // NewCode creates a new Code object that executes the given fn.
 func NewCode(name, filename string, params []Param, flags CodeFlag, fn func(*Frame, []*Object) (*Object, *BaseException)) *Code {
 	s := NewParamSpec(name, params, flags&CodeFlagVarArg != 0, flags&CodeFlagKWArg != 0)
-	return &Code{Object{typ: CodeType}, name, filename, len(params), flags, s, fn}
+	c := &Code{Object: Object{typ: CodeType}, name: name, filename: filename, argc: len(params), flags: flags, paramSpec: s, fn: fn}
+	if flags&CodeFlagVarArg != 0 {
+		c.varArg = "args"
+	}
+	if flags&CodeFlagKWArg != 0 {
+		c.kwArg = "kwargs"
+	}
+	return c
 }
 
 func toCodeUnsafe(o *Object) *Code {
 	return (*Code)(o.toPointer())
 }
 
+// ToObject upcasts c to an Object.
+func (c *Code) ToObject() *Object {
+	return &c.Object
+}
+
+// WithArgNames sets the names of c's *args and **kwargs parameters as reported
+// by co_varnames and returns c. An empty name leaves the corresponding name
+// unchanged.
+func (c *Code) WithArgNames(varArg, kwArg string) *Code {
+	if varArg != "" {
+		c.varArg = varArg
+	}
+	if kwArg != "" {
+		c.kwArg = kwArg
+	}
+	return c
+}
+
+// WithLocalNames sets the names of c's local variables other than its
+// parameters, which co_varnames lists after the parameters, and returns c.
+func (c *Code) WithLocalNames(names ...string) *Code {
+	c.localNames = names
+	return c
+}
+
+// WithLineno sets the line in c's file on which its definition begins, as
+// reported by co_firstlineno, and returns c.
+func (c *Code) WithLineno(lineno int) *Code {
+	c.firstLineno = lineno
+	return c
+}
+
 // Eval runs the code object c in the context of the given globals.
 func (c *Code) Eval(f *Frame, globals *Dict, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	validated := f.MakeArgs(c.paramSpec.Count)
@@ -63,7 +113,24 @@ func (c *Code) Eval(f *Frame, globals *Dict, args Args, kwargs KWArgs) (*Object,
 	next := newChildFrame(f)
 	next.code = c
 	next.globals = globals
-	ret, raised := c.fn(next, validated)
+	var ret *Object
+	var raised *BaseException
+	if trace := f.threadState.traceFunc; trace != nil {
+		next.lineno = c.firstLineno
+		raised = next.traceEvent(trace, "call", None)
+	}
+	if raised == nil {
+		ret, raised = c.fn(next, validated)
+		if next.trace != nil {
+			arg := ret
+			if arg == nil {
+				arg = None
+			}
+			if traceRaised := next.traceEvent(next.trace, "return", arg); traceRaised != nil && raised == nil {
+				ret, raised = nil, traceRaised
+			}
+		}
+	}
 	next.release()
 	f.FreeArgs(validated)
 	if raised == nil {
@@ -84,3 +151,28 @@ func (c *Code) Eval(f *Frame, globals *Dict, args Args, kwargs KWArgs) (*Object,
 	}
 	return ret, raised
 }
+
+func codeGetVarNames(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_varnames", args, CodeType); raised != nil {
+		return nil, raised
+	}
+	c := toCodeUnsafe(args[0])
+	var names []*Object
+	for _, p := range c.paramSpec.params {
+		names = append(names, NewStr(p.Name).ToObject())
+	}
+	if c.flags&CodeFlagVarArg != 0 {
+		names = append(names, NewStr(c.varArg).ToObject())
+	}
+	if c.flags&CodeFlagKWArg != 0 {
+		names = append(names, NewStr(c.kwArg).ToObject())
+	}
+	for _, name := range c.localNames {
+		names = append(names, NewStr(name).ToObject())
+	}
+	return NewTuple(names...).ToObject(), nil
+}
+
+func initCodeType(dict map[string]*Object) {
+	dict["co_varnames"] = newProperty(newBuiltinFunction("_get_varnames", codeGetVarNames).ToObject(), nil, nil).ToObject()
+}
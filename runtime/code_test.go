@@ -75,6 +75,34 @@ func TestNewCode(t *testing.T) {
 	}
 }
 
+func TestCodeAttrs(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, c *Code) (*Tuple, *BaseException) {
+		varNames, raised := GetAttr(f, c.ToObject(), NewStr("co_varnames"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		lineno, raised := GetAttr(f, c.ToObject(), NewStr("co_firstlineno"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(varNames, lineno), nil
+	})
+	params := []Param{{"a", nil}, {"b", None}}
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewCode("f1", "foo.py", nil, 0, nil)), want: newTestTuple(NewTuple(), 0).ToObject()},
+		{args: wrapArgs(NewCode("f2", "foo.py", params, 0, nil).WithLineno(12)), want: newTestTuple(newTestTuple("a", "b"), 12).ToObject()},
+		{args: wrapArgs(NewCode("f3", "foo.py", params, CodeFlagVarArg|CodeFlagKWArg, nil)), want: newTestTuple(newTestTuple("a", "b", "args", "kwargs"), 0).ToObject()},
+		{args: wrapArgs(NewCode("f4", "foo.py", nil, CodeFlagVarArg|CodeFlagKWArg, nil).WithArgNames("x", "y")), want: newTestTuple(newTestTuple("x", "y"), 0).ToObject()},
+		{args: wrapArgs(NewCode("f5", "foo.py", nil, CodeFlagKWArg, nil).WithArgNames("", "kw")), want: newTestTuple(newTestTuple("kw"), 0).ToObject()},
+		{args: wrapArgs(NewCode("f6", "foo.py", params, CodeFlagVarArg, nil).WithLocalNames("c", "d")), want: newTestTuple(newTestTuple("a", "b", "args", "c", "d"), 0).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestCodeEvalRestoreExc(t *testing.T) {
 	e := mustCreateException(RuntimeErrorType, "uh oh")
 	ranC1, ranC2 := false, false
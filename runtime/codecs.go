@@ -0,0 +1,516 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// codecFunc encodes or decodes o, resolving bad chars according to errors.
+type codecFunc func(f *Frame, o *Object, errors string) (*Object, *BaseException)
+
+// codec is an encoding implemented natively by the runtime. Native codecs
+// take precedence over search functions registered via CodecsRegister.
+type codec struct {
+	name   string
+	encode codecFunc
+	decode codecFunc
+	// info is the 4-tuple (encode, decode, None, None) returned by
+	// CodecsLookup for this codec.
+	info *Object
+}
+
+var (
+	// builtinCodecs maps normalized encoding names and aliases to native
+	// codecs.
+	builtinCodecs = map[string]*codec{}
+	codecRegistry = struct {
+		mutex       sync.Mutex
+		searchFuncs []*Object
+		cache       map[string]*Object
+	}{cache: map[string]*Object{}}
+)
+
+// CodecsRegister adds searchFunc to the codec registry. When an encoding that
+// is not built into the runtime is looked up, registered search functions are
+// called in order with the normalized encoding name until one returns a
+// 4-tuple (encoder, decoder, stream_reader, stream_writer).
+func CodecsRegister(f *Frame, searchFunc *Object) *BaseException {
+	if searchFunc.typ.slots.Call == nil {
+		return f.RaiseType(TypeErrorType, "argument must be callable")
+	}
+	codecRegistry.mutex.Lock()
+	codecRegistry.searchFuncs = append(codecRegistry.searchFuncs, searchFunc)
+	codecRegistry.mutex.Unlock()
+	return nil
+}
+
+// CodecsLookup returns the codec info tuple for encoding, raising LookupError
+// if no codec is found.
+func CodecsLookup(f *Frame, encoding string) (*Object, *BaseException) {
+	if c := builtinCodecs[normalizeEncoding(encoding)]; c != nil {
+		return c.info, nil
+	}
+	// Consistent with CPython 2.x, search functions are passed the encoding
+	// name lowercased with spaces converted to hyphens.
+	name := strings.Replace(strings.ToLower(encoding), " ", "-", -1)
+	codecRegistry.mutex.Lock()
+	info := codecRegistry.cache[name]
+	searchFuncs := codecRegistry.searchFuncs
+	codecRegistry.mutex.Unlock()
+	if info != nil {
+		return info, nil
+	}
+	for _, fn := range searchFuncs {
+		result, raised := fn.Call(f, Args{NewStr(name).ToObject()}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if result == None {
+			continue
+		}
+		if !result.isInstance(TupleType) || len(toTupleUnsafe(result).elems) != 4 {
+			return nil, f.RaiseType(TypeErrorType, "codec search functions must return 4-tuples")
+		}
+		codecRegistry.mutex.Lock()
+		codecRegistry.cache[name] = result
+		codecRegistry.mutex.Unlock()
+		return result, nil
+	}
+	return nil, f.RaiseType(LookupErrorType, fmt.Sprintf("unknown encoding: %s", encoding))
+}
+
+// CodecsEncode encodes o using the codec registered for encoding.
+func CodecsEncode(f *Frame, o *Object, encoding, errors string) (*Object, *BaseException) {
+	if c := builtinCodecs[normalizeEncoding(encoding)]; c != nil {
+		return c.encode(f, o, errors)
+	}
+	return codecsCall(f, "encoder", 0, o, encoding, errors)
+}
+
+// CodecsDecode decodes o using the codec registered for encoding.
+func CodecsDecode(f *Frame, o *Object, encoding, errors string) (*Object, *BaseException) {
+	if c := builtinCodecs[normalizeEncoding(encoding)]; c != nil {
+		return c.decode(f, o, errors)
+	}
+	return codecsCall(f, "decoder", 1, o, encoding, errors)
+}
+
+// codecsCall invokes the i'th function of the codec info for encoding, which
+// is expected to return an (object, length consumed) tuple.
+func codecsCall(f *Frame, kind string, i int, o *Object, encoding, errors string) (*Object, *BaseException) {
+	info, raised := CodecsLookup(f, encoding)
+	if raised != nil {
+		return nil, raised
+	}
+	fn, raised := GetItem(f, info, NewInt(i).ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	result, raised := fn.Call(f, Args{o, NewStr(errors).ToObject()}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	if !result.isInstance(TupleType) || len(toTupleUnsafe(result).elems) != 2 {
+		format := "%s must return a tuple (object,integer)"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, kind))
+	}
+	return toTupleUnsafe(result).elems[0], nil
+}
+
+// codecHandleError returns the substitute for a char or byte that a codec
+// can't process, or raises excType with msg when errors is "strict".
+func codecHandleError(f *Frame, errors, replacement string, excType *Type, msg string) (string, *BaseException) {
+	switch errors {
+	case EncodeIgnore:
+		return "", nil
+	case EncodeReplace:
+		return replacement, nil
+	case EncodeStrict:
+		return "", f.RaiseType(excType, msg)
+	case EncodeBackslashReplace:
+		format := "don't know how to handle %s in error callback"
+		return "", f.RaiseType(TypeErrorType, fmt.Sprintf(format, excType.Name()))
+	}
+	format := "unknown error handler name '%s'"
+	return "", f.RaiseType(LookupErrorType, fmt.Sprintf(format, errors))
+}
+
+// codecHandleEncodeError is like codecHandleError for a rune r that can't be
+// encoded. It additionally supports escaping r with "backslashreplace".
+func codecHandleEncodeError(f *Frame, errors string, r rune, replacement, msg string) (string, *BaseException) {
+	if errors == EncodeBackslashReplace {
+		return string(escapeRune(r)), nil
+	}
+	return codecHandleError(f, errors, replacement, UnicodeEncodeErrorType, msg)
+}
+
+// codecDecodeInput returns the bytes to be decoded from o. Unicode objects are
+// first encoded with the default encoding.
+func codecDecodeInput(f *Frame, o *Object) (string, *BaseException) {
+	switch {
+	case o.isInstance(StrType):
+		return toStrUnsafe(o).Value(), nil
+	case o.isInstance(UnicodeType):
+		s, raised := toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
+		if raised != nil {
+			return "", raised
+		}
+		return s.Value(), nil
+	}
+	format := "must be string or buffer, not %s"
+	return "", f.RaiseType(TypeErrorType, fmt.Sprintf(format, o.typ.Name()))
+}
+
+func asciiDecode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	return charmapDecode(f, "ascii", 128, o, errors)
+}
+
+func asciiEncode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	return charmapEncode(f, "ascii", 128, o, errors)
+}
+
+func base64Decode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	// Like binascii.a2b_base64, discard chars outside the base64 alphabet.
+	s = strings.Map(func(r rune) rune {
+		if r == '+' || r == '/' || r == '=' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, s)
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, f.RaiseType(ValueErrorType, "Incorrect padding")
+	}
+	return NewStr(string(b)).ToObject(), nil
+}
+
+func base64Encode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	// Like base64.encodestring, emit lines of at most 76 chars, each
+	// terminated by a newline.
+	const lineLen = 76
+	encoded := base64.StdEncoding.EncodeToString([]byte(s))
+	buf := bytes.Buffer{}
+	for len(encoded) > lineLen {
+		buf.WriteString(encoded[:lineLen])
+		buf.WriteByte('\n')
+		encoded = encoded[lineLen:]
+	}
+	if encoded != "" {
+		buf.WriteString(encoded)
+		buf.WriteByte('\n')
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+// charmapDecode decodes each byte of o as the code point with the same
+// ordinal, treating bytes >= limit as errors.
+func charmapDecode(f *Frame, name string, limit int, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if int(s[i]) < limit {
+			runes = append(runes, rune(s[i]))
+			continue
+		}
+		format := "'%s' codec can't decode byte 0x%02x in position %d: ordinal not in range(%d)"
+		msg := fmt.Sprintf(format, name, s[i], i, limit)
+		repl, raised := codecHandleError(f, errors, "\ufffd", UnicodeDecodeErrorType, msg)
+		if raised != nil {
+			return nil, raised
+		}
+		runes = append(runes, []rune(repl)...)
+	}
+	return NewUnicodeFromRunes(runes).ToObject(), nil
+}
+
+// charmapEncode encodes each code point of o as the byte with the same
+// ordinal, treating code points >= limit as errors.
+func charmapEncode(f *Frame, name string, limit int, o *Object, errors string) (*Object, *BaseException) {
+	u, raised := unicodeCoerce(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	buf := bytes.Buffer{}
+	for i, r := range u.Value() {
+		if r >= 0 && int(r) < limit {
+			buf.WriteByte(byte(r))
+			continue
+		}
+		format := "'%s' codec can't encode character %s in position %d: ordinal not in range(%d)"
+		msg := fmt.Sprintf(format, name, escapeRune(r), i, limit)
+		repl, raised := codecHandleEncodeError(f, errors, r, "?", msg)
+		if raised != nil {
+			return nil, raised
+		}
+		buf.WriteString(repl)
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+func hexDecode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	if len(s)%2 != 0 {
+		return nil, f.RaiseType(TypeErrorType, "Odd-length string")
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, f.RaiseType(TypeErrorType, "Non-hexadecimal digit found")
+	}
+	return NewStr(string(b)).ToObject(), nil
+}
+
+func hexEncode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewStr(hex.EncodeToString([]byte(s))).ToObject(), nil
+}
+
+func latin1Decode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	return charmapDecode(f, "latin-1", 256, o, errors)
+}
+
+func latin1Encode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	return charmapEncode(f, "latin-1", 256, o, errors)
+}
+
+// utf16Decode decodes o as UTF-16 with the given byte order. When order is
+// nil, a leading byte order mark selects the byte order, defaulting to little
+// endian.
+func utf16Decode(f *Frame, name string, order binary.ByteOrder, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	pos := 0
+	if order == nil {
+		order = binary.LittleEndian
+		if strings.HasPrefix(s, "\xff\xfe") {
+			pos = 2
+		} else if strings.HasPrefix(s, "\xfe\xff") {
+			order = binary.BigEndian
+			pos = 2
+		}
+	}
+	var runes []rune
+	for pos < len(s) {
+		var msg string
+		start := pos
+		if pos+2 > len(s) {
+			format := "'%s' codec can't decode byte 0x%02x in position %d: truncated data"
+			msg = fmt.Sprintf(format, name, s[pos], pos)
+			pos = len(s)
+		} else {
+			r := rune(order.Uint16([]byte(s[pos : pos+2])))
+			pos += 2
+			if !utf16.IsSurrogate(r) {
+				runes = append(runes, r)
+				continue
+			}
+			if pos+2 <= len(s) {
+				if r2 := utf16.DecodeRune(r, rune(order.Uint16([]byte(s[pos:pos+2])))); r2 != unicode.ReplacementChar {
+					runes = append(runes, r2)
+					pos += 2
+					continue
+				}
+			}
+			format := "'%s' codec can't decode bytes in position %d-%d: illegal encoding"
+			msg = fmt.Sprintf(format, name, start, pos-1)
+		}
+		repl, raised := codecHandleError(f, errors, "\ufffd", UnicodeDecodeErrorType, msg)
+		if raised != nil {
+			return nil, raised
+		}
+		runes = append(runes, []rune(repl)...)
+	}
+	return NewUnicodeFromRunes(runes).ToObject(), nil
+}
+
+// utf16Encode encodes o as UTF-16 with the given byte order. When order is
+// nil, the result is little endian and prefixed with a byte order mark.
+func utf16Encode(f *Frame, name string, order binary.ByteOrder, o *Object, errors string) (*Object, *BaseException) {
+	u, raised := unicodeCoerce(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	var units []uint16
+	if order == nil {
+		order = binary.LittleEndian
+		units = append(units, 0xfeff)
+	}
+	for i, r := range u.Value() {
+		switch {
+		case r >= 0 && r < 0x10000:
+			units = append(units, uint16(r))
+		case r <= unicode.MaxRune:
+			r1, r2 := utf16.EncodeRune(r)
+			units = append(units, uint16(r1), uint16(r2))
+		default:
+			format := "'%s' codec can't encode character %s in position %d"
+			msg := fmt.Sprintf(format, name, escapeRune(r), i)
+			repl, raised := codecHandleEncodeError(f, errors, r, "\ufffd", msg)
+			if raised != nil {
+				return nil, raised
+			}
+			units = append(units, utf16.Encode([]rune(repl))...)
+		}
+	}
+	buf := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(buf[2*i:], unit)
+	}
+	return NewStr(string(buf)).ToObject(), nil
+}
+
+// NOTE: Decoding UTF-8 data containing surrogates (e.g. U+D800 encoded as
+// '\xed\xa0\x80') will raise UnicodeDecodeError consistent with CPython 3.x
+// but different than 2.x.
+func utf8Decode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	s, raised := codecDecodeInput(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	var runes []rune
+	for pos, r := range s {
+		if r != utf8.RuneError {
+			runes = append(runes, r)
+			continue
+		}
+		format := "'utf8' codec can't decode byte 0x%02x in position %d"
+		msg := fmt.Sprintf(format, int(s[pos]), pos)
+		repl, raised := codecHandleError(f, errors, "\ufffd", UnicodeDecodeErrorType, msg)
+		if raised != nil {
+			return nil, raised
+		}
+		runes = append(runes, []rune(repl)...)
+	}
+	return NewUnicodeFromRunes(runes).ToObject(), nil
+}
+
+// NOTE: If o contains surrogates (e.g. U+D800), utf8Encode will raise
+// UnicodeEncodeError consistent with CPython 3.x but different than 2.x.
+func utf8Encode(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+	u, raised := unicodeCoerce(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	buf := bytes.Buffer{}
+	for i, r := range u.Value() {
+		if utf8.ValidRune(r) {
+			buf.WriteRune(r)
+			continue
+		}
+		format := "'utf8' codec can't encode character %s in position %d"
+		msg := fmt.Sprintf(format, escapeRune(r), i)
+		repl, raised := codecHandleEncodeError(f, errors, r, "\ufffd", msg)
+		if raised != nil {
+			return nil, raised
+		}
+		buf.WriteString(repl)
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+func newUTF16Codec(name string, order binary.ByteOrder) *codec {
+	encode := func(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+		return utf16Encode(f, name, order, o, errors)
+	}
+	decode := func(f *Frame, o *Object, errors string) (*Object, *BaseException) {
+		return utf16Decode(f, name, order, o, errors)
+	}
+	return &codec{name: name, encode: encode, decode: decode}
+}
+
+// newCodecFunction wraps fn in a builtin function with the signature of the
+// functions in a codec info tuple: fn(input, errors='strict') returning an
+// (output, length consumed) tuple.
+func newCodecFunction(name string, fn codecFunc) *Object {
+	return newBuiltinFunction(name, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		expectedTypes := []*Type{ObjectType, BaseStringType}
+		if len(args) == 1 {
+			expectedTypes = expectedTypes[:1]
+		}
+		if raised := checkFunctionArgs(f, name, args, expectedTypes...); raised != nil {
+			return nil, raised
+		}
+		errors := EncodeStrict
+		if len(args) > 1 {
+			s, raised := basestringToStr(f, args[1])
+			if raised != nil {
+				return nil, raised
+			}
+			errors = s.Value()
+		}
+		result, raised := fn(f, args[0], errors)
+		if raised != nil {
+			return nil, raised
+		}
+		n, raised := Len(f, args[0])
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(result, n.ToObject()).ToObject(), nil
+	}).ToObject()
+}
+
+func init() {
+	codecs := []struct {
+		c       *codec
+		aliases []string
+	}{
+		{&codec{name: "ascii", encode: asciiEncode, decode: asciiDecode}, []string{"646", "usascii"}},
+		{&codec{name: "base64", encode: base64Encode, decode: base64Decode}, []string{"base64codec"}},
+		{&codec{name: "hex", encode: hexEncode, decode: hexDecode}, []string{"hexcodec"}},
+		{&codec{name: "latin-1", encode: latin1Encode, decode: latin1Decode}, []string{"8859", "cp819", "iso88591", "l1", "latin"}},
+		{newUTF16Codec("utf-16", nil), []string{"u16"}},
+		{newUTF16Codec("utf-16-be", binary.BigEndian), []string{"unicodebigunmarked"}},
+		{newUTF16Codec("utf-16-le", binary.LittleEndian), []string{"unicodelittleunmarked"}},
+		{&codec{name: "utf-8", encode: utf8Encode, decode: utf8Decode}, []string{"u8", "utf"}},
+	}
+	for _, entry := range codecs {
+		c := entry.c
+		prefix := strings.Replace(c.name, "-", "_", -1)
+		c.info = NewTuple(newCodecFunction(prefix+"_encode", c.encode), newCodecFunction(prefix+"_decode", c.decode), None, None).ToObject()
+		builtinCodecs[normalizeEncoding(c.name)] = c
+		for _, alias := range entry.aliases {
+			builtinCodecs[alias] = c
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodecsDecode(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs("foo", "ascii", "strict"), want: NewUnicode("foo").ToObject()},
+		{args: wrapArgs("foo\xffbar", "US-ASCII", "replace"), want: NewUnicode("foo�bar").ToObject()},
+		{args: wrapArgs("foo\xffbar", "ascii", "ignore"), want: NewUnicode("foobar").ToObject()},
+		{args: wrapArgs("foo\xffbar", "ascii", "strict"), wantExc: mustCreateException(UnicodeDecodeErrorType, "'ascii' codec can't decode byte 0xff in position 3: ordinal not in range(128)")},
+		{args: wrapArgs("caf\xe9", "latin-1", "strict"), want: NewUnicode("café").ToObject()},
+		{args: wrapArgs("caf\xe9", "iso-8859-1", "strict"), want: NewUnicode("café").ToObject()},
+		{args: wrapArgs("caf\xc3\xa9", "UTF-8", "strict"), want: NewUnicode("café").ToObject()},
+		{args: wrapArgs(NewUnicode("café"), "utf8", "strict"), want: NewUnicode("café").ToObject()},
+		{args: wrapArgs("\xff\xfeh\x00i\x00", "utf-16", "strict"), want: NewUnicode("hi").ToObject()},
+		{args: wrapArgs("\xfe\xff\x00h\x00i", "utf-16", "strict"), want: NewUnicode("hi").ToObject()},
+		{args: wrapArgs("h\x00i\x00", "utf16", "strict"), want: NewUnicode("hi").ToObject()},
+		{args: wrapArgs("\x00h\x00i", "utf-16-be", "strict"), want: NewUnicode("hi").ToObject()},
+		{args: wrapArgs("=\xd8\x00\xde", "utf-16-le", "strict"), want: NewUnicode("\U0001f600").ToObject()},
+		{args: wrapArgs("h\x00i", "utf-16-le", "strict"), wantExc: mustCreateException(UnicodeDecodeErrorType, "'utf-16-le' codec can't decode byte 0x69 in position 2: truncated data")},
+		{args: wrapArgs("h\x00i", "utf-16-le", "replace"), want: NewUnicode("h�").ToObject()},
+		{args: wrapArgs("=\xd8h\x00", "utf-16-le", "strict"), wantExc: mustCreateException(UnicodeDecodeErrorType, "'utf-16-le' codec can't decode bytes in position 0-1: illegal encoding")},
+		{args: wrapArgs("=\xd8h\x00", "utf-16-le", "ignore"), want: NewUnicode("h").ToObject()},
+		{args: wrapArgs("foo\xffbar", "ascii", "backslashreplace"), wantExc: mustCreateException(TypeErrorType, "don't know how to handle UnicodeDecodeError in error callback")},
+		{args: wrapArgs("666f6F", "hex", "strict"), want: NewStr("foo").ToObject()},
+		{args: wrapArgs("666", "hex", "strict"), wantExc: mustCreateException(TypeErrorType, "Odd-length string")},
+		{args: wrapArgs("zz", "hex", "strict"), wantExc: mustCreateException(TypeErrorType, "Non-hexadecimal digit found")},
+		{args: wrapArgs("Zm9v\nYmFy\n", "base64", "strict"), want: NewStr("foobar").ToObject()},
+		{args: wrapArgs("Zm9vYg", "base64", "strict"), wantExc: mustCreateException(ValueErrorType, "Incorrect padding")},
+		{args: wrapArgs(42, "ascii", "strict"), wantExc: mustCreateException(TypeErrorType, "must be string or buffer, not int")},
+		{args: wrapArgs("foo", "noexist", "strict"), wantExc: mustCreateException(LookupErrorType, "unknown encoding: noexist")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(CodecsDecode), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCodecsEncode(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewUnicode("foo"), "ascii", "strict"), want: NewStr("foo").ToObject()},
+		{args: wrapArgs("foo", "ascii", "strict"), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(NewUnicode("café"), "ascii", "replace"), want: NewStr("caf?").ToObject()},
+		{args: wrapArgs(NewUnicode("café"), "ascii", "ignore"), want: NewStr("caf").ToObject()},
+		{args: wrapArgs(NewUnicode("café €\U0001f600"), "ascii", "backslashreplace"), want: NewStr(`caf\xe9 \u20ac\U0001f600`).ToObject()},
+		{args: wrapArgs(NewUnicode("café"), "ascii", "strict"), wantExc: mustCreateException(UnicodeEncodeErrorType, `'ascii' codec can't encode character \xe9 in position 3: ordinal not in range(128)`)},
+		{args: wrapArgs(NewUnicode("café"), "ascii", "noexist"), wantExc: mustCreateException(LookupErrorType, "unknown error handler name 'noexist'")},
+		{args: wrapArgs(NewUnicode("café"), "latin_1", "strict"), want: NewStr("caf\xe9").ToObject()},
+		{args: wrapArgs(NewUnicode("€"), "latin-1", "strict"), wantExc: mustCreateException(UnicodeEncodeErrorType, `'latin-1' codec can't encode character \u20ac in position 0: ordinal not in range(256)`)},
+		{args: wrapArgs(NewUnicode("café"), "utf-8", "strict"), want: NewStr("caf\xc3\xa9").ToObject()},
+		{args: wrapArgs(NewUnicode("hi"), "utf-16", "strict"), want: NewStr("\xff\xfeh\x00i\x00").ToObject()},
+		{args: wrapArgs(NewUnicode("hi"), "utf-16-be", "strict"), want: NewStr("\x00h\x00i").ToObject()},
+		{args: wrapArgs(NewUnicode("\U0001f600"), "utf-16-le", "strict"), want: NewStr("=\xd8\x00\xde").ToObject()},
+		{args: wrapArgs("foo", "hex", "strict"), want: NewStr("666f6f").ToObject()},
+		{args: wrapArgs("foobar", "base64", "strict"), want: NewStr("Zm9vYmFy\n").ToObject()},
+		{args: wrapArgs("", "base64", "strict"), want: NewStr("").ToObject()},
+		{args: wrapArgs(strings.Repeat("x", 60), "base64", "strict"), want: NewStr(strings.Repeat("eHh4", 19) + "\neHh4\n").ToObject()},
+		{args: wrapArgs(42, "utf8", "strict"), wantExc: mustCreateException(TypeErrorType, "coercing to Unicode: need string, int found")},
+		{args: wrapArgs(NewUnicode("foo"), "noexist", "strict"), wantExc: mustCreateException(LookupErrorType, "unknown encoding: noexist")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(CodecsEncode), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCodecsLookup(t *testing.T) {
+	upper := wrapFuncForTest(func(f *Frame, s *Str, errors string) (*Tuple, *BaseException) {
+		return NewTuple2(NewStr(strings.ToUpper(s.Value())).ToObject(), NewInt(len(s.Value())).ToObject()), nil
+	})
+	bad := wrapFuncForTest(func(f *Frame, s *Str, errors string) *Object {
+		return s.ToObject()
+	})
+	search := wrapFuncForTest(func(f *Frame, name string) *Object {
+		switch name {
+		case "test-codecs-upper":
+			return NewTuple(upper, upper, None, None).ToObject()
+		case "test-codecs-bad":
+			return NewTuple(bad, bad, None, None).ToObject()
+		case "test-codecs-short":
+			return NewTuple(upper).ToObject()
+		}
+		return None
+	})
+	if raised := CodecsRegister(NewRootFrame(), search); raised != nil {
+		t.Fatal(raised)
+	}
+	fun := wrapFuncForTest(func(f *Frame, encoding string) (*Tuple, *BaseException) {
+		info, raised := CodecsLookup(f, encoding)
+		if raised != nil {
+			return nil, raised
+		}
+		info2, raised := CodecsLookup(f, encoding)
+		if raised != nil {
+			return nil, raised
+		}
+		if info != info2 {
+			return nil, f.RaiseType(AssertionErrorType, "codec lookup was not cached")
+		}
+		encoded, raised := CodecsEncode(f, NewStr("foo").ToObject(), encoding, EncodeStrict)
+		if raised != nil {
+			return nil, raised
+		}
+		decoded, raised := toStrUnsafe(encoded).Decode(f, "ascii", EncodeStrict)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(encoded, decoded.ToObject()), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("Test Codecs Upper"), want: newTestTuple("FOO", NewUnicode("FOO")).ToObject()},
+		{args: wrapArgs("utf-8"), want: newTestTuple("foo", NewUnicode("foo")).ToObject()},
+		{args: wrapArgs("test-codecs-bad"), wantExc: mustCreateException(TypeErrorType, "encoder must return a tuple (object,integer)")},
+		{args: wrapArgs("test-codecs-short"), wantExc: mustCreateException(TypeErrorType, "codec search functions must return 4-tuples")},
+		{args: wrapArgs("test-codecs-noexist"), wantExc: mustCreateException(LookupErrorType, "unknown encoding: test-codecs-noexist")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCodecsRegister(t *testing.T) {
+	cas := invokeTestCase{args: wrapArgs(123), wantExc: mustCreateException(TypeErrorType, "argument must be callable")}
+	if err := runInvokeTestCase(wrapFuncForTest(CodecsRegister), &cas); err != "" {
+		t.Error(err)
+	}
+}
+
+func TestCodecInfoFunctions(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs("utf-8", 0, NewUnicode("café")), want: newTestTuple("caf\xc3\xa9", 4).ToObject()},
+		{args: wrapArgs("utf-8", 1, "caf\xc3\xa9"), want: newTestTuple(NewUnicode("café"), 5).ToObject()},
+		{args: wrapArgs("ascii", 1, "caf\xc3\xa9", "ignore"), want: newTestTuple(NewUnicode("caf"), 5).ToObject()},
+		{args: wrapArgs("hex", 0, "foo"), want: newTestTuple("666f6f", 3).ToObject()},
+		{args: wrapArgs("ascii", 1), wantExc: mustCreateException(TypeErrorType, "'ascii_decode' requires 2 arguments")},
+	}
+	fun := wrapFuncForTest(func(f *Frame, encoding string, i int, args ...*Object) (*Object, *BaseException) {
+		info, raised := CodecsLookup(f, encoding)
+		if raised != nil {
+			return nil, raised
+		}
+		return toTupleUnsafe(info).elems[i].Call(f, args, nil)
+	})
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"math"
 	"math/cmplx"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 )
@@ -490,7 +489,7 @@ func parseComplex(s string) (complex128, error) {
 	f := `(?P<real4>` + fre + `)`
 	sj := `(?P<sign5>[-+])j`
 	j := `(?P<onlyJ>j)`
-	r := regexp.MustCompile(`^(?:` + fsfj + `|` + fsj + `|` + fj + `|` + f + `|` + sj + `|` + j + `)$`)
+	r := compiledRegexps.mustCompile(`^(?:` + fsfj + `|` + fsj + `|` + fj + `|` + f + `|` + sj + `|` + j + `)$`)
 	subs := r.FindStringSubmatch(ts)
 	if subs == nil {
 		return complex(0, 0), errors.New("Malformed complex string, no mathing pattern found")
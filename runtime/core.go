@@ -17,8 +17,10 @@ package grumpy
 import (
 	"fmt"
 	"log"
+	"os"
 	"reflect"
 	"sync/atomic"
+	"unicode"
 )
 
 var (
@@ -26,6 +28,11 @@ var (
 	// ThreadCount is the number of goroutines started with StartThread that
 	// have not yet joined.
 	ThreadCount int64
+	// optimizeFlag disables assertions by default when non-empty. It can be
+	// set at build time with: -ldflags "-X grumpy.optimizeFlag=1"
+	optimizeFlag string
+	// debugEnabled is non-zero when assertions are enabled. See Debug().
+	debugEnabled = initDebugEnabled()
 )
 
 // Abs returns the result of o.__abs__ and is equivalent to the Python
@@ -52,8 +59,11 @@ func And(f *Frame, v, w *Object) (*Object, *BaseException) {
 
 // Assert raises an AssertionError if the given cond does not evaluate to true.
 // If msg is not nil, it is converted to a string via ToStr() and passed as args
-// to the raised exception.
+// to the raised exception. Assert does nothing when Debug() is false.
 func Assert(f *Frame, cond *Object, msg *Object) *BaseException {
+	if !Debug() {
+		return nil
+	}
 	result, raised := IsTrue(f, cond)
 	if raised == nil && !result {
 		if msg == nil {
@@ -69,6 +79,34 @@ func Assert(f *Frame, cond *Object, msg *Object) *BaseException {
 	return raised
 }
 
+// Debug returns the value of the Python __debug__ builtin. It is true unless
+// assertions have been disabled, either at build time, by setting the
+// PYTHONOPTIMIZE environment variable or by calling SetDebug(false).
+func Debug() bool {
+	return atomic.LoadInt32(&debugEnabled) != 0
+}
+
+// SetDebug enables or disables assertions and updates the __debug__ builtin
+// accordingly, similar to running CPython with or without -O. It should be
+// called before any Python code is run.
+func SetDebug(debug bool) {
+	var v int32
+	if debug {
+		v = 1
+	}
+	atomic.StoreInt32(&debugEnabled, v)
+	if raised := Builtins.SetItemString(NewRootFrame(), "__debug__", GetBool(debug).ToObject()); raised != nil {
+		logFatal(FormatExc(NewRootFrame()))
+	}
+}
+
+func initDebugEnabled() int32 {
+	if optimizeFlag != "" || os.Getenv("PYTHONOPTIMIZE") != "" {
+		return 0
+	}
+	return 1
+}
+
 // Compare implements a 3-way comparison which returns:
 //
 //   -1 if v < w
@@ -77,9 +115,8 @@ func Assert(f *Frame, cond *Object, msg *Object) *BaseException {
 //
 // It closely resembles the behavior of CPython's do_cmp in object.c.
 func Compare(f *Frame, v, w *Object) (*Object, *BaseException) {
-	cmp := v.typ.slots.Cmp
-	if v.typ == w.typ && cmp != nil {
-		return cmp.Fn(f, v, w)
+	if v.typ == w.typ && v.typ.slots.Cmp != nil {
+		return halfCompare(f, v, w)
 	}
 	r, raised := tryRichTo3wayCompare(f, v, w)
 	if r != NotImplemented {
@@ -159,6 +196,53 @@ func DelItem(f *Frame, o, key *Object) *BaseException {
 	return delItem.Fn(f, o, key)
 }
 
+// Dir returns a sorted list of the attribute names of o. The names come from
+// o.__dir__ when it is defined. Otherwise they are the keys of o's dict and of
+// the dicts of the types in its MRO, or for types, of the dicts in their own
+// MRO.
+func Dir(f *Frame, o *Object) (*List, *BaseException) {
+	var l *List
+	if dir := o.typ.slots.Dir; dir != nil {
+		result, raised := dir.Fn(f, o)
+		if raised != nil {
+			return nil, raised
+		}
+		if !result.isInstance(ListType) {
+			format := "__dir__() must return a list, not %s"
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
+		}
+		l = toListUnsafe(result)
+	} else {
+		d := NewDict()
+		var dicts []*Dict
+		switch {
+		case o.isInstance(TypeType):
+			for _, t := range toTypeUnsafe(o).mro {
+				dicts = append(dicts, t.Dict())
+			}
+		case o.isInstance(ModuleType):
+			dicts = append(dicts, o.Dict())
+		default:
+			if dict := o.Dict(); dict != nil {
+				dicts = append(dicts, dict)
+			}
+			for _, t := range o.typ.mro {
+				dicts = append(dicts, t.Dict())
+			}
+		}
+		for _, dict := range dicts {
+			if raised := d.Update(f, dict.ToObject()); raised != nil {
+				return nil, raised
+			}
+		}
+		l = d.Keys(f)
+	}
+	if raised := l.Sort(f); raised != nil {
+		return nil, raised
+	}
+	return l, nil
+}
+
 // Div returns the result of dividing v by w according to the __div/rdiv__
 // operator.
 func Div(f *Frame, v, w *Object) (*Object, *BaseException) {
@@ -188,34 +272,24 @@ func FloorDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return binaryOp(f, v, w, v.typ.slots.FloorDiv, v.typ.slots.RFloorDiv, w.typ.slots.RFloorDiv, "//")
 }
 
-// FormatExc calls traceback.format_exc, falling back to the single line
-// exception message if that fails, e.g. "NameError: name 'x' is not defined\n".
-func FormatExc(f *Frame) (s string) {
+// FormatExc returns the exception currently being handled by f formatted
+// like traceback.format_exc() does, i.e. the stack of frames it propagated
+// through followed by the exception type and message:
+//
+//   Traceback (most recent call last):
+//     File "foo.py", line 2, in <module>
+//       bar()
+//   NameError: global name 'bar' is not defined
+//
+// Frames without a code object, e.g. root frames, are omitted and the header
+// is only written if there is at least one frame to show.
+func FormatExc(f *Frame) string {
 	exc, tb := f.ExcInfo()
-	defer func() {
-		if s == "" {
-			strResult, raised := ToStr(f, exc.ToObject())
-			if raised == nil && strResult.Value() != "" {
-				s = fmt.Sprintf("%s: %s\n", exc.typ.Name(), strResult.Value())
-			} else {
-				s = exc.typ.Name() + "\n"
-			}
-		}
-		f.RestoreExc(exc, tb)
-	}()
-	tbMod, raised := SysModules.GetItemString(f, "traceback")
-	if raised != nil || tbMod == nil {
-		return
-	}
-	formatExc, raised := GetAttr(f, tbMod, NewStr("format_exc"), nil)
-	if raised != nil {
-		return
+	defer f.RestoreExc(exc, tb)
+	if exc == nil {
+		return "None\n"
 	}
-	result, raised := formatExc.Call(f, nil, nil)
-	if raised != nil || !result.isInstance(StrType) {
-		return
-	}
-	return toStrUnsafe(result).Value()
+	return formatTraceback(tb) + formatExceptionOnly(f, exc)
 }
 
 // GE returns the result of operation v >= w.
@@ -557,9 +631,17 @@ func Index(f *Frame, o *Object) (*Object, *BaseException) {
 }
 
 // IndexInt returns the value of o converted to a Go int according to o's
-// __index__ slot.
+// __index__ slot. Longs outside the range of int are clamped to MinInt or
+// MaxInt, which is the behavior wanted for slice bounds.
 // It raises a TypeError if o doesn't have an __index__ method.
 func IndexInt(f *Frame, o *Object) (i int, raised *BaseException) {
+	return indexIntChecked(f, o, nil)
+}
+
+// indexIntChecked is like IndexInt but raises an exception of type excType
+// when o is a long outside the range of int. When excType is nil the value is
+// clamped instead. It closely resembles CPython's PyNumber_AsSsize_t.
+func indexIntChecked(f *Frame, o *Object, excType *Type) (i int, raised *BaseException) {
 	if index := o.typ.slots.Index; index != nil {
 		// Unwrap __index__ slot and fall through.
 		o, raised = index.Fn(f, o)
@@ -572,11 +654,16 @@ func IndexInt(f *Frame, o *Object) (i int, raised *BaseException) {
 	}
 	if o.isInstance(LongType) {
 		l := toLongUnsafe(o).Value()
-		// Anything bigger than maxIntBig will treat as maxIntBig.
-		if !numInIntRange(l) {
-			l = maxIntBig
+		switch {
+		case numInIntRange(l):
+			return int(l.Int64()), nil
+		case excType != nil:
+			format := "cannot fit '%s' into an index-sized integer"
+			return 0, f.RaiseType(excType, fmt.Sprintf(format, o.typ.Name()))
+		case l.Sign() < 0:
+			return MinInt, nil
 		}
-		return int(l.Int64()), nil
+		return MaxInt, nil
 	}
 	return 0, f.RaiseType(TypeErrorType, errBadSliceIndex)
 }
@@ -586,17 +673,24 @@ func IndexInt(f *Frame, o *Object) (i int, raised *BaseException) {
 // packs the arguments into slices for the positional and keyword arguments,
 // then it passes those to *Object.Call.
 func Invoke(f *Frame, callable *Object, args Args, varargs *Object, keywords KWArgs, kwargs *Object) (*Object, *BaseException) {
+	var packedArgs Args
 	if varargs != nil {
-		raised := seqApply(f, varargs, func(elems []*Object, _ bool) *BaseException {
-			numArgs := len(args)
-			packed := make([]*Object, numArgs+len(elems))
-			copy(packed, args)
-			copy(packed[numArgs:], elems)
-			args = packed
-			return nil
-		})
-		if raised != nil {
-			return nil, raised
+		if len(args) == 0 && varargs.typ == TupleType {
+			// Tuples are immutable so their elements can be passed
+			// through without being copied.
+			args = toTupleUnsafe(varargs).elems
+		} else {
+			raised := seqApply(f, varargs, func(elems []*Object, _ bool) *BaseException {
+				numArgs := len(args)
+				packedArgs = f.MakeArgs(numArgs + len(elems))
+				copy(packedArgs, args)
+				copy(packedArgs[numArgs:], elems)
+				return nil
+			})
+			if raised != nil {
+				return nil, raised
+			}
+			args = packedArgs
 		}
 	}
 	if kwargs != nil {
@@ -641,7 +735,11 @@ func Invoke(f *Frame, callable *Object, args Args, varargs *Object, keywords KWA
 		}
 		keywords = packed
 	}
-	return callable.Call(f, args, keywords)
+	result, raised := callable.Call(f, args, keywords)
+	if packedArgs != nil {
+		f.FreeArgs(packedArgs)
+	}
+	return result, raised
 }
 
 // NE returns the non-equality of v and w according to the __ne__ operator.
@@ -696,17 +794,169 @@ func Pos(f *Frame, o *Object) (*Object, *BaseException) {
 }
 
 // Print implements the Python print statement. It calls str() on the given args
-// and outputs the results to stdout separated by spaces. Similar to the Python
-// print statement.
+// and outputs the results to sys.stdout separated by spaces. Similar to the
+// Python print statement.
 func Print(f *Frame, args Args, nl bool) *BaseException {
-	// TODO: Support outputting to files other than stdout and softspace.
-	var end string
+	return PrintTo(f, None, args, nl)
+}
+
+// PrintTo implements the Python statement "print >>dest, args...". The str()
+// of each arg is passed to dest.write(), or to sys.stdout when dest is None.
+// The softspace attribute of dest records whether a separating space is owed
+// to the next value printed, so that consecutive print statements ending in a
+// comma are separated by exactly one space.
+func PrintTo(f *Frame, dest *Object, args Args, nl bool) *BaseException {
+	if dest == None {
+		var raised *BaseException
+		if dest, raised = sysStream(f, "stdout"); raised != nil {
+			return raised
+		}
+	}
+	write, raised := writeFunc(f, dest)
+	if raised != nil {
+		return raised
+	}
+	for _, arg := range args {
+		if printGetSoftspace(f, dest) {
+			if raised := write(" "); raised != nil {
+				return raised
+			}
+		}
+		s, raised := ToStr(f, arg)
+		if raised != nil {
+			return raised
+		}
+		if raised := write(s.Value()); raised != nil {
+			return raised
+		}
+		// A value ending in whitespace other than a plain space, e.g. a
+		// newline, does not need to be followed by a space.
+		softspace := true
+		if v := s.Value(); v != "" && (arg.isInstance(StrType) || arg.isInstance(UnicodeType)) {
+			if c := v[len(v)-1]; c != ' ' && unicode.IsSpace(rune(c)) {
+				softspace = false
+			}
+		}
+		printSetSoftspace(f, dest, softspace)
+	}
 	if nl {
-		end = "\n"
-	} else if len(args) > 0 {
-		end = " "
+		if raised := write("\n"); raised != nil {
+			return raised
+		}
+		printSetSoftspace(f, dest, false)
 	}
-	return pyPrint(f, args, " ", end, Stdout)
+	return nil
+}
+
+// printFinishLine terminates output left pending by a print statement ending
+// in a comma, the way the interpreter does when it exits.
+func printFinishLine(f *Frame) {
+	stdout, raised := sysStream(f, "stdout")
+	if raised != nil {
+		f.RestoreExc(nil, nil)
+		return
+	}
+	if printGetSoftspace(f, stdout) {
+		if raised := PrintTo(f, stdout, nil, true); raised != nil {
+			f.RestoreExc(nil, nil)
+		}
+	}
+}
+
+// printGetSoftspace returns the truth value of dest.softspace. Objects that
+// lack the attribute are treated as if it were false.
+func printGetSoftspace(f *Frame, dest *Object) bool {
+	if dest.typ == FileType {
+		return toFileUnsafe(dest).Softspace != 0
+	}
+	o, raised := GetAttr(f, dest, NewStr("softspace"), False.ToObject())
+	if raised == nil {
+		var softspace bool
+		if softspace, raised = IsTrue(f, o); raised == nil {
+			return softspace
+		}
+	}
+	f.RestoreExc(nil, nil)
+	return false
+}
+
+// printSetSoftspace sets dest.softspace, ignoring objects that do not permit
+// the attribute to be set.
+func printSetSoftspace(f *Frame, dest *Object, softspace bool) {
+	i := 0
+	if softspace {
+		i = 1
+	}
+	if dest.typ == FileType {
+		toFileUnsafe(dest).Softspace = i
+	} else if raised := SetAttr(f, dest, NewStr("softspace"), NewInt(i).ToObject()); raised != nil {
+		f.RestoreExc(nil, nil)
+	}
+}
+
+// sysStream returns the sys module attribute with the given name, e.g.
+// "stdout". If the sys module has not been imported then the corresponding
+// File (Stdin, Stdout or Stderr) is returned instead.
+func sysStream(f *Frame, name string) (*Object, *BaseException) {
+	sys, raised := SysModules.GetItemString(f, "sys")
+	if raised != nil {
+		return nil, raised
+	}
+	if sys == nil {
+		switch name {
+		case "stdin":
+			return Stdin.ToObject(), nil
+		case "stderr":
+			return Stderr.ToObject(), nil
+		}
+		return Stdout.ToObject(), nil
+	}
+	stream, raised := GetAttr(f, sys, NewStr(name), None)
+	if raised != nil {
+		return nil, raised
+	}
+	if stream == None {
+		return nil, f.RaiseType(RuntimeErrorType, "lost sys."+name)
+	}
+	return stream, nil
+}
+
+// writeFunc returns a function that writes strings to dest. Files are written
+// to directly and other objects, including instances of file subclasses that
+// may override it, via their write() method.
+func writeFunc(f *Frame, dest *Object) (func(s string) *BaseException, *BaseException) {
+	if dest.typ == FileType {
+		file := toFileUnsafe(dest)
+		return func(s string) *BaseException {
+			if err := file.writeString(s); err != nil {
+				return RaiseGoError(f, err)
+			}
+			return nil
+		}, nil
+	}
+	method, raised := GetAttr(f, dest, NewStr("write"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return func(s string) *BaseException {
+		_, raised := method.Call(f, Args{NewStr(s).ToObject()}, nil)
+		return raised
+	}, nil
+}
+
+// writeStderr writes s to sys.stderr, falling back to Stderr if that fails.
+// It is used to report exceptions that cannot be propagated any further.
+func writeStderr(f *Frame, s string) {
+	exc, tb := f.ExcInfo()
+	defer f.RestoreExc(exc, tb)
+	if stderr, raised := sysStream(f, "stderr"); raised == nil {
+		if write, raised := writeFunc(f, stderr); raised == nil {
+			if raised := write(s); raised == nil {
+				return
+			}
+		}
+	}
+	Stderr.writeString(s)
 }
 
 // Repr returns a string containing a printable representation of o. This is
@@ -724,6 +974,11 @@ func Repr(f *Frame, o *Object) (*Str, *BaseException) {
 	if raised != nil {
 		return nil, raised
 	}
+	if r.isInstance(UnicodeType) {
+		// Escape non-ASCII chars so that the result is printable
+		// regardless of the output encoding.
+		return toUnicodeUnsafe(r).Encode(f, "ascii", EncodeBackslashReplace)
+	}
 	if !r.isInstance(StrType) {
 		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("__repr__ returned non-string (type %s)", r.typ.Name()))
 	}
@@ -750,12 +1005,13 @@ func ResolveClass(f *Frame, class *Dict, local *Object, name *Str) (*Object, *Ba
 }
 
 // ResolveGlobal looks up name in the frame's dict of global variables or in
-// the Builtins dict if absent. It raises NameError when absent from both.
+// the frame's builtins dict (see Frame.Builtins) if absent. It raises NameError
+// when absent from both.
 func ResolveGlobal(f *Frame, name *Str) (*Object, *BaseException) {
 	if value, raised := f.Globals().GetItem(f, name.ToObject()); raised != nil || value != nil {
 		return value, raised
 	}
-	value, raised := Builtins.GetItem(f, name.ToObject())
+	value, raised := f.Builtins().GetItem(f, name.ToObject())
 	if raised != nil {
 		return nil, raised
 	}
@@ -800,15 +1056,17 @@ func SetItem(f *Frame, o, key, value *Object) *BaseException {
 	return setItem.Fn(f, o, key, value)
 }
 
-// StartThread runs callable in a new goroutine.
-func StartThread(callable *Object) {
+// StartThread runs callable in a new goroutine. The new thread is subject to
+// the same Policy as f's stack.
+func StartThread(f *Frame, callable *Object) {
+	tf := newThreadRootFrame(f)
 	go func() {
 		atomic.AddInt64(&ThreadCount, 1)
 		defer atomic.AddInt64(&ThreadCount, -1)
-		f := NewRootFrame()
-		_, raised := callable.Call(f, nil, nil)
-		if raised != nil {
-			Stderr.writeString(FormatExc(f))
+		// As in CPython, SystemExit silently terminates the thread.
+		_, raised := callable.Call(tf, nil, nil)
+		if raised != nil && !raised.isInstance(SystemExitType) {
+			writeStderr(tf, FormatExc(tf))
 		}
 	}()
 }
@@ -847,6 +1105,38 @@ func Tie(f *Frame, t TieTarget, o *Object) *BaseException {
 		*t.Target = o
 		return nil
 	}
+	// Unpack exact lists and tuples directly from their elements to avoid
+	// allocating an iterator. Subtypes may override __iter__ so they take the
+	// slow path below.
+	var elems []*Object
+	switch o.typ {
+	case ListType:
+		l := toListUnsafe(o)
+		l.mutex.RLock()
+		elems = make([]*Object, len(l.elems))
+		copy(elems, l.elems)
+		l.mutex.RUnlock()
+	case TupleType:
+		elems = toTupleUnsafe(o).elems
+	default:
+		return tieIter(f, t, o)
+	}
+	n := len(t.Children)
+	if len(elems) < n {
+		return tieNeedMore(f, len(elems))
+	}
+	if len(elems) > n {
+		return tieTooMany(f, n)
+	}
+	for i, child := range t.Children {
+		if raised := Tie(f, child, elems[i]); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+func tieIter(f *Frame, t TieTarget, o *Object) *BaseException {
 	iter, raised := Iter(f, o)
 	if raised != nil {
 		return raised
@@ -857,14 +1147,15 @@ func Tie(f *Frame, t TieTarget, o *Object) *BaseException {
 				return raised
 			}
 		} else if raised.isInstance(StopIterationType) {
-			return f.RaiseType(ValueErrorType, fmt.Sprintf("need more than %d values to unpack", i))
+			f.RestoreExc(nil, nil)
+			return tieNeedMore(f, i)
 		} else {
 			return raised
 		}
 	}
 	_, raised = Next(f, iter)
 	if raised == nil {
-		return f.RaiseType(ValueErrorType, "too many values to unpack")
+		return tieTooMany(f, len(t.Children))
 	}
 	if !raised.isInstance(StopIterationType) {
 		return raised
@@ -873,6 +1164,18 @@ func Tie(f *Frame, t TieTarget, o *Object) *BaseException {
 	return nil
 }
 
+func tieNeedMore(f *Frame, got int) *BaseException {
+	plural := "s"
+	if got == 1 {
+		plural = ""
+	}
+	return f.RaiseType(ValueErrorType, fmt.Sprintf("need more than %d value%s to unpack", got, plural))
+}
+
+func tieTooMany(f *Frame, expected int) *BaseException {
+	return f.RaiseType(ValueErrorType, fmt.Sprintf("too many values to unpack (expected %d)", expected))
+}
+
 // ToInt converts o to an integer type according to the __int__ slot. If the
 // result is not an int or long, then an exception is raised.
 func ToInt(f *Frame, o *Object) (*Object, *BaseException) {
@@ -1174,18 +1477,28 @@ func tryRichCompareBool(f *Frame, op compareOp, v, w *Object) (bool, *BaseExcept
 }
 
 // halfCompare tries a comparison with the __cmp__ slot, ensures the result
-// is an integer, and returns it. It closely resembles the behavior of CPython's
-// half_compare in typeobject.c.
+// is an int or long, and returns its sign as an int. It closely resembles the
+// behavior of CPython's half_compare in typeobject.c.
 func halfCompare(f *Frame, v, w *Object) (*Object, *BaseException) {
 	cmp := v.typ.slots.Cmp
 	r, raised := cmp.Fn(f, v, w)
 	if raised != nil {
 		return nil, raised
 	}
-	if !r.isInstance(IntType) {
+	c := 0
+	switch {
+	case r.isInstance(IntType):
+		if i := toIntUnsafe(r).Value(); i < 0 {
+			c = -1
+		} else if i > 0 {
+			c = 1
+		}
+	case r.isInstance(LongType):
+		c = toLongUnsafe(r).Value().Sign()
+	default:
 		return nil, f.RaiseType(TypeErrorType, "an integer is required")
 	}
-	return r, nil
+	return NewInt(c).ToObject(), nil
 }
 
 // try3wayCompare tries a comparison with the __cmp__ slot with the given
@@ -1279,30 +1592,24 @@ func hashNotImplemented(f *Frame, o *Object) (*Object, *BaseException) {
 }
 
 // pyPrint encapsulates the logic of the Python print function.
-func pyPrint(f *Frame, args Args, sep, end string, file *File) *BaseException {
+func pyPrint(f *Frame, args Args, sep, end string, file *Object) *BaseException {
+	write, raised := writeFunc(f, file)
+	if raised != nil {
+		return raised
+	}
 	for i, arg := range args {
 		if i > 0 {
-			err := file.writeString(sep)
-			if err != nil {
-				return f.RaiseType(IOErrorType, err.Error())
+			if raised := write(sep); raised != nil {
+				return raised
 			}
 		}
-
 		s, raised := ToStr(f, arg)
 		if raised != nil {
 			return raised
 		}
-
-		err := file.writeString(s.Value())
-		if err != nil {
-			return f.RaiseType(IOErrorType, err.Error())
+		if raised := write(s.Value()); raised != nil {
+			return raised
 		}
 	}
-
-	err := file.writeString(end)
-	if err != nil {
-		return f.RaiseType(IOErrorType, err.Error())
-	}
-
-	return nil
+	return write(end)
 }
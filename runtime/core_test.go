@@ -54,6 +54,18 @@ func TestAssert(t *testing.T) {
 	}
 }
 
+func TestAssertNoDebug(t *testing.T) {
+	SetDebug(false)
+	defer SetDebug(true)
+	f := NewRootFrame()
+	if raised := Assert(f, False.ToObject(), nil); raised != nil {
+		t.Errorf("Assert(False) raised %v with debug disabled", raised)
+	}
+	if got, raised := Builtins.GetItemString(f, "__debug__"); raised != nil || got != False.ToObject() {
+		t.Errorf("__debug__ = %v, %v, want False, nil", got, raised)
+	}
+}
+
 func TestBinaryOps(t *testing.T) {
 	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
 		"__add__": newBuiltinFunction("__add__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -207,6 +219,16 @@ func TestCompare(t *testing.T) {
 			return NewStr("foo").ToObject(), nil
 		}).ToObject(),
 	}))
+	cmpLongResultType := newTestClass("CmpLongResult", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__cmp__": newBuiltinFunction("__cmp__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return NewLong(new(big.Int).Lsh(big.NewInt(-1), 100)).ToObject(), nil
+		}).ToObject(),
+	}))
+	cmpBigIntResultType := newTestClass("CmpBigIntResult", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__cmp__": newBuiltinFunction("__cmp__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return NewInt(42).ToObject(), nil
+		}).ToObject(),
+	}))
 	cases := []invokeTestCase{
 		// Test `__cmp__` less than.
 		{args: wrapArgs(newObject(cmpLtType), None), want: NewInt(-1).ToObject()},
@@ -232,12 +254,22 @@ func TestCompare(t *testing.T) {
 		// Test bad `__cmp__` with non-int result.
 		{args: wrapArgs(newObject(cmpNonIntResultType), None), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{args: wrapArgs(None, newObject(cmpNonIntResultType)), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
+		// Test `__cmp__` results are normalized to their sign.
+		{args: wrapArgs(newObject(cmpLongResultType), None), want: NewInt(-1).ToObject()},
+		{args: wrapArgs(None, newObject(cmpLongResultType)), want: NewInt(1).ToObject()},
+		{args: wrapArgs(newObject(cmpLongResultType), newObject(cmpLongResultType)), want: NewInt(-1).ToObject()},
+		{args: wrapArgs(newObject(cmpBigIntResultType), newObject(cmpBigIntResultType)), want: NewInt(1).ToObject()},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(wrapFuncForTest(Compare), &cas); err != "" {
 			t.Error(err)
 		}
 	}
+	// Rich comparisons fall back to `__cmp__` and must accept long results.
+	lt := wrapArgs(newObject(cmpLongResultType), None)
+	if got, raised := LT(NewRootFrame(), lt[0], lt[1]); raised != nil || got != True.ToObject() {
+		t.Errorf("LT(%v, None) = %v, %v, want True, nil", lt[0], got, raised)
+	}
 }
 
 func TestCompareDefault(t *testing.T) {
@@ -568,6 +600,20 @@ func TestIsTrue(t *testing.T) {
 			return None, nil
 		}).ToObject(),
 	}))
+	constFunc := func(name string, value *Object) *Object {
+		return newBuiltinFunction(name, func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return value, nil
+		}).ToObject()
+	}
+	boolType := newTestClass("Bool", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__bool__": constFunc("__bool__", False.ToObject()),
+	}))
+	// __nonzero__ takes precedence over __bool__, including inherited.
+	boolNonZeroType := newTestClass("BoolNonZero", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__bool__":    constFunc("__bool__", False.ToObject()),
+		"__nonzero__": constFunc("__nonzero__", True.ToObject()),
+	}))
+	boolSubType := newTestClass("BoolSub", []*Type{boolType}, NewDict())
 	cases := []invokeTestCase{
 		// Bool
 		{args: wrapArgs(true), want: True.ToObject()},
@@ -593,6 +639,9 @@ func TestIsTrue(t *testing.T) {
 		// Funky types
 		{args: wrapArgs(newObject(badNonZeroType)), wantExc: mustCreateException(TypeErrorType, "__nonzero__ should return bool, returned NoneType")},
 		{args: wrapArgs(newObject(badLenType)), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
+		{args: wrapArgs(newObject(boolType)), want: False.ToObject()},
+		{args: wrapArgs(newObject(boolNonZeroType)), want: True.ToObject()},
+		{args: wrapArgs(newObject(boolSubType)), want: False.ToObject()},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(wrapFuncForTest(IsTrue), &cas); err != "" {
@@ -820,7 +869,7 @@ func TestPos(t *testing.T) {
 func TestPyPrint(t *testing.T) {
 	fun := wrapFuncForTest(func(f *Frame, args *Tuple, sep, end string) (string, *BaseException) {
 		return captureStdout(f, func() *BaseException {
-			return pyPrint(NewRootFrame(), args.elems, sep, end, Stdout)
+			return pyPrint(NewRootFrame(), args.elems, sep, end, Stdout.ToObject())
 		})
 	})
 	cases := []invokeTestCase{
@@ -836,8 +885,50 @@ func TestPyPrint(t *testing.T) {
 	}
 }
 
-// TODO(corona10): Re-enable once #282 is addressed.
-/*func TestPrint(t *testing.T) {
+func TestPrintTo(t *testing.T) {
+	writes := NewList()
+	write := newBuiltinFunction("write", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		writes.Append(args[1])
+		return None, nil
+	}).ToObject()
+	writerType := newTestClass("Writer", []*Type{ObjectType}, newStringDict(map[string]*Object{"write": write}))
+	fileWriterType := newTestClass("FileWriter", []*Type{FileType}, newStringDict(map[string]*Object{"write": write}))
+	fun := wrapFuncForTest(func(f *Frame, dest *Object, stmts ...*Tuple) (*Tuple, *BaseException) {
+		for _, stmt := range stmts {
+			nl, raised := IsTrue(f, stmt.elems[0])
+			if raised != nil {
+				return nil, raised
+			}
+			if raised := PrintTo(f, dest, stmt.elems[1:], nl); raised != nil {
+				return nil, raised
+			}
+		}
+		if dest.isInstance(StringIOType) {
+			return NewTuple2(NewStr(toStringIOUnsafe(dest).buf.String()).ToObject(), NewInt(toStringIOUnsafe(dest).Softspace).ToObject()), nil
+		}
+		result := NewTuple2(writes.ToObject(), mustNotRaise(GetAttr(f, dest, NewStr("softspace"), None)))
+		writes = NewList()
+		return result, nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO(""), newTestTuple(true)), want: newTestTuple("\n", 0).ToObject()},
+		{args: wrapArgs(NewStringIO(""), newTestTuple(true, "abc", 123)), want: newTestTuple("abc 123\n", 0).ToObject()},
+		{args: wrapArgs(NewStringIO(""), newTestTuple(false, "foo"), newTestTuple(true, "bar")), want: newTestTuple("foo bar\n", 0).ToObject()},
+		{args: wrapArgs(NewStringIO(""), newTestTuple(false, "foo\n"), newTestTuple(false, "bar")), want: newTestTuple("foo\nbar", 1).ToObject()},
+		{args: wrapArgs(NewStringIO(""), newTestTuple(false, "foo\t"), newTestTuple(false, "foo ")), want: newTestTuple("foo\tfoo ", 1).ToObject()},
+		{args: wrapArgs(newObject(writerType), newTestTuple(false, 1, 2)), want: newTestTuple(newTestList("1", " ", "2"), 1).ToObject()},
+		{args: wrapArgs(newObject(writerType), newTestTuple(false, 1), newTestTuple(true)), want: newTestTuple(newTestList("1", "\n"), 0).ToObject()},
+		{args: wrapArgs(newObject(fileWriterType), newTestTuple(true, "foo", 2)), want: newTestTuple(newTestList("foo", " ", "2", "\n"), 0).ToObject()},
+		{args: wrapArgs(newObject(ObjectType), newTestTuple(true, 1)), wantExc: mustCreateException(AttributeErrorType, "'object' object has no attribute 'write'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
 	fun := wrapFuncForTest(func(f *Frame, args *Tuple, nl bool) (string, *BaseException) {
 		return captureStdout(f, func() *BaseException {
 			return Print(NewRootFrame(), args.elems, nl)
@@ -847,14 +938,15 @@ func TestPyPrint(t *testing.T) {
 		{args: wrapArgs(NewTuple(), true), want: NewStr("\n").ToObject()},
 		{args: wrapArgs(NewTuple(), false), want: NewStr("").ToObject()},
 		{args: wrapArgs(newTestTuple("abc", 123), true), want: NewStr("abc 123\n").ToObject()},
-		{args: wrapArgs(newTestTuple("foo"), false), want: NewStr("foo ").ToObject()},
+		// The trailing space is deferred until the next print via softspace.
+		{args: wrapArgs(newTestTuple("foo"), false), want: NewStr("foo").ToObject()},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
 			t.Error(err)
 		}
 	}
-}*/
+}
 
 func TestReprRaise(t *testing.T) {
 	testTypes := []*Type{
@@ -880,6 +972,38 @@ func TestReprRaise(t *testing.T) {
 	}
 }
 
+func TestReprMethodReturnsUnicode(t *testing.T) {
+	typ := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__repr__": newBuiltinFunction("__repr__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return NewUnicode("foo").ToObject(), nil
+		}).ToObject(),
+	}))
+	s, raised := Repr(NewRootFrame(), newObject(typ))
+	if raised != nil || s.Value() != "foo" {
+		t.Errorf(`Repr() = (%v, %v), want ("foo", nil)`, s, raised)
+	}
+}
+
+func TestReprMethodReturnsNonASCIIUnicode(t *testing.T) {
+	typ := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__repr__": newBuiltinFunction("__repr__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return NewUnicode("café €").ToObject(), nil
+		}).ToObject(),
+	}))
+	foo := newObject(typ)
+	cases := []invokeTestCase{
+		{args: wrapArgs(foo), want: NewStr(`caf\xe9 \u20ac`).ToObject()},
+		{args: wrapArgs(newTestList(foo, 1)), want: NewStr(`[caf\xe9 \u20ac, 1]`).ToObject()},
+		{args: wrapArgs(newTestTuple(foo)), want: NewStr(`(caf\xe9 \u20ac,)`).ToObject()},
+		{args: wrapArgs(newTestDict("k", foo)), want: NewStr(`{'k': caf\xe9 \u20ac}`).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestReprMethodReturnsNonStr(t *testing.T) {
 	// Don't use runInvokeTestCase since it takes repr(args) and in this
 	// case repr will raise.
@@ -1022,7 +1146,7 @@ func TestStartThread(t *testing.T) {
 		close(c)
 		return None, nil
 	}).ToObject()
-	StartThread(callable)
+	StartThread(NewRootFrame(), callable)
 	// Deadlock indicates the thread didn't start.
 	<-c
 }
@@ -1036,12 +1160,29 @@ func TestStartThreadRaises(t *testing.T) {
 		defer close(c)
 		return nil, f.RaiseType(ExceptionType, "foo")
 	}).ToObject()
-	StartThread(callable)
+	StartThread(NewRootFrame(), callable)
 	<-c
 }
 
 func TestTie(t *testing.T) {
 	targets := make([]*Object, 3)
+	newCountGenerator := func(n int) *Object {
+		i := 0
+		return NewGenerator(NewRootFrame(), func(*Object) (*Object, *BaseException) {
+			if i >= n {
+				return nil, nil
+			}
+			i++
+			return NewInt(i).ToObject(), nil
+		}).ToObject()
+	}
+	listSubclass := newTestClass("ListSubclass", []*Type{ListType}, newStringDict(map[string]*Object{
+		"__iter__": newBuiltinFunction("__iter__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return Iter(f, newTestTuple("foo", "bar").ToObject())
+		}).ToObject(),
+	}))
+	listSubclassInst := newObject(listSubclass)
+	toListUnsafe(listSubclassInst).Append(NewInt(1).ToObject())
 	cases := []struct {
 		t       TieTarget
 		o       *Object
@@ -1078,13 +1219,49 @@ func TestTie(t *testing.T) {
 			},
 			NewList(NewStr("foo").ToObject()).ToObject(),
 			nil,
-			mustCreateException(ValueErrorType, "need more than 1 values to unpack"),
+			mustCreateException(ValueErrorType, "need more than 1 value to unpack"),
+		},
+		{
+			TieTarget{Children: []TieTarget{{Target: &targets[0]}}},
+			NewTuple(NewInt(1).ToObject(), NewInt(2).ToObject()).ToObject(),
+			nil,
+			mustCreateException(ValueErrorType, "too many values to unpack (expected 1)"),
 		},
 		{
 			TieTarget{Children: []TieTarget{{Target: &targets[0]}}},
+			newTestDict("foo", 1).ToObject(),
+			NewTuple(NewStr("foo").ToObject()).ToObject(),
+			nil,
+		},
+		{
+			TieTarget{Children: []TieTarget{{Target: &targets[0]}, {Target: &targets[1]}}},
+			newCountGenerator(2),
 			NewTuple(NewInt(1).ToObject(), NewInt(2).ToObject()).ToObject(),
 			nil,
-			mustCreateException(ValueErrorType, "too many values to unpack"),
+		},
+		{
+			TieTarget{Children: []TieTarget{{Target: &targets[0]}, {Target: &targets[1]}}},
+			newCountGenerator(0),
+			nil,
+			mustCreateException(ValueErrorType, "need more than 0 values to unpack"),
+		},
+		{
+			TieTarget{Children: []TieTarget{{Target: &targets[0]}, {Target: &targets[1]}}},
+			newCountGenerator(3),
+			nil,
+			mustCreateException(ValueErrorType, "too many values to unpack (expected 2)"),
+		},
+		{
+			TieTarget{Children: []TieTarget{{Target: &targets[0]}, {Target: &targets[1]}}},
+			listSubclassInst,
+			NewTuple(NewStr("foo").ToObject(), NewStr("bar").ToObject()).ToObject(),
+			nil,
+		},
+		{
+			TieTarget{Children: []TieTarget{{Target: &targets[0]}}},
+			NewInt(42).ToObject(),
+			nil,
+			mustCreateException(TypeErrorType, "'int' object is not iterable"),
 		},
 	}
 	for _, cas := range cases {
@@ -0,0 +1,658 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The quoting modes accepted by dialects.
+const (
+	csvQuoteMinimal = iota
+	csvQuoteAll
+	csvQuoteNonNumeric
+	csvQuoteNone
+)
+
+var (
+	// CSVDialectType is the object representing the Python '_csv.Dialect'
+	// type.
+	CSVDialectType = newBasisType("Dialect", reflect.TypeOf(CSVDialect{}), toCSVDialectUnsafe, ObjectType)
+	// CSVReaderType is the object representing the Python '_csv.reader'
+	// type.
+	CSVReaderType = newBasisType("reader", reflect.TypeOf(CSVReader{}), toCSVReaderUnsafe, ObjectType)
+	// CSVWriterType is the object representing the Python '_csv.writer'
+	// type.
+	CSVWriterType = newBasisType("writer", reflect.TypeOf(CSVWriter{}), toCSVWriterUnsafe, ObjectType)
+	// csvDialectParams are the parameters accepted by Dialect() in the
+	// order they may be passed positionally.
+	csvDialectParams = []string{"dialect", "delimiter", "doublequote", "escapechar", "lineterminator", "quotechar", "quoting", "skipinitialspace", "strict"}
+	// csvFieldLimit is the largest field readers will parse. It's shared
+	// by all readers and accessed atomically.
+	csvFieldLimit int64 = 128 * 1024
+)
+
+// CSVDialect represents Python '_csv.Dialect' objects, which hold the
+// settings used by readers and writers. Dialects are immutable. A zero
+// quoteChar or escapeChar means the dialect has none, like in CPython.
+type CSVDialect struct {
+	Object
+	delimiter        byte
+	quoteChar        byte
+	escapeChar       byte
+	doubleQuote      bool   `attr:"doublequote"`
+	skipInitialSpace bool   `attr:"skipinitialspace"`
+	strict           bool   `attr:"strict"`
+	lineTerminator   string `attr:"lineterminator"`
+	quoting          int    `attr:"quoting"`
+}
+
+func toCSVDialectUnsafe(o *Object) *CSVDialect {
+	return (*CSVDialect)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *CSVDialect) ToObject() *Object {
+	return &d.Object
+}
+
+// csvDialectNew implements Dialect([dialect[, delimiter, ...]]). Settings
+// not passed explicitly are taken from the attributes of dialect, which may
+// be any object, and otherwise default to those of the excel dialect.
+func csvDialectNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) > len(csvDialectParams) {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("Dialect() takes at most %d arguments (%d given)", len(csvDialectParams), len(args)))
+	}
+	values := map[string]*Object{}
+	for i, arg := range args {
+		values[csvDialectParams[i]] = arg
+	}
+	for _, kw := range kwargs {
+		valid := false
+		for _, name := range csvDialectParams {
+			if kw.Name == name {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("'%s' is an invalid keyword argument for this function", kw.Name))
+		}
+		if _, ok := values[kw.Name]; ok {
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("Dialect() got multiple values for keyword argument '%s'", kw.Name))
+		}
+		values[kw.Name] = kw.Value
+	}
+	dialect := values["dialect"]
+	if dialect != nil && dialect.isInstance(CSVDialectType) && len(values) == 1 {
+		// Dialects are immutable so reuse the existing instance.
+		return dialect, nil
+	}
+	for _, name := range csvDialectParams[1:] {
+		if _, ok := values[name]; ok || dialect == nil || dialect == None {
+			continue
+		}
+		value, raised := GetAttr(f, dialect, NewStr(name), nil)
+		if raised != nil {
+			if !raised.isInstance(AttributeErrorType) {
+				return nil, raised
+			}
+			f.RestoreExc(nil, nil)
+			continue
+		}
+		values[name] = value
+	}
+	d := &CSVDialect{Object: Object{typ: t}, delimiter: ',', quoteChar: '"', doubleQuote: true, lineTerminator: "\r\n", quoting: csvQuoteMinimal}
+	var raised *BaseException
+	if d.delimiter, raised = csvDialectChar(f, "delimiter", values["delimiter"], d.delimiter); raised != nil {
+		return nil, raised
+	}
+	if d.escapeChar, raised = csvDialectChar(f, "escapechar", values["escapechar"], d.escapeChar); raised != nil {
+		return nil, raised
+	}
+	if d.quoteChar, raised = csvDialectChar(f, "quotechar", values["quotechar"], d.quoteChar); raised != nil {
+		return nil, raised
+	}
+	for _, b := range []struct {
+		name  string
+		field *bool
+	}{{"doublequote", &d.doubleQuote}, {"skipinitialspace", &d.skipInitialSpace}, {"strict", &d.strict}} {
+		if v := values[b.name]; v != nil {
+			if *b.field, raised = IsTrue(f, v); raised != nil {
+				return nil, raised
+			}
+		}
+	}
+	if v := values["lineterminator"]; v == None {
+		d.lineTerminator = ""
+	} else if v != nil {
+		if !v.isInstance(StrType) {
+			return nil, f.RaiseType(TypeErrorType, `"lineterminator" must be a string`)
+		}
+		d.lineTerminator = toStrUnsafe(v).Value()
+	}
+	if v := values["quoting"]; v != nil {
+		if !v.isInstance(IntType) && !v.isInstance(LongType) {
+			return nil, f.RaiseType(TypeErrorType, `"quoting" must be an integer`)
+		}
+		if d.quoting, raised = IndexInt(f, v); raised != nil {
+			return nil, raised
+		}
+	} else if values["quotechar"] == None {
+		d.quoting = csvQuoteNone
+	}
+	if d.quoting < csvQuoteMinimal || d.quoting > csvQuoteNone {
+		return nil, f.RaiseType(TypeErrorType, `bad "quoting" value`)
+	}
+	if d.delimiter == 0 {
+		return nil, f.RaiseType(TypeErrorType, `"delimiter" must be an 1-character string`)
+	}
+	if d.quoteChar == 0 && d.quoting != csvQuoteNone {
+		return nil, f.RaiseType(TypeErrorType, "quotechar must be set if quoting enabled")
+	}
+	if d.lineTerminator == "" {
+		return nil, f.RaiseType(TypeErrorType, "lineterminator must be set")
+	}
+	return d.ToObject(), nil
+}
+
+// csvDialectChar converts the value of the named dialect setting to a
+// character, or returns def if it was not given. None and the empty string
+// unset the character.
+func csvDialectChar(f *Frame, name string, o *Object, def byte) (byte, *BaseException) {
+	if o == nil {
+		return def, nil
+	}
+	if o == None {
+		return 0, nil
+	}
+	if !o.isInstance(StrType) {
+		return 0, f.RaiseType(TypeErrorType, fmt.Sprintf(`"%s" must be string, not %s`, name, o.typ.Name()))
+	}
+	s := toStrUnsafe(o).Value()
+	if len(s) > 1 || len(s) == 0 && name == "delimiter" {
+		return 0, f.RaiseType(TypeErrorType, fmt.Sprintf(`"%s" must be an 1-character string`, name))
+	}
+	if s == "" {
+		return 0, nil
+	}
+	return s[0], nil
+}
+
+// csvDialectCharGetter returns a property reading the character selected by
+// get, or None when it's unset.
+func csvDialectCharGetter(name string, get func(*CSVDialect) byte) *Object {
+	getter := newBuiltinFunction("_get_"+name, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkMethodArgs(f, "_get_"+name, args, CSVDialectType); raised != nil {
+			return nil, raised
+		}
+		c := get(toCSVDialectUnsafe(args[0]))
+		if c == 0 {
+			return None, nil
+		}
+		return NewStr(string([]byte{c})).ToObject(), nil
+	})
+	return newProperty(getter.ToObject(), nil, nil).ToObject()
+}
+
+func initCSVDialectType(dict map[string]*Object) {
+	CSVDialectType.flags &^= typeFlagBasetype
+	dict["__module__"] = NewStr("_csv").ToObject()
+	dict["delimiter"] = csvDialectCharGetter("delimiter", func(d *CSVDialect) byte { return d.delimiter })
+	dict["escapechar"] = csvDialectCharGetter("escapechar", func(d *CSVDialect) byte { return d.escapeChar })
+	dict["quotechar"] = csvDialectCharGetter("quotechar", func(d *CSVDialect) byte { return d.quoteChar })
+	CSVDialectType.slots.New = &newSlot{csvDialectNew}
+}
+
+// CSVFieldSizeLimit returns the largest field readers will parse.
+func CSVFieldSizeLimit() int {
+	return int(atomic.LoadInt64(&csvFieldLimit))
+}
+
+// CSVSetFieldSizeLimit sets the largest field readers will parse to limit and
+// returns the previous limit.
+func CSVSetFieldSizeLimit(limit int) int {
+	return int(atomic.SwapInt64(&csvFieldLimit, int64(limit)))
+}
+
+// csvParserState is the state of the reader's state machine, which mirrors
+// the one in CPython's _csv module.
+type csvParserState int
+
+const (
+	csvStartRecord csvParserState = iota
+	csvStartField
+	csvEscapedChar
+	csvInField
+	csvInQuotedField
+	csvEscapeInQuotedField
+	csvQuoteInQuotedField
+	csvEatCRNL
+)
+
+// csvParser accumulates the fields of a single record from the characters
+// of one or more lines. A zero character marks the end of each line.
+type csvParser struct {
+	dialect      *CSVDialect
+	errorType    *Type
+	state        csvParserState
+	field        []byte
+	fields       []*Object
+	numericField bool
+}
+
+func (p *csvParser) addChar(f *Frame, c byte) *BaseException {
+	if limit := CSVFieldSizeLimit(); len(p.field) >= limit {
+		return f.RaiseType(p.errorType, fmt.Sprintf("field larger than field limit (%d)", limit))
+	}
+	p.field = append(p.field, c)
+	return nil
+}
+
+func (p *csvParser) saveField(f *Frame) *BaseException {
+	field := NewStr(string(p.field)).ToObject()
+	p.field = p.field[:0]
+	if p.numericField {
+		p.numericField = false
+		var raised *BaseException
+		if field, raised = FloatType.Call(f, Args{field}, nil); raised != nil {
+			return raised
+		}
+	}
+	p.fields = append(p.fields, field)
+	return nil
+}
+
+// endField saves the current field when c ends it, moving on to the next
+// record if c ends the line.
+func (p *csvParser) endField(f *Frame, c byte) *BaseException {
+	if c == 0 {
+		p.state = csvStartRecord
+	} else {
+		p.state = csvEatCRNL
+	}
+	return p.saveField(f)
+}
+
+func (p *csvParser) process(f *Frame, c byte) *BaseException {
+	d := p.dialect
+	isQuote := c == d.quoteChar && d.quoting != csvQuoteNone
+	isEscape := c == d.escapeChar && c != 0
+	isEOL := c == '\n' || c == '\r' || c == 0
+	switch p.state {
+	case csvStartRecord:
+		if c == 0 {
+			// An empty line is an empty record.
+			return nil
+		}
+		if c == '\n' || c == '\r' {
+			p.state = csvEatCRNL
+			return nil
+		}
+		p.state = csvStartField
+		return p.process(f, c)
+	case csvStartField:
+		switch {
+		case isEOL:
+			return p.endField(f, c)
+		case isQuote:
+			p.state = csvInQuotedField
+		case isEscape:
+			p.state = csvEscapedChar
+		case c == ' ' && d.skipInitialSpace:
+		case c == d.delimiter:
+			return p.saveField(f)
+		default:
+			if d.quoting == csvQuoteNonNumeric {
+				p.numericField = true
+			}
+			p.state = csvInField
+			return p.addChar(f, c)
+		}
+	case csvEscapedChar:
+		if c == 0 {
+			c = '\n'
+		}
+		p.state = csvInField
+		return p.addChar(f, c)
+	case csvInField:
+		switch {
+		case isEOL:
+			return p.endField(f, c)
+		case isEscape:
+			p.state = csvEscapedChar
+		case c == d.delimiter:
+			p.state = csvStartField
+			return p.saveField(f)
+		default:
+			return p.addChar(f, c)
+		}
+	case csvInQuotedField:
+		switch {
+		case c == 0:
+		case isEscape:
+			p.state = csvEscapeInQuotedField
+		case isQuote:
+			if d.doubleQuote {
+				p.state = csvQuoteInQuotedField
+			} else {
+				p.state = csvInField
+			}
+		default:
+			return p.addChar(f, c)
+		}
+	case csvEscapeInQuotedField:
+		if c == 0 {
+			c = '\n'
+		}
+		p.state = csvInQuotedField
+		return p.addChar(f, c)
+	case csvQuoteInQuotedField:
+		switch {
+		case isQuote:
+			// A doubled quote inside a quoted field.
+			p.state = csvInQuotedField
+			return p.addChar(f, c)
+		case c == d.delimiter:
+			p.state = csvStartField
+			return p.saveField(f)
+		case isEOL:
+			return p.endField(f, c)
+		case !d.strict:
+			p.state = csvInField
+			return p.addChar(f, c)
+		default:
+			return f.RaiseType(p.errorType, fmt.Sprintf("'%c' expected after '%c'", d.delimiter, d.quoteChar))
+		}
+	case csvEatCRNL:
+		switch {
+		case c == '\n' || c == '\r':
+		case c == 0:
+			p.state = csvStartRecord
+		default:
+			return f.RaiseType(p.errorType, "new-line character seen in unquoted field - do you need to open the file in universal-newline mode?")
+		}
+	}
+	return nil
+}
+
+// CSVReader represents Python '_csv.reader' objects, which parse the lines
+// of an iterable into records. Lines are only consumed as records are
+// requested so arbitrarily large inputs are streamed.
+type CSVReader struct {
+	Object
+	mutex     sync.Mutex
+	iter      *Object
+	dialect   *CSVDialect `attr:"dialect"`
+	lineNum   int         `attr:"line_num"`
+	errorType *Type
+}
+
+// NewCSVReader returns a reader parsing the lines yielded by iterable
+// according to dialect. errorType is raised for malformed input.
+func NewCSVReader(f *Frame, iterable *Object, dialect *CSVDialect, errorType *Type) (*CSVReader, *BaseException) {
+	iter, raised := Iter(f, iterable)
+	if raised != nil {
+		if !raised.isInstance(TypeErrorType) {
+			return nil, raised
+		}
+		return nil, f.RaiseType(TypeErrorType, "argument 1 must be an iterator")
+	}
+	return &CSVReader{Object: Object{typ: CSVReaderType}, iter: iter, dialect: dialect, errorType: errorType}, nil
+}
+
+func toCSVReaderUnsafe(o *Object) *CSVReader {
+	return (*CSVReader)(o.toPointer())
+}
+
+// ToObject upcasts r to an Object.
+func (r *CSVReader) ToObject() *Object {
+	return &r.Object
+}
+
+func csvReaderIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func csvReaderNext(f *Frame, o *Object) (*Object, *BaseException) {
+	r := toCSVReaderUnsafe(o)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	p := &csvParser{dialect: r.dialect, errorType: r.errorType}
+	for {
+		line, raised := Next(f, r.iter)
+		if raised != nil {
+			if !raised.isInstance(StopIterationType) || len(p.field) == 0 && p.state != csvInQuotedField {
+				return nil, raised
+			}
+			// The input ended in the middle of a record.
+			if r.dialect.strict {
+				return nil, f.RaiseType(r.errorType, "unexpected end of data")
+			}
+			f.RestoreExc(nil, nil)
+			if raised := p.saveField(f); raised != nil {
+				return nil, raised
+			}
+			break
+		}
+		r.lineNum++
+		var s string
+		switch {
+		case line.isInstance(StrType):
+			s = toStrUnsafe(line).Value()
+		case line.isInstance(UnicodeType):
+			encoded, raised := toUnicodeUnsafe(line).Encode(f, EncodeDefault, EncodeStrict)
+			if raised != nil {
+				return nil, raised
+			}
+			s = encoded.Value()
+		default:
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("expected string or Unicode object, %s found", line.typ.Name()))
+		}
+		for i := 0; i < len(s); i++ {
+			if s[i] == 0 {
+				return nil, f.RaiseType(r.errorType, "line contains NUL")
+			}
+			if raised := p.process(f, s[i]); raised != nil {
+				return nil, raised
+			}
+		}
+		if raised := p.process(f, 0); raised != nil {
+			return nil, raised
+		}
+		if p.state == csvStartRecord {
+			break
+		}
+	}
+	return NewList(p.fields...).ToObject(), nil
+}
+
+func initCSVReaderType(dict map[string]*Object) {
+	CSVReaderType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dict["__module__"] = NewStr("_csv").ToObject()
+	CSVReaderType.slots.Iter = &unaryOpSlot{csvReaderIter}
+	CSVReaderType.slots.Next = &unaryOpSlot{csvReaderNext}
+}
+
+// CSVWriter represents Python '_csv.writer' objects, which format records
+// and pass them to the write method of a file-like object.
+type CSVWriter struct {
+	Object
+	write     *Object
+	dialect   *CSVDialect `attr:"dialect"`
+	errorType *Type
+}
+
+// NewCSVWriter returns a writer formatting records according to dialect and
+// writing them to fileobj. errorType is raised for records that the dialect
+// cannot represent.
+func NewCSVWriter(f *Frame, fileobj *Object, dialect *CSVDialect, errorType *Type) (*CSVWriter, *BaseException) {
+	write, raised := GetAttr(f, fileobj, NewStr("write"), nil)
+	if raised != nil {
+		if !raised.isInstance(AttributeErrorType) {
+			return nil, raised
+		}
+		return nil, f.RaiseType(TypeErrorType, `argument 1 must have a "write" method`)
+	}
+	return &CSVWriter{Object: Object{typ: CSVWriterType}, write: write, dialect: dialect, errorType: errorType}, nil
+}
+
+func toCSVWriterUnsafe(o *Object) *CSVWriter {
+	return (*CSVWriter)(o.toPointer())
+}
+
+// ToObject upcasts w to an Object.
+func (w *CSVWriter) ToObject() *Object {
+	return &w.Object
+}
+
+// appendField appends field to buf, preceded by a delimiter if it's not the
+// first field of the record. Characters special to the dialect are escaped
+// or doubled, and the field is quoted when quoted is set or it requires it.
+// quoteEmpty is set when field is the only field in the record, which must
+// be quoted if it's empty so that the record isn't read back as empty.
+func (w *CSVWriter) appendField(f *Frame, buf *bytes.Buffer, first bool, field string, quoted, quoteEmpty bool) *BaseException {
+	d := w.dialect
+	var data bytes.Buffer
+	for i := 0; i < len(field); i++ {
+		c := field[i]
+		special := c == d.delimiter || c == d.escapeChar && c != 0 || c == d.quoteChar && c != 0 || strings.IndexByte(d.lineTerminator, c) != -1
+		if special {
+			wantEscape := d.quoting == csvQuoteNone
+			if !wantEscape {
+				if c == d.quoteChar {
+					if d.doubleQuote {
+						data.WriteByte(d.quoteChar)
+					} else {
+						wantEscape = true
+					}
+				}
+				if !wantEscape {
+					quoted = true
+				}
+			}
+			if wantEscape {
+				if d.escapeChar == 0 {
+					return f.RaiseType(w.errorType, "need to escape, but no escapechar set")
+				}
+				data.WriteByte(d.escapeChar)
+			}
+		}
+		data.WriteByte(c)
+	}
+	if field == "" && quoteEmpty {
+		if d.quoting == csvQuoteNone {
+			return f.RaiseType(w.errorType, "single empty field record must be quoted")
+		}
+		quoted = true
+	}
+	if !first {
+		buf.WriteByte(d.delimiter)
+	}
+	if quoted {
+		buf.WriteByte(d.quoteChar)
+	}
+	buf.Write(data.Bytes())
+	if quoted {
+		buf.WriteByte(d.quoteChar)
+	}
+	return nil
+}
+
+func (w *CSVWriter) writeRow(f *Frame, seq *Object) (*Object, *BaseException) {
+	if seq.typ.slots.GetItem == nil || seq.isInstance(DictType) {
+		return nil, f.RaiseType(w.errorType, "sequence expected")
+	}
+	var buf bytes.Buffer
+	raised := seqApply(f, seq, func(elems []*Object, _ bool) *BaseException {
+		for i, elem := range elems {
+			quoted := false
+			switch w.dialect.quoting {
+			case csvQuoteNonNumeric:
+				quoted = elem.typ.slots.Int == nil && elem.typ.slots.Float == nil
+			case csvQuoteAll:
+				quoted = true
+			}
+			var field string
+			switch {
+			case elem.isInstance(StrType):
+				field = toStrUnsafe(elem).Value()
+			case elem == None:
+			default:
+				var s *Str
+				var raised *BaseException
+				if elem.isInstance(FloatType) {
+					s, raised = Repr(f, elem)
+				} else {
+					s, raised = ToStr(f, elem)
+				}
+				if raised != nil {
+					return raised
+				}
+				field = s.Value()
+			}
+			if raised := w.appendField(f, &buf, i == 0, field, quoted, len(elems) == 1); raised != nil {
+				return raised
+			}
+		}
+		return nil
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	buf.WriteString(w.dialect.lineTerminator)
+	return w.write.Call(f, Args{NewStr(buf.String()).ToObject()}, nil)
+}
+
+func csvWriterWriteRow(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "writerow", args, CSVWriterType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return toCSVWriterUnsafe(args[0]).writeRow(f, args[1])
+}
+
+func csvWriterWriteRows(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "writerows", args, CSVWriterType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	w := toCSVWriterUnsafe(args[0])
+	iter, raised := Iter(f, args[1])
+	if raised != nil {
+		if !raised.isInstance(TypeErrorType) {
+			return nil, raised
+		}
+		return nil, f.RaiseType(TypeErrorType, "writerows() argument must be iterable")
+	}
+	raised = seqForEach(f, iter, func(row *Object) *BaseException {
+		_, raised := w.writeRow(f, row)
+		return raised
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func initCSVWriterType(dict map[string]*Object) {
+	CSVWriterType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dict["__module__"] = NewStr("_csv").ToObject()
+	dict["writerow"] = newBuiltinFunction("writerow", csvWriterWriteRow).ToObject()
+	dict["writerows"] = newBuiltinFunction("writerows", csvWriterWriteRows).ToObject()
+}
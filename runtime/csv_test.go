@@ -0,0 +1,155 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestCSVDialectNew(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"delimiter": NewStr(";").ToObject(),
+		"quoting":   NewInt(csvQuoteAll).ToObject(),
+	}))
+	fun := wrapFuncForTest(func(f *Frame, args *Tuple, kwargs *Dict) (*Object, *BaseException) {
+		d, raised := Invoke(f, CSVDialectType.ToObject(), args.elems, nil, nil, kwargs.ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		var settings []*Object
+		for _, name := range csvDialectParams[1:] {
+			setting, raised := GetAttr(f, d, NewStr(name), nil)
+			if raised != nil {
+				return nil, raised
+			}
+			settings = append(settings, setting)
+		}
+		return NewTuple(settings...).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewTuple(), NewDict()), want: newTestTuple(",", true, None, "\r\n", `"`, csvQuoteMinimal, false, false).ToObject()},
+		{args: wrapArgs(newTestTuple(newObject(fooType)), NewDict()), want: newTestTuple(";", true, None, "\r\n", `"`, csvQuoteAll, false, false).ToObject()},
+		{args: wrapArgs(newTestTuple(newObject(fooType)), newTestDict("delimiter", "\t", "escapechar", `\`, "strict", 1)), want: newTestTuple("\t", true, `\`, "\r\n", `"`, csvQuoteAll, false, true).ToObject()},
+		{args: wrapArgs(NewTuple(), newTestDict("quotechar", None)), want: newTestTuple(",", true, None, "\r\n", None, csvQuoteNone, false, false).ToObject()},
+		{args: wrapArgs(NewTuple(), newTestDict("delimiter", "ab")), wantExc: mustCreateException(TypeErrorType, `"delimiter" must be an 1-character string`)},
+		{args: wrapArgs(NewTuple(), newTestDict("delimiter", 1)), wantExc: mustCreateException(TypeErrorType, `"delimiter" must be string, not int`)},
+		{args: wrapArgs(NewTuple(), newTestDict("quotechar", None, "quoting", csvQuoteAll)), wantExc: mustCreateException(TypeErrorType, "quotechar must be set if quoting enabled")},
+		{args: wrapArgs(NewTuple(), newTestDict("quoting", "foo")), wantExc: mustCreateException(TypeErrorType, `"quoting" must be an integer`)},
+		{args: wrapArgs(NewTuple(), newTestDict("quoting", 7)), wantExc: mustCreateException(TypeErrorType, `bad "quoting" value`)},
+		{args: wrapArgs(NewTuple(), newTestDict("lineterminator", None)), wantExc: mustCreateException(TypeErrorType, "lineterminator must be set")},
+		{args: wrapArgs(NewTuple(), newTestDict("foo", 1)), wantExc: mustCreateException(TypeErrorType, "'foo' is an invalid keyword argument for this function")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	d := mustNotRaise(CSVDialectType.Call(NewRootFrame(), nil, nil))
+	if got := mustNotRaise(CSVDialectType.Call(NewRootFrame(), Args{d}, nil)); got != d {
+		t.Errorf("Dialect(d) = %v, want %v", got, d)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, lines *List, kwargs *Dict) (*Object, *BaseException) {
+		d, raised := Invoke(f, CSVDialectType.ToObject(), nil, nil, nil, kwargs.ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		r, raised := NewCSVReader(f, lines.ToObject(), toCSVDialectUnsafe(d), ValueErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return ListType.Call(f, Args{r.ToObject()}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestList("a,b\r\n", "", "c"), NewDict()), want: newTestList(newTestList("a", "b"), NewList(), newTestList("c")).ToObject()},
+		{args: wrapArgs(newTestList(`"a""b",c`), NewDict()), want: newTestList(newTestList(`a"b`, "c")).ToObject()},
+		{args: wrapArgs(newTestList("\"a\n", "b\",c\n"), NewDict()), want: newTestList(newTestList("a\nb", "c")).ToObject()},
+		{args: wrapArgs(newTestList(`a,"b`), NewDict()), want: newTestList(newTestList("a", "b")).ToObject()},
+		{args: wrapArgs(newTestList(`a,"b"c`), NewDict()), want: newTestList(newTestList("a", "bc")).ToObject()},
+		{args: wrapArgs(newTestList(" a|'b|c'"), newTestDict("delimiter", "|", "quotechar", "'", "skipinitialspace", true)), want: newTestList(newTestList("a", "b|c")).ToObject()},
+		{args: wrapArgs(newTestList(`a\,b,"c\"d"`), newTestDict("escapechar", `\`)), want: newTestList(newTestList("a,b", `c"d`)).ToObject()},
+		{args: wrapArgs(newTestList(`1,"a",2.5`), newTestDict("quoting", csvQuoteNonNumeric)), want: newTestList(newTestList(1.0, "a", 2.5)).ToObject()},
+		{args: wrapArgs(newTestList(`"a,b"`), newTestDict("quoting", csvQuoteNone)), want: newTestList(newTestList(`"a`, `b"`)).ToObject()},
+		{args: wrapArgs(newTestList(`a,"b"c`), newTestDict("strict", true)), wantExc: mustCreateException(ValueErrorType, `',' expected after '"'`)},
+		{args: wrapArgs(newTestList(`a,"b`), newTestDict("strict", true)), wantExc: mustCreateException(ValueErrorType, "unexpected end of data")},
+		{args: wrapArgs(newTestList("a\rb"), NewDict()), wantExc: mustCreateException(ValueErrorType, "new-line character seen in unquoted field - do you need to open the file in universal-newline mode?")},
+		{args: wrapArgs(newTestList("a\x00"), NewDict()), wantExc: mustCreateException(ValueErrorType, "line contains NUL")},
+		{args: wrapArgs(newTestList(1), NewDict()), wantExc: mustCreateException(TypeErrorType, "expected string or Unicode object, int found")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCSVReaderFieldSizeLimit(t *testing.T) {
+	f := NewRootFrame()
+	old := CSVSetFieldSizeLimit(3)
+	defer CSVSetFieldSizeLimit(old)
+	d := toCSVDialectUnsafe(mustNotRaise(CSVDialectType.Call(f, nil, nil)))
+	r, raised := NewCSVReader(f, newTestList("abcd").ToObject(), d, ValueErrorType)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	want := mustCreateException(ValueErrorType, "field larger than field limit (3)")
+	if _, raised := Next(f, r.ToObject()); !exceptionsAreEquivalent(raised, want) {
+		t.Errorf("next(reader) raised %v, want %v", raised, want)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, rows *List, kwargs *Dict) (*Object, *BaseException) {
+		d, raised := Invoke(f, CSVDialectType.ToObject(), nil, nil, nil, kwargs.ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		sio := NewStringIO("")
+		w, raised := NewCSVWriter(f, sio.ToObject(), toCSVDialectUnsafe(d), ValueErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		writeRows, raised := GetAttr(f, w.ToObject(), NewStr("writerows"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if _, raised := writeRows.Call(f, Args{rows.ToObject()}, nil); raised != nil {
+			return nil, raised
+		}
+		return NewStr(sio.buf.String()).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestList(newTestList("a", "b,c", `d"e`, None, 1, 2.5)), NewDict()), want: NewStr("a,\"b,c\",\"d\"\"e\",,1,2.5\r\n").ToObject()},
+		{args: wrapArgs(newTestList(NewList(), newTestList(""), newTestTuple("", "")), NewDict()), want: NewStr("\r\n\"\"\r\n,\r\n").ToObject()},
+		{args: wrapArgs(newTestList(newTestList("a", 1)), newTestDict("quoting", csvQuoteAll)), want: NewStr("\"a\",\"1\"\r\n").ToObject()},
+		{args: wrapArgs(newTestList(newTestList("a", 1, None)), newTestDict("quoting", csvQuoteNonNumeric)), want: NewStr("\"a\",1,\"\"\r\n").ToObject()},
+		{args: wrapArgs(newTestList(newTestList("a,b", `c"d`, "e\nf")), newTestDict("quoting", csvQuoteNone, "escapechar", `\`)), want: NewStr("a\\,b,c\\\"d,e\\\nf\r\n").ToObject()},
+		{args: wrapArgs(newTestList(newTestList(`a"b`)), newTestDict("doublequote", false, "escapechar", `\`)), want: NewStr("a\\\"b\r\n").ToObject()},
+		{args: wrapArgs(newTestList(newTestList("a", "b c")), newTestDict("delimiter", "\t", "lineterminator", "\n")), want: NewStr("a\tb c\n").ToObject()},
+		{args: wrapArgs(newTestList(1), NewDict()), wantExc: mustCreateException(ValueErrorType, "sequence expected")},
+		{args: wrapArgs(newTestList(newTestList("a,b")), newTestDict("quoting", csvQuoteNone)), wantExc: mustCreateException(ValueErrorType, "need to escape, but no escapechar set")},
+		{args: wrapArgs(newTestList(newTestList("")), newTestDict("quoting", csvQuoteNone)), wantExc: mustCreateException(ValueErrorType, "single empty field record must be quoted")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	if _, raised := NewCSVWriter(NewRootFrame(), NewInt(1).ToObject(), nil, ValueErrorType); raised == nil || !raised.isInstance(TypeErrorType) {
+		t.Errorf("NewCSVWriter(1) raised %v, want TypeError", raised)
+	}
+}
@@ -0,0 +1,2464 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	datetimeMinYear = 1
+	datetimeMaxYear = 9999
+	// datetimeMaxOrdinal is the proleptic Gregorian ordinal of
+	// 9999-12-31.
+	datetimeMaxOrdinal = 3652059
+	timeDeltaMaxDays   = 999999999
+	// Days in 400, 100 and 4 years.
+	datetimeDaysIn400Years = 146097
+	datetimeDaysIn100Years = 36524
+	datetimeDaysIn4Years   = 1461
+)
+
+var (
+	datetimeMonthDays                 = [...]int{0, 31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+	datetimeMonthStartDays            = [...]int{0, 0, 31, 59, 90, 120, 151, 181, 212, 243, 273, 304, 334}
+	datetimeDayNames                  = [...]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+	datetimeFullDayNames              = [...]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	datetimeMonthNames                = [...]string{"", "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	datetimeFullMonthNames            = [...]string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	dateNewParamSpec                  = NewParamSpec("date", []Param{{"year", nil}, {"month", nil}, {"day", nil}}, false, false)
+	dateReplaceParamSpec              = NewParamSpec("replace", []Param{{"self", nil}, {"year", None}, {"month", None}, {"day", None}}, false, false)
+	dateStrftimeParamSpec             = NewParamSpec("strftime", []Param{{"self", nil}, {"format", nil}}, false, false)
+	dateTimeNewParamSpec              = NewParamSpec("datetime", []Param{{"year", nil}, {"month", nil}, {"day", nil}, {"hour", NewInt(0).ToObject()}, {"minute", NewInt(0).ToObject()}, {"second", NewInt(0).ToObject()}, {"microsecond", NewInt(0).ToObject()}, {"tzinfo", None}}, false, false)
+	dateTimeReplaceParamSpec          = NewParamSpec("replace", []Param{{"self", nil}, {"year", None}, {"month", None}, {"day", None}, {"hour", None}, {"minute", None}, {"second", None}, {"microsecond", None}, {"tzinfo", True.ToObject()}}, false, false)
+	dateTimeIsoformatParamSpec        = NewParamSpec("isoformat", []Param{{"self", nil}, {"sep", NewStr("T").ToObject()}}, false, false)
+	dateTimeNowParamSpec              = NewParamSpec("now", []Param{{"cls", nil}, {"tz", None}}, false, false)
+	dateTimeFromTimestampParamSpec    = NewParamSpec("fromtimestamp", []Param{{"cls", nil}, {"timestamp", nil}, {"tz", None}}, false, false)
+	dateTimeCombineParamSpec          = NewParamSpec("combine", []Param{{"cls", nil}, {"date", nil}, {"time", nil}}, false, false)
+	dateTimeAstimezoneParamSpec       = NewParamSpec("astimezone", []Param{{"self", nil}, {"tz", nil}}, false, false)
+	timeNewParamSpec                  = NewParamSpec("time", []Param{{"hour", NewInt(0).ToObject()}, {"minute", NewInt(0).ToObject()}, {"second", NewInt(0).ToObject()}, {"microsecond", NewInt(0).ToObject()}, {"tzinfo", None}}, false, false)
+	timeReplaceParamSpec              = NewParamSpec("replace", []Param{{"self", nil}, {"hour", None}, {"minute", None}, {"second", None}, {"microsecond", None}, {"tzinfo", True.ToObject()}}, false, false)
+	timeIsoformatParamSpec            = NewParamSpec("isoformat", []Param{{"self", nil}}, false, false)
+	dateTimeUTCNowParamSpec           = NewParamSpec("utcnow", []Param{{"cls", nil}}, false, false)
+	dateTimeUTCFromTimestampParamSpec = NewParamSpec("utcfromtimestamp", []Param{{"cls", nil}, {"timestamp", nil}}, false, false)
+	timeDeltaNewParamSpec             = NewParamSpec("timedelta", []Param{{"days", NewInt(0).ToObject()}, {"seconds", NewInt(0).ToObject()}, {"microseconds", NewInt(0).ToObject()}, {"milliseconds", NewInt(0).ToObject()}, {"minutes", NewInt(0).ToObject()}, {"hours", NewInt(0).ToObject()}, {"weeks", NewInt(0).ToObject()}}, false, false)
+)
+
+// TimeDelta represents Python 'datetime.timedelta' objects, durations held
+// as days, seconds and microseconds normalized like CPython's: seconds is in
+// [0, 86400) and microseconds in [0, 1000000).
+type TimeDelta struct {
+	Object
+	days         int `attr:"days"`
+	seconds      int `attr:"seconds"`
+	microseconds int `attr:"microseconds"`
+}
+
+// newTimeDelta returns an instance of t holding the given duration after
+// normalizing it, raising OverflowError if it has too many days.
+func newTimeDelta(f *Frame, t *Type, days, seconds, microseconds int) (*TimeDelta, *BaseException) {
+	seconds, microseconds = datetimeNormalizePair(seconds, microseconds, 1000000)
+	days, seconds = datetimeNormalizePair(days, seconds, 24*3600)
+	if days < -timeDeltaMaxDays || days > timeDeltaMaxDays {
+		format := "days=%d; must have magnitude <= %d"
+		return nil, f.RaiseType(OverflowErrorType, fmt.Sprintf(format, days, timeDeltaMaxDays))
+	}
+	var d *TimeDelta
+	if t == TimeDeltaType {
+		d = &TimeDelta{Object: Object{typ: TimeDeltaType}}
+	} else {
+		d = toTimeDeltaUnsafe(newObject(t))
+	}
+	d.days, d.seconds, d.microseconds = days, seconds, microseconds
+	return d, nil
+}
+
+// newTimeDeltaFromMicroseconds returns an instance of t holding the given
+// number of microseconds.
+func newTimeDeltaFromMicroseconds(f *Frame, t *Type, us *big.Int) (*TimeDelta, *BaseException) {
+	seconds, microseconds, days := new(big.Int), new(big.Int), new(big.Int)
+	longDivAndMod(seconds, microseconds, us, big.NewInt(1000000))
+	longDivAndMod(days, seconds, seconds, big.NewInt(24*3600))
+	if days.CmpAbs(big.NewInt(timeDeltaMaxDays)) > 0 {
+		format := "days=%s; must have magnitude <= %d"
+		return nil, f.RaiseType(OverflowErrorType, fmt.Sprintf(format, days, timeDeltaMaxDays))
+	}
+	return newTimeDelta(f, t, int(days.Int64()), int(seconds.Int64()), int(microseconds.Int64()))
+}
+
+func toTimeDeltaUnsafe(o *Object) *TimeDelta {
+	return (*TimeDelta)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *TimeDelta) ToObject() *Object {
+	return &d.Object
+}
+
+// microsecondsValue returns the total number of microseconds in d.
+func (d *TimeDelta) microsecondsValue() *big.Int {
+	us := big.NewInt(int64(d.days)*24*3600 + int64(d.seconds))
+	us.Mul(us, big.NewInt(1000000))
+	return us.Add(us, big.NewInt(int64(d.microseconds)))
+}
+
+// TimeDeltaType is the object representing the Python 'datetime.timedelta'
+// type.
+var TimeDeltaType = newBasisType("timedelta", reflect.TypeOf(TimeDelta{}), toTimeDeltaUnsafe, ObjectType)
+
+func timeDeltaAbs(f *Frame, o *Object) (*Object, *BaseException) {
+	if toTimeDeltaUnsafe(o).days < 0 {
+		return timeDeltaNeg(f, o)
+	}
+	return timeDeltaPos(f, o)
+}
+
+func timeDeltaAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return timeDeltaAddFactor(f, v, w, 1)
+}
+
+// timeDeltaAddFactor returns v + w*factor when both are timedeltas.
+func timeDeltaAddFactor(f *Frame, v, w *Object, factor int) (*Object, *BaseException) {
+	if !v.isInstance(TimeDeltaType) || !w.isInstance(TimeDeltaType) {
+		return NotImplemented, nil
+	}
+	x, y := toTimeDeltaUnsafe(v), toTimeDeltaUnsafe(w)
+	d, raised := newTimeDelta(f, TimeDeltaType, x.days+y.days*factor, x.seconds+y.seconds*factor, x.microseconds+y.microseconds*factor)
+	if raised != nil {
+		return nil, raised
+	}
+	return d.ToObject(), nil
+}
+
+func timeDeltaCompare(f *Frame, op compareOp, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(TimeDeltaType) {
+		return datetimeCompareOther(f, op, v, w)
+	}
+	x, y := toTimeDeltaUnsafe(v), toTimeDeltaUnsafe(w)
+	c := datetimeCompareFields([]int{x.days, x.seconds, x.microseconds}, []int{y.days, y.seconds, y.microseconds})
+	return convert3wayToObject(op, c), nil
+}
+
+func timeDeltaDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
+	var divisor *big.Int
+	switch {
+	case w.isInstance(IntType):
+		divisor = big.NewInt(int64(toIntUnsafe(w).Value()))
+	case w.isInstance(LongType):
+		divisor = toLongUnsafe(w).Value()
+	default:
+		return NotImplemented, nil
+	}
+	if divisor.Sign() == 0 {
+		return nil, f.RaiseType(ZeroDivisionErrorType, "integer division or modulo by zero")
+	}
+	us := toTimeDeltaUnsafe(v).microsecondsValue()
+	longDiv(us, us, divisor)
+	d, raised := newTimeDeltaFromMicroseconds(f, TimeDeltaType, us)
+	if raised != nil {
+		return nil, raised
+	}
+	return d.ToObject(), nil
+}
+
+func timeDeltaHash(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toTimeDeltaUnsafe(o)
+	h, raised := Hash(f, NewTuple3(NewInt(d.days).ToObject(), NewInt(d.seconds).ToObject(), NewInt(d.microseconds).ToObject()).ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	return h.ToObject(), nil
+}
+
+func timeDeltaMul(f *Frame, v, w *Object) (*Object, *BaseException) {
+	var factor *big.Int
+	switch {
+	case w.isInstance(IntType):
+		factor = big.NewInt(int64(toIntUnsafe(w).Value()))
+	case w.isInstance(LongType):
+		factor = toLongUnsafe(w).Value()
+	default:
+		return NotImplemented, nil
+	}
+	us := toTimeDeltaUnsafe(v).microsecondsValue()
+	d, raised := newTimeDeltaFromMicroseconds(f, TimeDeltaType, us.Mul(us, factor))
+	if raised != nil {
+		return nil, raised
+	}
+	return d.ToObject(), nil
+}
+
+func timeDeltaNeg(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toTimeDeltaUnsafe(o)
+	result, raised := newTimeDelta(f, TimeDeltaType, -d.days, -d.seconds, -d.microseconds)
+	if raised != nil {
+		return nil, raised
+	}
+	return result.ToObject(), nil
+}
+
+func timeDeltaNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [7]*Object
+	if raised := timeDeltaNewParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	// The components in the order CPython accumulates them along with
+	// the number of microseconds in each unit.
+	components := []struct {
+		tag    string
+		num    *Object
+		factor int64
+	}{
+		{"microseconds", validated[2], 1},
+		{"milliseconds", validated[3], 1000},
+		{"seconds", validated[1], 1000000},
+		{"minutes", validated[4], 60 * 1000000},
+		{"hours", validated[5], 3600 * 1000000},
+		{"days", validated[0], 24 * 3600 * 1000000},
+		{"weeks", validated[6], 7 * 24 * 3600 * 1000000},
+	}
+	us := new(big.Int)
+	leftover := 0.0
+	for _, c := range components {
+		if raised := timeDeltaAccum(f, c.tag, us, c.num, c.factor, &leftover); raised != nil {
+			return nil, raised
+		}
+	}
+	if leftover != 0 {
+		// Round to the nearest microsecond, with ties going to the
+		// value that makes the total even.
+		whole := math.Round(leftover)
+		if math.Abs(whole-leftover) == 0.5 {
+			odd := float64(us.Bit(0))
+			whole = 2*math.Round((leftover+odd)*0.5) - odd
+		}
+		us.Add(us, big.NewInt(int64(whole)))
+	}
+	d, raised := newTimeDeltaFromMicroseconds(f, t, us)
+	if raised != nil {
+		return nil, raised
+	}
+	return d.ToObject(), nil
+}
+
+// timeDeltaAccum adds num units of factor microseconds to sofar. The whole
+// microseconds of float values are added exactly and fractions of a
+// microsecond are added to leftover.
+func timeDeltaAccum(f *Frame, tag string, sofar *big.Int, num *Object, factor int64, leftover *float64) *BaseException {
+	switch {
+	case num.isInstance(IntType):
+		x := big.NewInt(int64(toIntUnsafe(num).Value()))
+		sofar.Add(sofar, x.Mul(x, big.NewInt(factor)))
+	case num.isInstance(LongType):
+		x := toLongUnsafe(num).Value()
+		sofar.Add(sofar, x.Mul(x, big.NewInt(factor)))
+	case num.isInstance(FloatType):
+		value := toFloatUnsafe(num).Value()
+		if math.IsInf(value, 0) {
+			return f.RaiseType(OverflowErrorType, "cannot convert float infinity to integer")
+		}
+		if math.IsNaN(value) {
+			return f.RaiseType(ValueErrorType, "cannot convert float NaN to integer")
+		}
+		intPart, fracPart := math.Modf(value)
+		x, _ := big.NewFloat(intPart).Int(nil)
+		sofar.Add(sofar, x.Mul(x, big.NewInt(factor)))
+		if fracPart != 0 {
+			intPart, fracPart = math.Modf(fracPart * float64(factor))
+			x, _ = big.NewFloat(intPart).Int(nil)
+			sofar.Add(sofar, x)
+			*leftover += fracPart
+		}
+	default:
+		format := "unsupported type for timedelta %s component: %s"
+		return f.RaiseType(TypeErrorType, fmt.Sprintf(format, tag, num.typ.Name()))
+	}
+	return nil
+}
+
+func timeDeltaNonZero(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toTimeDeltaUnsafe(o)
+	return GetBool(d.days != 0 || d.seconds != 0 || d.microseconds != 0).ToObject(), nil
+}
+
+func timeDeltaPos(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toTimeDeltaUnsafe(o)
+	result, raised := newTimeDelta(f, TimeDeltaType, d.days, d.seconds, d.microseconds)
+	if raised != nil {
+		return nil, raised
+	}
+	return result.ToObject(), nil
+}
+
+func timeDeltaReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, TimeDeltaType); raised != nil {
+		return nil, raised
+	}
+	d := toTimeDeltaUnsafe(args[0])
+	state := NewTuple3(NewInt(d.days).ToObject(), NewInt(d.seconds).ToObject(), NewInt(d.microseconds).ToObject())
+	return NewTuple2(d.typ.ToObject(), state.ToObject()).ToObject(), nil
+}
+
+func timeDeltaRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toTimeDeltaUnsafe(o)
+	name := datetimeTypeName(d.typ)
+	var s string
+	switch {
+	case d.microseconds != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d)", name, d.days, d.seconds, d.microseconds)
+	case d.seconds != 0:
+		s = fmt.Sprintf("%s(%d, %d)", name, d.days, d.seconds)
+	default:
+		s = fmt.Sprintf("%s(%d)", name, d.days)
+	}
+	return NewStr(s).ToObject(), nil
+}
+
+func timeDeltaStr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toTimeDeltaUnsafe(o)
+	var buf bytes.Buffer
+	if d.days != 0 {
+		plural := "s"
+		if d.days == 1 || d.days == -1 {
+			plural = ""
+		}
+		fmt.Fprintf(&buf, "%d day%s, ", d.days, plural)
+	}
+	fmt.Fprintf(&buf, "%d:%02d:%02d", d.seconds/3600, d.seconds/60%60, d.seconds%60)
+	if d.microseconds != 0 {
+		fmt.Fprintf(&buf, ".%06d", d.microseconds)
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+func timeDeltaSub(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return timeDeltaAddFactor(f, v, w, -1)
+}
+
+func timeDeltaTotalSeconds(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "total_seconds", args, TimeDeltaType); raised != nil {
+		return nil, raised
+	}
+	seconds, _ := new(big.Rat).SetFrac(toTimeDeltaUnsafe(args[0]).microsecondsValue(), big.NewInt(1000000)).Float64()
+	return NewFloat(seconds).ToObject(), nil
+}
+
+func initTimeDeltaType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("datetime").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", timeDeltaReduce).ToObject()
+	dict["total_seconds"] = newBuiltinFunction("total_seconds", timeDeltaTotalSeconds).ToObject()
+	dict["max"] = (&TimeDelta{Object{typ: TimeDeltaType}, timeDeltaMaxDays, 24*3600 - 1, 999999}).ToObject()
+	dict["min"] = (&TimeDelta{Object{typ: TimeDeltaType}, -timeDeltaMaxDays, 0, 0}).ToObject()
+	dict["resolution"] = (&TimeDelta{Object{typ: TimeDeltaType}, 0, 0, 1}).ToObject()
+	TimeDeltaType.slots.Abs = &unaryOpSlot{timeDeltaAbs}
+	TimeDeltaType.slots.Add = &binaryOpSlot{timeDeltaAdd}
+	TimeDeltaType.slots.Div = &binaryOpSlot{timeDeltaDiv}
+	TimeDeltaType.slots.FloorDiv = &binaryOpSlot{timeDeltaDiv}
+	TimeDeltaType.slots.Hash = &unaryOpSlot{timeDeltaHash}
+	TimeDeltaType.slots.Mul = &binaryOpSlot{timeDeltaMul}
+	TimeDeltaType.slots.Neg = &unaryOpSlot{timeDeltaNeg}
+	TimeDeltaType.slots.New = &newSlot{timeDeltaNew}
+	TimeDeltaType.slots.NonZero = &unaryOpSlot{timeDeltaNonZero}
+	TimeDeltaType.slots.Pos = &unaryOpSlot{timeDeltaPos}
+	TimeDeltaType.slots.Repr = &unaryOpSlot{timeDeltaRepr}
+	TimeDeltaType.slots.RMul = &binaryOpSlot{timeDeltaMul}
+	TimeDeltaType.slots.Str = &unaryOpSlot{timeDeltaStr}
+	TimeDeltaType.slots.Sub = &binaryOpSlot{timeDeltaSub}
+	datetimeSetCompareSlots(TimeDeltaType, timeDeltaCompare)
+}
+
+// Date represents Python 'datetime.date' objects.
+type Date struct {
+	Object
+	year  int `attr:"year"`
+	month int `attr:"month"`
+	day   int `attr:"day"`
+}
+
+// newDate returns an instance of t holding the given date, which must be
+// valid.
+func newDate(t *Type, year, month, day int) *Date {
+	var d *Date
+	if t == DateType {
+		d = &Date{Object: Object{typ: DateType}}
+	} else {
+		d = toDateUnsafe(newObject(t))
+	}
+	d.year, d.month, d.day = year, month, day
+	return d
+}
+
+func toDateUnsafe(o *Object) *Date {
+	return (*Date)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *Date) ToObject() *Object {
+	return &d.Object
+}
+
+func (d *Date) fields() datetimeFields {
+	return datetimeFields{year: d.year, month: d.month, day: d.day}
+}
+
+func (d *Date) ordinal() int {
+	return datetimeYMDToOrdinal(d.year, d.month, d.day)
+}
+
+// DateType is the object representing the Python 'datetime.date' type.
+var DateType = newBasisType("date", reflect.TypeOf(Date{}), toDateUnsafe, ObjectType)
+
+func dateAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	if v.isInstance(DateTimeType) || w.isInstance(DateTimeType) || !w.isInstance(TimeDeltaType) {
+		return NotImplemented, nil
+	}
+	return dateAddDays(f, toDateUnsafe(v), toTimeDeltaUnsafe(w).days)
+}
+
+// dateAddDays returns the date that is days after d.
+func dateAddDays(f *Frame, d *Date, days int) (*Object, *BaseException) {
+	year, month, day, ok := datetimeNormalizeDate(d.year, d.month, d.day+days)
+	if !ok {
+		return nil, f.RaiseType(OverflowErrorType, "date value out of range")
+	}
+	return newDate(DateType, year, month, day).ToObject(), nil
+}
+
+func dateCompare(f *Frame, op compareOp, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(DateType) {
+		hook, raised := datetimeHasTimeTuple(f, w)
+		if raised != nil {
+			return nil, raised
+		}
+		if hook {
+			return NotImplemented, nil
+		}
+		return datetimeCompareOther(f, op, v, w)
+	}
+	x, y := toDateUnsafe(v), toDateUnsafe(w)
+	c := datetimeCompareFields([]int{x.year, x.month, x.day}, []int{y.year, y.month, y.day})
+	return convert3wayToObject(op, c), nil
+}
+
+func dateCTime(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "ctime", args, DateType); raised != nil {
+		return nil, raised
+	}
+	return NewStr(datetimeCTime(toDateUnsafe(args[0]).fields())).ToObject(), nil
+}
+
+func dateFromOrdinal(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "fromordinal", args, TypeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	n, raised := datetimeIntArg(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	if n < 1 {
+		return nil, f.RaiseType(ValueErrorType, "ordinal must be >= 1")
+	}
+	year, month, day := datetimeOrdinalToYMD(n)
+	return args[0].Call(f, datetimeIntArgs(year, month, day), nil)
+}
+
+func dateFromTimestamp(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "fromtimestamp", args, TypeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	t, _, raised := datetimeFromTimestamp(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	return args[0].Call(f, datetimeIntArgs(t.Year(), int(t.Month()), t.Day()), nil)
+}
+
+func dateHash(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toDateUnsafe(o)
+	h, raised := Hash(f, NewTuple3(NewInt(d.year).ToObject(), NewInt(d.month).ToObject(), NewInt(d.day).ToObject()).ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	return h.ToObject(), nil
+}
+
+func dateIsoCalendar(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "isocalendar", args, DateType); raised != nil {
+		return nil, raised
+	}
+	d := toDateUnsafe(args[0])
+	year, week, weekday := datetimeISOCalendar(d.year, d.month, d.day)
+	return NewTuple3(NewInt(year).ToObject(), NewInt(week).ToObject(), NewInt(weekday).ToObject()).ToObject(), nil
+}
+
+func dateIsoFormat(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "isoformat", args, DateType); raised != nil {
+		return nil, raised
+	}
+	return dateStr(f, args[0])
+}
+
+func dateIsoWeekday(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "isoweekday", args, DateType); raised != nil {
+		return nil, raised
+	}
+	return NewInt(datetimeWeekday(toDateUnsafe(args[0]).ordinal()) + 1).ToObject(), nil
+}
+
+func dateNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) == 1 && len(kwargs) == 0 && args[0].isInstance(StrType) {
+		// Unpickle the state produced by __reduce__.
+		if s := toStrUnsafe(args[0]).Value(); len(s) == 4 && s[2] >= 1 && s[2] <= 12 {
+			return newDate(t, int(s[0])<<8|int(s[1]), int(s[2]), int(s[3])).ToObject(), nil
+		}
+	}
+	var validated [3]*Object
+	if raised := dateNewParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	var fields datetimeFields
+	for i, p := range []*int{&fields.year, &fields.month, &fields.day} {
+		var raised *BaseException
+		if *p, raised = datetimeIntArg(f, validated[i]); raised != nil {
+			return nil, raised
+		}
+	}
+	if raised := fields.checkDate(f); raised != nil {
+		return nil, raised
+	}
+	return newDate(t, fields.year, fields.month, fields.day).ToObject(), nil
+}
+
+func dateRAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dateAdd(f, v, w)
+}
+
+func dateReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, DateType); raised != nil {
+		return nil, raised
+	}
+	d := toDateUnsafe(args[0])
+	state := NewStr(string([]byte{byte(d.year >> 8), byte(d.year), byte(d.month), byte(d.day)}))
+	return NewTuple2(d.typ.ToObject(), NewTuple1(state.ToObject()).ToObject()).ToObject(), nil
+}
+
+func dateReplace(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [4]*Object
+	if raised := dateReplaceParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "replace", Args{validated[0]}, DateType); raised != nil {
+		return nil, raised
+	}
+	d := toDateUnsafe(validated[0])
+	fields := d.fields()
+	if raised := datetimeReplaceFields(f, validated[1:], &fields.year, &fields.month, &fields.day); raised != nil {
+		return nil, raised
+	}
+	if raised := fields.checkDate(f); raised != nil {
+		return nil, raised
+	}
+	return newDate(d.typ, fields.year, fields.month, fields.day).ToObject(), nil
+}
+
+func dateRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toDateUnsafe(o)
+	return NewStr(fmt.Sprintf("%s(%d, %d, %d)", datetimeTypeName(d.typ), d.year, d.month, d.day)).ToObject(), nil
+}
+
+func dateStr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toDateUnsafe(o)
+	return NewStr(fmt.Sprintf("%04d-%02d-%02d", d.year, d.month, d.day)).ToObject(), nil
+}
+
+func dateStrftime(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dateStrftimeParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "strftime", Args{validated[0]}, DateType); raised != nil {
+		return nil, raised
+	}
+	o := validated[0]
+	fields := toDateUnsafe(o).fields()
+	tzinfo := None
+	if o.isInstance(DateTimeType) {
+		d := toDateTimeUnsafe(o)
+		fields = d.fields()
+		tzinfo = d.tzinfo
+	}
+	if fields.year < 1900 {
+		format := "year=%d is before 1900; the datetime strftime() methods require year >= 1900"
+		return nil, f.RaiseType(ValueErrorType, fmt.Sprintf(format, fields.year))
+	}
+	return datetimeStrftime(f, validated[1], fields, tzinfo, o)
+}
+
+func dateSub(f *Frame, v, w *Object) (*Object, *BaseException) {
+	if v.isInstance(DateTimeType) || w.isInstance(DateTimeType) {
+		return NotImplemented, nil
+	}
+	d := toDateUnsafe(v)
+	switch {
+	case w.isInstance(TimeDeltaType):
+		return dateAddDays(f, d, -toTimeDeltaUnsafe(w).days)
+	case w.isInstance(DateType):
+		delta, raised := newTimeDelta(f, TimeDeltaType, d.ordinal()-toDateUnsafe(w).ordinal(), 0, 0)
+		if raised != nil {
+			return nil, raised
+		}
+		return delta.ToObject(), nil
+	}
+	return NotImplemented, nil
+}
+
+func dateTimeTuple(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "timetuple", args, DateType); raised != nil {
+		return nil, raised
+	}
+	return datetimeStructTime(f, toDateUnsafe(args[0]).fields(), -1)
+}
+
+func dateToday(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "today", args, TypeType); raised != nil {
+		return nil, raised
+	}
+	fromTimestamp, raised := GetAttr(f, args[0], NewStr("fromtimestamp"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	now := float64(time.Now().UnixNano()) / 1e9
+	return fromTimestamp.Call(f, Args{NewFloat(now).ToObject()}, nil)
+}
+
+func dateToOrdinal(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "toordinal", args, DateType); raised != nil {
+		return nil, raised
+	}
+	return NewInt(toDateUnsafe(args[0]).ordinal()).ToObject(), nil
+}
+
+func dateWeekday(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "weekday", args, DateType); raised != nil {
+		return nil, raised
+	}
+	return NewInt(datetimeWeekday(toDateUnsafe(args[0]).ordinal())).ToObject(), nil
+}
+
+func initDateType(dict map[string]*Object) {
+	dict["__format__"] = newBuiltinFunction("__format__", datetimeFormat).ToObject()
+	dict["__module__"] = NewStr("datetime").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", dateReduce).ToObject()
+	dict["ctime"] = newBuiltinFunction("ctime", dateCTime).ToObject()
+	dict["fromordinal"] = newClassMethod(newBuiltinFunction("fromordinal", dateFromOrdinal).ToObject()).ToObject()
+	dict["fromtimestamp"] = newClassMethod(newBuiltinFunction("fromtimestamp", dateFromTimestamp).ToObject()).ToObject()
+	dict["isocalendar"] = newBuiltinFunction("isocalendar", dateIsoCalendar).ToObject()
+	dict["isoformat"] = newBuiltinFunction("isoformat", dateIsoFormat).ToObject()
+	dict["isoweekday"] = newBuiltinFunction("isoweekday", dateIsoWeekday).ToObject()
+	dict["replace"] = newBuiltinFunction("replace", dateReplace).ToObject()
+	dict["strftime"] = newBuiltinFunction("strftime", dateStrftime).ToObject()
+	dict["timetuple"] = newBuiltinFunction("timetuple", dateTimeTuple).ToObject()
+	dict["today"] = newClassMethod(newBuiltinFunction("today", dateToday).ToObject()).ToObject()
+	dict["toordinal"] = newBuiltinFunction("toordinal", dateToOrdinal).ToObject()
+	dict["weekday"] = newBuiltinFunction("weekday", dateWeekday).ToObject()
+	dict["max"] = newDate(DateType, datetimeMaxYear, 12, 31).ToObject()
+	dict["min"] = newDate(DateType, datetimeMinYear, 1, 1).ToObject()
+	dict["resolution"] = (&TimeDelta{Object{typ: TimeDeltaType}, 1, 0, 0}).ToObject()
+	DateType.slots.Add = &binaryOpSlot{dateAdd}
+	DateType.slots.Hash = &unaryOpSlot{dateHash}
+	DateType.slots.New = &newSlot{dateNew}
+	DateType.slots.RAdd = &binaryOpSlot{dateRAdd}
+	DateType.slots.Repr = &unaryOpSlot{dateRepr}
+	DateType.slots.Str = &unaryOpSlot{dateStr}
+	DateType.slots.Sub = &binaryOpSlot{dateSub}
+	datetimeSetCompareSlots(DateType, dateCompare)
+}
+
+// TZInfo represents Python 'datetime.tzinfo' objects. tzinfo is an abstract
+// base class; subclasses implement utcoffset(), dst() and tzname().
+type TZInfo struct {
+	Object
+}
+
+func toTZInfoUnsafe(o *Object) *TZInfo {
+	return (*TZInfo)(o.toPointer())
+}
+
+// ToObject upcasts tz to an Object.
+func (tz *TZInfo) ToObject() *Object {
+	return &tz.Object
+}
+
+// TZInfoType is the object representing the Python 'datetime.tzinfo' type.
+var TZInfoType = newBasisType("tzinfo", reflect.TypeOf(TZInfo{}), toTZInfoUnsafe, ObjectType)
+
+func tzInfoDST(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "dst", args, TZInfoType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return nil, f.RaiseType(NotImplementedErrorType, "a tzinfo subclass must implement dst()")
+}
+
+// tzInfoFromUTC implements the default tzinfo.fromutc(), which converts the
+// UTC time in dt, whose tzinfo must be self, to local time using the
+// utcoffset() and dst() methods of self.
+func tzInfoFromUTC(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "fromutc", args, TZInfoType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if !args[1].isInstance(DateTimeType) {
+		return nil, f.RaiseType(TypeErrorType, "fromutc: argument must be a datetime")
+	}
+	dt := toDateTimeUnsafe(args[1])
+	if dt.tzinfo != args[0] {
+		return nil, f.RaiseType(ValueErrorType, "fromutc: dt.tzinfo is not self")
+	}
+	offset, aware, raised := datetimeTZOffset(f, dt.tzinfo, "utcoffset", dt.ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	if !aware {
+		return nil, f.RaiseType(ValueErrorType, "fromutc: non-None utcoffset() result required")
+	}
+	dst, aware, raised := datetimeTZOffset(f, dt.tzinfo, "dst", dt.ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	if !aware {
+		return nil, f.RaiseType(ValueErrorType, "fromutc: non-None dst() result required")
+	}
+	fields := dt.fields()
+	fields.minute += offset - dst
+	if !fields.normalize() {
+		return nil, f.RaiseType(OverflowErrorType, "date value out of range")
+	}
+	result := newDateTime(DateTimeType, fields, dt.tzinfo)
+	if dst, aware, raised = datetimeTZOffset(f, dt.tzinfo, "dst", result.ToObject()); raised != nil {
+		return nil, raised
+	}
+	if !aware {
+		return nil, f.RaiseType(ValueErrorType, "fromutc: tz.dst() gave inconsistent results; cannot convert")
+	}
+	if dst == 0 {
+		return result.ToObject(), nil
+	}
+	fields.minute += dst
+	if !fields.normalize() {
+		return nil, f.RaiseType(OverflowErrorType, "date value out of range")
+	}
+	return newDateTime(DateTimeType, fields, dt.tzinfo).ToObject(), nil
+}
+
+// tzInfoReduce pickles tzinfo subclasses by calling them with the result of
+// __getinitargs__() when it's defined and restoring the result of
+// __getstate__() or else the instance dict.
+func tzInfoReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, TZInfoType); raised != nil {
+		return nil, raised
+	}
+	o := args[0]
+	initArgs := NewTuple0().ToObject()
+	getInitArgs, raised := datetimeGetAttrIfExists(f, o, "__getinitargs__")
+	if raised != nil {
+		return nil, raised
+	}
+	if getInitArgs != nil {
+		if initArgs, raised = getInitArgs.Call(f, nil, nil); raised != nil {
+			return nil, raised
+		}
+	}
+	state := None
+	getState, raised := datetimeGetAttrIfExists(f, o, "__getstate__")
+	if raised != nil {
+		return nil, raised
+	}
+	if getState != nil {
+		if state, raised = getState.Call(f, nil, nil); raised != nil {
+			return nil, raised
+		}
+	} else if dict := o.Dict(); dict != nil && dict.Len() > 0 {
+		state = dict.ToObject()
+	}
+	if state == None {
+		return NewTuple2(o.typ.ToObject(), initArgs).ToObject(), nil
+	}
+	return NewTuple3(o.typ.ToObject(), initArgs, state).ToObject(), nil
+}
+
+func tzInfoTZName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "tzname", args, TZInfoType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return nil, f.RaiseType(NotImplementedErrorType, "a tzinfo subclass must implement tzname()")
+}
+
+func tzInfoUTCOffset(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "utcoffset", args, TZInfoType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return nil, f.RaiseType(NotImplementedErrorType, "a tzinfo subclass must implement utcoffset()")
+}
+
+func initTZInfoType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("datetime").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", tzInfoReduce).ToObject()
+	dict["dst"] = newBuiltinFunction("dst", tzInfoDST).ToObject()
+	dict["fromutc"] = newBuiltinFunction("fromutc", tzInfoFromUTC).ToObject()
+	dict["tzname"] = newBuiltinFunction("tzname", tzInfoTZName).ToObject()
+	dict["utcoffset"] = newBuiltinFunction("utcoffset", tzInfoUTCOffset).ToObject()
+}
+
+// Time represents Python 'datetime.time' objects, times of day with an
+// optional tzinfo. tzinfo is None for naive times.
+type Time struct {
+	Object
+	hour        int     `attr:"hour"`
+	minute      int     `attr:"minute"`
+	second      int     `attr:"second"`
+	microsecond int     `attr:"microsecond"`
+	tzinfo      *Object `attr:"tzinfo"`
+}
+
+// newTime returns an instance of t holding the given time, which must be
+// valid.
+func newTime(t *Type, hour, minute, second, microsecond int, tzinfo *Object) *Time {
+	var tm *Time
+	if t == TimeType {
+		tm = &Time{Object: Object{typ: TimeType}}
+	} else {
+		tm = toTimeUnsafe(newObject(t))
+	}
+	tm.hour, tm.minute, tm.second, tm.microsecond, tm.tzinfo = hour, minute, second, microsecond, tzinfo
+	return tm
+}
+
+func toTimeUnsafe(o *Object) *Time {
+	return (*Time)(o.toPointer())
+}
+
+// ToObject upcasts t to an Object.
+func (t *Time) ToObject() *Object {
+	return &t.Object
+}
+
+// fields returns t's fields on the date strftime() uses for times.
+func (t *Time) fields() datetimeFields {
+	return datetimeFields{1900, 1, 1, t.hour, t.minute, t.second, t.microsecond}
+}
+
+// TimeType is the object representing the Python 'datetime.time' type.
+var TimeType = newBasisType("time", reflect.TypeOf(Time{}), toTimeUnsafe, ObjectType)
+
+func timeCompare(f *Frame, op compareOp, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(TimeType) {
+		return datetimeCompareOther(f, op, v, w)
+	}
+	x, y := toTimeUnsafe(v), toTimeUnsafe(w)
+	offset1, offset2, aware1, aware2 := 0, 0, false, false
+	if x.tzinfo != y.tzinfo {
+		var raised *BaseException
+		if offset1, aware1, raised = datetimeTZOffset(f, x.tzinfo, "utcoffset", None); raised != nil {
+			return nil, raised
+		}
+		if offset2, aware2, raised = datetimeTZOffset(f, y.tzinfo, "utcoffset", None); raised != nil {
+			return nil, raised
+		}
+	}
+	if aware1 != aware2 {
+		return nil, f.RaiseType(TypeErrorType, "can't compare offset-naive and offset-aware times")
+	}
+	c := datetimeCompareFields(
+		[]int{x.hour*3600 + (x.minute-offset1)*60 + x.second, x.microsecond},
+		[]int{y.hour*3600 + (y.minute-offset2)*60 + y.second, y.microsecond})
+	return convert3wayToObject(op, c), nil
+}
+
+func timeDST(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "dst", args, TimeType); raised != nil {
+		return nil, raised
+	}
+	return datetimeTZOffsetDelta(f, toTimeUnsafe(args[0]).tzinfo, "dst", None)
+}
+
+func timeHash(f *Frame, o *Object) (*Object, *BaseException) {
+	t := toTimeUnsafe(o)
+	offset, _, raised := datetimeTZOffset(f, t.tzinfo, "utcoffset", None)
+	if raised != nil {
+		return nil, raised
+	}
+	seconds := t.hour*3600 + (t.minute-offset)*60 + t.second
+	h, raised := Hash(f, NewTuple2(NewInt(seconds).ToObject(), NewInt(t.microsecond).ToObject()).ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	return h.ToObject(), nil
+}
+
+func timeIsoFormat(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [1]*Object
+	if raised := timeIsoformatParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "isoformat", validated[:], TimeType); raised != nil {
+		return nil, raised
+	}
+	return timeStr(f, validated[0])
+}
+
+func timeNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if argc := len(args); argc >= 1 && argc <= 2 && len(kwargs) == 0 && args[0].isInstance(StrType) {
+		// Unpickle the state produced by __reduce__.
+		if s := toStrUnsafe(args[0]).Value(); len(s) == 6 && s[0] < 24 {
+			tzinfo := None
+			if argc == 2 {
+				tzinfo = args[1]
+				if tzinfo != None && !tzinfo.isInstance(TZInfoType) {
+					return nil, f.RaiseType(TypeErrorType, "bad tzinfo state arg")
+				}
+			}
+			us := int(s[3])<<16 | int(s[4])<<8 | int(s[5])
+			return newTime(t, int(s[0]), int(s[1]), int(s[2]), us, tzinfo).ToObject(), nil
+		}
+	}
+	var validated [5]*Object
+	if raised := timeNewParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	var fields datetimeFields
+	for i, p := range []*int{&fields.hour, &fields.minute, &fields.second, &fields.microsecond} {
+		var raised *BaseException
+		if *p, raised = datetimeIntArg(f, validated[i]); raised != nil {
+			return nil, raised
+		}
+	}
+	if raised := fields.checkTime(f); raised != nil {
+		return nil, raised
+	}
+	tzinfo := validated[4]
+	if raised := datetimeCheckTZInfo(f, tzinfo); raised != nil {
+		return nil, raised
+	}
+	return newTime(t, fields.hour, fields.minute, fields.second, fields.microsecond, tzinfo).ToObject(), nil
+}
+
+func timeNonZero(f *Frame, o *Object) (*Object, *BaseException) {
+	t := toTimeUnsafe(o)
+	if t.second != 0 || t.microsecond != 0 {
+		return True.ToObject(), nil
+	}
+	offset, _, raised := datetimeTZOffset(f, t.tzinfo, "utcoffset", None)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(t.hour*60+t.minute-offset != 0).ToObject(), nil
+}
+
+func timeReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, TimeType); raised != nil {
+		return nil, raised
+	}
+	t := toTimeUnsafe(args[0])
+	state := NewStr(string([]byte{byte(t.hour), byte(t.minute), byte(t.second), byte(t.microsecond >> 16), byte(t.microsecond >> 8), byte(t.microsecond)}))
+	return NewTuple2(t.typ.ToObject(), datetimeState(state, t.tzinfo)).ToObject(), nil
+}
+
+func timeReplace(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [6]*Object
+	if raised := timeReplaceParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "replace", Args{validated[0]}, TimeType); raised != nil {
+		return nil, raised
+	}
+	t := toTimeUnsafe(validated[0])
+	fields := t.fields()
+	if raised := datetimeReplaceFields(f, validated[1:5], &fields.hour, &fields.minute, &fields.second, &fields.microsecond); raised != nil {
+		return nil, raised
+	}
+	if raised := fields.checkTime(f); raised != nil {
+		return nil, raised
+	}
+	tzinfo := validated[5]
+	if tzinfo == True.ToObject() {
+		tzinfo = t.tzinfo
+	} else if raised := datetimeCheckTZInfo(f, tzinfo); raised != nil {
+		return nil, raised
+	}
+	return newTime(t.typ, fields.hour, fields.minute, fields.second, fields.microsecond, tzinfo).ToObject(), nil
+}
+
+func timeRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	t := toTimeUnsafe(o)
+	name := datetimeTypeName(t.typ)
+	var s string
+	switch {
+	case t.microsecond != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d", name, t.hour, t.minute, t.second, t.microsecond)
+	case t.second != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d", name, t.hour, t.minute, t.second)
+	default:
+		s = fmt.Sprintf("%s(%d, %d", name, t.hour, t.minute)
+	}
+	return datetimeReprTZInfo(f, s, t.tzinfo)
+}
+
+func timeStr(f *Frame, o *Object) (*Object, *BaseException) {
+	t := toTimeUnsafe(o)
+	offset, raised := datetimeFormatOffset(f, t.tzinfo, None, ":")
+	if raised != nil {
+		return nil, raised
+	}
+	return NewStr(datetimeFormatTime(t.fields()) + offset).ToObject(), nil
+}
+
+func timeStrftime(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dateStrftimeParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "strftime", Args{validated[0]}, TimeType); raised != nil {
+		return nil, raised
+	}
+	t := toTimeUnsafe(validated[0])
+	return datetimeStrftime(f, validated[1], t.fields(), t.tzinfo, None)
+}
+
+func timeTZName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "tzname", args, TimeType); raised != nil {
+		return nil, raised
+	}
+	return datetimeTZName(f, toTimeUnsafe(args[0]).tzinfo, None)
+}
+
+func timeUTCOffset(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "utcoffset", args, TimeType); raised != nil {
+		return nil, raised
+	}
+	return datetimeTZOffsetDelta(f, toTimeUnsafe(args[0]).tzinfo, "utcoffset", None)
+}
+
+func initTimeType(dict map[string]*Object) {
+	dict["__format__"] = newBuiltinFunction("__format__", datetimeFormat).ToObject()
+	dict["__module__"] = NewStr("datetime").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", timeReduce).ToObject()
+	dict["dst"] = newBuiltinFunction("dst", timeDST).ToObject()
+	dict["isoformat"] = newBuiltinFunction("isoformat", timeIsoFormat).ToObject()
+	dict["replace"] = newBuiltinFunction("replace", timeReplace).ToObject()
+	dict["strftime"] = newBuiltinFunction("strftime", timeStrftime).ToObject()
+	dict["tzname"] = newBuiltinFunction("tzname", timeTZName).ToObject()
+	dict["utcoffset"] = newBuiltinFunction("utcoffset", timeUTCOffset).ToObject()
+	dict["max"] = newTime(TimeType, 23, 59, 59, 999999, None).ToObject()
+	dict["min"] = newTime(TimeType, 0, 0, 0, 0, None).ToObject()
+	dict["resolution"] = (&TimeDelta{Object{typ: TimeDeltaType}, 0, 0, 1}).ToObject()
+	TimeType.slots.Hash = &unaryOpSlot{timeHash}
+	TimeType.slots.New = &newSlot{timeNew}
+	TimeType.slots.NonZero = &unaryOpSlot{timeNonZero}
+	TimeType.slots.Repr = &unaryOpSlot{timeRepr}
+	TimeType.slots.Str = &unaryOpSlot{timeStr}
+	datetimeSetCompareSlots(TimeType, timeCompare)
+}
+
+// DateTime represents Python 'datetime.datetime' objects, a date and a time
+// of day with an optional tzinfo. tzinfo is None for naive datetimes.
+type DateTime struct {
+	Date
+	hour        int     `attr:"hour"`
+	minute      int     `attr:"minute"`
+	second      int     `attr:"second"`
+	microsecond int     `attr:"microsecond"`
+	tzinfo      *Object `attr:"tzinfo"`
+}
+
+// newDateTime returns an instance of t holding the given fields, which must
+// be valid.
+func newDateTime(t *Type, fields datetimeFields, tzinfo *Object) *DateTime {
+	var d *DateTime
+	if t == DateTimeType {
+		d = &DateTime{Date: Date{Object: Object{typ: DateTimeType}}}
+	} else {
+		d = toDateTimeUnsafe(newObject(t))
+	}
+	d.year, d.month, d.day = fields.year, fields.month, fields.day
+	d.hour, d.minute, d.second, d.microsecond = fields.hour, fields.minute, fields.second, fields.microsecond
+	d.tzinfo = tzinfo
+	return d
+}
+
+func toDateTimeUnsafe(o *Object) *DateTime {
+	return (*DateTime)(o.toPointer())
+}
+
+func (d *DateTime) fields() datetimeFields {
+	return datetimeFields{d.year, d.month, d.day, d.hour, d.minute, d.second, d.microsecond}
+}
+
+// DateTimeType is the object representing the Python 'datetime.datetime'
+// type.
+var DateTimeType = newBasisType("datetime", reflect.TypeOf(DateTime{}), toDateTimeUnsafe, DateType)
+
+func dateTimeAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(TimeDeltaType) {
+		return NotImplemented, nil
+	}
+	return dateTimeAddDelta(f, toDateTimeUnsafe(v), toTimeDeltaUnsafe(w), 1)
+}
+
+// dateTimeAddDelta returns d + delta*factor.
+func dateTimeAddDelta(f *Frame, d *DateTime, delta *TimeDelta, factor int) (*Object, *BaseException) {
+	fields := d.fields()
+	fields.day += delta.days * factor
+	fields.second += delta.seconds * factor
+	fields.microsecond += delta.microseconds * factor
+	if !fields.normalize() {
+		return nil, f.RaiseType(OverflowErrorType, "date value out of range")
+	}
+	return newDateTime(DateTimeType, fields, d.tzinfo).ToObject(), nil
+}
+
+func dateTimeAstimezone(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dateTimeAstimezoneParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "astimezone", validated[:], DateTimeType, TZInfoType); raised != nil {
+		return nil, raised
+	}
+	d, tz := toDateTimeUnsafe(validated[0]), validated[1]
+	if d.tzinfo == None {
+		return nil, f.RaiseType(ValueErrorType, "astimezone() cannot be applied to a naive datetime")
+	}
+	if d.tzinfo == tz {
+		return d.ToObject(), nil
+	}
+	offset, aware, raised := datetimeTZOffset(f, d.tzinfo, "utcoffset", d.ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	if !aware {
+		return nil, f.RaiseType(ValueErrorType, "astimezone() cannot be applied to a naive datetime")
+	}
+	fields := d.fields()
+	fields.minute -= offset
+	if !fields.normalize() {
+		return nil, f.RaiseType(OverflowErrorType, "date value out of range")
+	}
+	return datetimeCallTZInfo(f, tz, "fromutc", newDateTime(DateTimeType, fields, tz).ToObject())
+}
+
+func dateTimeCombine(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [3]*Object
+	if raised := dateTimeCombineParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "combine", validated[:], TypeType, DateType, TimeType); raised != nil {
+		return nil, raised
+	}
+	d, t := toDateUnsafe(validated[1]), toTimeUnsafe(validated[2])
+	callArgs := append(datetimeIntArgs(d.year, d.month, d.day, t.hour, t.minute, t.second, t.microsecond), t.tzinfo)
+	return validated[0].Call(f, callArgs, nil)
+}
+
+func dateTimeCompare(f *Frame, op compareOp, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(DateTimeType) {
+		if !w.isInstance(DateType) {
+			hook, raised := datetimeHasTimeTuple(f, w)
+			if raised != nil {
+				return nil, raised
+			}
+			if hook {
+				return NotImplemented, nil
+			}
+		}
+		return datetimeCompareOther(f, op, v, w)
+	}
+	x, y := toDateTimeUnsafe(v), toDateTimeUnsafe(w)
+	offset1, offset2, aware1, aware2 := 0, 0, false, false
+	if x.tzinfo != y.tzinfo {
+		var raised *BaseException
+		if offset1, aware1, raised = datetimeTZOffset(f, x.tzinfo, "utcoffset", v); raised != nil {
+			return nil, raised
+		}
+		if offset2, aware2, raised = datetimeTZOffset(f, y.tzinfo, "utcoffset", w); raised != nil {
+			return nil, raised
+		}
+	}
+	if aware1 != aware2 {
+		return nil, f.RaiseType(TypeErrorType, "can't compare offset-naive and offset-aware datetimes")
+	}
+	c := datetimeCompareFields(
+		[]int{x.ordinal()*24*3600 + x.hour*3600 + (x.minute-offset1)*60 + x.second, x.microsecond},
+		[]int{y.ordinal()*24*3600 + y.hour*3600 + (y.minute-offset2)*60 + y.second, y.microsecond})
+	return convert3wayToObject(op, c), nil
+}
+
+func dateTimeCTime(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "ctime", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	return NewStr(datetimeCTime(toDateTimeUnsafe(args[0]).fields())).ToObject(), nil
+}
+
+func dateTimeDate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "date", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(args[0])
+	return newDate(DateType, d.year, d.month, d.day).ToObject(), nil
+}
+
+func dateTimeDST(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "dst", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	return datetimeTZOffsetDelta(f, toDateTimeUnsafe(args[0]).tzinfo, "dst", args[0])
+}
+
+func dateTimeFromTimestamp(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [3]*Object
+	if raised := dateTimeFromTimestampParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	tz := validated[2]
+	if raised := datetimeCheckTZInfo(f, tz); raised != nil {
+		return nil, raised
+	}
+	t, us, raised := datetimeFromTimestamp(f, validated[1])
+	if raised != nil {
+		return nil, raised
+	}
+	if tz != None {
+		t = t.UTC()
+	}
+	return dateTimeFromGoTime(f, validated[0], t, us, tz)
+}
+
+// dateTimeFromGoTime calls cls with the fields of t and us microseconds. If
+// tz is not None then t must be in UTC and it's converted using
+// tz.fromutc().
+func dateTimeFromGoTime(f *Frame, cls *Object, t time.Time, us int, tz *Object) (*Object, *BaseException) {
+	second := t.Second()
+	if second > 59 {
+		second = 59
+	}
+	callArgs := append(datetimeIntArgs(t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), second, us), tz)
+	result, raised := cls.Call(f, callArgs, nil)
+	if raised != nil || tz == None {
+		return result, raised
+	}
+	return datetimeCallTZInfo(f, tz, "fromutc", result)
+}
+
+func dateTimeHash(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toDateTimeUnsafe(o)
+	offset, _, raised := datetimeTZOffset(f, d.tzinfo, "utcoffset", o)
+	if raised != nil {
+		return nil, raised
+	}
+	seconds := d.ordinal()*24*3600 + d.hour*3600 + (d.minute-offset)*60 + d.second
+	h, raised := Hash(f, NewTuple2(NewInt(seconds).ToObject(), NewInt(d.microsecond).ToObject()).ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	return h.ToObject(), nil
+}
+
+func dateTimeIsoFormat(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dateTimeIsoformatParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "isoformat", Args{validated[0]}, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	sep := validated[1]
+	if !sep.isInstance(StrType) || toStrUnsafe(sep).Value() == "" || len(toStrUnsafe(sep).Value()) > 1 {
+		format := "isoformat() argument 1 must be char, not %s"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, sep.typ.Name()))
+	}
+	return dateTimeFormat(f, toDateTimeUnsafe(validated[0]), toStrUnsafe(sep).Value())
+}
+
+// dateTimeFormat returns d in ISO 8601 format with sep between the date and
+// the time.
+func dateTimeFormat(f *Frame, d *DateTime, sep string) (*Object, *BaseException) {
+	offset, raised := datetimeFormatOffset(f, d.tzinfo, d.ToObject(), ":")
+	if raised != nil {
+		return nil, raised
+	}
+	s := fmt.Sprintf("%04d-%02d-%02d%s%s%s", d.year, d.month, d.day, sep, datetimeFormatTime(d.fields()), offset)
+	return NewStr(s).ToObject(), nil
+}
+
+func dateTimeNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if argc := len(args); argc >= 1 && argc <= 2 && len(kwargs) == 0 && args[0].isInstance(StrType) {
+		// Unpickle the state produced by __reduce__.
+		if s := toStrUnsafe(args[0]).Value(); len(s) == 10 && s[2] >= 1 && s[2] <= 12 {
+			tzinfo := None
+			if argc == 2 {
+				tzinfo = args[1]
+				if tzinfo != None && !tzinfo.isInstance(TZInfoType) {
+					return nil, f.RaiseType(TypeErrorType, "bad tzinfo state arg")
+				}
+			}
+			fields := datetimeFields{int(s[0])<<8 | int(s[1]), int(s[2]), int(s[3]), int(s[4]), int(s[5]), int(s[6]), int(s[7])<<16 | int(s[8])<<8 | int(s[9])}
+			return newDateTime(t, fields, tzinfo).ToObject(), nil
+		}
+	}
+	var validated [8]*Object
+	if raised := dateTimeNewParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	var fields datetimeFields
+	for i, p := range []*int{&fields.year, &fields.month, &fields.day, &fields.hour, &fields.minute, &fields.second, &fields.microsecond} {
+		var raised *BaseException
+		if *p, raised = datetimeIntArg(f, validated[i]); raised != nil {
+			return nil, raised
+		}
+	}
+	if raised := fields.checkDate(f); raised != nil {
+		return nil, raised
+	}
+	if raised := fields.checkTime(f); raised != nil {
+		return nil, raised
+	}
+	tzinfo := validated[7]
+	if raised := datetimeCheckTZInfo(f, tzinfo); raised != nil {
+		return nil, raised
+	}
+	return newDateTime(t, fields, tzinfo).ToObject(), nil
+}
+
+func dateTimeNow(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dateTimeNowParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	tz := validated[1]
+	if raised := datetimeCheckTZInfo(f, tz); raised != nil {
+		return nil, raised
+	}
+	now := time.Now()
+	if tz != None {
+		now = now.UTC()
+	}
+	return dateTimeFromGoTime(f, validated[0], now, now.Nanosecond()/1000, tz)
+}
+
+func dateTimeRAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dateTimeAdd(f, v, w)
+}
+
+func dateTimeReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(args[0])
+	state := NewStr(string([]byte{byte(d.year >> 8), byte(d.year), byte(d.month), byte(d.day), byte(d.hour), byte(d.minute), byte(d.second), byte(d.microsecond >> 16), byte(d.microsecond >> 8), byte(d.microsecond)}))
+	return NewTuple2(d.typ.ToObject(), datetimeState(state, d.tzinfo)).ToObject(), nil
+}
+
+func dateTimeReplace(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [9]*Object
+	if raised := dateTimeReplaceParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "replace", Args{validated[0]}, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(validated[0])
+	fields := d.fields()
+	if raised := datetimeReplaceFields(f, validated[1:8], &fields.year, &fields.month, &fields.day, &fields.hour, &fields.minute, &fields.second, &fields.microsecond); raised != nil {
+		return nil, raised
+	}
+	if raised := fields.checkDate(f); raised != nil {
+		return nil, raised
+	}
+	if raised := fields.checkTime(f); raised != nil {
+		return nil, raised
+	}
+	tzinfo := validated[8]
+	if tzinfo == True.ToObject() {
+		tzinfo = d.tzinfo
+	} else if raised := datetimeCheckTZInfo(f, tzinfo); raised != nil {
+		return nil, raised
+	}
+	return newDateTime(d.typ, fields, tzinfo).ToObject(), nil
+}
+
+func dateTimeRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toDateTimeUnsafe(o)
+	name := datetimeTypeName(d.typ)
+	var s string
+	switch {
+	case d.microsecond != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d, %d, %d, %d", name, d.year, d.month, d.day, d.hour, d.minute, d.second, d.microsecond)
+	case d.second != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d, %d, %d", name, d.year, d.month, d.day, d.hour, d.minute, d.second)
+	default:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d, %d", name, d.year, d.month, d.day, d.hour, d.minute)
+	}
+	return datetimeReprTZInfo(f, s, d.tzinfo)
+}
+
+func dateTimeStr(f *Frame, o *Object) (*Object, *BaseException) {
+	return dateTimeFormat(f, toDateTimeUnsafe(o), " ")
+}
+
+func dateTimeStrptime(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "strptime", args, TypeType, BaseStringType, BaseStringType); raised != nil {
+		return nil, raised
+	}
+	s, raised := basestringToStr(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	format, raised := basestringToStr(f, args[2])
+	if raised != nil {
+		return nil, raised
+	}
+	fields, raised := datetimeStrptime(f, s.Value(), format.Value())
+	if raised != nil {
+		return nil, raised
+	}
+	return args[0].Call(f, datetimeIntArgs(fields.year, fields.month, fields.day, fields.hour, fields.minute, fields.second, fields.microsecond), nil)
+}
+
+func dateTimeSub(f *Frame, v, w *Object) (*Object, *BaseException) {
+	d := toDateTimeUnsafe(v)
+	if w.isInstance(TimeDeltaType) {
+		return dateTimeAddDelta(f, d, toTimeDeltaUnsafe(w), -1)
+	}
+	if !w.isInstance(DateTimeType) {
+		return NotImplemented, nil
+	}
+	other := toDateTimeUnsafe(w)
+	offset1, offset2, aware1, aware2 := 0, 0, false, false
+	if d.tzinfo != other.tzinfo {
+		var raised *BaseException
+		if offset1, aware1, raised = datetimeTZOffset(f, d.tzinfo, "utcoffset", v); raised != nil {
+			return nil, raised
+		}
+		if offset2, aware2, raised = datetimeTZOffset(f, other.tzinfo, "utcoffset", w); raised != nil {
+			return nil, raised
+		}
+	}
+	if aware1 != aware2 {
+		return nil, f.RaiseType(TypeErrorType, "can't subtract offset-naive and offset-aware datetimes")
+	}
+	days := d.ordinal() - other.ordinal()
+	seconds := (d.hour-other.hour)*3600 + (d.minute-other.minute)*60 + d.second - other.second + (offset2-offset1)*60
+	delta, raised := newTimeDelta(f, TimeDeltaType, days, seconds, d.microsecond-other.microsecond)
+	if raised != nil {
+		return nil, raised
+	}
+	return delta.ToObject(), nil
+}
+
+func dateTimeTime(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "time", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(args[0])
+	return newTime(TimeType, d.hour, d.minute, d.second, d.microsecond, None).ToObject(), nil
+}
+
+func dateTimeTimeTuple(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "timetuple", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(args[0])
+	dst, aware, raised := datetimeTZOffset(f, d.tzinfo, "dst", args[0])
+	if raised != nil {
+		return nil, raised
+	}
+	dstFlag := -1
+	if aware {
+		dstFlag = 0
+		if dst != 0 {
+			dstFlag = 1
+		}
+	}
+	return datetimeStructTime(f, d.fields(), dstFlag)
+}
+
+func dateTimeTimeTZ(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "timetz", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(args[0])
+	return newTime(TimeType, d.hour, d.minute, d.second, d.microsecond, d.tzinfo).ToObject(), nil
+}
+
+func dateTimeTZName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "tzname", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	return datetimeTZName(f, toDateTimeUnsafe(args[0]).tzinfo, args[0])
+}
+
+func dateTimeUTCFromTimestamp(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dateTimeUTCFromTimestampParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	t, us, raised := datetimeFromTimestamp(f, validated[1])
+	if raised != nil {
+		return nil, raised
+	}
+	return dateTimeFromGoTime(f, validated[0], t.UTC(), us, None)
+}
+
+func dateTimeUTCNow(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [1]*Object
+	if raised := dateTimeUTCNowParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	now := time.Now().UTC()
+	return dateTimeFromGoTime(f, validated[0], now, now.Nanosecond()/1000, None)
+}
+
+func dateTimeUTCOffset(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "utcoffset", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	return datetimeTZOffsetDelta(f, toDateTimeUnsafe(args[0]).tzinfo, "utcoffset", args[0])
+}
+
+func dateTimeUTCTimeTuple(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "utctimetuple", args, DateTimeType); raised != nil {
+		return nil, raised
+	}
+	d := toDateTimeUnsafe(args[0])
+	offset, _, raised := datetimeTZOffset(f, d.tzinfo, "utcoffset", args[0])
+	if raised != nil {
+		return nil, raised
+	}
+	fields := d.fields()
+	if offset != 0 {
+		// Like CPython, values beyond MINYEAR and MAXYEAR are
+		// returned as is.
+		fields.minute -= offset
+		fields.normalize()
+	}
+	return datetimeStructTime(f, fields, 0)
+}
+
+func initDateTimeType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("datetime").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", dateTimeReduce).ToObject()
+	dict["astimezone"] = newBuiltinFunction("astimezone", dateTimeAstimezone).ToObject()
+	dict["combine"] = newClassMethod(newBuiltinFunction("combine", dateTimeCombine).ToObject()).ToObject()
+	dict["ctime"] = newBuiltinFunction("ctime", dateTimeCTime).ToObject()
+	dict["date"] = newBuiltinFunction("date", dateTimeDate).ToObject()
+	dict["dst"] = newBuiltinFunction("dst", dateTimeDST).ToObject()
+	dict["fromtimestamp"] = newClassMethod(newBuiltinFunction("fromtimestamp", dateTimeFromTimestamp).ToObject()).ToObject()
+	dict["isoformat"] = newBuiltinFunction("isoformat", dateTimeIsoFormat).ToObject()
+	dict["now"] = newClassMethod(newBuiltinFunction("now", dateTimeNow).ToObject()).ToObject()
+	dict["replace"] = newBuiltinFunction("replace", dateTimeReplace).ToObject()
+	dict["strptime"] = newClassMethod(newBuiltinFunction("strptime", dateTimeStrptime).ToObject()).ToObject()
+	dict["time"] = newBuiltinFunction("time", dateTimeTime).ToObject()
+	dict["timetuple"] = newBuiltinFunction("timetuple", dateTimeTimeTuple).ToObject()
+	dict["timetz"] = newBuiltinFunction("timetz", dateTimeTimeTZ).ToObject()
+	dict["tzname"] = newBuiltinFunction("tzname", dateTimeTZName).ToObject()
+	dict["utcfromtimestamp"] = newClassMethod(newBuiltinFunction("utcfromtimestamp", dateTimeUTCFromTimestamp).ToObject()).ToObject()
+	dict["utcnow"] = newClassMethod(newBuiltinFunction("utcnow", dateTimeUTCNow).ToObject()).ToObject()
+	dict["utcoffset"] = newBuiltinFunction("utcoffset", dateTimeUTCOffset).ToObject()
+	dict["utctimetuple"] = newBuiltinFunction("utctimetuple", dateTimeUTCTimeTuple).ToObject()
+	dict["max"] = newDateTime(DateTimeType, datetimeFields{datetimeMaxYear, 12, 31, 23, 59, 59, 999999}, None).ToObject()
+	dict["min"] = newDateTime(DateTimeType, datetimeFields{datetimeMinYear, 1, 1, 0, 0, 0, 0}, None).ToObject()
+	dict["resolution"] = (&TimeDelta{Object{typ: TimeDeltaType}, 0, 0, 1}).ToObject()
+	DateTimeType.slots.Add = &binaryOpSlot{dateTimeAdd}
+	DateTimeType.slots.Hash = &unaryOpSlot{dateTimeHash}
+	DateTimeType.slots.New = &newSlot{dateTimeNew}
+	DateTimeType.slots.RAdd = &binaryOpSlot{dateTimeRAdd}
+	DateTimeType.slots.Repr = &unaryOpSlot{dateTimeRepr}
+	DateTimeType.slots.Str = &unaryOpSlot{dateTimeStr}
+	DateTimeType.slots.Sub = &binaryOpSlot{dateTimeSub}
+	datetimeSetCompareSlots(DateTimeType, dateTimeCompare)
+}
+
+// datetimeFields holds the fields of a date and time of day.
+type datetimeFields struct {
+	year, month, day, hour, minute, second, microsecond int
+}
+
+func (t *datetimeFields) checkDate(f *Frame) *BaseException {
+	if t.year < datetimeMinYear || t.year > datetimeMaxYear {
+		return f.RaiseType(ValueErrorType, "year is out of range")
+	}
+	if t.month < 1 || t.month > 12 {
+		return f.RaiseType(ValueErrorType, "month must be in 1..12")
+	}
+	if t.day < 1 || t.day > datetimeDaysInMonth(t.year, t.month) {
+		return f.RaiseType(ValueErrorType, "day is out of range for month")
+	}
+	return nil
+}
+
+func (t *datetimeFields) checkTime(f *Frame) *BaseException {
+	if t.hour < 0 || t.hour > 23 {
+		return f.RaiseType(ValueErrorType, "hour must be in 0..23")
+	}
+	if t.minute < 0 || t.minute > 59 {
+		return f.RaiseType(ValueErrorType, "minute must be in 0..59")
+	}
+	if t.second < 0 || t.second > 59 {
+		return f.RaiseType(ValueErrorType, "second must be in 0..59")
+	}
+	if t.microsecond < 0 || t.microsecond > 999999 {
+		return f.RaiseType(ValueErrorType, "microsecond must be in 0..999999")
+	}
+	return nil
+}
+
+// normalize carries time fields that are out of range over into the larger
+// units, returning false if the resulting date is out of range.
+func (t *datetimeFields) normalize() bool {
+	t.second, t.microsecond = datetimeNormalizePair(t.second, t.microsecond, 1000000)
+	t.minute, t.second = datetimeNormalizePair(t.minute, t.second, 60)
+	t.hour, t.minute = datetimeNormalizePair(t.hour, t.minute, 60)
+	t.day, t.hour = datetimeNormalizePair(t.day, t.hour, 24)
+	var ok bool
+	t.year, t.month, t.day, ok = datetimeNormalizeDate(t.year, t.month, t.day)
+	return ok
+}
+
+// datetimeCallTZInfo calls tzinfo's method with the given name passing arg.
+func datetimeCallTZInfo(f *Frame, tzinfo *Object, method string, arg *Object) (*Object, *BaseException) {
+	fn, raised := GetAttr(f, tzinfo, NewStr(method), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return fn.Call(f, Args{arg}, nil)
+}
+
+func datetimeCheckTZInfo(f *Frame, tzinfo *Object) *BaseException {
+	if tzinfo != None && !tzinfo.isInstance(TZInfoType) {
+		format := "tzinfo argument must be None or of a tzinfo subclass, not type '%s'"
+		return f.RaiseType(TypeErrorType, fmt.Sprintf(format, tzinfo.typ.Name()))
+	}
+	return nil
+}
+
+// datetimeCompareFields compares the corresponding elements of x and y,
+// returning -1, 0 or 1.
+func datetimeCompareFields(x, y []int) int {
+	for i, v := range x {
+		if v < y[i] {
+			return -1
+		}
+		if v > y[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// datetimeCompareOther compares v with w, an object of an unrelated type.
+// Objects of different types are never equal and ordering them is an error.
+func datetimeCompareOther(f *Frame, op compareOp, v, w *Object) (*Object, *BaseException) {
+	switch op {
+	case compareOpEq:
+		return False.ToObject(), nil
+	case compareOpNE:
+		return True.ToObject(), nil
+	}
+	format := "can't compare %s to %s"
+	return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, datetimeTypeName(v.typ), datetimeTypeName(w.typ)))
+}
+
+// datetimeCTime returns t formatted like C's ctime().
+func datetimeCTime(t datetimeFields) string {
+	weekday := datetimeWeekday(datetimeYMDToOrdinal(t.year, t.month, t.day))
+	return fmt.Sprintf("%s %s %2d %02d:%02d:%02d %04d", datetimeDayNames[weekday], datetimeMonthNames[t.month], t.day, t.hour, t.minute, t.second, t.year)
+}
+
+func datetimeDaysBeforeMonth(year, month int) int {
+	days := datetimeMonthStartDays[month]
+	if month > 2 && datetimeIsLeap(year) {
+		days++
+	}
+	return days
+}
+
+// datetimeDaysBeforeYear returns the number of days before January 1st of
+// year, which must be non-negative.
+func datetimeDaysBeforeYear(year int) int {
+	y := year - 1
+	if y < 0 {
+		// Year 0 can be seen when normalizing dates near MINYEAR.
+		return -366
+	}
+	return y*365 + y/4 - y/100 + y/400
+}
+
+func datetimeDaysInMonth(year, month int) int {
+	if month == 2 && datetimeIsLeap(year) {
+		return 29
+	}
+	return datetimeMonthDays[month]
+}
+
+// datetimeFormat implements __format__ for dates and times, which is
+// str(self) for an empty format and self.strftime(format) otherwise.
+func datetimeFormat(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__format__", args, ObjectType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	self, format := args[0], args[1]
+	switch {
+	case format.isInstance(StrType):
+		if toStrUnsafe(format).Value() == "" {
+			s, raised := ToStr(f, self)
+			if raised != nil {
+				return nil, raised
+			}
+			return s.ToObject(), nil
+		}
+	case format.isInstance(UnicodeType):
+		if len(toUnicodeUnsafe(format).Value()) == 0 {
+			return UnicodeType.ToObject().Call(f, Args{self}, nil)
+		}
+	default:
+		msg := fmt.Sprintf("__format__ expects str or unicode, not %s", format.typ.Name())
+		return nil, f.RaiseType(ValueErrorType, msg)
+	}
+	strftime, raised := GetAttr(f, self, NewStr("strftime"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return strftime.Call(f, Args{format}, nil)
+}
+
+// datetimeFormatOffset returns the result of tzinfo.utcoffset(arg) formatted
+// as +HHsepMM, or the empty string if it's None.
+func datetimeFormatOffset(f *Frame, tzinfo, arg *Object, sep string) (string, *BaseException) {
+	offset, aware, raised := datetimeTZOffset(f, tzinfo, "utcoffset", arg)
+	if raised != nil || !aware {
+		return "", raised
+	}
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d%s%02d", sign, offset/60, sep, offset%60), nil
+}
+
+// datetimeFormatTime returns the time of day of t in ISO 8601 format.
+func datetimeFormatTime(t datetimeFields) string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.hour, t.minute, t.second)
+	if t.microsecond != 0 {
+		s += fmt.Sprintf(".%06d", t.microsecond)
+	}
+	return s
+}
+
+// datetimeFromTimestamp converts the POSIX timestamp o to a local time with
+// whole seconds and the microseconds, rounded like CPython.
+func datetimeFromTimestamp(f *Frame, o *Object) (time.Time, int, *BaseException) {
+	floatSlot := o.typ.slots.Float
+	if floatSlot == nil {
+		return time.Time{}, 0, f.RaiseType(TypeErrorType, "a float is required")
+	}
+	fl, raised := floatConvert(floatSlot, f, o)
+	if raised != nil {
+		return time.Time{}, 0, raised
+	}
+	timestamp := fl.Value()
+	if math.IsNaN(timestamp) || timestamp <= math.MinInt64 || timestamp >= math.MaxInt64 {
+		return time.Time{}, 0, f.RaiseType(ValueErrorType, "timestamp out of range for platform time_t")
+	}
+	seconds := int64(timestamp)
+	us := int(math.Round((timestamp - float64(seconds)) * 1e6))
+	if us < 0 {
+		seconds--
+		us += 1000000
+	}
+	if us == 1000000 {
+		seconds++
+		us = 0
+	}
+	return time.Unix(seconds, 0), us, nil
+}
+
+// datetimeGetAttrIfExists returns the named attribute of o or nil if it
+// doesn't exist.
+func datetimeGetAttrIfExists(f *Frame, o *Object, name string) (*Object, *BaseException) {
+	attr, raised := GetAttr(f, o, NewStr(name), nil)
+	if raised != nil {
+		if raised.isInstance(AttributeErrorType) {
+			f.RestoreExc(nil, nil)
+			return nil, nil
+		}
+		return nil, raised
+	}
+	return attr, nil
+}
+
+// datetimeHasTimeTuple returns true if o has a timetuple attribute, the hook
+// other date-like types use to take over comparisons with dates.
+func datetimeHasTimeTuple(f *Frame, o *Object) (bool, *BaseException) {
+	attr, raised := datetimeGetAttrIfExists(f, o, "timetuple")
+	return attr != nil, raised
+}
+
+// datetimeIntArg converts o to an int the way CPython's datetime module
+// does, rejecting floats.
+func datetimeIntArg(f *Frame, o *Object) (int, *BaseException) {
+	if o.isInstance(FloatType) {
+		return 0, f.RaiseType(TypeErrorType, "integer argument expected, got float")
+	}
+	if !o.isInstance(IntType) && !o.isInstance(LongType) {
+		return 0, f.RaiseType(TypeErrorType, "an integer is required")
+	}
+	return ToIntValue(f, o)
+}
+
+func datetimeIntArgs(values ...int) Args {
+	args := make(Args, len(values))
+	for i, v := range values {
+		args[i] = NewInt(v).ToObject()
+	}
+	return args
+}
+
+func datetimeIsLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// datetimeISOCalendar returns the ISO year, week number and weekday of the
+// given date.
+func datetimeISOCalendar(year, month, day int) (int, int, int) {
+	week1Monday := datetimeISOWeek1Monday(year)
+	today := datetimeYMDToOrdinal(year, month, day)
+	week, weekday := datetimeNormalizePair(0, today-week1Monday, 7)
+	if week < 0 {
+		year--
+		week1Monday = datetimeISOWeek1Monday(year)
+		week, weekday = datetimeNormalizePair(0, today-week1Monday, 7)
+	} else if week >= 52 && today >= datetimeISOWeek1Monday(year+1) {
+		year++
+		week = 0
+	}
+	return year, week + 1, weekday + 1
+}
+
+// datetimeISOWeek1Monday returns the ordinal of the Monday starting the
+// first ISO week of year.
+func datetimeISOWeek1Monday(year int) int {
+	firstDay := datetimeYMDToOrdinal(year, 1, 1)
+	firstWeekday := datetimeWeekday(firstDay)
+	week1Monday := firstDay - firstWeekday
+	if firstWeekday > 3 {
+		week1Monday += 7
+	}
+	return week1Monday
+}
+
+// datetimeNormalizeDate returns the date day-1 days after the first of the
+// given month, returning false if it's out of range.
+func datetimeNormalizeDate(year, month, day int) (int, int, int, bool) {
+	if dim := datetimeDaysInMonth(year, month); day < 1 || day > dim {
+		switch {
+		case day == 0:
+			month--
+			if month > 0 {
+				day = datetimeDaysInMonth(year, month)
+			} else {
+				year--
+				month = 12
+				day = 31
+			}
+		case day == dim+1:
+			month++
+			day = 1
+			if month > 12 {
+				month = 1
+				year++
+			}
+		default:
+			ordinal := datetimeYMDToOrdinal(year, month, 1) + day - 1
+			if ordinal < 1 || ordinal > datetimeMaxOrdinal {
+				return year, month, day, false
+			}
+			year, month, day = datetimeOrdinalToYMD(ordinal)
+		}
+	}
+	return year, month, day, year >= datetimeMinYear && year <= datetimeMaxYear
+}
+
+// datetimeNormalizePair carries lo into hi so that lo is in [0, factor).
+func datetimeNormalizePair(hi, lo, factor int) (int, int) {
+	if lo < 0 || lo >= factor {
+		q := lo / factor
+		lo -= q * factor
+		if lo < 0 {
+			q--
+			lo += factor
+		}
+		hi += q
+	}
+	return hi, lo
+}
+
+// datetimeOrdinalToYMD returns the date with the given proleptic Gregorian
+// ordinal, where January 1st of year 1 is day 1.
+func datetimeOrdinalToYMD(ordinal int) (int, int, int) {
+	n := ordinal - 1
+	n400, n := n/datetimeDaysIn400Years, n%datetimeDaysIn400Years
+	n100, n := n/datetimeDaysIn100Years, n%datetimeDaysIn100Years
+	n4, n := n/datetimeDaysIn4Years, n%datetimeDaysIn4Years
+	n1, n := n/365, n%365
+	year := n400*400 + n100*100 + n4*4 + n1 + 1
+	if n1 == 4 || n100 == 4 {
+		// The last day of a leap year.
+		return year - 1, 12, 31
+	}
+	// The month estimate is either exact or one too large.
+	month := (n + 50) >> 5
+	preceding := datetimeDaysBeforeMonth(year, month)
+	if preceding > n {
+		month--
+		preceding -= datetimeDaysInMonth(year, month)
+	}
+	return year, month, n - preceding + 1
+}
+
+// datetimeReplaceFields sets the fields pointed to by dst to the
+// corresponding values that are not None.
+func datetimeReplaceFields(f *Frame, values []*Object, dst ...*int) *BaseException {
+	for i, v := range values {
+		if v != None {
+			var raised *BaseException
+			if *dst[i], raised = datetimeIntArg(f, v); raised != nil {
+				return raised
+			}
+		}
+	}
+	return nil
+}
+
+// datetimeReprTZInfo completes the repr prefix s of a time or datetime,
+// adding the tzinfo argument when it's not None.
+func datetimeReprTZInfo(f *Frame, s string, tzinfo *Object) (*Object, *BaseException) {
+	if tzinfo != None {
+		r, raised := Repr(f, tzinfo)
+		if raised != nil {
+			return nil, raised
+		}
+		s += ", tzinfo=" + r.Value()
+	}
+	return NewStr(s + ")").ToObject(), nil
+}
+
+// datetimeSetCompareSlots sets the rich comparison slots of t to call
+// compare.
+func datetimeSetCompareSlots(t *Type, compare func(*Frame, compareOp, *Object, *Object) (*Object, *BaseException)) {
+	slot := func(op compareOp) *binaryOpSlot {
+		return &binaryOpSlot{func(f *Frame, v, w *Object) (*Object, *BaseException) {
+			return compare(f, op, v, w)
+		}}
+	}
+	t.slots.Eq = slot(compareOpEq)
+	t.slots.GE = slot(compareOpGE)
+	t.slots.GT = slot(compareOpGT)
+	t.slots.LE = slot(compareOpLE)
+	t.slots.LT = slot(compareOpLT)
+	t.slots.NE = slot(compareOpNE)
+}
+
+// datetimeState returns the arguments that recreate a time or datetime with
+// the pickled state and tzinfo.
+func datetimeState(state *Str, tzinfo *Object) *Object {
+	if tzinfo == None {
+		return NewTuple1(state.ToObject()).ToObject()
+	}
+	return NewTuple2(state.ToObject(), tzinfo).ToObject()
+}
+
+// datetimeStructTime returns t as a time.struct_time.
+func datetimeStructTime(f *Frame, t datetimeFields, dstFlag int) (*Object, *BaseException) {
+	modules, raised := ImportModule(f, "time")
+	if raised != nil {
+		return nil, raised
+	}
+	structTime, raised := GetAttr(f, modules[0], NewStr("struct_time"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	ordinal := datetimeYMDToOrdinal(t.year, t.month, t.day)
+	yday := datetimeDaysBeforeMonth(t.year, t.month) + t.day
+	values := datetimeIntArgs(t.year, t.month, t.day, t.hour, t.minute, t.second, datetimeWeekday(ordinal), yday, dstFlag)
+	return structTime.Call(f, Args{NewTuple(values...).ToObject()}, nil)
+}
+
+// datetimeTypeName returns the name of t as CPython's datetime module
+// reports it, qualified with the module name for the builtin types.
+func datetimeTypeName(t *Type) string {
+	switch t {
+	case DateType, DateTimeType, TimeType, TimeDeltaType, TZInfoType:
+		return "datetime." + t.Name()
+	}
+	return t.Name()
+}
+
+// datetimeTZName returns the result of tzinfo.tzname(arg), which must be a
+// str or None.
+func datetimeTZName(f *Frame, tzinfo, arg *Object) (*Object, *BaseException) {
+	if tzinfo == None {
+		return None, nil
+	}
+	name, raised := datetimeCallTZInfo(f, tzinfo, "tzname", arg)
+	if raised != nil {
+		return nil, raised
+	}
+	if name != None && !name.isInstance(StrType) {
+		format := "tzinfo.tzname() must return None or a string, not '%s'"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, name.typ.Name()))
+	}
+	return name, nil
+}
+
+// datetimeTZOffset calls tzinfo's utcoffset() or dst() method, named by
+// method, with arg and returns the result in minutes. aware is false if
+// tzinfo is None or the method returned None.
+func datetimeTZOffset(f *Frame, tzinfo *Object, method string, arg *Object) (offset int, aware bool, raised *BaseException) {
+	if tzinfo == None {
+		return 0, false, nil
+	}
+	result, raised := datetimeCallTZInfo(f, tzinfo, method, arg)
+	if raised != nil {
+		return 0, false, raised
+	}
+	if result == None {
+		return 0, false, nil
+	}
+	if !result.isInstance(TimeDeltaType) {
+		format := "tzinfo.%s() must return None or timedelta, not '%s'"
+		return 0, false, f.RaiseType(TypeErrorType, fmt.Sprintf(format, method, result.typ.Name()))
+	}
+	d := toTimeDeltaUnsafe(result)
+	if d.days < -1 || d.days > 0 {
+		offset = 24 * 60
+	} else {
+		var seconds int
+		offset, seconds = datetimeNormalizePair(0, d.days*24*3600+d.seconds, 60)
+		if seconds != 0 || d.microseconds != 0 {
+			format := "tzinfo.%s() must return a whole number of minutes"
+			return 0, false, f.RaiseType(ValueErrorType, fmt.Sprintf(format, method))
+		}
+	}
+	if offset < -1439 || offset > 1439 {
+		format := "tzinfo.%s() returned %d; must be in -1439 .. 1439"
+		return 0, false, f.RaiseType(ValueErrorType, fmt.Sprintf(format, method, offset))
+	}
+	return offset, true, nil
+}
+
+// datetimeTZOffsetDelta returns the result of datetimeTZOffset as a
+// timedelta or None.
+func datetimeTZOffsetDelta(f *Frame, tzinfo *Object, method string, arg *Object) (*Object, *BaseException) {
+	offset, aware, raised := datetimeTZOffset(f, tzinfo, method, arg)
+	if raised != nil {
+		return nil, raised
+	}
+	if !aware {
+		return None, nil
+	}
+	d, raised := newTimeDelta(f, TimeDeltaType, 0, offset*60, 0)
+	if raised != nil {
+		return nil, raised
+	}
+	return d.ToObject(), nil
+}
+
+// datetimeWeekday returns the day of the week of ordinal where Monday is 0.
+func datetimeWeekday(ordinal int) int {
+	return (ordinal + 6) % 7
+}
+
+// datetimeYMDToOrdinal returns the proleptic Gregorian ordinal of the given
+// date, where January 1st of year 1 is day 1.
+func datetimeYMDToOrdinal(year, month, day int) int {
+	return datetimeDaysBeforeYear(year) + datetimeDaysBeforeMonth(year, month) + day
+}
+
+// datetimeStrftime formats t according to format like C's strftime() in the
+// C locale. %f is replaced by the microseconds and %z and %Z by the UTC
+// offset and name tzinfo returns for tzinfoArg, or the empty string when
+// tzinfo is None. Unknown directives are copied to the result.
+func datetimeStrftime(f *Frame, formatArg *Object, t datetimeFields, tzinfo, tzinfoArg *Object) (*Object, *BaseException) {
+	if !formatArg.isInstance(BaseStringType) {
+		format := "strftime() argument 1 must be string, not %s"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, formatArg.typ.Name()))
+	}
+	s, raised := basestringToStr(f, formatArg)
+	if raised != nil {
+		return nil, raised
+	}
+	format := s.Value()
+	ordinal := datetimeYMDToOrdinal(t.year, t.month, t.day)
+	weekday := datetimeWeekday(ordinal)
+	yday := datetimeDaysBeforeMonth(t.year, t.month) + t.day - 1
+	hour12 := t.hour % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+	ampm := "AM"
+	if t.hour >= 12 {
+		ampm = "PM"
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i == len(format)-1 {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = format[i]; c {
+		case 'a':
+			buf.WriteString(datetimeDayNames[weekday])
+		case 'A':
+			buf.WriteString(datetimeFullDayNames[weekday])
+		case 'b', 'h':
+			buf.WriteString(datetimeMonthNames[t.month])
+		case 'B':
+			buf.WriteString(datetimeFullMonthNames[t.month])
+		case 'c':
+			fmt.Fprintf(&buf, "%s %s %2d %02d:%02d:%02d %d", datetimeDayNames[weekday], datetimeMonthNames[t.month], t.day, t.hour, t.minute, t.second, t.year)
+		case 'C':
+			fmt.Fprintf(&buf, "%02d", t.year/100)
+		case 'd':
+			fmt.Fprintf(&buf, "%02d", t.day)
+		case 'D', 'x':
+			fmt.Fprintf(&buf, "%02d/%02d/%02d", t.month, t.day, t.year%100)
+		case 'e':
+			fmt.Fprintf(&buf, "%2d", t.day)
+		case 'f':
+			fmt.Fprintf(&buf, "%06d", t.microsecond)
+		case 'F':
+			fmt.Fprintf(&buf, "%d-%02d-%02d", t.year, t.month, t.day)
+		case 'g', 'G', 'V':
+			isoYear, isoWeek, _ := datetimeISOCalendar(t.year, t.month, t.day)
+			switch c {
+			case 'g':
+				fmt.Fprintf(&buf, "%02d", isoYear%100)
+			case 'G':
+				fmt.Fprintf(&buf, "%d", isoYear)
+			default:
+				fmt.Fprintf(&buf, "%02d", isoWeek)
+			}
+		case 'H':
+			fmt.Fprintf(&buf, "%02d", t.hour)
+		case 'I':
+			fmt.Fprintf(&buf, "%02d", hour12)
+		case 'j':
+			fmt.Fprintf(&buf, "%03d", yday+1)
+		case 'k':
+			fmt.Fprintf(&buf, "%2d", t.hour)
+		case 'l':
+			fmt.Fprintf(&buf, "%2d", hour12)
+		case 'm':
+			fmt.Fprintf(&buf, "%02d", t.month)
+		case 'M':
+			fmt.Fprintf(&buf, "%02d", t.minute)
+		case 'n':
+			buf.WriteByte('\n')
+		case 'p':
+			buf.WriteString(ampm)
+		case 'P':
+			buf.WriteString(strings.ToLower(ampm))
+		case 'r':
+			fmt.Fprintf(&buf, "%02d:%02d:%02d %s", hour12, t.minute, t.second, ampm)
+		case 'R':
+			fmt.Fprintf(&buf, "%02d:%02d", t.hour, t.minute)
+		case 's':
+			fmt.Fprintf(&buf, "%d", time.Date(t.year, time.Month(t.month), t.day, t.hour, t.minute, t.second, 0, time.Local).Unix())
+		case 'S':
+			fmt.Fprintf(&buf, "%02d", t.second)
+		case 't':
+			buf.WriteByte('\t')
+		case 'T', 'X':
+			fmt.Fprintf(&buf, "%02d:%02d:%02d", t.hour, t.minute, t.second)
+		case 'u':
+			fmt.Fprintf(&buf, "%d", weekday+1)
+		case 'U':
+			fmt.Fprintf(&buf, "%02d", (yday+7-(weekday+1)%7)/7)
+		case 'w':
+			fmt.Fprintf(&buf, "%d", (weekday+1)%7)
+		case 'W':
+			fmt.Fprintf(&buf, "%02d", (yday+7-weekday)/7)
+		case 'y':
+			fmt.Fprintf(&buf, "%02d", t.year%100)
+		case 'Y':
+			fmt.Fprintf(&buf, "%d", t.year)
+		case 'z':
+			offset, raised := datetimeFormatOffset(f, tzinfo, tzinfoArg, "")
+			if raised != nil {
+				return nil, raised
+			}
+			buf.WriteString(offset)
+		case 'Z':
+			name, raised := datetimeTZName(f, tzinfo, tzinfoArg)
+			if raised != nil {
+				return nil, raised
+			}
+			if name != None {
+				buf.WriteString(toStrUnsafe(name).Value())
+			}
+		case '%':
+			buf.WriteByte('%')
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(c)
+		}
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+// datetimeStrptimeDirectives maps the directives supported by strptime() to
+// the regexps that match them in the C locale. Directives that expand to
+// other directives are handled by datetimeStrptimePattern.
+var datetimeStrptimeDirectives = map[byte]string{
+	'a': `(?P<a>` + strings.Join(datetimeDayNames[:], "|") + `)`,
+	'A': `(?P<A>` + strings.Join(datetimeFullDayNames[:], "|") + `)`,
+	'b': `(?P<b>` + strings.Join(datetimeMonthNames[1:], "|") + `)`,
+	'B': `(?P<B>` + strings.Join(datetimeFullMonthNames[1:], "|") + `)`,
+	'd': `(?P<d>3[0-1]|[1-2]\d|0[1-9]|[1-9]| [1-9])`,
+	'f': `(?P<f>[0-9]{1,6})`,
+	'H': `(?P<H>2[0-3]|[0-1]\d|\d)`,
+	'I': `(?P<I>1[0-2]|0[1-9]|[1-9])`,
+	'j': `(?P<j>36[0-6]|3[0-5]\d|[1-2]\d\d|0[1-9]\d|00[1-9]|[1-9]\d|0[1-9]|[1-9])`,
+	'm': `(?P<m>1[0-2]|0[1-9]|[1-9])`,
+	'M': `(?P<M>[0-5]\d|\d)`,
+	'p': `(?P<p>AM|PM)`,
+	'S': `(?P<S>6[0-1]|[0-5]\d|\d)`,
+	'U': `(?P<U>5[0-3]|[0-4]\d|\d)`,
+	'w': `(?P<w>[0-6])`,
+	'W': `(?P<W>5[0-3]|[0-4]\d|\d)`,
+	'y': `(?P<y>\d\d)`,
+	'Y': `(?P<Y>\d\d\d\d)`,
+	'%': `%`,
+}
+
+// datetimeStrptimeExpansions maps the directives that stand for a
+// combination of others to the format they're equivalent to in the C locale.
+var datetimeStrptimeExpansions = map[byte]string{
+	'c': "%a %b %d %H:%M:%S %Y",
+	'x': "%m/%d/%y",
+	'X': "%H:%M:%S",
+}
+
+var datetimeWhitespaceRegexp = regexp.MustCompile(`\s+`)
+
+// datetimeStrptimePattern returns the regexp that matches format or the
+// directive that is not supported, or "%" if format ends in a stray %.
+func datetimeStrptimePattern(format string) (string, string) {
+	var buf bytes.Buffer
+	for len(format) > 0 {
+		i := strings.IndexByte(format, '%')
+		if i == -1 {
+			i = len(format)
+		}
+		literals := datetimeWhitespaceRegexp.Split(format[:i], -1)
+		for j, literal := range literals {
+			if j > 0 {
+				buf.WriteString(`\s+`)
+			}
+			buf.WriteString(regexp.QuoteMeta(literal))
+		}
+		if i == len(format) {
+			break
+		}
+		if i+1 == len(format) {
+			return "", "%"
+		}
+		c := format[i+1]
+		if expansion, ok := datetimeStrptimeExpansions[c]; ok {
+			pattern, bad := datetimeStrptimePattern(expansion)
+			if bad != "" {
+				return "", bad
+			}
+			buf.WriteString(pattern)
+		} else if pattern, ok := datetimeStrptimeDirectives[c]; ok {
+			buf.WriteString(pattern)
+		} else if c == 'Z' {
+			buf.WriteString(datetimeStrptimeZonePattern())
+		} else {
+			return "", string(c)
+		}
+		format = format[i+2:]
+	}
+	return buf.String(), ""
+}
+
+// datetimeStrptimeZonePattern returns the regexp matching the time zone
+// names strptime() accepts: UTC, GMT and the names of the local time zone.
+func datetimeStrptimeZonePattern() string {
+	names := []string{"UTC", "GMT"}
+	year := time.Now().Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		name, _ := time.Date(year, month, 1, 0, 0, 0, 0, time.Local).Zone()
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	return `(?P<Z>` + strings.Join(names, "|") + `)`
+}
+
+// datetimeStrptime parses s according to format the way Python 2.7's
+// _strptime module does in the C locale, returning the date and time it
+// holds.
+func datetimeStrptime(f *Frame, s, format string) (datetimeFields, *BaseException) {
+	fields := datetimeFields{year: 1900, month: 1, day: 1}
+	pattern, bad := datetimeStrptimePattern(format)
+	if bad != "" {
+		if bad == "%" && strings.HasSuffix(format, "%") && !strings.HasSuffix(format, "%%") {
+			return fields, f.RaiseType(ValueErrorType, fmt.Sprintf("stray %% in format '%s'", format))
+		}
+		if strings.TrimSpace(bad) == "" {
+			bad = "%"
+		}
+		return fields, f.RaiseType(ValueErrorType, fmt.Sprintf("'%s' is a bad directive in format '%s'", bad, format))
+	}
+	re := compiledRegexps.mustCompile(`(?i)^(?:` + pattern + `)`)
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		sRepr, raised := Repr(f, NewStr(s).ToObject())
+		if raised != nil {
+			return fields, raised
+		}
+		formatRepr, raised := Repr(f, NewStr(format).ToObject())
+		if raised != nil {
+			return fields, raised
+		}
+		return fields, f.RaiseType(ValueErrorType, fmt.Sprintf("time data %s does not match format %s", sRepr.Value(), formatRepr.Value()))
+	}
+	if len(match[0]) != len(s) {
+		return fields, f.RaiseType(ValueErrorType, fmt.Sprintf("unconverted data remains: %s", s[len(match[0]):]))
+	}
+	groups := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(strings.TrimSpace(s))
+		return n
+	}
+	indexOf := func(names []string, s string) int {
+		for i, name := range names {
+			if strings.EqualFold(name, s) {
+				return i
+			}
+		}
+		return -1
+	}
+	weekday, julian, weekOfYear := -1, -1, -1
+	weekStartsMonday := false
+	for name, value := range groups {
+		switch name {
+		case "y":
+			fields.year = atoi(value)
+			// Two digit years before 69 are in the 21st century.
+			if fields.year <= 68 {
+				fields.year += 2000
+			} else {
+				fields.year += 1900
+			}
+		case "Y":
+			fields.year = atoi(value)
+		case "m":
+			fields.month = atoi(value)
+		case "B":
+			fields.month = indexOf(datetimeFullMonthNames[:], value)
+		case "b":
+			fields.month = indexOf(datetimeMonthNames[:], value)
+		case "d":
+			fields.day = atoi(value)
+		case "H":
+			fields.hour = atoi(value)
+		case "I":
+			fields.hour = atoi(value)
+			if strings.EqualFold(groups["p"], "PM") {
+				if fields.hour != 12 {
+					fields.hour += 12
+				}
+			} else if fields.hour == 12 {
+				fields.hour = 0
+			}
+		case "M":
+			fields.minute = atoi(value)
+		case "S":
+			fields.second = atoi(value)
+		case "f":
+			fields.microsecond = atoi(value + strings.Repeat("0", 6-len(value)))
+		case "A":
+			weekday = indexOf(datetimeFullDayNames[:], value)
+		case "a":
+			weekday = indexOf(datetimeDayNames[:], value)
+		case "w":
+			weekday = (atoi(value) + 6) % 7
+		case "j":
+			julian = atoi(value)
+		case "U", "W":
+			weekOfYear = atoi(value)
+			weekStartsMonday = name == "W"
+		}
+	}
+	if julian == -1 && weekOfYear != -1 && weekday != -1 {
+		julian = datetimeJulianFromWeek(fields.year, weekOfYear, weekday, weekStartsMonday)
+		if julian <= 0 {
+			fields.year--
+			julian += 365
+			if datetimeIsLeap(fields.year) {
+				julian++
+			}
+		}
+	}
+	if julian != -1 {
+		ordinal := julian - 1 + datetimeYMDToOrdinal(fields.year, 1, 1)
+		if ordinal < 1 || ordinal > datetimeMaxOrdinal {
+			return fields, f.RaiseType(ValueErrorType, "year is out of range")
+		}
+		fields.year, fields.month, fields.day = datetimeOrdinalToYMD(ordinal)
+	}
+	return fields, nil
+}
+
+// datetimeJulianFromWeek returns the day of the year of the given weekday,
+// where Monday is 0, in week weekOfYear as numbered by %U or by %W when
+// weekStartsMonday is true.
+func datetimeJulianFromWeek(year, weekOfYear, weekday int, weekStartsMonday bool) int {
+	firstWeekday := datetimeWeekday(datetimeYMDToOrdinal(year, 1, 1))
+	if !weekStartsMonday {
+		firstWeekday = (firstWeekday + 1) % 7
+		weekday = (weekday + 1) % 7
+	}
+	if weekOfYear == 0 {
+		return 1 + weekday - firstWeekday
+	}
+	week0Length := (7 - firstWeekday) % 7
+	return 1 + week0Length + 7*(weekOfYear-1) + weekday
+}
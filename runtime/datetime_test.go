@@ -0,0 +1,253 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func newTestTimeDelta(days, seconds, microseconds int) *Object {
+	return mustNotRaise(TimeDeltaType.Call(NewRootFrame(), wrapArgs(days, seconds, microseconds), nil))
+}
+
+func newTestDateTime(year, month, day, hour, minute, second, microsecond int, tzinfo *Object) *Object {
+	fields := datetimeFields{year, month, day, hour, minute, second, microsecond}
+	return newDateTime(DateTimeType, fields, tzinfo).ToObject()
+}
+
+// newTestFixedOffset returns a tzinfo whose utcoffset() is offset minutes
+// and whose dst() is zero.
+func newTestFixedOffset(name string, offset int) *Object {
+	delta := newTestTimeDelta(0, offset*60, 0)
+	returnDelta := newBuiltinFunction("utcoffset", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+		return delta, nil
+	}).ToObject()
+	zero := newBuiltinFunction("dst", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+		return newTestTimeDelta(0, 0, 0), nil
+	}).ToObject()
+	tzname := newBuiltinFunction("tzname", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+		return NewStr(name).ToObject(), nil
+	}).ToObject()
+	fixedOffsetType := newTestClass("FixedOffset", []*Type{TZInfoType}, newStringDict(map[string]*Object{
+		"dst":       zero,
+		"tzname":    tzname,
+		"utcoffset": returnDelta,
+	}))
+	return newObject(fixedOffsetType)
+}
+
+func TestDatetimeOrdinal(t *testing.T) {
+	cases := []struct {
+		year, month, day int
+		ordinal          int
+	}{
+		{1, 1, 1, 1},
+		{1, 12, 31, 365},
+		{4, 12, 31, 1461},
+		{1970, 1, 1, 719163},
+		{2000, 2, 29, 730179},
+		{2000, 12, 31, 730485},
+		{9999, 12, 31, datetimeMaxOrdinal},
+	}
+	for _, cas := range cases {
+		if got := datetimeYMDToOrdinal(cas.year, cas.month, cas.day); got != cas.ordinal {
+			t.Errorf("datetimeYMDToOrdinal(%d, %d, %d) = %d, want %d", cas.year, cas.month, cas.day, got, cas.ordinal)
+		}
+		year, month, day := datetimeOrdinalToYMD(cas.ordinal)
+		if year != cas.year || month != cas.month || day != cas.day {
+			t.Errorf("datetimeOrdinalToYMD(%d) = (%d, %d, %d), want (%d, %d, %d)", cas.ordinal, year, month, day, cas.year, cas.month, cas.day)
+		}
+	}
+}
+
+func TestTimeDeltaNew(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(TimeDeltaType), want: newTestTimeDelta(0, 0, 0)},
+		{args: wrapArgs(TimeDeltaType, 1, 86401, 1000001), want: newTestTimeDelta(2, 2, 1)},
+		{args: wrapArgs(TimeDeltaType, 0, -1), want: newTestTimeDelta(-1, 86399, 0)},
+		{args: wrapArgs(TimeDeltaType), kwargs: wrapKWArgs("weeks", 1, "hours", 1, "minutes", 1, "milliseconds", 1), want: newTestTimeDelta(7, 3660, 1000)},
+		{args: wrapArgs(TimeDeltaType, 0.5), want: newTestTimeDelta(0, 43200, 0)},
+		{args: wrapArgs(TimeDeltaType, 0, 0, 1.5), want: newTestTimeDelta(0, 0, 2)},
+		{args: wrapArgs(TimeDeltaType, 0, 0, 2.5), want: newTestTimeDelta(0, 0, 2)},
+		{args: wrapArgs(TimeDeltaType, 1000000000), wantExc: mustCreateException(OverflowErrorType, "days=1000000000; must have magnitude <= 999999999")},
+		{args: wrapArgs(TimeDeltaType, "1"), wantExc: mustCreateException(TypeErrorType, "unsupported type for timedelta days component: str")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(TimeDeltaType, "__new__", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTimeDeltaOps(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, fn func(*Frame, *Object, *Object) (*Object, *BaseException), v, w *Object) (*Object, *BaseException) {
+		return fn(f, v, w)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(Add, newTestTimeDelta(1, 2, 3), newTestTimeDelta(1, 86399, 999999)), want: newTestTimeDelta(3, 2, 2)},
+		{args: wrapArgs(Sub, newTestTimeDelta(0, 0, 0), newTestTimeDelta(0, 0, 1)), want: newTestTimeDelta(-1, 86399, 999999)},
+		{args: wrapArgs(Mul, newTestTimeDelta(0, 1, 0), 3), want: newTestTimeDelta(0, 3, 0)},
+		{args: wrapArgs(Mul, 2, newTestTimeDelta(1, 0, 0)), want: newTestTimeDelta(2, 0, 0)},
+		{args: wrapArgs(Div, newTestTimeDelta(0, 0, -1), 2), want: newTestTimeDelta(0, 0, -1)},
+		{args: wrapArgs(FloorDiv, newTestTimeDelta(1, 0, 0), 86400), want: newTestTimeDelta(0, 1, 0)},
+		{args: wrapArgs(Div, newTestTimeDelta(1, 0, 0), 0), wantExc: mustCreateException(ZeroDivisionErrorType, "integer division or modulo by zero")},
+		{args: wrapArgs(Add, newTestTimeDelta(1, 0, 0), 1), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for +: 'timedelta' and 'int'")},
+		{args: wrapArgs(LT, newTestTimeDelta(0, 1, 0), newTestTimeDelta(0, 0, 2)), want: False.ToObject()},
+		{args: wrapArgs(Eq, newTestTimeDelta(0, 1, 0), 1), want: False.ToObject()},
+		{args: wrapArgs(LT, newTestTimeDelta(0, 1, 0), 1), wantExc: mustCreateException(TypeErrorType, "can't compare datetime.timedelta to int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTimeDeltaStrRepr(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object) (*Tuple, *BaseException) {
+		s, raised := ToStr(f, o)
+		if raised != nil {
+			return nil, raised
+		}
+		r, raised := Repr(f, o)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(s.ToObject(), r.ToObject()), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTimeDelta(0, 0, 0)), want: newTestTuple("0:00:00", "datetime.timedelta(0)").ToObject()},
+		{args: wrapArgs(newTestTimeDelta(1, 3661, 0)), want: newTestTuple("1 day, 1:01:01", "datetime.timedelta(1, 3661)").ToObject()},
+		{args: wrapArgs(newTestTimeDelta(-2, 0, 5)), want: newTestTuple("-2 days, 0:00:00.000005", "datetime.timedelta(-2, 0, 5)").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDateNew(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(DateType, 2016, 2, 29), want: newDate(DateType, 2016, 2, 29).ToObject()},
+		{args: wrapArgs(DateType, "\x07\xe0\x02\x1d"), want: newDate(DateType, 2016, 2, 29).ToObject()},
+		{args: wrapArgs(DateType, 2015, 2, 29), wantExc: mustCreateException(ValueErrorType, "day is out of range for month")},
+		{args: wrapArgs(DateType, 0, 1, 1), wantExc: mustCreateException(ValueErrorType, "year is out of range")},
+		{args: wrapArgs(DateType, 2016, 13, 1), wantExc: mustCreateException(ValueErrorType, "month must be in 1..12")},
+		{args: wrapArgs(DateType, 2016.0, 1, 1), wantExc: mustCreateException(TypeErrorType, "integer argument expected, got float")},
+		{args: wrapArgs(DateType, 2016, 1), wantExc: mustCreateException(TypeErrorType, "date() takes at least 3 arguments (2 given)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(DateType, "__new__", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, fn func(*Frame, *Object, *Object) (*Object, *BaseException), v, w *Object) (*Object, *BaseException) {
+		return fn(f, v, w)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(Add, newDate(DateType, 2016, 2, 28), newTestTimeDelta(1, 0, 0)), want: newDate(DateType, 2016, 2, 29).ToObject()},
+		{args: wrapArgs(Add, newTestTimeDelta(366, 0, 0), newDate(DateType, 2016, 1, 1)), want: newDate(DateType, 2017, 1, 1).ToObject()},
+		{args: wrapArgs(Sub, newDate(DateType, 2016, 3, 1), newTestTimeDelta(1, 0, 0)), want: newDate(DateType, 2016, 2, 29).ToObject()},
+		{args: wrapArgs(Sub, newDate(DateType, 2017, 1, 1), newDate(DateType, 2016, 1, 1)), want: newTestTimeDelta(366, 0, 0)},
+		{args: wrapArgs(Add, newDate(DateType, 9999, 12, 31), newTestTimeDelta(1, 0, 0)), wantExc: mustCreateException(OverflowErrorType, "date value out of range")},
+		{args: wrapArgs(LT, newDate(DateType, 2016, 1, 1), newDate(DateType, 2016, 1, 2)), want: True.ToObject()},
+		{args: wrapArgs(Add, newTestDateTime(2016, 12, 31, 23, 59, 59, 999999, None), newTestTimeDelta(0, 0, 1)), want: newTestDateTime(2017, 1, 1, 0, 0, 0, 0, None)},
+		{args: wrapArgs(Sub, newTestDateTime(2016, 1, 1, 0, 0, 0, 0, None), newTestDateTime(2015, 12, 31, 12, 0, 0, 0, None)), want: newTestTimeDelta(0, 43200, 0)},
+		{args: wrapArgs(Eq, newDate(DateType, 2016, 1, 1), newTestDateTime(2016, 1, 1, 0, 0, 0, 0, None)), want: False.ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDateTimeTZInfo(t *testing.T) {
+	utc := newTestFixedOffset("UTC", 0)
+	est := newTestFixedOffset("EST", -300)
+	fun := wrapFuncForTest(func(f *Frame, fn func(*Frame, *Object, *Object) (*Object, *BaseException), v, w *Object) (*Object, *BaseException) {
+		return fn(f, v, w)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(Eq, newTestDateTime(2016, 1, 1, 5, 0, 0, 0, utc), newTestDateTime(2016, 1, 1, 0, 0, 0, 0, est)), want: True.ToObject()},
+		{args: wrapArgs(Sub, newTestDateTime(2016, 1, 1, 0, 0, 0, 0, est), newTestDateTime(2016, 1, 1, 0, 0, 0, 0, utc)), want: newTestTimeDelta(0, 18000, 0)},
+		{args: wrapArgs(Eq, newTestDateTime(2016, 1, 1, 0, 0, 0, 0, utc), newTestDateTime(2016, 1, 1, 0, 0, 0, 0, None)), wantExc: mustCreateException(TypeErrorType, "can't compare offset-naive and offset-aware datetimes")},
+		{args: wrapArgs(Sub, newTestDateTime(2016, 1, 1, 0, 0, 0, 0, utc), newTestDateTime(2016, 1, 1, 0, 0, 0, 0, None)), wantExc: mustCreateException(TypeErrorType, "can't subtract offset-naive and offset-aware datetimes")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	astimezoneCases := []invokeTestCase{
+		{args: wrapArgs(newTestDateTime(2016, 1, 1, 0, 0, 0, 0, utc), est), want: newTestDateTime(2015, 12, 31, 19, 0, 0, 0, est)},
+		{args: wrapArgs(newTestDateTime(2016, 1, 1, 0, 0, 0, 0, None), est), wantExc: mustCreateException(ValueErrorType, "astimezone() cannot be applied to a naive datetime")},
+	}
+	for _, cas := range astimezoneCases {
+		if err := runInvokeMethodTestCase(DateTimeType, "astimezone", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	isoformatCases := []invokeTestCase{
+		{args: wrapArgs(newTestDateTime(2016, 1, 2, 3, 4, 5, 6, est)), want: NewStr("2016-01-02T03:04:05.000006-05:00").ToObject()},
+		{args: wrapArgs(newTestDateTime(2016, 1, 2, 3, 4, 5, 0, None), " "), want: NewStr("2016-01-02 03:04:05").ToObject()},
+		{args: wrapArgs(newTestDateTime(2016, 1, 2, 3, 4, 5, 0, None), "ab"), wantExc: mustCreateException(TypeErrorType, "isoformat() argument 1 must be char, not str")},
+	}
+	for _, cas := range isoformatCases {
+		if err := runInvokeMethodTestCase(DateTimeType, "isoformat", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDateTimeStrftime(t *testing.T) {
+	est := newTestFixedOffset("EST", -300)
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestDateTime(2016, 3, 5, 13, 4, 5, 6, None), "%Y-%m-%d %H:%M:%S.%f"), want: NewStr("2016-03-05 13:04:05.000006").ToObject()},
+		{args: wrapArgs(newTestDateTime(2016, 3, 5, 13, 4, 5, 0, None), "%a %A %b %B %I%p %j %w %U %W %y"), want: NewStr("Sat Saturday Mar March 01PM 065 6 09 09 16").ToObject()},
+		{args: wrapArgs(newTestDateTime(2016, 3, 5, 0, 0, 0, 0, None), "%c|%x|%X|%%|%Q"), want: NewStr("Sat Mar  5 00:00:00 2016|03/05/16|00:00:00|%|%Q").ToObject()},
+		{args: wrapArgs(newTestDateTime(2016, 3, 5, 0, 0, 0, 0, est), "%z %Z"), want: NewStr("-0500 EST").ToObject()},
+		{args: wrapArgs(newTestDateTime(2016, 3, 5, 0, 0, 0, 0, None), NewUnicode("%Y")), want: NewStr("2016").ToObject()},
+		{args: wrapArgs(newTestDateTime(1899, 1, 1, 0, 0, 0, 0, None), "%Y"), wantExc: mustCreateException(ValueErrorType, "year=1899 is before 1900; the datetime strftime() methods require year >= 1900")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(DateTimeType, "strftime", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDateTimeStrptime(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs("2016-03-05 13:04:05.12", "%Y-%m-%d %H:%M:%S.%f"), want: newTestDateTime(2016, 3, 5, 13, 4, 5, 120000, None)},
+		{args: wrapArgs("Sat  mar 5 1:04 pm 16", "%a %b %d %I:%M %p %y"), want: newTestDateTime(2016, 3, 5, 13, 4, 0, 0, None)},
+		{args: wrapArgs("69/065", "%y/%j"), want: newTestDateTime(1969, 3, 6, 0, 0, 0, 0, None)},
+		{args: wrapArgs("2016 9 6", "%Y %U %w"), want: newTestDateTime(2016, 3, 5, 0, 0, 0, 0, None)},
+		{args: wrapArgs("Sat Mar  5 00:00:00 2016", "%c"), want: newTestDateTime(2016, 3, 5, 0, 0, 0, 0, None)},
+		{args: wrapArgs("2016", "%Y%"), wantExc: mustCreateException(ValueErrorType, "stray % in format '%Y%'")},
+		{args: wrapArgs("2016", "%Q"), wantExc: mustCreateException(ValueErrorType, "'Q' is a bad directive in format '%Q'")},
+		{args: wrapArgs("2016-", "%Y"), wantExc: mustCreateException(ValueErrorType, "unconverted data remains: -")},
+		{args: wrapArgs("x2016", "%Y"), wantExc: mustCreateException(ValueErrorType, "time data 'x2016' does not match format '%Y'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(DateTimeType, "strptime", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DefaultDictType is the object representing the Python
+// 'collections.defaultdict' type.
+var DefaultDictType = newBasisType("defaultdict", reflect.TypeOf(DefaultDict{}), toDefaultDictUnsafe, DictType)
+
+// DefaultDict represents Python 'collections.defaultdict' objects. Looking up
+// a missing key calls the default factory and stores the result under that
+// key.
+type DefaultDict struct {
+	Dict
+	// factory is the default_factory or nil when it is None.
+	factory *Object
+}
+
+func toDefaultDictUnsafe(o *Object) *DefaultDict {
+	return (*DefaultDict)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *DefaultDict) ToObject() *Object {
+	return &d.Object
+}
+
+func (d *DefaultDict) defaultFactory() *Object {
+	if d.factory == nil {
+		return None
+	}
+	return d.factory
+}
+
+func defaultDictCopy(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "copy", args, DefaultDictType); raised != nil {
+		return nil, raised
+	}
+	d := toDefaultDictUnsafe(args[0])
+	return d.typ.Call(f, Args{d.defaultFactory(), d.ToObject()}, nil)
+}
+
+func defaultDictGetDefaultFactory(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_default_factory", args, DefaultDictType); raised != nil {
+		return nil, raised
+	}
+	return toDefaultDictUnsafe(args[0]).defaultFactory(), nil
+}
+
+func defaultDictMissing(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__missing__", args, DefaultDictType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	d, key := toDefaultDictUnsafe(args[0]), args[1]
+	if d.factory == nil {
+		return nil, raiseKeyError(f, key)
+	}
+	value, raised := d.factory.Call(f, nil, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	if raised := SetItem(f, d.ToObject(), key, value); raised != nil {
+		return nil, raised
+	}
+	return value, nil
+}
+
+func defaultDictReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, DefaultDictType); raised != nil {
+		return nil, raised
+	}
+	d := toDefaultDictUnsafe(args[0])
+	factoryArgs := NewTuple0()
+	if d.factory != nil {
+		factoryArgs = NewTuple1(d.factory)
+	}
+	items := newDictItemIterator(&d.Dict).ToObject()
+	return NewTuple(d.typ.ToObject(), factoryArgs.ToObject(), None, None, items).ToObject(), nil
+}
+
+func defaultDictSetDefaultFactory(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_set_default_factory", args, DefaultDictType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	d := toDefaultDictUnsafe(args[0])
+	if d.factory = args[1]; d.factory == None {
+		d.factory = nil
+	}
+	return None, nil
+}
+
+func defaultDictInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	d := toDefaultDictUnsafe(o)
+	d.factory = nil
+	if len(args) > 0 {
+		if factory := args[0]; factory != None {
+			if factory.typ.slots.Call == nil {
+				return nil, f.RaiseType(TypeErrorType, "first argument must be callable")
+			}
+			d.factory = factory
+		}
+		args = args[1:]
+	}
+	return dictInit(f, o, args, kwargs)
+}
+
+func defaultDictRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toDefaultDictUnsafe(o)
+	factory := "None"
+	if d.factory != nil {
+		if f.reprEnter(d.factory) {
+			factory = "..."
+		} else {
+			s, raised := Repr(f, d.factory)
+			f.reprLeave(d.factory)
+			if raised != nil {
+				return nil, raised
+			}
+			factory = s.Value()
+		}
+	}
+	s, raised := dictRepr(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewStr(fmt.Sprintf("defaultdict(%s, %s)", factory, toStrUnsafe(s).Value())).ToObject(), nil
+}
+
+func initDefaultDictType(dict map[string]*Object) {
+	dict["__copy__"] = newBuiltinFunction("__copy__", defaultDictCopy).ToObject()
+	dict["__missing__"] = newBuiltinFunction("__missing__", defaultDictMissing).ToObject()
+	dict["__module__"] = NewStr("collections").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", defaultDictReduce).ToObject()
+	dict["copy"] = newBuiltinFunction("copy", defaultDictCopy).ToObject()
+	dict["default_factory"] = newProperty(newBuiltinFunction("_get_default_factory", defaultDictGetDefaultFactory).ToObject(), newBuiltinFunction("_set_default_factory", defaultDictSetDefaultFactory).ToObject(), nil).ToObject()
+	DefaultDictType.slots.Init = &initSlot{defaultDictInit}
+	DefaultDictType.slots.Repr = &unaryOpSlot{defaultDictRepr}
+}
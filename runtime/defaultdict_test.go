@@ -0,0 +1,75 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestDefaultDictGetItem(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, factory, key *Object) (*Object, *BaseException) {
+		d, raised := DefaultDictType.Call(f, Args{factory}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		item, raised := GetItem(f, d, key)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(item, d).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(ListType, "foo"), want: newTestTuple(NewList(), newTestDict("foo", NewList())).ToObject()},
+		{args: wrapArgs(IntType, 1), want: newTestTuple(0, newTestDict(1, 0)).ToObject()},
+		{args: wrapArgs(None, "foo"), wantExc: mustCreateException(KeyErrorType, "foo")},
+		{args: wrapArgs(123, "foo"), wantExc: mustCreateException(TypeErrorType, "first argument must be callable")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDefaultDictDefaultFactory(t *testing.T) {
+	f := NewRootFrame()
+	d := mustNotRaise(DefaultDictType.Call(f, Args{IntType.ToObject()}, nil))
+	name := NewStr("default_factory")
+	if got := mustNotRaise(GetAttr(f, d, name, nil)); got != IntType.ToObject() {
+		t.Errorf("d.default_factory = %v, want int", got)
+	}
+	if raised := SetAttr(f, d, name, None); raised != nil {
+		t.Fatal(raised)
+	}
+	if got := mustNotRaise(GetAttr(f, d, name, nil)); got != None {
+		t.Errorf("d.default_factory = %v, want None", got)
+	}
+	if _, raised := GetItem(f, d, NewStr("foo").ToObject()); raised == nil || !raised.isInstance(KeyErrorType) {
+		t.Errorf("d['foo'] raised %v, want KeyError", raised)
+	}
+}
+
+func TestDefaultDictRepr(t *testing.T) {
+	f := NewRootFrame()
+	cases := []invokeTestCase{
+		{args: wrapArgs(mustNotRaise(DefaultDictType.Call(f, nil, nil))), want: NewStr("defaultdict(None, {})").ToObject()},
+		{args: wrapArgs(mustNotRaise(DefaultDictType.Call(f, wrapArgs(IntType, newTestDict("foo", 1)), nil))), want: NewStr("defaultdict(<type 'int'>, {'foo': 1})").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
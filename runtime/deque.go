@@ -0,0 +1,665 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const minDequeCapacity = 8
+
+var (
+	// DequeType is the object representing the Python 'collections.deque'
+	// type.
+	DequeType          = newBasisType("deque", reflect.TypeOf(Deque{}), toDequeUnsafe, ObjectType)
+	dequeIteratorType  = newBasisType("deque_iterator", reflect.TypeOf(dequeIterator{}), toDequeIteratorUnsafe, ObjectType)
+	dequeInitParamSpec = NewParamSpec("deque", []Param{{"iterable", emptyTuple.ToObject()}, {"maxlen", None}}, false, false)
+)
+
+// Deque represents Python 'collections.deque' objects. The elements are
+// stored in a ring buffer so that items can be added and removed at either
+// end in constant time.
+type Deque struct {
+	Object
+	mutex sync.RWMutex
+	// elems is the ring buffer. The deque's items are the size elements
+	// starting at index head, wrapping around at the end of elems.
+	elems []*Object
+	head  int
+	size  int
+	// maxlen is the maximum size of the deque or -1 if it is unbounded.
+	maxlen int
+	// state is incremented whenever the deque is modified so that
+	// iterators can detect concurrent modifications.
+	state int64
+}
+
+func toDequeUnsafe(o *Object) *Deque {
+	return (*Deque)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *Deque) ToObject() *Object {
+	return &d.Object
+}
+
+// Len returns the number of items in d.
+func (d *Deque) Len() int {
+	d.mutex.RLock()
+	size := d.size
+	d.mutex.RUnlock()
+	return size
+}
+
+// Append adds o to the right side of d, discarding an item from the left
+// side if d is full.
+func (d *Deque) Append(o *Object) {
+	d.mutex.Lock()
+	d.pushBack(o)
+	d.mutex.Unlock()
+}
+
+// AppendLeft adds o to the left side of d, discarding an item from the right
+// side if d is full.
+func (d *Deque) AppendLeft(o *Object) {
+	d.mutex.Lock()
+	d.pushFront(o)
+	d.mutex.Unlock()
+}
+
+// Pop removes and returns the rightmost item of d, or nil if d is empty.
+func (d *Deque) Pop() *Object {
+	d.mutex.Lock()
+	item := d.popBack()
+	d.mutex.Unlock()
+	return item
+}
+
+// PopLeft removes and returns the leftmost item of d, or nil if d is empty.
+func (d *Deque) PopLeft() *Object {
+	d.mutex.Lock()
+	item := d.popFront()
+	d.mutex.Unlock()
+	return item
+}
+
+// The methods below assume that d.mutex is held by the caller.
+
+func (d *Deque) index(i int) int {
+	return (d.head + i) % len(d.elems)
+}
+
+func (d *Deque) at(i int) *Object {
+	return d.elems[d.index(i)]
+}
+
+func (d *Deque) snapshot() []*Object {
+	elems := make([]*Object, d.size)
+	for i := range elems {
+		elems[i] = d.at(i)
+	}
+	return elems
+}
+
+func (d *Deque) grow() {
+	if d.size < len(d.elems) {
+		return
+	}
+	n := 2 * len(d.elems)
+	if n < minDequeCapacity {
+		n = minDequeCapacity
+	}
+	elems := make([]*Object, n)
+	for i := 0; i < d.size; i++ {
+		elems[i] = d.at(i)
+	}
+	d.elems, d.head = elems, 0
+}
+
+func (d *Deque) pushBack(o *Object) {
+	if d.maxlen == 0 {
+		return
+	}
+	if d.size == d.maxlen {
+		d.popFront()
+	}
+	d.grow()
+	d.elems[d.index(d.size)] = o
+	d.size++
+	d.state++
+}
+
+func (d *Deque) pushFront(o *Object) {
+	if d.maxlen == 0 {
+		return
+	}
+	if d.size == d.maxlen {
+		d.popBack()
+	}
+	d.grow()
+	d.head = (d.head + len(d.elems) - 1) % len(d.elems)
+	d.elems[d.head] = o
+	d.size++
+	d.state++
+}
+
+func (d *Deque) popBack() *Object {
+	if d.size == 0 {
+		return nil
+	}
+	i := d.index(d.size - 1)
+	item := d.elems[i]
+	d.elems[i] = nil
+	d.size--
+	d.state++
+	return item
+}
+
+func (d *Deque) popFront() *Object {
+	if d.size == 0 {
+		return nil
+	}
+	item := d.elems[d.head]
+	d.elems[d.head] = nil
+	d.head = (d.head + 1) % len(d.elems)
+	d.size--
+	d.state++
+	return item
+}
+
+// rotate moves the last n items to the front of d, or the first -n items to
+// the back when n is negative.
+func (d *Deque) rotate(n int) {
+	if d.size <= 1 {
+		return
+	}
+	n %= d.size
+	if n < 0 {
+		n += d.size
+	}
+	// Take the shorter way around.
+	if n <= d.size/2 {
+		for i := 0; i < n; i++ {
+			d.pushFront(d.popBack())
+		}
+	} else {
+		for i := n; i < d.size; i++ {
+			d.pushBack(d.popFront())
+		}
+	}
+}
+
+func (d *Deque) delAt(i int) {
+	for ; i < d.size-1; i++ {
+		d.elems[d.index(i)] = d.at(i + 1)
+	}
+	d.popBack()
+}
+
+func (d *Deque) extend(f *Frame, iterable *Object, left bool) *BaseException {
+	if iterable == d.ToObject() {
+		// Iterating over d while growing it would never terminate.
+		d.mutex.RLock()
+		iterable = NewList(d.snapshot()...).ToObject()
+		d.mutex.RUnlock()
+	}
+	return seqForEach(f, iterable, func(o *Object) *BaseException {
+		if left {
+			d.AppendLeft(o)
+		} else {
+			d.Append(o)
+		}
+		return nil
+	})
+}
+
+func dequeAppend(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "append", args, DequeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	toDequeUnsafe(args[0]).Append(args[1])
+	return None, nil
+}
+
+func dequeAppendLeft(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "appendleft", args, DequeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	toDequeUnsafe(args[0]).AppendLeft(args[1])
+	return None, nil
+}
+
+func dequeClear(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "clear", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	d := toDequeUnsafe(args[0])
+	d.mutex.Lock()
+	d.elems, d.head, d.size = nil, 0, 0
+	d.state++
+	d.mutex.Unlock()
+	return None, nil
+}
+
+func dequeCopy(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__copy__", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	d := toDequeUnsafe(args[0])
+	callArgs := Args{d.ToObject()}
+	if d.maxlen >= 0 {
+		callArgs = append(callArgs, NewInt(d.maxlen).ToObject())
+	}
+	return d.typ.Call(f, callArgs, nil)
+}
+
+func dequeCount(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "count", args, DequeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return seqCount(f, args[0], args[1])
+}
+
+func dequeExtend(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "extend", args, DequeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if raised := toDequeUnsafe(args[0]).extend(f, args[1], false); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func dequeExtendLeft(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "extendleft", args, DequeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if raised := toDequeUnsafe(args[0]).extend(f, args[1], true); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func dequeGetMaxLen(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_maxlen", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	d := toDequeUnsafe(args[0])
+	if d.maxlen < 0 {
+		return None, nil
+	}
+	return NewInt(d.maxlen).ToObject(), nil
+}
+
+func dequePop(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "pop", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	item := toDequeUnsafe(args[0]).Pop()
+	if item == nil {
+		return nil, f.RaiseType(IndexErrorType, "pop from an empty deque")
+	}
+	return item, nil
+}
+
+func dequePopLeft(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "popleft", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	item := toDequeUnsafe(args[0]).PopLeft()
+	if item == nil {
+		return nil, f.RaiseType(IndexErrorType, "pop from an empty deque")
+	}
+	return item, nil
+}
+
+func dequeReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	d := toDequeUnsafe(args[0])
+	d.mutex.RLock()
+	initArgs := []*Object{NewList(d.snapshot()...).ToObject()}
+	if d.maxlen >= 0 {
+		initArgs = append(initArgs, NewInt(d.maxlen).ToObject())
+	}
+	d.mutex.RUnlock()
+	state := None
+	if dict := d.Dict(); dict != nil && dict.Len() > 0 {
+		state = dict.ToObject()
+	}
+	return NewTuple(d.typ.ToObject(), NewTuple(initArgs...).ToObject(), state).ToObject(), nil
+}
+
+func dequeRemove(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "remove", args, DequeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	d := toDequeUnsafe(args[0])
+	// Compare against a snapshot so that __eq__ methods are free to
+	// access the deque.
+	d.mutex.RLock()
+	elems, state := d.snapshot(), d.state
+	d.mutex.RUnlock()
+	i, raised := seqFindElem(f, elems, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	if i == -1 {
+		return nil, f.RaiseType(ValueErrorType, "deque.remove(x): x not in deque")
+	}
+	d.mutex.Lock()
+	if d.state == state {
+		d.delAt(i)
+	} else {
+		raised = f.RaiseType(IndexErrorType, "deque mutated during remove().")
+	}
+	d.mutex.Unlock()
+	if raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func dequeReverse(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "reverse", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	d := toDequeUnsafe(args[0])
+	d.mutex.Lock()
+	for i, j := 0, d.size-1; i < j; i, j = i+1, j-1 {
+		m, n := d.index(i), d.index(j)
+		d.elems[m], d.elems[n] = d.elems[n], d.elems[m]
+	}
+	d.state++
+	d.mutex.Unlock()
+	return None, nil
+}
+
+func dequeReversed(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reversed__", args, DequeType); raised != nil {
+		return nil, raised
+	}
+	return newDequeIterator(toDequeUnsafe(args[0]), true), nil
+}
+
+func dequeRotate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{DequeType, IntType}
+	if len(args) == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "rotate", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	n := 1
+	if len(args) > 1 {
+		n = toIntUnsafe(args[1]).Value()
+	}
+	d := toDequeUnsafe(args[0])
+	d.mutex.Lock()
+	d.rotate(n)
+	d.mutex.Unlock()
+	return None, nil
+}
+
+func dequeCompare(f *Frame, v, w *Object, cmp binaryOpFunc) (*Object, *BaseException) {
+	if !w.isInstance(DequeType) {
+		return NotImplemented, nil
+	}
+	d1, d2 := toDequeUnsafe(v), toDequeUnsafe(w)
+	d1.mutex.RLock()
+	elems1 := d1.snapshot()
+	d1.mutex.RUnlock()
+	d2.mutex.RLock()
+	elems2 := d2.snapshot()
+	d2.mutex.RUnlock()
+	return seqCompare(f, elems1, elems2, cmp)
+}
+
+func dequeDelItem(f *Frame, o, key *Object) *BaseException {
+	d := toDequeUnsafe(o)
+	d.mutex.Lock()
+	i, raised := dequeIndex(f, d, key)
+	if raised == nil {
+		d.delAt(i)
+	}
+	d.mutex.Unlock()
+	return raised
+}
+
+func dequeEq(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dequeCompare(f, v, w, Eq)
+}
+
+func dequeGE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dequeCompare(f, v, w, GE)
+}
+
+func dequeGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
+	d := toDequeUnsafe(o)
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	i, raised := dequeIndex(f, d, key)
+	if raised != nil {
+		return nil, raised
+	}
+	return d.at(i), nil
+}
+
+func dequeGT(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dequeCompare(f, v, w, GT)
+}
+
+func dequeIAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	if raised := toDequeUnsafe(v).extend(f, w, false); raised != nil {
+		return nil, raised
+	}
+	return v, nil
+}
+
+// dequeIndex converts key into an index into d. It assumes that d.mutex is
+// held by the caller.
+func dequeIndex(f *Frame, d *Deque, key *Object) (int, *BaseException) {
+	if key.typ.slots.Index == nil {
+		format := "sequence index must be integer, not '%s'"
+		return 0, f.RaiseType(TypeErrorType, fmt.Sprintf(format, key.typ.Name()))
+	}
+	i, raised := indexIntChecked(f, key, IndexErrorType)
+	if raised != nil {
+		return 0, raised
+	}
+	if i < 0 {
+		i += d.size
+	}
+	if i < 0 || i >= d.size {
+		return 0, f.RaiseType(IndexErrorType, "deque index out of range")
+	}
+	return i, nil
+}
+
+func dequeInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := dequeInitParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	maxlen := -1
+	if m := validated[1]; m != None {
+		var raised *BaseException
+		if maxlen, raised = IndexInt(f, m); raised != nil {
+			return nil, raised
+		}
+		if maxlen < 0 {
+			return nil, f.RaiseType(ValueErrorType, "maxlen must be non-negative")
+		}
+	}
+	d := toDequeUnsafe(o)
+	d.mutex.Lock()
+	d.elems, d.head, d.size = nil, 0, 0
+	d.maxlen = maxlen
+	d.state++
+	d.mutex.Unlock()
+	if raised := d.extend(f, validated[0], false); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func dequeIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return newDequeIterator(toDequeUnsafe(o), false), nil
+}
+
+func dequeLE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dequeCompare(f, v, w, LE)
+}
+
+func dequeLen(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewInt(toDequeUnsafe(o).Len()).ToObject(), nil
+}
+
+func dequeLT(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dequeCompare(f, v, w, LT)
+}
+
+func dequeNE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return dequeCompare(f, v, w, NE)
+}
+
+func dequeNew(f *Frame, t *Type, _ Args, _ KWArgs) (*Object, *BaseException) {
+	d := toDequeUnsafe(newObject(t))
+	d.maxlen = -1
+	return d.ToObject(), nil
+}
+
+func dequeRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	if f.reprEnter(o) {
+		return NewStr("[...]").ToObject(), nil
+	}
+	d := toDequeUnsafe(o)
+	d.mutex.RLock()
+	elems, maxlen := d.snapshot(), d.maxlen
+	d.mutex.RUnlock()
+	s, raised := seqRepr(f, elems)
+	f.reprLeave(o)
+	if raised != nil {
+		return nil, raised
+	}
+	if maxlen >= 0 {
+		return NewStr(fmt.Sprintf("deque([%s], maxlen=%d)", s, maxlen)).ToObject(), nil
+	}
+	return NewStr(fmt.Sprintf("deque([%s])", s)).ToObject(), nil
+}
+
+func dequeSetItem(f *Frame, o, key, value *Object) *BaseException {
+	d := toDequeUnsafe(o)
+	d.mutex.Lock()
+	i, raised := dequeIndex(f, d, key)
+	if raised == nil {
+		d.elems[d.index(i)] = value
+	}
+	d.mutex.Unlock()
+	return raised
+}
+
+func initDequeType(dict map[string]*Object) {
+	dict["__copy__"] = newBuiltinFunction("__copy__", dequeCopy).ToObject()
+	dict["__module__"] = NewStr("collections").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", dequeReduce).ToObject()
+	dict["__reversed__"] = newBuiltinFunction("__reversed__", dequeReversed).ToObject()
+	dict["append"] = newBuiltinFunction("append", dequeAppend).ToObject()
+	dict["appendleft"] = newBuiltinFunction("appendleft", dequeAppendLeft).ToObject()
+	dict["clear"] = newBuiltinFunction("clear", dequeClear).ToObject()
+	dict["count"] = newBuiltinFunction("count", dequeCount).ToObject()
+	dict["extend"] = newBuiltinFunction("extend", dequeExtend).ToObject()
+	dict["extendleft"] = newBuiltinFunction("extendleft", dequeExtendLeft).ToObject()
+	dict["maxlen"] = newProperty(newBuiltinFunction("_get_maxlen", dequeGetMaxLen).ToObject(), nil, nil).ToObject()
+	dict["pop"] = newBuiltinFunction("pop", dequePop).ToObject()
+	dict["popleft"] = newBuiltinFunction("popleft", dequePopLeft).ToObject()
+	dict["remove"] = newBuiltinFunction("remove", dequeRemove).ToObject()
+	dict["reverse"] = newBuiltinFunction("reverse", dequeReverse).ToObject()
+	dict["rotate"] = newBuiltinFunction("rotate", dequeRotate).ToObject()
+	DequeType.slots.DelItem = &delItemSlot{dequeDelItem}
+	DequeType.slots.Eq = &binaryOpSlot{dequeEq}
+	DequeType.slots.GE = &binaryOpSlot{dequeGE}
+	DequeType.slots.GetItem = &binaryOpSlot{dequeGetItem}
+	DequeType.slots.GT = &binaryOpSlot{dequeGT}
+	DequeType.slots.Hash = &unaryOpSlot{hashNotImplemented}
+	DequeType.slots.IAdd = &binaryOpSlot{dequeIAdd}
+	DequeType.slots.Init = &initSlot{dequeInit}
+	DequeType.slots.Iter = &unaryOpSlot{dequeIter}
+	DequeType.slots.LE = &binaryOpSlot{dequeLE}
+	DequeType.slots.Len = &unaryOpSlot{dequeLen}
+	DequeType.slots.LT = &binaryOpSlot{dequeLT}
+	DequeType.slots.NE = &binaryOpSlot{dequeNE}
+	DequeType.slots.New = &newSlot{dequeNew}
+	DequeType.slots.Repr = &unaryOpSlot{dequeRepr}
+	DequeType.slots.SetItem = &setItemSlot{dequeSetItem}
+}
+
+// dequeIterator iterates over the items of a deque in either direction. It
+// raises RuntimeError if the deque is modified during iteration.
+type dequeIterator struct {
+	Object
+	mutex   sync.Mutex
+	deque   *Deque
+	index   int
+	state   int64
+	reverse bool
+}
+
+func newDequeIterator(d *Deque, reverse bool) *Object {
+	d.mutex.RLock()
+	state := d.state
+	d.mutex.RUnlock()
+	iter := &dequeIterator{Object: Object{typ: dequeIteratorType}, deque: d, state: state, reverse: reverse}
+	return &iter.Object
+}
+
+func toDequeIteratorUnsafe(o *Object) *dequeIterator {
+	return (*dequeIterator)(o.toPointer())
+}
+
+func dequeIteratorIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func dequeIteratorNext(f *Frame, o *Object) (item *Object, raised *BaseException) {
+	iter := toDequeIteratorUnsafe(o)
+	iter.mutex.Lock()
+	d := iter.deque
+	d.mutex.RLock()
+	if d.state != iter.state {
+		raised = f.RaiseType(RuntimeErrorType, "deque mutated during iteration")
+	} else if iter.index >= d.size {
+		raised = f.Raise(StopIterationType.ToObject(), nil, nil)
+	} else {
+		i := iter.index
+		if iter.reverse {
+			i = d.size - i - 1
+		}
+		item = d.at(i)
+		iter.index++
+	}
+	d.mutex.RUnlock()
+	iter.mutex.Unlock()
+	return item, raised
+}
+
+func initDequeIteratorType(map[string]*Object) {
+	dequeIteratorType.flags &= ^(typeFlagBasetype | typeFlagInstantiable)
+	dequeIteratorType.slots.Iter = &unaryOpSlot{dequeIteratorIter}
+	dequeIteratorType.slots.Next = &unaryOpSlot{dequeIteratorNext}
+}
@@ -0,0 +1,219 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestDequeNew(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		d, raised := DequeType.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		maxlen, raised := GetAttr(f, d, NewStr("maxlen"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		l, raised := ListType.Call(f, Args{d}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(l, maxlen).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{want: NewTuple2(NewList().ToObject(), None).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3)), want: newTestTuple(newTestList(1, 2, 3), None).ToObject()},
+		{args: wrapArgs("abc", 2), want: newTestTuple(newTestList("b", "c"), 2).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), 0), want: newTestTuple(NewList(), 0).ToObject()},
+		{args: wrapArgs(newTestList(1), -1), wantExc: mustCreateException(ValueErrorType, "maxlen must be non-negative")},
+		{args: wrapArgs(123), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+		{args: wrapArgs(NewList(), None, None), wantExc: mustCreateException(TypeErrorType, "deque() takes 2 arguments (3 given)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDequeMethods(t *testing.T) {
+	// fun calls the named method of a new deque and returns the method's
+	// result along with the deque's contents afterward.
+	fun := wrapFuncForTest(func(f *Frame, init *Object, maxlen *Object, name string, args ...*Object) (*Object, *BaseException) {
+		d, raised := DequeType.Call(f, Args{init, maxlen}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		method, raised := GetAttr(f, d, NewStr(name), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		result, raised := method.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		l, raised := ListType.Call(f, Args{d}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(result, l).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestList(1, 2), None, "append", 3), want: newTestTuple(None, newTestList(1, 2, 3)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), 2, "append", 3), want: newTestTuple(None, newTestList(2, 3)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), None, "appendleft", 3), want: newTestTuple(None, newTestList(3, 1, 2)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), 2, "appendleft", 3), want: newTestTuple(None, newTestList(3, 1)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), None, "clear"), want: newTestTuple(None, NewList()).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 1), None, "count", 1), want: newTestTuple(2, newTestList(1, 2, 1)).ToObject()},
+		{args: wrapArgs(newTestList(1), None, "extend", newTestTuple(2, 3)), want: newTestTuple(None, newTestList(1, 2, 3)).ToObject()},
+		{args: wrapArgs(newTestList(1), None, "extendleft", newTestTuple(2, 3)), want: newTestTuple(None, newTestList(3, 2, 1)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3), None, "pop"), want: newTestTuple(3, newTestList(1, 2)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3), None, "popleft"), want: newTestTuple(1, newTestList(2, 3)).ToObject()},
+		{args: wrapArgs(NewList(), None, "pop"), wantExc: mustCreateException(IndexErrorType, "pop from an empty deque")},
+		{args: wrapArgs(NewList(), None, "popleft"), wantExc: mustCreateException(IndexErrorType, "pop from an empty deque")},
+		{args: wrapArgs(newTestList(1, 2, 1), None, "remove", 1), want: newTestTuple(None, newTestList(2, 1)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), None, "remove", 3), wantExc: mustCreateException(ValueErrorType, "deque.remove(x): x not in deque")},
+		{args: wrapArgs(newTestList(1, 2, 3), None, "reverse"), want: newTestTuple(None, newTestList(3, 2, 1)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3, 4), None, "rotate"), want: newTestTuple(None, newTestList(4, 1, 2, 3)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3, 4), None, "rotate", 3), want: newTestTuple(None, newTestList(2, 3, 4, 1)).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3, 4), None, "rotate", -5), want: newTestTuple(None, newTestList(2, 3, 4, 1)).ToObject()},
+		{args: wrapArgs(NewList(), None, "rotate", 2), want: newTestTuple(None, NewList()).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDequeRingBuffer(t *testing.T) {
+	f := NewRootFrame()
+	d := toDequeUnsafe(mustNotRaise(DequeType.Call(f, nil, nil)))
+	// Interleave operations on both ends so the ring buffer wraps around
+	// and grows several times.
+	var want []*Object
+	for i := 0; i < 100; i++ {
+		o := NewInt(i).ToObject()
+		if i%3 == 0 {
+			d.AppendLeft(o)
+			want = append([]*Object{o}, want...)
+		} else {
+			d.Append(o)
+			want = append(want, o)
+		}
+		if i%7 == 0 {
+			d.PopLeft()
+			want = want[1:]
+		}
+	}
+	got := mustNotRaise(ListType.Call(f, Args{d.ToObject()}, nil))
+	if w := NewList(want...).ToObject(); mustNotRaise(Eq(f, got, w)) != True.ToObject() {
+		t.Errorf("list(d) = %v, want %v", got, w)
+	}
+	reversed := mustNotRaise(ListType.Call(f, Args{newDequeIterator(d, true)}, nil))
+	l := toListUnsafe(reversed)
+	for i, j := 0, len(want)-1; i < len(l.elems); i, j = i+1, j-1 {
+		if l.elems[i] != want[j] {
+			t.Fatalf("list(reversed(d))[%d] = %v, want %v", i, l.elems[i], want[j])
+		}
+	}
+}
+
+func TestDequeItems(t *testing.T) {
+	getItem := mustNotRaise(GetAttr(NewRootFrame(), DequeType.ToObject(), NewStr("__getitem__"), nil))
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestDeque(1, 2, 3), 0), want: NewInt(1).ToObject()},
+		{args: wrapArgs(newTestDeque(1, 2, 3), -1), want: NewInt(3).ToObject()},
+		{args: wrapArgs(newTestDeque(1, 2, 3), 3), wantExc: mustCreateException(IndexErrorType, "deque index out of range")},
+		{args: wrapArgs(newTestDeque(1, 2, 3), "foo"), wantExc: mustCreateException(TypeErrorType, "sequence index must be integer, not 'str'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(getItem, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	fun := wrapFuncForTest(func(f *Frame, d *Deque, set bool, key, value *Object) (*Object, *BaseException) {
+		var raised *BaseException
+		if set {
+			raised = SetItem(f, d.ToObject(), key, value)
+		} else {
+			raised = DelItem(f, d.ToObject(), key)
+		}
+		if raised != nil {
+			return nil, raised
+		}
+		return ListType.Call(f, Args{d.ToObject()}, nil)
+	})
+	cases = []invokeTestCase{
+		{args: wrapArgs(newTestDeque(1, 2, 3), true, 1, "foo"), want: newTestList(1, "foo", 3).ToObject()},
+		{args: wrapArgs(newTestDeque(1, 2, 3), true, -3, "foo"), want: newTestList("foo", 2, 3).ToObject()},
+		{args: wrapArgs(newTestDeque(1, 2, 3), false, 1, None), want: newTestList(1, 3).ToObject()},
+		{args: wrapArgs(newTestDeque(1, 2, 3), false, -1, None), want: newTestList(1, 2).ToObject()},
+		{args: wrapArgs(newTestDeque(), false, 0, None), wantExc: mustCreateException(IndexErrorType, "deque index out of range")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDequeCompare(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestDeque(1, 2), newTestDeque(1, 2)), want: compareAllResultEq},
+		{args: wrapArgs(newTestDeque(1, 2), newTestDeque(1, 3)), want: compareAllResultLT},
+		{args: wrapArgs(newTestDeque(1, 2, 3), newTestDeque(1, 2)), want: compareAllResultGT},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(compareAll, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDequeRepr(t *testing.T) {
+	f := NewRootFrame()
+	recursive := newTestDeque(1)
+	toDequeUnsafe(recursive).Append(recursive)
+	bounded := mustNotRaise(DequeType.Call(f, wrapArgs(newTestList("a"), 3), nil))
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestDeque()), want: NewStr("deque([])").ToObject()},
+		{args: wrapArgs(newTestDeque(1, "foo")), want: NewStr("deque([1, 'foo'])").ToObject()},
+		{args: wrapArgs(bounded), want: NewStr("deque(['a'], maxlen=3)").ToObject()},
+		{args: wrapArgs(recursive), want: NewStr("deque([1, [...]])").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDequeIteratorMutation(t *testing.T) {
+	f := NewRootFrame()
+	d := newTestDeque(1, 2)
+	iter := mustNotRaise(Iter(f, d))
+	mustNotRaise(Next(f, iter))
+	toDequeUnsafe(d).Append(NewInt(3).ToObject())
+	if _, raised := Next(f, iter); raised == nil || !raised.isInstance(RuntimeErrorType) {
+		t.Errorf("next(iter) after append raised %v, want RuntimeError", raised)
+	}
+}
+
+func newTestDeque(elems ...interface{}) *Object {
+	return mustNotRaise(DequeType.Call(NewRootFrame(), Args{newTestList(elems...).ToObject()}, nil))
+}
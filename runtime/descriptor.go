@@ -17,6 +17,7 @@ package grumpy
 import (
 	"fmt"
 	"reflect"
+	"unsafe"
 )
 
 type fieldDescriptorType int
@@ -29,11 +30,16 @@ const (
 // Property represents Python 'property' objects.
 type Property struct {
 	Object
-	get, set, del *Object
+	get *Object `attr:"fget"`
+	set *Object `attr:"fset"`
+	del *Object `attr:"fdel"`
+	doc *Object `attr:"__doc__"`
+	// getterDoc is true when doc was taken from get's docstring.
+	getterDoc bool
 }
 
 func newProperty(get, set, del *Object) *Property {
-	return &Property{Object{typ: PropertyType}, get, set, del}
+	return &Property{Object: Object{typ: PropertyType}, get: get, set: set, del: del, doc: None}
 }
 
 func toPropertyUnsafe(o *Object) *Property {
@@ -45,16 +51,56 @@ func (p *Property) ToObject() *Object {
 	return &p.Object
 }
 
-// PropertyType is the object representing the Python 'property' type.
-var PropertyType = newBasisType("property", reflect.TypeOf(Property{}), toPropertyUnsafe, ObjectType)
+var (
+	// PropertyType is the object representing the Python 'property' type.
+	PropertyType      = newBasisType("property", reflect.TypeOf(Property{}), toPropertyUnsafe, ObjectType)
+	propertyParamSpec = NewParamSpec("property", []Param{{"fget", None}, {"fset", None}, {"fdel", None}, {"doc", None}}, false, false)
+)
 
-func initPropertyType(map[string]*Object) {
+func initPropertyType(dict map[string]*Object) {
+	dict["deleter"] = newBuiltinFunction("deleter", propertyDeleter).ToObject()
+	dict["getter"] = newBuiltinFunction("getter", propertyGetter).ToObject()
+	dict["setter"] = newBuiltinFunction("setter", propertySetter).ToObject()
 	PropertyType.slots.Delete = &deleteSlot{propertyDelete}
 	PropertyType.slots.Get = &getSlot{propertyGet}
 	PropertyType.slots.Init = &initSlot{propertyInit}
 	PropertyType.slots.Set = &setSlot{propertySet}
 }
 
+// propertyCopy returns a copy of the property args[0] with the accessor
+// given by which (0 for fget, 1 for fset and 2 for fdel) replaced by args[1].
+// It implements the getter, setter and deleter decorators.
+func propertyCopy(f *Frame, name string, args Args, which int) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, name, args, PropertyType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	p := toPropertyUnsafe(args[0])
+	accessors := Args{p.get, p.set, p.del, p.doc}
+	for i, o := range accessors {
+		if o == nil {
+			accessors[i] = None
+		}
+	}
+	accessors[which] = args[1]
+	if which == 0 && p.getterDoc {
+		// Let the new getter supply the docstring.
+		accessors[3] = None
+	}
+	return p.typ.Call(f, accessors, nil)
+}
+
+func propertyDeleter(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return propertyCopy(f, "deleter", args, 2)
+}
+
+func propertyGetter(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return propertyCopy(f, "getter", args, 0)
+}
+
+func propertySetter(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return propertyCopy(f, "setter", args, 1)
+}
+
 func propertyDelete(f *Frame, desc, inst *Object) *BaseException {
 	p := toPropertyUnsafe(desc)
 	if p.del == nil || p.del == None {
@@ -65,6 +111,10 @@ func propertyDelete(f *Frame, desc, inst *Object) *BaseException {
 }
 
 func propertyGet(f *Frame, desc, instance *Object, _ *Type) (*Object, *BaseException) {
+	if instance == nil || instance == None {
+		// Accessed via the class so return the property itself.
+		return desc, nil
+	}
 	p := toPropertyUnsafe(desc)
 	if p.get == nil || p.get == None {
 		return nil, f.RaiseType(AttributeErrorType, "unreadable attribute")
@@ -72,24 +122,20 @@ func propertyGet(f *Frame, desc, instance *Object, _ *Type) (*Object, *BaseExcep
 	return p.get.Call(f, Args{instance}, nil)
 }
 
-func propertyInit(f *Frame, o *Object, args Args, _ KWArgs) (*Object, *BaseException) {
-	expectedTypes := []*Type{ObjectType, ObjectType, ObjectType}
-	argc := len(args)
-	if argc < 3 {
-		expectedTypes = expectedTypes[:argc]
-	}
-	if raised := checkFunctionArgs(f, "__init__", args, expectedTypes...); raised != nil {
+func propertyInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [4]*Object
+	if raised := propertyParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
 		return nil, raised
 	}
 	p := toPropertyUnsafe(o)
-	if argc > 0 {
-		p.get = args[0]
-	}
-	if argc > 1 {
-		p.set = args[1]
-	}
-	if argc > 2 {
-		p.del = args[2]
+	p.get, p.set, p.del, p.doc = validated[0], validated[1], validated[2], validated[3]
+	p.getterDoc = false
+	if p.doc == None && p.get != None {
+		doc, raised := GetAttr(f, p.get, NewStr("__doc__"), None)
+		if raised != nil {
+			return nil, raised
+		}
+		p.doc, p.getterDoc = doc, true
 	}
 	return None, nil
 }
@@ -103,6 +149,95 @@ func propertySet(f *Frame, desc, inst, value *Object) *BaseException {
 	return raised
 }
 
+// memberDescriptor represents Python 'member_descriptor' objects, which
+// provide access to a __slots__ entry of instances of objclass.
+type memberDescriptor struct {
+	Object
+	objclass *Type  `attr:"__objclass__"`
+	name     string `attr:"__name__"`
+	index    int
+}
+
+// memberDescriptorType is the object representing the Python
+// 'member_descriptor' type.
+var memberDescriptorType = newBasisType("member_descriptor", reflect.TypeOf(memberDescriptor{}), toMemberDescriptorUnsafe, ObjectType)
+
+func newMemberDescriptor(objclass *Type, name string, index int) *memberDescriptor {
+	return &memberDescriptor{Object: Object{typ: memberDescriptorType}, objclass: objclass, name: name, index: index}
+}
+
+func toMemberDescriptorUnsafe(o *Object) *memberDescriptor {
+	return (*memberDescriptor)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *memberDescriptor) ToObject() *Object {
+	return &d.Object
+}
+
+// slot returns the storage for d's __slots__ entry in inst.
+func (d *memberDescriptor) slot(f *Frame, inst *Object) (reflect.Value, *BaseException) {
+	if !inst.isInstance(d.objclass) {
+		format := "descriptor '%s' for '%s' objects doesn't apply to '%s' objects"
+		return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf(format, d.name, d.objclass.Name(), inst.typ.Name()))
+	}
+	// The basis of inst's type begins with objclass's basis so the slots
+	// are at the same offset in inst regardless of its type.
+	return reflect.NewAt(d.objclass.basis, unsafe.Pointer(inst)).Elem().Field(1).Index(d.index), nil
+}
+
+func initMemberDescriptorType(map[string]*Object) {
+	memberDescriptorType.flags &= ^(typeFlagInstantiable | typeFlagBasetype)
+	memberDescriptorType.slots.Delete = &deleteSlot{memberDescriptorDelete}
+	memberDescriptorType.slots.Get = &getSlot{memberDescriptorGet}
+	memberDescriptorType.slots.Repr = &unaryOpSlot{memberDescriptorRepr}
+	memberDescriptorType.slots.Set = &setSlot{memberDescriptorSet}
+}
+
+func memberDescriptorDelete(f *Frame, desc, inst *Object) *BaseException {
+	d := toMemberDescriptorUnsafe(desc)
+	v, raised := d.slot(f, inst)
+	if raised != nil {
+		return raised
+	}
+	if v.IsNil() {
+		return f.RaiseType(AttributeErrorType, d.name)
+	}
+	v.Set(reflect.Zero(objectPtrType))
+	return nil
+}
+
+func memberDescriptorGet(f *Frame, desc, instance *Object, _ *Type) (*Object, *BaseException) {
+	if instance == nil || instance == None {
+		// Accessed via the class so return the descriptor itself.
+		return desc, nil
+	}
+	d := toMemberDescriptorUnsafe(desc)
+	v, raised := d.slot(f, instance)
+	if raised != nil {
+		return nil, raised
+	}
+	o := v.Interface().(*Object)
+	if o == nil {
+		return nil, f.RaiseType(AttributeErrorType, d.name)
+	}
+	return o, nil
+}
+
+func memberDescriptorRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	d := toMemberDescriptorUnsafe(o)
+	return NewStr(fmt.Sprintf("<member '%s' of '%s' objects>", d.name, d.objclass.Name())).ToObject(), nil
+}
+
+func memberDescriptorSet(f *Frame, desc, inst, value *Object) *BaseException {
+	v, raised := toMemberDescriptorUnsafe(desc).slot(f, inst)
+	if raised != nil {
+		return raised
+	}
+	v.Set(reflect.ValueOf(value))
+	return nil
+}
+
 // makeStructFieldDescriptor creates a descriptor with a getter that returns
 // the field given by fieldName from t's basis structure.
 func makeStructFieldDescriptor(t *Type, fieldName, propertyName string, fieldMode fieldDescriptorType) *Object {
@@ -34,10 +34,12 @@ func TestPropertyDelete(t *testing.T) {
 
 func TestPropertyGet(t *testing.T) {
 	dummy := newObject(ObjectType)
+	prop := newProperty(None, None, None).ToObject()
 	cases := []invokeTestCase{
 		{args: wrapArgs(newProperty(wrapFuncForTest(func(f *Frame, o *Object) (*Object, *BaseException) { return o, nil }), nil, nil), dummy, ObjectType), want: dummy},
 		{args: wrapArgs(newProperty(wrapFuncForTest(func(f *Frame, o *Object) (*Object, *BaseException) { return nil, f.RaiseType(ValueErrorType, "bar") }), nil, nil), dummy, ObjectType), wantExc: mustCreateException(ValueErrorType, "bar")},
 		{args: wrapArgs(newProperty(nil, nil, nil), dummy, ObjectType), wantExc: mustCreateException(AttributeErrorType, "unreadable attribute")},
+		{args: wrapArgs(prop, None, ObjectType), want: prop},
 	}
 	for _, cas := range cases {
 		if err := runInvokeMethodTestCase(PropertyType, "__get__", &cas); err != "" {
@@ -60,7 +62,40 @@ func TestPropertyInit(t *testing.T) {
 		{args: wrapArgs("foo"), want: newTestTuple("foo", None, None).ToObject()},
 		{args: wrapArgs("foo", None), want: newTestTuple("foo", None, None).ToObject()},
 		{args: wrapArgs("foo", None, "bar"), want: newTestTuple("foo", None, "bar").ToObject()},
-		{args: wrapArgs(1, 2, 3, 4), wantExc: mustCreateException(TypeErrorType, "'__init__' requires 3 arguments")},
+		{args: wrapArgs(1, 2, 3, 4, 5), wantExc: mustCreateException(TypeErrorType, "property() takes 4 arguments (5 given)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPropertyAccessors(t *testing.T) {
+	fget := newBuiltinFunction("fget", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil }).ToObject()
+	fset := newBuiltinFunction("fset", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil }).ToObject()
+	fdel := newBuiltinFunction("fdel", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil }).ToObject()
+	fun := wrapFuncForTest(func(f *Frame, p *Property, method string, arg *Object) (*Tuple, *BaseException) {
+		m, raised := GetAttr(f, p.ToObject(), NewStr(method), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		o, raised := m.Call(f, Args{arg}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if o == p.ToObject() {
+			return nil, f.RaiseType(AssertionErrorType, "property was not copied")
+		}
+		q := toPropertyUnsafe(o)
+		return NewTuple(q.get, q.set, q.del, q.doc), nil
+	})
+	withDoc := mustNotRaise(PropertyType.Call(NewRootFrame(), wrapArgs(fget, None, None, "doc"), nil))
+	cases := []invokeTestCase{
+		{args: wrapArgs(newProperty(None, None, None), "getter", fget), want: NewTuple(fget, None, None, None).ToObject()},
+		{args: wrapArgs(newProperty(fget, None, None), "setter", fset), want: NewTuple(fget, fset, None, None).ToObject()},
+		{args: wrapArgs(newProperty(fget, fset, None), "deleter", fdel), want: NewTuple(fget, fset, fdel, None).ToObject()},
+		{args: wrapArgs(withDoc, "setter", fset), want: newTestTuple(fget, fset, None, "doc").ToObject()},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync/atomic"
 	"unsafe"
 )
@@ -29,6 +30,7 @@ var (
 	dictKeyIteratorType   = newBasisType("dictionary-keyiterator", reflect.TypeOf(dictKeyIterator{}), toDictKeyIteratorUnsafe, ObjectType)
 	dictValueIteratorType = newBasisType("dictionary-valueiterator", reflect.TypeOf(dictValueIterator{}), toDictValueIteratorUnsafe, ObjectType)
 	deletedEntry          = &dictEntry{}
+	dictKeysName          = InternStr("keys")
 )
 
 const (
@@ -37,9 +39,12 @@ const (
 	// number representable as int32.
 	maxDictSize = 1 << 30
 	minDictSize = 8
+	// dictSnapshotAttempts is the number of times Dict.snapshot reads the
+	// table without locking before it gives up and acquires the mutex.
+	dictSnapshotAttempts = 3
 )
 
-// dictEntry represents a slot in the hash table of a Dict. Entries are
+// dictEntry represents a key/value pair stored in a Dict. Entries are
 // intended to be immutable so that they can be read atomically.
 type dictEntry struct {
 	hash  int
@@ -47,13 +52,22 @@ type dictEntry struct {
 	value *Object
 }
 
-// dictTable is the hash table underlying Dict.
+// dictTable is the hash table underlying Dict. Like CPython's compact dict it
+// is split in two: entries holds the dict's entries densely in insertion order
+// and indices is an open addressing hash table of positions in entries. This
+// makes iteration follow insertion order.
 type dictTable struct {
-	// used is the number of slots in the entries table that contain values.
+	// used is the number of live entries in the table.
 	used int32
-	// fill is the number of slots that are used or once were used but have
-	// since been cleared. Thus used <= fill <= len(entries).
+	// fill is the number of elements of entries that have been populated,
+	// including those that have since been deleted. Entries are only ever
+	// appended so new entries are written to entries[fill].
 	fill int
+	// indices is the hash table proper. Each slot is either
+	// dictSlotEmpty, dictSlotDummy for a slot whose entry was deleted, or
+	// one more than the position in entries of the slot's entry. Its
+	// length is a power of two.
+	indices []int32
 	// entries is a slice of immutable dict entries. Although elements in
 	// the slice will be modified to point to different dictEntry objects
 	// as the dictionary is updated, the slice itself (i.e. location in
@@ -63,21 +77,29 @@ type dictTable struct {
 	entries []*dictEntry
 }
 
-// newDictTable allocates a table where at least minCapacity entries can be
-// accommodated. minCapacity must be <= maxDictSize.
+const (
+	dictSlotEmpty = 0
+	dictSlotDummy = -1
+)
+
+// newDictTable allocates a table with at least minCapacity hash slots.
+// minCapacity must be <= maxDictSize.
 func newDictTable(minCapacity int) *dictTable {
 	// This takes the given capacity and sets all bits less than the highest bit.
 	// Adding 1 to that value causes the number to become a multiple of 2 again.
 	// The minDictSize is mixed in to make sure the resulting value is at least
 	// that big. This implementation makes the function able to be inlined, as
 	// well as allows for complete evaluation of constants at compile time.
-	numEntries := (minDictSize - 1) | minCapacity
-	numEntries |= numEntries >> 1
-	numEntries |= numEntries >> 2
-	numEntries |= numEntries >> 4
-	numEntries |= numEntries >> 8
-	numEntries |= numEntries >> 16
-	return &dictTable{entries: make([]*dictEntry, numEntries+1)}
+	numSlots := (minDictSize - 1) | minCapacity
+	numSlots |= numSlots >> 1
+	numSlots |= numSlots >> 2
+	numSlots |= numSlots >> 4
+	numSlots |= numSlots >> 8
+	numSlots |= numSlots >> 16
+	numSlots++
+	// Like CPython, only two thirds of the slots are usable so that
+	// probe sequences stay short.
+	return &dictTable{indices: make([]int32, numSlots), entries: make([]*dictEntry, numSlots*2/3)}
 }
 
 // loadEntry atomically loads the i'th entry in t and returns it.
@@ -92,6 +114,16 @@ func (t *dictTable) storeEntry(i int, entry *dictEntry) {
 	atomic.StorePointer(p, unsafe.Pointer(entry))
 }
 
+// loadSlot atomically loads the value of the slot'th hash slot in t.
+func (t *dictTable) loadSlot(slot int) int32 {
+	return atomic.LoadInt32(&t.indices[slot])
+}
+
+// storeSlot atomically sets the value of the slot'th hash slot in t.
+func (t *dictTable) storeSlot(slot int, value int32) {
+	atomic.StoreInt32(&t.indices[slot], value)
+}
+
 func (t *dictTable) loadUsed() int {
 	return int(atomic.LoadInt32(&t.used))
 }
@@ -100,92 +132,137 @@ func (t *dictTable) incUsed(n int) {
 	atomic.AddInt32(&t.used, int32(n))
 }
 
-// insertAbsentEntry adds the populated entry to t assuming that the key
-// specified in entry is absent from t. Since the key is absent, no key
-// comparisons are necessary to perform the insert.
+// insertAbsentEntry appends the populated entry to t assuming that the key
+// specified in entry is absent from t and that t has room for it. Since the
+// key is absent, no key comparisons are necessary to perform the insert.
 func (t *dictTable) insertAbsentEntry(entry *dictEntry) {
-	mask := uint(len(t.entries) - 1)
+	mask := uint(len(t.indices) - 1)
 	i := uint(entry.hash) & mask
 	perturb := uint(entry.hash)
-	index := i
+	slot := i
 	// The key we're trying to insert is known to be absent from the dict
-	// so probe for the first nil entry.
-	for ; t.entries[index] != nil; index = i & mask {
+	// so probe for the first empty slot.
+	for ; t.indices[slot] != dictSlotEmpty; slot = i & mask {
 		i, perturb = dictNextIndex(i, perturb)
 	}
-	t.entries[index] = entry
-	t.incUsed(1)
+	t.entries[t.fill] = entry
 	t.fill++
+	t.indices[slot] = int32(t.fill)
+	t.incUsed(1)
 }
 
-// lookupEntry returns the index and entry in t with the given hash and key.
-// Elements in the table are updated with immutable entries atomically and
-// lookupEntry loads them atomically. So it is not necessary to lock the dict
-// to do entry lookups in a consistent way.
-func (t *dictTable) lookupEntry(f *Frame, hash int, key *Object) (int, *dictEntry, *BaseException) {
-	mask := uint(len(t.entries) - 1)
+// lookupEntry returns the hash slot, the position in entries and the entry in
+// t with the given hash and key. If key is not present, entry is nil and slot
+// is the slot where it should be inserted. Elements in the table are updated
+// atomically and lookupEntry loads them atomically. So it is not necessary to
+// lock the dict to do entry lookups in a consistent way.
+func (t *dictTable) lookupEntry(f *Frame, hash int, key *Object) (int, int, *dictEntry, *BaseException) {
+	mask := uint(len(t.indices) - 1)
 	i, perturb := uint(hash)&mask, uint(hash)
-	// free is the first slot that's available. We don't immediately use it
-	// because it has been previously used and therefore an exact match may
-	// be found further on.
+	// free is the first dummy slot. We don't immediately use it because
+	// an exact match may be found further on.
 	free := -1
-	var freeEntry *dictEntry
-	index := int(i & mask)
-	entry := t.loadEntry(index)
-	for {
-		if entry == nil {
+	for slot := int(i & mask); ; slot = int(i & mask) {
+		switch index := t.loadSlot(slot); index {
+		case dictSlotEmpty:
 			if free != -1 {
-				index = free
-				// Store the entry instead of fetching by index
-				// later since it may have changed by then.
-				entry = freeEntry
+				slot = free
 			}
-			break
-		}
-		if entry == deletedEntry {
+			return slot, -1, nil, nil
+		case dictSlotDummy:
 			if free == -1 {
-				free = index
+				free = slot
 			}
-		} else if entry.hash == hash {
-			o, raised := Eq(f, entry.key, key)
-			if raised != nil {
-				return -1, nil, raised
-			}
-			eq, raised := IsTrue(f, o)
-			if raised != nil {
-				return -1, nil, raised
-			}
-			if eq {
-				break
+		default:
+			pos := int(index - 1)
+			entry := t.loadEntry(pos)
+			if entry == deletedEntry {
+				// The entry was deleted after the slot was
+				// loaded so treat it like a dummy slot.
+				if free == -1 {
+					free = slot
+				}
+			} else if entry.key == key {
+				// Like CPython, identical keys are equal without
+				// calling __eq__. This is the common case for
+				// name lookups.
+				return slot, pos, entry, nil
+			} else if entry.hash == hash {
+				o, raised := Eq(f, entry.key, key)
+				if raised != nil {
+					return -1, -1, nil, raised
+				}
+				eq, raised := IsTrue(f, o)
+				if raised != nil {
+					return -1, -1, nil, raised
+				}
+				if eq {
+					return slot, pos, entry, nil
+				}
 			}
 		}
 		i, perturb = dictNextIndex(i, perturb)
-		index = int(i & mask)
-		entry = t.loadEntry(index)
 	}
-	return index, entry, nil
 }
 
-// writeEntry replaces t's entry at the given index with entry. If writing
-// entry would cause t's fill ratio to grow too large then a new table is
-// created, the entry is instead inserted there and that table is returned. t
-// remains unchanged. When a sufficiently sized table cannot be created, false
-// will be returned for the second value, otherwise true will be returned.
-func (t *dictTable) writeEntry(f *Frame, index int, entry *dictEntry) (*dictTable, bool) {
-	if t.entries[index] == deletedEntry {
-		t.storeEntry(index, entry)
-		t.incUsed(1)
-		return nil, true
+// findSlot returns the hash slot in t referring to the entry at position pos,
+// which must be present in t.
+func (t *dictTable) findSlot(pos int) int {
+	hash := t.entries[pos].hash
+	mask := uint(len(t.indices) - 1)
+	i, perturb := uint(hash)&mask, uint(hash)
+	for slot := int(i & mask); ; slot = int(i & mask) {
+		if t.indices[slot] == int32(pos+1) {
+			return slot
+		}
+		i, perturb = dictNextIndex(i, perturb)
+	}
+}
+
+// deleteEntry removes the entry at position pos, referred to by the given
+// hash slot, from t.
+func (t *dictTable) deleteEntry(slot, pos int) {
+	t.storeSlot(slot, dictSlotDummy)
+	t.storeEntry(pos, deletedEntry)
+	t.incUsed(-1)
+}
+
+// popEntry removes and returns the most recently inserted entry in t, or nil if
+// t is empty. The space it occupied is reclaimed so that repeatedly popping
+// entries doesn't leave a growing run of deleted entries to skip over.
+func (t *dictTable) popEntry() *dictEntry {
+	pos := t.fill - 1
+	for pos >= 0 && t.entries[pos] == deletedEntry {
+		pos--
 	}
-	if t.entries[index] != nil {
-		t.storeEntry(index, entry)
+	if pos < 0 {
+		return nil
+	}
+	entry := t.entries[pos]
+	t.deleteEntry(t.findSlot(pos), pos)
+	t.fill = pos
+	return entry
+}
+
+// writeEntry associates entry with the key found by lookupEntry at the given
+// hash slot and position. An existing entry is replaced in place so the key
+// keeps its position in the iteration order. A new entry is appended, unless
+// t is full in which case a new table holding t's live entries followed by
+// entry is created and returned. t remains unchanged. When a sufficiently
+// sized table cannot be created, false will be returned for the second value,
+// otherwise true will be returned.
+func (t *dictTable) writeEntry(f *Frame, slot, pos int, entry *dictEntry) (*dictTable, bool) {
+	if pos != -1 {
+		t.storeEntry(pos, entry)
 		return nil, true
 	}
-	if (t.fill+1)*3 <= len(t.entries)*2 {
-		// New entry does not necessitate growing the table.
-		t.storeEntry(index, entry)
-		t.incUsed(1)
+	if t.fill < len(t.entries) {
+		// Publish the entry before the slot that refers to it so
+		// that concurrent lookups never observe an unpopulated entry.
+		t.storeEntry(t.fill, entry)
 		t.fill++
+		t.storeSlot(slot, int32(t.fill))
+		t.incUsed(1)
 		return nil, true
 	}
 	// Grow the table.
@@ -199,7 +276,7 @@ func (t *dictTable) writeEntry(f *Frame, index int, entry *dictEntry) (*dictTabl
 	}
 	newTable := newDictTable(n)
 	for _, oldEntry := range t.entries {
-		if oldEntry != nil && oldEntry != deletedEntry {
+		if oldEntry != deletedEntry {
 			newTable.insertAbsentEntry(oldEntry)
 		}
 	}
@@ -207,40 +284,39 @@ func (t *dictTable) writeEntry(f *Frame, index int, entry *dictEntry) (*dictTabl
 	return newTable, true
 }
 
-// dictEntryIterator is used to iterate over the entries in a dictTable in an
-// arbitrary order.
+// dictEntryIterator is used to iterate over the entries in a dictTable in
+// insertion order.
 type dictEntryIterator struct {
 	index int64
 	table *dictTable
 }
 
-// newDictEntryIterator creates a dictEntryIterator object for d. It assumes
-// that d.mutex is held by the caller.
+// newDictEntryIterator creates a dictEntryIterator object for d. The
+// dictVersionGuard that accompanies the iterator must be created first so
+// that any write the iterator observes also invalidates the guard.
 func newDictEntryIterator(d *Dict) dictEntryIterator {
 	return dictEntryIterator{table: d.loadTable()}
 }
 
-// next advances this iterator to the next occupied entry and returns it. The
-// second return value is true if the dict changed since iteration began, false
-// otherwise.
+// next advances this iterator to the next live entry and returns it, or nil
+// when there are no more entries.
 func (iter *dictEntryIterator) next() *dictEntry {
 	numEntries := len(iter.table.entries)
-	var entry *dictEntry
-	for entry == nil {
+	for {
 		// 64bit atomic ops need to be 8 byte aligned. This compile time check
 		// verifies alignment by creating a negative constant for an unsigned type.
 		// See sync/atomic docs for details.
 		const blank = -(unsafe.Offsetof(iter.index) % 8)
 		index := int(atomic.AddInt64(&iter.index, 1)) - 1
 		if index >= numEntries {
-			break
+			return nil
 		}
-		entry = iter.table.loadEntry(index)
-		if entry == deletedEntry {
-			entry = nil
+		// Entries are populated in order so a nil entry marks the end
+		// of the table's contents.
+		if entry := iter.table.loadEntry(index); entry != deletedEntry {
+			return entry
 		}
 	}
-	return entry
 }
 
 // dictVersionGuard is used to detect when a dict has been modified.
@@ -261,6 +337,13 @@ func (g *dictVersionGuard) check() bool {
 
 // Dict represents Python 'dict' objects. The public methods of *Dict are
 // thread safe.
+//
+// Reads never acquire the mutex. Entries are immutable and writers publish
+// each change with a single atomic store, either of an entry or of a whole new
+// table, followed by a version increment. So lookups see a table that d was
+// actually in and bulk reads like keys() can detect that they raced with a
+// write by checking the version. Only writes are serialized by the mutex, so
+// concurrent lookups of module globals and attributes do not contend.
 type Dict struct {
 	Object
 	table *dictTable
@@ -270,6 +353,11 @@ type Dict struct {
 	// version is incremented whenever the Dict is modified. See:
 	// https://www.python.org/dev/peps/pep-0509/
 	version int64
+	// types points to the list of types that use the Dict as their dict.
+	// Modifying the Dict invalidates those types' attribute caches. The
+	// list is never mutated in place, only replaced atomically. See
+	// addType.
+	types *[]*Type
 }
 
 // NewDict returns an empty Dict.
@@ -281,10 +369,16 @@ func newStringDict(items map[string]*Object) *Dict {
 	if len(items) > maxDictSize/2 {
 		panic(fmt.Sprintf("dictionary too big: %d", len(items)))
 	}
-	n := len(items) * 2
-	table := newDictTable(n)
-	for key, value := range items {
-		table.insertAbsentEntry(&dictEntry{hashString(key), NewStr(key).ToObject(), value})
+	// Insert the keys in sorted order so that iteration order doesn't
+	// depend on Go's randomized map iteration.
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	table := newDictTable(len(items) * 2)
+	for _, key := range keys {
+		table.insertAbsentEntry(&dictEntry{hashString(key), NewStr(key).ToObject(), items[key]})
 	}
 	return &Dict{Object: Object{typ: DictType}, table: table}
 }
@@ -321,6 +415,40 @@ func (d *Dict) incVersion() {
 	// See sync/atomic docs for details.
 	const blank = -(unsafe.Offsetof(d.version) % 8)
 	atomic.AddInt64(&d.version, 1)
+	for _, t := range d.loadTypes() {
+		t.invalidateAttrCache()
+	}
+}
+
+// loadTypes atomically loads and returns the types that use d as their dict.
+func (d *Dict) loadTypes() []*Type {
+	p := (*unsafe.Pointer)(unsafe.Pointer(&d.types))
+	if types := (*[]*Type)(atomic.LoadPointer(p)); types != nil {
+		return *types
+	}
+	return nil
+}
+
+// addType atomically records that t uses d as its dict. d may already be
+// visible to other goroutines so the list is copied and swapped in.
+func (d *Dict) addType(t *Type) {
+	p := (*unsafe.Pointer)(unsafe.Pointer(&d.types))
+	for {
+		old := atomic.LoadPointer(p)
+		var types []*Type
+		if old != nil {
+			types = *(*[]*Type)(old)
+		}
+		for _, u := range types {
+			if u == t {
+				return
+			}
+		}
+		types = append(types[:len(types):len(types)], t)
+		if atomic.CompareAndSwapPointer(p, old, unsafe.Pointer(&types)) {
+			return
+		}
+	}
 }
 
 // DelItem removes the entry associated with key from d. It returns true if an
@@ -346,11 +474,11 @@ func (d *Dict) GetItem(f *Frame, key *Object) (*Object, *BaseException) {
 	if raised != nil {
 		return nil, raised
 	}
-	_, entry, raised := d.loadTable().lookupEntry(f, hash.Value(), key)
+	_, _, entry, raised := d.loadTable().lookupEntry(f, hash.Value(), key)
 	if raised != nil {
 		return nil, raised
 	}
-	if entry != nil && entry != deletedEntry {
+	if entry != nil {
 		return entry.value, nil
 	}
 	return nil, nil
@@ -370,16 +498,11 @@ func (d *Dict) Pop(f *Frame, key *Object) (*Object, *BaseException) {
 
 // Keys returns a list containing all the keys in d.
 func (d *Dict) Keys(f *Frame) *List {
-	d.mutex.Lock(f)
-	keys := make([]*Object, d.Len())
-	i := 0
-	for _, entry := range d.table.entries {
-		if entry != nil && entry != deletedEntry {
-			keys[i] = entry.key
-			i++
-		}
+	entries := d.snapshot(f)
+	keys := make([]*Object, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.key
 	}
-	d.mutex.Unlock(f)
 	return NewList(keys...)
 }
 
@@ -388,6 +511,36 @@ func (d *Dict) Len() int {
 	return d.loadTable().loadUsed()
 }
 
+// snapshot returns the entries of d as of some point in time. It does not
+// acquire the mutex unless it keeps racing with concurrent writes.
+func (d *Dict) snapshot(f *Frame) []*dictEntry {
+	for attempt := 0; ; attempt++ {
+		locked := attempt >= dictSnapshotAttempts
+		if locked {
+			d.mutex.Lock(f)
+		}
+		v := d.loadVersion()
+		t := d.loadTable()
+		entries := make([]*dictEntry, 0, t.loadUsed())
+		for i := range t.entries {
+			entry := t.loadEntry(i)
+			if entry == nil {
+				break
+			}
+			if entry != deletedEntry {
+				entries = append(entries, entry)
+			}
+		}
+		if locked {
+			d.mutex.Unlock(f)
+			return entries
+		}
+		if d.loadVersion() == v {
+			return entries
+		}
+	}
+}
+
 // putItem associates value with key in d, returning the old associated value if
 // the key was added, or nil if it was not already present in d.
 func (d *Dict) putItem(f *Frame, key, value *Object, overwrite bool) (*Object, *BaseException) {
@@ -398,7 +551,7 @@ func (d *Dict) putItem(f *Frame, key, value *Object, overwrite bool) (*Object, *
 	d.mutex.Lock(f)
 	t := d.table
 	v := d.version
-	index, entry, raised := t.lookupEntry(f, hash.Value(), key)
+	slot, pos, entry, raised := t.lookupEntry(f, hash.Value(), key)
 	var originValue *Object
 	if raised == nil {
 		if v != d.version {
@@ -408,14 +561,13 @@ func (d *Dict) putItem(f *Frame, key, value *Object, overwrite bool) (*Object, *
 		} else {
 			if value == nil {
 				// Going to delete the entry.
-				if entry != nil && entry != deletedEntry {
-					d.table.storeEntry(index, deletedEntry)
-					d.table.incUsed(-1)
+				if entry != nil {
+					t.deleteEntry(slot, pos)
 					d.incVersion()
 				}
 			} else if overwrite || entry == nil {
 				newEntry := &dictEntry{hash.Value(), key, value}
-				if newTable, ok := t.writeEntry(f, index, newEntry); ok {
+				if newTable, ok := t.writeEntry(f, slot, pos, newEntry); ok {
 					if newTable != nil {
 						d.storeTable(newTable)
 					}
@@ -424,7 +576,7 @@ func (d *Dict) putItem(f *Frame, key, value *Object, overwrite bool) (*Object, *
 					raised = f.RaiseType(OverflowErrorType, errResultTooLarge)
 				}
 			}
-			if entry != nil && entry != deletedEntry {
+			if entry != nil {
 				originValue = entry.value
 			}
 		}
@@ -449,21 +601,26 @@ func (d *Dict) ToObject() *Object {
 	return &d.Object
 }
 
-// Update copies the items from the mapping or sequence of 2-tuples o into d.
+// Update copies the items from o into d. Like CPython, o is treated as a
+// mapping if it has a keys method and as a sequence of 2-tuples otherwise.
 func (d *Dict) Update(f *Frame, o *Object) (raised *BaseException) {
 	var iter *Object
 	if o.isInstance(DictType) {
-		d2 := toDictUnsafe(o)
-		d2.mutex.Lock(f)
-		// Concurrent modifications to d2 will cause Update to raise
+		// Concurrent modifications to o will cause Update to raise
 		// "dictionary changed during iteration".
-		iter = newDictItemIterator(d2).ToObject()
-		d2.mutex.Unlock(f)
+		iter = newDictItemIterator(toDictUnsafe(o)).ToObject()
 	} else {
+		keys, raised := GetAttr(f, o, dictKeysName, None)
+		if raised != nil {
+			return raised
+		}
+		if keys != None {
+			return d.updateFromMapping(f, o, keys)
+		}
 		iter, raised = Iter(f, o)
-	}
-	if raised != nil {
-		return raised
+		if raised != nil {
+			return raised
+		}
 	}
 	return seqForEach(f, iter, func(item *Object) *BaseException {
 		return seqApply(f, item, func(elems []*Object, _ bool) *BaseException {
@@ -476,6 +633,22 @@ func (d *Dict) Update(f *Frame, o *Object) (raised *BaseException) {
 	})
 }
 
+// updateFromMapping copies the items of the mapping o into d using the
+// given bound keys method to enumerate o's keys.
+func (d *Dict) updateFromMapping(f *Frame, o, keys *Object) *BaseException {
+	keyList, raised := keys.Call(f, nil, nil)
+	if raised != nil {
+		return raised
+	}
+	return seqForEach(f, keyList, func(key *Object) *BaseException {
+		value, raised := GetItem(f, o, key)
+		if raised != nil {
+			return raised
+		}
+		return d.SetItem(f, key, value)
+	})
+}
+
 // dictsAreEqual returns true if d1 and d2 have the same keys and values, false
 // otherwise. If either d1 or d2 are concurrently modified then RuntimeError is
 // raised.
@@ -483,17 +656,10 @@ func dictsAreEqual(f *Frame, d1, d2 *Dict) (bool, *BaseException) {
 	if d1 == d2 {
 		return true, nil
 	}
-	// Do not hold both locks at the same time to avoid deadlock.
-	d1.mutex.Lock(f)
-	iter := newDictEntryIterator(d1)
 	g1 := newDictVersionGuard(d1)
-	len1 := d1.Len()
-	d1.mutex.Unlock(f)
-	d2.mutex.Lock(f)
-	g2 := newDictVersionGuard(d1)
-	len2 := d2.Len()
-	d2.mutex.Unlock(f)
-	if len1 != len2 {
+	iter := newDictEntryIterator(d1)
+	g2 := newDictVersionGuard(d2)
+	if iter.table.loadUsed() != d2.Len() {
 		return false, nil
 	}
 	result := true
@@ -525,7 +691,7 @@ func dictClear(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	}
 	d := toDictUnsafe(args[0])
 	d.mutex.Lock(f)
-	d.table = newDictTable(0)
+	d.storeTable(newDictTable(0))
 	d.incVersion()
 	d.mutex.Unlock(f)
 	return None, nil
@@ -568,6 +734,38 @@ func dictEq(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return GetBool(eq).ToObject(), nil
 }
 
+func dictFromKeys(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	argc := len(args)
+	if argc == 1 {
+		return nil, f.RaiseType(TypeErrorType, "fromkeys expected at least 1 arguments, got 0")
+	}
+	if argc > 3 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("fromkeys expected at most 2 arguments, got %v", argc-1))
+	}
+	expectedTypes := []*Type{TypeType, ObjectType, ObjectType}
+	if argc == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkFunctionArgs(f, "fromkeys", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	value := None
+	if argc > 2 {
+		value = args[2]
+	}
+	o, raised := args[0].Call(f, nil, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	raised = seqForEach(f, args[1], func(key *Object) *BaseException {
+		return SetItem(f, o, key, value)
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	return o, nil
+}
+
 func dictGet(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	expectedTypes := []*Type{DictType, ObjectType, ObjectType}
 	argc := len(args)
@@ -598,22 +796,19 @@ func dictItems(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "items", args, DictType); raised != nil {
 		return nil, raised
 	}
-	d := toDictUnsafe(args[0])
-	d.mutex.Lock(f)
-	iter := newDictItemIterator(d).ToObject()
-	d.mutex.Unlock(f)
-	return ListType.Call(f, Args{iter}, nil)
+	entries := toDictUnsafe(args[0]).snapshot(f)
+	items := make([]*Object, len(entries))
+	for i, entry := range entries {
+		items[i] = NewTuple2(entry.key, entry.value).ToObject()
+	}
+	return NewList(items...).ToObject(), nil
 }
 
 func dictIterItems(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "iteritems", args, DictType); raised != nil {
 		return nil, raised
 	}
-	d := toDictUnsafe(args[0])
-	d.mutex.Lock(f)
-	iter := newDictItemIterator(d).ToObject()
-	d.mutex.Unlock(f)
-	return iter, nil
+	return newDictItemIterator(toDictUnsafe(args[0])).ToObject(), nil
 }
 
 func dictIterKeys(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -627,11 +822,7 @@ func dictIterValues(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException
 	if raised := checkMethodArgs(f, "itervalues", args, DictType); raised != nil {
 		return nil, raised
 	}
-	d := toDictUnsafe(args[0])
-	d.mutex.Lock(f)
-	iter := newDictValueIterator(d).ToObject()
-	d.mutex.Unlock(f)
-	return iter, nil
+	return newDictValueIterator(toDictUnsafe(args[0])).ToObject(), nil
 }
 
 func dictKeys(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -647,6 +838,17 @@ func dictGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
 		return nil, raised
 	}
 	if item == nil {
+		// Like CPython, subclasses may supply values for missing keys
+		// by defining __missing__.
+		if o.typ != DictType {
+			missing, raised := o.typ.mroLookup(f, NewStr("__missing__"))
+			if raised != nil {
+				return nil, raised
+			}
+			if missing != nil {
+				return missing.Call(f, Args{o, key}, nil)
+			}
+		}
 		return nil, raiseKeyError(f, key)
 	}
 	return item, nil
@@ -676,11 +878,7 @@ func dictInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseExce
 }
 
 func dictIter(f *Frame, o *Object) (*Object, *BaseException) {
-	d := toDictUnsafe(o)
-	d.mutex.Lock(f)
-	iter := newDictKeyIterator(d).ToObject()
-	d.mutex.Unlock(f)
-	return iter, nil
+	return newDictKeyIterator(toDictUnsafe(o)).ToObject(), nil
 }
 
 func dictLen(f *Frame, o *Object) (*Object, *BaseException) {
@@ -702,7 +900,7 @@ func dictNE(f *Frame, v, w *Object) (*Object, *BaseException) {
 
 func dictNew(f *Frame, t *Type, _ Args, _ KWArgs) (*Object, *BaseException) {
 	d := toDictUnsafe(newObject(t))
-	d.table = &dictTable{entries: make([]*dictEntry, minDictSize, minDictSize)}
+	d.table = newDictTable(0)
 	return d.ToObject(), nil
 }
 
@@ -734,14 +932,10 @@ func dictPopItem(f *Frame, args Args, _ KWArgs) (item *Object, raised *BaseExcep
 	}
 	d := toDictUnsafe(args[0])
 	d.mutex.Lock(f)
-	iter := newDictEntryIterator(d)
-	entry := iter.next()
-	if entry == nil {
+	if entry := d.table.popEntry(); entry == nil {
 		raised = f.RaiseType(KeyErrorType, "popitem(): dictionary is empty")
 	} else {
 		item = NewTuple(entry.key, entry.value).ToObject()
-		d.table.storeEntry(int(iter.index-1), deletedEntry)
-		d.table.incUsed(-1)
 		d.incVersion()
 	}
 	d.mutex.Unlock(f)
@@ -754,15 +948,11 @@ func dictRepr(f *Frame, o *Object) (*Object, *BaseException) {
 		return NewStr("{...}").ToObject(), nil
 	}
 	defer f.reprLeave(d.ToObject())
-	// Lock d so that we get a consistent view of it. Otherwise we may
-	// return a state that d was never actually in.
-	d.mutex.Lock(f)
-	defer d.mutex.Unlock(f)
+	// Take a snapshot so that we get a consistent view of d. Otherwise we
+	// may return a state that d was never actually in.
 	var buf bytes.Buffer
 	buf.WriteString("{")
-	iter := newDictEntryIterator(d)
-	i := 0
-	for entry := iter.next(); entry != nil; entry = iter.next() {
+	for i, entry := range d.snapshot(f) {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
@@ -776,7 +966,6 @@ func dictRepr(f *Frame, o *Object) (*Object, *BaseException) {
 			return nil, raised
 		}
 		buf.WriteString(s.Value())
-		i++
 	}
 	buf.WriteString("}")
 	return NewStr(buf.String()).ToObject(), nil
@@ -850,9 +1039,31 @@ func dictValues(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return ListType.Call(f, Args{iter}, nil)
 }
 
+func dictViewItems(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "viewitems", args, DictType); raised != nil {
+		return nil, raised
+	}
+	return newDictItemsView(toDictUnsafe(args[0])).ToObject(), nil
+}
+
+func dictViewKeys(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "viewkeys", args, DictType); raised != nil {
+		return nil, raised
+	}
+	return newDictKeysView(toDictUnsafe(args[0])).ToObject(), nil
+}
+
+func dictViewValues(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "viewvalues", args, DictType); raised != nil {
+		return nil, raised
+	}
+	return newDictValuesView(toDictUnsafe(args[0])).ToObject(), nil
+}
+
 func initDictType(dict map[string]*Object) {
 	dict["clear"] = newBuiltinFunction("clear", dictClear).ToObject()
 	dict["copy"] = newBuiltinFunction("copy", dictCopy).ToObject()
+	dict["fromkeys"] = newClassMethod(newBuiltinFunction("fromkeys", dictFromKeys).ToObject()).ToObject()
 	dict["get"] = newBuiltinFunction("get", dictGet).ToObject()
 	dict["has_key"] = newBuiltinFunction("has_key", dictHasKey).ToObject()
 	dict["items"] = newBuiltinFunction("items", dictItems).ToObject()
@@ -865,6 +1076,9 @@ func initDictType(dict map[string]*Object) {
 	dict["setdefault"] = newBuiltinFunction("setdefault", dictSetDefault).ToObject()
 	dict["update"] = newBuiltinFunction("update", dictUpdate).ToObject()
 	dict["values"] = newBuiltinFunction("values", dictValues).ToObject()
+	dict["viewitems"] = newBuiltinFunction("viewitems", dictViewItems).ToObject()
+	dict["viewkeys"] = newBuiltinFunction("viewkeys", dictViewKeys).ToObject()
+	dict["viewvalues"] = newBuiltinFunction("viewvalues", dictViewValues).ToObject()
 	DictType.slots.Contains = &binaryOpSlot{dictContains}
 	DictType.slots.DelItem = &delItemSlot{dictDelItem}
 	DictType.slots.Eq = &binaryOpSlot{dictEq}
@@ -885,13 +1099,13 @@ type dictItemIterator struct {
 	guard dictVersionGuard
 }
 
-// newDictItemIterator creates a dictItemIterator object for d. It assumes that
-// d.mutex is held by the caller.
+// newDictItemIterator creates a dictItemIterator object for d.
 func newDictItemIterator(d *Dict) *dictItemIterator {
+	guard := newDictVersionGuard(d)
 	return &dictItemIterator{
 		Object: Object{typ: dictItemIteratorType},
 		iter:   newDictEntryIterator(d),
-		guard:  newDictVersionGuard(d),
+		guard:  guard,
 	}
 }
 
@@ -928,13 +1142,13 @@ type dictKeyIterator struct {
 	guard dictVersionGuard
 }
 
-// newDictKeyIterator creates a dictKeyIterator object for d. It assumes that
-// d.mutex is held by the caller.
+// newDictKeyIterator creates a dictKeyIterator object for d.
 func newDictKeyIterator(d *Dict) *dictKeyIterator {
+	guard := newDictVersionGuard(d)
 	return &dictKeyIterator{
 		Object: Object{typ: dictKeyIteratorType},
 		iter:   newDictEntryIterator(d),
-		guard:  newDictVersionGuard(d),
+		guard:  guard,
 	}
 }
 
@@ -971,13 +1185,13 @@ type dictValueIterator struct {
 	guard dictVersionGuard
 }
 
-// newDictValueIterator creates a dictValueIterator object for d. It assumes
-// that d.mutex is held by the caller.
+// newDictValueIterator creates a dictValueIterator object for d.
 func newDictValueIterator(d *Dict) *dictValueIterator {
+	guard := newDictVersionGuard(d)
 	return &dictValueIterator{
 		Object: Object{typ: dictValueIteratorType},
 		iter:   newDictEntryIterator(d),
-		guard:  newDictVersionGuard(d),
+		guard:  guard,
 	}
 }
 
@@ -15,6 +15,7 @@
 package grumpy
 
 import (
+	"fmt"
 	"reflect"
 	"regexp"
 	"runtime"
@@ -182,6 +183,35 @@ func TestDictEqNE(t *testing.T) {
 	}
 }
 
+func TestDictFromKeys(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{DictType}, NewDict())
+	fun := wrapFuncForTest(func(f *Frame, o *Object, args ...*Object) (*Object, *BaseException) {
+		fromKeys, raised := GetAttr(f, o, NewStr("fromkeys"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		d, raised := fromKeys.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(d.Type().ToObject(), d).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(DictType, NewTuple()), want: newTestTuple(DictType, NewDict()).ToObject()},
+		{args: wrapArgs(DictType, "abc"), want: newTestTuple(DictType, newTestDict("a", None, "b", None, "c", None)).ToObject()},
+		{args: wrapArgs(newTestDict("foo", 1), newTestList(4, 5), 0), want: newTestTuple(DictType, newTestDict(4, 0, 5, 0)).ToObject()},
+		{args: wrapArgs(fooType, newTestTuple("foo")), want: newTestTuple(fooType, newTestDict("foo", None)).ToObject()},
+		{args: wrapArgs(DictType, 3), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+		{args: wrapArgs(DictType), wantExc: mustCreateException(TypeErrorType, "fromkeys expected at least 1 arguments, got 0")},
+		{args: wrapArgs(DictType, "abc", None, None), wantExc: mustCreateException(TypeErrorType, "fromkeys expected at most 2 arguments, got 3")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestDictGet(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewDict(), "foo"), want: None},
@@ -218,12 +248,24 @@ func TestDictGetItem(t *testing.T) {
 	}
 	deletedItemDict := newTestDict(hashFoo, true, "foo", true)
 	deletedItemDict.DelItem(f, hashFoo)
+	eqRaisesType := newTestClass("Foo", []*Type{IntType}, newStringDict(map[string]*Object{
+		"__eq__": newBuiltinFunction("__eq__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return nil, f.RaiseType(TypeErrorType, "__eq__ called")
+		}).ToObject(),
+	}))
+	eqRaises := newObject(eqRaisesType)
+	eqRaisesDict := NewDict()
+	if raised := eqRaisesDict.SetItem(f, eqRaises, True.ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewDict(), "foo"), want: None},
 		{args: wrapArgs(newStringDict(map[string]*Object{"foo": True.ToObject()}), "foo"), want: True.ToObject()},
 		{args: wrapArgs(newTestDict(2, "bar", "baz", 3.14), 2), want: NewStr("bar").ToObject()},
 		{args: wrapArgs(newTestDict(2, "bar", "baz", 3.14), 3), want: None},
 		{args: wrapArgs(deletedItemDict, hashFoo), want: None},
+		{args: wrapArgs(eqRaisesDict, eqRaises), want: True.ToObject()},
+		{args: wrapArgs(eqRaisesDict, 0), wantExc: mustCreateException(TypeErrorType, "__eq__ called")},
 		{args: wrapArgs(NewDict(), NewList()), wantExc: mustCreateException(TypeErrorType, "unhashable type: 'list'")},
 	}
 	for _, cas := range cases {
@@ -233,6 +275,27 @@ func TestDictGetItem(t *testing.T) {
 	}
 }
 
+func TestDictGetItemMissing(t *testing.T) {
+	missing := newBuiltinFunction("__missing__", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		return NewTuple(args[1:]...).ToObject(), nil
+	}).ToObject()
+	fooType := newTestClass("Foo", []*Type{DictType}, newStringDict(map[string]*Object{"__missing__": missing}))
+	foo := mustNotRaise(fooType.Call(NewRootFrame(), nil, nil))
+	if raised := SetItem(NewRootFrame(), foo, NewStr("bar").ToObject(), NewInt(1).ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
+	cases := []invokeTestCase{
+		{args: wrapArgs(foo, "bar"), want: NewInt(1).ToObject()},
+		{args: wrapArgs(foo, "baz"), want: newTestTuple("baz").ToObject()},
+		{args: wrapArgs(newTestDict("bar", 1), "baz"), wantExc: mustCreateException(KeyErrorType, "baz")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(GetItem), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 // BenchmarkDictGetItem is to keep an eye on the speed of contended dict access
 // in a fast read loop.
 func BenchmarkDictGetItem(b *testing.B) {
@@ -256,6 +319,84 @@ func BenchmarkDictGetItem(b *testing.B) {
 	})
 }
 
+// BenchmarkDictGetItemStr looks up a str key in a dict the size of a typical
+// module's globals, as is done when resolving global names.
+func BenchmarkDictGetItemStr(b *testing.B) {
+	d := NewDict()
+	f := NewRootFrame()
+	for i := 0; i < 50; i++ {
+		d.SetItemString(f, fmt.Sprintf("name%d", i), NewInt(i).ToObject())
+	}
+	key := NewStr("name42").ToObject()
+	d.SetItem(f, key, None)
+	bench := func(k *Object) func(*testing.B) {
+		return func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				f := NewRootFrame()
+				var ret *Object
+				var raised *BaseException
+				for pb.Next() {
+					ret, raised = d.GetItem(f, k)
+				}
+				runtime.KeepAlive(ret)
+				runtime.KeepAlive(raised)
+			})
+		}
+	}
+	b.Run("identical", bench(key))
+	b.Run("equal", bench(NewStr("name42").ToObject()))
+}
+
+// BenchmarkDictGetItemWithWriter measures lookups that run concurrently with
+// a goroutine that keeps updating the dict.
+func BenchmarkDictGetItemWithWriter(b *testing.B) {
+	d := newTestDict("foo", 1, "bar", 2)
+	k := NewStr("foo").ToObject()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f := NewRootFrame()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			d.SetItemString(f, "bar", NewInt(i).ToObject())
+		}
+	}()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		f := NewRootFrame()
+		var ret *Object
+		var raised *BaseException
+		for pb.Next() {
+			ret, raised = d.GetItem(f, k)
+		}
+		runtime.KeepAlive(ret)
+		runtime.KeepAlive(raised)
+	})
+	b.StopTimer()
+	close(stop)
+	<-done
+}
+
+// BenchmarkDictKeys measures concurrent calls to keys(), which do not lock the
+// dict.
+func BenchmarkDictKeys(b *testing.B) {
+	d := newTestDict("foo", 1, "bar", 2, "baz", 3, "qux", 4)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		f := NewRootFrame()
+		var ret *List
+		for pb.Next() {
+			ret = d.Keys(f)
+		}
+		runtime.KeepAlive(ret)
+	})
+}
+
 func BenchmarkDictIterItems(b *testing.B) {
 	bench := func(d *Dict) func(*testing.B) {
 		return func(b *testing.B) {
@@ -451,7 +592,7 @@ func TestDictIter(t *testing.T) {
 	deletedItemDict.DelItem(f, hashFoo)
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewDict()), want: NewTuple().ToObject()},
-		{args: wrapArgs(newStringDict(map[string]*Object{"foo": NewInt(1).ToObject(), "bar": NewInt(2).ToObject()})), want: newTestTuple("foo", "bar").ToObject()},
+		{args: wrapArgs(newStringDict(map[string]*Object{"foo": NewInt(1).ToObject(), "bar": NewInt(2).ToObject()})), want: newTestTuple("bar", "foo").ToObject()},
 		{args: wrapArgs(newTestDict(123, True, "foo", False)), want: newTestTuple(123, "foo").ToObject()},
 		{args: wrapArgs(deletedItemDict), want: newTestTuple("foo").ToObject()},
 	}
@@ -522,7 +663,7 @@ func TestDictItems(t *testing.T) {
 	deletedItemDict.DelItem(f, hashFoo)
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewDict()), want: NewList().ToObject()},
-		{args: wrapArgs(newStringDict(map[string]*Object{"foo": NewInt(1).ToObject(), "bar": NewInt(2).ToObject()})), want: newTestList(newTestTuple("foo", 1), newTestTuple("bar", 2)).ToObject()},
+		{args: wrapArgs(newStringDict(map[string]*Object{"foo": NewInt(1).ToObject(), "bar": NewInt(2).ToObject()})), want: newTestList(newTestTuple("bar", 2), newTestTuple("foo", 1)).ToObject()},
 		{args: wrapArgs(newTestDict(123, True, "foo", False)), want: newTestList(newTestTuple(123, true), newTestTuple("foo", false)).ToObject()},
 		{args: wrapArgs(deletedItemDict), want: newTestList(newTestTuple("foo", None)).ToObject()},
 	}
@@ -563,7 +704,7 @@ func TestDictKeyIterModified(t *testing.T) {
 func TestDictKeys(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewDict()), want: NewList().ToObject()},
-		{args: wrapArgs(newTestDict("foo", None, 42, None)), want: newTestList(42, "foo").ToObject()},
+		{args: wrapArgs(newTestDict("foo", None, 42, None)), want: newTestList("foo", 42).ToObject()},
 	}
 	for _, cas := range cases {
 		if err := runInvokeMethodTestCase(DictType, "keys", &cas); err != "" {
@@ -616,6 +757,40 @@ func TestDictPopItem(t *testing.T) {
 	}
 }
 
+func TestDictInsertionOrder(t *testing.T) {
+	f := NewRootFrame()
+	// Overwriting a key keeps its position while deleting and re-adding
+	// a key moves it to the end.
+	reordered := newTestDict("foo", 1, "bar", 2, "baz", 3)
+	reordered.SetItemString(f, "foo", NewInt(4).ToObject())
+	reordered.DelItemString(f, "bar")
+	reordered.SetItemString(f, "bar", NewInt(5).ToObject())
+	// Growing the table, including after deletions, preserves order.
+	large := NewDict()
+	var wantKeys []*Object
+	for i := 0; i < 100; i++ {
+		large.SetItem(f, NewInt(i).ToObject(), None)
+		if i%3 == 0 {
+			large.DelItem(f, NewInt(i).ToObject())
+		} else {
+			wantKeys = append(wantKeys, NewInt(i).ToObject())
+		}
+	}
+	popped := newTestDict("foo", 1, "bar", 2, "baz", 3)
+	mustNotRaise(dictPopItem(f, Args{popped.ToObject()}, nil))
+	popped.SetItemString(f, "qux", None)
+	cases := []invokeTestCase{
+		{args: wrapArgs(reordered), want: newTestList("foo", "baz", "bar").ToObject()},
+		{args: wrapArgs(large), want: NewList(wantKeys...).ToObject()},
+		{args: wrapArgs(popped), want: newTestList("foo", "bar", "qux").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(DictType, "keys", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestDictNewInit(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(), want: NewDict().ToObject()},
@@ -781,6 +956,16 @@ func TestDictStrRepr(t *testing.T) {
 
 func TestDictUpdate(t *testing.T) {
 	updateMethod := mustNotRaise(GetAttr(NewRootFrame(), DictType.ToObject(), NewStr("update"), nil))
+	// mappingType has a keys method but is not a dict subclass.
+	mappingType := newTestClass("Mapping", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"keys": newBuiltinFunction("keys", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return newTestList("foo", "bar").ToObject(), nil
+		}).ToObject(),
+		"__getitem__": newBuiltinFunction("__getitem__", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+			return Add(f, args[1], args[1])
+		}).ToObject(),
+	}))
+	mapping := newObject(mappingType)
 	update := newBuiltinFunction("TestDictUpdate", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 		if raised := checkFunctionVarArgs(f, "TestDictUpdate", args, DictType); raised != nil {
 			return nil, raised
@@ -805,6 +990,8 @@ func TestDictUpdate(t *testing.T) {
 		{args: wrapArgs(NewDict()), want: NewDict().ToObject()},
 		{args: wrapArgs(NewDict()), kwargs: wrapKWArgs("foo", "bar"), want: newTestDict("foo", "bar").ToObject()},
 		{args: wrapArgs(newTestDict("foo", 1, "bar", 3.14), newTestDict("foo", 2)), kwargs: wrapKWArgs("foo", 3), want: newTestDict("foo", 3, "bar", 3.14).ToObject()},
+		{args: wrapArgs(newTestDict("foo", 1), mapping), want: newTestDict("foo", "foofoo", "bar", "barbar").ToObject()},
+		{args: wrapArgs(NewDict(), mapping), kwargs: wrapKWArgs("bar", 42), want: newTestDict("foo", "foofoo", "bar", 42).ToObject()},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(update, &cas); err != "" {
@@ -902,6 +1089,53 @@ func TestParallelDictUpdates(t *testing.T) {
 	finished.Wait()
 }
 
+// TestParallelDictSnapshots checks that snapshots taken concurrently with
+// writes always see a state the dict was actually in.
+func TestParallelDictSnapshots(t *testing.T) {
+	d := newTestDict("foo", 1, "bar", 2, "baz", 3)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		f := NewRootFrame()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			// Adding and removing a key repeatedly causes the table to be
+			// regrown and its deleted slots to be reused.
+			key := NewInt(i % 100).ToObject()
+			mustNotRaise(nil, d.SetItem(f, key, None))
+			if _, raised := d.DelItem(f, key); raised != nil {
+				t.Error(raised)
+				return
+			}
+		}
+	}()
+	f := NewRootFrame()
+	for i := 0; i < 10000; i++ {
+		entries := d.snapshot(f)
+		if n := len(entries); n != 3 && n != 4 {
+			t.Errorf("snapshot() returned %d entries, want 3 or 4", n)
+		}
+		seen := map[*Object]bool{}
+		for _, entry := range entries {
+			if seen[entry.key] {
+				t.Errorf("snapshot() returned key %v twice", entry.key)
+			}
+			seen[entry.key] = true
+		}
+		if l := d.Keys(f); len(l.elems) != 3 && len(l.elems) != 4 {
+			t.Errorf("Keys() returned %v", l)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
 func newTestDict(elems ...interface{}) *Dict {
 	if len(elems)%2 != 0 {
 		panic("invalid test dict spec")
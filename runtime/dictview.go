@@ -0,0 +1,229 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	dictItemsViewType  = newBasisType("dict_items", reflect.TypeOf(dictItemsView{}), toDictItemsViewUnsafe, ObjectType)
+	dictKeysViewType   = newBasisType("dict_keys", reflect.TypeOf(dictKeysView{}), toDictKeysViewUnsafe, ObjectType)
+	dictValuesViewType = newBasisType("dict_values", reflect.TypeOf(dictValuesView{}), toDictValuesViewUnsafe, ObjectType)
+)
+
+// dictItemsView represents the objects returned by dict.viewitems. It is a
+// dynamic, set-like view of the dict's (key, value) pairs.
+type dictItemsView struct {
+	Object
+	dict *Dict
+}
+
+func newDictItemsView(d *Dict) *dictItemsView {
+	return &dictItemsView{Object{typ: dictItemsViewType}, d}
+}
+
+func toDictItemsViewUnsafe(o *Object) *dictItemsView {
+	return (*dictItemsView)(o.toPointer())
+}
+
+func (v *dictItemsView) ToObject() *Object {
+	return &v.Object
+}
+
+func dictItemsViewContains(f *Frame, o, item *Object) (*Object, *BaseException) {
+	if !item.isInstance(TupleType) {
+		return False.ToObject(), nil
+	}
+	t := toTupleUnsafe(item)
+	if t.Len() != 2 {
+		return False.ToObject(), nil
+	}
+	value, raised := toDictItemsViewUnsafe(o).dict.GetItem(f, t.elems[0])
+	if raised != nil || value == nil {
+		return False.ToObject(), raised
+	}
+	return Eq(f, value, t.elems[1])
+}
+
+func dictItemsViewIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return newDictItemIterator(toDictItemsViewUnsafe(o).dict).ToObject(), nil
+}
+
+func dictItemsViewLen(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewInt(toDictItemsViewUnsafe(o).dict.Len()).ToObject(), nil
+}
+
+func initDictItemsViewType(map[string]*Object) {
+	dictItemsViewType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dictItemsViewType.slots.Contains = &binaryOpSlot{dictItemsViewContains}
+	dictItemsViewType.slots.Iter = &unaryOpSlot{dictItemsViewIter}
+	dictItemsViewType.slots.Len = &unaryOpSlot{dictItemsViewLen}
+	dictItemsViewType.slots.Repr = &unaryOpSlot{dictViewRepr}
+	initDictSetViewSlots(&dictItemsViewType.slots)
+}
+
+// dictKeysView represents the objects returned by dict.viewkeys. It is a
+// dynamic, set-like view of the dict's keys.
+type dictKeysView struct {
+	Object
+	dict *Dict
+}
+
+func newDictKeysView(d *Dict) *dictKeysView {
+	return &dictKeysView{Object{typ: dictKeysViewType}, d}
+}
+
+func toDictKeysViewUnsafe(o *Object) *dictKeysView {
+	return (*dictKeysView)(o.toPointer())
+}
+
+func (v *dictKeysView) ToObject() *Object {
+	return &v.Object
+}
+
+func dictKeysViewContains(f *Frame, o, key *Object) (*Object, *BaseException) {
+	return dictContains(f, toDictKeysViewUnsafe(o).dict.ToObject(), key)
+}
+
+func dictKeysViewIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return newDictKeyIterator(toDictKeysViewUnsafe(o).dict).ToObject(), nil
+}
+
+func dictKeysViewLen(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewInt(toDictKeysViewUnsafe(o).dict.Len()).ToObject(), nil
+}
+
+func initDictKeysViewType(map[string]*Object) {
+	dictKeysViewType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dictKeysViewType.slots.Contains = &binaryOpSlot{dictKeysViewContains}
+	dictKeysViewType.slots.Iter = &unaryOpSlot{dictKeysViewIter}
+	dictKeysViewType.slots.Len = &unaryOpSlot{dictKeysViewLen}
+	dictKeysViewType.slots.Repr = &unaryOpSlot{dictViewRepr}
+	initDictSetViewSlots(&dictKeysViewType.slots)
+}
+
+// dictValuesView represents the objects returned by dict.viewvalues. Unlike
+// the keys and items views, it is not set-like since values need not be
+// unique or hashable.
+type dictValuesView struct {
+	Object
+	dict *Dict
+}
+
+func newDictValuesView(d *Dict) *dictValuesView {
+	return &dictValuesView{Object{typ: dictValuesViewType}, d}
+}
+
+func toDictValuesViewUnsafe(o *Object) *dictValuesView {
+	return (*dictValuesView)(o.toPointer())
+}
+
+func (v *dictValuesView) ToObject() *Object {
+	return &v.Object
+}
+
+func dictValuesViewIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return newDictValueIterator(toDictValuesViewUnsafe(o).dict).ToObject(), nil
+}
+
+func dictValuesViewLen(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewInt(toDictValuesViewUnsafe(o).dict.Len()).ToObject(), nil
+}
+
+func initDictValuesViewType(map[string]*Object) {
+	dictValuesViewType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dictValuesViewType.slots.Iter = &unaryOpSlot{dictValuesViewIter}
+	dictValuesViewType.slots.Len = &unaryOpSlot{dictValuesViewLen}
+	dictValuesViewType.slots.Repr = &unaryOpSlot{dictViewRepr}
+}
+
+// dictViewRepr returns the repr of a dict view, e.g. "dict_keys(['foo'])".
+func dictViewRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	if f.reprEnter(o) {
+		return NewStr(fmt.Sprintf("%s(...)", o.typ.Name())).ToObject(), nil
+	}
+	defer f.reprLeave(o)
+	l, raised := ListType.Call(f, Args{o}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	repr, raised := Repr(f, l)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewStr(fmt.Sprintf("%s(%s)", o.typ.Name(), repr.Value())).ToObject(), nil
+}
+
+// initDictSetViewSlots populates the comparison and set operation slots
+// shared by the keys and items views. Like CPython, these operations
+// accept any iterable and produce a new set.
+func initDictSetViewSlots(slots *typeSlots) {
+	slots.And = &binaryOpSlot{dictViewSetOpFunc(setOpAnd, false)}
+	slots.Eq = &binaryOpSlot{dictViewCompareFunc(compareOpEq)}
+	slots.GE = &binaryOpSlot{dictViewCompareFunc(compareOpGE)}
+	slots.GT = &binaryOpSlot{dictViewCompareFunc(compareOpGT)}
+	slots.LE = &binaryOpSlot{dictViewCompareFunc(compareOpLE)}
+	slots.LT = &binaryOpSlot{dictViewCompareFunc(compareOpLT)}
+	slots.NE = &binaryOpSlot{dictViewCompareFunc(compareOpNE)}
+	slots.Or = &binaryOpSlot{dictViewSetOpFunc(setOpOr, false)}
+	slots.RAnd = &binaryOpSlot{dictViewSetOpFunc(setOpAnd, true)}
+	slots.ROr = &binaryOpSlot{dictViewSetOpFunc(setOpOr, true)}
+	slots.RSub = &binaryOpSlot{dictViewSetOpFunc(setOpSub, true)}
+	slots.RXor = &binaryOpSlot{dictViewSetOpFunc(setOpXor, true)}
+	slots.Sub = &binaryOpSlot{dictViewSetOpFunc(setOpSub, false)}
+	slots.Xor = &binaryOpSlot{dictViewSetOpFunc(setOpXor, false)}
+}
+
+// dictViewCompareFunc returns a rich comparison function for set-like dict
+// views. Views compare against sets, frozensets and other set-like views;
+// other types are NotImplemented.
+func dictViewCompareFunc(op compareOp) binaryOpFunc {
+	return func(f *Frame, v, w *Object) (*Object, *BaseException) {
+		if !w.isInstance(SetType) && !w.isInstance(FrozenSetType) && !w.isInstance(dictKeysViewType) && !w.isInstance(dictItemsViewType) {
+			return NotImplemented, nil
+		}
+		s1, raised := setFromSeq(f, v)
+		if raised != nil {
+			return nil, raised
+		}
+		s2, raised := setFromSeq(f, w)
+		if raised != nil {
+			return nil, raised
+		}
+		return setCompare(f, op, s1, &s2.Object)
+	}
+}
+
+// dictViewSetOpFunc returns a function computing op over a set-like dict view
+// and an arbitrary iterable. When reflected is true, the view is the right
+// hand operand.
+func dictViewSetOpFunc(op setOp, reflected bool) binaryOpFunc {
+	return func(f *Frame, v, w *Object) (*Object, *BaseException) {
+		if reflected {
+			v, w = w, v
+		}
+		s1, raised := setFromSeq(f, v)
+		if raised != nil {
+			return nil, raised
+		}
+		s2, raised := setFromSeq(f, w)
+		if raised != nil {
+			return nil, raised
+		}
+		return setBinaryOp(f, SetType, op, s1, &s2.Object)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestDictViews(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, method string, d *Dict) (*Object, *BaseException) {
+		m, raised := GetAttr(f, d.ToObject(), NewStr(method), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		view, raised := m.Call(f, nil, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		l, raised := ListType.Call(f, Args{view}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		n, raised := Len(f, view)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(l, n.ToObject()).ToObject(), nil
+	})
+	d := newTestDict("foo", 1, "bar", 2)
+	cases := []invokeTestCase{
+		{args: wrapArgs("viewkeys", NewDict()), want: newTestTuple(NewList(), 0).ToObject()},
+		{args: wrapArgs("viewkeys", d), want: newTestTuple(newTestList("foo", "bar"), 2).ToObject()},
+		{args: wrapArgs("viewvalues", d), want: newTestTuple(newTestList(1, 2), 2).ToObject()},
+		{args: wrapArgs("viewitems", d), want: newTestTuple(newTestList(newTestTuple("foo", 1), newTestTuple("bar", 2)), 2).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDictViewDynamic(t *testing.T) {
+	f := NewRootFrame()
+	d := NewDict()
+	view := newDictKeysView(d).ToObject()
+	if raised := d.SetItemString(f, "foo", None); raised != nil {
+		t.Fatal(raised)
+	}
+	got, raised := Contains(f, view, NewStr("foo").ToObject())
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if !got {
+		t.Errorf("'foo' in %v = false, want true", view)
+	}
+}
+
+func TestDictViewContains(t *testing.T) {
+	d := newTestDict("foo", 1, "bar", 2)
+	keysView := newDictKeysView(d).ToObject()
+	itemsView := newDictItemsView(d).ToObject()
+	cases := []invokeTestCase{
+		{args: wrapArgs(keysView, "foo"), want: True.ToObject()},
+		{args: wrapArgs(keysView, "baz"), want: False.ToObject()},
+		{args: wrapArgs(keysView, NewList()), wantExc: mustCreateException(TypeErrorType, "unhashable type: 'list'")},
+		{args: wrapArgs(itemsView, newTestTuple("foo", 1)), want: True.ToObject()},
+		{args: wrapArgs(itemsView, newTestTuple("foo", 2)), want: False.ToObject()},
+		{args: wrapArgs(itemsView, newTestTuple("baz", 1)), want: False.ToObject()},
+		{args: wrapArgs(itemsView, "foo"), want: False.ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(func(f *Frame, view, item *Object) (*Object, *BaseException) {
+			b, raised := Contains(f, view, item)
+			if raised != nil {
+				return nil, raised
+			}
+			return GetBool(b).ToObject(), nil
+		}), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDictViewSetOps(t *testing.T) {
+	keysView := newDictKeysView(newTestDict("foo", 1, "bar", 2)).ToObject()
+	itemsView := newDictItemsView(newTestDict("foo", 1)).ToObject()
+	cases := []struct {
+		fun     binaryOpFunc
+		v, w    *Object
+		want    *Object
+		wantExc *BaseException
+	}{
+		{And, keysView, newTestList("foo", "baz").ToObject(), newTestSet("foo").ToObject(), nil},
+		{Or, keysView, newTestSet("baz").ToObject(), newTestSet("foo", "bar", "baz").ToObject(), nil},
+		{Sub, keysView, newTestTuple("bar").ToObject(), newTestSet("foo").ToObject(), nil},
+		{Sub, newTestSet("foo", "baz").ToObject(), keysView, newTestSet("baz").ToObject(), nil},
+		{Xor, keysView, newTestSet("bar", "baz").ToObject(), newTestSet("foo", "baz").ToObject(), nil},
+		{And, itemsView, newTestList(newTestTuple("foo", 1)).ToObject(), newTestSet(newTestTuple("foo", 1)).ToObject(), nil},
+		{Eq, keysView, newTestSet("foo", "bar").ToObject(), True.ToObject(), nil},
+		{Eq, newTestSet("foo", "bar").ToObject(), keysView, True.ToObject(), nil},
+		{Eq, keysView, newTestList("foo", "bar").ToObject(), False.ToObject(), nil},
+		{NE, keysView, newDictKeysView(newTestDict("foo", 3)).ToObject(), True.ToObject(), nil},
+		{LT, newDictKeysView(newTestDict("foo", 3)).ToObject(), keysView, True.ToObject(), nil},
+		{And, keysView, NewInt(1).ToObject(), nil, mustCreateException(TypeErrorType, "'int' object is not iterable")},
+	}
+	for _, cas := range cases {
+		testCase := invokeTestCase{args: wrapArgs(cas.v, cas.w), want: cas.want, wantExc: cas.wantExc}
+		if err := runInvokeTestCase(wrapFuncForTest(cas.fun), &testCase); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDictViewRepr(t *testing.T) {
+	d := newTestDict("foo", 1)
+	recursive := NewDict()
+	recursiveView := newDictValuesView(recursive)
+	recursive.SetItemString(NewRootFrame(), "foo", recursiveView.ToObject())
+	cases := []invokeTestCase{
+		{args: wrapArgs(newDictKeysView(d)), want: NewStr("dict_keys(['foo'])").ToObject()},
+		{args: wrapArgs(newDictValuesView(d)), want: NewStr("dict_values([1])").ToObject()},
+		{args: wrapArgs(newDictItemsView(d)), want: NewStr("dict_items([('foo', 1)])").ToObject()},
+		{args: wrapArgs(recursiveView), want: NewStr("dict_values([dict_values(...)])").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	// DigestType is the object representing the Python 'HASH' type
+	// returned by the hashlib constructors.
+	DigestType = newBasisType("HASH", reflect.TypeOf(Digest{}), toDigestUnsafe, ObjectType)
+	// digestConstructors maps the names of the supported algorithms to
+	// the functions creating their Go implementations.
+	digestConstructors = map[string]func() hash.Hash{
+		"md5":    md5.New,
+		"sha1":   sha1.New,
+		"sha224": sha256.New224,
+		"sha256": sha256.New,
+		"sha384": sha512.New384,
+		"sha512": sha512.New,
+	}
+)
+
+// Digest represents Python 'HASH' objects, which compute a message digest
+// using one of the algorithms from Go's crypto packages.
+type Digest struct {
+	Object
+	name  string
+	mutex sync.Mutex
+	hash  hash.Hash
+}
+
+// NewDigest returns a new Digest computing the hash algorithm called name,
+// e.g. "md5" or "sha256". Names are case insensitive. ValueError is raised
+// if the algorithm is not supported.
+func NewDigest(f *Frame, name string) (*Digest, *BaseException) {
+	name = strings.ToLower(name)
+	newHash, ok := digestConstructors[name]
+	if !ok {
+		return nil, f.RaiseType(ValueErrorType, "unsupported hash type "+name)
+	}
+	return &Digest{Object: Object{typ: DigestType}, name: name, hash: newHash()}, nil
+}
+
+func toDigestUnsafe(o *Object) *Digest {
+	return (*Digest)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *Digest) ToObject() *Object {
+	return &d.Object
+}
+
+// Update feeds the bytes held by o, which must be a str, unicode or
+// bytearray, into d.
+func (d *Digest) Update(f *Frame, o *Object) *BaseException {
+	data, raised := bufferData(f, o, "must be string or buffer, not %s")
+	if raised != nil {
+		return raised
+	}
+	d.mutex.Lock()
+	d.hash.Write(data)
+	d.mutex.Unlock()
+	return nil
+}
+
+// Sum returns the digest of the data fed into d so far. Further updates may
+// follow.
+func (d *Digest) Sum() []byte {
+	d.mutex.Lock()
+	sum := d.hash.Sum(nil)
+	d.mutex.Unlock()
+	return sum
+}
+
+func digestCopy(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "copy", args, DigestType); raised != nil {
+		return nil, raised
+	}
+	d := toDigestUnsafe(args[0])
+	d.mutex.Lock()
+	// All of the hashes in digestConstructors support marshaling their
+	// internal state, which is the only way to clone them.
+	state, err := d.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	d.mutex.Unlock()
+	if err != nil {
+		return nil, f.RaiseType(SystemErrorType, err.Error())
+	}
+	c := &Digest{Object: Object{typ: DigestType}, name: d.name, hash: digestConstructors[d.name]()}
+	if err := c.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, f.RaiseType(SystemErrorType, err.Error())
+	}
+	return c.ToObject(), nil
+}
+
+func digestDigest(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "digest", args, DigestType); raised != nil {
+		return nil, raised
+	}
+	return NewStr(string(toDigestUnsafe(args[0]).Sum())).ToObject(), nil
+}
+
+func digestGetBlockSize(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_block_size", args, DigestType); raised != nil {
+		return nil, raised
+	}
+	return NewInt(toDigestUnsafe(args[0]).hash.BlockSize()).ToObject(), nil
+}
+
+func digestGetDigestSize(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_digest_size", args, DigestType); raised != nil {
+		return nil, raised
+	}
+	return NewInt(toDigestUnsafe(args[0]).hash.Size()).ToObject(), nil
+}
+
+func digestGetName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_name", args, DigestType); raised != nil {
+		return nil, raised
+	}
+	return NewStr(toDigestUnsafe(args[0]).name).ToObject(), nil
+}
+
+func digestHexDigest(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "hexdigest", args, DigestType); raised != nil {
+		return nil, raised
+	}
+	return NewStr(hex.EncodeToString(toDigestUnsafe(args[0]).Sum())).ToObject(), nil
+}
+
+func digestRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewStr(fmt.Sprintf("<%s HASH object @ %p>", toDigestUnsafe(o).name, o)).ToObject(), nil
+}
+
+func digestUpdate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "update", args, DigestType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if raised := toDigestUnsafe(args[0]).Update(f, args[1]); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func initDigestType(dict map[string]*Object) {
+	DigestType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dict["block_size"] = newProperty(newBuiltinFunction("_get_block_size", digestGetBlockSize).ToObject(), nil, nil).ToObject()
+	dict["copy"] = newBuiltinFunction("copy", digestCopy).ToObject()
+	dict["digest"] = newBuiltinFunction("digest", digestDigest).ToObject()
+	dict["digest_size"] = newProperty(newBuiltinFunction("_get_digest_size", digestGetDigestSize).ToObject(), nil, nil).ToObject()
+	dict["hexdigest"] = newBuiltinFunction("hexdigest", digestHexDigest).ToObject()
+	dict["name"] = newProperty(newBuiltinFunction("_get_name", digestGetName).ToObject(), nil, nil).ToObject()
+	dict["update"] = newBuiltinFunction("update", digestUpdate).ToObject()
+	DigestType.slots.Repr = &unaryOpSlot{digestRepr}
+}
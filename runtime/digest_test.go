@@ -0,0 +1,131 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestNewDigest(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, name string, data *Object) (*Object, *BaseException) {
+		d, raised := NewDigest(f, name)
+		if raised != nil {
+			return nil, raised
+		}
+		if raised := d.Update(f, data); raised != nil {
+			return nil, raised
+		}
+		return newTestTuple(d.name, d.hash.Size(), d.hash.BlockSize(), string(d.Sum())).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("md5", "abc"), want: newTestTuple("md5", 16, 64, "\x90\x01\x50\x98\x3c\xd2\x4f\xb0\xd6\x96\x3f\x7d\x28\xe1\x7f\x72").ToObject()},
+		{args: wrapArgs("SHA1", ""), want: newTestTuple("sha1", 20, 64, "\xda\x39\xa3\xee\x5e\x6b\x4b\x0d\x32\x55\xbf\xef\x95\x60\x18\x90\xaf\xd8\x07\x09").ToObject()},
+		{args: wrapArgs("md5", NewUnicode("abc")), want: newTestTuple("md5", 16, 64, "\x90\x01\x50\x98\x3c\xd2\x4f\xb0\xd6\x96\x3f\x7d\x28\xe1\x7f\x72").ToObject()},
+		{args: wrapArgs("md5", newTestByteArray("abc")), want: newTestTuple("md5", 16, 64, "\x90\x01\x50\x98\x3c\xd2\x4f\xb0\xd6\x96\x3f\x7d\x28\xe1\x7f\x72").ToObject()},
+		{args: wrapArgs("sha384", ""), want: newTestTuple("sha384", 48, 128, "\x38\xb0\x60\xa7\x51\xac\x96\x38\x4c\xd9\x32\x7e\xb1\xb1\xe3\x6a\x21\xfd\xb7\x11\x14\xbe\x07\x43\x4c\x0c\xc7\xbf\x63\xf6\xe1\xda\x27\x4e\xde\xbf\xe7\x6f\x65\xfb\xd5\x1a\xd2\xf1\x48\x98\xb9\x5b").ToObject()},
+		{args: wrapArgs("md4", ""), wantExc: mustCreateException(ValueErrorType, "unsupported hash type md4")},
+		{args: wrapArgs("md5", 123), wantExc: mustCreateException(TypeErrorType, "must be string or buffer, not int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDigestMethods(t *testing.T) {
+	newDigest := func(name, data string) *Object {
+		d, raised := NewDigest(NewRootFrame(), name)
+		if raised != nil {
+			panic(raised)
+		}
+		if raised := d.Update(NewRootFrame(), NewStr(data).ToObject()); raised != nil {
+			panic(raised)
+		}
+		return d.ToObject()
+	}
+	copyAndUpdate := wrapFuncForTest(func(f *Frame, o *Object, data string) (*Object, *BaseException) {
+		c, raised := GetAttr(f, o, NewStr("copy"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if c, raised = c.Call(f, nil, nil); raised != nil {
+			return nil, raised
+		}
+		if raised := toDigestUnsafe(c).Update(f, NewStr(data).ToObject()); raised != nil {
+			return nil, raised
+		}
+		return newTestTuple(NewStr(string(toDigestUnsafe(o).Sum())), NewStr(string(toDigestUnsafe(c).Sum()))).ToObject(), nil
+	})
+	abc := newDigest("sha256", "abc")
+	cases := []invokeTestCase{
+		{args: wrapArgs(newDigest("sha1", "abc"), "def"), want: newTestTuple("\xa9\x99\x3e\x36\x47\x06\x81\x6a\xba\x3e\x25\x71\x78\x50\xc2\x6c\x9c\xd0\xd8\x9d", "\x1f\x8a\xc1\x0f\x23\xc5\xb5\xbc\x11\x67\xbd\xa8\x4b\x83\x3e\x5c\x05\x7a\x77\xd2").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(copyAndUpdate, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	cases = []invokeTestCase{
+		{args: wrapArgs(newDigest("md5", "ab")), want: NewStr("\x18\x7e\xf4\x43\x61\x22\xd1\xcc\x2f\x40\xdc\x2b\x92\xf0\xeb\xa0").ToObject()},
+		{args: wrapArgs("abc"), wantExc: mustCreateException(TypeErrorType, "unbound method digest() must be called with HASH instance as first argument (got str instance instead)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(DigestType, "digest", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	cases = []invokeTestCase{
+		{args: wrapArgs(abc), want: NewStr("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad").ToObject()},
+		{args: wrapArgs(newDigest("sha224", "abc")), want: NewStr("23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7").ToObject()},
+		{args: wrapArgs(newDigest("sha512", "abc")), want: NewStr("ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(DigestType, "hexdigest", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	cas := invokeTestCase{args: wrapArgs(abc, 123), wantExc: mustCreateException(TypeErrorType, "must be string or buffer, not int")}
+	if err := runInvokeMethodTestCase(DigestType, "update", &cas); err != "" {
+		t.Error(err)
+	}
+}
+
+func TestDigestAttrs(t *testing.T) {
+	d, raised := NewDigest(NewRootFrame(), "sha512")
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	fun := wrapFuncForTest(func(f *Frame, name *Str) (*Object, *BaseException) {
+		return GetAttr(f, d.ToObject(), name, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("name"), want: NewStr("sha512").ToObject()},
+		{args: wrapArgs("digest_size"), want: NewInt(64).ToObject()},
+		{args: wrapArgs("block_size"), want: NewInt(128).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDigestNew(t *testing.T) {
+	cas := invokeTestCase{wantExc: mustCreateException(TypeErrorType, "object.__new__(HASH) is not safe, use HASH.__new__()")}
+	if err := runInvokeTestCase(DigestType.ToObject(), &cas); err != "" {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,1234 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grumpy compiles Python ahead of time so source strings passed to eval() and
+// the exec statement are handled by the small tree walking interpreter in this
+// file. It supports expressions and the simple statements along with if, for,
+// while and def. Code that needs class, try or with statements must be
+// compiled normally. Import statements can only load modules that were linked
+// into the program.
+
+const (
+	evalFilename = "<string>"
+	// compileOnlyAST is the compile() flag requesting an AST object
+	// rather than a code object.
+	compileOnlyAST = 0x400
+)
+
+type evalFlow int
+
+const (
+	evalFlowNormal evalFlow = iota
+	evalFlowBreak
+	evalFlowContinue
+	evalFlowReturn
+)
+
+// evalScope holds the variables visible to code being interpreted. locals is
+// the mapping that names are bound in. For function scopes it is always a
+// *Dict and parent refers to the enclosing function scope, if any. The values
+// of expression statements are printed when interactive is set.
+type evalScope struct {
+	info        *evalScopeInfo
+	filename    string
+	globals     *Dict
+	builtins    *Dict
+	locals      *Object
+	parent      *evalScope
+	retval      *Object
+	interactive bool
+}
+
+// evalProgram is source code parsed at runtime. expr is set for code parsed
+// in eval mode and code otherwise.
+type evalProgram struct {
+	filename    string
+	expr        evalExpr
+	code        *evalCode
+	interactive bool
+}
+
+// Compile parses source, which must be a str or unicode object, into a code
+// object that can be run by Eval or Exec. As for the compile builtin, mode is
+// one of "exec", "eval" or "single" and filename is used in error messages
+// and tracebacks.
+func Compile(f *Frame, source *Object, filename, mode string) (*Code, *BaseException) {
+	if mode != "exec" && mode != "eval" && mode != "single" {
+		return nil, f.RaiseType(ValueErrorType, "compile() arg 3 must be 'exec', 'eval' or 'single'")
+	}
+	src, raised := evalSource(f, "compile", source, "compile() arg 1 must be a string or AST object")
+	if raised != nil {
+		return nil, raised
+	}
+	p, raised := evalCompile(f, src, filename, mode)
+	if raised != nil {
+		return nil, raised
+	}
+	c := NewCode("<module>", filename, nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		s, raised := p.newScope(f, nil, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return p.run(f, s)
+	})
+	c.program = p
+	return c, nil
+}
+
+// Eval evaluates source in the context of the given globals and locals
+// mappings. source is either a code object or a str or unicode object holding
+// an expression. When globals is nil, the globals and locals of the calling
+// frame are used. Otherwise when locals is nil it defaults to globals.
+func Eval(f *Frame, source *Object, globals *Dict, locals *Object) (*Object, *BaseException) {
+	if source.isInstance(CodeType) {
+		return evalCodeObject(f, toCodeUnsafe(source), globals, locals)
+	}
+	src, raised := evalSource(f, "eval", source, "eval() arg 1 must be a string or code object")
+	if raised != nil {
+		return nil, raised
+	}
+	p, raised := evalCompile(f, strings.TrimLeft(src, " \t"), evalFilename, "eval")
+	if raised != nil {
+		return nil, raised
+	}
+	return p.eval(f, globals, locals)
+}
+
+// Exec implements the Python exec statement. body is the code to execute and
+// globals and locals are the optional mappings given after "in" which are nil
+// when absent. As in CPython, body may also be a tuple of the code and
+// mappings. Without explicit mappings the code runs in the globals and a
+// snapshot of the locals of the calling frame, so assignments made by the code
+// are not visible to the caller's function locals.
+func Exec(f *Frame, body, globals, locals *Object) *BaseException {
+	if globals == None {
+		globals = nil
+	}
+	if locals == None {
+		locals = nil
+	}
+	if globals == nil && body.isInstance(TupleType) {
+		elems := toTupleUnsafe(body).elems
+		if n := len(elems); n < 2 || n > 3 {
+			return f.RaiseType(TypeErrorType, evalExecTypeErrorMsg)
+		}
+		body, globals = elems[0], elems[1]
+		if len(elems) == 3 {
+			locals = elems[2]
+		}
+	}
+	var g *Dict
+	if globals != nil {
+		if !globals.isInstance(DictType) {
+			format := "exec: arg 2 must be a dictionary or None, not %s"
+			return f.RaiseType(TypeErrorType, fmt.Sprintf(format, globals.typ.Name()))
+		}
+		g = toDictUnsafe(globals)
+	}
+	return evalExec(f, body, g, locals)
+}
+
+const evalExecTypeErrorMsg = "exec: arg 1 must be a string, file, or code object"
+
+func evalExec(f *Frame, body *Object, globals *Dict, locals *Object) *BaseException {
+	if body.isInstance(CodeType) {
+		_, raised := evalCodeObject(f, toCodeUnsafe(body), globals, locals)
+		return raised
+	}
+	src, raised := evalSource(f, "exec", body, evalExecTypeErrorMsg)
+	if raised != nil {
+		return raised
+	}
+	p, raised := evalCompile(f, src, evalFilename, "exec")
+	if raised != nil {
+		return raised
+	}
+	_, raised = p.eval(f, globals, locals)
+	return raised
+}
+
+// evalCodeObject runs c. Code objects that were not created by Compile are
+// run in globals and ignore locals.
+func evalCodeObject(f *Frame, c *Code, globals *Dict, locals *Object) (*Object, *BaseException) {
+	if c.program != nil {
+		return c.program.eval(f, globals, locals)
+	}
+	if globals == nil {
+		globals = f.Globals()
+	}
+	return c.Eval(f, globals, nil, nil)
+}
+
+// evalSource returns the source code held by o as a Go string. typeErrMsg is
+// the message raised when o is not a string.
+func evalSource(f *Frame, name string, o *Object, typeErrMsg string) (string, *BaseException) {
+	var src string
+	switch {
+	case o.isInstance(StrType):
+		src = toStrUnsafe(o).Value()
+	case o.isInstance(UnicodeType):
+		s, raised := toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
+		if raised != nil {
+			return "", raised
+		}
+		src = s.Value()
+	default:
+		return "", f.RaiseType(TypeErrorType, typeErrMsg)
+	}
+	if strings.IndexByte(src, 0) != -1 {
+		return "", f.RaiseType(TypeErrorType, fmt.Sprintf("%s() expected string without null bytes", name))
+	}
+	return src, nil
+}
+
+func evalCompile(f *Frame, src, filename, mode string) (*evalProgram, *BaseException) {
+	p := &evalProgram{filename: filename, interactive: mode == "single"}
+	var err *evalSyntaxError
+	if mode == "eval" {
+		p.expr, err = evalParseExpr(src)
+	} else {
+		p.code, err = evalParseExec(src)
+	}
+	if err != nil {
+		return nil, err.raise(f, filename)
+	}
+	return p, nil
+}
+
+// eval runs p in a new frame with the given globals and locals.
+func (p *evalProgram) eval(f *Frame, globals *Dict, locals *Object) (*Object, *BaseException) {
+	s, raised := p.newScope(f, globals, locals)
+	if raised != nil {
+		return nil, raised
+	}
+	c := NewCode("<module>", p.filename, nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		return p.run(f, s)
+	})
+	return c.Eval(f, s.globals, nil, nil)
+}
+
+func (p *evalProgram) newScope(f *Frame, globals *Dict, locals *Object) (*evalScope, *BaseException) {
+	info := newEvalScopeInfo(false)
+	if p.code != nil {
+		info = p.code.info
+	}
+	s, raised := newEvalScope(f, info, globals, locals)
+	if raised != nil {
+		return nil, raised
+	}
+	s.filename = p.filename
+	s.interactive = p.interactive
+	return s, nil
+}
+
+// run evaluates p in s. The result is None for code not compiled in eval mode.
+func (p *evalProgram) run(f *Frame, s *evalScope) (*Object, *BaseException) {
+	if p.expr != nil {
+		return p.expr.eval(f, s)
+	}
+	if _, raised := evalBody(f, s, p.code.body); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func (e *evalSyntaxError) raise(f *Frame, filename string) *BaseException {
+	return f.RaiseType(SyntaxErrorType, fmt.Sprintf("%s (%s, line %d)", e.msg, filename, e.line))
+}
+
+// newEvalScope creates the scope for the top level of eval'd or exec'd code.
+// As in CPython, the builtins are taken from globals["__builtins__"], which is
+// populated from the calling frame when absent.
+func newEvalScope(f *Frame, info *evalScopeInfo, globals *Dict, locals *Object) (*evalScope, *BaseException) {
+	if globals == nil {
+		globals = f.Globals()
+		if globals == nil {
+			globals = NewDict()
+		} else if locals == nil {
+			locals = f.Locals().ToObject()
+		}
+	}
+	if locals == nil {
+		locals = globals.ToObject()
+	}
+	b, raised := globals.GetItemString(f, "__builtins__")
+	if raised != nil {
+		return nil, raised
+	}
+	var builtins *Dict
+	switch {
+	case b == nil:
+		builtins = f.Builtins()
+		if raised := globals.SetItemString(f, "__builtins__", builtins.ToObject()); raised != nil {
+			return nil, raised
+		}
+	case b.isInstance(DictType):
+		builtins = toDictUnsafe(b)
+	case b.isInstance(ModuleType):
+		builtins = b.Dict()
+	default:
+		builtins = f.Builtins()
+	}
+	return &evalScope{info: info, filename: evalFilename, globals: globals, builtins: builtins, locals: locals}, nil
+}
+
+func (s *evalScope) lookup(f *Frame, name *Str) (*Object, *BaseException) {
+	n := name.Value()
+	if !s.info.globals[n] {
+		if s.info.isFunction {
+			v, raised := toDictUnsafe(s.locals).GetItem(f, name.ToObject())
+			if raised != nil || v != nil {
+				return v, raised
+			}
+			if s.info.locals[n] {
+				return nil, f.RaiseType(UnboundLocalErrorType, fmt.Sprintf("local variable '%s' referenced before assignment", n))
+			}
+			for p := s.parent; p != nil; p = p.parent {
+				if p.info.globals[n] {
+					break
+				}
+				v, raised := toDictUnsafe(p.locals).GetItem(f, name.ToObject())
+				if raised != nil || v != nil {
+					return v, raised
+				}
+				if p.info.locals[n] {
+					format := "free variable '%s' referenced before assignment in enclosing scope"
+					return nil, f.RaiseType(NameErrorType, fmt.Sprintf(format, n))
+				}
+			}
+		} else if s.locals != s.globals.ToObject() {
+			v, raised := evalGetMapping(f, s.locals, name)
+			if raised != nil || v != nil {
+				return v, raised
+			}
+		}
+	}
+	v, raised := s.globals.GetItem(f, name.ToObject())
+	if raised != nil || v != nil {
+		return v, raised
+	}
+	v, raised = s.builtins.GetItem(f, name.ToObject())
+	if raised != nil || v != nil {
+		return v, raised
+	}
+	return nil, f.RaiseType(NameErrorType, fmt.Sprintf("name '%s' is not defined", n))
+}
+
+// evalGetMapping returns m[name] or nil if the key is absent.
+func evalGetMapping(f *Frame, m *Object, name *Str) (*Object, *BaseException) {
+	if m.typ == DictType {
+		return toDictUnsafe(m).GetItem(f, name.ToObject())
+	}
+	v, raised := GetItem(f, m, name.ToObject())
+	if raised != nil && raised.isInstance(KeyErrorType) {
+		f.RestoreExc(nil, nil)
+		return nil, nil
+	}
+	return v, raised
+}
+
+func (s *evalScope) namespace(name *Str) *Object {
+	if s.info.globals[name.Value()] {
+		return s.globals.ToObject()
+	}
+	return s.locals
+}
+
+func (s *evalScope) store(f *Frame, name *Str, value *Object) *BaseException {
+	if ns := s.namespace(name); ns.typ == DictType {
+		return toDictUnsafe(ns).SetItem(f, name.ToObject(), value)
+	}
+	return SetItem(f, s.namespace(name), name.ToObject(), value)
+}
+
+func (s *evalScope) delete(f *Frame, name *Str) *BaseException {
+	ns := s.namespace(name)
+	if ns.typ == DictType {
+		deleted, raised := toDictUnsafe(ns).DelItem(f, name.ToObject())
+		if raised == nil && !deleted {
+			raised = f.RaiseType(NameErrorType, fmt.Sprintf("name '%s' is not defined", name.Value()))
+		}
+		return raised
+	}
+	raised := DelItem(f, ns, name.ToObject())
+	if raised != nil && raised.isInstance(KeyErrorType) {
+		raised = f.RaiseType(NameErrorType, fmt.Sprintf("name '%s' is not defined", name.Value()))
+	}
+	return raised
+}
+
+// newChild returns a function scope for code defined within s.
+func (s *evalScope) newChild(info *evalScopeInfo) *evalScope {
+	child := &evalScope{info: info, filename: s.filename, globals: s.globals, builtins: s.builtins, locals: NewDict().ToObject()}
+	if s.info.isFunction {
+		child.parent = s
+	}
+	return child
+}
+
+func evalBody(f *Frame, s *evalScope, body []evalStmt) (evalFlow, *BaseException) {
+	for _, stmt := range body {
+		if raised := f.SetLineno(stmt.lineno()); raised != nil {
+			return evalFlowNormal, raised
+		}
+		if flow, raised := stmt.exec(f, s); raised != nil || flow != evalFlowNormal {
+			return flow, raised
+		}
+	}
+	return evalFlowNormal, nil
+}
+
+func evalExprs(f *Frame, s *evalScope, exprs []evalExpr) ([]*Object, *BaseException) {
+	values := make([]*Object, len(exprs))
+	for i, e := range exprs {
+		v, raised := e.eval(f, s)
+		if raised != nil {
+			return nil, raised
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// evalOptional evaluates e or returns nil if e is nil.
+func evalOptional(f *Frame, s *evalScope, e evalExpr) (*Object, *BaseException) {
+	if e == nil {
+		return nil, nil
+	}
+	return e.eval(f, s)
+}
+
+func evalAssignTarget(f *Frame, s *evalScope, target evalExpr, value *Object) *BaseException {
+	var elts []evalExpr
+	switch t := target.(type) {
+	case *evalName:
+		return s.store(f, t.name, value)
+	case *evalAttribute:
+		o, raised := t.value.eval(f, s)
+		if raised != nil {
+			return raised
+		}
+		return SetAttr(f, o, t.name, value)
+	case *evalSubscript:
+		o, raised := t.value.eval(f, s)
+		if raised != nil {
+			return raised
+		}
+		index, raised := t.index.eval(f, s)
+		if raised != nil {
+			return raised
+		}
+		return SetItem(f, o, index, value)
+	case *evalTuple:
+		elts = t.elts
+	case *evalList:
+		elts = t.elts
+	}
+	values := make([]*Object, len(elts))
+	tie := TieTarget{Children: make([]TieTarget, len(elts))}
+	for i := range elts {
+		tie.Children[i].Target = &values[i]
+	}
+	if raised := Tie(f, tie, value); raised != nil {
+		return raised
+	}
+	for i, elt := range elts {
+		if raised := evalAssignTarget(f, s, elt, values[i]); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+func evalDelTarget(f *Frame, s *evalScope, target evalExpr) *BaseException {
+	switch t := target.(type) {
+	case *evalName:
+		return s.delete(f, t.name)
+	case *evalAttribute:
+		o, raised := t.value.eval(f, s)
+		if raised != nil {
+			return raised
+		}
+		return DelAttr(f, o, t.name)
+	case *evalSubscript:
+		o, raised := t.value.eval(f, s)
+		if raised != nil {
+			return raised
+		}
+		index, raised := t.index.eval(f, s)
+		if raised != nil {
+			return raised
+		}
+		return DelItem(f, o, index)
+	case *evalTuple:
+		for _, elt := range t.elts {
+			if raised := evalDelTarget(f, s, elt); raised != nil {
+				return raised
+			}
+		}
+	case *evalList:
+		for _, elt := range t.elts {
+			if raised := evalDelTarget(f, s, elt); raised != nil {
+				return raised
+			}
+		}
+	}
+	return nil
+}
+
+func (e *evalConst) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	return e.value, nil
+}
+
+func (e *evalName) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	return s.lookup(f, e.name)
+}
+
+func (e *evalAttribute) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	o, raised := e.value.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetAttr(f, o, e.name, nil)
+}
+
+func (e *evalSubscript) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	o, raised := e.value.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	index, raised := e.index.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetItem(f, o, index)
+}
+
+func (e *evalSlice) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	args := make(Args, 3)
+	for i, part := range []evalExpr{e.lower, e.upper, e.step} {
+		args[i] = None
+		if part != nil {
+			v, raised := part.eval(f, s)
+			if raised != nil {
+				return nil, raised
+			}
+			args[i] = v
+		}
+	}
+	return SliceType.Call(f, args, nil)
+}
+
+func (e *evalCall) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	fn, raised := e.fn.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	args, raised := evalExprs(f, s, e.args)
+	if raised != nil {
+		return nil, raised
+	}
+	var keywords KWArgs
+	for _, kw := range e.keywords {
+		v, raised := kw.value.eval(f, s)
+		if raised != nil {
+			return nil, raised
+		}
+		keywords = append(keywords, KWArg{kw.name, v})
+	}
+	starargs, raised := evalOptional(f, s, e.starargs)
+	if raised != nil {
+		return nil, raised
+	}
+	kwargs, raised := evalOptional(f, s, e.kwargs)
+	if raised != nil {
+		return nil, raised
+	}
+	return Invoke(f, fn, args, starargs, keywords, kwargs)
+}
+
+func (e *evalBinOp) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	left, raised := e.left.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	right, raised := e.right.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	return e.fn(f, left, right)
+}
+
+func (e *evalUnaryOp) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	o, raised := e.operand.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	return e.fn(f, o)
+}
+
+func (e *evalNot) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	o, raised := e.operand.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	b, raised := IsTrue(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(!b).ToObject(), nil
+}
+
+func (e *evalBoolOp) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	var result *Object
+	for _, value := range e.values {
+		var raised *BaseException
+		if result, raised = value.eval(f, s); raised != nil {
+			return nil, raised
+		}
+		b, raised := IsTrue(f, result)
+		if raised != nil {
+			return nil, raised
+		}
+		if b != e.isAnd {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (e *evalCompare) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	left, raised := e.left.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	var result *Object
+	for i, op := range e.ops {
+		right, raised := e.comparators[i].eval(f, s)
+		if raised != nil {
+			return nil, raised
+		}
+		if result, raised = evalCompareOp(f, op, left, right); raised != nil {
+			return nil, raised
+		}
+		if i < len(e.ops)-1 {
+			b, raised := IsTrue(f, result)
+			if raised != nil {
+				return nil, raised
+			}
+			if !b {
+				break
+			}
+		}
+		left = right
+	}
+	return result, nil
+}
+
+func evalCompareOp(f *Frame, op string, v, w *Object) (*Object, *BaseException) {
+	switch op {
+	case "<":
+		return LT(f, v, w)
+	case "<=":
+		return LE(f, v, w)
+	case "==":
+		return Eq(f, v, w)
+	case "!=":
+		return NE(f, v, w)
+	case ">":
+		return GT(f, v, w)
+	case ">=":
+		return GE(f, v, w)
+	case "is":
+		return GetBool(v == w).ToObject(), nil
+	case "is not":
+		return GetBool(v != w).ToObject(), nil
+	}
+	contains, raised := Contains(f, w, v)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(contains == (op == "in")).ToObject(), nil
+}
+
+func (e *evalIfExp) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	test, raised := e.test.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	b, raised := IsTrue(f, test)
+	if raised != nil {
+		return nil, raised
+	}
+	if b {
+		return e.body.eval(f, s)
+	}
+	return e.orelse.eval(f, s)
+}
+
+func (e *evalTuple) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	elems, raised := evalExprs(f, s, e.elts)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewTuple(elems...).ToObject(), nil
+}
+
+func (e *evalList) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	elems, raised := evalExprs(f, s, e.elts)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewList(elems...).ToObject(), nil
+}
+
+func (e *evalSet) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	set := NewSet()
+	for _, elt := range e.elts {
+		v, raised := elt.eval(f, s)
+		if raised != nil {
+			return nil, raised
+		}
+		if _, raised := set.Add(f, v); raised != nil {
+			return nil, raised
+		}
+	}
+	return set.ToObject(), nil
+}
+
+func (e *evalDict) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	d := NewDict()
+	for i, key := range e.keys {
+		k, raised := key.eval(f, s)
+		if raised != nil {
+			return nil, raised
+		}
+		v, raised := e.values[i].eval(f, s)
+		if raised != nil {
+			return nil, raised
+		}
+		if raised := d.SetItem(f, k, v); raised != nil {
+			return nil, raised
+		}
+	}
+	return d.ToObject(), nil
+}
+
+func (e *evalRepr) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	o, raised := e.value.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	r, raised := Repr(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	return r.ToObject(), nil
+}
+
+// evalCompIter steps through the nested loops of a comprehension, binding the
+// loop targets in s. iters holds an iterator for each active for clause.
+type evalCompIter struct {
+	comp  *evalComp
+	s     *evalScope
+	iters []*Object
+}
+
+// next advances to the next set of loop values satisfying the if clauses. It
+// returns false when the loops are exhausted.
+func (it *evalCompIter) next(f *Frame) (bool, *BaseException) {
+Outer:
+	for len(it.iters) > 0 {
+		depth := len(it.iters)
+		v, raised := Next(f, it.iters[depth-1])
+		if raised != nil {
+			if !raised.isInstance(StopIterationType) {
+				return false, raised
+			}
+			f.RestoreExc(nil, nil)
+			it.iters = it.iters[:depth-1]
+			continue
+		}
+		gen := it.comp.generators[depth-1]
+		if raised := evalAssignTarget(f, it.s, gen.target, v); raised != nil {
+			return false, raised
+		}
+		for _, cond := range gen.ifs {
+			c, raised := cond.eval(f, it.s)
+			if raised != nil {
+				return false, raised
+			}
+			b, raised := IsTrue(f, c)
+			if raised != nil {
+				return false, raised
+			}
+			if !b {
+				continue Outer
+			}
+		}
+		if depth == len(it.comp.generators) {
+			return true, nil
+		}
+		o, raised := it.comp.generators[depth].iter.eval(f, it.s)
+		if raised != nil {
+			return false, raised
+		}
+		iter, raised := Iter(f, o)
+		if raised != nil {
+			return false, raised
+		}
+		it.iters = append(it.iters, iter)
+	}
+	return false, nil
+}
+
+func (e *evalComp) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	o, raised := e.generators[0].iter.eval(f, s)
+	if raised != nil {
+		return nil, raised
+	}
+	iter, raised := Iter(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	if e.info != nil {
+		s = s.newChild(e.info)
+	}
+	it := &evalCompIter{e, s, []*Object{iter}}
+	if e.kind == evalCompGen {
+		gf := newChildFrame(f)
+		gen := NewGenerator(gf, func(*Object) (*Object, *BaseException) {
+			ok, raised := it.next(gf)
+			if raised != nil || !ok {
+				return nil, raised
+			}
+			return e.elt.eval(gf, s)
+		})
+		return gen.ToObject(), nil
+	}
+	var result *Object
+	var add func(f *Frame) *BaseException
+	switch e.kind {
+	case evalCompList:
+		l := NewList()
+		result = l.ToObject()
+		add = func(f *Frame) *BaseException {
+			v, raised := e.elt.eval(f, s)
+			if raised == nil {
+				l.Append(v)
+			}
+			return raised
+		}
+	case evalCompSet:
+		set := NewSet()
+		result = set.ToObject()
+		add = func(f *Frame) *BaseException {
+			v, raised := e.elt.eval(f, s)
+			if raised == nil {
+				_, raised = set.Add(f, v)
+			}
+			return raised
+		}
+	default:
+		d := NewDict()
+		result = d.ToObject()
+		add = func(f *Frame) *BaseException {
+			k, raised := e.elt.eval(f, s)
+			if raised != nil {
+				return raised
+			}
+			v, raised := e.value.eval(f, s)
+			if raised != nil {
+				return raised
+			}
+			return d.SetItem(f, k, v)
+		}
+	}
+	for {
+		ok, raised := it.next(f)
+		if raised != nil {
+			return nil, raised
+		}
+		if !ok {
+			return result, nil
+		}
+		if raised := add(f); raised != nil {
+			return nil, raised
+		}
+	}
+}
+
+// makeFunction creates a Python function object that interprets fn when
+// called. Default values are evaluated in s.
+func (fn *evalFunction) makeFunction(f *Frame, s *evalScope) (*Object, *BaseException) {
+	defaults, raised := evalExprs(f, s, fn.defaults)
+	if raised != nil {
+		return nil, raised
+	}
+	numParams := len(fn.params)
+	params := make([]Param, numParams)
+	for i, name := range fn.params {
+		params[i].Name = name
+		if j := i - (numParams - len(defaults)); j >= 0 {
+			params[i].Def = defaults[j]
+		}
+	}
+	var flags CodeFlag
+	names := fn.params
+	if fn.vararg != "" {
+		flags |= CodeFlagVarArg
+		names = append(names[:numParams:numParams], fn.vararg)
+	}
+	if fn.kwarg != "" {
+		flags |= CodeFlagKWArg
+		names = append(names[:len(names):len(names)], fn.kwarg)
+	}
+	code := NewCode(fn.name, s.filename, params, flags, func(f *Frame, args []*Object) (*Object, *BaseException) {
+		child := s.newChild(fn.info)
+		locals := toDictUnsafe(child.locals)
+		for i, name := range names {
+			if raised := locals.SetItemString(f, name, args[i]); raised != nil {
+				return nil, raised
+			}
+		}
+		if _, raised := evalBody(f, child, fn.body); raised != nil {
+			return nil, raised
+		}
+		if child.retval == nil {
+			return None, nil
+		}
+		return child.retval, nil
+	}).WithArgNames(fn.vararg, fn.kwarg)
+	return NewFunction(code, s.globals).ToObject(), nil
+}
+
+func (e *evalLambda) eval(f *Frame, s *evalScope) (*Object, *BaseException) {
+	return e.fn.makeFunction(f, s)
+}
+
+func (st *evalExprStmt) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	v, raised := st.value.eval(f, s)
+	if raised != nil || !s.interactive {
+		return evalFlowNormal, raised
+	}
+	return evalFlowNormal, evalDisplay(f, s, v)
+}
+
+// evalDisplay outputs the repr of the value of an expression statement
+// compiled in single mode and saves it as _ in the builtins, like the default
+// sys.displayhook.
+func evalDisplay(f *Frame, s *evalScope, v *Object) *BaseException {
+	if v == None {
+		return nil
+	}
+	if raised := s.builtins.SetItemString(f, "_", None); raised != nil {
+		return raised
+	}
+	r, raised := Repr(f, v)
+	if raised != nil {
+		return raised
+	}
+	stdout, raised := sysStream(f, "stdout")
+	if raised != nil {
+		return raised
+	}
+	printSetSoftspace(f, stdout, false)
+	if raised := PrintTo(f, stdout, Args{r.ToObject()}, true); raised != nil {
+		return raised
+	}
+	return s.builtins.SetItemString(f, "_", v)
+}
+
+func (st *evalAssign) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	value, raised := st.value.eval(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	for _, target := range st.targets {
+		if raised := evalAssignTarget(f, s, target, value); raised != nil {
+			return evalFlowNormal, raised
+		}
+	}
+	return evalFlowNormal, nil
+}
+
+func (st *evalAugAssign) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	var get func() (*Object, *BaseException)
+	var set func(*Object) *BaseException
+	switch t := st.target.(type) {
+	case *evalName:
+		get = func() (*Object, *BaseException) { return s.lookup(f, t.name) }
+		set = func(v *Object) *BaseException { return s.store(f, t.name, v) }
+	case *evalAttribute:
+		o, raised := t.value.eval(f, s)
+		if raised != nil {
+			return evalFlowNormal, raised
+		}
+		get = func() (*Object, *BaseException) { return GetAttr(f, o, t.name, nil) }
+		set = func(v *Object) *BaseException { return SetAttr(f, o, t.name, v) }
+	case *evalSubscript:
+		o, raised := t.value.eval(f, s)
+		if raised != nil {
+			return evalFlowNormal, raised
+		}
+		index, raised := t.index.eval(f, s)
+		if raised != nil {
+			return evalFlowNormal, raised
+		}
+		get = func() (*Object, *BaseException) { return GetItem(f, o, index) }
+		set = func(v *Object) *BaseException { return SetItem(f, o, index, v) }
+	}
+	lhs, raised := get()
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	rhs, raised := st.value.eval(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	result, raised := st.fn(f, lhs, rhs)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	return evalFlowNormal, set(result)
+}
+
+func (st *evalPrint) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	dest := None
+	if st.dest != nil {
+		var raised *BaseException
+		if dest, raised = st.dest.eval(f, s); raised != nil {
+			return evalFlowNormal, raised
+		}
+	}
+	args, raised := evalExprs(f, s, st.values)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	return evalFlowNormal, PrintTo(f, dest, args, st.nl)
+}
+
+func (st *evalDel) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	for _, target := range st.targets {
+		if raised := evalDelTarget(f, s, target); raised != nil {
+			return evalFlowNormal, raised
+		}
+	}
+	return evalFlowNormal, nil
+}
+
+func (st *evalPass) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	return evalFlowNormal, nil
+}
+
+func (st *evalBreak) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	return evalFlowBreak, nil
+}
+
+func (st *evalContinue) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	return evalFlowContinue, nil
+}
+
+func (st *evalReturn) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	s.retval = None
+	if st.value != nil {
+		var raised *BaseException
+		if s.retval, raised = st.value.eval(f, s); raised != nil {
+			return evalFlowNormal, raised
+		}
+	}
+	return evalFlowReturn, nil
+}
+
+func (st *evalIf) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	test, raised := st.test.eval(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	b, raised := IsTrue(f, test)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	if b {
+		return evalBody(f, s, st.body)
+	}
+	return evalBody(f, s, st.orelse)
+}
+
+func (st *evalWhile) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	for {
+		test, raised := st.test.eval(f, s)
+		if raised != nil {
+			return evalFlowNormal, raised
+		}
+		b, raised := IsTrue(f, test)
+		if raised != nil {
+			return evalFlowNormal, raised
+		}
+		if !b {
+			return evalBody(f, s, st.orelse)
+		}
+		flow, raised := evalBody(f, s, st.body)
+		if raised != nil || flow == evalFlowReturn {
+			return flow, raised
+		}
+		if flow == evalFlowBreak {
+			return evalFlowNormal, nil
+		}
+	}
+}
+
+func (st *evalFor) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	o, raised := st.iter.eval(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	iter, raised := Iter(f, o)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	for {
+		item, raised := Next(f, iter)
+		if raised != nil {
+			if !raised.isInstance(StopIterationType) {
+				return evalFlowNormal, raised
+			}
+			f.RestoreExc(nil, nil)
+			return evalBody(f, s, st.orelse)
+		}
+		if raised := evalAssignTarget(f, s, st.target, item); raised != nil {
+			return evalFlowNormal, raised
+		}
+		flow, raised := evalBody(f, s, st.body)
+		if raised != nil || flow == evalFlowReturn {
+			return flow, raised
+		}
+		if flow == evalFlowBreak {
+			return evalFlowNormal, nil
+		}
+	}
+}
+
+func (st *evalRaise) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	typ, raised := evalOptional(f, s, st.typ)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	inst, raised := evalOptional(f, s, st.inst)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	tb, raised := evalOptional(f, s, st.tb)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	return evalFlowNormal, f.Raise(typ, inst, tb)
+}
+
+func (st *evalAssert) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	if !Debug() {
+		return evalFlowNormal, nil
+	}
+	test, raised := st.test.eval(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	msg, raised := evalOptional(f, s, st.msg)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	return evalFlowNormal, Assert(f, test, msg)
+}
+
+func (st *evalImport) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	for _, alias := range st.names {
+		mods, raised := ImportModule(f, alias.name)
+		if raised != nil {
+			return evalFlowNormal, raised
+		}
+		name, mod := strings.SplitN(alias.name, ".", 2)[0], mods[0]
+		if alias.asname != "" {
+			name, mod = alias.asname, mods[len(mods)-1]
+		}
+		if raised := s.store(f, NewStr(name), mod); raised != nil {
+			return evalFlowNormal, raised
+		}
+	}
+	return evalFlowNormal, nil
+}
+
+func (st *evalImportFrom) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	mods, raised := ImportModule(f, st.module)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	mod := mods[len(mods)-1]
+	for _, alias := range st.names {
+		member, raised := GetAttr(f, mod, NewStr(alias.name), nil)
+		if raised != nil {
+			if !raised.isInstance(AttributeErrorType) {
+				return evalFlowNormal, raised
+			}
+			f.RestoreExc(nil, nil)
+			// The name may refer to a submodule that has not been
+			// imported yet.
+			submods, raised := ImportModule(f, st.module+"."+alias.name)
+			if raised != nil {
+				if raised.isInstance(ImportErrorType) {
+					raised = f.RaiseType(ImportErrorType, "cannot import name "+alias.name)
+				}
+				return evalFlowNormal, raised
+			}
+			member = submods[len(submods)-1]
+		}
+		if raised := s.store(f, NewStr(alias.asname), member); raised != nil {
+			return evalFlowNormal, raised
+		}
+	}
+	return evalFlowNormal, nil
+}
+
+func (st *evalExecStmt) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	body, raised := st.body.eval(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	globals, raised := evalOptional(f, s, st.globals)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	locals, raised := evalOptional(f, s, st.locals)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	if globals == nil && !body.isInstance(TupleType) {
+		return evalFlowNormal, evalExec(f, body, s.globals, s.locals)
+	}
+	return evalFlowNormal, Exec(f, body, globals, locals)
+}
+
+func (st *evalFunctionDef) exec(f *Frame, s *evalScope) (evalFlow, *BaseException) {
+	fn, raised := st.fn.makeFunction(f, s)
+	if raised != nil {
+		return evalFlowNormal, raised
+	}
+	return evalFlowNormal, s.store(f, NewStr(st.fn.name), fn)
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, src *Object, globals *Dict) (*Object, *BaseException) {
+		return Eval(f, src, globals, nil)
+	})
+	bigInt := new(big.Int).Lsh(big.NewInt(1), 64)
+	cases := []invokeTestCase{
+		{args: wrapArgs("1 + 2 * 3", NewDict()), want: NewInt(7).ToObject()},
+		{args: wrapArgs("  (2 ** 3 ** 2, -2 ** 2, 7 // 2, 7 % 3, ~5)\n", NewDict()), want: newTestTuple(512, -4, 3, 1, -6).ToObject()},
+		{args: wrapArgs("0x1f + 017 + 0b11 + 0o10 + 1L", NewDict()), want: NewLong(big.NewInt(58)).ToObject()},
+		{args: wrapArgs("18446744073709551616", NewDict()), want: NewLong(bigInt).ToObject()},
+		{args: wrapArgs("1.5e1 + .5", NewDict()), want: NewFloat(15.5).ToObject()},
+		{args: wrapArgs("2j * 1j", NewDict()), want: NewComplex(-2).ToObject()},
+		{args: wrapArgs(`'a' "b" r'\n' '\x41\101\t'`, NewDict()), want: NewStr("ab\\nAA\t").ToObject()},
+		{args: wrapArgs(`u'é' 'x'`, NewDict()), want: NewUnicode("éx").ToObject()},
+		{args: wrapArgs(`"""a
+b"""`, NewDict()), want: NewStr("a\nb").ToObject()},
+		{args: wrapArgs("x + y", newTestDict("x", 1, "y", 2)), want: NewInt(3).ToObject()},
+		{args: wrapArgs("[1, 2][-1], (1, 2, 3)[1:], 'abcd'[::2]", NewDict()), want: newTestTuple(2, newTestTuple(2, 3), "ac").ToObject()},
+		{args: wrapArgs("{'a': [1, 2], 'b': (None, True)}", NewDict()), want: newTestDict("a", newTestList(1, 2), "b", newTestTuple(None, true)).ToObject()},
+		{args: wrapArgs("{1, 2} == set([2, 1])", NewDict()), want: True.ToObject()},
+		{args: wrapArgs("1 < 2 < 3, 1 < 3 < 2, 2 in [1, 2], 3 not in (3,), None is None, 1 is not 1, 1 <> 2", NewDict()), want: newTestTuple(true, false, true, false, true, false, true).ToObject()},
+		{args: wrapArgs("0 or '' or 'x', 1 and 0, not 0, 'a' if 0 else 'b'", NewDict()), want: newTestTuple("x", 0, true, "b").ToObject()},
+		{args: wrapArgs("[x * y for x in range(3) if x for y in (1, 10)]", NewDict()), want: newTestList(1, 10, 2, 20).ToObject()},
+		{args: wrapArgs("{x: x * x for x in range(3)}", NewDict()), want: newTestDict(0, 0, 1, 1, 2, 4).ToObject()},
+		{args: wrapArgs("sorted({c for c in 'abca'})", NewDict()), want: newTestList("a", "b", "c").ToObject()},
+		{args: wrapArgs("sum(x for x in range(5))", NewDict()), want: NewInt(10).ToObject()},
+		{args: wrapArgs("(lambda a, b=2, *args, **kwargs: (a, b, args, kwargs))(1, c=3)", NewDict()), want: newTestTuple(1, 2, NewTuple(), newTestDict("c", 3)).ToObject()},
+		{args: wrapArgs("(lambda x: lambda y: x + y)(1)(2)", NewDict()), want: NewInt(3).ToObject()},
+		{args: wrapArgs("dict(*[[('a', 1)]], **{'b': 2})", NewDict()), want: newTestDict("a", 1, "b", 2).ToObject()},
+		{args: wrapArgs("`1`, 'abc'.upper()", NewDict()), want: newTestTuple("1", "ABC").ToObject()},
+		{args: wrapArgs("len", newTestDict("__builtins__", newTestDict("len", 42))), want: NewInt(42).ToObject()},
+		{args: wrapArgs("x", NewDict()), wantExc: mustCreateException(NameErrorType, "name 'x' is not defined")},
+		{args: wrapArgs("1 +", NewDict()), wantExc: mustCreateException(SyntaxErrorType, "unexpected EOF while parsing (<string>, line 1)")},
+		{args: wrapArgs("x = 1", NewDict()), wantExc: mustCreateException(SyntaxErrorType, "invalid syntax (<string>, line 1)")},
+		{args: wrapArgs("(1,\n2 3)", NewDict()), wantExc: mustCreateException(SyntaxErrorType, "invalid syntax (<string>, line 2)")},
+		{args: wrapArgs("'abc", NewDict()), wantExc: mustCreateException(SyntaxErrorType, "EOL while scanning string literal (<string>, line 1)")},
+		{args: wrapArgs("f(x for x in y, 1)", NewDict()), wantExc: mustCreateException(SyntaxErrorType, "Generator expression must be parenthesized if not sole argument (<string>, line 1)")},
+		{args: wrapArgs(1, NewDict()), wantExc: mustCreateException(TypeErrorType, "eval() arg 1 must be a string or code object")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestEvalLocals(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, src string, globals, locals *Dict) (*Object, *BaseException) {
+		result, raised := Eval(f, NewStr(src).ToObject(), globals, locals.ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		b, raised := globals.GetItemString(f, "__builtins__")
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(result, locals.ToObject(), GetBool(b == f.Builtins().ToObject()).ToObject()).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("x, y", newTestDict("x", 1, "y", 2), newTestDict("x", 10)), want: newTestTuple(newTestTuple(10, 2), newTestDict("x", 10), true).ToObject()},
+		// List comprehension variables leak into the enclosing scope.
+		{args: wrapArgs("[z for z in (3,)]", NewDict(), NewDict()), want: newTestTuple(newTestList(3), newTestDict("z", 3), true).ToObject()},
+		// Generator expressions don't see the locals of module level code.
+		{args: wrapArgs("list(x for _ in 'a')", NewDict(), newTestDict("x", 1)), wantExc: mustCreateException(NameErrorType, "name 'x' is not defined")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestEvalFrameLocals(t *testing.T) {
+	// Without explicit mappings, eval and exec see the locals of the
+	// calling frame, e.g. those of a function body.
+	c := NewCode("f", "foo.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		q := NewInt(21).ToObject()
+		f.SetLocals(func() *Dict {
+			return NewLocalsDict([]string{"q"}, []*Object{q})
+		})
+		if raised := Exec(f, NewStr("r = q + g").ToObject(), nil, nil); raised != nil {
+			return nil, raised
+		}
+		result, raised := Eval(f, NewStr("q * 2").ToObject(), nil, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(result, f.Locals().ToObject()).ToObject(), nil
+	})
+	fun := wrapFuncForTest(func(f *Frame, globals *Dict) (*Object, *BaseException) {
+		return c.Eval(f, globals, nil, nil)
+	})
+	cases := []invokeTestCase{
+		// Names bound by exec go to a snapshot of the locals.
+		{args: wrapArgs(newTestDict("g", 1)), want: newTestTuple(42, newTestDict("q", 21)).ToObject()},
+		{args: wrapArgs(NewDict()), wantExc: mustCreateException(NameErrorType, "name 'g' is not defined")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, src string) (*Object, *BaseException) {
+		globals := NewDict()
+		if raised := Exec(f, NewStr(src).ToObject(), globals.ToObject(), nil); raised != nil {
+			return nil, raised
+		}
+		return globals.GetItemString(f, "r")
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("r = 1"), want: NewInt(1).ToObject()},
+		{args: wrapArgs("a = b = 2; r = a + b"), want: NewInt(4).ToObject()},
+		{args: wrapArgs("a, (b, [c]) = 1, (2, [3])\nr = a, b, c"), want: newTestTuple(1, 2, 3).ToObject()},
+		{args: wrapArgs("r = [0, 1]\nr[0] += 5\nr[1] **= 3\nr *= 2"), want: newTestList(5, 1, 5, 1).ToObject()},
+		{args: wrapArgs("r = []\nfor i in range(10):\n  if i % 2:\n    continue\n  elif i > 6:\n    break\n  r.append(i)\nelse:\n  r = None"), want: newTestList(0, 2, 4, 6).ToObject()},
+		{args: wrapArgs("r = 0\nwhile r < 5:\n\tr += 1\nelse:\n\tr = -r"), want: NewInt(-5).ToObject()},
+		{args: wrapArgs("def f(n, acc=1):\n  if n <= 1:\n    return acc\n  return f(n - 1, acc * n)\nr = f(5)"), want: NewInt(120).ToObject()},
+		{args: wrapArgs("x = 1\ndef f():\n  global x\n  x = 2\nf()\nr = x"), want: NewInt(2).ToObject()},
+		{args: wrapArgs("def counter():\n  n = [0]\n  def inc():\n    n[0] += 1\n    return n[0]\n  return inc\nc = counter()\nc()\nr = c()"), want: NewInt(2).ToObject()},
+		{args: wrapArgs("def f(*args, **kwargs):\n  return args, sorted(kwargs)\nr = f(1, 2, a=3)"), want: newTestTuple(newTestTuple(1, 2), newTestList("a")).ToObject()},
+		{args: wrapArgs("r = 1\ndel r\nr = [1, 2, 3]\ndel r[0], r[-1]"), want: newTestList(2).ToObject()},
+		{args: wrapArgs("exec 'r = 5'"), want: NewInt(5).ToObject()},
+		{args: wrapArgs("# comment\n\nif 1:\n\n    # another\n    r = 1 \\\n      + 1\n"), want: NewInt(2).ToObject()},
+		{args: wrapArgs("def f():\n  x\n  x = 1\nf()"), wantExc: mustCreateException(UnboundLocalErrorType, "local variable 'x' referenced before assignment")},
+		{args: wrapArgs("raise ValueError, 'foo'"), wantExc: mustCreateException(ValueErrorType, "foo")},
+		{args: wrapArgs("assert 1 == 2, 'bad'"), wantExc: mustCreateException(AssertionErrorType, "bad")},
+		{args: wrapArgs("import foo_bar_baz"), wantExc: mustCreateException(ImportErrorType, "foo_bar_baz")},
+		{args: wrapArgs("from foo_bar_baz import qux"), wantExc: mustCreateException(ImportErrorType, "foo_bar_baz")},
+		{args: wrapArgs("r = 1\n  r = 2"), wantExc: mustCreateException(SyntaxErrorType, "unexpected indent (<string>, line 2)")},
+		{args: wrapArgs("if 1:\n  r = 1\n r = 2"), wantExc: mustCreateException(SyntaxErrorType, "unindent does not match any outer indentation level (<string>, line 3)")},
+		{args: wrapArgs("if 1:\nr = 1"), wantExc: mustCreateException(SyntaxErrorType, "expected an indented block (<string>, line 2)")},
+		{args: wrapArgs("break"), wantExc: mustCreateException(SyntaxErrorType, "'break' outside loop (<string>, line 1)")},
+		{args: wrapArgs("return 1"), wantExc: mustCreateException(SyntaxErrorType, "'return' outside function (<string>, line 1)")},
+		{args: wrapArgs("f() = 1"), wantExc: mustCreateException(SyntaxErrorType, "can't assign to function call (<string>, line 1)")},
+		{args: wrapArgs("class Foo(object): pass"), wantExc: mustCreateException(SyntaxErrorType, "'class' statements are not supported by exec (<string>, line 1)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, body, globals, locals *Object) (*Object, *BaseException) {
+		if raised := Exec(f, body, globals, locals); raised != nil {
+			return nil, raised
+		}
+		return None, nil
+	})
+	globals := newTestDict("x", 1)
+	locals := NewDict()
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTuple("y = x + 1", globals, locals), None, None), want: None},
+		{args: wrapArgs("assert y == 2 and 'y' not in globals()", globals, locals), want: None},
+		{args: wrapArgs("pass", 1, None), wantExc: mustCreateException(TypeErrorType, "exec: arg 2 must be a dictionary or None, not int")},
+		{args: wrapArgs(newTestTuple("pass"), None, None), wantExc: mustCreateException(TypeErrorType, "exec: arg 1 must be a string, file, or code object")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, source *Object, mode string, globals *Dict) (*Object, *BaseException) {
+		c, raised := Compile(f, source, "foo.py", mode)
+		if raised != nil {
+			return nil, raised
+		}
+		var result *Object
+		output, raised := captureStdout(f, func() *BaseException {
+			var raised *BaseException
+			result, raised = Eval(f, c.ToObject(), globals, nil)
+			return raised
+		})
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(result, NewStr(output).ToObject()).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("x + 1", "eval", newTestDict("x", 2)), want: newTestTuple(3, "").ToObject()},
+		{args: wrapArgs("y = x * 2", "exec", newTestDict("x", 2)), want: newTestTuple(None, "").ToObject()},
+		{args: wrapArgs("def f(): return 1\nf()\nfor i in range(2): i\nNone", "single", NewDict()), want: newTestTuple(None, "1\n0\n1\n").ToObject()},
+		{args: wrapArgs("1 +", "eval", NewDict()), wantExc: mustCreateException(SyntaxErrorType, "unexpected EOF while parsing (foo.py, line 1)")},
+		{args: wrapArgs("pass", "foo", NewDict()), wantExc: mustCreateException(ValueErrorType, "compile() arg 3 must be 'exec', 'eval' or 'single'")},
+		{args: wrapArgs(1, "exec", NewDict()), wantExc: mustCreateException(TypeErrorType, "compile() arg 1 must be a string or AST object")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCompileCode(t *testing.T) {
+	f := NewRootFrame()
+	c, raised := Compile(f, NewStr("def g(): return y\ny = 3").ToObject(), "foo.py", "exec")
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if c.name != "<module>" || c.filename != "foo.py" {
+		t.Errorf("Compile() = code %q in %q, want code %q in %q", c.name, c.filename, "<module>", "foo.py")
+	}
+	globals := NewDict()
+	if raised := Exec(f, c.ToObject(), globals.ToObject(), nil); raised != nil {
+		t.Fatal(raised)
+	}
+	g := mustNotRaise(globals.GetItemString(f, "g"))
+	if got := toFunctionUnsafe(g).code.filename; got != "foo.py" {
+		t.Errorf("g.func_code.co_filename = %q, want %q", got, "foo.py")
+	}
+	if got := mustNotRaise(g.Call(f, nil, nil)); !got.isInstance(IntType) || toIntUnsafe(got).Value() != 3 {
+		t.Errorf("g() = %v, want 3", got)
+	}
+	// Code objects not created by Compile run like function bodies.
+	got, raised := Eval(f, toFunctionUnsafe(g).code.ToObject(), nil, nil)
+	if raised != nil || !got.isInstance(IntType) || toIntUnsafe(got).Value() != 3 {
+		t.Errorf("eval(g.func_code) = %v, %v, want 3", got, raised)
+	}
+}
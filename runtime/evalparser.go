@@ -0,0 +1,1727 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file contains a tokenizer and recursive descent parser for the subset
+// of Python 2 accepted by eval() and the exec statement. Source is parsed into
+// a small tree of evalExpr and evalStmt nodes that are evaluated by the tree
+// walking interpreter in eval.go.
+
+type evalTokenKind int
+
+const (
+	evalTokenEOF evalTokenKind = iota
+	evalTokenName
+	evalTokenNumber
+	evalTokenString
+	evalTokenOp
+	evalTokenNewline
+	evalTokenIndent
+	evalTokenDedent
+)
+
+type evalToken struct {
+	kind  evalTokenKind
+	value string
+	// obj holds the constant value of number and string tokens.
+	obj  *Object
+	line int
+}
+
+// evalSyntaxError describes a syntax error found while tokenizing or parsing.
+// It is raised as a Python SyntaxError once a frame is available.
+type evalSyntaxError struct {
+	msg  string
+	line int
+}
+
+var (
+	evalKeywords = map[string]bool{
+		"and": true, "as": true, "assert": true, "break": true,
+		"class": true, "continue": true, "def": true, "del": true,
+		"elif": true, "else": true, "except": true, "exec": true,
+		"finally": true, "for": true, "from": true, "global": true,
+		"if": true, "import": true, "in": true, "is": true,
+		"lambda": true, "not": true, "or": true, "pass": true,
+		"print": true, "raise": true, "return": true, "try": true,
+		"while": true, "with": true, "yield": true,
+	}
+	// evalOperators lists the operator tokens longest first so that the
+	// tokenizer always consumes the longest match.
+	evalOperators = []string{
+		"**=", "//=", ">>=", "<<=",
+		"**", "//", "<<", ">>", "<=", ">=", "==", "!=", "<>", "+=", "-=",
+		"*=", "/=", "%=", "&=", "|=", "^=",
+		"+", "-", "*", "/", "%", "&", "|", "^", "~", "<", ">", "(", ")",
+		"[", "]", "{", "}", ",", ":", ".", ";", "@", "=", "`",
+	}
+	evalStringPrefixes = map[string]bool{
+		"r": true, "u": true, "b": true, "ur": true, "br": true,
+	}
+)
+
+type evalLexer struct {
+	src         string
+	pos         int
+	line        int
+	depth       int
+	indents     []int
+	atLineStart bool
+	evalMode    bool
+	tokens      []evalToken
+}
+
+// evalTokenize splits src into tokens. In eval mode, indentation is not
+// significant and no INDENT or DEDENT tokens are produced.
+func evalTokenize(src string, evalMode bool) []evalToken {
+	l := &evalLexer{src: src, line: 1, indents: []int{0}, atLineStart: true, evalMode: evalMode}
+	l.run()
+	return l.tokens
+}
+
+func (l *evalLexer) fail(msg string) {
+	panic(&evalSyntaxError{msg, l.line})
+}
+
+func (l *evalLexer) emit(kind evalTokenKind, value string, obj *Object) {
+	l.tokens = append(l.tokens, evalToken{kind, value, obj, l.line})
+}
+
+func (l *evalLexer) run() {
+	for {
+		if l.atLineStart && l.depth == 0 && !l.indentLine() {
+			break
+		}
+		if l.pos >= len(l.src) {
+			break
+		}
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\f':
+			l.pos++
+		case c == '#':
+			l.skipComment()
+		case c == '\\':
+			l.pos++
+			if !l.skipNewline() {
+				l.fail("unexpected character after line continuation character")
+			}
+		case c == '\n' || c == '\r':
+			if l.depth == 0 {
+				l.emit(evalTokenNewline, "\n", nil)
+				l.atLineStart = true
+			}
+			l.skipNewline()
+		case isEvalNameStart(c):
+			start := l.pos
+			for l.pos < len(l.src) && isEvalNameChar(l.src[l.pos]) {
+				l.pos++
+			}
+			name := l.src[start:l.pos]
+			if l.pos < len(l.src) && (l.src[l.pos] == '\'' || l.src[l.pos] == '"') && evalStringPrefixes[strings.ToLower(name)] {
+				l.lexString(strings.ToLower(name))
+			} else {
+				l.emit(evalTokenName, name, nil)
+			}
+		case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+			l.lexNumber()
+		case c == '\'' || c == '"':
+			l.lexString("")
+		default:
+			l.lexOp()
+		}
+	}
+	if n := len(l.tokens); n > 0 && l.tokens[n-1].kind != evalTokenNewline {
+		// Terminate the last line. The token has no value which
+		// distinguishes it from a newline in the source.
+		l.emit(evalTokenNewline, "", nil)
+	}
+	for len(l.indents) > 1 {
+		l.indents = l.indents[:len(l.indents)-1]
+		l.emit(evalTokenDedent, "", nil)
+	}
+	l.emit(evalTokenEOF, "", nil)
+}
+
+// indentLine consumes the indentation at the start of a logical line,
+// skipping blank and comment-only lines and emitting INDENT and DEDENT tokens
+// as appropriate. It returns false when the end of the source is reached.
+func (l *evalLexer) indentLine() bool {
+	for {
+		col := 0
+		for ; l.pos < len(l.src); l.pos++ {
+			c := l.src[l.pos]
+			if c == ' ' {
+				col++
+			} else if c == '\t' {
+				col = (col/8 + 1) * 8
+			} else if c == '\f' {
+				col = 0
+			} else {
+				break
+			}
+		}
+		if l.pos >= len(l.src) {
+			return false
+		}
+		if c := l.src[l.pos]; c == '#' {
+			l.skipComment()
+			continue
+		}
+		if l.skipNewline() {
+			continue
+		}
+		l.atLineStart = false
+		if l.evalMode {
+			return true
+		}
+		top := l.indents[len(l.indents)-1]
+		if col > top {
+			l.indents = append(l.indents, col)
+			l.emit(evalTokenIndent, "", nil)
+		}
+		for col < top {
+			l.indents = l.indents[:len(l.indents)-1]
+			l.emit(evalTokenDedent, "", nil)
+			top = l.indents[len(l.indents)-1]
+			if col > top {
+				l.fail("unindent does not match any outer indentation level")
+			}
+		}
+		return true
+	}
+}
+
+func (l *evalLexer) skipComment() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+		l.pos++
+	}
+}
+
+// skipNewline consumes a single line ending if one is present at the current
+// position.
+func (l *evalLexer) skipNewline() bool {
+	if strings.HasPrefix(l.src[l.pos:], "\r\n") {
+		l.pos += 2
+	} else if l.pos < len(l.src) && (l.src[l.pos] == '\n' || l.src[l.pos] == '\r') {
+		l.pos++
+	} else {
+		return false
+	}
+	l.line++
+	return true
+}
+
+func (l *evalLexer) lexOp() {
+	for _, op := range evalOperators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			switch op {
+			case "(", "[", "{":
+				l.depth++
+			case ")", "]", "}":
+				if l.depth > 0 {
+					l.depth--
+				}
+			}
+			l.emit(evalTokenOp, op, nil)
+			return
+		}
+	}
+	l.fail("invalid syntax")
+}
+
+func (l *evalLexer) lexNumber() {
+	start := l.pos
+	isFloat := false
+	if l.src[l.pos] == '0' && l.pos+1 < len(l.src) && strings.IndexByte("xXoObB", l.src[l.pos+1]) != -1 {
+		l.pos += 2
+		for l.pos < len(l.src) && isHexDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	} else {
+		l.skipDigits()
+		if l.pos < len(l.src) && l.src[l.pos] == '.' {
+			isFloat = true
+			l.pos++
+			l.skipDigits()
+		}
+		if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+			i := l.pos + 1
+			if i < len(l.src) && (l.src[i] == '+' || l.src[i] == '-') {
+				i++
+			}
+			if i < len(l.src) && isDigit(l.src[i]) {
+				isFloat = true
+				l.pos = i
+				l.skipDigits()
+			}
+		}
+	}
+	text := l.src[start:l.pos]
+	var suffix byte
+	if l.pos < len(l.src) && strings.IndexByte("lLjJ", l.src[l.pos]) != -1 {
+		suffix = l.src[l.pos] | 0x20
+		l.pos++
+	}
+	if l.pos < len(l.src) && (isEvalNameChar(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.fail("invalid syntax")
+	}
+	var obj *Object
+	if isFloat || suffix == 'j' {
+		if suffix == 'l' {
+			l.fail("invalid syntax")
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil && err.(*strconv.NumError).Err != strconv.ErrRange {
+			l.fail("invalid syntax")
+		}
+		if suffix == 'j' {
+			obj = NewComplex(complex(0, v)).ToObject()
+		} else {
+			obj = NewFloat(v).ToObject()
+		}
+	} else {
+		i, ok := new(big.Int).SetString(text, 0)
+		if !ok {
+			l.fail("invalid token")
+		}
+		if suffix == 'l' || !numInIntRange(i) {
+			obj = NewLong(i).ToObject()
+		} else {
+			obj = NewInt(int(i.Int64())).ToObject()
+		}
+	}
+	l.emit(evalTokenNumber, l.src[start:l.pos], obj)
+}
+
+func (l *evalLexer) skipDigits() {
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+// lexString consumes a string literal starting at the opening quote. prefix is
+// the lowercased string prefix, e.g. "ur".
+func (l *evalLexer) lexString(prefix string) {
+	raw := strings.Contains(prefix, "r")
+	isUnicode := strings.Contains(prefix, "u")
+	quote := l.src[l.pos : l.pos+1]
+	triple := strings.HasPrefix(l.src[l.pos:], strings.Repeat(quote, 3))
+	if triple {
+		quote = strings.Repeat(quote, 3)
+	}
+	startLine := l.line
+	l.pos += len(quote)
+	var buf []byte
+	var runes []rune
+	add := func(r rune) {
+		if isUnicode {
+			runes = append(runes, r)
+		} else {
+			buf = append(buf, byte(r))
+		}
+	}
+	for {
+		if l.pos >= len(l.src) {
+			l.line = startLine
+			if triple {
+				l.fail("EOF while scanning triple-quoted string literal")
+			}
+			l.fail("EOL while scanning string literal")
+		}
+		if strings.HasPrefix(l.src[l.pos:], quote) {
+			l.pos += len(quote)
+			break
+		}
+		c := l.src[l.pos]
+		if c == '\n' || c == '\r' {
+			if !triple {
+				l.line = startLine
+				l.fail("EOL while scanning string literal")
+			}
+			l.skipNewline()
+			add('\n')
+			continue
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			if raw {
+				add('\\')
+				l.pos++
+				if !l.skipNewline() {
+					l.addSourceChar(add, isUnicode)
+				} else {
+					add('\n')
+				}
+				continue
+			}
+			l.pos++
+			l.lexEscape(add, isUnicode)
+			continue
+		}
+		l.addSourceChar(add, isUnicode)
+	}
+	var obj *Object
+	if isUnicode {
+		obj = NewUnicodeFromRunes(runes).ToObject()
+	} else {
+		obj = NewStr(string(buf)).ToObject()
+	}
+	l.emit(evalTokenString, "", obj)
+}
+
+// addSourceChar adds the character at the current position to a literal,
+// decoding it as UTF-8 for unicode literals.
+func (l *evalLexer) addSourceChar(add func(rune), isUnicode bool) {
+	if isUnicode {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		add(r)
+		l.pos += size
+	} else {
+		add(rune(l.src[l.pos]))
+		l.pos++
+	}
+}
+
+// lexEscape decodes the escape sequence following a backslash.
+func (l *evalLexer) lexEscape(add func(rune), isUnicode bool) {
+	if l.skipNewline() {
+		return
+	}
+	c := l.src[l.pos]
+	if simple, ok := map[byte]rune{'\\': '\\', '\'': '\'', '"': '"', 'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v'}[c]; ok {
+		add(simple)
+		l.pos++
+		return
+	}
+	if c >= '0' && c <= '7' {
+		end := l.pos + 1
+		for end < len(l.src) && end < l.pos+3 && l.src[end] >= '0' && l.src[end] <= '7' {
+			end++
+		}
+		v, _ := strconv.ParseUint(l.src[l.pos:end], 8, 32)
+		if !isUnicode {
+			v &= 0xff
+		}
+		add(rune(v))
+		l.pos = end
+		return
+	}
+	var digits int
+	switch {
+	case c == 'x':
+		digits = 2
+	case c == 'u' && isUnicode:
+		digits = 4
+	case c == 'U' && isUnicode:
+		digits = 8
+	default:
+		add('\\')
+		return
+	}
+	start := l.pos + 1
+	end := start
+	for end < len(l.src) && end < start+digits && isHexDigit(l.src[end]) {
+		end++
+	}
+	if end-start != digits {
+		if c == 'x' {
+			l.fail("invalid \\x escape")
+		}
+		l.fail("truncated \\" + string(c) + strings.Repeat("X", digits) + " escape")
+	}
+	v, _ := strconv.ParseUint(l.src[start:end], 16, 32)
+	if v > unicode.MaxRune {
+		l.fail("illegal Unicode character")
+	}
+	add(rune(v))
+	l.pos = end
+}
+
+func isEvalNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEvalNameChar(c byte) bool {
+	return isEvalNameStart(c) || isDigit(c)
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// evalScopeInfo records the names bound in a block of code. Python resolves
+// names statically so that any name bound within a function body is local to
+// that function unless declared global.
+type evalScopeInfo struct {
+	isFunction bool
+	locals     map[string]bool
+	globals    map[string]bool
+}
+
+func newEvalScopeInfo(isFunction bool) *evalScopeInfo {
+	return &evalScopeInfo{isFunction, map[string]bool{}, map[string]bool{}}
+}
+
+// evalExpr is a node of a parsed expression.
+type evalExpr interface {
+	eval(f *Frame, s *evalScope) (*Object, *BaseException)
+}
+
+// evalStmt is a node of a parsed statement.
+type evalStmt interface {
+	exec(f *Frame, s *evalScope) (evalFlow, *BaseException)
+	lineno() int
+}
+
+// evalCode is the result of parsing the source of an exec statement.
+type evalCode struct {
+	body []evalStmt
+	info *evalScopeInfo
+}
+
+type evalStmtLine int
+
+func (l evalStmtLine) lineno() int {
+	return int(l)
+}
+
+type evalCompKind int
+
+const (
+	evalCompList evalCompKind = iota
+	evalCompGen
+	evalCompSet
+	evalCompDict
+)
+
+type (
+	evalConst struct {
+		value *Object
+	}
+	evalName struct {
+		name *Str
+	}
+	evalAttribute struct {
+		value evalExpr
+		name  *Str
+	}
+	evalSubscript struct {
+		value evalExpr
+		index evalExpr
+	}
+	evalSlice struct {
+		lower, upper, step evalExpr
+	}
+	evalKeyword struct {
+		name  string
+		value evalExpr
+	}
+	evalCall struct {
+		fn       evalExpr
+		args     []evalExpr
+		keywords []evalKeyword
+		starargs evalExpr
+		kwargs   evalExpr
+	}
+	evalBinOp struct {
+		fn          binaryOpFunc
+		left, right evalExpr
+	}
+	evalUnaryOp struct {
+		fn      func(*Frame, *Object) (*Object, *BaseException)
+		operand evalExpr
+	}
+	evalNot struct {
+		operand evalExpr
+	}
+	evalBoolOp struct {
+		isAnd  bool
+		values []evalExpr
+	}
+	evalCompare struct {
+		left        evalExpr
+		ops         []string
+		comparators []evalExpr
+	}
+	evalIfExp struct {
+		test, body, orelse evalExpr
+	}
+	evalTuple struct {
+		elts []evalExpr
+	}
+	evalList struct {
+		elts []evalExpr
+	}
+	evalSet struct {
+		elts []evalExpr
+	}
+	evalDict struct {
+		keys, values []evalExpr
+	}
+	evalRepr struct {
+		value evalExpr
+	}
+	evalCompFor struct {
+		target evalExpr
+		iter   evalExpr
+		ifs    []evalExpr
+	}
+	// evalComp is a list, set or dict comprehension or a generator
+	// expression. value is only set for dict comprehensions and info is
+	// nil for list comprehensions, which run in the enclosing scope.
+	evalComp struct {
+		kind       evalCompKind
+		elt, value evalExpr
+		generators []*evalCompFor
+		info       *evalScopeInfo
+	}
+	evalFunction struct {
+		name     string
+		params   []string
+		defaults []evalExpr
+		vararg   string
+		kwarg    string
+		body     []evalStmt
+		info     *evalScopeInfo
+	}
+	evalLambda struct {
+		fn *evalFunction
+	}
+)
+
+type (
+	evalExprStmt struct {
+		evalStmtLine
+		value evalExpr
+	}
+	evalAssign struct {
+		evalStmtLine
+		targets []evalExpr
+		value   evalExpr
+	}
+	evalAugAssign struct {
+		evalStmtLine
+		target evalExpr
+		fn     binaryOpFunc
+		value  evalExpr
+	}
+	evalPrint struct {
+		evalStmtLine
+		dest   evalExpr
+		values []evalExpr
+		nl     bool
+	}
+	evalDel struct {
+		evalStmtLine
+		targets []evalExpr
+	}
+	evalPass struct {
+		evalStmtLine
+	}
+	evalBreak struct {
+		evalStmtLine
+	}
+	evalContinue struct {
+		evalStmtLine
+	}
+	evalReturn struct {
+		evalStmtLine
+		value evalExpr
+	}
+	evalIf struct {
+		evalStmtLine
+		test         evalExpr
+		body, orelse []evalStmt
+	}
+	evalWhile struct {
+		evalStmtLine
+		test         evalExpr
+		body, orelse []evalStmt
+	}
+	evalFor struct {
+		evalStmtLine
+		target, iter evalExpr
+		body, orelse []evalStmt
+	}
+	evalRaise struct {
+		evalStmtLine
+		typ, inst, tb evalExpr
+	}
+	evalAssert struct {
+		evalStmtLine
+		test, msg evalExpr
+	}
+	evalAlias struct {
+		name, asname string
+	}
+	evalImport struct {
+		evalStmtLine
+		names []evalAlias
+	}
+	evalImportFrom struct {
+		evalStmtLine
+		module string
+		names  []evalAlias
+	}
+	evalExecStmt struct {
+		evalStmtLine
+		body, globals, locals evalExpr
+	}
+	evalFunctionDef struct {
+		evalStmtLine
+		fn *evalFunction
+	}
+)
+
+var (
+	evalAugAssignOps = map[string]binaryOpFunc{
+		"+=": IAdd, "-=": ISub, "*=": IMul, "/=": IDiv, "//=": IFloorDiv,
+		"%=": IMod, "**=": IPow, "<<=": ILShift, ">>=": IRShift,
+		"&=": IAnd, "|=": IOr, "^=": IXor,
+	}
+	evalOrOps    = map[string]binaryOpFunc{"|": Or}
+	evalXorOps   = map[string]binaryOpFunc{"^": Xor}
+	evalAndOps   = map[string]binaryOpFunc{"&": And}
+	evalShiftOps = map[string]binaryOpFunc{"<<": LShift, ">>": RShift}
+	evalArithOps = map[string]binaryOpFunc{"+": Add, "-": Sub}
+	evalTermOps  = map[string]binaryOpFunc{"*": Mul, "/": Div, "%": Mod, "//": FloorDiv}
+	evalUnaryOps = map[string]func(*Frame, *Object) (*Object, *BaseException){
+		"+": Pos, "-": Neg, "~": Invert,
+	}
+	evalCompareOps = map[string]bool{
+		"<": true, ">": true, "==": true, ">=": true, "<=": true, "!=": true, "<>": true,
+	}
+)
+
+// evalParser is a recursive descent parser over the tokens of a source
+// string. Syntax errors abort parsing by panicking with an *evalSyntaxError
+// which is recovered by the parse entry points.
+type evalParser struct {
+	tokens []evalToken
+	pos    int
+	scope  *evalScopeInfo
+	loops  int
+}
+
+// evalParseExpr parses the source of an eval() expression.
+func evalParseExpr(src string) (expr evalExpr, err *evalSyntaxError) {
+	defer evalRecover(&err)
+	p := &evalParser{tokens: evalTokenize(src, true), scope: newEvalScopeInfo(false)}
+	expr = p.parseTestList()
+	for p.peek().kind == evalTokenNewline {
+		p.advance()
+	}
+	if p.peek().kind != evalTokenEOF {
+		p.syntaxError()
+	}
+	return expr, nil
+}
+
+// evalParseExec parses the source of an exec statement.
+func evalParseExec(src string) (code *evalCode, err *evalSyntaxError) {
+	defer evalRecover(&err)
+	p := &evalParser{tokens: evalTokenize(src, false), scope: newEvalScopeInfo(false)}
+	var body []evalStmt
+	for p.peek().kind != evalTokenEOF {
+		if p.peek().kind == evalTokenNewline {
+			p.advance()
+			continue
+		}
+		body = append(body, p.parseStmt()...)
+	}
+	return &evalCode{body, p.scope}, nil
+}
+
+func evalRecover(err **evalSyntaxError) {
+	if r := recover(); r != nil {
+		e, ok := r.(*evalSyntaxError)
+		if !ok {
+			panic(r)
+		}
+		*err = e
+	}
+}
+
+func (p *evalParser) peek() evalToken {
+	return p.tokens[p.pos]
+}
+
+func (p *evalParser) peekAt(n int) evalToken {
+	if i := p.pos + n; i < len(p.tokens) {
+		return p.tokens[i]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *evalParser) advance() evalToken {
+	t := p.tokens[p.pos]
+	if t.kind != evalTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *evalParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == evalTokenOp && t.value == op
+}
+
+func (p *evalParser) isName(name string) bool {
+	t := p.peek()
+	return t.kind == evalTokenName && t.value == name
+}
+
+func (p *evalParser) acceptOp(op string) bool {
+	if p.isOp(op) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *evalParser) acceptName(name string) bool {
+	if p.isName(name) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *evalParser) expectOp(op string) {
+	if !p.acceptOp(op) {
+		p.syntaxError()
+	}
+}
+
+func (p *evalParser) expectName(name string) {
+	if !p.acceptName(name) {
+		p.syntaxError()
+	}
+}
+
+// expectIdent consumes and returns an identifier that is not a keyword.
+func (p *evalParser) expectIdent() string {
+	t := p.peek()
+	if t.kind != evalTokenName || evalKeywords[t.value] {
+		p.syntaxError()
+	}
+	p.advance()
+	return t.value
+}
+
+func (p *evalParser) fail(line int, msg string) {
+	panic(&evalSyntaxError{msg, line})
+}
+
+func (p *evalParser) syntaxError() {
+	t := p.peek()
+	if t.kind == evalTokenEOF || (t.kind == evalTokenNewline && t.value == "") {
+		p.fail(t.line, "unexpected EOF while parsing")
+	}
+	if t.kind == evalTokenIndent {
+		p.fail(t.line, "unexpected indent")
+	}
+	p.fail(t.line, "invalid syntax")
+}
+
+// startsExpr returns true if the next token can begin an expression.
+func (p *evalParser) startsExpr() bool {
+	t := p.peek()
+	switch t.kind {
+	case evalTokenName:
+		return !evalKeywords[t.value] || t.value == "not" || t.value == "lambda"
+	case evalTokenNumber, evalTokenString:
+		return true
+	case evalTokenOp:
+		switch t.value {
+		case "(", "[", "{", "`", "-", "+", "~":
+			return true
+		}
+	}
+	return false
+}
+
+func (p *evalParser) atStmtEnd() bool {
+	t := p.peek()
+	return t.kind == evalTokenNewline || t.kind == evalTokenEOF || (t.kind == evalTokenOp && t.value == ";")
+}
+
+// declareLocal records that name is bound in the current function scope.
+func (p *evalParser) declareLocal(name string) {
+	if p.scope.isFunction {
+		p.scope.locals[name] = true
+	}
+}
+
+// bindTarget validates that e may be assigned to (or deleted, as described by
+// verb) and declares the names it binds.
+func (p *evalParser) bindTarget(e evalExpr, verb string, line int) {
+	var what string
+	switch t := e.(type) {
+	case *evalName:
+		if t.name.Value() == "None" {
+			p.fail(line, "cannot "+verb+" None")
+		}
+		p.declareLocal(t.name.Value())
+		return
+	case *evalAttribute, *evalSubscript:
+		return
+	case *evalTuple:
+		for _, elt := range t.elts {
+			p.bindTarget(elt, verb, line)
+		}
+		return
+	case *evalList:
+		for _, elt := range t.elts {
+			p.bindTarget(elt, verb, line)
+		}
+		return
+	case *evalConst:
+		what = "literal"
+	case *evalCall:
+		what = "function call"
+	case *evalLambda:
+		what = "lambda"
+	case *evalCompare:
+		what = "comparison"
+	case *evalIfExp:
+		what = "conditional expression"
+	case *evalRepr:
+		what = "repr"
+	case *evalComp:
+		what = map[evalCompKind]string{
+			evalCompList: "list comprehension",
+			evalCompGen:  "generator expression",
+			evalCompSet:  "set comprehension",
+			evalCompDict: "dict comprehension",
+		}[t.kind]
+	default:
+		what = "operator"
+	}
+	p.fail(line, "can't "+verb+" "+what)
+}
+
+func (p *evalParser) parseStmt() []evalStmt {
+	t := p.peek()
+	if t.kind == evalTokenName {
+		switch t.value {
+		case "if":
+			return []evalStmt{p.parseIf()}
+		case "while":
+			return []evalStmt{p.parseWhile()}
+		case "for":
+			return []evalStmt{p.parseFor()}
+		case "def":
+			return []evalStmt{p.parseFunctionDef()}
+		case "class", "try", "with":
+			p.fail(t.line, "'"+t.value+"' statements are not supported by exec")
+		}
+	} else if t.kind == evalTokenOp && t.value == "@" {
+		p.fail(t.line, "decorators are not supported by exec")
+	}
+	return p.parseSimpleStmt()
+}
+
+func (p *evalParser) parseSimpleStmt() []evalStmt {
+	stmts := []evalStmt{p.parseSmallStmt()}
+	for p.acceptOp(";") {
+		if p.peek().kind == evalTokenNewline {
+			break
+		}
+		stmts = append(stmts, p.parseSmallStmt())
+	}
+	if p.peek().kind != evalTokenNewline {
+		p.syntaxError()
+	}
+	p.advance()
+	return stmts
+}
+
+func (p *evalParser) parseSuite() []evalStmt {
+	if p.peek().kind != evalTokenNewline {
+		return p.parseSimpleStmt()
+	}
+	p.advance()
+	if t := p.peek(); t.kind != evalTokenIndent {
+		p.fail(t.line, "expected an indented block")
+	}
+	p.advance()
+	var body []evalStmt
+	for k := p.peek().kind; k != evalTokenDedent && k != evalTokenEOF; k = p.peek().kind {
+		body = append(body, p.parseStmt()...)
+	}
+	p.advance()
+	return body
+}
+
+func (p *evalParser) parseLoopBody() []evalStmt {
+	p.loops++
+	body := p.parseSuite()
+	p.loops--
+	return body
+}
+
+func (p *evalParser) parseElse() []evalStmt {
+	if !p.acceptName("else") {
+		return nil
+	}
+	p.expectOp(":")
+	return p.parseSuite()
+}
+
+func (p *evalParser) parseIf() evalStmt {
+	// The leading token is either "if" or "elif".
+	line := evalStmtLine(p.advance().line)
+	test := p.parseTest()
+	p.expectOp(":")
+	node := &evalIf{evalStmtLine: line, test: test, body: p.parseSuite()}
+	if p.isName("elif") {
+		node.orelse = []evalStmt{p.parseIf()}
+	} else {
+		node.orelse = p.parseElse()
+	}
+	return node
+}
+
+func (p *evalParser) parseWhile() evalStmt {
+	line := evalStmtLine(p.advance().line)
+	test := p.parseTest()
+	p.expectOp(":")
+	body := p.parseLoopBody()
+	return &evalWhile{line, test, body, p.parseElse()}
+}
+
+func (p *evalParser) parseFor() evalStmt {
+	line := evalStmtLine(p.advance().line)
+	target := p.parseExprList()
+	p.bindTarget(target, "assign to", int(line))
+	p.expectName("in")
+	iter := p.parseTestList()
+	p.expectOp(":")
+	body := p.parseLoopBody()
+	return &evalFor{line, target, iter, body, p.parseElse()}
+}
+
+func (p *evalParser) parseFunctionDef() evalStmt {
+	line := evalStmtLine(p.advance().line)
+	fn := &evalFunction{name: p.expectIdent()}
+	p.declareLocal(fn.name)
+	p.expectOp("(")
+	p.parseParams(fn, ")")
+	p.expectOp(")")
+	p.expectOp(":")
+	p.parseFunctionBody(fn, func() []evalStmt {
+		return p.parseSuite()
+	})
+	return &evalFunctionDef{line, fn}
+}
+
+// parseParams parses a parameter list up to but not including end. Default
+// values are parsed in the enclosing scope.
+func (p *evalParser) parseParams(fn *evalFunction, end string) {
+	seen := map[string]bool{}
+	declare := func(name string) {
+		if seen[name] {
+			p.fail(p.peek().line, "duplicate argument '"+name+"' in function definition")
+		}
+		seen[name] = true
+	}
+	for !p.isOp(end) {
+		if fn.kwarg != "" {
+			p.syntaxError()
+		}
+		if p.acceptOp("**") {
+			fn.kwarg = p.expectIdent()
+			declare(fn.kwarg)
+		} else if p.acceptOp("*") {
+			if fn.vararg != "" {
+				p.syntaxError()
+			}
+			fn.vararg = p.expectIdent()
+			declare(fn.vararg)
+		} else {
+			if fn.vararg != "" {
+				p.syntaxError()
+			}
+			if t := p.peek(); t.kind == evalTokenOp && t.value == "(" {
+				p.fail(t.line, "tuple parameters are not supported by exec")
+			}
+			name := p.expectIdent()
+			declare(name)
+			if p.acceptOp("=") {
+				fn.defaults = append(fn.defaults, p.parseTest())
+			} else if len(fn.defaults) > 0 {
+				p.fail(p.peek().line, "non-default argument follows default argument")
+			}
+			fn.params = append(fn.params, name)
+		}
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+}
+
+// parseFunctionBody parses the body of fn in a new function scope.
+func (p *evalParser) parseFunctionBody(fn *evalFunction, parseBody func() []evalStmt) {
+	outerScope, outerLoops := p.scope, p.loops
+	fn.info = newEvalScopeInfo(true)
+	for _, name := range append(fn.params, fn.vararg, fn.kwarg) {
+		if name != "" {
+			fn.info.locals[name] = true
+		}
+	}
+	p.scope, p.loops = fn.info, 0
+	fn.body = parseBody()
+	p.scope, p.loops = outerScope, outerLoops
+	for name := range fn.info.globals {
+		delete(fn.info.locals, name)
+	}
+}
+
+func (p *evalParser) parseSmallStmt() evalStmt {
+	t := p.peek()
+	line := evalStmtLine(t.line)
+	if t.kind == evalTokenName {
+		switch t.value {
+		case "pass":
+			p.advance()
+			return &evalPass{line}
+		case "break":
+			if p.loops == 0 {
+				p.fail(t.line, "'break' outside loop")
+			}
+			p.advance()
+			return &evalBreak{line}
+		case "continue":
+			if p.loops == 0 {
+				p.fail(t.line, "'continue' not properly in loop")
+			}
+			p.advance()
+			return &evalContinue{line}
+		case "return":
+			if !p.scope.isFunction {
+				p.fail(t.line, "'return' outside function")
+			}
+			p.advance()
+			var value evalExpr
+			if !p.atStmtEnd() {
+				value = p.parseTestList()
+			}
+			return &evalReturn{line, value}
+		case "yield":
+			if !p.scope.isFunction {
+				p.fail(t.line, "'yield' outside function")
+			}
+			p.fail(t.line, "'yield' is not supported by exec")
+		case "print":
+			return p.parsePrint()
+		case "del":
+			p.advance()
+			target := p.parseExprList()
+			p.bindTarget(target, "delete", t.line)
+			targets := []evalExpr{target}
+			if tuple, ok := target.(*evalTuple); ok {
+				targets = tuple.elts
+			}
+			return &evalDel{line, targets}
+		case "raise":
+			p.advance()
+			node := &evalRaise{evalStmtLine: line}
+			if !p.atStmtEnd() {
+				node.typ = p.parseTest()
+				if p.acceptOp(",") {
+					node.inst = p.parseTest()
+					if p.acceptOp(",") {
+						node.tb = p.parseTest()
+					}
+				}
+			}
+			return node
+		case "global":
+			p.advance()
+			for {
+				p.scope.globals[p.expectIdent()] = true
+				if !p.acceptOp(",") {
+					break
+				}
+			}
+			return &evalPass{line}
+		case "import":
+			return p.parseImport()
+		case "from":
+			return p.parseImportFrom()
+		case "assert":
+			p.advance()
+			node := &evalAssert{evalStmtLine: line, test: p.parseTest()}
+			if p.acceptOp(",") {
+				node.msg = p.parseTest()
+			}
+			return node
+		case "exec":
+			p.advance()
+			node := &evalExecStmt{evalStmtLine: line, body: p.parseExpr()}
+			if p.acceptName("in") {
+				node.globals = p.parseTest()
+				if p.acceptOp(",") {
+					node.locals = p.parseTest()
+				}
+			}
+			return node
+		}
+	}
+	return p.parseExprStmt()
+}
+
+func (p *evalParser) parseExprStmt() evalStmt {
+	t := p.peek()
+	line := evalStmtLine(t.line)
+	first := p.parseTestList()
+	if op := p.peek(); op.kind == evalTokenOp && evalAugAssignOps[op.value] != nil {
+		switch target := first.(type) {
+		case *evalName:
+			p.declareLocal(target.name.Value())
+		case *evalAttribute, *evalSubscript:
+		default:
+			p.fail(op.line, "illegal expression for augmented assignment")
+		}
+		p.advance()
+		return &evalAugAssign{line, first, evalAugAssignOps[op.value], p.parseTestList()}
+	}
+	if !p.isOp("=") {
+		return &evalExprStmt{line, first}
+	}
+	targets := []evalExpr{first}
+	for p.acceptOp("=") {
+		targets = append(targets, p.parseTestList())
+	}
+	value := targets[len(targets)-1]
+	targets = targets[:len(targets)-1]
+	for _, target := range targets {
+		p.bindTarget(target, "assign to", t.line)
+	}
+	return &evalAssign{line, targets, value}
+}
+
+func (p *evalParser) parsePrint() evalStmt {
+	node := &evalPrint{evalStmtLine: evalStmtLine(p.advance().line), nl: true}
+	if p.acceptOp(">>") {
+		node.dest = p.parseTest()
+		if !p.acceptOp(",") {
+			return node
+		}
+	}
+	for !p.atStmtEnd() {
+		node.values = append(node.values, p.parseTest())
+		if !p.acceptOp(",") {
+			break
+		}
+		node.nl = !p.atStmtEnd()
+	}
+	return node
+}
+
+func (p *evalParser) parseDottedName() string {
+	parts := []string{p.expectIdent()}
+	for p.acceptOp(".") {
+		parts = append(parts, p.expectIdent())
+	}
+	return strings.Join(parts, ".")
+}
+
+func (p *evalParser) parseImport() evalStmt {
+	node := &evalImport{evalStmtLine: evalStmtLine(p.advance().line)}
+	for {
+		alias := evalAlias{name: p.parseDottedName()}
+		bound := strings.SplitN(alias.name, ".", 2)[0]
+		if p.acceptName("as") {
+			alias.asname = p.expectIdent()
+			bound = alias.asname
+		}
+		p.declareLocal(bound)
+		node.names = append(node.names, alias)
+		if !p.acceptOp(",") {
+			return node
+		}
+	}
+}
+
+func (p *evalParser) parseImportFrom() evalStmt {
+	t := p.advance()
+	if p.isOp(".") {
+		p.fail(t.line, "relative imports are not supported by exec")
+	}
+	node := &evalImportFrom{evalStmtLine: evalStmtLine(t.line), module: p.parseDottedName()}
+	p.expectName("import")
+	if p.isOp("*") {
+		p.fail(t.line, "wildcard member import is not implemented")
+	}
+	parens := p.acceptOp("(")
+	for {
+		alias := evalAlias{name: p.expectIdent()}
+		alias.asname = alias.name
+		if p.acceptName("as") {
+			alias.asname = p.expectIdent()
+		}
+		p.declareLocal(alias.asname)
+		node.names = append(node.names, alias)
+		if !p.acceptOp(",") || (parens && p.isOp(")")) {
+			break
+		}
+	}
+	if parens {
+		p.expectOp(")")
+	}
+	if node.module == "__future__" {
+		return &evalPass{node.evalStmtLine}
+	}
+	return node
+}
+
+// parseExprList parses the target of a for loop or del statement.
+func (p *evalParser) parseExprList() evalExpr {
+	first := p.parseExpr()
+	if !p.isOp(",") {
+		return first
+	}
+	elts := []evalExpr{first}
+	for p.acceptOp(",") && p.startsExpr() {
+		elts = append(elts, p.parseExpr())
+	}
+	return &evalTuple{elts}
+}
+
+func (p *evalParser) parseTestList() evalExpr {
+	first := p.parseTest()
+	if !p.isOp(",") {
+		return first
+	}
+	elts := []evalExpr{first}
+	for p.acceptOp(",") && p.startsExpr() {
+		elts = append(elts, p.parseTest())
+	}
+	return &evalTuple{elts}
+}
+
+func (p *evalParser) parseTest() evalExpr {
+	if p.isName("lambda") {
+		return p.parseLambda()
+	}
+	e := p.parseOrTest()
+	if p.acceptName("if") {
+		test := p.parseOrTest()
+		p.expectName("else")
+		return &evalIfExp{test, e, p.parseTest()}
+	}
+	return e
+}
+
+func (p *evalParser) parseLambda() evalExpr {
+	line := evalStmtLine(p.advance().line)
+	fn := &evalFunction{name: "<lambda>"}
+	p.parseParams(fn, ":")
+	p.expectOp(":")
+	p.parseFunctionBody(fn, func() []evalStmt {
+		return []evalStmt{&evalReturn{line, p.parseTest()}}
+	})
+	return &evalLambda{fn}
+}
+
+func (p *evalParser) parseOrTest() evalExpr {
+	values := []evalExpr{p.parseAndTest()}
+	for p.acceptName("or") {
+		values = append(values, p.parseAndTest())
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return &evalBoolOp{false, values}
+}
+
+func (p *evalParser) parseAndTest() evalExpr {
+	values := []evalExpr{p.parseNotTest()}
+	for p.acceptName("and") {
+		values = append(values, p.parseNotTest())
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return &evalBoolOp{true, values}
+}
+
+func (p *evalParser) parseNotTest() evalExpr {
+	if p.acceptName("not") {
+		return &evalNot{p.parseNotTest()}
+	}
+	return p.parseComparison()
+}
+
+func (p *evalParser) parseComparison() evalExpr {
+	left := p.parseExpr()
+	var ops []string
+	var comparators []evalExpr
+	for {
+		t := p.peek()
+		var op string
+		switch {
+		case t.kind == evalTokenOp && evalCompareOps[t.value]:
+			op = t.value
+			if op == "<>" {
+				op = "!="
+			}
+		case p.isName("in"), p.isName("is"):
+			op = t.value
+		case p.isName("not") && p.peekAt(1).kind == evalTokenName && p.peekAt(1).value == "in":
+			p.advance()
+			op = "not in"
+		}
+		if op == "" {
+			break
+		}
+		p.advance()
+		if op == "is" && p.acceptName("not") {
+			op = "is not"
+		}
+		ops = append(ops, op)
+		comparators = append(comparators, p.parseExpr())
+	}
+	if len(ops) == 0 {
+		return left
+	}
+	return &evalCompare{left, ops, comparators}
+}
+
+func (p *evalParser) parseBinary(ops map[string]binaryOpFunc, parseOperand func() evalExpr) evalExpr {
+	left := parseOperand()
+	for {
+		t := p.peek()
+		fn := ops[t.value]
+		if t.kind != evalTokenOp || fn == nil {
+			return left
+		}
+		p.advance()
+		left = &evalBinOp{fn, left, parseOperand()}
+	}
+}
+
+func (p *evalParser) parseExpr() evalExpr {
+	return p.parseBinary(evalOrOps, p.parseXorExpr)
+}
+
+func (p *evalParser) parseXorExpr() evalExpr {
+	return p.parseBinary(evalXorOps, p.parseAndExpr)
+}
+
+func (p *evalParser) parseAndExpr() evalExpr {
+	return p.parseBinary(evalAndOps, p.parseShiftExpr)
+}
+
+func (p *evalParser) parseShiftExpr() evalExpr {
+	return p.parseBinary(evalShiftOps, p.parseArithExpr)
+}
+
+func (p *evalParser) parseArithExpr() evalExpr {
+	return p.parseBinary(evalArithOps, p.parseTerm)
+}
+
+func (p *evalParser) parseTerm() evalExpr {
+	return p.parseBinary(evalTermOps, p.parseFactor)
+}
+
+func (p *evalParser) parseFactor() evalExpr {
+	if t := p.peek(); t.kind == evalTokenOp && evalUnaryOps[t.value] != nil {
+		p.advance()
+		return &evalUnaryOp{evalUnaryOps[t.value], p.parseFactor()}
+	}
+	return p.parsePower()
+}
+
+func (p *evalParser) parsePower() evalExpr {
+	e := p.parseAtom()
+	for {
+		if p.acceptOp("(") {
+			e = p.parseCall(e)
+		} else if p.acceptOp("[") {
+			e = &evalSubscript{e, p.parseSubscriptList()}
+			p.expectOp("]")
+		} else if p.acceptOp(".") {
+			e = &evalAttribute{e, NewStr(p.expectIdent())}
+		} else {
+			break
+		}
+	}
+	if p.acceptOp("**") {
+		return &evalBinOp{Pow, e, p.parseFactor()}
+	}
+	return e
+}
+
+func (p *evalParser) parseCall(fn evalExpr) evalExpr {
+	call := &evalCall{fn: fn}
+	seen := map[string]bool{}
+	for !p.isOp(")") {
+		t := p.peek()
+		if call.kwargs != nil {
+			p.syntaxError()
+		}
+		if p.acceptOp("**") {
+			call.kwargs = p.parseTest()
+		} else if p.acceptOp("*") {
+			if call.starargs != nil {
+				p.syntaxError()
+			}
+			call.starargs = p.parseTest()
+		} else if t.kind == evalTokenName && p.peekAt(1).kind == evalTokenOp && p.peekAt(1).value == "=" {
+			name := p.expectIdent()
+			if seen[name] {
+				p.fail(t.line, "keyword argument repeated")
+			}
+			seen[name] = true
+			p.advance()
+			call.keywords = append(call.keywords, evalKeyword{name, p.parseTest()})
+		} else {
+			if call.starargs != nil {
+				p.fail(t.line, "only named arguments may follow *expression")
+			}
+			if len(call.keywords) > 0 {
+				p.fail(t.line, "non-keyword arg after keyword arg")
+			}
+			arg := p.parseTest()
+			if p.isName("for") {
+				arg = p.parseComp(evalCompGen, arg, nil)
+				if len(call.args) > 0 || !p.isOp(")") {
+					p.fail(t.line, "Generator expression must be parenthesized if not sole argument")
+				}
+			}
+			call.args = append(call.args, arg)
+		}
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	p.expectOp(")")
+	return call
+}
+
+func (p *evalParser) parseSubscriptList() evalExpr {
+	first := p.parseSubscript()
+	if !p.isOp(",") {
+		return first
+	}
+	elts := []evalExpr{first}
+	for p.acceptOp(",") && !p.isOp("]") {
+		elts = append(elts, p.parseSubscript())
+	}
+	return &evalTuple{elts}
+}
+
+func (p *evalParser) parseSubscript() evalExpr {
+	if p.isOp(".") && p.peekAt(1).value == "." && p.peekAt(2).value == "." {
+		p.pos += 3
+		return &evalConst{Ellipsis}
+	}
+	var lower evalExpr
+	if !p.isOp(":") {
+		lower = p.parseTest()
+		if !p.isOp(":") {
+			return lower
+		}
+	}
+	p.advance()
+	s := &evalSlice{lower: lower}
+	if p.startsExpr() {
+		s.upper = p.parseTest()
+	}
+	if p.acceptOp(":") && p.startsExpr() {
+		s.step = p.parseTest()
+	}
+	return s
+}
+
+func (p *evalParser) parseAtom() evalExpr {
+	t := p.peek()
+	switch t.kind {
+	case evalTokenName:
+		if evalKeywords[t.value] {
+			p.syntaxError()
+		}
+		p.advance()
+		return &evalName{NewStr(t.value)}
+	case evalTokenNumber:
+		p.advance()
+		return &evalConst{t.obj}
+	case evalTokenString:
+		return p.parseStrings()
+	case evalTokenOp:
+		switch t.value {
+		case "(":
+			p.advance()
+			if p.acceptOp(")") {
+				return &evalTuple{}
+			}
+			first := p.parseTest()
+			var e evalExpr
+			if p.isName("for") {
+				e = p.parseComp(evalCompGen, first, nil)
+			} else if p.isOp(",") {
+				elts := []evalExpr{first}
+				for p.acceptOp(",") && !p.isOp(")") {
+					elts = append(elts, p.parseTest())
+				}
+				e = &evalTuple{elts}
+			} else {
+				e = first
+			}
+			p.expectOp(")")
+			return e
+		case "[":
+			p.advance()
+			if p.acceptOp("]") {
+				return &evalList{}
+			}
+			first := p.parseTest()
+			var e evalExpr
+			if p.isName("for") {
+				e = p.parseComp(evalCompList, first, nil)
+			} else {
+				elts := []evalExpr{first}
+				for p.acceptOp(",") && !p.isOp("]") {
+					elts = append(elts, p.parseTest())
+				}
+				e = &evalList{elts}
+			}
+			p.expectOp("]")
+			return e
+		case "{":
+			p.advance()
+			if p.acceptOp("}") {
+				return &evalDict{}
+			}
+			e := p.parseDictOrSet()
+			p.expectOp("}")
+			return e
+		case "`":
+			p.advance()
+			e := p.parseTestList()
+			p.expectOp("`")
+			return &evalRepr{e}
+		}
+	}
+	p.syntaxError()
+	return nil
+}
+
+func (p *evalParser) parseDictOrSet() evalExpr {
+	first := p.parseTest()
+	if !p.acceptOp(":") {
+		if p.isName("for") {
+			return p.parseComp(evalCompSet, first, nil)
+		}
+		elts := []evalExpr{first}
+		for p.acceptOp(",") && !p.isOp("}") {
+			elts = append(elts, p.parseTest())
+		}
+		return &evalSet{elts}
+	}
+	value := p.parseTest()
+	if p.isName("for") {
+		return p.parseComp(evalCompDict, first, value)
+	}
+	d := &evalDict{[]evalExpr{first}, []evalExpr{value}}
+	for p.acceptOp(",") && !p.isOp("}") {
+		d.keys = append(d.keys, p.parseTest())
+		p.expectOp(":")
+		d.values = append(d.values, p.parseTest())
+	}
+	return d
+}
+
+// parseComp parses the for and if clauses of a comprehension whose element
+// expressions have already been parsed. Except for list comprehensions, the
+// clauses are parsed in a new function scope. The first iterable is always
+// evaluated in the enclosing scope.
+func (p *evalParser) parseComp(kind evalCompKind, elt, value evalExpr) evalExpr {
+	comp := &evalComp{kind: kind, elt: elt, value: value}
+	outer := p.scope
+	if kind != evalCompList {
+		comp.info = newEvalScopeInfo(true)
+		p.scope = comp.info
+	}
+	for p.isName("for") {
+		line := p.advance().line
+		gen := &evalCompFor{target: p.parseExprList()}
+		p.bindTarget(gen.target, "assign to", line)
+		p.expectName("in")
+		if len(comp.generators) == 0 {
+			p.scope = outer
+		}
+		gen.iter = p.parseOrTest()
+		if kind == evalCompList && p.isOp(",") {
+			elts := []evalExpr{gen.iter}
+			for p.acceptOp(",") && p.startsExpr() {
+				elts = append(elts, p.parseOrTest())
+			}
+			gen.iter = &evalTuple{elts}
+		}
+		if comp.info != nil {
+			p.scope = comp.info
+		}
+		for p.acceptName("if") {
+			gen.ifs = append(gen.ifs, p.parseOrTest())
+		}
+		comp.generators = append(comp.generators, gen)
+	}
+	p.scope = outer
+	return comp
+}
+
+// parseStrings parses a sequence of adjacent string literals, which are
+// concatenated. The result is unicode if any of the literals are.
+func (p *evalParser) parseStrings() evalExpr {
+	var parts []*Object
+	isUnicode := false
+	for p.peek().kind == evalTokenString {
+		o := p.advance().obj
+		isUnicode = isUnicode || o.isInstance(UnicodeType)
+		parts = append(parts, o)
+	}
+	if len(parts) == 1 {
+		return &evalConst{parts[0]}
+	}
+	if !isUnicode {
+		var buf []byte
+		for _, o := range parts {
+			buf = append(buf, toStrUnsafe(o).Value()...)
+		}
+		return &evalConst{NewStr(string(buf)).ToObject()}
+	}
+	var runes []rune
+	for _, o := range parts {
+		if o.isInstance(UnicodeType) {
+			runes = append(runes, toUnicodeUnsafe(o).Value()...)
+		} else {
+			runes = append(runes, []rune(toStrUnsafe(o).Value())...)
+		}
+	}
+	return &evalConst{NewUnicodeFromRunes(runes).ToObject()}
+}
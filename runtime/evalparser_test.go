@@ -0,0 +1,99 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvalTokenize(t *testing.T) {
+	const (
+		eof     = evalTokenEOF
+		name    = evalTokenName
+		number  = evalTokenNumber
+		str     = evalTokenString
+		op      = evalTokenOp
+		newline = evalTokenNewline
+		indent  = evalTokenIndent
+		dedent  = evalTokenDedent
+	)
+	cases := []struct {
+		src      string
+		evalMode bool
+		want     []evalTokenKind
+	}{
+		{"x", true, []evalTokenKind{name, newline, eof}},
+		{"", false, []evalTokenKind{eof}},
+		{"a.b(1, 'c') # comment", true, []evalTokenKind{name, op, name, op, number, op, str, op, newline, eof}},
+		{"x **= 2\n", false, []evalTokenKind{name, op, number, newline, eof}},
+		{"(1,\n 2)", false, []evalTokenKind{op, number, op, number, op, newline, eof}},
+		{"1 + \\\n 2", false, []evalTokenKind{number, op, number, newline, eof}},
+		{"if x:\n  y\n\n  # foo\nz", false, []evalTokenKind{name, name, op, newline, indent, name, newline, dedent, name, newline, eof}},
+		{"if x:\n\tif y:\n\t\tz", false, []evalTokenKind{name, name, op, newline, indent, name, name, op, newline, indent, name, newline, dedent, dedent, eof}},
+		{"  x\n  y", true, []evalTokenKind{name, newline, name, newline, eof}},
+		{`ur"\n" b'x'`, true, []evalTokenKind{str, str, newline, eof}},
+	}
+	for _, cas := range cases {
+		var got []evalTokenKind
+		for _, tok := range evalTokenize(cas.src, cas.evalMode) {
+			got = append(got, tok.kind)
+		}
+		if !reflect.DeepEqual(got, cas.want) {
+			t.Errorf("evalTokenize(%q, %v) = %v, want %v", cas.src, cas.evalMode, got, cas.want)
+		}
+	}
+}
+
+func TestEvalTokenizeInvalid(t *testing.T) {
+	cases := []struct {
+		src  string
+		want evalSyntaxError
+	}{
+		{"x $ y", evalSyntaxError{"invalid syntax", 1}},
+		{"1\n2abc", evalSyntaxError{"invalid syntax", 2}},
+		{"09", evalSyntaxError{"invalid token", 1}},
+		{"'''abc\n", evalSyntaxError{"EOF while scanning triple-quoted string literal", 1}},
+		{`'\x4'`, evalSyntaxError{`invalid \x escape`, 1}},
+		{`u'\u12'`, evalSyntaxError{`truncated \uXXXX escape`, 1}},
+		{"x \\ y", evalSyntaxError{"unexpected character after line continuation character", 1}},
+	}
+	for _, cas := range cases {
+		_, err := evalParseExpr(cas.src)
+		if err == nil || *err != cas.want {
+			t.Errorf("evalParseExpr(%q) failed with %v, want %v", cas.src, err, cas.want)
+		}
+	}
+}
+
+func TestEvalParseScopes(t *testing.T) {
+	code, err := evalParseExec("def f(a, *b):\n  global g\n  c = g = 1\n  for d, e in x: pass\n  import h.i\n  return lambda j: [k for k in j]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.info.isFunction || len(code.info.locals) != 0 {
+		t.Errorf("module scope info = %v, want no locals", code.info)
+	}
+	fn := code.body[0].(*evalFunctionDef).fn
+	wantLocals := map[string]bool{"a": true, "b": true, "c": true, "d": true, "e": true, "h": true}
+	if !reflect.DeepEqual(fn.info.locals, wantLocals) {
+		t.Errorf("%s() locals = %v, want %v", fn.name, fn.info.locals, wantLocals)
+	}
+	lambda := fn.body[len(fn.body)-1].(*evalReturn).value.(*evalLambda).fn
+	wantLocals = map[string]bool{"j": true, "k": true}
+	if !reflect.DeepEqual(lambda.info.locals, wantLocals) {
+		t.Errorf("%s locals = %v, want %v", lambda.name, lambda.info.locals, wantLocals)
+	}
+}
@@ -14,6 +14,10 @@
 
 package grumpy
 
+import (
+	"reflect"
+)
+
 var (
 	// ArithmeticErrorType corresponds to the Python type 'ArithmeticError'.
 	ArithmeticErrorType = newSimpleType("ArithmeticError", StandardErrorType)
@@ -34,6 +38,10 @@ var (
 	ExceptionType = newSimpleType("Exception", BaseExceptionType)
 	// FutureWarningType corresponds to the Python type 'FutureWarning'.
 	FutureWarningType = newSimpleType("FutureWarning", WarningType)
+	// GoErrorType is the Python type 'GoError' which is raised when a Go
+	// error value propagates into Python code. The original error is
+	// available via the go_error attribute and ToNative.
+	GoErrorType = newSimpleType("GoError", IOErrorType)
 	// ImportErrorType corresponds to the Python type 'ImportError'.
 	ImportErrorType = newSimpleType("ImportError", StandardErrorType)
 	// ImportWarningType corresponds to the Python type 'ImportWarning'.
@@ -67,6 +75,9 @@ var (
 	RuntimeErrorType = newSimpleType("RuntimeError", StandardErrorType)
 	// RuntimeWarningType corresponds to the Python type 'RuntimeWarning'.
 	RuntimeWarningType = newSimpleType("RuntimeWarning", WarningType)
+	// SecurityErrorType is raised when code attempts an operation that is
+	// denied by the Policy of the stack it is running on.
+	SecurityErrorType = newSimpleType("SecurityError", StandardErrorType)
 	// StandardErrorType corresponds to the Python type 'StandardError'.
 	StandardErrorType = newSimpleType("StandardError", ExceptionType)
 	// StopIterationType corresponds to the Python type 'StopIteration'.
@@ -105,9 +116,13 @@ var (
 
 func systemExitInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	baseExceptionInit(f, o, args, kwargs)
+	// Like CPython, code is the sole argument if there's exactly one and the
+	// tuple of arguments if there are several.
 	code := None
-	if len(args) > 0 {
+	if len(args) == 1 {
 		code = args[0]
+	} else if len(args) > 1 {
+		code = NewTuple(args.makeCopy()...).ToObject()
 	}
 	if raised := SetAttr(f, o, NewStr("code"), code); raised != nil {
 		return nil, raised
@@ -118,3 +133,48 @@ func systemExitInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *Ba
 func initSystemExitType(map[string]*Object) {
 	SystemExitType.slots.Init = &initSlot{systemExitInit}
 }
+
+// RaiseGoError returns a GoError exception wrapping err and sets it as the
+// exception being raised by f.
+func RaiseGoError(f *Frame, err error) *BaseException {
+	o, raised := WrapNative(f, reflect.ValueOf(err))
+	if raised != nil {
+		return raised
+	}
+	return f.Raise(GoErrorType.ToObject(), o, nil)
+}
+
+func goErrorInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	// When constructed from a native error value, the message is the
+	// error's Error() string and the value itself is stored in go_error.
+	goErr := None
+	if len(args) == 1 && args[0].isInstance(nativeType) {
+		if err, ok := toNativeUnsafe(args[0]).value.Interface().(error); ok {
+			goErr = args[0]
+			args = Args{NewStr(err.Error()).ToObject()}
+		}
+	}
+	baseExceptionInit(f, o, args, kwargs)
+	if raised := SetAttr(f, o, NewStr("go_error"), goErr); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func goErrorNative(f *Frame, o *Object) (reflect.Value, *BaseException) {
+	if d := o.Dict(); d != nil {
+		goErr, raised := d.GetItemString(f, "go_error")
+		if raised != nil {
+			return reflect.Value{}, raised
+		}
+		if goErr != nil && goErr != None {
+			return ToNative(f, goErr)
+		}
+	}
+	return reflect.ValueOf(o), nil
+}
+
+func initGoErrorType(map[string]*Object) {
+	GoErrorType.slots.Init = &initSlot{goErrorInit}
+	GoErrorType.slots.Native = &nativeSlot{goErrorNative}
+}
@@ -0,0 +1,187 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// ToGoValue converts o into plain Go data that can be marshaled with
+// encoding/json or encoding/gob without any knowledge of Python objects:
+//
+// - None becomes nil.
+// - bool becomes bool, int becomes int and float becomes float64.
+// - long becomes *big.Int.
+// - str becomes string and unicode becomes its UTF-8 encoded string.
+// - list and tuple become []interface{}.
+// - dict becomes map[string]interface{}. Its keys must be str or unicode.
+//
+// Subclasses of these types are converted like their base type. Other types,
+// as well as containers that contain themselves, raise TypeError and
+// ValueError respectively. Note that gob requires []interface{} and
+// map[string]interface{} to be registered with gob.Register before they can
+// be nested inside one another.
+func ToGoValue(f *Frame, o *Object) (interface{}, *BaseException) {
+	return toGoValue(f, o, map[*Object]bool{})
+}
+
+func toGoValue(f *Frame, o *Object, visiting map[*Object]bool) (interface{}, *BaseException) {
+	switch {
+	case o == None:
+		return nil, nil
+	case o.isInstance(BoolType):
+		return toIntUnsafe(o).IsTrue(), nil
+	case o.isInstance(IntType):
+		return toIntUnsafe(o).Value(), nil
+	case o.isInstance(LongType):
+		return toLongUnsafe(o).Value(), nil
+	case o.isInstance(FloatType):
+		return toFloatUnsafe(o).Value(), nil
+	case o.isInstance(StrType):
+		return toStrUnsafe(o).Value(), nil
+	case o.isInstance(UnicodeType):
+		return string(toUnicodeUnsafe(o).Value()), nil
+	case o.isInstance(ListType), o.isInstance(TupleType), o.isInstance(DictType):
+		if visiting[o] {
+			return nil, f.RaiseType(ValueErrorType, "circular reference detected")
+		}
+		visiting[o] = true
+		defer delete(visiting, o)
+	default:
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("%s is not convertible to a Go value", o.typ.Name()))
+	}
+	if o.isInstance(DictType) {
+		entries := toDictUnsafe(o).snapshot(f)
+		m := make(map[string]interface{}, len(entries))
+		for _, entry := range entries {
+			var key string
+			switch {
+			case entry.key.isInstance(StrType):
+				key = toStrUnsafe(entry.key).Value()
+			case entry.key.isInstance(UnicodeType):
+				key = string(toUnicodeUnsafe(entry.key).Value())
+			default:
+				return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("dict key must be str or unicode, not %s", entry.key.typ.Name()))
+			}
+			value, raised := toGoValue(f, entry.value, visiting)
+			if raised != nil {
+				return nil, raised
+			}
+			m[key] = value
+		}
+		return m, nil
+	}
+	var elems []*Object
+	if o.isInstance(ListType) {
+		l := toListUnsafe(o)
+		l.mutex.RLock()
+		elems = make([]*Object, len(l.elems))
+		copy(elems, l.elems)
+		l.mutex.RUnlock()
+	} else {
+		elems = toTupleUnsafe(o).elems
+	}
+	s := make([]interface{}, len(elems))
+	for i, elem := range elems {
+		value, raised := toGoValue(f, elem, visiting)
+		if raised != nil {
+			return nil, raised
+		}
+		s[i] = value
+	}
+	return s, nil
+}
+
+// FromGoValue is the inverse of ToGoValue. It converts plain Go data such as
+// the result of unmarshaling JSON into interface{} back into Python objects.
+// Slices and arrays become lists, maps with string keys become dicts, integers
+// become int (or long when they overflow int), *big.Int becomes long, strings
+// become str and []byte becomes str. Other values raise TypeError.
+func FromGoValue(f *Frame, v interface{}) (*Object, *BaseException) {
+	switch x := v.(type) {
+	case nil:
+		return None, nil
+	case *Object:
+		return x, nil
+	case *big.Int:
+		if x == nil {
+			return None, nil
+		}
+		return NewLong(x).ToObject(), nil
+	case []byte:
+		return NewStr(string(x)).ToObject(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return GetBool(rv.Bool()).ToObject(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := rv.Int()
+		if i < int64(MinInt) || i > int64(MaxInt) {
+			return NewLong(big.NewInt(i)).ToObject(), nil
+		}
+		return NewInt(int(i)).ToObject(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i := rv.Uint()
+		if i > uint64(MaxInt) {
+			return NewLong(new(big.Int).SetUint64(i)).ToObject(), nil
+		}
+		return NewInt(int(i)).ToObject(), nil
+	case reflect.Float32, reflect.Float64:
+		return NewFloat(rv.Float()).ToObject(), nil
+	case reflect.String:
+		return NewStr(rv.String()).ToObject(), nil
+	case reflect.Interface, reflect.Ptr:
+		if rv.IsNil() {
+			return None, nil
+		}
+		return FromGoValue(f, rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return None, nil
+		}
+		n := rv.Len()
+		elems := make([]*Object, n)
+		for i := 0; i < n; i++ {
+			elem, raised := FromGoValue(f, rv.Index(i).Interface())
+			if raised != nil {
+				return nil, raised
+			}
+			elems[i] = elem
+		}
+		return NewList(elems...).ToObject(), nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return None, nil
+		}
+		d := NewDict()
+		for _, key := range rv.MapKeys() {
+			value, raised := FromGoValue(f, rv.MapIndex(key).Interface())
+			if raised != nil {
+				return nil, raised
+			}
+			if raised := d.SetItemString(f, key.String(), value); raised != nil {
+				return nil, raised
+			}
+		}
+		return d.ToObject(), nil
+	}
+	return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("cannot convert Go value of type %T", v))
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestToGoValue(t *testing.T) {
+	recursive := NewList()
+	recursive.Append(recursive.ToObject())
+	cases := []struct {
+		o       *Object
+		want    interface{}
+		wantExc *BaseException
+	}{
+		{None, nil, nil},
+		{True.ToObject(), true, nil},
+		{NewInt(42).ToObject(), 42, nil},
+		{NewLong(big.NewInt(-3)).ToObject(), big.NewInt(-3), nil},
+		{NewFloat(1.5).ToObject(), 1.5, nil},
+		{NewStr("foo").ToObject(), "foo", nil},
+		{NewUnicode("café").ToObject(), "café", nil},
+		{newTestList(1, "a", None).ToObject(), []interface{}{1, "a", nil}, nil},
+		{newTestTuple(newTestTuple(), 2.0).ToObject(), []interface{}{[]interface{}{}, 2.0}, nil},
+		{newTestDict("a", 1, NewUnicode("b"), newTestList(true)).ToObject(), map[string]interface{}{"a": 1, "b": []interface{}{true}}, nil},
+		{newTestDict(1, 2).ToObject(), nil, mustCreateException(TypeErrorType, "dict key must be str or unicode, not int")},
+		{NewSet().ToObject(), nil, mustCreateException(TypeErrorType, "set is not convertible to a Go value")},
+		{recursive.ToObject(), nil, mustCreateException(ValueErrorType, "circular reference detected")},
+	}
+	for _, cas := range cases {
+		got, raised := ToGoValue(NewRootFrame(), cas.o)
+		if !exceptionsAreEquivalent(raised, cas.wantExc) {
+			t.Errorf("ToGoValue(%v) raised %v, want %v", cas.o, raised, cas.wantExc)
+		} else if raised == nil && !reflect.DeepEqual(got, cas.want) {
+			t.Errorf("ToGoValue(%v) = %#v, want %#v", cas.o, got, cas.want)
+		}
+	}
+}
+
+func TestFromGoValue(t *testing.T) {
+	cases := []struct {
+		v       interface{}
+		want    *Object
+		wantExc *BaseException
+	}{
+		{nil, None, nil},
+		{false, False.ToObject(), nil},
+		{int8(-4), NewInt(-4).ToObject(), nil},
+		{uint64(1) << 63, NewLong(new(big.Int).SetUint64(1 << 63)).ToObject(), nil},
+		{big.NewInt(7), NewLong(big.NewInt(7)).ToObject(), nil},
+		{float32(0.5), NewFloat(0.5).ToObject(), nil},
+		{"foo", NewStr("foo").ToObject(), nil},
+		{[]byte("bar"), NewStr("bar").ToObject(), nil},
+		{[]interface{}{1, "a", nil}, newTestList(1, "a", None).ToObject(), nil},
+		{[2]int{1, 2}, newTestList(1, 2).ToObject(), nil},
+		{map[string]interface{}{"a": []string{"b"}}, newTestDict("a", newTestList("b")).ToObject(), nil},
+		{map[int]int{}, nil, mustCreateException(TypeErrorType, "cannot convert Go value of type map[int]int")},
+		{struct{}{}, nil, mustCreateException(TypeErrorType, "cannot convert Go value of type struct {}")},
+	}
+	for _, cas := range cases {
+		f := NewRootFrame()
+		got, raised := FromGoValue(f, cas.v)
+		if !exceptionsAreEquivalent(raised, cas.wantExc) {
+			t.Errorf("FromGoValue(%#v) raised %v, want %v", cas.v, raised, cas.wantExc)
+			continue
+		}
+		if raised != nil {
+			continue
+		}
+		if got.typ != cas.want.typ || mustNotRaise(Eq(f, got, cas.want)) != True.ToObject() {
+			t.Errorf("FromGoValue(%#v) = %v, want %v", cas.v, got, cas.want)
+		}
+	}
+}
+
+func TestGoValueJSONRoundTrip(t *testing.T) {
+	f := NewRootFrame()
+	o := newTestDict("foo", newTestList(1, 2.5, "bar", None), "baz", newTestDict("qux", true)).ToObject()
+	v, raised := ToGoValue(f, o)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	got, raised := FromGoValue(f, decoded)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	// JSON numbers decode as float64 so ints come back as floats, which
+	// compare equal.
+	if mustNotRaise(Eq(f, got, o)) != True.ToObject() {
+		t.Errorf("round trip of %v through %s = %v", o, b, got)
+	}
+}
@@ -17,6 +17,7 @@ package grumpy
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -32,17 +33,39 @@ type File struct {
 	// mutex synchronizes the state of the File struct, not access to the
 	// underlying os.File. So, for example, when doing file reads and
 	// writes we only acquire a read lock.
-	mutex       sync.Mutex
-	mode        string
-	open        bool
-	Softspace   int `attr:"softspace" attr_mode:"rw"`
-	reader      *bufio.Reader
-	file        *os.File
+	mutex     sync.Mutex
+	mode      string
+	open      bool
+	Softspace int `attr:"softspace" attr_mode:"rw"`
+	reader    *bufio.Reader
+	// writer buffers writes when the file was opened with a positive
+	// buffering argument. It is nil for unbuffered files.
+	writer     *bufio.Writer
+	lineBuffer bool
+	file       *os.File
+	// in and out are the streams read and written when file is nil, as
+	// for files created by NewFileFromStreams. Either may be nil.
+	in          io.Reader
+	out         io.Writer
+	streamName  string
 	skipNextLF  bool
 	univNewLine bool
 	close       *Object
 }
 
+var (
+	errFileNotReadable = errors.New("File not open for reading")
+	errFileNotWritable = errors.New("File not open for writing")
+	errFileNotSeekable = errors.New("[Errno 29] Illegal seek")
+)
+
+// unreadableStream is the reader of files with no input stream.
+type unreadableStream struct{}
+
+func (unreadableStream) Read([]byte) (int, error) {
+	return 0, errFileNotReadable
+}
+
 // NewFileFromFD creates a file object from the given file descriptor fd.
 func NewFileFromFD(fd uintptr, close *Object) *File {
 	// TODO: Use fcntl or something to get the mode of the descriptor.
@@ -59,6 +82,18 @@ func NewFileFromFD(fd uintptr, close *Object) *File {
 	return file
 }
 
+// NewFileFromStreams returns an open file object called name that reads from
+// r and writes to w. Either may be nil, in which case reading or writing
+// raises an error. Writes are unbuffered and are serialized by the file so w
+// need not be safe for concurrent use. Closing the file does not close r or
+// w. This allows embedders to connect Python code to arbitrary Go streams,
+// e.g. by assigning the file to sys.stdout.
+func NewFileFromStreams(name string, r io.Reader, w io.Writer) *File {
+	file := &File{Object: Object{typ: FileType}, open: true}
+	file.setStreams(name, r, w)
+	return file
+}
+
 func toFileUnsafe(o *Object) *File {
 	return (*File)(o.toPointer())
 }
@@ -67,16 +102,156 @@ func (f *File) name() string {
 	name := "<uninitialized file>"
 	if f.file != nil {
 		name = f.file.Name()
+	} else if f.streamName != "" {
+		name = f.streamName
 	}
 	return name
 }
 
+// setStreams makes f read from r and write to w instead of an os.File. The
+// mode of f reflects which of r and w are non-nil. f.mutex must be held.
+func (f *File) setStreams(name string, r io.Reader, w io.Writer) {
+	f.file = nil
+	f.in = r
+	f.out = w
+	f.streamName = name
+	switch {
+	case r != nil && w != nil:
+		f.mode = "r+"
+	case w != nil:
+		f.mode = "w"
+	default:
+		f.mode = "r"
+	}
+	if r == nil {
+		r = unreadableStream{}
+	}
+	f.reader = bufio.NewReader(r)
+	f.writer = nil
+	f.lineBuffer = false
+	f.skipNextLF = false
+	f.univNewLine = false
+}
+
+// Redirect makes f read from r and write to w, as for NewFileFromStreams,
+// until the returned function is called to restore its previous streams.
+// Pending buffered writes are flushed first. Redirecting Stdout or Stderr
+// affects sys.stdout and sys.stderr too, unless they've been reassigned.
+func (f *File) Redirect(r io.Reader, w io.Writer) (restore func()) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.writer != nil {
+		f.writer.Flush()
+	}
+	mode, reader, writer, lineBuffer := f.mode, f.reader, f.writer, f.lineBuffer
+	file, in, out, streamName := f.file, f.in, f.out, f.streamName
+	skipNextLF, univNewLine := f.skipNextLF, f.univNewLine
+	f.setStreams(f.name(), r, w)
+	return func() {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.mode, f.reader, f.writer, f.lineBuffer = mode, reader, writer, lineBuffer
+		f.file, f.in, f.out, f.streamName = file, in, out, streamName
+		f.skipNextLF, f.univNewLine = skipNextLF, univNewLine
+	}
+}
+
 // ToObject upcasts f to an Object.
 func (f *File) ToObject() *Object {
 	return &f.Object
 }
 
+// prepareRead flushes pending writes so that subsequent reads observe them.
+// f.mutex must be held.
+func (f *File) prepareRead() error {
+	if f.writer != nil {
+		return f.writer.Flush()
+	}
+	return nil
+}
+
+// prepareWrite discards data buffered for reading, repositioning the
+// underlying file at the logical read position so that writes land where
+// the caller expects. f.mutex must be held.
+func (f *File) prepareWrite() error {
+	if f.file == nil {
+		return nil
+	}
+	if n := f.reader.Buffered(); n > 0 {
+		if _, err := f.file.Seek(int64(-n), io.SeekCurrent); err != nil {
+			return err
+		}
+		f.reader.Reset(f.file)
+	}
+	return nil
+}
+
+// seek moves the logical position of f, flushing and discarding any buffered
+// data. f.mutex must be held.
+func (f *File) seek(offset int64, whence int) (int64, error) {
+	if f.file == nil {
+		return 0, errFileNotSeekable
+	}
+	if err := f.prepareRead(); err != nil {
+		return 0, err
+	}
+	if whence == io.SeekCurrent {
+		offset -= int64(f.reader.Buffered())
+	}
+	pos, err := f.file.Seek(offset, whence)
+	if err != nil {
+		return 0, err
+	}
+	f.reader.Reset(f.file)
+	f.skipNextLF = false
+	return pos, nil
+}
+
+// tell returns the logical position of f taking buffered data into account.
+// f.mutex must be held.
+func (f *File) tell() (int64, error) {
+	if f.file == nil {
+		return 0, errFileNotSeekable
+	}
+	pos, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	pos -= int64(f.reader.Buffered())
+	if f.writer != nil {
+		pos += int64(f.writer.Buffered())
+	}
+	return pos, nil
+}
+
+// write writes s to f honoring its buffering mode. f.mutex must be held.
+func (f *File) write(s string) error {
+	if err := f.prepareWrite(); err != nil {
+		return err
+	}
+	if f.writer == nil {
+		var w io.Writer = f.file
+		if f.file == nil {
+			if w = f.out; w == nil {
+				return errFileNotWritable
+			}
+		}
+		_, err := io.WriteString(w, s)
+		return err
+	}
+	if _, err := f.writer.WriteString(s); err != nil {
+		return err
+	}
+	if f.lineBuffer && strings.Contains(s, "\n") {
+		return f.writer.Flush()
+	}
+	return nil
+}
+
 func (f *File) readLine(maxBytes int) (string, error) {
+	if err := f.prepareRead(); err != nil {
+		return "", err
+	}
 	var buf bytes.Buffer
 	numBytesRead := 0
 	for maxBytes < 0 || numBytesRead < maxBytes {
@@ -113,11 +288,7 @@ func (f *File) writeString(s string) error {
 	if !f.open {
 		return io.ErrClosedPipe
 	}
-	if _, err := f.file.Write([]byte(s)); err != nil {
-		return err
-	}
-
-	return nil
+	return f.write(s)
 }
 
 // FileType is the object representing the Python 'file' type.
@@ -125,17 +296,30 @@ var FileType = newBasisType("file", reflect.TypeOf(File{}), toFileUnsafe, Object
 
 func fileInit(f *Frame, o *Object, args Args, _ KWArgs) (*Object, *BaseException) {
 	argc := len(args)
-	expectedTypes := []*Type{StrType, StrType}
+	expectedTypes := []*Type{StrType, StrType, ObjectType}
+	if argc < 3 {
+		expectedTypes = expectedTypes[:2]
+	}
 	if argc == 1 {
 		expectedTypes = expectedTypes[:1]
 	}
 	if raised := checkFunctionArgs(f, "__init__", args, expectedTypes...); raised != nil {
 		return nil, raised
 	}
+	if raised := f.checkFileAccess(toStrUnsafe(args[0]).Value()); raised != nil {
+		return nil, raised
+	}
 	mode := "r"
 	if argc > 1 {
 		mode = toStrUnsafe(args[1]).Value()
 	}
+	buffering := -1
+	if argc > 2 {
+		var raised *BaseException
+		if buffering, raised = ToIntValue(f, args[2]); raised != nil {
+			return nil, raised
+		}
+	}
 	// TODO: Do something with the binary mode flag.
 	var flag int
 	switch mode {
@@ -160,12 +344,22 @@ func fileInit(f *Frame, o *Object, args Args, _ KWArgs) (*Object, *BaseException
 	defer file.mutex.Unlock()
 	osFile, err := os.OpenFile(toStrUnsafe(args[0]).Value(), flag, 0644)
 	if err != nil {
-		return nil, f.RaiseType(IOErrorType, err.Error())
+		return nil, RaiseGoError(f, err)
 	}
 	file.mode = mode
 	file.open = true
 	file.file = osFile
 	file.reader = bufio.NewReader(osFile)
+	// Writes are unbuffered unless a buffer size is requested so that
+	// output is immediately visible to other readers of the file.
+	file.writer = nil
+	file.lineBuffer = buffering == 1
+	if buffering == 1 {
+		file.writer = bufio.NewWriter(osFile)
+	} else if buffering > 1 {
+		file.reader = bufio.NewReaderSize(osFile, buffering)
+		file.writer = bufio.NewWriterSize(osFile, buffering)
+	}
 	file.univNewLine = strings.HasSuffix(mode, "U")
 	return None, nil
 }
@@ -201,12 +395,17 @@ func fileClose(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	defer file.mutex.Unlock()
 	ret := None
 	if file.open {
+		if file.writer != nil {
+			if err := file.writer.Flush(); err != nil {
+				return nil, RaiseGoError(f, err)
+			}
+		}
 		var raised *BaseException
 		if file.close != nil {
 			ret, raised = file.close.Call(f, args, nil)
 		} else if file.file != nil {
 			if err := file.file.Close(); err != nil {
-				raised = f.RaiseType(IOErrorType, err.Error())
+				raised = RaiseGoError(f, err)
 			}
 		}
 		if raised != nil {
@@ -234,15 +433,35 @@ func fileFileno(f *Frame, args Args, _ KWArgs) (ret *Object, raised *BaseExcepti
 	}
 	file := toFileUnsafe(args[0])
 	file.mutex.Lock()
-	if file.open {
-		ret = NewInt(int(file.file.Fd())).ToObject()
-	} else {
+	if !file.open {
 		raised = f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	} else if file.file == nil {
+		raised = f.RaiseType(IOErrorType, "file has no file descriptor")
+	} else {
+		ret = NewInt(int(file.file.Fd())).ToObject()
 	}
 	file.mutex.Unlock()
 	return ret, raised
 }
 
+func fileFlush(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "flush", args, FileType); raised != nil {
+		return nil, raised
+	}
+	file := toFileUnsafe(args[0])
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if !file.open {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	if file.writer != nil {
+		if err := file.writer.Flush(); err != nil {
+			return nil, RaiseGoError(f, err)
+		}
+	}
+	return None, nil
+}
+
 func fileGetName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "_get_name", args, FileType); raised != nil {
 		return nil, raised
@@ -267,7 +486,7 @@ func fileNext(f *Frame, o *Object) (ret *Object, raised *BaseException) {
 	}
 	line, err := file.readLine(-1)
 	if err != nil {
-		return nil, f.RaiseType(IOErrorType, err.Error())
+		return nil, RaiseGoError(f, err)
 	}
 	if line == "" {
 		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
@@ -285,18 +504,21 @@ func fileRead(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if !file.open {
 		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
 	}
+	if err := file.prepareRead(); err != nil {
+		return nil, RaiseGoError(f, err)
+	}
 	var data []byte
 	var err error
 	if size < 0 {
-		data, err = ioutil.ReadAll(file.file)
+		data, err = ioutil.ReadAll(file.reader)
 	} else {
 		data = make([]byte, size)
 		var n int
-		n, err = file.reader.Read(data)
+		n, err = io.ReadFull(file.reader, data)
 		data = data[:n]
 	}
-	if err != nil && err != io.EOF {
-		return nil, f.RaiseType(IOErrorType, err.Error())
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, RaiseGoError(f, err)
 	}
 	return NewStr(string(data)).ToObject(), nil
 }
@@ -313,7 +535,7 @@ func fileReadLine(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	}
 	line, err := file.readLine(size)
 	if err != nil {
-		return nil, f.RaiseType(IOErrorType, err.Error())
+		return nil, RaiseGoError(f, err)
 	}
 	return NewStr(line).ToObject(), nil
 }
@@ -336,7 +558,7 @@ func fileReadLines(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	for size < 0 || numBytesRead < size {
 		line, err := file.readLine(-1)
 		if err != nil {
-			return nil, f.RaiseType(IOErrorType, err.Error())
+			return nil, RaiseGoError(f, err)
 		}
 		if line != "" {
 			lines = append(lines, NewStr(line).ToObject())
@@ -349,6 +571,98 @@ func fileReadLines(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	return NewList(lines...).ToObject(), nil
 }
 
+func fileSeek(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{FileType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkMethodArgs(f, "seek", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	offset, raised := ToIntValue(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	whence := 0
+	if argc > 2 {
+		if whence, raised = ToIntValue(f, args[2]); raised != nil {
+			return nil, raised
+		}
+	}
+	if whence < 0 || whence > 2 {
+		return nil, f.RaiseType(IOErrorType, "[Errno 22] Invalid argument")
+	}
+	file := toFileUnsafe(args[0])
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if !file.open {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	if _, err := file.seek(int64(offset), whence); err != nil {
+		return nil, RaiseGoError(f, err)
+	}
+	return None, nil
+}
+
+func fileTell(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "tell", args, FileType); raised != nil {
+		return nil, raised
+	}
+	file := toFileUnsafe(args[0])
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if !file.open {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	pos, err := file.tell()
+	if err != nil {
+		return nil, RaiseGoError(f, err)
+	}
+	return NewInt(int(pos)).ToObject(), nil
+}
+
+func fileTruncate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{FileType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "truncate", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	file := toFileUnsafe(args[0])
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if !file.open {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	pos, err := file.tell()
+	if err != nil {
+		return nil, RaiseGoError(f, err)
+	}
+	size := pos
+	if argc > 1 && args[1] != None {
+		i, raised := ToIntValue(f, args[1])
+		if raised != nil {
+			return nil, raised
+		}
+		if i < 0 {
+			return nil, f.RaiseType(IOErrorType, "[Errno 22] Invalid argument")
+		}
+		size = int64(i)
+	}
+	// Flush pending writes and drop read buffers, keeping the current
+	// position, before changing the size of the underlying file.
+	if _, err := file.seek(pos, io.SeekStart); err != nil {
+		return nil, RaiseGoError(f, err)
+	}
+	if err := file.file.Truncate(size); err != nil {
+		return nil, RaiseGoError(f, err)
+	}
+	return None, nil
+}
+
 func fileRepr(f *Frame, o *Object) (*Object, *BaseException) {
 	file := toFileUnsafe(o)
 	file.mutex.Lock()
@@ -378,12 +692,19 @@ func fileWrite(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if !file.open {
 		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
 	}
-	if _, err := file.file.Write([]byte(toStrUnsafe(args[1]).Value())); err != nil {
-		return nil, f.RaiseType(IOErrorType, err.Error())
+	if err := file.write(toStrUnsafe(args[1]).Value()); err != nil {
+		return nil, RaiseGoError(f, err)
 	}
 	return None, nil
 }
 
+func fileXReadLines(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "xreadlines", args, FileType); raised != nil {
+		return nil, raised
+	}
+	return args[0], nil
+}
+
 func initFileType(dict map[string]*Object) {
 	// TODO: Make enter/exit into slots.
 	dict["__enter__"] = newBuiltinFunction("__enter__", fileEnter).ToObject()
@@ -391,11 +712,16 @@ func initFileType(dict map[string]*Object) {
 	dict["close"] = newBuiltinFunction("close", fileClose).ToObject()
 	dict["closed"] = newBuiltinFunction("closed", fileClosed).ToObject()
 	dict["fileno"] = newBuiltinFunction("fileno", fileFileno).ToObject()
+	dict["flush"] = newBuiltinFunction("flush", fileFlush).ToObject()
 	dict["name"] = newProperty(newBuiltinFunction("_get_name", fileGetName).ToObject(), nil, nil).ToObject()
 	dict["read"] = newBuiltinFunction("read", fileRead).ToObject()
 	dict["readline"] = newBuiltinFunction("readline", fileReadLine).ToObject()
 	dict["readlines"] = newBuiltinFunction("readlines", fileReadLines).ToObject()
+	dict["seek"] = newBuiltinFunction("seek", fileSeek).ToObject()
+	dict["tell"] = newBuiltinFunction("tell", fileTell).ToObject()
+	dict["truncate"] = newBuiltinFunction("truncate", fileTruncate).ToObject()
 	dict["write"] = newBuiltinFunction("write", fileWrite).ToObject()
+	dict["xreadlines"] = newBuiltinFunction("xreadlines", fileXReadLines).ToObject()
 	FileType.slots.Init = &initSlot{fileInit}
 	FileType.slots.Iter = &unaryOpSlot{fileIter}
 	FileType.slots.Next = &unaryOpSlot{fileNext}
@@ -413,11 +739,10 @@ func fileParseReadArgs(f *Frame, method string, args Args) (*File, int, *BaseExc
 	}
 	size := -1
 	if argc > 1 {
-		o, raised := IntType.Call(f, args[1:], nil)
-		if raised != nil {
+		var raised *BaseException
+		if size, raised = ToIntValue(f, args[1]); raised != nil {
 			return nil, 0, raised
 		}
-		size = toIntUnsafe(o).Value()
 	}
 	return toFileUnsafe(args[0]), size, nil
 }
@@ -430,3 +755,22 @@ var (
 	// Stderr is an alias for sys.stderr.
 	Stderr = NewFileFromFD(os.Stderr.Fd(), nil)
 )
+
+// captureMutex serializes CaptureStdout calls so that concurrent captures
+// don't receive each other's output.
+var captureMutex sync.Mutex
+
+// CaptureStdout calls fn with Stdout redirected to a buffer and returns the
+// output written to it along with any exception raised by fn. Output written
+// by other threads while fn runs is captured too.
+func CaptureStdout(fn func() *BaseException) (string, *BaseException) {
+	captureMutex.Lock()
+	defer captureMutex.Unlock()
+	var buf bytes.Buffer
+	restore := Stdout.Redirect(nil, &buf)
+	raised := fn()
+	// Restoring acquires Stdout's mutex so all writes to buf are complete
+	// afterwards.
+	restore()
+	return buf.String(), raised
+}
@@ -15,10 +15,14 @@
 package grumpy
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -34,7 +38,7 @@ func TestFileInit(t *testing.T) {
 		{args: wrapArgs(newObject(FileType), f.path), want: None},
 		{args: wrapArgs(newObject(FileType)), wantExc: mustCreateException(TypeErrorType, "'__init__' requires 2 arguments")},
 		{args: wrapArgs(newObject(FileType), f.path, "abc"), wantExc: mustCreateException(ValueErrorType, `invalid mode string: "abc"`)},
-		{args: wrapArgs(newObject(FileType), "nonexistent-file"), wantExc: mustCreateException(IOErrorType, "open nonexistent-file: no such file or directory")},
+		{args: wrapArgs(newObject(FileType), "nonexistent-file"), wantExc: mustCreateException(GoErrorType, "open nonexistent-file: no such file or directory")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeMethodTestCase(FileType, "__init__", &cas); err != "" {
@@ -73,7 +77,7 @@ func TestFileCloseExit(t *testing.T) {
 		cases := []invokeTestCase{
 			{args: wrapArgs(newObject(FileType)), want: None},
 			{args: wrapArgs(f.open("r")), want: None},
-			{args: wrapArgs(closedFile), wantExc: mustCreateException(IOErrorType, closedFile.file.Close().Error())},
+			{args: wrapArgs(closedFile), wantExc: mustCreateException(GoErrorType, closedFile.file.Close().Error())},
 		}
 		for _, cas := range cases {
 			if err := runInvokeMethodTestCase(FileType, method, &cas); err != "" {
@@ -117,7 +121,7 @@ func TestFileIter(t *testing.T) {
 		{args: wrapArgs(files[3].open("rU")), want: newTestList("foo\n").ToObject()},
 		{args: wrapArgs(files[4].open("r")), want: newTestList("foo\rbar").ToObject()},
 		{args: wrapArgs(files[4].open("rU")), want: newTestList("foo\n", "bar").ToObject()},
-		{args: wrapArgs(closedFile), wantExc: mustCreateException(IOErrorType, closedFileReadError.Error())},
+		{args: wrapArgs(closedFile), wantExc: mustCreateException(GoErrorType, closedFileReadError.Error())},
 		{args: wrapArgs(newObject(FileType)), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
 	}
 	for _, cas := range cases {
@@ -145,7 +149,7 @@ func TestFileNext(t *testing.T) {
 		{args: wrapArgs(files[4].open("r")), want: NewStr("foo\rbar").ToObject()},
 		{args: wrapArgs(files[4].open("rU")), want: NewStr("foo\n").ToObject()},
 		{args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "unbound method next() must be called with file instance as first argument (got nothing instead)")},
-		{args: wrapArgs(closedFile), wantExc: mustCreateException(IOErrorType, closedFileReadError.Error())},
+		{args: wrapArgs(closedFile), wantExc: mustCreateException(GoErrorType, closedFileReadError.Error())},
 		{args: wrapArgs(newObject(FileType)), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
 	}
 	for _, cas := range cases {
@@ -164,11 +168,12 @@ func TestFileRead(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(f.open("r")), want: NewStr("foo\nbar").ToObject()},
 		{args: wrapArgs(f.open("r"), 3), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(f.open("r"), big.NewInt(3)), want: NewStr("foo").ToObject()},
 		{args: wrapArgs(f.open("r"), 1000), want: NewStr("foo\nbar").ToObject()},
 		{args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "unbound method read() must be called with file instance as first argument (got nothing instead)")},
-		{args: wrapArgs(closedFile), wantExc: mustCreateException(IOErrorType, closedFileReadError.Error())},
+		{args: wrapArgs(closedFile), wantExc: mustCreateException(GoErrorType, closedFileReadError.Error())},
 		{args: wrapArgs(newObject(FileType)), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
-		{args: wrapArgs(newObject(FileType), "abc"), wantExc: mustCreateException(ValueErrorType, "invalid literal for int() with base 10: abc")},
+		{args: wrapArgs(newObject(FileType), "abc"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{args: wrapArgs(newObject(FileType), 123, 456), wantExc: mustCreateException(TypeErrorType, "'read' of 'file' requires 2 arguments")},
 	}
 	for _, cas := range cases {
@@ -209,9 +214,9 @@ func TestFileReadLine(t *testing.T) {
 		// does not count toward the bytes read.
 		{args: wrapArgs(partialReadFile, 3), want: NewStr("bar").ToObject()},
 		{args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "unbound method readline() must be called with file instance as first argument (got nothing instead)")},
-		{args: wrapArgs(closedFile), wantExc: mustCreateException(IOErrorType, closedFileReadError.Error())},
+		{args: wrapArgs(closedFile), wantExc: mustCreateException(GoErrorType, closedFileReadError.Error())},
 		{args: wrapArgs(newObject(FileType)), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
-		{args: wrapArgs(newObject(FileType), "abc"), wantExc: mustCreateException(ValueErrorType, "invalid literal for int() with base 10: abc")},
+		{args: wrapArgs(newObject(FileType), "abc"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{args: wrapArgs(newObject(FileType), 123, 456), wantExc: mustCreateException(TypeErrorType, "'readline' of 'file' requires 2 arguments")},
 	}
 	for _, cas := range cases {
@@ -252,9 +257,9 @@ func TestFileReadLines(t *testing.T) {
 		// does not count toward the bytes read.
 		{args: wrapArgs(partialReadFile, 3), want: newTestList("bar\n").ToObject()},
 		{args: wrapArgs(), wantExc: mustCreateException(TypeErrorType, "unbound method readlines() must be called with file instance as first argument (got nothing instead)")},
-		{args: wrapArgs(closedFile), wantExc: mustCreateException(IOErrorType, closedFileReadError.Error())},
+		{args: wrapArgs(closedFile), wantExc: mustCreateException(GoErrorType, closedFileReadError.Error())},
 		{args: wrapArgs(newObject(FileType)), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
-		{args: wrapArgs(newObject(FileType), "abc"), wantExc: mustCreateException(ValueErrorType, "invalid literal for int() with base 10: abc")},
+		{args: wrapArgs(newObject(FileType), "abc"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{args: wrapArgs(newObject(FileType), 123, 456), wantExc: mustCreateException(TypeErrorType, "'readlines' of 'file' requires 2 arguments")},
 	}
 	for _, cas := range cases {
@@ -349,7 +354,7 @@ func TestFileWrite(t *testing.T) {
 		{args: wrapArgs("append.txt", "a", "\nbar"), want: NewStr("append.txt\nbar").ToObject()},
 
 		{args: wrapArgs("rplus.txt", "r+", "fooey"), want: NewStr("fooey.txt").ToObject()},
-		{args: wrapArgs("noexistplus1.txt", "r+", "pooey"), wantExc: mustCreateException(IOErrorType, "open noexistplus1.txt: no such file or directory")},
+		{args: wrapArgs("noexistplus1.txt", "r+", "pooey"), wantExc: mustCreateException(GoErrorType, "open noexistplus1.txt: no such file or directory")},
 
 		{args: wrapArgs("aplus.txt", "a+", "\napper"), want: NewStr("aplus.txt\napper").ToObject()},
 		{args: wrapArgs("noexistplus3.txt", "a+", "snappbacktoreality"), want: NewStr("snappbacktoreality").ToObject()},
@@ -357,7 +362,7 @@ func TestFileWrite(t *testing.T) {
 		{args: wrapArgs("wplus.txt", "w+", "destructo"), want: NewStr("destructo").ToObject()},
 		{args: wrapArgs("noexistplus2.txt", "w+", "wapper"), want: NewStr("wapper").ToObject()},
 
-		{args: wrapArgs("readonly.txt", "r", "foo"), wantExc: mustCreateException(IOErrorType, "write readonly.txt: bad file descriptor")},
+		{args: wrapArgs("readonly.txt", "r", "foo"), wantExc: mustCreateException(GoErrorType, "write readonly.txt: bad file descriptor")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -366,6 +371,246 @@ func TestFileWrite(t *testing.T) {
 	}
 }
 
+func TestFileSeekTell(t *testing.T) {
+	f := newTestFile("foo\nbar\nbaz")
+	defer f.cleanup()
+	fun := wrapFuncForTest(func(f *Frame, file *File, readSize int, args ...*Object) (*Tuple, *BaseException) {
+		if _, raised := callNativeMethod(f, file.ToObject(), "read", NewInt(readSize).ToObject()); raised != nil {
+			return nil, raised
+		}
+		if _, raised := callNativeMethod(f, file.ToObject(), "seek", args...); raised != nil {
+			return nil, raised
+		}
+		pos, raised := callNativeMethod(f, file.ToObject(), "tell")
+		if raised != nil {
+			return nil, raised
+		}
+		line, raised := callNativeMethod(f, file.ToObject(), "readline")
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(pos, line), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(f.open("r"), 0, 4), want: newTestTuple(4, "bar\n").ToObject()},
+		{args: wrapArgs(f.open("r"), 2, 2, 1), want: newTestTuple(4, "bar\n").ToObject()},
+		{args: wrapArgs(f.open("r"), 0, -3, 2), want: newTestTuple(8, "baz").ToObject()},
+		{args: wrapArgs(f.open("r"), 5, 0, 0), want: newTestTuple(0, "foo\n").ToObject()},
+		{args: wrapArgs(f.open("r"), 0, 100), want: newTestTuple(100, "").ToObject()},
+		{args: wrapArgs(f.open("r"), 0, 0, 3), wantExc: mustCreateException(IOErrorType, "[Errno 22] Invalid argument")},
+		{args: wrapArgs(f.open("r"), 0, "foo"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFileTruncate(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, contents string, readSize int, args ...*Object) (*Tuple, *BaseException) {
+		tf := newTestFile(contents)
+		defer tf.cleanup()
+		file := tf.open("r+")
+		if _, raised := callNativeMethod(f, file.ToObject(), "read", NewInt(readSize).ToObject()); raised != nil {
+			return nil, raised
+		}
+		if _, raised := callNativeMethod(f, file.ToObject(), "truncate", args...); raised != nil {
+			return nil, raised
+		}
+		pos, raised := callNativeMethod(f, file.ToObject(), "tell")
+		if raised != nil {
+			return nil, raised
+		}
+		data, err := ioutil.ReadFile(tf.path)
+		if err != nil {
+			return nil, f.RaiseType(RuntimeErrorType, err.Error())
+		}
+		return NewTuple2(NewStr(string(data)).ToObject(), pos), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("foobar", 3), want: newTestTuple("foo", 3).ToObject()},
+		{args: wrapArgs("foobar", 3, None), want: newTestTuple("foo", 3).ToObject()},
+		{args: wrapArgs("foobar", 0, 2), want: newTestTuple("fo", 0).ToObject()},
+		{args: wrapArgs("foobar", 1, 10), want: newTestTuple("foobar\x00\x00\x00\x00", 1).ToObject()},
+		{args: wrapArgs("foobar", 0, -1), wantExc: mustCreateException(IOErrorType, "[Errno 22] Invalid argument")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFileBuffering(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, buffering int, data string) (*Tuple, *BaseException) {
+		tf := newTestFile("")
+		defer tf.cleanup()
+		file, raised := FileType.Call(f, wrapArgs(tf.path, "w", buffering), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if _, raised := callNativeMethod(f, file, "write", NewStr(data).ToObject()); raised != nil {
+			return nil, raised
+		}
+		before, err := ioutil.ReadFile(tf.path)
+		if err != nil {
+			return nil, f.RaiseType(RuntimeErrorType, err.Error())
+		}
+		if _, raised := callNativeMethod(f, file, "flush"); raised != nil {
+			return nil, raised
+		}
+		after, err := ioutil.ReadFile(tf.path)
+		if err != nil {
+			return nil, f.RaiseType(RuntimeErrorType, err.Error())
+		}
+		if _, raised := callNativeMethod(f, file, "close"); raised != nil {
+			return nil, raised
+		}
+		return newTestTuple(string(before), string(after)), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(-1, "foo"), want: newTestTuple("foo", "foo").ToObject()},
+		{args: wrapArgs(0, "foo"), want: newTestTuple("foo", "foo").ToObject()},
+		{args: wrapArgs(1, "foo"), want: newTestTuple("", "foo").ToObject()},
+		{args: wrapArgs(1, "foo\nbar"), want: newTestTuple("foo\nbar", "foo\nbar").ToObject()},
+		{args: wrapArgs(1024, "foo\nbar"), want: newTestTuple("", "foo\nbar").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFileReadAfterBufferedWrite(t *testing.T) {
+	tf := newTestFile("foobar")
+	defer tf.cleanup()
+	f := NewRootFrame()
+	file := mustNotRaise(FileType.Call(f, wrapArgs(tf.path, "r+", 1024), nil))
+	mustNotRaise(callNativeMethod(f, file, "read", NewInt(1).ToObject()))
+	mustNotRaise(callNativeMethod(f, file, "write", NewStr("OO").ToObject()))
+	if got := mustNotRaise(callNativeMethod(f, file, "read")); !got.isInstance(StrType) || toStrUnsafe(got).Value() != "bar" {
+		t.Errorf("read() after write = %v, want 'bar'", got)
+	}
+	mustNotRaise(callNativeMethod(f, file, "seek", NewInt(0).ToObject()))
+	if got := mustNotRaise(callNativeMethod(f, file, "read")); !got.isInstance(StrType) || toStrUnsafe(got).Value() != "fOObar" {
+		t.Errorf("read() after seek = %v, want 'fOObar'", got)
+	}
+	mustNotRaise(callNativeMethod(f, file, "close"))
+}
+
+func TestFileXReadLines(t *testing.T) {
+	f := newTestFile("foo\nbar")
+	defer f.cleanup()
+	fun := wrapFuncForTest(func(f *Frame, file *File) (*Object, *BaseException) {
+		iter, raised := callNativeMethod(f, file.ToObject(), "xreadlines")
+		if raised != nil {
+			return nil, raised
+		}
+		return ListType.Call(f, Args{iter}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(f.open("r")), want: newTestList("foo\n", "bar").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFileFromStreams(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, in string, method string, args ...*Object) (*Object, *BaseException) {
+		var out bytes.Buffer
+		var r io.Reader
+		if in != "" {
+			r = strings.NewReader(in)
+		}
+		file := NewFileFromStreams("<test>", r, &out)
+		result, raised := callNativeMethod(f, file.ToObject(), method, args...)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(result, NewStr(out.String()).ToObject()).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("foo\nbar", "readline"), want: newTestTuple("foo\n", "").ToObject()},
+		{args: wrapArgs("foo\nbar", "readlines"), want: newTestTuple(newTestList("foo\n", "bar"), "").ToObject()},
+		{args: wrapArgs("", "write", "foo"), want: newTestTuple(None, "foo").ToObject()},
+		{args: wrapArgs("", "read"), wantExc: mustCreateException(GoErrorType, "File not open for reading")},
+		{args: wrapArgs("", "seek", 0), wantExc: mustCreateException(GoErrorType, "[Errno 29] Illegal seek")},
+		{args: wrapArgs("", "fileno"), wantExc: mustCreateException(IOErrorType, "file has no file descriptor")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFileFromStreamsReadOnly(t *testing.T) {
+	f := NewRootFrame()
+	file := NewFileFromStreams("<test>", strings.NewReader("foo"), nil)
+	if got, want := file.mode, "r"; got != want {
+		t.Errorf("mode = %q, want %q", got, want)
+	}
+	if got := mustNotRaise(callNativeMethod(f, file.ToObject(), "read")); !got.isInstance(StrType) || toStrUnsafe(got).Value() != "foo" {
+		t.Errorf("read() = %v, want 'foo'", got)
+	}
+	_, raised := callNativeMethod(f, file.ToObject(), "write", NewStr("bar").ToObject())
+	if raised == nil || raised.Type() != GoErrorType {
+		t.Errorf("write() raised %v, want GoError", raised)
+	}
+}
+
+func TestFileRedirect(t *testing.T) {
+	f := NewRootFrame()
+	var before, during bytes.Buffer
+	file := NewFileFromStreams("<test>", nil, &before)
+	write := func(s string) {
+		mustNotRaise(callNativeMethod(f, file.ToObject(), "write", NewStr(s).ToObject()))
+	}
+	write("foo")
+	restore := file.Redirect(strings.NewReader("baz\n"), &during)
+	write("bar")
+	if got := mustNotRaise(callNativeMethod(f, file.ToObject(), "readline")); toStrUnsafe(got).Value() != "baz\n" {
+		t.Errorf("readline() = %v, want 'baz\\n'", got)
+	}
+	restore()
+	write("qux")
+	if got, want := before.String(), "fooqux"; got != want {
+		t.Errorf("output before redirect = %q, want %q", got, want)
+	}
+	if got, want := during.String(), "bar"; got != want {
+		t.Errorf("output during redirect = %q, want %q", got, want)
+	}
+	if got, want := file.mode, "w"; got != want {
+		t.Errorf("mode after restore = %q, want %q", got, want)
+	}
+}
+
+func TestCaptureStdout(t *testing.T) {
+	f := NewRootFrame()
+	print := mustNotRaise(Builtins.GetItemString(f, "print"))
+	output, raised := CaptureStdout(func() *BaseException {
+		_, raised := print.Call(f, wrapArgs("foo", 123), nil)
+		return raised
+	})
+	if raised != nil || output != "foo 123\n" {
+		t.Errorf("CaptureStdout(print) = (%q, %v), want ('foo 123\\n', nil)", output, raised)
+	}
+	output, raised = CaptureStdout(func() *BaseException {
+		if raised := pyPrint(f, wrapArgs("bar"), "", "", Stdout.ToObject()); raised != nil {
+			return raised
+		}
+		return f.RaiseType(ValueErrorType, "baz")
+	})
+	if output != "bar" || raised == nil || raised.Type() != ValueErrorType {
+		t.Errorf("CaptureStdout(raise) = (%q, %v), want ('bar', ValueError)", output, raised)
+	}
+}
+
 type testFile struct {
 	path  string
 	files []*File
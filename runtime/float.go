@@ -22,7 +22,6 @@ import (
 	"strconv"
 	"strings"
 	"sync/atomic"
-	"unicode"
 	"unsafe"
 )
 
@@ -75,11 +74,7 @@ func floatDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
 
 func floatDivMod(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return floatDivAndModOp(f, "__divmod__", v, w, func(v, w float64) (float64, float64, bool) {
-		m, r := floatModFunc(v, w)
-		if !r {
-			return 0, 0, false
-		}
-		return math.Floor(v / w), m, true
+		return floatDivModFunc(v, w)
 	})
 }
 
@@ -93,10 +88,8 @@ func floatFloat(f *Frame, o *Object) (*Object, *BaseException) {
 
 func floatFloorDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return floatDivModOp(f, "__floordiv__", v, w, func(v, w float64) (float64, bool) {
-		if w == 0.0 {
-			return 0, false
-		}
-		return math.Floor(v / w), true
+		q, _, ok := floatDivModFunc(v, w)
+		return q, ok
 	})
 }
 
@@ -104,11 +97,68 @@ func floatGE(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return floatCompare(toFloatUnsafe(v), w, False, True, True), nil
 }
 
+// floatFormat implements float.__format__, rendering the float according to
+// a PEP 3101 format specification.
+func floatFormat(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__format__", args, FloatType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if !args[1].isInstance(BaseStringType) {
+		return nil, f.RaiseType(TypeErrorType, "__format__ requires str or unicode")
+	}
+	format, raised := basestringToStr(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	spec, raised := parseFormatSpec(f, format.Value())
+	if raised != nil {
+		return nil, raised
+	}
+	if spec.alt {
+		return nil, f.RaiseType(ValueErrorType, "Alternate form (#) not allowed in float format specifier")
+	}
+	v := toFloatUnsafe(args[0]).Value()
+	var body string
+	switch spec.typ {
+	case 0:
+		// Like 'g' but always with a digit after the decimal point in
+		// fixed notation, the same as str when no precision is given.
+		precision := spec.precision
+		if precision < 0 {
+			precision = floatStrPrecision
+		}
+		body = floatToString(v, precision)
+	case 'e', 'E', 'f', 'F', 'g', 'G', 'n', '%':
+		precision := spec.precision
+		if precision < 0 {
+			precision = 6
+		}
+		conv, suffix := spec.typ, ""
+		if conv == 'n' {
+			conv = 'g'
+		} else if conv == '%' {
+			conv, suffix = 'f', "%"
+			v *= 100
+		}
+		body = strFormatFloat(v, conv, precision, false) + suffix
+	default:
+		format := "Unknown format code '%c' for object of type 'float'"
+		return nil, f.RaiseType(ValueErrorType, fmt.Sprintf(format, spec.typ))
+	}
+	sign := ""
+	if strings.HasPrefix(body, "-") {
+		sign, body = "-", body[1:]
+	} else if spec.sign == '+' || spec.sign == ' ' {
+		sign = string(spec.sign)
+	}
+	return NewStr(spec.pad(sign, body)).ToObject(), nil
+}
+
 func floatGetNewArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "__getnewargs__", args, FloatType); raised != nil {
 		return nil, raised
 	}
-	return NewTuple1(args[0]).ToObject(), nil
+	return NewTuple1(NewFloat(toFloatUnsafe(args[0]).Value()).ToObject()).ToObject(), nil
 }
 
 func floatGT(f *Frame, v, w *Object) (*Object, *BaseException) {
@@ -215,10 +265,14 @@ func floatNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException)
 		}
 		return fl.ToObject(), nil
 	}
-	if !o.isInstance(StrType) {
+	if !o.isInstance(BaseStringType) {
 		return nil, f.RaiseType(TypeErrorType, "float() argument must be a string or a number")
 	}
-	s := toStrUnsafe(o).Value()
+	str, raised := basestringToStr(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	s := str.Value()
 	result, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		return nil, f.RaiseType(ValueErrorType, fmt.Sprintf("could not convert string to float: %s", s))
@@ -253,16 +307,17 @@ func floatRDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
 
 func floatRDivMod(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return floatDivAndModOp(f, "__rdivmod__", v, w, func(v, w float64) (float64, float64, bool) {
-		m, r := floatModFunc(w, v)
-		if !r {
-			return 0, 0, false
-		}
-		return w / v, m, true
+		return floatDivModFunc(w, v)
 	})
 }
 
 const (
-	floatReprPrecision = 16
+	// floatReprExpLimit is the decimal exponent at which repr switches
+	// from fixed to exponent notation.
+	floatReprExpLimit = 16
+	// floatReprPrecision selects the shortest representation that round
+	// trips, per strconv's convention.
+	floatReprPrecision = -1
 	floatStrPrecision  = 12
 )
 
@@ -272,10 +327,8 @@ func floatRepr(f *Frame, o *Object) (*Object, *BaseException) {
 
 func floatRFloorDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return floatDivModOp(f, "__rfloordiv__", v, w, func(v, w float64) (float64, bool) {
-		if v == 0.0 {
-			return 0, false
-		}
-		return math.Floor(w / v), true
+		q, _, ok := floatDivModFunc(w, v)
+		return q, ok
 	})
 }
 
@@ -306,6 +359,7 @@ func floatSub(f *Frame, v, w *Object) (*Object, *BaseException) {
 }
 
 func initFloatType(dict map[string]*Object) {
+	dict["__format__"] = newBuiltinFunction("__format__", floatFormat).ToObject()
 	dict["__getnewargs__"] = newBuiltinFunction("__getnewargs__", floatGetNewArgs).ToObject()
 	FloatType.slots.Abs = &unaryOpSlot{floatAbs}
 	FloatType.slots.Add = &binaryOpSlot{floatAdd}
@@ -432,7 +486,7 @@ func floatDivModOp(f *Frame, method string, v, w *Object, fun func(v, w float64)
 	}
 	x, ok := fun(toFloatUnsafe(v).Value(), floatW)
 	if !ok {
-		return nil, f.RaiseType(ZeroDivisionErrorType, "float division or modulo by zero")
+		return nil, f.RaiseType(ZeroDivisionErrorType, floatZeroDivisionMsg(method))
 	}
 	return NewFloat(x).ToObject(), nil
 }
@@ -447,11 +501,23 @@ func floatDivAndModOp(f *Frame, method string, v, w *Object, fun func(v, w float
 	}
 	q, m, ok := fun(toFloatUnsafe(v).Value(), floatW)
 	if !ok {
-		return nil, f.RaiseType(ZeroDivisionErrorType, "float division or modulo by zero")
+		return nil, f.RaiseType(ZeroDivisionErrorType, floatZeroDivisionMsg(method))
 	}
 	return NewTuple2(NewFloat(q).ToObject(), NewFloat(m).ToObject()).ToObject(), nil
 }
 
+// floatZeroDivisionMsg returns the message CPython uses when the float
+// operation implemented by method is given a zero divisor.
+func floatZeroDivisionMsg(method string) string {
+	switch method {
+	case "__divmod__", "__rdivmod__", "__floordiv__", "__rfloordiv__":
+		return "float divmod()"
+	case "__mod__", "__rmod__":
+		return "float modulo"
+	}
+	return "float division or modulo by zero"
+}
+
 func hashFloat(v float64) int {
 	if math.IsNaN(v) {
 		return 0
@@ -486,12 +552,43 @@ func hashFloat(v float64) int {
 	return x
 }
 
+// floatDivModFunc returns the floored quotient and the modulus of v and w. The
+// quotient is derived from the modulus rather than from v / w so that, as in
+// CPython, v == q*w + m holds as closely as floating point allows.
+func floatDivModFunc(v, w float64) (float64, float64, bool) {
+	if w == 0.0 {
+		return 0, 0, false
+	}
+	m := math.Mod(v, w)
+	div := (v - m) / w
+	if m != 0 {
+		if math.Signbit(m) != math.Signbit(w) {
+			m += w
+			div--
+		}
+	} else {
+		m = math.Copysign(0, w)
+	}
+	if div == 0 {
+		return math.Copysign(0, v/w), m, true
+	}
+	q := math.Floor(div)
+	if div-q > 0.5 {
+		q++
+	}
+	return q, m, true
+}
+
 func floatModFunc(v, w float64) (float64, bool) {
 	if w == 0.0 {
 		return 0, false
 	}
 	x := math.Mod(v, w)
-	if x != 0 && math.Signbit(x) != math.Signbit(w) {
+	if x == 0 {
+		// As in CPython, a zero result has the sign of the divisor.
+		return math.Copysign(0, w), true
+	}
+	if math.Signbit(x) != math.Signbit(w) {
 		// In Python the result of the modulo operator is
 		// always the same sign as the divisor, whereas in Go,
 		// the result is always the same sign as the dividend.
@@ -503,17 +600,51 @@ func floatModFunc(v, w float64) (float64, bool) {
 	return x, true
 }
 
+// floatToString returns f formatted with p significant digits or, when p is
+// negative, with the fewest digits that round trip. Like CPython's float str
+// and repr, exponent notation is used for very large and very small
+// magnitudes, and integral values get a trailing ".0".
 func floatToString(f float64, p int) string {
-	s := unsignPositiveInf(strings.ToLower(strconv.FormatFloat(f, 'g', p, 64)))
-	fun := func(r rune) bool {
-		return !unicode.IsDigit(r)
-	}
-	if i := strings.IndexFunc(s, fun); i == -1 {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return strFormatFloat(f, 'g', 0, false)
+	}
+	if p == 0 {
+		p = 1
+	}
+	// Fixed notation switches to exponent notation one digit earlier than
+	// %g would, leaving room for the ".0" suffix.
+	expLimit := floatReprExpLimit
+	digits := -1
+	if p > 0 {
+		expLimit = p - 1
+		digits = p - 1
+	}
+	s := strconv.FormatFloat(f, 'e', digits, 64)
+	e := strings.IndexByte(s, 'e')
+	exp, _ := strconv.Atoi(s[e+1:])
+	if exp < -4 || exp >= expLimit {
+		return floatTrimZeros(s[:e]) + s[e:]
+	}
+	if p < 0 {
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	} else {
+		s = floatTrimZeros(strconv.FormatFloat(f, 'f', p-1-exp, 64))
+	}
+	if strings.IndexByte(s, '.') == -1 {
 		s += ".0"
 	}
 	return s
 }
 
+// floatTrimZeros strips trailing zeros from the fractional part of the
+// decimal number s, along with the decimal point if nothing follows it.
+func floatTrimZeros(s string) string {
+	if strings.IndexByte(s, '.') == -1 {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
 func unsignPositiveInf(s string) string {
 	if s == "+inf" {
 		return "inf"
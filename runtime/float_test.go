@@ -64,12 +64,12 @@ func TestFloatArithmeticOps(t *testing.T) {
 		{FloorDiv, NewFloat(-12.5).ToObject(), NewInt(4).ToObject(), NewFloat(-4).ToObject(), nil},
 		{FloorDiv, NewInt(25).ToObject(), NewFloat(5).ToObject(), NewFloat(5.0).ToObject(), nil},
 		{FloorDiv, NewFloat(math.Inf(1)).ToObject(), NewFloat(math.Inf(1)).ToObject(), NewFloat(math.NaN()).ToObject(), nil},
-		{FloorDiv, NewFloat(math.Inf(-1)).ToObject(), NewInt(-20).ToObject(), NewFloat(math.Inf(1)).ToObject(), nil},
+		{FloorDiv, NewFloat(math.Inf(-1)).ToObject(), NewInt(-20).ToObject(), NewFloat(math.NaN()).ToObject(), nil},
 		{FloorDiv, NewInt(1).ToObject(), NewFloat(math.Inf(1)).ToObject(), NewFloat(0).ToObject(), nil},
 		{FloorDiv, newObject(ObjectType), NewFloat(1.1).ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for //: 'object' and 'float'")},
 		{FloorDiv, NewFloat(1.0).ToObject(), NewLong(bigLongNumber).ToObject(), nil, mustCreateException(OverflowErrorType, "long int too large to convert to float")},
-		{FloorDiv, True.ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
-		{FloorDiv, NewFloat(math.Inf(1)).ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
+		{FloorDiv, True.ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float divmod()")},
+		{FloorDiv, NewFloat(math.Inf(1)).ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float divmod()")},
 		{Mod, NewFloat(50.5).ToObject(), NewInt(10).ToObject(), NewFloat(0.5).ToObject(), nil},
 		{Mod, NewFloat(50.5).ToObject(), NewFloat(-10).ToObject(), NewFloat(-9.5).ToObject(), nil},
 		{Mod, NewFloat(-20.2).ToObject(), NewFloat(40).ToObject(), NewFloat(19.8).ToObject(), nil},
@@ -80,9 +80,9 @@ func TestFloatArithmeticOps(t *testing.T) {
 		{Mod, NewFloat(4.5).ToObject(), NewFloat(math.Inf(-1)).ToObject(), NewFloat(math.Inf(-1)).ToObject(), nil},
 		{Mod, NewFloat(math.Inf(1)).ToObject(), NewFloat(math.Inf(-1)).ToObject(), NewFloat(math.NaN()).ToObject(), nil},
 		{Mod, None, NewFloat(42).ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for %: 'NoneType' and 'float'")},
-		{Mod, NewFloat(-32.25).ToObject(), NewInt(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
-		{Mod, NewFloat(math.Inf(-1)).ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
-		{Mod, NewInt(2).ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
+		{Mod, NewFloat(-32.25).ToObject(), NewInt(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float modulo")},
+		{Mod, NewFloat(math.Inf(-1)).ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float modulo")},
+		{Mod, NewInt(2).ToObject(), NewFloat(0).ToObject(), nil, mustCreateException(ZeroDivisionErrorType, "float modulo")},
 		{Mul, NewFloat(1.2).ToObject(), True.ToObject(), NewFloat(1.2).ToObject(), nil},
 		{Mul, NewInt(-4).ToObject(), NewFloat(1.2).ToObject(), NewFloat(-4.8).ToObject(), nil},
 		{Mul, NewFloat(math.Inf(1)).ToObject(), NewInt(-5).ToObject(), NewFloat(math.Inf(-1)).ToObject(), nil},
@@ -119,11 +119,14 @@ func TestFloatDivMod(t *testing.T) {
 		{args: wrapArgs(-20.2, 40.0), want: NewTuple2(NewFloat(-1).ToObject(), NewFloat(19.8).ToObject()).ToObject()},
 		{args: wrapArgs(math.Inf(1), math.Inf(1)), want: NewTuple2(NewFloat(math.NaN()).ToObject(), NewFloat(math.NaN()).ToObject()).ToObject()},
 		{args: wrapArgs(math.Inf(1), math.Inf(-1)), want: NewTuple2(NewFloat(math.NaN()).ToObject(), NewFloat(math.NaN()).ToObject()).ToObject()},
-		{args: wrapArgs(math.Inf(-1), -20.0), want: NewTuple2(NewFloat(math.Inf(1)).ToObject(), NewFloat(math.NaN()).ToObject()).ToObject()},
+		{args: wrapArgs(math.Inf(-1), -20.0), want: NewTuple2(NewFloat(math.NaN()).ToObject(), NewFloat(math.NaN()).ToObject()).ToObject()},
 		{args: wrapArgs(1, math.Inf(1)), want: NewTuple2(NewFloat(0).ToObject(), NewFloat(1).ToObject()).ToObject()},
+		{args: wrapArgs(big.NewInt(7), 2.5), want: NewTuple2(NewFloat(2).ToObject(), NewFloat(2).ToObject()).ToObject()},
+		{args: wrapArgs(7, -2.5), want: NewTuple2(NewFloat(-3).ToObject(), NewFloat(-0.5).ToObject()).ToObject()},
+		{args: wrapArgs(1e30, 7.0), want: NewTuple2(NewFloat(1.4285714285714285e+29).ToObject(), NewFloat(5).ToObject()).ToObject()},
 		{args: wrapArgs(newObject(ObjectType), 1.1), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for divmod(): 'object' and 'float'")},
-		{args: wrapArgs(True.ToObject(), 0.0), wantExc: mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
-		{args: wrapArgs(math.Inf(1), 0.0), wantExc: mustCreateException(ZeroDivisionErrorType, "float division or modulo by zero")},
+		{args: wrapArgs(True.ToObject(), 0.0), wantExc: mustCreateException(ZeroDivisionErrorType, "float divmod()")},
+		{args: wrapArgs(math.Inf(1), 0.0), wantExc: mustCreateException(ZeroDivisionErrorType, "float divmod()")},
 		{args: wrapArgs(1.0, bigLongNumber), wantExc: mustCreateException(OverflowErrorType, "long int too large to convert to float")},
 	}
 	for _, cas := range cases {
@@ -203,6 +206,51 @@ func TestFloatLong(t *testing.T) {
 	}
 }
 
+func TestFloatFormat(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(1e11, ""), want: NewStr("1e+11").ToObject()},
+		{args: wrapArgs(0.30000000000000004, ""), want: NewStr("0.3").ToObject()},
+		{args: wrapArgs(100.0, ".3"), want: NewStr("1e+02").ToObject()},
+		{args: wrapArgs(10.0, ".3"), want: NewStr("10.0").ToObject()},
+		{args: wrapArgs(1234.5, "10"), want: NewStr("    1234.5").ToObject()},
+		{args: wrapArgs(1234.5, "f"), want: NewStr("1234.500000").ToObject()},
+		{args: wrapArgs(2.5, ".0f"), want: NewStr("2").ToObject()},
+		{args: wrapArgs(math.Inf(1), "F"), want: NewStr("INF").ToObject()},
+		{args: wrapArgs(-1234.5, ".3e"), want: NewStr("-1.234e+03").ToObject()},
+		{args: wrapArgs(1234.5, "E"), want: NewStr("1.234500E+03").ToObject()},
+		{args: wrapArgs(100.0, "g"), want: NewStr("100").ToObject()},
+		{args: wrapArgs(1e-7, "G"), want: NewStr("1E-07").ToObject()},
+		{args: wrapArgs(-1234567.891, "n"), want: NewStr("-1.23457e+06").ToObject()},
+		{args: wrapArgs(0.125, "%"), want: NewStr("12.500000%").ToObject()},
+		{args: wrapArgs(12.345, ",.1%"), want: NewStr("1,234.5%").ToObject()},
+		{args: wrapArgs(1.0, "+"), want: NewStr("+1.0").ToObject()},
+		{args: wrapArgs(1.0, " .2f"), want: NewStr(" 1.00").ToObject()},
+		{args: wrapArgs(math.Copysign(0, -1), "+.1f"), want: NewStr("-0.0").ToObject()},
+		{args: wrapArgs(1.0, "<6.1f"), want: NewStr("1.0   ").ToObject()},
+		{args: wrapArgs(1.0, "*^8.1f"), want: NewStr("**1.0***").ToObject()},
+		{args: wrapArgs(-1.0, "=+8.1f"), want: NewStr("-    1.0").ToObject()},
+		{args: wrapArgs(-1.0, "08.1f"), want: NewStr("-00001.0").ToObject()},
+		{args: wrapArgs(1.0, "0<8.1f"), want: NewStr("1.000000").ToObject()},
+		{args: wrapArgs(1234.5, "010,.1f"), want: NewStr("0,001,234.5").ToObject()},
+		{args: wrapArgs(1234.5, "09,.1f"), want: NewStr("001,234.5").ToObject()},
+		{args: wrapArgs(math.Inf(1), "010,.1f"), want: NewStr("0000000inf").ToObject()},
+		{args: wrapArgs(1e16, ",.2f"), want: NewStr("10,000,000,000,000,000.00").ToObject()},
+		{args: wrapArgs(1.5, NewUnicode(".2f")), want: NewStr("1.50").ToObject()},
+		{args: wrapArgs(1.0, 3), wantExc: mustCreateException(TypeErrorType, "__format__ requires str or unicode")},
+		{args: wrapArgs(1.0, "#g"), wantExc: mustCreateException(ValueErrorType, "Alternate form (#) not allowed in float format specifier")},
+		{args: wrapArgs(1.0, "=10c"), wantExc: mustCreateException(ValueErrorType, "Unknown format code 'c' for object of type 'float'")},
+		{args: wrapArgs(1.0, ".f"), wantExc: mustCreateException(ValueErrorType, "Format specifier missing precision")},
+		{args: wrapArgs(1.0, "10.2.3f"), wantExc: mustCreateException(ValueErrorType, "Invalid conversion specification")},
+		{args: wrapArgs(1.0, ",n"), wantExc: mustCreateException(ValueErrorType, "Cannot specify ',' with 'n'.")},
+		{args: wrapArgs(1.0, "99999999999999999999"), wantExc: mustCreateException(ValueErrorType, "Too many decimal digits in format string")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(FloatType, "__format__", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestFloatHash(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewFloat(0.0)), want: NewInt(0).ToObject()},
@@ -263,6 +311,7 @@ func TestFloatNew(t *testing.T) {
 		{args: wrapArgs(FloatType, -102.1), want: NewFloat(-102.1).ToObject()},
 		{args: wrapArgs(FloatType, 42), want: NewFloat(42).ToObject()},
 		{args: wrapArgs(FloatType, "1.024e3"), want: NewFloat(1024).ToObject()},
+		{args: wrapArgs(FloatType, NewUnicode("1.024e3")), want: NewFloat(1024).ToObject()},
 		{args: wrapArgs(FloatType, "-42"), want: NewFloat(-42).ToObject()},
 		{args: wrapArgs(FloatType, math.Inf(1)), want: NewFloat(math.Inf(1)).ToObject()},
 		{args: wrapArgs(FloatType, math.Inf(-1)), want: NewFloat(math.Inf(-1)).ToObject()},
@@ -309,6 +358,12 @@ func TestFloatRepr(t *testing.T) {
 		{args: wrapArgs(1e+16), want: NewStr("1e+16").ToObject()},
 		{args: wrapArgs(1E16), want: NewStr("1e+16").ToObject()},
 		{args: wrapArgs(1e-6), want: NewStr("1e-06").ToObject()},
+		{args: wrapArgs(1e-4), want: NewStr("0.0001").ToObject()},
+		{args: wrapArgs(0.30000000000000004), want: NewStr("0.30000000000000004").ToObject()},
+		{args: wrapArgs(-5.0), want: NewStr("-5.0").ToObject()},
+		{args: wrapArgs(123456789012345678.0), want: NewStr("1.2345678901234568e+17").ToObject()},
+		{args: wrapArgs(5e-324), want: NewStr("5e-324").ToObject()},
+		{args: wrapArgs(math.MaxFloat64), want: NewStr("1.7976931348623157e+308").ToObject()},
 		{args: wrapArgs(math.Inf(1)), want: NewStr("inf").ToObject()},
 		{args: wrapArgs(math.Inf(-1)), want: NewStr("-inf").ToObject()},
 		{args: wrapArgs(math.NaN()), want: NewStr("nan").ToObject()},
@@ -325,8 +380,11 @@ func TestFloatStr(t *testing.T) {
 		{args: wrapArgs(1.0), want: NewStr("1.0").ToObject()},
 		{args: wrapArgs(-847.373), want: NewStr("-847.373").ToObject()},
 		{args: wrapArgs(0.123456789123456789), want: NewStr("0.123456789123").ToObject()},
-		{args: wrapArgs(1e+11), want: NewStr("100000000000.0").ToObject()},
-		{args: wrapArgs(1e+12), want: NewStr("1e+12").ToObject()},
+		{args: wrapArgs(1e+10), want: NewStr("10000000000.0").ToObject()},
+		{args: wrapArgs(1e+11), want: NewStr("1e+11").ToObject()},
+		{args: wrapArgs(9.999999999999), want: NewStr("10.0").ToObject()},
+		{args: wrapArgs(-5.0), want: NewStr("-5.0").ToObject()},
+		{args: wrapArgs(math.Copysign(0, -1)), want: NewStr("-0.0").ToObject()},
 		{args: wrapArgs(1e-4), want: NewStr("0.0001").ToObject()},
 		{args: wrapArgs(1e-5), want: NewStr("1e-05").ToObject()},
 		{args: wrapArgs(math.Inf(1)), want: NewStr("inf").ToObject()},
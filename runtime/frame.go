@@ -39,7 +39,16 @@ type Frame struct {
 	globals     *Dict `attr:"f_globals"`
 	lineno      int   `attr:"f_lineno"`
 	code        *Code `attr:"f_code"`
-	taken       bool
+	// locals produces a snapshot of the local variables of the code
+	// running in the frame. It is nil at module level.
+	locals func() *Dict
+	// tracedLocals is the snapshot of the local variables recorded by
+	// SetTracedLocals for code that does not set locals.
+	tracedLocals *Dict
+	// trace is the local trace function called for each line executed in
+	// the frame or nil. See SetTrace.
+	trace *Object
+	taken bool
 }
 
 // NewRootFrame creates a Frame that is the bottom of a new stack.
@@ -49,6 +58,53 @@ func NewRootFrame() *Frame {
 	return f
 }
 
+// NewRootFrameWithBuiltins creates a Frame that is the bottom of a new stack
+// and that resolves builtin names using the given dict instead of the global
+// Builtins dict. This allows embedders to restrict the builtins available to
+// a particular execution context. Threads started from the stack use the same
+// dict. Modifications to the dict are not visible to other stacks unless it
+// is shared.
+func NewRootFrameWithBuiltins(builtins *Dict) *Frame {
+	return NewRootFrameWithPolicy(&Policy{Builtins: builtins})
+}
+
+// NewRootFrameWithPolicy creates a Frame that is the bottom of a new stack
+// whose operations are restricted by p.
+func NewRootFrameWithPolicy(p *Policy) *Frame {
+	f := NewRootFrame()
+	f.threadState.policy = p
+	f.threadState.steps = new(int64)
+	return f
+}
+
+// newThreadRootFrame creates a Frame that is the bottom of a new stack
+// started from f's stack, e.g. by a new thread. The new stack is subject to
+// the same Policy as f's and shares its step budget.
+func newThreadRootFrame(f *Frame) *Frame {
+	return f.stackPolicy().newRootFrame()
+}
+
+// stackPolicy is the Policy of a stack together with its step counter. It is
+// recorded for code that runs later on behalf of the stack, e.g. finalizers.
+type stackPolicy struct {
+	policy *Policy
+	steps  *int64
+}
+
+// stackPolicy returns the Policy and step counter of f's stack.
+func (f *Frame) stackPolicy() stackPolicy {
+	return stackPolicy{f.threadState.policy, f.threadState.steps}
+}
+
+// newRootFrame creates a Frame that is the bottom of a new stack subject to
+// s's Policy and sharing its step budget.
+func (s stackPolicy) newRootFrame() *Frame {
+	f := NewRootFrame()
+	f.threadState.policy = s.policy
+	f.threadState.steps = s.steps
+	return f
+}
+
 // newChildFrame creates a new Frame whose parent frame is back.
 func newChildFrame(back *Frame) *Frame {
 	f := back.frameCache
@@ -73,6 +129,9 @@ func (f *Frame) release() {
 		f.setDict(nil)
 		f.globals = nil
 		f.code = nil
+		f.locals = nil
+		f.tracedLocals = nil
+		f.trace = nil
 	} else if f.back != nil {
 		f.back.taken = true
 	}
@@ -97,14 +156,126 @@ func (f *Frame) Globals() *Dict {
 	return f.globals
 }
 
+// Locals returns a dict holding the local variables of the code running in f.
+// At module level this is the globals dict.
+func (f *Frame) Locals() *Dict {
+	if f.locals == nil {
+		if f.tracedLocals != nil {
+			return f.tracedLocals
+		}
+		return f.globals
+	}
+	return f.locals()
+}
+
+// SetLocals sets the function used by Locals to collect the local variables of
+// the code running in f. Compiled functions that may call the locals builtin
+// pass a closure over their variables, e.g. one that calls NewLocalsDict.
+func (f *Frame) SetLocals(locals func() *Dict) {
+	f.locals = locals
+}
+
+// Traced returns true when f has a local trace function, i.e. when a debugger
+// receives 'line' events for the code running in f.
+func (f *Frame) Traced() bool {
+	return f.trace != nil
+}
+
+// SetTracedLocals records locals as the snapshot of the local variables of the
+// code running in f. Compiled code that doesn't set a locals function with
+// SetLocals calls it before each statement while f is traced so that
+// debuggers can inspect f_locals without the cost of a closure over the
+// variables.
+func (f *Frame) SetTracedLocals(locals *Dict) {
+	f.tracedLocals = locals
+}
+
+// NewLocalsDict returns a dict mapping each name to the corresponding value,
+// omitting local variables that are not bound.
+func NewLocalsDict(names []string, values []*Object) *Dict {
+	table := newDictTable(len(names) * 2)
+	for i, name := range names {
+		if v := values[i]; v != UnboundLocal {
+			table.insertAbsentEntry(&dictEntry{hashString(name), NewStr(name).ToObject(), v})
+		}
+	}
+	return &Dict{Object: Object{typ: DictType}, table: table}
+}
+
+// Builtins returns the dict used to resolve builtin names in this frame's
+// stack.
+func (f *Frame) Builtins() *Dict {
+	if p := f.threadState.policy; p != nil && p.Builtins != nil {
+		return p.Builtins
+	}
+	return Builtins
+}
+
 // ToObject upcasts f to an Object.
 func (f *Frame) ToObject() *Object {
 	return &f.Object
 }
 
-// SetLineno sets the current line number for the frame.
-func (f *Frame) SetLineno(lineno int) {
+// SetLineno sets the current line number for the frame. It is called before
+// each statement is executed and raises SecurityError if doing so would exceed
+// the step limit of the stack's Policy. When the frame has a local trace
+// function it is called with a 'line' event.
+func (f *Frame) SetLineno(lineno int) *BaseException {
 	f.lineno = lineno
+	if f.trace != nil {
+		if raised := f.traceEvent(f.trace, "line", None); raised != nil {
+			return raised
+		}
+	}
+	return f.checkStep()
+}
+
+// SetTrace sets the trace function for the thread running f, as for
+// sys.settrace. fn is called with a 'call' event for each function invoked
+// on the thread and its return value, if not None, becomes the local trace
+// function of the new frame, which receives 'line' and 'return' events. A nil
+// or None fn disables tracing of subsequent calls.
+func SetTrace(f *Frame, fn *Object) {
+	if fn == None {
+		fn = nil
+	}
+	f.threadState.traceFunc = fn
+}
+
+// GetTrace returns the trace function set for the thread running f by
+// SetTrace or None.
+func GetTrace(f *Frame) *Object {
+	if fn := f.threadState.traceFunc; fn != nil {
+		return fn
+	}
+	return None
+}
+
+// traceEvent calls the trace function fn with (f, event, arg) and replaces
+// f's local trace function with the result. Events raised while a trace
+// function is running are ignored. If fn raises then tracing is disabled for
+// the thread and the exception is returned.
+func (f *Frame) traceEvent(fn *Object, event string, arg *Object) *BaseException {
+	ts := f.threadState
+	if ts.tracing {
+		return nil
+	}
+	// The trace function may keep a reference to the frame.
+	f.taken = true
+	ts.tracing = true
+	result, raised := fn.Call(f, Args{f.ToObject(), NewStr(event).ToObject(), arg}, nil)
+	ts.tracing = false
+	if raised != nil {
+		ts.traceFunc = nil
+		f.trace = nil
+		return raised
+	}
+	if event != "return" {
+		if f.trace = result; result == None {
+			f.trace = nil
+		}
+	}
+	return nil
 }
 
 // State returns the current run state for f.
@@ -267,6 +438,28 @@ func (f *Frame) FreeArgs(args Args) {
 	f.threadState.argsCache[numEntries] = args
 }
 
+// callArgs calls callable with the given positional arguments. The arguments
+// are passed in a slice obtained from MakeArgs so fixed-arity calls such as
+// those made by slot wrappers don't allocate a new Args each time.
+func (f *Frame) callArgs(callable *Object, args ...*Object) (*Object, *BaseException) {
+	callArgs := f.MakeArgs(len(args))
+	copy(callArgs, args)
+	result, raised := callable.Call(f, callArgs, nil)
+	f.FreeArgs(callArgs)
+	return result, raised
+}
+
+// callPrepend calls callable with o followed by args as its positional
+// arguments, using a slice obtained from MakeArgs.
+func (f *Frame) callPrepend(callable, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	callArgs := f.MakeArgs(len(args) + 1)
+	callArgs[0] = o
+	copy(callArgs[1:], args)
+	result, raised := callable.Call(f, callArgs, kwargs)
+	f.FreeArgs(callArgs)
+	return result, raised
+}
+
 // FrameType is the object representing the Python 'frame' type.
 var FrameType = newBasisType("frame", reflect.TypeOf(Frame{}), toFrameUnsafe, ObjectType)
 
@@ -293,8 +486,48 @@ func frameExcInfo(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException)
 	return NewTuple2(excObj, tbObj).ToObject(), nil
 }
 
+func frameGetLocals(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_locals", args, FrameType); raised != nil {
+		return nil, raised
+	}
+	frame := toFrameUnsafe(args[0])
+	if frame.locals == nil && frame.tracedLocals == nil && frame.code != nil && frame.code.name != "<module>" {
+		// Compiled functions only record their variables when they
+		// reference locals(), vars(), dir() or eval(), contain an exec
+		// statement or are traced so nothing is visible.
+		return NewDict().ToObject(), nil
+	}
+	if locals := frame.Locals(); locals != nil {
+		return locals.ToObject(), nil
+	}
+	return NewDict().ToObject(), nil
+}
+
+func frameGetTrace(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_trace", args, FrameType); raised != nil {
+		return nil, raised
+	}
+	if trace := toFrameUnsafe(args[0]).trace; trace != nil {
+		return trace, nil
+	}
+	return None, nil
+}
+
+func frameSetTrace(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_set_trace", args, FrameType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	frame := toFrameUnsafe(args[0])
+	if frame.trace = args[1]; frame.trace == None {
+		frame.trace = nil
+	}
+	return None, nil
+}
+
 func initFrameType(dict map[string]*Object) {
 	FrameType.flags &= ^(typeFlagInstantiable | typeFlagBasetype)
+	dict["f_locals"] = newProperty(newBuiltinFunction("_get_locals", frameGetLocals).ToObject(), nil, nil).ToObject()
+	dict["f_trace"] = newProperty(newBuiltinFunction("_get_trace", frameGetTrace).ToObject(), newBuiltinFunction("_set_trace", frameSetTrace).ToObject(), nil).ToObject()
 	dict["__exc_clear__"] = newBuiltinFunction("__exc_clear__", frameExcClear).ToObject()
 	dict["__exc_info__"] = newBuiltinFunction("__exc_info__", frameExcInfo).ToObject()
 }
@@ -62,6 +62,32 @@ func TestFrameArgsCache(t *testing.T) {
 	}
 }
 
+func TestFrameCallArgs(t *testing.T) {
+	f := NewRootFrame()
+	var gotArgs Args
+	fun := newBuiltinFunction("TestFrameCallArgs", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		gotArgs = args
+		return newTestTuple(NewTuple(args.makeCopy()...), kwargs.makeDict()).ToObject(), nil
+	}).ToObject()
+	one, two := NewInt(1).ToObject(), NewInt(2).ToObject()
+	want := newTestTuple(newTestTuple(one, two), NewDict()).ToObject()
+	if got, raised := f.callArgs(fun, one, two); raised != nil {
+		t.Fatal(raised)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Errorf("f.callArgs(fun, 1, 2) = %v, want %v", got, want)
+	}
+	// The args passed to fun are returned to the cache after the call.
+	if args := f.MakeArgs(2); &args[0] != &gotArgs[0] {
+		t.Error("args passed by callArgs not returned to the cache")
+	}
+	want = newTestTuple(newTestTuple(one, two), newTestDict("foo", one)).ToObject()
+	if got, raised := f.callPrepend(fun, one, Args{two}, wrapKWArgs("foo", 1)); raised != nil {
+		t.Fatal(raised)
+	} else if !reflect.DeepEqual(got, want) {
+		t.Errorf("f.callPrepend(fun, 1, (2,), foo=1) = %v, want %v", got, want)
+	}
+}
+
 func TestFramePopCheckpoint(t *testing.T) {
 	cases := []struct {
 		states  []RunState
@@ -206,6 +232,178 @@ func TestFrameRoot(t *testing.T) {
 	}
 }
 
+func TestFrameBuiltins(t *testing.T) {
+	builtins := newStringDict(map[string]*Object{"foo": NewInt(42).ToObject()})
+	f := NewRootFrameWithBuiltins(builtins)
+	f.globals = NewDict()
+	if got := newChildFrame(f).Builtins(); got != builtins {
+		t.Errorf("child frame Builtins() = %v, want %v", got, builtins)
+	}
+	if got, raised := ResolveGlobal(f, NewStr("foo")); raised != nil || got == nil || !got.isInstance(IntType) || toIntUnsafe(got).Value() != 42 {
+		t.Errorf("ResolveGlobal(foo) = %v, %v, want 42, nil", got, raised)
+	}
+	if _, raised := ResolveGlobal(f, NewStr("len")); raised == nil || raised.typ != NameErrorType {
+		t.Errorf("ResolveGlobal(len) raised %v, want NameError", raised)
+	}
+	c := make(chan *Dict)
+	callable := newBuiltinFunction("TestFrameBuiltins", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		c <- f.Builtins()
+		return None, nil
+	}).ToObject()
+	StartThread(f, callable)
+	if got := <-c; got != builtins {
+		t.Errorf("thread Builtins() = %v, want %v", got, builtins)
+	}
+	if got := NewRootFrame().Builtins(); got != Builtins {
+		t.Errorf("NewRootFrame().Builtins() = %v, want global Builtins", got)
+	}
+}
+
+func TestFrameLocals(t *testing.T) {
+	f := NewRootFrame()
+	f.globals = NewDict()
+	if got := f.Locals(); got != f.globals {
+		t.Errorf("Locals() = %v, want globals %v", got, f.globals)
+	}
+	x := NewInt(1).ToObject()
+	f.SetLocals(func() *Dict {
+		return NewLocalsDict([]string{"x"}, []*Object{x})
+	})
+	first := f.Locals()
+	x = NewInt(2).ToObject()
+	if first == f.Locals() {
+		t.Error("Locals() returned the same dict twice, want a new snapshot")
+	}
+	if got := mustNotRaise(f.Locals().GetItemString(f, "x")); got != x {
+		t.Errorf("Locals()['x'] = %v, want %v", got, x)
+	}
+	// Frames returned to the cache must not keep their locals.
+	child := newChildFrame(f)
+	child.SetLocals(f.locals)
+	child.release()
+	if got := newChildFrame(f); got.locals != nil {
+		t.Error("reused child frame has a locals function, want nil")
+	}
+}
+
+func TestFrameGetLocals(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, frame *Frame) (*Object, *BaseException) {
+		return GetAttr(f, frame.ToObject(), NewStr("f_locals"), nil)
+	})
+	globals := newTestDict("foo", 1)
+	module := NewRootFrame()
+	module.globals = globals
+	module.code = NewCode("<module>", "foo.py", nil, 0, nil)
+	function := newChildFrame(module)
+	function.globals = globals
+	function.code = NewCode("bar", "foo.py", nil, 0, nil)
+	withLocals := newChildFrame(module)
+	withLocals.globals = globals
+	withLocals.code = function.code
+	withLocals.SetLocals(func() *Dict { return newTestDict("baz", 2) })
+	traced := newChildFrame(module)
+	traced.globals = globals
+	traced.code = function.code
+	traced.SetTracedLocals(newTestDict("qux", 3))
+	cases := []invokeTestCase{
+		{args: wrapArgs(module), want: globals.ToObject()},
+		{args: wrapArgs(function), want: NewDict().ToObject()},
+		{args: wrapArgs(withLocals), want: newTestDict("baz", 2).ToObject()},
+		{args: wrapArgs(traced), want: newTestDict("qux", 3).ToObject()},
+		{args: wrapArgs(NewRootFrame()), want: NewDict().ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFrameTrace(t *testing.T) {
+	var events []string
+	var tracer *Object
+	tracer = newBuiltinFunction("tracer", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		frame := toFrameUnsafe(args[0])
+		s, raised := ToStr(f, args[2])
+		if raised != nil {
+			return nil, raised
+		}
+		events = append(events, fmt.Sprintf("%s %s %d %s", frame.code.name, toStrUnsafe(args[1]).Value(), frame.lineno, s.Value()))
+		return tracer, nil
+	}).ToObject()
+	c := NewCode("foo", "foo.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		for _, lineno := range []int{2, 3} {
+			if raised := f.SetLineno(lineno); raised != nil {
+				return nil, raised
+			}
+		}
+		return NewInt(42).ToObject(), nil
+	}).WithLineno(1)
+	f := NewRootFrame()
+	SetTrace(f, tracer)
+	if got := GetTrace(f); got != tracer {
+		t.Errorf("GetTrace() = %v, want %v", got, tracer)
+	}
+	if _, raised := c.Eval(f, nil, nil, nil); raised != nil {
+		t.Fatalf("Eval() raised %v", raised)
+	}
+	SetTrace(f, None)
+	if got := GetTrace(f); got != None {
+		t.Errorf("GetTrace() = %v, want None", got)
+	}
+	if _, raised := c.Eval(f, nil, nil, nil); raised != nil {
+		t.Fatalf("Eval() raised %v", raised)
+	}
+	want := []string{"foo call 1 None", "foo line 2 None", "foo line 3 None", "foo return 3 42"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("trace events = %v, want %v", events, want)
+	}
+}
+
+func TestFrameTraceRaises(t *testing.T) {
+	tracer := newBuiltinFunction("tracer", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+		return nil, f.RaiseType(ValueErrorType, "foo")
+	}).ToObject()
+	called := false
+	c := NewCode("foo", "foo.py", nil, 0, func(*Frame, []*Object) (*Object, *BaseException) {
+		called = true
+		return None, nil
+	})
+	f := NewRootFrame()
+	SetTrace(f, tracer)
+	_, raised := c.Eval(f, nil, nil, nil)
+	if want := mustCreateException(ValueErrorType, "foo"); !exceptionsAreEquivalent(raised, want) {
+		t.Errorf("Eval() raised %v, want %v", raised, want)
+	}
+	if called {
+		t.Error("code was run after the trace function raised")
+	}
+	if got := GetTrace(f); got != None {
+		t.Errorf("GetTrace() = %v, want None", got)
+	}
+}
+
+func TestFrameGetSetTrace(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, frame *Frame, trace *Object) (*Object, *BaseException) {
+		if raised := SetAttr(f, frame.ToObject(), NewStr("f_trace"), trace); raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, frame.ToObject(), NewStr("f_trace"), nil)
+	})
+	tracer := newBuiltinFunction("tracer", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+		return None, nil
+	}).ToObject()
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewRootFrame(), tracer), want: tracer},
+		{args: wrapArgs(NewRootFrame(), None), want: None},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestFrameExcInfo(t *testing.T) {
 	raisedFrame := NewRootFrame()
 	raisedExc := mustCreateException(ValueErrorType, "foo")
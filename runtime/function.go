@@ -78,9 +78,14 @@ type Func func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException)
 type Function struct {
 	Object
 	fn      Func
-	name    string `attr:"__name__"`
-	code    *Code  `attr:"func_code"`
-	globals *Dict  `attr:"func_globals"`
+	name    string
+	code    *Code `attr:"func_code"`
+	globals *Dict `attr:"func_globals"`
+	// doc is the function's __doc__ attribute or nil when it is None.
+	doc *Object
+	// module is the function's __module__ attribute or nil when it has not
+	// been set, in which case it is looked up in globals.
+	module *Object
 }
 
 // NewFunction creates a function object corresponding to a Python function
@@ -89,7 +94,7 @@ type Function struct {
 // number of arguments are provided, populating *args and **kwargs if
 // necessary, etc.
 func NewFunction(c *Code, globals *Dict) *Function {
-	return &Function{Object{typ: FunctionType, dict: NewDict()}, nil, c.name, c, globals}
+	return &Function{Object: Object{typ: FunctionType, dict: NewDict()}, name: c.name, code: c, globals: globals}
 }
 
 // newBuiltinFunction returns a function object with the given name that
@@ -121,6 +126,141 @@ func functionCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object
 	return code.Eval(f, fun.globals, args, kwargs)
 }
 
+func functionGetDefaults(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_defaults", args, FunctionType); raised != nil {
+		return nil, raised
+	}
+	code := toFunctionUnsafe(args[0]).code
+	if code == nil {
+		return None, nil
+	}
+	var defaults []*Object
+	for _, p := range code.paramSpec.params {
+		if p.Def != nil {
+			defaults = append(defaults, p.Def)
+		}
+	}
+	if len(defaults) == 0 {
+		return None, nil
+	}
+	return NewTuple(defaults...).ToObject(), nil
+}
+
+func functionSetDefaults(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_set_defaults", args, FunctionType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	code := toFunctionUnsafe(args[0]).code
+	if code == nil {
+		return nil, f.RaiseType(TypeErrorType, "func_defaults cannot be set on builtin functions")
+	}
+	var defaults []*Object
+	if args[1] != None {
+		if !args[1].isInstance(TupleType) {
+			return nil, f.RaiseType(TypeErrorType, "func_defaults must be set to a tuple object")
+		}
+		defaults = toTupleUnsafe(args[1]).elems
+	}
+	s := code.paramSpec
+	params := make([]Param, len(s.params))
+	numDefaults := len(defaults)
+	if numDefaults > len(params) {
+		defaults = defaults[numDefaults-len(params):]
+		numDefaults = len(params)
+	}
+	for i, p := range s.params {
+		params[i].Name = p.Name
+		if j := i - (len(params) - numDefaults); j >= 0 {
+			params[i].Def = defaults[j]
+		}
+	}
+	code.paramSpec = NewParamSpec(s.name, params, s.varArgIndex != -1, s.kwArgIndex != -1)
+	return None, nil
+}
+
+func functionGetClosure(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_closure", args, FunctionType); raised != nil {
+		return nil, raised
+	}
+	// Free variables are captured by Go closures rather than cells.
+	return None, nil
+}
+
+func functionGetDict(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_dict", args, FunctionType); raised != nil {
+		return nil, raised
+	}
+	return toFunctionUnsafe(args[0]).Dict().ToObject(), nil
+}
+
+func functionGetDoc(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_doc", args, FunctionType); raised != nil {
+		return nil, raised
+	}
+	if doc := toFunctionUnsafe(args[0]).doc; doc != nil {
+		return doc, nil
+	}
+	return None, nil
+}
+
+func functionGetModule(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_module", args, FunctionType); raised != nil {
+		return nil, raised
+	}
+	fun := toFunctionUnsafe(args[0])
+	if fun.module != nil {
+		return fun.module, nil
+	}
+	if fun.globals == nil {
+		return builtinStr.ToObject(), nil
+	}
+	name, raised := fun.globals.GetItemString(f, "__name__")
+	if raised != nil {
+		return nil, raised
+	}
+	if name == nil {
+		return None, nil
+	}
+	return name, nil
+}
+
+func functionGetName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_name", args, FunctionType); raised != nil {
+		return nil, raised
+	}
+	return NewStr(toFunctionUnsafe(args[0]).name).ToObject(), nil
+}
+
+func functionSetDoc(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_set_doc", args, FunctionType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	fun := toFunctionUnsafe(args[0])
+	if fun.doc = args[1]; fun.doc == None {
+		fun.doc = nil
+	}
+	return None, nil
+}
+
+func functionSetModule(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_set_module", args, FunctionType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	toFunctionUnsafe(args[0]).module = args[1]
+	return None, nil
+}
+
+func functionSetName(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_set_name", args, FunctionType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if args[1].typ != StrType {
+		return nil, f.RaiseType(TypeErrorType, "__name__ must be set to a string object")
+	}
+	toFunctionUnsafe(args[0]).name = toStrUnsafe(args[1]).Value()
+	return None, nil
+}
+
 func functionGet(f *Frame, desc, instance *Object, owner *Type) (*Object, *BaseException) {
 	args := f.MakeArgs(3)
 	args[0] = desc
@@ -136,7 +276,23 @@ func functionRepr(_ *Frame, o *Object) (*Object, *BaseException) {
 	return NewStr(fmt.Sprintf("<%s %s at %p>", fun.typ.Name(), fun.Name(), fun)).ToObject(), nil
 }
 
-func initFunctionType(map[string]*Object) {
+func initFunctionType(dict map[string]*Object) {
+	doc := newProperty(newBuiltinFunction("_get_doc", functionGetDoc).ToObject(), newBuiltinFunction("_set_doc", functionSetDoc).ToObject(), nil).ToObject()
+	name := newProperty(newBuiltinFunction("_get_name", functionGetName).ToObject(), newBuiltinFunction("_set_name", functionSetName).ToObject(), nil).ToObject()
+	defaults := newProperty(newBuiltinFunction("_get_defaults", functionGetDefaults).ToObject(), newBuiltinFunction("_set_defaults", functionSetDefaults).ToObject(), nil).ToObject()
+	closure := newProperty(newBuiltinFunction("_get_closure", functionGetClosure).ToObject(), nil, nil).ToObject()
+	dict["__closure__"] = closure
+	dict["__code__"] = makeStructFieldDescriptor(FunctionType, "code", "__code__", fieldDescriptorRO)
+	dict["__defaults__"] = defaults
+	dict["__doc__"] = doc
+	dict["__globals__"] = makeStructFieldDescriptor(FunctionType, "globals", "__globals__", fieldDescriptorRO)
+	dict["__module__"] = newProperty(newBuiltinFunction("_get_module", functionGetModule).ToObject(), newBuiltinFunction("_set_module", functionSetModule).ToObject(), nil).ToObject()
+	dict["__name__"] = name
+	dict["func_closure"] = closure
+	dict["func_defaults"] = defaults
+	dict["func_dict"] = newProperty(newBuiltinFunction("_get_dict", functionGetDict).ToObject(), nil, nil).ToObject()
+	dict["func_doc"] = doc
+	dict["func_name"] = name
 	FunctionType.flags &= ^(typeFlagInstantiable | typeFlagBasetype)
 	FunctionType.slots.Call = &callSlot{functionCall}
 	FunctionType.slots.Get = &getSlot{functionGet}
@@ -146,7 +302,7 @@ func initFunctionType(map[string]*Object) {
 // staticMethod represents Python 'staticmethod' objects.
 type staticMethod struct {
 	Object
-	callable *Object
+	callable *Object `attr:"__func__"`
 }
 
 func newStaticMethod(callable *Object) *staticMethod {
@@ -186,7 +342,7 @@ func initStaticMethodType(map[string]*Object) {
 // classMethod represents Python 'classmethod' objects.
 type classMethod struct {
 	Object
-	callable *Object
+	callable *Object `attr:"__func__"`
 }
 
 func newClassMethod(callable *Object) *classMethod {
@@ -202,11 +358,14 @@ func (m *classMethod) ToObject() *Object {
 	return &m.Object
 }
 
-func classMethodGet(f *Frame, desc, _ *Object, owner *Type) (*Object, *BaseException) {
+func classMethodGet(f *Frame, desc, instance *Object, owner *Type) (*Object, *BaseException) {
 	m := toClassMethodUnsafe(desc)
 	if m.callable == nil {
 		return nil, f.RaiseType(RuntimeErrorType, "uninitialized classmethod object")
 	}
+	if owner == nil {
+		owner = instance.typ
+	}
 	args := f.MakeArgs(3)
 	args[0] = m.callable
 	args[1] = owner.ToObject()
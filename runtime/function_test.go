@@ -39,6 +39,74 @@ func TestFunctionCall(t *testing.T) {
 	}
 }
 
+func TestFunctionDefaults(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, fn *Function) (*Object, *BaseException) {
+		return GetAttr(f, fn.ToObject(), NewStr("func_defaults"), nil)
+	})
+	fn := func(*Frame, []*Object) (*Object, *BaseException) { return None, nil }
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewFunction(NewCode("f", "f.py", nil, 0, fn), nil)), want: None},
+		{args: wrapArgs(NewFunction(NewCode("f", "f.py", []Param{{"a", nil}, {"b", NewInt(2).ToObject()}}, 0, fn), nil)), want: newTestTuple(2).ToObject()},
+		{args: wrapArgs(newBuiltinFunction("f", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil })), want: None},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFunctionSetDefaults(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, fn *Function, defaults *Object, args ...*Object) (*Object, *BaseException) {
+		if raised := SetAttr(f, fn.ToObject(), NewStr("func_defaults"), defaults); raised != nil {
+			return nil, raised
+		}
+		return fn.Call(f, args, nil)
+	})
+	newFn := func() *Function {
+		params := []Param{{"a", nil}, {"b", NewInt(2).ToObject()}}
+		return NewFunction(NewCode("f", "f.py", params, 0, func(f *Frame, args []*Object) (*Object, *BaseException) {
+			return NewTuple(Args(args).makeCopy()...).ToObject(), nil
+		}), nil)
+	}
+	builtin := newBuiltinFunction("f", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil })
+	cases := []invokeTestCase{
+		{args: wrapArgs(newFn(), newTestTuple(3), 1), want: newTestTuple(1, 3).ToObject()},
+		{args: wrapArgs(newFn(), newTestTuple(4, 5)), want: newTestTuple(4, 5).ToObject()},
+		{args: wrapArgs(newFn(), newTestTuple(4, 5, 6)), want: newTestTuple(5, 6).ToObject()},
+		{args: wrapArgs(newFn(), None, 1), wantExc: mustCreateException(TypeErrorType, "f() takes at least 2 arguments (1 given)")},
+		{args: wrapArgs(newFn(), newTestList(3), 1), wantExc: mustCreateException(TypeErrorType, "func_defaults must be set to a tuple object")},
+		{args: wrapArgs(builtin, NewTuple()), wantExc: mustCreateException(TypeErrorType, "func_defaults cannot be set on builtin functions")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFunctionModule(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, fn *Function) (*Object, *BaseException) {
+		return GetAttr(f, fn.ToObject(), NewStr("__module__"), nil)
+	})
+	code := NewCode("f", "f.py", nil, 0, nil)
+	renamed := NewFunction(code, NewDict())
+	if raised := SetAttr(NewRootFrame(), renamed.ToObject(), NewStr("__module__"), NewStr("bar").ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewFunction(code, newTestDict("__name__", "foo"))), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(NewFunction(code, NewDict())), want: None},
+		{args: wrapArgs(renamed), want: NewStr("bar").ToObject()},
+		{args: wrapArgs(newBuiltinFunction("f", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil })), want: NewStr("__builtin__").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestFunctionGet(t *testing.T) {
 	appendMethod := mustNotRaise(GetAttr(NewRootFrame(), NewList().ToObject(), NewStr("append"), nil))
 	if !appendMethod.isInstance(MethodType) {
@@ -56,6 +124,29 @@ func TestFunctionName(t *testing.T) {
 	}
 }
 
+func TestFunctionSetAttr(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, name string, value *Object) (*Object, *BaseException) {
+		foo := newBuiltinFunction("foo", func(*Frame, Args, KWArgs) (*Object, *BaseException) { return None, nil }).ToObject()
+		if raised := SetAttr(f, foo, NewStr(name), value); raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, foo, NewStr(name), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("__name__", "bar"), want: NewStr("bar").ToObject()},
+		{args: wrapArgs("func_name", "bar"), want: NewStr("bar").ToObject()},
+		{args: wrapArgs("__name__", 123), wantExc: mustCreateException(TypeErrorType, "__name__ must be set to a string object")},
+		{args: wrapArgs("__doc__", "bar"), want: NewStr("bar").ToObject()},
+		{args: wrapArgs("func_doc", 123), want: NewInt(123).ToObject()},
+		{args: wrapArgs("__doc__", None), want: None},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestFunctionStrRepr(t *testing.T) {
 	fn := func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) { return nil, nil }
 	cases := []struct {
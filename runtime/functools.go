@@ -0,0 +1,237 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"reflect"
+)
+
+var (
+	// PartialType is the object representing the Python
+	// 'functools.partial' type.
+	PartialType = newBasisType("partial", reflect.TypeOf(Partial{}), toPartialUnsafe, ObjectType)
+	// KeyWrapperType is the object representing the key wrapper objects
+	// returned by functools.cmp_to_key.
+	KeyWrapperType = newBasisType("KeyWrapper", reflect.TypeOf(KeyWrapper{}), toKeyWrapperUnsafe, ObjectType)
+)
+
+// Partial represents Python 'functools.partial' objects. Calling a partial
+// calls fn with args prepended to the positional arguments and kwargs merged
+// underneath the keyword arguments.
+type Partial struct {
+	Object
+	fn     *Object `attr:"func"`
+	args   *Tuple  `attr:"args"`
+	kwargs *Dict   `attr:"keywords"`
+}
+
+func toPartialUnsafe(o *Object) *Partial {
+	return (*Partial)(o.toPointer())
+}
+
+// ToObject upcasts p to an Object.
+func (p *Partial) ToObject() *Object {
+	return &p.Object
+}
+
+func partialCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	p := toPartialUnsafe(callable)
+	if len(p.args.elems) > 0 {
+		args = append(p.args.elems[:len(p.args.elems):len(p.args.elems)], args...)
+	}
+	if p.kwargs.Len() == 0 {
+		return p.fn.Call(f, args, kwargs)
+	}
+	d := NewDict()
+	if raised := d.Update(f, p.kwargs.ToObject()); raised != nil {
+		return nil, raised
+	}
+	for _, kwarg := range kwargs {
+		if raised := d.SetItemString(f, kwarg.Name, kwarg.Value); raised != nil {
+			return nil, raised
+		}
+	}
+	return Invoke(f, p.fn, args, nil, nil, d.ToObject())
+}
+
+func partialNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) == 0 {
+		return nil, f.RaiseType(TypeErrorType, "type 'partial' takes at least one argument")
+	}
+	if args[0].typ.slots.Call == nil {
+		return nil, f.RaiseType(TypeErrorType, "the first argument must be callable")
+	}
+	p := toPartialUnsafe(newObject(t))
+	p.fn = args[0]
+	p.args = NewTuple(args[1:].makeCopy()...)
+	p.kwargs = kwargs.makeDict()
+	return p.ToObject(), nil
+}
+
+func partialReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, PartialType); raised != nil {
+		return nil, raised
+	}
+	p := toPartialUnsafe(args[0])
+	dict := None
+	if d := p.Dict(); d != nil && d.Len() > 0 {
+		dict = d.ToObject()
+	}
+	state := NewTuple(p.fn, p.args.ToObject(), p.kwargs.ToObject(), dict)
+	return NewTuple(p.typ.ToObject(), NewTuple1(p.fn).ToObject(), state.ToObject()).ToObject(), nil
+}
+
+func partialSetState(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__setstate__", args, PartialType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	p, state := toPartialUnsafe(args[0]), args[1]
+	if !state.isInstance(TupleType) || len(toTupleUnsafe(state).elems) != 4 {
+		return nil, f.RaiseType(TypeErrorType, "invalid partial state")
+	}
+	elems := toTupleUnsafe(state).elems
+	fn, fnArgs, kw, dict := elems[0], elems[1], elems[2], elems[3]
+	if fn.typ.slots.Call == nil || !fnArgs.isInstance(TupleType) || (kw != None && !kw.isInstance(DictType)) {
+		return nil, f.RaiseType(TypeErrorType, "invalid partial state")
+	}
+	if dict != None && !dict.isInstance(DictType) {
+		return nil, f.RaiseType(TypeErrorType, "invalid partial state")
+	}
+	p.fn = fn
+	p.args = toTupleUnsafe(fnArgs)
+	if kw == None {
+		p.kwargs = NewDict()
+	} else {
+		p.kwargs = toDictUnsafe(kw)
+	}
+	if dict != None {
+		p.setDict(toDictUnsafe(dict))
+	}
+	return None, nil
+}
+
+func initPartialType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("functools").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", partialReduce).ToObject()
+	dict["__setstate__"] = newBuiltinFunction("__setstate__", partialSetState).ToObject()
+	PartialType.slots.Call = &callSlot{partialCall}
+	PartialType.slots.New = &newSlot{partialNew}
+}
+
+// KeyWrapper represents the objects returned by functools.cmp_to_key. A key
+// wrapper with a nil obj is the key function itself and calling it returns a
+// new wrapper around its argument. Wrappers around objects compare by calling
+// cmp on the wrapped objects.
+type KeyWrapper struct {
+	Object
+	cmp *Object
+	obj *Object
+}
+
+func toKeyWrapperUnsafe(o *Object) *KeyWrapper {
+	return (*KeyWrapper)(o.toPointer())
+}
+
+// ToObject upcasts k to an Object.
+func (k *KeyWrapper) ToObject() *Object {
+	return &k.Object
+}
+
+func newKeyWrapper(t *Type, cmp, obj *Object) *KeyWrapper {
+	return &KeyWrapper{Object: Object{typ: t}, cmp: cmp, obj: obj}
+}
+
+func keyWrapperCall(f *Frame, callable *Object, args Args, _ KWArgs) (*Object, *BaseException) {
+	k := toKeyWrapperUnsafe(callable)
+	if k.obj != nil {
+		return nil, f.RaiseType(TypeErrorType, "'KeyWrapper' object is not callable")
+	}
+	if raised := checkFunctionArgs(f, "K", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	return newKeyWrapper(k.typ, k.cmp, args[0]).ToObject(), nil
+}
+
+func keyWrapperCompare(f *Frame, op binaryOpFunc, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(KeyWrapperType) || toKeyWrapperUnsafe(w).obj == nil || toKeyWrapperUnsafe(v).obj == nil {
+		return nil, f.RaiseType(TypeErrorType, "other argument must be K instance")
+	}
+	x, y := toKeyWrapperUnsafe(v), toKeyWrapperUnsafe(w)
+	r, raised := x.cmp.Call(f, Args{x.obj, y.obj}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return op(f, r, NewInt(0).ToObject())
+}
+
+func keyWrapperEq(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return keyWrapperCompare(f, Eq, v, w)
+}
+
+func keyWrapperGE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return keyWrapperCompare(f, GE, v, w)
+}
+
+func keyWrapperGT(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return keyWrapperCompare(f, GT, v, w)
+}
+
+func keyWrapperGetObj(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "_get_obj", args, KeyWrapperType); raised != nil {
+		return nil, raised
+	}
+	k := toKeyWrapperUnsafe(args[0])
+	if k.obj == nil {
+		return nil, f.RaiseType(AttributeErrorType, "obj")
+	}
+	return k.obj, nil
+}
+
+func keyWrapperLE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return keyWrapperCompare(f, LE, v, w)
+}
+
+func keyWrapperLT(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return keyWrapperCompare(f, LT, v, w)
+}
+
+func keyWrapperNE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return keyWrapperCompare(f, NE, v, w)
+}
+
+func keyWrapperNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "cmp_to_key", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if len(kwargs) > 0 {
+		return nil, f.RaiseType(TypeErrorType, "cmp_to_key() does not take keyword arguments")
+	}
+	return newKeyWrapper(t, args[0], nil).ToObject(), nil
+}
+
+func initKeyWrapperType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("functools").ToObject()
+	dict["obj"] = newProperty(newBuiltinFunction("_get_obj", keyWrapperGetObj).ToObject(), nil, nil).ToObject()
+	KeyWrapperType.flags &^= typeFlagBasetype
+	KeyWrapperType.slots.Call = &callSlot{keyWrapperCall}
+	KeyWrapperType.slots.Eq = &binaryOpSlot{keyWrapperEq}
+	KeyWrapperType.slots.GE = &binaryOpSlot{keyWrapperGE}
+	KeyWrapperType.slots.GT = &binaryOpSlot{keyWrapperGT}
+	KeyWrapperType.slots.Hash = &unaryOpSlot{hashNotImplemented}
+	KeyWrapperType.slots.LE = &binaryOpSlot{keyWrapperLE}
+	KeyWrapperType.slots.LT = &binaryOpSlot{keyWrapperLT}
+	KeyWrapperType.slots.NE = &binaryOpSlot{keyWrapperNE}
+	KeyWrapperType.slots.New = &newSlot{keyWrapperNew}
+}
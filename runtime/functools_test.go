@@ -0,0 +1,152 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestPartialCall(t *testing.T) {
+	capture := newBuiltinFunction("capture", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		return newTestTuple(NewTuple(args.makeCopy()...), kwargs.makeDict()).ToObject(), nil
+	}).ToObject()
+	fun := wrapFuncForTest(func(f *Frame, partialArgs *Tuple, partialKWArgs *Dict, args *Tuple, kwargs *Dict) (*Object, *BaseException) {
+		p, raised := Invoke(f, PartialType.ToObject(), partialArgs.elems, nil, nil, partialKWArgs.ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		return Invoke(f, p, args.elems, nil, nil, kwargs.ToObject())
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTuple(capture), NewDict(), NewTuple(), NewDict()), want: newTestTuple(NewTuple(), NewDict()).ToObject()},
+		{args: wrapArgs(newTestTuple(capture, 1, 2), NewDict(), newTestTuple(3), NewDict()), want: newTestTuple(newTestTuple(1, 2, 3), NewDict()).ToObject()},
+		{args: wrapArgs(newTestTuple(capture), newTestDict("a", 1), NewTuple(), newTestDict("b", 2)), want: newTestTuple(NewTuple(), newTestDict("a", 1, "b", 2)).ToObject()},
+		{args: wrapArgs(newTestTuple(capture), newTestDict("a", 1), NewTuple(), newTestDict("a", 2)), want: newTestTuple(NewTuple(), newTestDict("a", 2)).ToObject()},
+		{args: wrapArgs(NewTuple(), NewDict(), NewTuple(), NewDict()), wantExc: mustCreateException(TypeErrorType, "type 'partial' takes at least one argument")},
+		{args: wrapArgs(newTestTuple(123), NewDict(), NewTuple(), NewDict()), wantExc: mustCreateException(TypeErrorType, "the first argument must be callable")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPartialAttrs(t *testing.T) {
+	lenFunc := mustNotRaise(Builtins.GetItemString(NewRootFrame(), "len"))
+	fun := wrapFuncForTest(func(f *Frame, name string) (*Object, *BaseException) {
+		p, raised := PartialType.Call(f, wrapArgs(lenFunc, "abc"), wrapKWArgs("foo", 1))
+		if raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, p, NewStr(name), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("func"), want: lenFunc},
+		{args: wrapArgs("args"), want: newTestTuple("abc").ToObject()},
+		{args: wrapArgs("keywords"), want: newTestDict("foo", 1).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPartialSetState(t *testing.T) {
+	lenFunc := mustNotRaise(Builtins.GetItemString(NewRootFrame(), "len"))
+	fun := wrapFuncForTest(func(f *Frame, state *Object) (*Object, *BaseException) {
+		p, raised := PartialType.Call(f, wrapArgs(lenFunc), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		setState, raised := GetAttr(f, p, NewStr("__setstate__"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if _, raised := setState.Call(f, Args{state}, nil); raised != nil {
+			return nil, raised
+		}
+		return p.Call(f, nil, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTuple(lenFunc, newTestTuple("abc"), NewDict(), None)), want: NewInt(3).ToObject()},
+		{args: wrapArgs(newTestTuple(lenFunc, newTestTuple("abc"), None, NewDict())), want: NewInt(3).ToObject()},
+		{args: wrapArgs(newTestTuple(lenFunc, newTestTuple("abc"))), wantExc: mustCreateException(TypeErrorType, "invalid partial state")},
+		{args: wrapArgs(newTestTuple(123, NewTuple(), NewDict(), None)), wantExc: mustCreateException(TypeErrorType, "invalid partial state")},
+		{args: wrapArgs(newTestTuple(lenFunc, NewList(), NewDict(), None)), wantExc: mustCreateException(TypeErrorType, "invalid partial state")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestKeyWrapperCompare(t *testing.T) {
+	f := NewRootFrame()
+	cmpFunc := mustNotRaise(Builtins.GetItemString(f, "cmp"))
+	reversedCmp := wrapFuncForTest(func(f *Frame, a, b *Object) (*Object, *BaseException) {
+		return cmpFunc.Call(f, Args{b, a}, nil)
+	})
+	key := mustNotRaise(KeyWrapperType.Call(f, Args{cmpFunc}, nil))
+	reversedKey := mustNotRaise(KeyWrapperType.Call(f, Args{reversedCmp}, nil))
+	wrap := func(k *Object, o interface{}) *Object {
+		return mustNotRaise(k.Call(f, wrapArgs(o), nil))
+	}
+	cases := []invokeTestCase{
+		{args: wrapArgs(wrap(key, 1), wrap(key, 1)), want: compareAllResultEq},
+		{args: wrapArgs(wrap(key, 1), wrap(key, 2)), want: compareAllResultLT},
+		{args: wrapArgs(wrap(key, "b"), wrap(key, "a")), want: compareAllResultGT},
+		{args: wrapArgs(wrap(reversedKey, 1), wrap(reversedKey, 2)), want: compareAllResultGT},
+		{args: wrapArgs(wrap(key, 1), 1), wantExc: mustCreateException(TypeErrorType, "other argument must be K instance")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(compareAll, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestKeyWrapperCall(t *testing.T) {
+	lenFunc := mustNotRaise(Builtins.GetItemString(NewRootFrame(), "len"))
+	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		key, raised := KeyWrapperType.Call(f, wrapArgs(lenFunc), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		k, raised := key.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, k, NewStr("obj"), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("foo"), want: NewStr("foo").ToObject()},
+		{wantExc: mustCreateException(TypeErrorType, "'K' requires 1 arguments")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	cas := invokeTestCase{wantExc: mustCreateException(TypeErrorType, "'cmp_to_key' requires 1 arguments")}
+	if err := runInvokeTestCase(KeyWrapperType.ToObject(), &cas); err != "" {
+		t.Error(err)
+	}
+	if _, raised := Hash(NewRootFrame(), mustNotRaise(KeyWrapperType.Call(NewRootFrame(), wrapArgs(lenFunc), nil))); raised == nil || !raised.isInstance(TypeErrorType) {
+		t.Errorf("hash(cmp_to_key(len)) raised %v, want TypeError", raised)
+	}
+}
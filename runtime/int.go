@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"math/bits"
 	"reflect"
 	"strconv"
 )
@@ -86,6 +87,19 @@ func intAnd(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return NewInt(toIntUnsafe(v).Value() & toIntUnsafe(w).Value()).ToObject(), nil
 }
 
+func intBitLength(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "bit_length", args, IntType); raised != nil {
+		return nil, raised
+	}
+	i := toIntUnsafe(args[0]).Value()
+	if i < 0 {
+		// Negating MinInt overflows but the uint conversion still
+		// yields its magnitude.
+		return NewInt(bits.Len(uint(-i))).ToObject(), nil
+	}
+	return NewInt(bits.Len(uint(i))).ToObject(), nil
+}
+
 func intDiv(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return intDivModOp(f, "__div__", v, w, intCheckedDiv, longDiv)
 }
@@ -106,7 +120,7 @@ func intGetNewArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "__getnewargs__", args, IntType); raised != nil {
 		return nil, raised
 	}
-	return NewTuple1(args[0]).ToObject(), nil
+	return NewTuple1(NewInt(toIntUnsafe(args[0]).Value()).ToObject()).ToObject(), nil
 }
 
 func intGT(f *Frame, v, w *Object) (*Object, *BaseException) {
@@ -212,13 +226,17 @@ func intNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 	if len(args) > 2 {
 		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("int() takes at most 2 arguments (%d given)", len(args)))
 	}
-	if !o.isInstance(StrType) {
+	if !o.isInstance(BaseStringType) {
 		if len(args) == 2 {
 			return nil, f.RaiseType(TypeErrorType, "int() can't convert non-string with explicit base")
 		}
 		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("int() argument must be a string or a number, not '%s'", o.typ.Name()))
 	}
-	s := toStrUnsafe(o).Value()
+	str, raised := basestringToStr(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	s := str.Value()
 	base := 10
 	if len(args) == 2 {
 		var raised *BaseException
@@ -378,6 +396,7 @@ func intXor(f *Frame, v, w *Object) (*Object, *BaseException) {
 
 func initIntType(dict map[string]*Object) {
 	dict["__getnewargs__"] = newBuiltinFunction("__getnewargs__", intGetNewArgs).ToObject()
+	dict["bit_length"] = newBuiltinFunction("bit_length", intBitLength).ToObject()
 	IntType.slots.Abs = &unaryOpSlot{intAbs}
 	IntType.slots.Add = &binaryOpSlot{intAdd}
 	IntType.slots.And = &binaryOpSlot{intAnd}
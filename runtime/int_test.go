@@ -125,6 +125,24 @@ func TestIntInvert(t *testing.T) {
 	}
 }
 
+func TestIntBitLength(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(0), want: NewInt(0).ToObject()},
+		{args: wrapArgs(1), want: NewInt(1).ToObject()},
+		{args: wrapArgs(-1), want: NewInt(1).ToObject()},
+		{args: wrapArgs(255), want: NewInt(8).ToObject()},
+		{args: wrapArgs(-256), want: NewInt(9).ToObject()},
+		{args: wrapArgs(MaxInt), want: NewInt(maxIntBig.BitLen()).ToObject()},
+		{args: wrapArgs(MinInt), want: NewInt(minIntBig.BitLen()).ToObject()},
+		{args: wrapArgs(1, 2), wantExc: mustCreateException(TypeErrorType, "'bit_length' of 'int' requires 1 arguments")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(IntType, "bit_length", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestIntNew(t *testing.T) {
 	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
 		"__int__": newBuiltinFunction("__int__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -155,6 +173,8 @@ func TestIntNew(t *testing.T) {
 		{args: wrapArgs(IntType, " \t123"), want: NewInt(123).ToObject()},
 		{args: wrapArgs(IntType, "123 \t"), want: NewInt(123).ToObject()},
 		{args: wrapArgs(IntType, "FF", 16), want: NewInt(255).ToObject()},
+		{args: wrapArgs(IntType, NewUnicode(" 123")), want: NewInt(123).ToObject()},
+		{args: wrapArgs(IntType, NewUnicode("ff"), 16), want: NewInt(255).ToObject()},
 		{args: wrapArgs(IntType, "0xFF", 16), want: NewInt(255).ToObject()},
 		{args: wrapArgs(IntType, "0xE", 0), want: NewInt(14).ToObject()},
 		{args: wrapArgs(IntType, "0b101", 0), want: NewInt(5).ToObject()},
@@ -0,0 +1,1077 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ChainType is the object representing the Python 'itertools.chain'
+	// type.
+	ChainType = newBasisType("chain", reflect.TypeOf(chain{}), toChainUnsafe, ObjectType)
+	// CombinationsType is the object representing the Python
+	// 'itertools.combinations' type.
+	CombinationsType = newBasisType("combinations", reflect.TypeOf(combinations{}), toCombinationsUnsafe, ObjectType)
+	// CountType is the object representing the Python 'itertools.count'
+	// type.
+	CountType = newBasisType("count", reflect.TypeOf(count{}), toCountUnsafe, ObjectType)
+	// CycleType is the object representing the Python 'itertools.cycle'
+	// type.
+	CycleType = newBasisType("cycle", reflect.TypeOf(cycle{}), toCycleUnsafe, ObjectType)
+	// GroupByType is the object representing the Python
+	// 'itertools.groupby' type.
+	GroupByType = newBasisType("groupby", reflect.TypeOf(groupBy{}), toGroupByUnsafe, ObjectType)
+	grouperType = newBasisType("_grouper", reflect.TypeOf(grouper{}), toGrouperUnsafe, ObjectType)
+	// ISliceType is the object representing the Python 'itertools.islice'
+	// type.
+	ISliceType = newBasisType("islice", reflect.TypeOf(iSlice{}), toISliceUnsafe, ObjectType)
+	// IZipType is the object representing the Python 'itertools.izip'
+	// type.
+	IZipType = newBasisType("izip", reflect.TypeOf(iZip{}), toIZipUnsafe, ObjectType)
+	// PermutationsType is the object representing the Python
+	// 'itertools.permutations' type.
+	PermutationsType = newBasisType("permutations", reflect.TypeOf(permutations{}), toPermutationsUnsafe, ObjectType)
+	// ProductType is the object representing the Python
+	// 'itertools.product' type.
+	ProductType = newBasisType("product", reflect.TypeOf(product{}), toProductUnsafe, ObjectType)
+	// RepeatType is the object representing the Python 'itertools.repeat'
+	// type.
+	RepeatType = newBasisType("repeat", reflect.TypeOf(repeat{}), toRepeatUnsafe, ObjectType)
+	// StarMapType is the object representing the Python
+	// 'itertools.starmap' type.
+	StarMapType = newBasisType("starmap", reflect.TypeOf(starMap{}), toStarMapUnsafe, ObjectType)
+	// TeeType is the object representing the Python 'itertools.tee'
+	// type, one of the independent iterators returned by tee().
+	TeeType = newBasisType("tee", reflect.TypeOf(tee{}), toTeeUnsafe, ObjectType)
+
+	combinationsParamSpec = NewParamSpec("combinations", []Param{{"iterable", nil}, {"r", nil}}, false, false)
+	countParamSpec        = NewParamSpec("count", []Param{{"start", NewInt(0).ToObject()}, {"step", NewInt(1).ToObject()}}, false, false)
+	groupByParamSpec      = NewParamSpec("groupby", []Param{{"iterable", nil}, {"key", None}}, false, false)
+	permutationsParamSpec = NewParamSpec("permutations", []Param{{"iterable", nil}, {"r", None}}, false, false)
+	repeatParamSpec       = NewParamSpec("repeat", []Param{{"object", nil}, {"times", None}}, false, false)
+)
+
+// iterToolsDict returns the instance dict for a new object of type t, which
+// is only needed when t is a subclass of the builtin type base.
+func iterToolsDict(t, base *Type) *Dict {
+	if t == base {
+		return nil
+	}
+	return NewDict()
+}
+
+func iterToolsNoKeywords(f *Frame, name string, kwargs KWArgs) *BaseException {
+	if len(kwargs) != 0 {
+		return f.RaiseType(TypeErrorType, fmt.Sprintf("%s() does not take keyword arguments", name))
+	}
+	return nil
+}
+
+// iterToolsNext returns the next item of iter or nil when it's exhausted.
+func iterToolsNext(f *Frame, iter *Object) (*Object, *BaseException) {
+	item, raised := Next(f, iter)
+	if raised != nil {
+		if raised.isInstance(StopIterationType) {
+			f.RestoreExc(nil, nil)
+			return nil, nil
+		}
+		return nil, raised
+	}
+	return item, nil
+}
+
+// iterToolsPool returns the items of iterable and the number of items to be
+// drawn from them according to r, for combinations and permutations.
+func iterToolsPool(f *Frame, iterable, r *Object) ([]*Object, int, *BaseException) {
+	pool, raised := TupleType.Call(f, Args{iterable}, nil)
+	if raised != nil {
+		return nil, 0, raised
+	}
+	elems := toTupleUnsafe(pool).elems
+	if r == None {
+		return elems, len(elems), nil
+	}
+	n, raised := IndexInt(f, r)
+	if raised != nil {
+		return nil, 0, raised
+	}
+	if n < 0 {
+		return nil, 0, f.RaiseType(ValueErrorType, "r must be non-negative")
+	}
+	return elems, n, nil
+}
+
+func iterToolsIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+type chain struct {
+	Object
+	mutex sync.Mutex
+	// source yields the iterables to chain together and active is the
+	// iterator currently being drained. source is nil once exhausted.
+	source *Object
+	active *Object
+}
+
+func toChainUnsafe(o *Object) *chain {
+	return (*chain)(o.toPointer())
+}
+
+func newChain(t *Type, source *Object) *Object {
+	c := &chain{Object: Object{typ: t, dict: iterToolsDict(t, ChainType)}, source: source}
+	return &c.Object
+}
+
+func (c *chain) next(f *Frame) (*Object, *BaseException) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for c.source != nil {
+		if c.active == nil {
+			iterable, raised := iterToolsNext(f, c.source)
+			if raised != nil {
+				return nil, raised
+			}
+			if iterable == nil {
+				c.source = nil
+				break
+			}
+			if c.active, raised = Iter(f, iterable); raised != nil {
+				return nil, raised
+			}
+		}
+		item, raised := iterToolsNext(f, c.active)
+		if raised != nil || item != nil {
+			return item, raised
+		}
+		c.active = nil
+	}
+	return nil, nil
+}
+
+func chainFromIterable(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "from_iterable", args, TypeType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	source, raised := Iter(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	return newChain(toTypeUnsafe(args[0]), source), nil
+}
+
+func chainNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := iterToolsNoKeywords(f, "chain", kwargs); raised != nil {
+		return nil, raised
+	}
+	source, raised := Iter(f, NewTuple(args.makeCopy()...).ToObject())
+	if raised != nil {
+		return nil, raised
+	}
+	return newChain(t, source), nil
+}
+
+func chainNext(f *Frame, o *Object) (*Object, *BaseException) {
+	item, raised := toChainUnsafe(o).next(f)
+	if raised == nil && item == nil {
+		raised = f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	return item, raised
+}
+
+func initChainType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	dict["from_iterable"] = newClassMethod(newBuiltinFunction("from_iterable", chainFromIterable).ToObject()).ToObject()
+	ChainType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	ChainType.slots.Next = &unaryOpSlot{chainNext}
+	ChainType.slots.New = &newSlot{chainNew}
+}
+
+type combinations struct {
+	Object
+	mutex   sync.Mutex
+	pool    []*Object
+	indices []int
+	// started is set after the first combination has been produced and
+	// done once all of them have.
+	started bool
+	done    bool
+}
+
+func toCombinationsUnsafe(o *Object) *combinations {
+	return (*combinations)(o.toPointer())
+}
+
+func combinationsNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := combinationsParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	pool, r, raised := iterToolsPool(f, validated[0], validated[1])
+	if raised != nil {
+		return nil, raised
+	}
+	indices := make([]int, r)
+	for i := range indices {
+		indices[i] = i
+	}
+	c := &combinations{Object: Object{typ: t, dict: iterToolsDict(t, CombinationsType)}, pool: pool, indices: indices, done: r > len(pool)}
+	return &c.Object, nil
+}
+
+func combinationsNext(f *Frame, o *Object) (*Object, *BaseException) {
+	c := toCombinationsUnsafe(o)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	n, r := len(c.pool), len(c.indices)
+	if c.started && !c.done {
+		// Find the rightmost index that isn't at its maximum, advance
+		// it and reset the indices to its right to follow on from it.
+		i := r - 1
+		for i >= 0 && c.indices[i] == i+n-r {
+			i--
+		}
+		if i < 0 {
+			c.done = true
+		} else {
+			c.indices[i]++
+			for j := i + 1; j < r; j++ {
+				c.indices[j] = c.indices[j-1] + 1
+			}
+		}
+	}
+	c.started = true
+	if c.done {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	elems := make([]*Object, r)
+	for i, index := range c.indices {
+		elems[i] = c.pool[index]
+	}
+	return NewTuple(elems...).ToObject(), nil
+}
+
+func initCombinationsType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	CombinationsType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	CombinationsType.slots.Next = &unaryOpSlot{combinationsNext}
+	CombinationsType.slots.New = &newSlot{combinationsNew}
+}
+
+type count struct {
+	Object
+	mutex sync.Mutex
+	value *Object
+	step  *Object
+}
+
+func toCountUnsafe(o *Object) *count {
+	return (*count)(o.toPointer())
+}
+
+func countNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := countParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	for _, o := range validated {
+		if o.typ.slots.Int == nil && o.typ.slots.Float == nil {
+			return nil, f.RaiseType(TypeErrorType, "a number is required")
+		}
+	}
+	c := &count{Object: Object{typ: t, dict: iterToolsDict(t, CountType)}, value: validated[0], step: validated[1]}
+	return &c.Object, nil
+}
+
+func countNext(f *Frame, o *Object) (*Object, *BaseException) {
+	c := toCountUnsafe(o)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	next, raised := Add(f, c.value, c.step)
+	if raised != nil {
+		return nil, raised
+	}
+	item := c.value
+	c.value = next
+	return item, nil
+}
+
+func countRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	c := toCountUnsafe(o)
+	c.mutex.Lock()
+	value, step := c.value, c.step
+	c.mutex.Unlock()
+	s, raised := Repr(f, value)
+	if raised != nil {
+		return nil, raised
+	}
+	if step.typ == IntType && toIntUnsafe(step).Value() == 1 {
+		return NewStr(fmt.Sprintf("count(%s)", s.Value())).ToObject(), nil
+	}
+	stepRepr, raised := Repr(f, step)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewStr(fmt.Sprintf("count(%s, %s)", s.Value(), stepRepr.Value())).ToObject(), nil
+}
+
+func initCountType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	CountType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	CountType.slots.Next = &unaryOpSlot{countNext}
+	CountType.slots.New = &newSlot{countNew}
+	CountType.slots.Repr = &unaryOpSlot{countRepr}
+}
+
+type cycle struct {
+	Object
+	mutex sync.Mutex
+	// iter is nil once the first pass over the iterable is complete, after
+	// which saved is replayed from index onward.
+	iter  *Object
+	saved []*Object
+	index int
+}
+
+func toCycleUnsafe(o *Object) *cycle {
+	return (*cycle)(o.toPointer())
+}
+
+func cycleNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := iterToolsNoKeywords(f, "cycle", kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkFunctionArgs(f, "cycle", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	iter, raised := Iter(f, args[0])
+	if raised != nil {
+		return nil, raised
+	}
+	c := &cycle{Object: Object{typ: t, dict: iterToolsDict(t, CycleType)}, iter: iter}
+	return &c.Object, nil
+}
+
+func cycleNext(f *Frame, o *Object) (*Object, *BaseException) {
+	c := toCycleUnsafe(o)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.iter != nil {
+		item, raised := iterToolsNext(f, c.iter)
+		if raised != nil {
+			return nil, raised
+		}
+		if item != nil {
+			c.saved = append(c.saved, item)
+			return item, nil
+		}
+		c.iter = nil
+	}
+	if len(c.saved) == 0 {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	item := c.saved[c.index]
+	c.index = (c.index + 1) % len(c.saved)
+	return item, nil
+}
+
+func initCycleType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	CycleType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	CycleType.slots.Next = &unaryOpSlot{cycleNext}
+	CycleType.slots.New = &newSlot{cycleNew}
+}
+
+// groupBy yields pairs of keys and _grouper iterators over runs of
+// consecutive items with equal keys. Advancing the groupby skips the rest of
+// the current group so groupers share the underlying iterator with it.
+type groupBy struct {
+	Object
+	mutex   sync.Mutex
+	iter    *Object
+	keyFunc *Object
+	// tgtKey is the key of the group most recently returned. currKey and
+	// currValue are the key and value of the last item read from iter,
+	// with currValue nil once that item has been consumed by a grouper.
+	tgtKey    *Object
+	currKey   *Object
+	currValue *Object
+}
+
+func toGroupByUnsafe(o *Object) *groupBy {
+	return (*groupBy)(o.toPointer())
+}
+
+// advance reads the next item from g.iter and computes its key. It returns
+// false when g.iter is exhausted.
+func (g *groupBy) advance(f *Frame) (bool, *BaseException) {
+	value, raised := iterToolsNext(f, g.iter)
+	if raised != nil || value == nil {
+		return false, raised
+	}
+	key := value
+	if g.keyFunc != None {
+		if key, raised = g.keyFunc.Call(f, Args{value}, nil); raised != nil {
+			return false, raised
+		}
+	}
+	g.currKey, g.currValue = key, value
+	return true, nil
+}
+
+func groupByKeysEqual(f *Frame, v, w *Object) (bool, *BaseException) {
+	if v == w {
+		return true, nil
+	}
+	eq, raised := Eq(f, v, w)
+	if raised != nil {
+		return false, raised
+	}
+	return IsTrue(f, eq)
+}
+
+func groupByNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := groupByParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	iter, raised := Iter(f, validated[0])
+	if raised != nil {
+		return nil, raised
+	}
+	g := &groupBy{Object: Object{typ: t, dict: iterToolsDict(t, GroupByType)}, iter: iter, keyFunc: validated[1]}
+	return &g.Object, nil
+}
+
+func groupByNext(f *Frame, o *Object) (*Object, *BaseException) {
+	g := toGroupByUnsafe(o)
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	// Skip the remainder of the current group.
+	for g.currKey == nil || g.tgtKey != nil {
+		if g.currKey != nil {
+			eq, raised := groupByKeysEqual(f, g.tgtKey, g.currKey)
+			if raised != nil {
+				return nil, raised
+			}
+			if !eq {
+				break
+			}
+		}
+		ok, raised := g.advance(f)
+		if raised != nil {
+			return nil, raised
+		}
+		if !ok {
+			return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+		}
+	}
+	g.tgtKey = g.currKey
+	r := &grouper{Object: Object{typ: grouperType}, parent: g, tgtKey: g.tgtKey}
+	return NewTuple2(g.currKey, &r.Object).ToObject(), nil
+}
+
+func initGroupByType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	GroupByType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	GroupByType.slots.Next = &unaryOpSlot{groupByNext}
+	GroupByType.slots.New = &newSlot{groupByNew}
+}
+
+type grouper struct {
+	Object
+	parent *groupBy
+	tgtKey *Object
+}
+
+func toGrouperUnsafe(o *Object) *grouper {
+	return (*grouper)(o.toPointer())
+}
+
+func grouperNext(f *Frame, o *Object) (*Object, *BaseException) {
+	r := toGrouperUnsafe(o)
+	g := r.parent
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.currValue == nil {
+		ok, raised := g.advance(f)
+		if raised != nil {
+			return nil, raised
+		}
+		if !ok {
+			return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+		}
+	}
+	eq, raised := groupByKeysEqual(f, r.tgtKey, g.currKey)
+	if raised != nil {
+		return nil, raised
+	}
+	if !eq {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	item := g.currValue
+	g.currValue = nil
+	return item, nil
+}
+
+func initGrouperType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	grouperType.flags &^= typeFlagInstantiable | typeFlagBasetype
+	grouperType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	grouperType.slots.Next = &unaryOpSlot{grouperNext}
+}
+
+type iSlice struct {
+	Object
+	mutex sync.Mutex
+	iter  *Object
+	// next is the position of the next item to yield, consumed is the
+	// number of items read from iter so far and stop is -1 when there's
+	// no upper bound.
+	next     int
+	consumed int
+	stop     int
+	step     int
+}
+
+func toISliceUnsafe(o *Object) *iSlice {
+	return (*iSlice)(o.toPointer())
+}
+
+// iSliceIndex converts an islice argument to a non-negative int, returning
+// def if o is None.
+func iSliceIndex(f *Frame, o *Object, def int, msg string) (int, *BaseException) {
+	if o == None {
+		return def, nil
+	}
+	if o.typ.slots.Index != nil {
+		if i, raised := IndexInt(f, o); raised == nil && i >= 0 {
+			return i, nil
+		}
+		f.RestoreExc(nil, nil)
+	}
+	return 0, f.RaiseType(ValueErrorType, msg)
+}
+
+func iSliceNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := iterToolsNoKeywords(f, "islice", kwargs); raised != nil {
+		return nil, raised
+	}
+	argc := len(args)
+	if argc < 2 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("islice expected at least 2 arguments, got %d", argc))
+	}
+	if argc > 4 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("islice expected at most 4 arguments, got %d", argc))
+	}
+	start, step := 0, 1
+	var stop int
+	var raised *BaseException
+	if argc == 2 {
+		if stop, raised = iSliceIndex(f, args[1], -1, "Stop argument for islice() must be None or an integer: 0 <= x <= maxint."); raised != nil {
+			return nil, raised
+		}
+	} else {
+		const msg = "Indices for islice() must be None or an integer: 0 <= x <= maxint."
+		if start, raised = iSliceIndex(f, args[1], 0, msg); raised != nil {
+			return nil, raised
+		}
+		if stop, raised = iSliceIndex(f, args[2], -1, msg); raised != nil {
+			return nil, raised
+		}
+		if argc == 4 {
+			const stepMsg = "Step for islice() must be a positive integer or None."
+			if step, raised = iSliceIndex(f, args[3], 1, stepMsg); raised != nil {
+				return nil, raised
+			}
+			if step == 0 {
+				return nil, f.RaiseType(ValueErrorType, stepMsg)
+			}
+		}
+	}
+	iter, raised := Iter(f, args[0])
+	if raised != nil {
+		return nil, raised
+	}
+	s := &iSlice{Object: Object{typ: t, dict: iterToolsDict(t, ISliceType)}, iter: iter, next: start, stop: stop, step: step}
+	return &s.Object, nil
+}
+
+func iSliceNext(f *Frame, o *Object) (*Object, *BaseException) {
+	s := toISliceUnsafe(o)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for s.consumed < s.next {
+		if _, raised := Next(f, s.iter); raised != nil {
+			return nil, raised
+		}
+		s.consumed++
+	}
+	if s.stop != -1 && s.consumed >= s.stop {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	item, raised := Next(f, s.iter)
+	if raised != nil {
+		return nil, raised
+	}
+	s.consumed++
+	prev := s.next
+	s.next += s.step
+	if s.next < prev || (s.stop != -1 && s.next > s.stop) {
+		s.next = s.stop
+	}
+	return item, nil
+}
+
+func initISliceType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	ISliceType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	ISliceType.slots.Next = &unaryOpSlot{iSliceNext}
+	ISliceType.slots.New = &newSlot{iSliceNew}
+}
+
+type iZip struct {
+	Object
+	mutex sync.Mutex
+	iters []*Object
+}
+
+func toIZipUnsafe(o *Object) *iZip {
+	return (*iZip)(o.toPointer())
+}
+
+func iZipNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := iterToolsNoKeywords(f, "izip", kwargs); raised != nil {
+		return nil, raised
+	}
+	iters := make([]*Object, len(args))
+	for i, arg := range args {
+		iter, raised := Iter(f, arg)
+		if raised != nil {
+			if raised.isInstance(TypeErrorType) {
+				f.RestoreExc(nil, nil)
+				raised = f.RaiseType(TypeErrorType, fmt.Sprintf("izip argument #%d must support iteration", i+1))
+			}
+			return nil, raised
+		}
+		iters[i] = iter
+	}
+	z := &iZip{Object: Object{typ: t, dict: iterToolsDict(t, IZipType)}, iters: iters}
+	return &z.Object, nil
+}
+
+func iZipNext(f *Frame, o *Object) (*Object, *BaseException) {
+	z := toIZipUnsafe(o)
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+	if len(z.iters) == 0 {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	elems := make([]*Object, len(z.iters))
+	for i, iter := range z.iters {
+		item, raised := Next(f, iter)
+		if raised != nil {
+			return nil, raised
+		}
+		elems[i] = item
+	}
+	return NewTuple(elems...).ToObject(), nil
+}
+
+func initIZipType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	IZipType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	IZipType.slots.Next = &unaryOpSlot{iZipNext}
+	IZipType.slots.New = &newSlot{iZipNew}
+}
+
+// permutations generates its results in lexicographic order of the indices
+// into pool using the same cycle counting algorithm as CPython.
+type permutations struct {
+	Object
+	mutex   sync.Mutex
+	pool    []*Object
+	r       int
+	indices []int
+	cycles  []int
+	started bool
+	done    bool
+}
+
+func toPermutationsUnsafe(o *Object) *permutations {
+	return (*permutations)(o.toPointer())
+}
+
+func permutationsNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := permutationsParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	pool, r, raised := iterToolsPool(f, validated[0], validated[1])
+	if raised != nil {
+		return nil, raised
+	}
+	n := len(pool)
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	var cycles []int
+	if r <= n {
+		cycles = make([]int, r)
+		for i := range cycles {
+			cycles[i] = n - i
+		}
+	}
+	p := &permutations{Object: Object{typ: t, dict: iterToolsDict(t, PermutationsType)}, pool: pool, r: r, indices: indices, cycles: cycles, done: r > n}
+	return &p.Object, nil
+}
+
+func (p *permutations) advance() {
+	n, r := len(p.pool), p.r
+	if n == 0 {
+		p.done = true
+		return
+	}
+	for i := r - 1; i >= 0; i-- {
+		p.cycles[i]--
+		if p.cycles[i] == 0 {
+			// Rotate indices[i:] left by one.
+			index := p.indices[i]
+			copy(p.indices[i:], p.indices[i+1:])
+			p.indices[n-1] = index
+			p.cycles[i] = n - i
+		} else {
+			j := n - p.cycles[i]
+			p.indices[i], p.indices[j] = p.indices[j], p.indices[i]
+			return
+		}
+	}
+	p.done = true
+}
+
+func permutationsNext(f *Frame, o *Object) (*Object, *BaseException) {
+	p := toPermutationsUnsafe(o)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.started && !p.done {
+		p.advance()
+	}
+	p.started = true
+	if p.done {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	elems := make([]*Object, p.r)
+	for i := range elems {
+		elems[i] = p.pool[p.indices[i]]
+	}
+	return NewTuple(elems...).ToObject(), nil
+}
+
+func initPermutationsType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	PermutationsType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	PermutationsType.slots.Next = &unaryOpSlot{permutationsNext}
+	PermutationsType.slots.New = &newSlot{permutationsNew}
+}
+
+type product struct {
+	Object
+	mutex   sync.Mutex
+	pools   [][]*Object
+	indices []int
+	started bool
+	done    bool
+}
+
+func toProductUnsafe(o *Object) *product {
+	return (*product)(o.toPointer())
+}
+
+func productNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	repeat := 1
+	for _, kwarg := range kwargs {
+		if kwarg.Name != "repeat" {
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("'%s' is an invalid keyword argument for this function", kwarg.Name))
+		}
+		var raised *BaseException
+		if repeat, raised = IndexInt(f, kwarg.Value); raised != nil {
+			return nil, raised
+		}
+		if repeat < 0 {
+			return nil, f.RaiseType(ValueErrorType, "repeat argument cannot be negative")
+		}
+	}
+	argPools := make([][]*Object, len(args))
+	for i, arg := range args {
+		pool, raised := TupleType.Call(f, Args{arg}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		argPools[i] = toTupleUnsafe(pool).elems
+	}
+	pools := make([][]*Object, 0, len(args)*repeat)
+	for i := 0; i < repeat; i++ {
+		pools = append(pools, argPools...)
+	}
+	done := false
+	for _, pool := range pools {
+		if len(pool) == 0 {
+			done = true
+		}
+	}
+	p := &product{Object: Object{typ: t, dict: iterToolsDict(t, ProductType)}, pools: pools, indices: make([]int, len(pools)), done: done}
+	return &p.Object, nil
+}
+
+func productNext(f *Frame, o *Object) (*Object, *BaseException) {
+	p := toProductUnsafe(o)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.started && !p.done {
+		// Advance the indices like an odometer, rightmost first.
+		i := len(p.pools) - 1
+		for ; i >= 0; i-- {
+			p.indices[i]++
+			if p.indices[i] < len(p.pools[i]) {
+				break
+			}
+			p.indices[i] = 0
+		}
+		p.done = i < 0
+	}
+	p.started = true
+	if p.done {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	elems := make([]*Object, len(p.pools))
+	for i, pool := range p.pools {
+		elems[i] = pool[p.indices[i]]
+	}
+	return NewTuple(elems...).ToObject(), nil
+}
+
+func initProductType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	ProductType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	ProductType.slots.Next = &unaryOpSlot{productNext}
+	ProductType.slots.New = &newSlot{productNew}
+}
+
+type repeat struct {
+	Object
+	mutex sync.Mutex
+	elem  *Object
+	// remaining is -1 when elem is repeated indefinitely.
+	remaining int
+}
+
+func toRepeatUnsafe(o *Object) *repeat {
+	return (*repeat)(o.toPointer())
+}
+
+func repeatNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := repeatParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	remaining := -1
+	if validated[1] != None {
+		var raised *BaseException
+		if remaining, raised = IndexInt(f, validated[1]); raised != nil {
+			return nil, raised
+		}
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+	r := &repeat{Object: Object{typ: t, dict: iterToolsDict(t, RepeatType)}, elem: validated[0], remaining: remaining}
+	return &r.Object, nil
+}
+
+func repeatNext(f *Frame, o *Object) (*Object, *BaseException) {
+	r := toRepeatUnsafe(o)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.remaining == 0 {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	if r.remaining > 0 {
+		r.remaining--
+	}
+	return r.elem, nil
+}
+
+func repeatRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	r := toRepeatUnsafe(o)
+	s, raised := Repr(f, r.elem)
+	if raised != nil {
+		return nil, raised
+	}
+	r.mutex.Lock()
+	remaining := r.remaining
+	r.mutex.Unlock()
+	if remaining == -1 {
+		return NewStr(fmt.Sprintf("repeat(%s)", s.Value())).ToObject(), nil
+	}
+	return NewStr(fmt.Sprintf("repeat(%s, %d)", s.Value(), remaining)).ToObject(), nil
+}
+
+func initRepeatType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	RepeatType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	RepeatType.slots.Next = &unaryOpSlot{repeatNext}
+	RepeatType.slots.New = &newSlot{repeatNew}
+	RepeatType.slots.Repr = &unaryOpSlot{repeatRepr}
+}
+
+type starMap struct {
+	Object
+	fn   *Object
+	iter *Object
+}
+
+func toStarMapUnsafe(o *Object) *starMap {
+	return (*starMap)(o.toPointer())
+}
+
+func starMapNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := iterToolsNoKeywords(f, "starmap", kwargs); raised != nil {
+		return nil, raised
+	}
+	if len(args) != 2 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("starmap expected 2 arguments, got %d", len(args)))
+	}
+	iter, raised := Iter(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	s := &starMap{Object: Object{typ: t, dict: iterToolsDict(t, StarMapType)}, fn: args[0], iter: iter}
+	return &s.Object, nil
+}
+
+func starMapNext(f *Frame, o *Object) (*Object, *BaseException) {
+	s := toStarMapUnsafe(o)
+	item, raised := Next(f, s.iter)
+	if raised != nil {
+		return nil, raised
+	}
+	if !item.isInstance(TupleType) {
+		if item, raised = TupleType.Call(f, Args{item}, nil); raised != nil {
+			return nil, raised
+		}
+	}
+	return s.fn.Call(f, toTupleUnsafe(item).elems, nil)
+}
+
+func initStarMapType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	StarMapType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	StarMapType.slots.Next = &unaryOpSlot{starMapNext}
+	StarMapType.slots.New = &newSlot{starMapNew}
+}
+
+// teeChunkSize is the number of items buffered in each teeChunk.
+const teeChunkSize = 57
+
+// teeSource is the iterator shared by a set of tee iterators.
+type teeSource struct {
+	mutex sync.Mutex
+	iter  *Object
+}
+
+// teeChunk is a node in the linked list of items read from a teeSource. Each
+// tee holds a pointer to the chunk it's reading so chunks that every tee has
+// moved past are garbage collected.
+type teeChunk struct {
+	values [teeChunkSize]*Object
+	n      int
+	next   *teeChunk
+}
+
+type tee struct {
+	Object
+	source *teeSource
+	chunk  *teeChunk
+	index  int
+}
+
+func toTeeUnsafe(o *Object) *tee {
+	return (*tee)(o.toPointer())
+}
+
+func newTee(t *Type, source *teeSource, chunk *teeChunk, index int) *Object {
+	e := &tee{Object: Object{typ: t, dict: iterToolsDict(t, TeeType)}, source: source, chunk: chunk, index: index}
+	return &e.Object
+}
+
+func teeCopy(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__copy__", args, TeeType); raised != nil {
+		return nil, raised
+	}
+	e := toTeeUnsafe(args[0])
+	e.source.mutex.Lock()
+	chunk, index := e.chunk, e.index
+	e.source.mutex.Unlock()
+	return newTee(TeeType, e.source, chunk, index), nil
+}
+
+func teeNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "tee", args, ObjectType); raised != nil {
+		return nil, raised
+	}
+	iter, raised := Iter(f, args[0])
+	if raised != nil {
+		return nil, raised
+	}
+	if iter.isInstance(TeeType) {
+		return teeCopy(f, Args{iter}, nil)
+	}
+	return newTee(t, &teeSource{iter: iter}, &teeChunk{}, 0), nil
+}
+
+func teeNext(f *Frame, o *Object) (*Object, *BaseException) {
+	e := toTeeUnsafe(o)
+	e.source.mutex.Lock()
+	defer e.source.mutex.Unlock()
+	if e.index == teeChunkSize {
+		if e.chunk.next == nil {
+			e.chunk.next = &teeChunk{}
+		}
+		e.chunk, e.index = e.chunk.next, 0
+	}
+	if e.index == e.chunk.n {
+		item, raised := Next(f, e.source.iter)
+		if raised != nil {
+			return nil, raised
+		}
+		e.chunk.values[e.chunk.n] = item
+		e.chunk.n++
+	}
+	item := e.chunk.values[e.index]
+	e.index++
+	return item, nil
+}
+
+func initTeeType(dict map[string]*Object) {
+	dict["__copy__"] = newBuiltinFunction("__copy__", teeCopy).ToObject()
+	dict["__module__"] = NewStr("itertools").ToObject()
+	TeeType.slots.Iter = &unaryOpSlot{iterToolsIter}
+	TeeType.slots.Next = &unaryOpSlot{teeNext}
+	TeeType.slots.New = &newSlot{teeNew}
+}
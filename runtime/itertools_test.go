@@ -0,0 +1,184 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestIterToolsNew(t *testing.T) {
+	add := wrapFuncForTest(func(f *Frame, i, j int) int { return i + j })
+	fun := wrapFuncForTest(func(f *Frame, t *Type, args ...*Object) (*Object, *BaseException) {
+		iter, raised := t.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return TupleType.Call(f, Args{iter}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(ChainType), want: NewTuple().ToObject()},
+		{args: wrapArgs(ChainType, "ab", newTestList(1)), want: newTestTuple("a", "b", 1).ToObject()},
+		{args: wrapArgs(ChainType, newTestList(1), 2), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+		{args: wrapArgs(CombinationsType, "abc", 2), want: newTestTuple(newTestTuple("a", "b"), newTestTuple("a", "c"), newTestTuple("b", "c")).ToObject()},
+		{args: wrapArgs(CombinationsType, "ab", 0), want: newTestTuple(NewTuple()).ToObject()},
+		{args: wrapArgs(CombinationsType, "ab", 3), want: NewTuple().ToObject()},
+		{args: wrapArgs(CombinationsType, "ab", -1), wantExc: mustCreateException(ValueErrorType, "r must be non-negative")},
+		{args: wrapArgs(CycleType, NewTuple()), want: NewTuple().ToObject()},
+		{args: wrapArgs(ISliceType, "abcdef", 1, 5, 2), want: newTestTuple("b", "d").ToObject()},
+		{args: wrapArgs(ISliceType, "abc", None), want: newTestTuple("a", "b", "c").ToObject()},
+		{args: wrapArgs(ISliceType, "abc", -1), wantExc: mustCreateException(ValueErrorType, "Stop argument for islice() must be None or an integer: 0 <= x <= maxint.")},
+		{args: wrapArgs(ISliceType, "abc", 0, 1, 0), wantExc: mustCreateException(ValueErrorType, "Step for islice() must be a positive integer or None.")},
+		{args: wrapArgs(ISliceType, "abc"), wantExc: mustCreateException(TypeErrorType, "islice expected at least 2 arguments, got 1")},
+		{args: wrapArgs(IZipType), want: NewTuple().ToObject()},
+		{args: wrapArgs(IZipType, "ab", newTestList(1, 2, 3)), want: newTestTuple(newTestTuple("a", 1), newTestTuple("b", 2)).ToObject()},
+		{args: wrapArgs(IZipType, "ab", 3), wantExc: mustCreateException(TypeErrorType, "izip argument #2 must support iteration")},
+		{args: wrapArgs(PermutationsType, "abc", 2), want: newTestTuple(newTestTuple("a", "b"), newTestTuple("a", "c"), newTestTuple("b", "a"), newTestTuple("b", "c"), newTestTuple("c", "a"), newTestTuple("c", "b")).ToObject()},
+		{args: wrapArgs(PermutationsType, NewTuple()), want: newTestTuple(NewTuple()).ToObject()},
+		{args: wrapArgs(PermutationsType, "ab", 3), want: NewTuple().ToObject()},
+		{args: wrapArgs(ProductType, "ab", newTestList(1, 2)), want: newTestTuple(newTestTuple("a", 1), newTestTuple("a", 2), newTestTuple("b", 1), newTestTuple("b", 2)).ToObject()},
+		{args: wrapArgs(ProductType), want: newTestTuple(NewTuple()).ToObject()},
+		{args: wrapArgs(ProductType, "ab", NewList()), want: NewTuple().ToObject()},
+		{args: wrapArgs(RepeatType, "a", 2), want: newTestTuple("a", "a").ToObject()},
+		{args: wrapArgs(RepeatType, "a", -2), want: NewTuple().ToObject()},
+		{args: wrapArgs(StarMapType, add, newTestList(newTestTuple(1, 2), newTestList(3, 4))), want: newTestTuple(3, 7).ToObject()},
+		{args: wrapArgs(StarMapType, add), wantExc: mustCreateException(TypeErrorType, "starmap expected 2 arguments, got 1")},
+		{args: wrapArgs(TeeType, "ab"), want: newTestTuple("a", "b").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestIterToolsKeywords(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, t *Type, kwargs KWArgs, args ...*Object) (*Object, *BaseException) {
+		iter, raised := t.Call(f, args, kwargs)
+		if raised != nil {
+			return nil, raised
+		}
+		return TupleType.Call(f, Args{iter}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(ChainType, wrapKWArgs("foo", 1)), wantExc: mustCreateException(TypeErrorType, "chain() does not take keyword arguments")},
+		{args: wrapArgs(ProductType, wrapKWArgs("repeat", 2), "ab"), want: newTestTuple(newTestTuple("a", "a"), newTestTuple("a", "b"), newTestTuple("b", "a"), newTestTuple("b", "b")).ToObject()},
+		{args: wrapArgs(ProductType, wrapKWArgs("repeat", 0), "ab"), want: newTestTuple(NewTuple()).ToObject()},
+		{args: wrapArgs(ProductType, wrapKWArgs("foo", 2), "ab"), wantExc: mustCreateException(TypeErrorType, "'foo' is an invalid keyword argument for this function")},
+		{args: wrapArgs(RepeatType, wrapKWArgs("times", 1), "a"), want: newTestTuple("a").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestIterToolsInfinite(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, t *Type, args ...*Object) (*Object, *BaseException) {
+		iter, raised := t.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		slice, raised := ISliceType.Call(f, Args{iter, NewInt(5).ToObject()}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return TupleType.Call(f, Args{slice}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(CountType), want: newTestTuple(0, 1, 2, 3, 4).ToObject()},
+		{args: wrapArgs(CountType, 10, -3), want: newTestTuple(10, 7, 4, 1, -2).ToObject()},
+		{args: wrapArgs(CountType, 0.5), want: newTestTuple(0.5, 1.5, 2.5, 3.5, 4.5).ToObject()},
+		{args: wrapArgs(CountType, "a"), wantExc: mustCreateException(TypeErrorType, "a number is required")},
+		{args: wrapArgs(CycleType, "ab"), want: newTestTuple("a", "b", "a", "b", "a").ToObject()},
+		{args: wrapArgs(RepeatType, None), want: newTestTuple(None, None, None, None, None).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestIterToolsRepr(t *testing.T) {
+	f := NewRootFrame()
+	cases := []invokeTestCase{
+		{args: wrapArgs(mustNotRaise(CountType.Call(f, nil, nil))), want: NewStr("count(0)").ToObject()},
+		{args: wrapArgs(mustNotRaise(CountType.Call(f, wrapArgs(1.5, 2.5), nil))), want: NewStr("count(1.5, 2.5)").ToObject()},
+		{args: wrapArgs(mustNotRaise(RepeatType.Call(f, wrapArgs("a"), nil))), want: NewStr("repeat('a')").ToObject()},
+		{args: wrapArgs(mustNotRaise(RepeatType.Call(f, wrapArgs("a", 3), nil))), want: NewStr("repeat('a', 3)").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	f := NewRootFrame()
+	g := mustNotRaise(GroupByType.Call(f, wrapArgs("aabbbc"), nil))
+	first := toTupleUnsafe(mustNotRaise(Next(f, g)))
+	second := toTupleUnsafe(mustNotRaise(Next(f, g)))
+	// Advancing the groupby skips over the rest of the previous group.
+	if got := mustNotRaise(ListType.Call(f, Args{first.elems[1]}, nil)); len(toListUnsafe(got).elems) != 0 {
+		t.Errorf("list(first group) = %v, want []", got)
+	}
+	want := newTestTuple("b", newTestList("b", "b", "b")).ToObject()
+	got := NewTuple2(second.elems[0], mustNotRaise(ListType.Call(f, Args{second.elems[1]}, nil))).ToObject()
+	if mustNotRaise(Eq(f, got, want)) != True.ToObject() {
+		t.Errorf("second group = %v, want %v", got, want)
+	}
+	keyFunc := wrapFuncForTest(func(f *Frame, i int) bool { return i%2 == 0 })
+	g = mustNotRaise(GroupByType.Call(f, wrapArgs(newTestList(1, 3, 2, 4, 5), keyFunc), nil))
+	var keys []*Object
+	for {
+		item, raised := Next(f, g)
+		if raised != nil {
+			if !raised.isInstance(StopIterationType) {
+				t.Fatal(raised)
+			}
+			f.RestoreExc(nil, nil)
+			break
+		}
+		keys = append(keys, toTupleUnsafe(item).elems[0])
+	}
+	if got, want := NewList(keys...).ToObject(), newTestList(false, true, false).ToObject(); mustNotRaise(Eq(f, got, want)) != True.ToObject() {
+		t.Errorf("groupby keys = %v, want %v", got, want)
+	}
+}
+
+func TestTee(t *testing.T) {
+	f := NewRootFrame()
+	// Read enough items to span several chunks of the shared buffer.
+	n := 3*teeChunkSize + 1
+	a := mustNotRaise(TeeType.Call(f, wrapArgs(mustNotRaise(xrangeType.Call(f, wrapArgs(n), nil))), nil))
+	if item := mustNotRaise(Next(f, a)); toIntUnsafe(item).Value() != 0 {
+		t.Fatalf("next(a) = %v, want 0", item)
+	}
+	b := mustNotRaise(mustNotRaise(GetAttr(f, a, NewStr("__copy__"), nil)).Call(f, nil, nil))
+	c := mustNotRaise(TeeType.Call(f, Args{b}, nil))
+	for _, iter := range []*Object{a, b, c} {
+		l := toListUnsafe(mustNotRaise(ListType.Call(f, Args{iter}, nil)))
+		if len(l.elems) != n-1 {
+			t.Fatalf("len(list(tee)) = %d, want %d", len(l.elems), n-1)
+		}
+		for i, item := range l.elems {
+			if toIntUnsafe(item).Value() != i+1 {
+				t.Fatalf("list(tee)[%d] = %v, want %d", i, item, i+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,682 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsonMaxDepth bounds the nesting of encoded containers when circular
+// reference checking is disabled, so that a cycle raises instead of
+// exhausting the Go stack.
+const jsonMaxDepth = 1000
+
+// JSONDecode parses the JSON document s, a str or unicode, into Python
+// objects with the same semantics as CPython's json.loads: objects become
+// dicts with unicode keys, strings become unicode and numbers become int, long
+// or float depending on their form. If objectPairsHook is not None it is
+// called with a list of (key, value) pairs for each object and its result
+// used in place of a dict. Otherwise, if objectHook is not None it is called
+// with each decoded dict. ValueError is raised for malformed documents.
+func JSONDecode(f *Frame, s, objectHook, objectPairsHook *Object) (*Object, *BaseException) {
+	var doc []rune
+	switch {
+	case s.isInstance(UnicodeType):
+		doc = toUnicodeUnsafe(s).Value()
+	case s.isInstance(StrType):
+		u, raised := toStrUnsafe(s).Decode(f, EncodeDefault, EncodeStrict)
+		if raised != nil {
+			return nil, raised
+		}
+		doc = u.Value()
+	default:
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("expected string or buffer, got %s", s.typ.Name()))
+	}
+	d := &jsonDecoder{doc: doc}
+	if objectHook != None {
+		d.objectHook = objectHook
+	}
+	if objectPairsHook != None {
+		d.objectPairsHook = objectPairsHook
+	}
+	d.skipSpace()
+	o, raised := d.value(f)
+	if raised != nil {
+		return nil, raised
+	}
+	d.skipSpace()
+	if d.pos != len(doc) {
+		msg := fmt.Sprintf("Extra data: %s - %s (char %d - %d)", d.lineCol(d.pos), d.lineCol(len(doc)), d.pos, len(doc))
+		return nil, f.RaiseType(ValueErrorType, msg)
+	}
+	return o, nil
+}
+
+type jsonDecoder struct {
+	doc             []rune
+	pos             int
+	depth           int
+	objectHook      *Object
+	objectPairsHook *Object
+}
+
+// lineCol formats the position pos in the document like CPython's json
+// errors, e.g. "line 1 column 5".
+func (d *jsonDecoder) lineCol(pos int) string {
+	line, col := 1, pos+1
+	for i := 0; i < pos; i++ {
+		if d.doc[i] == '\n' {
+			line++
+			col = pos - i
+		}
+	}
+	return fmt.Sprintf("line %d column %d", line, col)
+}
+
+func (d *jsonDecoder) raise(f *Frame, msg string, pos int) *BaseException {
+	return f.RaiseType(ValueErrorType, fmt.Sprintf("%s: %s (char %d)", msg, d.lineCol(pos), pos))
+}
+
+func (d *jsonDecoder) skipSpace() {
+	for d.pos < len(d.doc) {
+		switch d.doc[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (d *jsonDecoder) hasPrefix(s string) bool {
+	runes := []rune(s)
+	if len(d.doc)-d.pos < len(runes) {
+		return false
+	}
+	for i, r := range runes {
+		if d.doc[d.pos+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// errorNoValue raises ValueError for a missing or malformed value.
+func (d *jsonDecoder) errorNoValue(f *Frame, pos int) *BaseException {
+	if d.depth == 0 {
+		return f.RaiseType(ValueErrorType, "No JSON object could be decoded")
+	}
+	return d.raise(f, "Expecting object", pos)
+}
+
+func (d *jsonDecoder) value(f *Frame) (*Object, *BaseException) {
+	if d.pos == len(d.doc) {
+		return nil, d.errorNoValue(f, d.pos)
+	}
+	switch c := d.doc[d.pos]; {
+	case c == '"':
+		return d.str(f)
+	case c == '{':
+		return d.object(f)
+	case c == '[':
+		return d.array(f)
+	case c == '-' || (c >= '0' && c <= '9'):
+		if d.hasPrefix("-Infinity") {
+			d.pos += len("-Infinity")
+			return NewFloat(math.Inf(-1)).ToObject(), nil
+		}
+		return d.number(f)
+	}
+	constants := []struct {
+		name  string
+		value *Object
+	}{
+		{"null", None},
+		{"true", True.ToObject()},
+		{"false", False.ToObject()},
+		{"NaN", NewFloat(math.NaN()).ToObject()},
+		{"Infinity", NewFloat(math.Inf(1)).ToObject()},
+	}
+	for _, c := range constants {
+		if d.hasPrefix(c.name) {
+			d.pos += len(c.name)
+			return c.value, nil
+		}
+	}
+	return nil, d.errorNoValue(f, d.pos)
+}
+
+func (d *jsonDecoder) number(f *Frame) (*Object, *BaseException) {
+	start := d.pos
+	isFloat := false
+	digits := func() int {
+		n := 0
+		for d.pos < len(d.doc) && d.doc[d.pos] >= '0' && d.doc[d.pos] <= '9' {
+			d.pos++
+			n++
+		}
+		return n
+	}
+	if d.doc[d.pos] == '-' {
+		d.pos++
+	}
+	if d.pos < len(d.doc) && d.doc[d.pos] == '0' {
+		d.pos++
+	} else if digits() == 0 {
+		return nil, d.errorNoValue(f, start)
+	}
+	if d.pos+1 < len(d.doc) && d.doc[d.pos] == '.' && d.doc[d.pos+1] >= '0' && d.doc[d.pos+1] <= '9' {
+		d.pos++
+		digits()
+		isFloat = true
+	}
+	if d.pos < len(d.doc) && (d.doc[d.pos] == 'e' || d.doc[d.pos] == 'E') {
+		save := d.pos
+		d.pos++
+		if d.pos < len(d.doc) && (d.doc[d.pos] == '+' || d.doc[d.pos] == '-') {
+			d.pos++
+		}
+		if digits() == 0 {
+			d.pos = save
+		} else {
+			isFloat = true
+		}
+	}
+	s := string(d.doc[start:d.pos])
+	if isFloat {
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil && !strings.Contains(err.Error(), "range") {
+			return nil, d.raise(f, "Invalid number", start)
+		}
+		return NewFloat(x).ToObject(), nil
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return NewInt(i).ToObject(), nil
+	}
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, d.raise(f, "Invalid number", start)
+	}
+	return NewLong(i).ToObject(), nil
+}
+
+func (d *jsonDecoder) str(f *Frame) (*Object, *BaseException) {
+	start := d.pos
+	d.pos++
+	var buf []rune
+	for {
+		if d.pos >= len(d.doc) {
+			return nil, d.raise(f, "Unterminated string starting at", start)
+		}
+		c := d.doc[d.pos]
+		d.pos++
+		switch {
+		case c == '"':
+			return NewUnicodeFromRunes(buf).ToObject(), nil
+		case c < 0x20:
+			return nil, d.raise(f, "Invalid control character at", d.pos-1)
+		case c != '\\':
+			buf = append(buf, c)
+			continue
+		}
+		if d.pos >= len(d.doc) {
+			return nil, d.raise(f, "Unterminated string starting at", start)
+		}
+		esc := d.doc[d.pos]
+		d.pos++
+		switch esc {
+		case '"', '\\', '/':
+			buf = append(buf, esc)
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, ok := d.hex4()
+			if !ok {
+				return nil, d.raise(f, "Invalid \\uXXXX escape", d.pos-2)
+			}
+			if utf16.IsSurrogate(r) && d.hasPrefix("\\u") {
+				save := d.pos
+				d.pos += 2
+				r2, ok := d.hex4()
+				if !ok {
+					return nil, d.raise(f, "Invalid \\uXXXX escape", d.pos-2)
+				}
+				if combined := utf16.DecodeRune(r, r2); combined != utf8.RuneError {
+					r = combined
+				} else {
+					d.pos = save
+				}
+			}
+			buf = append(buf, r)
+		default:
+			return nil, d.raise(f, "Invalid \\escape", d.pos-2)
+		}
+	}
+}
+
+func (d *jsonDecoder) hex4() (rune, bool) {
+	if len(d.doc)-d.pos < 4 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(d.doc[d.pos:d.pos+4]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	d.pos += 4
+	return rune(n), true
+}
+
+func (d *jsonDecoder) array(f *Frame) (*Object, *BaseException) {
+	d.pos++
+	d.depth++
+	defer func() { d.depth-- }()
+	var elems []*Object
+	d.skipSpace()
+	if d.pos < len(d.doc) && d.doc[d.pos] == ']' {
+		d.pos++
+		return NewList().ToObject(), nil
+	}
+	for {
+		d.skipSpace()
+		elem, raised := d.value(f)
+		if raised != nil {
+			return nil, raised
+		}
+		elems = append(elems, elem)
+		d.skipSpace()
+		if d.pos < len(d.doc) && d.doc[d.pos] == ']' {
+			d.pos++
+			return NewList(elems...).ToObject(), nil
+		}
+		if d.pos >= len(d.doc) || d.doc[d.pos] != ',' {
+			return nil, d.raise(f, "Expecting ',' delimiter", d.pos)
+		}
+		d.pos++
+	}
+}
+
+func (d *jsonDecoder) object(f *Frame) (*Object, *BaseException) {
+	d.pos++
+	d.depth++
+	defer func() { d.depth-- }()
+	var pairs []*Object
+	d.skipSpace()
+	if d.pos < len(d.doc) && d.doc[d.pos] == '}' {
+		d.pos++
+		return d.makeObject(f, pairs)
+	}
+	for {
+		d.skipSpace()
+		if d.pos >= len(d.doc) || d.doc[d.pos] != '"' {
+			return nil, d.raise(f, "Expecting property name enclosed in double quotes", d.pos)
+		}
+		key, raised := d.str(f)
+		if raised != nil {
+			return nil, raised
+		}
+		d.skipSpace()
+		if d.pos >= len(d.doc) || d.doc[d.pos] != ':' {
+			return nil, d.raise(f, "Expecting ':' delimiter", d.pos)
+		}
+		d.pos++
+		d.skipSpace()
+		value, raised := d.value(f)
+		if raised != nil {
+			return nil, raised
+		}
+		pairs = append(pairs, NewTuple2(key, value).ToObject())
+		d.skipSpace()
+		if d.pos < len(d.doc) && d.doc[d.pos] == '}' {
+			d.pos++
+			return d.makeObject(f, pairs)
+		}
+		if d.pos >= len(d.doc) || d.doc[d.pos] != ',' {
+			return nil, d.raise(f, "Expecting ',' delimiter", d.pos)
+		}
+		d.pos++
+	}
+}
+
+func (d *jsonDecoder) makeObject(f *Frame, pairs []*Object) (*Object, *BaseException) {
+	if d.objectPairsHook != nil {
+		return d.objectPairsHook.Call(f, Args{NewList(pairs...).ToObject()}, nil)
+	}
+	dict := NewDict()
+	for _, pair := range pairs {
+		elems := toTupleUnsafe(pair).elems
+		if raised := dict.SetItem(f, elems[0], elems[1]); raised != nil {
+			return nil, raised
+		}
+	}
+	if d.objectHook != nil {
+		return d.objectHook.Call(f, Args{dict.ToObject()}, nil)
+	}
+	return dict.ToObject(), nil
+}
+
+// JSONEncode serializes o to a JSON formatted str with the same semantics as
+// CPython's json.dumps. The result is unicode if ensureASCII is false and o
+// contains unicode strings. indent is None or an int giving the number of
+// spaces to indent each nesting level by. defaultFn is None or a callable
+// returning a serializable version of objects that otherwise can't be
+// serialized.
+func JSONEncode(f *Frame, o *Object, skipKeys, ensureASCII, checkCircular, allowNaN, sortKeys bool, indent *Object, itemSeparator, keySeparator string, defaultFn *Object) (*Object, *BaseException) {
+	e := &jsonEncoder{
+		skipKeys:      skipKeys,
+		ensureASCII:   ensureASCII,
+		checkCircular: checkCircular,
+		allowNaN:      allowNaN,
+		sortKeys:      sortKeys,
+		indent:        -1,
+		itemSeparator: itemSeparator,
+		keySeparator:  keySeparator,
+		markers:       map[*Object]bool{},
+	}
+	if indent != None {
+		n, raised := IndexInt(f, indent)
+		if raised != nil {
+			return nil, raised
+		}
+		e.indent = n
+	}
+	if defaultFn != None {
+		e.defaultFn = defaultFn
+	}
+	if raised := e.encode(f, o, 0); raised != nil {
+		return nil, raised
+	}
+	if !ensureASCII && e.hasUnicode {
+		return NewUnicode(e.buf.String()).ToObject(), nil
+	}
+	return NewStr(e.buf.String()).ToObject(), nil
+}
+
+type jsonEncoder struct {
+	buf           bytes.Buffer
+	skipKeys      bool
+	ensureASCII   bool
+	checkCircular bool
+	allowNaN      bool
+	sortKeys      bool
+	indent        int
+	itemSeparator string
+	keySeparator  string
+	defaultFn     *Object
+	markers       map[*Object]bool
+	hasUnicode    bool
+}
+
+func (e *jsonEncoder) encode(f *Frame, o *Object, depth int) *BaseException {
+	switch {
+	case o == None:
+		e.buf.WriteString("null")
+	case o.isInstance(BoolType):
+		e.buf.WriteString(jsonBoolString(o))
+	case o.isInstance(StrType), o.isInstance(UnicodeType):
+		return e.encodeString(f, o)
+	case o.isInstance(IntType), o.isInstance(LongType), o.isInstance(FloatType):
+		s, raised := e.number(f, o)
+		if raised != nil {
+			return raised
+		}
+		e.buf.WriteString(s)
+	case o.isInstance(ListType), o.isInstance(TupleType):
+		return e.container(f, o, depth, e.encodeList)
+	case o.isInstance(DictType):
+		return e.container(f, o, depth, e.encodeDict)
+	default:
+		if e.defaultFn == nil {
+			s, raised := Repr(f, o)
+			if raised != nil {
+				return raised
+			}
+			return f.RaiseType(TypeErrorType, s.Value()+" is not JSON serializable")
+		}
+		return e.container(f, o, depth, func(f *Frame, o *Object, depth int) *BaseException {
+			replacement, raised := e.defaultFn.Call(f, Args{o}, nil)
+			if raised != nil {
+				return raised
+			}
+			return e.encode(f, replacement, depth)
+		})
+	}
+	return nil
+}
+
+// container encodes o, a list, dict or object passed to the default hook,
+// using fn while guarding against reference cycles.
+func (e *jsonEncoder) container(f *Frame, o *Object, depth int, fn func(*Frame, *Object, int) *BaseException) *BaseException {
+	if e.checkCircular {
+		if e.markers[o] {
+			return f.RaiseType(ValueErrorType, "Circular reference detected")
+		}
+		e.markers[o] = true
+		defer delete(e.markers, o)
+	} else if depth > jsonMaxDepth {
+		return f.RaiseType(RuntimeErrorType, "maximum recursion depth exceeded while encoding a JSON object")
+	}
+	return fn(f, o, depth)
+}
+
+func (e *jsonEncoder) newline(depth int) {
+	if e.indent >= 0 {
+		e.buf.WriteByte('\n')
+		e.buf.WriteString(strings.Repeat(" ", e.indent*depth))
+	}
+}
+
+func (e *jsonEncoder) encodeList(f *Frame, o *Object, depth int) *BaseException {
+	var elems []*Object
+	if o.isInstance(ListType) {
+		l := toListUnsafe(o)
+		l.mutex.RLock()
+		elems = make([]*Object, len(l.elems))
+		copy(elems, l.elems)
+		l.mutex.RUnlock()
+	} else {
+		elems = toTupleUnsafe(o).elems
+	}
+	if len(elems) == 0 {
+		e.buf.WriteString("[]")
+		return nil
+	}
+	e.buf.WriteByte('[')
+	for i, elem := range elems {
+		if i > 0 {
+			e.buf.WriteString(e.itemSeparator)
+		}
+		e.newline(depth + 1)
+		if raised := e.encode(f, elem, depth+1); raised != nil {
+			return raised
+		}
+	}
+	e.newline(depth)
+	e.buf.WriteByte(']')
+	return nil
+}
+
+func (e *jsonEncoder) encodeDict(f *Frame, o *Object, depth int) *BaseException {
+	d := toDictUnsafe(o)
+	var keys, values []*Object
+	if e.sortKeys {
+		l := d.Keys(f)
+		if raised := l.Sort(f); raised != nil {
+			return raised
+		}
+		keys = l.elems
+		for _, k := range keys {
+			v, raised := d.GetItem(f, k)
+			if raised != nil {
+				return raised
+			}
+			if v == nil {
+				return f.RaiseType(RuntimeErrorType, "dictionary changed size during iteration")
+			}
+			values = append(values, v)
+		}
+	} else {
+		iter := newDictEntryIterator(d)
+		for entry := iter.next(); entry != nil; entry = iter.next() {
+			keys = append(keys, entry.key)
+			values = append(values, entry.value)
+		}
+	}
+	if len(keys) == 0 {
+		e.buf.WriteString("{}")
+		return nil
+	}
+	e.buf.WriteByte('{')
+	first := true
+	for i, k := range keys {
+		var key *Object
+		switch {
+		case k.isInstance(StrType), k.isInstance(UnicodeType):
+			key = k
+		case k == None:
+			key = NewStr("null").ToObject()
+		case k.isInstance(BoolType):
+			key = NewStr(jsonBoolString(k)).ToObject()
+		case k.isInstance(IntType), k.isInstance(LongType), k.isInstance(FloatType):
+			s, raised := e.number(f, k)
+			if raised != nil {
+				return raised
+			}
+			key = NewStr(s).ToObject()
+		case e.skipKeys:
+			continue
+		default:
+			s, raised := Repr(f, k)
+			if raised != nil {
+				return raised
+			}
+			return f.RaiseType(TypeErrorType, fmt.Sprintf("key %s is not a string", s.Value()))
+		}
+		if !first {
+			e.buf.WriteString(e.itemSeparator)
+		}
+		first = false
+		e.newline(depth + 1)
+		if raised := e.encodeString(f, key); raised != nil {
+			return raised
+		}
+		e.buf.WriteString(e.keySeparator)
+		if raised := e.encode(f, values[i], depth+1); raised != nil {
+			return raised
+		}
+	}
+	e.newline(depth)
+	e.buf.WriteByte('}')
+	return nil
+}
+
+func jsonBoolString(o *Object) string {
+	if toIntUnsafe(o).IsTrue() {
+		return "true"
+	}
+	return "false"
+}
+
+// number formats the int, long or float o as a JSON number.
+func (e *jsonEncoder) number(f *Frame, o *Object) (string, *BaseException) {
+	switch {
+	case o.isInstance(FloatType):
+		x := toFloatUnsafe(o).Value()
+		var s string
+		switch {
+		case math.IsNaN(x):
+			s = "NaN"
+		case math.IsInf(x, 1):
+			s = "Infinity"
+		case math.IsInf(x, -1):
+			s = "-Infinity"
+		default:
+			return floatToString(x, floatReprPrecision), nil
+		}
+		if !e.allowNaN {
+			s, raised := Repr(f, o)
+			if raised != nil {
+				return "", raised
+			}
+			return "", f.RaiseType(ValueErrorType, "Out of range float values are not JSON compliant: "+s.Value())
+		}
+		return s, nil
+	case o.isInstance(IntType):
+		return strconv.Itoa(toIntUnsafe(o).Value()), nil
+	default:
+		return toLongUnsafe(o).Value().String(), nil
+	}
+}
+
+func (e *jsonEncoder) encodeString(f *Frame, o *Object) *BaseException {
+	var runes []rune
+	if o.isInstance(UnicodeType) {
+		e.hasUnicode = true
+		runes = toUnicodeUnsafe(o).Value()
+	} else {
+		u, raised := toStrUnsafe(o).Decode(f, EncodeDefault, EncodeStrict)
+		if raised != nil {
+			return raised
+		}
+		runes = u.Value()
+	}
+	e.buf.WriteByte('"')
+	for _, r := range runes {
+		switch r {
+		case '"':
+			e.buf.WriteString(`\"`)
+		case '\\':
+			e.buf.WriteString(`\\`)
+		case '\n':
+			e.buf.WriteString(`\n`)
+		case '\r':
+			e.buf.WriteString(`\r`)
+		case '\t':
+			e.buf.WriteString(`\t`)
+		case '\b':
+			e.buf.WriteString(`\b`)
+		case '\f':
+			e.buf.WriteString(`\f`)
+		default:
+			switch {
+			case r < 0x20:
+				fmt.Fprintf(&e.buf, `\u%04x`, r)
+			case r < 0x7f || !e.ensureASCII:
+				e.buf.WriteRune(r)
+			case r > 0xffff:
+				r1, r2 := utf16.EncodeRune(r)
+				fmt.Fprintf(&e.buf, `\u%04x\u%04x`, r1, r2)
+			default:
+				fmt.Fprintf(&e.buf, `\u%04x`, r)
+			}
+		}
+	}
+	e.buf.WriteByte('"')
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestJSONDecode(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *Object) (*Object, *BaseException) {
+		return JSONDecode(f, s, None, None)
+	})
+	bigInt := new(big.Int).Lsh(big.NewInt(1), 100)
+	cases := []invokeTestCase{
+		{args: wrapArgs("null"), want: None},
+		{args: wrapArgs(" true "), want: True.ToObject()},
+		{args: wrapArgs("false"), want: False.ToObject()},
+		{args: wrapArgs("42"), want: NewInt(42).ToObject()},
+		{args: wrapArgs("-0"), want: NewInt(0).ToObject()},
+		{args: wrapArgs("1.5"), want: NewFloat(1.5).ToObject()},
+		{args: wrapArgs("1e3"), want: NewFloat(1000).ToObject()},
+		{args: wrapArgs("1267650600228229401496703205376"), want: NewLong(bigInt).ToObject()},
+		{args: wrapArgs("-Infinity"), want: NewFloat(math.Inf(-1)).ToObject()},
+		{args: wrapArgs(`"foo"`), want: NewUnicode("foo").ToObject()},
+		{args: wrapArgs(`"\"\\\/\b\f\n\r\t"`), want: NewUnicode("\"\\/\b\f\n\r\t").ToObject()},
+		{args: wrapArgs(`"é😀"`), want: NewUnicode("é😀").ToObject()},
+		{args: wrapArgs(`"вол"`), want: NewUnicode("вол").ToObject()},
+		{args: wrapArgs(NewUnicode(`"вол"`)), want: NewUnicode("вол").ToObject()},
+		{args: wrapArgs(`[1, [], {}, "a"]`), want: newTestList(1, NewList(), NewDict(), NewUnicode("a")).ToObject()},
+		{args: wrapArgs(`{"a": {"b": null}, "a": 2}`), want: newTestDict(NewUnicode("a"), 2).ToObject()},
+		{args: wrapArgs(""), wantExc: mustCreateException(ValueErrorType, "No JSON object could be decoded")},
+		{args: wrapArgs("nul"), wantExc: mustCreateException(ValueErrorType, "No JSON object could be decoded")},
+		{args: wrapArgs("[1,]"), wantExc: mustCreateException(ValueErrorType, "Expecting object: line 1 column 4 (char 3)")},
+		{args: wrapArgs("[1 2]"), wantExc: mustCreateException(ValueErrorType, "Expecting ',' delimiter: line 1 column 4 (char 3)")},
+		{args: wrapArgs("{\n'a': 1}"), wantExc: mustCreateException(ValueErrorType, "Expecting property name enclosed in double quotes: line 2 column 1 (char 2)")},
+		{args: wrapArgs(`{"a" 1}`), wantExc: mustCreateException(ValueErrorType, "Expecting ':' delimiter: line 1 column 6 (char 5)")},
+		{args: wrapArgs(`"abc`), wantExc: mustCreateException(ValueErrorType, "Unterminated string starting at: line 1 column 1 (char 0)")},
+		{args: wrapArgs(`"\x"`), wantExc: mustCreateException(ValueErrorType, "Invalid \\escape: line 1 column 2 (char 1)")},
+		{args: wrapArgs(`"\u12"`), wantExc: mustCreateException(ValueErrorType, "Invalid \\uXXXX escape: line 1 column 2 (char 1)")},
+		{args: wrapArgs("\"a\tb\""), wantExc: mustCreateException(ValueErrorType, "Invalid control character at: line 1 column 3 (char 2)")},
+		{args: wrapArgs("[] []"), wantExc: mustCreateException(ValueErrorType, "Extra data: line 1 column 4 - line 1 column 6 (char 3 - 5)")},
+		{args: wrapArgs(123), wantExc: mustCreateException(TypeErrorType, "expected string or buffer, got int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestJSONDecodeHooks(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s, objectHook, objectPairsHook *Object) (*Object, *BaseException) {
+		return JSONDecode(f, s, objectHook, objectPairsHook)
+	})
+	lenHook := wrapFuncForTest(func(f *Frame, o *Object) (*Object, *BaseException) {
+		l, raised := Len(f, o)
+		if raised != nil {
+			return nil, raised
+		}
+		return l.ToObject(), nil
+	})
+	pairs := newTestTuple(newTestTuple(NewUnicode("b"), 1), newTestTuple(NewUnicode("a"), 2)).ToObject()
+	cases := []invokeTestCase{
+		{args: wrapArgs(`[{"a": 1, "b": 2}, {}]`, lenHook, None), want: newTestList(2, 0).ToObject()},
+		{args: wrapArgs(`{"b": 1, "a": 2}`, None, TupleType), want: pairs},
+		{args: wrapArgs(`{"b": 1, "a": 2}`, lenHook, TupleType), want: pairs},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestJSONEncode(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object, ensureASCII, allowNaN, sortKeys bool, indent *Object) (*Object, *BaseException) {
+		return JSONEncode(f, o, false, ensureASCII, true, allowNaN, sortKeys, indent, ", ", ": ", None)
+	})
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	foo := newObject(fooType)
+	cyclic := NewList()
+	cyclic.Append(cyclic.ToObject())
+	cases := []invokeTestCase{
+		{args: wrapArgs(None, true, true, false, None), want: NewStr("null").ToObject()},
+		{args: wrapArgs(newTestTuple(true, false, 1, 1.5, 1e16).ToObject(), true, true, false, None), want: NewStr("[true, false, 1, 1.5, 1e+16]").ToObject()},
+		{args: wrapArgs(NewLong(new(big.Int).Lsh(big.NewInt(1), 70)), true, true, false, None), want: NewStr("1180591620717411303424").ToObject()},
+		{args: wrapArgs(math.Inf(-1), true, true, false, None), want: NewStr("-Infinity").ToObject()},
+		{args: wrapArgs(math.NaN(), true, false, false, None), wantExc: mustCreateException(ValueErrorType, "Out of range float values are not JSON compliant: nan")},
+		{args: wrapArgs("a\"\\\n\x01é", true, true, false, None), want: NewStr(`"a\"\\\n\u0001\u00e9"`).ToObject()},
+		{args: wrapArgs(NewUnicode("😀"), true, true, false, None), want: NewStr(`"\ud83d\ude00"`).ToObject()},
+		{args: wrapArgs(NewUnicode("é"), false, true, false, None), want: NewUnicode(`"é"`).ToObject()},
+		{args: wrapArgs("é", false, true, false, None), want: NewStr(`"é"`).ToObject()},
+		{args: wrapArgs("~\x7f", true, true, false, None), want: NewStr(`"~\u007f"`).ToObject()},
+		{args: wrapArgs("~\x7f", false, true, false, None), want: NewStr("\"~\x7f\"").ToObject()},
+		{args: wrapArgs("\xff", true, true, false, None), wantExc: mustCreateException(UnicodeDecodeErrorType, "'utf8' codec can't decode byte 0xff in position 0")},
+		{args: wrapArgs(newTestDict("b", 1, "a", newTestList(2, 3)), true, true, true, None), want: NewStr(`{"a": [2, 3], "b": 1}`).ToObject()},
+		{args: wrapArgs(newTestDict(1, 2, None, 3, 1.5, 4, false, 5), true, true, true, None), want: NewStr(`{"null": 3, "false": 5, "1": 2, "1.5": 4}`).ToObject()},
+		{args: wrapArgs(newTestDict("a", newTestList(1), "b", NewDict()), true, true, true, 2), want: NewStr("{\n  \"a\": [\n    1\n  ], \n  \"b\": {}\n}").ToObject()},
+		{args: wrapArgs(newTestList(1, 2), true, true, false, 0), want: NewStr("[\n1, \n2\n]").ToObject()},
+		{args: wrapArgs(newTestDict(foo, 1), true, true, false, None), wantExc: mustCreateException(TypeErrorType, fmt.Sprintf("key <Foo object at %p> is not a string", foo))},
+		{args: wrapArgs(foo, true, true, false, None), wantExc: mustCreateException(TypeErrorType, fmt.Sprintf("<Foo object at %p> is not JSON serializable", foo))},
+		{args: wrapArgs(cyclic, true, true, false, None), wantExc: mustCreateException(ValueErrorType, "Circular reference detected")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestJSONEncodeOptions(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object, skipKeys, checkCircular bool, itemSeparator, keySeparator string, defaultFn *Object) (*Object, *BaseException) {
+		return JSONEncode(f, o, skipKeys, true, checkCircular, true, false, None, itemSeparator, keySeparator, defaultFn)
+	})
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	reprDefault := wrapFuncForTest(func(f *Frame, o *Object) (*Object, *BaseException) {
+		s, raised := Repr(f, o.Type().ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		return s.ToObject(), nil
+	})
+	selfDefault := wrapFuncForTest(func(f *Frame, o *Object) *Object {
+		return o
+	})
+	cyclic := NewList()
+	cyclic.Append(cyclic.ToObject())
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestDict(newObject(fooType), 1, "a", 2), true, true, ",", ":", None), want: NewStr(`{"a":2}`).ToObject()},
+		{args: wrapArgs(newTestList(newObject(fooType)), false, true, ", ", ": ", reprDefault), want: NewStr(`["<type 'Foo'>"]`).ToObject()},
+		{args: wrapArgs(newObject(fooType), false, true, ", ", ": ", selfDefault), wantExc: mustCreateException(ValueErrorType, "Circular reference detected")},
+		{args: wrapArgs(cyclic, false, false, ", ", ": ", None), wantExc: mustCreateException(RuntimeErrorType, "maximum recursion depth exceeded while encoding a JSON object")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -17,7 +17,6 @@ package grumpy
 import (
 	"fmt"
 	"reflect"
-	"sort"
 	"sync"
 )
 
@@ -61,6 +60,31 @@ func (l *List) Append(o *Object) {
 	l.mutex.Unlock()
 }
 
+// ListAppend appends v to o. It is equivalent to o.append(v) but avoids
+// creating a bound method when o is exactly a list, so generated code uses it
+// to build lists element by element.
+func ListAppend(f *Frame, o, v *Object) *BaseException {
+	if o.typ == ListType {
+		toListUnsafe(o).Append(v)
+		return nil
+	}
+	append, raised := GetAttr(f, o, listAppendName, nil)
+	if raised != nil {
+		return raised
+	}
+	_, raised = f.callArgs(append, v)
+	return raised
+}
+
+// extend adds elems to the end of l.
+func (l *List) extend(elems []*Object) {
+	l.mutex.Lock()
+	numElems := len(l.elems)
+	l.resize(numElems + len(elems))
+	copy(l.elems[numElems:], elems)
+	l.mutex.Unlock()
+}
+
 // DelItem removes the index'th element of l.
 func (l *List) DelItem(f *Frame, index int) *BaseException {
 	l.mutex.Lock()
@@ -78,29 +102,68 @@ func (l *List) DelItem(f *Frame, index int) *BaseException {
 func (l *List) DelSlice(f *Frame, s *Slice) *BaseException {
 	l.mutex.Lock()
 	numListElems := len(l.elems)
-	start, stop, step, numSliceElems, raised := s.calcSlice(f, numListElems)
-	if raised == nil {
+	start, _, step, numSliceElems, raised := s.calcSlice(f, numListElems)
+	if raised == nil && numSliceElems > 0 {
+		if step < 0 {
+			// Deleting is order independent so walk the slice
+			// forward from its lowest index.
+			start, step = start+(numSliceElems-1)*step, -step
+		}
 		if step == 1 {
-			copy(l.elems[start:numListElems-numSliceElems], l.elems[stop:numListElems])
+			copy(l.elems[start:], l.elems[start+numSliceElems:])
 		} else {
-			j := 0
-			for i := start; i != stop; i += step {
-				next := i + step
-				if next > numListElems {
-					next = numListElems
+			// Shift down the elements that are not part of the
+			// slice, skipping over those that are.
+			j, deleted := start, 0
+			for i := start; i < numListElems; i++ {
+				if deleted < numSliceElems && i == start+deleted*step {
+					deleted++
+					continue
 				}
-				dest := l.elems[i-j : next-j-1]
-				src := l.elems[i+1 : next]
-				copy(dest, src)
+				l.elems[j] = l.elems[i]
 				j++
 			}
 		}
-		l.elems = l.elems[:numListElems-numSliceElems]
+		newLen := numListElems - numSliceElems
+		// Clear the vacated tail so the deleted elements can be
+		// garbage collected.
+		for i := newLen; i < numListElems; i++ {
+			l.elems[i] = nil
+		}
+		l.elems = l.elems[:newLen]
 	}
 	l.mutex.Unlock()
 	return raised
 }
 
+// find returns the index of the first element of l in the range [start, stop)
+// that equals value, or -1 if there is none. Like CPython, l is not locked
+// while elements are compared so that __eq__ may access l. Instead the bounds
+// are rechecked for each element in case l is modified concurrently.
+func (l *List) find(f *Frame, value *Object, start, stop int) (int, *BaseException) {
+	for i := start; i < stop; i++ {
+		l.mutex.RLock()
+		if i >= len(l.elems) {
+			l.mutex.RUnlock()
+			break
+		}
+		elem := l.elems[i]
+		l.mutex.RUnlock()
+		eq, raised := Eq(f, elem, value)
+		if raised != nil {
+			return -1, raised
+		}
+		found, raised := IsTrue(f, eq)
+		if raised != nil {
+			return -1, raised
+		}
+		if found {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
 // SetItem sets the index'th element of l to value.
 func (l *List) SetItem(f *Frame, index int, value *Object) *BaseException {
 	l.mutex.Lock()
@@ -113,53 +176,91 @@ func (l *List) SetItem(f *Frame, index int, value *Object) *BaseException {
 }
 
 // SetSlice replaces the slice of l specified by s with the contents of value
-// (an iterable).
+// (an iterable). As in CPython, an extended slice (one whose step is not 1)
+// may only be assigned a sequence of the same length.
 func (l *List) SetSlice(f *Frame, s *Slice, value *Object) *BaseException {
+	// Collect the new elements before locking l since value may be l
+	// itself or an iterator that accesses l.
+	elems, raised := seqCopy(f, value)
+	if raised != nil {
+		return raised
+	}
+	numElems := len(elems)
 	l.mutex.Lock()
 	numListElems := len(l.elems)
 	start, stop, step, numSliceElems, raised := s.calcSlice(f, numListElems)
 	if raised == nil {
-		raised = seqApply(f, value, func(elems []*Object, _ bool) *BaseException {
-			numElems := len(elems)
-			if step == 1 {
-				tailElems := l.elems[stop:numListElems]
-				l.resize(numListElems - numSliceElems + numElems)
-				copy(l.elems[start+numElems:], tailElems)
-				copy(l.elems[start:start+numElems], elems)
-			} else if numSliceElems == numElems {
-				i := 0
-				for j := start; j != stop; j += step {
-					l.elems[j] = elems[i]
-					i++
-				}
-			} else {
-				format := "attempt to assign sequence of size %d to extended slice of size %d"
-				return f.RaiseType(ValueErrorType, fmt.Sprintf(format, numElems, numSliceElems))
+		if step == 1 {
+			newLen := numListElems - numSliceElems + numElems
+			if numElems > numSliceElems {
+				l.resize(newLen)
+			}
+			copy(l.elems[start+numElems:], l.elems[stop:numListElems])
+			copy(l.elems[start:], elems)
+			for i := newLen; i < numListElems; i++ {
+				l.elems[i] = nil
 			}
-			return nil
-		})
+			l.elems = l.elems[:newLen]
+		} else if numSliceElems == numElems {
+			for i, j := 0, start; i < numElems; i, j = i+1, j+step {
+				l.elems[j] = elems[i]
+			}
+		} else {
+			format := "attempt to assign sequence of size %d to extended slice of size %d"
+			raised = f.RaiseType(ValueErrorType, fmt.Sprintf(format, numElems, numSliceElems))
+		}
 	}
 	l.mutex.Unlock()
 	return raised
 }
 
 // Sort reorders l so that its elements are in sorted order.
-func (l *List) Sort(f *Frame) (raised *BaseException) {
-	l.mutex.RLock()
-	sorter := &listSorter{f, l, nil}
-	defer func() {
-		l.mutex.RUnlock()
-		if val := recover(); val == nil {
-			return
-		} else if s, ok := val.(*listSorter); !ok || s != sorter {
-			panic(val)
+func (l *List) Sort(f *Frame) *BaseException {
+	return l.sort(f, nil, nil, false)
+}
+
+// sort stably sorts l in place. If cmp is non-nil it is called with pairs of
+// elements and must return a negative, zero or positive int. If key is
+// non-nil it is called on each element to produce the values that are
+// compared. While sorting, l appears empty and if it is modified by cmp or key
+// ValueError is raised.
+func (l *List) sort(f *Frame, cmp, key *Object, reverse bool) *BaseException {
+	// Sort without holding the lock, like CPython, so that cmp and key may
+	// access the list without deadlocking.
+	l.mutex.Lock()
+	elems := l.elems
+	l.elems = nil
+	l.mutex.Unlock()
+	s := &listSorter{f: f, cmp: cmp, elems: make([]listSortElem, len(elems))}
+	for i, o := range elems {
+		s.elems[i].value = o
+		s.elems[i].key = o
+	}
+	raised := s.computeKeys(key)
+	if raised == nil {
+		if reverse {
+			// Reverse before and after so that elements that
+			// compare equal retain their original order.
+			s.reverse()
 		}
-		raised = sorter.raised
-	}()
-	// Python guarantees stability.  See note (9) in:
-	// https://docs.python.org/2/library/stdtypes.html#mutable-sequence-types
-	sort.Stable(sorter)
-	return nil
+		raised = s.mergeSort(s.elems, make([]listSortElem, len(s.elems)))
+		if raised == nil && reverse {
+			s.reverse()
+		}
+	}
+	if raised == nil {
+		for i, e := range s.elems {
+			elems[i] = e.value
+		}
+	}
+	l.mutex.Lock()
+	modified := l.elems != nil
+	l.elems = elems
+	l.mutex.Unlock()
+	if raised == nil && modified {
+		raised = f.RaiseType(ValueErrorType, "list modified during sort")
+	}
+	return raised
 }
 
 // resize ensures that len(l.elems) == newLen, reallocating if necessary.
@@ -178,8 +279,12 @@ func (l *List) resize(newLen int) {
 	l.elems = l.elems[:newLen]
 }
 
-// ListType is the object representing the Python 'list' type.
-var ListType = newBasisType("list", reflect.TypeOf(List{}), toListUnsafe, ObjectType)
+var (
+	// ListType is the object representing the Python 'list' type.
+	ListType          = newBasisType("list", reflect.TypeOf(List{}), toListUnsafe, ObjectType)
+	listAppendName    = InternStr("append")
+	listSortParamSpec = NewParamSpec("sort", []Param{{"self", nil}, {"cmp", None}, {"key", None}, {"reverse", False.ToObject()}}, false, false)
+)
 
 func listAdd(f *Frame, v, w *Object) (ret *Object, raised *BaseException) {
 	if !w.isInstance(ListType) {
@@ -190,7 +295,7 @@ func listAdd(f *Frame, v, w *Object) (ret *Object, raised *BaseException) {
 	listW.mutex.RLock()
 	elems, raised := seqAdd(f, listV.elems, listW.elems)
 	if raised == nil {
-		ret = NewList(elems...).ToObject()
+		ret = (&List{Object: Object{typ: ListType}, elems: elems}).ToObject()
 	}
 	listW.mutex.RUnlock()
 	listV.mutex.RUnlock()
@@ -209,7 +314,19 @@ func listCount(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "count", args, ListType, ObjectType); raised != nil {
 		return nil, raised
 	}
-	return seqCount(f, args[0], args[1])
+	l := toListUnsafe(args[0])
+	count := 0
+	for i := 0; ; i++ {
+		var raised *BaseException
+		if i, raised = l.find(f, args[1], i, MaxInt); raised != nil {
+			return nil, raised
+		}
+		if i == -1 {
+			break
+		}
+		count++
+	}
+	return NewInt(count).ToObject(), nil
 }
 
 func listDelItem(f *Frame, o *Object, key *Object) *BaseException {
@@ -221,7 +338,7 @@ func listDelItem(f *Frame, o *Object, key *Object) *BaseException {
 		format := "list indices must be integers, not %s"
 		return f.RaiseType(TypeErrorType, fmt.Sprintf(format, key.Type().Name()))
 	}
-	index, raised := IndexInt(f, key)
+	index, raised := indexIntChecked(f, key, IndexErrorType)
 	if raised != nil {
 		return raised
 	}
@@ -232,21 +349,23 @@ func listRemove(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "remove", args, ListType, ObjectType); raised != nil {
 		return nil, raised
 	}
-	value := args[1]
 	l := toListUnsafe(args[0])
-	l.mutex.Lock()
-	index, raised := seqFindElem(f, l.elems, value)
-	if raised == nil {
-		if index != -1 {
-			l.elems = append(l.elems[:index], l.elems[index+1:]...)
-		} else {
-			raised = f.RaiseType(ValueErrorType, "list.remove(x): x not in list")
-		}
-	}
-	l.mutex.Unlock()
+	index, raised := l.find(f, args[1], 0, MaxInt)
 	if raised != nil {
 		return nil, raised
 	}
+	if index == -1 {
+		return nil, f.RaiseType(ValueErrorType, "list.remove(x): x not in list")
+	}
+	// Like CPython, remove the element at the matching index even if l was
+	// modified by __eq__.
+	l.mutex.Lock()
+	if numElems := len(l.elems); index < numElems {
+		copy(l.elems[index:], l.elems[index+1:])
+		l.elems[numElems-1] = nil
+		l.elems = l.elems[:numElems-1]
+	}
+	l.mutex.Unlock()
 	return None, nil
 }
 
@@ -292,14 +411,19 @@ func listGT(f *Frame, v, w *Object) (*Object, *BaseException) {
 }
 
 func listIAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
-	l := toListUnsafe(v)
-	raised := seqForEach(f, w, func(o *Object) *BaseException {
-		l.Append(o)
-		return nil
-	})
+	// Collect the new elements before locking l since w may be l itself.
+	elems, raised := seqCopy(f, w)
 	if raised != nil {
 		return nil, raised
 	}
+	l := toListUnsafe(v)
+	l.mutex.RLock()
+	n := len(l.elems) + len(elems)
+	l.mutex.RUnlock()
+	if raised := f.checkAlloc(n); raised != nil {
+		return nil, raised
+	}
+	l.extend(elems)
 	return v, nil
 }
 
@@ -321,23 +445,33 @@ func listIMul(f *Frame, v, w *Object) (*Object, *BaseException) {
 }
 
 func listInsert(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	if raised := checkMethodArgs(f, "insert", args, ListType, IntType, ObjectType); raised != nil {
+	if raised := checkMethodArgs(f, "insert", args, ListType, ObjectType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	index, raised := listIndexArg(f, args[1])
+	if raised != nil {
 		return nil, raised
 	}
 	l := toListUnsafe(args[0])
 	l.mutex.Lock()
-	elems := l.elems
-	numElems := len(elems)
-	i := seqClampIndex(toIntUnsafe(args[1]).Value(), numElems)
+	numElems := len(l.elems)
+	i := seqClampIndex(index, numElems)
 	l.resize(numElems + 1)
-	// TODO: The resize() above may have done a copy so we're doing a lot
-	// of extra work here. Optimize this.
-	copy(l.elems[i+1:], elems[i:])
+	copy(l.elems[i+1:], l.elems[i:numElems])
 	l.elems[i] = args[2]
 	l.mutex.Unlock()
 	return None, nil
 }
 
+// listIndexArg converts o, the index argument to insert or pop, to an int.
+// Like CPython, o must support __index__.
+func listIndexArg(f *Frame, o *Object) (int, *BaseException) {
+	if o.typ.slots.Index == nil {
+		return 0, f.RaiseType(TypeErrorType, "an integer is required")
+	}
+	return indexIntChecked(f, o, OverflowErrorType)
+}
+
 func listIter(f *Frame, o *Object) (*Object, *BaseException) {
 	return newListIterator(toListUnsafe(o)), nil
 }
@@ -369,10 +503,14 @@ func listLT(f *Frame, v, w *Object) (*Object, *BaseException) {
 }
 
 func listMul(f *Frame, v, w *Object) (*Object, *BaseException) {
-	if !w.isInstance(IntType) {
+	if !w.isInstance(IntType) && !w.isInstance(LongType) {
 		return NotImplemented, nil
 	}
-	l, n := toListUnsafe(v), toIntUnsafe(w).Value()
+	n, raised := indexIntChecked(f, w, OverflowErrorType)
+	if raised != nil {
+		return nil, raised
+	}
+	l := toListUnsafe(v)
 	l.mutex.RLock()
 	elems, raised := seqMul(f, l.elems, n)
 	l.mutex.RUnlock()
@@ -397,37 +535,32 @@ func listIndex(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 		return nil, raised
 	}
 	l := toListUnsafe(args[0])
-	l.mutex.RLock()
-	numElems := len(l.elems)
-	start, stop := 0, numElems
+	start, stop := 0, MaxInt
 	if argc > 2 {
 		start, raised = IndexInt(f, args[2])
 		if raised != nil {
-			l.mutex.RUnlock()
 			return nil, raised
 		}
 	}
 	if argc > 3 {
 		stop, raised = IndexInt(f, args[3])
 		if raised != nil {
-			l.mutex.RUnlock()
 			return nil, raised
 		}
 	}
+	l.mutex.RLock()
+	numElems := len(l.elems)
+	l.mutex.RUnlock()
 	start, stop = adjustIndex(start, stop, numElems)
 	value := args[1]
-	index := -1
-	if start < numElems && start < stop {
-		index, raised = seqFindElem(f, l.elems[start:stop], value)
-	}
-	l.mutex.RUnlock()
+	index, raised := l.find(f, value, start, stop)
 	if raised != nil {
 		return nil, raised
 	}
 	if index == -1 {
 		return nil, f.RaiseType(ValueErrorType, fmt.Sprintf("%v is not in list", value))
 	}
-	return NewInt(index + start).ToObject(), nil
+	return NewInt(index).ToObject(), nil
 }
 
 func listPop(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
@@ -442,7 +575,7 @@ func listPop(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	i := -1
 	if argc == 2 {
 		var raised *BaseException
-		i, raised = ToIntValue(f, args[1])
+		i, raised = listIndexArg(f, args[1])
 		if raised != nil {
 			return nil, raised
 		}
@@ -455,11 +588,15 @@ func listPop(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	}
 	var item *Object
 	var raised *BaseException
-	if i >= numElems || i < 0 {
-		raised = f.RaiseType(IndexErrorType, "list index out of range")
+	if numElems == 0 {
+		raised = f.RaiseType(IndexErrorType, "pop from empty list")
+	} else if i >= numElems || i < 0 {
+		raised = f.RaiseType(IndexErrorType, "pop index out of range")
 	} else {
 		item = l.elems[i]
-		l.elems = append(l.elems[:i], l.elems[i+1:]...)
+		copy(l.elems[i:], l.elems[i+1:])
+		l.elems[numElems-1] = nil
+		l.elems = l.elems[:numElems-1]
 	}
 	l.mutex.Unlock()
 	return item, raised
@@ -498,7 +635,7 @@ func listReverse(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 func listSetItem(f *Frame, o, key, value *Object) *BaseException {
 	l := toListUnsafe(o)
 	if key.typ.slots.Index != nil {
-		i, raised := IndexInt(f, key)
+		i, raised := indexIntChecked(f, key, IndexErrorType)
 		if raised != nil {
 			return raised
 		}
@@ -510,13 +647,28 @@ func listSetItem(f *Frame, o, key, value *Object) *BaseException {
 	return f.RaiseType(TypeErrorType, fmt.Sprintf("list indices must be integers, not %s", key.Type().Name()))
 }
 
-func listSort(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	// TODO: Support (cmp=None, key=None, reverse=False)
-	if raised := checkMethodArgs(f, "sort", args, ListType); raised != nil {
+func listSort(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) == 0 || !args[0].isInstance(ListType) {
+		return nil, checkMethodArgs(f, "sort", args, ListType)
+	}
+	var validated [4]*Object
+	if raised := listSortParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	var cmp, key *Object
+	if validated[1] != None {
+		cmp = validated[1]
+	}
+	if validated[2] != None {
+		key = validated[2]
+	}
+	reverse, raised := IsTrue(f, validated[3])
+	if raised != nil {
+		return nil, raised
+	}
+	if raised := toListUnsafe(args[0]).sort(f, cmp, key, reverse); raised != nil {
 		return nil, raised
 	}
-	l := toListUnsafe(args[0])
-	l.Sort(f)
 	return None, nil
 }
 
@@ -613,30 +765,99 @@ func listCompare(f *Frame, v *List, w *Object, cmp binaryOpFunc) (*Object, *Base
 	return ret, raised
 }
 
+type listSortElem struct {
+	key, value *Object
+}
+
 type listSorter struct {
-	f      *Frame
-	l      *List
-	raised *BaseException
+	f     *Frame
+	cmp   *Object
+	elems []listSortElem
 }
 
-func (s *listSorter) Len() int {
-	return len(s.l.elems)
+func (s *listSorter) computeKeys(key *Object) *BaseException {
+	if key == nil {
+		return nil
+	}
+	for i := range s.elems {
+		k, raised := key.Call(s.f, Args{s.elems[i].value}, nil)
+		if raised != nil {
+			return raised
+		}
+		s.elems[i].key = k
+	}
+	return nil
 }
 
-func (s *listSorter) Less(i, j int) bool {
-	lt, raised := LT(s.f, s.l.elems[i], s.l.elems[j])
-	if raised != nil {
-		s.raised = raised
-		panic(s)
+func (s *listSorter) less(v, w *Object) (bool, *BaseException) {
+	if s.cmp == nil {
+		lt, raised := LT(s.f, v, w)
+		if raised != nil {
+			return false, raised
+		}
+		return IsTrue(s.f, lt)
 	}
-	ret, raised := IsTrue(s.f, lt)
+	result, raised := s.cmp.Call(s.f, Args{v, w}, nil)
 	if raised != nil {
-		s.raised = raised
-		panic(s)
+		return false, raised
+	}
+	switch {
+	case result.isInstance(IntType):
+		return toIntUnsafe(result).Value() < 0, nil
+	case result.isInstance(LongType):
+		return toLongUnsafe(result).Value().Sign() < 0, nil
 	}
-	return ret
+	format := "comparison function must return int, not %s"
+	return false, s.f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
 }
 
-func (s *listSorter) Swap(i, j int) {
-	s.l.elems[i], s.l.elems[j] = s.l.elems[j], s.l.elems[i]
+// mergeSort stably sorts elems using buf, which must be the same length, as
+// scratch space.
+func (s *listSorter) mergeSort(elems, buf []listSortElem) *BaseException {
+	n := len(elems)
+	if n < 2 {
+		return nil
+	}
+	mid := n / 2
+	if raised := s.mergeSort(elems[:mid], buf[:mid]); raised != nil {
+		return raised
+	}
+	if raised := s.mergeSort(elems[mid:], buf[mid:]); raised != nil {
+		return raised
+	}
+	// Skip the merge if the halves are already in order.
+	lt, raised := s.less(elems[mid].key, elems[mid-1].key)
+	if raised != nil || !lt {
+		return raised
+	}
+	copy(buf, elems)
+	i, j, k := 0, mid, 0
+	for i < mid && j < n {
+		// Take from the right half only when strictly less so that
+		// equal elements keep their relative order.
+		lt, raised := s.less(buf[j].key, buf[i].key)
+		if raised != nil {
+			// Leave elems as a permutation of its original
+			// contents.
+			copy(elems, buf)
+			return raised
+		}
+		if lt {
+			elems[k] = buf[j]
+			j++
+		} else {
+			elems[k] = buf[i]
+			i++
+		}
+		k++
+	}
+	k += copy(elems[k:], buf[i:mid])
+	copy(elems[k:], buf[j:n])
+	return nil
+}
+
+func (s *listSorter) reverse() {
+	for i, j := 0, len(s.elems)-1; i < j; i, j = i+1, j-1 {
+		s.elems[i], s.elems[j] = s.elems[j], s.elems[i]
+	}
 }
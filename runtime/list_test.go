@@ -53,6 +53,9 @@ func TestListBinaryOps(t *testing.T) {
 		{Mul, newObject(ObjectType), NewList(newObject(ObjectType)).ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for *: 'object' and 'list'")},
 		{Mul, NewList(newObject(ObjectType)).ToObject(), NewList().ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for *: 'list' and 'list'")},
 		{Mul, NewList(None, None).ToObject(), NewInt(MaxInt).ToObject(), nil, mustCreateException(OverflowErrorType, "result too large")},
+		{Mul, newTestList(1).ToObject(), NewLong(big.NewInt(2)).ToObject(), newTestList(1, 1).ToObject(), nil},
+		{Mul, NewLong(big.NewInt(2)).ToObject(), newTestList(1).ToObject(), newTestList(1, 1).ToObject(), nil},
+		{Mul, newTestList(1).ToObject(), NewLong(new(big.Int).Lsh(big.NewInt(1), 100)).ToObject(), nil, mustCreateException(OverflowErrorType, "cannot fit 'long' into an index-sized integer")},
 	}
 	for _, cas := range cases {
 		testCase := invokeTestCase{args: wrapArgs(cas.v, cas.w), want: cas.want, wantExc: cas.wantExc}
@@ -83,6 +86,7 @@ func TestListCount(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewList(), NewInt(1)), want: NewInt(0).ToObject()},
 		{args: wrapArgs(NewList(None, None, None), None), want: NewInt(3).ToObject()},
+		{args: wrapArgs(newTestList(1, "foo", 1, 2), 1), want: NewInt(2).ToObject()},
 		{args: wrapArgs(newTestList()), wantExc: mustCreateException(TypeErrorType, "'count' of 'list' requires 2 arguments")},
 	}
 	for _, cas := range cases {
@@ -110,6 +114,8 @@ func TestListDelItem(t *testing.T) {
 		{args: wrapArgs(newTestRange(3), 0), want: newTestList(1, 2).ToObject()},
 		{args: wrapArgs(newTestRange(3), 2), want: newTestList(0, 1).ToObject()},
 		{args: wrapArgs(NewList(), 101), wantExc: mustCreateException(IndexErrorType, "index out of range")},
+		{args: wrapArgs(newTestList(1, 2), new(big.Int).Lsh(big.NewInt(1), 100)), wantExc: mustCreateException(IndexErrorType, "cannot fit 'long' into an index-sized integer")},
+		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(new(big.Int).Lsh(big.NewInt(-1), 100), big.NewInt(2))), want: newTestList(3).ToObject()},
 		{args: wrapArgs(NewList(), newTestSlice(50, 100)), want: NewList().ToObject()},
 		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(1, 3, None)), want: newTestList(1, 4, 5).ToObject()},
 		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(1, None, 2)), want: newTestList(1, 3, 5).ToObject()},
@@ -119,6 +125,10 @@ func TestListDelItem(t *testing.T) {
 		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(1.0, 3, None)), wantExc: mustCreateException(TypeErrorType, errBadSliceIndex)},
 		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(None, None, 4)), want: newTestList(2, 3, 4).ToObject()},
 		{args: wrapArgs(newTestRange(10), newTestSlice(1, 8, 3)), want: newTestList(0, 2, 3, 5, 6, 8, 9).ToObject()},
+		{args: wrapArgs(newTestRange(10), newTestSlice(None, None, -3)), want: newTestList(1, 2, 4, 5, 7, 8).ToObject()},
+		{args: wrapArgs(newTestRange(10), newTestSlice(8, 1, -2)), want: newTestList(0, 1, 3, 5, 7, 9).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(None, None, -1)), want: NewList().ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(1, 2, -1)), want: newTestList(1, 2, 3).ToObject()},
 		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(1, None, 0)), wantExc: mustCreateException(ValueErrorType, "slice step cannot be zero")},
 		{args: wrapArgs(newTestList(true), None), wantExc: mustCreateException(TypeErrorType, "list indices must be integers, not NoneType")},
 		{args: wrapArgs(newTestList(true), newObject(badIndexType)), wantExc: mustCreateException(ValueErrorType, "wut")},
@@ -186,6 +196,39 @@ func TestListRemove(t *testing.T) {
 	}
 }
 
+// TestListModifiedByEq checks that list methods which compare elements don't
+// hold the list's lock while calling __eq__, which may modify the list.
+func TestListModifiedByEq(t *testing.T) {
+	var l *List
+	clearOnEqType := newTestClass("ClearOnEq", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__eq__": newBuiltinFunction("__eq__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			if raised := l.DelSlice(f, toSliceUnsafe(newTestSlice(None, None))); raised != nil {
+				return nil, raised
+			}
+			return False.ToObject(), nil
+		}).ToObject(),
+	}))
+	cases := []struct {
+		method  string
+		want    *Object
+		wantExc *BaseException
+	}{
+		{"count", NewInt(0).ToObject(), nil},
+		{"index", nil, mustCreateException(ValueErrorType, "42 is not in list")},
+		{"remove", nil, mustCreateException(ValueErrorType, "list.remove(x): x not in list")},
+	}
+	for _, cas := range cases {
+		l = newTestList(newObject(clearOnEqType), 1, 2)
+		testCase := invokeTestCase{args: wrapArgs(l, 42), want: cas.want, wantExc: cas.wantExc}
+		if err := runInvokeMethodTestCase(ListType, cas.method, &testCase); err != "" {
+			t.Error(err)
+		}
+		if len(l.elems) != 0 {
+			t.Errorf("%s() left %v, want []", cas.method, l)
+		}
+	}
+}
+
 func BenchmarkListContains(b *testing.B) {
 	b.Run("false-3", func(b *testing.B) {
 		t := newTestList("foo", 42, "bar").ToObject()
@@ -242,6 +285,8 @@ func TestListGetItem(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(newTestRange(20), 0), want: NewInt(0).ToObject()},
 		{args: wrapArgs(newTestRange(20), 19), want: NewInt(19).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), new(big.Int).Lsh(big.NewInt(1), 100)), wantExc: mustCreateException(IndexErrorType, "cannot fit 'long' into an index-sized integer")},
+		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(new(big.Int).Lsh(big.NewInt(-1), 100), big.NewInt(2))), want: newTestList(1, 2).ToObject()},
 		{args: wrapArgs(NewList(), 101), wantExc: mustCreateException(IndexErrorType, "index out of range")},
 		{args: wrapArgs(NewList(), newTestSlice(50, 100)), want: NewList().ToObject()},
 		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(1, 3, None)), want: newTestList(2, 3).ToObject()},
@@ -261,6 +306,7 @@ func TestListGetItem(t *testing.T) {
 }
 
 func TestListInplaceOps(t *testing.T) {
+	self := newTestList(1, 2).ToObject()
 	cases := []struct {
 		fun     func(f *Frame, v, w *Object) (*Object, *BaseException)
 		v, w    *Object
@@ -270,6 +316,7 @@ func TestListInplaceOps(t *testing.T) {
 		{IAdd, newTestList(3).ToObject(), newTestList("foo").ToObject(), newTestList(3, "foo").ToObject(), nil},
 		{IAdd, NewList(None).ToObject(), NewList().ToObject(), NewList(None).ToObject(), nil},
 		{IAdd, NewList().ToObject(), newObject(ObjectType), nil, mustCreateException(TypeErrorType, "'object' object is not iterable")},
+		{IAdd, self, self, newTestList(1, 2, 1, 2).ToObject(), nil},
 		{IMul, NewList().ToObject(), NewInt(10).ToObject(), NewList().ToObject(), nil},
 		{IMul, newTestList("baz").ToObject(), NewInt(-2).ToObject(), NewList().ToObject(), nil},
 		{IMul, NewList().ToObject(), None, nil, mustCreateException(TypeErrorType, "can't multiply sequence by non-int of type 'NoneType'")},
@@ -327,6 +374,37 @@ func TestListAppend(t *testing.T) {
 	}
 }
 
+func TestListAppendFunc(t *testing.T) {
+	appendCalled := false
+	listSubclass := newTestClass("ListSubclass", []*Type{ListType}, newStringDict(map[string]*Object{
+		"append": newBuiltinFunction("append", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+			appendCalled = true
+			toListUnsafe(args[0]).Append(args[1])
+			return None, nil
+		}).ToObject(),
+	}))
+	fun := wrapFuncForTest(func(f *Frame, o, v *Object) (*Object, *BaseException) {
+		if raised := ListAppend(f, o, v); raised != nil {
+			return nil, raised
+		}
+		return o, nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewList(), None), want: NewList(None).ToObject()},
+		{args: wrapArgs(newTestRange(100), 100), want: newTestRange(101).ToObject()},
+		{args: wrapArgs(newObject(listSubclass), 42), want: newTestList(42).ToObject()},
+		{args: wrapArgs(NewDict(), 42), wantExc: mustCreateException(AttributeErrorType, "'dict' object has no attribute 'append'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	if !appendCalled {
+		t.Error("ListAppend did not call the append method of a list subclass")
+	}
+}
+
 func TestListExtend(t *testing.T) {
 	extend := mustNotRaise(GetAttr(NewRootFrame(), ListType.ToObject(), NewStr("extend"), nil))
 	fun := newBuiltinFunction("TestListExtend", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
@@ -376,6 +454,11 @@ func TestListNew(t *testing.T) {
 		{args: wrapArgs(newTestTuple(1, 2, 3)), want: newTestList(1, 2, 3).ToObject()},
 		{args: wrapArgs(newTestDict(1, "foo", "bar", None)), want: newTestList(1, "bar").ToObject()},
 		{args: wrapArgs(42), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+		{args: wrapArgs(newTestSizedIterable(NewInt(3).ToObject(), nil)), want: newTestList("foo", "bar").ToObject()},
+		{args: wrapArgs(newTestSizedIterable(NewInt(-1).ToObject(), nil)), want: newTestList("foo", "bar").ToObject()},
+		{args: wrapArgs(newTestSizedIterable(None, nil)), want: newTestList("foo", "bar").ToObject()},
+		{args: wrapArgs(newTestSizedIterable(nil, mustCreateException(TypeErrorType, "foo"))), want: newTestList("foo", "bar").ToObject()},
+		{args: wrapArgs(newTestSizedIterable(nil, mustCreateException(ValueErrorType, "foo"))), wantExc: mustCreateException(ValueErrorType, "foo")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(ListType.ToObject(), &cas); err != "" {
@@ -441,8 +524,12 @@ func TestListInsert(t *testing.T) {
 		{args: wrapArgs(newTestList("foo", "bar"), 101, "baz"), want: newTestList("foo", "bar", "baz").ToObject()},
 		{args: wrapArgs(newTestList("a", "c"), 1, "b"), want: newTestList("a", "b", "c").ToObject()},
 		{args: wrapArgs(newTestList(1, 2), 0, 0), want: newTestList(0, 1, 2).ToObject()},
+		{args: wrapArgs(newTestList("a", "c"), big.NewInt(1), "b"), want: newTestList("a", "b", "c").ToObject()},
+		{args: wrapArgs(NewList(), new(big.Int).Lsh(big.NewInt(1), 100), 1), wantExc: mustCreateException(OverflowErrorType, "cannot fit 'long' into an index-sized integer")},
+		{args: wrapArgs(newTestList("a", "b"), -1, "c"), want: newTestList("a", "c", "b").ToObject()},
+		{args: wrapArgs(NewList(), 1.5, "a"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{args: wrapArgs(NewList()), wantExc: mustCreateException(TypeErrorType, "'insert' of 'list' requires 3 arguments")},
-		{args: wrapArgs(NewList(), "foo", 123), wantExc: mustCreateException(TypeErrorType, "'insert' requires a 'int' object but received a 'str'")},
+		{args: wrapArgs(NewList(), "foo", 123), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -508,10 +595,13 @@ func TestListPop(t *testing.T) {
 		{args: wrapArgs(newTestList(-1, 0, 1), NewLong(big.NewInt(1))), want: newTestTuple(0, newTestList(-1, 1).ToObject()).ToObject()},
 		{args: wrapArgs(newTestList(-1, 0, 1), None), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 		{args: wrapArgs(newTestList(-1, 0, 1), None), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
-		{args: wrapArgs(newTestList(-1, 0, 1), 3), wantExc: mustCreateException(IndexErrorType, "list index out of range")},
-		{args: wrapArgs(newTestList()), wantExc: mustCreateException(IndexErrorType, "list index out of range")},
-		{args: wrapArgs(newTestList(), 0), wantExc: mustCreateException(IndexErrorType, "list index out of range")},
-		{args: wrapArgs(newTestList(), 1), wantExc: mustCreateException(IndexErrorType, "list index out of range")},
+		{args: wrapArgs(newTestList(-1, 0, 1), -2), want: newTestTuple(0, newTestList(-1, 1).ToObject()).ToObject()},
+		{args: wrapArgs(newTestList(-1, 0, 1), 3), wantExc: mustCreateException(IndexErrorType, "pop index out of range")},
+		{args: wrapArgs(newTestList(-1, 0, 1), -4), wantExc: mustCreateException(IndexErrorType, "pop index out of range")},
+		{args: wrapArgs(newTestList(-1, 0, 1), 2.0), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
+		{args: wrapArgs(newTestList()), wantExc: mustCreateException(IndexErrorType, "pop from empty list")},
+		{args: wrapArgs(newTestList(), 0), wantExc: mustCreateException(IndexErrorType, "pop from empty list")},
+		{args: wrapArgs(newTestList(), 1), wantExc: mustCreateException(IndexErrorType, "pop from empty list")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -537,6 +627,8 @@ func TestListSetItem(t *testing.T) {
 		}
 		return args[0], nil
 	}).ToObject()
+	// Assigning a list to a slice of itself must not deadlock.
+	selfAssigned := newTestList(1, 2, 3)
 	cases := []invokeTestCase{
 		{args: wrapArgs(newTestList("foo", "bar"), 1, None), want: newTestList("foo", None).ToObject()},
 		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(0), newTestList(0)), want: newTestList(0, 1, 2, 3).ToObject()},
@@ -546,9 +638,16 @@ func TestListSetItem(t *testing.T) {
 		{args: wrapArgs(newTestList(1, 2, 4, 5), newTestSlice(1, None, 2), newTestTuple("foo", "bar")), want: newTestList(1, "foo", 4, "bar").ToObject()},
 		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(None, None, 2), newTestList("foo")), wantExc: mustCreateException(ValueErrorType, "attempt to assign sequence of size 1 to extended slice of size 2")},
 		{args: wrapArgs(newTestRange(100), newTestSlice(None, None), NewList()), want: NewList().ToObject()},
+		{args: wrapArgs(selfAssigned, newTestSlice(1, 2), selfAssigned), want: newTestList(1, 1, 2, 3, 3).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3, 4), newTestSlice(1, 3), newTestTuple("foo")), want: newTestList(1, "foo", 4).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(None, None, -2), newTestTuple("a", "b", "c")), want: newTestList("c", 2, "b", 4, "a").ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(None, None, -1), newTestList(4, 5, 6)), want: newTestList(6, 5, 4).ToObject()},
+		{args: wrapArgs(newTestList(1, 2, 3, 4, 5), newTestSlice(4, 1, -1), NewList()), wantExc: mustCreateException(ValueErrorType, "attempt to assign sequence of size 0 to extended slice of size 3")},
+		{args: wrapArgs(newTestList(1, 2, 3), newTestSlice(None, None, 2), 42), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
 		{args: wrapArgs(NewList(), newTestSlice(4, 8, 0), NewList()), wantExc: mustCreateException(ValueErrorType, "slice step cannot be zero")},
 		{args: wrapArgs(newTestList("foo", "bar"), -100, None), wantExc: mustCreateException(IndexErrorType, "index out of range")},
 		{args: wrapArgs(NewList(), 101, None), wantExc: mustCreateException(IndexErrorType, "index out of range")},
+		{args: wrapArgs(NewList(), new(big.Int).Lsh(big.NewInt(1), 100), None), wantExc: mustCreateException(IndexErrorType, "cannot fit 'long' into an index-sized integer")},
 		{args: wrapArgs(newTestList(true), None, false), wantExc: mustCreateException(TypeErrorType, "list indices must be integers, not NoneType")},
 	}
 	for _, cas := range cases {
@@ -573,13 +672,94 @@ func TestListSort(t *testing.T) {
 		{args: wrapArgs(newTestList(1, 2, 0, 3)), want: newTestRange(4).ToObject()},
 		{args: wrapArgs(newTestRange(100)), want: newTestRange(100).ToObject()},
 		{args: wrapArgs(1), wantExc: mustCreateException(TypeErrorType, "unbound method sort() must be called with list instance as first argument (got int instance instead)")},
-		{args: wrapArgs(NewList(), 1), wantExc: mustCreateException(TypeErrorType, "'sort' of 'list' requires 1 arguments")},
+		{args: wrapArgs(NewList(), None, None, false, 1), wantExc: mustCreateException(TypeErrorType, "sort() takes 4 arguments (5 given)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestListSortArgs(t *testing.T) {
+	f := NewRootFrame()
+	sort := mustNotRaise(GetAttr(f, ListType.ToObject(), NewStr("sort"), nil))
+	fun := newBuiltinFunction("TestListSortArgs", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		if _, raised := sort.Call(f, args, kwargs); raised != nil {
+			return nil, raised
+		}
+		return args[0], nil
+	}).ToObject()
+	// Compare only the first element of pairs so that stability is
+	// observable via the second element.
+	cmpFirst := wrapFuncForTest(func(f *Frame, v, w *Tuple) (*Object, *BaseException) {
+		return Compare(f, v.elems[0], w.elems[0])
+	})
+	first := wrapFuncForTest(func(f *Frame, t *Tuple) *Object { return t.elems[0] })
+	descending := wrapFuncForTest(func(f *Frame, v, w int) int { return w - v })
+	badCmp := wrapFuncForTest(func(f *Frame, v, w *Object) string { return "foo" })
+	longCmp := wrapFuncForTest(func(f *Frame, v, w int) *Long { return NewLong(big.NewInt(int64(v - w))) })
+	pairs := func() *List {
+		return newTestList(newTestTuple(2, "a"), newTestTuple(1, "b"), newTestTuple(2, "c"), newTestTuple(1, "d"))
+	}
+	ascendingPairs := newTestList(newTestTuple(1, "b"), newTestTuple(1, "d"), newTestTuple(2, "a"), newTestTuple(2, "c")).ToObject()
+	descendingPairs := newTestList(newTestTuple(2, "a"), newTestTuple(2, "c"), newTestTuple(1, "b"), newTestTuple(1, "d")).ToObject()
+	reversedRange := newTestRange(100)
+	for i, j := 0, 99; i < j; i, j = i+1, j-1 {
+		reversedRange.elems[i], reversedRange.elems[j] = reversedRange.elems[j], reversedRange.elems[i]
+	}
+	mutated := newTestList(3, 1, 2)
+	mutate := wrapFuncForTest(func(f *Frame, v, w int) int {
+		mutated.Append(None)
+		return v - w
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestList(3, 1, 2), descending), want: newTestList(3, 2, 1).ToObject()},
+		{args: wrapArgs(pairs()), kwargs: wrapKWArgs("cmp", cmpFirst), want: ascendingPairs},
+		{args: wrapArgs(pairs()), kwargs: wrapKWArgs("key", first), want: ascendingPairs},
+		{args: wrapArgs(pairs()), kwargs: wrapKWArgs("key", first, "reverse", true), want: descendingPairs},
+		{args: wrapArgs(pairs(), None, first, 1), want: descendingPairs},
+		{args: wrapArgs(newTestRange(100), descending), want: reversedRange.ToObject()},
+		{args: wrapArgs(newTestList(3, 1, 2), longCmp), want: newTestList(1, 2, 3).ToObject()},
+		{args: wrapArgs(newTestList(1, 2), badCmp), wantExc: mustCreateException(TypeErrorType, "comparison function must return int, not str")},
+		{args: wrapArgs(mutated, mutate), wantExc: mustCreateException(ValueErrorType, "list modified during sort")},
+		{args: wrapArgs(NewList()), kwargs: wrapKWArgs("foo", 1), wantExc: mustCreateException(TypeErrorType, "sort() got an unexpected keyword argument 'foo'")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
 			t.Error(err)
 		}
 	}
+	// The list appears empty to key functions during the sort.
+	l := newTestList(2, 1)
+	var lens []int
+	lenDuringSort := wrapFuncForTest(func(f *Frame, o *Object) *Object {
+		lens = append(lens, len(l.elems))
+		return o
+	})
+	if _, raised := sort.Call(f, Args{l.ToObject()}, wrapKWArgs("key", lenDuringSort)); raised != nil {
+		t.Fatal(raised)
+	}
+	if want := []int{0, 0}; !reflect.DeepEqual(lens, want) {
+		t.Errorf("lengths seen during sort = %v, want %v", lens, want)
+	}
+}
+
+// newTestSizedIterable returns an object that iterates over ("foo", "bar")
+// and whose __len__ returns length or raises exc.
+func newTestSizedIterable(length *Object, exc *BaseException) *Object {
+	sizedType := newTestClass("Sized", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__iter__": newBuiltinFunction("__iter__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return Iter(f, newTestTuple("foo", "bar").ToObject())
+		}).ToObject(),
+		"__len__": newBuiltinFunction("__len__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			if exc != nil {
+				return nil, exc
+			}
+			return length, nil
+		}).ToObject(),
+	}))
+	return newObject(sizedType)
 }
 
 func newTestRange(n int) *List {
@@ -107,6 +107,13 @@ func longAnd(z, x, y *big.Int) {
 	z.And(x, y)
 }
 
+func longBitLength(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "bit_length", args, LongType); raised != nil {
+		return nil, raised
+	}
+	return NewInt(toLongUnsafe(args[0]).value.BitLen()).ToObject(), nil
+}
+
 func longDiv(z, x, y *big.Int) {
 	m := big.Int{}
 	longDivMod(x, y, z, &m)
@@ -128,7 +135,7 @@ func longGetNewArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "__getnewargs__", args, LongType); raised != nil {
 		return nil, raised
 	}
-	return NewTuple1(args[0]).ToObject(), nil
+	return NewTuple1(NewLong(toLongUnsafe(args[0]).Value()).ToObject()).ToObject(), nil
 }
 
 func longGT(x, y *big.Int) bool {
@@ -252,11 +259,11 @@ func longNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 			}
 			return result, nil
 		}
-		if raised := checkMethodArgs(f, "__new__", args, StrType); raised != nil {
+		if raised := checkMethodArgs(f, "__new__", args, BaseStringType); raised != nil {
 			return nil, raised
 		}
 	} else {
-		if raised := checkMethodArgs(f, "__new__", args, StrType, IntType); raised != nil {
+		if raised := checkMethodArgs(f, "__new__", args, BaseStringType, IntType); raised != nil {
 			return nil, raised
 		}
 		baseArg = toIntUnsafe(args[1]).Value()
@@ -264,7 +271,11 @@ func longNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 			return nil, f.RaiseType(ValueErrorType, "long() base must be >= 2 and <= 36")
 		}
 	}
-	s := strings.TrimSpace(toStrUnsafe(o).Value())
+	str, raised := basestringToStr(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	s := strings.TrimSpace(str.Value())
 	if len(s) > 0 && (s[len(s)-1] == 'L' || s[len(s)-1] == 'l') {
 		s = s[:len(s)-1]
 	}
@@ -337,6 +348,7 @@ func longXor(z, x, y *big.Int) {
 
 func initLongType(dict map[string]*Object) {
 	dict["__getnewargs__"] = newBuiltinFunction("__getnewargs__", longGetNewArgs).ToObject()
+	dict["bit_length"] = newBuiltinFunction("bit_length", longBitLength).ToObject()
 	LongType.slots.Abs = longUnaryOpSlot(longAbs)
 	LongType.slots.Add = longBinaryOpSlot(longAdd)
 	LongType.slots.And = longBinaryOpSlot(longAnd)
@@ -430,14 +442,14 @@ func longCallShift(fun func(z, x *big.Int, n uint), f *Frame, v, w *Long) (*Obje
 
 func longCallDivMod(fun func(z, x, y *big.Int), f *Frame, v, w *Long) (*Object, *BaseException) {
 	if w.value.Sign() == 0 {
-		return nil, f.RaiseType(ZeroDivisionErrorType, "integer division or modulo by zero")
+		return nil, f.RaiseType(ZeroDivisionErrorType, "long division or modulo by zero")
 	}
 	return longCallBinary(fun, v, w), nil
 }
 
 func longCallDivAndMod(fun func(z, m, x, y *big.Int), f *Frame, v, w *Long) (*Object, *BaseException) {
 	if w.value.Sign() == 0 {
-		return nil, f.RaiseType(ZeroDivisionErrorType, "integer division or modulo by zero")
+		return nil, f.RaiseType(ZeroDivisionErrorType, "long division or modulo by zero")
 	}
 	return longCallBinaryTuple(fun, v, w), nil
 }
@@ -615,6 +627,16 @@ func longPow(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return NewLong(big.NewInt(0).Exp(vLong, wLong, nil)).ToObject(), nil
 }
 
+// longPowMod sets z to x**y mod m. Like Python, the result takes the sign of
+// m. y must be non-negative and m must be non-zero.
+func longPowMod(z, x, y, m *big.Int) {
+	mAbs := new(big.Int).Abs(m)
+	z.Exp(x, y, mAbs)
+	if z.Sign() != 0 && m.Sign() < 0 {
+		z.Sub(z, mAbs)
+	}
+}
+
 func longRPow(f *Frame, v, w *Object) (*Object, *BaseException) {
 	if w.isInstance(LongType) {
 		return longPow(f, w, v)
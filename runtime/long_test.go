@@ -82,6 +82,8 @@ func TestLongNew(t *testing.T) {
 		{args: wrapArgs(LongType, " \t123L"), want: NewLong(big.NewInt(123)).ToObject()},
 		{args: wrapArgs(LongType, "123L \t"), want: NewLong(big.NewInt(123)).ToObject()},
 		{args: wrapArgs(LongType, "FF", 16), want: NewLong(big.NewInt(255)).ToObject()},
+		{args: wrapArgs(LongType, NewUnicode("FF"), 16), want: NewLong(big.NewInt(255)).ToObject()},
+		{args: wrapArgs(LongType, NewUnicode("123L")), want: NewLong(big.NewInt(123)).ToObject()},
 		{args: wrapArgs(LongType, "0xFFL", 16), want: NewLong(big.NewInt(255)).ToObject()},
 		{args: wrapArgs(LongType, "0xE", 0), want: NewLong(big.NewInt(14)).ToObject()},
 		{args: wrapArgs(LongType, "0b101L", 0), want: NewLong(big.NewInt(5)).ToObject()},
@@ -104,7 +106,7 @@ func TestLongNew(t *testing.T) {
 		{args: wrapArgs(LongType, NewInt(3).ToObject()), want: NewLong(big.NewInt(3)).ToObject()},
 		{args: wrapArgs(LongType, NewLong(big.NewInt(3))), want: NewLong(big.NewInt(3)).ToObject()},
 		{args: wrapArgs(LongType, NewLong(big.NewInt(3)).ToObject()), want: NewLong(big.NewInt(3)).ToObject()},
-		{args: wrapArgs(LongType, newObject(ObjectType)), wantExc: mustCreateException(TypeErrorType, "'__new__' requires a 'str' object but received a 'object'")},
+		{args: wrapArgs(LongType, newObject(ObjectType)), wantExc: mustCreateException(TypeErrorType, "'__new__' requires a 'basestring' object but received a 'object'")},
 		{args: wrapArgs(LongType, newObject(fooType)), wantExc: mustCreateException(TypeErrorType, "__long__ returned non-long (type Foo)")},
 	}
 	for _, cas := range cases {
@@ -131,7 +133,7 @@ func TestLongBinaryOps(t *testing.T) {
 		{Div, MaxInt, MinInt, NewLong(big.NewInt(-1)).ToObject(), nil},
 		{Div, MinInt, MaxInt, NewLong(big.NewInt(-2)).ToObject(), nil},
 		{Div, NewList().ToObject(), NewLong(big.NewInt(21)).ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for /: 'list' and 'long'")},
-		{Div, 1, 0, nil, mustCreateException(ZeroDivisionErrorType, "integer division or modulo by zero")},
+		{Div, 1, 0, nil, mustCreateException(ZeroDivisionErrorType, "long division or modulo by zero")},
 		{Div, MinInt, -1, NewLong(new(big.Int).Neg(minIntBig)).ToObject(), nil},
 		{DivMod, 7, 3, NewTuple2(NewLong(big.NewInt(2)).ToObject(), NewLong(big.NewInt(1)).ToObject()).ToObject(), nil},
 		{DivMod, 3, -7, NewTuple2(NewLong(big.NewInt(-1)).ToObject(), NewLong(big.NewInt(-4)).ToObject()).ToObject(), nil},
@@ -140,12 +142,12 @@ func TestLongBinaryOps(t *testing.T) {
 		{DivMod, MinInt, 1, NewTuple2(NewLong(big.NewInt(MinInt)).ToObject(), NewLong(big.NewInt(0)).ToObject()).ToObject(), nil},
 		{DivMod, MinInt, -1, NewTuple2(NewLong(new(big.Int).Neg(minIntBig)).ToObject(), NewLong(big.NewInt(0)).ToObject()).ToObject(), nil},
 		{DivMod, NewList().ToObject(), NewLong(big.NewInt(21)).ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for divmod(): 'list' and 'long'")},
-		{DivMod, 1, 0, nil, mustCreateException(ZeroDivisionErrorType, "integer division or modulo by zero")},
+		{DivMod, 1, 0, nil, mustCreateException(ZeroDivisionErrorType, "long division or modulo by zero")},
 		{FloorDiv, 7, 3, NewLong(big.NewInt(2)).ToObject(), nil},
 		{FloorDiv, MaxInt, MinInt, NewLong(big.NewInt(-1)).ToObject(), nil},
 		{FloorDiv, MinInt, MaxInt, NewLong(big.NewInt(-2)).ToObject(), nil},
 		{FloorDiv, NewList().ToObject(), NewLong(big.NewInt(21)).ToObject(), nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for //: 'list' and 'long'")},
-		{FloorDiv, 1, 0, nil, mustCreateException(ZeroDivisionErrorType, "integer division or modulo by zero")},
+		{FloorDiv, 1, 0, nil, mustCreateException(ZeroDivisionErrorType, "long division or modulo by zero")},
 		{FloorDiv, MinInt, -1, NewLong(new(big.Int).Neg(minIntBig)).ToObject(), nil},
 		{LShift, 2, 4, NewLong(big.NewInt(32)).ToObject(), nil},
 		{LShift, 12, 10, NewLong(big.NewInt(12288)).ToObject(), nil},
@@ -164,7 +166,7 @@ func TestLongBinaryOps(t *testing.T) {
 		{Mod, MaxInt, MinInt, NewLong(big.NewInt(-1)).ToObject(), nil},
 		{Mod, MinInt, MaxInt, NewLong(big.NewInt(int64(MaxInt) - 1)).ToObject(), nil},
 		{Mod, None, 4, nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for %: 'NoneType' and 'long'")},
-		{Mod, 10, 0, nil, mustCreateException(ZeroDivisionErrorType, "integer division or modulo by zero")},
+		{Mod, 10, 0, nil, mustCreateException(ZeroDivisionErrorType, "long division or modulo by zero")},
 		{Mod, MinInt, 1, NewLong(big.NewInt(0)).ToObject(), nil},
 		{Mul, 1, 3, NewLong(big.NewInt(3)).ToObject(), nil},
 		{Mul, newObject(ObjectType), 101, nil, mustCreateException(TypeErrorType, "unsupported operand type(s) for *: 'object' and 'long'")},
@@ -291,6 +293,21 @@ func TestLongInt(t *testing.T) {
 	}
 }
 
+func TestLongBitLength(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(big.NewInt(0)), want: NewInt(0).ToObject()},
+		{args: wrapArgs(big.NewInt(-1)), want: NewInt(1).ToObject()},
+		{args: wrapArgs(big.NewInt(1024)), want: NewInt(11).ToObject()},
+		{args: wrapArgs(new(big.Int).Lsh(big.NewInt(1), 100)), want: NewInt(101).ToObject()},
+		{args: wrapArgs(new(big.Int).Lsh(big.NewInt(-1), 100)), want: NewInt(101).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(LongType, "bit_length", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestLongFloat(t *testing.T) {
 	googol, _ := big.NewFloat(1e100).Int(nil)
 	cases := []invokeTestCase{
@@ -44,12 +44,7 @@ func methodCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object,
 	m := toMethodUnsafe(callable)
 	argc := len(args)
 	if m.self != nil {
-		methodArgs := f.MakeArgs(argc + 1)
-		methodArgs[0] = m.self
-		copy(methodArgs[1:], args)
-		result, raised := m.function.Call(f, methodArgs, kwargs)
-		f.FreeArgs(methodArgs)
-		return result, raised
+		return f.callPrepend(m.function, m.self, args, kwargs)
 	}
 	if argc < 1 {
 		className, raised := methodGetMemberName(f, m.class)
@@ -97,6 +92,22 @@ func methodGet(f *Frame, desc, instance *Object, owner *Type) (*Object, *BaseExc
 	return (&Method{Object{typ: MethodType}, m.function, instance, owner.ToObject(), m.name}).ToObject(), nil
 }
 
+// methodGetAttribute looks up name on the method itself and then, like
+// CPython, on the underlying function.
+func methodGetAttribute(f *Frame, o *Object, name *Str) (*Object, *BaseException) {
+	result, raised := objectGetAttribute(f, o, name)
+	if raised == nil || !raised.isInstance(AttributeErrorType) {
+		return result, raised
+	}
+	exc, tb := f.RestoreExc(nil, nil)
+	result, fnRaised := GetAttr(f, toMethodUnsafe(o).function, name, nil)
+	if fnRaised == nil || !fnRaised.isInstance(AttributeErrorType) {
+		return result, fnRaised
+	}
+	f.RestoreExc(exc, tb)
+	return nil, raised
+}
+
 func methodNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 	expectedTypes := []*Type{ObjectType, ObjectType, ObjectType}
 	argc := len(args)
@@ -154,6 +165,7 @@ func initMethodType(map[string]*Object) {
 	MethodType.flags &= ^typeFlagBasetype
 	MethodType.slots.Call = &callSlot{methodCall}
 	MethodType.slots.Get = &getSlot{methodGet}
+	MethodType.slots.GetAttribute = &getAttributeSlot{methodGetAttribute}
 	MethodType.slots.Repr = &unaryOpSlot{methodRepr}
 	MethodType.slots.New = &newSlot{methodNew}
 }
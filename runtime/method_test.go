@@ -76,6 +76,33 @@ func TestMethodGet(t *testing.T) {
 	}
 }
 
+func TestMethodGetAttribute(t *testing.T) {
+	fun := newBuiltinFunction("fun", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+		return None, nil
+	}).ToObject()
+	funcWithAttr := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__call__": fun,
+	}))
+	o := newObject(funcWithAttr)
+	if raised := SetAttr(NewRootFrame(), o, NewStr("bar"), NewInt(42).ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
+	method := newTestMethod(o, NewStr("self").ToObject(), None)
+	getAttr := wrapFuncForTest(func(f *Frame, o *Object, name *Str) (*Object, *BaseException) {
+		return GetAttr(f, o, name, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(method, "bar"), want: NewInt(42).ToObject()},
+		{args: wrapArgs(method, "im_func"), want: o},
+		{args: wrapArgs(method, "baz"), wantExc: mustCreateException(AttributeErrorType, "'instancemethod' object has no attribute 'baz'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(getAttr, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestMethodNew(t *testing.T) {
 	cases := []invokeTestCase{
 		{wantExc: mustCreateException(TypeErrorType, "'__new__' requires 3 arguments")},
@@ -17,6 +17,7 @@ package grumpy
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime/pprof"
 	"strings"
@@ -34,6 +35,9 @@ const (
 var (
 	importMutex    sync.Mutex
 	moduleRegistry = map[string]*Code{}
+	// resourceRegistry maps package names to the data files embedded in
+	// them, keyed by slash separated path relative to the package.
+	resourceRegistry = map[string]map[string]string{}
 	// ModuleType is the object representing the Python 'module' type.
 	ModuleType = newBasisType("module", reflect.TypeOf(Module{}), toModuleUnsafe, ObjectType)
 	// SysModules is the global dict of imported modules, aka sys.modules.
@@ -68,6 +72,38 @@ func RegisterModule(name string, c *Code) {
 	}
 }
 
+// RegisterResource embeds data as the named resource of the given package so
+// that it can be retrieved at runtime via GetResource. pkg is the dotted name
+// of the package containing the resource, or "" for top level modules, and
+// name is a slash separated path relative to the package directory.
+func RegisterResource(pkg, name, data string) {
+	err := ""
+	importMutex.Lock()
+	resources := resourceRegistry[pkg]
+	if resources == nil {
+		resources = map[string]string{}
+		resourceRegistry[pkg] = resources
+	}
+	if _, ok := resources[name]; ok {
+		err = fmt.Sprintf("resource already registered: %s in package %q", name, pkg)
+	} else {
+		resources[name] = data
+	}
+	importMutex.Unlock()
+	if err != "" {
+		logFatal(err)
+	}
+}
+
+// GetResource returns the data registered for the named resource of pkg via
+// RegisterResource. ok is false if no such resource exists.
+func GetResource(pkg, name string) (data string, ok bool) {
+	importMutex.Lock()
+	data, ok = resourceRegistry[pkg][name]
+	importMutex.Unlock()
+	return data, ok
+}
+
 // ImportModule takes a fully qualified module name (e.g. a.b.c) and a slice of
 // code objects where the name of the i'th module is the prefix of name
 // ending in the i'th dot. The number of dot delimited parts of name must be the
@@ -179,15 +215,35 @@ func importOne(f *Frame, name string) (*Object, *BaseException) {
 }
 
 // newModule creates a new Module object with the given fully qualified name
-// (e.g a.b.c) and its corresponding Python filename.
+// (e.g a.b.c) and its corresponding Python filename. __doc__ is initialized to
+// None and is subsequently bound by the module code if it has a docstring.
 func newModule(name, filename string) *Module {
 	d := newStringDict(map[string]*Object{
-		"__file__": NewStr(filename).ToObject(),
-		"__name__": NewStr(name).ToObject(),
+		"__doc__":     None,
+		"__file__":    NewStr(filename).ToObject(),
+		"__name__":    NewStr(name).ToObject(),
+		"__package__": modulePackage(name, filename),
 	})
 	return &Module{Object: Object{typ: ModuleType, dict: d}}
 }
 
+// modulePackage returns the value of __package__ for the module with the
+// given name and filename: the module's own name for packages, the name of the
+// containing package for submodules, an empty string for top level modules and
+// None for __main__.
+func modulePackage(name, filename string) *Object {
+	if name == "__main__" {
+		return None
+	}
+	if filepath.Base(filename) == "__init__.py" {
+		return NewStr(name).ToObject()
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return NewStr(name[:i]).ToObject()
+	}
+	return NewStr("").ToObject()
+}
+
 func toModuleUnsafe(o *Object) *Module {
 	return (*Module)(o.toPointer())
 }
@@ -270,9 +326,12 @@ func initModuleType(map[string]*Object) {
 // RunMain execs the given code object as a module under the name "__main__".
 // It handles any exceptions raised during module execution. If no exceptions
 // were raised then the return value is zero. If a SystemExit was raised then
-// the return value depends on its code attribute: None -> zero, integer values
-// are returned as-is. Other code values and exception types produce a return
-// value of 1.
+// the return value depends on its code attribute: None -> zero, int and long
+// values are returned as-is. Other code values are written to sys.stderr and
+// produce a return value of 1, as do other exception types, whose traceback is
+// written instead. Then sys.exitfunc is called, which runs the functions
+// registered with the atexit module, and sys.stdout and sys.stderr are
+// flushed before returning.
 func RunMain(code *Code) int {
 	if file := os.Getenv("GRUMPY_PROFILE"); file != "" {
 		f, err := os.Create(file)
@@ -284,35 +343,105 @@ func RunMain(code *Code) int {
 		}
 		defer pprof.StopCPUProfile()
 	}
-	m := newModule("__main__", code.filename)
+	f := newMainFrame(code.filename)
+	f.code = code
+	_, e := code.fn(f, nil)
+	exc, tb := f.ExcInfo()
+	printFinishLine(f)
+	f.RestoreExc(exc, tb)
+	defer flushStdStreams(f)
+	return runExitFunc(f, exitStatus(f, e))
+}
+
+// newMainFrame returns a root frame whose globals are the dict of a new
+// __main__ module, which is registered in sys.modules.
+func newMainFrame(filename string) *Frame {
+	m := newModule("__main__", filename)
 	m.state = moduleStateInitializing
 	f := NewRootFrame()
-	f.code = code
 	f.globals = m.Dict()
 	if raised := SysModules.SetItemString(f, "__main__", m.ToObject()); raised != nil {
 		Stderr.writeString(raised.String())
 	}
-	_, e := code.fn(f, nil)
+	return f
+}
+
+// exitStatus returns the process exit status for the exception e raised by
+// the main module, writing the traceback or exit message to sys.stderr as
+// described for RunMain.
+func exitStatus(f *Frame, e *BaseException) int {
 	if e == nil {
 		return 0
 	}
 	if !e.isInstance(SystemExitType) {
-		Stderr.writeString(FormatExc(f))
+		writeStderr(f, FormatExc(f))
 		return 1
 	}
 	f.RestoreExc(nil, nil)
 	o, raised := GetAttr(f, e.ToObject(), NewStr("code"), nil)
 	if raised != nil {
+		f.RestoreExc(nil, nil)
 		return 1
 	}
-	if o.isInstance(IntType) {
-		return toIntUnsafe(o).Value()
-	}
 	if o == None {
 		return 0
 	}
+	if o.isInstance(IntType) || o.isInstance(LongType) {
+		if i, raised := ToIntValue(f, o); raised == nil {
+			return i
+		}
+		f.RestoreExc(nil, nil)
+	}
 	if s, raised := ToStr(f, o); raised == nil {
-		Stderr.writeString(s.Value() + "\n")
+		writeStderr(f, s.Value()+"\n")
+	} else {
+		f.RestoreExc(nil, nil)
 	}
 	return 1
 }
+
+// runExitFunc calls sys.exitfunc, if it is set, as the program exits with the
+// given status and returns the final exit status. If the function raises
+// SystemExit then the status is determined by the exception as for RunMain.
+// Other exceptions are written to sys.stderr.
+func runExitFunc(f *Frame, status int) int {
+	f.RestoreExc(nil, nil)
+	sys, raised := SysModules.GetItemString(f, "sys")
+	if raised != nil || sys == nil {
+		f.RestoreExc(nil, nil)
+		return status
+	}
+	exitFunc, raised := GetAttr(f, sys, NewStr("exitfunc"), None)
+	if raised != nil {
+		f.RestoreExc(nil, nil)
+		return status
+	}
+	if exitFunc == None {
+		return status
+	}
+	if _, raised := exitFunc.Call(f, nil, nil); raised != nil {
+		if raised.isInstance(SystemExitType) {
+			return exitStatus(f, raised)
+		}
+		writeStderr(f, "Error in sys.exitfunc:\n"+FormatExc(f))
+		f.RestoreExc(nil, nil)
+	}
+	return status
+}
+
+// flushStdStreams flushes sys.stdout and sys.stderr, ignoring any errors, so
+// that output buffered by Python code is not lost when the process exits.
+func flushStdStreams(f *Frame) {
+	for _, name := range []string{"stdout", "stderr"} {
+		stream, raised := sysStream(f, name)
+		if raised == nil {
+			var flush *Object
+			if flush, raised = GetAttr(f, stream, NewStr("flush"), None); raised == nil && flush != None {
+				_, raised = flush.Call(f, nil, nil)
+			}
+		}
+		if raised != nil {
+			f.RestoreExc(nil, nil)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package grumpy
 
 import (
 	"io/ioutil"
+	"math/big"
 	"os"
 	"testing"
 )
@@ -209,6 +210,61 @@ func TestModuleGetNameAndFilename(t *testing.T) {
 	}
 }
 
+func TestNewModuleAttrs(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, name, filename string) (*Tuple, *BaseException) {
+		d := newModule(name, filename).Dict()
+		doc, raised := d.GetItemString(f, "__doc__")
+		if raised != nil {
+			return nil, raised
+		}
+		pkg, raised := d.GetItemString(f, "__package__")
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(doc, pkg), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("foo", "foo.py"), want: newTestTuple(None, "").ToObject()},
+		{args: wrapArgs("foo.bar", "foo/bar.py"), want: newTestTuple(None, "foo").ToObject()},
+		{args: wrapArgs("foo.bar", "foo/bar/__init__.py"), want: newTestTuple(None, "foo.bar").ToObject()},
+		{args: wrapArgs("__main__", "main.py"), want: newTestTuple(None, None).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestRegisterResource(t *testing.T) {
+	RegisterResource("registerresourcetest.pkg", "data/foo.txt", "foo\x00bar")
+	RegisterResource("", "registerresourcetest.txt", "baz")
+	cases := []struct {
+		pkg, name string
+		want      string
+		wantOK    bool
+	}{
+		{"registerresourcetest.pkg", "data/foo.txt", "foo\x00bar", true},
+		{"", "registerresourcetest.txt", "baz", true},
+		{"registerresourcetest.pkg", "data/bar.txt", "", false},
+		{"registerresourcetest", "data/foo.txt", "", false},
+	}
+	for _, cas := range cases {
+		if got, ok := GetResource(cas.pkg, cas.name); got != cas.want || ok != cas.wantOK {
+			t.Errorf("GetResource(%q, %q) = (%q, %v), want (%q, %v)", cas.pkg, cas.name, got, ok, cas.want, cas.wantOK)
+		}
+	}
+	oldLogFatal := logFatal
+	logFatal = func(msg string) { panic(msg) }
+	defer func() {
+		logFatal = oldLogFatal
+		if e := recover(); e == nil {
+			t.Error("RegisterResource with duplicate name didn't call logFatal")
+		}
+	}()
+	RegisterResource("", "registerresourcetest.txt", "qux")
+}
+
 func TestModuleInit(t *testing.T) {
 	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Tuple, *BaseException) {
 		o, raised := ModuleType.Call(f, args, nil)
@@ -269,11 +325,20 @@ func TestRunMain(t *testing.T) {
 		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
 			return nil, f.Raise(SystemExitType.ToObject(), None, nil)
 		}), 0, ""},
-		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) { return nil, f.RaiseType(TypeErrorType, "foo") }), 1, "TypeError: foo\n"},
+		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) { return nil, f.RaiseType(TypeErrorType, "foo") }), 1, "Traceback (most recent call last):\n  File \"test.py\", line 0, in <test>\nTypeError: foo\n"},
 		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) { return nil, f.RaiseType(SystemExitType, "foo") }), 1, "foo\n"},
 		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
 			return nil, f.Raise(SystemExitType.ToObject(), NewInt(12).ToObject(), nil)
 		}), 12, ""},
+		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+			return nil, f.Raise(SystemExitType.ToObject(), True.ToObject(), nil)
+		}), 1, ""},
+		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+			return nil, f.Raise(SystemExitType.ToObject(), NewLong(big.NewInt(3)).ToObject(), nil)
+		}), 3, ""},
+		{NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+			return nil, f.Raise(mustNotRaise(SystemExitType.Call(f, wrapArgs(2, "foo"), nil)), None, nil)
+		}), 1, "(2, 'foo')\n"},
 	}
 	for _, cas := range cases {
 		SysModules = NewDict()
@@ -287,6 +352,61 @@ func TestRunMain(t *testing.T) {
 	}
 }
 
+func TestRunMainExitFunc(t *testing.T) {
+	oldSysModules := SysModules
+	defer func() {
+		SysModules = oldSysModules
+	}()
+	exitCode := func(status int) *Code {
+		return NewCode("<test>", "test.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+			return nil, f.Raise(SystemExitType.ToObject(), NewInt(status).ToObject(), nil)
+		})
+	}
+	called := 0
+	cases := []struct {
+		code       *Code
+		exitFunc   func(*Frame) *BaseException
+		wantCode   int
+		wantOutput string
+	}{
+		{exitCode(0), func(*Frame) *BaseException { return nil }, 0, ""},
+		{exitCode(3), func(*Frame) *BaseException { return nil }, 3, ""},
+		{exitCode(3), func(f *Frame) *BaseException {
+			if exc, _ := f.ExcInfo(); exc != nil {
+				return f.RaiseType(AssertionErrorType, "exception not cleared")
+			}
+			return nil
+		}, 3, ""},
+		{exitCode(0), func(f *Frame) *BaseException {
+			return f.Raise(SystemExitType.ToObject(), NewInt(4).ToObject(), nil)
+		}, 4, ""},
+		{exitCode(2), func(f *Frame) *BaseException { return f.RaiseType(TypeErrorType, "foo") }, 2, "Error in sys.exitfunc:\nTraceback (most recent call last):\n  File \"test.py\", line 0, in <test>\nTypeError: foo\n"},
+	}
+	for _, cas := range cases {
+		exitFunc := cas.exitFunc
+		fun := newBuiltinFunction("exitfunc", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			called++
+			if raised := exitFunc(f); raised != nil {
+				return nil, raised
+			}
+			return None, nil
+		}).ToObject()
+		sys := newModule("sys", "<test>")
+		sys.Dict().SetItemString(NewRootFrame(), "exitfunc", fun)
+		SysModules = newStringDict(map[string]*Object{"sys": sys.ToObject()})
+		called = 0
+		if gotCode, gotOutput, err := runMainAndCaptureStderr(cas.code); err != nil {
+			t.Errorf("runMainRedirectStderr() failed: %v", err)
+		} else if called != 1 {
+			t.Errorf("RunMain() called sys.exitfunc %d times, want 1", called)
+		} else if gotCode != cas.wantCode {
+			t.Errorf("RunMain() = %v, want %v", gotCode, cas.wantCode)
+		} else if gotOutput != cas.wantOutput {
+			t.Errorf("RunMain() output %q, want %q", gotOutput, cas.wantOutput)
+		}
+	}
+}
+
 func runMainAndCaptureStderr(code *Code) (int, string, error) {
 	oldStderr := Stderr
 	defer func() {
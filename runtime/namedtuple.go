@@ -0,0 +1,284 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// pythonKeywords is the set of reserved words that may not be used as
+// namedtuple type or field names.
+var pythonKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "break": true, "class": true,
+	"continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "exec": true, "finally": true, "for": true, "from": true,
+	"global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "not": true, "or": true, "pass": true, "print": true,
+	"raise": true, "return": true, "try": true, "while": true, "with": true,
+	"yield": true,
+}
+
+// NewNamedTupleType returns a new subclass of tuple called typename whose
+// items can also be accessed as the attributes named by fieldNames. It
+// implements the Python function collections.namedtuple without generating
+// and executing source code for the class. fieldNames is either a sequence of
+// names or a single string of names separated by commas or whitespace. When
+// rename is true, invalid field names are replaced with positional names.
+func NewNamedTupleType(f *Frame, typename, fieldNames *Object, rename bool) (*Type, *BaseException) {
+	if fieldNames.isInstance(BaseStringType) {
+		s, raised := ToStr(f, fieldNames)
+		if raised != nil {
+			return nil, raised
+		}
+		var names []*Object
+		for _, name := range strings.Fields(strings.Replace(s.Value(), ",", " ", -1)) {
+			names = append(names, NewStr(name).ToObject())
+		}
+		fieldNames = NewList(names...).ToObject()
+	}
+	var fields []string
+	raised := seqForEach(f, fieldNames, func(o *Object) *BaseException {
+		s, raised := ToStr(f, o)
+		if raised == nil {
+			fields = append(fields, s.Value())
+		}
+		return raised
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	name, raised := ToStr(f, typename)
+	if raised != nil {
+		return nil, raised
+	}
+	if rename {
+		seen := map[string]bool{}
+		for i, field := range fields {
+			if !namedTupleValidName(field) || field[0] == '_' || seen[field] {
+				fields[i] = fmt.Sprintf("_%d", i)
+			}
+			seen[field] = true
+		}
+	}
+	for _, s := range append([]string{name.Value()}, fields...) {
+		var format string
+		switch {
+		case s == "" || strings.TrimLeft(s, "_0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") != "":
+			format = "Type names and field names can only contain alphanumeric characters and underscores: %s"
+		case pythonKeywords[s]:
+			format = "Type names and field names cannot be a keyword: %s"
+		case s[0] >= '0' && s[0] <= '9':
+			format = "Type names and field names cannot start with a number: %s"
+		default:
+			continue
+		}
+		return nil, namedTupleRaise(f, ValueErrorType, format, s)
+	}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if field[0] == '_' && !rename {
+			return nil, namedTupleRaise(f, ValueErrorType, "Field names cannot start with an underscore: %s", field)
+		}
+		if seen[field] {
+			return nil, namedTupleRaise(f, ValueErrorType, "Encountered duplicate field name: %s", field)
+		}
+		seen[field] = true
+	}
+	return newNamedTupleType(f, name.Value(), fields)
+}
+
+func namedTupleValidName(s string) bool {
+	if s == "" || pythonKeywords[s] || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	return strings.TrimLeft(s, "_0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+}
+
+// namedTupleRaise raises an exception of type t whose message is format
+// applied to the repr of s.
+func namedTupleRaise(f *Frame, t *Type, format string, s string) *BaseException {
+	r, raised := Repr(f, NewStr(s).ToObject())
+	if raised != nil {
+		return raised
+	}
+	return f.RaiseType(t, fmt.Sprintf(format, r.Value()))
+}
+
+func newNamedTupleType(f *Frame, name string, fields []string) (*Type, *BaseException) {
+	var t *Type
+	numFields := len(fields)
+	fieldObjs := make([]*Object, numFields)
+	params := make([]Param, numFields)
+	for i, field := range fields {
+		fieldObjs[i] = NewStr(field).ToObject()
+		params[i] = Param{field, nil}
+	}
+	newParamSpec := NewParamSpec("__new__", params, false, false)
+	newFn := func(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		elems := make([]*Object, numFields)
+		if raised := newParamSpec.Validate(f, elems, args, kwargs); raised != nil {
+			return nil, raised
+		}
+		tup := toTupleUnsafe(newObject(t))
+		tup.elems = elems
+		return tup.ToObject(), nil
+	}
+	asDict := func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkMethodArgs(f, "_asdict", args, t); raised != nil {
+			return nil, raised
+		}
+		o, raised := OrderedDictType.Call(f, nil, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		d := toOrderedDictUnsafe(o)
+		for i, elem := range toTupleUnsafe(args[0]).elems {
+			if i < numFields {
+				if raised := d.SetItem(f, fieldObjs[i], elem); raised != nil {
+					return nil, raised
+				}
+			}
+		}
+		return o, nil
+	}
+	getNewArgs := func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkMethodArgs(f, "__getnewargs__", args, t); raised != nil {
+			return nil, raised
+		}
+		return NewTuple(toTupleUnsafe(args[0]).elems...).ToObject(), nil
+	}
+	makeFn := func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkMethodArgs(f, "_make", args, TypeType, ObjectType); raised != nil {
+			return nil, raised
+		}
+		o, raised := tupleNew(f, toTypeUnsafe(args[0]), args[1:], nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if n := len(toTupleUnsafe(o).elems); n != numFields {
+			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("Expected %d arguments, got %d", numFields, n))
+		}
+		return o, nil
+	}
+	replace := func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		if raised := checkMethodArgs(f, "_replace", args, t); raised != nil {
+			return nil, raised
+		}
+		elems := toTupleUnsafe(args[0]).elems
+		elems = append(make([]*Object, 0, len(elems)), elems...)
+		var unexpected []*Object
+		for _, kwarg := range kwargs {
+			i := 0
+			for i < numFields && fields[i] != kwarg.Name {
+				i++
+			}
+			if i == numFields || i >= len(elems) {
+				unexpected = append(unexpected, NewStr(kwarg.Name).ToObject())
+			} else {
+				elems[i] = kwarg.Value
+			}
+		}
+		if unexpected != nil {
+			s, raised := Repr(f, NewList(unexpected...).ToObject())
+			if raised != nil {
+				return nil, raised
+			}
+			return nil, f.RaiseType(ValueErrorType, fmt.Sprintf("Got unexpected field names: %s", s.Value()))
+		}
+		makeMethod, raised := GetAttr(f, args[0], NewStr("_make"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return makeMethod.Call(f, Args{NewList(elems...).ToObject()}, nil)
+	}
+	repr := func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkMethodArgs(f, "__repr__", args, t); raised != nil {
+			return nil, raised
+		}
+		var buf bytes.Buffer
+		buf.WriteString(name)
+		buf.WriteString("(")
+		for i, elem := range toTupleUnsafe(args[0]).elems {
+			if i >= numFields {
+				break
+			}
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			s, raised := Repr(f, elem)
+			if raised != nil {
+				return nil, raised
+			}
+			buf.WriteString(fields[i])
+			buf.WriteString("=")
+			buf.WriteString(s.Value())
+		}
+		buf.WriteString(")")
+		return NewStr(buf.String()).ToObject(), nil
+	}
+	argList := strings.Join(fields, ", ")
+	if numFields == 1 {
+		argList += ","
+	}
+	dict := newStringDict(map[string]*Object{
+		"__doc__":        NewStr(fmt.Sprintf("%s(%s)", name, argList)).ToObject(),
+		"__getnewargs__": newBuiltinFunction("__getnewargs__", getNewArgs).ToObject(),
+		"__repr__":       newBuiltinFunction("__repr__", repr).ToObject(),
+		"_asdict":        newBuiltinFunction("_asdict", asDict).ToObject(),
+		"_fields":        NewTuple(fieldObjs...).ToObject(),
+		"_make":          newClassMethod(newBuiltinFunction("_make", makeFn).ToObject()).ToObject(),
+		"_replace":       newBuiltinFunction("_replace", replace).ToObject(),
+	})
+	if globals := f.Globals(); globals != nil {
+		module, raised := globals.GetItemString(f, "__name__")
+		if raised != nil {
+			return nil, raised
+		}
+		if module != nil {
+			if raised := dict.SetItemString(f, "__module__", module); raised != nil {
+				return nil, raised
+			}
+		}
+	}
+	for i, field := range fields {
+		i := i
+		get := newBuiltinFunction("_get_"+field, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+			if raised := checkFunctionArgs(f, "_get_"+fields[i], args, t); raised != nil {
+				return nil, raised
+			}
+			elems := toTupleUnsafe(args[0]).elems
+			if i >= len(elems) {
+				return nil, f.RaiseType(IndexErrorType, "tuple index out of range")
+			}
+			return elems[i], nil
+		}).ToObject()
+		p := newProperty(get, nil, nil)
+		p.doc = NewStr(fmt.Sprintf("Alias for field number %d", i)).ToObject()
+		if raised := dict.SetItemString(f, field, p.ToObject()); raised != nil {
+			return nil, raised
+		}
+	}
+	var raised *BaseException
+	if t, raised = newClass(f, TypeType, name, []*Type{TupleType}, dict); raised != nil {
+		return nil, raised
+	}
+	t.slots.New = &newSlot{newFn}
+	if raised := dict.SetItemString(f, "__new__", t.slots.New.makeCallable(t, "__new__")); raised != nil {
+		return nil, raised
+	}
+	return t, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestNewNamedTupleType(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, typename, fieldNames *Object, rename bool) (*Object, *BaseException) {
+		typ, raised := NewNamedTupleType(f, typename, fieldNames, rename)
+		if raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, typ.ToObject(), NewStr("_fields"), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("Point", "x y", false), want: newTestTuple("x", "y").ToObject()},
+		{args: wrapArgs("Point", "x, y", false), want: newTestTuple("x", "y").ToObject()},
+		{args: wrapArgs("Point", newTestList("x", "y"), false), want: newTestTuple("x", "y").ToObject()},
+		{args: wrapArgs("Point", NewList(), false), want: NewTuple().ToObject()},
+		{args: wrapArgs("Point", "abc def ghi abc", true), want: newTestTuple("abc", "_1", "ghi", "_3").ToObject()},
+		{args: wrapArgs("Point", "x _y class 1z", true), want: newTestTuple("x", "_1", "_2", "_3").ToObject()},
+		{args: wrapArgs("Point", "x x", false), wantExc: mustCreateException(ValueErrorType, "Encountered duplicate field name: 'x'")},
+		{args: wrapArgs("Point", "x _y", false), wantExc: mustCreateException(ValueErrorType, "Field names cannot start with an underscore: '_y'")},
+		{args: wrapArgs("Point", "x class", false), wantExc: mustCreateException(ValueErrorType, "Type names and field names cannot be a keyword: 'class'")},
+		{args: wrapArgs("Point", "x 1y", false), wantExc: mustCreateException(ValueErrorType, "Type names and field names cannot start with a number: '1y'")},
+		{args: wrapArgs("Po-int", "x", false), wantExc: mustCreateException(ValueErrorType, "Type names and field names can only contain alphanumeric characters and underscores: 'Po-int'")},
+		{args: wrapArgs("Point", 123, false), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNamedTupleMethods(t *testing.T) {
+	f := NewRootFrame()
+	pointType, raised := NewNamedTupleType(f, NewStr("Point").ToObject(), NewStr("x y").ToObject(), false)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	p := mustNotRaise(pointType.Call(f, wrapArgs(1, 2), nil))
+	callMethod := wrapFuncForTest(func(f *Frame, o *Object, name string, args ...*Object) (*Object, *BaseException) {
+		method, raised := GetAttr(f, o, NewStr(name), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return method.Call(f, args, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(p, "__repr__"), want: NewStr("Point(x=1, y=2)").ToObject()},
+		{args: wrapArgs(p, "__getnewargs__"), want: newTestTuple(1, 2).ToObject()},
+		{args: wrapArgs(pointType, "_make", newTestList(3, 4)), want: newTestTuple(3, 4).ToObject()},
+		{args: wrapArgs(pointType, "_make", newTestList(3)), wantExc: mustCreateException(TypeErrorType, "Expected 2 arguments, got 1")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(callMethod, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	if got := mustNotRaise(GetAttr(f, p, NewStr("y"), nil)); !got.isInstance(IntType) || toIntUnsafe(got).Value() != 2 {
+		t.Errorf("p.y = %v, want 2", got)
+	}
+	replace := mustNotRaise(GetAttr(f, p, NewStr("_replace"), nil))
+	q, raised := replace.Call(f, nil, wrapKWArgs("x", 5))
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if q.typ != pointType || mustNotRaise(Eq(f, q, newTestTuple(5, 2).ToObject())) != True.ToObject() {
+		t.Errorf("p._replace(x=5) = %v, want Point(x=5, y=2)", q)
+	}
+	if _, raised := replace.Call(f, nil, wrapKWArgs("z", 5)); raised == nil || !raised.isInstance(ValueErrorType) {
+		t.Errorf("p._replace(z=5) raised %v, want ValueError", raised)
+	}
+	if _, raised := pointType.Call(f, wrapArgs(1), nil); raised == nil || !raised.isInstance(TypeErrorType) {
+		t.Errorf("Point(1) raised %v, want TypeError", raised)
+	}
+	asDict := mustNotRaise(mustNotRaise(GetAttr(f, p, NewStr("_asdict"), nil)).Call(f, nil, nil))
+	if !asDict.isInstance(OrderedDictType) {
+		t.Errorf("p._asdict() = %v, want an OrderedDict", asDict)
+	} else if keys := toOrderedDictUnsafe(asDict).Keys(f).ToObject(); mustNotRaise(Eq(f, keys, newTestList("x", "y").ToObject())) != True.ToObject() {
+		t.Errorf("p._asdict().keys() = %v, want ['x', 'y']", keys)
+	}
+}
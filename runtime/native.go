@@ -16,17 +16,23 @@ package grumpy
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
 	"runtime"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"unsafe"
 )
 
 var (
+	errorRType              = reflect.TypeOf((*error)(nil)).Elem()
 	nativeBoolMetaclassType = newBasisType("nativebooltype", reflect.TypeOf(nativeBoolMetaclass{}), toNativeBoolMetaclassUnsafe, nativeMetaclassType)
+	nativeChanType          = newSimpleType("chan", nativeType)
 	nativeFuncType          = newSimpleType("func", nativeType)
+	nativeMapType           = newSimpleType("map", nativeType)
 	nativeMetaclassType     = newBasisType("nativetype", reflect.TypeOf(nativeMetaclass{}), toNativeMetaclassUnsafe, TypeType)
 	nativeSliceType         = newSimpleType("slice", nativeType)
 	nativeType              = newBasisType("native", reflect.TypeOf(native{}), toNativeUnsafe, ObjectType)
@@ -98,6 +104,11 @@ func nativeTypedefNative(f *Frame, o *Object) (reflect.Value, *BaseException) {
 }
 
 func nativeMetaclassNew(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) > 0 && args[0].isInstance(nativeMetaclassType) {
+		if rtype := toNativeMetaclassUnsafe(args[0]).rtype; rtype.Kind() == reflect.Chan {
+			return nativeChanNew(f, rtype, args[1:])
+		}
+	}
 	if raised := checkMethodArgs(f, "new", args, nativeMetaclassType); raised != nil {
 		return nil, raised
 	}
@@ -191,6 +202,208 @@ func initNativeType(map[string]*Object) {
 	nativeType.slots.Native = &nativeSlot{nativeNative}
 }
 
+// nativeChanNew creates a new channel of the given type with an optional
+// buffer size. Unlike other native types, new() on channel types returns the
+// channel itself rather than a pointer since a pointer to a nil channel is
+// rarely useful.
+func nativeChanNew(f *Frame, rtype reflect.Type, args Args) (*Object, *BaseException) {
+	expectedTypes := []*Type{IntType}
+	argc := len(args)
+	if argc == 0 {
+		expectedTypes = nil
+	}
+	if raised := checkFunctionArgs(f, "new", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	size := 0
+	if argc > 0 {
+		size = toIntUnsafe(args[0]).Value()
+		if size < 0 {
+			return nil, f.RaiseType(ValueErrorType, "negative channel buffer size")
+		}
+	}
+	return WrapNative(f, reflect.MakeChan(reflect.ChanOf(reflect.BothDir, rtype.Elem()), size).Convert(rtype))
+}
+
+func nativeChanClose(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "close", args, nativeChanType); raised != nil {
+		return nil, raised
+	}
+	v := toNativeUnsafe(args[0]).value
+	if v.Type().ChanDir()&reflect.SendDir == 0 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("cannot close receive-only channel %s", nativeTypeName(v.Type())))
+	}
+	if v.IsNil() {
+		return nil, f.RaiseType(ValueErrorType, "close of nil channel")
+	}
+	if !nativeChanTry(v.Close) {
+		return nil, f.RaiseType(ValueErrorType, "close of closed channel")
+	}
+	return None, nil
+}
+
+func nativeChanIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func nativeChanLen(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewInt(toNativeUnsafe(o).value.Len()).ToObject(), nil
+}
+
+func nativeChanNext(f *Frame, o *Object) (*Object, *BaseException) {
+	elem, ok, raised := nativeChanRecvValue(f, toNativeUnsafe(o).value)
+	if raised != nil {
+		return nil, raised
+	}
+	if !ok {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	return WrapNative(f, elem)
+}
+
+// nativeChanRecv blocks until a value is received from the channel and returns
+// a tuple (value, ok) where ok is False if the channel has been closed, in
+// which case value is the zero value of the channel's element type.
+func nativeChanRecv(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "recv", args, nativeChanType); raised != nil {
+		return nil, raised
+	}
+	elem, ok, raised := nativeChanRecvValue(f, toNativeUnsafe(args[0]).value)
+	if raised != nil {
+		return nil, raised
+	}
+	o, raised := WrapNative(f, elem)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewTuple2(o, GetBool(ok).ToObject()).ToObject(), nil
+}
+
+func nativeChanRecvValue(f *Frame, v reflect.Value) (reflect.Value, bool, *BaseException) {
+	if v.Type().ChanDir()&reflect.RecvDir == 0 {
+		return reflect.Value{}, false, f.RaiseType(TypeErrorType, fmt.Sprintf("cannot receive from send-only channel %s", nativeTypeName(v.Type())))
+	}
+	elem, ok := v.Recv()
+	return elem, ok, nil
+}
+
+func nativeChanRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	typeName := nativeTypeName(toNativeUnsafe(o).value.Type())
+	return NewStr(fmt.Sprintf("<%s object at %p>", typeName, o)).ToObject(), nil
+}
+
+// nativeChanSend blocks until the given value has been sent on the channel.
+// ValueError is raised if the channel has been closed.
+func nativeChanSend(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "send", args, nativeChanType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	v := toNativeUnsafe(args[0]).value
+	rtype := v.Type()
+	if rtype.ChanDir()&reflect.SendDir == 0 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("cannot send to receive-only channel %s", nativeTypeName(rtype)))
+	}
+	elem, raised := maybeConvertValue(f, args[1], rtype.Elem())
+	if raised != nil {
+		return nil, raised
+	}
+	if !nativeChanTry(func() { v.Send(elem) }) {
+		return nil, f.RaiseType(ValueErrorType, "send on closed channel")
+	}
+	return None, nil
+}
+
+// nativeChanTry calls fn and returns false if it panicked because it sent on or
+// closed a closed channel. Any other panic is propagated.
+func nativeChanTry(fn func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, isErr := r.(runtime.Error); isErr {
+				switch err.Error() {
+				case "close of closed channel", "send on closed channel":
+					ok = false
+					return
+				}
+			}
+			panic(r)
+		}
+	}()
+	fn()
+	return true
+}
+
+func initNativeChanType(dict map[string]*Object) {
+	dict["close"] = newBuiltinFunction("close", nativeChanClose).ToObject()
+	dict["recv"] = newBuiltinFunction("recv", nativeChanRecv).ToObject()
+	dict["send"] = newBuiltinFunction("send", nativeChanSend).ToObject()
+	nativeChanType.slots.Iter = &unaryOpSlot{nativeChanIter}
+	nativeChanType.slots.Len = &unaryOpSlot{nativeChanLen}
+	nativeChanType.slots.Next = &unaryOpSlot{nativeChanNext}
+	nativeChanType.slots.Repr = &unaryOpSlot{nativeChanRepr}
+}
+
+// SelectChannels waits until one of the given channel operations can proceed,
+// similar to Go's select statement. cases must be a sequence whose elements are
+// either channels, denoting a receive operation, or (channel, value) tuples,
+// denoting a send. The result is a tuple (i, value, ok) where i is the index of
+// the case that proceeded. For receives, value and ok are as returned by the
+// channel's recv() method and for sends they are None and True. When block is
+// false and no operation can proceed immediately, (-1, None, False) is
+// returned.
+func SelectChannels(f *Frame, cases *Object, block bool) (*Object, *BaseException) {
+	var selectCases []reflect.SelectCase
+	raised := seqForEach(f, cases, func(o *Object) *BaseException {
+		sc := reflect.SelectCase{Dir: reflect.SelectRecv}
+		if o.isInstance(TupleType) {
+			elems := toTupleUnsafe(o).elems
+			if len(elems) != 2 || !elems[0].isInstance(nativeChanType) {
+				return f.RaiseType(TypeErrorType, "send cases must be (channel, value) tuples")
+			}
+			sc.Dir = reflect.SelectSend
+			sc.Chan = toNativeUnsafe(elems[0]).value
+			if sc.Chan.Type().ChanDir()&reflect.SendDir == 0 {
+				return f.RaiseType(TypeErrorType, fmt.Sprintf("cannot send to receive-only channel %s", nativeTypeName(sc.Chan.Type())))
+			}
+			var raised *BaseException
+			if sc.Send, raised = maybeConvertValue(f, elems[1], sc.Chan.Type().Elem()); raised != nil {
+				return raised
+			}
+		} else if o.isInstance(nativeChanType) {
+			sc.Chan = toNativeUnsafe(o).value
+			if sc.Chan.Type().ChanDir()&reflect.RecvDir == 0 {
+				return f.RaiseType(TypeErrorType, fmt.Sprintf("cannot receive from send-only channel %s", nativeTypeName(sc.Chan.Type())))
+			}
+		} else {
+			return f.RaiseType(TypeErrorType, fmt.Sprintf("select case must be a channel or (channel, value) tuple, not %s", o.typ.Name()))
+		}
+		selectCases = append(selectCases, sc)
+		return nil
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	if !block {
+		selectCases = append(selectCases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+	var chosen int
+	var recv reflect.Value
+	var recvOK bool
+	if !nativeChanTry(func() { chosen, recv, recvOK = reflect.Select(selectCases) }) {
+		return nil, f.RaiseType(ValueErrorType, "send on closed channel")
+	}
+	if !block && chosen == len(selectCases)-1 {
+		return NewTuple(NewInt(-1).ToObject(), None, False.ToObject()).ToObject(), nil
+	}
+	if selectCases[chosen].Dir == reflect.SelectSend {
+		return NewTuple(NewInt(chosen).ToObject(), None, True.ToObject()).ToObject(), nil
+	}
+	value, raised := WrapNative(f, recv)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewTuple(NewInt(chosen).ToObject(), value, GetBool(recvOK).ToObject()).ToObject(), nil
+}
+
 func nativeFuncCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return nativeInvoke(f, toNativeUnsafe(callable).value, args)
 }
@@ -222,6 +435,171 @@ func initNativeFuncType(dict map[string]*Object) {
 	nativeFuncType.slots.Repr = &unaryOpSlot{nativeFuncRepr}
 }
 
+func nativeMapContains(f *Frame, o, key *Object) (*Object, *BaseException) {
+	v := toNativeUnsafe(o).value
+	k, raised := maybeConvertValue(f, key, v.Type().Key())
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(v.MapIndex(k).IsValid()).ToObject(), nil
+}
+
+func nativeMapDelItem(f *Frame, o, key *Object) *BaseException {
+	v := toNativeUnsafe(o).value
+	k, raised := maybeConvertValue(f, key, v.Type().Key())
+	if raised != nil {
+		return raised
+	}
+	if !v.MapIndex(k).IsValid() {
+		return raiseKeyError(f, key)
+	}
+	v.SetMapIndex(k, reflect.Value{})
+	return nil
+}
+
+func nativeMapGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
+	v := toNativeUnsafe(o).value
+	k, raised := maybeConvertValue(f, key, v.Type().Key())
+	if raised != nil {
+		return nil, raised
+	}
+	elem := v.MapIndex(k)
+	if !elem.IsValid() {
+		return nil, raiseKeyError(f, key)
+	}
+	return WrapNative(f, elem)
+}
+
+// nativeMapItems returns a list of (key, value) tuples for the entries in the
+// native map o, in unspecified order.
+func nativeMapItems(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "items", args, nativeMapType); raised != nil {
+		return nil, raised
+	}
+	v := toNativeUnsafe(args[0]).value
+	var items []*Object
+	for _, k := range v.MapKeys() {
+		key, raised := WrapNative(f, k)
+		if raised != nil {
+			return nil, raised
+		}
+		value, raised := WrapNative(f, v.MapIndex(k))
+		if raised != nil {
+			return nil, raised
+		}
+		items = append(items, NewTuple2(key, value).ToObject())
+	}
+	return NewList(items...).ToObject(), nil
+}
+
+func nativeMapIter(f *Frame, o *Object) (*Object, *BaseException) {
+	keys, raised := nativeMapKeys(f, Args{o}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return Iter(f, keys)
+}
+
+// nativeMapKeys returns a list of the keys of the native map o, in unspecified
+// order.
+func nativeMapKeys(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "keys", args, nativeMapType); raised != nil {
+		return nil, raised
+	}
+	v := toNativeUnsafe(args[0]).value
+	var keys []*Object
+	for _, k := range v.MapKeys() {
+		key, raised := WrapNative(f, k)
+		if raised != nil {
+			return nil, raised
+		}
+		keys = append(keys, key)
+	}
+	return NewList(keys...).ToObject(), nil
+}
+
+func nativeMapLen(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewInt(toNativeUnsafe(o).value.Len()).ToObject(), nil
+}
+
+func nativeMapRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	typeName := nativeTypeName(toNativeUnsafe(o).value.Type())
+	if f.reprEnter(o) {
+		return NewStr(fmt.Sprintf("%s{...}", typeName)).ToObject(), nil
+	}
+	defer f.reprLeave(o)
+	items, raised := nativeMapItems(f, Args{o}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	var buf bytes.Buffer
+	buf.WriteString(typeName)
+	buf.WriteString("{")
+	for i, item := range toListUnsafe(items).elems {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		elems := toTupleUnsafe(item).elems
+		s, raised := Repr(f, elems[0])
+		if raised != nil {
+			return nil, raised
+		}
+		buf.WriteString(s.Value())
+		buf.WriteString(": ")
+		if s, raised = Repr(f, elems[1]); raised != nil {
+			return nil, raised
+		}
+		buf.WriteString(s.Value())
+	}
+	buf.WriteString("}")
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+func nativeMapSetItem(f *Frame, o, key, value *Object) *BaseException {
+	v := toNativeUnsafe(o).value
+	k, raised := maybeConvertValue(f, key, v.Type().Key())
+	if raised != nil {
+		return raised
+	}
+	elem, raised := maybeConvertValue(f, value, v.Type().Elem())
+	if raised != nil {
+		return raised
+	}
+	v.SetMapIndex(k, elem)
+	return nil
+}
+
+// nativeMapValues returns a list of the values of the native map o, in
+// unspecified order.
+func nativeMapValues(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "values", args, nativeMapType); raised != nil {
+		return nil, raised
+	}
+	v := toNativeUnsafe(args[0]).value
+	var values []*Object
+	for _, k := range v.MapKeys() {
+		value, raised := WrapNative(f, v.MapIndex(k))
+		if raised != nil {
+			return nil, raised
+		}
+		values = append(values, value)
+	}
+	return NewList(values...).ToObject(), nil
+}
+
+func initNativeMapType(dict map[string]*Object) {
+	dict["items"] = newBuiltinFunction("items", nativeMapItems).ToObject()
+	dict["keys"] = newBuiltinFunction("keys", nativeMapKeys).ToObject()
+	dict["values"] = newBuiltinFunction("values", nativeMapValues).ToObject()
+	nativeMapType.slots.Contains = &binaryOpSlot{nativeMapContains}
+	nativeMapType.slots.DelItem = &delItemSlot{nativeMapDelItem}
+	nativeMapType.slots.GetItem = &binaryOpSlot{nativeMapGetItem}
+	nativeMapType.slots.Iter = &unaryOpSlot{nativeMapIter}
+	nativeMapType.slots.Len = &unaryOpSlot{nativeMapLen}
+	nativeMapType.slots.Repr = &unaryOpSlot{nativeMapRepr}
+	nativeMapType.slots.SetItem = &setItemSlot{nativeMapSetItem}
+}
+
 func nativeSliceGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
 	v := toNativeUnsafe(o).value
 	if key.typ.slots.Index != nil {
@@ -340,11 +718,7 @@ func initNativeSliceType(map[string]*Object) {
 }
 
 func nativeSliceGetIndex(f *Frame, slice reflect.Value, key *Object) (reflect.Value, *BaseException) {
-	i, raised := IndexInt(f, key)
-	if raised != nil {
-		return reflect.Value{}, raised
-	}
-	i, raised = seqCheckedIndex(f, slice.Len(), i)
+	i, raised := seqIndex(f, slice.Len(), key)
 	if raised != nil {
 		return reflect.Value{}, raised
 	}
@@ -400,7 +774,12 @@ func initSliceIteratorType(map[string]*Object) {
 // - *big.Int is represented by Python long.
 // - Functions are represented by Python type that supports calling into native
 //   functions.
+// - Channels are represented by a Python type with blocking send(), recv() and
+//   close() methods. Iterating over a channel receives values until it is
+//   closed.
 // - Interfaces are converted to their concrete held type, or None if IsNil.
+// - Slices and maps are represented by Python types supporting the sequence
+//   and mapping protocols respectively, operating on the underlying Go object.
 // - Other native types are wrapped in an opaque native type that does not
 //   support directly accessing the underlying object from Python. When these
 //   opaque objects are passed back into Go by native function calls, however,
@@ -517,6 +896,8 @@ func getNativeType(rtype reflect.Type) *Type {
 		// object.
 		base := nativeType
 		switch rtype.Kind() {
+		case reflect.Chan:
+			base = nativeChanType
 		case reflect.Complex64, reflect.Complex128:
 			base = ComplexType
 		case reflect.Float32, reflect.Float64:
@@ -525,6 +906,8 @@ func getNativeType(rtype reflect.Type) *Type {
 			base = nativeFuncType
 		case reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int8, reflect.Int, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8, reflect.Uint, reflect.Uintptr:
 			base = IntType
+		case reflect.Map:
+			base = nativeMapType
 		case reflect.Array, reflect.Slice:
 			base = nativeSliceType
 		case reflect.String:
@@ -545,14 +928,30 @@ func getNativeType(rtype reflect.Type) *Type {
 		} else {
 			t = newNativeType(rtype, base)
 		}
+		if rtype.Kind() == reflect.Struct {
+			// Methods with pointer receivers can be called on
+			// addressable struct values, e.g. fields of structs
+			// accessed through a pointer.
+			ptrType := reflect.PtrTo(rtype)
+			numMethod := ptrType.NumMethod()
+			for i := 0; i < numMethod; i++ {
+				meth := ptrType.Method(i)
+				if _, ok := d[meth.Name]; !ok && meth.PkgPath == "" {
+					d[meth.Name] = newNativeMethod(meth.Name, meth.Func)
+				}
+			}
+		}
 		derefed := rtype
 		for derefed.Kind() == reflect.Ptr {
 			derefed = derefed.Elem()
 		}
 		if derefed.Kind() == reflect.Struct {
-			for i := 0; i < derefed.NumField(); i++ {
-				name := derefed.Field(i).Name
-				d[name] = newNativeField(name, i, t)
+			for _, name := range nativeFieldNames(derefed, nil) {
+				// FieldByName resolves promoted fields and
+				// fails for ambiguous ones.
+				if field, ok := derefed.FieldByName(name); ok {
+					d[name] = newNativeField(name, field.Index, t)
+				}
 			}
 		}
 		t.setDict(newStringDict(d))
@@ -566,26 +965,67 @@ func getNativeType(rtype reflect.Type) *Type {
 	return t
 }
 
-func newNativeField(name string, i int, t *Type) *Object {
+// nativeFieldNames returns the names of the fields of struct type rtype,
+// including those promoted from embedded structs. visited holds the embedded
+// struct types already traversed.
+func nativeFieldNames(rtype reflect.Type, visited map[reflect.Type]bool) []string {
+	if visited == nil {
+		visited = map[reflect.Type]bool{}
+	}
+	visited[rtype] = true
+	var names []string
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		names = append(names, field.Name)
+		embedded := field.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && embedded.Kind() == reflect.Struct && !visited[embedded] {
+			names = append(names, nativeFieldNames(embedded, visited)...)
+		}
+	}
+	return names
+}
+
+func newNativeField(name string, index []int, t *Type) *Object {
+	// fieldValue returns the field of the struct o, following pointers to
+	// embedded structs for promoted fields.
+	fieldValue := func(f *Frame, o *Object) (reflect.Value, *BaseException) {
+		v := toNativeUnsafe(o).value
+		for v.Type().Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		for i, x := range index {
+			if i > 0 && v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					msg := fmt.Sprintf("cannot access field '%s' of type '%s' through nil embedded pointer", name, t.Name())
+					return reflect.Value{}, f.RaiseType(AttributeErrorType, msg)
+				}
+				v = v.Elem()
+			}
+			v = v.Field(x)
+		}
+		return v, nil
+	}
 	get := newBuiltinFunction(name, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 		if raised := checkFunctionArgs(f, name, args, t); raised != nil {
 			return nil, raised
 		}
-		v := toNativeUnsafe(args[0]).value
-		for v.Type().Kind() == reflect.Ptr {
-			v = v.Elem()
+		v, raised := fieldValue(f, args[0])
+		if raised != nil {
+			return nil, raised
 		}
-		return WrapNative(f, v.Field(i))
+		return WrapNative(f, v)
 	}).ToObject()
 	set := newBuiltinFunction(name, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 		if raised := checkFunctionArgs(f, name, args, t, ObjectType); raised != nil {
 			return nil, raised
 		}
-		v := toNativeUnsafe(args[0]).value
-		for v.Type().Kind() == reflect.Ptr {
-			v = v.Elem()
+		field, raised := fieldValue(f, args[0])
+		if raised != nil {
+			return nil, raised
 		}
-		field := v.Field(i)
 		if !field.CanSet() {
 			msg := fmt.Sprintf("cannot set field '%s' of type '%s'", name, t.Name())
 			return nil, f.RaiseType(TypeErrorType, msg)
@@ -606,6 +1046,119 @@ func newNativeMethod(name string, fun reflect.Value) *Object {
 	}).ToObject()
 }
 
+// nativeCallbackPanic is the value panicked by functions created with
+// MakeNativeFunc when the Python callable raises and the function's
+// signature has no error result to report it with. nativeCall recovers it and
+// re-raises the original exception.
+type nativeCallbackPanic struct {
+	exc *BaseException
+	tb  *Traceback
+}
+
+// MakeNativeFunc returns a Go func value of type rtype that calls callable.
+// This allows Python callables to be passed to Go code that expects a
+// function. The arguments are wrapped with WrapNative and the return value,
+// which must be a tuple when rtype has several non-error results, is
+// converted to the result types.
+//
+// Each call runs in a new root frame since Go code may invoke the function
+// from any goroutine. The new stack is subject to the same Policy as f's. If
+// the call raises and the last result of rtype is an error, the exception is
+// returned as an error, which is the original Go error for GoError
+// exceptions. Otherwise the function panics, and if it was
+// called from a native function invoked by Python code, the exception is
+// re-raised there.
+func MakeNativeFunc(f *Frame, callable *Object, rtype reflect.Type) (reflect.Value, *BaseException) {
+	if rtype.Kind() != reflect.Func {
+		return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf("cannot make native function of type %s", rtype))
+	}
+	if callable.typ.slots.Call == nil {
+		return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf("'%s' object is not callable", callable.typ.Name()))
+	}
+	numOut := rtype.NumOut()
+	hasErr := numOut > 0 && rtype.Out(numOut-1) == errorRType
+	if hasErr {
+		numOut--
+	}
+	fun := func(in []reflect.Value) []reflect.Value {
+		cf := newThreadRootFrame(f)
+		out := make([]reflect.Value, rtype.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(rtype.Out(i))
+		}
+		raised := nativeFuncCallback(cf, callable, rtype, in, out[:numOut])
+		if raised != nil {
+			_, tb := cf.ExcInfo()
+			if !hasErr {
+				panic(nativeCallbackPanic{raised, tb})
+			}
+			var err error
+			if v, _ := ToNative(cf, raised.ToObject()); v.IsValid() && v.CanInterface() {
+				err, _ = v.Interface().(error)
+			}
+			if err == nil {
+				err = errors.New(strings.TrimSuffix(formatExceptionOnly(cf, raised), "\n"))
+			}
+			out[numOut] = reflect.ValueOf(&err).Elem()
+		}
+		return out
+	}
+	return reflect.MakeFunc(rtype, fun), nil
+}
+
+// nativeFuncCallback calls callable with the wrapped in values and stores the
+// converted results in out.
+func nativeFuncCallback(f *Frame, callable *Object, rtype reflect.Type, in, out []reflect.Value) *BaseException {
+	var args Args
+	for i, v := range in {
+		if rtype.IsVariadic() && i == len(in)-1 {
+			for j := 0; j < v.Len(); j++ {
+				o, raised := WrapNative(f, v.Index(j))
+				if raised != nil {
+					return raised
+				}
+				args = append(args, o)
+			}
+			break
+		}
+		o, raised := WrapNative(f, v)
+		if raised != nil {
+			return raised
+		}
+		args = append(args, o)
+	}
+	result, raised := callable.Call(f, args, nil)
+	if raised != nil {
+		return raised
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		v, raised := maybeConvertValue(f, result, rtype.Out(0))
+		if raised != nil {
+			return raised
+		}
+		out[0] = v
+		return nil
+	}
+	if !result.isInstance(TupleType) || len(toTupleUnsafe(result).elems) != len(out) {
+		return f.RaiseType(TypeErrorType, fmt.Sprintf("native callback must return a tuple of %d values", len(out)))
+	}
+	for i, o := range toTupleUnsafe(result).elems {
+		v, raised := maybeConvertValue(f, o, rtype.Out(i))
+		if raised != nil {
+			return raised
+		}
+		out[i] = v
+	}
+	return nil
+}
+
+// maybeConvertValue converts o to a Go value of type expectedRType. Python
+// lists and tuples are converted to slices and arrays, and dicts to maps,
+// converting their elements recursively. Python values passed where a pointer
+// is expected are converted to a newly allocated value.
 func maybeConvertValue(f *Frame, o *Object, expectedRType reflect.Type) (reflect.Value, *BaseException) {
 	if expectedRType.Kind() == reflect.Ptr {
 		// When the expected type is some basis pointer, check if o is
@@ -619,19 +1172,38 @@ func maybeConvertValue(f *Frame, o *Object, expectedRType reflect.Type) (reflect
 		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
 			return reflect.Zero(expectedRType), nil
 		default:
-			return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf("an %s is required", expectedRType))
+			return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf("%s %s is required", nativeTypeArticle(expectedRType), expectedRType))
+		}
+	}
+	if expectedRType.Kind() == reflect.Func && !o.isInstance(nativeType) && o.typ.slots.Call != nil {
+		return MakeNativeFunc(f, o, expectedRType)
+	}
+	switch expectedRType.Kind() {
+	case reflect.Array, reflect.Slice:
+		if o.isInstance(ListType) || o.isInstance(TupleType) {
+			return convertNativeSeq(f, o, expectedRType)
+		}
+	case reflect.Map:
+		if o.isInstance(DictType) {
+			return convertNativeMap(f, toDictUnsafe(o), expectedRType)
 		}
 	}
 	val, raised := ToNative(f, o)
 	if raised != nil {
 		return reflect.Value{}, raised
 	}
+	if expectedRType.Kind() == reflect.Ptr && val.Type() == expectedRType.Elem() && val.CanAddr() {
+		// Addressable values like fields of structs accessed through a
+		// pointer can be passed by reference, e.g. to methods with
+		// pointer receivers.
+		return val.Addr(), nil
+	}
 	rtype := val.Type()
 	for {
 		if rtype == expectedRType {
 			return val, nil
 		}
-		if rtype.ConvertibleTo(expectedRType) {
+		if rtype.ConvertibleTo(expectedRType) && !isNativeIntToString(rtype, expectedRType) {
 			return val.Convert(expectedRType), nil
 		}
 		if rtype.Kind() == reflect.Ptr {
@@ -641,7 +1213,119 @@ func maybeConvertValue(f *Frame, o *Object, expectedRType reflect.Type) (reflect
 		}
 		break
 	}
-	return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf("an %s is required", expectedRType))
+	if expectedRType.Kind() == reflect.Ptr && !o.isInstance(nativeType) {
+		// Python values can be passed by reference by converting them
+		// to a newly allocated value of the pointed-to type.
+		elem, raised := maybeConvertValue(f, o, expectedRType.Elem())
+		if raised != nil {
+			return reflect.Value{}, raised
+		}
+		ptr := reflect.New(expectedRType.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+	return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf("%s %s is required", nativeTypeArticle(expectedRType), expectedRType))
+}
+
+// nativeTypeArticle returns the indefinite article to use before the name of
+// t in messages, e.g. "an int" but "a string".
+func nativeTypeArticle(t reflect.Type) string {
+	if name := t.String(); strings.IndexByte("aeio", name[0]) >= 0 {
+		return "an"
+	}
+	return "a"
+}
+
+// isNativeIntToString returns true if converting from to to would interpret an
+// integer as a rune, e.g. string(65) == "A", which is never what a Python
+// caller passing an int where a string is expected means.
+func isNativeIntToString(from, to reflect.Type) bool {
+	if to.Kind() != reflect.String {
+		return false
+	}
+	switch from.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// convertNativeSeq converts the elements of the list or tuple o to a new Go
+// slice or array of type rtype.
+func convertNativeSeq(f *Frame, o *Object, rtype reflect.Type) (reflect.Value, *BaseException) {
+	var elems []*Object
+	if o.isInstance(ListType) {
+		l := toListUnsafe(o)
+		l.mutex.RLock()
+		elems = make([]*Object, len(l.elems))
+		copy(elems, l.elems)
+		l.mutex.RUnlock()
+	} else {
+		elems = toTupleUnsafe(o).elems
+	}
+	numElems := len(elems)
+	var result reflect.Value
+	if rtype.Kind() == reflect.Array {
+		if numElems != rtype.Len() {
+			format := "%s %s is required, got %s of length %d"
+			return reflect.Value{}, f.RaiseType(TypeErrorType, fmt.Sprintf(format, nativeTypeArticle(rtype), rtype, o.typ.Name(), numElems))
+		}
+		result = reflect.New(rtype).Elem()
+	} else {
+		result = reflect.MakeSlice(rtype, numElems, numElems)
+	}
+	for i, elem := range elems {
+		v, raised := convertNativeElem(f, elem, rtype.Elem(), fmt.Sprintf("%s index %d", rtype, i))
+		if raised != nil {
+			return reflect.Value{}, raised
+		}
+		result.Index(i).Set(v)
+	}
+	return result, nil
+}
+
+// convertNativeMap converts the entries of d to a new Go map of type rtype.
+func convertNativeMap(f *Frame, d *Dict, rtype reflect.Type) (reflect.Value, *BaseException) {
+	entries := d.snapshot(f)
+	result := reflect.MakeMapWithSize(rtype, len(entries))
+	for _, entry := range entries {
+		k, raised := convertNativeElem(f, entry.key, rtype.Key(), fmt.Sprintf("%s key", rtype))
+		if raised != nil {
+			return reflect.Value{}, raised
+		}
+		v, raised := maybeConvertValue(f, entry.value, rtype.Elem())
+		if raised != nil {
+			s, raised2 := Repr(f, entry.key)
+			if raised2 != nil {
+				return reflect.Value{}, raised2
+			}
+			return reflect.Value{}, annotateNativeTypeError(f, raised, fmt.Sprintf("%s value for key %s", rtype, s.Value()))
+		}
+		result.SetMapIndex(k, v)
+	}
+	return result, nil
+}
+
+// convertNativeElem converts o, an element of a container being converted to
+// Go, to type rtype. TypeErrors are annotated with desc, a description of
+// where the element was found.
+func convertNativeElem(f *Frame, o *Object, rtype reflect.Type, desc string) (reflect.Value, *BaseException) {
+	v, raised := maybeConvertValue(f, o, rtype)
+	if raised != nil {
+		return reflect.Value{}, annotateNativeTypeError(f, raised, desc)
+	}
+	return v, nil
+}
+
+// annotateNativeTypeError prefixes the message of the TypeError raised with
+// desc and re-raises it. Other exceptions are returned as is.
+func annotateNativeTypeError(f *Frame, raised *BaseException, desc string) *BaseException {
+	if !raised.isInstance(TypeErrorType) || raised.args == nil || len(raised.args.elems) != 1 || !raised.args.elems[0].isInstance(StrType) {
+		return raised
+	}
+	msg := toStrUnsafe(raised.args.elems[0]).Value()
+	f.RestoreExc(nil, nil)
+	return f.RaiseType(TypeErrorType, fmt.Sprintf("%s: %s", desc, msg))
 }
 
 func nativeFuncTypeName(rtype reflect.Type) string {
@@ -673,6 +1357,9 @@ func nativeFuncTypeName(rtype reflect.Type) string {
 }
 
 func nativeInvoke(f *Frame, fun reflect.Value, args Args) (ret *Object, raised *BaseException) {
+	if raised := f.checkNativeCall(fun); raised != nil {
+		return nil, raised
+	}
 	rtype := fun.Type()
 	argc := len(args)
 	expectedArgc := rtype.NumIn()
@@ -706,7 +1393,10 @@ func nativeInvoke(f *Frame, fun reflect.Value, args Args) (ret *Object, raised *
 		}
 	}
 	origExc, origTb := f.RestoreExc(nil, nil)
-	result := fun.Call(nativeArgs)
+	result, raised := nativeCall(f, fun, nativeArgs)
+	if raised != nil {
+		return nil, raised
+	}
 	if e, _ := f.ExcInfo(); e != nil {
 		return nil, e
 	}
@@ -722,11 +1412,11 @@ func nativeInvoke(f *Frame, fun reflect.Value, args Args) (ret *Object, raised *
 	case 0:
 		ret = None
 	case 1:
-		ret, raised = WrapNative(f, result[0])
+		ret, raised = wrapNativeResult(f, result[0])
 	default:
 		elems := make([]*Object, numResults)
 		for i := 0; i < numResults; i++ {
-			if elems[i], raised = WrapNative(f, result[i]); raised != nil {
+			if elems[i], raised = wrapNativeResult(f, result[i]); raised != nil {
 				return nil, raised
 			}
 		}
@@ -735,6 +1425,54 @@ func nativeInvoke(f *Frame, fun reflect.Value, args Args) (ret *Object, raised *
 	return ret, raised
 }
 
+// wrapNativeResult converts a value returned by a native function to a Python
+// object. Unlike WrapNative, slices of unnamed types are copied into new lists,
+// converting their elements recursively, so that they behave like the lists
+// Python functions return. Byte and rune slices, and slices of named types,
+// which may have methods, are wrapped by WrapNative.
+func wrapNativeResult(f *Frame, v reflect.Value) (*Object, *BaseException) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice || v.Type().Name() != "" {
+		return WrapNative(f, v)
+	}
+	if elemKind := v.Type().Elem().Kind(); elemKind == reflect.Uint8 || elemKind == reflect.Int32 {
+		return WrapNative(f, v)
+	}
+	n := v.Len()
+	elems := make([]*Object, n)
+	for i := 0; i < n; i++ {
+		elem, raised := wrapNativeResult(f, v.Index(i))
+		if raised != nil {
+			return nil, raised
+		}
+		elems[i] = elem
+	}
+	return NewList(elems...).ToObject(), nil
+}
+
+// nativeCall calls fun with args, converting any panic that occurs in the
+// native code into a RuntimeError whose message contains the panic value and
+// the Go stack at the point of the panic.
+func nativeCall(f *Frame, fun reflect.Value, args []reflect.Value) (result []reflect.Value, raised *BaseException) {
+	defer func() {
+		if r := recover(); r != nil {
+			if p, ok := r.(nativeCallbackPanic); ok {
+				var tb *Object
+				if p.tb != nil {
+					tb = p.tb.ToObject()
+				}
+				raised = f.Raise(p.exc.ToObject(), nil, tb)
+				return
+			}
+			msg := fmt.Sprintf("native function panicked: %v\n\n%s", r, debug.Stack())
+			raised = f.RaiseType(RuntimeErrorType, msg)
+		}
+	}()
+	return fun.Call(args), nil
+}
+
 func nativeTypeName(rtype reflect.Type) string {
 	if rtype.Name() != "" {
 		return rtype.Name()
@@ -743,7 +1481,7 @@ func nativeTypeName(rtype reflect.Type) string {
 	case reflect.Array:
 		return fmt.Sprintf("[%d]%s", rtype.Len(), nativeTypeName(rtype.Elem()))
 	case reflect.Chan:
-		return fmt.Sprintf("chan %s", nativeTypeName(rtype.Elem()))
+		return fmt.Sprintf("%s %s", rtype.ChanDir(), nativeTypeName(rtype.Elem()))
 	case reflect.Func:
 		return nativeFuncTypeName(rtype)
 	case reflect.Map:
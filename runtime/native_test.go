@@ -20,6 +20,7 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -69,6 +70,11 @@ func TestNativeFuncCall(t *testing.T) {
 		{func(s ...string) int { return len(s) }, invokeTestCase{args: wrapArgs("foo", "bar"), want: NewInt(2).ToObject()}},
 		{func() {}, invokeTestCase{args: wrapArgs(3.14), wantExc: mustCreateException(TypeErrorType, "native function takes 0 arguments, (1 given)")}},
 		{func(int, ...string) {}, invokeTestCase{wantExc: mustCreateException(TypeErrorType, "native function takes at least 1 arguments, (0 given)")}},
+		{strings.Split, invokeTestCase{args: wrapArgs("a,b", ","), want: newTestList("a", "b").ToObject()}},
+		{func() [][]int { return [][]int{{1}, {2, 3}} }, invokeTestCase{want: newTestList(newTestList(1), newTestList(2, 3)).ToObject()}},
+		{func() ([]int, int) { return nil, 1 }, invokeTestCase{want: newTestTuple(NewList(), 1).ToObject()}},
+		{func() interface{} { return []string{"foo"} }, invokeTestCase{want: newTestList("foo").ToObject()}},
+		{func() []rune { return []rune("foo") }, invokeTestCase{want: NewUnicode("foo").ToObject()}},
 	}
 	for _, cas := range cases {
 		n := &native{Object{typ: nativeFuncType}, reflect.ValueOf(cas.fun)}
@@ -78,6 +84,134 @@ func TestNativeFuncCall(t *testing.T) {
 	}
 }
 
+func TestNativeFuncCallSliceResult(t *testing.T) {
+	type intSlice []int
+	f := NewRootFrame()
+	for _, fun := range []interface{}{
+		func() []byte { return []byte("foo") },
+		func() intSlice { return intSlice{1, 2} },
+	} {
+		n := &native{Object{typ: nativeFuncType}, reflect.ValueOf(fun)}
+		result, raised := n.ToObject().Call(f, nil, nil)
+		if raised != nil {
+			t.Errorf("%T() raised %v", fun, raised)
+		} else if result.isInstance(ListType) {
+			t.Errorf("%T() returned a list, want a native slice", fun)
+		}
+	}
+}
+
+func TestNativeFuncCallPanic(t *testing.T) {
+	fun := newNativeMethod("foo", reflect.ValueOf(func() { panic("foo") }))
+	f := NewRootFrame()
+	_, raised := fun.Call(f, nil, nil)
+	if raised == nil || !raised.isInstance(RuntimeErrorType) {
+		t.Fatalf("foo() raised %v, want RuntimeError", raised)
+	}
+	msg, raised := ToStr(f, raised.ToObject())
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if s := msg.Value(); !strings.HasPrefix(s, "native function panicked: foo\n") || !strings.Contains(s, "TestNativeFuncCallPanic") {
+		t.Errorf("foo() raised %q, want panic value and stack", s)
+	}
+}
+
+func TestMakeNativeFunc(t *testing.T) {
+	goErr := errors.New("foo")
+	concat := newBuiltinFunction("concat", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		return Mul(f, args[1], args[0])
+	}).ToObject()
+	pair := newBuiltinFunction("pair", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		return NewTuple(args.makeCopy()...).ToObject(), nil
+	}).ToObject()
+	raiseValueError := newBuiltinFunction("raiseValueError", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+		return nil, f.RaiseType(ValueErrorType, "bar")
+	}).ToObject()
+	raiseGoError := newBuiltinFunction("raiseGoError", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+		return nil, RaiseGoError(f, goErr)
+	}).ToObject()
+	f := NewRootFrame()
+	var repeat func(int, string) string
+	var split func(...string) (string, int)
+	var check func() error
+	var get func() (int, error)
+	cases := []struct {
+		callable *Object
+		fn       interface{}
+		test     func() error
+	}{
+		{concat, &repeat, func() error {
+			if got := repeat(3, "ab"); got != "ababab" {
+				return fmt.Errorf("repeat(3, 'ab') = %q, want 'ababab'", got)
+			}
+			return nil
+		}},
+		{pair, &split, func() (err error) {
+			// There's no error result to report the bad return value
+			// with so split panics.
+			defer func() {
+				if p, ok := recover().(nativeCallbackPanic); !ok || !p.exc.isInstance(TypeErrorType) {
+					err = fmt.Errorf("split('a', 'b') panicked with %v, want TypeError", p)
+				}
+			}()
+			split("a", "b")
+			return nil
+		}},
+		{raiseValueError, &check, func() error {
+			if err := check(); err == nil || err.Error() != "ValueError: bar" {
+				return fmt.Errorf("check() = %v, want ValueError: bar", err)
+			}
+			return nil
+		}},
+		{raiseGoError, &get, func() error {
+			if i, err := get(); i != 0 || err != goErr {
+				return fmt.Errorf("get() = (%d, %v), want (0, %v)", i, err, goErr)
+			}
+			return nil
+		}},
+	}
+	for _, cas := range cases {
+		fn := reflect.ValueOf(cas.fn).Elem()
+		v, raised := MakeNativeFunc(f, cas.callable, fn.Type())
+		if raised != nil {
+			t.Errorf("MakeNativeFunc(%v, %v) raised %v", cas.callable, fn.Type(), raised)
+			continue
+		}
+		fn.Set(v)
+		if err := cas.test(); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, raised := MakeNativeFunc(f, concat, reflect.TypeOf(0)); raised == nil || !raised.isInstance(TypeErrorType) {
+		t.Errorf("MakeNativeFunc(int) raised %v, want TypeError", raised)
+	}
+	if _, raised := MakeNativeFunc(f, NewInt(1).ToObject(), reflect.TypeOf(repeat)); raised == nil || !raised.isInstance(TypeErrorType) {
+		t.Errorf("MakeNativeFunc(1) raised %v, want TypeError", raised)
+	}
+}
+
+func TestNativeFuncCallback(t *testing.T) {
+	apply := func(fn func(int) int, i int) int { return fn(i) }
+	double := newBuiltinFunction("double", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		return Mul(f, args[0], NewInt(2).ToObject())
+	}).ToObject()
+	raiseValueError := newBuiltinFunction("raiseValueError", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+		return nil, f.RaiseType(ValueErrorType, "bar")
+	}).ToObject()
+	cases := []invokeTestCase{
+		{args: wrapArgs(double, 21), want: NewInt(42).ToObject()},
+		{args: wrapArgs(raiseValueError, 21), wantExc: mustCreateException(ValueErrorType, "bar")},
+		{args: wrapArgs(NewStr("foo"), 21), wantExc: mustCreateException(TypeErrorType, "a func(int) int is required")},
+	}
+	fun := newNativeMethod("apply", reflect.ValueOf(apply))
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestNativeFuncName(t *testing.T) {
 	re := regexp.MustCompile(`(\w+\.)*\w+$`)
 	fun := wrapFuncForTest(func(f *Frame, o *Object) (string, *BaseException) {
@@ -100,7 +234,7 @@ func TestNativeFuncName(t *testing.T) {
 	})
 	cases := []invokeTestCase{
 		{args: wrapArgs(TestNativeFuncName), want: NewStr("grumpy.TestNativeFuncName").ToObject()},
-		{args: wrapArgs(None), wantExc: mustCreateException(TypeErrorType, "'_get_name' requires a 'func' object but received a 'NoneType'")},
+		{args: wrapArgs(42), wantExc: mustCreateException(TypeErrorType, "'_get_name' requires a 'func' object but received a 'int'")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -348,6 +482,184 @@ func TestGetNativeTypeSlice(t *testing.T) {
 	}
 }
 
+func TestGetNativeTypeChan(t *testing.T) {
+	if typ := getNativeType(reflect.TypeOf(make(chan int))); !typ.isSubclass(nativeChanType) {
+		t.Errorf("getNativeType(chan int) = %v, want a subclass of chan", typ)
+	} else if name := typ.Name(); name != "chan int" {
+		t.Errorf(`%v.__name__ == %q, want "chan int"`, typ, name)
+	}
+}
+
+func TestNativeChanNew(t *testing.T) {
+	type testChan chan string
+	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		chanType := getNativeType(reflect.TypeOf(testChan(nil)))
+		ret, raised := callNativeMethod(f, chanType.ToObject(), "new", args...)
+		if raised != nil {
+			return nil, raised
+		}
+		c, ok := toNativeUnsafe(ret).value.Interface().(testChan)
+		if !ok || c == nil {
+			t.Errorf("%v.new() returned %v, want a non-nil testChan", chanType, ret)
+		}
+		return NewInt(cap(c)).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{want: NewInt(0).ToObject()},
+		{args: wrapArgs(3), want: NewInt(3).ToObject()},
+		{args: wrapArgs(-1), wantExc: mustCreateException(ValueErrorType, "negative channel buffer size")},
+		{args: wrapArgs("foo"), wantExc: mustCreateException(TypeErrorType, "'new' requires a 'int' object but received a \"str\"")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeChanSendRecv(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, c *Object, value *Object) (*Object, *BaseException) {
+		if _, raised := callNativeMethod(f, c, "send", value); raised != nil {
+			return nil, raised
+		}
+		return callNativeMethod(f, c, "recv")
+	})
+	closed := make(chan int, 1)
+	close(closed)
+	cases := []invokeTestCase{
+		{args: wrapArgs(make(chan int, 1), 42), want: newTestTuple(42, true).ToObject()},
+		{args: wrapArgs(make(chan *Object, 1), "foo"), want: newTestTuple("foo", true).ToObject()},
+		{args: wrapArgs(make(chan int, 1), "foo"), wantExc: mustCreateException(TypeErrorType, "an int is required")},
+		{args: wrapArgs(closed, 1), wantExc: mustCreateException(ValueErrorType, "send on closed channel")},
+		{args: wrapArgs(make(<-chan int), 1), wantExc: mustCreateException(TypeErrorType, "cannot send to receive-only channel <-chan int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeChanRecvClosed(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, c *Object) (*Object, *BaseException) {
+		return callNativeMethod(f, c, "recv")
+	})
+	closed := make(chan int, 1)
+	closed <- 42
+	close(closed)
+	cases := []invokeTestCase{
+		{args: wrapArgs(closed), want: newTestTuple(42, true).ToObject()},
+		{args: wrapArgs(closed), want: newTestTuple(0, false).ToObject()},
+		{args: wrapArgs(make(chan<- int)), wantExc: mustCreateException(TypeErrorType, "cannot receive from send-only channel chan<- int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeChanClose(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, c *Object) (*Object, *BaseException) {
+		return callNativeMethod(f, c, "close")
+	})
+	closed := make(chan int)
+	close(closed)
+	// WrapNative converts nil channels to None so build one directly.
+	nilChan := &native{Object{typ: nativeChanType}, reflect.ValueOf((chan int)(nil))}
+	cases := []invokeTestCase{
+		{args: wrapArgs(make(chan int)), want: None},
+		{args: wrapArgs(closed), wantExc: mustCreateException(ValueErrorType, "close of closed channel")},
+		{args: wrapArgs(nilChan), wantExc: mustCreateException(ValueErrorType, "close of nil channel")},
+		{args: wrapArgs(make(<-chan int)), wantExc: mustCreateException(TypeErrorType, "cannot close receive-only channel <-chan int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeChanTryPropagatesPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "foo" {
+			t.Errorf("nativeChanTry panicked with %v, want foo", r)
+		}
+	}()
+	nativeChanTry(func() { panic("foo") })
+	t.Error("nativeChanTry did not propagate the panic")
+}
+
+func TestNativeChanIter(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, c interface{}) (*Object, *BaseException) {
+		o, raised := WrapNative(f, reflect.ValueOf(c))
+		if raised != nil {
+			return nil, raised
+		}
+		return TupleType.Call(f, []*Object{o}, nil)
+	})
+	c := make(chan string, 3)
+	c <- "foo"
+	c <- "bar"
+	close(c)
+	cases := []invokeTestCase{
+		{args: wrapArgs(c), want: newTestTuple("foo", "bar").ToObject()},
+		{args: wrapArgs(c), want: NewTuple().ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeChanLen(t *testing.T) {
+	c := make(chan bool, 5)
+	c <- true
+	c <- false
+	cas := invokeTestCase{args: wrapArgs(c), want: NewInt(2).ToObject()}
+	if err := runInvokeTestCase(wrapFuncForTest(Len), &cas); err != "" {
+		t.Error(err)
+	}
+}
+
+func callNativeMethod(f *Frame, o *Object, name string, args ...*Object) (*Object, *BaseException) {
+	method, raised := GetAttr(f, o, NewStr(name), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return method.Call(f, args, nil)
+}
+
+func TestSelectChannels(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, cases *Object, block bool) (*Object, *BaseException) {
+		return SelectChannels(f, cases, block)
+	})
+	full := make(chan int, 1)
+	full <- 42
+	closed := make(chan string)
+	close(closed)
+	fullObj := mustNotRaise(WrapNative(NewRootFrame(), reflect.ValueOf(full)))
+	emptyObj := mustNotRaise(WrapNative(NewRootFrame(), reflect.ValueOf(make(chan int, 1))))
+	closedObj := mustNotRaise(WrapNative(NewRootFrame(), reflect.ValueOf(closed)))
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewList(), false), want: newTestTuple(-1, None, false).ToObject()},
+		{args: wrapArgs(NewList(emptyObj), false), want: newTestTuple(-1, None, false).ToObject()},
+		{args: wrapArgs(NewList(emptyObj, fullObj), true), want: newTestTuple(1, 42, true).ToObject()},
+		{args: wrapArgs(NewList(closedObj), true), want: newTestTuple(0, "", false).ToObject()},
+		{args: wrapArgs(NewList(fullObj, newTestTuple(emptyObj, 123).ToObject()), true), want: newTestTuple(1, None, true).ToObject()},
+		{args: wrapArgs(NewList(emptyObj), false), want: newTestTuple(0, 123, true).ToObject()},
+		{args: wrapArgs(NewList(NewInt(1).ToObject()), true), wantExc: mustCreateException(TypeErrorType, "select case must be a channel or (channel, value) tuple, not int")},
+		{args: wrapArgs(NewList(newTestTuple(emptyObj).ToObject()), true), wantExc: mustCreateException(TypeErrorType, "send cases must be (channel, value) tuples")},
+		{args: wrapArgs(NewList(newTestTuple(emptyObj, "foo").ToObject()), true), wantExc: mustCreateException(TypeErrorType, "an int is required")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
 func TestGetNativeTypeTypedefs(t *testing.T) {
 	type testBool bool
 	type testInt int
@@ -398,7 +710,23 @@ func TestMaybeConvertValue(t *testing.T) {
 		{NewFloat(0.5).ToObject(), reflect.TypeOf(float32(0)), float32(0.5), nil},
 		{fooNative.ToObject(), reflect.TypeOf(&fooStruct{}), foo, nil},
 		{None, reflect.TypeOf((*int)(nil)), (*int)(nil), nil},
-		{None, reflect.TypeOf(""), nil, mustCreateException(TypeErrorType, "an string is required")},
+		{None, reflect.TypeOf(""), nil, mustCreateException(TypeErrorType, "a string is required")},
+		{newTestList(1, 2, 3).ToObject(), reflect.TypeOf([]int{}), []int{1, 2, 3}, nil},
+		{newTestTuple("foo", "bar").ToObject(), reflect.TypeOf([]string{}), []string{"foo", "bar"}, nil},
+		{newTestList(1, 2).ToObject(), reflect.TypeOf([2]float64{}), [2]float64{1, 2}, nil},
+		{NewList().ToObject(), reflect.TypeOf([]int{}), []int{}, nil},
+		{newTestList(newTestList(1).ToObject(), NewList().ToObject()).ToObject(), reflect.TypeOf([][]int{}), [][]int{{1}, {}}, nil},
+		{newTestDict("foo", "bar").ToObject(), reflect.TypeOf(map[string]string{}), map[string]string{"foo": "bar"}, nil},
+		{newTestDict(1, newTestList("a").ToObject()).ToObject(), reflect.TypeOf(map[int][]string{}), map[int][]string{1: {"a"}}, nil},
+		{newTestList(1, "foo").ToObject(), reflect.TypeOf([]interface{}{}), []interface{}{1, "foo"}, nil},
+		{NewInt(42).ToObject(), reflect.TypeOf((*int)(nil)), func() *int { i := 42; return &i }(), nil},
+		{NewStr("foo").ToObject(), reflect.TypeOf([]int{}), nil, mustCreateException(TypeErrorType, "a []int is required")},
+		{newTestList(1, "foo").ToObject(), reflect.TypeOf([]int{}), nil, mustCreateException(TypeErrorType, "[]int index 1: an int is required")},
+		{newTestList(1).ToObject(), reflect.TypeOf([2]int{}), nil, mustCreateException(TypeErrorType, "a [2]int is required, got list of length 1")},
+		{newTestDict("foo", 1).ToObject(), reflect.TypeOf(map[string]string{}), nil, mustCreateException(TypeErrorType, "map[string]string value for key 'foo': a string is required")},
+		{newTestDict(1, "foo").ToObject(), reflect.TypeOf(map[string]string{}), nil, mustCreateException(TypeErrorType, "map[string]string key: a string is required")},
+		{newTestList(newTestDict("foo", 1).ToObject()).ToObject(), reflect.TypeOf([]map[string]string{}), nil, mustCreateException(TypeErrorType, "[]map[string]string index 0: map[string]string value for key 'foo': a string is required")},
+		{NewStr("foo").ToObject(), reflect.TypeOf((*int)(nil)), nil, mustCreateException(TypeErrorType, "an int is required")},
 	}
 	for _, cas := range cases {
 		fun := wrapFuncForTest(func(f *Frame) *BaseException {
@@ -619,8 +947,8 @@ func TestNativeSliceSetItemSlice(t *testing.T) {
 		{args: wrapArgs([]int{1, 2, 3}, newTestSlice(2, None), newTestList("foo"), None), wantExc: mustCreateException(TypeErrorType, "an int is required")},
 		{args: wrapArgs(bar, 1, 42, None), wantExc: mustCreateException(TypeErrorType, "cannot set slice element")},
 		{args: wrapArgs(bar, newTestSlice(1), newTestList(42), None), wantExc: mustCreateException(TypeErrorType, "cannot set slice element")},
-		{args: wrapArgs([]string{"foo", "bar"}, 1, 123.0, None), wantExc: mustCreateException(TypeErrorType, "an string is required")},
-		{args: wrapArgs([]string{"foo", "bar"}, 1, 123.0, None), wantExc: mustCreateException(TypeErrorType, "an string is required")},
+		{args: wrapArgs([]string{"foo", "bar"}, 1, 123.0, None), wantExc: mustCreateException(TypeErrorType, "a string is required")},
+		{args: wrapArgs([]string{"foo", "bar"}, 1, 123.0, None), wantExc: mustCreateException(TypeErrorType, "a string is required")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -664,7 +992,145 @@ func TestNativeStructFieldSet(t *testing.T) {
 		{args: wrapArgs(&fooStruct{}, "Baz", 1.5), want: NewFloat(1.5).ToObject()},
 		{args: wrapArgs(fooStruct{}, "bar", 123), wantExc: mustCreateException(TypeErrorType, `cannot set field 'bar' of type 'fooStruct'`)},
 		{args: wrapArgs(fooStruct{}, "qux", "abc"), wantExc: mustCreateException(AttributeErrorType, `'fooStruct' has no attribute 'qux'`)},
-		{args: wrapArgs(&fooStruct{}, "Baz", "abc"), wantExc: mustCreateException(TypeErrorType, "an float64 is required")},
+		{args: wrapArgs(&fooStruct{}, "Baz", "abc"), wantExc: mustCreateException(TypeErrorType, "a float64 is required")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeMap(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, m *Object) (*Object, *BaseException) {
+		if raised := SetItem(f, m, NewStr("bar").ToObject(), NewInt(2).ToObject()); raised != nil {
+			return nil, raised
+		}
+		if raised := DelItem(f, m, NewStr("foo").ToObject()); raised != nil {
+			return nil, raised
+		}
+		bar, raised := GetItem(f, m, NewStr("bar").ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		hasFoo, raised := Contains(f, m, NewStr("foo").ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		keys, raised := ListType.Call(f, Args{m}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		l, raised := Len(f, m)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(bar, GetBool(hasFoo).ToObject(), keys, l.ToObject()).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(map[string]int{"foo": 1}), want: newTestTuple(2, false, newTestList("bar"), 1).ToObject()},
+		{args: wrapArgs(map[string]int{}), wantExc: mustCreateException(KeyErrorType, "foo")},
+		{args: wrapArgs(map[int]int{}), wantExc: mustCreateException(TypeErrorType, "an int is required")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeMapMethods(t *testing.T) {
+	m := map[string]int{"foo": 1}
+	cases := []invokeTestCase{
+		{args: wrapArgs(m, "items"), want: newTestList(newTestTuple("foo", 1)).ToObject()},
+		{args: wrapArgs(m, "keys"), want: newTestList("foo").ToObject()},
+		{args: wrapArgs(m, "values"), want: newTestList(1).ToObject()},
+		{args: wrapArgs(map[string]int{}, "keys"), want: NewList().ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(func(f *Frame, o *Object, name *Str) (*Object, *BaseException) {
+			method, raised := GetAttr(f, o, name, nil)
+			if raised != nil {
+				return nil, raised
+			}
+			return method.Call(f, nil, nil)
+		}), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeMapRepr(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(map[string]int{"foo": 1}), want: NewStr("map[string]int{'foo': 1}").ToObject()},
+		{args: wrapArgs(map[int]bool{}), want: NewStr("map[int]bool{}").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+type testNativeCounter struct {
+	Count int
+}
+
+func (c *testNativeCounter) Incr() {
+	c.Count++
+}
+
+type testNativeContainer struct {
+	testNativeCounter
+	Named testNativeCounter
+}
+
+type testNativePtrContainer struct {
+	*testNativeCounter
+}
+
+func TestNativeStructPromotedField(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object, attr *Str, value *Object) (*Object, *BaseException) {
+		if value != None {
+			if raised := SetAttr(f, o, attr, value); raised != nil {
+				return nil, raised
+			}
+		}
+		return GetAttr(f, o, attr, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(testNativeContainer{testNativeCounter: testNativeCounter{3}}, "Count", None), want: NewInt(3).ToObject()},
+		{args: wrapArgs(&testNativeContainer{}, "Count", 42), want: NewInt(42).ToObject()},
+		{args: wrapArgs(testNativePtrContainer{&testNativeCounter{5}}, "Count", None), want: NewInt(5).ToObject()},
+		{args: wrapArgs(testNativePtrContainer{&testNativeCounter{}}, "Count", 7), want: NewInt(7).ToObject()},
+		{args: wrapArgs(testNativePtrContainer{}, "Count", None), wantExc: mustCreateException(AttributeErrorType, "cannot access field 'Count' of type 'testNativePtrContainer' through nil embedded pointer")},
+		{args: wrapArgs(testNativePtrContainer{}, "Count", 7), wantExc: mustCreateException(AttributeErrorType, "cannot access field 'Count' of type 'testNativePtrContainer' through nil embedded pointer")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNativeStructPointerMethod(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object) (*Object, *BaseException) {
+		named, raised := GetAttr(f, o, NewStr("Named"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		incr, raised := GetAttr(f, named, NewStr("Incr"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if _, raised := incr.Call(f, nil, nil); raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, named, NewStr("Count"), nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(&testNativeContainer{Named: testNativeCounter{1}}), want: NewInt(2).ToObject()},
+		{args: wrapArgs(testNativeContainer{}), wantExc: mustCreateException(TypeErrorType, "a *grumpy.testNativeCounter is required")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
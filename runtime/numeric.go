@@ -1,7 +1,10 @@
 package grumpy
 
 import (
+	"bytes"
+	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -68,3 +71,146 @@ func numParseInteger(z *big.Int, s string, base int) (*big.Int, bool) {
 func numInIntRange(i *big.Int) bool {
 	return i.Cmp(minIntBig) >= 0 && i.Cmp(maxIntBig) <= 0
 }
+
+// formatSpec is a parsed PEP 3101 format specification of the form
+// [[fill]align][sign][#][0][width][,][.precision][type], as accepted by the
+// __format__ methods of numeric types.
+type formatSpec struct {
+	fill, align, sign byte
+	alt, grouping     bool
+	width, precision  int
+	// typ is the presentation type or zero when none was given.
+	typ byte
+}
+
+// parseFormatSpec parses s into a formatSpec. Omitted characters are left
+// zero, except width and precision which are -1 when absent.
+func parseFormatSpec(f *Frame, s string) (formatSpec, *BaseException) {
+	spec := formatSpec{width: -1, precision: -1}
+	isAlign := func(c byte) bool {
+		return strings.IndexByte("<>=^", c) != -1
+	}
+	i := 0
+	if len(s) > 1 && isAlign(s[1]) {
+		spec.fill, spec.align = s[0], s[1]
+		i = 2
+	} else if len(s) > 0 && isAlign(s[0]) {
+		spec.align = s[0]
+		i = 1
+	}
+	if i < len(s) && strings.IndexByte("+- ", s[i]) != -1 {
+		spec.sign = s[i]
+		i++
+	}
+	if i < len(s) && s[i] == '#' {
+		spec.alt = true
+		i++
+	}
+	if i < len(s) && s[i] == '0' && spec.fill == 0 {
+		// Zero padding is shorthand for a '0' fill between the sign
+		// and the digits.
+		spec.fill = '0'
+		if spec.align == 0 {
+			spec.align = '='
+		}
+		i++
+	}
+	var raised *BaseException
+	if spec.width, i, raised = parseFormatSpecInt(f, s, i); raised != nil {
+		return spec, raised
+	}
+	if i < len(s) && s[i] == ',' {
+		spec.grouping = true
+		i++
+	}
+	if i < len(s) && s[i] == '.' {
+		if spec.precision, i, raised = parseFormatSpecInt(f, s, i+1); raised != nil {
+			return spec, raised
+		}
+		if spec.precision == -1 {
+			return spec, f.RaiseType(ValueErrorType, "Format specifier missing precision")
+		}
+	}
+	if len(s)-i > 1 {
+		return spec, f.RaiseType(ValueErrorType, "Invalid conversion specification")
+	}
+	if i < len(s) {
+		spec.typ = s[i]
+	}
+	if spec.grouping {
+		switch spec.typ {
+		case 0, 'd', 'e', 'E', 'f', 'F', 'g', 'G', '%':
+		default:
+			return spec, f.RaiseType(ValueErrorType, fmt.Sprintf("Cannot specify ',' with '%c'.", spec.typ))
+		}
+	}
+	return spec, nil
+}
+
+// parseFormatSpecInt parses the run of digits in s starting at i, returning
+// its value, or -1 when there are none, and the index following it.
+func parseFormatSpecInt(f *Frame, s string, i int) (int, int, *BaseException) {
+	j := i
+	for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+		j++
+	}
+	if j == i {
+		return -1, i, nil
+	}
+	n, err := strconv.Atoi(s[i:j])
+	if err != nil {
+		return 0, j, f.RaiseType(ValueErrorType, "Too many decimal digits in format string")
+	}
+	return n, j, nil
+}
+
+// pad lays out a formatted number according to spec's width, fill, alignment
+// and grouping. sign is the sign prefix, if any, and body the remainder,
+// whose leading digits are grouped into thousands when requested.
+func (spec formatSpec) pad(sign, body string) string {
+	n := 0
+	for n < len(body) && body[n] >= '0' && body[n] <= '9' {
+		n++
+	}
+	digits, rest := body[:n], body[n:]
+	// Like CPython, non-numeric bodies like "inf" are padded but never
+	// grouped.
+	grouping := spec.grouping && n > 0
+	if spec.fill == '0' && spec.align == '=' {
+		// Zero padding extends the digits themselves so that they're
+		// grouped along with the rest, e.g. "0,001,234".
+		numDigits, want := len(digits), spec.width-len(sign)-len(rest)
+		for numDigits < want && (!grouping || numDigits+(numDigits-1)/3 < want) {
+			numDigits++
+		}
+		digits = strings.Repeat("0", numDigits-len(digits)) + digits
+	}
+	if grouping && len(digits) > 3 {
+		var buf bytes.Buffer
+		head := (len(digits)-1)%3 + 1
+		buf.WriteString(digits[:head])
+		for i := head; i < len(digits); i += 3 {
+			buf.WriteByte(',')
+			buf.WriteString(digits[i : i+3])
+		}
+		digits = buf.String()
+	}
+	padding := spec.width - len(sign) - len(digits) - len(rest)
+	if padding <= 0 {
+		return sign + digits + rest
+	}
+	fill := spec.fill
+	if fill == 0 {
+		fill = ' '
+	}
+	switch spec.align {
+	case '<':
+		return sign + digits + rest + strings.Repeat(string(fill), padding)
+	case '^':
+		left := strings.Repeat(string(fill), padding/2)
+		return left + sign + digits + rest + strings.Repeat(string(fill), padding-padding/2)
+	case '=':
+		return sign + strings.Repeat(string(fill), padding) + digits + rest
+	}
+	return strings.Repeat(string(fill), padding) + sign + digits + rest
+}
@@ -17,6 +17,7 @@ package grumpy
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"sync/atomic"
 	"unsafe"
 )
@@ -35,8 +36,17 @@ var (
 		flags: typeFlagDefault,
 		slots: typeSlots{Basis: &basisSlot{objectBasisFunc}},
 	}
+	// objectFinalizer is objectFinalize for objects not created on behalf
+	// of a particular stack. It's assigned in init() since objectFinalize
+	// depends indirectly on objects created by newObject during package
+	// initialization.
+	objectFinalizer func(*Object)
 )
 
+func init() {
+	objectFinalizer = func(o *Object) { objectFinalize(o, stackPolicy{}) }
+}
+
 // Object represents Python 'object' objects.
 type Object struct {
 	typ  *Type `attr:"__class__"`
@@ -46,15 +56,107 @@ type Object struct {
 
 func newObject(t *Type) *Object {
 	var dict *Dict
-	if t != ObjectType {
+	if t != ObjectType && t.flags&typeFlagNoDict == 0 {
 		dict = NewDict()
 	}
 	o := (*Object)(unsafe.Pointer(reflect.New(t.basis).Pointer()))
 	o.typ = t
 	o.setDict(dict)
+	if t.slots.Del != nil {
+		runtime.SetFinalizer(o, objectFinalizer)
+	}
 	return o
 }
 
+// objectSetPolicy makes the finalizer of o, which was created on behalf of
+// the code running in f, call o's __del__ method subject to the Policy and
+// step budget of f's stack. It does nothing if o's type doesn't define
+// __del__.
+func objectSetPolicy(f *Frame, o *Object) {
+	if o.typ.slots.Del == nil {
+		return
+	}
+	s := f.stackPolicy()
+	runtime.SetFinalizer(o, nil)
+	runtime.SetFinalizer(o, func(o *Object) { objectFinalize(o, s) })
+}
+
+// objectFinalize is the finalizer for objects whose type defines __del__ and
+// for weakly referenced objects. It runs some time after the Go garbage
+// collector finds o unreachable. o's weakrefs are cleared and then their
+// callbacks and o's __del__ method, if any, are called on a new goroutine so
+// that Python code that blocks doesn't hold up other finalizers. __del__ runs
+// subject to s, the Policy of the stack that created o. Exceptions raised by
+// __del__ are printed to stderr and otherwise ignored. Like CPython, there's
+// no guarantee that __del__ is called for objects that are still alive when
+// the program exits.
+//
+// __del__ may resurrect o by storing a reference to it somewhere. It is not
+// called again when o next becomes unreachable but o may be weakly referenced
+// again, so objects with __del__ are freed by the collection after the one
+// that finalized them.
+func objectFinalize(o *Object, s stackPolicy) {
+	if fn := objectPrepareFinalize(o, s); fn != nil {
+		go fn()
+	}
+}
+
+// objectPrepareFinalize clears o's weakrefs and returns a function that calls
+// their callbacks and o's __del__ method, or nil if there's nothing to call.
+func objectPrepareFinalize(o *Object, s stackPolicy) func() {
+	refs, dead := weakRefFinalizeReferent(o)
+	if !dead {
+		// A weakref handed out a reference to o after it was
+		// scheduled for finalization so it may be alive again.
+		runtime.SetFinalizer(o, func(o *Object) { objectFinalize(o, s) })
+		return nil
+	}
+	del := o.typ.slots.Del
+	if del == nil {
+		if len(refs) == 0 {
+			return nil
+		}
+		return func() { weakRefCallCallbacks(refs) }
+	}
+	// Weakrefs created from now on get a new base weakref and are
+	// cleared by objectFinalizeWeakRefs.
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&o.ref)), nil)
+	runtime.SetFinalizer(o, objectFinalizeWeakRefs)
+	return func() {
+		weakRefCallCallbacks(refs)
+		objectCallDel(s.newRootFrame(), o, del)
+	}
+}
+
+// objectCallDel calls o's __del__ method, which is del, printing any exception
+// it raises.
+func objectCallDel(f *Frame, o *Object, del *unaryOpSlot) {
+	if _, raised := del.Fn(f, o); raised != nil {
+		s := fmt.Sprintf("<bound method %s.__del__>", o.typ.Name())
+		if m, raised := GetAttr(f, o, NewStr("__del__"), nil); raised == nil {
+			if repr, raised := Repr(f, m); raised == nil {
+				s = repr.Value()
+			}
+		}
+		msg := raised.typ.Name()
+		if repr, raised := Repr(f, raised.ToObject()); raised == nil {
+			msg += ": " + repr.Value()
+		}
+		Stderr.writeString(fmt.Sprintf("Exception %s in %s ignored\n", msg, s))
+	}
+}
+
+// objectFinalizeWeakRefs is the finalizer for objects whose __del__ method
+// has already been called.
+func objectFinalizeWeakRefs(o *Object) {
+	refs, dead := weakRefFinalizeReferent(o)
+	if !dead {
+		runtime.SetFinalizer(o, objectFinalizeWeakRefs)
+	} else if len(refs) > 0 {
+		go weakRefCallCallbacks(refs)
+	}
+}
+
 // Call invokes the callable Python object o with the given positional and
 // keyword args. args must be non-nil (but can be empty). kwargs can be nil.
 func (o *Object) Call(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -114,6 +216,10 @@ func objectDelAttr(f *Frame, o *Object, name *Str) *BaseException {
 		if del := desc.Type().slots.Delete; del != nil {
 			return del.Fn(f, desc, o)
 		}
+		// A data descriptor that can't be deleted shadows the dict.
+		if desc.Type().slots.Set != nil {
+			return f.RaiseType(AttributeErrorType, "__delete__")
+		}
 	}
 	deleted := false
 	d := o.Dict()
@@ -168,6 +274,10 @@ func objectHash(f *Frame, o *Object) (*Object, *BaseException) {
 	return NewInt(int(uintptr(o.toPointer()))).ToObject(), nil
 }
 
+func objectInit(f *Frame, _ *Object, _ Args, _ KWArgs) (*Object, *BaseException) {
+	return None, nil
+}
+
 func objectNew(f *Frame, t *Type, _ Args, _ KWArgs) (*Object, *BaseException) {
 	if t.flags&typeFlagInstantiable == 0 {
 		format := "object.__new__(%s) is not safe, use %s.__new__()"
@@ -203,7 +313,7 @@ func objectReduceEx(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	}
 	if reduce != nil && reduce != objectReduceFunc {
 		// __reduce__ is overridden so prefer using it.
-		return reduce.Call(f, args, nil)
+		return reduce.Call(f, args[:1], nil)
 	}
 	return objectReduceCommon(f, args)
 }
@@ -215,6 +325,10 @@ func objectSetAttr(f *Frame, o *Object, name *Str, value *Object) *BaseException
 		if typeSet := typeAttr.typ.slots.Set; typeSet != nil {
 			return typeSet.Fn(f, typeAttr, o, value)
 		}
+		// A data descriptor that can't be set shadows the dict.
+		if typeAttr.typ.slots.Delete != nil {
+			return f.RaiseType(AttributeErrorType, "__set__")
+		}
 	}
 	if d := o.Dict(); d != nil {
 		if raised := d.SetItem(f, name.ToObject(), value); raised == nil || !raised.isInstance(KeyErrorType) {
@@ -232,6 +346,7 @@ func initObjectType(dict map[string]*Object) {
 	ObjectType.slots.DelAttr = &delAttrSlot{objectDelAttr}
 	ObjectType.slots.GetAttribute = &getAttributeSlot{objectGetAttribute}
 	ObjectType.slots.Hash = &unaryOpSlot{objectHash}
+	ObjectType.slots.Init = &initSlot{objectInit}
 	ObjectType.slots.New = &newSlot{objectNew}
 	ObjectType.slots.SetAttr = &setAttrSlot{objectSetAttr}
 }
@@ -253,11 +368,13 @@ func objectReconstructor(f *Frame, args Args, _ KWArgs) (*Object, *BaseException
 		return nil, raised
 	}
 	if basisType != ObjectType {
-		initMethod, raised := GetAttr(f, basisType.ToObject(), NewStr("__init__"), None)
+		// Look up __init__ in the basis type's MRO so that type.__init__
+		// is not found via the metaclass.
+		initMethod, raised := basisType.mroLookup(f, NewStr("__init__"))
 		if raised != nil {
 			return nil, raised
 		}
-		if initMethod != None {
+		if initMethod != nil {
 			if _, raised := initMethod.Call(f, Args{o, state}, nil); raised != nil {
 				return nil, raised
 			}
@@ -267,7 +384,6 @@ func objectReconstructor(f *Frame, args Args, _ KWArgs) (*Object, *BaseException
 }
 
 func objectReduceCommon(f *Frame, args Args) (*Object, *BaseException) {
-	// TODO: Support __getstate__ and __getnewargs__.
 	o := args[0]
 	t := o.Type()
 	proto := 0
@@ -293,8 +409,12 @@ func objectReduceCommon(f *Frame, args Args) (*Object, *BaseException) {
 			}
 		}
 		newArgs := NewTuple3(t.ToObject(), basisType.ToObject(), state).ToObject()
-		if d := o.Dict(); d != nil {
-			return NewTuple3(objectReconstructorFunc, newArgs, d.ToObject()).ToObject(), nil
+		dict, raised := objectGetState(f, o)
+		if raised != nil {
+			return nil, raised
+		}
+		if dict != None {
+			return NewTuple3(objectReconstructorFunc, newArgs, dict).ToObject(), nil
 		}
 		return NewTuple2(objectReconstructorFunc, newArgs).ToObject(), nil
 	}
@@ -314,9 +434,9 @@ func objectReduceCommon(f *Frame, args Args) (*Object, *BaseException) {
 		}
 		newArgs = append(newArgs, toTupleUnsafe(extraNewArgs).elems...)
 	}
-	dict := None
-	if d := o.Dict(); d != nil {
-		dict = d.ToObject()
+	dict, raised := objectGetState(f, o)
+	if raised != nil {
+		return nil, raised
 	}
 	// For proto >= 2 include list and dict items.
 	listItems := None
@@ -344,6 +464,22 @@ func objectReduceCommon(f *Frame, args Args) (*Object, *BaseException) {
 	return NewTuple5(newFunc, NewTuple(newArgs...).ToObject(), dict, listItems, dictItems).ToObject(), nil
 }
 
+// objectGetState returns the state of o to be pickled: the result of its
+// __getstate__ method if it has one, otherwise its __dict__ or None.
+func objectGetState(f *Frame, o *Object) (*Object, *BaseException) {
+	getState, raised := GetAttr(f, o, NewStr("__getstate__"), None)
+	if raised != nil {
+		return nil, raised
+	}
+	if getState != None {
+		return getState.Call(f, nil, nil)
+	}
+	if d := o.Dict(); d != nil {
+		return d.ToObject(), nil
+	}
+	return None, nil
+}
+
 func objectGetDict(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "_get_dict", args, ObjectType); raised != nil {
 		return nil, raised
@@ -362,10 +498,18 @@ func objectSetDict(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 		return nil, raised
 	}
 	o := args[0]
-	if o.Type() == ObjectType {
+	if o.Type() == ObjectType || o.typ.flags&typeFlagNoDict != 0 {
 		format := "'%s' object has no attribute '__dict__'"
 		return nil, f.RaiseType(AttributeErrorType, fmt.Sprintf(format, o.typ.Name()))
 	}
-	o.setDict(toDictUnsafe(args[1]))
+	d := toDictUnsafe(args[1])
+	if !o.isInstance(TypeType) {
+		o.setDict(d)
+		return None, nil
+	}
+	t := toTypeUnsafe(o)
+	d.addType(t)
+	o.setDict(d)
+	t.invalidateAttrCache()
 	return None, nil
 }
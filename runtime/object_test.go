@@ -15,10 +15,13 @@
 package grumpy
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 	"regexp"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestObjectCall(t *testing.T) {
@@ -71,6 +74,134 @@ func TestNewObject(t *testing.T) {
 	}
 }
 
+func TestObjectDel(t *testing.T) {
+	called := make(chan bool, 1)
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__del__": newBuiltinFunction("__del__", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+			called <- true
+			return None, nil
+		}).ToObject(),
+	}))
+	mustNotRaise(fooType.Call(NewRootFrame(), nil, nil))
+	timeout := time.After(time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-called:
+			return
+		case <-timeout:
+			t.Fatal("__del__ was not called")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestObjectFinalize(t *testing.T) {
+	var resurrected []*Object
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__del__": newBuiltinFunction("__del__", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+			resurrected = append(resurrected, args[0])
+			return None, nil
+		}).ToObject(),
+	}))
+	o := newObject(fooType)
+	// Finalize o directly rather than waiting for the garbage collector.
+	runtime.SetFinalizer(o, nil)
+	r := newTestWeakRef(o, nil)
+	objectPrepareFinalize(o, stackPolicy{})()
+	if len(resurrected) != 1 || resurrected[0] != o {
+		t.Fatalf("__del__ called with %v, want [%v]", resurrected, o)
+	}
+	if got := r.referent(); got != nil {
+		t.Errorf("weakref to finalized object returned %v, want nil", got)
+	}
+	// o was resurrected by __del__ so new weakrefs to it are alive and
+	// are cleared without calling __del__ again.
+	r = newTestWeakRef(o, nil)
+	if got := r.referent(); got != o {
+		t.Errorf("weakref to resurrected object returned %v, want %v", got, o)
+	}
+	// The first finalization only resets the finalizer because r has
+	// handed out o.
+	for i := 0; i < 2; i++ {
+		runtime.SetFinalizer(o, nil)
+		objectFinalizeWeakRefs(o)
+	}
+	if got := r.referent(); got != nil {
+		t.Errorf("weakref to finalized object returned %v, want nil", got)
+	}
+	if len(resurrected) != 1 {
+		t.Errorf("__del__ called %d times, want 1", len(resurrected))
+	}
+}
+
+func TestObjectFinalizeRaises(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__del__": newBuiltinFunction("__del__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return nil, f.RaiseType(ValueErrorType, "foo")
+		}).ToObject(),
+		"__repr__": newBuiltinFunction("__repr__", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+			return NewStr("<Foo>").ToObject(), nil
+		}).ToObject(),
+	}))
+	o := newObject(fooType)
+	runtime.SetFinalizer(o, nil)
+	var stderr bytes.Buffer
+	restore := Stderr.Redirect(nil, &stderr)
+	objectPrepareFinalize(o, stackPolicy{})()
+	restore()
+	want := "Exception ValueError: ValueError('foo',) in <bound method Foo.__del__ of <Foo>> ignored\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("objectFinalize(%v) wrote %q, want %q", o, got, want)
+	}
+}
+
+func TestObjectDelBlocking(t *testing.T) {
+	unblock := make(chan bool)
+	blocked := make(chan bool, 1)
+	called := make(chan bool, 1)
+	blockingType := newTestClass("Blocking", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__del__": newBuiltinFunction("__del__", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+			blocked <- true
+			<-unblock
+			return None, nil
+		}).ToObject(),
+	}))
+	defer close(unblock)
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__del__": newBuiltinFunction("__del__", func(*Frame, Args, KWArgs) (*Object, *BaseException) {
+			called <- true
+			return None, nil
+		}).ToObject(),
+	}))
+	f := NewRootFrame()
+	mustNotRaise(blockingType.Call(f, nil, nil))
+	timeout := time.After(time.Second)
+	for waiting := blocked; waiting != nil; {
+		runtime.GC()
+		select {
+		case <-waiting:
+			// Objects finalized while a __del__ method blocks are
+			// still finalized.
+			mustNotRaise(fooType.Call(f, nil, nil))
+			waiting = nil
+		case <-timeout:
+			t.Fatal("__del__ was not called")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	for {
+		runtime.GC()
+		select {
+		case <-called:
+			return
+		case <-timeout:
+			t.Fatal("__del__ was not called while another __del__ blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
 func TestObjectString(t *testing.T) {
 	typ := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
 		"__repr__": newBuiltinFunction("__repr__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -125,12 +256,18 @@ func TestObjectDelAttr(t *testing.T) {
 			return None, nil
 		}).ToObject(),
 	}))
-	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"deller": newObject(dellerType)}))
+	setterType := newTestClass("Setter", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__set__": newBuiltinFunction("__set__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return None, nil
+		}).ToObject(),
+	}))
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"deller": newObject(dellerType), "setter": newObject(setterType)}))
 	foo := newObject(fooType)
 	if raised := foo.Dict().SetItemString(NewRootFrame(), "attr", NewInt(123).ToObject()); raised != nil {
 		t.Fatal(raised)
 	}
 	cases := []invokeTestCase{
+		{args: wrapArgs(foo, "setter"), wantExc: mustCreateException(AttributeErrorType, "__delete__")},
 		{args: wrapArgs(foo, "deller"), want: None},
 		{args: wrapArgs(newObject(fooType), "foo"), wantExc: mustCreateException(AttributeErrorType, "'Foo' object has no attribute 'foo'")},
 		{args: wrapArgs(newObject(fooType), "deller"), wantExc: mustCreateException(AttributeErrorType, "attr")},
@@ -341,6 +478,12 @@ func TestObjectReduce(t *testing.T) {
 			return NewInt(123).ToObject(), nil
 		}).ToObject(),
 	}))
+	getStateType := newTestClass("GetState", []*Type{StrType}, newStringDict(map[string]*Object{
+		"__getstate__": newBuiltinFunction("__getstate__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return NewStr("state").ToObject(), nil
+		}).ToObject(),
+	}))
+	getStateInst := &Str{Object: Object{typ: getStateType}, value: "getState"}
 	// Attempting to reduce an int will fail with "can't pickle" but
 	// subclasses can be reduced.
 	intSubclass := newTestClass("IntSubclass", []*Type{IntType}, NewDict())
@@ -355,6 +498,8 @@ func TestObjectReduce(t *testing.T) {
 		{args: wrapArgs("__reduce__", newObject(fooType), wrapArgs(2)), want: newTestTuple("", NewDict(), None, None).ToObject()},
 		{args: wrapArgs("__reduce_ex__", newObject(fooType), Args{}), want: newTestTuple("", NewDict(), None, None).ToObject()},
 		{args: wrapArgs("__reduce_ex__", newObject(reduceOverrideType), Args{}), want: newTestTuple("ReduceOverride", None, None, None).ToObject()},
+		{args: wrapArgs("__reduce__", getStateInst, Args{}), want: newTestTuple("getState", "state", None, None).ToObject()},
+		{args: wrapArgs("__reduce__", getStateInst, wrapArgs(2)), want: newTestTuple("getState", "state", None, None).ToObject()},
 		{args: wrapArgs("__reduce__", fooNoDict, Args{}), want: newTestTuple("fooNoDict", None, None, None).ToObject()},
 		{args: wrapArgs("__reduce__", newTestList(1, 2, 3), wrapArgs(2)), want: newTestTuple(NewList(), None, newTestList(1, 2, 3), None).ToObject()},
 		{args: wrapArgs("__reduce__", newTestDict("a", 1, "b", 2), wrapArgs(2)), want: newTestTuple(NewDict(), None, None, newTestDict("a", 1, "b", 2)).ToObject()},
@@ -398,11 +543,17 @@ func TestObjectSetAttr(t *testing.T) {
 		}).ToObject(),
 	}))
 	setter := newObject(setterType)
-	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"setter": setter}))
+	dellerType := newTestClass("Deller", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__delete__": newBuiltinFunction("__delete__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return None, nil
+		}).ToObject(),
+	}))
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"setter": setter, "deller": newObject(dellerType)}))
 	foo := newObject(fooType)
 	cases := []invokeTestCase{
 		{args: wrapArgs(newObject(fooType), "foo", "abc"), want: NewStr("abc").ToObject()},
 		{args: wrapArgs(foo, "setter", "baz"), want: NewTuple(setter, foo, NewStr("baz").ToObject()).ToObject()},
+		{args: wrapArgs(foo, "deller", "baz"), wantExc: mustCreateException(AttributeErrorType, "__set__")},
 		{args: wrapArgs(newObject(ObjectType), "foo", 10), wantExc: mustCreateException(AttributeErrorType, "'object' has no attribute 'foo'")},
 	}
 	for _, cas := range cases {
@@ -0,0 +1,158 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+var objectRType = reflect.TypeOf(Object{})
+
+// ObjectHistogram walks the Python objects reachable from sys.modules and the
+// stack of f and returns a dict mapping type names to the number of reachable
+// objects of that type.
+//
+// Only references the runtime knows about are followed: attributes, container
+// elements and the fields of builtin objects. In particular, variables local
+// to a function and closed over by nested functions live in Go and are not
+// visible.
+func ObjectHistogram(f *Frame) (*Dict, *BaseException) {
+	counts := map[string]int{}
+	walkObjectGraph(f, func(o, _ *Object) bool {
+		counts[o.typ.Name()]++
+		return true
+	})
+	d := NewDict()
+	for name, n := range counts {
+		if raised := d.SetItemString(f, name, NewInt(n).ToObject()); raised != nil {
+			return nil, raised
+		}
+	}
+	return d, nil
+}
+
+// FindReferencePath returns a shortest chain of references leading from a root
+// object (sys.modules or a frame on the stack of f) to target. The first
+// element of the returned list is the root and the last is target. nil is
+// returned if target is not reachable. See ObjectHistogram for the references
+// that are followed.
+func FindReferencePath(f *Frame, target *Object) *List {
+	parents := map[*Object]*Object{}
+	found := false
+	walkObjectGraph(f, func(o, parent *Object) bool {
+		parents[o] = parent
+		found = o == target
+		return !found
+	})
+	if !found {
+		return nil
+	}
+	var path []*Object
+	for o := target; o != nil; o = parents[o] {
+		path = append(path, o)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return NewList(path...)
+}
+
+// Referents returns a list of the objects directly referenced by o.
+func Referents(o *Object) *List {
+	return NewList(objectReferents(o)...)
+}
+
+// walkObjectGraph visits each object reachable from the roots of f in
+// breadth first order, calling visit with the object and the object it was
+// first reached from (nil for roots). The walk stops when visit returns false.
+func walkObjectGraph(f *Frame, visit func(o, parent *Object) bool) {
+	roots := []*Object{SysModules.ToObject()}
+	for frame := f; frame != nil; frame = frame.back {
+		roots = append(roots, frame.ToObject())
+	}
+	seen := map[*Object]bool{}
+	var queue []*Object
+	for _, root := range roots {
+		if !seen[root] {
+			seen[root] = true
+			if !visit(root, nil) {
+				return
+			}
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		o := queue[0]
+		queue = queue[1:]
+		for _, ref := range objectReferents(o) {
+			if !seen[ref] {
+				seen[ref] = true
+				if !visit(ref, o) {
+					return
+				}
+				queue = append(queue, ref)
+			}
+		}
+	}
+}
+
+// objectReferents returns the objects directly referenced by o. References
+// are discovered by inspecting the fields of o's basis struct for pointers to
+// other basis types. Dict entries are handled specially since they're stored
+// in a table that is not itself an object.
+func objectReferents(o *Object) []*Object {
+	var refs []*Object
+	if o.isInstance(ListType) {
+		l := toListUnsafe(o)
+		l.mutex.RLock()
+		defer l.mutex.RUnlock()
+	}
+	v := reflect.NewAt(o.typ.basis, unsafe.Pointer(o)).Elem()
+	refs = appendReferents(refs, v)
+	if o.isInstance(DictType) {
+		iter := newDictEntryIterator(toDictUnsafe(o))
+		for entry := iter.next(); entry != nil; entry = iter.next() {
+			refs = append(refs, entry.key, entry.value)
+		}
+	}
+	return refs
+}
+
+func appendReferents(refs []*Object, v reflect.Value) []*Object {
+	switch v.Kind() {
+	case reflect.Struct:
+		rtype := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if rtype == objectRType && rtype.Field(i).Name == "ref" {
+				// An object's weak reference does not keep
+				// anything alive.
+				continue
+			}
+			refs = appendReferents(refs, v.Field(i))
+		}
+	case reflect.Ptr:
+		if _, ok := basisTypes[v.Type().Elem()]; ok && !v.IsNil() {
+			refs = append(refs, (*Object)(unsafe.Pointer(v.Pointer())))
+		}
+	case reflect.Array, reflect.Slice:
+		if elem := v.Type().Elem(); elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Struct {
+			for i := 0; i < v.Len(); i++ {
+				refs = appendReferents(refs, v.Index(i))
+			}
+		}
+	}
+	return refs
+}
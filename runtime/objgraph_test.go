@@ -0,0 +1,95 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestObjectHistogram(t *testing.T) {
+	f := NewRootFrame()
+	fooType := newTestClass("ObjGraphFoo", []*Type{ObjectType}, NewDict())
+	foo := newObject(fooType)
+	module := newTestModule("objgraph_test", "objgraph_test.py")
+	mustNotRaise(nil, module.Dict().SetItemString(f, "foos", newTestList(foo, newObject(fooType)).ToObject()))
+	mustNotRaise(nil, SysModules.SetItemString(f, "objgraph_test", module.ToObject()))
+	defer SysModules.DelItemString(f, "objgraph_test")
+	fun := wrapFuncForTest(func(f *Frame, name *Str) (*Object, *BaseException) {
+		d, raised := ObjectHistogram(f)
+		if raised != nil {
+			return nil, raised
+		}
+		count, raised := d.GetItem(f, name.ToObject())
+		if raised != nil || count == nil {
+			return None, raised
+		}
+		return count, nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("ObjGraphFoo"), want: NewInt(2).ToObject()},
+		{args: wrapArgs("ObjGraphBar"), want: None},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFindReferencePath(t *testing.T) {
+	f := NewRootFrame()
+	target := newObject(ObjectType)
+	l := newTestList("foo", target)
+	module := newTestModule("objgraph_test", "objgraph_test.py")
+	mustNotRaise(nil, module.Dict().SetItemString(f, "l", l.ToObject()))
+	mustNotRaise(nil, SysModules.SetItemString(f, "objgraph_test", module.ToObject()))
+	defer SysModules.DelItemString(f, "objgraph_test")
+	fun := wrapFuncForTest(func(f *Frame, o *Object) *List {
+		return FindReferencePath(f, o)
+	})
+	want := newTestList(SysModules, module, module.Dict(), l, target).ToObject()
+	cases := []invokeTestCase{
+		{args: Args{target}, want: want},
+		{args: Args{SysModules.ToObject()}, want: newTestList(SysModules).ToObject()},
+		{args: Args{newObject(ObjectType)}, want: None},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestReferents(t *testing.T) {
+	foo := NewStr("foo").ToObject()
+	bar := NewStr("bar").ToObject()
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	o := newObject(fooType)
+	fun := wrapFuncForTest(func(f *Frame, o *Object) *List {
+		return Referents(o)
+	})
+	cases := []invokeTestCase{
+		{args: Args{NewTuple(foo, bar).ToObject()}, want: newTestList(TupleType, foo, bar).ToObject()},
+		{args: Args{newTestList(foo).ToObject()}, want: newTestList(ListType, foo).ToObject()},
+		{args: Args{newTestDict(foo, bar).ToObject()}, want: newTestList(DictType, foo, bar).ToObject()},
+		{args: Args{o}, want: newTestList(fooType, o.Dict()).ToObject()},
+		{args: Args{NewInt(1).ToObject()}, want: newTestList(IntType).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
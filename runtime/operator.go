@@ -0,0 +1,355 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// OperatorFuncs contains the functions of the Python operator module that
+	// map directly onto runtime operations, keyed by name.
+	OperatorFuncs = NewDict()
+	// AttrGetterType is the object representing the Python
+	// 'operator.attrgetter' type.
+	AttrGetterType = newBasisType("attrgetter", reflect.TypeOf(AttrGetter{}), toAttrGetterUnsafe, ObjectType)
+	// ItemGetterType is the object representing the Python
+	// 'operator.itemgetter' type.
+	ItemGetterType = newBasisType("itemgetter", reflect.TypeOf(ItemGetter{}), toItemGetterUnsafe, ObjectType)
+	// MethodCallerType is the object representing the Python
+	// 'operator.methodcaller' type.
+	MethodCallerType = newBasisType("methodcaller", reflect.TypeOf(MethodCaller{}), toMethodCallerUnsafe, ObjectType)
+)
+
+func operatorBinaryFunc(name string, fn binaryOpFunc) *Object {
+	return newBuiltinFunction(name, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkFunctionArgs(f, name, args, ObjectType, ObjectType); raised != nil {
+			return nil, raised
+		}
+		return fn(f, args[0], args[1])
+	}).ToObject()
+}
+
+func operatorUnaryFunc(name string, fn func(*Frame, *Object) (*Object, *BaseException)) *Object {
+	return newBuiltinFunction(name, func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		if raised := checkFunctionArgs(f, name, args, ObjectType); raised != nil {
+			return nil, raised
+		}
+		return fn(f, args[0])
+	}).ToObject()
+}
+
+func operatorContains(f *Frame, seq, value *Object) (*Object, *BaseException) {
+	contains, raised := Contains(f, seq, value)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(contains).ToObject(), nil
+}
+
+func operatorDelItem(f *Frame, o, key *Object) (*Object, *BaseException) {
+	if raised := DelItem(f, o, key); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func operatorIndex(f *Frame, o *Object) (*Object, *BaseException) {
+	index, raised := Index(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	if index == nil {
+		format := "'%s' object cannot be interpreted as an index"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, o.typ.Name()))
+	}
+	return index, nil
+}
+
+func operatorIs(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return GetBool(v == w).ToObject(), nil
+}
+
+func operatorIsNot(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return GetBool(v != w).ToObject(), nil
+}
+
+func operatorNot(f *Frame, o *Object) (*Object, *BaseException) {
+	b, raised := IsTrue(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(!b).ToObject(), nil
+}
+
+func operatorSetItem(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionArgs(f, "setitem", args, ObjectType, ObjectType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	if raised := SetItem(f, args[0], args[1], args[2]); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func operatorTruth(f *Frame, o *Object) (*Object, *BaseException) {
+	b, raised := IsTrue(f, o)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(b).ToObject(), nil
+}
+
+// operatorCheckCallArgs raises TypeError unless a getter or caller object
+// named name was called with exactly one positional argument.
+func operatorCheckCallArgs(f *Frame, name string, args Args, kwargs KWArgs) *BaseException {
+	if len(kwargs) > 0 {
+		return f.RaiseType(TypeErrorType, fmt.Sprintf("%s does not take keyword arguments", name))
+	}
+	if len(args) != 1 {
+		return f.RaiseType(TypeErrorType, fmt.Sprintf("%s expected 1 arguments, got %d", name, len(args)))
+	}
+	return nil
+}
+
+// AttrGetter represents Python 'operator.attrgetter' objects. Each element of
+// attrs is the dotted path of one attribute to fetch.
+type AttrGetter struct {
+	Object
+	attrs [][]*Str
+}
+
+func toAttrGetterUnsafe(o *Object) *AttrGetter {
+	return (*AttrGetter)(o.toPointer())
+}
+
+// ToObject upcasts a to an Object.
+func (a *AttrGetter) ToObject() *Object {
+	return &a.Object
+}
+
+func attrGetterCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := operatorCheckCallArgs(f, "attrgetter", args, kwargs); raised != nil {
+		return nil, raised
+	}
+	attrs := toAttrGetterUnsafe(callable).attrs
+	results := make([]*Object, len(attrs))
+	for i, path := range attrs {
+		o := args[0]
+		for _, name := range path {
+			var raised *BaseException
+			if o, raised = GetAttr(f, o, name, nil); raised != nil {
+				return nil, raised
+			}
+		}
+		results[i] = o
+	}
+	if len(results) == 1 {
+		return results[0], nil
+	}
+	return NewTuple(results...).ToObject(), nil
+}
+
+func attrGetterNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(kwargs) > 0 {
+		return nil, f.RaiseType(TypeErrorType, "attrgetter() does not take keyword arguments")
+	}
+	if len(args) == 0 {
+		return nil, f.RaiseType(TypeErrorType, "attrgetter expected 1 arguments, got 0")
+	}
+	attrs := make([][]*Str, len(args))
+	for i, arg := range args {
+		if !arg.isInstance(StrType) {
+			return nil, f.RaiseType(TypeErrorType, "attribute name must be a string")
+		}
+		names := strings.Split(toStrUnsafe(arg).Value(), ".")
+		path := make([]*Str, len(names))
+		for j, name := range names {
+			path[j] = NewStr(name)
+		}
+		attrs[i] = path
+	}
+	return (&AttrGetter{Object: Object{typ: t}, attrs: attrs}).ToObject(), nil
+}
+
+func initAttrGetterType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("operator").ToObject()
+	AttrGetterType.flags &^= typeFlagBasetype
+	AttrGetterType.slots.Call = &callSlot{attrGetterCall}
+	AttrGetterType.slots.New = &newSlot{attrGetterNew}
+}
+
+// ItemGetter represents Python 'operator.itemgetter' objects.
+type ItemGetter struct {
+	Object
+	items []*Object
+}
+
+func toItemGetterUnsafe(o *Object) *ItemGetter {
+	return (*ItemGetter)(o.toPointer())
+}
+
+// ToObject upcasts g to an Object.
+func (g *ItemGetter) ToObject() *Object {
+	return &g.Object
+}
+
+func itemGetterCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := operatorCheckCallArgs(f, "itemgetter", args, kwargs); raised != nil {
+		return nil, raised
+	}
+	items := toItemGetterUnsafe(callable).items
+	if len(items) == 1 {
+		return GetItem(f, args[0], items[0])
+	}
+	results := make([]*Object, len(items))
+	for i, item := range items {
+		var raised *BaseException
+		if results[i], raised = GetItem(f, args[0], item); raised != nil {
+			return nil, raised
+		}
+	}
+	return NewTuple(results...).ToObject(), nil
+}
+
+func itemGetterNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(kwargs) > 0 {
+		return nil, f.RaiseType(TypeErrorType, "itemgetter() does not take keyword arguments")
+	}
+	if len(args) == 0 {
+		return nil, f.RaiseType(TypeErrorType, "itemgetter expected 1 arguments, got 0")
+	}
+	return (&ItemGetter{Object: Object{typ: t}, items: args.makeCopy()}).ToObject(), nil
+}
+
+func initItemGetterType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("operator").ToObject()
+	ItemGetterType.flags &^= typeFlagBasetype
+	ItemGetterType.slots.Call = &callSlot{itemGetterCall}
+	ItemGetterType.slots.New = &newSlot{itemGetterNew}
+}
+
+// MethodCaller represents Python 'operator.methodcaller' objects.
+type MethodCaller struct {
+	Object
+	name   *Str
+	args   Args
+	kwargs KWArgs
+}
+
+func toMethodCallerUnsafe(o *Object) *MethodCaller {
+	return (*MethodCaller)(o.toPointer())
+}
+
+// ToObject upcasts m to an Object.
+func (m *MethodCaller) ToObject() *Object {
+	return &m.Object
+}
+
+func methodCallerCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if raised := operatorCheckCallArgs(f, "methodcaller", args, kwargs); raised != nil {
+		return nil, raised
+	}
+	m := toMethodCallerUnsafe(callable)
+	method, raised := GetAttr(f, args[0], m.name, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return method.Call(f, m.args, m.kwargs)
+}
+
+func methodCallerNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) == 0 {
+		return nil, f.RaiseType(TypeErrorType, "methodcaller needs at least one argument, the method name")
+	}
+	if !args[0].isInstance(StrType) {
+		return nil, f.RaiseType(TypeErrorType, "method name must be a string")
+	}
+	m := &MethodCaller{Object: Object{typ: t}, name: toStrUnsafe(args[0])}
+	m.args = args[1:].makeCopy()
+	if len(kwargs) > 0 {
+		m.kwargs = append(KWArgs(nil), kwargs...)
+	}
+	return m.ToObject(), nil
+}
+
+func initMethodCallerType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("operator").ToObject()
+	MethodCallerType.flags &^= typeFlagBasetype
+	MethodCallerType.slots.Call = &callSlot{methodCallerCall}
+	MethodCallerType.slots.New = &newSlot{methodCallerNew}
+}
+
+func init() {
+	binaryFuncs := map[string]binaryOpFunc{
+		"add":       Add,
+		"and_":      And,
+		"contains":  operatorContains,
+		"delitem":   operatorDelItem,
+		"div":       Div,
+		"eq":        Eq,
+		"floordiv":  FloorDiv,
+		"ge":        GE,
+		"getitem":   GetItem,
+		"gt":        GT,
+		"iadd":      IAdd,
+		"iand":      IAnd,
+		"idiv":      IDiv,
+		"ifloordiv": IFloorDiv,
+		"ilshift":   ILShift,
+		"imod":      IMod,
+		"imul":      IMul,
+		"ior":       IOr,
+		"ipow":      IPow,
+		"irshift":   IRShift,
+		"is_":       operatorIs,
+		"is_not":    operatorIsNot,
+		"isub":      ISub,
+		"ixor":      IXor,
+		"le":        LE,
+		"lshift":    LShift,
+		"lt":        LT,
+		"mod":       Mod,
+		"mul":       Mul,
+		"ne":        NE,
+		"or_":       Or,
+		"pow":       Pow,
+		"rshift":    RShift,
+		"sub":       Sub,
+		"xor":       Xor,
+	}
+	unaryFuncs := map[string]func(*Frame, *Object) (*Object, *BaseException){
+		"abs":    Abs,
+		"index":  operatorIndex,
+		"inv":    Invert,
+		"invert": Invert,
+		"neg":    Neg,
+		"not_":   operatorNot,
+		"pos":    Pos,
+		"truth":  operatorTruth,
+	}
+	funcs := map[string]*Object{
+		"setitem": newBuiltinFunction("setitem", operatorSetItem).ToObject(),
+	}
+	for name, fn := range binaryFuncs {
+		funcs[name] = operatorBinaryFunc(name, fn)
+	}
+	for name, fn := range unaryFuncs {
+		funcs[name] = operatorUnaryFunc(name, fn)
+	}
+	OperatorFuncs = newStringDict(funcs)
+}
@@ -0,0 +1,141 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestOperatorFuncs(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	foo := newObject(fooType)
+	cases := []struct {
+		name string
+		invokeTestCase
+	}{
+		{"add", invokeTestCase{args: wrapArgs(1, 2), want: NewInt(3).ToObject()}},
+		{"add", invokeTestCase{args: wrapArgs(1), wantExc: mustCreateException(TypeErrorType, "'add' requires 2 arguments")}},
+		{"contains", invokeTestCase{args: wrapArgs(newTestList(1, 2), 2), want: True.ToObject()}},
+		{"div", invokeTestCase{args: wrapArgs(7, 2), want: NewInt(3).ToObject()}},
+		{"eq", invokeTestCase{args: wrapArgs("a", "a"), want: True.ToObject()}},
+		{"getitem", invokeTestCase{args: wrapArgs("abc", 1), want: NewStr("b").ToObject()}},
+		{"iadd", invokeTestCase{args: wrapArgs(newTestList(1), newTestList(2)), want: newTestList(1, 2).ToObject()}},
+		{"index", invokeTestCase{args: wrapArgs(3), want: NewInt(3).ToObject()}},
+		{"index", invokeTestCase{args: wrapArgs("a"), wantExc: mustCreateException(TypeErrorType, "'str' object cannot be interpreted as an index")}},
+		{"inv", invokeTestCase{args: wrapArgs(5), want: NewInt(-6).ToObject()}},
+		{"is_", invokeTestCase{args: wrapArgs(foo, foo), want: True.ToObject()}},
+		{"is_not", invokeTestCase{args: wrapArgs(foo, None), want: True.ToObject()}},
+		{"lt", invokeTestCase{args: wrapArgs(1, 2), want: True.ToObject()}},
+		{"neg", invokeTestCase{args: wrapArgs(3), want: NewInt(-3).ToObject()}},
+		{"not_", invokeTestCase{args: wrapArgs(NewList()), want: True.ToObject()}},
+		{"pow", invokeTestCase{args: wrapArgs(2, 10), want: NewInt(1024).ToObject()}},
+		{"sub", invokeTestCase{args: wrapArgs("a", 1), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for -: 'str' and 'int'")}},
+		{"truth", invokeTestCase{args: wrapArgs("a"), want: True.ToObject()}},
+	}
+	for _, cas := range cases {
+		fun := mustNotRaise(OperatorFuncs.GetItemString(NewRootFrame(), cas.name))
+		if err := runInvokeTestCase(fun, &cas.invokeTestCase); err != "" {
+			t.Errorf("%s: %s", cas.name, err)
+		}
+	}
+}
+
+func TestOperatorItemMutation(t *testing.T) {
+	f := NewRootFrame()
+	setItem := mustNotRaise(OperatorFuncs.GetItemString(f, "setitem"))
+	delItem := mustNotRaise(OperatorFuncs.GetItemString(f, "delitem"))
+	d := NewDict()
+	mustNotRaise(setItem.Call(f, wrapArgs(d, "foo", 1), nil))
+	if got := mustNotRaise(d.GetItemString(f, "foo")); got == nil || !got.isInstance(IntType) || toIntUnsafe(got).Value() != 1 {
+		t.Errorf("setitem(d, 'foo', 1) stored %v, want 1", got)
+	}
+	mustNotRaise(delItem.Call(f, wrapArgs(d, "foo"), nil))
+	if d.Len() != 0 {
+		t.Errorf("delitem(d, 'foo') left %v, want {}", d)
+	}
+}
+
+func TestAttrGetter(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	foo := newObject(fooType)
+	bar := newObject(fooType)
+	mustNotRaise(nil, SetAttr(NewRootFrame(), foo, NewStr("bar"), bar))
+	mustNotRaise(nil, SetAttr(NewRootFrame(), bar, NewStr("baz"), NewInt(123).ToObject()))
+	fun := wrapFuncForTest(func(f *Frame, attrs *Tuple, o *Object) (*Object, *BaseException) {
+		getter, raised := AttrGetterType.Call(f, attrs.elems, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return getter.Call(f, Args{o}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTuple("bar"), foo), want: bar},
+		{args: wrapArgs(newTestTuple("bar.baz"), foo), want: NewInt(123).ToObject()},
+		{args: wrapArgs(newTestTuple("bar", "bar.baz"), foo), want: newTestTuple(bar, 123).ToObject()},
+		{args: wrapArgs(newTestTuple("bar.qux"), foo), wantExc: mustCreateException(AttributeErrorType, "'Foo' object has no attribute 'qux'")},
+		{args: wrapArgs(NewTuple(), foo), wantExc: mustCreateException(TypeErrorType, "attrgetter expected 1 arguments, got 0")},
+		{args: wrapArgs(newTestTuple(123), foo), wantExc: mustCreateException(TypeErrorType, "attribute name must be a string")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestItemGetter(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, items *Tuple, args ...*Object) (*Object, *BaseException) {
+		getter, raised := ItemGetterType.Call(f, items.elems, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return getter.Call(f, args, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTuple(1), "abc"), want: NewStr("b").ToObject()},
+		{args: wrapArgs(newTestTuple(2, 0), "abc"), want: newTestTuple("c", "a").ToObject()},
+		{args: wrapArgs(newTestTuple("foo"), newTestDict("foo", 1)), want: NewInt(1).ToObject()},
+		{args: wrapArgs(newTestTuple(5), "abc"), wantExc: mustCreateException(IndexErrorType, "index out of range")},
+		{args: wrapArgs(newTestTuple(1), "abc", "def"), wantExc: mustCreateException(TypeErrorType, "itemgetter expected 1 arguments, got 2")},
+		{args: wrapArgs(NewTuple(), "abc"), wantExc: mustCreateException(TypeErrorType, "itemgetter expected 1 arguments, got 0")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestMethodCaller(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, args *Tuple, kwargs *Dict, o *Object) (*Object, *BaseException) {
+		caller, raised := Invoke(f, MethodCallerType.ToObject(), args.elems, nil, nil, kwargs.ToObject())
+		if raised != nil {
+			return nil, raised
+		}
+		return caller.Call(f, Args{o}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestTuple("upper"), NewDict(), "abc"), want: NewStr("ABC").ToObject()},
+		{args: wrapArgs(newTestTuple("split", ",", 1), NewDict(), "a,b,c"), want: newTestList("a", "b,c").ToObject()},
+		{args: wrapArgs(newTestTuple("get"), newTestDict("key", "foo"), NewDict()), wantExc: mustCreateException(TypeErrorType, "'get' of 'dict' requires 3 arguments")},
+		{args: wrapArgs(NewTuple(), NewDict(), "abc"), wantExc: mustCreateException(TypeErrorType, "methodcaller needs at least one argument, the method name")},
+		{args: wrapArgs(newTestTuple(123), NewDict(), "abc"), wantExc: mustCreateException(TypeErrorType, "method name must be a string")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,623 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// OrderedDictType is the object representing the Python
+	// 'collections.OrderedDict' type.
+	OrderedDictType              = newBasisType("OrderedDict", reflect.TypeOf(OrderedDict{}), toOrderedDictUnsafe, DictType)
+	orderedDictIteratorType      = newBasisType("odict_iterator", reflect.TypeOf(orderedDictIterator{}), toOrderedDictIteratorUnsafe, ObjectType)
+	orderedDictFromKeysParamSpec = NewParamSpec("fromkeys", []Param{{"cls", nil}, {"iterable", nil}, {"value", None}}, false, false)
+	orderedDictPopItemParamSpec  = NewParamSpec("popitem", []Param{{"self", nil}, {"last", True.ToObject()}}, false, false)
+)
+
+// OrderedDict represents Python 'collections.OrderedDict' objects, dicts that
+// remember the order in which keys were first inserted. The entries live in
+// the embedded Dict and the order is tracked alongside it.
+type OrderedDict struct {
+	Dict
+	// keys holds the keys of the dict in insertion order. Deleted keys
+	// leave nil holes behind which are trimmed from either end right away
+	// and squeezed out of the middle once they make up half of keys.
+	keys  []*Object
+	holes int
+	// positions maps each key to its position in the order. The key at
+	// position p is found at keys[p-offset].
+	positions *Dict
+	offset    int
+	// state is incremented whenever a key is added or removed so that
+	// iterators can detect concurrent modifications.
+	state int64
+}
+
+func toOrderedDictUnsafe(o *Object) *OrderedDict {
+	return (*OrderedDict)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *OrderedDict) ToObject() *Object {
+	return &d.Object
+}
+
+// DelItem removes the entry associated with key from d. It returns true if an
+// item was removed, or false if it did not exist in d.
+func (d *OrderedDict) DelItem(f *Frame, key *Object) (bool, *BaseException) {
+	value, raised := d.Pop(f, key)
+	return value != nil, raised
+}
+
+// Keys returns a list containing all the keys in d in insertion order.
+func (d *OrderedDict) Keys(f *Frame) *List {
+	d.mutex.Lock(f)
+	keys := make([]*Object, 0, len(d.keys)-d.holes)
+	for _, key := range d.keys {
+		if key != nil {
+			keys = append(keys, key)
+		}
+	}
+	d.mutex.Unlock(f)
+	return NewList(keys...)
+}
+
+// Pop removes the entry associated with key from d, returning its value or
+// nil if key is not present in d.
+func (d *OrderedDict) Pop(f *Frame, key *Object) (*Object, *BaseException) {
+	d.mutex.Lock(f)
+	defer d.mutex.Unlock(f)
+	if d.positions == nil {
+		return nil, nil
+	}
+	pos, raised := d.positions.Pop(f, key)
+	if raised != nil || pos == nil {
+		return nil, raised
+	}
+	value, raised := d.Dict.Pop(f, key)
+	if raised != nil {
+		return nil, raised
+	}
+	d.keys[toIntUnsafe(pos).Value()-d.offset] = nil
+	d.holes++
+	d.state++
+	return value, d.trim(f)
+}
+
+// SetItem associates value with key in d. New keys are placed at the end of
+// the order while existing keys keep their position.
+func (d *OrderedDict) SetItem(f *Frame, key, value *Object) *BaseException {
+	d.mutex.Lock(f)
+	defer d.mutex.Unlock(f)
+	originValue, raised := d.putItem(f, key, value, true)
+	if raised != nil || originValue != nil {
+		return raised
+	}
+	if d.positions == nil {
+		// d was not created by OrderedDict.__new__.
+		d.positions = NewDict()
+	}
+	pos := NewInt(d.offset + len(d.keys)).ToObject()
+	if raised := d.positions.SetItem(f, key, pos); raised != nil {
+		return raised
+	}
+	d.keys = append(d.keys, key)
+	d.state++
+	return nil
+}
+
+// trim drops the holes left by deleted keys from the ends of d.keys and
+// compacts it when too many remain in the middle. It assumes that d.mutex is
+// held by the caller.
+func (d *OrderedDict) trim(f *Frame) *BaseException {
+	for len(d.keys) > 0 && d.keys[0] == nil {
+		d.keys = d.keys[1:]
+		d.offset++
+		d.holes--
+	}
+	for n := len(d.keys); n > 0 && d.keys[n-1] == nil; n-- {
+		d.keys = d.keys[:n-1]
+		d.holes--
+	}
+	if len(d.keys) == 0 {
+		d.keys, d.offset = nil, 0
+	}
+	if d.holes < 8 || d.holes < len(d.keys)/2 {
+		return nil
+	}
+	keys := make([]*Object, 0, len(d.keys)-d.holes)
+	for _, key := range d.keys {
+		if key != nil {
+			if raised := d.positions.SetItem(f, key, NewInt(len(keys)).ToObject()); raised != nil {
+				return raised
+			}
+			keys = append(keys, key)
+		}
+	}
+	d.keys, d.holes, d.offset = keys, 0, 0
+	return nil
+}
+
+// update copies the items of the mapping or sequence of 2-tuples o into the
+// OrderedDict od via its __setitem__.
+func orderedDictUpdate(f *Frame, od, o *Object) *BaseException {
+	keys := o
+	if !o.isInstance(DictType) {
+		keysMethod, raised := GetAttr(f, o, NewStr("keys"), None)
+		if raised != nil {
+			return raised
+		}
+		if keysMethod == None {
+			return seqForEach(f, o, func(item *Object) *BaseException {
+				return seqApply(f, item, func(elems []*Object, _ bool) *BaseException {
+					if numElems := len(elems); numElems != 2 {
+						format := "dictionary update sequence element has length %d; 2 is required"
+						return f.RaiseType(ValueErrorType, fmt.Sprintf(format, numElems))
+					}
+					return SetItem(f, od, elems[0], elems[1])
+				})
+			})
+		}
+		if keys, raised = keysMethod.Call(f, nil, nil); raised != nil {
+			return raised
+		}
+	}
+	return seqForEach(f, keys, func(key *Object) *BaseException {
+		value, raised := GetItem(f, o, key)
+		if raised != nil {
+			return raised
+		}
+		return SetItem(f, od, key, value)
+	})
+}
+
+func orderedDictClear(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "clear", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	d := toOrderedDictUnsafe(args[0])
+	d.mutex.Lock(f)
+	d.table = newDictTable(0)
+	d.incVersion()
+	d.keys, d.holes, d.offset = nil, 0, 0
+	d.positions = NewDict()
+	d.state++
+	d.mutex.Unlock(f)
+	return None, nil
+}
+
+func orderedDictCopy(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "copy", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return args[0].typ.Call(f, args, nil)
+}
+
+func orderedDictFromKeys(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [3]*Object
+	if raised := orderedDictFromKeysParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	cls, iterable, value := validated[0], validated[1], validated[2]
+	d, raised := cls.Call(f, nil, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	raised = seqForEach(f, iterable, func(key *Object) *BaseException {
+		return SetItem(f, d, key, value)
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	return d, nil
+}
+
+func orderedDictItems(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "items", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return ListType.Call(f, Args{newOrderedDictIterator(f, toOrderedDictUnsafe(args[0]), orderedDictItemsKind, false)}, nil)
+}
+
+func orderedDictIterItems(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "iteritems", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return newOrderedDictIterator(f, toOrderedDictUnsafe(args[0]), orderedDictItemsKind, false), nil
+}
+
+func orderedDictIterKeys(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "iterkeys", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return newOrderedDictIterator(f, toOrderedDictUnsafe(args[0]), orderedDictKeysKind, false), nil
+}
+
+func orderedDictIterValues(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "itervalues", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return newOrderedDictIterator(f, toOrderedDictUnsafe(args[0]), orderedDictValuesKind, false), nil
+}
+
+func orderedDictKeys(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "keys", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return toOrderedDictUnsafe(args[0]).Keys(f).ToObject(), nil
+}
+
+func orderedDictPop(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{OrderedDictType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkMethodArgs(f, "pop", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	key := args[1]
+	item, raised := toOrderedDictUnsafe(args[0]).Pop(f, key)
+	if raised == nil && item == nil {
+		if argc > 2 {
+			item = args[2]
+		} else {
+			raised = raiseKeyError(f, key)
+		}
+	}
+	return item, raised
+}
+
+func orderedDictPopItem(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	var validated [2]*Object
+	if raised := orderedDictPopItemParamSpec.Validate(f, validated[:], args, kwargs); raised != nil {
+		return nil, raised
+	}
+	if raised := checkMethodArgs(f, "popitem", validated[:1], OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	last, raised := IsTrue(f, validated[1])
+	if raised != nil {
+		return nil, raised
+	}
+	d := toOrderedDictUnsafe(validated[0])
+	d.mutex.Lock(f)
+	defer d.mutex.Unlock(f)
+	// The holes at either end of d.keys are always trimmed.
+	if len(d.keys) == 0 {
+		return nil, f.RaiseType(KeyErrorType, "dictionary is empty")
+	}
+	key := d.keys[0]
+	if last {
+		key = d.keys[len(d.keys)-1]
+	}
+	value, raised := d.Pop(f, key)
+	if raised != nil {
+		return nil, raised
+	}
+	return NewTuple2(key, value).ToObject(), nil
+}
+
+func orderedDictReduce(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reduce__", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	d := toOrderedDictUnsafe(args[0])
+	items, raised := ListType.Call(f, Args{newOrderedDictIterator(f, d, orderedDictItemsKind, false)}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	// Items are pickled as lists to match CPython.
+	l := toListUnsafe(items)
+	for i, item := range l.elems {
+		l.elems[i] = NewList(toTupleUnsafe(item).elems...).ToObject()
+	}
+	initArgs := NewTuple1(items).ToObject()
+	if dict := d.ToObject().Dict(); dict != nil && dict.Len() > 0 {
+		return NewTuple3(d.typ.ToObject(), initArgs, dict.ToObject()).ToObject(), nil
+	}
+	return NewTuple2(d.typ.ToObject(), initArgs).ToObject(), nil
+}
+
+func orderedDictReversed(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "__reversed__", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return newOrderedDictIterator(f, toOrderedDictUnsafe(args[0]), orderedDictKeysKind, true), nil
+}
+
+func orderedDictSetDefault(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{OrderedDictType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkMethodArgs(f, "setdefault", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	d, key, value := toOrderedDictUnsafe(args[0]), args[1], None
+	if argc > 2 {
+		value = args[2]
+	}
+	d.mutex.Lock(f)
+	defer d.mutex.Unlock(f)
+	originValue, raised := d.GetItem(f, key)
+	if raised != nil {
+		return nil, raised
+	}
+	if originValue != nil {
+		return originValue, nil
+	}
+	if raised := d.SetItem(f, key, value); raised != nil {
+		return nil, raised
+	}
+	return value, nil
+}
+
+func orderedDictUpdateMethod(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) == 0 {
+		return nil, f.RaiseType(TypeErrorType, "descriptor 'update' of 'OrderedDict' object needs an argument")
+	}
+	if len(args) > 2 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("update expected at most 1 arguments, got %d", len(args)-1))
+	}
+	if raised := orderedDictUpdateArgs(f, args[0], args[1:], kwargs); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func orderedDictUpdateArgs(f *Frame, od *Object, args Args, kwargs KWArgs) *BaseException {
+	if len(args) > 0 {
+		if raised := orderedDictUpdate(f, od, args[0]); raised != nil {
+			return raised
+		}
+	}
+	for _, kwarg := range kwargs {
+		if raised := SetItem(f, od, NewStr(kwarg.Name).ToObject(), kwarg.Value); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+func orderedDictValues(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "values", args, OrderedDictType); raised != nil {
+		return nil, raised
+	}
+	return ListType.Call(f, Args{newOrderedDictIterator(f, toOrderedDictUnsafe(args[0]), orderedDictValuesKind, false)}, nil)
+}
+
+func orderedDictDelItem(f *Frame, o, key *Object) *BaseException {
+	deleted, raised := toOrderedDictUnsafe(o).DelItem(f, key)
+	if raised != nil {
+		return raised
+	}
+	if !deleted {
+		return raiseKeyError(f, key)
+	}
+	return nil
+}
+
+func orderedDictEq(f *Frame, v, w *Object) (*Object, *BaseException) {
+	if !w.isInstance(OrderedDictType) {
+		return dictEq(f, v, w)
+	}
+	eq, raised := orderedDictsAreEqual(f, toOrderedDictUnsafe(v), toOrderedDictUnsafe(w))
+	if raised != nil {
+		return nil, raised
+	}
+	return GetBool(eq).ToObject(), nil
+}
+
+// orderedDictsAreEqual returns true if d1 and d2 have equal items in the same
+// order.
+func orderedDictsAreEqual(f *Frame, d1, d2 *OrderedDict) (bool, *BaseException) {
+	eq, raised := dictsAreEqual(f, &d1.Dict, &d2.Dict)
+	if raised != nil || !eq {
+		return false, raised
+	}
+	keys1, keys2 := d1.Keys(f), d2.Keys(f)
+	if len(keys1.elems) != len(keys2.elems) {
+		return false, nil
+	}
+	for i, key := range keys1.elems {
+		o, raised := Eq(f, key, keys2.elems[i])
+		if raised != nil {
+			return false, raised
+		}
+		if eq, raised = IsTrue(f, o); raised != nil || !eq {
+			return false, raised
+		}
+	}
+	return true, nil
+}
+
+func orderedDictInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(args) > 1 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("expected at most 1 arguments, got %d", len(args)))
+	}
+	if raised := orderedDictUpdateArgs(f, o, args, kwargs); raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func orderedDictIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return newOrderedDictIterator(f, toOrderedDictUnsafe(o), orderedDictKeysKind, false), nil
+}
+
+func orderedDictNE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	eq, raised := orderedDictEq(f, v, w)
+	if raised != nil || eq == NotImplemented {
+		return eq, raised
+	}
+	return GetBool(eq == False.ToObject()).ToObject(), nil
+}
+
+func orderedDictNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	o, raised := dictNew(f, t, args, kwargs)
+	if raised != nil {
+		return nil, raised
+	}
+	toOrderedDictUnsafe(o).positions = NewDict()
+	return o, nil
+}
+
+func orderedDictRepr(f *Frame, o *Object) (*Object, *BaseException) {
+	if f.reprEnter(o) {
+		return NewStr("...").ToObject(), nil
+	}
+	defer f.reprLeave(o)
+	d := toOrderedDictUnsafe(o)
+	if d.Len() == 0 {
+		return NewStr(fmt.Sprintf("%s()", d.typ.Name())).ToObject(), nil
+	}
+	items, raised := ListType.Call(f, Args{newOrderedDictIterator(f, d, orderedDictItemsKind, false)}, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	s, raised := Repr(f, items)
+	if raised != nil {
+		return nil, raised
+	}
+	var buf bytes.Buffer
+	buf.WriteString(d.typ.Name())
+	buf.WriteString("(")
+	buf.WriteString(s.Value())
+	buf.WriteString(")")
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+func orderedDictSetItem(f *Frame, o, key, value *Object) *BaseException {
+	return toOrderedDictUnsafe(o).SetItem(f, key, value)
+}
+
+func initOrderedDictType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("collections").ToObject()
+	dict["__reduce__"] = newBuiltinFunction("__reduce__", orderedDictReduce).ToObject()
+	dict["__reversed__"] = newBuiltinFunction("__reversed__", orderedDictReversed).ToObject()
+	dict["clear"] = newBuiltinFunction("clear", orderedDictClear).ToObject()
+	dict["copy"] = newBuiltinFunction("copy", orderedDictCopy).ToObject()
+	dict["fromkeys"] = newClassMethod(newBuiltinFunction("fromkeys", orderedDictFromKeys).ToObject()).ToObject()
+	dict["items"] = newBuiltinFunction("items", orderedDictItems).ToObject()
+	dict["iteritems"] = newBuiltinFunction("iteritems", orderedDictIterItems).ToObject()
+	dict["iterkeys"] = newBuiltinFunction("iterkeys", orderedDictIterKeys).ToObject()
+	dict["itervalues"] = newBuiltinFunction("itervalues", orderedDictIterValues).ToObject()
+	dict["keys"] = newBuiltinFunction("keys", orderedDictKeys).ToObject()
+	dict["pop"] = newBuiltinFunction("pop", orderedDictPop).ToObject()
+	dict["popitem"] = newBuiltinFunction("popitem", orderedDictPopItem).ToObject()
+	dict["setdefault"] = newBuiltinFunction("setdefault", orderedDictSetDefault).ToObject()
+	dict["update"] = newBuiltinFunction("update", orderedDictUpdateMethod).ToObject()
+	dict["values"] = newBuiltinFunction("values", orderedDictValues).ToObject()
+	OrderedDictType.slots.DelItem = &delItemSlot{orderedDictDelItem}
+	OrderedDictType.slots.Eq = &binaryOpSlot{orderedDictEq}
+	OrderedDictType.slots.Init = &initSlot{orderedDictInit}
+	OrderedDictType.slots.Iter = &unaryOpSlot{orderedDictIter}
+	OrderedDictType.slots.NE = &binaryOpSlot{orderedDictNE}
+	OrderedDictType.slots.New = &newSlot{orderedDictNew}
+	OrderedDictType.slots.Repr = &unaryOpSlot{orderedDictRepr}
+	OrderedDictType.slots.SetItem = &setItemSlot{orderedDictSetItem}
+}
+
+type orderedDictIteratorKind int
+
+const (
+	orderedDictKeysKind orderedDictIteratorKind = iota
+	orderedDictValuesKind
+	orderedDictItemsKind
+)
+
+// orderedDictIterator yields the keys, values or items of an OrderedDict in
+// insertion order or its reverse. It raises RuntimeError if keys are added
+// or removed during iteration.
+type orderedDictIterator struct {
+	Object
+	mutex   sync.Mutex
+	dict    *OrderedDict
+	kind    orderedDictIteratorKind
+	reverse bool
+	// next is the position in dict.keys of the next key to yield.
+	next  int
+	state int64
+}
+
+func newOrderedDictIterator(f *Frame, d *OrderedDict, kind orderedDictIteratorKind, reverse bool) *Object {
+	d.mutex.Lock(f)
+	iter := &orderedDictIterator{Object: Object{typ: orderedDictIteratorType}, dict: d, kind: kind, reverse: reverse, state: d.state}
+	iter.next = d.offset
+	if reverse {
+		iter.next = d.offset + len(d.keys) - 1
+	}
+	d.mutex.Unlock(f)
+	return &iter.Object
+}
+
+func toOrderedDictIteratorUnsafe(o *Object) *orderedDictIterator {
+	return (*orderedDictIterator)(o.toPointer())
+}
+
+func orderedDictIteratorIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func orderedDictIteratorNext(f *Frame, o *Object) (*Object, *BaseException) {
+	iter := toOrderedDictIteratorUnsafe(o)
+	iter.mutex.Lock()
+	defer iter.mutex.Unlock()
+	d := iter.dict
+	d.mutex.Lock(f)
+	defer d.mutex.Unlock(f)
+	if d.state != iter.state {
+		return nil, f.RaiseType(RuntimeErrorType, "OrderedDict mutated during iteration")
+	}
+	var key *Object
+	for key == nil {
+		i := iter.next - d.offset
+		if i < 0 || i >= len(d.keys) {
+			return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+		}
+		key = d.keys[i]
+		if iter.reverse {
+			iter.next--
+		} else {
+			iter.next++
+		}
+	}
+	if iter.kind == orderedDictKeysKind {
+		return key, nil
+	}
+	value, raised := d.GetItem(f, key)
+	if raised != nil {
+		return nil, raised
+	}
+	if value == nil {
+		return nil, f.RaiseType(RuntimeErrorType, "OrderedDict mutated during iteration")
+	}
+	if iter.kind == orderedDictValuesKind {
+		return value, nil
+	}
+	return NewTuple2(key, value).ToObject(), nil
+}
+
+func initOrderedDictIteratorType(map[string]*Object) {
+	orderedDictIteratorType.flags &= ^(typeFlagBasetype | typeFlagInstantiable)
+	orderedDictIteratorType.slots.Iter = &unaryOpSlot{orderedDictIteratorIter}
+	orderedDictIteratorType.slots.Next = &unaryOpSlot{orderedDictIteratorNext}
+}
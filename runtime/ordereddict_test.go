@@ -0,0 +1,147 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestOrderedDictKeys(t *testing.T) {
+	f := NewRootFrame()
+	d := toOrderedDictUnsafe(mustNotRaise(OrderedDictType.Call(f, nil, nil)))
+	// Enough deletions to exercise trimming at both ends and compaction of
+	// the holes in the middle.
+	for i := 0; i < 50; i++ {
+		if raised := d.SetItem(f, NewInt(i).ToObject(), None); raised != nil {
+			t.Fatal(raised)
+		}
+	}
+	var want []*Object
+	for i := 0; i < 50; i++ {
+		if i%3 == 0 || i < 5 || i > 45 {
+			if _, raised := d.DelItem(f, NewInt(i).ToObject()); raised != nil {
+				t.Fatal(raised)
+			}
+		} else {
+			want = append(want, NewInt(i).ToObject())
+		}
+	}
+	// Re-inserting an existing key keeps its position while a deleted key
+	// moves to the end.
+	if raised := d.SetItem(f, NewInt(7).ToObject(), True.ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
+	if raised := d.SetItem(f, NewInt(0).ToObject(), None); raised != nil {
+		t.Fatal(raised)
+	}
+	want = append(want, NewInt(0).ToObject())
+	got := d.Keys(f).ToObject()
+	if w := NewList(want...).ToObject(); mustNotRaise(Eq(f, got, w)) != True.ToObject() {
+		t.Errorf("d.keys() = %v, want %v", got, w)
+	}
+}
+
+func TestOrderedDictMethods(t *testing.T) {
+	// fun calls the named method of an OrderedDict built from items and
+	// returns the method's result along with the dict's items afterward.
+	fun := wrapFuncForTest(func(f *Frame, items *Object, name string, args ...*Object) (*Object, *BaseException) {
+		d, raised := OrderedDictType.Call(f, Args{items}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		method, raised := GetAttr(f, d, NewStr(name), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		result, raised := method.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		itemsMethod, raised := GetAttr(f, d, NewStr("items"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		l, raised := itemsMethod.Call(f, nil, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(result, l).ToObject(), nil
+	})
+	items := newTestList(newTestTuple("b", 1), newTestTuple("a", 2), newTestTuple("c", 3))
+	cases := []invokeTestCase{
+		{args: wrapArgs(items, "keys"), want: newTestTuple(newTestList("b", "a", "c"), items).ToObject()},
+		{args: wrapArgs(items, "values"), want: newTestTuple(newTestList(1, 2, 3), items).ToObject()},
+		{args: wrapArgs(items, "pop", "a"), want: newTestTuple(2, newTestList(newTestTuple("b", 1), newTestTuple("c", 3))).ToObject()},
+		{args: wrapArgs(items, "pop", "z", None), want: newTestTuple(None, items).ToObject()},
+		{args: wrapArgs(items, "pop", "z"), wantExc: mustCreateException(KeyErrorType, "z")},
+		{args: wrapArgs(items, "popitem"), want: newTestTuple(newTestTuple("c", 3), newTestList(newTestTuple("b", 1), newTestTuple("a", 2))).ToObject()},
+		{args: wrapArgs(items, "popitem", false), want: newTestTuple(newTestTuple("b", 1), newTestList(newTestTuple("a", 2), newTestTuple("c", 3))).ToObject()},
+		{args: wrapArgs(NewList(), "popitem"), wantExc: mustCreateException(KeyErrorType, "dictionary is empty")},
+		{args: wrapArgs(items, "setdefault", "a", 5), want: newTestTuple(2, items).ToObject()},
+		{args: wrapArgs(items, "setdefault", "d", 4), want: newTestTuple(4, newTestList(newTestTuple("b", 1), newTestTuple("a", 2), newTestTuple("c", 3), newTestTuple("d", 4))).ToObject()},
+		{args: wrapArgs(items, "update", newTestList(newTestTuple("a", 5), newTestTuple("e", 6))), want: newTestTuple(None, newTestList(newTestTuple("b", 1), newTestTuple("a", 5), newTestTuple("c", 3), newTestTuple("e", 6))).ToObject()},
+		{args: wrapArgs(items, "clear"), want: newTestTuple(None, NewList()).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestOrderedDictEq(t *testing.T) {
+	f := NewRootFrame()
+	newOrderedDict := func(items ...interface{}) *Object {
+		return mustNotRaise(OrderedDictType.Call(f, Args{newTestList(items...).ToObject()}, nil))
+	}
+	ab := newOrderedDict(newTestTuple("a", 1), newTestTuple("b", 2))
+	ba := newOrderedDict(newTestTuple("b", 2), newTestTuple("a", 1))
+	cases := []invokeTestCase{
+		{args: wrapArgs(ab, newOrderedDict(newTestTuple("a", 1), newTestTuple("b", 2))), want: True.ToObject()},
+		{args: wrapArgs(ab, ba), want: False.ToObject()},
+		{args: wrapArgs(ab, newTestDict("b", 2, "a", 1)), want: True.ToObject()},
+		{args: wrapArgs(ab, NewList()), want: False.ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Eq), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestOrderedDictIteratorMutation(t *testing.T) {
+	f := NewRootFrame()
+	d := mustNotRaise(OrderedDictType.Call(f, Args{newTestDict("a", 1).ToObject()}, nil))
+	iter := mustNotRaise(Iter(f, d))
+	if raised := SetItem(f, d, NewStr("b").ToObject(), None); raised != nil {
+		t.Fatal(raised)
+	}
+	if _, raised := Next(f, iter); raised == nil || !raised.isInstance(RuntimeErrorType) {
+		t.Errorf("next(iter) after insert raised %v, want RuntimeError", raised)
+	}
+}
+
+func TestOrderedDictRepr(t *testing.T) {
+	f := NewRootFrame()
+	cases := []invokeTestCase{
+		{args: wrapArgs(mustNotRaise(OrderedDictType.Call(f, nil, nil))), want: NewStr("OrderedDict()").ToObject()},
+		{args: wrapArgs(mustNotRaise(OrderedDictType.Call(f, wrapArgs(newTestList(newTestTuple("b", 1), newTestTuple("a", 2))), nil))), want: NewStr("OrderedDict([('b', 1), ('a', 2)])").ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Repr), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,1446 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// pickleHighestProtocol is the highest pickle protocol supported.
+	pickleHighestProtocol = 2
+	// pickleBatchSize is the maximum number of list or dict items written
+	// per APPENDS or SETITEMS opcode.
+	pickleBatchSize = 1000
+)
+
+// Pickle opcodes. See pickletools.py in the CPython standard library for
+// documentation of each opcode.
+const (
+	pickleMark           = '('
+	pickleStop           = '.'
+	picklePop            = '0'
+	picklePopMark        = '1'
+	pickleDup            = '2'
+	pickleFloat          = 'F'
+	pickleInt            = 'I'
+	pickleBinInt         = 'J'
+	pickleBinInt1        = 'K'
+	pickleLong           = 'L'
+	pickleBinInt2        = 'M'
+	pickleNone           = 'N'
+	pickleReduce         = 'R'
+	pickleString         = 'S'
+	pickleBinString      = 'T'
+	pickleShortBinString = 'U'
+	pickleUnicode        = 'V'
+	pickleBinUnicode     = 'X'
+	pickleAppend         = 'a'
+	pickleBuild          = 'b'
+	pickleGlobal         = 'c'
+	pickleDict           = 'd'
+	pickleEmptyDict      = '}'
+	pickleAppends        = 'e'
+	pickleGet            = 'g'
+	pickleBinGet         = 'h'
+	pickleInst           = 'i'
+	pickleLongBinGet     = 'j'
+	pickleList           = 'l'
+	pickleEmptyList      = ']'
+	pickleObj            = 'o'
+	picklePut            = 'p'
+	pickleBinPut         = 'q'
+	pickleLongBinPut     = 'r'
+	pickleSetItem        = 's'
+	pickleTuple          = 't'
+	pickleEmptyTuple     = ')'
+	pickleSetItems       = 'u'
+	pickleBinFloat       = 'G'
+	pickleProto          = '\x80'
+	pickleNewObj         = '\x81'
+	pickleTuple1         = '\x85'
+	pickleTuple2         = '\x86'
+	pickleTuple3         = '\x87'
+	pickleNewTrue        = '\x88'
+	pickleNewFalse       = '\x89'
+	pickleLong1          = '\x8a'
+	pickleLong4          = '\x8b'
+)
+
+// PickleDumps returns the pickled representation of o using the given
+// protocol. A negative protocol selects the highest protocol supported.
+// Objects that can't be pickled because they can't be found by name cause
+// errorType to be raised.
+func PickleDumps(f *Frame, o *Object, protocol int, errorType *Type) (*Str, *BaseException) {
+	if protocol < 0 {
+		protocol = pickleHighestProtocol
+	} else if protocol > pickleHighestProtocol {
+		return nil, f.RaiseType(ValueErrorType, fmt.Sprintf("pickle protocol must be <= %d", pickleHighestProtocol))
+	}
+	p := &pickler{proto: protocol, memo: map[*Object]int{}, errorType: errorType}
+	if protocol >= 2 {
+		p.buf.WriteByte(pickleProto)
+		p.buf.WriteByte(byte(protocol))
+	}
+	if raised := p.save(f, o); raised != nil {
+		return nil, raised
+	}
+	p.buf.WriteByte(pickleStop)
+	return NewStr(p.buf.String()), nil
+}
+
+// PickleLoads reconstructs an object from the pickle data in s. Data after
+// the end of the pickle is ignored. Malformed pickles cause errorType to be
+// raised.
+func PickleLoads(f *Frame, s *Str, errorType *Type) (*Object, *BaseException) {
+	return unpickle(f, &pickleStrReader{data: s.Value()}, errorType)
+}
+
+// PickleLoad is like PickleLoads but reads the pickle from file, which must
+// have read and readline methods. Only the bytes making up the pickle are
+// consumed from file.
+func PickleLoad(f *Frame, file *Object, errorType *Type) (*Object, *BaseException) {
+	read, raised := GetAttr(f, file, NewStr("read"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	readline, raised := GetAttr(f, file, NewStr("readline"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	return unpickle(f, &pickleFileReader{readFunc: read, readlineFunc: readline}, errorType)
+}
+
+type pickler struct {
+	buf       bytes.Buffer
+	proto     int
+	memo      map[*Object]int
+	errorType *Type
+}
+
+func (p *pickler) save(f *Frame, o *Object) *BaseException {
+	if id, ok := p.memo[o]; ok {
+		p.get(id)
+		return nil
+	}
+	switch {
+	case o == None:
+		p.buf.WriteByte(pickleNone)
+	case o.typ == BoolType:
+		p.saveBool(toIntUnsafe(o).IsTrue())
+	case o.typ == IntType:
+		p.saveInt(toIntUnsafe(o).Value())
+	case o.typ == LongType:
+		p.saveLong(toLongUnsafe(o).Value())
+	case o.typ == FloatType:
+		p.saveFloat(toFloatUnsafe(o).Value())
+	case o.typ == StrType:
+		return p.saveStr(f, o)
+	case o.typ == UnicodeType:
+		p.saveUnicode(o)
+	case o.typ == TupleType:
+		return p.saveTuple(f, o)
+	case o.typ == ListType:
+		return p.saveList(f, o)
+	case o.typ == DictType:
+		return p.saveDict(f, o)
+	case o.isInstance(TypeType), o.typ == FunctionType:
+		return p.saveGlobal(f, o, "")
+	default:
+		return p.saveReduce(f, o)
+	}
+	return nil
+}
+
+func (p *pickler) saveBool(v bool) {
+	switch {
+	case p.proto >= 2 && v:
+		p.buf.WriteByte(pickleNewTrue)
+	case p.proto >= 2:
+		p.buf.WriteByte(pickleNewFalse)
+	case v:
+		p.buf.WriteString("I01\n")
+	default:
+		p.buf.WriteString("I00\n")
+	}
+}
+
+func (p *pickler) saveInt(v int) {
+	if p.proto >= 1 {
+		switch {
+		case v >= 0 && v <= 0xff:
+			p.buf.WriteByte(pickleBinInt1)
+			p.buf.WriteByte(byte(v))
+			return
+		case v >= 0 && v <= 0xffff:
+			p.buf.WriteByte(pickleBinInt2)
+			p.writeUint16(uint16(v))
+			return
+		case v >= math.MinInt32 && v <= math.MaxInt32:
+			p.buf.WriteByte(pickleBinInt)
+			p.writeUint32(uint32(int32(v)))
+			return
+		}
+	}
+	fmt.Fprintf(&p.buf, "%c%d\n", pickleInt, v)
+}
+
+func (p *pickler) saveLong(v *big.Int) {
+	if p.proto < 2 {
+		fmt.Fprintf(&p.buf, "%c%sL\n", pickleLong, v.String())
+		return
+	}
+	b := pickleEncodeLong(v)
+	if len(b) < 256 {
+		p.buf.WriteByte(pickleLong1)
+		p.buf.WriteByte(byte(len(b)))
+	} else {
+		p.buf.WriteByte(pickleLong4)
+		p.writeUint32(uint32(len(b)))
+	}
+	p.buf.Write(b)
+}
+
+func (p *pickler) saveFloat(v float64) {
+	if p.proto >= 1 {
+		p.buf.WriteByte(pickleBinFloat)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+		p.buf.Write(b[:])
+		return
+	}
+	fmt.Fprintf(&p.buf, "%c%s\n", pickleFloat, floatToString(v, floatReprPrecision))
+}
+
+func (p *pickler) saveStr(f *Frame, o *Object) *BaseException {
+	s := toStrUnsafe(o).Value()
+	switch {
+	case p.proto >= 1 && len(s) < 256:
+		p.buf.WriteByte(pickleShortBinString)
+		p.buf.WriteByte(byte(len(s)))
+		p.buf.WriteString(s)
+	case p.proto >= 1:
+		p.buf.WriteByte(pickleBinString)
+		p.writeUint32(uint32(len(s)))
+		p.buf.WriteString(s)
+	default:
+		r, raised := Repr(f, o)
+		if raised != nil {
+			return raised
+		}
+		p.buf.WriteByte(pickleString)
+		p.buf.WriteString(r.Value())
+		p.buf.WriteByte('\n')
+	}
+	p.memoize(o)
+	return nil
+}
+
+func (p *pickler) saveUnicode(o *Object) {
+	runes := toUnicodeUnsafe(o).Value()
+	if p.proto >= 1 {
+		s := string(runes)
+		p.buf.WriteByte(pickleBinUnicode)
+		p.writeUint32(uint32(len(s)))
+		p.buf.WriteString(s)
+	} else {
+		p.buf.WriteByte(pickleUnicode)
+		p.buf.WriteString(rawUnicodeEscape(runes))
+		p.buf.WriteByte('\n')
+	}
+	p.memoize(o)
+}
+
+func (p *pickler) saveTuple(f *Frame, o *Object) *BaseException {
+	elems := toTupleUnsafe(o).elems
+	n := len(elems)
+	if n == 0 {
+		if p.proto >= 1 {
+			p.buf.WriteByte(pickleEmptyTuple)
+		} else {
+			p.buf.WriteByte(pickleMark)
+			p.buf.WriteByte(pickleTuple)
+		}
+		return nil
+	}
+	if p.proto >= 2 && n <= 3 {
+		for _, elem := range elems {
+			if raised := p.save(f, elem); raised != nil {
+				return raised
+			}
+		}
+		if id, ok := p.memo[o]; ok {
+			// The tuple is recursive and was pickled while saving
+			// its elements so discard them and fetch it instead.
+			p.buf.WriteString(strings.Repeat(string(picklePop), n))
+			p.get(id)
+			return nil
+		}
+		p.buf.WriteByte([]byte{pickleTuple1, pickleTuple2, pickleTuple3}[n-1])
+		p.memoize(o)
+		return nil
+	}
+	p.buf.WriteByte(pickleMark)
+	for _, elem := range elems {
+		if raised := p.save(f, elem); raised != nil {
+			return raised
+		}
+	}
+	if id, ok := p.memo[o]; ok {
+		if p.proto >= 1 {
+			p.buf.WriteByte(picklePopMark)
+		} else {
+			p.buf.WriteString(strings.Repeat(string(picklePop), n+1))
+		}
+		p.get(id)
+		return nil
+	}
+	p.buf.WriteByte(pickleTuple)
+	p.memoize(o)
+	return nil
+}
+
+func (p *pickler) saveList(f *Frame, o *Object) *BaseException {
+	if p.proto >= 1 {
+		p.buf.WriteByte(pickleEmptyList)
+	} else {
+		p.buf.WriteByte(pickleMark)
+		p.buf.WriteByte(pickleList)
+	}
+	p.memoize(o)
+	l := toListUnsafe(o)
+	l.mutex.RLock()
+	elems := make([]*Object, len(l.elems))
+	copy(elems, l.elems)
+	l.mutex.RUnlock()
+	return p.batchAppends(f, elems)
+}
+
+func (p *pickler) batchAppends(f *Frame, elems []*Object) *BaseException {
+	if p.proto == 0 {
+		for _, elem := range elems {
+			if raised := p.save(f, elem); raised != nil {
+				return raised
+			}
+			p.buf.WriteByte(pickleAppend)
+		}
+		return nil
+	}
+	for len(elems) > 0 {
+		n := len(elems)
+		if n > pickleBatchSize {
+			n = pickleBatchSize
+		}
+		if n > 1 {
+			p.buf.WriteByte(pickleMark)
+		}
+		for _, elem := range elems[:n] {
+			if raised := p.save(f, elem); raised != nil {
+				return raised
+			}
+		}
+		if n > 1 {
+			p.buf.WriteByte(pickleAppends)
+		} else {
+			p.buf.WriteByte(pickleAppend)
+		}
+		elems = elems[n:]
+	}
+	return nil
+}
+
+func (p *pickler) saveDict(f *Frame, o *Object) *BaseException {
+	if p.proto >= 1 {
+		p.buf.WriteByte(pickleEmptyDict)
+	} else {
+		p.buf.WriteByte(pickleMark)
+		p.buf.WriteByte(pickleDict)
+	}
+	p.memoize(o)
+	var items []*Object
+	iter := newDictEntryIterator(toDictUnsafe(o))
+	for entry := iter.next(); entry != nil; entry = iter.next() {
+		items = append(items, entry.key, entry.value)
+	}
+	return p.batchSetItems(f, items)
+}
+
+// batchSetItems writes the SETITEM or SETITEMS opcodes for items, which
+// holds alternating keys and values.
+func (p *pickler) batchSetItems(f *Frame, items []*Object) *BaseException {
+	if p.proto == 0 {
+		for i := 0; i < len(items); i += 2 {
+			if raised := p.saveAll(f, items[i:i+2]); raised != nil {
+				return raised
+			}
+			p.buf.WriteByte(pickleSetItem)
+		}
+		return nil
+	}
+	for len(items) > 0 {
+		n := len(items)
+		if n > 2*pickleBatchSize {
+			n = 2 * pickleBatchSize
+		}
+		if n > 2 {
+			p.buf.WriteByte(pickleMark)
+		}
+		if raised := p.saveAll(f, items[:n]); raised != nil {
+			return raised
+		}
+		if n > 2 {
+			p.buf.WriteByte(pickleSetItems)
+		} else {
+			p.buf.WriteByte(pickleSetItem)
+		}
+		items = items[n:]
+	}
+	return nil
+}
+
+func (p *pickler) saveAll(f *Frame, objs []*Object) *BaseException {
+	for _, o := range objs {
+		if raised := p.save(f, o); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+// saveGlobal writes a reference to o by its module and name. If name is
+// empty then o's __name__ attribute is used.
+func (p *pickler) saveGlobal(f *Frame, o *Object, name string) *BaseException {
+	if name == "" {
+		nameAttr, raised := GetAttr(f, o, NewStr("__name__"), None)
+		if raised != nil {
+			return raised
+		}
+		if !nameAttr.isInstance(StrType) {
+			return p.raiseUnfound(f, o, "?", "?")
+		}
+		name = toStrUnsafe(nameAttr).Value()
+	}
+	var module string
+	if o == objectReconstructorFunc {
+		// The runtime's reconstructor is equivalent to the one in
+		// copy_reg, so reference that one like CPython does.
+		module = "copy_reg"
+	} else {
+		var raised *BaseException
+		if module, raised = p.whichModule(f, o, name); raised != nil {
+			return raised
+		}
+		if module == "" {
+			return p.raiseUnfound(f, o, "__main__", name)
+		}
+	}
+	fmt.Fprintf(&p.buf, "%c%s\n%s\n", pickleGlobal, module, name)
+	p.memoize(o)
+	return nil
+}
+
+// whichModule returns the name of the module having an attribute name that
+// is o. o's __module__ is tried first, followed by each imported module. An
+// empty string is returned if no such module is found.
+func (p *pickler) whichModule(f *Frame, o *Object, name string) (string, *BaseException) {
+	moduleAttr, raised := GetAttr(f, o, NewStr("__module__"), None)
+	if raised != nil {
+		return "", raised
+	}
+	if moduleAttr.isInstance(StrType) {
+		module := toStrUnsafe(moduleAttr).Value()
+		attr, raised := pickleLookupGlobal(f, module, name)
+		if raised == nil && attr == o {
+			return module, nil
+		}
+		if raised != nil {
+			if !raised.isInstance(ImportErrorType) && !raised.isInstance(AttributeErrorType) {
+				return "", raised
+			}
+			f.RestoreExc(nil, nil)
+		}
+	}
+	iter := newDictEntryIterator(SysModules)
+	for entry := iter.next(); entry != nil; entry = iter.next() {
+		if !entry.key.isInstance(StrType) || !entry.value.isInstance(ModuleType) {
+			continue
+		}
+		d := entry.value.Dict()
+		if d == nil {
+			continue
+		}
+		attr, raised := d.GetItemString(f, name)
+		if raised != nil {
+			return "", raised
+		}
+		if attr == o {
+			return toStrUnsafe(entry.key).Value(), nil
+		}
+	}
+	return "", nil
+}
+
+func (p *pickler) raiseUnfound(f *Frame, o *Object, module, name string) *BaseException {
+	r, raised := Repr(f, o)
+	if raised != nil {
+		return raised
+	}
+	format := "Can't pickle %s: it's not found as %s.%s"
+	return f.RaiseType(p.errorType, fmt.Sprintf(format, r.Value(), module, name))
+}
+
+// saveReduce pickles o using the result of its __reduce_ex__ method.
+func (p *pickler) saveReduce(f *Frame, o *Object) *BaseException {
+	reduceEx, raised := GetAttr(f, o, NewStr("__reduce_ex__"), nil)
+	if raised != nil {
+		return raised
+	}
+	rv, raised := reduceEx.Call(f, Args{NewInt(p.proto).ToObject()}, nil)
+	if raised != nil {
+		return raised
+	}
+	if rv.isInstance(StrType) {
+		return p.saveGlobal(f, o, toStrUnsafe(rv).Value())
+	}
+	if !rv.isInstance(TupleType) {
+		format := "__reduce__ must return a string or tuple, not %s"
+		return f.RaiseType(p.errorType, fmt.Sprintf(format, rv.typ.Name()))
+	}
+	elems := toTupleUnsafe(rv).elems
+	if len(elems) < 2 || len(elems) > 5 {
+		return f.RaiseType(p.errorType, "tuple returned by __reduce__ must contain 2 through 5 elements")
+	}
+	fn, args := elems[0], elems[1]
+	state, listItems, dictItems := None, None, None
+	if len(elems) > 2 {
+		state = elems[2]
+	}
+	if len(elems) > 3 {
+		listItems = elems[3]
+	}
+	if len(elems) > 4 {
+		dictItems = elems[4]
+	}
+	if fn.typ.slots.Call == nil {
+		return f.RaiseType(p.errorType, "first item of the tuple returned by __reduce__ must be callable")
+	}
+	if !args.isInstance(TupleType) {
+		return f.RaiseType(p.errorType, "second item of the tuple returned by __reduce__ must be a tuple")
+	}
+	if raised := p.saveCall(f, fn, toTupleUnsafe(args)); raised != nil {
+		return raised
+	}
+	p.memoize(o)
+	if listItems != None {
+		var items []*Object
+		raised := seqForEach(f, listItems, func(item *Object) *BaseException {
+			items = append(items, item)
+			return nil
+		})
+		if raised != nil {
+			return raised
+		}
+		if raised := p.batchAppends(f, items); raised != nil {
+			return raised
+		}
+	}
+	if dictItems != None {
+		var items []*Object
+		raised := seqForEach(f, dictItems, func(item *Object) *BaseException {
+			if !item.isInstance(TupleType) || len(toTupleUnsafe(item).elems) != 2 {
+				return f.RaiseType(p.errorType, "dict items iterator must return 2-tuples")
+			}
+			items = append(items, toTupleUnsafe(item).elems...)
+			return nil
+		})
+		if raised != nil {
+			return raised
+		}
+		if raised := p.batchSetItems(f, items); raised != nil {
+			return raised
+		}
+	}
+	if state != None {
+		if raised := p.save(f, state); raised != nil {
+			return raised
+		}
+		p.buf.WriteByte(pickleBuild)
+	}
+	return nil
+}
+
+// saveCall writes the opcodes that call fn with args when unpickled. Under
+// protocol 2, calls to cls.__new__(cls, ...) are written as NEWOBJ.
+func (p *pickler) saveCall(f *Frame, fn *Object, args *Tuple) *BaseException {
+	if p.proto >= 2 && len(args.elems) > 0 && args.elems[0].isInstance(TypeType) {
+		name, raised := GetAttr(f, fn, NewStr("__name__"), None)
+		if raised != nil {
+			return raised
+		}
+		if name.isInstance(StrType) {
+			if s := toStrUnsafe(name).Value(); s == "__new__" || s == "__newobj__" {
+				if raised := p.save(f, args.elems[0]); raised != nil {
+					return raised
+				}
+				if raised := p.save(f, NewTuple(args.elems[1:]...).ToObject()); raised != nil {
+					return raised
+				}
+				p.buf.WriteByte(pickleNewObj)
+				return nil
+			}
+		}
+	}
+	if raised := p.save(f, fn); raised != nil {
+		return raised
+	}
+	if raised := p.save(f, args.ToObject()); raised != nil {
+		return raised
+	}
+	p.buf.WriteByte(pickleReduce)
+	return nil
+}
+
+func (p *pickler) memoize(o *Object) {
+	id := len(p.memo)
+	p.memo[o] = id
+	switch {
+	case p.proto == 0:
+		fmt.Fprintf(&p.buf, "%c%d\n", picklePut, id)
+	case id < 256:
+		p.buf.WriteByte(pickleBinPut)
+		p.buf.WriteByte(byte(id))
+	default:
+		p.buf.WriteByte(pickleLongBinPut)
+		p.writeUint32(uint32(id))
+	}
+}
+
+func (p *pickler) get(id int) {
+	switch {
+	case p.proto == 0:
+		fmt.Fprintf(&p.buf, "%c%d\n", pickleGet, id)
+	case id < 256:
+		p.buf.WriteByte(pickleBinGet)
+		p.buf.WriteByte(byte(id))
+	default:
+		p.buf.WriteByte(pickleLongBinGet)
+		p.writeUint32(uint32(id))
+	}
+}
+
+func (p *pickler) writeUint16(v uint16) {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], v)
+	p.buf.Write(b[:])
+}
+
+func (p *pickler) writeUint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	p.buf.Write(b[:])
+}
+
+// pickleEncodeLong returns the little endian two's complement representation
+// of v using as few bytes as possible. Zero is represented by no bytes.
+func pickleEncodeLong(v *big.Int) []byte {
+	if v.Sign() == 0 {
+		return nil
+	}
+	x := new(big.Int).Set(v)
+	var n int
+	if x.Sign() > 0 {
+		n = x.BitLen()/8 + 1
+	} else {
+		n = new(big.Int).Not(x).BitLen()/8 + 1
+		x.Add(x, new(big.Int).Lsh(big.NewInt(1), uint(8*n)))
+	}
+	b := x.Bytes()
+	result := make([]byte, n)
+	for i := range b {
+		result[i] = b[len(b)-1-i]
+	}
+	return result
+}
+
+// pickleDecodeLong is the inverse of pickleEncodeLong.
+func pickleDecodeLong(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[i] = b[len(b)-1-i]
+	}
+	x := new(big.Int).SetBytes(be)
+	if len(b) > 0 && b[len(b)-1]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return x
+}
+
+// rawUnicodeEscape encodes runes like the raw-unicode-escape codec, except
+// that backslashes and newlines are also escaped so the result can be read
+// back with readline.
+func rawUnicodeEscape(runes []rune) string {
+	var buf bytes.Buffer
+	for _, r := range runes {
+		switch {
+		case r == '\\' || r == '\n':
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		case r < 0x100:
+			buf.WriteByte(byte(r))
+		case r < 0x10000:
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		default:
+			fmt.Fprintf(&buf, "\\U%08x", r)
+		}
+	}
+	return buf.String()
+}
+
+// rawUnicodeUnescape decodes s, which was encoded with raw-unicode-escape.
+func rawUnicodeUnescape(s string) ([]rune, bool) {
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			runes = append(runes, rune(c))
+			continue
+		}
+		// Only an odd number of backslashes followed by u or U starts an
+		// escape.
+		j := i
+		for j < len(s) && s[j] == '\\' {
+			j++
+		}
+		numSlashes := j - i
+		for k := 0; k < numSlashes-1; k++ {
+			runes = append(runes, '\\')
+		}
+		i = j - 1
+		if numSlashes%2 == 0 || j == len(s) || (s[j] != 'u' && s[j] != 'U') {
+			if numSlashes%2 != 0 {
+				runes = append(runes, '\\')
+			}
+			continue
+		}
+		width := 4
+		if s[j] == 'U' {
+			width = 8
+		}
+		if j+1+width > len(s) {
+			return nil, false
+		}
+		v, err := strconv.ParseUint(s[j+1:j+1+width], 16, 32)
+		if err != nil || v > unicode.MaxRune {
+			return nil, false
+		}
+		runes = append(runes, rune(v))
+		i = j + width
+	}
+	return runes, true
+}
+
+// pickleUnquote decodes s, the repr of a str.
+func pickleUnquote(s string) (string, bool) {
+	if len(s) < 2 || (s[0] != '\'' && s[0] != '"') || s[len(s)-1] != s[0] {
+		return "", false
+	}
+	s = s[1 : len(s)-1]
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = s[i]; c {
+		case '\\', '\'', '"':
+			buf.WriteByte(c)
+		case 'a':
+			buf.WriteByte('\a')
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'v':
+			buf.WriteByte('\v')
+		case 'x':
+			if i+3 > len(s) {
+				return "", false
+			}
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", false
+			}
+			buf.WriteByte(byte(v))
+			i += 2
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			v, _ := strconv.ParseUint(s[i:j], 8, 16)
+			buf.WriteByte(byte(v))
+			i = j - 1
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String(), true
+}
+
+type pickleReader interface {
+	read(f *Frame, n int) (string, *BaseException)
+	readline(f *Frame) (string, *BaseException)
+}
+
+type pickleStrReader struct {
+	data string
+	pos  int
+}
+
+func (r *pickleStrReader) read(f *Frame, n int) (string, *BaseException) {
+	if r.pos+n > len(r.data) {
+		return "", f.Raise(EOFErrorType.ToObject(), nil, nil)
+	}
+	s := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return s, nil
+}
+
+func (r *pickleStrReader) readline(f *Frame) (string, *BaseException) {
+	i := strings.IndexByte(r.data[r.pos:], '\n')
+	if i < 0 {
+		return "", f.Raise(EOFErrorType.ToObject(), nil, nil)
+	}
+	s := r.data[r.pos : r.pos+i]
+	r.pos += i + 1
+	return s, nil
+}
+
+type pickleFileReader struct {
+	readFunc, readlineFunc *Object
+}
+
+func (r *pickleFileReader) read(f *Frame, n int) (string, *BaseException) {
+	o, raised := r.readFunc.Call(f, Args{NewInt(n).ToObject()}, nil)
+	if raised != nil {
+		return "", raised
+	}
+	if !o.isInstance(StrType) {
+		return "", f.RaiseType(TypeErrorType, fmt.Sprintf("read() should return str, not %s", o.typ.Name()))
+	}
+	s := toStrUnsafe(o).Value()
+	if len(s) < n {
+		return "", f.Raise(EOFErrorType.ToObject(), nil, nil)
+	}
+	return s, nil
+}
+
+func (r *pickleFileReader) readline(f *Frame) (string, *BaseException) {
+	o, raised := r.readlineFunc.Call(f, nil, nil)
+	if raised != nil {
+		return "", raised
+	}
+	if !o.isInstance(StrType) {
+		return "", f.RaiseType(TypeErrorType, fmt.Sprintf("readline() should return str, not %s", o.typ.Name()))
+	}
+	s := toStrUnsafe(o).Value()
+	if !strings.HasSuffix(s, "\n") {
+		return "", f.Raise(EOFErrorType.ToObject(), nil, nil)
+	}
+	return s[:len(s)-1], nil
+}
+
+type unpickler struct {
+	r         pickleReader
+	stack     []*Object
+	marks     []int
+	memo      map[int]*Object
+	errorType *Type
+}
+
+func unpickle(f *Frame, r pickleReader, errorType *Type) (*Object, *BaseException) {
+	u := &unpickler{r: r, memo: map[int]*Object{}, errorType: errorType}
+	for {
+		op, raised := r.read(f, 1)
+		if raised != nil {
+			return nil, raised
+		}
+		if op[0] == pickleStop {
+			return u.pop(f)
+		}
+		if raised := u.dispatch(f, op[0]); raised != nil {
+			return nil, raised
+		}
+	}
+}
+
+func (u *unpickler) dispatch(f *Frame, op byte) *BaseException {
+	switch op {
+	case pickleMark:
+		u.marks = append(u.marks, len(u.stack))
+	case picklePop:
+		if len(u.stack) == 0 && len(u.marks) > 0 {
+			_, raised := u.popMark(f)
+			return raised
+		}
+		_, raised := u.pop(f)
+		return raised
+	case picklePopMark:
+		_, raised := u.popMark(f)
+		return raised
+	case pickleDup:
+		o, raised := u.top(f)
+		if raised != nil {
+			return raised
+		}
+		u.push(o)
+	case pickleNone:
+		u.push(None)
+	case pickleNewTrue:
+		u.push(True.ToObject())
+	case pickleNewFalse:
+		u.push(False.ToObject())
+	case pickleInt:
+		return u.loadInt(f)
+	case pickleBinInt:
+		b, raised := u.r.read(f, 4)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewInt(int(int32(binary.LittleEndian.Uint32([]byte(b))))).ToObject())
+	case pickleBinInt1:
+		b, raised := u.r.read(f, 1)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewInt(int(b[0])).ToObject())
+	case pickleBinInt2:
+		b, raised := u.r.read(f, 2)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewInt(int(binary.LittleEndian.Uint16([]byte(b)))).ToObject())
+	case pickleLong:
+		line, raised := u.r.readline(f)
+		if raised != nil {
+			return raised
+		}
+		x, ok := new(big.Int).SetString(strings.TrimSuffix(line, "L"), 10)
+		if !ok {
+			return u.raiseInvalid(f, "LONG", line)
+		}
+		u.push(NewLong(x).ToObject())
+	case pickleLong1, pickleLong4:
+		n, raised := u.readSize(f, op == pickleLong4)
+		if raised != nil {
+			return raised
+		}
+		b, raised := u.r.read(f, n)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewLong(pickleDecodeLong([]byte(b))).ToObject())
+	case pickleFloat:
+		line, raised := u.r.readline(f)
+		if raised != nil {
+			return raised
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+		if err != nil {
+			return u.raiseInvalid(f, "FLOAT", line)
+		}
+		u.push(NewFloat(x).ToObject())
+	case pickleBinFloat:
+		b, raised := u.r.read(f, 8)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewFloat(math.Float64frombits(binary.BigEndian.Uint64([]byte(b)))).ToObject())
+	case pickleString:
+		line, raised := u.r.readline(f)
+		if raised != nil {
+			return raised
+		}
+		s, ok := pickleUnquote(strings.TrimRight(line, " \t\r"))
+		if !ok {
+			return f.RaiseType(u.errorType, "insecure string pickle")
+		}
+		u.push(NewStr(s).ToObject())
+	case pickleBinString, pickleShortBinString:
+		n, raised := u.readSize(f, op == pickleBinString)
+		if raised != nil {
+			return raised
+		}
+		s, raised := u.r.read(f, n)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewStr(s).ToObject())
+	case pickleUnicode:
+		line, raised := u.r.readline(f)
+		if raised != nil {
+			return raised
+		}
+		runes, ok := rawUnicodeUnescape(line)
+		if !ok {
+			return u.raiseInvalid(f, "UNICODE", line)
+		}
+		u.push(NewUnicodeFromRunes(runes).ToObject())
+	case pickleBinUnicode:
+		n, raised := u.readSize(f, true)
+		if raised != nil {
+			return raised
+		}
+		s, raised := u.r.read(f, n)
+		if raised != nil {
+			return raised
+		}
+		if !utf8.ValidString(s) {
+			decoded, raised := NewStr(s).Decode(f, "utf8", EncodeStrict)
+			if raised != nil {
+				return raised
+			}
+			u.push(decoded.ToObject())
+		} else {
+			u.push(NewUnicode(s).ToObject())
+		}
+	case pickleEmptyTuple:
+		u.push(NewTuple().ToObject())
+	case pickleTuple:
+		elems, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewTuple(elems...).ToObject())
+	case pickleTuple1, pickleTuple2, pickleTuple3:
+		elems, raised := u.popN(f, int(op-pickleTuple1)+1)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewTuple(elems...).ToObject())
+	case pickleEmptyList:
+		u.push(NewList().ToObject())
+	case pickleList:
+		elems, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		u.push(NewList(elems...).ToObject())
+	case pickleEmptyDict:
+		u.push(NewDict().ToObject())
+	case pickleDict:
+		items, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		d := NewDict()
+		u.push(d.ToObject())
+		return u.setItems(f, d.ToObject(), items)
+	case pickleAppend:
+		elems, raised := u.popN(f, 1)
+		if raised != nil {
+			return raised
+		}
+		return u.appends(f, elems)
+	case pickleAppends:
+		elems, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		return u.appends(f, elems)
+	case pickleSetItem:
+		items, raised := u.popN(f, 2)
+		if raised != nil {
+			return raised
+		}
+		d, raised := u.top(f)
+		if raised != nil {
+			return raised
+		}
+		return u.setItems(f, d, items)
+	case pickleSetItems:
+		items, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		d, raised := u.top(f)
+		if raised != nil {
+			return raised
+		}
+		return u.setItems(f, d, items)
+	case picklePut, pickleBinPut, pickleLongBinPut:
+		id, raised := u.readMemoID(f, op, picklePut, pickleBinPut)
+		if raised != nil {
+			return raised
+		}
+		o, raised := u.top(f)
+		if raised != nil {
+			return raised
+		}
+		u.memo[id] = o
+	case pickleGet, pickleBinGet, pickleLongBinGet:
+		id, raised := u.readMemoID(f, op, pickleGet, pickleBinGet)
+		if raised != nil {
+			return raised
+		}
+		o, ok := u.memo[id]
+		if !ok {
+			return f.RaiseType(u.errorType, fmt.Sprintf("memo value not found at index %d", id))
+		}
+		u.push(o)
+	case pickleGlobal:
+		cls, raised := u.findClass(f)
+		if raised != nil {
+			return raised
+		}
+		u.push(cls)
+	case pickleInst:
+		cls, raised := u.findClass(f)
+		if raised != nil {
+			return raised
+		}
+		args, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		o, raised := cls.Call(f, args, nil)
+		if raised != nil {
+			return raised
+		}
+		u.push(o)
+	case pickleObj:
+		elems, raised := u.popMark(f)
+		if raised != nil {
+			return raised
+		}
+		if len(elems) == 0 {
+			return f.RaiseType(u.errorType, "unpickling stack underflow")
+		}
+		o, raised := elems[0].Call(f, elems[1:], nil)
+		if raised != nil {
+			return raised
+		}
+		u.push(o)
+	case pickleReduce:
+		elems, raised := u.popN(f, 2)
+		if raised != nil {
+			return raised
+		}
+		fn, args := elems[0], elems[1]
+		if !args.isInstance(TupleType) {
+			return f.RaiseType(u.errorType, "REDUCE argument must be a tuple")
+		}
+		o, raised := fn.Call(f, toTupleUnsafe(args).elems, nil)
+		if raised != nil {
+			return raised
+		}
+		u.push(o)
+	case pickleNewObj:
+		elems, raised := u.popN(f, 2)
+		if raised != nil {
+			return raised
+		}
+		cls, args := elems[0], elems[1]
+		if !cls.isInstance(TypeType) || !args.isInstance(TupleType) {
+			return f.RaiseType(u.errorType, "NEWOBJ expected a class and a tuple")
+		}
+		newMethod, raised := GetAttr(f, cls, NewStr("__new__"), nil)
+		if raised != nil {
+			return raised
+		}
+		o, raised := newMethod.Call(f, append(Args{cls}, toTupleUnsafe(args).elems...), nil)
+		if raised != nil {
+			return raised
+		}
+		u.push(o)
+	case pickleBuild:
+		state, raised := u.pop(f)
+		if raised != nil {
+			return raised
+		}
+		o, raised := u.top(f)
+		if raised != nil {
+			return raised
+		}
+		return u.build(f, o, state)
+	case pickleProto:
+		b, raised := u.r.read(f, 1)
+		if raised != nil {
+			return raised
+		}
+		if b[0] > pickleHighestProtocol {
+			return f.RaiseType(ValueErrorType, fmt.Sprintf("unsupported pickle protocol: %d", b[0]))
+		}
+	default:
+		return f.RaiseType(u.errorType, fmt.Sprintf("invalid load key, %q.", op))
+	}
+	return nil
+}
+
+func (u *unpickler) push(o *Object) {
+	u.stack = append(u.stack, o)
+}
+
+func (u *unpickler) top(f *Frame) (*Object, *BaseException) {
+	if len(u.stack) == 0 || (len(u.marks) > 0 && u.marks[len(u.marks)-1] == len(u.stack)) {
+		return nil, f.RaiseType(u.errorType, "unpickling stack underflow")
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+func (u *unpickler) pop(f *Frame) (*Object, *BaseException) {
+	o, raised := u.top(f)
+	if raised != nil {
+		return nil, raised
+	}
+	u.stack = u.stack[:len(u.stack)-1]
+	return o, nil
+}
+
+// popN pops and returns the top n objects on the stack in the order they
+// were pushed.
+func (u *unpickler) popN(f *Frame, n int) ([]*Object, *BaseException) {
+	base := 0
+	if len(u.marks) > 0 {
+		base = u.marks[len(u.marks)-1]
+	}
+	if len(u.stack)-n < base {
+		return nil, f.RaiseType(u.errorType, "unpickling stack underflow")
+	}
+	elems := make([]*Object, n)
+	copy(elems, u.stack[len(u.stack)-n:])
+	u.stack = u.stack[:len(u.stack)-n]
+	return elems, nil
+}
+
+// popMark pops and returns the objects pushed since the topmost mark.
+func (u *unpickler) popMark(f *Frame) ([]*Object, *BaseException) {
+	if len(u.marks) == 0 {
+		return nil, f.RaiseType(u.errorType, "could not find MARK")
+	}
+	i := u.marks[len(u.marks)-1]
+	u.marks = u.marks[:len(u.marks)-1]
+	elems := make([]*Object, len(u.stack)-i)
+	copy(elems, u.stack[i:])
+	u.stack = u.stack[:i]
+	return elems, nil
+}
+
+func (u *unpickler) appends(f *Frame, elems []*Object) *BaseException {
+	o, raised := u.top(f)
+	if raised != nil {
+		return raised
+	}
+	if o.typ == ListType {
+		l := toListUnsafe(o)
+		for _, elem := range elems {
+			l.Append(elem)
+		}
+		return nil
+	}
+	appendMethod, raised := GetAttr(f, o, NewStr("append"), nil)
+	if raised != nil {
+		return raised
+	}
+	for _, elem := range elems {
+		if _, raised := appendMethod.Call(f, Args{elem}, nil); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+// setItems sets the key/value pairs in items, which holds alternating keys
+// and values, on d.
+func (u *unpickler) setItems(f *Frame, d *Object, items []*Object) *BaseException {
+	if len(items)%2 != 0 {
+		return f.RaiseType(u.errorType, "odd number of items for SETITEMS")
+	}
+	for i := 0; i < len(items); i += 2 {
+		if raised := SetItem(f, d, items[i], items[i+1]); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+// build restores the state of o from state, either by calling o's
+// __setstate__ method or by updating its __dict__ and attributes.
+func (u *unpickler) build(f *Frame, o, state *Object) *BaseException {
+	setState, raised := GetAttr(f, o, NewStr("__setstate__"), None)
+	if raised != nil {
+		return raised
+	}
+	if setState != None {
+		_, raised := setState.Call(f, Args{state}, nil)
+		return raised
+	}
+	slotState := None
+	if state.isInstance(TupleType) && len(toTupleUnsafe(state).elems) == 2 {
+		state, slotState = toTupleUnsafe(state).elems[0], toTupleUnsafe(state).elems[1]
+	}
+	if state != None {
+		if !state.isInstance(DictType) {
+			return f.RaiseType(u.errorType, "state is not a dictionary")
+		}
+		d := o.Dict()
+		iter := newDictEntryIterator(toDictUnsafe(state))
+		for entry := iter.next(); entry != nil; entry = iter.next() {
+			var raised *BaseException
+			if d != nil {
+				raised = d.SetItem(f, entry.key, entry.value)
+			} else if entry.key.isInstance(StrType) {
+				raised = SetAttr(f, o, toStrUnsafe(entry.key), entry.value)
+			} else {
+				raised = f.RaiseType(TypeErrorType, "attribute name must be string")
+			}
+			if raised != nil {
+				return raised
+			}
+		}
+	}
+	if slotState != None {
+		if !slotState.isInstance(DictType) {
+			return f.RaiseType(u.errorType, "slot state is not a dictionary")
+		}
+		iter := newDictEntryIterator(toDictUnsafe(slotState))
+		for entry := iter.next(); entry != nil; entry = iter.next() {
+			if !entry.key.isInstance(StrType) {
+				return f.RaiseType(TypeErrorType, "attribute name must be string")
+			}
+			if raised := SetAttr(f, o, toStrUnsafe(entry.key), entry.value); raised != nil {
+				return raised
+			}
+		}
+	}
+	return nil
+}
+
+// findClass reads a module and name and returns the named attribute of the
+// module, importing it if necessary.
+func (u *unpickler) findClass(f *Frame) (*Object, *BaseException) {
+	module, raised := u.r.readline(f)
+	if raised != nil {
+		return nil, raised
+	}
+	name, raised := u.r.readline(f)
+	if raised != nil {
+		return nil, raised
+	}
+	return pickleLookupGlobal(f, module, name)
+}
+
+// pickleLookupGlobal returns the attribute name of the given module, importing
+// the module if necessary. Builtins are resolved without importing
+// __builtin__ since it may not be linked into the program.
+func pickleLookupGlobal(f *Frame, module, name string) (*Object, *BaseException) {
+	if module == "__builtin__" {
+		o, raised := Builtins.GetItemString(f, name)
+		if raised == nil && o == nil {
+			format := "'module' object has no attribute '%s'"
+			raised = f.RaiseType(AttributeErrorType, fmt.Sprintf(format, name))
+		}
+		return o, raised
+	}
+	modules, raised := ImportModule(f, module)
+	if raised != nil {
+		return nil, raised
+	}
+	return GetAttr(f, modules[len(modules)-1], NewStr(name), nil)
+}
+
+func (u *unpickler) readSize(f *Frame, long bool) (int, *BaseException) {
+	if !long {
+		b, raised := u.r.read(f, 1)
+		if raised != nil {
+			return 0, raised
+		}
+		return int(b[0]), nil
+	}
+	b, raised := u.r.read(f, 4)
+	if raised != nil {
+		return 0, raised
+	}
+	n := int(int32(binary.LittleEndian.Uint32([]byte(b))))
+	if n < 0 {
+		return 0, f.RaiseType(u.errorType, "pickle data has negative byte count")
+	}
+	return n, nil
+}
+
+// readMemoID reads the memo index operand of a PUT or GET opcode. textOp and
+// binOp are the text and one byte variants of op.
+func (u *unpickler) readMemoID(f *Frame, op, textOp, binOp byte) (int, *BaseException) {
+	switch op {
+	case textOp:
+		line, raised := u.r.readline(f)
+		if raised != nil {
+			return 0, raised
+		}
+		id, err := strconv.Atoi(line)
+		if err != nil {
+			return 0, u.raiseInvalid(f, "memo index", line)
+		}
+		return id, nil
+	case binOp:
+		return u.readSize(f, false)
+	}
+	return u.readSize(f, true)
+}
+
+func (u *unpickler) loadInt(f *Frame) *BaseException {
+	line, raised := u.r.readline(f)
+	if raised != nil {
+		return raised
+	}
+	switch line {
+	case "00":
+		u.push(False.ToObject())
+		return nil
+	case "01":
+		u.push(True.ToObject())
+		return nil
+	}
+	if i, err := strconv.Atoi(line); err == nil {
+		u.push(NewInt(i).ToObject())
+		return nil
+	}
+	x, ok := new(big.Int).SetString(line, 10)
+	if !ok {
+		return u.raiseInvalid(f, "INT", line)
+	}
+	u.push(NewLong(x).ToObject())
+	return nil
+}
+
+func (u *unpickler) raiseInvalid(f *Frame, what, value string) *BaseException {
+	return f.RaiseType(u.errorType, fmt.Sprintf("invalid %s in pickle data: %q", what, value))
+}
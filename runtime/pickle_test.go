@@ -0,0 +1,229 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPickleDumps(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object, protocol int) (*Str, *BaseException) {
+		return PickleDumps(f, o, protocol, ValueErrorType)
+	})
+	shared := NewList()
+	fooType := newPickleTestModule()
+	defer SysModules.DelItemString(NewRootFrame(), "pickle_test")
+	cases := []invokeTestCase{
+		{args: wrapArgs(None, 0), want: NewStr("N.").ToObject()},
+		{args: wrapArgs(true, 0), want: NewStr("I01\n.").ToObject()},
+		{args: wrapArgs(true, 2), want: NewStr("\x80\x02\x88.").ToObject()},
+		{args: wrapArgs(1, 0), want: NewStr("I1\n.").ToObject()},
+		{args: wrapArgs(1, 1), want: NewStr("K\x01.").ToObject()},
+		{args: wrapArgs(300, 1), want: NewStr("M,\x01.").ToObject()},
+		{args: wrapArgs(-1, 1), want: NewStr("J\xff\xff\xff\xff.").ToObject()},
+		{args: wrapArgs(1<<40, 1), want: NewStr("I1099511627776\n.").ToObject()},
+		{args: wrapArgs(NewLong(big.NewInt(5)), 0), want: NewStr("L5L\n.").ToObject()},
+		{args: wrapArgs(NewLong(big.NewInt(5)), 2), want: NewStr("\x80\x02\x8a\x01\x05.").ToObject()},
+		{args: wrapArgs(NewLong(big.NewInt(-129)), 2), want: NewStr("\x80\x02\x8a\x02\x7f\xff.").ToObject()},
+		{args: wrapArgs(NewLong(big.NewInt(255)), 2), want: NewStr("\x80\x02\x8a\x02\xff\x00.").ToObject()},
+		{args: wrapArgs(NewLong(big.NewInt(0)), 2), want: NewStr("\x80\x02\x8a\x00.").ToObject()},
+		{args: wrapArgs(1.5, 0), want: NewStr("F1.5\n.").ToObject()},
+		{args: wrapArgs(1.5, 1), want: NewStr("G?\xf8\x00\x00\x00\x00\x00\x00.").ToObject()},
+		{args: wrapArgs("foo", 0), want: NewStr("S'foo'\np0\n.").ToObject()},
+		{args: wrapArgs("a'b\"\n", 0), want: NewStr("S'a\\'b\"\\n'\np0\n.").ToObject()},
+		{args: wrapArgs("foo", 1), want: NewStr("U\x03fooq\x00.").ToObject()},
+		{args: wrapArgs(NewUnicode("é\\\n€"), 0), want: NewStr("V\xe9\\u005c\\u000a\\u20ac\np0\n.").ToObject()},
+		{args: wrapArgs(NewUnicode("é"), 2), want: NewStr("\x80\x02X\x02\x00\x00\x00\xc3\xa9q\x00.").ToObject()},
+		{args: wrapArgs(newTestTuple(1, 2), 0), want: NewStr("(I1\nI2\ntp0\n.").ToObject()},
+		{args: wrapArgs(newTestTuple(1, 2), 2), want: NewStr("\x80\x02K\x01K\x02\x86q\x00.").ToObject()},
+		{args: wrapArgs(NewTuple(), 1), want: NewStr(").").ToObject()},
+		{args: wrapArgs(newTestList(1, 2), 0), want: NewStr("(lp0\nI1\naI2\na.").ToObject()},
+		{args: wrapArgs(newTestList(1, 2), 1), want: NewStr("]q\x00(K\x01K\x02e.").ToObject()},
+		{args: wrapArgs(newTestList(1), 1), want: NewStr("]q\x00K\x01a.").ToObject()},
+		{args: wrapArgs(newTestDict("a", 1), 0), want: NewStr("(dp0\nS'a'\np1\nI1\ns.").ToObject()},
+		{args: wrapArgs(newTestDict("a", 1), 2), want: NewStr("\x80\x02}q\x00U\x01aq\x01K\x01s.").ToObject()},
+		{args: wrapArgs(newTestList(shared, shared), 1), want: NewStr("]q\x00(]q\x01h\x01e.").ToObject()},
+		{args: wrapArgs(fooType, 0), want: NewStr("cpickle_test\nFoo\np0\n.").ToObject()},
+		{args: wrapArgs(fooType, 2), want: NewStr("\x80\x02cpickle_test\nFoo\nq\x00.").ToObject()},
+		{args: wrapArgs(None, 3), wantExc: mustCreateException(ValueErrorType, "pickle protocol must be <= 2")},
+		{args: wrapArgs(newObject(ObjectType), 0), wantExc: mustCreateException(TypeErrorType, "can't pickle object objects")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+// newPickleTestModule registers a module named pickle_test in sys.modules
+// containing a class Foo and a function pair that returns its args as a
+// tuple.
+func newPickleTestModule() *Type {
+	f := NewRootFrame()
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__module__": NewStr("pickle_test").ToObject(),
+	}))
+	pair := newBuiltinFunction("pair", func(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+		return NewTuple(args.makeCopy()...).ToObject(), nil
+	})
+	module := newTestModule("pickle_test", "pickle_test.py")
+	module.state = moduleStateReady
+	mustNotRaise(nil, module.Dict().SetItemString(f, "Foo", fooType.ToObject()))
+	mustNotRaise(nil, module.Dict().SetItemString(f, "pair", pair.ToObject()))
+	mustNotRaise(nil, SysModules.SetItemString(f, "pickle_test", module.ToObject()))
+	return fooType
+}
+
+func TestPickleDumpsUnfoundGlobal(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	f := NewRootFrame()
+	_, raised := PickleDumps(f, fooType.ToObject(), 0, ValueErrorType)
+	if raised == nil || !raised.isInstance(ValueErrorType) {
+		t.Fatalf("PickleDumps(Foo) raised %v, want ValueError", raised)
+	}
+}
+
+func TestPickleLoads(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *Str) (*Object, *BaseException) {
+		return PickleLoads(f, s, ValueErrorType)
+	})
+	fooType := newPickleTestModule()
+	defer SysModules.DelItemString(NewRootFrame(), "pickle_test")
+	cases := []invokeTestCase{
+		{args: wrapArgs("N."), want: None},
+		{args: wrapArgs("I00\n."), want: False.ToObject()},
+		{args: wrapArgs("\x80\x02\x88."), want: True.ToObject()},
+		{args: wrapArgs("I-12\n."), want: NewInt(-12).ToObject()},
+		{args: wrapArgs("I12345678901234567890\n."), want: NewLong(new(big.Int).SetUint64(12345678901234567890)).ToObject()},
+		{args: wrapArgs("J\xff\xff\xff\xff."), want: NewInt(-1).ToObject()},
+		{args: wrapArgs("L-5L\n."), want: NewLong(big.NewInt(-5)).ToObject()},
+		{args: wrapArgs("\x80\x02\x8a\x02\x7f\xff."), want: NewLong(big.NewInt(-129)).ToObject()},
+		{args: wrapArgs("F1.5\n."), want: NewFloat(1.5).ToObject()},
+		{args: wrapArgs("S'a\\'b\"\\n\\x41\\101'\np0\n."), want: NewStr("a'b\"\nAA").ToObject()},
+		{args: wrapArgs("S\"foo\"\n."), want: NewStr("foo").ToObject()},
+		{args: wrapArgs("V\xe9\\u005c\\u000a\\u20ac\\U0001f600\np0\n."), want: NewUnicode("é\\\n€😀").ToObject()},
+		{args: wrapArgs("X\x02\x00\x00\x00\xc3\xa9."), want: NewUnicode("é").ToObject()},
+		{args: wrapArgs("(I1\nI2\ntp0\n."), want: newTestTuple(1, 2).ToObject()},
+		{args: wrapArgs("(lp0\nI1\naI2\na."), want: newTestList(1, 2).ToObject()},
+		{args: wrapArgs("]q\x00(K\x01K\x02e."), want: newTestList(1, 2).ToObject()},
+		{args: wrapArgs("(dp0\nS'a'\np1\nI1\ns."), want: newTestDict("a", 1).ToObject()},
+		{args: wrapArgs("(S'a'\nI1\nd."), want: newTestDict("a", 1).ToObject()},
+		{args: wrapArgs("cpickle_test\nFoo\n."), want: fooType.ToObject()},
+		{args: wrapArgs("cpickle_test\npair\n(I1\nS'a'\ntR."), want: newTestTuple(1, "a").ToObject()},
+		{args: wrapArgs("cpickle_test\nbar\n."), wantExc: mustCreateException(AttributeErrorType, "'testModule' object has no attribute 'bar'")},
+		{args: wrapArgs("cno_such_module\nFoo\n."), wantExc: mustCreateException(ImportErrorType, "no_such_module")},
+		{args: wrapArgs("N.trailing"), want: None},
+		{args: wrapArgs(""), wantExc: mustCreateException(EOFErrorType, "")},
+		{args: wrapArgs("(I1\n"), wantExc: mustCreateException(EOFErrorType, "")},
+		{args: wrapArgs("z."), wantExc: mustCreateException(ValueErrorType, "invalid load key, 'z'.")},
+		{args: wrapArgs("."), wantExc: mustCreateException(ValueErrorType, "unpickling stack underflow")},
+		{args: wrapArgs("t."), wantExc: mustCreateException(ValueErrorType, "could not find MARK")},
+		{args: wrapArgs("S'foo\n."), wantExc: mustCreateException(ValueErrorType, "insecure string pickle")},
+		{args: wrapArgs("h\x05."), wantExc: mustCreateException(ValueErrorType, "memo value not found at index 5")},
+		{args: wrapArgs("\x80\x03N."), wantExc: mustCreateException(ValueErrorType, "unsupported pickle protocol: 3")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPickleRoundTrip(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, o *Object, protocol int) (*Object, *BaseException) {
+		s, raised := PickleDumps(f, o, protocol, ValueErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return PickleLoads(f, s, ValueErrorType)
+	})
+	bigInt := new(big.Int).Lsh(big.NewInt(-3), 2000)
+	values := []*Object{
+		None,
+		True.ToObject(),
+		NewInt(-70000).ToObject(),
+		NewInt(1 << 40).ToObject(),
+		NewLong(bigInt).ToObject(),
+		NewFloat(-0.1).ToObject(),
+		NewStr("\x00\xff'\"\\").ToObject(),
+		NewUnicode("\\u0041 ☃\n").ToObject(),
+		newTestTuple(1, newTestTuple(), newTestTuple("a", "b", "c", "d")).ToObject(),
+		newTestList(1, newTestList(), newTestDict(1.5, NewStr("x"))).ToObject(),
+		newTestDict("a", newTestTuple(NewLong(big.NewInt(7))), NewUnicode("b"), None).ToObject(),
+	}
+	var cases []invokeTestCase
+	for _, v := range values {
+		for protocol := 0; protocol <= pickleHighestProtocol; protocol++ {
+			cases = append(cases, invokeTestCase{args: wrapArgs(v, protocol), want: v})
+		}
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPickleRecursive(t *testing.T) {
+	f := NewRootFrame()
+	for protocol := 0; protocol <= pickleHighestProtocol; protocol++ {
+		l := NewList()
+		l.Append(newTestTuple(l).ToObject())
+		s, raised := PickleDumps(f, l.ToObject(), protocol, ValueErrorType)
+		if raised != nil {
+			t.Fatalf("PickleDumps(%d) raised %v", protocol, raised)
+		}
+		o, raised := PickleLoads(f, s, ValueErrorType)
+		if raised != nil {
+			t.Fatalf("PickleLoads(%q) raised %v", s.Value(), raised)
+		}
+		if !o.isInstance(ListType) || len(toListUnsafe(o).elems) != 1 {
+			t.Fatalf("PickleLoads(%q) = %v, want a list of one element", s.Value(), o)
+		}
+		elem := toListUnsafe(o).elems[0]
+		if !elem.isInstance(TupleType) || toTupleUnsafe(elem).elems[0] != o {
+			t.Errorf("PickleLoads(%q) did not restore the reference cycle", s.Value())
+		}
+	}
+}
+
+func TestPickleLoad(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *Str) (*Object, *BaseException) {
+		sio, raised := StringIOType.Call(f, Args{s.ToObject()}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		first, raised := PickleLoad(f, sio, ValueErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		second, raised := PickleLoad(f, sio, ValueErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(first, second).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("I1\n.(lp0\nS'a'\na."), want: newTestTuple(1, newTestList("a")).ToObject()},
+		{args: wrapArgs("]q\x00K\x01a.U\x03foo."), want: newTestTuple(newTestList(1), "foo").ToObject()},
+		{args: wrapArgs("N."), wantExc: mustCreateException(EOFErrorType, "")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
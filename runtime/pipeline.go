@@ -0,0 +1,275 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// pipelineIteratorType is the base of the native lazy iterators that
+	// apply a function to or filter the items of other iterators.
+	pipelineIteratorType = newBasisType("pipelineiterator", reflect.TypeOf(pipelineIterator{}), toPipelineIteratorUnsafe, ObjectType)
+	// IMapType is the object representing the Python 'itertools.imap'
+	// type.
+	IMapType = newSimpleType("imap", pipelineIteratorType)
+	// IFilterType is the object representing the Python
+	// 'itertools.ifilter' type.
+	IFilterType = newSimpleType("ifilter", pipelineIteratorType)
+	// IFilterFalseType is the object representing the Python
+	// 'itertools.ifilterfalse' type.
+	IFilterFalseType = newSimpleType("ifilterfalse", pipelineIteratorType)
+)
+
+type pipelineStageKind int
+
+const (
+	pipelineMap pipelineStageKind = iota
+	pipelineFilter
+	pipelineFilterFalse
+)
+
+// pipelineStage is a single map or filter step of a pipeline. fn is None for
+// map stages that build tuples of their arguments and for filter stages that
+// test the truth of the items themselves.
+type pipelineStage struct {
+	kind pipelineStageKind
+	fn   *Object
+}
+
+// pipelineIterator yields the items of its source iterators passed through a
+// sequence of map and filter stages. When an imap or ifilter is built on top
+// of another exact imap or ifilter, the two are fused: the new iterator pulls
+// directly from the inner one's sources and runs both sets of stages in a
+// single loop, so no intermediate iterator is involved per item. Since stages
+// hold no state, advancing either iterator has the same effect as it would
+// without fusion.
+type pipelineIterator struct {
+	Object
+	mutex sync.Mutex
+	// sources holds the iterators items are pulled from. The first stage
+	// is called with one item from each of them.
+	sources []*Object
+	stages  []pipelineStage
+}
+
+func toPipelineIteratorUnsafe(o *Object) *pipelineIterator {
+	return (*pipelineIterator)(o.toPointer())
+}
+
+// newPipelineIterator returns an iterator of type t that applies stage to
+// the items of iterables. Exact pipeline iterators passed as the only
+// iterable are fused with the new one.
+func newPipelineIterator(f *Frame, t *Type, stage pipelineStage, iterables Args) (*Object, *BaseException) {
+	var sources []*Object
+	var stages []pipelineStage
+	if inner, ok := pipelineIteratorFusable(iterables); ok {
+		sources = inner.sources
+		stages = make([]pipelineStage, len(inner.stages), len(inner.stages)+1)
+		copy(stages, inner.stages)
+	} else {
+		sources = make([]*Object, len(iterables))
+		for i, iterable := range iterables {
+			iter, raised := Iter(f, iterable)
+			if raised != nil {
+				return nil, raised
+			}
+			sources[i] = iter
+		}
+	}
+	var d *Dict
+	if t != IMapType && t != IFilterType && t != IFilterFalseType {
+		d = NewDict()
+	}
+	p := &pipelineIterator{Object: Object{typ: t, dict: d}, sources: sources, stages: append(stages, stage)}
+	return &p.Object, nil
+}
+
+// pipelineIteratorFusable returns the pipeline iterator iterables consists
+// of if it can be fused with the stages of another iterator. Subclasses
+// are not fused since they may override next().
+func pipelineIteratorFusable(iterables Args) (*pipelineIterator, bool) {
+	if len(iterables) != 1 {
+		return nil, false
+	}
+	switch iterables[0].typ {
+	case IMapType, IFilterType, IFilterFalseType:
+		return toPipelineIteratorUnsafe(iterables[0]), true
+	}
+	return nil, false
+}
+
+// next returns the next item that makes it through the stages of p followed
+// by extra, or nil when the sources are exhausted.
+func (p *pipelineIterator) next(f *Frame, extra []pipelineStage) (*Object, *BaseException) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for {
+		args := make(Args, len(p.sources))
+		for i, source := range p.sources {
+			item, raised := Next(f, source)
+			if raised != nil {
+				if raised.isInstance(StopIterationType) {
+					f.RestoreExc(nil, nil)
+					return nil, nil
+				}
+				return nil, raised
+			}
+			args[i] = item
+		}
+		item, raised := pipelineApply(f, p.stages, args)
+		if raised == nil && item != nil {
+			item, raised = pipelineApply(f, extra, Args{item})
+		}
+		if raised != nil || item != nil {
+			return item, raised
+		}
+	}
+}
+
+// pipelineApply passes args through stages, returning nil if a filter
+// rejected them.
+func pipelineApply(f *Frame, stages []pipelineStage, args Args) (*Object, *BaseException) {
+	for _, stage := range stages {
+		if stage.kind == pipelineMap {
+			var item *Object
+			if stage.fn == None {
+				item = NewTuple(args.makeCopy()...).ToObject()
+			} else {
+				var raised *BaseException
+				if item, raised = stage.fn.Call(f, args, nil); raised != nil {
+					return nil, raised
+				}
+			}
+			args = Args{item}
+			continue
+		}
+		test := args[0]
+		if stage.fn != None {
+			var raised *BaseException
+			if test, raised = stage.fn.Call(f, args, nil); raised != nil {
+				return nil, raised
+			}
+		}
+		ok, raised := IsTrue(f, test)
+		if raised != nil {
+			return nil, raised
+		}
+		if ok != (stage.kind == pipelineFilter) {
+			return nil, nil
+		}
+	}
+	return args[0], nil
+}
+
+// pipelineCollect returns the items of iterable passed through extra. When
+// iterable is a pipeline iterator, extra is run in the same loop as its
+// stages.
+func pipelineCollect(f *Frame, iterable *Object, extra []pipelineStage) ([]*Object, *BaseException) {
+	var result []*Object
+	if p, ok := pipelineIteratorFusable(Args{iterable}); ok {
+		for {
+			item, raised := p.next(f, extra)
+			if raised != nil {
+				return nil, raised
+			}
+			if item == nil {
+				return result, nil
+			}
+			result = append(result, item)
+		}
+	}
+	raised := seqForEach(f, iterable, func(o *Object) *BaseException {
+		item, raised := pipelineApply(f, extra, Args{o})
+		if raised == nil && item != nil {
+			result = append(result, item)
+		}
+		return raised
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	return result, nil
+}
+
+func pipelineIteratorIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func pipelineIteratorNext(f *Frame, o *Object) (*Object, *BaseException) {
+	item, raised := toPipelineIteratorUnsafe(o).next(f, nil)
+	if raised != nil {
+		return nil, raised
+	}
+	if item == nil {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	return item, nil
+}
+
+func iMapNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if len(kwargs) != 0 {
+		return nil, f.RaiseType(TypeErrorType, "imap does not take keyword arguments")
+	}
+	if len(args) < 2 {
+		return nil, f.RaiseType(TypeErrorType, "imap() must have at least two arguments.")
+	}
+	return newPipelineIterator(f, t, pipelineStage{pipelineMap, args[0]}, args[1:])
+}
+
+func iFilterNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	return pipelineFilterNew(f, t, pipelineFilter, args, kwargs)
+}
+
+func iFilterFalseNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	return pipelineFilterNew(f, t, pipelineFilterFalse, args, kwargs)
+}
+
+func pipelineFilterNew(f *Frame, t *Type, kind pipelineStageKind, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	name := IFilterType.Name()
+	if kind == pipelineFilterFalse {
+		name = IFilterFalseType.Name()
+	}
+	if len(kwargs) != 0 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("%s does not take keyword arguments", name))
+	}
+	if len(args) != 2 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("%s expected 2 arguments, got %d", name, len(args)))
+	}
+	return newPipelineIterator(f, t, pipelineStage{kind, args[0]}, args[1:])
+}
+
+func initPipelineIteratorType(map[string]*Object) {
+	pipelineIteratorType.flags &^= typeFlagInstantiable
+	pipelineIteratorType.slots.Iter = &unaryOpSlot{pipelineIteratorIter}
+	pipelineIteratorType.slots.Next = &unaryOpSlot{pipelineIteratorNext}
+}
+
+func initIMapType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	IMapType.slots.New = &newSlot{iMapNew}
+}
+
+func initIFilterType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	IFilterType.slots.New = &newSlot{iFilterNew}
+}
+
+func initIFilterFalseType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("itertools").ToObject()
+	IFilterFalseType.slots.New = &newSlot{iFilterFalseNew}
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func TestPipelineIteratorNew(t *testing.T) {
+	neg := wrapFuncForTest(func(f *Frame, i int) int { return -i })
+	add := wrapFuncForTest(func(f *Frame, i, j int) int { return i + j })
+	isOdd := wrapFuncForTest(func(f *Frame, i int) bool { return i%2 != 0 })
+	fun := wrapFuncForTest(func(f *Frame, t *Type, args ...*Object) (*Object, *BaseException) {
+		iter, raised := t.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return TupleType.Call(f, Args{iter}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(IMapType, neg, newTestList(1, 2)), want: newTestTuple(-1, -2).ToObject()},
+		{args: wrapArgs(IMapType, add, newTestList(1, 2, 3), newTestList(10, 20)), want: newTestTuple(11, 22).ToObject()},
+		{args: wrapArgs(IMapType, None, newTestList(1, 2)), want: newTestTuple(newTestTuple(1), newTestTuple(2)).ToObject()},
+		{args: wrapArgs(IFilterType, isOdd, newTestList(1, 2, 3)), want: newTestTuple(1, 3).ToObject()},
+		{args: wrapArgs(IFilterType, None, newTestList(0, 1, "")), want: newTestTuple(1).ToObject()},
+		{args: wrapArgs(IFilterFalseType, isOdd, newTestList(1, 2, 3)), want: newTestTuple(2).ToObject()},
+		{args: wrapArgs(IMapType, neg), wantExc: mustCreateException(TypeErrorType, "imap() must have at least two arguments.")},
+		{args: wrapArgs(IFilterType, isOdd), wantExc: mustCreateException(TypeErrorType, "ifilter expected 2 arguments, got 1")},
+		{args: wrapArgs(IFilterFalseType, isOdd, 123), wantExc: mustCreateException(TypeErrorType, "'int' object is not iterable")},
+		{args: wrapArgs(IMapType, neg, newTestList("a")), wantExc: mustCreateException(TypeErrorType, "an int is required")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPipelineIteratorFusion(t *testing.T) {
+	f := NewRootFrame()
+	neg := wrapFuncForTest(func(f *Frame, i int) int { return -i })
+	isOdd := wrapFuncForTest(func(f *Frame, i int) bool { return i%2 != 0 })
+	inner := mustNotRaise(IFilterType.Call(f, wrapArgs(isOdd, newTestList(1, 2, 3, 4, 5)), nil))
+	outer := mustNotRaise(IMapType.Call(f, wrapArgs(neg, inner), nil))
+	p, q := toPipelineIteratorUnsafe(inner), toPipelineIteratorUnsafe(outer)
+	if len(q.sources) != 1 || q.sources[0] != p.sources[0] || len(q.stages) != 2 {
+		t.Errorf("imap(neg, ifilter(isOdd, ...)) was not fused with its iterable")
+	}
+	// Advancing the inner iterator consumes the shared source like it
+	// would without fusion.
+	if got := mustNotRaise(Next(f, inner)); !got.isInstance(IntType) || toIntUnsafe(got).Value() != 1 {
+		t.Errorf("next(inner) = %v, want 1", got)
+	}
+	got := mustNotRaise(ListType.Call(f, Args{outer}, nil))
+	if want := newTestList(-3, -5).ToObject(); mustNotRaise(Eq(f, got, want)) != True.ToObject() {
+		t.Errorf("list(outer) = %v, want %v", got, want)
+	}
+	// Subclasses may override next() so they're not fused.
+	subclass := newTestClass("SubIFilter", []*Type{IFilterType}, NewDict())
+	inner = mustNotRaise(subclass.Call(f, wrapArgs(isOdd, newTestList(1, 2, 3)), nil))
+	outer = mustNotRaise(IMapType.Call(f, wrapArgs(neg, inner), nil))
+	if q := toPipelineIteratorUnsafe(outer); len(q.sources) != 1 || q.sources[0] != inner || len(q.stages) != 1 {
+		t.Errorf("imap(neg, SubIFilter(isOdd, ...)) was fused with its iterable")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// Policy restricts what code running on a particular stack may do. It is
+// intended for embedders that execute untrusted code. A Policy is installed on
+// a root frame via NewRootFrameWithPolicy and applies to every frame on that
+// stack. Threads started from that stack, Python callables invoked from Go via
+// MakeNativeFunc, and the __del__ methods and weakref callbacks of objects and
+// weakrefs created on it inherit the Policy. Violations raise SecurityError.
+type Policy struct {
+	// Builtins, if non-nil, is used in place of the global Builtins dict to
+	// resolve builtin names.
+	Builtins *Dict
+	// DenyFiles prevents files from being opened via open() and file().
+	DenyFiles bool
+	// DenyPackages is a list of Go package import paths (e.g. "os/exec" or
+	// "net") whose functions and methods may not be called from Python.
+	// Subpackages of a denied package are also denied.
+	DenyPackages []string
+	// MaxSteps is the maximum number of statements that may be executed,
+	// or zero for no limit. The budget is shared between the stack and
+	// any threads started from it.
+	MaxSteps int
+	// MaxAllocLen is the maximum number of elements (or characters for
+	// strings) that a single operation such as sequence repetition or
+	// range() may allocate, or zero for no limit.
+	MaxAllocLen int
+}
+
+// Policy returns the Policy in effect for f's stack or nil if unrestricted.
+func (f *Frame) Policy() *Policy {
+	return f.threadState.policy
+}
+
+// allocLimit returns the Policy's MaxAllocLen for f's stack, or zero for no
+// limit.
+func (f *Frame) allocLimit() int {
+	if p := f.threadState.policy; p != nil {
+		return p.MaxAllocLen
+	}
+	return 0
+}
+
+func (f *Frame) checkAlloc(n int) *BaseException {
+	if limit := f.allocLimit(); limit > 0 && n > limit {
+		return raiseAllocLimit(f, n, limit)
+	}
+	return nil
+}
+
+func (f *Frame) checkFileAccess(filename string) *BaseException {
+	if p := f.threadState.policy; p != nil && p.DenyFiles {
+		return f.RaiseType(SecurityErrorType, fmt.Sprintf("file access denied: '%s'", filename))
+	}
+	return nil
+}
+
+func (f *Frame) checkNativeCall(fun reflect.Value) *BaseException {
+	p := f.threadState.policy
+	if p == nil || len(p.DenyPackages) == 0 {
+		return nil
+	}
+	name := "<unknown>"
+	if rf := runtime.FuncForPC(fun.Pointer()); rf != nil {
+		name = rf.Name()
+	}
+	pkg := nativeFuncPackage(name)
+	for _, denied := range p.DenyPackages {
+		if pkg == denied || strings.HasPrefix(pkg, denied+"/") {
+			return f.RaiseType(SecurityErrorType, fmt.Sprintf("call to native function %s denied", name))
+		}
+	}
+	return nil
+}
+
+func (f *Frame) checkStep() *BaseException {
+	if p := f.threadState.policy; p != nil && p.MaxSteps > 0 {
+		if atomic.AddInt64(f.threadState.steps, 1) > int64(p.MaxSteps) {
+			return f.RaiseType(SecurityErrorType, fmt.Sprintf("exceeded limit of %d steps", p.MaxSteps))
+		}
+	}
+	return nil
+}
+
+func raiseAllocLimit(f *Frame, n, limit int) *BaseException {
+	return f.RaiseType(SecurityErrorType, fmt.Sprintf("allocation of %d elements exceeds limit of %d", n, limit))
+}
+
+// nativeFuncPackage returns the import path of the package containing the
+// function with the given fully qualified name, e.g. "os/exec.Command" or
+// "os.(*File).Close".
+func nativeFuncPackage(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
@@ -0,0 +1,221 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyAlloc(t *testing.T) {
+	f := NewRootFrameWithPolicy(&Policy{MaxAllocLen: 10})
+	str := func(s string) *Object { return NewStr(s).ToObject() }
+	list := func(n int) *Object { return NewList(make([]*Object, n)...).ToObject() }
+	tuple := func(n int) *Object { return NewTuple(make([]*Object, n)...).ToObject() }
+	parts := []string{"abc", "def", "ghi"}
+	bomb, raised := ZlibCompress(NewRootFrame(), NewStr(strings.Repeat("a", 100000)).ToObject(), 9, RuntimeErrorType)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	zlibDecompress := func(f *Frame, data string) (*Object, *BaseException) {
+		s, raised := ZlibDecompress(f, NewStr(data).ToObject(), zlibMaxWBits, RuntimeErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return s.ToObject(), nil
+	}
+	zlibDecompressor := func(f *Frame, data *Str) (*Object, *BaseException) {
+		d, raised := NewZlibDecompressor(f, zlibMaxWBits, RuntimeErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return zlibDecompressorDecompress(f, wrapArgs(d, data), nil)
+	}
+	cases := []struct {
+		fun     func() (*Object, *BaseException)
+		wantExc bool
+	}{
+		{func() (*Object, *BaseException) { return Mul(f, NewStr("ab").ToObject(), NewInt(5).ToObject()) }, false},
+		{func() (*Object, *BaseException) { return Mul(f, NewStr("ab").ToObject(), NewInt(6).ToObject()) }, true},
+		{func() (*Object, *BaseException) { return Mul(f, NewUnicode("ab").ToObject(), NewInt(6).ToObject()) }, true},
+		{func() (*Object, *BaseException) { return Mul(f, NewList(None).ToObject(), NewInt(11).ToObject()) }, true},
+		{func() (*Object, *BaseException) { return Mul(f, NewTuple(None).ToObject(), NewInt(10).ToObject()) }, false},
+		{func() (*Object, *BaseException) { return builtinRange(f, wrapArgs(10), nil) }, false},
+		{func() (*Object, *BaseException) { return builtinRange(f, wrapArgs(0, 100, 5), nil) }, true},
+		{func() (*Object, *BaseException) { return builtinRange(f, wrapArgs(0, 30, 3), nil) }, false},
+		{func() (*Object, *BaseException) { return builtinRange(f, wrapArgs(0, 31, 3), nil) }, true},
+		{func() (*Object, *BaseException) { return Add(f, str("abcde"), str("fghij")) }, false},
+		{func() (*Object, *BaseException) { return Add(f, str("abcde"), str("fghijk")) }, true},
+		{func() (*Object, *BaseException) { return Add(f, NewUnicode("abcde").ToObject(), str("fghijk")) }, true},
+		{func() (*Object, *BaseException) { return Add(f, list(5), list(6)) }, true},
+		{func() (*Object, *BaseException) { return Add(f, tuple(5), tuple(5)) }, false},
+		{func() (*Object, *BaseException) { return strJoin(f, wrapArgs(",", parts[:2]), nil) }, false},
+		{func() (*Object, *BaseException) { return strJoin(f, wrapArgs(",", parts), nil) }, true},
+		{func() (*Object, *BaseException) { return unicodeJoin(f, wrapArgs(NewUnicode(","), parts), nil) }, true},
+		{func() (*Object, *BaseException) { return listExtend(f, wrapArgs(list(5), tuple(5)), nil) }, false},
+		{func() (*Object, *BaseException) { return listExtend(f, wrapArgs(list(5), tuple(6)), nil) }, true},
+		{func() (*Object, *BaseException) { return IAdd(f, list(10), list(1)) }, true},
+		{func() (*Object, *BaseException) { return zlibDecompress(f, zlibHello) }, false},
+		{func() (*Object, *BaseException) { return zlibDecompress(f, bomb.Value()) }, true},
+		{func() (*Object, *BaseException) { return zlibDecompressor(f, bomb) }, true},
+	}
+	for i, cas := range cases {
+		_, raised := cas.fun()
+		if cas.wantExc && (raised == nil || raised.typ != SecurityErrorType) {
+			t.Errorf("case %d raised %v, want SecurityError", i, raised)
+		} else if !cas.wantExc && raised != nil {
+			t.Errorf("case %d raised %v, want no exception", i, raised)
+		}
+	}
+}
+
+func TestPolicyDenyFiles(t *testing.T) {
+	f := NewRootFrameWithPolicy(&Policy{DenyFiles: true})
+	_, raised := builtinOpen(f, wrapArgs("/dev/null"), nil)
+	if raised == nil || raised.typ != SecurityErrorType {
+		t.Errorf("open('/dev/null') raised %v, want SecurityError", raised)
+	}
+	if _, raised := builtinOpen(NewRootFrame(), wrapArgs("/nonexistent/file"), nil); raised == nil || !raised.isInstance(IOErrorType) {
+		t.Errorf("open('/nonexistent/file') raised %v, want IOError", raised)
+	}
+}
+
+func TestPolicyDenyPackages(t *testing.T) {
+	fun := reflect.ValueOf(strings.ToUpper)
+	method := reflect.ValueOf((*strings.Reader).Len)
+	cases := []struct {
+		denied  []string
+		fun     reflect.Value
+		wantExc bool
+	}{
+		{nil, fun, false},
+		{[]string{"strings"}, fun, true},
+		{[]string{"str"}, fun, false},
+		{[]string{"fmt", "strings"}, method, true},
+		{[]string{"reflect"}, method, false},
+	}
+	for _, cas := range cases {
+		f := NewRootFrameWithPolicy(&Policy{DenyPackages: cas.denied})
+		args := wrapArgs("foo")
+		if cas.fun == method {
+			args = wrapArgs(strings.NewReader("foo"))
+		}
+		_, raised := nativeInvoke(f, cas.fun, args)
+		if cas.wantExc && (raised == nil || raised.typ != SecurityErrorType) {
+			t.Errorf("nativeInvoke(%v) with DenyPackages %v raised %v, want SecurityError", cas.fun, cas.denied, raised)
+		} else if !cas.wantExc && raised != nil {
+			t.Errorf("nativeInvoke(%v) with DenyPackages %v raised %v, want no exception", cas.fun, cas.denied, raised)
+		}
+	}
+}
+
+func TestPolicyMaxSteps(t *testing.T) {
+	f := NewRootFrameWithPolicy(&Policy{MaxSteps: 3})
+	child := newChildFrame(f)
+	for i := 0; i < 3; i++ {
+		if raised := child.SetLineno(i); raised != nil {
+			t.Fatalf("SetLineno(%d) raised %v", i, raised)
+		}
+	}
+	if raised := f.SetLineno(4); raised == nil || raised.typ != SecurityErrorType {
+		t.Errorf("SetLineno(4) raised %v, want SecurityError", raised)
+	}
+	f = NewRootFrame()
+	for i := 0; i < 100; i++ {
+		if raised := f.SetLineno(i); raised != nil {
+			t.Fatalf("SetLineno(%d) raised %v with no policy", i, raised)
+		}
+	}
+}
+
+func TestPolicyThread(t *testing.T) {
+	f := NewRootFrameWithPolicy(&Policy{DenyFiles: true, MaxSteps: 3})
+	for i := 0; i < 2; i++ {
+		if raised := f.SetLineno(i); raised != nil {
+			t.Fatalf("SetLineno(%d) raised %v", i, raised)
+		}
+	}
+	results := make(chan *BaseException, 3)
+	callable := newBuiltinFunction("TestPolicyThread", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		_, raised := builtinOpen(f, wrapArgs("/dev/null"), nil)
+		results <- raised
+		results <- f.SetLineno(2)
+		results <- f.SetLineno(3)
+		return None, nil
+	}).ToObject()
+	StartThread(f, callable)
+	if raised := <-results; raised == nil || raised.typ != SecurityErrorType {
+		t.Errorf("open('/dev/null') in thread raised %v, want SecurityError", raised)
+	}
+	if raised := <-results; raised != nil {
+		t.Errorf("SetLineno(2) in thread raised %v", raised)
+	}
+	if raised := <-results; raised == nil || raised.typ != SecurityErrorType {
+		t.Errorf("SetLineno(3) in thread raised %v, want SecurityError", raised)
+	}
+}
+
+func TestPolicyFinalizers(t *testing.T) {
+	p := &Policy{MaxSteps: 100}
+	f := NewRootFrameWithPolicy(p)
+	policies := make(chan *Policy, 2)
+	recordPolicy := newBuiltinFunction("recordPolicy", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		policies <- f.Policy()
+		return None, nil
+	}).ToObject()
+	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"__del__": recordPolicy}))
+	// __del__ and weakref callbacks run subject to the Policy of the stack
+	// that created the object and the weakref respectively.
+	mustNotRaise(fooType.Call(f, nil, nil))
+	o := newObject(ObjectType)
+	r := mustNotRaise(WeakRefType.Call(f, Args{o, recordPolicy}, nil))
+	o = nil
+	timeout := time.After(time.Second)
+	for i := 0; i < 2; {
+		runtime.GC()
+		select {
+		case got := <-policies:
+			if got != p {
+				t.Errorf("finalizer ran with Policy %v, want %v", got, p)
+			}
+			i++
+		case <-timeout:
+			t.Fatal("finalizers were not called")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	runtime.KeepAlive(r)
+}
+
+func TestNativeFuncPackage(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"os/exec.Command", "os/exec"},
+		{"os.(*File).Close", "os"},
+		{"github.com/foo/bar.Baz.func1", "github.com/foo/bar"},
+		{"main.main", "main"},
+		{"nodot", "nodot"},
+	}
+	for _, cas := range cases {
+		if got := nativeFuncPackage(cas.name); got != cas.want {
+			t.Errorf("nativeFuncPackage(%q) = %q, want %q", cas.name, got, cas.want)
+		}
+	}
+}
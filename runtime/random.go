@@ -0,0 +1,340 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// Parameters of the MT19937 Mersenne Twister, see:
+	// http://www.math.sci.hiroshima-u.ac.jp/~m-mat/MT/emt.html
+	mtN         = 624
+	mtM         = 397
+	mtMatrixA   = 0x9908b0df
+	mtUpperMask = 0x80000000
+	mtLowerMask = 0x7fffffff
+)
+
+// RandomType is the object representing the Python '_random.Random' type.
+var RandomType = newBasisType("Random", reflect.TypeOf(Random{}), toRandomUnsafe, ObjectType)
+
+// Random represents Python '_random.Random' objects. It is a Mersenne Twister
+// that produces the same sequences as CPython's generator for the same seed.
+type Random struct {
+	Object
+	mutex sync.Mutex
+	state [mtN]uint32
+	index int
+}
+
+func toRandomUnsafe(o *Object) *Random {
+	return (*Random)(o.toPointer())
+}
+
+// ToObject upcasts r to an Object.
+func (r *Random) ToObject() *Object {
+	return &r.Object
+}
+
+// initGenRand initializes the state from a single 32 bit seed. r.mutex must
+// be held.
+func (r *Random) initGenRand(s uint32) {
+	r.state[0] = s
+	for i := 1; i < mtN; i++ {
+		r.state[i] = 1812433253*(r.state[i-1]^(r.state[i-1]>>30)) + uint32(i)
+	}
+	r.index = mtN
+}
+
+// initByArray initializes the state from a seed of arbitrary length. r.mutex
+// must be held.
+func (r *Random) initByArray(key []uint32) {
+	r.initGenRand(19650218)
+	i, j := 1, 0
+	k := mtN
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		r.state[i] = (r.state[i] ^ ((r.state[i-1] ^ (r.state[i-1] >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= mtN {
+			r.state[0] = r.state[mtN-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = mtN - 1; k > 0; k-- {
+		r.state[i] = (r.state[i] ^ ((r.state[i-1] ^ (r.state[i-1] >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= mtN {
+			r.state[0] = r.state[mtN-1]
+			i = 1
+		}
+	}
+	// MSB is 1, assuring a non-zero initial state.
+	r.state[0] = 0x80000000
+}
+
+// genRandUint32 returns the next 32 bit value from the generator. r.mutex
+// must be held.
+func (r *Random) genRandUint32() uint32 {
+	mag01 := [2]uint32{0, mtMatrixA}
+	if r.index >= mtN {
+		kk := 0
+		for ; kk < mtN-mtM; kk++ {
+			y := (r.state[kk] & mtUpperMask) | (r.state[kk+1] & mtLowerMask)
+			r.state[kk] = r.state[kk+mtM] ^ (y >> 1) ^ mag01[y&1]
+		}
+		for ; kk < mtN-1; kk++ {
+			y := (r.state[kk] & mtUpperMask) | (r.state[kk+1] & mtLowerMask)
+			r.state[kk] = r.state[kk+mtM-mtN] ^ (y >> 1) ^ mag01[y&1]
+		}
+		y := (r.state[mtN-1] & mtUpperMask) | (r.state[0] & mtLowerMask)
+		r.state[mtN-1] = r.state[mtM-1] ^ (y >> 1) ^ mag01[y&1]
+		r.index = 0
+	}
+	y := r.state[r.index]
+	r.index++
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// Float returns the next float in the range [0.0, 1.0) with 53 bits of
+// precision, exactly as CPython's random.random() does.
+func (r *Random) Float() float64 {
+	r.mutex.Lock()
+	a, b := r.genRandUint32()>>5, r.genRandUint32()>>6
+	r.mutex.Unlock()
+	return (float64(a)*67108864.0 + float64(b)) * (1.0 / 9007199254740992.0)
+}
+
+// Seed initializes the generator from the absolute value of n the same way
+// CPython does: the 32 bit words of n, least significant first, are the key.
+func (r *Random) Seed(n *big.Int) {
+	b := new(big.Int).Abs(n).Bytes()
+	key := make([]uint32, (len(b)+3)/4)
+	for i := range b {
+		key[i/4] |= uint32(b[len(b)-1-i]) << (8 * uint(i%4))
+	}
+	if len(key) == 0 {
+		key = []uint32{0}
+	}
+	r.mutex.Lock()
+	r.initByArray(key)
+	r.mutex.Unlock()
+}
+
+func randomGetRandBits(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "getrandbits", args, RandomType, IntType); raised != nil {
+		return nil, raised
+	}
+	r, k := toRandomUnsafe(args[0]), toIntUnsafe(args[1]).Value()
+	if k <= 0 {
+		return nil, f.RaiseType(ValueErrorType, "number of bits must be greater than zero")
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if k <= 32 {
+		return NewLong(big.NewInt(int64(r.genRandUint32() >> uint(32-k)))).ToObject(), nil
+	}
+	// Fill b with 32 bit words, most significant first, discarding the
+	// excess low order bits of the final (most significant) word.
+	numWords := (k-1)/32 + 1
+	b := make([]byte, numWords*4)
+	for i := 0; i < numWords; i, k = i+1, k-32 {
+		w := r.genRandUint32()
+		if k < 32 {
+			w >>= uint(32 - k)
+		}
+		j := len(b) - 4*(i+1)
+		b[j], b[j+1], b[j+2], b[j+3] = byte(w>>24), byte(w>>16), byte(w>>8), byte(w)
+	}
+	return NewLongFromBytes(b).ToObject(), nil
+}
+
+func randomGetState(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "getstate", args, RandomType); raised != nil {
+		return nil, raised
+	}
+	r := toRandomUnsafe(args[0])
+	elems := make([]*Object, mtN+1)
+	r.mutex.Lock()
+	for i, word := range r.state {
+		elems[i] = NewLong(big.NewInt(int64(word))).ToObject()
+	}
+	elems[mtN] = NewLong(big.NewInt(int64(r.index))).ToObject()
+	r.mutex.Unlock()
+	return NewTuple(elems...).ToObject(), nil
+}
+
+func randomJumpAhead(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "jumpahead", args, RandomType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	r, o := toRandomUnsafe(args[0]), args[1]
+	var n *big.Int
+	switch {
+	case o.isInstance(IntType):
+		n = big.NewInt(int64(toIntUnsafe(o).Value()))
+	case o.isInstance(LongType):
+		n = toLongUnsafe(o).Value()
+	default:
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("jumpahead requires an integer, not '%s'", o.typ.Name()))
+	}
+	r.mutex.Lock()
+	var j, divisor big.Int
+	for i := mtN - 1; i > 1; i-- {
+		divisor.SetInt64(int64(i))
+		k := j.Mod(n, &divisor).Int64()
+		r.state[i], r.state[k] = r.state[k], r.state[i]
+	}
+	for i := range r.state {
+		r.state[i] += uint32(i + 1)
+	}
+	r.index = mtN
+	r.mutex.Unlock()
+	return None, nil
+}
+
+func randomNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	if t == RandomType && len(kwargs) > 0 {
+		return nil, f.RaiseType(TypeErrorType, "Random() does not take keyword arguments")
+	}
+	o := newObject(t)
+	if _, raised := randomSeed(f, append(Args{o}, args...), nil); raised != nil {
+		return nil, raised
+	}
+	return o, nil
+}
+
+func randomRandom(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "random", args, RandomType); raised != nil {
+		return nil, raised
+	}
+	return NewFloat(toRandomUnsafe(args[0]).Float()).ToObject(), nil
+}
+
+func randomSeed(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	argc := len(args)
+	if argc > 2 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("seed expected at most 1 arguments, got %d", argc-1))
+	}
+	expectedTypes := []*Type{RandomType, ObjectType}
+	if argc == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "seed", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	r := toRandomUnsafe(args[0])
+	if argc == 1 || args[1] == None {
+		// Seed with enough bytes to span the whole state, falling back to
+		// the time if the system has no source of randomness.
+		key := make([]uint32, mtN)
+		b := make([]byte, 4*mtN)
+		if _, err := rand.Read(b); err != nil {
+			key = []uint32{uint32(time.Now().UnixNano())}
+		} else {
+			for i := range key {
+				key[i] = uint32(b[4*i]) | uint32(b[4*i+1])<<8 | uint32(b[4*i+2])<<16 | uint32(b[4*i+3])<<24
+			}
+		}
+		r.mutex.Lock()
+		r.initByArray(key)
+		r.mutex.Unlock()
+		return None, nil
+	}
+	o := args[1]
+	switch {
+	case o.isInstance(IntType):
+		r.Seed(big.NewInt(int64(toIntUnsafe(o).Value())))
+	case o.isInstance(LongType):
+		r.Seed(toLongUnsafe(o).Value())
+	default:
+		h, raised := Hash(f, o)
+		if raised != nil {
+			return nil, raised
+		}
+		r.Seed(big.NewInt(int64(h.Value())))
+	}
+	return None, nil
+}
+
+func randomSetState(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "setstate", args, RandomType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	r, o := toRandomUnsafe(args[0]), args[1]
+	if !o.isInstance(TupleType) {
+		return nil, f.RaiseType(TypeErrorType, "state vector must be a tuple")
+	}
+	elems := toTupleUnsafe(o).elems
+	if len(elems) != mtN+1 {
+		return nil, f.RaiseType(ValueErrorType, "state vector is the wrong size")
+	}
+	var words [mtN + 1]uint64
+	for i, elem := range elems {
+		var n *big.Int
+		switch {
+		case elem.isInstance(IntType):
+			n = big.NewInt(int64(toIntUnsafe(elem).Value()))
+		case elem.isInstance(LongType):
+			n = toLongUnsafe(elem).Value()
+		default:
+			return nil, f.RaiseType(TypeErrorType, "an integer is required")
+		}
+		if n.Sign() < 0 {
+			return nil, f.RaiseType(OverflowErrorType, "can't convert negative value to unsigned long")
+		}
+		if n.BitLen() > 64 {
+			return nil, f.RaiseType(OverflowErrorType, "long int too large to convert")
+		}
+		words[i] = n.Uint64()
+	}
+	if words[mtN] > mtN {
+		return nil, f.RaiseType(ValueErrorType, "invalid state")
+	}
+	r.mutex.Lock()
+	for i := range r.state {
+		r.state[i] = uint32(words[i])
+	}
+	r.index = int(words[mtN])
+	r.mutex.Unlock()
+	return None, nil
+}
+
+func initRandomType(dict map[string]*Object) {
+	dict["__module__"] = NewStr("_random").ToObject()
+	dict["getrandbits"] = newBuiltinFunction("getrandbits", randomGetRandBits).ToObject()
+	dict["getstate"] = newBuiltinFunction("getstate", randomGetState).ToObject()
+	dict["jumpahead"] = newBuiltinFunction("jumpahead", randomJumpAhead).ToObject()
+	dict["random"] = newBuiltinFunction("random", randomRandom).ToObject()
+	dict["seed"] = newBuiltinFunction("seed", randomSeed).ToObject()
+	dict["setstate"] = newBuiltinFunction("setstate", randomSetState).ToObject()
+	RandomType.slots.New = &newSlot{randomNew}
+}
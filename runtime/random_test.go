@@ -0,0 +1,142 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The expected values in these tests were produced by CPython 2.7's _random
+// module.
+
+func TestRandomSeed(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, seed *Object) (*Object, *BaseException) {
+		r, raised := RandomType.Call(f, Args{seed}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewFloat(toRandomUnsafe(r).Float()).ToObject(), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(42), want: NewFloat(0.6394267984578837).ToObject()},
+		{args: wrapArgs(0), want: NewFloat(0.8444218515250481).ToObject()},
+		{args: wrapArgs(-1), want: NewFloat(0.13436424411240122).ToObject()},
+		{args: wrapArgs(NewLong(new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1)))), want: NewFloat(0.10175875467846374).ToObject()},
+		{args: wrapArgs(NewList()), wantExc: mustCreateException(TypeErrorType, "unhashable type: 'list'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	if _, raised := RandomType.Call(NewRootFrame(), wrapArgs(1, 2), nil); raised == nil || !raised.isInstance(TypeErrorType) {
+		t.Errorf("Random(1, 2) raised %v, want TypeError", raised)
+	}
+}
+
+func TestRandomMethods(t *testing.T) {
+	// fun calls the named method of a Random seeded with 42 one or more
+	// times, once for each element of args.
+	fun := wrapFuncForTest(func(f *Frame, name string, args ...*Object) (*Object, *BaseException) {
+		r, raised := RandomType.Call(f, wrapArgs(42), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		method, raised := GetAttr(f, r, NewStr(name), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if len(args) == 0 {
+			return method.Call(f, nil, nil)
+		}
+		results := make([]*Object, len(args))
+		for i, arg := range args {
+			if results[i], raised = method.Call(f, Args{arg}, nil); raised != nil {
+				return nil, raised
+			}
+		}
+		return NewTuple(results...).ToObject(), nil
+	})
+	big100, _ := new(big.Int).SetString("176140902141063639299770569303", 10)
+	cases := []invokeTestCase{
+		{args: wrapArgs("random"), want: NewFloat(0.6394267984578837).ToObject()},
+		{args: wrapArgs("getrandbits", 1, 32, 33, 100), want: newTestTuple(NewLong(big.NewInt(1)), NewLong(big.NewInt(478163327)), NewLong(big.NewInt(4402387665)), NewLong(big100)).ToObject()},
+		{args: wrapArgs("getrandbits", 0), wantExc: mustCreateException(ValueErrorType, "number of bits must be greater than zero")},
+		{args: wrapArgs("jumpahead", "foo"), wantExc: mustCreateException(TypeErrorType, "jumpahead requires an integer, not 'str'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestRandomJumpAhead(t *testing.T) {
+	f := NewRootFrame()
+	cases := []struct {
+		n    int
+		want float64
+	}{
+		{12345, 0.7401130258205925},
+		{-3, 0.5576244844129775},
+	}
+	for _, cas := range cases {
+		r := mustNotRaise(RandomType.Call(f, wrapArgs(42), nil))
+		jumpAhead := mustNotRaise(GetAttr(f, r, NewStr("jumpahead"), nil))
+		mustNotRaise(jumpAhead.Call(f, wrapArgs(cas.n), nil))
+		if got := toRandomUnsafe(r).Float(); got != cas.want {
+			t.Errorf("jumpahead(%d); random() = %v, want %v", cas.n, got, cas.want)
+		}
+	}
+}
+
+func TestRandomState(t *testing.T) {
+	f := NewRootFrame()
+	r := mustNotRaise(RandomType.Call(f, wrapArgs(42), nil))
+	getState := mustNotRaise(GetAttr(f, r, NewStr("getstate"), nil))
+	setState := mustNotRaise(GetAttr(f, r, NewStr("setstate"), nil))
+	state := toTupleUnsafe(mustNotRaise(getState.Call(f, nil, nil)))
+	for _, want := range []struct {
+		i    int
+		word int64
+	}{{0, 2147483648}, {1, 3564348608}, {623, 3831079317}, {624, 624}} {
+		if got := toLongUnsafe(state.elems[want.i]).Value().Int64(); got != want.word {
+			t.Errorf("getstate()[%d] = %d, want %d", want.i, got, want.word)
+		}
+	}
+	first := toRandomUnsafe(r).Float()
+	mustNotRaise(setState.Call(f, Args{state.ToObject()}, nil))
+	if second := toRandomUnsafe(r).Float(); second != first {
+		t.Errorf("random() after setstate(getstate()) = %v, want %v", second, first)
+	}
+	badIndex := make([]*Object, mtN+1)
+	copy(badIndex, state.elems)
+	badIndex[mtN] = NewInt(mtN + 1).ToObject()
+	negative := make([]*Object, mtN+1)
+	copy(negative, state.elems)
+	negative[0] = NewInt(-1).ToObject()
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewList()), wantExc: mustCreateException(TypeErrorType, "state vector must be a tuple")},
+		{args: wrapArgs(newTestTuple(1, 2, 3)), wantExc: mustCreateException(ValueErrorType, "state vector is the wrong size")},
+		{args: wrapArgs(NewTuple(badIndex...)), wantExc: mustCreateException(ValueErrorType, "invalid state")},
+		{args: wrapArgs(NewTuple(negative...)), wantExc: mustCreateException(OverflowErrorType, "can't convert negative value to unsigned long")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(setState, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -165,12 +165,8 @@ func xrangeGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
 		format := "sequence index must be integer, not '%s'"
 		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, key.typ.Name()))
 	}
-	i, raised := IndexInt(f, key)
-	if raised != nil {
-		return nil, raised
-	}
 	r := toXRangeUnsafe(o)
-	i, raised = seqCheckedIndex(f, (r.stop-r.start)/r.step, i)
+	i, raised := seqIndex(f, (r.stop-r.start)/r.step, key)
 	if raised != nil {
 		return nil, raised
 	}
@@ -188,24 +184,25 @@ func xrangeLen(f *Frame, o *Object) (*Object, *BaseException) {
 }
 
 func xrangeNew(f *Frame, _ *Type, args Args, _ KWArgs) (*Object, *BaseException) {
-	expectedTypes := []*Type{IntType, IntType, IntType}
 	argc := len(args)
-	if argc > 0 && argc < 3 {
-		expectedTypes = expectedTypes[:argc]
+	if argc == 0 || argc > 3 {
+		return nil, f.RaiseType(TypeErrorType, "'__new__' of 'int' requires 3 arguments")
 	}
-	if raised := checkMethodArgs(f, "__new__", args, expectedTypes...); raised != nil {
-		return nil, raised
+	values := []int{0, 0, 1}
+	for i, arg := range args {
+		if !arg.isInstance(IntType) && !arg.isInstance(LongType) {
+			return nil, f.RaiseType(TypeErrorType, "an integer is required")
+		}
+		v, raised := indexIntChecked(f, arg, OverflowErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		values[i] = v
 	}
-	start, stop, step := 0, 0, 1
 	if argc == 1 {
-		stop = toIntUnsafe(args[0]).Value()
-	} else {
-		start = toIntUnsafe(args[0]).Value()
-		stop = toIntUnsafe(args[1]).Value()
-		if argc > 2 {
-			step = toIntUnsafe(args[2]).Value()
-		}
+		values[0], values[1] = 0, values[0]
 	}
+	start, stop, step := values[0], values[1], values[2]
 	stop, _, result := seqRange(start, stop, step)
 	switch result {
 	case seqRangeZeroStep:
@@ -15,6 +15,7 @@
 package grumpy
 
 import (
+	"math/big"
 	"testing"
 )
 
@@ -76,6 +77,8 @@ func TestXRangeGetItem(t *testing.T) {
 		{args: wrapArgs(newTestXRange(10, 12), 1), want: NewInt(11).ToObject()},
 		{args: wrapArgs(newTestXRange(5, -2, -3), 2), want: NewInt(-1).ToObject()},
 		{args: wrapArgs(newTestXRange(3), 100), wantExc: mustCreateException(IndexErrorType, "index out of range")},
+		{args: wrapArgs(newTestXRange(3), NewLong(big.NewInt(-1))), want: NewInt(2).ToObject()},
+		{args: wrapArgs(newTestXRange(3), new(big.Int).Lsh(big.NewInt(1), 100)), wantExc: mustCreateException(IndexErrorType, "cannot fit 'long' into an index-sized integer")},
 		{args: wrapArgs(newTestXRange(5), newTestSlice(1, 3)), wantExc: mustCreateException(TypeErrorType, "sequence index must be integer, not 'slice'")},
 	}
 	for _, cas := range cases {
@@ -120,6 +123,10 @@ func TestXRangeNew(t *testing.T) {
 		{args: wrapArgs(-26, MinInt), want: NewList().ToObject()},
 		{args: wrapArgs(1, 2, 0), wantExc: mustCreateException(ValueErrorType, "xrange() arg 3 must not be zero")},
 		{args: wrapArgs(0, MinInt, -1), wantExc: mustCreateException(OverflowErrorType, "result too large")},
+		{args: wrapArgs(NewLong(big.NewInt(3))), want: newTestList(0, 1, 2).ToObject()},
+		{args: wrapArgs(1, NewLong(big.NewInt(3))), want: newTestList(1, 2).ToObject()},
+		{args: wrapArgs(new(big.Int).Lsh(big.NewInt(1), 100)), wantExc: mustCreateException(OverflowErrorType, "cannot fit 'long' into an index-sized integer")},
+		{args: wrapArgs(1.5), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -0,0 +1,128 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+)
+
+const regexpCacheSize = 128
+
+// regexpCacheEntry is the value held by each element of a regexpCache's
+// recency list.
+type regexpCacheEntry struct {
+	pattern string
+	re      *regexp.Regexp
+	hits    int
+}
+
+// regexpCacheStats reports how often a regexpCache was able to reuse a
+// compiled pattern.
+type regexpCacheStats struct {
+	hits      int
+	misses    int
+	evictions int
+	// patternHits maps the cached patterns to the number of times each
+	// was reused since it was compiled.
+	patternHits map[string]int
+}
+
+// regexpCache holds the most recently used compiled regexps so that
+// patterns built at runtime, e.g. by complex() or the re module, aren't
+// recompiled on every use.
+type regexpCache struct {
+	mutex     sync.Mutex
+	capacity  int
+	entries   map[string]*list.Element
+	recency   *list.List
+	hits      int
+	misses    int
+	evictions int
+}
+
+// compiledRegexps is the regexp cache shared by the runtime.
+var compiledRegexps = newRegexpCache(regexpCacheSize)
+
+func newRegexpCache(capacity int) *regexpCache {
+	return &regexpCache{capacity: capacity, entries: map[string]*list.Element{}, recency: list.New()}
+}
+
+// compile returns the compiled form of pattern, compiling and caching it if
+// it's not already present. When the cache is full the least recently used
+// pattern is discarded. Patterns that fail to compile are not cached.
+func (c *regexpCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mutex.Lock()
+	if e, ok := c.entries[pattern]; ok {
+		c.recency.MoveToFront(e)
+		entry := e.Value.(*regexpCacheEntry)
+		entry.hits++
+		c.hits++
+		c.mutex.Unlock()
+		return entry.re, nil
+	}
+	c.misses++
+	c.mutex.Unlock()
+	// Compile outside the lock since it may be slow. Concurrent misses on
+	// the same pattern compile it more than once but only one survives.
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.entries[pattern]; ok {
+		c.recency.MoveToFront(e)
+		return e.Value.(*regexpCacheEntry).re, nil
+	}
+	c.entries[pattern] = c.recency.PushFront(&regexpCacheEntry{pattern: pattern, re: re})
+	for c.recency.Len() > c.capacity {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.entries, oldest.Value.(*regexpCacheEntry).pattern)
+		c.evictions++
+	}
+	return re, nil
+}
+
+// mustCompile is like compile but panics if pattern is invalid. It's
+// intended for patterns constructed by the runtime itself.
+func (c *regexpCache) mustCompile(pattern string) *regexp.Regexp {
+	re, err := c.compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// purge discards all cached patterns and resets the statistics.
+func (c *regexpCache) purge() {
+	c.mutex.Lock()
+	c.entries = map[string]*list.Element{}
+	c.recency.Init()
+	c.hits, c.misses, c.evictions = 0, 0, 0
+	c.mutex.Unlock()
+}
+
+func (c *regexpCache) stats() regexpCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s := regexpCacheStats{hits: c.hits, misses: c.misses, evictions: c.evictions, patternHits: make(map[string]int, len(c.entries))}
+	for pattern, e := range c.entries {
+		s.patternHits[pattern] = e.Value.(*regexpCacheEntry).hits
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRegexpCacheCompile(t *testing.T) {
+	c := newRegexpCache(2)
+	foo, err := c.compile("fo+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !foo.MatchString("foo") {
+		t.Errorf(`compile("fo+") does not match "foo"`)
+	}
+	if got, _ := c.compile("fo+"); got != foo {
+		t.Errorf(`compile("fo+") = %v, want the cached %v`, got, foo)
+	}
+	if _, err := c.compile("(unclosed"); err == nil {
+		t.Errorf(`compile("(unclosed") succeeded, want error`)
+	}
+	want := regexpCacheStats{hits: 1, misses: 2, patternHits: map[string]int{"fo+": 1}}
+	if got := c.stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegexpCacheEviction(t *testing.T) {
+	c := newRegexpCache(2)
+	a := c.mustCompile("a")
+	c.mustCompile("b")
+	// Using "a" makes "b" the least recently used pattern.
+	c.mustCompile("a")
+	c.mustCompile("c")
+	want := regexpCacheStats{hits: 1, misses: 3, evictions: 1, patternHits: map[string]int{"a": 1, "c": 0}}
+	if got := c.stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+	if got := c.mustCompile("a"); got != a {
+		t.Errorf(`mustCompile("a") = %v, want the cached %v`, got, a)
+	}
+	c.purge()
+	want = regexpCacheStats{patternHits: map[string]int{}}
+	if got := c.stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stats() after purge() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegexpCacheConcurrent(t *testing.T) {
+	c := newRegexpCache(4)
+	patterns := []string{"a", "b+", "c*", "d?", "e|f", "[gh]"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pattern := patterns[j%len(patterns)]
+				if re := c.mustCompile(pattern); re.String() != pattern {
+					t.Errorf("mustCompile(%q) = %v", pattern, re)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	s := c.stats()
+	if s.hits+s.misses != 800 {
+		t.Errorf("hits + misses = %d, want 800", s.hits+s.misses)
+	}
+	if len(s.patternHits) != 4 {
+		t.Errorf("%d patterns cached, want 4", len(s.patternHits))
+	}
+}
+
+func TestRegexpCacheMustCompilePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`mustCompile("*") did not panic`)
+		}
+	}()
+	newRegexpCache(1).mustCompile("*")
+}
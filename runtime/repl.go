@@ -0,0 +1,145 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const (
+	replFilename = "<stdin>"
+	replPS1      = ">>> "
+	replPS2      = "... "
+)
+
+// RunInteractive runs an interactive interpreter. Statements are read from
+// sys.stdin and executed in the namespace of the __main__ module and the repr
+// of the values of expression statements is printed. Statements are
+// interpreted like the source given to exec so only modules linked into the
+// program can be imported. When code is not nil it is first run as __main__,
+// like python -i. The return value is zero when the end of the input is
+// reached, otherwise it is the exit status for the SystemExit that was raised,
+// as described for RunMain. sys.exitfunc is called before returning.
+func RunInteractive(code *Code) (status int) {
+	var f *Frame
+	if code == nil {
+		f = newMainFrame(replFilename)
+		if _, raised := f.globals.DelItemString(f, "__file__"); raised != nil {
+			f.RestoreExc(nil, nil)
+		}
+	} else {
+		f = newMainFrame(code.filename)
+		f.code = code
+	}
+	defer flushStdStreams(f)
+	defer func() {
+		status = runExitFunc(f, status)
+	}()
+	if code != nil {
+		_, raised := code.fn(f, nil)
+		if raised != nil && replShowException(f, raised) {
+			return exitStatus(f, raised)
+		}
+	}
+	writeStderr(f, fmt.Sprintf("Grumpy on Go %s\nPress Ctrl-D to exit.\n", runtime.Version()))
+	for {
+		stmt, raised := replRead(f)
+		if raised == nil {
+			p := &evalProgram{filename: replFilename, code: stmt, interactive: true}
+			_, raised = p.eval(f, f.globals, nil)
+		}
+		printFinishLine(f)
+		if raised != nil {
+			if raised.isInstance(EOFErrorType) && stmt == nil {
+				f.RestoreExc(nil, nil)
+				writeStderr(f, "\n")
+				return 0
+			}
+			if replShowException(f, raised) {
+				return exitStatus(f, raised)
+			}
+		}
+	}
+}
+
+// replShowException writes the traceback for e to sys.stderr and clears it
+// unless e is a SystemExit, in which case true is returned.
+func replShowException(f *Frame, e *BaseException) bool {
+	if e.isInstance(SystemExitType) {
+		return true
+	}
+	writeStderr(f, FormatExc(f))
+	f.RestoreExc(nil, nil)
+	return false
+}
+
+// replRead prompts for and reads lines from sys.stdin until they form a
+// complete statement, which is returned. Compound statements are terminated
+// by a blank line.
+func replRead(f *Frame) (*evalCode, *BaseException) {
+	var lines []string
+	for {
+		prompt := replPS1
+		if len(lines) > 0 {
+			prompt = replPS2
+		}
+		o, raised := builtinRawInput(f, Args{NewStr(prompt).ToObject()}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		line := toStrUnsafe(o).Value()
+		blank := strings.TrimSpace(line) == ""
+		if blank && len(lines) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+		code, err := evalParseExec(strings.Join(lines, "\n") + "\n")
+		if err != nil {
+			if replIncomplete(err, len(lines), blank) {
+				continue
+			}
+			return nil, err.raise(f, replFilename)
+		}
+		if blank || !replCompound(code) {
+			return code, nil
+		}
+	}
+}
+
+// replIncomplete returns true if err was caused by reaching the end of the n
+// lines read so far, meaning that more input is required.
+func replIncomplete(err *evalSyntaxError, n int, blank bool) bool {
+	switch err.msg {
+	case "unexpected EOF while parsing", "EOF while scanning triple-quoted string literal":
+		return true
+	case "expected an indented block":
+		return !blank && err.line > n
+	}
+	return false
+}
+
+// replCompound returns true if code contains a statement with a body, which
+// may be continued on following lines.
+func replCompound(code *evalCode) bool {
+	for _, stmt := range code.body {
+		switch stmt.(type) {
+		case *evalIf, *evalWhile, *evalFor, *evalFunctionDef:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunInteractive(t *testing.T) {
+	mainCode := NewCode("<module>", "main.py", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		return nil, f.Globals().SetItemString(f, "y", NewInt(5).ToObject())
+	})
+	cases := []struct {
+		code       *Code
+		input      string
+		wantCode   int
+		wantOutput string
+		wantErrors []string
+	}{
+		{nil, "x = 2\n\nx * 3\nNone\n", 0, ">>> >>> >>> 6\n>>> >>> ", nil},
+		{nil, "if True:\n  print 'yes'\n\n", 0, ">>> ... ... yes\n>>> ", nil},
+		{nil, "def f(a):\n  return a + 1\n\nf(1)\n", 0, ">>> ... ... >>> 2\n>>> ", nil},
+		{nil, "(1,\n\n 2)\n'''a\nb'''\n", 0, ">>> ... ... (1, 2)\n>>> ... 'a\\nb'\n>>> ", nil},
+		{nil, "print 'a',\n1\n_ + 1\n", 0, ">>> a\n>>> 1\n>>> 2\n>>> ", nil},
+		{nil, "1 +\nfoo\nif True:\n\n", 0, ">>> >>> >>> ... >>> ", []string{
+			"SyntaxError: invalid syntax (<stdin>, line 1)",
+			"File \"<stdin>\", line 1, in <module>\nNameError: name 'foo' is not defined",
+			"SyntaxError: expected an indented block (<stdin>, line 3)",
+		}},
+		{nil, "raise SystemExit(3)\n1\n", 3, ">>> ", nil},
+		{nil, "__name__, '__file__' in globals()\n", 0, ">>> ('__main__', False)\n>>> ", nil},
+		{mainCode, "y\n", 0, ">>> 5\n>>> ", nil},
+	}
+	defer Builtins.DelItemString(NewRootFrame(), "_")
+	for _, cas := range cases {
+		var stdout, stderr bytes.Buffer
+		restoreStdin := Stdin.Redirect(strings.NewReader(cas.input), nil)
+		restoreStdout := Stdout.Redirect(nil, &stdout)
+		restoreStderr := Stderr.Redirect(nil, &stderr)
+		oldSysModules := SysModules
+		SysModules = NewDict()
+		gotCode := RunInteractive(cas.code)
+		SysModules = oldSysModules
+		restoreStderr()
+		restoreStdout()
+		restoreStdin()
+		if gotCode != cas.wantCode {
+			t.Errorf("RunInteractive(%q) = %d, want %d", cas.input, gotCode, cas.wantCode)
+		}
+		if got := stdout.String(); got != cas.wantOutput {
+			t.Errorf("RunInteractive(%q) output %q, want %q", cas.input, got, cas.wantOutput)
+		}
+		for _, want := range cas.wantErrors {
+			if got := stderr.String(); !strings.Contains(got, want) {
+				t.Errorf("RunInteractive(%q) errors %q, want to contain %q", cas.input, got, want)
+			}
+		}
+	}
+}
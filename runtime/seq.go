@@ -23,6 +23,10 @@ import (
 	"sync"
 )
 
+// seqLengthHintMax bounds the preallocation performed based on the result of
+// seqLengthHint, which is not trusted for arbitrary objects.
+const seqLengthHintMax = 1 << 16
+
 var (
 	seqIteratorType = newBasisType("iterator", reflect.TypeOf(seqIterator{}), toSeqIteratorUnsafe, ObjectType)
 )
@@ -32,7 +36,16 @@ func seqAdd(f *Frame, elems1, elems2 []*Object) ([]*Object, *BaseException) {
 		// This indicates an int overflow.
 		return nil, f.RaiseType(OverflowErrorType, errResultTooLarge)
 	}
-	return append(elems1, elems2...), nil
+	if raised := f.checkAlloc(len(elems1) + len(elems2)); raised != nil {
+		return nil, raised
+	}
+	// Always allocate a new slice since elems1 may have spare capacity
+	// that is shared with other sequences.
+	n1 := len(elems1)
+	result := make([]*Object, n1+len(elems2))
+	copy(result, elems1)
+	copy(result[n1:], elems2)
+	return result, nil
 }
 
 func seqCompare(f *Frame, elems1, elems2 []*Object, cmp binaryOpFunc) (*Object, *BaseException) {
@@ -75,8 +88,12 @@ func seqApply(f *Frame, seq *Object, fun func([]*Object, bool) *BaseException) *
 	case seq.typ == TupleType:
 		return fun(toTupleUnsafe(seq).elems, true)
 	default:
-		elems := []*Object{}
-		raised := seqForEach(f, seq, func(elem *Object) *BaseException {
+		n, raised := seqLengthHint(f, seq)
+		if raised != nil {
+			return raised
+		}
+		elems := make([]*Object, 0, n)
+		raised = seqForEach(f, seq, func(elem *Object) *BaseException {
 			elems = append(elems, elem)
 			return nil
 		})
@@ -87,6 +104,44 @@ func seqApply(f *Frame, seq *Object, fun func([]*Object, bool) *BaseException) *
 	}
 }
 
+// seqLengthHint returns the number of elements seq is expected to produce
+// when iterated, or 0 if that is unknown. Like CPython's
+// _PyObject_LengthHint, TypeError and AttributeError raised by __len__ are
+// ignored. The result is only used to preallocate storage so it is capped at
+// seqLengthHintMax.
+func seqLengthHint(f *Frame, seq *Object) (int, *BaseException) {
+	if seq.typ.slots.Len == nil {
+		return 0, nil
+	}
+	n, raised := Len(f, seq)
+	if raised != nil {
+		if !raised.isInstance(TypeErrorType) && !raised.isInstance(AttributeErrorType) {
+			return 0, raised
+		}
+		f.RestoreExc(nil, nil)
+		return 0, nil
+	}
+	switch v := n.Value(); {
+	case v < 0:
+		return 0, nil
+	case v > seqLengthHintMax:
+		return seqLengthHintMax, nil
+	default:
+		return v, nil
+	}
+}
+
+// seqIndex converts key to an index into a sequence of length seqLen,
+// counting from the end of the sequence when negative. It raises IndexError
+// when key is out of range, including longs that don't fit in an int.
+func seqIndex(f *Frame, seqLen int, key *Object) (int, *BaseException) {
+	i, raised := indexIntChecked(f, key, IndexErrorType)
+	if raised != nil {
+		return 0, raised
+	}
+	return seqCheckedIndex(f, seqLen, i)
+}
+
 func seqCheckedIndex(f *Frame, seqLen, index int) (int, *BaseException) {
 	if index < 0 {
 		index = seqLen + index
@@ -214,11 +269,7 @@ func seqForEach(f *Frame, iterable *Object, callback func(*Object) *BaseExceptio
 func seqGetItem(f *Frame, elems []*Object, index *Object) (*Object, []*Object, *BaseException) {
 	switch {
 	case index.typ.slots.Index != nil:
-		i, raised := IndexInt(f, index)
-		if raised != nil {
-			return nil, nil, raised
-		}
-		i, raised = seqCheckedIndex(f, len(elems), i)
+		i, raised := seqIndex(f, len(elems), index)
 		if raised != nil {
 			return nil, nil, raised
 		}
@@ -249,6 +300,9 @@ func seqMul(f *Frame, elems []*Object, n int) ([]*Object, *BaseException) {
 		return nil, f.RaiseType(OverflowErrorType, errResultTooLarge)
 	}
 	newNumElems := numElems * n
+	if raised := f.checkAlloc(newNumElems); raised != nil {
+		return nil, raised
+	}
 	resultElems := make([]*Object, newNumElems)
 	for i := 0; i < newNumElems; i++ {
 		resultElems[i] = elems[i%numElems]
@@ -263,8 +317,13 @@ func seqNew(f *Frame, args Args) ([]*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "__new__", args, ObjectType); raised != nil {
 		return nil, raised
 	}
+	return seqCopy(f, args[0])
+}
+
+// seqCopy returns a new slice holding the elements of seq.
+func seqCopy(f *Frame, seq *Object) ([]*Object, *BaseException) {
 	var result []*Object
-	raised := seqApply(f, args[0], func(elems []*Object, borrowed bool) *BaseException {
+	raised := seqApply(f, seq, func(elems []*Object, borrowed bool) *BaseException {
 		if borrowed {
 			result = make([]*Object, len(elems))
 			copy(result, elems)
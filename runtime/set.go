@@ -26,6 +26,16 @@ var (
 	SetType = newBasisType("set", reflect.TypeOf(Set{}), toSetUnsafe, ObjectType)
 )
 
+// setOp identifies one of the binary set algebra operations.
+type setOp int
+
+const (
+	setOpAnd setOp = iota
+	setOpOr
+	setOpSub
+	setOpXor
+)
+
 type setBase struct {
 	Object
 	dict *Dict
@@ -121,6 +131,10 @@ func setAdd(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	return None, nil
 }
 
+func setAnd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, SetType, setOpAnd, (*setBase)(toSetUnsafe(v)), w)
+}
+
 func setContains(f *Frame, seq, value *Object) (*Object, *BaseException) {
 	contains, raised := toSetUnsafe(seq).Contains(f, value)
 	if raised != nil {
@@ -209,6 +223,10 @@ func setNew(f *Frame, t *Type, _ Args, _ KWArgs) (*Object, *BaseException) {
 	return s.ToObject(), nil
 }
 
+func setOr(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, SetType, setOpOr, (*setBase)(toSetUnsafe(v)), w)
+}
+
 func setRemove(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "remove", args, SetType, ObjectType); raised != nil {
 		return nil, raised
@@ -226,6 +244,10 @@ func setRepr(f *Frame, o *Object) (*Object, *BaseException) {
 	return (*setBase)(toSetUnsafe(o)).repr(f)
 }
 
+func setSub(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, SetType, setOpSub, (*setBase)(toSetUnsafe(v)), w)
+}
+
 func setUpdate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "update", args, SetType, ObjectType); raised != nil {
 		return nil, raised
@@ -236,6 +258,10 @@ func setUpdate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	return None, nil
 }
 
+func setXor(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, SetType, setOpXor, (*setBase)(toSetUnsafe(v)), w)
+}
+
 func initSetType(dict map[string]*Object) {
 	dict["add"] = newBuiltinFunction("add", setAdd).ToObject()
 	dict["discard"] = newBuiltinFunction("discard", setDiscard).ToObject()
@@ -243,6 +269,7 @@ func initSetType(dict map[string]*Object) {
 	dict["issuperset"] = newBuiltinFunction("issuperset", setIsSuperset).ToObject()
 	dict["remove"] = newBuiltinFunction("remove", setRemove).ToObject()
 	dict["update"] = newBuiltinFunction("update", setUpdate).ToObject()
+	SetType.slots.And = &binaryOpSlot{setAnd}
 	SetType.slots.Contains = &binaryOpSlot{setContains}
 	SetType.slots.Eq = &binaryOpSlot{setEq}
 	SetType.slots.GE = &binaryOpSlot{setGE}
@@ -255,7 +282,10 @@ func initSetType(dict map[string]*Object) {
 	SetType.slots.LT = &binaryOpSlot{setLT}
 	SetType.slots.NE = &binaryOpSlot{setNE}
 	SetType.slots.New = &newSlot{setNew}
+	SetType.slots.Or = &binaryOpSlot{setOr}
 	SetType.slots.Repr = &unaryOpSlot{setRepr}
+	SetType.slots.Sub = &binaryOpSlot{setSub}
+	SetType.slots.Xor = &binaryOpSlot{setXor}
 }
 
 // FrozenSet represents Python 'set' objects.
@@ -275,6 +305,10 @@ func (s *FrozenSet) ToObject() *Object {
 	return &s.Object
 }
 
+func frozenSetAnd(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, FrozenSetType, setOpAnd, (*setBase)(toFrozenSetUnsafe(v)), w)
+}
+
 func frozenSetContains(f *Frame, seq, value *Object) (*Object, *BaseException) {
 	contains, raised := toFrozenSetUnsafe(seq).Contains(f, value)
 	if raised != nil {
@@ -352,13 +386,26 @@ func frozenSetNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseExcepti
 	return s.ToObject(), nil
 }
 
+func frozenSetOr(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, FrozenSetType, setOpOr, (*setBase)(toFrozenSetUnsafe(v)), w)
+}
+
 func frozenSetRepr(f *Frame, o *Object) (*Object, *BaseException) {
 	return (*setBase)(toFrozenSetUnsafe(o)).repr(f)
 }
 
+func frozenSetSub(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, FrozenSetType, setOpSub, (*setBase)(toFrozenSetUnsafe(v)), w)
+}
+
+func frozenSetXor(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return setBinaryOp(f, FrozenSetType, setOpXor, (*setBase)(toFrozenSetUnsafe(v)), w)
+}
+
 func initFrozenSetType(dict map[string]*Object) {
 	dict["issubset"] = newBuiltinFunction("issubset", frozenSetIsSubset).ToObject()
 	dict["issuperset"] = newBuiltinFunction("issuperset", frozenSetIsSuperset).ToObject()
+	FrozenSetType.slots.And = &binaryOpSlot{frozenSetAnd}
 	FrozenSetType.slots.Contains = &binaryOpSlot{frozenSetContains}
 	FrozenSetType.slots.Eq = &binaryOpSlot{frozenSetEq}
 	FrozenSetType.slots.GE = &binaryOpSlot{frozenSetGE}
@@ -371,7 +418,10 @@ func initFrozenSetType(dict map[string]*Object) {
 	FrozenSetType.slots.LT = &binaryOpSlot{frozenSetLT}
 	FrozenSetType.slots.NE = &binaryOpSlot{frozenSetNE}
 	FrozenSetType.slots.New = &newSlot{frozenSetNew}
+	FrozenSetType.slots.Or = &binaryOpSlot{frozenSetOr}
 	FrozenSetType.slots.Repr = &unaryOpSlot{frozenSetRepr}
+	FrozenSetType.slots.Sub = &binaryOpSlot{frozenSetSub}
+	FrozenSetType.slots.Xor = &binaryOpSlot{frozenSetXor}
 }
 
 func setCompare(f *Frame, op compareOp, v *setBase, w *Object) (*Object, *BaseException) {
@@ -428,6 +478,63 @@ func setCompare(f *Frame, op compareOp, v *setBase, w *Object) (*Object, *BaseEx
 	return GetBool(result).ToObject(), nil
 }
 
+// setBinaryOp computes op over the elements of v and w, returning a new
+// instance of t. If w is not a set or frozenset then NotImplemented is
+// returned.
+func setBinaryOp(f *Frame, t *Type, op setOp, v *setBase, w *Object) (*Object, *BaseException) {
+	var s2 *setBase
+	switch {
+	case w.isInstance(SetType):
+		s2 = (*setBase)(toSetUnsafe(w))
+	case w.isInstance(FrozenSetType):
+		s2 = (*setBase)(toFrozenSetUnsafe(w))
+	default:
+		return NotImplemented, nil
+	}
+	result := NewDict()
+	// addKeys inserts the keys of src into result. When filter is non-nil,
+	// only keys whose membership in filter matches want are inserted.
+	addKeys := func(src, filter *setBase, want bool) *BaseException {
+		for _, key := range src.dict.Keys(f).elems {
+			if filter != nil {
+				contains, raised := filter.contains(f, key)
+				if raised != nil {
+					return raised
+				}
+				if contains != want {
+					continue
+				}
+			}
+			if raised := result.SetItem(f, key, None); raised != nil {
+				return raised
+			}
+		}
+		return nil
+	}
+	var raised *BaseException
+	switch op {
+	case setOpAnd:
+		raised = addKeys(v, s2, true)
+	case setOpOr:
+		if raised = addKeys(v, nil, false); raised == nil {
+			raised = addKeys(s2, nil, false)
+		}
+	case setOpSub:
+		raised = addKeys(v, s2, false)
+	case setOpXor:
+		if raised = addKeys(v, s2, false); raised == nil {
+			raised = addKeys(s2, v, false)
+		}
+	}
+	if raised != nil {
+		return nil, raised
+	}
+	if t == SetType {
+		return (&Set{Object{typ: SetType}, result}).ToObject(), nil
+	}
+	return (&FrozenSet{Object{typ: FrozenSetType}, result}).ToObject(), nil
+}
+
 func setFromSeq(f *Frame, seq *Object) (*setBase, *BaseException) {
 	switch {
 	case seq.isInstance(SetType):
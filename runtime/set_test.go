@@ -84,6 +84,39 @@ func TestSetDiscard(t *testing.T) {
 	}
 }
 
+func TestSetBinaryOps(t *testing.T) {
+	cases := []struct {
+		fun     func(f *Frame, v, w *Object) (*Object, *BaseException)
+		v, w    *Object
+		want    *Object
+		wantExc *BaseException
+	}{
+		{fun: And, v: newTestSet(1, 2, 3).ToObject(), w: newTestSet(2, 3, 4).ToObject(), want: newTestSet(2, 3).ToObject()},
+		{fun: And, v: newTestFrozenSet(1, 2).ToObject(), w: newTestSet(3).ToObject(), want: newTestFrozenSet().ToObject()},
+		{fun: Or, v: newTestSet(1, 2).ToObject(), w: newTestFrozenSet(2, 3).ToObject(), want: newTestSet(1, 2, 3).ToObject()},
+		{fun: Or, v: newTestFrozenSet().ToObject(), w: newTestSet("foo").ToObject(), want: newTestFrozenSet("foo").ToObject()},
+		{fun: Sub, v: newTestSet(1, 2, 3).ToObject(), w: newTestSet(2).ToObject(), want: newTestSet(1, 3).ToObject()},
+		{fun: Sub, v: newTestFrozenSet("foo", "bar").ToObject(), w: newTestFrozenSet("foo", "bar").ToObject(), want: newTestFrozenSet().ToObject()},
+		{fun: Xor, v: newTestSet(1, 2, 3).ToObject(), w: newTestSet(3, 4).ToObject(), want: newTestSet(1, 2, 4).ToObject()},
+		{fun: Xor, v: newTestFrozenSet(1).ToObject(), w: newTestFrozenSet(1).ToObject(), want: newTestFrozenSet().ToObject()},
+		{fun: Or, v: newTestSet(1).ToObject(), w: newTestTuple(2).ToObject(), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for |: 'set' and 'tuple'")},
+		{fun: Sub, v: newTestFrozenSet(1).ToObject(), w: NewList().ToObject(), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for -: 'frozenset' and 'list'")},
+	}
+	for _, cas := range cases {
+		testCase := invokeTestCase{args: wrapArgs(cas.v, cas.w), want: cas.want, wantExc: cas.wantExc}
+		if err := runInvokeTestCase(wrapFuncForTest(cas.fun), &testCase); err != "" {
+			t.Error(err)
+		}
+		if cas.want == nil {
+			continue
+		}
+		got, raised := cas.fun(NewRootFrame(), cas.v, cas.w)
+		if raised == nil && got.typ != cas.want.typ {
+			t.Errorf("%v(%v, %v) returned %s, want %s", getFuncName(cas.fun), cas.v, cas.w, got.typ.Name(), cas.want.typ.Name())
+		}
+	}
+}
+
 func TestSetCompare(t *testing.T) {
 	modifiedSet := newTestSet(0)
 	modifiedType := newTestClass("Foo", []*Type{IntType}, newStringDict(map[string]*Object{
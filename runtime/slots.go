@@ -66,7 +66,7 @@ func (s *binaryOpSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *binaryOpSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, v, w *Object) (*Object, *BaseException) {
-		return callable.Call(f, Args{v, w}, nil)
+		return f.callArgs(callable, v, w)
 	}
 	return true
 }
@@ -86,10 +86,7 @@ func (s *callSlot) makeCallable(t *Type, _ string) *Object {
 
 func (s *callSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
-		callArgs := make(Args, len(args)+1)
-		callArgs[0] = o
-		copy(callArgs[1:], args)
-		return callable.Call(f, callArgs, kwargs)
+		return f.callPrepend(callable, o, args, kwargs)
 	}
 	return true
 }
@@ -112,7 +109,7 @@ func (s *delAttrSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *delAttrSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, name *Str) *BaseException {
-		_, raised := callable.Call(f, Args{o, name.ToObject()}, nil)
+		_, raised := f.callArgs(callable, o, name.ToObject())
 		return raised
 	}
 	return true
@@ -136,7 +133,7 @@ func (s *deleteSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *deleteSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, desc *Object, inst *Object) *BaseException {
-		_, raised := callable.Call(f, Args{desc, inst}, nil)
+		_, raised := f.callArgs(callable, desc, inst)
 		return raised
 	}
 	return true
@@ -160,7 +157,7 @@ func (s *delItemSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *delItemSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, key *Object) *BaseException {
-		_, raised := callable.Call(f, Args{o, key}, nil)
+		_, raised := f.callArgs(callable, o, key)
 		return raised
 	}
 	return true
@@ -181,7 +178,7 @@ func (s *getAttributeSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *getAttributeSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, name *Str) (*Object, *BaseException) {
-		return callable.Call(f, Args{o, name.ToObject()}, nil)
+		return f.callArgs(callable, o, name.ToObject())
 	}
 	return true
 }
@@ -192,16 +189,38 @@ type getSlot struct {
 
 func (s *getSlot) makeCallable(t *Type, slotName string) *Object {
 	return newBuiltinFunction(slotName, func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-		if raised := checkMethodArgs(f, slotName, args, t, ObjectType, TypeType); raised != nil {
+		// The owner argument is optional and defaults to the type of the
+		// instance.
+		expectedTypes := []*Type{t, ObjectType, TypeType}
+		if len(args) == 2 || (len(args) == 3 && args[2] == None) {
+			expectedTypes[2] = NoneType
+			if len(args) == 2 {
+				expectedTypes = expectedTypes[:2]
+			}
+		}
+		if raised := checkMethodArgs(f, slotName, args, expectedTypes...); raised != nil {
 			return nil, raised
 		}
-		return s.Fn(f, args[0], args[1], toTypeUnsafe(args[2]))
+		owner := args[1].typ
+		if len(args) == 3 && args[2] != None {
+			owner = toTypeUnsafe(args[2])
+		} else if args[1] == None {
+			return nil, f.RaiseType(TypeErrorType, slotName+"(None, None) is invalid")
+		}
+		return s.Fn(f, args[0], args[1], owner)
 	}).ToObject()
 }
 
 func (s *getSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, desc, inst *Object, owner *Type) (*Object, *BaseException) {
-		return callable.Call(f, Args{desc, inst, owner.ToObject()}, nil)
+		ownerObj := None
+		if owner != nil {
+			ownerObj = owner.ToObject()
+		}
+		if inst == nil {
+			inst = None
+		}
+		return f.callArgs(callable, desc, inst, ownerObj)
 	}
 	return true
 }
@@ -221,10 +240,7 @@ func (s *initSlot) makeCallable(t *Type, _ string) *Object {
 
 func (s *initSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
-		callArgs := make(Args, len(args)+1)
-		callArgs[0] = o
-		copy(callArgs[1:], args)
-		return callable.Call(f, callArgs, kwargs)
+		return f.callPrepend(callable, o, args, kwargs)
 	}
 	return true
 }
@@ -255,16 +271,18 @@ func (s *newSlot) makeCallable(t *Type, _ string) *Object {
 			format := "%[1]s.__new__(%[2]s): %[2]s is not a subtype of %[1]s"
 			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, t.Name(), typeArg.Name()))
 		}
-		return t.slots.New.Fn(f, typeArg, args[1:], kwargs)
+		o, raised := t.slots.New.Fn(f, typeArg, args[1:], kwargs)
+		if raised != nil {
+			return nil, raised
+		}
+		objectSetPolicy(f, o)
+		return o, nil
 	}).ToObject()).ToObject()
 }
 
 func (s *newSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
-		callArgs := make(Args, len(args)+1)
-		callArgs[0] = t.ToObject()
-		copy(callArgs[1:], args)
-		return callable.Call(f, callArgs, kwargs)
+		return f.callPrepend(callable, t.ToObject(), args, kwargs)
 	}
 	return true
 }
@@ -287,7 +305,7 @@ func (s *setAttrSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *setAttrSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, name *Str, value *Object) *BaseException {
-		_, raised := callable.Call(f, Args{o, name.ToObject(), value}, nil)
+		_, raised := f.callArgs(callable, o, name.ToObject(), value)
 		return raised
 	}
 	return true
@@ -311,7 +329,7 @@ func (s *setItemSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *setItemSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object, key *Object, value *Object) *BaseException {
-		_, raised := callable.Call(f, Args{o, key, value}, nil)
+		_, raised := f.callArgs(callable, o, key, value)
 		return raised
 	}
 	return true
@@ -335,7 +353,7 @@ func (s *setSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *setSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, desc, inst, value *Object) *BaseException {
-		_, raised := callable.Call(f, Args{desc, inst, value}, nil)
+		_, raised := f.callArgs(callable, desc, inst, value)
 		return raised
 	}
 	return true
@@ -356,7 +374,7 @@ func (s *unaryOpSlot) makeCallable(t *Type, slotName string) *Object {
 
 func (s *unaryOpSlot) wrapCallable(callable *Object) bool {
 	s.Fn = func(f *Frame, o *Object) (*Object, *BaseException) {
-		return callable.Call(f, Args{o}, nil)
+		return f.callArgs(callable, o)
 	}
 	return true
 }
@@ -377,9 +395,11 @@ type typeSlots struct {
 	Cmp          *binaryOpSlot
 	Complex      *unaryOpSlot
 	Contains     *binaryOpSlot
+	Del          *unaryOpSlot
 	DelAttr      *delAttrSlot
 	Delete       *deleteSlot
 	DelItem      *delItemSlot
+	Dir          *unaryOpSlot
 	Div          *binaryOpSlot
 	DivMod       *binaryOpSlot
 	Eq           *binaryOpSlot
@@ -58,6 +58,9 @@ func TestSlotMakeCallable(t *testing.T) {
 		{args: wrapArgs(&getAttributeSlot{}, RuntimeErrorType, foo, "bar"), wantExc: mustCreateException(RuntimeErrorType, "")},
 		{args: wrapArgs(&getSlot{}, 3.14, foo, 123, IntType), want: newTestTuple(3.14, newTestTuple(foo, 123, IntType)).ToObject()},
 		{args: wrapArgs(&getSlot{}, None, foo, "bar", "baz"), wantExc: mustCreateException(TypeErrorType, "'__slot__' requires a 'type' object but received a 'str'")},
+		{args: wrapArgs(&getSlot{}, 3.14, foo, 123), want: newTestTuple(3.14, newTestTuple(foo, 123, IntType)).ToObject()},
+		{args: wrapArgs(&getSlot{}, 3.14, foo, 123, None), want: newTestTuple(3.14, newTestTuple(foo, 123, IntType)).ToObject()},
+		{args: wrapArgs(&getSlot{}, 3.14, foo, None, None), wantExc: mustCreateException(TypeErrorType, "__slot__(None, None) is invalid")},
 		{args: wrapArgs(&nativeSlot{}, None), want: None},
 		{args: wrapArgs(&setAttrSlot{}, None, foo, "bar", 123), want: newTestTuple(None, newTestTuple(foo, "bar", 123)).ToObject()},
 		{args: wrapArgs(&setAttrSlot{}, None, foo, true, None), wantExc: mustCreateException(TypeErrorType, "'__slot__' requires a 'str' object but received a 'bool'")},
@@ -129,6 +132,7 @@ func TestSlotWrapCallable(t *testing.T) {
 		{args: wrapArgs(&getAttributeSlot{}, "ret", o, "foo"), want: newTestTuple("ret", newTestTuple(o, "foo"), NewDict()).ToObject()},
 		{args: wrapArgs(&getAttributeSlot{}, RuntimeErrorType, o, "foo"), wantExc: mustCreateException(RuntimeErrorType, "")},
 		{args: wrapArgs(&getSlot{}, "ret", o, "foo", SetType), want: newTestTuple("ret", newTestTuple(o, "foo", SetType), NewDict()).ToObject()},
+		{args: wrapArgs(&getSlot{}, "ret", o, None, None), want: newTestTuple("ret", newTestTuple(o, None, None), NewDict()).ToObject()},
 		{args: wrapArgs(&getSlot{}, RuntimeErrorType, o, "foo", SetType), wantExc: mustCreateException(RuntimeErrorType, "")},
 		{args: wrapArgs(&initSlot{}, "ret", true, wrapArgs(1, 2), None), want: newTestTuple("ret", newTestTuple(true, 1, 2), NewDict()).ToObject()},
 		{args: wrapArgs(&initSlot{}, "ret", "foo", None, wrapKWArgs("a", "b")), want: newTestTuple("ret", newTestTuple("foo"), newTestDict("a", "b")).ToObject()},
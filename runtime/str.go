@@ -17,10 +17,13 @@ package grumpy
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
@@ -29,16 +32,29 @@ import (
 
 var (
 	// StrType is the object representing the Python 'str' type.
-	StrType                = newBasisType("str", reflect.TypeOf(Str{}), toStrUnsafe, BaseStringType)
-	whitespaceSplitRegexp  = regexp.MustCompile(`\s+`)
-	strASCIISpaces         = []byte(" \t\n\v\f\r")
-	strInterpolationRegexp = regexp.MustCompile(`^%([#0 +-]?)((\*|[0-9]+)?)((\.(\*|[0-9]+))?)[hlL]?([diouxXeEfFgGcrs%])`)
-	internedStrs           = map[string]*Str{}
-	caseOffset             = byte('a' - 'A')
+	StrType               = newBasisType("str", reflect.TypeOf(Str{}), toStrUnsafe, BaseStringType)
+	whitespaceSplitRegexp = regexp.MustCompile(`\s+`)
+	strASCIISpaces        = []byte(" \t\n\v\f\r")
+	internedStrs          = map[string]*Str{}
+	caseOffset            = byte('a' - 'A')
+	// startsWithParamSpec and endsWithParamSpec describe the parameters
+	// of startswith and endswith for str and unicode when called with
+	// keyword arguments.
+	startsWithParamSpec = NewParamSpec("startswith", []Param{{"self", nil}, {"prefix", nil}, {"start", None}, {"end", None}}, false, false)
+	endsWithParamSpec   = NewParamSpec("endswith", []Param{{"self", nil}, {"suffix", nil}, {"start", None}, {"end", None}}, false, false)
+
+	// dynamicInternedStrs holds the strings interned by the intern builtin
+	// after module initialization, when internedStrs may no longer change.
+	dynamicInternedStrs      = map[string]*Str{}
+	dynamicInternedStrsMutex sync.Mutex
 
 	internedName = NewStr("__name__")
 )
 
+// strConcatMinLen is the length below which concatenated strings are copied
+// rather than being backed by a growable strBuffer.
+const strConcatMinLen = 64
+
 type stripSide int
 
 const (
@@ -59,11 +75,65 @@ func InternStr(s string) *Str {
 	return str
 }
 
+// NewStrTable returns pre-hashed Strs holding each of the given values.
+// Generated modules use it to build their table of string constants once at
+// initialization rather than allocating a new Str each time a literal is
+// evaluated.
+func NewStrTable(values ...string) []*Str {
+	strs := make([]*Str, len(values))
+	for i, value := range values {
+		if s := internedStrs[value]; s != nil {
+			strs[i] = s
+		} else {
+			strs[i] = &Str{Object: Object{typ: StrType}, value: value, hash: NewInt(hashString(value))}
+		}
+	}
+	return strs
+}
+
+// internStrDynamic returns the canonical Str holding the same value as s,
+// adding s to the table of dynamically interned strings if needed. Unlike
+// InternStr it is safe to call at any time.
+func internStrDynamic(s *Str) *Str {
+	if interned := internedStrs[s.value]; interned != nil {
+		return interned
+	}
+	dynamicInternedStrsMutex.Lock()
+	interned := dynamicInternedStrs[s.value]
+	if interned == nil {
+		interned = s
+		dynamicInternedStrs[s.value] = s
+	}
+	dynamicInternedStrsMutex.Unlock()
+	return interned
+}
+
 // Str represents Python 'str' objects.
 type Str struct {
 	Object
 	value string
-	hash  *Int
+	// hash is populated when the Str is created by InternStr or
+	// NewStrTable and otherwise the first time the Str is hashed. It is
+	// accessed atomically.
+	hash *Int
+	// buf is non-nil for Strs produced by concatenation. In that case
+	// value is backed by the first len(value) bytes of buf.b. See
+	// strConcat.
+	buf *strBuffer
+}
+
+// strBuffer is a growable byte buffer shared by a chain of concatenated Strs.
+// Bytes are only ever appended to b so the prefixes referenced by existing
+// Strs never change.
+type strBuffer struct {
+	mutex sync.Mutex
+	b     []byte
+}
+
+// NewStrFromBuilder returns a new Str holding the contents of b without
+// copying them. b may continue to be written to afterward.
+func NewStrFromBuilder(b *strings.Builder) *Str {
+	return NewStr(b.String())
 }
 
 // NewStr returns a new Str holding the given string value.
@@ -82,34 +152,18 @@ func toStrUnsafe(o *Object) *Str {
 // given encoding. Invalid code points are resolved using a strategy given by
 // errors: "ignore" will bypass them, "replace" will substitute the Unicode
 // replacement character (U+FFFD) and "strict" will raise UnicodeDecodeError.
-//
-// NOTE: Decoding UTF-8 data containing surrogates (e.g. U+D800 encoded as
-// '\xed\xa0\x80') will raise UnicodeDecodeError consistent with CPython 3.x
-// but different than 2.x.
+// Encodings not built into the runtime are looked up in the codec registry.
 func (s *Str) Decode(f *Frame, encoding, errors string) (*Unicode, *BaseException) {
-	// TODO: Support custom encodings and error handlers.
-	normalized := normalizeEncoding(encoding)
-	if normalized != "utf8" {
-		return nil, f.RaiseType(LookupErrorType, fmt.Sprintf("unknown encoding: %s", encoding))
+	// TODO: Support custom error handlers.
+	result, raised := CodecsDecode(f, s.ToObject(), encoding, errors)
+	if raised != nil {
+		return nil, raised
 	}
-	var runes []rune
-	for pos, r := range s.Value() {
-		switch {
-		case r != utf8.RuneError:
-			runes = append(runes, r)
-		case errors == EncodeIgnore:
-			// Do nothing
-		case errors == EncodeReplace:
-			runes = append(runes, unicode.ReplacementChar)
-		case errors == EncodeStrict:
-			format := "'%s' codec can't decode byte 0x%02x in position %d"
-			return nil, f.RaiseType(UnicodeDecodeErrorType, fmt.Sprintf(format, encoding, int(s.Value()[pos]), pos))
-		default:
-			format := "unknown error handler name '%s'"
-			return nil, f.RaiseType(LookupErrorType, fmt.Sprintf(format, errors))
-		}
+	if !result.isInstance(UnicodeType) {
+		format := "decoder did not return an unicode object (type=%s)"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
 	}
-	return NewUnicodeFromRunes(runes), nil
+	return toUnicodeUnsafe(result), nil
 }
 
 // ToObject upcasts s to an Object.
@@ -152,12 +206,51 @@ func strAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
 	if !w.isInstance(StrType) {
 		return NotImplemented, nil
 	}
-	stringV, stringW := toStrUnsafe(v).Value(), toStrUnsafe(w).Value()
-	if len(stringV)+len(stringW) < 0 {
+	strV, stringW := toStrUnsafe(v), toStrUnsafe(w).Value()
+	if len(strV.value)+len(stringW) < 0 {
 		// This indicates an int overflow.
 		return nil, f.RaiseType(OverflowErrorType, errResultTooLarge)
 	}
-	return NewStr(stringV + stringW).ToObject(), nil
+	if raised := f.checkAlloc(len(strV.value) + len(stringW)); raised != nil {
+		return nil, raised
+	}
+	return strConcat(strV, stringW).ToObject(), nil
+}
+
+// strConcat returns a new Str holding v's value followed by w. When v was
+// itself produced by concatenation and nothing has since been appended to its
+// buffer, w is appended to that buffer in place. The buffer grows
+// geometrically so building a string with repeated s += piece takes amortized
+// linear rather than quadratic time.
+func strConcat(v *Str, w string) *Str {
+	n := len(v.value) + len(w)
+	if n < strConcatMinLen {
+		return NewStr(v.value + w)
+	}
+	if buf := v.buf; buf != nil {
+		buf.mutex.Lock()
+		if len(buf.b) == len(v.value) {
+			// v is the longest Str backed by buf so bytes past
+			// its end are unused and may be overwritten.
+			buf.b = append(buf.b, w...)
+			value := bytesToStringUnsafe(buf.b)
+			buf.mutex.Unlock()
+			return &Str{Object: Object{typ: StrType}, value: value, buf: buf}
+		}
+		buf.mutex.Unlock()
+	}
+	// Give the first concatenation an exact fit since most results are
+	// never appended to. The buffer is grown by append on the next one.
+	b := make([]byte, n)
+	copy(b, v.value)
+	copy(b[len(v.value):], w)
+	return &Str{Object: Object{typ: StrType}, value: bytesToStringUnsafe(b), buf: &strBuffer{b: b}}
+}
+
+// bytesToStringUnsafe returns a string sharing b's underlying memory. The
+// caller must guarantee that the first len(b) bytes are never modified.
+func bytesToStringUnsafe(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
 }
 
 func strCapitalize(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
@@ -206,7 +299,10 @@ func strContains(f *Frame, o *Object, value *Object) (*Object, *BaseException) {
 }
 
 func strCount(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	if raised := checkMethodArgs(f, "count", args, StrType, ObjectType); raised != nil {
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeCount, args)
+	}
+	if raised := checkMethodArgs(f, "count", args, StrType, StrType); raised != nil {
 		return nil, raised
 	}
 	s := toStrUnsafe(args[0]).Value()
@@ -216,8 +312,7 @@ func strCount(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 }
 
 func strDecode(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	// TODO: Accept unicode for encoding and errors args.
-	expectedTypes := []*Type{StrType, StrType, StrType}
+	expectedTypes := []*Type{StrType, BaseStringType, BaseStringType}
 	argc := len(args)
 	if argc >= 1 && argc < 3 {
 		expectedTypes = expectedTypes[:argc]
@@ -225,22 +320,43 @@ func strDecode(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "decode", args, expectedTypes...); raised != nil {
 		return nil, raised
 	}
-	encoding := EncodeDefault
-	if argc > 1 {
-		encoding = toStrUnsafe(args[1]).Value()
-	}
-	errors := EncodeStrict
-	if argc > 2 {
-		errors = toStrUnsafe(args[2]).Value()
+	encoding, errors, raised := encodingArgs(f, args[1:])
+	if raised != nil {
+		return nil, raised
 	}
-	s, raised := toStrUnsafe(args[0]).Decode(f, encoding, errors)
+	result, raised := CodecsDecode(f, args[0], encoding, errors)
 	if raised != nil {
 		return nil, raised
 	}
-	return s.ToObject(), nil
+	if !result.isInstance(BaseStringType) {
+		format := "decoder did not return a string/unicode object (type=%s)"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
+	}
+	return result, nil
+}
+
+// encodingArgs returns the optional encoding and errors arguments passed to
+// decode() or encode(), which may each be str or unicode.
+func encodingArgs(f *Frame, args Args) (encoding, errors string, raised *BaseException) {
+	values := []string{EncodeDefault, EncodeStrict}
+	for i, arg := range args {
+		s, raised := basestringToStr(f, arg)
+		if raised != nil {
+			return "", "", raised
+		}
+		values[i] = s.Value()
+	}
+	return values[0], values[1], nil
 }
 
-func strEndsWith(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+func strEndsWith(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	args, raised := startsEndsWithArgs(f, endsWithParamSpec, args, kwargs)
+	if raised != nil {
+		return nil, raised
+	}
+	if strStartsEndsWithHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeEndsWith, args)
+	}
 	return strStartsEndsWith(f, "endswith", args)
 }
 
@@ -250,7 +366,48 @@ func strEq(f *Frame, v, w *Object) (*Object, *BaseException) {
 
 // strFind returns the lowest index in s where the substring sub is found such
 // that sub is wholly contained in s[start:end]. Return -1 on failure.
+func strExpandTabs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{StrType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, "expandtabs", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	tabSize := 8
+	if argc > 1 {
+		var raised *BaseException
+		if tabSize, raised = ToIntValue(f, args[1]); raised != nil {
+			return nil, raised
+		}
+	}
+	s := toStrUnsafe(args[0]).Value()
+	var buf bytes.Buffer
+	column := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\t':
+			if tabSize > 0 {
+				n := tabSize - column%tabSize
+				buf.WriteString(strings.Repeat(" ", n))
+				column += n
+			}
+		case '\n', '\r':
+			buf.WriteByte(c)
+			column = 0
+		default:
+			buf.WriteByte(c)
+			column++
+		}
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
 func strFind(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeFind, args)
+	}
 	return strFindOrIndex(f, args, func(s, sub string) (int, *BaseException) {
 		return strings.Index(s, sub), nil
 	})
@@ -266,11 +423,7 @@ func strGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
 	s := toStrUnsafe(o).Value()
 	switch {
 	case key.typ.slots.Index != nil:
-		index, raised := IndexInt(f, key)
-		if raised != nil {
-			return nil, raised
-		}
-		index, raised = seqCheckedIndex(f, len(s), index)
+		index, raised := seqIndex(f, len(s), key)
 		if raised != nil {
 			return nil, raised
 		}
@@ -297,7 +450,7 @@ func strGetNewArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "__getnewargs__", args, StrType); raised != nil {
 		return nil, raised
 	}
-	return NewTuple1(args[0]).ToObject(), nil
+	return NewTuple1(NewStr(toStrUnsafe(args[0]).Value()).ToObject()).ToObject(), nil
 }
 
 func strGT(f *Frame, v, w *Object) (*Object, *BaseException) {
@@ -316,6 +469,9 @@ func strHash(f *Frame, o *Object) (*Object, *BaseException) {
 }
 
 func strIndex(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeIndex, args)
+	}
 	return strFindOrIndex(f, args, func(s, sub string) (i int, raised *BaseException) {
 		i = strings.Index(s, sub)
 		if i == -1 {
@@ -491,6 +647,9 @@ func strJoin(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 				return f.RaiseType(TypeErrorType, fmt.Sprintf(format, i, part.typ.Name()))
 			}
 		}
+		if raised := f.checkAlloc(numChars); raised != nil {
+			return raised
+		}
 		// Piece together the result string into buf.
 		buf := bytes.Buffer{}
 		buf.Grow(numChars)
@@ -554,15 +713,7 @@ func strLT(f *Frame, v, w *Object) (*Object, *BaseException) {
 }
 
 func strMod(f *Frame, v, w *Object) (*Object, *BaseException) {
-	s := toStrUnsafe(v).Value()
-	switch {
-	case w.isInstance(DictType):
-		return nil, f.RaiseType(NotImplementedErrorType, "mappings not yet supported")
-	case w.isInstance(TupleType):
-		return strInterpolate(f, s, toTupleUnsafe(w))
-	default:
-		return strInterpolate(f, s, NewTuple1(w))
-	}
+	return strInterpolate(f, toStrUnsafe(v).Value(), w, false)
 }
 
 func strMul(f *Frame, v, w *Object) (*Object, *BaseException) {
@@ -611,6 +762,13 @@ func strNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 		if raised != nil {
 			return nil, raised
 		}
+		if result.isInstance(UnicodeType) {
+			s, raised := toUnicodeUnsafe(result).Encode(f, EncodeDefault, EncodeStrict)
+			if raised != nil {
+				return nil, raised
+			}
+			result = s.ToObject()
+		}
 		if !result.isInstance(StrType) {
 			format := "__str__ returned non-string (type %s)"
 			return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
@@ -628,9 +786,15 @@ func strNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 // instances of old replaced by sub. If old is empty, it matches at the
 // beginning of the string. If n < 0, there is no limit on the number of
 // replacements.
+func strPartition(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return strPartitionImpl(f, "partition", args, strings.Index)
+}
+
 func strReplace(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeReplace, args)
+	}
 	var raised *BaseException
-	// TODO: Support unicode replace.
 	expectedTypes := []*Type{StrType, StrType, StrType, ObjectType}
 	argc := len(args)
 	if argc == 3 {
@@ -708,12 +872,18 @@ func strRepr(_ *Frame, o *Object) (*Object, *BaseException) {
 }
 
 func strRFind(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeRFind, args)
+	}
 	return strFindOrIndex(f, args, func(s, sub string) (int, *BaseException) {
 		return strings.LastIndex(s, sub), nil
 	})
 }
 
 func strRIndex(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeRIndex, args)
+	}
 	return strFindOrIndex(f, args, func(s, sub string) (i int, raised *BaseException) {
 		i = strings.LastIndex(s, sub)
 		if i == -1 {
@@ -734,8 +904,79 @@ func strRJust(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return NewStr(pad(s, width-len(s), 0, fill)).ToObject(), nil
 }
 
+func strRPartition(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return strPartitionImpl(f, "rpartition", args, strings.LastIndex)
+}
+
+func strRSplit(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{StrType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 1 || argc == 2 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, "rsplit", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	sep := ""
+	if argc > 1 {
+		if arg1 := args[1]; arg1.isInstance(StrType) {
+			sep = toStrUnsafe(arg1).Value()
+			if sep == "" {
+				return nil, f.RaiseType(ValueErrorType, "empty separator")
+			}
+		} else if arg1 != None {
+			return nil, f.RaiseType(TypeErrorType, "expected a str separator")
+		}
+	}
+	maxSplit := -1
+	if argc > 2 {
+		i, raised := ToIntValue(f, args[2])
+		if raised != nil {
+			return nil, raised
+		}
+		if i >= 0 {
+			maxSplit = i
+		}
+	}
+	s := toStrUnsafe(args[0]).Value()
+	// Collect the parts from right to left and then reverse them.
+	var parts []string
+	if sep == "" {
+		spaces := string(strASCIISpaces)
+		s = strings.TrimRight(s, spaces)
+		for s != "" {
+			if maxSplit >= 0 && len(parts) == maxSplit {
+				parts = append(parts, s)
+				break
+			}
+			i := strings.LastIndexAny(s, spaces)
+			parts = append(parts, s[i+1:])
+			s = strings.TrimRight(s[:i+1], spaces)
+		}
+	} else {
+		for maxSplit < 0 || len(parts) < maxSplit {
+			i := strings.LastIndex(s, sep)
+			if i == -1 {
+				break
+			}
+			parts = append(parts, s[i+len(sep):])
+			s = s[:i]
+		}
+		parts = append(parts, s)
+	}
+	n := len(parts)
+	results := make([]*Object, n)
+	for i, part := range parts {
+		results[n-i-1] = NewStr(part).ToObject()
+	}
+	return NewList(results...).ToObject(), nil
+}
+
 func strSplit(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	expectedTypes := []*Type{StrType, ObjectType, IntType}
+	if strArgsHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeSplit, args)
+	}
+	expectedTypes := []*Type{StrType, ObjectType, ObjectType}
 	argc := len(args)
 	if argc == 1 || argc == 2 {
 		expectedTypes = expectedTypes[:argc]
@@ -756,7 +997,11 @@ func strSplit(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	}
 	maxSplit := -1
 	if argc > 2 {
-		if i := toIntUnsafe(args[2]).Value(); i >= 0 {
+		i, raised := ToIntValue(f, args[2])
+		if raised != nil {
+			return nil, raised
+		}
+		if i >= 0 {
 			maxSplit = i + 1
 		}
 	}
@@ -899,7 +1144,14 @@ func strStripImpl(f *Frame, args Args, side stripSide) (*Object, *BaseException)
 	return NewStr(string(byteSlice[lindex:rindex])).ToObject(), nil
 }
 
-func strStartsWith(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+func strStartsWith(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	args, raised := startsEndsWithArgs(f, startsWithParamSpec, args, kwargs)
+	if raised != nil {
+		return nil, raised
+	}
+	if strStartsEndsWithHaveUnicode(args) {
+		return strCallUnicodeMethod(f, unicodeStartsWith, args)
+	}
 	return strStartsEndsWith(f, "startswith", args)
 }
 
@@ -939,6 +1191,7 @@ func initStrType(dict map[string]*Object) {
 	dict["center"] = newBuiltinFunction("center", strCenter).ToObject()
 	dict["decode"] = newBuiltinFunction("decode", strDecode).ToObject()
 	dict["endswith"] = newBuiltinFunction("endswith", strEndsWith).ToObject()
+	dict["expandtabs"] = newBuiltinFunction("expandtabs", strExpandTabs).ToObject()
 	dict["find"] = newBuiltinFunction("find", strFind).ToObject()
 	dict["index"] = newBuiltinFunction("index", strIndex).ToObject()
 	dict["isalnum"] = newBuiltinFunction("isalnum", strIsAlNum).ToObject()
@@ -952,9 +1205,12 @@ func initStrType(dict map[string]*Object) {
 	dict["lower"] = newBuiltinFunction("lower", strLower).ToObject()
 	dict["ljust"] = newBuiltinFunction("ljust", strLJust).ToObject()
 	dict["lstrip"] = newBuiltinFunction("lstrip", strLStrip).ToObject()
+	dict["partition"] = newBuiltinFunction("partition", strPartition).ToObject()
 	dict["rfind"] = newBuiltinFunction("rfind", strRFind).ToObject()
 	dict["rindex"] = newBuiltinFunction("rindex", strRIndex).ToObject()
 	dict["rjust"] = newBuiltinFunction("rjust", strRJust).ToObject()
+	dict["rpartition"] = newBuiltinFunction("rpartition", strRPartition).ToObject()
+	dict["rsplit"] = newBuiltinFunction("rsplit", strRSplit).ToObject()
 	dict["split"] = newBuiltinFunction("split", strSplit).ToObject()
 	dict["splitlines"] = newBuiltinFunction("splitlines", strSplitLines).ToObject()
 	dict["startswith"] = newBuiltinFunction("startswith", strStartsWith).ToObject()
@@ -963,6 +1219,7 @@ func initStrType(dict map[string]*Object) {
 	dict["replace"] = newBuiltinFunction("replace", strReplace).ToObject()
 	dict["rstrip"] = newBuiltinFunction("rstrip", strRStrip).ToObject()
 	dict["title"] = newBuiltinFunction("title", strTitle).ToObject()
+	dict["translate"] = newBuiltinFunction("translate", strTranslate).ToObject()
 	dict["upper"] = newBuiltinFunction("upper", strUpper).ToObject()
 	dict["zfill"] = newBuiltinFunction("zfill", strZFill).ToObject()
 	StrType.slots.Add = &binaryOpSlot{strAdd}
@@ -1003,151 +1260,393 @@ func strCompare(v, w *Object, ltResult, eqResult, gtResult *Int) *Object {
 	return gtResult.ToObject()
 }
 
-func strInterpolate(f *Frame, format string, values *Tuple) (*Object, *BaseException) {
-	var buf bytes.Buffer
+// strFormatSpec is a parsed %-style conversion specifier of the form
+// %[(key)][flags][width][.precision][length]type.
+type strFormatSpec struct {
+	alt, left, zero, space, plus bool
+	width, precision             int
+	conv                         byte
+	// unicode is set when producing a unicode result, in which case widths
+	// and precisions count code points rather than bytes.
+	unicode bool
+}
+
+// strInterpolate implements the % operator for str and unicode. format is
+// utf-8 encoded when isUnicode is true. arg is a tuple of values, a mapping
+// used to resolve "%(key)s" specifiers or a single value. Like CPython, a str
+// format is promoted to unicode when a unicode value is passed to %s.
+func strInterpolate(f *Frame, format string, arg *Object, isUnicode bool) (*Object, *BaseException) {
+	origFormat := format
+	values := []*Object{arg}
+	var mapping *Object
+	if arg.isInstance(TupleType) {
+		values = toTupleUnsafe(arg).elems
+	} else if arg.typ.slots.GetItem != nil && !arg.isInstance(BaseStringType) {
+		mapping = arg
+	}
 	valueIndex := 0
-	index := strings.Index(format, "%")
-	for index != -1 {
-		buf.WriteString(format[:index])
-		format = format[index:]
-		matches := strInterpolationRegexp.FindStringSubmatch(format)
-		if matches == nil {
-			return nil, f.RaiseType(ValueErrorType, "invalid format spec")
-		}
-		flags, fieldType := matches[1], matches[7]
-		if fieldType != "%" && valueIndex >= len(values.elems) {
+	nextValue := func() (*Object, *BaseException) {
+		if valueIndex >= len(values) {
 			return nil, f.RaiseType(TypeErrorType, "not enough arguments for format string")
 		}
-		fieldWidth := -1
-		if matches[2] == "*" || matches[4] != "" {
-			return nil, f.RaiseType(NotImplementedErrorType, "field width not yet supported")
+		valueIndex++
+		return values[valueIndex-1], nil
+	}
+	nextInt := func() (int, *BaseException) {
+		o, raised := nextValue()
+		if raised != nil {
+			return 0, raised
+		}
+		if !o.isInstance(IntType) {
+			return 0, f.RaiseType(TypeErrorType, "* wants int")
+		}
+		return toIntUnsafe(o).Value(), nil
+	}
+	var buf bytes.Buffer
+	for {
+		index := strings.IndexByte(format, '%')
+		if index == -1 {
+			break
 		}
-		if matches[2] != "" {
-			var err error
-			fieldWidth, err = strconv.Atoi(matches[2])
+		buf.WriteString(format[:index])
+		i := index + 1
+		var keyValue *Object
+		if i < len(format) && format[i] == '(' {
+			if mapping == nil {
+				return nil, f.RaiseType(TypeErrorType, "format requires a mapping")
+			}
+			depth, start := 1, i+1
+			for i++; i < len(format) && depth > 0; i++ {
+				if format[i] == '(' {
+					depth++
+				} else if format[i] == ')' {
+					depth--
+				}
+			}
+			if depth > 0 {
+				return nil, f.RaiseType(ValueErrorType, "incomplete format key")
+			}
+			key := NewStr(format[start : i-1]).ToObject()
+			if isUnicode {
+				key = NewUnicode(format[start : i-1]).ToObject()
+			}
+			var raised *BaseException
+			if keyValue, raised = GetItem(f, mapping, key); raised != nil {
+				return nil, raised
+			}
+			// CPython treats the mapping as having no positional values once
+			// a key is used.
+			values = nil
+		}
+		spec := strFormatSpec{width: -1, precision: -1, unicode: isUnicode}
+	flags:
+		for ; i < len(format); i++ {
+			switch format[i] {
+			case '#':
+				spec.alt = true
+			case '-':
+				spec.left = true
+			case '0':
+				spec.zero = true
+			case ' ':
+				spec.space = true
+			case '+':
+				spec.plus = true
+			default:
+				break flags
+			}
+		}
+		if i < len(format) && format[i] == '*' {
+			w, raised := nextInt()
+			if raised != nil {
+				return nil, raised
+			}
+			if w < 0 {
+				spec.left, w = true, -w
+			}
+			spec.width = w
+			i++
+		} else if j := strScanDigits(format, i); j > i {
+			w, err := strconv.Atoi(format[i:j])
 			if err != nil {
-				return nil, f.RaiseType(TypeErrorType, fmt.Sprint(err))
+				return nil, f.RaiseType(ValueErrorType, "width too big")
+			}
+			spec.width, i = w, j
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			spec.precision = 0
+			if i < len(format) && format[i] == '*' {
+				p, raised := nextInt()
+				if raised != nil {
+					return nil, raised
+				}
+				if p > 0 {
+					spec.precision = p
+				}
+				i++
+			} else if j := strScanDigits(format, i); j > i {
+				p, err := strconv.Atoi(format[i:j])
+				if err != nil {
+					return nil, f.RaiseType(ValueErrorType, "prec too big")
+				}
+				spec.precision, i = p, j
+			}
+		}
+		if i < len(format) && strings.IndexByte("hlL", format[i]) != -1 {
+			i++
+		}
+		if i >= len(format) {
+			return nil, f.RaiseType(ValueErrorType, "incomplete format")
+		}
+		spec.conv = format[i]
+		format = format[i+1:]
+		if spec.conv == '%' {
+			buf.WriteString(spec.pad("", "", "%", false))
+			continue
+		}
+		o := keyValue
+		if o == nil {
+			var raised *BaseException
+			if o, raised = nextValue(); raised != nil {
+				return nil, raised
 			}
 		}
-		if flags != "" && flags != "0" {
-			return nil, f.RaiseType(NotImplementedErrorType, "conversion flags not yet supported")
+		if !isUnicode && spec.conv == 's' && o.isInstance(UnicodeType) {
+			return strInterpolate(f, origFormat, arg, true)
 		}
+		s, raised := strFormatValue(f, o, spec, i)
+		if raised != nil {
+			return nil, raised
+		}
+		buf.WriteString(s)
+	}
+	if mapping == nil && valueIndex < len(values) {
+		return nil, f.RaiseType(TypeErrorType, "not all arguments converted during string formatting")
+	}
+	buf.WriteString(format)
+	if isUnicode {
+		return NewUnicode(buf.String()).ToObject(), nil
+	}
+	return NewStr(buf.String()).ToObject(), nil
+}
+
+// strFormatValue formats o according to spec. index is the position of the
+// conversion character in the format string, used for error messages.
+func strFormatValue(f *Frame, o *Object, spec strFormatSpec, index int) (string, *BaseException) {
+	switch spec.conv {
+	case 'r', 's':
 		var val string
-		switch fieldType {
-		case "r", "s":
-			o := values.elems[valueIndex]
+		if spec.conv == 's' && spec.unicode {
+			u, raised := UnicodeType.Call(f, Args{o}, nil)
+			if raised != nil {
+				return "", raised
+			}
+			runes := toUnicodeUnsafe(u).Value()
+			if spec.precision >= 0 && spec.precision < len(runes) {
+				runes = runes[:spec.precision]
+			}
+			val = string(runes)
+		} else {
 			var s *Str
 			var raised *BaseException
-			if fieldType == "r" {
+			if spec.conv == 'r' {
 				s, raised = Repr(f, o)
 			} else {
 				s, raised = ToStr(f, o)
 			}
 			if raised != nil {
-				return nil, raised
+				return "", raised
 			}
 			val = s.Value()
-			if fieldWidth > 0 {
-				val = strLeftPad(val, fieldWidth, " ")
+			if spec.precision >= 0 && spec.precision < len(val) {
+				val = val[:spec.precision]
 			}
-			buf.WriteString(val)
-			valueIndex++
-		case "f":
-			o := values.elems[valueIndex]
-			if v, ok := floatCoerce(o); ok {
-				val := strconv.FormatFloat(v, 'f', 6, 64)
-				if fieldWidth > 0 {
-					fillchar := " "
-					if flags != "" {
-						fillchar = flags
-					}
-					val = strLeftPad(val, fieldWidth, fillchar)
-				}
-				buf.WriteString(val)
-				valueIndex++
-			} else {
-				return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("float argument required, not %s", o.typ.Name()))
-			}
-		case "d", "x", "X", "o":
-			o := values.elems[valueIndex]
-			i, raised := ToInt(f, values.elems[valueIndex])
+		}
+		return spec.pad("", "", val, false), nil
+	case 'c':
+		var val string
+		switch {
+		case o.isInstance(IntType) || o.isInstance(LongType):
+			i, raised := IndexInt(f, o)
 			if raised != nil {
-				return nil, raised
+				return "", raised
 			}
-			if fieldType == "d" {
-				s, raised := ToStr(f, i)
-				if raised != nil {
-					return nil, raised
-				}
-				val = s.Value()
-			} else if matches[7] == "o" {
-				if o.isInstance(LongType) {
-					val = toLongUnsafe(o).Value().Text(8)
-				} else {
-					val = strconv.FormatInt(int64(toIntUnsafe(i).Value()), 8)
+			if spec.unicode {
+				if i < 0 || i > unicode.MaxRune {
+					return "", f.RaiseType(OverflowErrorType, "%c arg not in range(0x110000)")
 				}
+				val = string(rune(i))
 			} else {
-				if o.isInstance(LongType) {
-					val = toLongUnsafe(o).Value().Text(16)
-				} else {
-					val = strconv.FormatInt(int64(toIntUnsafe(i).Value()), 16)
-				}
-				if fieldType == "X" {
-					val = strings.ToUpper(val)
+				if i < 0 || i > 255 {
+					return "", f.RaiseType(OverflowErrorType, "%c arg not in range(256)")
 				}
+				val = string([]byte{byte(i)})
 			}
-			if fieldWidth > 0 {
-				fillchar := " "
-				if flags != "" {
-					fillchar = flags
+		case o.isInstance(StrType) && len(toStrUnsafe(o).Value()) == 1:
+			val = toStrUnsafe(o).Value()
+		case spec.unicode && o.isInstance(UnicodeType) && len(toUnicodeUnsafe(o).Value()) == 1:
+			val = string(toUnicodeUnsafe(o).Value())
+		default:
+			return "", f.RaiseType(TypeErrorType, "%c requires int or char")
+		}
+		return spec.pad("", "", val, false), nil
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		v, ok := floatCoerce(o)
+		if !ok {
+			return "", f.RaiseType(TypeErrorType, fmt.Sprintf("float argument required, not %s", o.typ.Name()))
+		}
+		if spec.precision < 0 {
+			spec.precision = 6
+		}
+		finite := !math.IsInf(v, 0) && !math.IsNaN(v)
+		neg := math.Signbit(v) && !math.IsNaN(v)
+		body := strFormatFloat(math.Abs(v), spec.conv, spec.precision, spec.alt)
+		return spec.pad(strSign(neg, spec), "", body, spec.zero && finite), nil
+	case 'd', 'i', 'u', 'o', 'x', 'X':
+		i, raised := ToInt(f, o)
+		if raised != nil {
+			return "", raised
+		}
+		base := 10
+		if spec.conv == 'o' {
+			base = 8
+		} else if spec.conv == 'x' || spec.conv == 'X' {
+			base = 16
+		}
+		var digits string
+		var neg bool
+		if i.isInstance(LongType) {
+			l := toLongUnsafe(i).Value()
+			neg = l.Sign() < 0
+			digits = new(big.Int).Abs(l).Text(base)
+		} else {
+			v := toIntUnsafe(i).Value()
+			neg = v < 0
+			digits = strings.TrimPrefix(strconv.FormatInt(int64(v), base), "-")
+		}
+		if spec.precision > len(digits) {
+			// The precision is the minimum number of digits.
+			digits = strings.Repeat("0", spec.precision-len(digits)) + digits
+		}
+		prefix := ""
+		if spec.alt {
+			switch spec.conv {
+			case 'o':
+				if digits[0] != '0' {
+					digits = "0" + digits
 				}
-				val = strLeftPad(val, fieldWidth, fillchar)
+			case 'x':
+				prefix = "0x"
+			case 'X':
+				prefix = "0X"
 			}
-			buf.WriteString(val)
-			valueIndex++
-		case "%":
-			val = "%"
-			if fieldWidth > 0 {
-				val = strLeftPad(val, fieldWidth, " ")
-			}
-			buf.WriteString(val)
-		default:
-			format := "conversion type not yet supported: %s"
-			return nil, f.RaiseType(NotImplementedErrorType, fmt.Sprintf(format, fieldType))
 		}
-		format = format[len(matches[0]):]
-		index = strings.Index(format, "%")
+		if spec.conv == 'X' {
+			digits = strings.ToUpper(digits)
+		}
+		return spec.pad(strSign(neg, spec), prefix, digits, spec.zero), nil
 	}
-	if valueIndex < len(values.elems) {
-		return nil, f.RaiseType(TypeErrorType, "not all arguments converted during string formatting")
+	format := "unsupported format character '%c' (0x%x) at index %d"
+	return "", f.RaiseType(ValueErrorType, fmt.Sprintf(format, spec.conv, spec.conv, index))
+}
+
+// pad pads sign+prefix+body to spec.width. Zero padding goes between the
+// prefix and the body, e.g. "-0x00ff".
+func (spec strFormatSpec) pad(sign, prefix, body string, zero bool) string {
+	bodyLen := len(body)
+	if spec.unicode {
+		bodyLen = utf8.RuneCountInString(body)
 	}
-	buf.WriteString(format)
-	return NewStr(buf.String()).ToObject(), nil
+	n := spec.width - len(sign) - len(prefix) - bodyLen
+	switch {
+	case n <= 0:
+		return sign + prefix + body
+	case spec.left:
+		return sign + prefix + body + strings.Repeat(" ", n)
+	case zero:
+		return sign + prefix + strings.Repeat("0", n) + body
+	}
+	return strings.Repeat(" ", n) + sign + prefix + body
 }
 
-func strRepeatCount(f *Frame, numChars int, mult *Object) (int, bool, *BaseException) {
-	var n int
+// strScanDigits returns the index of the first non-digit in s at or after i.
+func strScanDigits(s string, i int) int {
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// strSign returns the sign to display for a number given the spec's flags.
+func strSign(neg bool, spec strFormatSpec) string {
 	switch {
-	case mult.isInstance(IntType):
-		n = toIntUnsafe(mult).Value()
-	case mult.isInstance(LongType):
-		l := toLongUnsafe(mult).Value()
-		if !numInIntRange(l) {
-			return 0, false, f.RaiseType(OverflowErrorType, fmt.Sprintf("cannot fit '%s' into an index-sized integer", mult.typ.Name()))
-		}
-		n = int(l.Int64())
+	case neg:
+		return "-"
+	case spec.plus:
+		return "+"
+	case spec.space:
+		return " "
+	}
+	return ""
+}
+
+// strFormatFloat formats v according to the %-style float conversion conv
+// (one of 'e', 'E', 'f', 'F', 'g' or 'G') with the given precision. When alt
+// is set, the result always has a decimal point and 'g' keeps trailing zeros.
+func strFormatFloat(v float64, conv byte, precision int, alt bool) string {
+	var s string
+	switch {
+	case math.IsNaN(v):
+		s = "nan"
+	case math.IsInf(v, 1):
+		s = "inf"
+	case math.IsInf(v, -1):
+		s = "-inf"
+	case alt:
+		s = fmt.Sprintf("%#.*"+string(conv|caseOffset), precision, v)
 	default:
+		lower := conv | caseOffset
+		if lower == 'g' && precision == 0 {
+			precision = 1
+		}
+		s = strconv.FormatFloat(v, lower, precision, 64)
+	}
+	if conv < 'a' {
+		s = strings.ToUpper(s)
+	}
+	return s
+}
+
+func strRepeatCount(f *Frame, numChars int, mult *Object) (int, bool, *BaseException) {
+	if !mult.isInstance(IntType) && !mult.isInstance(LongType) {
 		return 0, false, nil
 	}
+	n, raised := indexIntChecked(f, mult, OverflowErrorType)
+	if raised != nil {
+		return 0, false, raised
+	}
 	if n <= 0 {
 		return 0, true, nil
 	}
 	if numChars > MaxInt/n {
 		return 0, false, f.RaiseType(OverflowErrorType, errResultTooLarge)
 	}
+	if raised := f.checkAlloc(numChars * n); raised != nil {
+		return 0, false, raised
+	}
 	return n, true, nil
 }
 
+// basestringToStr returns the str or unicode object o as a Str, encoding
+// unicode objects with the default encoding.
+func basestringToStr(f *Frame, o *Object) (*Str, *BaseException) {
+	if o.isInstance(UnicodeType) {
+		return toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
+	}
+	return toStrUnsafe(o), nil
+}
+
 func adjustIndex(start, end, length int) (int, int) {
 	if end > length {
 		end = length
@@ -1166,8 +1665,68 @@ func adjustIndex(start, end, length int) (int, int) {
 	return start, end
 }
 
+// strArgsHaveUnicode returns true if args[0] is a str and any of the remaining
+// args is unicode, in which case CPython coerces the call to unicode.
+func strArgsHaveUnicode(args Args) bool {
+	if len(args) == 0 || !args[0].isInstance(StrType) {
+		return false
+	}
+	for _, arg := range args[1:] {
+		if arg.isInstance(UnicodeType) {
+			return true
+		}
+	}
+	return false
+}
+
+// strCallUnicodeMethod decodes the str receiver args[0] and calls the unicode
+// method fn with it and the remaining args.
+func strCallUnicodeMethod(f *Frame, fn func(*Frame, Args, KWArgs) (*Object, *BaseException), args Args) (*Object, *BaseException) {
+	s, raised := toStrUnsafe(args[0]).Decode(f, EncodeDefault, EncodeStrict)
+	if raised != nil {
+		return nil, raised
+	}
+	unicodeArgs := f.MakeArgs(len(args))
+	unicodeArgs[0] = s.ToObject()
+	copy(unicodeArgs[1:], args[1:])
+	result, raised := fn(f, unicodeArgs, nil)
+	f.FreeArgs(unicodeArgs)
+	return result, raised
+}
+
+// startsEndsWithArgs returns the positional arguments for a startswith or
+// endswith call, merging in the start and end kwargs when present.
+func startsEndsWithArgs(f *Frame, spec *ParamSpec, args Args, kwargs KWArgs) (Args, *BaseException) {
+	if len(kwargs) == 0 {
+		return args, nil
+	}
+	validated := make(Args, spec.Count)
+	if raised := spec.Validate(f, validated, args, kwargs); raised != nil {
+		return nil, raised
+	}
+	return validated, nil
+}
+
+// strStartsEndsWithHaveUnicode returns true if the arguments to a str
+// startswith or endswith call include unicode, either directly or within a
+// tuple of prefixes, in which case the str should be promoted to unicode.
+func strStartsEndsWithHaveUnicode(args Args) bool {
+	if strArgsHaveUnicode(args) {
+		return true
+	}
+	if len(args) < 2 || !args[0].isInstance(StrType) || !args[1].isInstance(TupleType) {
+		return false
+	}
+	for _, o := range toTupleUnsafe(args[1]).elems {
+		if o.isInstance(UnicodeType) {
+			return true
+		}
+	}
+	return false
+}
+
 func strStartsEndsWith(f *Frame, method string, args Args) (*Object, *BaseException) {
-	expectedTypes := []*Type{StrType, ObjectType, IntType, IntType}
+	expectedTypes := []*Type{StrType, ObjectType, ObjectType, ObjectType}
 	argc := len(args)
 	if argc == 2 || argc == 3 {
 		expectedTypes = expectedTypes[:argc]
@@ -1204,11 +1763,16 @@ func strStartsEndsWith(f *Frame, method string, args Args) (*Object, *BaseExcept
 	s := toStrUnsafe(args[0]).Value()
 	l := len(s)
 	start, end := 0, l
-	if argc >= 3 {
-		start = toIntUnsafe(args[2]).Value()
+	var raised *BaseException
+	if argc >= 3 && args[2] != None {
+		if start, raised = IndexInt(f, args[2]); raised != nil {
+			return nil, raised
+		}
 	}
-	if argc == 4 {
-		end = toIntUnsafe(args[3]).Value()
+	if argc == 4 && args[3] != None {
+		if end, raised = IndexInt(f, args[3]); raised != nil {
+			return nil, raised
+		}
 	}
 	start, end = adjustIndex(start, end, l)
 	if start > end {
@@ -1261,6 +1825,52 @@ func strTitle(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	return NewStr(string(b)).ToObject(), nil
 }
 
+func strTranslate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{StrType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, "translate", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	var table string
+	if arg1 := args[1]; arg1.isInstance(StrType) {
+		table = toStrUnsafe(arg1).Value()
+		if len(table) != 256 {
+			return nil, f.RaiseType(ValueErrorType, "translation table must be 256 characters long")
+		}
+	} else if arg1 != None {
+		return nil, f.RaiseType(TypeErrorType, "expected a string or other character buffer object")
+	}
+	var deleteChars [256]bool
+	if argc > 2 {
+		arg2 := args[2]
+		if arg2.isInstance(UnicodeType) {
+			return nil, f.RaiseType(TypeErrorType, "deletions are implemented differently for unicode")
+		}
+		if !arg2.isInstance(StrType) {
+			return nil, f.RaiseType(TypeErrorType, "expected a string or other character buffer object")
+		}
+		for _, c := range []byte(toStrUnsafe(arg2).Value()) {
+			deleteChars[c] = true
+		}
+	}
+	s := toStrUnsafe(args[0]).Value()
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if deleteChars[c] {
+			continue
+		}
+		if table != "" {
+			c = table[c]
+		}
+		b = append(b, c)
+	}
+	return NewStr(string(b)).ToObject(), nil
+}
+
 func strUpper(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	expectedTypes := []*Type{StrType}
 	if raised := checkMethodArgs(f, "upper", args, expectedTypes...); raised != nil {
@@ -1387,8 +1997,28 @@ func strLeftPad(s string, width int, fillchar string) string {
 
 type indexFunc func(string, string) (int, *BaseException)
 
+// strPartitionImpl splits the str args[0] around the separator args[1] found
+// by fn. The separator and the second part are empty if it is not found.
+func strPartitionImpl(f *Frame, method string, args Args, fn func(s, sep string) int) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, method, args, StrType, StrType); raised != nil {
+		return nil, raised
+	}
+	s := toStrUnsafe(args[0]).Value()
+	sep := toStrUnsafe(args[1]).Value()
+	if sep == "" {
+		return nil, f.RaiseType(ValueErrorType, "empty separator")
+	}
+	i := fn(s, sep)
+	if i == -1 {
+		if method == "rpartition" {
+			return NewTuple3(NewStr("").ToObject(), NewStr("").ToObject(), args[0]).ToObject(), nil
+		}
+		return NewTuple3(args[0], NewStr("").ToObject(), NewStr("").ToObject()).ToObject(), nil
+	}
+	return NewTuple3(NewStr(s[:i]).ToObject(), args[1], NewStr(s[i+len(sep):]).ToObject()).ToObject(), nil
+}
+
 func strFindOrIndex(f *Frame, args Args, fn indexFunc) (*Object, *BaseException) {
-	// TODO: Support for unicode substring.
 	expectedTypes := []*Type{StrType, StrType, ObjectType, ObjectType}
 	argc := len(args)
 	if argc == 2 || argc == 3 {
@@ -1433,12 +2063,15 @@ func strFindOrIndex(f *Frame, args Args, fn indexFunc) (*Object, *BaseException)
 }
 
 func strJustDecodeArgs(f *Frame, args Args, name string) (string, int, string, *BaseException) {
-	expectedTypes := []*Type{StrType, IntType, StrType}
+	expectedTypes := []*Type{StrType, ObjectType, StrType}
 	if raised := checkMethodArgs(f, name, args, expectedTypes...); raised != nil {
 		return "", 0, "", raised
 	}
 	s := toStrUnsafe(args[0]).Value()
-	width := toIntUnsafe(args[1]).Value()
+	width, raised := ToIntValue(f, args[1])
+	if raised != nil {
+		return "", 0, "", raised
+	}
 	fill := toStrUnsafe(args[2]).Value()
 
 	if numChars := len(fill); numChars != 1 {
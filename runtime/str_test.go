@@ -16,9 +16,11 @@ package grumpy
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -30,6 +32,37 @@ func TestNewStr(t *testing.T) {
 	}
 }
 
+func TestNewStrTable(t *testing.T) {
+	interned := InternStr("TestNewStrTable")
+	strs := NewStrTable("TestNewStrTable", "foo bar")
+	if len(strs) != 2 || strs[0] != interned || strs[1].Value() != "foo bar" {
+		t.Errorf(`NewStrTable("TestNewStrTable", "foo bar") = %v, want ['TestNewStrTable', 'foo bar'] with the first interned`, strs)
+	}
+}
+
+func TestBuiltinIntern(t *testing.T) {
+	intern := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		return builtinIntern(f, args, nil)
+	})
+	strSubclass := newTestClass("StrSubclass", []*Type{StrType}, NewDict())
+	cases := []invokeTestCase{
+		{args: wrapArgs("foo"), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(newObject(strSubclass)), wantExc: mustCreateException(TypeErrorType, "can't intern subclass of string")},
+		{args: wrapArgs(NewUnicode("foo")), wantExc: mustCreateException(TypeErrorType, `'intern' requires a 'str' object but received a "unicode"`)},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(intern, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	// Strings not interned at initialization are interned on first use.
+	s1, s2 := NewStr("not interned at init").ToObject(), NewStr("not interned at init").ToObject()
+	f := NewRootFrame()
+	if got1, got2 := mustNotRaise(builtinIntern(f, Args{s1}, nil)), mustNotRaise(builtinIntern(f, Args{s2}, nil)); got1 != s1 || got2 != s1 {
+		t.Errorf("intern(%v) returned distinct objects %p and %p, want %p", s1, got1, got2, s1)
+	}
+}
+
 func BenchmarkNewStr(b *testing.B) {
 	var ret *Str
 	for i := 0; i < b.N; i++ {
@@ -62,6 +95,26 @@ func TestHashString(t *testing.T) {
 	}
 }
 
+func TestStrHashCache(t *testing.T) {
+	f := NewRootFrame()
+	// Strs built at runtime, e.g. by concatenation, cache their hash on
+	// first use just like interned ones.
+	s := toStrUnsafe(mustNotRaise(Add(f, NewStr("foo").ToObject(), NewStr("bar").ToObject())))
+	if s.hash != nil {
+		t.Fatalf("%v has a hash before being hashed", s)
+	}
+	h, raised := Hash(f, s.ToObject())
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if s.hash != h {
+		t.Errorf("hash(%v) was not cached", s)
+	}
+	if h.Value() != hashString("foobar") {
+		t.Errorf("hash(%v) = %v, want %v", s, h, hashString("foobar"))
+	}
+}
+
 func TestStrBinaryOps(t *testing.T) {
 	fun := wrapFuncForTest(func(f *Frame, fn binaryOpFunc, v *Object, w *Object) (*Object, *BaseException) {
 		return fn(f, v, w)
@@ -87,10 +140,64 @@ func TestStrBinaryOps(t *testing.T) {
 		{args: wrapArgs(Mod, "%6r", "abc"), want: NewStr(" 'abc'").ToObject()},
 		{args: wrapArgs(Mod, "%06r", "abc"), want: NewStr(" 'abc'").ToObject()},
 		{args: wrapArgs(Mod, "%s %s", true), wantExc: mustCreateException(TypeErrorType, "not enough arguments for format string")},
-		{args: wrapArgs(Mod, "%Z", None), wantExc: mustCreateException(ValueErrorType, "invalid format spec")},
-		{args: wrapArgs(Mod, "%s", NewDict()), wantExc: mustCreateException(NotImplementedErrorType, "mappings not yet supported")},
-		{args: wrapArgs(Mod, "% d", 23), wantExc: mustCreateException(NotImplementedErrorType, "conversion flags not yet supported")},
-		{args: wrapArgs(Mod, "%.3f", 102.1), wantExc: mustCreateException(NotImplementedErrorType, "field width not yet supported")},
+		{args: wrapArgs(Mod, "%Z", None), wantExc: mustCreateException(ValueErrorType, "unsupported format character 'Z' (0x5a) at index 1")},
+		{args: wrapArgs(Mod, "abc %", NewTuple()), wantExc: mustCreateException(ValueErrorType, "incomplete format")},
+		{args: wrapArgs(Mod, "%s", NewDict()), want: NewStr("{}").ToObject()},
+		{args: wrapArgs(Mod, "%(foo)s-%(bar)03d", newTestDict("foo", "abc", "bar", 7)), want: NewStr("abc-007").ToObject()},
+		{args: wrapArgs(Mod, "%(a(b))r %%", newTestDict("a(b)", None)), want: NewStr("None %").ToObject()},
+		{args: wrapArgs(Mod, "%(foo)s", newTestDict("bar", 1)), wantExc: mustCreateException(KeyErrorType, "foo")},
+		{args: wrapArgs(Mod, "%(foo", NewDict()), wantExc: mustCreateException(ValueErrorType, "incomplete format key")},
+		{args: wrapArgs(Mod, "%(foo)s", newTestTuple(1)), wantExc: mustCreateException(TypeErrorType, "format requires a mapping")},
+		{args: wrapArgs(Mod, "%(foo)s %s", newTestDict("foo", 1)), wantExc: mustCreateException(TypeErrorType, "not enough arguments for format string")},
+		{args: wrapArgs(Mod, "% d|% d", newTestTuple(23, -23)), want: NewStr(" 23|-23").ToObject()},
+		{args: wrapArgs(Mod, "%+05d", 3), want: NewStr("+0003").ToObject()},
+		{args: wrapArgs(Mod, "%-05d|", 3), want: NewStr("3    |").ToObject()},
+		{args: wrapArgs(Mod, "%-4s|%-3c|%-3%|", newTestTuple("a", "b")), want: NewStr("a   |b  |%  |").ToObject()},
+		{args: wrapArgs(Mod, "%#o %#.3o %#o", newTestTuple(8, 8, 0)), want: NewStr("010 010 0").ToObject()},
+		{args: wrapArgs(Mod, "%#x|%#07X|%#6x", newTestTuple(-255, 255, 10)), want: NewStr("-0xff|0X000FF|   0xa").ToObject()},
+		{args: wrapArgs(Mod, "%+x", NewLong(big.NewInt(-255))), want: NewStr("-ff").ToObject()},
+		{args: wrapArgs(Mod, "%*d", newTestTuple(5, 102)), want: NewStr("  102").ToObject()},
+		{args: wrapArgs(Mod, "%-*d|", newTestTuple(-4, 5)), want: NewStr("5   |").ToObject()},
+		{args: wrapArgs(Mod, "%*.*f", newTestTuple(8, 2, 3.14159)), want: NewStr("    3.14").ToObject()},
+		{args: wrapArgs(Mod, "%.*s", newTestTuple(-1, "abc")), want: NewStr("").ToObject()},
+		{args: wrapArgs(Mod, "%*d", newTestTuple("3", 102)), wantExc: mustCreateException(TypeErrorType, "* wants int")},
+		{args: wrapArgs(Mod, "%*d", newTestTuple(3)), wantExc: mustCreateException(TypeErrorType, "not enough arguments for format string")},
+		{args: wrapArgs(Mod, "%+f|% e", newTestTuple(math.Inf(1), 1.5)), want: NewStr("+inf| 1.500000e+00").ToObject()},
+		{args: wrapArgs(Mod, "%f", math.Copysign(0, -1)), want: NewStr("-0.000000").ToObject()},
+		{args: wrapArgs(Mod, "%#.0f|%#g|%#.2e", newTestTuple(2, 0.5, 3)), want: NewStr("2.|0.500000|3.00e+00").ToObject()},
+		{args: wrapArgs(Mod, "%ld %hi %Lf", newTestTuple(1, 2, 0.5)), want: NewStr("1 2 0.500000").ToObject()},
+		{args: wrapArgs(Mod, "", newTestList(1)), want: NewStr("").ToObject()},
+		{args: wrapArgs(Mod, "%.3f", 102.1), want: NewStr("102.100").ToObject()},
+		{args: wrapArgs(Mod, "%8.2f", -3.14159), want: NewStr("   -3.14").ToObject()},
+		{args: wrapArgs(Mod, "%08.2f", -3.14159), want: NewStr("-0003.14").ToObject()},
+		{args: wrapArgs(Mod, "%.f", 2.5), want: NewStr("2").ToObject()},
+		{args: wrapArgs(Mod, "%F", math.Inf(1)), want: NewStr("INF").ToObject()},
+		{args: wrapArgs(Mod, "%f", math.NaN()), want: NewStr("nan").ToObject()},
+		{args: wrapArgs(Mod, "%05f", math.Inf(-1)), want: NewStr(" -inf").ToObject()},
+		{args: wrapArgs(Mod, "%e", 12345.678), want: NewStr("1.234568e+04").ToObject()},
+		{args: wrapArgs(Mod, "%.2E", 0.000123), want: NewStr("1.23E-04").ToObject()},
+		{args: wrapArgs(Mod, "%12.3e", 1e100), want: NewStr("  1.000e+100").ToObject()},
+		{args: wrapArgs(Mod, "%e", 3), want: NewStr("3.000000e+00").ToObject()},
+		{args: wrapArgs(Mod, "%g", 0.5), want: NewStr("0.5").ToObject()},
+		{args: wrapArgs(Mod, "%g", 100000.0), want: NewStr("100000").ToObject()},
+		{args: wrapArgs(Mod, "%g", 1e6), want: NewStr("1e+06").ToObject()},
+		{args: wrapArgs(Mod, "%g", 0.00001), want: NewStr("1e-05").ToObject()},
+		{args: wrapArgs(Mod, "%.3g", 3.14159), want: NewStr("3.14").ToObject()},
+		{args: wrapArgs(Mod, "%.0g", 123.0), want: NewStr("1e+02").ToObject()},
+		{args: wrapArgs(Mod, "%10.4G", 1.5e-10), want: NewStr("   1.5E-10").ToObject()},
+		{args: wrapArgs(Mod, "%g", "foo"), wantExc: mustCreateException(TypeErrorType, "float argument required, not str")},
+		{args: wrapArgs(Mod, "%c", 65), want: NewStr("A").ToObject()},
+		{args: wrapArgs(Mod, "%c", NewLong(big.NewInt(97))), want: NewStr("a").ToObject()},
+		{args: wrapArgs(Mod, "%3c", "x"), want: NewStr("  x").ToObject()},
+		{args: wrapArgs(Mod, "%c", 256), wantExc: mustCreateException(OverflowErrorType, "%c arg not in range(256)")},
+		{args: wrapArgs(Mod, "%c", -1), wantExc: mustCreateException(OverflowErrorType, "%c arg not in range(256)")},
+		{args: wrapArgs(Mod, "%c", "ab"), wantExc: mustCreateException(TypeErrorType, "%c requires int or char")},
+		{args: wrapArgs(Mod, "%c", 1.5), wantExc: mustCreateException(TypeErrorType, "%c requires int or char")},
+		{args: wrapArgs(Mod, "%.2s", "abc"), want: NewStr("ab").ToObject()},
+		{args: wrapArgs(Mod, "%5.1r", "abc"), want: NewStr("    '").ToObject()},
+		{args: wrapArgs(Mod, "%.3d", -5), want: NewStr("-005").ToObject()},
+		{args: wrapArgs(Mod, "%6.3x", 255), want: NewStr("   0ff").ToObject()},
+		{args: wrapArgs(Mod, "%i %u", newTestTuple(1, 2)), want: NewStr("1 2").ToObject()},
 		{args: wrapArgs(Mod, "%x", 0x1f), want: NewStr("1f").ToObject()},
 		{args: wrapArgs(Mod, "%X", 0xffff), want: NewStr("FFFF").ToObject()},
 		{args: wrapArgs(Mod, "%x", 1.2), want: NewStr("1").ToObject()},
@@ -122,6 +229,56 @@ func TestStrBinaryOps(t *testing.T) {
 	}
 }
 
+func TestStrConcat(t *testing.T) {
+	f := NewRootFrame()
+	piece := strings.Repeat("x", strConcatMinLen)
+	s := NewStr("")
+	for i := 0; i < 100; i++ {
+		s = toStrUnsafe(mustNotRaise(Add(f, s.ToObject(), NewStr(piece).ToObject())))
+	}
+	if want := strings.Repeat(piece, 100); s.Value() != want {
+		t.Errorf("concatenated str had length %d, want %d", len(s.Value()), len(want))
+	}
+	// Appending to a str that has since been extended must not clobber
+	// the bytes of the str it was extended to.
+	a := toStrUnsafe(mustNotRaise(Add(f, s.ToObject(), NewStr("a").ToObject())))
+	b := toStrUnsafe(mustNotRaise(Add(f, s.ToObject(), NewStr("b").ToObject())))
+	c := toStrUnsafe(mustNotRaise(Add(f, a.ToObject(), NewStr("c").ToObject())))
+	for _, cas := range []struct {
+		s    *Str
+		want string
+	}{
+		{a, s.Value() + "a"},
+		{b, s.Value() + "b"},
+		{c, s.Value() + "ac"},
+	} {
+		if got := cas.s.Value(); got != cas.want {
+			t.Errorf("concatenated str ends with %q, want %q", got[len(got)-2:], cas.want[len(cas.want)-2:])
+		}
+	}
+}
+
+func TestNewStrFromBuilder(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("foo")
+	s := NewStrFromBuilder(&b)
+	b.WriteString("bar")
+	if got := s.Value(); got != "foo" {
+		t.Errorf("NewStrFromBuilder(b).Value() = %q, want %q", got, "foo")
+	}
+}
+
+func BenchmarkStrConcat(b *testing.B) {
+	f := NewRootFrame()
+	piece := NewStr("foo").ToObject()
+	for i := 0; i < b.N; i++ {
+		s := NewStr("").ToObject()
+		for j := 0; j < 1000; j++ {
+			s = mustNotRaise(IAdd(f, s, piece))
+		}
+	}
+}
+
 func TestStrCompare(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs("", ""), want: compareAllResultEq},
@@ -157,6 +314,8 @@ func TestStrDecode(t *testing.T) {
 		{args: wrapArgs("foo"), want: NewUnicode("foo").ToObject()},
 		{args: wrapArgs("foo\xffbar", "utf8", "replace"), want: NewUnicode("foo\ufffdbar").ToObject()},
 		{args: wrapArgs("foo\xffbar", "utf8", "ignore"), want: NewUnicode("foobar").ToObject()},
+		{args: wrapArgs("foo\xffbar", NewUnicode("utf8"), NewUnicode("ignore")), want: NewUnicode("foobar").ToObject()},
+		{args: wrapArgs("foo", 42), wantExc: mustCreateException(TypeErrorType, "'decode' requires a 'basestring' object but received a 'int'")},
 		// Bad error handler name only triggers LookupError when an
 		// error is encountered.
 		{args: wrapArgs("foobar", "utf8", "noexist"), want: NewUnicode("foobar").ToObject()},
@@ -195,7 +354,11 @@ func TestStrGetItem(t *testing.T) {
 		{args: wrapArgs("baz", -4), wantExc: mustCreateException(IndexErrorType, "index out of range")},
 		{args: wrapArgs("", 0), wantExc: mustCreateException(IndexErrorType, "index out of range")},
 		{args: wrapArgs("foo", 3), wantExc: mustCreateException(IndexErrorType, "index out of range")},
+		{args: wrapArgs("foo", new(big.Int).Lsh(big.NewInt(1), 100)), wantExc: mustCreateException(IndexErrorType, "cannot fit 'long' into an index-sized integer")},
 		{args: wrapArgs("bar", newTestSlice(None, 2)), want: NewStr("ba").ToObject()},
+		{args: wrapArgs("bar", newTestSlice(new(big.Int).Lsh(big.NewInt(-1), 100), None)), want: NewStr("bar").ToObject()},
+		{args: wrapArgs("bar", newTestSlice(None, new(big.Int).Lsh(big.NewInt(1), 100))), want: NewStr("bar").ToObject()},
+		{args: wrapArgs("bar", newTestSlice(None, new(big.Int).Lsh(big.NewInt(-1), 100))), want: NewStr("").ToObject()},
 		{args: wrapArgs("bar", newTestSlice(1, 3)), want: NewStr("ar").ToObject()},
 		{args: wrapArgs("bar", newTestSlice(1, None)), want: NewStr("ar").ToObject()},
 		{args: wrapArgs("foobarbaz", newTestSlice(1, 8, 2)), want: NewStr("obra").ToObject()},
@@ -231,6 +394,11 @@ func TestStrNew(t *testing.T) {
 			return newObject(ObjectType), nil
 		}).ToObject(),
 	}))
+	unicodeSlotType := newTestClass("UnicodeSlot", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__str__": newBuiltinFunction("__str__", func(_ *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return NewUnicode("abc").ToObject(), nil
+		}).ToObject(),
+	}))
 	slotSubTypeType := newTestClass("SlotSubType", []*Type{ObjectType}, newStringDict(map[string]*Object{
 		"__str__": newBuiltinFunction("__str__", func(_ *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
 			return subTypeObject, nil
@@ -248,6 +416,7 @@ func TestStrNew(t *testing.T) {
 		{args: wrapArgs(StrType, newObject(goodSlotType)), want: NewStr("abc").ToObject()},
 		{args: wrapArgs(StrType, newObject(badSlotType)), wantExc: mustCreateException(TypeErrorType, "__str__ returned non-string (type object)")},
 		{args: wrapArgs(StrType, newObject(slotSubTypeType)), want: subTypeObject},
+		{args: wrapArgs(StrType, newObject(unicodeSlotType)), want: NewStr("abc").ToObject()},
 		{args: wrapArgs(strictEqType, newObject(goodSlotType)), want: (&Str{Object: Object{typ: strictEqType}, value: "abc"}).ToObject()},
 		{args: wrapArgs(strictEqType, newObject(badSlotType)), wantExc: mustCreateException(TypeErrorType, "__str__ returned non-string (type object)")},
 	}
@@ -272,6 +441,14 @@ func TestStrRepr(t *testing.T) {
 }
 
 func TestStrMethods(t *testing.T) {
+	rot13 := make([]byte, 256)
+	for i := range rot13 {
+		rot13[i] = byte(i)
+		if i >= 'a' && i <= 'z' {
+			rot13[i] = byte('a' + (i-'a'+13)%26)
+		}
+	}
+	rot13Table := string(rot13)
 	fooType := newTestClass("Foo", []*Type{ObjectType}, newStringDict(map[string]*Object{"bar": None}))
 	intIndexType := newTestClass("IntIndex", []*Type{ObjectType}, newStringDict(map[string]*Object{
 		"__index__": newBuiltinFunction("__index__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
@@ -316,6 +493,7 @@ func TestStrMethods(t *testing.T) {
 		{"count", wrapArgs("five", ""), NewInt(5).ToObject(), nil},
 		{"count", wrapArgs("abba", "bb"), NewInt(1).ToObject(), nil},
 		{"count", wrapArgs("abbba", "bb"), NewInt(1).ToObject(), nil},
+		{"count", wrapArgs("abab", NewUnicode("ab")), NewInt(2).ToObject(), nil},
 		{"count", wrapArgs("abbbba", "bb"), NewInt(2).ToObject(), nil},
 		{"count", wrapArgs("abcdeffdeabcb", "b"), NewInt(3).ToObject(), nil},
 		{"count", wrapArgs(""), nil, mustCreateException(TypeErrorType, "'count' of 'str' requires 2 arguments")},
@@ -327,9 +505,17 @@ func TestStrMethods(t *testing.T) {
 		{"endswith", wrapArgs("foobar", "bar", 3, 5), False.ToObject(), nil},
 		{"endswith", wrapArgs("foobar", "bar", 5, 3), False.ToObject(), nil},
 		{"endswith", wrapArgs("bar", "foobar"), False.ToObject(), nil},
+		{"endswith", wrapArgs("foobar", NewUnicode("bar")), True.ToObject(), nil},
 		{"endswith", wrapArgs("foo", newTestTuple("barfoo", "oo").ToObject()), True.ToObject(), nil},
 		{"endswith", wrapArgs("foo", 123), nil, mustCreateException(TypeErrorType, "endswith first arg must be str, unicode, or tuple, not int")},
+		{"expandtabs", wrapArgs("a\tbc\td\n\tx"), NewStr("a       bc      d\n        x").ToObject(), nil},
+		{"expandtabs", wrapArgs("ab\tc", 4), NewStr("ab  c").ToObject(), nil},
+		{"expandtabs", wrapArgs("ab\tc", big.NewInt(4)), NewStr("ab  c").ToObject(), nil},
+		{"expandtabs", wrapArgs("a\tb", 0), NewStr("ab").ToObject(), nil},
+		{"expandtabs", wrapArgs("a\tb", -1), NewStr("ab").ToObject(), nil},
+		{"expandtabs", wrapArgs("a\tb", "4"), nil, mustCreateException(TypeErrorType, "an integer is required")},
 		{"endswith", wrapArgs("foo", newTestTuple(123).ToObject()), nil, mustCreateException(TypeErrorType, "expected a str")},
+		{"endswith", wrapArgs("foo", newTestTuple(NewUnicode("oo"), "bar").ToObject()), True.ToObject(), nil},
 		{"find", wrapArgs("", ""), NewInt(0).ToObject(), nil},
 		{"find", wrapArgs("", "", 1), NewInt(-1).ToObject(), nil},
 		{"find", wrapArgs("", "", -1), NewInt(0).ToObject(), nil},
@@ -343,7 +529,9 @@ func TestStrMethods(t *testing.T) {
 		{"find", wrapArgs("foobar", "bar", newObject(longIndexType)), NewInt(3).ToObject(), nil},
 		{"find", wrapArgs("foobar", "bar", None, newObject(longIndexType)), NewInt(-1).ToObject(), nil},
 		// TODO: Support unicode substring.
-		{"find", wrapArgs("foobar", NewUnicode("bar")), nil, mustCreateException(TypeErrorType, "'find/index' requires a 'str' object but received a 'unicode'")},
+		{"find", wrapArgs("foobar", NewUnicode("bar")), NewInt(3).ToObject(), nil},
+		{"find", wrapArgs("foo\xc3\xa9bar", NewUnicode("bar")), NewInt(4).ToObject(), nil},
+		{"find", wrapArgs("foo\xffbar", NewUnicode("bar")), nil, mustCreateException(UnicodeDecodeErrorType, "'utf8' codec can't decode byte 0xff in position 3")},
 		{"find", wrapArgs("foobar", "bar", "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"find", wrapArgs("foobar", "bar", 0, "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"find", wrapArgs("foobar", "bar", None), NewInt(3).ToObject(), nil},
@@ -375,7 +563,8 @@ func TestStrMethods(t *testing.T) {
 		{"index", wrapArgs("foobar", "bar", newObject(longIndexType)), NewInt(3).ToObject(), nil},
 		{"index", wrapArgs("foobar", "bar", None, newObject(longIndexType)), nil, mustCreateException(ValueErrorType, "substring not found")},
 		//TODO: Support unicode substring.
-		{"index", wrapArgs("foobar", NewUnicode("bar")), nil, mustCreateException(TypeErrorType, "'find/index' requires a 'str' object but received a 'unicode'")},
+		{"index", wrapArgs("foobar", NewUnicode("bar")), NewInt(3).ToObject(), nil},
+		{"index", wrapArgs("foobar", NewUnicode("baz")), nil, mustCreateException(ValueErrorType, "substring not found")},
 		{"index", wrapArgs("foobar", "bar", "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"index", wrapArgs("foobar", "bar", 0, "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"index", wrapArgs("foobar", "bar", None), NewInt(3).ToObject(), nil},
@@ -467,6 +656,10 @@ func TestStrMethods(t *testing.T) {
 		{"lstrip", wrapArgs("foo", "bar", "baz"), nil, mustCreateException(TypeErrorType, "'strip' of 'str' requires 2 arguments")},
 		{"lstrip", wrapArgs("\xfboo", NewUnicode("o")), nil, mustCreateException(UnicodeDecodeErrorType, "'utf8' codec can't decode byte 0xfb in position 0")},
 		{"lstrip", wrapArgs("foo", NewUnicode("o")), NewUnicode("f").ToObject(), nil},
+		{"partition", wrapArgs("a,b,c", ","), newTestTuple("a", ",", "b,c").ToObject(), nil},
+		{"partition", wrapArgs("abc", ","), newTestTuple("abc", "", "").ToObject(), nil},
+		{"partition", wrapArgs("abc", ""), nil, mustCreateException(ValueErrorType, "empty separator")},
+		{"partition", wrapArgs("abc", 1), nil, mustCreateException(TypeErrorType, "'partition' requires a 'str' object but received a 'int'")},
 		{"rfind", wrapArgs("", ""), NewInt(0).ToObject(), nil},
 		{"rfind", wrapArgs("", "", 1), NewInt(-1).ToObject(), nil},
 		{"rfind", wrapArgs("", "", -1), NewInt(0).ToObject(), nil},
@@ -480,7 +673,7 @@ func TestStrMethods(t *testing.T) {
 		{"rfind", wrapArgs("foobar", "bar", newObject(longIndexType)), NewInt(3).ToObject(), nil},
 		{"rfind", wrapArgs("foobar", "bar", None, newObject(longIndexType)), NewInt(-1).ToObject(), nil},
 		//r TODO: Support unicode substring.
-		{"rfind", wrapArgs("foobar", NewUnicode("bar")), nil, mustCreateException(TypeErrorType, "'find/index' requires a 'str' object but received a 'unicode'")},
+		{"rfind", wrapArgs("foobarbar", NewUnicode("bar")), NewInt(6).ToObject(), nil},
 		{"rfind", wrapArgs("foobar", "bar", "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"rfind", wrapArgs("foobar", "bar", 0, "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"rfind", wrapArgs("foobar", "bar", None), NewInt(3).ToObject(), nil},
@@ -514,7 +707,7 @@ func TestStrMethods(t *testing.T) {
 		{"rindex", wrapArgs("foobar", "bar", newObject(longIndexType)), NewInt(3).ToObject(), nil},
 		{"rindex", wrapArgs("foobar", "bar", None, newObject(longIndexType)), nil, mustCreateException(ValueErrorType, "substring not found")},
 		// TODO: Support unicode substring.
-		{"rindex", wrapArgs("foobar", NewUnicode("bar")), nil, mustCreateException(TypeErrorType, "'find/index' requires a 'str' object but received a 'unicode'")},
+		{"rindex", wrapArgs("foobarbar", NewUnicode("bar")), NewInt(6).ToObject(), nil},
 		{"rindex", wrapArgs("foobar", "bar", "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"rindex", wrapArgs("foobar", "bar", 0, "baz"), nil, mustCreateException(TypeErrorType, "slice indices must be integers or None or have an __index__ method")},
 		{"rindex", wrapArgs("foobar", "bar", None), NewInt(3).ToObject(), nil},
@@ -536,16 +729,35 @@ func TestStrMethods(t *testing.T) {
 		{"rindex", wrapArgs("barbaz", "ba"), NewInt(3).ToObject(), nil},
 		{"rindex", wrapArgs("barbaz", "ba", None, 4), NewInt(0).ToObject(), nil},
 		{"rjust", wrapArgs("foobar", 10, "#"), NewStr("####foobar").ToObject(), nil},
+		{"rjust", wrapArgs("foobar", big.NewInt(8), "#"), NewStr("##foobar").ToObject(), nil},
+		{"rjust", wrapArgs("foobar", new(big.Int).Lsh(big.NewInt(1), 100), "#"), nil, mustCreateException(OverflowErrorType, "Python int too large to convert to a Go int")},
 		{"rjust", wrapArgs("foobar", 3, "#"), NewStr("foobar").ToObject(), nil},
 		{"rjust", wrapArgs("foobar", -1, "#"), NewStr("foobar").ToObject(), nil},
 		{"rjust", wrapArgs("foobar", 10, "##"), nil, mustCreateException(TypeErrorType, "rjust() argument 2 must be char, not str")},
 		{"rjust", wrapArgs("foobar", 10, ""), nil, mustCreateException(TypeErrorType, "rjust() argument 2 must be char, not str")},
+		{"rpartition", wrapArgs("a,b,c", ","), newTestTuple("a,b", ",", "c").ToObject(), nil},
+		{"rpartition", wrapArgs("abc", ","), newTestTuple("", "", "abc").ToObject(), nil},
+		{"rpartition", wrapArgs("abc", ""), nil, mustCreateException(ValueErrorType, "empty separator")},
+		{"rsplit", wrapArgs(" a  b c "), newTestList("a", "b", "c").ToObject(), nil},
+		{"rsplit", wrapArgs(" a b c ", None, 1), newTestList(" a b", "c").ToObject(), nil},
+		{"rsplit", wrapArgs(" a b c ", None, big.NewInt(1)), newTestList(" a b", "c").ToObject(), nil},
+		{"rsplit", wrapArgs("  a b ", None, 0), newTestList("  a b").ToObject(), nil},
+		{"rsplit", wrapArgs("\t\n "), newTestList().ToObject(), nil},
+		{"rsplit", wrapArgs("a,b,,c", ","), newTestList("a", "b", "", "c").ToObject(), nil},
+		{"rsplit", wrapArgs("a,b,c", ",", 1), newTestList("a,b", "c").ToObject(), nil},
+		{"rsplit", wrapArgs("a--b--c", "--", 5), newTestList("a", "b", "c").ToObject(), nil},
+		{"rsplit", wrapArgs("", "x"), newTestList("").ToObject(), nil},
+		{"rsplit", wrapArgs("abc", "", 1), nil, mustCreateException(ValueErrorType, "empty separator")},
+		{"rsplit", wrapArgs("abc", 1), nil, mustCreateException(TypeErrorType, "expected a str separator")},
 		{"split", wrapArgs("foo,bar", ","), newTestList("foo", "bar").ToObject(), nil},
 		{"split", wrapArgs("1,2,3", ",", 1), newTestList("1", "2,3").ToObject(), nil},
+		{"split", wrapArgs("1,2,3", NewUnicode(","), 1), newTestList(NewUnicode("1"), NewUnicode("2,3")).ToObject(), nil},
 		{"split", wrapArgs("a \tb\nc"), newTestList("a", "b", "c").ToObject(), nil},
 		{"split", wrapArgs("a \tb\nc", None), newTestList("a", "b", "c").ToObject(), nil},
 		{"split", wrapArgs("a \tb\nc", None, -1), newTestList("a", "b", "c").ToObject(), nil},
 		{"split", wrapArgs("a \tb\nc", None, 1), newTestList("a", "b\nc").ToObject(), nil},
+		{"split", wrapArgs("a \tb\nc", None, big.NewInt(1)), newTestList("a", "b\nc").ToObject(), nil},
+		{"split", wrapArgs("a b", None, "1"), nil, mustCreateException(TypeErrorType, "an integer is required")},
 		{"split", wrapArgs("foo", 1), nil, mustCreateException(TypeErrorType, "expected a str separator")},
 		{"split", wrapArgs("foo", ""), nil, mustCreateException(ValueErrorType, "empty separator")},
 		{"split", wrapArgs(""), newTestList().ToObject(), nil},
@@ -571,6 +783,10 @@ func TestStrMethods(t *testing.T) {
 		{"startswith", wrapArgs("", ""), True.ToObject(), nil},
 		{"startswith", wrapArgs("", "", 1), False.ToObject(), nil},
 		{"startswith", wrapArgs("foobar", "foo"), True.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", NewUnicode("foo"), 1), False.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", "bar", big.NewInt(3)), True.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", "bar", newObject(intIndexType), None), False.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", "obar", newObject(intIndexType), None), True.ToObject(), nil},
 		{"startswith", wrapArgs("foobar", "foo", 2), False.ToObject(), nil},
 		{"startswith", wrapArgs("foobar", "bar", 3), True.ToObject(), nil},
 		{"startswith", wrapArgs("foobar", "bar", 3, 5), False.ToObject(), nil},
@@ -578,8 +794,11 @@ func TestStrMethods(t *testing.T) {
 		{"startswith", wrapArgs("foo", "foobar"), False.ToObject(), nil},
 		{"startswith", wrapArgs("foo", newTestTuple("foobar", "fo").ToObject()), True.ToObject(), nil},
 		{"startswith", wrapArgs("foo", 123), nil, mustCreateException(TypeErrorType, "startswith first arg must be str, unicode, or tuple, not int")},
-		{"startswith", wrapArgs("foo", "f", "123"), nil, mustCreateException(TypeErrorType, "'startswith' requires a 'int' object but received a 'str'")},
+		{"startswith", wrapArgs("foo", "f", "123"), nil, mustCreateException(TypeErrorType, errBadSliceIndex)},
 		{"startswith", wrapArgs("foo", newTestTuple(123).ToObject()), nil, mustCreateException(TypeErrorType, "expected a str")},
+		{"startswith", wrapArgs("foo", newTestTuple("bar", NewUnicode("fo")).ToObject()), True.ToObject(), nil},
+		{"startswith", wrapArgs("foo", newTestTuple(NewUnicode("bar")).ToObject()), False.ToObject(), nil},
+		{"startswith", wrapArgs("\xff", newTestTuple(NewUnicode("f")).ToObject()), nil, mustCreateException(UnicodeDecodeErrorType, "'utf8' codec can't decode byte 0xff in position 0")},
 		{"strip", wrapArgs("foo "), NewStr("foo").ToObject(), nil},
 		{"strip", wrapArgs(" foo bar "), NewStr("foo bar").ToObject(), nil},
 		{"strip", wrapArgs("foo foo", "o"), NewStr("foo f").ToObject(), nil},
@@ -619,8 +838,8 @@ func TestStrMethods(t *testing.T) {
 		{"replace", wrapArgs("", "", "x", 1), NewStr("").ToObject(), nil},
 		{"replace", wrapArgs("", "", "x", 1000), NewStr("").ToObject(), nil},
 		// TODO: Support unicode substring.
-		{"replace", wrapArgs("foobar", "", NewUnicode("bar")), nil, mustCreateException(TypeErrorType, "'replace' requires a 'str' object but received a 'unicode'")},
-		{"replace", wrapArgs("foobar", NewUnicode("bar"), ""), nil, mustCreateException(TypeErrorType, "'replace' requires a 'str' object but received a 'unicode'")},
+		{"replace", wrapArgs("foobar", "", NewUnicode("bar")), NewUnicode("barfbarobarobarbbarabarrbar").ToObject(), nil},
+		{"replace", wrapArgs("foobar", NewUnicode("bar"), ""), NewUnicode("foo").ToObject(), nil},
 		{"replace", wrapArgs("foobar", "bar", "baz", None), nil, mustCreateException(TypeErrorType, "an integer is required")},
 		{"replace", wrapArgs("foobar", "bar", "baz", newObject(intIndexType)), nil, mustCreateException(TypeErrorType, "an integer is required")},
 		{"replace", wrapArgs("foobar", "bar", "baz", newObject(longIndexType)), nil, mustCreateException(TypeErrorType, "an integer is required")},
@@ -644,6 +863,14 @@ func TestStrMethods(t *testing.T) {
 		{"title", wrapArgs(123), nil, mustCreateException(TypeErrorType, "unbound method title() must be called with str instance as first argument (got int instance instead)")},
 		{"title", wrapArgs("вол"), NewStr("вол").ToObject(), nil},
 		{"title", wrapArgs("ВОЛ"), NewStr("ВОЛ").ToObject(), nil},
+		{"translate", wrapArgs("abc", None), NewStr("abc").ToObject(), nil},
+		{"translate", wrapArgs("abcb", None, "b"), NewStr("ac").ToObject(), nil},
+		{"translate", wrapArgs("abc\xff", rot13Table), NewStr("nop\xff").ToObject(), nil},
+		{"translate", wrapArgs("abc", rot13Table, "ac"), NewStr("o").ToObject(), nil},
+		{"translate", wrapArgs("abc", "x"), nil, mustCreateException(ValueErrorType, "translation table must be 256 characters long")},
+		{"translate", wrapArgs("abc", 1), nil, mustCreateException(TypeErrorType, "expected a string or other character buffer object")},
+		{"translate", wrapArgs("abc", None, 1), nil, mustCreateException(TypeErrorType, "expected a string or other character buffer object")},
+		{"translate", wrapArgs("abc", None, NewUnicode("b")), nil, mustCreateException(TypeErrorType, "deletions are implemented differently for unicode")},
 		{"upper", wrapArgs(""), NewStr("").ToObject(), nil},
 		{"upper", wrapArgs("a"), NewStr("A").ToObject(), nil},
 		{"upper", wrapArgs("A"), NewStr("A").ToObject(), nil},
@@ -697,6 +924,38 @@ func TestStrMethods(t *testing.T) {
 	}
 }
 
+func TestStrStartsEndsWithKwargs(t *testing.T) {
+	cases := []struct {
+		methodName string
+		args       Args
+		kwargs     KWArgs
+		want       *Object
+		wantExc    *BaseException
+	}{
+		{"startswith", wrapArgs("foobar", "bar"), wrapKWArgs("start", 3), True.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", "bar"), wrapKWArgs("start", 3, "end", 5), False.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", "foo"), wrapKWArgs("end", 3), True.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", newTestTuple(NewUnicode("ba"))), wrapKWArgs("start", 3), True.ToObject(), nil},
+		{"startswith", wrapArgs("foobar", "bar", 3), wrapKWArgs("start", 3), nil, mustCreateException(TypeErrorType, "startswith() got multiple values for keyword argument 'start'")},
+		{"startswith", wrapArgs("foobar", "bar"), wrapKWArgs("stop", 3), nil, mustCreateException(TypeErrorType, "startswith() got an unexpected keyword argument 'stop'")},
+		{"endswith", wrapArgs("foobar", "foo"), wrapKWArgs("end", 3), True.ToObject(), nil},
+		{"endswith", wrapArgs("foobar", "bar"), wrapKWArgs("start", 1, "end", None), True.ToObject(), nil},
+		{"endswith", wrapArgs("foobar", newTestTuple("x", NewUnicode("oob"))), wrapKWArgs("end", 4), True.ToObject(), nil},
+	}
+	for _, cas := range cases {
+		for _, typ := range []*Type{StrType, UnicodeType} {
+			args := cas.args
+			if typ == UnicodeType {
+				args = append(Args{NewUnicode(toStrUnsafe(args[0]).Value()).ToObject()}, args[1:]...)
+			}
+			testCase := invokeTestCase{args: args, kwargs: cas.kwargs, want: cas.want, wantExc: cas.wantExc}
+			if err := runInvokeMethodTestCase(typ, cas.methodName, &testCase); err != "" {
+				t.Error(err)
+			}
+		}
+	}
+}
+
 func TestStrStr(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs("foo"), want: NewStr("foo").ToObject()},
@@ -0,0 +1,474 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// StringIO represents Python 'cStringIO.StringIO' objects, in-memory files
+// backed by a byte buffer.
+type StringIO struct {
+	Object
+	mutex     sync.Mutex
+	buf       bytes.Buffer
+	pos       int
+	closed    bool
+	Softspace int `attr:"softspace" attr_mode:"rw"`
+}
+
+// NewStringIO returns a new StringIO positioned at the start of value.
+func NewStringIO(value string) *StringIO {
+	s := &StringIO{Object: Object{typ: StringIOType}}
+	s.buf.WriteString(value)
+	return s
+}
+
+func toStringIOUnsafe(o *Object) *StringIO {
+	return (*StringIO)(o.toPointer())
+}
+
+// ToObject upcasts s to an Object.
+func (s *StringIO) ToObject() *Object {
+	return &s.Object
+}
+
+// read returns up to size bytes from the current position, or everything
+// remaining when size is negative. s.mutex must be held.
+func (s *StringIO) read(size int) string {
+	b := s.buf.Bytes()
+	if s.pos >= len(b) {
+		return ""
+	}
+	end := len(b)
+	if size >= 0 && s.pos+size < end {
+		end = s.pos + size
+	}
+	data := string(b[s.pos:end])
+	s.pos = end
+	return data
+}
+
+// readLine returns the bytes up to and including the next newline, reading no
+// more than maxBytes when it is non-negative. s.mutex must be held.
+func (s *StringIO) readLine(maxBytes int) string {
+	b := s.buf.Bytes()
+	if s.pos >= len(b) {
+		return ""
+	}
+	end := len(b)
+	if i := bytes.IndexByte(b[s.pos:], '\n'); i != -1 {
+		end = s.pos + i + 1
+	}
+	if maxBytes >= 0 && s.pos+maxBytes < end {
+		end = s.pos + maxBytes
+	}
+	line := string(b[s.pos:end])
+	s.pos = end
+	return line
+}
+
+// write stores data at the current position, overwriting existing bytes and
+// padding with null bytes when positioned past the end. s.mutex must be held.
+func (s *StringIO) write(data string) {
+	if n := s.buf.Len(); s.pos > n {
+		s.buf.Write(make([]byte, s.pos-n))
+	}
+	copied := copy(s.buf.Bytes()[s.pos:], data)
+	s.buf.WriteString(data[copied:])
+	s.pos += len(data)
+}
+
+// StringIOType is the object representing the Python 'cStringIO.StringIO'
+// type.
+var StringIOType = newBasisType("StringIO", reflect.TypeOf(StringIO{}), toStringIOUnsafe, ObjectType)
+
+func stringIOInit(f *Frame, o *Object, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkFunctionVarArgs(f, "__init__", args); raised != nil {
+		return nil, raised
+	}
+	if len(args) > 1 {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("StringIO() takes at most 1 argument (%d given)", len(args)))
+	}
+	value := ""
+	if len(args) == 1 {
+		var raised *BaseException
+		if value, raised = stringIOData(f, args[0], "expected read buffer, %s found"); raised != nil {
+			return nil, raised
+		}
+	}
+	s := toStringIOUnsafe(o)
+	s.mutex.Lock()
+	s.buf.Reset()
+	s.buf.WriteString(value)
+	s.pos = 0
+	s.closed = false
+	s.mutex.Unlock()
+	return None, nil
+}
+
+func stringIOClose(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "close", args, StringIOType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	s.closed = true
+	s.buf.Reset()
+	s.pos = 0
+	s.mutex.Unlock()
+	return None, nil
+}
+
+func stringIOFlush(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "flush", args, StringIOType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	return None, nil
+}
+
+func stringIOGetClosed(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_closed", args, StringIOType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+	return GetBool(closed).ToObject(), nil
+}
+
+func stringIOGetValue(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{StringIOType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "getvalue", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	usePos := false
+	if argc > 1 {
+		var raised *BaseException
+		if usePos, raised = IsTrue(f, args[1]); raised != nil {
+			return nil, raised
+		}
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	b := s.buf.Bytes()
+	if usePos && s.pos < len(b) {
+		b = b[:s.pos]
+	}
+	return NewStr(string(b)).ToObject(), nil
+}
+
+func stringIOIsATTY(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "isatty", args, StringIOType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	return False.ToObject(), nil
+}
+
+func stringIOIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func stringIONext(f *Frame, o *Object) (*Object, *BaseException) {
+	s := toStringIOUnsafe(o)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	line := s.readLine(-1)
+	if line == "" {
+		return nil, f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	return NewStr(line).ToObject(), nil
+}
+
+func stringIORead(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	s, size, raised := stringIOParseSizeArg(f, "read", args)
+	if raised != nil {
+		return nil, raised
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	return NewStr(s.read(size)).ToObject(), nil
+}
+
+func stringIOReadLine(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	s, size, raised := stringIOParseSizeArg(f, "readline", args)
+	if raised != nil {
+		return nil, raised
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	return NewStr(s.readLine(size)).ToObject(), nil
+}
+
+func stringIOReadLines(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	s, sizeHint, raised := stringIOParseSizeArg(f, "readlines", args)
+	if raised != nil {
+		return nil, raised
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	var lines []*Object
+	numBytesRead := 0
+	for sizeHint <= 0 || numBytesRead < sizeHint {
+		line := s.readLine(-1)
+		if line == "" {
+			break
+		}
+		lines = append(lines, NewStr(line).ToObject())
+		numBytesRead += len(line)
+	}
+	return NewList(lines...).ToObject(), nil
+}
+
+func stringIOReset(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "reset", args, StringIOType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	s.pos = 0
+	return None, nil
+}
+
+func stringIORepr(f *Frame, o *Object) (*Object, *BaseException) {
+	return NewStr(fmt.Sprintf("<cStringIO.StringIO object at %p>", o)).ToObject(), nil
+}
+
+func stringIOSeek(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{StringIOType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 {
+		expectedTypes = expectedTypes[:2]
+	}
+	if raised := checkMethodArgs(f, "seek", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	offset, raised := ToIntValue(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	whence := 0
+	if argc > 2 {
+		if whence, raised = ToIntValue(f, args[2]); raised != nil {
+			return nil, raised
+		}
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	switch whence {
+	case 0:
+	case 1:
+		offset += s.pos
+	case 2:
+		offset += s.buf.Len()
+	default:
+		return nil, f.RaiseType(IOErrorType, fmt.Sprintf("invalid whence (%d, should be 0, 1 or 2)", whence))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	s.pos = offset
+	return None, nil
+}
+
+func stringIOTell(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "tell", args, StringIOType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	return NewInt(s.pos).ToObject(), nil
+}
+
+func stringIOTruncate(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{StringIOType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "truncate", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	size := s.pos
+	if argc > 1 && args[1] != None {
+		var raised *BaseException
+		if size, raised = ToIntValue(f, args[1]); raised != nil {
+			return nil, raised
+		}
+		if size < 0 {
+			return nil, f.RaiseType(IOErrorType, "Negative size not allowed")
+		}
+	}
+	if size < s.buf.Len() {
+		s.buf.Truncate(size)
+	}
+	if s.pos > s.buf.Len() {
+		s.pos = s.buf.Len()
+	}
+	return None, nil
+}
+
+func stringIOWrite(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "write", args, StringIOType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	data, raised := stringIOData(f, args[1], "must be string or buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil, f.RaiseType(ValueErrorType, "I/O operation on closed file")
+	}
+	s.write(data)
+	return None, nil
+}
+
+func stringIOWriteLines(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "writelines", args, StringIOType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	s := toStringIOUnsafe(args[0])
+	raised := seqForEach(f, args[1], func(o *Object) *BaseException {
+		data, raised := stringIOData(f, o, "must be string or buffer, not %s")
+		if raised != nil {
+			return raised
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.closed {
+			return f.RaiseType(ValueErrorType, "I/O operation on closed file")
+		}
+		s.write(data)
+		return nil
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	return None, nil
+}
+
+func initStringIOType(dict map[string]*Object) {
+	dict["close"] = newBuiltinFunction("close", stringIOClose).ToObject()
+	dict["closed"] = newProperty(newBuiltinFunction("_get_closed", stringIOGetClosed).ToObject(), nil, nil).ToObject()
+	dict["flush"] = newBuiltinFunction("flush", stringIOFlush).ToObject()
+	dict["getvalue"] = newBuiltinFunction("getvalue", stringIOGetValue).ToObject()
+	dict["isatty"] = newBuiltinFunction("isatty", stringIOIsATTY).ToObject()
+	dict["read"] = newBuiltinFunction("read", stringIORead).ToObject()
+	dict["readline"] = newBuiltinFunction("readline", stringIOReadLine).ToObject()
+	dict["readlines"] = newBuiltinFunction("readlines", stringIOReadLines).ToObject()
+	dict["reset"] = newBuiltinFunction("reset", stringIOReset).ToObject()
+	dict["seek"] = newBuiltinFunction("seek", stringIOSeek).ToObject()
+	dict["tell"] = newBuiltinFunction("tell", stringIOTell).ToObject()
+	dict["truncate"] = newBuiltinFunction("truncate", stringIOTruncate).ToObject()
+	dict["write"] = newBuiltinFunction("write", stringIOWrite).ToObject()
+	dict["writelines"] = newBuiltinFunction("writelines", stringIOWriteLines).ToObject()
+	StringIOType.slots.Init = &initSlot{stringIOInit}
+	StringIOType.slots.Iter = &unaryOpSlot{stringIOIter}
+	StringIOType.slots.Next = &unaryOpSlot{stringIONext}
+	StringIOType.slots.Repr = &unaryOpSlot{stringIORepr}
+}
+
+// stringIOData returns the bytes held by o, which must be a str or a unicode
+// object, in which case it is encoded with the default encoding. format is
+// used to describe the error for other types.
+func stringIOData(f *Frame, o *Object, format string) (string, *BaseException) {
+	switch {
+	case o.isInstance(StrType):
+		return toStrUnsafe(o).Value(), nil
+	case o.isInstance(UnicodeType):
+		s, raised := toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
+		if raised != nil {
+			return "", raised
+		}
+		return s.Value(), nil
+	}
+	return "", f.RaiseType(TypeErrorType, fmt.Sprintf(format, o.typ.Name()))
+}
+
+func stringIOParseSizeArg(f *Frame, method string, args Args) (*StringIO, int, *BaseException) {
+	expectedTypes := []*Type{StringIOType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, method, args, expectedTypes...); raised != nil {
+		return nil, 0, raised
+	}
+	size := -1
+	if argc > 1 && args[1] != None {
+		var raised *BaseException
+		if size, raised = ToIntValue(f, args[1]); raised != nil {
+			return nil, 0, raised
+		}
+	}
+	return toStringIOUnsafe(args[0]), size, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func newClosedStringIO() *StringIO {
+	s := NewStringIO("foo")
+	s.closed = true
+	return s
+}
+
+func TestStringIOInit(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		s, raised := StringIOType.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, s, NewStr("read"), nil)
+	})
+	read := wrapFuncForTest(func(f *Frame, args ...*Object) (*Object, *BaseException) {
+		fn, raised := fun.Call(f, args, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return fn.Call(f, nil, nil)
+	})
+	cases := []invokeTestCase{
+		{want: NewStr("").ToObject()},
+		{args: wrapArgs("foo\nbar"), want: NewStr("foo\nbar").ToObject()},
+		{args: wrapArgs(NewUnicode("foo")), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(42), wantExc: mustCreateException(TypeErrorType, "expected read buffer, int found")},
+		{args: wrapArgs("foo", "bar"), wantExc: mustCreateException(TypeErrorType, "StringIO() takes at most 1 argument (2 given)")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(read, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOClose(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *StringIO) (*Tuple, *BaseException) {
+		before, raised := GetAttr(f, s.ToObject(), NewStr("closed"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if _, raised := callNativeMethod(f, s.ToObject(), "close"); raised != nil {
+			return nil, raised
+		}
+		after, raised := GetAttr(f, s.ToObject(), NewStr("closed"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(before, after), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO("foo")), want: newTestTuple(false, true).ToObject()},
+		{args: wrapArgs(newClosedStringIO()), want: newTestTuple(true, true).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOGetValue(t *testing.T) {
+	partlyRead := NewStringIO("foo\nbar")
+	partlyRead.pos = 4
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO("")), want: NewStr("").ToObject()},
+		{args: wrapArgs(partlyRead), want: NewStr("foo\nbar").ToObject()},
+		{args: wrapArgs(partlyRead, true), want: NewStr("foo\n").ToObject()},
+		{args: wrapArgs(newClosedStringIO()), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(StringIOType, "getvalue", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOIter(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO("")), want: NewList().ToObject()},
+		{args: wrapArgs(NewStringIO("foo")), want: newTestList("foo").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar\n")), want: newTestList("foo\n", "bar\n").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\r\n\nbar")), want: newTestList("foo\r\n", "\n", "bar").ToObject()},
+		{args: wrapArgs(newClosedStringIO()), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(ListType.ToObject(), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIORead(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *StringIO, args ...*Object) (*Tuple, *BaseException) {
+		result, raised := callNativeMethod(f, s.ToObject(), "read", args...)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(result, NewInt(s.pos).ToObject()), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO("foo\nbar")), want: newTestTuple("foo\nbar", 7).ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar"), 2), want: newTestTuple("fo", 2).ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar"), 100), want: newTestTuple("foo\nbar", 7).ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar"), -1), want: newTestTuple("foo\nbar", 7).ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar"), None), want: newTestTuple("foo\nbar", 7).ToObject()},
+		{args: wrapArgs(NewStringIO("foo"), "2"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
+		{args: wrapArgs(newClosedStringIO()), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOReadLine(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO("")), want: NewStr("").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar")), want: NewStr("foo\n").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar"), 2), want: NewStr("fo").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar"), 10), want: NewStr("foo\n").ToObject()},
+		{args: wrapArgs(NewStringIO("foo")), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(newClosedStringIO()), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(StringIOType, "readline", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOReadLines(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO("")), want: NewList().ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar\nbaz")), want: newTestList("foo\n", "bar\n", "baz").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar\nbaz"), 5), want: newTestList("foo\n", "bar\n").ToObject()},
+		{args: wrapArgs(NewStringIO("foo\nbar\nbaz"), 4), want: newTestList("foo\n").ToObject()},
+		{args: wrapArgs(newClosedStringIO()), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeMethodTestCase(StringIOType, "readlines", &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOSeekTell(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *StringIO, args ...*Object) (*Object, *BaseException) {
+		if _, raised := callNativeMethod(f, s.ToObject(), "seek", args...); raised != nil {
+			return nil, raised
+		}
+		return callNativeMethod(f, s.ToObject(), "tell")
+	})
+	midway := func() *StringIO {
+		s := NewStringIO("foobar")
+		s.pos = 3
+		return s
+	}
+	cases := []invokeTestCase{
+		{args: wrapArgs(midway(), 1), want: NewInt(1).ToObject()},
+		{args: wrapArgs(midway(), 1, 0), want: NewInt(1).ToObject()},
+		{args: wrapArgs(midway(), 1, 1), want: NewInt(4).ToObject()},
+		{args: wrapArgs(midway(), -2, 2), want: NewInt(4).ToObject()},
+		{args: wrapArgs(midway(), -10, 1), want: NewInt(0).ToObject()},
+		{args: wrapArgs(midway(), 10), want: NewInt(10).ToObject()},
+		{args: wrapArgs(midway(), 1, 3), wantExc: mustCreateException(IOErrorType, "invalid whence (3, should be 0, 1 or 2)")},
+		{args: wrapArgs(midway()), wantExc: mustCreateException(TypeErrorType, "'seek' of 'StringIO' requires 3 arguments")},
+		{args: wrapArgs(newClosedStringIO(), 0), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOTruncate(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *StringIO, args ...*Object) (*Tuple, *BaseException) {
+		if _, raised := callNativeMethod(f, s.ToObject(), "truncate", args...); raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(NewStr(s.buf.String()).ToObject(), NewInt(s.pos).ToObject()), nil
+	})
+	midway := func() *StringIO {
+		s := NewStringIO("foobar")
+		s.pos = 3
+		return s
+	}
+	cases := []invokeTestCase{
+		{args: wrapArgs(midway()), want: newTestTuple("foo", 3).ToObject()},
+		{args: wrapArgs(midway(), None), want: newTestTuple("foo", 3).ToObject()},
+		{args: wrapArgs(midway(), 5), want: newTestTuple("fooba", 3).ToObject()},
+		{args: wrapArgs(midway(), 1), want: newTestTuple("f", 1).ToObject()},
+		{args: wrapArgs(midway(), 10), want: newTestTuple("foobar", 3).ToObject()},
+		{args: wrapArgs(midway(), -1), wantExc: mustCreateException(IOErrorType, "Negative size not allowed")},
+		{args: wrapArgs(newClosedStringIO()), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStringIOWrite(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, s *StringIO, pos int, method string, arg *Object) (*Tuple, *BaseException) {
+		s.pos = pos
+		if _, raised := callNativeMethod(f, s.ToObject(), method, arg); raised != nil {
+			return nil, raised
+		}
+		return NewTuple2(NewStr(s.buf.String()).ToObject(), NewInt(s.pos).ToObject()), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewStringIO(""), 0, "write", "foo"), want: newTestTuple("foo", 3).ToObject()},
+		{args: wrapArgs(NewStringIO("foobar"), 6, "write", "baz"), want: newTestTuple("foobarbaz", 9).ToObject()},
+		{args: wrapArgs(NewStringIO("foobar"), 1, "write", "aa"), want: newTestTuple("faabar", 3).ToObject()},
+		{args: wrapArgs(NewStringIO("foobar"), 4, "write", "baz"), want: newTestTuple("foobbaz", 7).ToObject()},
+		{args: wrapArgs(NewStringIO("foo"), 5, "write", "x"), want: newTestTuple("foo\x00\x00x", 6).ToObject()},
+		{args: wrapArgs(NewStringIO(""), 0, "write", NewUnicode("bar")), want: newTestTuple("bar", 3).ToObject()},
+		{args: wrapArgs(NewStringIO(""), 0, "writelines", newTestList("foo\n", "bar")), want: newTestTuple("foo\nbar", 7).ToObject()},
+		{args: wrapArgs(NewStringIO(""), 0, "write", 42), wantExc: mustCreateException(TypeErrorType, "must be string or buffer, not int")},
+		{args: wrapArgs(NewStringIO(""), 0, "writelines", newTestList("foo", 42)), wantExc: mustCreateException(TypeErrorType, "must be string or buffer, not int")},
+		{args: wrapArgs(newClosedStringIO(), 0, "write", "foo"), wantExc: mustCreateException(ValueErrorType, "I/O operation on closed file")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ package grumpy
 import (
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -40,6 +41,20 @@ type threadState struct {
 	// reuse. The cache is maintained through the Frame `back` pointer as a
 	// singly linked list.
 	frameCache *Frame
+
+	// policy restricts the operations permitted on this stack when
+	// non-nil. See NewRootFrameWithPolicy.
+	policy *Policy
+	// steps counts statements executed while policy.MaxSteps is set. It
+	// is shared by all stacks started from the same root so that the
+	// budget applies to threads too.
+	steps *int64
+
+	// traceFunc is the trace function set by sys.settrace or nil.
+	traceFunc *Object
+	// tracing is true while a trace function is running so that it is not
+	// itself traced.
+	tracing bool
 }
 
 func newThreadState() *threadState {
@@ -113,7 +128,144 @@ func (m *TryableMutex) TryLock() bool {
 	}
 }
 
+// LockTimeout blocks until the mutex is available or d has elapsed. It
+// returns true if the lock was acquired. A negative d blocks indefinitely.
+func (m *TryableMutex) LockTimeout(d time.Duration) bool {
+	if d < 0 {
+		m.Lock()
+		return true
+	}
+	if m.TryLock() {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-m.c:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
 // Unlock releases the mutex's lock.
 func (m *TryableMutex) Unlock() {
 	m.c <- true
 }
+
+// TryUnlock releases the mutex's lock and returns true if it is locked,
+// otherwise it returns false.
+func (m *TryableMutex) TryUnlock() bool {
+	select {
+	case m.c <- true:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitGroup waits for a collection of goroutines to finish, like
+// sync.WaitGroup. Unlike sync.WaitGroup, a negative counter is reported to
+// the caller rather than panicking and waiting may time out.
+type WaitGroup struct {
+	mutex sync.Mutex
+	count int
+	// zero is closed whenever count is zero.
+	zero chan struct{}
+}
+
+// NewWaitGroup returns a new WaitGroup with a zero counter.
+func NewWaitGroup() *WaitGroup {
+	wg := &WaitGroup{zero: make(chan struct{})}
+	close(wg.zero)
+	return wg
+}
+
+// Add adds delta to the counter, releasing waiters if it reaches zero. It
+// returns false and leaves the counter unchanged if it would go negative.
+func (wg *WaitGroup) Add(delta int) bool {
+	wg.mutex.Lock()
+	defer wg.mutex.Unlock()
+	count := wg.count + delta
+	if count < 0 {
+		return false
+	}
+	if wg.count == 0 && count > 0 {
+		wg.zero = make(chan struct{})
+	} else if wg.count > 0 && count == 0 {
+		close(wg.zero)
+	}
+	wg.count = count
+	return true
+}
+
+// Done decrements the counter by one.
+func (wg *WaitGroup) Done() bool {
+	return wg.Add(-1)
+}
+
+// Count returns the current value of the counter.
+func (wg *WaitGroup) Count() int {
+	wg.mutex.Lock()
+	defer wg.mutex.Unlock()
+	return wg.count
+}
+
+// Wait blocks until the counter is zero.
+func (wg *WaitGroup) Wait() {
+	wg.WaitTimeout(-1)
+}
+
+// WaitTimeout blocks until the counter is zero or d has elapsed. It returns
+// true if the counter reached zero. A negative d blocks indefinitely.
+func (wg *WaitGroup) WaitTimeout(d time.Duration) bool {
+	wg.mutex.Lock()
+	zero := wg.zero
+	wg.mutex.Unlock()
+	if d < 0 {
+		<-zero
+		return true
+	}
+	select {
+	case <-zero:
+		return true
+	default:
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-zero:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Once calls a Python callable exactly once, like sync.Once.
+type Once struct {
+	once sync.Once
+	done int32
+}
+
+// NewOnce returns a new Once.
+func NewOnce() *Once {
+	return &Once{}
+}
+
+// Do calls callable if and only if Do has not been called before on o.
+// Concurrent callers block until the first call returns. An exception raised
+// by callable is raised to the caller that made the call and o is still
+// considered done.
+func (o *Once) Do(f *Frame, callable *Object) *BaseException {
+	var raised *BaseException
+	o.once.Do(func() {
+		defer atomic.StoreInt32(&o.done, 1)
+		_, raised = callable.Call(f, nil, nil)
+	})
+	return raised
+}
+
+// Done returns true once a call to Do has returned.
+func (o *Once) Done() bool {
+	return atomic.LoadInt32(&o.done) != 0
+}
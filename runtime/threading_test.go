@@ -15,7 +15,10 @@
 package grumpy
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestRecursiveMutex(t *testing.T) {
@@ -56,3 +59,109 @@ func TestRecursiveMutexUnlockFrameMismatch(t *testing.T) {
 	}()
 	m.Unlock(NewRootFrame())
 }
+
+func TestTryableMutexLockTimeout(t *testing.T) {
+	m := NewTryableMutex()
+	if !m.LockTimeout(0) {
+		t.Fatal("LockTimeout(0) on unlocked mutex returned false")
+	}
+	if m.LockTimeout(time.Millisecond) {
+		t.Error("LockTimeout(1ms) on locked mutex returned true")
+	}
+	go func() {
+		time.Sleep(time.Millisecond)
+		m.Unlock()
+	}()
+	if !m.LockTimeout(-1) {
+		t.Error("LockTimeout(-1) returned false")
+	}
+}
+
+func TestTryableMutexTryUnlock(t *testing.T) {
+	m := NewTryableMutex()
+	if m.TryUnlock() {
+		t.Error("TryUnlock on unlocked mutex returned true")
+	}
+	m.Lock()
+	if !m.TryUnlock() {
+		t.Error("TryUnlock on locked mutex returned false")
+	}
+	if !m.TryLock() {
+		t.Error("TryLock after TryUnlock returned false")
+	}
+}
+
+func TestWaitGroup(t *testing.T) {
+	wg := NewWaitGroup()
+	if !wg.WaitTimeout(0) {
+		t.Error("WaitTimeout(0) with zero counter returned false")
+	}
+	if wg.Done() {
+		t.Error("Done() with zero counter returned true")
+	}
+	if !wg.Add(3) {
+		t.Fatal("Add(3) returned false")
+	}
+	if wg.Add(-4) {
+		t.Error("Add(-4) with counter 3 returned true")
+	}
+	if got := wg.Count(); got != 3 {
+		t.Errorf("Count() = %d, want 3", got)
+	}
+	if wg.WaitTimeout(time.Millisecond) {
+		t.Error("WaitTimeout(1ms) with counter 3 returned true")
+	}
+	for i := 0; i < 3; i++ {
+		go func() {
+			time.Sleep(time.Millisecond)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	if got := wg.Count(); got != 0 {
+		t.Errorf("Count() after Wait() = %d, want 0", got)
+	}
+	// The group is reusable once the counter reaches zero.
+	wg.Add(1)
+	if wg.WaitTimeout(0) {
+		t.Error("WaitTimeout(0) after reuse returned true")
+	}
+	wg.Done()
+	if !wg.WaitTimeout(-1) {
+		t.Error("WaitTimeout(-1) returned false")
+	}
+}
+
+func TestOnce(t *testing.T) {
+	calls := 0
+	fun := newBuiltinFunction("fun", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+		calls++
+		return nil, f.RaiseType(ValueErrorType, "foo")
+	}).ToObject()
+	o := NewOnce()
+	if o.Done() {
+		t.Error("Done() before Do() returned true")
+	}
+	f := NewRootFrame()
+	var wg sync.WaitGroup
+	raisedCount := int32(0)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if raised := o.Do(NewRootFrame(), fun); raised != nil {
+				atomic.AddInt32(&raisedCount, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if raised := o.Do(f, fun); raised != nil {
+		t.Errorf("second Do() raised %v", raised)
+	}
+	if calls != 1 || raisedCount != 1 {
+		t.Errorf("callable called %d times raising %d times, want 1 and 1", calls, raisedCount)
+	}
+	if !o.Done() {
+		t.Error("Done() after Do() returned false")
+	}
+}
@@ -15,7 +15,20 @@
 package grumpy
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	// sourceLines caches the lines of the source files that appear in
+	// formatted tracebacks, keyed by filename. Files that cannot be read
+	// map to nil.
+	sourceLines      = map[string][]string{}
+	sourceLinesMutex sync.Mutex
 )
 
 // Traceback represents Python 'traceback' objects.
@@ -46,3 +59,55 @@ var TracebackType = newBasisType("traceback", reflect.TypeOf(Traceback{}), toTra
 func initTracebackType(map[string]*Object) {
 	TracebackType.flags &^= typeFlagInstantiable | typeFlagBasetype
 }
+
+// formatTraceback returns the "Traceback (most recent call last):" header and
+// the "File ..." entries for each frame in tb, outermost first, followed by
+// the corresponding source line where it's available.
+func formatTraceback(tb *Traceback) string {
+	var buf bytes.Buffer
+	for ; tb != nil; tb = tb.next {
+		code := tb.frame.code
+		if code == nil {
+			continue
+		}
+		if buf.Len() == 0 {
+			buf.WriteString("Traceback (most recent call last):\n")
+		}
+		fmt.Fprintf(&buf, "  File \"%s\", line %d, in %s\n", code.filename, tb.lineno, code.name)
+		if line := getSourceLine(code.filename, tb.lineno); line != "" {
+			fmt.Fprintf(&buf, "    %s\n", line)
+		}
+	}
+	return buf.String()
+}
+
+// formatExceptionOnly returns the last line of a formatted traceback, e.g.
+// "TypeError: foo\n", or just the type name if str(exc) is empty.
+func formatExceptionOnly(f *Frame, exc *BaseException) string {
+	s, raised := ToStr(f, exc.ToObject())
+	if raised != nil {
+		return fmt.Sprintf("%s: <unprintable %s object>\n", exc.typ.Name(), exc.typ.Name())
+	}
+	if s.Value() == "" {
+		return exc.typ.Name() + "\n"
+	}
+	return fmt.Sprintf("%s: %s\n", exc.typ.Name(), s.Value())
+}
+
+// getSourceLine returns line lineno (1-based) of filename with surrounding
+// whitespace removed, or the empty string if it's not available.
+func getSourceLine(filename string, lineno int) string {
+	sourceLinesMutex.Lock()
+	lines, ok := sourceLines[filename]
+	if !ok {
+		if b, err := ioutil.ReadFile(filename); err == nil {
+			lines = strings.Split(string(b), "\n")
+		}
+		sourceLines[filename] = lines
+	}
+	sourceLinesMutex.Unlock()
+	if lineno < 1 || lineno > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[lineno-1])
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatExcTraceback(t *testing.T) {
+	src := newTestFile("def inner():\n    raise ValueError('foo')\ndef outer():\n  inner()\n")
+	defer src.cleanup()
+	globals := NewDict()
+	inner := NewCode("inner", src.path, nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		f.SetLineno(2)
+		return nil, f.RaiseType(ValueErrorType, "foo")
+	})
+	outer := NewCode("outer", src.path, nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		f.SetLineno(4)
+		return inner.Eval(f, globals, nil, nil)
+	})
+	noSource := NewCode("<test>", "<test>", nil, 0, func(f *Frame, _ []*Object) (*Object, *BaseException) {
+		f.SetLineno(7)
+		return outer.Eval(f, globals, nil, nil)
+	})
+	f := NewRootFrame()
+	if _, raised := noSource.Eval(f, globals, nil, nil); raised == nil {
+		t.Fatal("noSource.Eval() did not raise")
+	}
+	want := fmt.Sprintf(`Traceback (most recent call last):
+  File "<test>", line 7, in <test>
+  File "%[1]s", line 4, in outer
+    inner()
+  File "%[1]s", line 2, in inner
+    raise ValueError('foo')
+ValueError: foo
+`, src.path)
+	if got := FormatExc(f); got != want {
+		t.Errorf("FormatExc() = %q, want %q", got, want)
+	}
+	// FormatExc should leave the exception in place.
+	if exc, _ := f.ExcInfo(); exc == nil || !exc.isInstance(ValueErrorType) {
+		t.Errorf("ExcInfo() = %v, want ValueError", exc)
+	}
+}
+
+func TestFormatExcUnprintable(t *testing.T) {
+	unprintableType := newTestClass("Unprintable", []*Type{ExceptionType}, newStringDict(map[string]*Object{
+		"__str__": newBuiltinFunction("__str__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			return nil, f.RaiseType(RuntimeErrorType, "bar")
+		}).ToObject(),
+	}))
+	f := NewRootFrame()
+	f.Raise(unprintableType.ToObject(), nil, nil)
+	if got, want := FormatExc(f), "Unprintable: <unprintable Unprintable object>\n"; got != want {
+		t.Errorf("FormatExc() = %q, want %q", got, want)
+	}
+	if exc, _ := f.ExcInfo(); exc == nil || !exc.isInstance(unprintableType) {
+		t.Errorf("ExcInfo() = %v, want Unprintable", exc)
+	}
+}
+
+func TestFormatExcNoException(t *testing.T) {
+	if got := FormatExc(NewRootFrame()); got != "None\n" {
+		t.Errorf(`FormatExc() = %q, want "None\n"`, got)
+	}
+}
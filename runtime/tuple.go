@@ -17,6 +17,8 @@ package grumpy
 import (
 	"fmt"
 	"reflect"
+	"sync/atomic"
+	"unsafe"
 )
 
 // Tuple represents Python 'tuple' objects.
@@ -25,6 +27,10 @@ import (
 type Tuple struct {
 	Object
 	elems []*Object
+	// hash caches the tuple's hash once computed. It is only populated
+	// when the hashes of all the elements are known never to change. It
+	// is accessed atomically.
+	hash *Int
 }
 
 // NewTuple returns a tuple containing the given elements.
@@ -205,13 +211,60 @@ func tupleGetNewArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "__getnewargs__", args, TupleType); raised != nil {
 		return nil, raised
 	}
-	return NewTuple1(args[0]).ToObject(), nil
+	return NewTuple1(NewTuple(toTupleUnsafe(args[0]).elems...).ToObject()).ToObject(), nil
 }
 
 func tupleGT(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return tupleCompare(f, toTupleUnsafe(v), w, GT)
 }
 
+// tupleHash computes the hash of a tuple from the hashes of its elements using
+// the same algorithm as CPython.
+func tupleHash(f *Frame, o *Object) (*Object, *BaseException) {
+	t := toTupleUnsafe(o)
+	p := (*unsafe.Pointer)(unsafe.Pointer(&t.hash))
+	if v := atomic.LoadPointer(p); v != unsafe.Pointer(nil) {
+		return (*Int)(v).ToObject(), nil
+	}
+	elems := t.elems
+	l := len(elems)
+	x, mult := 0x345678, 1000003
+	cacheable := true
+	for i, elem := range elems {
+		y, raised := Hash(f, elem)
+		if raised != nil {
+			return nil, raised
+		}
+		x = (x ^ y.Value()) * mult
+		mult += 82520 + 2*(l-i-1)
+		cacheable = cacheable && hashIsStable(elem)
+	}
+	x += 97531
+	if x == -1 {
+		x = -2
+	}
+	h := NewInt(x)
+	if cacheable {
+		atomic.StorePointer(p, unsafe.Pointer(h))
+	}
+	return h.ToObject(), nil
+}
+
+// hashIsStable returns true if o, which has already been hashed, is of a
+// builtin immutable type whose hash can never change. Instances of other
+// types, including subclasses of builtins, may define __hash__ in terms of
+// mutable state, so tuples containing them don't cache their hash.
+func hashIsStable(o *Object) bool {
+	switch o.typ {
+	case BoolType, FloatType, IntType, LongType, NoneType, StrType, UnicodeType:
+		return true
+	case TupleType:
+		p := (*unsafe.Pointer)(unsafe.Pointer(&toTupleUnsafe(o).hash))
+		return atomic.LoadPointer(p) != unsafe.Pointer(nil)
+	}
+	return false
+}
+
 func tupleIter(f *Frame, o *Object) (*Object, *BaseException) {
 	return newSliceIterator(reflect.ValueOf(toTupleUnsafe(o).elems)), nil
 }
@@ -229,10 +282,14 @@ func tupleLT(f *Frame, v, w *Object) (*Object, *BaseException) {
 }
 
 func tupleMul(f *Frame, v, w *Object) (*Object, *BaseException) {
-	if !w.isInstance(IntType) {
+	if !w.isInstance(IntType) && !w.isInstance(LongType) {
 		return NotImplemented, nil
 	}
-	elems, raised := seqMul(f, toTupleUnsafe(v).elems, toIntUnsafe(w).Value())
+	n, raised := indexIntChecked(f, w, OverflowErrorType)
+	if raised != nil {
+		return nil, raised
+	}
+	elems, raised := seqMul(f, toTupleUnsafe(v).elems, n)
 	if raised != nil {
 		return nil, raised
 	}
@@ -276,10 +333,14 @@ func tupleRepr(f *Frame, o *Object) (*Object, *BaseException) {
 }
 
 func tupleRMul(f *Frame, v, w *Object) (*Object, *BaseException) {
-	if !w.isInstance(IntType) {
+	if !w.isInstance(IntType) && !w.isInstance(LongType) {
 		return NotImplemented, nil
 	}
-	elems, raised := seqMul(f, toTupleUnsafe(v).elems, toIntUnsafe(w).Value())
+	n, raised := indexIntChecked(f, w, OverflowErrorType)
+	if raised != nil {
+		return nil, raised
+	}
+	elems, raised := seqMul(f, toTupleUnsafe(v).elems, n)
 	if raised != nil {
 		return nil, raised
 	}
@@ -295,6 +356,7 @@ func initTupleType(dict map[string]*Object) {
 	TupleType.slots.GE = &binaryOpSlot{tupleGE}
 	TupleType.slots.GetItem = &binaryOpSlot{tupleGetItem}
 	TupleType.slots.GT = &binaryOpSlot{tupleGT}
+	TupleType.slots.Hash = &unaryOpSlot{tupleHash}
 	TupleType.slots.Iter = &unaryOpSlot{tupleIter}
 	TupleType.slots.LE = &binaryOpSlot{tupleLE}
 	TupleType.slots.Len = &unaryOpSlot{tupleLen}
@@ -15,6 +15,7 @@
 package grumpy
 
 import (
+	"math/big"
 	"reflect"
 	"testing"
 )
@@ -50,6 +51,8 @@ func TestTupleBinaryOps(t *testing.T) {
 		{args: wrapArgs(Mul, newObject(ObjectType), newTestTuple(newObject(ObjectType))), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for *: 'object' and 'tuple'")},
 		{args: wrapArgs(Mul, NewTuple(newObject(ObjectType)), NewTuple()), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for *: 'tuple' and 'tuple'")},
 		{args: wrapArgs(Mul, NewTuple(None, None), MaxInt), wantExc: mustCreateException(OverflowErrorType, "result too large")},
+		{args: wrapArgs(Mul, newTestTuple(1), big.NewInt(2)), want: newTestTuple(1, 1).ToObject()},
+		{args: wrapArgs(Mul, newTestTuple(1), new(big.Int).Lsh(big.NewInt(-1), 100)), wantExc: mustCreateException(OverflowErrorType, "cannot fit 'long' into an index-sized integer")},
 	}
 	for _, cas := range cases {
 		if err := runInvokeTestCase(fun, &cas); err != "" {
@@ -185,6 +188,69 @@ func TestTupleGetItem(t *testing.T) {
 	}
 }
 
+func TestTupleHash(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewTuple()), want: NewInt(3527539).ToObject()},
+		{args: wrapArgs(newTestTuple(1, 2)), want: NewInt(3713081631934410656).ToObject()},
+		{args: wrapArgs(newTestTuple("foo", newTestTuple(1))), want: NewInt(-2564110257876723378).ToObject()},
+		{args: wrapArgs(newTestTuple(1, NewList())), wantExc: mustCreateException(TypeErrorType, "unhashable type: 'list'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(wrapFuncForTest(Hash), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTupleHashCache(t *testing.T) {
+	f := NewRootFrame()
+	hashCalls := 0
+	hashCountingType := newTestClass("HashCounting", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__hash__": newBuiltinFunction("__hash__", func(f *Frame, _ Args, _ KWArgs) (*Object, *BaseException) {
+			hashCalls++
+			return NewInt(hashCalls).ToObject(), nil
+		}).ToObject(),
+	}))
+	cases := []struct {
+		tuple      *Tuple
+		wantCached bool
+	}{
+		{NewTuple(), true},
+		{newTestTuple(1, 2.5, "foo", NewUnicode("bar"), None, true, big.NewInt(3)), true},
+		{newTestTuple("foo", newTestTuple(1, 2)), true},
+		{newTestTuple(newTestTuple(newObject(hashCountingType))), false},
+		{newTestTuple(1, newObject(hashCountingType)), false},
+	}
+	for _, cas := range cases {
+		h1, raised := Hash(f, cas.tuple.ToObject())
+		if raised != nil {
+			t.Fatal(raised)
+		}
+		if cached := cas.tuple.hash != nil; cached != cas.wantCached {
+			t.Errorf("hash(%v) cached = %v, want %v", cas.tuple, cached, cas.wantCached)
+		}
+		h2, raised := Hash(f, cas.tuple.ToObject())
+		if raised != nil {
+			t.Fatal(raised)
+		}
+		// Tuples containing objects whose hash changes are rehashed
+		// each time.
+		if same := h1.Value() == h2.Value(); same != cas.wantCached {
+			t.Errorf("hash(%v) returned %v then %v", cas.tuple, h1, h2)
+		}
+	}
+}
+
+func BenchmarkTupleHash(b *testing.B) {
+	f := NewRootFrame()
+	tuple := newTestTuple("foo", 42, newTestTuple("bar", 3.14)).ToObject()
+	for i := 0; i < b.N; i++ {
+		if _, raised := Hash(f, tuple); raised != nil {
+			b.Fatal(raised)
+		}
+	}
+}
+
 func TestTupleLen(t *testing.T) {
 	tuple := newTestTuple("foo", 42, "bar")
 	if got := tuple.Len(); got != 3 {
@@ -17,6 +17,11 @@ package grumpy
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+	"weak"
 )
 
 type typeFlag int
@@ -30,7 +35,10 @@ const (
 	// Set when the type can be used as a base class. This is the default.
 	// Corresponds to the Py_TPFLAGS_BASETYPE flag in CPython.
 	typeFlagBasetype typeFlag = 1 << iota
-	typeFlagDefault           = typeFlagInstantiable | typeFlagBasetype
+	// Set when instances do not have a __dict__ because the class and all
+	// of its bases (other than object) declare __slots__.
+	typeFlagNoDict  typeFlag = 1 << iota
+	typeFlagDefault          = typeFlagInstantiable | typeFlagBasetype
 )
 
 // Type represents Python 'type' objects.
@@ -42,13 +50,46 @@ type Type struct {
 	mro   []*Type
 	flags typeFlag
 	slots typeSlots
+	// attrCache holds the results of recent mroLookup calls. See
+	// typeAttrCacheEntry.
+	attrCache [typeAttrCacheSize]*typeAttrCacheEntry
+	// attrVersion is the version tag of attrCache. It is incremented when
+	// the dict of the type or of any of its bases changes.
+	attrVersion int64
+	// subclasses holds weak references to the types that list this one
+	// among their bases, so that changes to this type's dict can
+	// invalidate their caches too.
+	subclassesMutex sync.Mutex
+	subclasses      []weak.Pointer[Type]
 }
 
-var basisTypes = map[reflect.Type]*Type{
-	objectBasis: ObjectType,
-	typeBasis:   TypeType,
+// typeAttrCacheSize is the number of entries in each type's attribute cache.
+// It must be a power of two.
+const typeAttrCacheSize = 32
+
+// typeAttrCacheEntry is an immutable record of the result of looking up name
+// in a type's mro, or nil value if it was not found. The entry is valid while
+// the type's attrVersion still equals version. Entries are keyed on the identity of name,
+// so they are effective for the interned identifiers used by generated code.
+type typeAttrCacheEntry struct {
+	version int64
+	name    *Str
+	value   *Object
 }
 
+var (
+	basisTypes = map[reflect.Type]*Type{
+		objectBasis: ObjectType,
+		typeBasis:   TypeType,
+	}
+	// slotsBasisCount is used to give each class that declares __slots__ a
+	// distinct basis so that classes with conflicting layouts can't be
+	// combined via multiple inheritance.
+	slotsBasisCount int64
+	slotNameRegexp  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	objectPtrType   = reflect.TypeOf((*Object)(nil))
+)
+
 // newClass creates a Python type with the given name, base classes and type
 // dict. It is similar to the Python expression 'type(name, bases, dict)'.
 func newClass(f *Frame, meta *Type, name string, bases []*Type, dict *Dict) (*Type, *BaseException) {
@@ -67,7 +108,30 @@ func newClass(f *Frame, meta *Type, name string, bases []*Type, dict *Dict) (*Ty
 	if basis == nil {
 		return nil, f.RaiseType(TypeErrorType, "class layout error")
 	}
+	slotAttrs, hasDict, raised := classSlotAttrs(f, dict)
+	if raised != nil {
+		return nil, raised
+	}
+	if len(slotAttrs) > 0 {
+		basis = reflect.StructOf([]reflect.StructField{
+			{Name: "Base", Type: basis},
+			{Name: "Slots", Type: reflect.ArrayOf(len(slotAttrs), objectPtrType), Tag: reflect.StructTag(fmt.Sprintf(`slots:"%d"`, atomic.AddInt64(&slotsBasisCount, 1)))},
+		})
+	}
 	t := newType(meta, name, basis, bases, dict)
+	if slotAttrs != nil && !hasDict {
+		t.flags |= typeFlagNoDict
+		for _, base := range bases {
+			if base != ObjectType && base.flags&typeFlagNoDict == 0 {
+				t.flags &^= typeFlagNoDict
+			}
+		}
+	}
+	for i, attr := range slotAttrs {
+		if raised := dict.SetItemString(f, attr, newMemberDescriptor(t, attr, i).ToObject()); raised != nil {
+			return nil, raised
+		}
+	}
 	// Populate slots for any special methods overridden in dict.
 	slotsValue := reflect.ValueOf(&t.slots).Elem()
 	for i := 0; i < numSlots; i++ {
@@ -83,6 +147,19 @@ func newClass(f *Frame, meta *Type, name string, bases []*Type, dict *Dict) (*Ty
 			}
 		}
 	}
+	// Accept the Python 3 spelling of __nonzero__ for dual compatible code.
+	if t.slots.NonZero == nil {
+		boolFunc, raised := dict.GetItemString(f, "__bool__")
+		if raised != nil {
+			return nil, raised
+		}
+		if boolFunc != nil {
+			slot := &unaryOpSlot{}
+			if slot.wrapCallable(boolFunc) {
+				t.slots.NonZero = slot
+			}
+		}
+	}
 	if err := prepareType(t); err != "" {
 		return nil, f.RaiseType(TypeErrorType, err)
 	}
@@ -99,6 +176,56 @@ func newClass(f *Frame, meta *Type, name string, bases []*Type, dict *Dict) (*Ty
 	return t, nil
 }
 
+// classSlotAttrs returns the names of the instance attributes declared in the
+// __slots__ entry of a class dict, excluding __dict__ and __weakref__. The
+// returned slice is nil if dict has no __slots__. hasDict is true if
+// __slots__ is absent or contains "__dict__".
+func classSlotAttrs(f *Frame, dict *Dict) (names []string, hasDict bool, raised *BaseException) {
+	slots, raised := dict.GetItemString(f, "__slots__")
+	if raised != nil || slots == nil {
+		return nil, true, raised
+	}
+	if slots.isInstance(BaseStringType) {
+		slots = NewTuple(slots).ToObject()
+	}
+	names = []string{}
+	raised = seqForEach(f, slots, func(o *Object) *BaseException {
+		if o.isInstance(UnicodeType) {
+			s, raised := toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
+			if raised != nil {
+				return raised
+			}
+			o = s.ToObject()
+		}
+		if !o.isInstance(StrType) {
+			return f.RaiseType(TypeErrorType, fmt.Sprintf("__slots__ items must be strings, not '%s'", o.typ.Name()))
+		}
+		name := toStrUnsafe(o).Value()
+		if !slotNameRegexp.MatchString(name) {
+			return f.RaiseType(TypeErrorType, "__slots__ must be identifiers")
+		}
+		switch name {
+		case "__dict__":
+			hasDict = true
+		case "__weakref__":
+		default:
+			v, raised := dict.GetItemString(f, name)
+			if raised != nil {
+				return raised
+			}
+			if v != nil {
+				return f.RaiseType(ValueErrorType, fmt.Sprintf("'%s' in __slots__ conflicts with class variable", name))
+			}
+			names = append(names, name)
+		}
+		return nil
+	})
+	if raised != nil {
+		return nil, false, raised
+	}
+	return names, hasDict, nil
+}
+
 func newType(meta *Type, name string, basis reflect.Type, bases []*Type, dict *Dict) *Type {
 	return &Type{
 		Object: Object{typ: meta, dict: dict},
@@ -181,6 +308,12 @@ func prepareBuiltinType(typ *Type, init builtinTypeInit) {
 // prepareType calculates typ's mro and inherits its flags and slots from its
 // base classes.
 func prepareType(typ *Type) string {
+	if d := typ.Dict(); d != nil {
+		d.addType(typ)
+	}
+	for _, base := range typ.bases {
+		base.addSubclass(typ)
+	}
 	typ.mro = mroCalc(typ)
 	if typ.mro == nil {
 		return fmt.Sprintf("mro error for: %s", typ.name)
@@ -199,8 +332,7 @@ func prepareType(typ *Type) string {
 		slotField := slotsValue.Field(i)
 		if slotField.IsNil() {
 			for _, base := range typ.mro {
-				baseSlotFunc := reflect.ValueOf(base.slots).Field(i)
-				if !baseSlotFunc.IsNil() {
+				if baseSlotFunc := ownSlot(base, i); baseSlotFunc.IsValid() {
 					slotField.Set(baseSlotFunc)
 					break
 				}
@@ -210,6 +342,23 @@ func prepareType(typ *Type) string {
 	return ""
 }
 
+// ownSlot returns the i'th slot of t if it was defined by t itself rather
+// than inherited from one of its bases, otherwise an invalid Value. This
+// ensures that e.g. a mixin that inherits object's __init__ does not shadow
+// an __init__ defined later in the mro.
+func ownSlot(t *Type, i int) reflect.Value {
+	slotFunc := reflect.ValueOf(t.slots).Field(i)
+	if slotFunc.IsNil() {
+		return reflect.Value{}
+	}
+	for _, base := range t.mro[1:] {
+		if reflect.ValueOf(base.slots).Field(i).Pointer() == slotFunc.Pointer() {
+			return reflect.Value{}
+		}
+	}
+	return slotFunc
+}
+
 // Precondition: At least one of seqs is non-empty.
 func mroMerge(seqs [][]*Type) []*Type {
 	var res []*Type
@@ -312,13 +461,71 @@ func (t *Type) isSubclass(super *Type) bool {
 }
 
 func (t *Type) mroLookup(f *Frame, name *Str) (*Object, *BaseException) {
+	if t.mro == nil {
+		// t has not been prepared yet so don't cache anything.
+		return nil, nil
+	}
+	// Load the version before searching so that a concurrent change to a
+	// type dict invalidates the entry stored below.
+	version := atomic.LoadInt64(&t.attrVersion)
+	i := uintptr(unsafe.Pointer(name)) >> 4 & (typeAttrCacheSize - 1)
+	p := (*unsafe.Pointer)(unsafe.Pointer(&t.attrCache[i]))
+	if entry := (*typeAttrCacheEntry)(atomic.LoadPointer(p)); entry != nil && entry.name == name && entry.version == version {
+		return entry.value, nil
+	}
+	var value *Object
 	for _, t := range t.mro {
 		v, raised := t.Dict().GetItem(f, name.ToObject())
-		if v != nil || raised != nil {
-			return v, raised
+		if raised != nil {
+			return nil, raised
+		}
+		if v != nil {
+			value = v
+			break
+		}
+	}
+	atomic.StorePointer(p, unsafe.Pointer(&typeAttrCacheEntry{version, name, value}))
+	return value, nil
+}
+
+// invalidateAttrCache discards the cached attribute lookups of t and of its
+// subclasses. It is called when t's dict is modified or replaced.
+func (t *Type) invalidateAttrCache() {
+	// 64bit atomic ops need to be 8 byte aligned. This compile time check
+	// verifies alignment by creating a negative constant for an unsigned type.
+	// See sync/atomic docs for details.
+	const blank = -(unsafe.Offsetof(t.attrVersion) % 8)
+	atomic.AddInt64(&t.attrVersion, 1)
+	for _, sub := range t.loadSubclasses() {
+		sub.invalidateAttrCache()
+	}
+}
+
+// addSubclass records sub as a direct subclass of t, dropping the entries of
+// subclasses that have since been collected.
+func (t *Type) addSubclass(sub *Type) {
+	t.subclassesMutex.Lock()
+	subclasses := t.subclasses[:0]
+	for _, p := range t.subclasses {
+		if p.Value() != nil {
+			subclasses = append(subclasses, p)
+		}
+	}
+	t.subclasses = append(subclasses, weak.Make(sub))
+	t.subclassesMutex.Unlock()
+}
+
+// loadSubclasses returns the direct subclasses of t that are still alive.
+func (t *Type) loadSubclasses() []*Type {
+	t.subclassesMutex.Lock()
+	var subclasses []*Type
+	for _, p := range t.subclasses {
+		if sub := p.Value(); sub != nil {
+			subclasses = append(subclasses, sub)
 		}
 	}
-	return nil, nil
+	t.subclassesMutex.Unlock()
+	return subclasses
 }
 
 var typeBasis = reflect.TypeOf(Type{})
@@ -349,6 +556,15 @@ func typeCall(f *Frame, callable *Object, args Args, kwargs KWArgs) (*Object, *B
 	if raised != nil {
 		return nil, raised
 	}
+	objectSetPolicy(f, o)
+	// Like CPython, don't initialize the result of type(x) or an object
+	// that __new__ returned which is not an instance of t.
+	if t == TypeType && len(args) == 1 && len(kwargs) == 0 {
+		return o, nil
+	}
+	if !o.isInstance(t) {
+		return o, nil
+	}
 	if init := o.Type().slots.Init; init != nil {
 		if _, raised := init.Fn(f, o, args, kwargs); raised != nil {
 			return nil, raised
@@ -398,6 +614,15 @@ func typeGetAttribute(f *Frame, o *Object, name *Str) (*Object, *BaseException)
 	return nil, f.RaiseType(AttributeErrorType, msg)
 }
 
+func typeInit(f *Frame, o *Object, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	// The class is fully constructed by __new__ so there's nothing to do
+	// here, but the arguments must still be valid.
+	if len(args) != 1 && len(args) != 3 {
+		return nil, f.RaiseType(TypeErrorType, "type.__init__() takes 1 or 3 arguments")
+	}
+	return None, nil
+}
+
 func typeNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseException) {
 	switch len(args) {
 	case 0:
@@ -411,6 +636,10 @@ func typeNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseExcepti
 	}
 	name := toStrUnsafe(args[0]).Value()
 	bases := toTupleUnsafe(args[1]).elems
+	if len(bases) == 0 {
+		// Like CPython, classes without bases derive from object.
+		bases = []*Object{ObjectType.ToObject()}
+	}
 	dict := toDictUnsafe(args[2])
 	baseTypes := make([]*Type, len(bases))
 	meta := t
@@ -428,11 +657,15 @@ func typeNew(f *Frame, t *Type, args Args, kwargs KWArgs) (*Object, *BaseExcepti
 			meta = o.typ
 		} else if !meta.isSubclass(o.typ) {
 			msg := "metaclass conflict: the metaclass of a derived class must " +
-				"a be a (non-strict) subclass of the metaclasses of all its bases"
+				"be a (non-strict) subclass of the metaclasses of all its bases"
 			return nil, f.RaiseType(TypeErrorType, msg)
 		}
 		baseTypes[i] = toTypeUnsafe(o)
 	}
+	if meta != t && meta.slots.New != t.slots.New {
+		// The most derived metaclass overrides __new__ so defer to it.
+		return meta.slots.New.Fn(f, meta, args, kwargs)
+	}
 	ret, raised := newClass(f, meta, name, baseTypes, dict)
 	if raised != nil {
 		return nil, raised
@@ -452,6 +685,7 @@ func initTypeType(map[string]*Object) {
 	TypeType.typ = TypeType
 	TypeType.slots.Call = &callSlot{typeCall}
 	TypeType.slots.GetAttribute = &getAttributeSlot{typeGetAttribute}
+	TypeType.slots.Init = &initSlot{typeInit}
 	TypeType.slots.New = &newSlot{typeNew}
 	TypeType.slots.Repr = &unaryOpSlot{typeRepr}
 }
@@ -57,6 +57,102 @@ func TestNewClass(t *testing.T) {
 	}
 }
 
+func TestNewClassSlots(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, slots *Object) (*Tuple, *BaseException) {
+		dict := NewDict()
+		if raised := dict.SetItemString(f, "__slots__", slots); raised != nil {
+			return nil, raised
+		}
+		cls, raised := newClass(f, TypeType, "Foo", []*Type{ObjectType}, dict)
+		if raised != nil {
+			return nil, raised
+		}
+		o := newObject(cls)
+		if raised := SetAttr(f, o, NewStr("foo"), NewInt(1).ToObject()); raised != nil {
+			return nil, raised
+		}
+		foo, raised := GetAttr(f, o, NewStr("foo"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(foo, GetBool(o.Dict() != nil).ToObject()), nil
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("foo"), want: newTestTuple(1, false).ToObject()},
+		{args: wrapArgs(newTestTuple("bar", "foo")), want: newTestTuple(1, false).ToObject()},
+		{args: wrapArgs(NewUnicode("foo")), want: newTestTuple(1, false).ToObject()},
+		{args: wrapArgs(newTestList("foo", "__dict__", "__weakref__")), want: newTestTuple(1, true).ToObject()},
+		{args: wrapArgs(newTestTuple("__dict__")), want: newTestTuple(1, true).ToObject()},
+		{args: wrapArgs(NewTuple()), wantExc: mustCreateException(AttributeErrorType, "'Foo' has no attribute 'foo'")},
+		{args: wrapArgs(newTestTuple(1)), wantExc: mustCreateException(TypeErrorType, "__slots__ items must be strings, not 'int'")},
+		{args: wrapArgs("foo bar"), wantExc: mustCreateException(TypeErrorType, "__slots__ must be identifiers")},
+		{args: wrapArgs(newTestTuple("__slots__")), wantExc: mustCreateException(ValueErrorType, "'__slots__' in __slots__ conflicts with class variable")},
+		{args: wrapArgs(None), wantExc: mustCreateException(TypeErrorType, "'NoneType' object is not iterable")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSlotDescriptor(t *testing.T) {
+	f := NewRootFrame()
+	newSlotted := func(name string, bases ...*Type) *Type {
+		dict := newStringDict(map[string]*Object{"__slots__": NewStr(name).ToObject()})
+		cls, raised := newClass(f, TypeType, name, bases, dict)
+		if raised != nil {
+			t.Fatalf("newClass(%q) raised %v", name, raised)
+		}
+		return cls
+	}
+	fooType := newSlotted("foo", ObjectType)
+	barType := newSlotted("bar", fooType)
+	if _, raised := newClass(f, TypeType, "Baz", []*Type{fooType, newSlotted("qux", ObjectType)}, NewDict()); raised == nil || raised.typ != TypeErrorType {
+		t.Errorf("class with conflicting slotted bases raised %v, want TypeError", raised)
+	}
+	fun := wrapFuncForTest(func(f *Frame, o *Object, name *Str, set bool) (*Object, *BaseException) {
+		if set {
+			if raised := SetAttr(f, o, name, name.ToObject()); raised != nil {
+				return nil, raised
+			}
+		} else if raised := DelAttr(f, o, name); raised != nil {
+			return nil, raised
+		}
+		return GetAttr(f, o, name, None)
+	})
+	bar := newObject(barType)
+	cases := []invokeTestCase{
+		{args: wrapArgs(bar, "foo", true), want: NewStr("foo").ToObject()},
+		{args: wrapArgs(bar, "bar", true), want: NewStr("bar").ToObject()},
+		{args: wrapArgs(bar, "foo", false), want: None},
+		{args: wrapArgs(bar, "foo", false), wantExc: mustCreateException(AttributeErrorType, "foo")},
+		{args: wrapArgs(newObject(fooType), "bar", true), wantExc: mustCreateException(AttributeErrorType, "'foo' has no attribute 'bar'")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	// The bar slot must not have been clobbered by changes to foo.
+	if got, raised := GetAttr(f, bar, NewStr("bar"), nil); raised != nil || got.typ != StrType || toStrUnsafe(got).Value() != "bar" {
+		t.Errorf("bar.bar = (%v, %v), want 'bar'", got, raised)
+	}
+	desc, raised := fooType.Dict().GetItemString(f, "foo")
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if _, raised := memberDescriptorGet(f, desc, newObject(ObjectType), ObjectType); raised == nil || raised.typ != TypeErrorType {
+		t.Errorf("foo descriptor applied to object raised %v, want TypeError", raised)
+	}
+	if got, raised := GetAttr(f, fooType.ToObject(), NewStr("foo"), nil); raised != nil || got != desc {
+		t.Errorf("foo.foo = (%v, %v), want %v", got, raised, desc)
+	}
+	if got, raised := Repr(f, desc); raised != nil || got.Value() != "<member 'foo' of 'foo' objects>" {
+		t.Errorf("repr(foo.foo) = (%v, %v), want %q", got, raised, "<member 'foo' of 'foo' objects>")
+	}
+}
+
 func TestNewBasisType(t *testing.T) {
 	type basisStruct struct{ Object }
 	basisStructFunc := func(o *Object) *basisStruct { return (*basisStruct)(o.toPointer()) }
@@ -160,6 +256,24 @@ func TestPrepareType(t *testing.T) {
 	}
 }
 
+func TestPrepareTypeInheritsOwnSlots(t *testing.T) {
+	initFunc := newBuiltinFunction("__init__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+		return None, nil
+	}).ToObject()
+	// class Mixin(object): pass
+	// class Base(object): def __init__(self): pass
+	// class Derived(Mixin, Base): pass
+	mixinType := newTestClass("Mixin", []*Type{ObjectType}, NewDict())
+	baseType := newTestClass("Base", []*Type{ObjectType}, newStringDict(map[string]*Object{"__init__": initFunc}))
+	derivedType := newTestClass("Derived", []*Type{mixinType, baseType}, NewDict())
+	if mixinType.slots.Init != ObjectType.slots.Init {
+		t.Errorf("Mixin.slots.Init = %v, want %v", mixinType.slots.Init, ObjectType.slots.Init)
+	}
+	if derivedType.slots.Init != baseType.slots.Init {
+		t.Errorf("Derived.slots.Init = %v, want %v", derivedType.slots.Init, baseType.slots.Init)
+	}
+}
+
 func makeTestType(name string, bases ...*Type) *Type {
 	return newType(TypeType, name, nil, bases, NewDict())
 }
@@ -214,13 +328,115 @@ func TestTypeIsSubclass(t *testing.T) {
 	}
 }
 
+func TestTypeMROLookupCache(t *testing.T) {
+	f := NewRootFrame()
+	name := NewStr("bar")
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	barType := newTestClass("Bar", []*Type{fooType}, NewDict())
+	lookup := func(want *Object) {
+		t.Helper()
+		// Look up twice so that the second lookup hits the cache.
+		for i := 0; i < 2; i++ {
+			if got, raised := barType.mroLookup(f, name); raised != nil {
+				t.Fatal(raised)
+			} else if got != want {
+				t.Errorf("Bar.mroLookup(%q) = %v, want %v", name.Value(), got, want)
+			}
+		}
+	}
+	lookup(nil)
+	one := NewInt(1).ToObject()
+	if raised := SetAttr(f, fooType.ToObject(), name, one); raised != nil {
+		t.Fatal(raised)
+	}
+	lookup(one)
+	two := NewInt(2).ToObject()
+	if raised := barType.Dict().SetItem(f, name.ToObject(), two); raised != nil {
+		t.Fatal(raised)
+	}
+	lookup(two)
+	// Lookups with an equal but distinct name are not fooled by the entry
+	// cached for name.
+	if got := mustNotRaise(barType.mroLookup(f, NewStr("bar"))); got != two {
+		t.Errorf("Bar.mroLookup('bar') = %v, want %v", got, two)
+	}
+	if _, raised := barType.Dict().DelItem(f, name.ToObject()); raised != nil {
+		t.Fatal(raised)
+	}
+	lookup(one)
+	if _, raised := objectSetDict(f, wrapArgs(fooType, NewDict()), nil); raised != nil {
+		t.Fatal(raised)
+	}
+	lookup(nil)
+}
+
+func TestTypeMROLookupCacheVersion(t *testing.T) {
+	f := NewRootFrame()
+	fooType := newTestClass("Foo", []*Type{ObjectType}, NewDict())
+	barType := newTestClass("Bar", []*Type{fooType}, NewDict())
+	bazType := newTestClass("Baz", []*Type{barType}, NewDict())
+	quxType := newTestClass("Qux", []*Type{ObjectType}, NewDict())
+	types := []*Type{fooType, barType, bazType, quxType}
+	cases := []struct {
+		mutate *Type
+		want   []bool
+	}{
+		{fooType, []bool{true, true, true, false}},
+		{barType, []bool{false, true, true, false}},
+		{bazType, []bool{false, false, true, false}},
+		{quxType, []bool{false, false, false, true}},
+	}
+	for _, cas := range cases {
+		var versions []int64
+		for _, typ := range types {
+			versions = append(versions, typ.attrVersion)
+		}
+		if raised := cas.mutate.Dict().SetItem(f, NewStr("bar").ToObject(), None); raised != nil {
+			t.Fatal(raised)
+		}
+		for i, typ := range types {
+			if got := typ.attrVersion != versions[i]; got != cas.want[i] {
+				t.Errorf("setting %s.bar changed %s.attrVersion = %v, want %v", cas.mutate.Name(), typ.Name(), got, cas.want[i])
+			}
+		}
+	}
+	// A dict installed with _set_dict invalidates the type's cache when it
+	// is later modified.
+	d := NewDict()
+	if _, raised := objectSetDict(f, wrapArgs(barType, d), nil); raised != nil {
+		t.Fatal(raised)
+	}
+	version := bazType.attrVersion
+	if raised := d.SetItem(f, NewStr("bar").ToObject(), None); raised != nil {
+		t.Fatal(raised)
+	}
+	if bazType.attrVersion == version {
+		t.Errorf("setting an item in Bar's new dict did not change Baz.attrVersion")
+	}
+}
+
 func TestTypeCall(t *testing.T) {
 	fooType := makeTestType("Foo")
 	prepareType(fooType)
 	emptyExc := toBaseExceptionUnsafe(newObject(ExceptionType))
 	emptyExc.args = NewTuple()
+	// class Bar(object):
+	//   def __new__(cls):
+	//     return 'bar'
+	//   def __init__(self):
+	//     raise AssertionError
+	barType := newTestClass("Bar", []*Type{ObjectType}, newStringDict(map[string]*Object{
+		"__new__": newBuiltinFunction("__new__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return NewStr("bar").ToObject(), nil
+		}).ToObject(),
+		"__init__": newBuiltinFunction("__init__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return nil, f.RaiseType(AssertionErrorType, "")
+		}).ToObject(),
+	}))
 	cases := []invokeTestCase{
 		{wantExc: mustCreateException(TypeErrorType, "unbound method __call__() must be called with type instance as first argument (got nothing instead)")},
+		{args: wrapArgs(TypeType, 42), want: IntType.ToObject()},
+		{args: wrapArgs(barType), want: NewStr("bar").ToObject()},
 		{args: wrapArgs(42), wantExc: mustCreateException(TypeErrorType, "unbound method __call__() must be called with type instance as first argument (got int instance instead)")},
 		{args: wrapArgs(fooType), wantExc: mustCreateException(TypeErrorType, "type Foo has no __new__")},
 		{args: wrapArgs(IntType), want: NewInt(0).ToObject()},
@@ -392,12 +608,26 @@ func TestTypeNew(t *testing.T) {
 	if raised != nil {
 		panic(raised)
 	}
+	// class QuxMeta(type):
+	//   def __new__(mcs, name, bases, dict):
+	//     return mcs
+	quxMetaType := newTestClass("QuxMeta", []*Type{TypeType}, newStringDict(map[string]*Object{
+		"__new__": newBuiltinFunction("__new__", func(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+			return args[0], nil
+		}).ToObject(),
+	}))
+	quxType, raised := newClass(NewRootFrame(), quxMetaType, "Qux", []*Type{ObjectType}, NewDict())
+	if raised != nil {
+		panic(raised)
+	}
 	cases := []invokeTestCase{
 		{wantExc: mustCreateException(TypeErrorType, "'__new__' requires 1 arguments")},
+		// The most derived metaclass's __new__ is invoked.
+		{args: wrapArgs(TypeType, "Quux", newTestTuple(quxType), NewDict()), want: quxMetaType.ToObject()},
 		{args: wrapArgs(TypeType), wantExc: mustCreateException(TypeErrorType, "type() takes 1 or 3 arguments")},
 		{args: wrapArgs(TypeType, "foo", newTestTuple(false), NewDict()), wantExc: mustCreateException(TypeErrorType, "not a valid base class: False")},
 		{args: wrapArgs(TypeType, None), want: NoneType.ToObject()},
-		{args: wrapArgs(fooMetaType, "Qux", newTestTuple(fooType, barType), NewDict()), wantExc: mustCreateException(TypeErrorType, "metaclass conflict: the metaclass of a derived class must a be a (non-strict) subclass of the metaclasses of all its bases")},
+		{args: wrapArgs(fooMetaType, "Qux", newTestTuple(fooType, barType), NewDict()), wantExc: mustCreateException(TypeErrorType, "metaclass conflict: the metaclass of a derived class must be a (non-strict) subclass of the metaclasses of all its bases")},
 		// Test that the metaclass of the result is the most derived
 		// metaclass of the bases. In this case that should be
 		// bazMetaType so pass bazMetaType to be compared by the __eq__
@@ -409,6 +639,11 @@ func TestTypeNew(t *testing.T) {
 			t.Error(err)
 		}
 	}
+	// Classes without bases derive from object.
+	empty := toTypeUnsafe(mustNotRaise(TypeType.Call(NewRootFrame(), wrapArgs("Empty", NewTuple(), NewDict()), nil)))
+	if len(empty.bases) != 1 || empty.bases[0] != ObjectType {
+		t.Errorf("type('Empty', (), {}).__bases__ = %v, want [object]", empty.bases)
+	}
 }
 
 func TestTypeNewResult(t *testing.T) {
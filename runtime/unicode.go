@@ -18,13 +18,16 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sync"
 	"unicode"
-	"unicode/utf8"
 )
 
 var (
 	// UnicodeType is the object representing the Python 'unicode' type.
 	UnicodeType = newBasisType("unicode", reflect.TypeOf(Unicode{}), toUnicodeUnsafe, BaseStringType)
+	// unicodeIteratorType is the object representing the Python
+	// 'unicodeiterator' type.
+	unicodeIteratorType = newBasisType("unicodeiterator", reflect.TypeOf(unicodeIterator{}), toUnicodeIteratorUnsafe, ObjectType)
 )
 
 // Unicode represents Python 'unicode' objects. The string value is stored as
@@ -50,33 +53,18 @@ func toUnicodeUnsafe(o *Object) *Unicode {
 }
 
 // Encode translates the runes in s into a str with the given encoding.
-//
-// NOTE: If s contains surrogates (e.g. U+D800), Encode will raise
-// UnicodeDecodeError consistent with CPython 3.x but different than 2.x.
+// Encodings not built into the runtime are looked up in the codec registry.
 func (s *Unicode) Encode(f *Frame, encoding, errors string) (*Str, *BaseException) {
-	// TODO: Support custom encodings and error handlers.
-	normalized := normalizeEncoding(encoding)
-	if normalized != "utf8" {
-		return nil, f.RaiseType(LookupErrorType, fmt.Sprintf("unknown encoding: %s", encoding))
+	// TODO: Support custom error handlers.
+	result, raised := CodecsEncode(f, s.ToObject(), encoding, errors)
+	if raised != nil {
+		return nil, raised
 	}
-	buf := bytes.Buffer{}
-	for i, r := range s.Value() {
-		switch {
-		case utf8.ValidRune(r):
-			buf.WriteRune(r)
-		case errors == EncodeIgnore:
-			// Do nothing
-		case errors == EncodeReplace:
-			buf.WriteRune(unicode.ReplacementChar)
-		case errors == EncodeStrict:
-			format := "'%s' codec can't encode character %s in position %d"
-			return nil, f.RaiseType(UnicodeEncodeErrorType, fmt.Sprintf(format, encoding, escapeRune(r), i))
-		default:
-			format := "unknown error handler name '%s'"
-			return nil, f.RaiseType(LookupErrorType, fmt.Sprintf(format, errors))
-		}
+	if !result.isInstance(StrType) {
+		format := "encoder did not return a string object (type=%s)"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
 	}
-	return NewStr(buf.String()), nil
+	return toStrUnsafe(result), nil
 }
 
 // ToObject upcasts s to an Object.
@@ -100,32 +88,62 @@ func unicodeAdd(f *Frame, v, w *Object) (*Object, *BaseException) {
 	if newLen < 0 {
 		return nil, f.RaiseType(OverflowErrorType, errResultTooLarge)
 	}
+	if raised := f.checkAlloc(newLen); raised != nil {
+		return nil, raised
+	}
 	value := make([]rune, newLen)
 	copy(value, unicodeV.Value())
 	copy(value[lenV:], unicodeW.Value())
 	return NewUnicodeFromRunes(value).ToObject(), nil
 }
 
+func unicodeCapitalize(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "capitalize", args, UnicodeType); raised != nil {
+		return nil, raised
+	}
+	runes := toUnicodeUnsafe(args[0]).Value()
+	if len(runes) == 0 {
+		return args[0], nil
+	}
+	result := make([]rune, len(runes))
+	result[0] = unicode.ToUpper(runes[0])
+	for i := 1; i < len(runes); i++ {
+		result[i] = unicode.ToLower(runes[i])
+	}
+	return NewUnicodeFromRunes(result).ToObject(), nil
+}
+
 func unicodeContains(f *Frame, o *Object, value *Object) (*Object, *BaseException) {
 	lhs := toUnicodeUnsafe(o).Value()
 	s, raised := unicodeCoerce(f, value)
 	if raised != nil {
 		return nil, raised
 	}
-	rhs := s.Value()
-	lhsLen, rhsLen := len(lhs), len(rhs)
-	maxOffset := lhsLen - rhsLen
-	for offset := 0; offset <= maxOffset; offset++ {
-		if runeSliceCmp(lhs[offset:offset+rhsLen], rhs) == 0 {
-			return True.ToObject(), nil
-		}
+	return GetBool(runeSliceIndex(lhs, s.Value()) != -1).ToObject(), nil
+}
+
+func unicodeCount(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	search, sub, _, ok, raised := unicodeSearchArgs(f, "count", args)
+	if raised != nil {
+		return nil, raised
 	}
-	return False.ToObject(), nil
+	if !ok {
+		return NewInt(0).ToObject(), nil
+	}
+	n := len(sub)
+	if n == 0 {
+		return NewInt(len(search) + 1).ToObject(), nil
+	}
+	count := 0
+	for i := runeSliceIndex(search, sub); i != -1; i = runeSliceIndex(search, sub) {
+		search = search[i+n:]
+		count++
+	}
+	return NewInt(count).ToObject(), nil
 }
 
 func unicodeEncode(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
-	// TODO: Accept unicode for encoding and errors args.
-	expectedTypes := []*Type{UnicodeType, StrType, StrType}
+	expectedTypes := []*Type{UnicodeType, BaseStringType, BaseStringType}
 	argc := len(args)
 	if argc >= 1 && argc < 3 {
 		expectedTypes = expectedTypes[:argc]
@@ -133,25 +151,78 @@ func unicodeEncode(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException)
 	if raised := checkMethodArgs(f, "encode", args, expectedTypes...); raised != nil {
 		return nil, raised
 	}
-	encoding := EncodeDefault
-	if argc > 1 {
-		encoding = toStrUnsafe(args[1]).Value()
+	encoding, errors, raised := encodingArgs(f, args[1:])
+	if raised != nil {
+		return nil, raised
 	}
-	errors := EncodeStrict
-	if argc > 2 {
-		errors = toStrUnsafe(args[2]).Value()
+	result, raised := CodecsEncode(f, args[0], encoding, errors)
+	if raised != nil {
+		return nil, raised
+	}
+	if !result.isInstance(BaseStringType) {
+		format := "encoder did not return a string/unicode object (type=%s)"
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf(format, result.typ.Name()))
 	}
-	ret, raised := toUnicodeUnsafe(args[0]).Encode(f, encoding, errors)
+	return result, nil
+}
+
+func unicodeEndsWith(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	args, raised := startsEndsWithArgs(f, endsWithParamSpec, args, kwargs)
 	if raised != nil {
 		return nil, raised
 	}
-	return ret.ToObject(), nil
+	return unicodeStartsEndsWith(f, "endswith", args)
 }
 
 func unicodeEq(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return unicodeCompareEq(f, toUnicodeUnsafe(v), w, true)
 }
 
+func unicodeExpandTabs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{UnicodeType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, "expandtabs", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	tabSize := 8
+	if argc > 1 {
+		var raised *BaseException
+		if tabSize, raised = ToIntValue(f, args[1]); raised != nil {
+			return nil, raised
+		}
+	}
+	var result []rune
+	column := 0
+	for _, r := range toUnicodeUnsafe(args[0]).Value() {
+		switch r {
+		case '\t':
+			if tabSize > 0 {
+				n := tabSize - column%tabSize
+				for i := 0; i < n; i++ {
+					result = append(result, ' ')
+				}
+				column += n
+			}
+		case '\n', '\r':
+			result = append(result, r)
+			column = 0
+		default:
+			result = append(result, r)
+			column++
+		}
+	}
+	return NewUnicodeFromRunes(result).ToObject(), nil
+}
+
+func unicodeFind(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeFindOrIndex(f, "find", args, func(s, sub []rune) (int, *BaseException) {
+		return runeSliceIndex(s, sub), nil
+	})
+}
+
 func unicodeGE(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return unicodeCompare(f, toUnicodeUnsafe(v), w, False, True, True)
 }
@@ -163,7 +234,7 @@ func unicodeGetItem(f *Frame, o, key *Object) (*Object, *BaseException) {
 	s := toUnicodeUnsafe(o).Value()
 	switch {
 	case key.typ.slots.Index != nil:
-		index, raised := seqCheckedIndex(f, len(s), toIntUnsafe(key).Value())
+		index, raised := seqIndex(f, len(s), key)
 		if raised != nil {
 			return nil, raised
 		}
@@ -190,7 +261,7 @@ func unicodeGetNewArgs(f *Frame, args Args, _ KWArgs) (*Object, *BaseException)
 	if raised := checkMethodArgs(f, "__getnewargs__", args, UnicodeType); raised != nil {
 		return nil, raised
 	}
-	return NewTuple1(args[0]).ToObject(), nil
+	return NewTuple1(NewUnicodeFromRunes(toUnicodeUnsafe(args[0]).Value()).ToObject()).ToObject(), nil
 }
 
 func unicodeGT(f *Frame, v, w *Object) (*Object, *BaseException) {
@@ -214,6 +285,81 @@ func unicodeHash(f *Frame, o *Object) (*Object, *BaseException) {
 	return NewInt(h).ToObject(), nil
 }
 
+func unicodeIndex(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeFindOrIndex(f, "index", args, func(s, sub []rune) (i int, raised *BaseException) {
+		if i = runeSliceIndex(s, sub); i == -1 {
+			raised = f.RaiseType(ValueErrorType, "substring not found")
+		}
+		return i, raised
+	})
+}
+
+func unicodeIsAlNum(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsAll(f, "isalnum", args, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	})
+}
+
+func unicodeIsAlpha(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsAll(f, "isalpha", args, unicode.IsLetter)
+}
+
+func unicodeIsDecimal(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsAll(f, "isdecimal", args, unicode.IsDigit)
+}
+
+func unicodeIsDigit(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsAll(f, "isdigit", args, unicode.IsDigit)
+}
+
+func unicodeIsLower(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsCased(f, "islower", args, unicode.IsLower, unicode.IsUpper)
+}
+
+func unicodeIsNumeric(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsAll(f, "isnumeric", args, unicode.IsNumber)
+}
+
+func unicodeIsSpace(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsAll(f, "isspace", args, unicode.IsSpace)
+}
+
+func unicodeIsTitle(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "istitle", args, UnicodeType); raised != nil {
+		return nil, raised
+	}
+	cased := false
+	previousIsCased := false
+	for _, r := range toUnicodeUnsafe(args[0]).Value() {
+		switch {
+		case unicode.IsUpper(r) || unicode.IsTitle(r):
+			if previousIsCased {
+				return False.ToObject(), nil
+			}
+			previousIsCased = true
+			cased = true
+		case unicode.IsLower(r):
+			if !previousIsCased {
+				return False.ToObject(), nil
+			}
+			previousIsCased = true
+			cased = true
+		default:
+			previousIsCased = false
+		}
+	}
+	return GetBool(cased).ToObject(), nil
+}
+
+func unicodeIsUpper(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeIsCased(f, "isupper", args, unicode.IsUpper, unicode.IsLower)
+}
+
+func unicodeIter(f *Frame, o *Object) (*Object, *BaseException) {
+	iter := &unicodeIterator{Object: Object{typ: unicodeIteratorType}, runes: toUnicodeUnsafe(o).Value()}
+	return &iter.Object, nil
+}
+
 func unicodeJoin(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkMethodArgs(f, "join", args, UnicodeType, ObjectType); raised != nil {
 		return nil, raised
@@ -237,10 +383,25 @@ func unicodeLen(f *Frame, o *Object) (*Object, *BaseException) {
 	return NewInt(len(toUnicodeUnsafe(o).Value())).ToObject(), nil
 }
 
+func unicodeLower(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "lower", args, UnicodeType); raised != nil {
+		return nil, raised
+	}
+	return unicodeMapRunes(toUnicodeUnsafe(args[0]), unicode.ToLower).ToObject(), nil
+}
+
+func unicodeLStrip(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeStripImpl(f, "lstrip", args, stripSideLeft)
+}
+
 func unicodeLT(f *Frame, v, w *Object) (*Object, *BaseException) {
 	return unicodeCompare(f, toUnicodeUnsafe(v), w, True, False, False)
 }
 
+func unicodeMod(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return strInterpolate(f, string(toUnicodeUnsafe(v).Value()), w, true)
+}
+
 func unicodeMul(f *Frame, v, w *Object) (*Object, *BaseException) {
 	value := toUnicodeUnsafe(v).Value()
 	numChars := len(value)
@@ -338,6 +499,64 @@ func unicodeNew(f *Frame, t *Type, args Args, _ KWArgs) (ret *Object, raised *Ba
 	return s.ToObject(), nil
 }
 
+func unicodeReplace(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{UnicodeType, ObjectType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 3 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, "replace", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	n := -1
+	if argc == 4 {
+		var raised *BaseException
+		if n, raised = ToIntValue(f, args[3]); raised != nil {
+			return nil, raised
+		}
+	}
+	old, raised := unicodeCoerce(f, args[1])
+	if raised != nil {
+		return nil, raised
+	}
+	sub, raised := unicodeCoerce(f, args[2])
+	if raised != nil {
+		return nil, raised
+	}
+	s := toUnicodeUnsafe(args[0]).Value()
+	oldRunes, subRunes := old.Value(), sub.Value()
+	numRunes := len(s)
+	if n == 0 || (numRunes == 0 && len(oldRunes) == 0 && n > 0) {
+		return NewUnicodeFromRunes(s).ToObject(), nil
+	}
+	var result []rune
+	i := 0
+	if len(oldRunes) == 0 {
+		// An empty old inserts sub before every character and at the end.
+		for ; n != 0 && i <= numRunes; n-- {
+			result = append(result, subRunes...)
+			if i < numRunes {
+				result = append(result, s[i])
+			}
+			i++
+		}
+	} else {
+		for ; n != 0; n-- {
+			j := runeSliceIndex(s[i:], oldRunes)
+			if j == -1 {
+				break
+			}
+			result = append(result, s[i:i+j]...)
+			result = append(result, subRunes...)
+			i += j + len(oldRunes)
+		}
+	}
+	if i < numRunes {
+		result = append(result, s[i:]...)
+	}
+	return NewUnicodeFromRunes(result).ToObject(), nil
+}
+
 func unicodeRepr(_ *Frame, o *Object) (*Object, *BaseException) {
 	buf := bytes.Buffer{}
 	buf.WriteString("u'")
@@ -354,67 +573,181 @@ func unicodeRepr(_ *Frame, o *Object) (*Object, *BaseException) {
 	return NewStr(buf.String()).ToObject(), nil
 }
 
-func unicodeStr(f *Frame, o *Object) (*Object, *BaseException) {
-	ret, raised := toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
-	if raised != nil {
-		return nil, raised
-	}
-	return ret.ToObject(), nil
+func unicodeRFind(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeFindOrIndex(f, "rfind", args, func(s, sub []rune) (int, *BaseException) {
+		return runeSliceLastIndex(s, sub), nil
+	})
 }
 
-func unicodeStrip(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
-	expectedTypes := []*Type{UnicodeType, ObjectType}
+func unicodeRIndex(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeFindOrIndex(f, "rindex", args, func(s, sub []rune) (i int, raised *BaseException) {
+		if i = runeSliceLastIndex(s, sub); i == -1 {
+			raised = f.RaiseType(ValueErrorType, "substring not found")
+		}
+		return i, raised
+	})
+}
+
+func unicodeRStrip(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeStripImpl(f, "rstrip", args, stripSideRight)
+}
+
+func unicodeSplit(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{UnicodeType, ObjectType, ObjectType}
 	argc := len(args)
-	if argc == 1 {
+	if argc == 1 || argc == 2 {
 		expectedTypes = expectedTypes[:argc]
 	}
-	if raised := checkMethodArgs(f, "strip", args, expectedTypes...); raised != nil {
+	if raised := checkMethodArgs(f, "split", args, expectedTypes...); raised != nil {
 		return nil, raised
 	}
-	s := toUnicodeUnsafe(args[0])
-	charsArg := None
-	if argc > 1 {
-		charsArg = args[1]
+	var sep []rune
+	if argc > 1 && args[1] != None {
+		u, raised := unicodeCoerce(f, args[1])
+		if raised != nil {
+			return nil, raised
+		}
+		if sep = u.Value(); len(sep) == 0 {
+			return nil, f.RaiseType(ValueErrorType, "empty separator")
+		}
 	}
-	matchFunc := unicode.IsSpace
-	if charsArg != None {
-		chars, raised := unicodeCoerce(f, charsArg)
+	maxSplit := -1
+	if argc > 2 {
+		i, raised := ToIntValue(f, args[2])
 		if raised != nil {
 			return nil, raised
 		}
-		matchFunc = func(r rune) bool {
-			for _, c := range chars.Value() {
-				if r == c {
-					return true
-				}
+		if i >= 0 {
+			maxSplit = i
+		}
+	}
+	s := toUnicodeUnsafe(args[0]).Value()
+	var results []*Object
+	if sep == nil {
+		i, n := 0, len(s)
+		for {
+			for i < n && unicode.IsSpace(s[i]) {
+				i++
 			}
-			return false
+			if i == n {
+				break
+			}
+			if maxSplit == 0 {
+				results = append(results, NewUnicodeFromRunes(s[i:]).ToObject())
+				break
+			}
+			j := i
+			for j < n && !unicode.IsSpace(s[j]) {
+				j++
+			}
+			results = append(results, NewUnicodeFromRunes(s[i:j]).ToObject())
+			maxSplit--
+			i = j
+		}
+	} else {
+		for ; maxSplit != 0; maxSplit-- {
+			i := runeSliceIndex(s, sep)
+			if i == -1 {
+				break
+			}
+			results = append(results, NewUnicodeFromRunes(s[:i]).ToObject())
+			s = s[i+len(sep):]
 		}
+		results = append(results, NewUnicodeFromRunes(s).ToObject())
 	}
-	runes := s.Value()
-	numRunes := len(runes)
-	lindex := 0
-	for ; lindex < numRunes; lindex++ {
-		if !matchFunc(runes[lindex]) {
-			break
+	return NewList(results...).ToObject(), nil
+}
+
+func unicodeStartsWith(f *Frame, args Args, kwargs KWArgs) (*Object, *BaseException) {
+	args, raised := startsEndsWithArgs(f, startsWithParamSpec, args, kwargs)
+	if raised != nil {
+		return nil, raised
+	}
+	return unicodeStartsEndsWith(f, "startswith", args)
+}
+
+func unicodeStr(f *Frame, o *Object) (*Object, *BaseException) {
+	ret, raised := toUnicodeUnsafe(o).Encode(f, EncodeDefault, EncodeStrict)
+	if raised != nil {
+		return nil, raised
+	}
+	return ret.ToObject(), nil
+}
+
+func unicodeStrip(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	return unicodeStripImpl(f, "strip", args, stripSideBoth)
+}
+
+func unicodeSwapCase(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "swapcase", args, UnicodeType); raised != nil {
+		return nil, raised
+	}
+	return unicodeMapRunes(toUnicodeUnsafe(args[0]), func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		if unicode.IsLower(r) {
+			return unicode.ToUpper(r)
 		}
+		return r
+	}).ToObject(), nil
+}
+
+func unicodeTitle(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "title", args, UnicodeType); raised != nil {
+		return nil, raised
 	}
-	rindex := numRunes
-	for ; rindex > lindex; rindex-- {
-		if !matchFunc(runes[rindex-1]) {
-			break
+	previousIsCased := false
+	return unicodeMapRunes(toUnicodeUnsafe(args[0]), func(r rune) rune {
+		c := r
+		if previousIsCased {
+			c = unicode.ToLower(r)
+		} else {
+			c = unicode.ToTitle(r)
 		}
+		previousIsCased = unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r)
+		return c
+	}).ToObject(), nil
+}
+
+func unicodeUpper(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "upper", args, UnicodeType); raised != nil {
+		return nil, raised
 	}
-	result := make([]rune, rindex-lindex)
-	copy(result, runes[lindex:rindex])
-	return NewUnicodeFromRunes(result).ToObject(), nil
+	return unicodeMapRunes(toUnicodeUnsafe(args[0]), unicode.ToUpper).ToObject(), nil
 }
 
 func initUnicodeType(dict map[string]*Object) {
 	dict["__getnewargs__"] = newBuiltinFunction("__getnewargs__", unicodeGetNewArgs).ToObject()
+	dict["capitalize"] = newBuiltinFunction("capitalize", unicodeCapitalize).ToObject()
+	dict["count"] = newBuiltinFunction("count", unicodeCount).ToObject()
 	dict["encode"] = newBuiltinFunction("encode", unicodeEncode).ToObject()
+	dict["endswith"] = newBuiltinFunction("endswith", unicodeEndsWith).ToObject()
+	dict["expandtabs"] = newBuiltinFunction("expandtabs", unicodeExpandTabs).ToObject()
+	dict["find"] = newBuiltinFunction("find", unicodeFind).ToObject()
+	dict["index"] = newBuiltinFunction("index", unicodeIndex).ToObject()
+	dict["isalnum"] = newBuiltinFunction("isalnum", unicodeIsAlNum).ToObject()
+	dict["isalpha"] = newBuiltinFunction("isalpha", unicodeIsAlpha).ToObject()
+	dict["isdecimal"] = newBuiltinFunction("isdecimal", unicodeIsDecimal).ToObject()
+	dict["isdigit"] = newBuiltinFunction("isdigit", unicodeIsDigit).ToObject()
+	dict["islower"] = newBuiltinFunction("islower", unicodeIsLower).ToObject()
+	dict["isnumeric"] = newBuiltinFunction("isnumeric", unicodeIsNumeric).ToObject()
+	dict["isspace"] = newBuiltinFunction("isspace", unicodeIsSpace).ToObject()
+	dict["istitle"] = newBuiltinFunction("istitle", unicodeIsTitle).ToObject()
+	dict["isupper"] = newBuiltinFunction("isupper", unicodeIsUpper).ToObject()
 	dict["join"] = newBuiltinFunction("join", unicodeJoin).ToObject()
+	dict["lower"] = newBuiltinFunction("lower", unicodeLower).ToObject()
+	dict["lstrip"] = newBuiltinFunction("lstrip", unicodeLStrip).ToObject()
+	dict["replace"] = newBuiltinFunction("replace", unicodeReplace).ToObject()
+	dict["rfind"] = newBuiltinFunction("rfind", unicodeRFind).ToObject()
+	dict["rindex"] = newBuiltinFunction("rindex", unicodeRIndex).ToObject()
+	dict["rstrip"] = newBuiltinFunction("rstrip", unicodeRStrip).ToObject()
+	dict["split"] = newBuiltinFunction("split", unicodeSplit).ToObject()
+	dict["startswith"] = newBuiltinFunction("startswith", unicodeStartsWith).ToObject()
 	dict["strip"] = newBuiltinFunction("strip", unicodeStrip).ToObject()
+	dict["swapcase"] = newBuiltinFunction("swapcase", unicodeSwapCase).ToObject()
+	dict["title"] = newBuiltinFunction("title", unicodeTitle).ToObject()
+	dict["upper"] = newBuiltinFunction("upper", unicodeUpper).ToObject()
 	UnicodeType.slots.Add = &binaryOpSlot{unicodeAdd}
 	UnicodeType.slots.Contains = &binaryOpSlot{unicodeContains}
 	UnicodeType.slots.Eq = &binaryOpSlot{unicodeEq}
@@ -422,9 +755,11 @@ func initUnicodeType(dict map[string]*Object) {
 	UnicodeType.slots.GetItem = &binaryOpSlot{unicodeGetItem}
 	UnicodeType.slots.GT = &binaryOpSlot{unicodeGT}
 	UnicodeType.slots.Hash = &unaryOpSlot{unicodeHash}
+	UnicodeType.slots.Iter = &unaryOpSlot{unicodeIter}
 	UnicodeType.slots.LE = &binaryOpSlot{unicodeLE}
 	UnicodeType.slots.Len = &unaryOpSlot{unicodeLen}
 	UnicodeType.slots.LT = &binaryOpSlot{unicodeLT}
+	UnicodeType.slots.Mod = &binaryOpSlot{unicodeMod}
 	UnicodeType.slots.Mul = &binaryOpSlot{unicodeMul}
 	UnicodeType.slots.NE = &binaryOpSlot{unicodeNE}
 	UnicodeType.slots.New = &newSlot{unicodeNew}
@@ -434,6 +769,42 @@ func initUnicodeType(dict map[string]*Object) {
 	UnicodeType.slots.Str = &unaryOpSlot{unicodeStr}
 }
 
+// unicodeIterator yields the code points of a unicode object as one character
+// unicode strings.
+type unicodeIterator struct {
+	Object
+	runes []rune
+	mutex sync.Mutex
+	index int
+}
+
+func toUnicodeIteratorUnsafe(o *Object) *unicodeIterator {
+	return (*unicodeIterator)(o.toPointer())
+}
+
+func unicodeIteratorIter(f *Frame, o *Object) (*Object, *BaseException) {
+	return o, nil
+}
+
+func unicodeIteratorNext(f *Frame, o *Object) (ret *Object, raised *BaseException) {
+	i := toUnicodeIteratorUnsafe(o)
+	i.mutex.Lock()
+	if i.index < len(i.runes) {
+		ret = NewUnicodeFromRunes([]rune{i.runes[i.index]}).ToObject()
+		i.index++
+	} else {
+		raised = f.Raise(StopIterationType.ToObject(), nil, nil)
+	}
+	i.mutex.Unlock()
+	return ret, raised
+}
+
+func initUnicodeIteratorType(map[string]*Object) {
+	unicodeIteratorType.flags &= ^(typeFlagBasetype | typeFlagInstantiable)
+	unicodeIteratorType.slots.Iter = &unaryOpSlot{unicodeIteratorIter}
+	unicodeIteratorType.slots.Next = &unaryOpSlot{unicodeIteratorNext}
+}
+
 func unicodeCompare(f *Frame, v *Unicode, w *Object, ltResult, eqResult, gtResult *Int) (*Object, *BaseException) {
 	rhs := []rune(nil)
 	if w.isInstance(UnicodeType) {
@@ -538,6 +909,9 @@ func unicodeJoinParts(f *Frame, s *Unicode, parts []*Object) (*Object, *BaseExce
 		unicodeParts[i] = s
 		numRunes += len(s.Value())
 	}
+	if raised := f.checkAlloc(numRunes); raised != nil {
+		return nil, raised
+	}
 	// Piece together the result string into buf.
 	buf := make([]rune, numRunes)
 	offset := 0
@@ -553,3 +927,245 @@ func unicodeJoinParts(f *Frame, s *Unicode, parts []*Object) (*Object, *BaseExce
 	}
 	return NewUnicodeFromRunes(buf).ToObject(), nil
 }
+
+type unicodeIndexFunc func(s, sub []rune) (int, *BaseException)
+
+func unicodeFindOrIndex(f *Frame, name string, args Args, fn unicodeIndexFunc) (*Object, *BaseException) {
+	search, sub, start, ok, raised := unicodeSearchArgs(f, name, args)
+	if raised != nil {
+		return nil, raised
+	}
+	if !ok {
+		// Default to an impossible search.
+		sub = []rune{'-'}
+	}
+	index, raised := fn(search, sub)
+	if raised != nil {
+		return nil, raised
+	}
+	if index != -1 {
+		index += start
+	}
+	return NewInt(index).ToObject(), nil
+}
+
+// unicodeSearchArgs decodes the (sub[, start[, end]]) arguments accepted by
+// find, count and similar methods. It returns the runes of s[start:end] and
+// sub along with the adjusted start. ok is false when the range is out of
+// bounds so that nothing, not even an empty sub, can match.
+func unicodeSearchArgs(f *Frame, name string, args Args) (search, sub []rune, start int, ok bool, raised *BaseException) {
+	expectedTypes := []*Type{UnicodeType, ObjectType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 || argc == 3 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, name, args, expectedTypes...); raised != nil {
+		return nil, nil, 0, false, raised
+	}
+	subUnicode, raised := unicodeCoerce(f, args[1])
+	if raised != nil {
+		return nil, nil, 0, false, raised
+	}
+	s := toUnicodeUnsafe(args[0]).Value()
+	l := len(s)
+	end := l
+	if argc >= 3 && args[2] != None {
+		if start, raised = IndexInt(f, args[2]); raised != nil {
+			return nil, nil, 0, false, raised
+		}
+	}
+	if argc == 4 && args[3] != None {
+		if end, raised = IndexInt(f, args[3]); raised != nil {
+			return nil, nil, 0, false, raised
+		}
+	}
+	if start > l {
+		return nil, nil, 0, false, nil
+	}
+	if start, end = adjustIndex(start, end, l); start > end {
+		return nil, nil, 0, false, nil
+	}
+	return s[start:end], subUnicode.Value(), start, true, nil
+}
+
+// unicodeIsAll returns True if s is non-empty and fn is true for all of its
+// runes.
+func unicodeIsAll(f *Frame, name string, args Args, fn func(rune) bool) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, name, args, UnicodeType); raised != nil {
+		return nil, raised
+	}
+	runes := toUnicodeUnsafe(args[0]).Value()
+	if len(runes) == 0 {
+		return False.ToObject(), nil
+	}
+	for _, r := range runes {
+		if !fn(r) {
+			return False.ToObject(), nil
+		}
+	}
+	return True.ToObject(), nil
+}
+
+// unicodeIsCased returns True if s contains at least one rune matching is and
+// none matching isNot or title case.
+func unicodeIsCased(f *Frame, name string, args Args, is, isNot func(rune) bool) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, name, args, UnicodeType); raised != nil {
+		return nil, raised
+	}
+	cased := false
+	for _, r := range toUnicodeUnsafe(args[0]).Value() {
+		if isNot(r) || unicode.IsTitle(r) {
+			return False.ToObject(), nil
+		}
+		if is(r) {
+			cased = true
+		}
+	}
+	return GetBool(cased).ToObject(), nil
+}
+
+// unicodeMapRunes returns a new Unicode with fn applied to each rune of s.
+func unicodeMapRunes(s *Unicode, fn func(rune) rune) *Unicode {
+	runes := s.Value()
+	result := make([]rune, len(runes))
+	for i, r := range runes {
+		result[i] = fn(r)
+	}
+	return NewUnicodeFromRunes(result)
+}
+
+func unicodeStartsEndsWith(f *Frame, method string, args Args) (*Object, *BaseException) {
+	expectedTypes := []*Type{UnicodeType, ObjectType, ObjectType, ObjectType}
+	argc := len(args)
+	if argc == 2 || argc == 3 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, method, args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	matchesArg := args[1]
+	var matches []*Object
+	switch {
+	case matchesArg.isInstance(TupleType):
+		matches = toTupleUnsafe(matchesArg).elems
+	case matchesArg.isInstance(BaseStringType):
+		matches = []*Object{matchesArg}
+	default:
+		msg := " first arg must be str, unicode, or tuple, not "
+		return nil, f.RaiseType(TypeErrorType, method+msg+matchesArg.typ.Name())
+	}
+	s := toUnicodeUnsafe(args[0]).Value()
+	l := len(s)
+	start, end := 0, l
+	var raised *BaseException
+	if argc >= 3 && args[2] != None {
+		if start, raised = IndexInt(f, args[2]); raised != nil {
+			return nil, raised
+		}
+	}
+	if argc == 4 && args[3] != None {
+		if end, raised = IndexInt(f, args[3]); raised != nil {
+			return nil, raised
+		}
+	}
+	start, end = adjustIndex(start, end, l)
+	for _, match := range matches {
+		u, raised := unicodeCoerce(f, match)
+		if raised != nil {
+			return nil, raised
+		}
+		runes := u.Value()
+		n := len(runes)
+		if n == 0 {
+			// Unlike str, unicode always matches '' regardless of bounds.
+			return True.ToObject(), nil
+		}
+		if start > end || end-start < n {
+			continue
+		}
+		pos := start
+		if method == "endswith" {
+			pos = end - n
+		}
+		if runeSliceCmp(s[pos:pos+n], runes) == 0 {
+			return True.ToObject(), nil
+		}
+	}
+	return False.ToObject(), nil
+}
+
+func unicodeStripImpl(f *Frame, name string, args Args, side stripSide) (*Object, *BaseException) {
+	expectedTypes := []*Type{UnicodeType, ObjectType}
+	argc := len(args)
+	if argc == 1 {
+		expectedTypes = expectedTypes[:argc]
+	}
+	if raised := checkMethodArgs(f, name, args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	s := toUnicodeUnsafe(args[0])
+	charsArg := None
+	if argc > 1 {
+		charsArg = args[1]
+	}
+	matchFunc := unicode.IsSpace
+	if charsArg != None {
+		chars, raised := unicodeCoerce(f, charsArg)
+		if raised != nil {
+			return nil, raised
+		}
+		matchFunc = func(r rune) bool {
+			for _, c := range chars.Value() {
+				if r == c {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	runes := s.Value()
+	numRunes := len(runes)
+	lindex := 0
+	if side == stripSideLeft || side == stripSideBoth {
+		for ; lindex < numRunes; lindex++ {
+			if !matchFunc(runes[lindex]) {
+				break
+			}
+		}
+	}
+	rindex := numRunes
+	if side == stripSideRight || side == stripSideBoth {
+		for ; rindex > lindex; rindex-- {
+			if !matchFunc(runes[rindex-1]) {
+				break
+			}
+		}
+	}
+	result := make([]rune, rindex-lindex)
+	copy(result, runes[lindex:rindex])
+	return NewUnicodeFromRunes(result).ToObject(), nil
+}
+
+// runeSliceIndex returns the index of the first occurrence of sub in s, or -1
+// if sub is not present.
+func runeSliceIndex(s, sub []rune) int {
+	n := len(sub)
+	for i := 0; i+n <= len(s); i++ {
+		if runeSliceCmp(s[i:i+n], sub) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// runeSliceLastIndex returns the index of the last occurrence of sub in s, or
+// -1 if sub is not present.
+func runeSliceLastIndex(s, sub []rune) int {
+	n := len(sub)
+	for i := len(s) - n; i >= 0; i-- {
+		if runeSliceCmp(s[i:i+n], sub) == 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -16,6 +16,7 @@ package grumpy
 
 import (
 	"bytes"
+	"math/big"
 	"reflect"
 	"testing"
 	"unicode"
@@ -53,6 +54,13 @@ func TestUnicodeBinaryOps(t *testing.T) {
 		{args: wrapArgs(Add, NewUnicode("baz"), NewUnicode("")), want: NewUnicode("baz").ToObject()},
 		{args: wrapArgs(Add, NewUnicode(""), newObject(ObjectType)), wantExc: mustCreateException(TypeErrorType, "coercing to Unicode: need string, object found")},
 		{args: wrapArgs(Add, None, NewUnicode("")), wantExc: mustCreateException(TypeErrorType, "unsupported operand type(s) for +: 'NoneType' and 'unicode'")},
+		{args: wrapArgs(Mod, NewUnicode("%5s|%-4s|%.1s"), newTestTuple(NewUnicode("вол"), "ab", NewUnicode("во"))), want: NewUnicode("  вол|ab  |в").ToObject()},
+		{args: wrapArgs(Mod, NewUnicode("%(a)s %(b)d"), newTestDict(NewUnicode("a"), "x", "b", 2)), want: NewUnicode("x 2").ToObject()},
+		{args: wrapArgs(Mod, NewUnicode("%c%c"), newTestTuple(0x432, NewUnicode("x"))), want: NewUnicode("вx").ToObject()},
+		{args: wrapArgs(Mod, NewUnicode("%c"), 0x110000), wantExc: mustCreateException(OverflowErrorType, "%c arg not in range(0x110000)")},
+		{args: wrapArgs(Mod, NewUnicode("%s"), "\xff"), wantExc: mustCreateException(UnicodeDecodeErrorType, "'utf8' codec can't decode byte 0xff in position 0")},
+		{args: wrapArgs(Mod, "%s-%d", newTestTuple(NewUnicode("в"), 3)), want: NewUnicode("в-3").ToObject()},
+		{args: wrapArgs(Mod, "%r", NewUnicode("x")), want: NewStr("u'x'").ToObject()},
 		{args: wrapArgs(Mul, NewUnicode(""), 10), want: NewUnicode("").ToObject()},
 		{args: wrapArgs(Mul, NewUnicode("foo"), -2), want: NewUnicode("").ToObject()},
 		{args: wrapArgs(Mul, NewUnicode("foobar"), 0), want: NewUnicode("").ToObject()},
@@ -97,6 +105,9 @@ func TestUnicodeContains(t *testing.T) {
 		{args: wrapArgs(NewUnicode("foobar"), NewUnicode("foo")), want: True.ToObject()},
 		{args: wrapArgs(NewUnicode("abcdef"), NewUnicode("bar")), want: False.ToObject()},
 		{args: wrapArgs(NewUnicode(""), NewUnicode("")), want: True.ToObject()},
+		{args: wrapArgs(NewUnicode("вол"), NewUnicode("ол")), want: True.ToObject()},
+		{args: wrapArgs(NewUnicode("вол"), "\xd0\xbe"), want: True.ToObject()},
+		{args: wrapArgs(NewUnicode("вол"), NewUnicode("\u00d0")), want: False.ToObject()},
 		{args: wrapArgs(NewUnicode(""), 102.1), wantExc: mustCreateException(TypeErrorType, "coercing to Unicode: need string, float found")},
 	}
 	for _, cas := range cases {
@@ -110,6 +121,7 @@ func TestUnicodeEncode(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewUnicode("foo")), want: NewStr("foo").ToObject()},
 		{args: wrapArgs(NewUnicode("foob\u0300ar"), "utf8"), want: NewStr("foob\u0300ar").ToObject()},
+		{args: wrapArgs(NewUnicode("foob\u0300ar"), NewUnicode("utf8")), want: NewStr("foob\u0300ar").ToObject()},
 		{args: wrapArgs(NewUnicode("foo"), "noexist", "strict"), wantExc: mustCreateException(LookupErrorType, "unknown encoding: noexist")},
 		{args: wrapArgs(NewUnicodeFromRunes([]rune{'в', 'о', 'л', 'н'}), "utf8", "strict"), want: NewStr("\xd0\xb2\xd0\xbe\xd0\xbb\xd0\xbd").ToObject()},
 		{args: wrapArgs(NewUnicodeFromRunes([]rune{'\xff'}), "utf8"), want: NewStr("\xc3\xbf").ToObject()},
@@ -133,6 +145,8 @@ func TestUnicodeGetItem(t *testing.T) {
 		{args: wrapArgs(NewUnicode("baz"), -4), wantExc: mustCreateException(IndexErrorType, "index out of range")},
 		{args: wrapArgs(NewUnicode(""), 0), wantExc: mustCreateException(IndexErrorType, "index out of range")},
 		{args: wrapArgs(NewUnicode("foo"), 3), wantExc: mustCreateException(IndexErrorType, "index out of range")},
+		{args: wrapArgs(NewUnicode("bar"), big.NewInt(-2)), want: NewUnicode("a").ToObject()},
+		{args: wrapArgs(NewUnicode("bar"), new(big.Int).Lsh(big.NewInt(-1), 100)), wantExc: mustCreateException(IndexErrorType, "cannot fit 'long' into an index-sized integer")},
 		{args: wrapArgs(NewUnicode("bar"), newTestSlice(None, 2)), want: NewStr("ba").ToObject()},
 		{args: wrapArgs(NewUnicode("bar"), newTestSlice(1, 3)), want: NewStr("ar").ToObject()},
 		{args: wrapArgs(NewUnicode("bar"), newTestSlice(1, None)), want: NewStr("ar").ToObject()},
@@ -161,6 +175,27 @@ func TestUnicodeHash(t *testing.T) {
 	}
 }
 
+func TestUnicodeIter(t *testing.T) {
+	cases := []invokeTestCase{
+		{args: wrapArgs(NewUnicode("")), want: NewList().ToObject()},
+		{args: wrapArgs(NewUnicode("foo")), want: newTestList(NewUnicode("f"), NewUnicode("o"), NewUnicode("o")).ToObject()},
+		{args: wrapArgs(NewUnicode("в\U0001f600")), want: newTestList(NewUnicode("в"), NewUnicode("\U0001f600")).ToObject()},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(ListType.ToObject(), &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestUnicodeIteratorIter(t *testing.T) {
+	iter := mustNotRaise(Iter(NewRootFrame(), NewUnicode("foo").ToObject()))
+	cas := &invokeTestCase{args: wrapArgs(iter), want: iter}
+	if err := runInvokeMethodTestCase(unicodeIteratorType, "__iter__", cas); err != "" {
+		t.Error(err)
+	}
+}
+
 func TestUnicodeLen(t *testing.T) {
 	cases := []invokeTestCase{
 		{args: wrapArgs(NewUnicode("foo")), want: NewInt(3).ToObject()},
@@ -181,11 +216,71 @@ func TestUnicodeMethods(t *testing.T) {
 		want       *Object
 		wantExc    *BaseException
 	}{
+		{"isalnum", wrapArgs(NewUnicode("абв123")), True.ToObject(), nil},
+		{"isalnum", wrapArgs(NewUnicode("abc_")), False.ToObject(), nil},
+		{"isalpha", wrapArgs(NewUnicode("")), False.ToObject(), nil},
+		{"isalpha", wrapArgs(NewUnicode("вол")), True.ToObject(), nil},
+		{"isdigit", wrapArgs(NewUnicode("123")), True.ToObject(), nil},
+		{"isdigit", wrapArgs(NewUnicode("1.5")), False.ToObject(), nil},
+		{"islower", wrapArgs(NewUnicode("вол 1")), True.ToObject(), nil},
+		{"islower", wrapArgs(NewUnicode("123")), False.ToObject(), nil},
+		{"islower", wrapArgs(NewUnicode("воЛ")), False.ToObject(), nil},
+		{"isnumeric", wrapArgs(NewUnicode("\u00bd")), True.ToObject(), nil},
+		{"isspace", wrapArgs(NewUnicode(" \t\u3000")), True.ToObject(), nil},
+		{"isspace", wrapArgs(NewUnicode("")), False.ToObject(), nil},
+		{"istitle", wrapArgs(NewUnicode("Вол Кот")), True.ToObject(), nil},
+		{"istitle", wrapArgs(NewUnicode("1 Ab")), True.ToObject(), nil},
+		{"istitle", wrapArgs(NewUnicode("\u01c5emal")), True.ToObject(), nil},
+		{"istitle", wrapArgs(NewUnicode("ВОЛ")), False.ToObject(), nil},
+		{"istitle", wrapArgs(NewUnicode("")), False.ToObject(), nil},
+		{"isupper", wrapArgs(NewUnicode("ВОЛ!")), True.ToObject(), nil},
+		{"isupper", wrapArgs(NewUnicode("Вол")), False.ToObject(), nil},
 		{"join", wrapArgs(NewUnicode(","), newTestList("foo", "bar")), NewUnicode("foo,bar").ToObject(), nil},
 		{"join", wrapArgs(NewUnicode(":"), newTestList(NewUnicode("foo"), "bar", NewUnicode("baz"))), NewUnicode("foo:bar:baz").ToObject(), nil},
 		{"join", wrapArgs(NewUnicode("nope"), NewTuple()), NewUnicode("").ToObject(), nil},
 		{"join", wrapArgs(NewUnicode("nope"), newTestTuple(NewUnicode("foo"))), NewUnicode("foo").ToObject(), nil},
+		{"capitalize", wrapArgs(NewUnicode("вОЛ кот")), NewUnicode("Вол кот").ToObject(), nil},
+		{"capitalize", wrapArgs(NewUnicode("")), NewUnicode("").ToObject(), nil},
+		{"expandtabs", wrapArgs(NewUnicode("a\tbc\td\n\tв")), NewUnicode("a       bc      d\n        в").ToObject(), nil},
+		{"expandtabs", wrapArgs(NewUnicode("ab\tc"), 4), NewUnicode("ab  c").ToObject(), nil},
+		{"expandtabs", wrapArgs(NewUnicode("a\tb"), 0), NewUnicode("ab").ToObject(), nil},
+		{"find", wrapArgs(NewUnicode("abcabc"), NewUnicode("c"), 3), NewInt(5).ToObject(), nil},
+		{"find", wrapArgs(NewUnicode("abc"), "", 5), NewInt(-1).ToObject(), nil},
+		{"find", wrapArgs(NewUnicode("абв"), NewUnicode("в")), NewInt(2).ToObject(), nil},
+		{"find", wrapArgs(NewUnicode("abc"), 1), nil, mustCreateException(TypeErrorType, "coercing to Unicode: need string, int found")},
+		{"index", wrapArgs(NewUnicode("abc"), NewUnicode("bc")), NewInt(1).ToObject(), nil},
+		{"index", wrapArgs(NewUnicode("abc"), NewUnicode("d")), nil, mustCreateException(ValueErrorType, "substring not found")},
+		{"count", wrapArgs(NewUnicode("вавав"), NewUnicode("ва")), NewInt(2).ToObject(), nil},
+		{"count", wrapArgs(NewUnicode("abc"), ""), NewInt(4).ToObject(), nil},
+		{"count", wrapArgs(NewUnicode("abcabc"), "bc", 2, 5), NewInt(0).ToObject(), nil},
+		{"endswith", wrapArgs(NewUnicode("вол"), NewUnicode("ол")), True.ToObject(), nil},
+		{"endswith", wrapArgs(NewUnicode("foobar"), newTestTuple("baz", NewUnicode("bar"))), True.ToObject(), nil},
+		{"endswith", wrapArgs(NewUnicode("foobar"), "bar", 0, -1), False.ToObject(), nil},
+		{"endswith", wrapArgs(NewUnicode("foo"), 123), nil, mustCreateException(TypeErrorType, "endswith first arg must be str, unicode, or tuple, not int")},
+		{"split", wrapArgs(NewUnicode(" a\u3000b  c ")), newTestList(NewUnicode("a"), NewUnicode("b"), NewUnicode("c")).ToObject(), nil},
+		{"split", wrapArgs(NewUnicode(" a b c "), None, 1), newTestList(NewUnicode("a"), NewUnicode("b c ")).ToObject(), nil},
+		{"split", wrapArgs(NewUnicode("в,о,л"), ",", 1), newTestList(NewUnicode("в"), NewUnicode("о,л")).ToObject(), nil},
+		{"split", wrapArgs(NewUnicode("abc"), ""), nil, mustCreateException(ValueErrorType, "empty separator")},
+		{"startswith", wrapArgs(NewUnicode("вол"), NewUnicode("во")), True.ToObject(), nil},
+		{"startswith", wrapArgs(NewUnicode("foobar"), "bar", 3), True.ToObject(), nil},
+		{"startswith", wrapArgs(NewUnicode("foo"), "", 4), True.ToObject(), nil},
+		{"startswith", wrapArgs(NewUnicode("foo"), "o", 4), False.ToObject(), nil},
 		{"join", wrapArgs(NewUnicode(","), newTestList("foo", "bar", 3.14)), nil, mustCreateException(TypeErrorType, "coercing to Unicode: need string, float found")},
+		{"lower", wrapArgs(NewUnicode("FoO")), NewUnicode("foo").ToObject(), nil},
+		{"lower", wrapArgs(NewUnicode("ВОЛ")), NewUnicode("вол").ToObject(), nil},
+		{"lstrip", wrapArgs(NewUnicode("  x\n")), NewUnicode("x\n").ToObject(), nil},
+		{"lstrip", wrapArgs(NewUnicode("xxaxx"), "x"), NewUnicode("axx").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode("aaa"), "a", NewUnicode("b"), 2), NewUnicode("bba").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode("абаб"), NewUnicode("б"), NewUnicode("")), NewUnicode("аа").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode("abc"), NewUnicode(""), NewUnicode("-")), NewUnicode("-a-b-c-").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode("abc"), NewUnicode(""), NewUnicode("-"), 2), NewUnicode("-a-bc").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode(""), NewUnicode(""), NewUnicode("x")), NewUnicode("x").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode(""), NewUnicode(""), NewUnicode("x"), 1), NewUnicode("").ToObject(), nil},
+		{"replace", wrapArgs(NewUnicode("abc"), NewUnicode("b"), NewUnicode("x"), 0), NewUnicode("abc").ToObject(), nil},
+		{"rfind", wrapArgs(NewUnicode("abcabc"), "b"), NewInt(4).ToObject(), nil},
+		{"rfind", wrapArgs(NewUnicode("abc"), NewUnicode("")), NewInt(3).ToObject(), nil},
+		{"rindex", wrapArgs(NewUnicode("abc"), NewUnicode("d")), nil, mustCreateException(ValueErrorType, "substring not found")},
+		{"rstrip", wrapArgs(NewUnicode("xxaxx"), NewUnicode("x")), NewUnicode("xxa").ToObject(), nil},
 		{"strip", wrapArgs(NewUnicode("foo ")), NewStr("foo").ToObject(), nil},
 		{"strip", wrapArgs(NewUnicode(" foo bar ")), NewStr("foo bar").ToObject(), nil},
 		{"strip", wrapArgs(NewUnicode("foo foo"), "o"), NewStr("foo f").ToObject(), nil},
@@ -194,6 +289,10 @@ func TestUnicodeMethods(t *testing.T) {
 		{"strip", wrapArgs(NewUnicode("123"), 3), nil, mustCreateException(TypeErrorType, "coercing to Unicode: need string, int found")},
 		{"strip", wrapArgs(NewUnicode("foo"), "bar", "baz"), nil, mustCreateException(TypeErrorType, "'strip' of 'unicode' requires 2 arguments")},
 		{"strip", wrapArgs(NewUnicode("foo"), NewUnicode("o")), NewUnicode("f").ToObject(), nil},
+		{"swapcase", wrapArgs(NewUnicode("ВоЛ 1")), NewUnicode("вОл 1").ToObject(), nil},
+		{"title", wrapArgs(NewUnicode("вол кОТ1x")), NewUnicode("Вол Кот1X").ToObject(), nil},
+		{"title", wrapArgs(NewUnicode("\u01c6emal")), NewUnicode("\u01c5emal").ToObject(), nil},
+		{"upper", wrapArgs(NewUnicode("вол")), NewUnicode("ВОЛ").ToObject(), nil},
 	}
 	for _, cas := range cases {
 		testCase := invokeTestCase{args: cas.args, want: cas.want, wantExc: cas.wantExc}
@@ -256,11 +355,11 @@ func TestUnicodeNew(t *testing.T) {
 		{args: wrapArgs(UnicodeType, 3.14, "utf8"), wantExc: mustCreateException(TypeErrorType, "coercing to Unicode: need str, float found")},
 		{args: wrapArgs(UnicodeType, "baz", "utf8"), want: NewUnicode("baz").ToObject()},
 		{args: wrapArgs(UnicodeType, "baz", "utf-8"), want: NewUnicode("baz").ToObject()},
-		{args: wrapArgs(UnicodeType, "foo\xffbar", "utf_8"), wantExc: mustCreateException(UnicodeDecodeErrorType, "'utf_8' codec can't decode byte 0xff in position 3")},
+		{args: wrapArgs(UnicodeType, "foo\xffbar", "utf_8"), wantExc: mustCreateException(UnicodeDecodeErrorType, "'utf8' codec can't decode byte 0xff in position 3")},
 		{args: wrapArgs(UnicodeType, "foo\xffbar", "UTF8", "ignore"), want: NewUnicode("foobar").ToObject()},
 		{args: wrapArgs(UnicodeType, "foo\xffbar", "utf8", "replace"), want: NewUnicode("foo\ufffdbar").ToObject()},
 		{args: wrapArgs(UnicodeType, "\xff", "utf-8", "noexist"), wantExc: mustCreateException(LookupErrorType, "unknown error handler name 'noexist'")},
-		{args: wrapArgs(UnicodeType, "\xff", "utf16"), wantExc: mustCreateException(LookupErrorType, "unknown encoding: utf16")},
+		{args: wrapArgs(UnicodeType, "\xff", "utf32"), wantExc: mustCreateException(LookupErrorType, "unknown encoding: utf32")},
 		{args: wrapArgs(strictEqType, NewUnicode("foo")), want: (&Unicode{Object{typ: strictEqType}, bytes.Runes([]byte("foo"))}).ToObject()},
 	}
 	for _, cas := range cases {
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"unsafe"
+	"weak"
 )
 
 var (
@@ -39,26 +40,57 @@ const (
 // WeakRef represents Python 'weakref' objects.
 type WeakRef struct {
 	Object
-	ptr       uintptr
-	mutex     sync.Mutex
-	state     weakRefState
-	callbacks []*Object
-	hash      *Object
+	ptr   uintptr
+	mutex sync.Mutex
+	state weakRefState
+	hash  *Object
+	// base is the weakref stored in the referent, which tracks whether
+	// the referent is alive. It is nil if r is that weakref, in which
+	// case r.mutex guards the state of all weakrefs to the referent.
+	base *WeakRef
+	// callback is called with r when the referent dies, or nil.
+	callback *Object
+	// stack is the Policy of the stack that created r, to which callback
+	// is subject.
+	stack stackPolicy
+	// refs holds the weakrefs with callbacks to the referent of a base
+	// weakref. The referent does not keep them alive: a collected one
+	// yields nil and is pruned from refs when it's finalized.
+	refs []weak.Pointer[WeakRef]
 }
 
 func toWeakRefUnsafe(o *Object) *WeakRef {
 	return (*WeakRef)(o.toPointer())
 }
 
-// get returns r's referent, or nil if r is "dead".
+// root returns the base weakref that tracks the liveness of r's referent.
+func (r *WeakRef) root() *WeakRef {
+	if r.base != nil {
+		return r.base
+	}
+	return r
+}
+
+// get returns r's referent, or nil if r is "dead". The mutex of r's root
+// must be held.
 func (r *WeakRef) get() *Object {
-	if r.state == weakRefStateDead {
+	b := r.root()
+	if b.state == weakRefStateDead {
 		return nil
 	}
-	r.state = weakRefStateUsed
+	b.state = weakRefStateUsed
 	return (*Object)(unsafe.Pointer(r.ptr))
 }
 
+// referent returns r's referent, or nil if r is "dead".
+func (r *WeakRef) referent() *Object {
+	b := r.root()
+	b.mutex.Lock()
+	o := r.get()
+	b.mutex.Unlock()
+	return o
+}
+
 // ToObject upcasts r to an Object.
 func (r *WeakRef) ToObject() *Object {
 	return &r.Object
@@ -68,34 +100,36 @@ func weakRefCall(f *Frame, callable *Object, args Args, _ KWArgs) (*Object, *Bas
 	if raised := checkFunctionArgs(f, "__call__", args); raised != nil {
 		return nil, raised
 	}
-	r := toWeakRefUnsafe(callable)
-	r.mutex.Lock()
-	o := r.get()
-	r.mutex.Unlock()
+	o := toWeakRefUnsafe(callable).referent()
 	if o == nil {
 		o = None
 	}
 	return o, nil
 }
 
+func weakRefEq(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return weakRefCompare(f, v, w, Eq, true)
+}
+
 func weakRefHash(f *Frame, o *Object) (result *Object, raised *BaseException) {
 	r := toWeakRefUnsafe(o)
+	b := r.root()
 	var referent *Object
-	r.mutex.Lock()
+	b.mutex.Lock()
 	if r.hash != nil {
 		result = r.hash
 	} else {
 		referent = r.get()
 	}
-	r.mutex.Unlock()
+	b.mutex.Unlock()
 	if referent != nil {
 		var hash *Int
 		hash, raised = Hash(f, referent)
 		if raised == nil {
 			result = hash.ToObject()
-			r.mutex.Lock()
+			b.mutex.Lock()
 			r.hash = result
-			r.mutex.Unlock()
+			b.mutex.Unlock()
 		}
 	} else if result == nil {
 		raised = f.RaiseType(TypeErrorType, "weak object has gone away")
@@ -103,6 +137,10 @@ func weakRefHash(f *Frame, o *Object) (result *Object, raised *BaseException) {
 	return result, raised
 }
 
+func weakRefNE(f *Frame, v, w *Object) (*Object, *BaseException) {
+	return weakRefCompare(f, v, w, NE, false)
+}
+
 func weakRefNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException) {
 	if raised := checkFunctionVarArgs(f, "__new__", args, ObjectType); raised != nil {
 		return nil, raised
@@ -115,34 +153,51 @@ func weakRefNew(f *Frame, t *Type, args Args, _ KWArgs) (*Object, *BaseException
 	o := args[0]
 	nilPtr := unsafe.Pointer(nil)
 	addr := (*unsafe.Pointer)(unsafe.Pointer(&o.ref))
-	var r *WeakRef
+	var b *WeakRef
 	// Atomically fetch or initialize o.ref.
 	for {
 		p := atomic.LoadPointer(addr)
 		if p != nilPtr {
-			r = (*WeakRef)(p)
+			b = (*WeakRef)(p)
 			break
 		} else {
-			r = &WeakRef{Object: Object{typ: WeakRefType}, ptr: uintptr(o.toPointer())}
-			if atomic.CompareAndSwapPointer(addr, nilPtr, r.toPointer()) {
-				runtime.SetFinalizer(o, weakRefFinalizeReferent)
+			b = &WeakRef{Object: Object{typ: WeakRefType}, ptr: uintptr(o.toPointer())}
+			if atomic.CompareAndSwapPointer(addr, nilPtr, b.toPointer()) {
+				// Objects whose type defines __del__ always
+				// have a finalizer.
+				if o.typ.slots.Del == nil {
+					runtime.SetFinalizer(o, objectFinalizer)
+				}
 				break
 			}
 		}
 	}
-	if argc > 1 {
-		r.mutex.Lock()
-		r.callbacks = append(r.callbacks, args[1])
-		r.mutex.Unlock()
+	var callback *Object
+	if argc > 1 && args[1] != None {
+		callback = args[1]
+	}
+	// Like CPython, plain weakrefs to the same object are shared but each
+	// weakref with a callback or of a subclass is a distinct object.
+	if t == WeakRefType && callback == nil {
+		return b.ToObject(), nil
+	}
+	r := toWeakRefUnsafe(newObject(t))
+	r.ptr = b.ptr
+	r.base = b
+	r.callback = callback
+	if callback != nil {
+		r.stack = f.stackPolicy()
+		b.mutex.Lock()
+		b.refs = append(b.refs, weak.Make(r))
+		b.mutex.Unlock()
+		runtime.SetFinalizer(r, weakRefFinalize)
 	}
 	return r.ToObject(), nil
 }
 
 func weakRefRepr(f *Frame, o *Object) (*Object, *BaseException) {
 	r := toWeakRefUnsafe(o)
-	r.mutex.Lock()
-	p := r.get()
-	r.mutex.Unlock()
+	p := r.referent()
 	s := "dead"
 	if p != nil {
 		s = fmt.Sprintf("to '%s' at %p", p.Type().Name(), p)
@@ -152,43 +207,92 @@ func weakRefRepr(f *Frame, o *Object) (*Object, *BaseException) {
 
 func initWeakRefType(map[string]*Object) {
 	WeakRefType.slots.Call = &callSlot{weakRefCall}
+	WeakRefType.slots.Eq = &binaryOpSlot{weakRefEq}
 	WeakRefType.slots.Hash = &unaryOpSlot{weakRefHash}
+	WeakRefType.slots.NE = &binaryOpSlot{weakRefNE}
 	WeakRefType.slots.New = &newSlot{weakRefNew}
 	WeakRefType.slots.Repr = &unaryOpSlot{weakRefRepr}
 }
 
-func weakRefFinalizeReferent(o *Object) {
+// weakRefCompare compares the referents of weakrefs v and w using cmp. If
+// either is dead then they're equal only if they're the same weakref.
+func weakRefCompare(f *Frame, v, w *Object, cmp binaryOpFunc, eq bool) (*Object, *BaseException) {
+	if !w.isInstance(WeakRefType) {
+		return NotImplemented, nil
+	}
+	p := toWeakRefUnsafe(v).referent()
+	q := toWeakRefUnsafe(w).referent()
+	if p == nil || q == nil {
+		return GetBool((v == w) == eq).ToObject(), nil
+	}
+	return cmp(f, p, q)
+}
+
+// weakRefFinalize prunes r, whose weak pointer has already been cleared, and
+// any other collected weakrefs from the refs of r's base weakref.
+func weakRefFinalize(r *WeakRef) {
+	b := r.base
+	b.mutex.Lock()
+	refs := b.refs[:0]
+	for _, p := range b.refs {
+		if p.Value() != nil {
+			refs = append(refs, p)
+		}
+	}
+	b.refs = refs
+	b.mutex.Unlock()
+}
+
+// weakRefFinalizeReferent clears the weakrefs to o, which is being finalized,
+// and returns those with callbacks, which should be passed to
+// weakRefCallCallbacks. It returns false if o may still be alive, in which
+// case the finalizer must be reset.
+func weakRefFinalizeReferent(o *Object) ([]*WeakRef, bool) {
 	// Note that although o should be the last reference to that object
 	// (since this is its finalizer), in the time between the runtime
 	// scheduling this finalizer and the Lock() call below, r may have
 	// handed out another reference to o. So we can't simply mark r "dead".
 	addr := (*unsafe.Pointer)(unsafe.Pointer(&o.ref))
 	r := (*WeakRef)(atomic.LoadPointer(addr))
-	numCallbacks := 0
-	var callbacks []*Object
+	if r == nil {
+		return nil, true
+	}
+	dead := true
+	var refs []*WeakRef
 	r.mutex.Lock()
 	switch r.state {
 	case weakRefStateNew:
 		// State "new" means that no references have been handed out by
 		// r and therefore o is the only live reference.
 		r.state = weakRefStateDead
-		numCallbacks = len(r.callbacks)
-		callbacks = make([]*Object, numCallbacks)
-		copy(callbacks, r.callbacks)
+		// Skip the weakrefs in r.refs that have been collected since
+		// their callbacks must not be called.
+		for _, p := range r.refs {
+			if w := p.Value(); w != nil {
+				refs = append(refs, w)
+			}
+		}
+		r.refs = nil
 	case weakRefStateUsed:
 		// Most likely it's safe to mark r "dead" at this point, but
 		// because a reference was handed out at some point, play it
 		// safe and reset the finalizer. If no more references are
 		// handed out before the next finalize then it will be "dead".
 		r.state = weakRefStateNew
-		runtime.SetFinalizer(o, weakRefFinalizeReferent)
+		dead = false
 	}
 	r.mutex.Unlock()
-	// Don't hold r.mutex while invoking callbacks in case they access r
-	// and attempt to acquire the mutex.
-	for i := numCallbacks - 1; i >= 0; i-- {
-		f := NewRootFrame()
-		if _, raised := callbacks[i].Call(f, Args{r.ToObject()}, nil); raised != nil {
+	return refs, dead
+}
+
+// weakRefCallCallbacks calls the callbacks of refs, most recently created
+// first, each subject to the Policy of the stack that created the weakref.
+// The caller must not hold the mutex of the weakrefs' base in case the
+// callbacks access it.
+func weakRefCallCallbacks(refs []*WeakRef) {
+	for i := len(refs) - 1; i >= 0; i-- {
+		f := refs[i].stack.newRootFrame()
+		if _, raised := refs[i].callback.Call(f, Args{refs[i].ToObject()}, nil); raised != nil {
 			Stderr.writeString(FormatExc(f))
 		}
 	}
@@ -87,7 +87,7 @@ func TestWeakRefNewCallback(t *testing.T) {
 	})
 	r := newTestWeakRef(newObject(ObjectType), callback)
 	weakRefMustDie(r)
-	if r.get() != nil {
+	if r.referent() != nil {
 		t.Fatalf("expected weakref %v to be dead", r)
 	}
 	if callbackGot := <-callbackChannel; callbackGot != r {
@@ -95,6 +95,99 @@ func TestWeakRefNewCallback(t *testing.T) {
 	}
 }
 
+func TestWeakRefNewCallbackCollected(t *testing.T) {
+	called := make(chan bool, 1)
+	callback := wrapFuncForTest(func(f *Frame, r *WeakRef) {
+		called <- true
+	})
+	o := newObject(ObjectType)
+	base := newTestWeakRef(o, nil)
+	newTestWeakRef(o, callback)
+	// Wait for the unreferenced weakref to be finalized.
+	for i := 0; ; i++ {
+		base.mutex.Lock()
+		n := len(base.refs)
+		base.mutex.Unlock()
+		if n == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("weakref with callback was not finalized")
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	runtime.KeepAlive(o)
+	weakRefMustDie(base)
+	select {
+	case <-called:
+		t.Error("callback of collected weakref was called")
+	default:
+	}
+}
+
+func TestWeakRefNewDistinct(t *testing.T) {
+	fooType := newTestClass("Foo", []*Type{WeakRefType}, NewDict())
+	alive := NewStr("foo").ToObject()
+	callback := wrapFuncForTest(func(*Frame, *WeakRef) {})
+	shared := newTestWeakRef(alive, nil)
+	if r := newTestWeakRef(alive, None); r != shared {
+		t.Errorf("weakref(%v, None) = %v, want %v", alive, r, shared)
+	}
+	withCallback := newTestWeakRef(alive, callback)
+	if withCallback == shared {
+		t.Errorf("weakref(%v, %v) returned the shared weakref", alive, callback)
+	}
+	sub := toWeakRefUnsafe(mustNotRaise(fooType.Call(NewRootFrame(), Args{alive}, nil)))
+	if sub == shared || sub.typ != fooType {
+		t.Errorf("Foo(%v) = %v, want a new Foo", alive, sub)
+	}
+	for _, r := range []*WeakRef{withCallback, sub} {
+		if got := r.referent(); got != alive {
+			t.Errorf("%v() = %v, want %v", r, got, alive)
+		}
+	}
+	if sub.Dict() == nil {
+		t.Errorf("Foo(%v) has no __dict__", alive)
+	}
+	runtime.KeepAlive(alive)
+}
+
+func TestWeakRefCompare(t *testing.T) {
+	aliveRef, alive, deadRef := makeWeakRefsForTest()
+	callbackRef := newTestWeakRef(alive, wrapFuncForTest(func(*Frame, *WeakRef) {}))
+	other := NewStr("foo").ToObject()
+	otherRef := newTestWeakRef(other, nil)
+	_, _, otherDeadRef := makeWeakRefsForTest()
+	cases := []struct {
+		v, w   *WeakRef
+		wantEq bool
+	}{
+		{aliveRef, aliveRef, true},
+		{aliveRef, callbackRef, true},
+		{aliveRef, otherRef, true},
+		{aliveRef, deadRef, false},
+		{deadRef, deadRef, true},
+		{deadRef, otherDeadRef, false},
+	}
+	for _, cas := range cases {
+		for _, op := range []struct {
+			fun  binaryOpFunc
+			want bool
+		}{{Eq, cas.wantEq}, {NE, !cas.wantEq}} {
+			got, raised := op.fun(NewRootFrame(), cas.v.ToObject(), cas.w.ToObject())
+			if raised != nil || got != GetBool(op.want).ToObject() {
+				t.Errorf("comparing %v and %v = %v (raised %v), want %v", cas.v, cas.w, got, raised, op.want)
+			}
+		}
+	}
+	if got := mustNotRaise(Eq(NewRootFrame(), aliveRef.ToObject(), alive)); got != False.ToObject() {
+		t.Errorf("%v == %v = %v, want False", aliveRef, alive, got)
+	}
+	runtime.KeepAlive(alive)
+	runtime.KeepAlive(other)
+}
+
 func TestWeakRefNewCallbackRaises(t *testing.T) {
 	// It's not easy to verify that the exception is output properly, but
 	// we can at least make sure the program doesn't blow up if the
@@ -104,7 +197,7 @@ func TestWeakRefNewCallbackRaises(t *testing.T) {
 	})
 	r := newTestWeakRef(newObject(ObjectType), callback)
 	weakRefMustDie(r)
-	if r.get() != nil {
+	if r.referent() != nil {
 		t.Fatalf("expected weakref %v to be dead", r)
 	}
 }
@@ -145,9 +238,7 @@ func makeWeakRefsForTest() (*WeakRef, *Object, *WeakRef) {
 }
 
 func weakRefMustDie(r *WeakRef) {
-	r.mutex.Lock()
-	o := r.get()
-	r.mutex.Unlock()
+	o := r.referent()
 	if o == nil {
 		return
 	}
@@ -155,7 +246,9 @@ func weakRefMustDie(r *WeakRef) {
 	callback := wrapFuncForTest(func(f *Frame, r *WeakRef) {
 		close(doneChannel)
 	})
-	mustNotRaise(WeakRefType.Call(NewRootFrame(), Args{o, callback}, nil))
+	// The callback is only called while the weakref is alive.
+	callbackRef := mustNotRaise(WeakRefType.Call(NewRootFrame(), Args{o, callback}, nil))
+	defer runtime.KeepAlive(callbackRef)
 	o = nil
 	timeoutChannel := make(chan bool)
 	go func() {
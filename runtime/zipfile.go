@@ -0,0 +1,298 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// zipFlagUTF8 is the general purpose flag bit marking UTF-8 file names.
+const zipFlagUTF8 = 0x800
+
+// ZipOpenReader parses the zip archive held in data. errorType is raised if
+// data is not a zip archive.
+func ZipOpenReader(f *Frame, data *Str, errorType *Type) (*zip.Reader, *BaseException) {
+	s := data.Value()
+	r, err := zip.NewReader(strings.NewReader(s), int64(len(s)))
+	// ErrInsecurePath is informational: r is still usable. Python
+	// sanitizes the names of extracted files itself.
+	if err != nil && err != zip.ErrInsecurePath {
+		return nil, f.RaiseType(errorType, "File is not a zip file")
+	}
+	return r, nil
+}
+
+// ZipSetInfo populates the attributes of the Python ZipInfo object info
+// from the header of file.
+func ZipSetInfo(f *Frame, info *Object, file *zip.File) *BaseException {
+	var filename *Object
+	if file.Flags&zipFlagUTF8 != 0 {
+		filename = NewUnicode(file.Name).ToObject()
+	} else {
+		filename = NewStr(file.Name).ToObject()
+	}
+	d, t := int(file.ModifiedDate), int(file.ModifiedTime)
+	dateTime := NewTuple(NewInt(d>>9+1980).ToObject(), NewInt(d>>5&0xf).ToObject(), NewInt(d&0x1f).ToObject(), NewInt(t>>11).ToObject(), NewInt(t>>5&0x3f).ToObject(), NewInt(t&0x1f*2).ToObject())
+	attrs := []struct {
+		name  string
+		value *Object
+	}{
+		{"filename", filename},
+		{"date_time", dateTime.ToObject()},
+		{"compress_type", NewInt(int(file.Method)).ToObject()},
+		{"comment", NewStr(file.Comment).ToObject()},
+		{"extra", NewStr(string(file.Extra)).ToObject()},
+		{"create_system", NewInt(int(file.CreatorVersion >> 8)).ToObject()},
+		{"create_version", NewInt(int(file.CreatorVersion & 0xff)).ToObject()},
+		{"extract_version", NewInt(int(file.ReaderVersion)).ToObject()},
+		{"flag_bits", NewInt(int(file.Flags)).ToObject()},
+		{"external_attr", NewLong(zipUint64(uint64(file.ExternalAttrs))).ToObject()},
+		{"CRC", NewLong(zipUint64(uint64(file.CRC32))).ToObject()},
+		{"compress_size", NewLong(zipUint64(file.CompressedSize64)).ToObject()},
+		{"file_size", NewLong(zipUint64(file.UncompressedSize64)).ToObject()},
+	}
+	for _, attr := range attrs {
+		if raised := SetAttr(f, info, NewStr(attr.name), attr.value); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+// ZipReadFile returns the decompressed contents of file, raising errorType if
+// they are corrupt.
+func ZipReadFile(f *Frame, file *zip.File, errorType *Type) (*Str, *BaseException) {
+	rc, err := file.Open()
+	if err == zip.ErrAlgorithm {
+		return nil, f.RaiseType(NotImplementedErrorType, "That compression method is not supported")
+	}
+	if err != nil {
+		return nil, f.RaiseType(errorType, err.Error())
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err == zip.ErrChecksum {
+		return nil, f.RaiseType(errorType, fmt.Sprintf("Bad CRC-32 for file %q", file.Name))
+	}
+	if err != nil {
+		return nil, f.RaiseType(errorType, err.Error())
+	}
+	return NewStr(string(data)), nil
+}
+
+// ZipWriter builds a zip archive in memory.
+type ZipWriter struct {
+	mutex  sync.Mutex
+	buf    bytes.Buffer
+	w      *zip.Writer
+	offset int
+}
+
+// NewZipWriter returns a new ZipWriter for an archive that will be written
+// offset bytes into a file.
+func NewZipWriter(offset int) *ZipWriter {
+	zw := &ZipWriter{offset: offset}
+	zw.w = zip.NewWriter(&zw.buf)
+	zw.w.SetOffset(int64(offset))
+	return zw
+}
+
+// ZipCopyFile adds file, read from another archive, to w without
+// recompressing it.
+func ZipCopyFile(f *Frame, w *ZipWriter, file *zip.File, errorType *Type) *BaseException {
+	w.mutex.Lock()
+	err := w.w.Copy(file)
+	w.mutex.Unlock()
+	if err != nil {
+		return f.RaiseType(errorType, err.Error())
+	}
+	return nil
+}
+
+// ZipWriteFile adds an entry holding data to w, described by the attributes
+// of the Python ZipInfo object info. The CRC, compress_size, file_size and
+// header_offset attributes of info are updated to reflect the new entry.
+func ZipWriteFile(f *Frame, w *ZipWriter, info, data *Object, errorType *Type) *BaseException {
+	fh, raised := zipFileHeader(f, info)
+	if raised != nil {
+		return raised
+	}
+	b, raised := bufferData(f, data, "data must be string or read-only buffer, not %s")
+	if raised != nil {
+		return raised
+	}
+	compressed := b
+	switch fh.Method {
+	case zip.Store:
+	case zip.Deflate:
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		fw.Write(b)
+		fw.Close()
+		compressed = buf.Bytes()
+	default:
+		return f.RaiseType(NotImplementedErrorType, "That compression method is not supported")
+	}
+	fh.CRC32 = crc32.ChecksumIEEE(b)
+	fh.CompressedSize64 = uint64(len(compressed))
+	fh.UncompressedSize64 = uint64(len(b))
+	w.mutex.Lock()
+	w.w.Flush()
+	offset := w.offset + w.buf.Len()
+	fw, err := w.w.CreateRaw(fh)
+	if err == nil {
+		_, err = fw.Write(compressed)
+	}
+	w.mutex.Unlock()
+	if err != nil {
+		return f.RaiseType(errorType, err.Error())
+	}
+	attrs := []struct {
+		name  string
+		value *Object
+	}{
+		{"CRC", NewLong(zipUint64(uint64(fh.CRC32))).ToObject()},
+		{"compress_size", NewLong(zipUint64(fh.CompressedSize64)).ToObject()},
+		{"file_size", NewLong(zipUint64(fh.UncompressedSize64)).ToObject()},
+		{"header_offset", NewLong(zipUint64(uint64(offset))).ToObject()},
+	}
+	for _, attr := range attrs {
+		if raised := SetAttr(f, info, NewStr(attr.name), attr.value); raised != nil {
+			return raised
+		}
+	}
+	return nil
+}
+
+// ZipCloseWriter writes the central directory of the archive built by w with
+// the given comment and returns the archive's contents.
+func ZipCloseWriter(f *Frame, w *ZipWriter, comment string, errorType *Type) (*Str, *BaseException) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.w.SetComment(comment); err != nil {
+		return nil, f.RaiseType(errorType, err.Error())
+	}
+	if err := w.w.Close(); err != nil {
+		return nil, f.RaiseType(errorType, err.Error())
+	}
+	return NewStr(w.buf.String()), nil
+}
+
+// zipFileHeader returns a header populated from the attributes of the Python
+// ZipInfo object info.
+func zipFileHeader(f *Frame, info *Object) (*zip.FileHeader, *BaseException) {
+	fh := &zip.FileHeader{}
+	o, raised := GetAttr(f, info, NewStr("filename"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	if o.isInstance(UnicodeType) {
+		s, raised := toUnicodeUnsafe(o).Encode(f, "utf-8", EncodeStrict)
+		if raised != nil {
+			return nil, raised
+		}
+		fh.Name = s.Value()
+	} else if o.isInstance(StrType) {
+		fh.Name = toStrUnsafe(o).Value()
+	} else {
+		return nil, f.RaiseType(TypeErrorType, fmt.Sprintf("filename must be a string, not %s", o.typ.Name()))
+	}
+	names := []string{"compress_type", "create_system", "create_version", "extract_version", "flag_bits", "external_attr"}
+	values := make([]int, len(names))
+	for i, name := range names {
+		v, raised := GetAttr(f, info, NewStr(name), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		if values[i], raised = zipIntValue(f, v); raised != nil {
+			return nil, raised
+		}
+	}
+	fh.Method = uint16(values[0])
+	fh.CreatorVersion = uint16(values[1]<<8 | values[2]&0xff)
+	fh.ReaderVersion = uint16(values[3])
+	fh.Flags = uint16(values[4])
+	fh.ExternalAttrs = uint32(values[5])
+	if o.isInstance(UnicodeType) && strings.IndexFunc(fh.Name, func(r rune) bool { return r >= 0x80 }) != -1 {
+		fh.Flags |= zipFlagUTF8
+	}
+	fh.NonUTF8 = fh.Flags&zipFlagUTF8 == 0
+	for _, attr := range []struct {
+		name  string
+		value *string
+	}{{"comment", &fh.Comment}, {"extra", nil}} {
+		o, raised := GetAttr(f, info, NewStr(attr.name), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		s, raised := stringIOData(f, o, attr.name+" must be a string, not %s")
+		if raised != nil {
+			return nil, raised
+		}
+		if attr.value != nil {
+			*attr.value = s
+		} else {
+			fh.Extra = []byte(s)
+		}
+	}
+	o, raised = GetAttr(f, info, NewStr("date_time"), nil)
+	if raised != nil {
+		return nil, raised
+	}
+	var dateTime [6]int
+	i := 0
+	raised = seqForEach(f, o, func(elem *Object) *BaseException {
+		if i >= len(dateTime) {
+			return f.RaiseType(ValueErrorType, "date_time must have 6 elements")
+		}
+		var raised *BaseException
+		dateTime[i], raised = zipIntValue(f, elem)
+		i++
+		return raised
+	})
+	if raised != nil {
+		return nil, raised
+	}
+	if i != len(dateTime) {
+		return nil, f.RaiseType(ValueErrorType, "date_time must have 6 elements")
+	}
+	if dateTime[0] < 1980 {
+		return nil, f.RaiseType(ValueErrorType, "ZIP does not support timestamps before 1980")
+	}
+	// Set the MS-DOS fields directly rather than Modified, which would also
+	// add an extended timestamp to the extra field.
+	fh.ModifiedDate = uint16((dateTime[0]-1980)<<9 | dateTime[1]<<5 | dateTime[2])
+	fh.ModifiedTime = uint16(dateTime[3]<<11 | dateTime[4]<<5 | dateTime[5]/2)
+	return fh, nil
+}
+
+func zipIntValue(f *Frame, o *Object) (int, *BaseException) {
+	if o.isInstance(LongType) {
+		return int(toLongUnsafe(o).Value().Int64()), nil
+	}
+	return ToIntValue(f, o)
+}
+
+func zipUint64(v uint64) *big.Int {
+	return new(big.Int).SetUint64(v)
+}
@@ -0,0 +1,142 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"testing"
+)
+
+func newTestZipInfo(filename *Object, compressType int, dateTime *Tuple) *Object {
+	info := newObject(newTestClass("ZipInfo", []*Type{ObjectType}, NewDict()))
+	d := info.Dict()
+	for name, value := range map[string]*Object{
+		"filename":        filename,
+		"date_time":       dateTime.ToObject(),
+		"compress_type":   NewInt(compressType).ToObject(),
+		"comment":         NewStr("").ToObject(),
+		"extra":           NewStr("").ToObject(),
+		"create_system":   NewInt(3).ToObject(),
+		"create_version":  NewInt(20).ToObject(),
+		"extract_version": NewInt(20).ToObject(),
+		"flag_bits":       NewInt(0).ToObject(),
+		"external_attr":   NewInt(0644 << 16).ToObject(),
+	} {
+		d.SetItemString(NewRootFrame(), name, value)
+	}
+	return info
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	f := NewRootFrame()
+	dateTime := newTestTuple(2016, 12, 31, 23, 59, 58)
+	w := NewZipWriter(0)
+	stored := newTestZipInfo(NewStr("foo.txt").ToObject(), 0, dateTime)
+	if raised := ZipWriteFile(f, w, stored, NewStr("foo").ToObject(), RuntimeErrorType); raised != nil {
+		t.Fatal(raised)
+	}
+	deflated := newTestZipInfo(NewUnicode("bär.txt").ToObject(), 8, dateTime)
+	if raised := ZipWriteFile(f, w, deflated, newTestByteArray("barbarbarbar").ToObject(), RuntimeErrorType); raised != nil {
+		t.Fatal(raised)
+	}
+	data, raised := ZipCloseWriter(f, w, "comment", RuntimeErrorType)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	r, raised := ZipOpenReader(f, data, RuntimeErrorType)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if r.Comment != "comment" {
+		t.Errorf("archive comment = %q, want %q", r.Comment, "comment")
+	}
+	if len(r.File) != 2 {
+		t.Fatalf("archive has %d files, want 2", len(r.File))
+	}
+	cases := []struct {
+		file     int
+		filename *Object
+		contents string
+		offset   int
+	}{
+		{0, NewStr("foo.txt").ToObject(), "foo", 0},
+		{1, NewUnicode("bär.txt").ToObject(), "barbarbarbar", 30 + len("foo.txt") + len("foo")},
+	}
+	for _, cas := range cases {
+		file := r.File[cas.file]
+		info := newObject(newTestClass("ZipInfo", []*Type{ObjectType}, NewDict()))
+		if raised := ZipSetInfo(f, info, file); raised != nil {
+			t.Fatal(raised)
+		}
+		for name, want := range map[string]*Object{
+			"filename":      cas.filename,
+			"date_time":     dateTime.ToObject(),
+			"create_system": NewInt(3).ToObject(),
+			"file_size":     NewLong(zipUint64(uint64(len(cas.contents)))).ToObject(),
+			"external_attr": NewLong(zipUint64(0644 << 16)).ToObject(),
+		} {
+			got, raised := GetAttr(f, info, NewStr(name), nil)
+			if raised != nil {
+				t.Fatal(raised)
+			}
+			if eq, raised := Eq(f, got, want); raised != nil || eq != True.ToObject() {
+				t.Errorf("file %d: %s = %v, want %v", cas.file, name, got, want)
+			}
+		}
+		got, raised := ZipReadFile(f, file, RuntimeErrorType)
+		if raised != nil {
+			t.Fatal(raised)
+		}
+		if got.Value() != cas.contents {
+			t.Errorf("ZipReadFile(file %d) = %q, want %q", cas.file, got.Value(), cas.contents)
+		}
+	}
+	offset, raised := GetAttr(f, deflated, NewStr("header_offset"), nil)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	if want := NewLong(zipUint64(uint64(cases[1].offset))).ToObject(); mustNotRaise(Eq(f, offset, want)) != True.ToObject() {
+		t.Errorf("header_offset = %v, want %v", offset, want)
+	}
+}
+
+func TestZipOpenReaderInvalid(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, data *Str) *BaseException {
+		_, raised := ZipOpenReader(f, data, RuntimeErrorType)
+		return raised
+	})
+	cas := invokeTestCase{args: wrapArgs("not a zip"), wantExc: mustCreateException(RuntimeErrorType, "File is not a zip file")}
+	if err := runInvokeTestCase(fun, &cas); err != "" {
+		t.Error(err)
+	}
+}
+
+func TestZipWriteFileErrors(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, info, data *Object) *BaseException {
+		return ZipWriteFile(f, NewZipWriter(0), info, data, RuntimeErrorType)
+	})
+	dateTime := newTestTuple(2016, 1, 1, 0, 0, 0)
+	cases := []invokeTestCase{
+		{args: wrapArgs(newTestZipInfo(NewStr("a").ToObject(), 0, newTestTuple(1979, 1, 1, 0, 0, 0)), "a"), wantExc: mustCreateException(ValueErrorType, "ZIP does not support timestamps before 1980")},
+		{args: wrapArgs(newTestZipInfo(NewStr("a").ToObject(), 0, newTestTuple(2016, 1, 1)), "a"), wantExc: mustCreateException(ValueErrorType, "date_time must have 6 elements")},
+		{args: wrapArgs(newTestZipInfo(NewInt(1).ToObject(), 0, dateTime), "a"), wantExc: mustCreateException(TypeErrorType, "filename must be a string, not int")},
+		{args: wrapArgs(newTestZipInfo(NewStr("a").ToObject(), 12, dateTime), "a"), wantExc: mustCreateException(NotImplementedErrorType, "That compression method is not supported")},
+		{args: wrapArgs(newTestZipInfo(NewStr("a").ToObject(), 0, dateTime), 123), wantExc: mustCreateException(TypeErrorType, "data must be string or read-only buffer, not int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
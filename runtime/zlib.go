@@ -0,0 +1,628 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+const (
+	zlibMaxWBits = 15
+	// Flush modes accepted by Compress.flush().
+	zlibNoFlush   = 0
+	zlibSyncFlush = 2
+	zlibFullFlush = 3
+	zlibFinish    = 4
+)
+
+var (
+	// errZlibAllocLimit stops a zlibInflater whose output exceeds its
+	// limit.
+	errZlibAllocLimit = errors.New("zlib output exceeds allocation limit")
+	// ZlibCompressorType is the object representing the Python
+	// 'zlib.Compress' type.
+	ZlibCompressorType = newBasisType("Compress", reflect.TypeOf(ZlibCompressor{}), toZlibCompressorUnsafe, ObjectType)
+	// ZlibDecompressorType is the object representing the Python
+	// 'zlib.Decompress' type.
+	ZlibDecompressorType = newBasisType("Decompress", reflect.TypeOf(ZlibDecompressor{}), toZlibDecompressorUnsafe, ObjectType)
+)
+
+// zlibFormat is the container wrapping a deflate stream, as selected by the
+// wbits argument of the zlib functions.
+type zlibFormat int
+
+const (
+	zlibFormatZlib zlibFormat = iota
+	zlibFormatRaw
+	zlibFormatGzip
+	// zlibFormatAuto detects a zlib or gzip container when decompressing.
+	zlibFormatAuto
+)
+
+// zlibParseWBits converts wbits into the format it selects. Like zlib,
+// 8..15 selects a zlib container, -8..-15 a raw stream and 16 more than that
+// a gzip container. Decompressors also accept 32 more to detect zlib or gzip
+// automatically, and 0 for the window size in the zlib header. Go's flate
+// package always uses the largest window so the size itself is ignored.
+func zlibParseWBits(f *Frame, wbits int, decompress bool) (zlibFormat, *BaseException) {
+	switch {
+	case wbits >= 8 && wbits <= zlibMaxWBits, wbits == 0 && decompress:
+		return zlibFormatZlib, nil
+	case wbits >= -zlibMaxWBits && wbits <= -8:
+		return zlibFormatRaw, nil
+	case wbits >= 16+8 && wbits <= 16+zlibMaxWBits:
+		return zlibFormatGzip, nil
+	case wbits >= 32+8 && wbits <= 32+zlibMaxWBits && decompress:
+		return zlibFormatAuto, nil
+	}
+	return 0, f.RaiseType(ValueErrorType, "Invalid initialization option")
+}
+
+// zlibWriter is the interface shared by the compressing writers of the
+// flate, zlib and gzip packages.
+type zlibWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func newZlibWriter(f *Frame, w io.Writer, level int, format zlibFormat, errorType *Type) (zlibWriter, *BaseException) {
+	if level < flate.DefaultCompression || level > flate.BestCompression {
+		return nil, f.RaiseType(errorType, "Bad compression level")
+	}
+	var zw zlibWriter
+	var err error
+	switch format {
+	case zlibFormatZlib:
+		zw, err = zlib.NewWriterLevel(w, level)
+	case zlibFormatRaw:
+		zw, err = flate.NewWriter(w, level)
+	default:
+		zw, err = gzip.NewWriterLevel(w, level)
+	}
+	if err != nil {
+		return nil, f.RaiseType(errorType, err.Error())
+	}
+	return zw, nil
+}
+
+// zlibSource is the input of a decompressing reader.
+type zlibSource interface {
+	io.Reader
+	io.ByteScanner
+}
+
+// newZlibReader returns a reader decompressing the data read from r. The
+// readers of the flate, zlib and gzip packages don't read past the end of
+// the compressed stream when r is an io.ByteReader, so whatever remains in r
+// afterward is data following the stream.
+func newZlibReader(r zlibSource, format zlibFormat) (io.Reader, error) {
+	switch format {
+	case zlibFormatZlib:
+		return zlib.NewReader(r)
+	case zlibFormatRaw:
+		return flate.NewReader(r), nil
+	case zlibFormatGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		// Stop at the end of the first member so that subsequent
+		// members are reported as unused data, like zlib does.
+		gr.Multistream(false)
+		return gr, nil
+	}
+	// zlibFormatAuto: gzip streams start with the magic number 0x1f 0x8b
+	// while the first byte of a zlib header is never 0x1f.
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.UnreadByte(); err != nil {
+		return nil, err
+	}
+	if b == 0x1f {
+		return newZlibReader(r, zlibFormatGzip)
+	}
+	return newZlibReader(r, zlibFormatZlib)
+}
+
+// zlibDecompressError raises errorType describing the error err encountered
+// while decompressing, worded like zlib's own messages.
+func zlibDecompressError(f *Frame, err error, errorType *Type) *BaseException {
+	code, msg := -3, "invalid compressed data"
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF:
+		code, msg = -5, "incomplete or truncated stream"
+	case zlib.ErrHeader, gzip.ErrHeader:
+		msg = "incorrect header check"
+	case zlib.ErrChecksum, gzip.ErrChecksum:
+		msg = "incorrect data check"
+	case zlib.ErrDictionary:
+		code, msg = 2, "need dictionary"
+	}
+	return f.RaiseType(errorType, fmt.Sprintf("Error %d while decompressing data: %s", code, msg))
+}
+
+// ZlibCompress returns the bytes held by data compressed in the zlib format
+// at the given level. errorType is raised when level is out of range.
+func ZlibCompress(f *Frame, data *Object, level int, errorType *Type) (*Str, *BaseException) {
+	b, raised := bufferData(f, data, "compress() argument 1 must be string or read-only buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	var buf bytes.Buffer
+	w, raised := newZlibWriter(f, &buf, level, zlibFormatZlib, errorType)
+	if raised != nil {
+		return nil, raised
+	}
+	w.Write(b)
+	w.Close()
+	return NewStr(buf.String()), nil
+}
+
+// ZlibDecompress returns the bytes held by data decompressed according to
+// wbits. Data following the end of the compressed stream is ignored.
+// errorType is raised if data is not a complete compressed stream.
+func ZlibDecompress(f *Frame, data *Object, wbits int, errorType *Type) (*Str, *BaseException) {
+	b, raised := bufferData(f, data, "decompress() argument 1 must be string or read-only buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	format, raised := zlibParseWBits(f, wbits, true)
+	if raised != nil {
+		return nil, raised
+	}
+	r, err := newZlibReader(bytes.NewReader(b), format)
+	if err != nil {
+		return nil, zlibDecompressError(f, err, errorType)
+	}
+	limit := f.allocLimit()
+	if limit > 0 {
+		// Stop inflating as soon as the result is known to be too
+		// large.
+		r = io.LimitReader(r, int64(limit)+1)
+	}
+	result, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, zlibDecompressError(f, err, errorType)
+	}
+	if raised := f.checkAlloc(len(result)); raised != nil {
+		return nil, raised
+	}
+	return NewStr(string(result)), nil
+}
+
+// ZlibCRC32 returns the CRC-32 checksum of the bytes held by data, starting
+// from the checksum value. Like Python 2, the result is a signed integer.
+func ZlibCRC32(f *Frame, data, value *Object) (int, *BaseException) {
+	b, raised := bufferData(f, data, "crc32() argument 1 must be string or read-only buffer, not %s")
+	if raised != nil {
+		return 0, raised
+	}
+	start, raised := zlibChecksumValue(f, value)
+	if raised != nil {
+		return 0, raised
+	}
+	return int(int32(crc32.Update(start, crc32.IEEETable, b))), nil
+}
+
+// ZlibAdler32 returns the Adler-32 checksum of the bytes held by data,
+// starting from the checksum value. Like Python 2, the result is a signed
+// integer.
+func ZlibAdler32(f *Frame, data, value *Object) (int, *BaseException) {
+	b, raised := bufferData(f, data, "adler32() argument 1 must be string or read-only buffer, not %s")
+	if raised != nil {
+		return 0, raised
+	}
+	start, raised := zlibChecksumValue(f, value)
+	if raised != nil {
+		return 0, raised
+	}
+	if start == 1 {
+		return int(int32(adler32.Checksum(b))), nil
+	}
+	// The adler32 package can't resume from a checksum so continue
+	// the computation here.
+	const mod = 65521
+	s1, s2 := start&0xffff, start>>16
+	for _, c := range b {
+		s1 = (s1 + uint32(c)) % mod
+		s2 = (s2 + s1) % mod
+	}
+	return int(int32(s2<<16 | s1)), nil
+}
+
+// zlibChecksumValue converts the int or long o to the unsigned 32 bit value
+// it represents, as with a C cast.
+func zlibChecksumValue(f *Frame, o *Object) (uint32, *BaseException) {
+	switch {
+	case o.isInstance(IntType):
+		return uint32(toIntUnsafe(o).Value()), nil
+	case o.isInstance(LongType):
+		v := toLongUnsafe(o).Value()
+		return uint32(v.And(v, big.NewInt(0xffffffff)).Uint64()), nil
+	}
+	return 0, f.RaiseType(TypeErrorType, "an integer is required")
+}
+
+// ZlibCompressor represents Python 'zlib.Compress' objects, which compress a
+// stream of data incrementally.
+type ZlibCompressor struct {
+	Object
+	mutex     sync.Mutex
+	buf       bytes.Buffer
+	w         zlibWriter
+	finished  bool
+	errorType *Type
+}
+
+// NewZlibCompressor returns a new ZlibCompressor writing the container
+// selected by wbits at the given compression level. errorType is raised for
+// errors compressing data.
+func NewZlibCompressor(f *Frame, level, wbits int, errorType *Type) (*ZlibCompressor, *BaseException) {
+	format, raised := zlibParseWBits(f, wbits, false)
+	if raised != nil {
+		return nil, raised
+	}
+	c := &ZlibCompressor{Object: Object{typ: ZlibCompressorType}, errorType: errorType}
+	if c.w, raised = newZlibWriter(f, &c.buf, level, format, errorType); raised != nil {
+		return nil, raised
+	}
+	return c, nil
+}
+
+func toZlibCompressorUnsafe(o *Object) *ZlibCompressor {
+	return (*ZlibCompressor)(o.toPointer())
+}
+
+// ToObject upcasts c to an Object.
+func (c *ZlibCompressor) ToObject() *Object {
+	return &c.Object
+}
+
+// output returns and discards the compressed data produced so far. c.mutex
+// must be held.
+func (c *ZlibCompressor) output() *Object {
+	s := NewStr(c.buf.String())
+	c.buf.Reset()
+	return s.ToObject()
+}
+
+func zlibCompressorCompress(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "compress", args, ZlibCompressorType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	c := toZlibCompressorUnsafe(args[0])
+	data, raised := bufferData(f, args[1], "compress() argument 1 must be string or read-only buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.finished {
+		return nil, f.RaiseType(c.errorType, "Error -2 while compressing data: inconsistent stream state")
+	}
+	c.w.Write(data)
+	return c.output(), nil
+}
+
+func zlibCompressorFlush(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{ZlibCompressorType, IntType}
+	if len(args) == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "flush", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	mode := zlibFinish
+	if len(args) > 1 {
+		mode = toIntUnsafe(args[1]).Value()
+	}
+	c := toZlibCompressorUnsafe(args[0])
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.finished || mode == zlibNoFlush {
+		return NewStr("").ToObject(), nil
+	}
+	switch mode {
+	case zlibSyncFlush, zlibFullFlush:
+		c.w.Flush()
+	case zlibFinish:
+		c.w.Close()
+		c.finished = true
+	default:
+		return nil, f.RaiseType(c.errorType, "Error -2 while flushing: inconsistent stream state")
+	}
+	return c.output(), nil
+}
+
+func initZlibCompressorType(dict map[string]*Object) {
+	ZlibCompressorType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dict["compress"] = newBuiltinFunction("compress", zlibCompressorCompress).ToObject()
+	dict["flush"] = newBuiltinFunction("flush", zlibCompressorFlush).ToObject()
+}
+
+// zlibInflater decompresses a stream fed to it in chunks. Go's readers pull
+// their input, so decompression runs in a goroutine reading from a channel
+// of chunks. The goroutine signals on need when it has consumed all the
+// input it was given and has produced all the output it can from it. The
+// goroutine only touches out, err and rest between receiving a chunk and
+// signaling need or closing done, so the caller may access them at any other
+// time.
+//
+// If limit is positive, the goroutine stops with errZlibAllocLimit once it
+// has produced more than limit bytes of output that have not been taken.
+type zlibInflater struct {
+	limit int
+	input chan []byte
+	need  chan struct{}
+	done  chan struct{}
+	out   []byte
+	err   error
+	rest  []byte
+	// unread is the value of rest before the last call to ReadByte.
+	unread []byte
+	closed bool
+}
+
+func newZlibInflater(format zlibFormat, limit int) *zlibInflater {
+	z := &zlibInflater{limit: limit, input: make(chan []byte), need: make(chan struct{}, 1), done: make(chan struct{})}
+	go z.run(format)
+	// Wait for the goroutine to ask for its first chunk.
+	z.wait()
+	return z
+}
+
+func (z *zlibInflater) run(format zlibFormat) {
+	defer close(z.done)
+	r, err := newZlibReader(z, format)
+	if err != nil {
+		z.err = err
+		return
+	}
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(chunk)
+		z.out = append(z.out, chunk[:n]...)
+		if z.limit > 0 && len(z.out) > z.limit {
+			z.err = errZlibAllocLimit
+			return
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			z.err = err
+			return
+		}
+	}
+}
+
+// wait blocks until the goroutine needs more input or is done, returning
+// true in the latter case.
+func (z *zlibInflater) wait() bool {
+	select {
+	case <-z.need:
+		return false
+	case <-z.done:
+		return true
+	}
+}
+
+// feed passes data to the goroutine and returns true if the end of the
+// stream was reached.
+func (z *zlibInflater) feed(data []byte) bool {
+	z.input <- data
+	return z.wait()
+}
+
+// finish signals the end of the input to the goroutine and waits for it to
+// exit.
+func (z *zlibInflater) finish() {
+	close(z.input)
+	<-z.done
+}
+
+// fill waits for input when z.rest is empty, returning false if there
+// will be no more. It's called from the goroutine.
+func (z *zlibInflater) fill() bool {
+	for len(z.rest) == 0 {
+		if z.closed {
+			return false
+		}
+		z.need <- struct{}{}
+		chunk, ok := <-z.input
+		if !ok {
+			z.closed = true
+			return false
+		}
+		z.rest = chunk
+	}
+	return true
+}
+
+// Read implements io.Reader for the goroutine.
+func (z *zlibInflater) Read(p []byte) (int, error) {
+	if !z.fill() {
+		return 0, io.EOF
+	}
+	n := copy(p, z.rest)
+	z.rest = z.rest[n:]
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader for the goroutine, preventing the
+// decompressors from reading ahead of the data they need.
+func (z *zlibInflater) ReadByte() (byte, error) {
+	if !z.fill() {
+		return 0, io.EOF
+	}
+	b := z.rest[0]
+	z.unread = z.rest
+	z.rest = z.rest[1:]
+	return b, nil
+}
+
+// UnreadByte implements io.ByteScanner for the goroutine. It may only be
+// called after a successful ReadByte.
+func (z *zlibInflater) UnreadByte() error {
+	z.rest = z.unread
+	return nil
+}
+
+// ZlibDecompressor represents Python 'zlib.Decompress' objects, which
+// decompress a stream of data incrementally.
+type ZlibDecompressor struct {
+	Object
+	mutex      sync.Mutex
+	inflater   *zlibInflater
+	done       bool
+	unusedData []byte
+	errorType  *Type
+}
+
+// NewZlibDecompressor returns a new ZlibDecompressor reading the container
+// selected by wbits. errorType is raised when the input is not valid
+// compressed data.
+func NewZlibDecompressor(f *Frame, wbits int, errorType *Type) (*ZlibDecompressor, *BaseException) {
+	format, raised := zlibParseWBits(f, wbits, true)
+	if raised != nil {
+		return nil, raised
+	}
+	d := &ZlibDecompressor{Object: Object{typ: ZlibDecompressorType}, inflater: newZlibInflater(format, f.allocLimit()), errorType: errorType}
+	// Stop the goroutine if d is discarded before the end of the stream.
+	// The goroutine doesn't reference d so this doesn't keep it alive.
+	runtime.SetFinalizer(d, func(d *ZlibDecompressor) {
+		if !d.done {
+			d.inflater.finish()
+		}
+	})
+	return d, nil
+}
+
+func toZlibDecompressorUnsafe(o *Object) *ZlibDecompressor {
+	return (*ZlibDecompressor)(o.toPointer())
+}
+
+// ToObject upcasts d to an Object.
+func (d *ZlibDecompressor) ToObject() *Object {
+	return &d.Object
+}
+
+// output returns and discards the decompressed data produced so far,
+// raising if the stream ended in error. d.mutex must be held.
+func (d *ZlibDecompressor) output(f *Frame, done bool) (*Object, *BaseException) {
+	z := d.inflater
+	if z.err == errZlibAllocLimit {
+		// The goroutine has stopped so the stream can't be resumed.
+		d.done = true
+		n := len(z.out)
+		z.out = nil
+		return nil, raiseAllocLimit(f, n, z.limit)
+	}
+	out := NewStr(string(z.out))
+	z.out = nil
+	if done && !d.done {
+		d.done = true
+		d.unusedData = append(d.unusedData, z.rest...)
+		truncated := z.closed && (z.err == io.EOF || z.err == io.ErrUnexpectedEOF)
+		if z.err != nil && !truncated {
+			return nil, zlibDecompressError(f, z.err, d.errorType)
+		}
+	}
+	return out.ToObject(), nil
+}
+
+func zlibDecompressorDecompress(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "decompress", args, ZlibDecompressorType, ObjectType); raised != nil {
+		return nil, raised
+	}
+	d := toZlibDecompressorUnsafe(args[0])
+	data, raised := bufferData(f, args[1], "decompress() argument 1 must be string or read-only buffer, not %s")
+	if raised != nil {
+		return nil, raised
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.done {
+		d.unusedData = append(d.unusedData, data...)
+		return NewStr("").ToObject(), nil
+	}
+	if len(data) == 0 {
+		return NewStr("").ToObject(), nil
+	}
+	return d.output(f, d.inflater.feed(data))
+}
+
+func zlibDecompressorFlush(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	expectedTypes := []*Type{ZlibDecompressorType, IntType}
+	if len(args) == 1 {
+		expectedTypes = expectedTypes[:1]
+	}
+	if raised := checkMethodArgs(f, "flush", args, expectedTypes...); raised != nil {
+		return nil, raised
+	}
+	d := toZlibDecompressorUnsafe(args[0])
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.done {
+		return NewStr("").ToObject(), nil
+	}
+	// Ending the input makes the goroutine return everything it has
+	// decompressed. A truncated stream is not an error here.
+	d.inflater.finish()
+	return d.output(f, true)
+}
+
+func zlibDecompressorGetUnconsumedTail(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_unconsumed_tail", args, ZlibDecompressorType); raised != nil {
+		return nil, raised
+	}
+	// All input is consumed since decompress() doesn't limit the size of
+	// its output.
+	return NewStr("").ToObject(), nil
+}
+
+func zlibDecompressorGetUnusedData(f *Frame, args Args, _ KWArgs) (*Object, *BaseException) {
+	if raised := checkMethodArgs(f, "_get_unused_data", args, ZlibDecompressorType); raised != nil {
+		return nil, raised
+	}
+	d := toZlibDecompressorUnsafe(args[0])
+	d.mutex.Lock()
+	s := NewStr(string(d.unusedData))
+	d.mutex.Unlock()
+	return s.ToObject(), nil
+}
+
+func initZlibDecompressorType(dict map[string]*Object) {
+	ZlibDecompressorType.flags &^= typeFlagBasetype | typeFlagInstantiable
+	dict["decompress"] = newBuiltinFunction("decompress", zlibDecompressorDecompress).ToObject()
+	dict["flush"] = newBuiltinFunction("flush", zlibDecompressorFlush).ToObject()
+	dict["unconsumed_tail"] = newProperty(newBuiltinFunction("_get_unconsumed_tail", zlibDecompressorGetUnconsumedTail).ToObject(), nil, nil).ToObject()
+	dict["unused_data"] = newProperty(newBuiltinFunction("_get_unused_data", zlibDecompressorGetUnusedData).ToObject(), nil, nil).ToObject()
+}
@@ -0,0 +1,242 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grumpy
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const (
+	// The results of compressing "hello" with CPython's zlib module in
+	// the zlib, raw and gzip formats.
+	zlibHello = "x\x9c\xcbH\xcd\xc9\xc9\x07\x00\x06,\x02\x15"
+	rawHello  = "\xcbH\xcd\xc9\xc9\x07\x00"
+	gzipHello = "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xcbH\xcd\xc9\xc9\x07\x00\x86\xa6\x106\x05\x00\x00\x00"
+)
+
+func TestZlibCompress(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, data *Object, level int) (*Str, *BaseException) {
+		compressed, raised := ZlibCompress(f, data, level, RuntimeErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return ZlibDecompress(f, compressed.ToObject(), zlibMaxWBits, RuntimeErrorType)
+	})
+	long := strings.Repeat("abc", 10000)
+	cases := []invokeTestCase{
+		{args: wrapArgs("", -1), want: NewStr("").ToObject()},
+		{args: wrapArgs("hello", 0), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(long, 9), want: NewStr(long).ToObject()},
+		{args: wrapArgs(NewUnicode("abc"), 1), want: NewStr("abc").ToObject()},
+		{args: wrapArgs(newTestByteArray("abc"), 1), want: NewStr("abc").ToObject()},
+		{args: wrapArgs("hello", 10), wantExc: mustCreateException(RuntimeErrorType, "Bad compression level")},
+		{args: wrapArgs(123, -1), wantExc: mustCreateException(TypeErrorType, "compress() argument 1 must be string or read-only buffer, not int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestZlibDecompress(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, data *Object, wbits int) (*Str, *BaseException) {
+		return ZlibDecompress(f, data, wbits, RuntimeErrorType)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(zlibHello, 15), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(zlibHello+"trailing", 15), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(zlibHello, 0), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(rawHello, -15), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(gzipHello, 31), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(gzipHello, 47), want: NewStr("hello").ToObject()},
+		{args: wrapArgs(zlibHello, 47), want: NewStr("hello").ToObject()},
+		{args: wrapArgs("", 15), wantExc: mustCreateException(RuntimeErrorType, "Error -5 while decompressing data: incomplete or truncated stream")},
+		{args: wrapArgs(zlibHello[:6], 15), wantExc: mustCreateException(RuntimeErrorType, "Error -5 while decompressing data: incomplete or truncated stream")},
+		{args: wrapArgs("hello", 15), wantExc: mustCreateException(RuntimeErrorType, "Error -3 while decompressing data: incorrect header check")},
+		{args: wrapArgs(zlibHello[:9]+"\x00\x00\x00\x00", 15), wantExc: mustCreateException(RuntimeErrorType, "Error -3 while decompressing data: incorrect data check")},
+		{args: wrapArgs(zlibHello, 31), wantExc: mustCreateException(RuntimeErrorType, "Error -3 while decompressing data: incorrect header check")},
+		{args: wrapArgs("\xff\xff\xff\xff", -15), wantExc: mustCreateException(RuntimeErrorType, "Error -3 while decompressing data: invalid compressed data")},
+		{args: wrapArgs(zlibHello, 7), wantExc: mustCreateException(ValueErrorType, "Invalid initialization option")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestZlibChecksums(t *testing.T) {
+	crc32 := wrapFuncForTest(func(f *Frame, data, value *Object) (int, *BaseException) {
+		return ZlibCRC32(f, data, value)
+	})
+	adler32 := wrapFuncForTest(func(f *Frame, data, value *Object) (int, *BaseException) {
+		return ZlibAdler32(f, data, value)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs("hello", 0), want: NewInt(907060870).ToObject()},
+		{args: wrapArgs("a", 0), want: NewInt(-390611389).ToObject()},
+		{args: wrapArgs("b", -390611389), want: NewInt(-1635563411).ToObject()},
+		{args: wrapArgs("b", NewLong(big.NewInt(3904355907))), want: NewInt(-1635563411).ToObject()},
+		{args: wrapArgs("", 0), want: NewInt(0).ToObject()},
+		{args: wrapArgs("a", "b"), wantExc: mustCreateException(TypeErrorType, "an integer is required")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(crc32, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+	cases = []invokeTestCase{
+		{args: wrapArgs("hello", 1), want: NewInt(103547413).ToObject()},
+		{args: wrapArgs("hello world", 1), want: NewInt(436929629).ToObject()},
+		{args: wrapArgs(" world", 103547413), want: NewInt(436929629).ToObject()},
+		{args: wrapArgs(123, 1), wantExc: mustCreateException(TypeErrorType, "adler32() argument 1 must be string or read-only buffer, not int")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(adler32, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestZlibCompressor(t *testing.T) {
+	fun := wrapFuncForTest(func(f *Frame, wbits int, chunks *Tuple, mode int) (*Object, *BaseException) {
+		c, raised := NewZlibCompressor(f, -1, wbits, RuntimeErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		compress, raised := GetAttr(f, c.ToObject(), NewStr("compress"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		flush, raised := GetAttr(f, c.ToObject(), NewStr("flush"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		compressed := ""
+		for _, chunk := range chunks.elems {
+			s, raised := compress.Call(f, Args{chunk}, nil)
+			if raised != nil {
+				return nil, raised
+			}
+			compressed += toStrUnsafe(s).Value()
+		}
+		s, raised := flush.Call(f, Args{NewInt(mode).ToObject()}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		compressed += toStrUnsafe(s).Value()
+		if mode == zlibFinish {
+			// Flushing a finished stream is a no-op.
+			if s, raised = flush.Call(f, nil, nil); raised != nil {
+				return nil, raised
+			}
+			compressed += toStrUnsafe(s).Value()
+		}
+		d, raised := NewZlibDecompressor(f, wbits, RuntimeErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		return zlibDecompressorDecompress(f, Args{d.ToObject(), NewStr(compressed).ToObject()}, nil)
+	})
+	cases := []invokeTestCase{
+		{args: wrapArgs(15, newTestTuple("foo", "bar"), zlibFinish), want: NewStr("foobar").ToObject()},
+		{args: wrapArgs(-15, newTestTuple("foo", "bar"), zlibFinish), want: NewStr("foobar").ToObject()},
+		{args: wrapArgs(31, newTestTuple("foo", "bar"), zlibFinish), want: NewStr("foobar").ToObject()},
+		{args: wrapArgs(15, newTestTuple("foo", "bar"), zlibSyncFlush), want: NewStr("foobar").ToObject()},
+		{args: wrapArgs(15, newTestTuple("foo", "bar"), 1), wantExc: mustCreateException(RuntimeErrorType, "Error -2 while flushing: inconsistent stream state")},
+		{args: wrapArgs(47, NewTuple(), zlibFinish), wantExc: mustCreateException(ValueErrorType, "Invalid initialization option")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
+
+func TestZlibCompressorCompressFinished(t *testing.T) {
+	f := NewRootFrame()
+	c, raised := NewZlibCompressor(f, -1, zlibMaxWBits, RuntimeErrorType)
+	if raised != nil {
+		t.Fatal(raised)
+	}
+	mustNotRaise(zlibCompressorFlush(f, Args{c.ToObject()}, nil))
+	cas := invokeTestCase{args: wrapArgs(c, "foo"), wantExc: mustCreateException(RuntimeErrorType, "Error -2 while compressing data: inconsistent stream state")}
+	if err := runInvokeMethodTestCase(ZlibCompressorType, "compress", &cas); err != "" {
+		t.Error(err)
+	}
+}
+
+func TestZlibDecompressor(t *testing.T) {
+	// fun feeds the chunks to a new decompressor one by one and returns
+	// the output of each call followed by the output of flush() and
+	// unused_data.
+	fun := wrapFuncForTest(func(f *Frame, wbits int, chunks *Tuple) (*Object, *BaseException) {
+		d, raised := NewZlibDecompressor(f, wbits, RuntimeErrorType)
+		if raised != nil {
+			return nil, raised
+		}
+		var results []*Object
+		for _, chunk := range chunks.elems {
+			s, raised := zlibDecompressorDecompress(f, Args{d.ToObject(), chunk}, nil)
+			if raised != nil {
+				return nil, raised
+			}
+			results = append(results, s)
+		}
+		s, raised := zlibDecompressorFlush(f, Args{d.ToObject()}, nil)
+		if raised != nil {
+			return nil, raised
+		}
+		results = append(results, s)
+		unusedData, raised := GetAttr(f, d.ToObject(), NewStr("unused_data"), nil)
+		if raised != nil {
+			return nil, raised
+		}
+		return NewTuple(append(results, unusedData)...).ToObject(), nil
+	})
+	var byteChunks []*Object
+	for i := 0; i < len(zlibHello); i++ {
+		byteChunks = append(byteChunks, NewStr(zlibHello[i:i+1]).ToObject())
+	}
+	byByte := NewTuple(byteChunks...)
+	wantByByte := make([]*Object, len(zlibHello)+2)
+	for i := range wantByByte {
+		wantByByte[i] = NewStr("").ToObject()
+	}
+	// The final byte of the checksum completes the stream.
+	wantByByte[len(zlibHello)-1] = NewStr("hello").ToObject()
+	cases := []invokeTestCase{
+		{args: wrapArgs(15, newTestTuple(zlibHello)), want: newTestTuple("hello", "", "").ToObject()},
+		{args: wrapArgs(15, byByte), want: NewTuple(wantByByte...).ToObject()},
+		{args: wrapArgs(15, newTestTuple(zlibHello+"foo", "bar")), want: newTestTuple("hello", "", "", "foobar").ToObject()},
+		{args: wrapArgs(31, newTestTuple(gzipHello+gzipHello)), want: newTestTuple("hello", "", gzipHello).ToObject()},
+		{args: wrapArgs(47, newTestTuple(gzipHello[:5], gzipHello[5:])), want: newTestTuple("", "hello", "", "").ToObject()},
+		{args: wrapArgs(-15, newTestTuple(rawHello, "")), want: newTestTuple("hello", "", "", "").ToObject()},
+		// A truncated stream returns what it can when flushed.
+		{args: wrapArgs(15, newTestTuple(zlibHello[:9])), want: newTestTuple("", "hello", "").ToObject()},
+		{args: wrapArgs(15, NewTuple()), want: newTestTuple("", "").ToObject()},
+		{args: wrapArgs(15, newTestTuple("x\x9c", "\xff\xff")), wantExc: mustCreateException(RuntimeErrorType, "Error -3 while decompressing data: invalid compressed data")},
+		{args: wrapArgs(15, newTestTuple(123)), wantExc: mustCreateException(TypeErrorType, "decompress() argument 1 must be string or read-only buffer, not int")},
+		{args: wrapArgs(-7, NewTuple()), wantExc: mustCreateException(ValueErrorType, "Invalid initialization option")},
+	}
+	for _, cas := range cases {
+		if err := runInvokeTestCase(fun, &cas); err != "" {
+			t.Error(err)
+		}
+	}
+}
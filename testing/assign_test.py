@@ -38,7 +38,7 @@ assert bar == [7, 8, 9]
 try:
   bar, baz = foo
 except ValueError as e:
-  assert str(e) == 'too many values to unpack'
+  assert str(e) == 'too many values to unpack (expected 2)'
 else:
   raise AssertionError('this was supposed to raise an exception')
 
@@ -49,6 +49,16 @@ except ValueError as e:
 else:
   raise AssertionError('this was supposed to raise an exception')
 
+try:
+  bar, baz = (i for i in [1])
+except ValueError as e:
+  assert str(e) == 'need more than 1 value to unpack'
+else:
+  raise AssertionError('this was supposed to raise an exception')
+
+bar, baz = {'a': 1, 'b': 2}
+assert sorted([bar, baz]) == ['a', 'b']
+
 foo = Foo()
 
 foo.bar = 1
@@ -320,7 +320,7 @@ assert list(zip('abcdef')) == zip('abcdef')
 assert list(zip()) == zip()
 assert [tuple(list(pair)) for pair in zip('abc', 'def')] == zip('abc', 'def')
 assert [pair for pair in zip('abc', 'def')] == zip('abc', 'def')
-assert zip({'b': 1, 'a': 2}) == [('a',), ('b',)]
+assert zip({'b': 1, 'a': 2}) == [('b',), ('a',)]
 assert zip(range(5)) == [(0,), (1,), (2,), (3,), (4,)]
 assert zip(xrange(5)) == [(0,), (1,), (2,), (3,), (4,)]
 assert zip([1, 2, 3], [1], [4, 5, 6]) == [(1, 1, 4)]
@@ -380,6 +380,26 @@ assert divmod(long(-sys.maxsize), long(-1)) == (sys.maxsize, 0L)
 assert isinstance(divmod(long(7), long(3)), tuple)
 assert isinstance(divmod(long(7), long(3))[0], long)
 assert isinstance(divmod(long(7), long(3))[1], long)
+assert divmod(long(-7), 2) == (-4L, 1L)
+assert divmod(7, long(-2)) == (-4L, -1L)
+assert divmod(long(0), 3) == (0L, 0L)
+assert divmod(2 ** 100, -3) == (-(2 ** 100 // 3) - 1, -2L)
+assert divmod(long(7), 2.5) == (2.0, 2.0)
+
+try:
+  divmod(long(1), 0)
+except ZeroDivisionError as e:
+  assert str(e) == 'long division or modulo by zero'
+else:
+  raise AssertionError
+
+assert (0).bit_length() == 0
+assert (-1).bit_length() == 1
+assert (255).bit_length() == 8
+assert (-sys.maxsize - 1).bit_length() == sys.maxsize.bit_length() + 1
+assert long(0).bit_length() == 0
+assert (2 ** 100).bit_length() == 101
+assert (-2 ** 100).bit_length() == 101
 
 assert divmod(3.25, 1.0) == (3.0, 0.25)
 assert divmod(-3.25, 1.0) == (-4.0, 0.75)
@@ -388,6 +408,26 @@ assert divmod(-3.25, -1.0) == (3.0, -0.25)
 assert isinstance(divmod(3.25, 1.0), tuple)
 assert isinstance(divmod(3.25, 1.0)[0], float)
 assert isinstance(divmod(3.25, 1.0)[1], float)
+assert repr(divmod(-0.0, 5)) == '(-0.0, 0.0)'
+assert repr(divmod(0.0, -5)) == '(-0.0, -0.0)'
+assert repr(-0.0 % 5) == '0.0'
+assert repr(0.0 % -5) == '-0.0'
+assert repr(5.0 % -5) == '-0.0'
+assert repr(-5.0 % 5) == '0.0'
+
+try:
+  divmod(1, 0.0)
+except ZeroDivisionError as e:
+  assert str(e) == 'float divmod()'
+else:
+  raise AssertionError
+
+try:
+  1 % 0.0
+except ZeroDivisionError as e:
+  assert str(e) == 'float modulo'
+else:
+  raise AssertionError
 
 try:
   divmod('a', 'b')
@@ -396,6 +436,33 @@ except TypeError as e:
 else:
   assert AssertionError
 
+# dir([object])
+
+class Dir(object):
+  def __dir__(self):
+    return ['b', 'a']
+
+assert dir(Dir()) == ['a', 'b']
+assert 'b' not in dir(Dir)
+
+class BadDir(object):
+  def __dir__(self):
+    return ('a',)
+
+try:
+  dir(BadDir())
+except TypeError as e:
+  assert str(e) == '__dir__() must return a list, not tuple'
+else:
+  raise AssertionError
+
+def dir_locals(a):
+  b = 1
+  return dir()
+
+assert dir_locals(0) == ['a', 'b']
+assert 'dir_locals' in dir()
+
 # Check for a bug where zip() and map() were not properly cleaning their
 # internal exception state. See:
 # https://github.com/google/grumpy/issues/305
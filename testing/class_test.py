@@ -12,6 +12,10 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import time
+
+from '__go__/runtime' import GC
+
 
 class Foo(object):
 
@@ -48,3 +52,314 @@ except AttributeError:
   pass
 else:
   raise AssertionError
+
+
+class Slotted(object):
+
+  __slots__ = ('x', 'y')
+
+  def __init__(self, x):
+    self.x = x
+
+
+s = Slotted(1)
+assert s.x == 1
+assert not hasattr(s, 'y')
+s.y = 2
+assert s.y == 2
+del s.y
+assert not hasattr(s, 'y')
+try:
+  s.z = 3
+except AttributeError:
+  pass
+else:
+  raise AssertionError
+assert not hasattr(s, '__dict__')
+assert repr(Slotted.x) == "<member 'x' of 'Slotted' objects>"
+assert type(Slotted.x).__name__ == 'member_descriptor'
+assert Slotted.x.__get__(s) == 1
+
+
+class SlottedSub(Slotted):
+
+  __slots__ = 'z'
+
+
+s = SlottedSub(4)
+s.z = 5
+assert (s.x, s.z) == (4, 5)
+assert not hasattr(s, '__dict__')
+
+
+class Unslotted(Slotted):
+  pass
+
+
+u = Unslotted(6)
+u.w = 7
+assert (u.x, u.w, u.__dict__) == (6, 7, {'w': 7})
+
+
+class SlottedDict(object):
+
+  __slots__ = ('a', '__dict__')
+
+
+d = SlottedDict()
+d.a = 8
+d.b = 9
+assert d.__dict__ == {'b': 9}
+
+try:
+  type('Conflict', (Slotted, SlottedDict), {})
+except TypeError:
+  pass
+else:
+  raise AssertionError
+
+
+class Prop(object):
+
+  def __init__(self):
+    self._x = 0
+
+  @property
+  def x(self):
+    """The x value."""
+    return self._x
+
+  @x.setter
+  def x(self, value):
+    self._x = value
+
+  @x.deleter
+  def x(self):
+    del self._x
+
+  @classmethod
+  def make(cls):
+    return cls()
+
+  @staticmethod
+  def add(a, b):
+    return a + b
+
+
+class PropSub(Prop):
+  pass
+
+
+p = PropSub()
+p.x = 3
+assert p.x == 3
+del p.x
+assert not hasattr(p, '_x')
+assert isinstance(Prop.x, property)
+assert Prop.x.fget is not None and Prop.x.fdel is not None
+assert type(PropSub.make()) is PropSub
+assert type(p.make()) is PropSub
+assert PropSub.add(1, 2) == p.add(1, 2) == 3
+
+
+class DataDesc(object):
+
+  def __get__(self, inst, owner):
+    if inst is None:
+      return self
+    return inst.__dict__.get('_data', 'default')
+
+  def __set__(self, inst, value):
+    inst.__dict__['_data'] = value
+
+  def __delete__(self, inst):
+    del inst.__dict__['_data']
+
+
+class NonDataDesc(object):
+
+  def __get__(self, inst, owner):
+    return (inst, owner)
+
+
+class ReadOnlyDesc(object):
+
+  def __get__(self, inst, owner):
+    return 'ro'
+
+  def __set__(self, inst, value):
+    raise AttributeError('read-only')
+
+
+class UndeletableDesc(ReadOnlyDesc):
+
+  def __set__(self, inst, value):
+    pass
+
+
+class Lazy(object):
+
+  def __init__(self, func):
+    self.func = func
+
+  def __get__(self, inst, owner):
+    if inst is None:
+      return self
+    value = self.func(inst)
+    setattr(inst, self.func.__name__, value)
+    return value
+
+
+class Described(object):
+
+  data = DataDesc()
+  nondata = NonDataDesc()
+  ro = ReadOnlyDesc()
+  undeletable = UndeletableDesc()
+
+  @Lazy
+  def lazy(self):
+    self.computed += 1
+    return 42
+
+  computed = 0
+
+
+d = Described()
+# Data descriptors take precedence over the instance dict.
+assert d.data == 'default'
+d.data = 5
+assert d.data == 5 and d.__dict__['_data'] == 5
+d.__dict__['data'] = 'shadow'
+assert d.data == 5
+del d.data
+assert d.data == 'default'
+assert isinstance(Described.data, DataDesc)
+# Non-data descriptors are shadowed by the instance dict.
+assert d.nondata == (d, Described)
+assert Described.nondata == (None, Described)
+d.nondata = 'inst'
+assert d.nondata == 'inst'
+assert (lambda self: self).__get__(d)() is d
+try:
+  d.ro = 1
+except AttributeError:
+  pass
+else:
+  raise AssertionError
+try:
+  del d.undeletable
+except AttributeError:
+  pass
+else:
+  raise AssertionError
+# Descriptors are inherited and passed the instance's class as owner.
+assert type('DescribedSub', (Described,), {})().nondata[1].__name__ == 'DescribedSub'
+assert d.lazy == 42 and d.lazy == 42 and d.computed == 1
+assert isinstance(Described.lazy, Lazy)
+
+
+class MetaDesc(type):
+
+  meta = NonDataDesc()
+
+
+class WithMetaDesc(object):
+
+  __metaclass__ = MetaDesc
+
+
+assert WithMetaDesc.meta == (WithMetaDesc, MetaDesc)
+
+
+class Meta(type):
+
+  created = []
+
+  def __new__(mcs, name, bases, dict):
+    dict['meta_added'] = name.lower()
+    return super(Meta, mcs).__new__(mcs, name, bases, dict)
+
+  def __init__(cls, name, bases, dict):
+    super(Meta, cls).__init__(name, bases, dict)
+    Meta.created.append(name)
+
+  def __call__(cls, *args):
+    inst = super(Meta, cls).__call__(*args)
+    inst.via_meta = True
+    return inst
+
+  def describe(cls):
+    return 'class ' + cls.__name__
+
+
+class WithMeta(object):
+
+  __metaclass__ = Meta
+
+  def __init__(self, x=None):
+    self.x = x
+
+
+class WithMetaSub(WithMeta):
+  pass
+
+
+assert type(WithMeta) is Meta and type(WithMetaSub) is Meta
+assert Meta.created == ['WithMeta', 'WithMetaSub']
+assert WithMeta.meta_added == 'withmeta'
+assert WithMetaSub.meta_added == 'withmetasub'
+assert WithMetaSub.describe() == 'class WithMetaSub'
+assert not hasattr(WithMeta(), 'describe')
+w = WithMetaSub(3)
+assert w.x == 3 and w.via_meta
+Dynamic = Meta('Dynamic', (object,), {'y': 1})
+assert type(Dynamic) is Meta and Dynamic.y == 1 and Dynamic.meta_added == 'dynamic'
+assert type(type('DynamicSub', (Dynamic,), {})) is Meta
+assert Meta.created[-2:] == ['Dynamic', 'DynamicSub']
+
+
+def func_metaclass(name, bases, dict):
+  return name, bases
+
+
+class FuncMeta(object):
+  __metaclass__ = func_metaclass
+
+
+assert FuncMeta == ('FuncMeta', (object,))
+
+
+class OtherMeta(type):
+  pass
+
+
+try:
+  OtherMeta('Conflict', (WithMeta,), {})
+except TypeError:
+  pass
+else:
+  raise AssertionError
+
+
+class Finalized(object):
+
+  deleted = []
+
+  def __init__(self, name):
+    self.name = name
+
+  def __del__(self):
+    Finalized.deleted.append(self.name)
+
+
+Finalized('foo')
+Finalized('bar')
+# __del__ is called some time after the garbage collector finds an object
+# unreachable.
+for _ in xrange(100):
+  if len(Finalized.deleted) == 2:
+    break
+  GC()
+  time.sleep(0.01)
+assert sorted(Finalized.deleted) == ['bar', 'foo']
@@ -105,3 +105,61 @@ assert a.cmp_called
 a, b = Cmp(5), Cmp(4)
 assert a >= b
 assert a.cmp_called
+
+# Test that chained comparisons evaluate each operand at most once and stop at
+# the first false comparison.
+
+evaluated = []
+
+
+def Operand(x):
+  evaluated.append(x)
+  return x
+
+assert Operand(1) < Operand(2) < Operand(3)
+assert evaluated == [1, 2, 3]
+
+evaluated = []
+assert not Operand(1) < Operand(0) < Operand(3)
+assert evaluated == [1, 0]
+
+evaluated = []
+assert not Operand(1) < Operand(2) > Operand(3) < Operand(4)
+assert evaluated == [1, 2, 3]
+
+evaluated = []
+assert Operand(1) < Operand(2) in Operand([2]) is not Operand(None)
+assert evaluated == [1, 2, [2], None]
+
+# The result of a chain is the result of the last comparison evaluated, not a
+# bool.
+
+
+
+class AndCmp(object):
+
+  def __init__(self, x):
+    self.x = x
+
+  def __lt__(self, other):
+    return self.x and other.x
+
+assert (AndCmp(1) < AndCmp(2) < AndCmp(3)) == 3
+assert (AndCmp(1) < AndCmp(0) < AndCmp(3)) == 0
+
+# Exceptions raised by a comparison in the middle of a chain propagate without
+# evaluating the remaining operands.
+
+
+class RaisingCmp(object):
+
+  def __lt__(self, other):
+    raise ValueError('bad comparison')
+
+evaluated = []
+try:
+  Operand(RaisingCmp()) < Operand(1) < Operand(3)  # pylint: disable=expression-not-assigned
+  raise AssertionError('comparison did not raise')
+except ValueError as e:
+  assert str(e) == 'bad comparison'
+assert len(evaluated) == 2
@@ -21,3 +21,5 @@ assert [c for c in 'abc'] == ['a', 'b', 'c']
 assert [i + j for i in range(2) for j in range(2)] == [0, 1, 1, 2]
 assert [c for c in 'foobar' if c in 'aeiou'] == ['o', 'o', 'a']
 assert {i: str(i) for i in range(2)} == {0: '0', 1: '1'}
+assert [[j for j in range(i)] for i in range(3)] == [[], [0], [0, 1]]
+assert [f() for f in [lambda: i for i in range(2)]] == [1, 1]
@@ -31,7 +31,7 @@ assert d['foo'] == 5
 l = []
 for k in d:
   l.append(k)
-assert l == ['baz', 'foo', 'bar', 'qux']
+assert l == ['foo', 'bar', 'baz', 'qux']
 
 try:
   for k in d:
@@ -61,3 +61,59 @@ try:
   assert AssertionError
 except TypeError:
   pass
+
+# Test iteration order
+d = {'foo': 1, 'bar': 2}
+d['baz'] = 3
+d['foo'] = 4
+del d['bar']
+d['bar'] = 5
+assert d.keys() == ['foo', 'baz', 'bar']
+assert d.values() == [4, 3, 5]
+assert d.popitem() == ('bar', 5)
+
+# Test views
+d = {'foo': 1, 'bar': 2}
+keys = d.viewkeys()
+values = d.viewvalues()
+items = d.viewitems()
+d['baz'] = 3
+assert len(keys) == 3
+assert list(keys) == ['foo', 'bar', 'baz']
+assert list(values) == [1, 2, 3]
+assert list(items) == [('foo', 1), ('bar', 2), ('baz', 3)]
+assert 'baz' in keys
+assert ('bar', 2) in items
+assert ('bar', 3) not in items
+assert keys == {'foo', 'bar', 'baz'}
+assert keys & ['foo', 'qux'] == {'foo'}
+assert keys - {'foo'} == {'bar', 'baz'}
+assert {'qux'} | keys == {'foo', 'bar', 'baz', 'qux'}
+assert repr({'foo': 1}.viewitems()) == "dict_items([('foo', 1)])"
+
+# Test fromkeys
+assert dict.fromkeys('ab') == {'a': None, 'b': None}
+assert {}.fromkeys([1, 2], 0) == {1: 0, 2: 0}
+
+
+class Foo(dict):
+  pass
+
+
+assert type(Foo.fromkeys([1])) is Foo
+
+
+# Test update with a mapping that isn't a dict
+class Mapping(object):
+
+  def keys(self):
+    return ['foo']
+
+  def __getitem__(self, key):
+    return key * 2
+
+
+d = {'bar': 1}
+d.update(Mapping(), baz=2)
+assert d == {'bar': 1, 'foo': 'foofoo', 'baz': 2}
+assert dict(Mapping()) == {'foo': 'foofoo'}
@@ -20,3 +20,37 @@ assert 1E6 == 1e6
 assert -1E6 == -1e6
 assert 1E+6 == 1e6
 assert 1E-6 == 0.000001
+
+# Test repr and str.
+assert repr(0.1) == '0.1'
+assert repr(0.1 + 0.2) == '0.30000000000000004'
+assert repr(1e16) == '1e+16'
+assert repr(1e15) == '1000000000000000.0'
+assert repr(-5.0) == '-5.0'
+assert repr(1.2345678901234567) == '1.2345678901234567'
+assert repr(9007199254740993.0) == '9007199254740992.0'
+assert 9007199254740993.0 == 2.0 ** 53
+assert str(0.1 + 0.2) == '0.3'
+assert str(1e11) == '1e+11'
+assert str(1e10) == '10000000000.0'
+assert float(repr(2.0 / 3)) == 2.0 / 3
+
+# Test __format__.
+assert (2.0 / 3).__format__('') == str(2.0 / 3)
+assert (1234.5).__format__('.2f') == '1234.50'
+assert (1234.5).__format__('e') == '1.234500e+03'
+assert (1234.5).__format__('.3g') == '1.23e+03'
+assert (1234.5).__format__('.3') == '1.23e+03'
+assert (0.25).__format__('%') == '25.000000%'
+assert (0.25).__format__('.1%') == '25.0%'
+assert (1234.5).__format__('*^+12,.1f') == '**+1,234.5**'
+assert (-1234.5).__format__('012,.1f') == '-0,001,234.5'
+assert (1.5).__format__(u'<6') == '1.5   '
+
+for spec in ('#g', 'x', '.f', ',n'):
+  try:
+    (1.0).__format__(spec)
+  except ValueError:
+    pass
+  else:
+    raise AssertionError('%r should raise ValueError' % spec)
@@ -86,3 +86,28 @@ assert (foo(**{'a': 'apple', 'b': 'bear'}) ==
         {'a': 'apple', 'kwargs': {'b': 'bear'}})
 assert (foo('bar', b='baz', c='qux') ==
         {'a': 'bar', 'kwargs': {'b': 'baz', 'c': 'qux'}})
+
+
+def foo(a, b=1, *args, **kwargs):
+  """Docstring."""
+  return a, b
+
+
+assert foo.func_name == foo.__name__ == 'foo'
+assert foo.func_doc == foo.__doc__ == 'Docstring.'
+assert foo.__module__ == __name__
+assert foo.func_globals is foo.__globals__ is globals()
+assert foo.func_code is foo.__code__
+assert foo.func_code.co_argcount == 2
+assert foo.func_code.co_varnames == ('a', 'b', 'args', 'kwargs')
+assert foo.func_closure is None
+assert foo.func_defaults == (1,)
+foo.func_defaults = (2, 3)
+assert foo() == (2, 3)
+foo.__defaults__ = None
+try:
+  foo()
+  raise AssertionError
+except TypeError:
+  pass
+assert (lambda: None).__doc__ is None
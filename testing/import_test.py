@@ -12,6 +12,15 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import os
+import os.path
 import sys
 
 print sys.maxint
+
+assert os.__doc__ == 'Miscellaneous operating system interfaces.'
+assert os.__file__.endswith('os/__init__.py')
+assert os.__package__ == 'os'
+assert os.path.__package__ == 'os'
+assert sys.__package__ == ''
+assert __package__ is None
@@ -110,3 +110,77 @@ try:
   assert AssertionError
 except TypeError:
   pass
+
+# Test sort
+a = [3, 1, 2]
+a.sort()
+assert a == [1, 2, 3]
+a.sort(reverse=True)
+assert a == [3, 2, 1]
+a.sort(lambda x, y: x - y)
+assert a == [1, 2, 3]
+a = ['b', 'A', 'c', 'a']
+a.sort(key=str.lower)
+assert a == ['A', 'a', 'b', 'c']
+assert sorted(a, cmp=lambda x, y: cmp(y, x)) == ['c', 'b', 'a', 'A']
+
+a = [3, 1, 2]
+try:
+  a.sort(lambda x, y: a.append(0) or 0)
+except ValueError:
+  pass
+else:
+  raise AssertionError
+
+# Test insert
+a = ['a', 'c']
+a.insert(1, 'b')
+a.insert(-1, 'x')
+a.insert(100, 'd')
+a.insert(-100, 'z')
+assert a == ['z', 'a', 'b', 'x', 'c', 'd']
+
+# Test remove, index and reverse
+a = [1, 2, 3, 2]
+a.remove(2)
+assert a == [1, 3, 2]
+try:
+  a.remove(4)
+except ValueError:
+  pass
+else:
+  raise AssertionError
+assert a.index(2) == 2
+assert a.index(3, -2) == 1
+try:
+  a.index(1, 1, 3)
+except ValueError:
+  pass
+else:
+  raise AssertionError
+a.reverse()
+assert a == [2, 3, 1]
+
+# Test del of a slice
+a = [0, 1, 2, 3, 4]
+del a[1:3]
+assert a == [0, 3, 4]
+del a[-1:]
+assert a == [0, 3]
+
+# Test extended slices
+a = range(10)
+del a[::-3]
+assert a == [1, 2, 4, 5, 7, 8]
+a = range(5)
+a[::-2] = 'abc'
+assert a == ['c', 1, 'b', 3, 'a']
+try:
+  a[::2] = []
+except ValueError:
+  pass
+else:
+  raise AssertionError
+a = [1, 2, 3]
+a[1:2] = a
+assert a == [1, 1, 2, 3, 3]
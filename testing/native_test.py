@@ -15,10 +15,14 @@
 # pylint: disable=g-multiple-import
 
 from '__go__/math' import MaxInt32, Pow10, Signbit
-from '__go__/strings' import Count, IndexAny, Repeat
+from '__go__/strings' import Count, FieldsFunc, IndexAny, Join, Map, Repeat
 from '__go__/encoding/csv' import NewReader as NewCSVReader
+from '__go__/grumpy' import GoErrorType
+from '__go__/os' import Open
 from '__go__/image' import Pt
+from '__go__/mime' import FormatMediaType, ParseMediaType
 from '__go__/strings' import NewReader as NewStringReader
+from '__go__/time' import After, Tick
 
 assert Count('foo,bar,baz', ',') == 2
 assert IndexAny('foobar', 'obr') == 1
@@ -33,3 +37,63 @@ assert Pt(1, 0).X == 1
 # Can access field on pointer to struct (NewCSVReader returns a pointer to a
 # csv.Reader struct)
 assert NewCSVReader(NewStringReader("foo")).LazyQuotes == False
+
+# Can receive from channels (After returns a <-chan time.Time).
+_, ok = After(0).recv()
+assert ok
+
+# Can iterate over channels.
+for i, _ in zip(range(3), Tick(1)):
+  pass
+assert i == 2
+
+# Panics in native code are raised as RuntimeError.
+try:
+  Repeat('foo', -1)
+  raise AssertionError
+except RuntimeError as e:
+  assert 'negative Repeat count' in str(e), str(e)
+
+# Go errors can be raised as GoError, which is an IOError.
+_, err = Open('/nonexistent/file')
+try:
+  raise GoErrorType(err)
+except IOError as e:
+  assert e.go_error is err
+  assert str(e) == err.Error(), str(e)
+
+# Errors from the runtime's own Go calls are raised as GoError.
+try:
+  open('/nonexistent/file')
+  raise AssertionError
+except GoErrorType as e:
+  assert 'no such file or directory' in str(e), str(e)
+  assert e.go_error is not None
+
+# Python callables can be passed where Go functions are expected.
+assert Map(lambda r: r + 1, 'HAL') == 'IBM'
+assert list(FieldsFunc('a,b;c', lambda r: r in (ord(','), ord(';')))) == [
+    'a', 'b', 'c']
+try:
+  Map(lambda r: 1 / 0, 'foo')
+  raise AssertionError
+except ZeroDivisionError:
+  pass
+
+# Lists, tuples and dicts are converted to Go slices and maps.
+assert Join(['a', 'b'], ', ') == 'a, b'
+assert Join(('a',), ', ') == 'a'
+assert FormatMediaType('text/plain', {'charset': 'utf-8'}) == (
+    'text/plain; charset=utf-8')
+try:
+  FormatMediaType('text/plain', {'charset': 1})
+  raise AssertionError
+except TypeError as e:
+  assert "value for key 'charset'" in str(e), str(e)
+
+# Go maps support the mapping protocol.
+_, params, _ = ParseMediaType('text/html; charset=utf-8')
+assert len(params) == 1
+assert params['charset'] == 'utf-8'
+assert 'charset' in params
+assert dict(params.items()) == {'charset': 'utf-8'}
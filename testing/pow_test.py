@@ -78,3 +78,33 @@ assert large_number ** -1 == (1.0  / large_number), "large_number ** -1 == (1.0
 assert large_number ** 0 == 1, "large_number ** 0 == 1"
 assert large_number ** 1 == large_number, "large_number ** 1 == large_number"
 
+
+# Test pow with a modulus.
+assert pow(2, 10, 1000) == 24, "pow(2, 10, 1000)"
+assert pow(-2, 3, 5) == 2, "pow(-2, 3, 5)"
+assert pow(2, 3, -5) == -2, "pow(2, 3, -5)"
+assert pow(-2, 3, -5) == -3, "pow(-2, 3, -5)"
+assert pow(5, 0, 1) == 0, "pow(5, 0, 1)"
+assert pow(2, 10, None) == 1024, "pow(2, 10, None)"
+assert isinstance(pow(2, 10, 1000), int)
+assert pow(3L, 2 ** 100, 7) == 4L, "pow(3L, 2 ** 100, 7)"
+assert isinstance(pow(3L, 2 ** 100, 7), long)
+assert pow(7, 2 ** 70, -(2 ** 65)) == 1 - 2 ** 65, "pow(7, 2 ** 70, -(2 ** 65))"
+
+for args, exc in (((2, -1, 5), TypeError), ((2, 3, 0), ValueError),
+                  ((2.0, 3, 5), TypeError), ((2, 3, 'a'), TypeError)):
+  try:
+    pow(*args)
+  except exc:
+    pass
+  else:
+    raise AssertionError('pow%r should raise %s' % (args, exc.__name__))
+
+
+class Modular(object):
+
+  def __pow__(self, other, mod=None):
+    return (other, mod)
+
+assert pow(Modular(), 2, 3) == (2, 3), "pow(Modular(), 2, 3)"
+assert pow(Modular(), 2) == (2, None), "pow(Modular(), 2)"
@@ -179,6 +179,11 @@ assert "%%" % tuple() == "%"
 assert "%r" % "abc" == "'abc'"
 assert "%x" % 0x1f == "1f"
 assert "%X" % 0xffff == "FFFF"
+assert "%(name)s is %(age)d" % {"name": "Bob", "age": 42} == "Bob is 42"
+assert "%s" % {"a": 1} == "{'a': 1}"
+assert "% d|%+d|%-4d|" % (1, 2, 3) == " 1|+2|3   |"
+assert "%#x %#o" % (255, 8) == "0xff 010"
+assert "%*d|%-*s|%.*f" % (4, 1, 3, "a", 2, 3.14159) == "   1|a  |3.14"
 
 vals = [
     ['-16', '-16', '       -16', '-16', '-000000016'],
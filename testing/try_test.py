@@ -248,3 +248,30 @@ def foo():
 
 foo()
 assert x == [1, 2, 3]
+
+
+# A finally block that completes normally inside an except clause, here in a
+# called function, should not re-raise the exception being handled.
+def foo():
+  try:
+    pass
+  finally:
+    pass
+
+
+try:
+  raise AssertionError
+except AssertionError:
+  foo()
+
+
+# Exceptions hash by identity while their args compare by value.
+e1, e2 = ValueError('foo', 1), ValueError('foo', 1)
+assert len(set([e1, e2, e1])) == 2
+assert {e1: 'x'}[e1] == 'x'
+assert e1 != e2 and e1.args == e2.args
+assert len(set([e1.args, e2.args])) == 1
+assert e1[1] == 1
+e1.args = ['bar']
+assert e1.args == ('bar',) and str(e1) == 'bar'
+assert ValueError().args == ()
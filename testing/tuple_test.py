@@ -23,3 +23,14 @@ try:
   assert AssertionError
 except TypeError:
   pass
+
+# Test hash
+assert hash((1, 'a')) == hash((1, 'a'))
+assert len({(1, 2): 'a', (1, 2): 'b'}) == 1
+assert ('foo', (1,)) in set([('foo', (1,))])
+
+try:
+  hash(([],))
+  assert AssertionError
+except TypeError:
+  pass
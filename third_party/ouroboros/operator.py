@@ -9,10 +9,10 @@ This is the pure Python implementation of the module.
 """
 
 __all__ = ['abs', 'add', 'and_', 'attrgetter', 'concat', 'contains', 'countOf',
-           'delitem', 'eq', 'floordiv', 'ge', 'getitem', 'gt', 'iadd', 'iand',
-           'iconcat', 'ifloordiv', 'ilshift', 'imod', 'imul', 'index',
-           'indexOf', 'inv', 'invert', 'ior', 'ipow', 'irshift', 'is_',
-           'is_not', 'isub', 'itemgetter', 'itruediv', 'ixor', 'le',
+           'delitem', 'div', 'eq', 'floordiv', 'ge', 'getitem', 'gt', 'iadd',
+           'iand', 'iconcat', 'idiv', 'ifloordiv', 'ilshift', 'imod', 'imul',
+           'index', 'indexOf', 'inv', 'invert', 'ior', 'ipow', 'irshift',
+           'is_', 'is_not', 'isub', 'itemgetter', 'itruediv', 'ixor', 'le',
            'length_hint', 'lshift', 'lt', 'methodcaller', 'mod', 'mul', 'ne',
            'neg', 'not_', 'or_', 'pos', 'pow', 'rshift', 'setitem', 'sub',
            'truediv', 'truth', 'xor']
@@ -78,6 +78,10 @@ def and_(a, b):
     "Same as a & b."
     return a & b
 
+def div(a, b):
+    "Same as a / b when __future__.division is not in effect."
+    return a / b
+
 def floordiv(a, b):
     "Same as a // b."
     return a // b
@@ -308,6 +312,11 @@ def iconcat(a, b):
     a += b
     return a
 
+def idiv(a, b):
+    "Same as a /= b when __future__.division is not in effect."
+    a /= b
+    return a
+
 def ifloordiv(a, b):
     "Same as a //= b."
     a //= b
@@ -360,13 +369,14 @@ def ixor(a, b):
     a ^= b
     return a
 
-# TODO: https://github.com/google/grumpy/pull/263
-#try:
-#    from _operator import *
-#except ImportError:
-#    pass
-#else:
-#    from _operator import __doc__
+try:
+    import _operator
+except ImportError:
+    pass
+else:
+    # TODO: Use "from _operator import *" once wildcard imports are supported.
+    for _name in _operator.__all__:
+        globals()[_name] = getattr(_operator, _name)
 
 # All of these "__func__ = func" assignments have to happen after importing
 # from _operator to make sure they're set to the right function
@@ -380,6 +390,7 @@ __not__ = not_
 __abs__ = abs
 __add__ = add
 __and__ = and_
+__div__ = div
 __floordiv__ = floordiv
 __index__ = index
 __inv__ = inv
@@ -403,6 +414,7 @@ __setitem__ = setitem
 __iadd__ = iadd
 __iand__ = iand
 __iconcat__ = iconcat
+__idiv__ = idiv
 __ifloordiv__ = ifloordiv
 __ilshift__ = ilshift
 __imod__ = imod
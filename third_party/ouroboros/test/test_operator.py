@@ -135,7 +135,6 @@ class OperatorTestCase(unittest.TestCase):
         self.assertTrue(operator.countOf([1, 2, 1, 3, 1, 4], 3) == 1)
         self.assertTrue(operator.countOf([1, 2, 1, 3, 1, 4], 5) == 0)
 
-    @unittest.expectedFailure
     def test_delitem(self):
         #operator = self.module
         a = [4, 3, 2, 1]
@@ -258,7 +257,6 @@ class OperatorTestCase(unittest.TestCase):
         self.assertRaises(TypeError, operator.sub, None, None)
         self.assertTrue(operator.sub(5, 2) == 3)
 
-    @unittest.expectedFailure
     def test_truth(self):
         #operator = self.module
         class C(object):
@@ -345,7 +343,6 @@ class OperatorTestCase(unittest.TestCase):
         f = operator.attrgetter('name', 'child.name', 'child.child.name')
         self.assertEqual(f(a), ('arthur', 'thomas', 'johnson'))
 
-    @unittest.expectedFailure
     def test_itemgetter(self):
         #operator = self.module
         a = 'ABCDE'
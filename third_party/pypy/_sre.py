@@ -479,7 +479,8 @@ class _State(object):
         self.marks_stack = self.marks_stack[:-1]
 
     def marks_pop_keep(self):
-        self.marks, self.lastindex = self.marks_stack[-1]
+        marks, self.lastindex = self.marks_stack[-1]
+        self.marks = marks[:]
 
     def marks_pop_discard(self):
         # TODO: Use .pop once implemented
@@ -3,7 +3,7 @@ The :mod:`Diagnostic` module provides several commonly useful
 algorithms that operate on abstract syntax trees.
 """
 
-from __future__ import absolute_import, division, print_function, unicode_literals
+from __future__ import absolute_import, print_function, unicode_literals
 from . import ast
 
 class Visitor:
@@ -23,7 +23,7 @@ In particular this affects:
       :class:`arg` in ``vararg`` and ``kwarg`` slots.
 """
 
-from __future__ import absolute_import, division, print_function, unicode_literals
+from __future__ import absolute_import, print_function, unicode_literals
 
 # Location mixins
 
@@ -61,6 +61,10 @@ class commonloc(object):
     def lineno(self):
         return self.loc.line()
 
+    @property
+    def col_offset(self):
+        return self.loc.column()
+
 class keywordloc(commonloc):
     """
     A mixin common for all keyword statements, e.g. ``pass`` and ``yield expr``.
@@ -3,7 +3,7 @@ The :mod:`Diagnostic` module concerns itself with processing
 and presentation of diagnostic messages.
 """
 
-from __future__ import absolute_import, division, print_function, unicode_literals
+from __future__ import absolute_import, print_function, unicode_literals
 from functools import reduce
 from contextlib import contextmanager
 import sys, re
@@ -61,7 +61,10 @@ class Diagnostic:
         """
         Returns the formatted message.
         """
-        return self.reason.format(**self.arguments)
+        # TODO: Use str.format once it is supported by Grumpy.
+        # return self.reason.format(**self.arguments)
+        return re.sub(r"\{(\w+)\}",
+                      lambda m: "%s" % (self.arguments[m.group(1)],), self.reason)
 
     def render(self, only_line=False, colored=False):
         """
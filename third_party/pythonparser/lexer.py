@@ -2,7 +2,7 @@
 The :mod:`lexer` module concerns itself with tokenizing Python source.
 """
 
-from __future__ import absolute_import, division, print_function, unicode_literals
+from __future__ import absolute_import, print_function, unicode_literals
 from . import source, diagnostic
 import re
 import unicodedata
@@ -179,12 +179,12 @@ class Lexer:
                 # 21 unterminated
             |   (""\"|'''|"|')
             )
-        |   ((?:{keywords})\b|{operators}) # 22 keywords and operators
+        |   ((?:%(keywords)s)\b|%(operators)s) # 22 keywords and operators
         |   ([A-Za-z_][A-Za-z0-9_]*\b) # 23 identifier
-        |   (\p{{{id_xid}ID_Start}}\p{{{id_xid}ID_Continue}}*) # 24 Unicode identifier
+        |   (\p{%(id_xid)sID_Start}\p{%(id_xid)sID_Continue}*) # 24 Unicode identifier
         |   ($) # 25 end-of-file
         )
-        """.format(keywords=re_keywords, operators=re_operators,
+        """ % dict(keywords=re_keywords, operators=re_operators,
                    id_xid=id_xid), re.VERBOSE|re.UNICODE)
 
     # These are identical for all lexer instances.
@@ -4,7 +4,7 @@
 The :mod:`parser` module concerns itself with parsing Python source.
 """
 
-from __future__ import absolute_import, division, print_function, unicode_literals
+from __future__ import absolute_import, print_function, unicode_literals
 from functools import reduce
 from . import source, diagnostic, lexer, ast
 
@@ -897,11 +897,15 @@ class Parser:
         return ast.Print(dest=None, values=values, nl=nl,
                          dest_loc=None, loc=loc)
 
-    @action(Seq(Loc(">>"), Rule("test"), Tok(","), List(Rule("test"), ",", trailing=True)))
-    def print_stmt_2(self, dest_loc, dest, comma_tok, values):
-        nl, loc = True, values[-1].loc
-        if values.trailing_comma:
-            nl, loc = False, values.trailing_comma.loc
+    @action(Seq(Loc(">>"), Rule("test"),
+                Opt(Seq(Tok(","), List(Rule("test"), ",", trailing=True)))))
+    def print_stmt_2(self, dest_loc, dest, rest):
+        nl, loc, values = True, dest.loc, []
+        if rest is not None:
+            values = rest[1]
+            loc = values[-1].loc
+            if values.trailing_comma:
+                nl, loc = False, values.trailing_comma.loc
         return ast.Print(dest=dest, values=values, nl=nl,
                          dest_loc=dest_loc, loc=loc)
 
@@ -5,7 +5,7 @@ to a token, combining these ranges, extracting human-readable
 location information and original source from a range.
 """
 
-from __future__ import absolute_import, division, print_function, unicode_literals
+from __future__ import absolute_import, print_function, unicode_literals
 import bisect
 import re
 
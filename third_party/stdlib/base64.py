@@ -10,6 +10,7 @@ import re
 import _struct as struct
 import string
 import binascii
+from '__go__/grumpy' import BinasciiDecodeBase64, BinasciiEncodeBase64
 
 
 __all__ = [
@@ -51,11 +52,10 @@ def b64encode(s, altchars=None):
 
     The encoded string is returned.
     """
-    # Strip off the trailing newline
-    encoded = binascii.b2a_base64(s)[:-1]
-    if altchars is not None:
-        return encoded.translate(string.maketrans(b'+/', altchars[:2]))
-    return encoded
+    # Encode natively rather than translating the output of binascii.
+    if altchars is None:
+        altchars = ''
+    return BinasciiEncodeBase64(__frame__(), s, altchars[:2])  # pylint: disable=undefined-variable
 
 
 def b64decode(s, altchars=None):
@@ -70,13 +70,10 @@ def b64decode(s, altchars=None):
     alphabet nor the alternative alphabet are discarded prior to the padding
     check.
     """
-    if altchars is not None:
-        s = s.translate(string.maketrans(altchars[:2], '+/'))
-    try:
-        return binascii.a2b_base64(s)
-    except binascii.Error, msg:
-        # Transform this exception for consistency
-        raise TypeError(msg)
+    if altchars is None:
+        altchars = ''
+    # Raise TypeError rather than binascii.Error for consistency.
+    return BinasciiDecodeBase64(__frame__(), s, altchars[:2], TypeError)  # pylint: disable=undefined-variable
 
 
 def standard_b64encode(s):
@@ -96,16 +93,13 @@ def standard_b64decode(s):
     """
     return b64decode(s)
 
-_urlsafe_encode_translation = string.maketrans(b'+/', b'-_')
-_urlsafe_decode_translation = string.maketrans(b'-_', b'+/')
-
 def urlsafe_b64encode(s):
     """Encode a string using the URL- and filesystem-safe Base64 alphabet.
 
     Argument s is the string to encode.  The encoded string is returned.  The
     alphabet uses '-' instead of '+' and '_' instead of '/'.
     """
-    return b64encode(s).translate(_urlsafe_encode_translation)
+    return b64encode(s, '-_')
 
 def urlsafe_b64decode(s):
     """Decode a string using the URL- and filesystem-safe Base64 alphabet.
@@ -117,7 +111,7 @@ def urlsafe_b64decode(s):
 
     The alphabet uses '-' instead of '+' and '_' instead of '/'.
     """
-    return b64decode(s.translate(_urlsafe_decode_translation))
+    return b64decode(s, '-_')
 
 
 
@@ -10,7 +10,7 @@ list, set, and tuple.
 
 '''
 
-__all__ = ['Counter', 'namedtuple', 'OrderedDict', 'deque'] # 'deque', 'defaultdict',
+__all__ = ['Counter', 'deque', 'defaultdict', 'namedtuple', 'OrderedDict']
 # For bootstrapping reasons, the collection ABCs are defined in _abcoll.py.
 # They should however be considered an integral part of collections.py.
 import _abcoll
@@ -20,7 +20,7 @@ for name in _abcoll.__all__:
 
 import _collections
 deque = _collections.deque
-#defaultdict = _collections.defaultdict
+defaultdict = _collections.defaultdict
 import operator
 _itemgetter = operator.itemgetter
 _eq = operator.eq
@@ -45,369 +45,52 @@ _get_ident = thread.get_ident
 ### OrderedDict
 ################################################################################
 
-class OrderedDict(dict):
-    'Dictionary that remembers insertion order'
-    # An inherited dict maps keys to values.
-    # The inherited dict provides __getitem__, __len__, __contains__, and get.
-    # The remaining methods are order-aware.
-    # Big-O running times for all methods are the same as regular dictionaries.
-
-    # The internal self.__map dict maps keys to links in a doubly linked list.
-    # The circular doubly linked list starts and ends with a sentinel element.
-    # The sentinel element never gets deleted (this simplifies the algorithm).
-    # Each link is stored as a list of length three:  [PREV, NEXT, KEY].
-
-    def __init__(*args, **kwds):
-        '''Initialize an ordered dictionary.  The signature is the same as
-        regular dictionaries, but keyword arguments are not recommended because
-        their insertion order is arbitrary.
-
-        '''
-        if not args:
-            raise TypeError("descriptor '__init__' of 'OrderedDict' object "
-                            "needs an argument")
-        self = args[0]
-        args = args[1:]
-        if len(args) > 1:
-            raise TypeError('expected at most 1 arguments, got %d' % len(args))
-        try:
-            self.__root
-        except AttributeError:
-            self.__root = root = []                     # sentinel node
-            root[:] = [root, root, None]
-            self.__map = {}
-        self.__update(*args, **kwds)
-
-    def __setitem__(self, key, value, dict_setitem=dict.__setitem__):
-        'od.__setitem__(i, y) <==> od[i]=y'
-        # Setting a new item creates a new link at the end of the linked list,
-        # and the inherited dictionary is updated with the new key/value pair.
-        if key not in self:
-            root = self.__root
-            last = root[0]
-            last[1] = root[0] = self.__map[key] = [last, root, key]
-        return dict_setitem(self, key, value)
-
-    def __delitem__(self, key, dict_delitem=dict.__delitem__):
-        'od.__delitem__(y) <==> del od[y]'
-        # Deleting an existing item uses self.__map to find the link which gets
-        # removed by updating the links in the predecessor and successor nodes.
-        dict_delitem(self, key)
-        link_prev, link_next, _ = self.__map.pop(key)
-        link_prev[1] = link_next                        # update link_prev[NEXT]
-        link_next[0] = link_prev                        # update link_next[PREV]
-
-    def __iter__(self):
-        'od.__iter__() <==> iter(od)'
-        # Traverse the linked list in order.
-        root = self.__root
-        curr = root[1]                                  # start at the first node
-        while curr is not root:
-            yield curr[2]                               # yield the curr[KEY]
-            curr = curr[1]                              # move to next node
-
-    def __reversed__(self):
-        'od.__reversed__() <==> reversed(od)'
-        # Traverse the linked list in reverse order.
-        root = self.__root
-        curr = root[0]                                  # start at the last node
-        while curr is not root:
-            yield curr[2]                               # yield the curr[KEY]
-            curr = curr[0]                              # move to previous node
-
-    def clear(self):
-        'od.clear() -> None.  Remove all items from od.'
-        root = self.__root
-        root[:] = [root, root, None]
-        self.__map.clear()
-        dict.clear(self)
-
-    # -- the following methods do not depend on the internal structure --
-
-    def keys(self):
-        'od.keys() -> list of keys in od'
-        return list(self)
-
-    def values(self):
-        'od.values() -> list of values in od'
-        return [self[key] for key in self]
-
-    def items(self):
-        'od.items() -> list of (key, value) pairs in od'
-        return [(key, self[key]) for key in self]
-
-    def iterkeys(self):
-        'od.iterkeys() -> an iterator over the keys in od'
-        return iter(self)
-
-    def itervalues(self):
-        'od.itervalues -> an iterator over the values in od'
-        for k in self:
-            yield self[k]
-
-    def iteritems(self):
-        'od.iteritems -> an iterator over the (key, value) pairs in od'
-        for k in self:
-            yield (k, self[k])
-
-    update = MutableMapping.update
-
-    __update = update # let subclasses override update without breaking __init__
-
-    __marker = object()
-
-    def pop(self, key, default=__marker):
-        '''od.pop(k[,d]) -> v, remove specified key and return the corresponding
-        value.  If key is not found, d is returned if given, otherwise KeyError
-        is raised.
-
-        '''
-        if key in self:
-            result = self[key]
-            del self[key]
-            return result
-        if default is self.__marker:
-            raise KeyError(key)
-        return default
-
-    def setdefault(self, key, default=None):
-        'od.setdefault(k[,d]) -> od.get(k,d), also set od[k]=d if k not in od'
-        if key in self:
-            return self[key]
-        self[key] = default
-        return default
-
-    def popitem(self, last=True):
-        '''od.popitem() -> (k, v), return and remove a (key, value) pair.
-        Pairs are returned in LIFO order if last is true or FIFO order if false.
-
-        '''
-        if not self:
-            raise KeyError('dictionary is empty')
-        key = next(reversed(self) if last else iter(self))
-        value = self.pop(key)
-        return key, value
-
-    def __repr__(self, _repr_running={}):
-        'od.__repr__() <==> repr(od)'
-        call_key = id(self), _get_ident()
-        if call_key in _repr_running:
-            return '...'
-        _repr_running[call_key] = 1
-        try:
-            if not self:
-                return '%s()' % (self.__class__.__name__,)
-            return '%s(%r)' % (self.__class__.__name__, self.items())
-        finally:
-            del _repr_running[call_key]
-
-    def __reduce__(self):
-        'Return state information for pickling'
-        items = [[k, self[k]] for k in self]
-        inst_dict = vars(self).copy()
-        for k in vars(OrderedDict()):
-            inst_dict.pop(k, None)
-        if inst_dict:
-            return (self.__class__, (items,), inst_dict)
-        return self.__class__, (items,)
-
-    def copy(self):
-        'od.copy() -> a shallow copy of od'
-        return self.__class__(self)
-
-    @classmethod
-    def fromkeys(cls, iterable, value=None):
-        '''OD.fromkeys(S[, v]) -> New ordered dictionary with keys from S.
-        If not specified, the value defaults to None.
-
-        '''
-        self = cls()
-        for key in iterable:
-            self[key] = value
-        return self
-
-    def __eq__(self, other):
-        '''od.__eq__(y) <==> od==y.  Comparison to another OD is order-sensitive
-        while comparison to a regular mapping is order-insensitive.
-
-        '''
-        if isinstance(other, OrderedDict):
-            return dict.__eq__(self, other) and all(_imap(_eq, self, other))
-        return dict.__eq__(self, other)
-
-    def __ne__(self, other):
-        'od.__ne__(y) <==> od!=y'
-        return not self == other
-
-    # -- the following methods support python 3.x style dictionary views --
-
-    def viewkeys(self):
-        "od.viewkeys() -> a set-like object providing a view on od's keys"
-        return KeysView(self)
-
-    def viewvalues(self):
-        "od.viewvalues() -> an object providing a view on od's values"
-        return ValuesView(self)
-
-    def viewitems(self):
-        "od.viewitems() -> a set-like object providing a view on od's items"
-        return ItemsView(self)
+OrderedDict = _collections.OrderedDict
 
 
 ################################################################################
 ### namedtuple
 ################################################################################
 
-_class_template = '''\
-class {typename}(tuple):
-    '{typename}({arg_list})'
-
-    __slots__ = ()
-
-    _fields = {field_names!r}
-
-    def __new__(_cls, {arg_list}):
-        'Create new instance of {typename}({arg_list})'
-        return _tuple.__new__(_cls, ({arg_list}))
-
-    @classmethod
-    def _make(cls, iterable, new=tuple.__new__, len=len):
-        'Make a new {typename} object from a sequence or iterable'
-        result = new(cls, iterable)
-        if len(result) != {num_fields:d}:
-            raise TypeError('Expected {num_fields:d} arguments, got %d' % len(result))
-        return result
-
-    def __repr__(self):
-        'Return a nicely formatted representation string'
-        return '{typename}({repr_fmt})' % self
-
-    def _asdict(self):
-        'Return a new OrderedDict which maps field names to their values'
-        return OrderedDict(zip(self._fields, self))
-
-    def _replace(_self, **kwds):
-        'Return a new {typename} object replacing specified fields with new values'
-        result = _self._make(map(kwds.pop, {field_names!r}, _self))
-        if kwds:
-            raise ValueError('Got unexpected field names: %r' % kwds.keys())
-        return result
-
-    def __getnewargs__(self):
-        'Return self as a plain tuple.  Used by copy and pickle.'
-        return tuple(self)
-
-    __dict__ = _property(_asdict)
-
-    def __getstate__(self):
-        'Exclude the OrderedDict from pickling'
+def namedtuple(typename, field_names, verbose=False, rename=False):
+    """Returns a new subclass of tuple with named fields.
+
+    >>> Point = namedtuple('Point', ['x', 'y'])
+    >>> Point.__doc__                   # docstring for the new class
+    'Point(x, y)'
+    >>> p = Point(11, y=22)             # instantiate with positional args or keywords
+    >>> p[0] + p[1]                     # indexable like a plain tuple
+    33
+    >>> x, y = p                        # unpack like a regular tuple
+    >>> x, y
+    (11, 22)
+    >>> p.x + p.y                       # fields also accessible by name
+    33
+    >>> d = p._asdict()                 # convert to a dictionary
+    >>> d['x']
+    11
+    >>> Point(**d)                      # convert from a dictionary
+    Point(x=11, y=22)
+    >>> p._replace(x=100)               # _replace() is like str.replace() but targets named fields
+    Point(x=100, y=22)
+
+    """
+
+    # The class is built natively rather than from a source template, so there
+    # is no class definition to print when verbose is set.
+    result = _collections.NewNamedTupleType(__frame__(), typename, field_names,  # pylint: disable=undefined-variable
+                                            bool(rename))
+
+    # For pickling to work, the __module__ variable needs to be set to the frame
+    # where the named tuple is created.  Bypass this step in environments where
+    # sys._getframe is not defined (Jython for example) or sys._getframe is not
+    # defined for arguments greater than 0 (IronPython).
+    try:
+        result.__module__ = _sys._getframe(1).f_globals.get('__name__', '__main__')
+    except (AttributeError, ValueError):
         pass
 
-{field_defs}
-'''
-
-_repr_template = '{name}=%r'
-
-_field_template = '''\
-    {name} = _property(_itemgetter({index:d}), doc='Alias for field number {index:d}')
-'''
-
-#def namedtuple(typename, field_names, verbose=False, rename=False):
-#    """Returns a new subclass of tuple with named fields.
-#
-#    >>> Point = namedtuple('Point', ['x', 'y'])
-#    >>> Point.__doc__                   # docstring for the new class
-#    'Point(x, y)'
-#    >>> p = Point(11, y=22)             # instantiate with positional args or keywords
-#    >>> p[0] + p[1]                     # indexable like a plain tuple
-#    33
-#    >>> x, y = p                        # unpack like a regular tuple
-#    >>> x, y
-#    (11, 22)
-#    >>> p.x + p.y                       # fields also accessible by name
-#    33
-#    >>> d = p._asdict()                 # convert to a dictionary
-#    >>> d['x']
-#    11
-#    >>> Point(**d)                      # convert from a dictionary
-#    Point(x=11, y=22)
-#    >>> p._replace(x=100)               # _replace() is like str.replace() but targets named fields
-#    Point(x=100, y=22)
-#
-#    """
-#
-#    # Validate the field names.  At the user's option, either generate an error
-#    # message or automatically replace the field name with a valid name.
-#    if isinstance(field_names, basestring):
-#        field_names = field_names.replace(',', ' ').split()
-#    field_names = map(str, field_names)
-#    typename = str(typename)
-#    if rename:
-#        seen = set()
-#        for index, name in enumerate(field_names):
-#            if (not all(c.isalnum() or c=='_' for c in name)
-#                or _iskeyword(name)
-#                or not name
-#                or name[0].isdigit()
-#                or name.startswith('_')
-#                or name in seen):
-#                field_names[index] = '_%d' % index
-#            seen.add(name)
-#    for name in [typename] + field_names:
-#        if type(name) != str:
-#            raise TypeError('Type names and field names must be strings')
-#        if not all(c.isalnum() or c=='_' for c in name):
-#            raise ValueError('Type names and field names can only contain '
-#                             'alphanumeric characters and underscores: %r' % name)
-#        if _iskeyword(name):
-#            raise ValueError('Type names and field names cannot be a '
-#                             'keyword: %r' % name)
-#        if name[0].isdigit():
-#            raise ValueError('Type names and field names cannot start with '
-#                             'a number: %r' % name)
-#    seen = set()
-#    for name in field_names:
-#        if name.startswith('_') and not rename:
-#            raise ValueError('Field names cannot start with an underscore: '
-#                             '%r' % name)
-#        if name in seen:
-#            raise ValueError('Encountered duplicate field name: %r' % name)
-#        seen.add(name)
-#
-#    # Fill-in the class template
-#    class_definition = _class_template.format(
-#        typename = typename,
-#        field_names = tuple(field_names),
-#        num_fields = len(field_names),
-#        arg_list = repr(tuple(field_names)).replace("'", "")[1:-1],
-#        repr_fmt = ', '.join(_repr_template.format(name=name)
-#                             for name in field_names),
-#        field_defs = '\n'.join(_field_template.format(index=index, name=name)
-#                               for index, name in enumerate(field_names))
-#    )
-#    if verbose:
-#        print class_definition
-#
-#    # Execute the template string in a temporary namespace and support
-#    # tracing utilities by setting a value for frame.f_globals['__name__']
-#    namespace = dict(_itemgetter=_itemgetter, __name__='namedtuple_%s' % typename,
-#                     OrderedDict=OrderedDict, _property=property, _tuple=tuple)
-#    try:
-#        exec class_definition in namespace
-#    except SyntaxError as e:
-#        raise SyntaxError(e.message + ':\n' + class_definition)
-#    result = namespace[typename]
-#
-#    # For pickling to work, the __module__ variable needs to be set to the frame
-#    # where the named tuple is created.  Bypass this step in environments where
-#    # sys._getframe is not defined (Jython for example) or sys._getframe is not
-#    # defined for arguments greater than 0 (IronPython).
-#    try:
-#        result.__module__ = _sys._getframe(1).f_globals.get('__name__', '__main__')
-#    except (AttributeError, ValueError):
-#        pass
-#
-#    return result
+    return result
 
 
 ########################################################################
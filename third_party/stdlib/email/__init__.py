@@ -0,0 +1,15 @@
+# Copyright (C) 2001-2006 Python Software Foundation
+# Author: Barry Warsaw
+# Contact: email-sig@python.org
+
+"""A package for parsing, handling, and generating email messages."""
+
+# Grumpy only provides the email.utils address and date helpers. The message
+# parsing and generating modules, and the lazy importers for the old module
+# names, are not available.
+
+__version__ = '4.0.3'
+
+__all__ = [
+    'utils',
+    ]
@@ -110,6 +110,7 @@ __author__ = 'Bob Ippolito <bob@redivi.com>'
 import json.decoder
 import json.encoder
 import json_scanner
+from '__go__/grumpy' import JSONDecode as _JSONDecode, JSONEncode as _JSONEncode
 JSONDecoder = json.decoder.JSONDecoder
 JSONEncoder = json.encoder.JSONEncoder
 scanner = json_scanner
@@ -125,6 +126,16 @@ _default_encoder = JSONEncoder(
     default=None,
 )
 
+def _native_dumps(obj, skipkeys, ensure_ascii, check_circular, allow_nan,
+                  indent, separators, default, sort_keys):
+    # Grumpy: encode with the runtime's native encoder, which supports all
+    # the options of JSONEncoder except custom encodings and subclasses.
+    item_separator, key_separator = separators or (', ', ': ')
+    return _JSONEncode(__frame__(), obj, skipkeys, ensure_ascii,  # pylint: disable=undefined-variable
+                       check_circular, allow_nan, sort_keys, indent,
+                       str(item_separator), str(key_separator), default)
+
+
 def dump(obj, fp, skipkeys=False, ensure_ascii=True, check_circular=True,
         allow_nan=True, cls=None, indent=None, separators=None,
         encoding='utf-8', default=None, sort_keys=False, **kw):
@@ -177,19 +188,17 @@ def dump(obj, fp, skipkeys=False, ensure_ascii=True, check_circular=True,
     the ``cls`` kwarg; otherwise ``JSONEncoder`` is used.
 
     """
-    # cached encoder
-    if (not skipkeys and ensure_ascii and
-        check_circular and allow_nan and
-        cls is None and indent is None and separators is None and
-        encoding == 'utf-8' and default is None and not sort_keys and not kw):
-        iterable = _default_encoder.iterencode(obj)
-    else:
-        if cls is None:
-            cls = JSONEncoder
-        iterable = cls(skipkeys=skipkeys, ensure_ascii=ensure_ascii,
-            check_circular=check_circular, allow_nan=allow_nan, indent=indent,
-            separators=separators, encoding=encoding,
-            default=default, sort_keys=sort_keys, **kw).iterencode(obj)
+    if cls is None and encoding == 'utf-8' and not kw:
+        fp.write(_native_dumps(obj, skipkeys, ensure_ascii, check_circular,
+                               allow_nan, indent, separators, default,
+                               sort_keys))
+        return
+    if cls is None:
+        cls = JSONEncoder
+    iterable = cls(skipkeys=skipkeys, ensure_ascii=ensure_ascii,
+        check_circular=check_circular, allow_nan=allow_nan, indent=indent,
+        separators=separators, encoding=encoding,
+        default=default, sort_keys=sort_keys, **kw).iterencode(obj)
     # could accelerate with writelines in some versions of Python, at
     # a debuggability cost
     for chunk in iterable:
@@ -242,12 +251,9 @@ def dumps(obj, skipkeys=False, ensure_ascii=True, check_circular=True,
     the ``cls`` kwarg; otherwise ``JSONEncoder`` is used.
 
     """
-    # cached encoder
-    if (not skipkeys and ensure_ascii and
-        check_circular and allow_nan and
-        cls is None and indent is None and separators is None and
-        encoding == 'utf-8' and default is None and not sort_keys and not kw):
-        return _default_encoder.encode(obj)
+    if cls is None and encoding == 'utf-8' and not kw:
+        return _native_dumps(obj, skipkeys, ensure_ascii, check_circular,
+                             allow_nan, indent, separators, default, sort_keys)
     if cls is None:
         cls = JSONEncoder
     return cls(
@@ -339,10 +345,11 @@ def loads(s, encoding=None, cls=None, object_hook=None, parse_float=None,
     kwarg; otherwise ``JSONDecoder`` is used.
 
     """
-    if (cls is None and encoding is None and object_hook is None and
+    if (cls is None and encoding in (None, 'utf-8') and
             parse_int is None and parse_float is None and
-            parse_constant is None and object_pairs_hook is None and not kw):
-        return _default_decoder.decode(s)
+            parse_constant is None and not kw):
+        # Grumpy: decode with the runtime's native decoder.
+        return _JSONDecode(__frame__(), s, object_hook, object_pairs_hook)  # pylint: disable=undefined-variable
     if cls is None:
         cls = JSONDecoder
     if object_hook is not None:
@@ -18,8 +18,10 @@ __all__ = ['JSONDecoder']
 FLAGS = re.VERBOSE | re.MULTILINE | re.DOTALL
 
 def _floatconstants():
-    nan, = struct.unpack('>d', b'\x7f\xf8\x00\x00\x00\x00\x00\x00')
-    inf, = struct.unpack('>d', b'\x7f\xf0\x00\x00\x00\x00\x00\x00')
+    # Grumpy: the parser treats a single element tuple target like "nan, = x"
+    # as a plain name so index the unpacked tuple instead.
+    nan = struct.unpack('>d', b'\x7f\xf8\x00\x00\x00\x00\x00\x00')[0]
+    inf = struct.unpack('>d', b'\x7f\xf0\x00\x00\x00\x00\x00\x00')[0]
     return nan, inf, -inf
 
 NaN, PosInf, NegInf = _floatconstants()
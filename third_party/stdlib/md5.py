@@ -7,9 +7,8 @@
 # warnings.warn("the md5 module is deprecated; use hashlib instead",
 #                 DeprecationWarning, 2)
 
-# from hashlib import md5
-import _md5
+from hashlib import md5
 
-new = _md5.new
-md5 = _md5.new
+new = md5
+blocksize = 1        # legacy value (wrong in any useful sense)
 digest_size = 16
@@ -39,35 +39,33 @@ General notes on the underlying Mersenne Twister core generator:
 
 """
 
-#from warnings import warn as _warn
-#from types import MethodType as _MethodType, BuiltinMethodType as _BuiltinMethodType
-#from math import log as _log, exp as _exp, pi as _pi, e as _e, ceil as _ceil
-#from math import sqrt as _sqrt, acos as _acos, cos as _cos, sin as _sin
-#from os import urandom as _urandom
-#from binascii import hexlify as _hexlify
-#import hashlib as _hashlib
+from warnings import warn as _warn
+from types import MethodType as _MethodType, BuiltinMethodType as _BuiltinMethodType
+from math import log as _log, exp as _exp, pi as _pi, e as _e, ceil as _ceil
+from math import sqrt as _sqrt, acos as _acos, cos as _cos, sin as _sin
+import hashlib as _hashlib
 
 __all__ = ["Random","seed","random","uniform","randint","choice","sample",
            "randrange","shuffle","normalvariate","lognormvariate",
            "expovariate","vonmisesvariate","gammavariate","triangular",
            "gauss","betavariate","paretovariate","weibullvariate",
-           "getstate","setstate","jumpahead", "WichmannHill", "getrandbits",
-           "SystemRandom"]
+           "getstate","setstate","jumpahead", "getrandbits"]
 
-# Import _random wrapper from grumpy std lib.
-# It is used as a replacement for the CPython random generator.
+NV_MAGICCONST = 4 * _exp(-0.5)/_sqrt(2.0)
+TWOPI = 2.0*_pi
+LOG4 = _log(4.0)
+SG_MAGICCONST = 1.0 + _log(4.5)
+BPF = 53        # Number of bits in a float
+RECIP_BPF = 2**-BPF
 
-import _random
 
-# NV_MAGICCONST = 4 * _exp(-0.5)/_sqrt(2.0)
-# TWOPI = 2.0*_pi
-# LOG4 = _log(4.0)
-# SG_MAGICCONST = 1.0 + _log(4.5)
-BPF = _random.BPF
-RECIP_BPF = _random.RECIP_BPF
+# Translated by Guido van Rossum from C source provided by
+# Adrian Baddeley.  Adapted by Raymond Hettinger for use with
+# the Mersenne Twister  and os.urandom() core generators.
 
+import _random
 
-class Random(_random.GrumpyRandom):
+class Random(_random.Random):
     """Random number generator base class used by bound module functions.
 
     Used to instantiate instances of Random to get generators that don't
@@ -106,12 +104,64 @@ class Random(_random.GrumpyRandom):
         PYTHONHASHSEED environment variable is enabled.
         """
 
+        # When a is None, _random.Random.seed() seeds with enough bytes from
+        # the operating system's randomness source to span the 19937 bit
+        # state space for the Mersenne Twister.
         super(Random, self).seed(a)
         self.gauss_next = None
 
+    def getstate(self):
+        """Return internal state; can be passed to setstate() later."""
+        return self.VERSION, super(Random, self).getstate(), self.gauss_next
+
+    def setstate(self, state):
+        """Restore internal state from object returned by getstate()."""
+        version = state[0]
+        if version == 3:
+            version, internalstate, self.gauss_next = state
+            super(Random, self).setstate(internalstate)
+        elif version == 2:
+            version, internalstate, self.gauss_next = state
+            # In version 2, the state was saved as signed ints, which causes
+            #   inconsistencies between 32/64-bit systems. The state is
+            #   really unsigned 32-bit ints, so we convert negative ints from
+            #   version 2 to positive longs for version 3.
+            try:
+                internalstate = tuple( long(x) % (2**32) for x in internalstate )
+            except ValueError, e:
+                raise TypeError, e
+            super(Random, self).setstate(internalstate)
+        else:
+            raise ValueError("state with version %s passed to "
+                             "Random.setstate() of version %s" %
+                             (version, self.VERSION))
+
+    def jumpahead(self, n):
+        """Change the internal state to one that is likely far away
+        from the current state.  This method will not be in Py3.x,
+        so it is better to simply reseed.
+        """
+        # The super.jumpahead() method uses shuffling to change state,
+        # so it needs a large and "interesting" n to work with.  Here,
+        # we use hashing to create a large n for the shuffle.
+        s = repr(n) + repr(self.getstate())
+        n = int(_hashlib.new('sha512', s).hexdigest(), 16)
+        super(Random, self).jumpahead(n)
+
 ## ---- Methods below this point do not need to be overridden when
 ## ---- subclassing for the purpose of using a different core generator.
 
+## -------------------- pickle support  -------------------
+
+    def __getstate__(self): # for pickle
+        return self.getstate()
+
+    def __setstate__(self, state):  # for pickle
+        self.setstate(state)
+
+    def __reduce__(self):
+        return self.__class__, (), self.getstate()
+
 ## -------------------- integer methods  -------------------
 
     def randrange(self, start, stop=None, step=1, _int=int, _maxwidth=1L<<BPF):
@@ -183,6 +233,33 @@ class Random(_random.GrumpyRandom):
 
         return self.randrange(a, b+1)
 
+    def _randbelow(self, n, _log=_log, _int=int, _maxwidth=1L<<BPF,
+                   _Method=_MethodType, _BuiltinMethod=_BuiltinMethodType):
+        """Return a random int in the range [0,n)
+
+        Handles the case where n has more bits than returned
+        by a single call to the underlying generator.
+        """
+
+        try:
+            getrandbits = self.getrandbits
+        except AttributeError:
+            pass
+        else:
+            # Only call self.getrandbits if the original random() builtin method
+            # has not been overridden or if a new getrandbits() was supplied.
+            # This assures that the two methods correspond.
+            if type(self.random) is _BuiltinMethod or type(getrandbits) is _Method:
+                k = _int(1.00001 + _log(n-1, 2.0))   # 2**k > n-1 > 2**(k-2)
+                r = getrandbits(k)
+                while r >= n:
+                    r = getrandbits(k)
+                return r
+        if n >= _maxwidth:
+            _warn("Underlying random() generator does not supply \n"
+                "enough bits to choose from a population range this large")
+        return _int(self.random() * n)
+
 ## -------------------- sequence methods  -------------------
 
     def choice(self, seq):
@@ -200,71 +277,71 @@ class Random(_random.GrumpyRandom):
         if random is None:
             random = self.random
         _int = int
-        for i in reversed(xrange(1, len(x))):
+        for i in xrange(len(x) - 1, 0, -1):
             # pick an element in x[:i+1] with which to exchange x[i]
             j = _int(random() * (i+1))
             x[i], x[j] = x[j], x[i]
 
-    # def sample(self, population, k):
-    #     """Chooses k unique random elements from a population sequence.
-
-    #     Returns a new list containing elements from the population while
-    #     leaving the original population unchanged.  The resulting list is
-    #     in selection order so that all sub-slices will also be valid random
-    #     samples.  This allows raffle winners (the sample) to be partitioned
-    #     into grand prize and second place winners (the subslices).
-
-    #     Members of the population need not be hashable or unique.  If the
-    #     population contains repeats, then each occurrence is a possible
-    #     selection in the sample.
-
-    #     To choose a sample in a range of integers, use xrange as an argument.
-    #     This is especially fast and space efficient for sampling from a
-    #     large population:   sample(xrange(10000000), 60)
-    #     """
-
-    #     # Sampling without replacement entails tracking either potential
-    #     # selections (the pool) in a list or previous selections in a set.
-
-    #     # When the number of selections is small compared to the
-    #     # population, then tracking selections is efficient, requiring
-    #     # only a small set and an occasional reselection.  For
-    #     # a larger number of selections, the pool tracking method is
-    #     # preferred since the list takes less space than the
-    #     # set and it doesn't suffer from frequent reselections.
-
-    #     n = len(population)
-    #     if not 0 <= k <= n:
-    #         raise ValueError("sample larger than population")
-    #     random = self.random
-    #     _int = int
-    #     result = [None] * k
-    #     setsize = 21        # size of a small set minus size of an empty list
-    #     if k > 5:
-    #         setsize += 4 ** _ceil(_log(k * 3, 4)) # table size for big sets
-    #     if n <= setsize or hasattr(population, "keys"):
-    #         # An n-length list is smaller than a k-length set, or this is a
-    #         # mapping type so the other algorithm wouldn't work.
-    #         pool = list(population)
-    #         for i in xrange(k):         # invariant:  non-selected at [0,n-i)
-    #             j = _int(random() * (n-i))
-    #             result[i] = pool[j]
-    #             pool[j] = pool[n-i-1]   # move non-selected item into vacancy
-    #     else:
-    #         try:
-    #             selected = set()
-    #             selected_add = selected.add
-    #             for i in xrange(k):
-    #                 j = _int(random() * n)
-    #                 while j in selected:
-    #                     j = _int(random() * n)
-    #                 selected_add(j)
-    #                 result[i] = population[j]
-    #         except (TypeError, KeyError):   # handle (at least) sets
-    #             if isinstance(population, list):
-    #                 raise
-    #             return self.sample(tuple(population), k)
-    #     return result
+    def sample(self, population, k):
+        """Chooses k unique random elements from a population sequence.
+
+        Returns a new list containing elements from the population while
+        leaving the original population unchanged.  The resulting list is
+        in selection order so that all sub-slices will also be valid random
+        samples.  This allows raffle winners (the sample) to be partitioned
+        into grand prize and second place winners (the subslices).
+
+        Members of the population need not be hashable or unique.  If the
+        population contains repeats, then each occurrence is a possible
+        selection in the sample.
+
+        To choose a sample in a range of integers, use xrange as an argument.
+        This is especially fast and space efficient for sampling from a
+        large population:   sample(xrange(10000000), 60)
+        """
+
+        # Sampling without replacement entails tracking either potential
+        # selections (the pool) in a list or previous selections in a set.
+
+        # When the number of selections is small compared to the
+        # population, then tracking selections is efficient, requiring
+        # only a small set and an occasional reselection.  For
+        # a larger number of selections, the pool tracking method is
+        # preferred since the list takes less space than the
+        # set and it doesn't suffer from frequent reselections.
+
+        n = len(population)
+        if not 0 <= k <= n:
+            raise ValueError("sample larger than population")
+        random = self.random
+        _int = int
+        result = [None] * k
+        setsize = 21        # size of a small set minus size of an empty list
+        if k > 5:
+            setsize += 4 ** _ceil(_log(k * 3, 4)) # table size for big sets
+        if n <= setsize or hasattr(population, "keys"):
+            # An n-length list is smaller than a k-length set, or this is a
+            # mapping type so the other algorithm wouldn't work.
+            pool = list(population)
+            for i in xrange(k):         # invariant:  non-selected at [0,n-i)
+                j = _int(random() * (n-i))
+                result[i] = pool[j]
+                pool[j] = pool[n-i-1]   # move non-selected item into vacancy
+        else:
+            try:
+                selected = set()
+                selected_add = selected.add
+                for i in xrange(k):
+                    j = _int(random() * n)
+                    while j in selected:
+                        j = _int(random() * n)
+                    selected_add(j)
+                    result[i] = population[j]
+            except (TypeError, KeyError):   # handle (at least) sets
+                if isinstance(population, list):
+                    raise
+                return self.sample(tuple(population), k)
+        return result
 
 ## -------------------- real-valued distributions  -------------------
 
@@ -276,242 +353,242 @@ class Random(_random.GrumpyRandom):
 
 ## -------------------- triangular --------------------
 
-    # def triangular(self, low=0.0, high=1.0, mode=None):
-    #     """Triangular distribution.
+    def triangular(self, low=0.0, high=1.0, mode=None):
+        """Triangular distribution.
 
-    #     Continuous distribution bounded by given lower and upper limits,
-    #     and having a given mode value in-between.
+        Continuous distribution bounded by given lower and upper limits,
+        and having a given mode value in-between.
 
-    #     http://en.wikipedia.org/wiki/Triangular_distribution
+        http://en.wikipedia.org/wiki/Triangular_distribution
 
-    #     """
-    #     u = self.random()
-    #     try:
-    #         c = 0.5 if mode is None else (mode - low) / (high - low)
-    #     except ZeroDivisionError:
-    #         return low
-    #     if u > c:
-    #         u = 1.0 - u
-    #         c = 1.0 - c
-    #         low, high = high, low
-    #     return low + (high - low) * (u * c) ** 0.5
+        """
+        u = self.random()
+        try:
+            c = 0.5 if mode is None else (mode - low) / float(high - low)
+        except ZeroDivisionError:
+            return low
+        if u > c:
+            u = 1.0 - u
+            c = 1.0 - c
+            low, high = high, low
+        return low + (high - low) * (u * c) ** 0.5
 
 ## -------------------- normal distribution --------------------
 
-    # def normalvariate(self, mu, sigma):
-    #     """Normal distribution.
-
-    #     mu is the mean, and sigma is the standard deviation.
-
-    #     """
-    #     # mu = mean, sigma = standard deviation
+    def normalvariate(self, mu, sigma):
+        """Normal distribution.
 
-    #     # Uses Kinderman and Monahan method. Reference: Kinderman,
-    #     # A.J. and Monahan, J.F., "Computer generation of random
-    #     # variables using the ratio of uniform deviates", ACM Trans
-    #     # Math Software, 3, (1977), pp257-260.
+        mu is the mean, and sigma is the standard deviation.
 
-    #     random = self.random
-    #     while 1:
-    #         u1 = random()
-    #         u2 = 1.0 - random()
-    #         z = NV_MAGICCONST*(u1-0.5)/u2
-    #         zz = z*z/4.0
-    #         if zz <= -_log(u2):
-    #             break
-    #     return mu + z*sigma
+        """
+        # mu = mean, sigma = standard deviation
+
+        # Uses Kinderman and Monahan method. Reference: Kinderman,
+        # A.J. and Monahan, J.F., "Computer generation of random
+        # variables using the ratio of uniform deviates", ACM Trans
+        # Math Software, 3, (1977), pp257-260.
+
+        random = self.random
+        while 1:
+            u1 = random()
+            u2 = 1.0 - random()
+            z = NV_MAGICCONST*(u1-0.5)/u2
+            zz = z*z/4.0
+            if zz <= -_log(u2):
+                break
+        return mu + z*sigma
 
 ## -------------------- lognormal distribution --------------------
 
-    # def lognormvariate(self, mu, sigma):
-    #     """Log normal distribution.
+    def lognormvariate(self, mu, sigma):
+        """Log normal distribution.
 
-    #     If you take the natural logarithm of this distribution, you'll get a
-    #     normal distribution with mean mu and standard deviation sigma.
-    #     mu can have any value, and sigma must be greater than zero.
+        If you take the natural logarithm of this distribution, you'll get a
+        normal distribution with mean mu and standard deviation sigma.
+        mu can have any value, and sigma must be greater than zero.
 
-    #     """
-    #     return _exp(self.normalvariate(mu, sigma))
+        """
+        return _exp(self.normalvariate(mu, sigma))
 
 ## -------------------- exponential distribution --------------------
 
-    # def expovariate(self, lambd):
-    #     """Exponential distribution.
+    def expovariate(self, lambd):
+        """Exponential distribution.
 
-    #     lambd is 1.0 divided by the desired mean.  It should be
-    #     nonzero.  (The parameter would be called "lambda", but that is
-    #     a reserved word in Python.)  Returned values range from 0 to
-    #     positive infinity if lambd is positive, and from negative
-    #     infinity to 0 if lambd is negative.
+        lambd is 1.0 divided by the desired mean.  It should be
+        nonzero.  (The parameter would be called "lambda", but that is
+        a reserved word in Python.)  Returned values range from 0 to
+        positive infinity if lambd is positive, and from negative
+        infinity to 0 if lambd is negative.
 
-    #     """
-    #     # lambd: rate lambd = 1/mean
-    #     # ('lambda' is a Python reserved word)
+        """
+        # lambd: rate lambd = 1/mean
+        # ('lambda' is a Python reserved word)
 
-    #     # we use 1-random() instead of random() to preclude the
-    #     # possibility of taking the log of zero.
-    #     return -_log(1.0 - self.random())/lambd
+        # we use 1-random() instead of random() to preclude the
+        # possibility of taking the log of zero.
+        return -_log(1.0 - self.random())/lambd
 
 ## -------------------- von Mises distribution --------------------
 
-    # def vonmisesvariate(self, mu, kappa):
-    #     """Circular data distribution.
+    def vonmisesvariate(self, mu, kappa):
+        """Circular data distribution.
+
+        mu is the mean angle, expressed in radians between 0 and 2*pi, and
+        kappa is the concentration parameter, which must be greater than or
+        equal to zero.  If kappa is equal to zero, this distribution reduces
+        to a uniform random angle over the range 0 to 2*pi.
+
+        """
+        # mu:    mean angle (in radians between 0 and 2*pi)
+        # kappa: concentration parameter kappa (>= 0)
+        # if kappa = 0 generate uniform random angle
+
+        # Based upon an algorithm published in: Fisher, N.I.,
+        # "Statistical Analysis of Circular Data", Cambridge
+        # University Press, 1993.
+
+        # Thanks to Magnus Kessler for a correction to the
+        # implementation of step 4.
+
+        random = self.random
+        if kappa <= 1e-6:
+            return TWOPI * random()
+
+        s = 0.5 / kappa
+        r = s + _sqrt(1.0 + s * s)
+
+        while 1:
+            u1 = random()
+            z = _cos(_pi * u1)
+
+            d = z / (r + z)
+            u2 = random()
+            if u2 < 1.0 - d * d or u2 <= (1.0 - d) * _exp(d):
+                break
+
+        q = 1.0 / r
+        f = (q + z) / (1.0 + q * z)
+        u3 = random()
+        if u3 > 0.5:
+            theta = (mu + _acos(f)) % TWOPI
+        else:
+            theta = (mu - _acos(f)) % TWOPI
 
-    #     mu is the mean angle, expressed in radians between 0 and 2*pi, and
-    #     kappa is the concentration parameter, which must be greater than or
-    #     equal to zero.  If kappa is equal to zero, this distribution reduces
-    #     to a uniform random angle over the range 0 to 2*pi.
+        return theta
 
-    #     """
-    #     # mu:    mean angle (in radians between 0 and 2*pi)
-    #     # kappa: concentration parameter kappa (>= 0)
-    #     # if kappa = 0 generate uniform random angle
+## -------------------- gamma distribution --------------------
 
-    #     # Based upon an algorithm published in: Fisher, N.I.,
-    #     # "Statistical Analysis of Circular Data", Cambridge
-    #     # University Press, 1993.
+    def gammavariate(self, alpha, beta):
+        """Gamma distribution.  Not the gamma function!
 
-    #     # Thanks to Magnus Kessler for a correction to the
-    #     # implementation of step 4.
+        Conditions on the parameters are alpha > 0 and beta > 0.
 
-    #     random = self.random
-    #     if kappa <= 1e-6:
-    #         return TWOPI * random()
+        The probability distribution function is:
 
-    #     s = 0.5 / kappa
-    #     r = s + _sqrt(1.0 + s * s)
+                    x ** (alpha - 1) * math.exp(-x / beta)
+          pdf(x) =  --------------------------------------
+                      math.gamma(alpha) * beta ** alpha
 
-    #     while 1:
-    #         u1 = random()
-    #         z = _cos(_pi * u1)
+        """
 
-    #         d = z / (r + z)
-    #         u2 = random()
-    #         if u2 < 1.0 - d * d or u2 <= (1.0 - d) * _exp(d):
-    #             break
+        # alpha > 0, beta > 0, mean is alpha*beta, variance is alpha*beta**2
+
+        # Warning: a few older sources define the gamma distribution in terms
+        # of alpha > -1.0
+        if alpha <= 0.0 or beta <= 0.0:
+            raise ValueError, 'gammavariate: alpha and beta must be > 0.0'
+
+        random = self.random
+        if alpha > 1.0:
+
+            # Uses R.C.H. Cheng, "The generation of Gamma
+            # variables with non-integral shape parameters",
+            # Applied Statistics, (1977), 26, No. 1, p71-74
+
+            ainv = _sqrt(2.0 * alpha - 1.0)
+            bbb = alpha - LOG4
+            ccc = alpha + ainv
+
+            while 1:
+                u1 = random()
+                if not 1e-7 < u1 < .9999999:
+                    continue
+                u2 = 1.0 - random()
+                v = _log(u1/(1.0-u1))/ainv
+                x = alpha*_exp(v)
+                z = u1*u1*u2
+                r = bbb+ccc*v-x
+                if r + SG_MAGICCONST - 4.5*z >= 0.0 or r >= _log(z):
+                    return x * beta
+
+        elif alpha == 1.0:
+            # expovariate(1)
+            u = random()
+            while u <= 1e-7:
+                u = random()
+            return -_log(u) * beta
+
+        else:   # alpha is between 0 and 1 (exclusive)
+
+            # Uses ALGORITHM GS of Statistical Computing - Kennedy & Gentle
+
+            while 1:
+                u = random()
+                b = (_e + alpha)/_e
+                p = b*u
+                if p <= 1.0:
+                    x = p ** (1.0/alpha)
+                else:
+                    x = -_log((b-p)/alpha)
+                u1 = random()
+                if p > 1.0:
+                    if u1 <= x ** (alpha - 1.0):
+                        break
+                elif u1 <= _exp(-x):
+                    break
+            return x * beta
 
-    #     q = 1.0 / r
-    #     f = (q + z) / (1.0 + q * z)
-    #     u3 = random()
-    #     if u3 > 0.5:
-    #         theta = (mu + _acos(f)) % TWOPI
-    #     else:
-    #         theta = (mu - _acos(f)) % TWOPI
+## -------------------- Gauss (faster alternative) --------------------
 
-    #     return theta
+    def gauss(self, mu, sigma):
+        """Gaussian distribution.
 
-## -------------------- gamma distribution --------------------
+        mu is the mean, and sigma is the standard deviation.  This is
+        slightly faster than the normalvariate() function.
 
-    # def gammavariate(self, alpha, beta):
-    #     """Gamma distribution.  Not the gamma function!
-
-    #     Conditions on the parameters are alpha > 0 and beta > 0.
-
-    #     The probability distribution function is:
-
-    #                 x ** (alpha - 1) * math.exp(-x / beta)
-    #       pdf(x) =  --------------------------------------
-    #                   math.gamma(alpha) * beta ** alpha
-
-    #     """
-
-    #     # alpha > 0, beta > 0, mean is alpha*beta, variance is alpha*beta**2
-
-    #     # Warning: a few older sources define the gamma distribution in terms
-    #     # of alpha > -1.0
-    #     if alpha <= 0.0 or beta <= 0.0:
-    #         raise ValueError, 'gammavariate: alpha and beta must be > 0.0'
-
-    #     random = self.random
-    #     if alpha > 1.0:
-
-    #         # Uses R.C.H. Cheng, "The generation of Gamma
-    #         # variables with non-integral shape parameters",
-    #         # Applied Statistics, (1977), 26, No. 1, p71-74
-
-    #         ainv = _sqrt(2.0 * alpha - 1.0)
-    #         bbb = alpha - LOG4
-    #         ccc = alpha + ainv
-
-    #         while 1:
-    #             u1 = random()
-    #             if not 1e-7 < u1 < .9999999:
-    #                 continue
-    #             u2 = 1.0 - random()
-    #             v = _log(u1/(1.0-u1))/ainv
-    #             x = alpha*_exp(v)
-    #             z = u1*u1*u2
-    #             r = bbb+ccc*v-x
-    #             if r + SG_MAGICCONST - 4.5*z >= 0.0 or r >= _log(z):
-    #                 return x * beta
-
-    #     elif alpha == 1.0:
-    #         # expovariate(1)
-    #         u = random()
-    #         while u <= 1e-7:
-    #             u = random()
-    #         return -_log(u) * beta
-
-    #     else:   # alpha is between 0 and 1 (exclusive)
-
-    #         # Uses ALGORITHM GS of Statistical Computing - Kennedy & Gentle
-
-    #         while 1:
-    #             u = random()
-    #             b = (_e + alpha)/_e
-    #             p = b*u
-    #             if p <= 1.0:
-    #                 x = p ** (1.0/alpha)
-    #             else:
-    #                 x = -_log((b-p)/alpha)
-    #             u1 = random()
-    #             if p > 1.0:
-    #                 if u1 <= x ** (alpha - 1.0):
-    #                     break
-    #             elif u1 <= _exp(-x):
-    #                 break
-    #         return x * beta
+        Not thread-safe without a lock around calls.
 
-## -------------------- Gauss (faster alternative) --------------------
+        """
 
-    # def gauss(self, mu, sigma):
-    #     """Gaussian distribution.
-
-    #     mu is the mean, and sigma is the standard deviation.  This is
-    #     slightly faster than the normalvariate() function.
-
-    #     Not thread-safe without a lock around calls.
-
-    #     """
-
-    #     # When x and y are two variables from [0, 1), uniformly
-    #     # distributed, then
-    #     #
-    #     #    cos(2*pi*x)*sqrt(-2*log(1-y))
-    #     #    sin(2*pi*x)*sqrt(-2*log(1-y))
-    #     #
-    #     # are two *independent* variables with normal distribution
-    #     # (mu = 0, sigma = 1).
-    #     # (Lambert Meertens)
-    #     # (corrected version; bug discovered by Mike Miller, fixed by LM)
-
-    #     # Multithreading note: When two threads call this function
-    #     # simultaneously, it is possible that they will receive the
-    #     # same return value.  The window is very small though.  To
-    #     # avoid this, you have to use a lock around all calls.  (I
-    #     # didn't want to slow this down in the serial case by using a
-    #     # lock here.)
-
-    #     random = self.random
-    #     z = self.gauss_next
-    #     self.gauss_next = None
-    #     if z is None:
-    #         x2pi = random() * TWOPI
-    #         g2rad = _sqrt(-2.0 * _log(1.0 - random()))
-    #         z = _cos(x2pi) * g2rad
-    #         self.gauss_next = _sin(x2pi) * g2rad
-
-    #     return mu + z*sigma
+        # When x and y are two variables from [0, 1), uniformly
+        # distributed, then
+        #
+        #    cos(2*pi*x)*sqrt(-2*log(1-y))
+        #    sin(2*pi*x)*sqrt(-2*log(1-y))
+        #
+        # are two *independent* variables with normal distribution
+        # (mu = 0, sigma = 1).
+        # (Lambert Meertens)
+        # (corrected version; bug discovered by Mike Miller, fixed by LM)
+
+        # Multithreading note: When two threads call this function
+        # simultaneously, it is possible that they will receive the
+        # same return value.  The window is very small though.  To
+        # avoid this, you have to use a lock around all calls.  (I
+        # didn't want to slow this down in the serial case by using a
+        # lock here.)
+
+        random = self.random
+        z = self.gauss_next
+        self.gauss_next = None
+        if z is None:
+            x2pi = random() * TWOPI
+            g2rad = _sqrt(-2.0 * _log(1.0 - random()))
+            z = _cos(x2pi) * g2rad
+            self.gauss_next = _sin(x2pi) * g2rad
+
+        return mu + z*sigma
 
 ## -------------------- beta --------------------
 ## See
@@ -527,43 +604,43 @@ class Random(_random.GrumpyRandom):
 ##
 ## was dead wrong, and how it probably got that way.
 
-    # def betavariate(self, alpha, beta):
-    #     """Beta distribution.
+    def betavariate(self, alpha, beta):
+        """Beta distribution.
 
-    #     Conditions on the parameters are alpha > 0 and beta > 0.
-    #     Returned values range between 0 and 1.
+        Conditions on the parameters are alpha > 0 and beta > 0.
+        Returned values range between 0 and 1.
 
-    #     """
+        """
 
-    #     # This version due to Janne Sinkkonen, and matches all the std
-    #     # texts (e.g., Knuth Vol 2 Ed 3 pg 134 "the beta distribution").
-    #     y = self.gammavariate(alpha, 1.)
-    #     if y == 0:
-    #         return 0.0
-    #     else:
-    #         return y / (y + self.gammavariate(beta, 1.))
+        # This version due to Janne Sinkkonen, and matches all the std
+        # texts (e.g., Knuth Vol 2 Ed 3 pg 134 "the beta distribution").
+        y = self.gammavariate(alpha, 1.)
+        if y == 0:
+            return 0.0
+        else:
+            return y / (y + self.gammavariate(beta, 1.))
 
 ## -------------------- Pareto --------------------
 
-    # def paretovariate(self, alpha):
-    #     """Pareto distribution.  alpha is the shape parameter."""
-    #     # Jain, pg. 495
+    def paretovariate(self, alpha):
+        """Pareto distribution.  alpha is the shape parameter."""
+        # Jain, pg. 495
 
-    #     u = 1.0 - self.random()
-    #     return 1.0 / pow(u, 1.0/alpha)
+        u = 1.0 - self.random()
+        return 1.0 / u ** (1.0/alpha)
 
 ## -------------------- Weibull --------------------
 
-    # def weibullvariate(self, alpha, beta):
-    #     """Weibull distribution.
+    def weibullvariate(self, alpha, beta):
+        """Weibull distribution.
 
-    #     alpha is the scale parameter and beta is the shape parameter.
+        alpha is the scale parameter and beta is the shape parameter.
 
-    #     """
-    #     # Jain, pg. 499; bug fix courtesy Bill Arms
+        """
+        # Jain, pg. 499; bug fix courtesy Bill Arms
 
-    #     u = 1.0 - self.random()
-    #     return alpha * pow(-_log(u), 1.0/beta)
+        u = 1.0 - self.random()
+        return alpha * (-_log(u)) ** (1.0/beta)
 
 ## -------------------- test program --------------------
 
@@ -616,34 +693,26 @@ def _test(N=2000):
 _inst = Random()
 seed = _inst.seed
 random = _inst.random
+uniform = _inst.uniform
+triangular = _inst.triangular
 randint = _inst.randint
 choice = _inst.choice
 randrange = _inst.randrange
-getrandbits = _inst.getrandbits
+sample = _inst.sample
+shuffle = _inst.shuffle
+normalvariate = _inst.normalvariate
+lognormvariate = _inst.lognormvariate
+expovariate = _inst.expovariate
+vonmisesvariate = _inst.vonmisesvariate
+gammavariate = _inst.gammavariate
+gauss = _inst.gauss
+betavariate = _inst.betavariate
+paretovariate = _inst.paretovariate
+weibullvariate = _inst.weibullvariate
 getstate = _inst.getstate
 setstate = _inst.setstate
-uniform = _inst.uniform
-
-
-def _notimplemented(*args, **kwargs):
-  raise NotImplementedError
-
-
-shuffle = _notimplemented
-choices = _notimplemented
-sample = _notimplemented
-triangular = _notimplemented
-normalvariate = _notimplemented
-lognormvariate = _notimplemented
-expovariate = _notimplemented
-vonmisesvariate = _notimplemented
-gammavariate = _notimplemented
-gauss = _notimplemented
-betavariate = _notimplemented
-paretovariate = _notimplemented
-weibullvariate = _notimplemented
-
+jumpahead = _inst.jumpahead
+getrandbits = _inst.getrandbits
 
 if __name__ == '__main__':
-    pass
-    #_test()
\ No newline at end of file
+    _test()
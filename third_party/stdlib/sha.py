@@ -7,10 +7,9 @@
 # warnings.warn("the sha module is deprecated; use the hashlib module instead",
 #                 DeprecationWarning, 2)
 
-import _sha
+from hashlib import sha1 as sha
 
-sha = _sha.new
-new = _sha.new
+new = sha
 
 blocksize = 1        # legacy value (wrong in any useful sense)
 digest_size = 20
@@ -119,9 +119,7 @@ def print_exception(etype, value, tb, limit=None, file=None):
     position of the error.
     """
     if file is None:
-        # TODO: Use sys.stderr when that's implemented.
-        file = open('/dev/stderr', 'w')
-        #file = sys.stderr
+        file = sys.stderr
     if tb:
         _print(file, 'Traceback (most recent call last):')
         print_tb(tb, limit, file)
@@ -229,9 +227,7 @@ def print_exc(limit=None, file=None):
     (In fact, it uses sys.exc_info() to retrieve the same information
     in a thread-safe way.)"""
     if file is None:
-        # TODO: Use sys.stderr when that's implemented.
-        file = open('/dev/stderr', 'w')
-        #file = sys.stderr
+        file = sys.stderr
     try:
         etype, value, tb = sys.exc_info()
         print_exception(etype, value, tb, limit, file)
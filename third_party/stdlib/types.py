@@ -41,8 +41,8 @@ DictType = DictionaryType = dict
 
 def _f(): pass
 FunctionType = type(_f)
-#LambdaType = type(lambda: None)         # Same as FunctionType
-#CodeType = type(_f.func_code)
+LambdaType = type(lambda: None)         # Same as FunctionType
+CodeType = type(_f.func_code)
 
 def _g():
     yield 1
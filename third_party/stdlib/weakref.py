@@ -11,17 +11,14 @@ http://www.python.org/dev/peps/pep-0205/
 
 import UserDict
 
-#from _weakref import (
+from _weakref import (
 #     getweakrefcount,
 #     getweakrefs,
-#     ref,
-#     proxy,
-#     CallableProxyType,
-#     ProxyType,
-#     ReferenceType)
-
-from '__go__/grumpy' import WeakRefType as ReferenceType
-ref = ReferenceType
+     ref,
+     proxy,
+     CallableProxyType,
+     ProxyType,
+     ReferenceType)
 
 import _weakrefset
 WeakSet = _weakrefset.WeakSet
@@ -31,11 +28,11 @@ import exceptions
 ReferenceError = exceptions.ReferenceError
 
 
-#ProxyTypes = (ProxyType, CallableProxyType)
+ProxyTypes = (ProxyType, CallableProxyType)
 
-#__all__ = ["ref", "proxy", "getweakrefcount", "getweakrefs",
-#           "WeakKeyDictionary", "ReferenceError", "ReferenceType", "ProxyType",
-#           "CallableProxyType", "ProxyTypes", "WeakValueDictionary", 'WeakSet']
+__all__ = ["ref", "proxy", #"getweakrefcount", "getweakrefs",
+           "WeakKeyDictionary", "ReferenceError", "ReferenceType", "ProxyType",
+           "CallableProxyType", "ProxyTypes", "WeakValueDictionary", 'WeakSet']
 
 
 class WeakValueDictionary(UserDict.UserDict):